@@ -0,0 +1,407 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/blocksync"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/network"
+	"github.com/iotexproject/iotex-core/txpool"
+)
+
+var log = logger.New("admin")
+
+// The HTTP paths of the admin operations
+const (
+	// StopPath is the path stopping the node gracefully on a POST request
+	StopPath = "/stop"
+	// PeersPath is the path the peers connected to or banned by the node are listed on
+	PeersPath = "/peers"
+	// MempoolPath is the path the transactions accepted into the txpool are listed on
+	MempoolPath = "/mempool"
+	// LogLevelPath is the path the log levels are read and set on, see logger.LevelHandler
+	LogLevelPath = "/loglevel"
+	// VerifyChainPath is the path re-validating the last 'depth' blocks, or the whole chain without depth, on a POST
+	// request, e.g., POST ?depth=100
+	VerifyChainPath = "/verifychain"
+	// RollbackPath is the path resetting the chain to the block at 'height' on a POST request, which has to confirm the
+	// current tip hash as 'tip' in hex, e.g., POST ?height=100&tip=...
+	RollbackPath = "/rollback"
+	// ReloadPath is the path re-reading the config file and applying its hot reloadable fields on a POST request
+	ReloadPath = "/reload"
+	// HealthPath is the path the health of the blockchain tip is told on, see blocksync.TipMonitor
+	HealthPath = "/health"
+	// ReorgPath is the path the fork halting the chain for being deeper than the max reorg depth is told on, and
+	// allowing the fork starting with the block of 'hash' in hex and resuming the chain on a POST request, e.g.,
+	// POST ?hash=...
+	ReorgPath = "/reorg"
+)
+
+// PeerManager provides the peers connected to or banned by the node
+type PeerManager interface {
+	PeerInfos() []network.PeerInfo
+}
+
+// HealthMonitor provides the health of the blockchain tip
+type HealthMonitor interface {
+	Health() blocksync.HealthStatus
+}
+
+// Health is the health of the blockchain tip told by the admin service
+type Health struct {
+	Status        string `json:"status"`
+	TipHeight     uint32 `json:"tipHeight"`
+	LastAdvance   int64  `json:"lastAdvance"`
+	PeerTipHeight uint32 `json:"peerTipHeight"`
+	Resyncs       uint64 `json:"resyncs"`
+}
+
+// ReorgStatus is the fork halting the chain told by the admin service
+type ReorgStatus struct {
+	Halted bool   `json:"halted"`
+	Height uint32 `json:"height,omitempty"`
+	Hash   string `json:"hash,omitempty"`
+	Depth  uint32 `json:"depth,omitempty"`
+}
+
+// Peer is a peer listed by the admin service
+type Peer struct {
+	Addr        string `json:"addr"`
+	Connected   bool   `json:"connected"`
+	Score       uint   `json:"score"`
+	LastResTime int64  `json:"lastResTime,omitempty"`
+	BannedUntil int64  `json:"bannedUntil,omitempty"`
+}
+
+// MempoolTx is a transaction of the txpool listed by the admin service
+type MempoolTx struct {
+	Hash        string  `json:"hash"`
+	Size        uint32  `json:"size"`
+	Fee         int64   `json:"fee"`
+	FeePerKB    int64   `json:"feePerKB"`
+	Priority    float64 `json:"priority"`
+	AddedTime   int64   `json:"addedTime"`
+	BlockHeight uint32  `json:"blockHeight"`
+}
+
+// Server serves the operational controls of the node over HTTP to the operators holding the token of the config
+type Server struct {
+	config     config.Admin
+	blockchain blockchain.IBlockchain
+	txpool     txpool.TxPool
+	peers      PeerManager
+	health     HealthMonitor
+	stop       func()
+	reload     func() error
+	httpserver *http.Server
+}
+
+// NewServer creates an instance of the admin server, stop is called to stop the node gracefully
+func NewServer(c config.Admin, bc blockchain.IBlockchain, tp txpool.TxPool, stop func()) (*Server, error) {
+	if stop == nil {
+		return nil, errors.New("cannot new admin server with nil stop function")
+	}
+	return &Server{config: c, blockchain: bc, txpool: tp, stop: stop}, nil
+}
+
+// SetPeerManager sets the peer manager whose peers are listed on PeersPath
+func (s *Server) SetPeerManager(pm PeerManager) {
+	s.peers = pm
+}
+
+// SetHealthMonitor sets the monitor whose health of the tip is told on HealthPath
+func (s *Server) SetHealthMonitor(hm HealthMonitor) {
+	s.health = hm
+}
+
+// SetReloader sets the function reloading the config on ReloadPath, which returns an error whose cause is
+// config.ErrNotReloadable if the config changes fields which cannot be applied at runtime
+func (s *Server) SetReloader(reload func() error) {
+	s.reload = reload
+}
+
+// Handler serves the admin operations, rejecting the requests without the bearer token of the config
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(StopPath, s.handleStop)
+	mux.HandleFunc(PeersPath, s.handlePeers)
+	mux.HandleFunc(MempoolPath, s.handleMempool)
+	mux.Handle(LogLevelPath, logger.LevelHandler())
+	mux.HandleFunc(VerifyChainPath, s.handleVerifyChain)
+	mux.HandleFunc(RollbackPath, s.handleRollback)
+	mux.HandleFunc(ReloadPath, s.handleReload)
+	mux.HandleFunc(HealthPath, s.handleHealth)
+	mux.HandleFunc(ReorgPath, s.handleReorg)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// authorized returns true if the request carries the token of the config, an empty token authorizes nothing
+func (s *Server) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if s.config.Token == "" || !strings.HasPrefix(auth, prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(s.config.Token)) == 1
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	log.Info("Stopping the node on admin request")
+	w.WriteHeader(http.StatusAccepted)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	s.stop()
+}
+
+func (s *Server) handlePeers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	peers := []Peer{}
+	if s.peers != nil {
+		for _, info := range s.peers.PeerInfos() {
+			peer := Peer{Addr: info.Addr, Connected: info.Connected, Score: info.Score}
+			if !info.LastResTime.IsZero() {
+				peer.LastResTime = info.LastResTime.Unix()
+			}
+			if !info.BannedUntil.IsZero() {
+				peer.BannedUntil = info.BannedUntil.Unix()
+			}
+			peers = append(peers, peer)
+		}
+	}
+	writeJSON(w, peers)
+}
+
+func (s *Server) handleMempool(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	txs := []MempoolTx{}
+	if s.txpool != nil {
+		for _, desc := range s.txpool.TxDescs() {
+			hash := desc.Tx.Hash()
+			txs = append(txs, MempoolTx{
+				Hash:        hex.EncodeToString(hash[:]),
+				Size:        desc.Tx.TotalSize(),
+				Fee:         desc.Fee,
+				FeePerKB:    desc.FeePerKB,
+				Priority:    desc.Priority,
+				AddedTime:   desc.AddedTime.Unix(),
+				BlockHeight: desc.BlockHeight,
+			})
+		}
+	}
+	writeJSON(w, txs)
+}
+
+func (s *Server) handleVerifyChain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var depth uint64
+	if d := r.FormValue("depth"); d != "" {
+		var err error
+		if depth, err = strconv.ParseUint(d, 10, 32); err != nil {
+			http.Error(w, "invalid depth "+d, http.StatusBadRequest)
+			return
+		}
+	}
+	start := time.Now()
+	if err := s.blockchain.VerifyChain(r.Context(), uint32(depth)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Infof("Verified the chain of depth %d in %v on admin request", depth, time.Since(start))
+	writeJSON(w, map[string]uint32{"tipHeight": s.blockchain.TipHeight()})
+}
+
+// handleRollback rolls the chain back once the request confirms the tip it is rolled back from, so a stale or replayed
+// request does not remove the blocks committed since, and the blocks removed are within the depth of the config
+func (s *Server) handleRollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h := r.FormValue("height")
+	height, err := strconv.ParseUint(h, 10, 32)
+	if err != nil {
+		http.Error(w, "invalid height "+h, http.StatusBadRequest)
+		return
+	}
+	tip := s.blockchain.TipHash()
+	if confirm := r.FormValue("tip"); confirm != hex.EncodeToString(tip[:]) {
+		http.Error(w, fmt.Sprintf("tip %q does not confirm the current tip %x", confirm, tip), http.StatusConflict)
+		return
+	}
+	tipHeight := s.blockchain.TipHeight()
+	if uint32(height) >= tipHeight {
+		http.Error(w, fmt.Sprintf("height %d is not below the tip height %d", height, tipHeight), http.StatusBadRequest)
+		return
+	}
+	if depth := tipHeight - uint32(height); depth > s.config.MaxRollbackDepth {
+		http.Error(w, fmt.Sprintf("rolling back %d blocks exceeds the max rollback depth %d", depth, s.config.MaxRollbackDepth), http.StatusForbidden)
+		return
+	}
+	if err := s.blockchain.RollbackToHeight(r.Context(), uint32(height)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Warningf("Rolled back the chain from height %d to %d on admin request", tipHeight, height)
+	tip = s.blockchain.TipHash()
+	writeJSON(w, map[string]interface{}{"tipHeight": s.blockchain.TipHeight(), "tipHash": hex.EncodeToString(tip[:])})
+}
+
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.reload == nil {
+		http.Error(w, "config reloading is not supported", http.StatusNotImplemented)
+		return
+	}
+	if err := s.reload(); err != nil {
+		if errors.Cause(err) == config.ErrNotReloadable {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Info("Reloaded the config on admin request")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.health == nil {
+		http.Error(w, "tip monitoring is not enabled", http.StatusNotImplemented)
+		return
+	}
+	status := s.health.Health()
+	writeJSON(w, Health{
+		Status:        status.Status,
+		TipHeight:     status.TipHeight,
+		LastAdvance:   status.LastAdvance.Unix(),
+		PeerTipHeight: status.PeerTipHeight,
+		Resyncs:       status.Resyncs,
+	})
+}
+
+// handleReorg tells the fork halting the chain, or allows the fork starting with the block of the hash beyond the max
+// reorg depth on a POST request, resuming the chain
+func (s *Server) handleReorg(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		status := ReorgStatus{}
+		if halt := s.blockchain.ReorgHalt(); halt != nil {
+			status = ReorgStatus{Halted: true, Height: halt.Height, Hash: hex.EncodeToString(halt.Hash[:]), Depth: halt.Depth}
+		}
+		writeJSON(w, status)
+	case http.MethodPost:
+		h := r.FormValue("hash")
+		buf, err := hex.DecodeString(h)
+		if err != nil || len(buf) != len(cp.ZeroHash32B) {
+			http.Error(w, "invalid hash "+h, http.StatusBadRequest)
+			return
+		}
+		var hash cp.Hash32B
+		copy(hash[:], buf)
+		s.blockchain.AllowReorg(hash)
+		log.Warningf("Allowed the fork starting with block %x beyond the max reorg depth on admin request", hash)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("Failed to write admin reply: %v", err)
+	}
+}
+
+// Start starts the admin server
+func (s *Server) Start() error {
+	if s.config.Addr == "" {
+		log.Warning("Admin service is not configured")
+		return nil
+	}
+	if s.config.Token == "" {
+		return errors.New("admin service cannot be started without token")
+	}
+
+	var tlsConfig *tls.Config
+	if s.config.TLSEnabled {
+		cert, err := tls.LoadX509KeyPair(s.config.CertPath, s.config.KeyPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to load admin server certificate")
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	lis, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return errors.Wrap(err, "admin server failed to listen")
+	}
+	if tlsConfig != nil {
+		lis = tls.NewListener(lis, tlsConfig)
+	}
+	log.Infof("Admin server is listening on %v", lis.Addr().String())
+
+	s.httpserver = &http.Server{Handler: s.Handler()}
+	go func() {
+		if err := s.httpserver.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Admin server failed to serve: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop stops the admin server
+func (s *Server) Stop() error {
+	if s.httpserver != nil {
+		return s.httpserver.Close()
+	}
+	return nil
+}
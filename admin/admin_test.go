@@ -0,0 +1,264 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/blocksync"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/network"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_txpool"
+	"github.com/iotexproject/iotex-core/txpool"
+)
+
+const testToken = "secret"
+
+type testPeerManager []network.PeerInfo
+
+func (pm testPeerManager) PeerInfos() []network.PeerInfo {
+	return pm
+}
+
+func do(t *testing.T, server *httptest.Server, method string, path string, token string) (int, string) {
+	req, err := http.NewRequest(method, server.URL+path, nil)
+	require.Nil(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := server.Client().Do(req)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestAdmin(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mtp := mock_txpool.NewMockTxPool(ctrl)
+	stopped := 0
+	s, err := NewServer(config.Admin{Token: testToken}, mbc, mtp, func() { stopped++ })
+	require.Nil(err)
+	added := time.Unix(1524000000, 0)
+	s.SetPeerManager(testPeerManager{
+		{Addr: "127.0.0.1:10001", Connected: true, LastResTime: added},
+		{Addr: "127.0.0.1:10002", Score: 100, BannedUntil: added},
+	})
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	// requests without the token are rejected
+	for _, token := range []string{"", "wrong"} {
+		code, _ := do(t, server, http.MethodPost, StopPath, token)
+		assert.Equal(http.StatusUnauthorized, code)
+	}
+	assert.Equal(0, stopped)
+
+	code, _ := do(t, server, http.MethodGet, StopPath, testToken)
+	assert.Equal(http.StatusMethodNotAllowed, code)
+	code, _ = do(t, server, http.MethodPost, StopPath, testToken)
+	assert.Equal(http.StatusAccepted, code)
+	assert.Equal(1, stopped)
+
+	code, body := do(t, server, http.MethodGet, PeersPath, testToken)
+	assert.Equal(http.StatusOK, code)
+	var peers []Peer
+	require.Nil(json.Unmarshal([]byte(body), &peers))
+	assert.Equal([]Peer{
+		{Addr: "127.0.0.1:10001", Connected: true, LastResTime: added.Unix()},
+		{Addr: "127.0.0.1:10002", Score: 100, BannedUntil: added.Unix()},
+	}, peers)
+
+	tx := blockchain.NewTx(1, nil, nil, 0)
+	hash := tx.Hash()
+	mtp.EXPECT().TxDescs().Return([]*txpool.TxDesc{{Tx: tx, AddedTime: added, BlockHeight: 3, Fee: 10, FeePerKB: 100}})
+	code, body = do(t, server, http.MethodGet, MempoolPath, testToken)
+	assert.Equal(http.StatusOK, code)
+	var txs []MempoolTx
+	require.Nil(json.Unmarshal([]byte(body), &txs))
+	require.Equal(1, len(txs))
+	assert.Equal(MempoolTx{
+		Hash:        hex.EncodeToString(hash[:]),
+		Size:        tx.TotalSize(),
+		Fee:         10,
+		FeePerKB:    100,
+		AddedTime:   added.Unix(),
+		BlockHeight: 3,
+	}, txs[0])
+
+	defer logger.SetModuleLevel("admin_test", logger.InfoLevel)
+	code, body = do(t, server, http.MethodPost, LogLevelPath+"?module=admin_test&level=debug", testToken)
+	assert.Equal(http.StatusOK, code)
+	assert.Contains(strings.Split(body, "\n"), "admin_test=debug")
+	assert.Equal(logger.DebugLevel, logger.ModuleLevel("admin_test"))
+
+	mbc.EXPECT().VerifyChain(gomock.Any(), uint32(100)).Return(nil)
+	mbc.EXPECT().TipHeight().Return(uint32(120))
+	code, body = do(t, server, http.MethodPost, VerifyChainPath+"?depth=100", testToken)
+	assert.Equal(http.StatusOK, code)
+	assert.Equal("{\"tipHeight\":120}\n", body)
+	mbc.EXPECT().VerifyChain(gomock.Any(), uint32(0)).Return(errors.New("UTXO set mismatch"))
+	code, body = do(t, server, http.MethodPost, VerifyChainPath, testToken)
+	assert.Equal(http.StatusInternalServerError, code)
+	assert.Contains(body, "UTXO set mismatch")
+	code, _ = do(t, server, http.MethodPost, VerifyChainPath+"?depth=-1", testToken)
+	assert.Equal(http.StatusBadRequest, code)
+}
+
+func TestAdminRollback(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	s, err := NewServer(config.Admin{Token: testToken, MaxRollbackDepth: 10}, mbc, nil, func() {})
+	assert.Nil(err)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	tip := crypto.Hash32B{1}
+	confirm := "&tip=" + hex.EncodeToString(tip[:])
+	mbc.EXPECT().TipHash().Return(tip).AnyTimes()
+	mbc.EXPECT().TipHeight().Return(uint32(100)).Times(3)
+
+	code, _ := do(t, server, http.MethodGet, RollbackPath+"?height=95"+confirm, testToken)
+	assert.Equal(http.StatusMethodNotAllowed, code)
+	code, _ = do(t, server, http.MethodPost, RollbackPath+"?height=-1"+confirm, testToken)
+	assert.Equal(http.StatusBadRequest, code)
+	// the request has to confirm the current tip
+	code, body := do(t, server, http.MethodPost, RollbackPath+"?height=95&tip=00", testToken)
+	assert.Equal(http.StatusConflict, code)
+	assert.Contains(body, "does not confirm")
+	code, _ = do(t, server, http.MethodPost, RollbackPath+"?height=100"+confirm, testToken)
+	assert.Equal(http.StatusBadRequest, code)
+	code, _ = do(t, server, http.MethodPost, RollbackPath+"?height=89"+confirm, testToken)
+	assert.Equal(http.StatusForbidden, code)
+
+	mbc.EXPECT().RollbackToHeight(gomock.Any(), uint32(90)).Return(nil)
+	mbc.EXPECT().TipHeight().Return(uint32(90))
+	code, body = do(t, server, http.MethodPost, RollbackPath+"?height=90"+confirm, testToken)
+	assert.Equal(http.StatusOK, code)
+	assert.Contains(body, "\"tipHeight\":90")
+}
+
+func TestAdminReload(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewServer(config.Admin{Token: testToken}, nil, nil, func() {})
+	assert.Nil(err)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	code, _ := do(t, server, http.MethodPost, ReloadPath, testToken)
+	assert.Equal(http.StatusNotImplemented, code)
+
+	var reloadErr error
+	s.SetReloader(func() error { return reloadErr })
+	code, _ = do(t, server, http.MethodGet, ReloadPath, testToken)
+	assert.Equal(http.StatusMethodNotAllowed, code)
+	code, _ = do(t, server, http.MethodPost, ReloadPath, testToken)
+	assert.Equal(http.StatusNoContent, code)
+	reloadErr = errors.Wrap(config.ErrNotReloadable, "consensus-critical sections [Chain] changed")
+	code, body := do(t, server, http.MethodPost, ReloadPath, testToken)
+	assert.Equal(http.StatusConflict, code)
+	assert.Contains(body, "[Chain]")
+	reloadErr = errors.New("error when reading the config file")
+	code, _ = do(t, server, http.MethodPost, ReloadPath, testToken)
+	assert.Equal(http.StatusInternalServerError, code)
+}
+
+func TestAdminReorg(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	s, err := NewServer(config.Admin{Token: testToken}, mbc, nil, func() {})
+	assert.Nil(err)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	mbc.EXPECT().ReorgHalt().Return(nil)
+	code, body := do(t, server, http.MethodGet, ReorgPath, testToken)
+	assert.Equal(http.StatusOK, code)
+	assert.JSONEq(`{"halted":false}`, body)
+
+	hash := crypto.Hash32B{1}
+	hexHash := hex.EncodeToString(hash[:])
+	mbc.EXPECT().ReorgHalt().Return(&blockchain.ReorgHalt{Height: 90, Hash: hash, Depth: 11})
+	code, body = do(t, server, http.MethodGet, ReorgPath, testToken)
+	assert.Equal(http.StatusOK, code)
+	assert.JSONEq(`{"halted":true,"height":90,"hash":"`+hexHash+`","depth":11}`, body)
+
+	code, _ = do(t, server, http.MethodPost, ReorgPath+"?hash=00", testToken)
+	assert.Equal(http.StatusBadRequest, code)
+	code, _ = do(t, server, http.MethodDelete, ReorgPath, testToken)
+	assert.Equal(http.StatusMethodNotAllowed, code)
+	mbc.EXPECT().AllowReorg(hash)
+	code, _ = do(t, server, http.MethodPost, ReorgPath+"?hash="+hexHash, testToken)
+	assert.Equal(http.StatusNoContent, code)
+}
+
+type testHealthMonitor struct {
+	status blocksync.HealthStatus
+}
+
+func (m *testHealthMonitor) Health() blocksync.HealthStatus { return m.status }
+
+func TestAdminHealth(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewServer(config.Admin{Token: testToken}, nil, nil, func() {})
+	assert.Nil(err)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	code, _ := do(t, server, http.MethodGet, HealthPath, testToken)
+	assert.Equal(http.StatusNotImplemented, code)
+
+	s.SetHealthMonitor(&testHealthMonitor{blocksync.HealthStatus{
+		Status:        blocksync.TipResyncing,
+		TipHeight:     10,
+		LastAdvance:   time.Unix(1000, 0),
+		PeerTipHeight: 20,
+		Resyncs:       1,
+	}})
+	code, _ = do(t, server, http.MethodPost, HealthPath, testToken)
+	assert.Equal(http.StatusMethodNotAllowed, code)
+	code, body := do(t, server, http.MethodGet, HealthPath, testToken)
+	assert.Equal(http.StatusOK, code)
+	assert.JSONEq(`{"status":"resyncing","tipHeight":10,"lastAdvance":1000,"peerTipHeight":20,"resyncs":1}`, body)
+}
+
+func TestAdminStart(t *testing.T) {
+	s, err := NewServer(config.Admin{Addr: "127.0.0.1:0"}, nil, nil, func() {})
+	assert.Nil(t, err)
+	assert.NotNil(t, s.Start())
+
+	_, err = NewServer(config.Admin{}, nil, nil, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,806 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package api
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/network"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/txpool"
+)
+
+var log = logger.New("api")
+
+const (
+	// MaxBlocksPerRange is the max number of blocks returned by one GetBlocksByRange request unless configured
+	MaxBlocksPerRange = 100
+	// MaxBlocksPerLogQuery is the max number of blocks searched by one GetLogs request unless configured
+	MaxBlocksPerLogQuery = 10000
+	// DefaultFeeTargetBlocks is the number of blocks the fee estimated by ValidateTransaction targets unless requested
+	DefaultFeeTargetBlocks = 6
+)
+
+var (
+	// ErrInvalidRequest indicates the request is malformed
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrTxNotFound indicates the transaction cannot be found on the chain
+	ErrTxNotFound = errors.New("transaction not found")
+	// ErrChainNotFound indicates the chain selected by the request is not hosted by the node
+	ErrChainNotFound = errors.New("chain not found")
+)
+
+// The request metadata selecting the chain a request is served by, the main chain of the server if it is not given
+const (
+	// ChainIDKey is the gRPC metadata key carrying the chain ID of a request
+	ChainIDKey = "chain-id"
+	// ChainIDHeader is the HTTP header carrying the chain ID of a JSON-RPC request
+	ChainIDHeader = "X-Chain-ID"
+)
+
+// PeerManager provides the peers connected to or banned by the node
+type PeerManager interface {
+	PeerInfos() []network.PeerInfo
+}
+
+// ChainRouter provides the chains hosted by the node along with the main chain of the server
+type ChainRouter interface {
+	// Chain returns the chain of the ID, or nil if it is not hosted
+	Chain(chainID uint32) blockchain.IBlockchain
+}
+
+// Server is used to implement the node API service
+type Server struct {
+	blockchain  blockchain.IBlockchain
+	config      config.API
+	dispatcher  cm.Dispatcher
+	grpcserver  *grpc.Server
+	httpserver  *http.Server
+	broadcastcb func(proto.Message) error
+	peers       PeerManager
+	txpool      txpool.TxPool
+	chains      ChainRouter
+	limiterMu   sync.RWMutex
+	limiter     *rateLimiter
+}
+
+// NewServer creates an instance of the API server
+func NewServer(c config.API, b blockchain.IBlockchain, dp cm.Dispatcher, cb func(proto.Message) error) (*Server, error) {
+	if cb == nil {
+		return nil, errors.New("cannot new api server with nil callback")
+	}
+	return &Server{blockchain: b, config: c, dispatcher: dp, broadcastcb: cb, limiter: newRateLimiter(c)}, nil
+}
+
+// SetPeerManager sets the peer manager whose peers are returned by GetPeers
+func (s *Server) SetPeerManager(pm PeerManager) {
+	s.peers = pm
+}
+
+// SetTxPool sets the pool validating and accepting the transactions sent by SendRawTransaction
+func (s *Server) SetTxPool(tp txpool.TxPool) {
+	s.txpool = tp
+}
+
+// SetChainRouter sets the router of the chains other than the main chain the requests can select by chain ID
+func (s *Server) SetChainRouter(r ChainRouter) {
+	s.chains = r
+}
+
+// chain returns the chain selected by the chain ID in the metadata of the request, or the main chain without it
+func (s *Server) chain(ctx context.Context) (blockchain.IBlockchain, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[ChainIDKey]) == 0 {
+		return s.blockchain, nil
+	}
+	id, err := strconv.ParseUint(md[ChainIDKey][0], 10, 32)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidRequest, "chain ID = %s", md[ChainIDKey][0])
+	}
+	if uint32(id) == s.blockchain.ChainID() {
+		return s.blockchain, nil
+	}
+	if s.chains != nil {
+		if bc := s.chains.Chain(uint32(id)); bc != nil {
+			return bc, nil
+		}
+	}
+	return nil, errors.Wrapf(ErrChainNotFound, "chain ID = %d", id)
+}
+
+// GetBlockByHeight returns the block at the given height
+func (s *Server) GetBlockByHeight(ctx context.Context, in *pb.GetBlockByHeightRequest) (*pb.GetBlockReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blk, err := bc.GetBlockByHeight(in.Height)
+	if err != nil {
+		return nil, err
+	}
+	hash := blk.HashBlock()
+	return &pb.GetBlockReply{Block: blk.ConvertToBlockPb(), Hash: hash[:], Height: in.Height}, nil
+}
+
+// GetBlockByHash returns the block with the given hash
+func (s *Server) GetBlockByHash(ctx context.Context, in *pb.GetBlockByHashRequest) (*pb.GetBlockReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(in.Hash) != len(cp.ZeroHash32B) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "hash length = %d", len(in.Hash))
+	}
+	var hash cp.Hash32B
+	copy(hash[:], in.Hash)
+	height, err := bc.GetHeightByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	blk, err := bc.GetBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetBlockReply{Block: blk.ConvertToBlockPb(), Hash: hash[:], Height: height}, nil
+}
+
+// GetBlockMetaByHeight returns the header info of the block at the given height without reading the whole block
+func (s *Server) GetBlockMetaByHeight(ctx context.Context, in *pb.GetBlockMetaByHeightRequest) (*pb.GetBlockMetaReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := bc.GetBlockMetaByHeight(in.Height)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetBlockMetaReply{Meta: meta.ConvertToBlockMetaPb()}, nil
+}
+
+// GetBlockMetaByHash returns the header info of the block with the given hash without reading the whole block
+func (s *Server) GetBlockMetaByHash(ctx context.Context, in *pb.GetBlockMetaByHashRequest) (*pb.GetBlockMetaReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(in.Hash) != len(cp.ZeroHash32B) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "hash length = %d", len(in.Hash))
+	}
+	var hash cp.Hash32B
+	copy(hash[:], in.Hash)
+	meta, err := bc.GetBlockMetaByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetBlockMetaReply{Meta: meta.ConvertToBlockMetaPb()}, nil
+}
+
+// GetFinality returns the highest block finalized by the checkpoint votes of the delegates, and whether the block at
+// the height is final, i.e., at or below it
+func (s *Server) GetFinality(ctx context.Context, in *pb.GetFinalityRequest) (*pb.GetFinalityReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	height := bc.FinalizedHeight()
+	hash, err := bc.GetHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetFinalityReply{
+		FinalizedHeight: height,
+		FinalizedHash:   hash[:],
+		Final:           height > 0 && in.Height <= height,
+	}, nil
+}
+
+// GetBlocksByRange returns the blocks from the start height to the end height inclusive
+func (s *Server) GetBlocksByRange(ctx context.Context, in *pb.GetBlocksByRangeRequest) (*pb.GetBlocksByRangeReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	max := maxBlocks(s.config.MaxBlocksPerRange, MaxBlocksPerRange)
+	if in.Start > in.End || in.End-in.Start >= max {
+		return nil, errors.Wrapf(ErrInvalidRequest, "block range [%d, %d], at most %d blocks", in.Start, in.End, max)
+	}
+	blks, err := bc.GetBlocksByRange(ctx, in.Start, in.End)
+	if err != nil {
+		return nil, err
+	}
+	r := &pb.GetBlocksByRangeReply{}
+	for _, blk := range blks {
+		hash := blk.HashBlock()
+		r.Blocks = append(r.Blocks, &pb.GetBlockReply{Block: blk.ConvertToBlockPb(), Hash: hash[:], Height: blk.Height()})
+	}
+	return r, nil
+}
+
+// GetTransaction returns the transaction with the given hash along with the block containing it
+func (s *Server) GetTransaction(ctx context.Context, in *pb.GetTransactionRequest) (*pb.GetTransactionReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(in.Hash) != len(cp.ZeroHash32B) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "hash length = %d", len(in.Hash))
+	}
+	var hash cp.Hash32B
+	copy(hash[:], in.Hash)
+	blk, tx, err := findTransaction(bc, hash)
+	if err != nil {
+		return nil, err
+	}
+	blkHash := blk.HashBlock()
+	return &pb.GetTransactionReply{Tx: tx.ConvertToTxPb(), BlockHash: blkHash[:], BlockHeight: blk.Height()}, nil
+}
+
+// GetMerkleProof returns the proof of the transaction's inclusion in its block, along with the block header
+func (s *Server) GetMerkleProof(ctx context.Context, in *pb.GetMerkleProofRequest) (*pb.GetMerkleProofReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(in.TxHash) != len(cp.ZeroHash32B) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "hash length = %d", len(in.TxHash))
+	}
+	var hash cp.Hash32B
+	copy(hash[:], in.TxHash)
+	blk, _, err := findTransaction(bc, hash)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := blk.MerkleProof(hash)
+	if err != nil {
+		return nil, err
+	}
+	blkHash := blk.HashBlock()
+	r := &pb.GetMerkleProofReply{
+		Header:      blk.ConvertToBlockHeaderPb(),
+		BlockHash:   blkHash[:],
+		BlockHeight: blk.Height(),
+		Index:       proof.Index,
+	}
+	for i := range proof.Siblings {
+		r.Siblings = append(r.Siblings, proof.Siblings[i][:])
+	}
+	return r, nil
+}
+
+// GetReceiptByTxHash returns the receipt of the execution with the given hash
+func (s *Server) GetReceiptByTxHash(ctx context.Context, in *pb.GetReceiptByTxHashRequest) (*pb.GetReceiptReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(in.Hash) != len(cp.ZeroHash32B) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "hash length = %d", len(in.Hash))
+	}
+	var hash cp.Hash32B
+	copy(hash[:], in.Hash)
+	receipt, err := bc.GetReceipt(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetReceiptReply{Receipt: receipt.ConvertToReceiptPb()}, nil
+}
+
+// GetLogs returns the logs emitted from the from height to the to height inclusive by any of the given contracts and
+// carrying all of the given topics
+func (s *Server) GetLogs(ctx context.Context, in *pb.GetLogsRequest) (*pb.GetLogsReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	max := maxBlocks(s.config.MaxBlocksPerLogQuery, MaxBlocksPerLogQuery)
+	if in.FromHeight > in.ToHeight || in.ToHeight-in.FromHeight >= max {
+		return nil, errors.Wrapf(ErrInvalidRequest, "block range [%d, %d], at most %d blocks", in.FromHeight, in.ToHeight, max)
+	}
+	filter := &blockchain.LogFilter{Addresses: in.Addresses, Topics: in.Topics}
+	logs, err := bc.GetLogs(ctx, in.FromHeight, in.ToHeight, filter)
+	if err != nil {
+		return nil, err
+	}
+	r := &pb.GetLogsReply{}
+	for _, l := range logs {
+		r.Logs = append(r.Logs, l.ConvertToLogPb())
+	}
+	return r, nil
+}
+
+// maxBlocks returns the configured max number of blocks of a query, or the default if it is not configured
+func maxBlocks(configured uint32, def uint32) uint32 {
+	if configured > 0 {
+		return configured
+	}
+	return def
+}
+
+// findTransaction returns the block containing the transaction with the given hash and the transaction
+func findTransaction(bc blockchain.IBlockchain, hash cp.Hash32B) (*blockchain.Block, *blockchain.Tx, error) {
+	// there is no transaction index yet, so walk the chain backwards from the tip
+	for height := bc.TipHeight(); ; height-- {
+		blk, err := bc.GetBlockByHeight(height)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, tx := range blk.Tranxs {
+			if tx.Hash() == hash {
+				return blk, tx, nil
+			}
+		}
+		if height == 0 {
+			break
+		}
+	}
+	return nil, nil, errors.Wrapf(ErrTxNotFound, "hash = %x", hash)
+}
+
+// GetBalance returns the balance of the given address
+func (s *Server) GetBalance(ctx context.Context, in *pb.GetBalanceRequest) (*pb.GetBalanceReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !iotxaddress.ValidateAddress(in.Address) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "address = %s", in.Address)
+	}
+	return &pb.GetBalanceReply{Balance: bc.BalanceOf(in.Address, 0)}, nil
+}
+
+// GetPendingNonce returns the nonce of the given address confirmed by the chain and the one once its actions pending in
+// the txpool are confirmed, the next action of the address has to carry the nonce after the pending one
+func (s *Server) GetPendingNonce(ctx context.Context, in *pb.GetPendingNonceRequest) (*pb.GetPendingNonceReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !iotxaddress.ValidateAddress(in.Address) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "address = %s", in.Address)
+	}
+	r := &pb.GetPendingNonceReply{ConfirmedNonce: bc.AccountState(in.Address).Nonce}
+	r.PendingNonce = r.ConfirmedNonce
+	// the txpool only holds the actions of the main chain
+	if bc == s.blockchain && s.txpool != nil {
+		r.PendingNonce = s.txpool.PendingNonce(in.Address)
+	}
+	return r, nil
+}
+
+// GetPendingTxs returns the transactions pending in the txpool which pay the given address or spend its UTXO, oldest
+// first
+func (s *Server) GetPendingTxs(ctx context.Context, in *pb.GetPendingTxsRequest) (*pb.GetPendingTxsReply, error) {
+	if err := s.checkTxPool(ctx); err != nil {
+		return nil, err
+	}
+	if !iotxaddress.ValidateAddress(in.Address) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "address = %s", in.Address)
+	}
+	r := &pb.GetPendingTxsReply{}
+	for _, desc := range s.txpool.PendingByAddress(in.Address) {
+		r.Txs = append(r.Txs, s.pendingTxPb(desc))
+	}
+	return r, nil
+}
+
+// GetTxPackage returns the package of a transaction pending in the txpool along with its unmined ancestors, which a
+// miner has to include before it, or along with its descendants if requested, which are evicted along with it
+// The fee rate of the package is the one a child has to raise to get its parents mined.
+func (s *Server) GetTxPackage(ctx context.Context, in *pb.GetTxPackageRequest) (*pb.TxPackageReply, error) {
+	if err := s.checkTxPool(ctx); err != nil {
+		return nil, err
+	}
+	if len(in.Hash) != len(cp.ZeroHash32B) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "hash length = %d", len(in.Hash))
+	}
+	var hash cp.Hash32B
+	copy(hash[:], in.Hash)
+	var pkg *txpool.TxPackage
+	var err error
+	if in.Descendants {
+		pkg, err = s.txpool.DescendantPackage(hash)
+	} else {
+		pkg, err = s.txpool.AncestorPackage(hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.txPackageReply(pkg), nil
+}
+
+// GetTxConflicts returns the package of the transactions pending in the txpool which spend the same UTXO as the signed
+// serialized transaction, along with their descendants, which the transaction has to outbid to replace them
+func (s *Server) GetTxConflicts(ctx context.Context, in *pb.GetTxConflictsRequest) (*pb.TxPackageReply, error) {
+	if err := s.checkTxPool(ctx); err != nil {
+		return nil, err
+	}
+	if len(in.SerializedTx) == 0 {
+		return nil, errors.Wrap(ErrInvalidRequest, "empty transaction")
+	}
+	txPb := &pb.TxPb{}
+	if err := proto.Unmarshal(in.SerializedTx, txPb); err != nil {
+		return nil, errors.Wrap(ErrInvalidRequest, err.Error())
+	}
+	tx := blockchain.Tx{}
+	tx.ConvertFromTxPb(txPb)
+	return s.txPackageReply(s.txpool.Conflicts(&tx)), nil
+}
+
+// checkTxPool returns error unless the request is served by the main chain, whose transactions the txpool holds
+func (s *Server) checkTxPool(ctx context.Context) error {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return err
+	}
+	if bc != s.blockchain {
+		return errors.Wrap(ErrInvalidRequest, "pending transactions are only held for the main chain")
+	}
+	if s.txpool == nil {
+		return status.Error(codes.Unavailable, "txpool is not available")
+	}
+	return nil
+}
+
+// pendingTxPb converts the desc of a pending transaction, listing the pending transactions whose outputs it spends
+func (s *Server) pendingTxPb(desc *txpool.TxDesc) *pb.PendingTxPb {
+	hash := desc.Tx.Hash()
+	r := &pb.PendingTxPb{Hash: hash[:], Fee: uint64(desc.Fee), AddedTime: desc.AddedTime.Unix()}
+	if serialize, err := desc.Tx.Serialize(); err == nil {
+		r.Size = uint32(len(serialize))
+	}
+	parents := make(map[cp.Hash32B]bool)
+	for _, txIn := range desc.Tx.TxIn {
+		parent := txpool.NewTxSourcePointer(txIn).Hash
+		if parents[parent] {
+			continue
+		}
+		parents[parent] = true
+		if _, err := s.txpool.FetchTx(&parent); err == nil {
+			r.Parents = append(r.Parents, parent[:])
+		}
+	}
+	return r
+}
+
+// txPackageReply converts the package of pending transactions
+func (s *Server) txPackageReply(pkg *txpool.TxPackage) *pb.TxPackageReply {
+	r := &pb.TxPackageReply{Fee: uint64(pkg.Fee), Size: pkg.Size, FeeRate: pkg.FeeRate()}
+	for _, desc := range pkg.Txs {
+		r.Txs = append(r.Txs, s.pendingTxPb(desc))
+	}
+	return r
+}
+
+// CreateRawTransaction creates a serialized transaction paying amount from one address to another, whose inputs are
+// left unsigned for the holder of the key of the sender to sign, see blockchain.Tx.Sign
+func (s *Server) CreateRawTransaction(ctx context.Context, in *pb.CreateRawTransactionRequest) (*pb.CreateRawTransactionReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !iotxaddress.ValidateAddress(in.From) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "from = %s", in.From)
+	}
+	if !iotxaddress.ValidateAddress(in.To) {
+		return nil, errors.Wrapf(ErrInvalidRequest, "to = %s", in.To)
+	}
+	if in.Amount == 0 {
+		return nil, errors.Wrap(ErrInvalidRequest, "zero amount")
+	}
+	payees := []*blockchain.Payee{{Address: in.To, Amount: in.Amount}}
+	tx, err := bc.CreateRawTransaction(iotxaddress.Address{Address: in.From}, in.Amount, payees)
+	if err != nil {
+		return nil, err
+	}
+	stx, err := proto.Marshal(tx.ConvertToTxPb())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateRawTransactionReply{SerializedTx: stx}, nil
+}
+
+// SendRawTransaction broadcasts a signed serialized transaction and hands it to the local txpool
+// With the txpool set, the transaction is only broadcast once accepted into the pool, and the status code and message of
+// the returned error tell why it is rejected.
+func (s *Server) SendRawTransaction(ctx context.Context, in *pb.SendRawTransactionRequest) (*pb.SendRawTransactionReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the transactions are only relayed and pooled for the main chain
+	if bc != s.blockchain {
+		return nil, errors.Wrap(ErrInvalidRequest, "transactions can only be sent to the main chain")
+	}
+	if len(in.SerializedTx) == 0 {
+		return nil, errors.Wrap(ErrInvalidRequest, "empty transaction")
+	}
+
+	txPb := &pb.TxPb{}
+	if err := proto.Unmarshal(in.SerializedTx, txPb); err != nil {
+		return nil, errors.Wrap(ErrInvalidRequest, err.Error())
+	}
+	tx := blockchain.Tx{}
+	tx.ConvertFromTxPb(txPb)
+	hash := tx.Hash()
+	if s.txpool != nil {
+		// only the transactions accepted into the pool are broadcast
+		if _, err := s.txpool.AcceptTransaction(&tx); err != nil {
+			return nil, status.Error(rejectCode(err), err.Error())
+		}
+		if err := s.broadcastcb(txPb); err != nil {
+			return nil, err
+		}
+		return &pb.SendRawTransactionReply{TxHash: hash[:]}, nil
+	}
+	// broadcast to the network
+	if err := s.broadcastcb(txPb); err != nil {
+		return nil, err
+	}
+	// send to txpool via dispatcher
+	s.dispatcher.HandleBroadcast(nil, txPb, nil)
+	return &pb.SendRawTransactionReply{TxHash: hash[:]}, nil
+}
+
+// ValidateTransaction runs the checks of accepting a signed serialized transaction into the txpool without adding it
+// anywhere, and returns the verdict along with the fee the transaction pays and the one estimated to get it mined within
+// the target blocks
+// Unless the transaction is valid, the code and reason of the verdict tell why it would be rejected like the error of
+// SendRawTransaction, and the inputs left unsigned or spending unknown outputs are listed.
+func (s *Server) ValidateTransaction(ctx context.Context, in *pb.ValidateTransactionRequest) (*pb.ValidateTransactionReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if bc != s.blockchain {
+		return nil, errors.Wrap(ErrInvalidRequest, "transactions can only be validated on the main chain")
+	}
+	if s.txpool == nil {
+		return nil, status.Error(codes.Unavailable, "txpool is not available")
+	}
+	if len(in.SerializedTx) == 0 {
+		return nil, errors.Wrap(ErrInvalidRequest, "empty transaction")
+	}
+	target := in.TargetBlocks
+	if target == 0 {
+		target = DefaultFeeTargetBlocks
+	}
+
+	txPb := &pb.TxPb{}
+	if err := proto.Unmarshal(in.SerializedTx, txPb); err != nil {
+		return nil, errors.Wrap(ErrInvalidRequest, err.Error())
+	}
+	tx := blockchain.Tx{}
+	tx.ConvertFromTxPb(txPb)
+	hash := tx.Hash()
+	rate, err := bc.EstimateFee(target)
+	if err != nil {
+		return nil, err
+	}
+	v := s.txpool.ValidateTransaction(&tx)
+	r := &pb.ValidateTransactionReply{
+		TxHash:       hash[:],
+		Valid:        v.Err == nil,
+		Code:         uint32(codes.OK),
+		Size:         v.Size,
+		Fee:          uint64(v.Fee),
+		MinFee:       uint64(v.MinFee),
+		EstimatedFee: rate * uint64(v.Size),
+	}
+	if v.Err != nil {
+		r.Code = uint32(rejectCode(v.Err))
+		r.Reason = v.Err.Error()
+	}
+	for i := range v.MissingInputs {
+		r.MissingInputs = append(r.MissingInputs, v.MissingInputs[i][:])
+	}
+	for _, index := range v.UnsignedInputs {
+		r.UnsignedInputs = append(r.UnsignedInputs, uint32(index))
+	}
+	return r, nil
+}
+
+// rejectCode returns the status code telling why the txpool rejects a transaction
+func rejectCode(err error) codes.Code {
+	switch errors.Cause(err) {
+	case txpool.ErrInvalidTx:
+		return codes.InvalidArgument
+	case txpool.ErrDuplicateTx:
+		return codes.AlreadyExists
+	case txpool.ErrPoolStopped:
+		return codes.Unavailable
+	default:
+		return codes.FailedPrecondition
+	}
+}
+
+// GetTipInfo returns the height and hash of the tip block
+func (s *Server) GetTipInfo(ctx context.Context, in *pb.GetTipInfoRequest) (*pb.GetTipInfoReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hash := bc.TipHash()
+	return &pb.GetTipInfoReply{Height: bc.TipHeight(), Hash: hash[:]}, nil
+}
+
+// GetPeers returns the peers connected to or banned by the node along with their misbehavior scores
+func (s *Server) GetPeers(ctx context.Context, in *pb.GetPeersRequest) (*pb.GetPeersReply, error) {
+	r := &pb.GetPeersReply{}
+	if s.peers == nil {
+		return r, nil
+	}
+	for _, info := range s.peers.PeerInfos() {
+		peer := &pb.PeerInfoPb{Addr: info.Addr, Connected: info.Connected, Score: uint32(info.Score)}
+		if !info.LastResTime.IsZero() {
+			peer.LastResTime = info.LastResTime.Unix()
+		}
+		if !info.BannedUntil.IsZero() {
+			peer.BannedUntil = info.BannedUntil.Unix()
+		}
+		r.Peers = append(r.Peers, peer)
+	}
+	return r, nil
+}
+
+// GetChainMeta returns the statistics of the chain as of the tip
+func (s *Server) GetChainMeta(ctx context.Context, in *pb.GetChainMetaRequest) (*pb.GetChainMetaReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meta := bc.ChainMeta()
+	return &pb.GetChainMetaReply{
+		Height:               meta.Height,
+		TotalTxs:             meta.TotalTxs,
+		RecentTxCounts:       meta.RecentTxCounts,
+		AverageBlockInterval: int64(meta.AverageBlockInterval / time.Millisecond),
+		CirculatingSupply:    meta.CirculatingSupply,
+		NumHolders:           meta.NumHolders,
+	}, nil
+}
+
+// GetTopHolders returns up to limit of the holders with the most balance, the richest first, all of the holders kept
+// by the chain statistics if limit is 0
+func (s *Server) GetTopHolders(ctx context.Context, in *pb.GetTopHoldersRequest) (*pb.GetTopHoldersReply, error) {
+	bc, err := s.chain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit := in.Limit
+	if limit == 0 || limit > blockchain.MaxTopHolders {
+		limit = blockchain.MaxTopHolders
+	}
+	r := &pb.GetTopHoldersReply{}
+	for _, holder := range bc.TopHolders(limit) {
+		r.Holders = append(r.Holders, &pb.HolderPb{PubKeyHash: holder.PubKeyHash, Balance: holder.Balance})
+	}
+	return r, nil
+}
+
+// SubscribeBlocks streams block events to the client until the client goes away
+func (s *Server) SubscribeBlocks(in *pb.SubscribeBlocksRequest, stream pb.ApiService_SubscribeBlocksServer) error {
+	bc, err := s.chain(stream.Context())
+	if err != nil {
+		return err
+	}
+	ch := bc.Subscribe()
+	defer bc.Unsubscribe(ch)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case evt, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(convertToBlockEventPb(evt)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func convertToBlockEventPb(evt *blockchain.BlockEvent) *pb.BlockEventPb {
+	hash := evt.Block.HashBlock()
+	evtPb := &pb.BlockEventPb{
+		Type:   pb.BlockEventPb_BLOCK_COMMITTED,
+		Block:  evt.Block.ConvertToBlockPb(),
+		Hash:   hash[:],
+		Height: evt.Block.Height(),
+		OldTip: evt.OldTip[:],
+	}
+	if evt.Type == blockchain.ChainReorged {
+		evtPb.Type = pb.BlockEventPb_CHAIN_REORGED
+	}
+	for _, tx := range evt.Block.Tranxs {
+		txHash := tx.Hash()
+		evtPb.TxHashes = append(evtPb.TxHashes, txHash[:])
+	}
+	return evtPb
+}
+
+// Start starts the API server, along with its JSON-RPC gateway if configured
+func (s *Server) Start() error {
+	if s.config.Addr == "" && s.config.JSONRPCAddr == "" {
+		log.Warning("API service is not configured")
+		return nil
+	}
+
+	var tlsConfig *tls.Config
+	if s.config.TLSEnabled {
+		cert, err := tls.LoadX509KeyPair(s.config.CertPath, s.config.KeyPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to load API server certificate")
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	if s.config.Addr != "" {
+		opts := []grpc.ServerOption{
+			grpc.MaxRecvMsgSize(int(s.maxRequestSize())),
+			grpc.UnaryInterceptor(s.unaryInterceptor),
+			grpc.StreamInterceptor(s.streamInterceptor),
+		}
+		if tlsConfig != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+
+		lis, err := net.Listen("tcp", s.config.Addr)
+		if err != nil {
+			return errors.Wrap(err, "API server failed to listen")
+		}
+		log.Infof("API server is listening on %v", lis.Addr().String())
+
+		s.grpcserver = grpc.NewServer(opts...)
+		pb.RegisterApiServiceServer(s.grpcserver, s)
+		reflection.Register(s.grpcserver)
+
+		go func() {
+			if err := s.grpcserver.Serve(lis); err != nil {
+				log.Errorf("API server failed to serve: %v", err)
+			}
+		}()
+	}
+
+	if s.config.JSONRPCAddr != "" {
+		return s.startJSONRPC(tlsConfig)
+	}
+	return nil
+}
+
+// Stop stops the API server and its JSON-RPC gateway
+func (s *Server) Stop() error {
+	if s.grpcserver != nil {
+		s.grpcserver.Stop()
+	}
+	if s.httpserver != nil {
+		return s.httpserver.Close()
+	}
+	return nil
+}
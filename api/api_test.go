@@ -0,0 +1,647 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/network"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_dispatcher"
+	"github.com/iotexproject/iotex-core/test/mock/mock_txpool"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/txpool"
+)
+
+func testingBlocks() []*blockchain.Block {
+	cbtx0 := blockchain.NewCoinbaseTx(ta.Addrinfo["miner"].Address, 100, "genesis")
+	blk0 := blockchain.NewBlock(0, 0, cp.ZeroHash32B, []*blockchain.Tx{cbtx0})
+	cbtx1 := blockchain.NewCoinbaseTx(ta.Addrinfo["alfa"].Address, 5, "")
+	blk1 := blockchain.NewBlock(0, 1, blk0.HashBlock(), []*blockchain.Tx{cbtx1})
+	return []*blockchain.Block{blk0, blk1}
+}
+
+func TestGetBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	blks := testingBlocks()
+	hash := blks[1].HashBlock()
+	mbc.EXPECT().GetBlockByHeight(uint32(1)).Return(blks[1], nil).Times(1)
+	r, err := s.GetBlockByHeight(context.Background(), &pb.GetBlockByHeightRequest{Height: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, hash[:], r.Hash)
+	assert.Equal(t, uint32(1), r.Block.Header.Height)
+
+	mbc.EXPECT().GetHeightByHash(hash).Return(uint32(1), nil).Times(1)
+	mbc.EXPECT().GetBlockByHash(hash).Return(blks[1], nil).Times(1)
+	r, err = s.GetBlockByHash(context.Background(), &pb.GetBlockByHashRequest{Hash: hash[:]})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), r.Height)
+
+	_, err = s.GetBlockByHash(context.Background(), &pb.GetBlockByHashRequest{Hash: []byte{1, 2, 3}})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
+
+func TestGetBlockMeta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	hash := testingBlocks()[1].HashBlock()
+	meta := &blockchain.BlockMeta{Hash: hash, Height: 1, Timestamp: 100, TxCount: 1, Producer: []byte{1}, Size: 200}
+	meta.Coinbase = &blockchain.CoinbasePayload{Producer: "io1producer", Epoch: 0, Height: 1, ClientVersion: "v1"}
+	mbc.EXPECT().GetBlockMetaByHeight(uint32(1)).Return(meta, nil).Times(1)
+	r, err := s.GetBlockMetaByHeight(context.Background(), &pb.GetBlockMetaByHeightRequest{Height: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, hash[:], r.Meta.Hash)
+	assert.Equal(t, uint32(1), r.Meta.TxCount)
+	assert.Equal(t, uint32(200), r.Meta.Size)
+	assert.Equal(t, "io1producer", r.Meta.CoinbasePayload.Producer)
+	assert.Equal(t, "v1", r.Meta.CoinbasePayload.ClientVersion)
+
+	mbc.EXPECT().GetBlockMetaByHash(hash).Return(meta, nil).Times(1)
+	r, err = s.GetBlockMetaByHash(context.Background(), &pb.GetBlockMetaByHashRequest{Hash: hash[:]})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), r.Meta.Height)
+	assert.Equal(t, uint64(100), r.Meta.Timestamp)
+
+	_, err = s.GetBlockMetaByHash(context.Background(), &pb.GetBlockMetaByHashRequest{Hash: []byte{1, 2, 3}})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
+
+func TestGetFinality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	hash := testingBlocks()[1].HashBlock()
+	mbc.EXPECT().FinalizedHeight().Return(uint32(1)).Times(2)
+	mbc.EXPECT().GetHashByHeight(uint32(1)).Return(hash, nil).Times(2)
+	r, err := s.GetFinality(context.Background(), &pb.GetFinalityRequest{Height: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), r.FinalizedHeight)
+	assert.Equal(t, hash[:], r.FinalizedHash)
+	assert.True(t, r.Final)
+	r, err = s.GetFinality(context.Background(), &pb.GetFinalityRequest{Height: 2})
+	assert.Nil(t, err)
+	assert.False(t, r.Final)
+}
+
+func TestGetBlocksByRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	blks := testingBlocks()
+	mbc.EXPECT().GetBlocksByRange(gomock.Any(), uint32(0), uint32(1)).Return(blks, nil).Times(1)
+	r, err := s.GetBlocksByRange(context.Background(), &pb.GetBlocksByRangeRequest{Start: 0, End: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(r.Blocks))
+	for i, blk := range blks {
+		hash := blk.HashBlock()
+		assert.Equal(t, hash[:], r.Blocks[i].Hash)
+		assert.Equal(t, uint32(i), r.Blocks[i].Height)
+	}
+
+	_, err = s.GetBlocksByRange(context.Background(), &pb.GetBlocksByRangeRequest{Start: 2, End: 1})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+	_, err = s.GetBlocksByRange(context.Background(), &pb.GetBlocksByRangeRequest{Start: 0, End: MaxBlocksPerRange})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
+
+func TestGetTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	blks := testingBlocks()
+	mbc.EXPECT().TipHeight().Return(uint32(1)).AnyTimes()
+	mbc.EXPECT().GetBlockByHeight(uint32(1)).Return(blks[1], nil).AnyTimes()
+	mbc.EXPECT().GetBlockByHeight(uint32(0)).Return(blks[0], nil).AnyTimes()
+
+	txHash := blks[0].Tranxs[0].Hash()
+	r, err := s.GetTransaction(context.Background(), &pb.GetTransactionRequest{Hash: txHash[:]})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), r.BlockHeight)
+	blkHash := blks[0].HashBlock()
+	assert.Equal(t, blkHash[:], r.BlockHash)
+
+	_, err = s.GetTransaction(context.Background(), &pb.GetTransactionRequest{Hash: cp.ZeroHash32B[:]})
+	assert.Equal(t, ErrTxNotFound, errors.Cause(err))
+}
+
+func TestGetBalanceAndTipInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	mbc.EXPECT().BalanceOf(ta.Addrinfo["alfa"].Address, uint32(0)).Return(uint64(42)).Times(1)
+	r, err := s.GetBalance(context.Background(), &pb.GetBalanceRequest{Address: ta.Addrinfo["alfa"].Address})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(42), r.Balance)
+
+	_, err = s.GetBalance(context.Background(), &pb.GetBalanceRequest{Address: "Alice"})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+
+	hash := testingBlocks()[1].HashBlock()
+	mbc.EXPECT().TipHash().Return(hash).Times(1)
+	mbc.EXPECT().TipHeight().Return(uint32(1)).Times(1)
+	tip, err := s.GetTipInfo(context.Background(), &pb.GetTipInfoRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), tip.Height)
+	assert.Equal(t, hash[:], tip.Hash)
+}
+
+func TestGetPendingNonce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	alfa := ta.Addrinfo["alfa"].Address
+	mbc.EXPECT().AccountState(alfa).Return(&state.Account{Nonce: 3}).Times(2)
+	r, err := s.GetPendingNonce(context.Background(), &pb.GetPendingNonceRequest{Address: alfa})
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.GetPendingNonceReply{ConfirmedNonce: 3, PendingNonce: 3}, r)
+
+	// the pending nonce counts the actions in the txpool
+	mtp := mock_txpool.NewMockTxPool(ctrl)
+	s.SetTxPool(mtp)
+	mtp.EXPECT().PendingNonce(alfa).Return(uint64(5)).Times(1)
+	r, err = s.GetPendingNonce(context.Background(), &pb.GetPendingNonceRequest{Address: alfa})
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.GetPendingNonceReply{ConfirmedNonce: 3, PendingNonce: 5}, r)
+
+	_, err = s.GetPendingNonce(context.Background(), &pb.GetPendingNonceRequest{Address: "Alice"})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
+
+func TestGetPendingTxs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	alfa := ta.Addrinfo["alfa"].Address
+	tx := testingBlocks()[1].Tranxs[0]
+	stx, err := tx.Serialize()
+	assert.Nil(t, err)
+	hash := tx.Hash()
+	desc := &txpool.TxDesc{Tx: tx, Fee: 10, AddedTime: time.Unix(100, 0)}
+	pending := &pb.PendingTxPb{Hash: hash[:], Fee: 10, Size: uint32(len(stx)), AddedTime: 100}
+
+	// the pending transactions are queried from the txpool
+	_, err = s.GetPendingTxs(context.Background(), &pb.GetPendingTxsRequest{Address: alfa})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	mtp := mock_txpool.NewMockTxPool(ctrl)
+	s.SetTxPool(mtp)
+	_, err = s.GetPendingTxs(context.Background(), &pb.GetPendingTxsRequest{Address: "Alice"})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+
+	// the parents are the inputs spending the outputs of the pending transactions
+	mtp.EXPECT().FetchTx(gomock.Any()).Return(nil, errors.New("cannot find transaction in the pool")).AnyTimes()
+	mtp.EXPECT().PendingByAddress(alfa).Return([]*txpool.TxDesc{desc}).Times(1)
+	r, err := s.GetPendingTxs(context.Background(), &pb.GetPendingTxsRequest{Address: alfa})
+	assert.Nil(t, err)
+	assert.Equal(t, []*pb.PendingTxPb{pending}, r.Txs)
+
+	pkg := &txpool.TxPackage{Txs: []*txpool.TxDesc{desc}, Fee: 10, Size: uint32(len(stx))}
+	mtp.EXPECT().AncestorPackage(hash).Return(pkg, nil).Times(1)
+	p, err := s.GetTxPackage(context.Background(), &pb.GetTxPackageRequest{Hash: hash[:]})
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.TxPackageReply{Txs: []*pb.PendingTxPb{pending}, Fee: 10, Size: uint32(len(stx)), FeeRate: pkg.FeeRate()}, p)
+	mtp.EXPECT().DescendantPackage(hash).Return(nil, errors.Wrap(txpool.ErrTxNotInPool, "hash")).Times(1)
+	_, err = s.GetTxPackage(context.Background(), &pb.GetTxPackageRequest{Hash: hash[:], Descendants: true})
+	assert.Equal(t, codes.NotFound, status.Code(grpcError(err)))
+	_, err = s.GetTxPackage(context.Background(), &pb.GetTxPackageRequest{Hash: hash[:4]})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+
+	mtp.EXPECT().Conflicts(gomock.Any()).Return(pkg).Times(1)
+	p, err = s.GetTxConflicts(context.Background(), &pb.GetTxConflictsRequest{SerializedTx: stx})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), p.Fee)
+	assert.Equal(t, 1, len(p.Txs))
+	_, err = s.GetTxConflicts(context.Background(), &pb.GetTxConflictsRequest{})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
+
+func TestCreateRawTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	from, to := ta.Addrinfo["miner"].Address, ta.Addrinfo["alfa"].Address
+	tx := testingBlocks()[1].Tranxs[0]
+	payees := []*blockchain.Payee{{Address: to, Amount: 10}}
+	mbc.EXPECT().CreateRawTransaction(iotxaddress.Address{Address: from}, uint64(10), payees).Return(tx, nil).Times(1)
+	r, err := s.CreateRawTransaction(context.Background(), &pb.CreateRawTransactionRequest{From: from, To: to, Amount: 10})
+	assert.Nil(t, err)
+	stx, err := tx.Serialize()
+	assert.Nil(t, err)
+	assert.Equal(t, stx, r.SerializedTx)
+
+	mbc.EXPECT().CreateRawTransaction(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, blockchain.ErrInsufficientFunds).Times(1)
+	_, err = s.CreateRawTransaction(context.Background(), &pb.CreateRawTransactionRequest{From: from, To: to, Amount: 10})
+	assert.Equal(t, blockchain.ErrInsufficientFunds, errors.Cause(err))
+
+	_, err = s.CreateRawTransaction(context.Background(), &pb.CreateRawTransactionRequest{From: "Alice", To: to, Amount: 10})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+	_, err = s.CreateRawTransaction(context.Background(), &pb.CreateRawTransactionRequest{From: from, To: to})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
+
+func TestSendRawTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+
+	cbinvoked := false
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error {
+		cbinvoked = true
+		return nil
+	})
+	assert.Nil(t, err)
+
+	tx := testingBlocks()[1].Tranxs[0]
+	stx, err := tx.Serialize()
+	assert.Nil(t, err)
+
+	mdp.EXPECT().HandleBroadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	r, err := s.SendRawTransaction(context.Background(), &pb.SendRawTransactionRequest{SerializedTx: stx})
+	assert.Nil(t, err)
+	hash := tx.Hash()
+	assert.Equal(t, hash[:], r.TxHash)
+	assert.True(t, cbinvoked)
+
+	_, err = s.SendRawTransaction(context.Background(), &pb.SendRawTransactionRequest{})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+
+	// the transaction is only broadcast once accepted by the txpool, which tells why it is rejected
+	mtp := mock_txpool.NewMockTxPool(ctrl)
+	s.SetTxPool(mtp)
+	cbinvoked = false
+	mtp.EXPECT().AcceptTransaction(gomock.Any()).Return(nil, errors.Wrap(txpool.ErrInsufficientFee, "fee 0")).Times(1)
+	_, err = s.SendRawTransaction(context.Background(), &pb.SendRawTransactionRequest{SerializedTx: stx})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.False(t, cbinvoked)
+	mtp.EXPECT().AcceptTransaction(gomock.Any()).Return(nil, errors.Wrap(txpool.ErrInvalidTx, "bad script")).Times(1)
+	_, err = s.SendRawTransaction(context.Background(), &pb.SendRawTransactionRequest{SerializedTx: stx})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mtp.EXPECT().AcceptTransaction(gomock.Any()).Return(&txpool.TxDesc{Tx: tx}, nil).Times(1)
+	r, err = s.SendRawTransaction(context.Background(), &pb.SendRawTransactionRequest{SerializedTx: stx})
+	assert.Nil(t, err)
+	assert.Equal(t, hash[:], r.TxHash)
+	assert.True(t, cbinvoked)
+}
+
+func TestValidateTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+
+	cbinvoked := false
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error {
+		cbinvoked = true
+		return nil
+	})
+	assert.Nil(t, err)
+
+	tx := testingBlocks()[1].Tranxs[0]
+	stx, err := tx.Serialize()
+	assert.Nil(t, err)
+	hash := tx.Hash()
+
+	// the transactions are validated against the txpool
+	_, err = s.ValidateTransaction(context.Background(), &pb.ValidateTransactionRequest{SerializedTx: stx})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	mtp := mock_txpool.NewMockTxPool(ctrl)
+	s.SetTxPool(mtp)
+	_, err = s.ValidateTransaction(context.Background(), &pb.ValidateTransactionRequest{})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+
+	mbc.EXPECT().EstimateFee(uint32(DefaultFeeTargetBlocks)).Return(uint64(2), nil).Times(1)
+	mtp.EXPECT().ValidateTransaction(gomock.Any()).Return(&txpool.TxVerdict{
+		Err:            errors.Wrap(txpool.ErrInvalidTx, "bad script"),
+		Size:           100,
+		Fee:            10,
+		MinFee:         100,
+		MissingInputs:  []cp.Hash32B{hash},
+		UnsignedInputs: []int{0},
+	}).Times(1)
+	r, err := s.ValidateTransaction(context.Background(), &pb.ValidateTransactionRequest{SerializedTx: stx})
+	assert.Nil(t, err)
+	assert.Equal(t, hash[:], r.TxHash)
+	assert.False(t, r.Valid)
+	assert.Equal(t, uint32(codes.InvalidArgument), r.Code)
+	assert.Equal(t, "bad script: invalid transaction", r.Reason)
+	assert.Equal(t, uint64(10), r.Fee)
+	assert.Equal(t, uint64(100), r.MinFee)
+	assert.Equal(t, uint64(200), r.EstimatedFee)
+	assert.Equal(t, [][]byte{hash[:]}, r.MissingInputs)
+	assert.Equal(t, []uint32{0}, r.UnsignedInputs)
+
+	mbc.EXPECT().EstimateFee(uint32(1)).Return(uint64(1), nil).Times(1)
+	mtp.EXPECT().ValidateTransaction(gomock.Any()).Return(&txpool.TxVerdict{Size: 100, Fee: 100, MinFee: 100}).Times(1)
+	r, err = s.ValidateTransaction(context.Background(), &pb.ValidateTransactionRequest{SerializedTx: stx, TargetBlocks: 1})
+	assert.Nil(t, err)
+	assert.True(t, r.Valid)
+	assert.Equal(t, uint32(codes.OK), r.Code)
+	assert.Equal(t, uint64(100), r.EstimatedFee)
+	// nothing is broadcast
+	assert.False(t, cbinvoked)
+}
+
+type fakeSubscribeStream struct {
+	pb.ApiService_SubscribeBlocksServer
+	ctx    context.Context
+	events []*pb.BlockEventPb
+}
+
+func (s *fakeSubscribeStream) Context() context.Context { return s.ctx }
+
+func (s *fakeSubscribeStream) Send(evt *pb.BlockEventPb) error {
+	s.events = append(s.events, evt)
+	return nil
+}
+
+func TestSubscribeBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	blks := testingBlocks()
+	ch := make(chan *blockchain.BlockEvent, 2)
+	ch <- &blockchain.BlockEvent{Type: blockchain.BlockCommitted, Block: blks[0]}
+	ch <- &blockchain.BlockEvent{Type: blockchain.ChainReorged, Block: blks[1], OldTip: cp.ZeroHash32B}
+	close(ch)
+	mbc.EXPECT().Subscribe().Return((<-chan *blockchain.BlockEvent)(ch)).Times(1)
+	mbc.EXPECT().Unsubscribe(gomock.Any()).Times(1)
+
+	stream := &fakeSubscribeStream{ctx: context.Background()}
+	assert.Nil(t, s.SubscribeBlocks(&pb.SubscribeBlocksRequest{}, stream))
+	assert.Equal(t, 2, len(stream.events))
+	assert.Equal(t, pb.BlockEventPb_BLOCK_COMMITTED, stream.events[0].Type)
+	assert.Equal(t, pb.BlockEventPb_CHAIN_REORGED, stream.events[1].Type)
+	assert.Equal(t, uint32(1), stream.events[1].Height)
+	txHash := blks[1].Tranxs[0].Hash()
+	assert.Equal(t, [][]byte{txHash[:]}, stream.events[1].TxHashes)
+}
+
+func TestGetMerkleProof(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	cbtx := blockchain.NewCoinbaseTx(ta.Addrinfo["miner"].Address, 5, "")
+	tx0 := blockchain.NewCoinbaseTx(ta.Addrinfo["alfa"].Address, 1, "")
+	tx1 := blockchain.NewCoinbaseTx(ta.Addrinfo["bravo"].Address, 2, "")
+	blk := blockchain.NewBlock(0, 1, cp.ZeroHash32B, []*blockchain.Tx{cbtx, tx0, tx1})
+	mbc.EXPECT().TipHeight().Return(uint32(1)).AnyTimes()
+	mbc.EXPECT().GetBlockByHeight(uint32(1)).Return(blk, nil).AnyTimes()
+	mbc.EXPECT().GetBlockByHeight(uint32(0)).Return(testingBlocks()[0], nil).AnyTimes()
+
+	txHash := tx1.Hash()
+	r, err := s.GetMerkleProof(context.Background(), &pb.GetMerkleProofRequest{TxHash: txHash[:]})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), r.BlockHeight)
+	assert.Equal(t, uint32(2), r.Index)
+	assert.Equal(t, 2, len(r.Siblings))
+
+	// the proof verifies against the returned header alone
+	header := blockchain.Block{}
+	header.ConvertFromBlockHeaderPb(&pb.BlockPb{Header: r.Header})
+	proof := &cp.MerkleProof{Index: r.Index}
+	for _, sibling := range r.Siblings {
+		var hash cp.Hash32B
+		copy(hash[:], sibling)
+		proof.Siblings = append(proof.Siblings, hash)
+	}
+	assert.True(t, blockchain.VerifyMerkleProof(txHash, proof, &header))
+
+	_, err = s.GetMerkleProof(context.Background(), &pb.GetMerkleProofRequest{TxHash: cp.ZeroHash32B[:]})
+	assert.Equal(t, ErrTxNotFound, errors.Cause(err))
+	_, err = s.GetMerkleProof(context.Background(), &pb.GetMerkleProofRequest{TxHash: []byte{1, 2, 3}})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
+
+func TestGetReceiptAndLogs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	hash := cp.Hash32B{1, 2, 3}
+	l := &blockchain.Log{Address: "counter", Topics: [][]byte{[]byte("count")}, Data: []byte{5}, Hash: hash, BlockHeight: 3}
+	receipt := &blockchain.Receipt{Hash: hash, Status: blockchain.ReceiptSuccess, GasUsed: 1200, BlockHeight: 3, Logs: []*blockchain.Log{l}}
+	mbc.EXPECT().GetReceipt(hash).Return(receipt, nil).Times(1)
+	r, err := s.GetReceiptByTxHash(context.Background(), &pb.GetReceiptByTxHashRequest{Hash: hash[:]})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(blockchain.ReceiptSuccess), r.Receipt.Status)
+	assert.Equal(t, uint32(3), r.Receipt.BlockHeight)
+	assert.Equal(t, 1, len(r.Receipt.Logs))
+	_, err = s.GetReceiptByTxHash(context.Background(), &pb.GetReceiptByTxHashRequest{Hash: []byte{1, 2, 3}})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+
+	filter := &blockchain.LogFilter{Addresses: []string{"counter"}, Topics: [][]byte{[]byte("count")}}
+	mbc.EXPECT().GetLogs(gomock.Any(), uint32(0), uint32(3), filter).Return([]*blockchain.Log{l}, nil).Times(1)
+	logs, err := s.GetLogs(context.Background(), &pb.GetLogsRequest{
+		FromHeight: 0,
+		ToHeight:   3,
+		Addresses:  []string{"counter"},
+		Topics:     [][]byte{[]byte("count")},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []*pb.LogPb{l.ConvertToLogPb()}, logs.Logs)
+
+	_, err = s.GetLogs(context.Background(), &pb.GetLogsRequest{FromHeight: 0, ToHeight: MaxBlocksPerLogQuery})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
+
+type testPeerManager []network.PeerInfo
+
+func (pm testPeerManager) PeerInfos() []network.PeerInfo {
+	return pm
+}
+
+func TestGetPeers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	// no peer manager
+	r, err := s.GetPeers(context.Background(), &pb.GetPeersRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(r.Peers))
+
+	now := time.Now()
+	s.SetPeerManager(testPeerManager{
+		{Addr: "127.0.0.1:10001", Connected: true, Score: 4, LastResTime: now},
+		{Addr: "127.0.0.1:10002", Score: 100, BannedUntil: now.Add(time.Hour)},
+	})
+	r, err = s.GetPeers(context.Background(), &pb.GetPeersRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, []*pb.PeerInfoPb{
+		{Addr: "127.0.0.1:10001", Connected: true, Score: 4, LastResTime: now.Unix()},
+		{Addr: "127.0.0.1:10002", Score: 100, BannedUntil: now.Add(time.Hour).Unix()},
+	}, r.Peers)
+}
+
+func TestGetChainMeta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	mbc.EXPECT().ChainMeta().Return(&blockchain.ChainMeta{
+		Height:               2,
+		TotalTxs:             5,
+		RecentTxCounts:       []uint32{1, 2, 2},
+		AverageBlockInterval: 1500 * time.Millisecond,
+		CirculatingSupply:    100,
+		NumHolders:           3,
+	})
+	r, err := s.GetChainMeta(context.Background(), &pb.GetChainMetaRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.GetChainMetaReply{
+		Height:               2,
+		TotalTxs:             5,
+		RecentTxCounts:       []uint32{1, 2, 2},
+		AverageBlockInterval: 1500,
+		CirculatingSupply:    100,
+		NumHolders:           3,
+	}, r)
+}
+
+func TestGetTopHolders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	holders := []*blockchain.Holder{{PubKeyHash: []byte{1}, Balance: 20}, {PubKeyHash: []byte{2}, Balance: 10}}
+	mbc.EXPECT().TopHolders(uint32(2)).Return(holders)
+	r, err := s.GetTopHolders(context.Background(), &pb.GetTopHoldersRequest{Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []*pb.HolderPb{{PubKeyHash: []byte{1}, Balance: 20}, {PubKeyHash: []byte{2}, Balance: 10}}, r.Holders)
+
+	// the limit defaults to and is capped at the holders kept by the chain statistics
+	mbc.EXPECT().TopHolders(uint32(blockchain.MaxTopHolders)).Return(holders).Times(2)
+	_, err = s.GetTopHolders(context.Background(), &pb.GetTopHoldersRequest{})
+	assert.Nil(t, err)
+	_, err = s.GetTopHolders(context.Background(), &pb.GetTopHoldersRequest{Limit: blockchain.MaxTopHolders + 1})
+	assert.Nil(t, err)
+}
+
+type testChainRouter map[uint32]blockchain.IBlockchain
+
+func (r testChainRouter) Chain(chainID uint32) blockchain.IBlockchain {
+	return r[chainID]
+}
+
+func TestChainRouting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	sub := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+	withChainID := func(id string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(ChainIDKey, id))
+	}
+
+	mbc.EXPECT().ChainID().Return(uint32(1)).AnyTimes()
+	mbc.EXPECT().TipHash().Return(cp.Hash32B{1}).Times(2)
+	mbc.EXPECT().TipHeight().Return(uint32(10)).Times(2)
+	sub.EXPECT().TipHash().Return(cp.Hash32B{2}).Times(1)
+	sub.EXPECT().TipHeight().Return(uint32(20)).Times(1)
+
+	// the main chain serves the requests without chain ID and those of its chain ID
+	r, err := s.GetTipInfo(context.Background(), &pb.GetTipInfoRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(10), r.Height)
+	r, err = s.GetTipInfo(withChainID("1"), &pb.GetTipInfoRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(10), r.Height)
+	_, err = s.GetTipInfo(withChainID("2"), &pb.GetTipInfoRequest{})
+	assert.Equal(t, ErrChainNotFound, errors.Cause(err))
+
+	s.SetChainRouter(testChainRouter{2: sub})
+	r, err = s.GetTipInfo(withChainID("2"), &pb.GetTipInfoRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(20), r.Height)
+	_, err = s.GetTipInfo(withChainID("3"), &pb.GetTipInfoRequest{})
+	assert.Equal(t, ErrChainNotFound, errors.Cause(err))
+	_, err = s.GetTipInfo(withChainID("main"), &pb.GetTipInfoRequest{})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+
+	// the transactions are only sent to the main chain
+	stx, err := testingBlocks()[1].Tranxs[0].Serialize()
+	assert.Nil(t, err)
+	_, err = s.SendRawTransaction(withChainID("2"), &pb.SendRawTransactionRequest{SerializedTx: stx})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
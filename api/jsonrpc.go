@@ -0,0 +1,604 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/iotexproject/iotex-core/proto"
+)
+
+const (
+	// DefaultMaxRequestSize is the max size of a request sent to the JSON-RPC gateway if the config does not set one
+	DefaultMaxRequestSize = 1 << 20
+	// WebSocketPath is the HTTP path the JSON-RPC gateway accepts WebSocket connections on, the other paths accept
+	// JSON-RPC requests POSTed over HTTP
+	WebSocketPath = "/ws"
+)
+
+// The error codes defined by JSON-RPC 2.0
+const (
+	jsonrpcParseError     = -32700
+	jsonrpcInvalidRequest = -32600
+	jsonrpcMethodNotFound = -32601
+	jsonrpcInvalidParams  = -32602
+	jsonrpcServerError    = -32000
+	// jsonrpcLimitExceeded is the code of the requests rejected by the rate limits
+	jsonrpcLimitExceeded = -32005
+)
+
+type jsonrpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonrpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+type jsonrpcNotification struct {
+	Version string             `json:"jsonrpc"`
+	Method  string             `json:"method"`
+	Params  subscriptionResult `json:"params"`
+}
+
+type subscriptionResult struct {
+	Subscription string          `json:"subscription"`
+	Result       json.RawMessage `json:"result"`
+}
+
+// jsonrpcMethod maps a JSON-RPC method to the gRPC method of the server, the params of the method are the request
+// message in the JSON mapping of protobuf
+type jsonrpcMethod struct {
+	newRequest func() proto.Message
+	call       func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error)
+}
+
+var jsonrpcMethods = map[string]jsonrpcMethod{
+	"getBlockByHeight": {
+		func() proto.Message { return &pb.GetBlockByHeightRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetBlockByHeight(ctx, in.(*pb.GetBlockByHeightRequest))
+		},
+	},
+	"getBlockByHash": {
+		func() proto.Message { return &pb.GetBlockByHashRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetBlockByHash(ctx, in.(*pb.GetBlockByHashRequest))
+		},
+	},
+	"getBlockMetaByHeight": {
+		func() proto.Message { return &pb.GetBlockMetaByHeightRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetBlockMetaByHeight(ctx, in.(*pb.GetBlockMetaByHeightRequest))
+		},
+	},
+	"getBlockMetaByHash": {
+		func() proto.Message { return &pb.GetBlockMetaByHashRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetBlockMetaByHash(ctx, in.(*pb.GetBlockMetaByHashRequest))
+		},
+	},
+	"getBlocksByRange": {
+		func() proto.Message { return &pb.GetBlocksByRangeRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetBlocksByRange(ctx, in.(*pb.GetBlocksByRangeRequest))
+		},
+	},
+	"getTransaction": {
+		func() proto.Message { return &pb.GetTransactionRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetTransaction(ctx, in.(*pb.GetTransactionRequest))
+		},
+	},
+	"getMerkleProof": {
+		func() proto.Message { return &pb.GetMerkleProofRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetMerkleProof(ctx, in.(*pb.GetMerkleProofRequest))
+		},
+	},
+	"getReceiptByTxHash": {
+		func() proto.Message { return &pb.GetReceiptByTxHashRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetReceiptByTxHash(ctx, in.(*pb.GetReceiptByTxHashRequest))
+		},
+	},
+	"getLogs": {
+		func() proto.Message { return &pb.GetLogsRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetLogs(ctx, in.(*pb.GetLogsRequest))
+		},
+	},
+	"getBalance": {
+		func() proto.Message { return &pb.GetBalanceRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetBalance(ctx, in.(*pb.GetBalanceRequest))
+		},
+	},
+	"getPendingNonce": {
+		func() proto.Message { return &pb.GetPendingNonceRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetPendingNonce(ctx, in.(*pb.GetPendingNonceRequest))
+		},
+	},
+	"getPendingTxs": {
+		func() proto.Message { return &pb.GetPendingTxsRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetPendingTxs(ctx, in.(*pb.GetPendingTxsRequest))
+		},
+	},
+	"getTxPackage": {
+		func() proto.Message { return &pb.GetTxPackageRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetTxPackage(ctx, in.(*pb.GetTxPackageRequest))
+		},
+	},
+	"getTxConflicts": {
+		func() proto.Message { return &pb.GetTxConflictsRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetTxConflicts(ctx, in.(*pb.GetTxConflictsRequest))
+		},
+	},
+	"getFinality": {
+		func() proto.Message { return &pb.GetFinalityRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetFinality(ctx, in.(*pb.GetFinalityRequest))
+		},
+	},
+	"createRawTransaction": {
+		func() proto.Message { return &pb.CreateRawTransactionRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.CreateRawTransaction(ctx, in.(*pb.CreateRawTransactionRequest))
+		},
+	},
+	"sendRawTransaction": {
+		func() proto.Message { return &pb.SendRawTransactionRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.SendRawTransaction(ctx, in.(*pb.SendRawTransactionRequest))
+		},
+	},
+	"validateTransaction": {
+		func() proto.Message { return &pb.ValidateTransactionRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.ValidateTransaction(ctx, in.(*pb.ValidateTransactionRequest))
+		},
+	},
+	"getTipInfo": {
+		func() proto.Message { return &pb.GetTipInfoRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetTipInfo(ctx, in.(*pb.GetTipInfoRequest))
+		},
+	},
+	"getPeers": {
+		func() proto.Message { return &pb.GetPeersRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetPeers(ctx, in.(*pb.GetPeersRequest))
+		},
+	},
+	"getChainMeta": {
+		func() proto.Message { return &pb.GetChainMetaRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetChainMeta(ctx, in.(*pb.GetChainMetaRequest))
+		},
+	},
+	"getTopHolders": {
+		func() proto.Message { return &pb.GetTopHoldersRequest{} },
+		func(ctx context.Context, s *Server, in proto.Message) (proto.Message, error) {
+			return s.GetTopHolders(ctx, in.(*pb.GetTopHoldersRequest))
+		},
+	},
+}
+
+// The methods only available over WebSocket, notifying the block events of a subscription as the "blockEvent" method
+// and the events of the txs of the watched addresses as the "paymentEvent" method
+const (
+	subscribeBlocksMethod = "subscribeBlocks"
+	watchAddressesMethod  = "watchAddresses"
+	unsubscribeMethod     = "unsubscribe"
+	blockEventMethod      = "blockEvent"
+	paymentEventMethod    = "paymentEvent"
+)
+
+var pbMarshaler = jsonpb.Marshaler{EmitDefaults: true}
+
+// JSONRPCHandler serves the JSON-RPC 2.0 gateway to the API, POSTed over HTTP or sent over a WebSocket connection on
+// WebSocketPath, along with the CORS preflight requests of the origins of the config
+func (s *Server) JSONRPCHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(WebSocketPath, websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			// the connections made by other than browsers carry no origin
+			if origin := r.Header.Get("Origin"); origin != "" && !s.allowedOrigin(origin) {
+				return errors.Errorf("origin %s is not allowed", origin)
+			}
+			return nil
+		},
+		Handler: s.serveWebSocket,
+	})
+	mux.HandleFunc("/", s.serveHTTP)
+	return mux
+}
+
+func (s *Server) allowedOrigin(origin string) bool {
+	for _, o := range s.config.CORSOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) maxRequestSize() int64 {
+	if s.config.MaxRequestSize > 0 {
+		return s.config.MaxRequestSize
+	}
+	return DefaultMaxRequestSize
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if !s.allowedOrigin(origin) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+ChainIDHeader)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodPost:
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.maxRequestSize()))
+	if err != nil {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	ctx := withChainID(context.WithValue(r.Context(), clientIPKey{}, hostOf(r.RemoteAddr)), r)
+	reply := s.handleJSONRPC(ctx, body, nil)
+	if reply == nil {
+		// only notifications are received
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(reply)
+}
+
+// handleJSONRPC handles a request or a batch of requests and returns the reply, which is nil if only notifications
+// are received, the subscriptions are only handled over WebSocket
+func (s *Server) handleJSONRPC(ctx context.Context, body []byte, ws *wsSession) []byte {
+	body = bytes.TrimSpace(body)
+	if !json.Valid(body) {
+		return mustMarshal(errorResponse(nil, jsonrpcParseError, "parse error"))
+	}
+	if len(body) == 0 || body[0] != '[' {
+		if r := s.callJSONRPC(ctx, body, ws); r != nil {
+			return mustMarshal(r)
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil || len(batch) == 0 {
+		return mustMarshal(errorResponse(nil, jsonrpcInvalidRequest, "invalid request"))
+	}
+	var replies []*jsonrpcResponse
+	for _, raw := range batch {
+		if r := s.callJSONRPC(ctx, raw, ws); r != nil {
+			replies = append(replies, r)
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	return mustMarshal(replies)
+}
+
+// callJSONRPC calls the method of the request and returns the response, which is nil if the request is a notification
+func (s *Server) callJSONRPC(ctx context.Context, raw json.RawMessage, ws *wsSession) *jsonrpcResponse {
+	var req jsonrpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil || req.Version != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, jsonrpcInvalidRequest, "invalid request")
+	}
+
+	var r *jsonrpcResponse
+	m, ok := jsonrpcMethods[req.Method]
+	// the rate limits are of the methods named as in the gRPC service
+	err := s.currentLimiter().allow(clientIP(ctx), strings.ToUpper(req.Method[:1])+req.Method[1:])
+	switch {
+	case err != nil:
+		r = errorResponse(req.ID, jsonrpcLimitExceeded, err.Error())
+	case ok:
+		r = s.callMethod(ctx, &req, m)
+	case ws != nil && (req.Method == subscribeBlocksMethod || req.Method == watchAddressesMethod || req.Method == unsubscribeMethod):
+		r = ws.callMethod(&req)
+	default:
+		r = errorResponse(req.ID, jsonrpcMethodNotFound, "method not found: "+req.Method)
+	}
+	if req.ID == nil {
+		return nil
+	}
+	return r
+}
+
+func (s *Server) callMethod(ctx context.Context, req *jsonrpcRequest, m jsonrpcMethod) *jsonrpcResponse {
+	in := m.newRequest()
+	if len(req.Params) > 0 && !bytes.Equal(req.Params, []byte("null")) {
+		if err := jsonpb.Unmarshal(bytes.NewReader(req.Params), in); err != nil {
+			return errorResponse(req.ID, jsonrpcInvalidParams, err.Error())
+		}
+	}
+	out, err := m.call(ctx, s, in)
+	if err != nil {
+		code, msg := jsonrpcServerError, err.Error()
+		if errors.Cause(err) == ErrInvalidRequest {
+			code = jsonrpcInvalidParams
+		} else if st, ok := status.FromError(err); ok {
+			msg = st.Message()
+		}
+		return errorResponse(req.ID, code, msg)
+	}
+	result, err := pbMarshaler.MarshalToString(out)
+	if err != nil {
+		return errorResponse(req.ID, jsonrpcServerError, err.Error())
+	}
+	return &jsonrpcResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(result)}
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *jsonrpcResponse {
+	return &jsonrpcResponse{Version: "2.0", ID: id, Error: &jsonrpcError{Code: code, Message: msg}}
+}
+
+func mustMarshal(v interface{}) []byte {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to marshal JSON-RPC reply"))
+	}
+	return buf
+}
+
+// wsSession is a WebSocket connection to the gateway along with the block subscriptions made over it
+type wsSession struct {
+	s       *Server
+	conn    *websocket.Conn
+	ctx     context.Context
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  uint64
+	subs    map[string]context.CancelFunc
+	// pending are the subscriptions to start once the reply to their requests is sent
+	pending []func()
+}
+
+// withChainID returns the context carrying the chain ID of the HTTP request as the metadata of a gRPC request, see
+// ChainIDKey
+func withChainID(ctx context.Context, r *http.Request) context.Context {
+	if id := r.Header.Get(ChainIDHeader); id != "" {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(ChainIDKey, id))
+	}
+	return ctx
+}
+
+func (s *Server) serveWebSocket(conn *websocket.Conn) {
+	ctx := withChainID(context.WithValue(context.Background(), clientIPKey{}, hostOf(conn.Request().RemoteAddr)), conn.Request())
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	conn.MaxPayloadBytes = int(s.maxRequestSize())
+	ws := &wsSession{s: s, conn: conn, ctx: ctx, subs: make(map[string]context.CancelFunc)}
+	// the subscriptions made by the last request are started even if the connection is gone, to unsubscribe them
+	defer ws.startPending()
+	for {
+		var msg []byte
+		if err := websocket.Message.Receive(conn, &msg); err != nil {
+			if err == websocket.ErrFrameTooLarge {
+				ws.send(mustMarshal(errorResponse(nil, jsonrpcInvalidRequest, "request too large")))
+				continue
+			}
+			return
+		}
+		if reply := s.handleJSONRPC(ctx, msg, ws); reply != nil {
+			if err := ws.send(reply); err != nil {
+				return
+			}
+		}
+		ws.startPending()
+	}
+}
+
+func (ws *wsSession) startPending() {
+	ws.mu.Lock()
+	pending := ws.pending
+	ws.pending = nil
+	ws.mu.Unlock()
+	for _, start := range pending {
+		start()
+	}
+}
+
+func (ws *wsSession) send(buf []byte) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	return websocket.Message.Send(ws.conn, string(buf))
+}
+
+func (ws *wsSession) callMethod(req *jsonrpcRequest) *jsonrpcResponse {
+	switch req.Method {
+	case subscribeBlocksMethod:
+		id, err := ws.subscribeBlocks()
+		if err != nil {
+			return errorResponse(req.ID, jsonrpcServerError, err.Error())
+		}
+		return &jsonrpcResponse{Version: "2.0", ID: req.ID, Result: mustMarshal(id)}
+	case watchAddressesMethod:
+		in := &pb.WatchAddressesRequest{}
+		if err := jsonpb.Unmarshal(bytes.NewReader(req.Params), in); err != nil {
+			return errorResponse(req.ID, jsonrpcInvalidParams, err.Error())
+		}
+		id, err := ws.watchAddresses(in)
+		if err != nil {
+			code := jsonrpcServerError
+			if errors.Cause(err) == ErrInvalidRequest {
+				code = jsonrpcInvalidParams
+			}
+			return errorResponse(req.ID, code, err.Error())
+		}
+		return &jsonrpcResponse{Version: "2.0", ID: req.ID, Result: mustMarshal(id)}
+	default:
+		var params struct {
+			Subscription string `json:"subscription"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return errorResponse(req.ID, jsonrpcInvalidParams, err.Error())
+		}
+		return &jsonrpcResponse{Version: "2.0", ID: req.ID, Result: mustMarshal(ws.unsubscribe(params.Subscription))}
+	}
+}
+
+// subscribeBlocks subscribes to the block events of the chain of the connection, which are notified once the reply to
+// the request is sent
+func (ws *wsSession) subscribeBlocks() (string, error) {
+	bc, err := ws.s.chain(ws.ctx)
+	if err != nil {
+		return "", err
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.nextID++
+	id := strconv.FormatUint(ws.nextID, 16)
+	ctx, cancel := context.WithCancel(ws.ctx)
+	ws.subs[id] = cancel
+	ch := bc.Subscribe()
+	ws.pending = append(ws.pending, func() {
+		go func() {
+			defer bc.Unsubscribe(ch)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case evt, ok := <-ch:
+					if !ok {
+						return
+					}
+					result, err := pbMarshaler.MarshalToString(convertToBlockEventPb(evt))
+					if err != nil {
+						log.Errorf("Failed to marshal block event: %v", err)
+						continue
+					}
+					n := jsonrpcNotification{
+						Version: "2.0",
+						Method:  blockEventMethod,
+						Params:  subscriptionResult{Subscription: id, Result: json.RawMessage(result)},
+					}
+					if err := ws.send(mustMarshal(n)); err != nil {
+						return
+					}
+				}
+			}
+		}()
+	})
+	return id, nil
+}
+
+// watchAddresses subscribes to the events of the txs of the addresses on the chain of the connection, see
+// WatchAddresses, which are notified once the reply to the request is sent
+func (ws *wsSession) watchAddresses(in *pb.WatchAddressesRequest) (string, error) {
+	bc, err := ws.s.chain(ws.ctx)
+	if err != nil {
+		return "", err
+	}
+	w, err := newAddressWatch(bc, in)
+	if err != nil {
+		return "", err
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.nextID++
+	id := strconv.FormatUint(ws.nextID, 16)
+	ctx, cancel := context.WithCancel(ws.ctx)
+	ws.subs[id] = cancel
+	ws.pending = append(ws.pending, func() {
+		go ws.s.watchAddresses(ctx, w, func(evt *pb.PaymentEventPb) error {
+			result, err := pbMarshaler.MarshalToString(evt)
+			if err != nil {
+				return err
+			}
+			n := jsonrpcNotification{
+				Version: "2.0",
+				Method:  paymentEventMethod,
+				Params:  subscriptionResult{Subscription: id, Result: json.RawMessage(result)},
+			}
+			return ws.send(mustMarshal(n))
+		})
+	})
+	return id, nil
+}
+
+// unsubscribe cancels the subscription and returns false if there is no such subscription
+func (ws *wsSession) unsubscribe(id string) bool {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	cancel, ok := ws.subs[id]
+	if ok {
+		cancel()
+		delete(ws.subs, id)
+	}
+	return ok
+}
+
+// startJSONRPC starts the JSON-RPC gateway, serving over TLS with the given config if it is not nil
+func (s *Server) startJSONRPC(tlsConfig *tls.Config) error {
+	lis, err := net.Listen("tcp", s.config.JSONRPCAddr)
+	if err != nil {
+		return errors.Wrap(err, "JSON-RPC gateway failed to listen")
+	}
+	if tlsConfig != nil {
+		lis = tls.NewListener(lis, tlsConfig)
+	}
+	log.Infof("JSON-RPC gateway is listening on %v", lis.Addr().String())
+
+	s.httpserver = &http.Server{Handler: s.JSONRPCHandler()}
+	go func() {
+		if err := s.httpserver.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Errorf("JSON-RPC gateway failed to serve: %v", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_dispatcher"
+)
+
+type testResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpcError   `json:"error"`
+}
+
+func post(t *testing.T, server *httptest.Server, body string) (int, string) {
+	resp, err := server.Client().Post(server.URL, "application/json", strings.NewReader(body))
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	reply, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	return resp.StatusCode, string(reply)
+}
+
+func TestJSONRPC(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{MaxRequestSize: 512}, mbc, mdp, func(proto.Message) error { return nil })
+	require.Nil(err)
+	server := httptest.NewServer(s.JSONRPCHandler())
+	defer server.Close()
+
+	hash := cp.Hash32B{1, 2, 3}
+	mbc.EXPECT().TipHash().Return(hash).AnyTimes()
+	mbc.EXPECT().TipHeight().Return(uint32(7)).AnyTimes()
+	code, body := post(t, server, `{"jsonrpc":"2.0","id":1,"method":"getTipInfo"}`)
+	assert.Equal(http.StatusOK, code)
+	var r testResponse
+	require.Nil(json.Unmarshal([]byte(body), &r))
+	assert.Nil(r.Error)
+	assert.Equal("1", string(r.ID))
+	var tip struct {
+		Height uint32 `json:"height"`
+		Hash   []byte `json:"hash"`
+	}
+	require.Nil(json.Unmarshal(r.Result, &tip))
+	assert.Equal(uint32(7), tip.Height)
+	assert.Equal(hash[:], tip.Hash)
+
+	// the notifications are not replied to
+	code, body = post(t, server, `[
+		{"jsonrpc":"2.0","id":"a","method":"getBalance","params":{"address":"invalid"}},
+		{"jsonrpc":"2.0","method":"getTipInfo"},
+		{"jsonrpc":"2.0","id":"b","method":"getBlock"},
+		{"jsonrpc":"2.0","id":"c","method":"getTipInfo","params":{"unknown":1}},
+		{"jsonrpc":"2.0","id":"d","method":"subscribeBlocks"},
+		{"id":"e","method":"getTipInfo"}
+	]`)
+	assert.Equal(http.StatusOK, code)
+	var batch []testResponse
+	require.Nil(json.Unmarshal([]byte(body), &batch))
+	require.Equal(5, len(batch))
+	for i, c := range []int{jsonrpcInvalidParams, jsonrpcMethodNotFound, jsonrpcInvalidParams, jsonrpcMethodNotFound, jsonrpcInvalidRequest} {
+		require.NotNil(batch[i].Error)
+		assert.Equal(c, batch[i].Error.Code)
+	}
+	assert.Equal(`"e"`, string(batch[4].ID))
+
+	code, _ = post(t, server, `{"jsonrpc":"2.0","method":"getTipInfo"}`)
+	assert.Equal(http.StatusNoContent, code)
+	_, body = post(t, server, `{"jsonrpc":"2.0",`)
+	require.Nil(json.Unmarshal([]byte(body), &r))
+	assert.Equal(jsonrpcParseError, r.Error.Code)
+	_, body = post(t, server, `[]`)
+	require.Nil(json.Unmarshal([]byte(body), &r))
+	assert.Equal(jsonrpcInvalidRequest, r.Error.Code)
+	code, _ = post(t, server, `{"jsonrpc":"2.0","id":1,"method":"getTipInfo","params":{"pad":"`+strings.Repeat("x", 512)+`"}}`)
+	assert.Equal(http.StatusRequestEntityTooLarge, code)
+
+	// the chain ID header routes the request to the chain
+	mbc.EXPECT().ChainID().Return(uint32(1)).AnyTimes()
+	for id, ok := range map[string]bool{"1": true, "2": false} {
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"getTipInfo"}`))
+		require.Nil(err)
+		req.Header.Set(ChainIDHeader, id)
+		resp, err := server.Client().Do(req)
+		require.Nil(err)
+		r = testResponse{}
+		require.Nil(json.NewDecoder(resp.Body).Decode(&r))
+		resp.Body.Close()
+		assert.Equal(ok, r.Error == nil)
+	}
+
+	resp, err := server.Client().Get(server.URL)
+	require.Nil(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestJSONRPCCORS(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	c := config.API{CORSOrigins: []string{"https://explorer.iotex.io"}}
+	s, err := NewServer(c, mbc, mdp, func(proto.Message) error { return nil })
+	require.Nil(err)
+	server := httptest.NewServer(s.JSONRPCHandler())
+	defer server.Close()
+
+	for origin, code := range map[string]int{
+		"https://explorer.iotex.io": http.StatusNoContent,
+		"https://evil.example.com":  http.StatusForbidden,
+	} {
+		req, err := http.NewRequest(http.MethodOptions, server.URL, nil)
+		require.Nil(err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		resp, err := server.Client().Do(req)
+		require.Nil(err)
+		resp.Body.Close()
+		assert.Equal(code, resp.StatusCode)
+		if code == http.StatusNoContent {
+			assert.Equal(origin, resp.Header.Get("Access-Control-Allow-Origin"))
+			assert.Contains(resp.Header.Get("Access-Control-Allow-Methods"), http.MethodPost)
+		}
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + WebSocketPath
+	_, err = websocket.Dial(wsURL, "", "https://evil.example.com")
+	assert.NotNil(err)
+}
+
+func TestJSONRPCWebSocket(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{CORSOrigins: []string{"*"}}, mbc, mdp, func(proto.Message) error { return nil })
+	require.Nil(err)
+	server := httptest.NewServer(s.JSONRPCHandler())
+	defer server.Close()
+
+	ch := make(chan *blockchain.BlockEvent, 1)
+	mbc.EXPECT().Subscribe().Return((<-chan *blockchain.BlockEvent)(ch)).Times(1)
+	unsubscribed := make(chan struct{})
+	mbc.EXPECT().Unsubscribe(gomock.Any()).Do(func(<-chan *blockchain.BlockEvent) { close(unsubscribed) }).Times(1)
+	mbc.EXPECT().TipHash().Return(cp.ZeroHash32B).AnyTimes()
+	mbc.EXPECT().TipHeight().Return(uint32(1)).AnyTimes()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+WebSocketPath, "", "https://explorer.iotex.io")
+	require.Nil(err)
+	defer conn.Close()
+	call := func(req string) testResponse {
+		require.Nil(websocket.Message.Send(conn, req))
+		var r testResponse
+		require.Nil(websocket.JSON.Receive(conn, &r))
+		return r
+	}
+
+	r := call(`{"jsonrpc":"2.0","id":1,"method":"getTipInfo"}`)
+	assert.Nil(r.Error)
+	r = call(`{"jsonrpc":"2.0","id":2,"method":"subscribeBlocks"}`)
+	require.Nil(r.Error)
+	var id string
+	require.Nil(json.Unmarshal(r.Result, &id))
+
+	blk := testingBlocks()[1]
+	ch <- &blockchain.BlockEvent{Type: blockchain.BlockCommitted, Block: blk}
+	var n struct {
+		Method string `json:"method"`
+		Params struct {
+			Subscription string `json:"subscription"`
+			Result       struct {
+				Type   string `json:"type"`
+				Height uint32 `json:"height"`
+			} `json:"result"`
+		} `json:"params"`
+	}
+	require.Nil(websocket.JSON.Receive(conn, &n))
+	assert.Equal(blockEventMethod, n.Method)
+	assert.Equal(id, n.Params.Subscription)
+	assert.Equal("BLOCK_COMMITTED", n.Params.Result.Type)
+	assert.Equal(uint32(1), n.Params.Result.Height)
+
+	r = call(`{"jsonrpc":"2.0","id":3,"method":"unsubscribe","params":{"subscription":"` + id + `"}}`)
+	assert.Equal("true", string(r.Result))
+	<-unsubscribed
+	r = call(`{"jsonrpc":"2.0","id":4,"method":"unsubscribe","params":{"subscription":"` + id + `"}}`)
+	assert.Equal("false", string(r.Result))
+}
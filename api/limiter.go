@@ -0,0 +1,156 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/common/utils"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/txpool"
+)
+
+// ErrRateLimited indicates the client sends requests more frequently than allowed
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// rateLimiter limits the requests of each client IP, in total and to each method, over a sliding window
+type rateLimiter struct {
+	window       time.Duration
+	limit        uint64
+	methodLimits map[string]uint64
+	// counters are the sliding window counters by client IP and by client IP and method
+	counters sync.Map
+}
+
+// newRateLimiter returns the limiter of the config, or nil if rate limiting is disabled
+func newRateLimiter(c config.API) *rateLimiter {
+	if !c.RateLimitEnabled {
+		return nil
+	}
+	perWindow := func(perSec uint64) uint64 {
+		return perSec * uint64(c.RateLimitWindowSize) / uint64(time.Second)
+	}
+	l := &rateLimiter{window: c.RateLimitWindowSize, limit: perWindow(c.RateLimitPerSec), methodLimits: map[string]uint64{}}
+	for method, perSec := range c.MethodRateLimits {
+		l.methodLimits[method] = perWindow(perSec)
+	}
+	return l
+}
+
+// SetRateLimits applies the rate limits of the config, RateLimitEnabled, RateLimitPerSec, MethodRateLimits and
+// RateLimitWindowSize, to the requests received from now on, the requests counted so far are forgotten
+func (s *Server) SetRateLimits(c config.API) {
+	s.limiterMu.Lock()
+	defer s.limiterMu.Unlock()
+	s.limiter = newRateLimiter(c)
+}
+
+// currentLimiter returns the current limiter of the server
+func (s *Server) currentLimiter() *rateLimiter {
+	s.limiterMu.RLock()
+	defer s.limiterMu.RUnlock()
+	return s.limiter
+}
+
+// allow counts the request of the client to the method, named as in the gRPC service, and returns ErrRateLimited if
+// the client exceeds the limit in total or the limit of the method, a zero total limit means no such limit
+func (l *rateLimiter) allow(ip string, method string) error {
+	if l == nil {
+		return nil
+	}
+	if l.limit > 0 && l.count(ip) > l.limit {
+		return errors.Wrapf(ErrRateLimited, "client %s, at most %d requests per %v", ip, l.limit, l.window)
+	}
+	if limit, ok := l.methodLimits[method]; ok && l.count(ip+"/"+method) > limit {
+		return errors.Wrapf(ErrRateLimited, "client %s, at most %d %s requests per %v", ip, limit, method, l.window)
+	}
+	return nil
+}
+
+func (l *rateLimiter) count(key string) uint64 {
+	c, _ := l.counters.LoadOrStore(key, utils.NewSlidingWindowCounterWithSecondSlot(l.window))
+	c.(*utils.SlidingWindowCounter).Increment()
+	return c.(*utils.SlidingWindowCounter).Count()
+}
+
+// hostOf returns the host of the address, or the address itself if it has no port
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// clientIPKey is the key of the context value holding the IP of the client of the JSON-RPC gateway
+type clientIPKey struct{}
+
+// clientIP returns the IP of the client of the context, either of the JSON-RPC gateway or of gRPC
+func clientIP(ctx context.Context) string {
+	if ip, ok := ctx.Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return hostOf(p.Addr.String())
+	}
+	return ""
+}
+
+// methodName returns the name of the method of the full gRPC method name, e.g., GetTipInfo of
+// /iproto.ApiService/GetTipInfo
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
+
+// grpcError returns the error with the status code telling the client why the request fails
+func grpcError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch errors.Cause(err) {
+	case ErrInvalidRequest:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case ErrTxNotFound, ErrChainNotFound, txpool.ErrTxNotInPool:
+		return status.Error(codes.NotFound, err.Error())
+	case ErrRateLimited:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case blockchain.ErrInsufficientFunds:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return err
+	}
+}
+
+// unaryInterceptor applies the rate limits to the unary calls and sets the status codes of their errors
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.currentLimiter().allow(clientIP(ctx), methodName(info.FullMethod)); err != nil {
+		return nil, grpcError(err)
+	}
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+// streamInterceptor applies the rate limits to the streaming calls
+func (s *Server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.currentLimiter().allow(clientIP(ss.Context()), methodName(info.FullMethod)); err != nil {
+		return grpcError(err)
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_dispatcher"
+)
+
+func TestRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(newRateLimiter(config.API{RateLimitPerSec: 1}))
+	l := newRateLimiter(config.API{
+		RateLimitEnabled:    true,
+		RateLimitPerSec:     3,
+		MethodRateLimits:    map[string]uint64{"SendRawTransaction": 1},
+		RateLimitWindowSize: time.Second,
+	})
+	assert.Nil(l.allow("10.0.0.1", "SendRawTransaction"))
+	assert.Equal(ErrRateLimited, errors.Cause(l.allow("10.0.0.1", "SendRawTransaction")))
+	// the method limit of a client does not apply to the other clients
+	assert.Nil(l.allow("10.0.0.2", "SendRawTransaction"))
+	// the rejected requests count towards the total limit
+	assert.Nil(l.allow("10.0.0.1", "GetTipInfo"))
+	assert.Equal(ErrRateLimited, errors.Cause(l.allow("10.0.0.1", "GetTipInfo")))
+	assert.Nil(l.allow("10.0.0.2", "GetTipInfo"))
+
+	assert.Equal("GetTipInfo", methodName("/iproto.ApiService/GetTipInfo"))
+	assert.Equal("10.0.0.1", hostOf("10.0.0.1:4689"))
+	assert.Equal("10.0.0.1", hostOf("10.0.0.1"))
+}
+
+func TestGRPCInterceptor(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	c := config.API{
+		RateLimitEnabled:    true,
+		MethodRateLimits:    map[string]uint64{"GetBlocksByRange": 1},
+		RateLimitWindowSize: time.Second,
+		MaxBlocksPerRange:   10,
+	}
+	s, err := NewServer(c, mbc, mdp, func(proto.Message) error { return nil })
+	require.Nil(err)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 4689}})
+	info := &grpc.UnaryServerInfo{FullMethod: "/iproto.ApiService/GetBlocksByRange"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.GetBlocksByRange(ctx, req.(*pb.GetBlocksByRangeRequest))
+	}
+	_, err = s.unaryInterceptor(ctx, &pb.GetBlocksByRangeRequest{Start: 0, End: 10}, info, handler)
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+	assert.Contains(status.Convert(err).Message(), "at most 10 blocks")
+	_, err = s.unaryInterceptor(ctx, &pb.GetBlocksByRangeRequest{Start: 0, End: 9}, info, handler)
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+	// the limits are lifted at runtime
+	s.SetRateLimits(config.API{})
+	_, err = s.unaryInterceptor(ctx, &pb.GetBlocksByRangeRequest{Start: 0, End: 10}, info, handler)
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	assert.Equal(codes.NotFound, status.Code(grpcError(errors.Wrap(ErrTxNotFound, "hash"))))
+	assert.Equal(codes.NotFound, status.Code(grpcError(ErrChainNotFound)))
+	assert.Equal(codes.FailedPrecondition, status.Code(grpcError(errors.Wrap(blockchain.ErrInsufficientFunds, "address"))))
+	assert.Equal(codes.AlreadyExists, status.Code(grpcError(status.Error(codes.AlreadyExists, "duplicate"))))
+}
+
+func TestJSONRPCRateLimit(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	c := config.API{RateLimitEnabled: true, RateLimitPerSec: 2, RateLimitWindowSize: time.Second}
+	s, err := NewServer(c, mbc, mdp, func(proto.Message) error { return nil })
+	require.Nil(err)
+	server := httptest.NewServer(s.JSONRPCHandler())
+	defer server.Close()
+
+	mbc.EXPECT().TipHash().Return(cp.ZeroHash32B).Times(2)
+	mbc.EXPECT().TipHeight().Return(uint32(1)).Times(2)
+	_, body := post(t, server, `[
+		{"jsonrpc":"2.0","id":1,"method":"getTipInfo"},
+		{"jsonrpc":"2.0","id":2,"method":"getTipInfo"},
+		{"jsonrpc":"2.0","id":3,"method":"getTipInfo"}
+	]`)
+	var batch []testResponse
+	require.Nil(json.Unmarshal([]byte(body), &batch))
+	require.Equal(3, len(batch))
+	assert.Nil(batch[0].Error)
+	assert.Nil(batch[1].Error)
+	require.NotNil(batch[2].Error)
+	assert.Equal(jsonrpcLimitExceeded, batch[2].Error.Code)
+}
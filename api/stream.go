@@ -0,0 +1,137 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package api
+
+import (
+	"sync"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	pb "github.com/iotexproject/iotex-core/proto"
+)
+
+// blockCursor follows the block events of a chain for a block stream, coalescing them into the lowest height a reorg
+// happens at and a wakeup, so the events are never dropped by the chain however slow the client of the stream is
+type blockCursor struct {
+	mu       sync.Mutex
+	reorged  uint32
+	hasReorg bool
+	closed   bool
+	wake     chan struct{}
+}
+
+func newBlockCursor() *blockCursor {
+	return &blockCursor{wake: make(chan struct{}, 1)}
+}
+
+// follow consumes the events of the channel until it is closed
+func (c *blockCursor) follow(ch <-chan *blockchain.BlockEvent) {
+	for evt := range ch {
+		c.mu.Lock()
+		if height := evt.Block.Height(); evt.Type == blockchain.ChainReorged && (!c.hasReorg || height < c.reorged) {
+			c.reorged, c.hasReorg = height, true
+		}
+		c.mu.Unlock()
+		c.signal()
+	}
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.signal()
+}
+
+func (c *blockCursor) signal() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// takeReorg returns the lowest height a reorg happened at since the last call, if any
+func (c *blockCursor) takeReorg() (uint32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	height, ok := c.reorged, c.hasReorg
+	c.reorged, c.hasReorg = 0, false
+	return height, ok
+}
+
+func (c *blockCursor) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// StreamBlocks streams the blocks from the requested height on, replaying the committed blocks before following the
+// ones committed from now on, until the client goes away or the chain is stopped
+// A CHAIN_REORGED event tells the client to drop the blocks it has received at and above the height of the event
+// block, which replaces them. The blocks are read from the chain as fast as the client receives them, so a slow client
+// falls behind the tip without the server buffering blocks for it, and catches up once it speeds up.
+func (s *Server) StreamBlocks(in *pb.StreamBlocksRequest, stream pb.ApiService_StreamBlocksServer) error {
+	bc, err := s.chain(stream.Context())
+	if err != nil {
+		return err
+	}
+	// subscribe before reading the tip, so no block committed meanwhile goes unnoticed
+	ch := bc.Subscribe()
+	defer bc.Unsubscribe(ch)
+	cursor := newBlockCursor()
+	go cursor.follow(ch)
+
+	next := in.FromHeight
+	// tip is the hash of the last block sent
+	tip := cp.ZeroHash32B
+	send := func(typ blockchain.EventType, blk *blockchain.Block) error {
+		oldTip := blk.PrevHash()
+		if typ == blockchain.ChainReorged {
+			oldTip = tip
+		}
+		if err := stream.Send(convertToBlockEventPb(&blockchain.BlockEvent{Type: typ, Block: blk, OldTip: oldTip})); err != nil {
+			return err
+		}
+		tip = blk.HashBlock()
+		next = blk.Height() + 1
+		return nil
+	}
+	for {
+		// the client only has to drop blocks once it has received some
+		if height, ok := cursor.takeReorg(); ok && height < next && next > in.FromHeight {
+			blk, err := bc.GetBlockByHeight(height)
+			if err != nil {
+				if height > bc.TipHeight() {
+					// rolled back further since, which the event to come tells
+					continue
+				}
+				return err
+			}
+			if err := send(blockchain.ChainReorged, blk); err != nil {
+				return err
+			}
+			continue
+		}
+		if next <= bc.TipHeight() {
+			blk, err := bc.GetBlockByHeight(next)
+			if err != nil {
+				if next > bc.TipHeight() {
+					continue
+				}
+				return err
+			}
+			if err := send(blockchain.BlockCommitted, blk); err != nil {
+				return err
+			}
+			continue
+		}
+		if cursor.isClosed() {
+			return nil
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-cursor.wake:
+		}
+	}
+}
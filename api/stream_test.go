@@ -0,0 +1,113 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package api
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_dispatcher"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+type fakeBlockStream struct {
+	pb.ApiService_StreamBlocksServer
+	ctx    context.Context
+	events []*pb.BlockEventPb
+	// onSend is called with the number of events sent so far
+	onSend func(n int)
+}
+
+func (s *fakeBlockStream) Context() context.Context { return s.ctx }
+
+func (s *fakeBlockStream) Send(evt *pb.BlockEventPb) error {
+	s.events = append(s.events, evt)
+	s.onSend(len(s.events))
+	return nil
+}
+
+func TestStreamBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+
+	blks := testingBlocks()
+	fork := blockchain.NewBlock(0, 1, blks[0].HashBlock(), []*blockchain.Tx{blockchain.NewCoinbaseTx(ta.Addrinfo["bravo"].Address, 5, "")})
+	next := blockchain.NewBlock(0, 2, fork.HashBlock(), []*blockchain.Tx{blockchain.NewCoinbaseTx(ta.Addrinfo["alfa"].Address, 5, "")})
+
+	var mu sync.Mutex
+	chain := []*blockchain.Block{blks[0], blks[1]}
+	mbc.EXPECT().TipHeight().DoAndReturn(func() uint32 {
+		mu.Lock()
+		defer mu.Unlock()
+		return uint32(len(chain) - 1)
+	}).AnyTimes()
+	mbc.EXPECT().GetBlockByHeight(gomock.Any()).DoAndReturn(func(height uint32) (*blockchain.Block, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if int(height) >= len(chain) {
+			return nil, errors.New("block not found")
+		}
+		return chain[height], nil
+	}).AnyTimes()
+	ch := make(chan *blockchain.BlockEvent, 2)
+	mbc.EXPECT().Subscribe().Return((<-chan *blockchain.BlockEvent)(ch)).Times(1)
+	mbc.EXPECT().Unsubscribe(gomock.Any()).Times(1)
+
+	// the blocks are replayed, then the fork replacing block 1 is reported and the block committed on top follows
+	stream := &fakeBlockStream{ctx: context.Background()}
+	stream.onSend = func(n int) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch n {
+		case 2:
+			chain = []*blockchain.Block{blks[0], fork}
+			ch <- &blockchain.BlockEvent{Type: blockchain.ChainReorged, Block: fork, OldTip: blks[1].HashBlock()}
+		case 3:
+			chain = append(chain, next)
+			ch <- &blockchain.BlockEvent{Type: blockchain.BlockCommitted, Block: next, OldTip: fork.HashBlock()}
+		case 4:
+			close(ch)
+		}
+	}
+	assert.Nil(t, s.StreamBlocks(&pb.StreamBlocksRequest{}, stream))
+	assert.Equal(t, 4, len(stream.events))
+	for i, height := range []uint32{0, 1, 1, 2} {
+		assert.Equal(t, height, stream.events[i].Height)
+	}
+	assert.Equal(t, pb.BlockEventPb_BLOCK_COMMITTED, stream.events[1].Type)
+	assert.Equal(t, pb.BlockEventPb_CHAIN_REORGED, stream.events[2].Type)
+	forkHash, oldHash := fork.HashBlock(), blks[1].HashBlock()
+	assert.Equal(t, forkHash[:], stream.events[2].Hash)
+	assert.Equal(t, oldHash[:], stream.events[2].OldTip)
+	assert.Equal(t, pb.BlockEventPb_BLOCK_COMMITTED, stream.events[3].Type)
+	assert.Equal(t, forkHash[:], stream.events[3].OldTip)
+
+	// the stream ends when the client goes away
+	ch = make(chan *blockchain.BlockEvent)
+	mbc.EXPECT().Subscribe().Return((<-chan *blockchain.BlockEvent)(ch)).Times(1)
+	mbc.EXPECT().Unsubscribe(gomock.Any()).Times(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream = &fakeBlockStream{ctx: ctx}
+	stream.onSend = func(n int) { cancel() }
+	assert.Equal(t, context.Canceled, s.StreamBlocks(&pb.StreamBlocksRequest{FromHeight: 2}, stream))
+	assert.Equal(t, 1, len(stream.events))
+	assert.Equal(t, uint32(2), stream.events[0].Height)
+	close(ch)
+}
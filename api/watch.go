@@ -0,0 +1,355 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/txpool"
+)
+
+const (
+	// MaxWatchedAddresses is the max number of addresses one WatchAddresses request watches
+	MaxWatchedAddresses = 100
+	// DefaultWatchConfirmations is the number of confirmations a watched tx is reported confirmed at unless requested
+	DefaultWatchConfirmations = 6
+)
+
+// outpoint identifies an output by the hash of its tx and its index
+type outpoint struct {
+	hash  cp.Hash32B
+	index int32
+}
+
+// watchedOutput is an output locked to a watched address
+type watchedOutput struct {
+	address string
+	// height is the height of the block creating the output, 0 while its tx is pending
+	height uint32
+	// spent is the height of the block spending the output, 0 while it is unspent
+	spent uint32
+}
+
+// minedTx is a watched tx mined in a block which has yet to be confirmed
+type minedTx struct {
+	addresses []string
+	height    uint32
+	blockHash cp.Hash32B
+}
+
+// addressWatch follows the txs touching the watched addresses, i.e., paying an output locked to one of them or
+// spending one, from the time they enter the txpool until they are mined and confirmed
+// The watch tracks the outputs of the addresses itself, so no index of the chain is needed. The blocks are connected
+// one at a time in order, and a reorg disconnects the blocks replaced down to the fork point, reporting the watched
+// txs they mined as reorged. The txs confirmed by the requested number of blocks are no longer tracked, so a deeper
+// reorg goes unreported.
+type addressWatch struct {
+	bc            blockchain.IBlockchain
+	keys          map[string][]byte
+	confirmations uint32
+	outputs       map[outpoint]*watchedOutput
+	pending       map[cp.Hash32B]bool
+	mined         map[cp.Hash32B]*minedTx
+	// hashes are the hashes of the connected blocks which have yet to be final
+	hashes map[uint32]cp.Hash32B
+	// final is the height up to which the blocks are final to the watch
+	final uint32
+	// next is the height of the next block to connect
+	next uint32
+}
+
+// newAddressWatch validates the request and creates the watch of its addresses on the chain
+func newAddressWatch(bc blockchain.IBlockchain, in *pb.WatchAddressesRequest) (*addressWatch, error) {
+	if len(in.Addresses) == 0 || len(in.Addresses) > MaxWatchedAddresses {
+		return nil, errors.Wrapf(ErrInvalidRequest, "number of addresses = %d", len(in.Addresses))
+	}
+	w := &addressWatch{
+		bc:            bc,
+		keys:          make(map[string][]byte),
+		confirmations: in.Confirmations,
+		outputs:       make(map[outpoint]*watchedOutput),
+		pending:       make(map[cp.Hash32B]bool),
+		mined:         make(map[cp.Hash32B]*minedTx),
+		hashes:        make(map[uint32]cp.Hash32B),
+	}
+	if w.confirmations == 0 {
+		w.confirmations = DefaultWatchConfirmations
+	}
+	for _, addr := range in.Addresses {
+		if !iotxaddress.ValidateAddress(addr) {
+			return nil, errors.Wrapf(ErrInvalidRequest, "address = %s", addr)
+		}
+		w.keys[addr] = iotxaddress.GetPubkeyHash(addr)
+	}
+	return w, nil
+}
+
+// load starts the watch at the tip of the chain, tracking the UTXO of the watched addresses
+func (w *addressWatch) load() error {
+	w.final = w.bc.TipHeight()
+	w.next = w.final + 1
+	for addr := range w.keys {
+		utxo, err := w.bc.ListUnspent(addr, 0, 0, 0, 0)
+		if err != nil {
+			return err
+		}
+		for _, u := range utxo {
+			w.outputs[outpoint{u.Hash, u.Index}] = &watchedOutput{address: addr, height: u.Height}
+		}
+	}
+	return nil
+}
+
+// touch returns the watched addresses the tx touches, tracking the outputs it pays to them and, if it is mined in the
+// block at the height, the ones it spends
+func (w *addressWatch) touch(tx *blockchain.Tx, height uint32) []string {
+	touched := make(map[string]bool)
+	for _, txIn := range tx.TxIn {
+		key := outpoint{index: txIn.OutIndex}
+		copy(key.hash[:], txIn.TxHash)
+		if out, ok := w.outputs[key]; ok && out.spent == 0 {
+			touched[out.address] = true
+			out.spent = height
+		}
+	}
+	hash := tx.Hash()
+	for i, txOut := range tx.TxOut {
+		for addr, key := range w.keys {
+			if txOut.IsLockedWithKey(key) {
+				touched[addr] = true
+				w.outputs[outpoint{hash, int32(i)}] = &watchedOutput{address: addr, height: height}
+			}
+		}
+	}
+	addrs := make([]string, 0, len(touched))
+	for addr := range touched {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// pend returns the PENDING event of the tx accepted into the txpool, nil if it does not touch the watched addresses or
+// has been reported already
+func (w *addressWatch) pend(tx *blockchain.Tx) *pb.PaymentEventPb {
+	hash := tx.Hash()
+	if w.pending[hash] || w.mined[hash] != nil {
+		return nil
+	}
+	addrs := w.touch(tx, 0)
+	if len(addrs) == 0 {
+		return nil
+	}
+	w.pending[hash] = true
+	return &pb.PaymentEventPb{Type: pb.PaymentEventPb_PENDING, TxHash: hash[:], Addresses: addrs}
+}
+
+// connect connects the next block, returning the MINED events of the watched txs it mines and the CONFIRMED events of
+// the ones it confirms
+func (w *addressWatch) connect(blk *blockchain.Block) []*pb.PaymentEventPb {
+	height, blkHash := blk.Height(), blk.HashBlock()
+	evts := []*pb.PaymentEventPb{}
+	for _, tx := range blk.Tranxs {
+		addrs := w.touch(tx, height)
+		if len(addrs) == 0 {
+			continue
+		}
+		hash := tx.Hash()
+		delete(w.pending, hash)
+		w.mined[hash] = &minedTx{addresses: addrs, height: height, blockHash: blkHash}
+		evts = append(evts, minedEvent(pb.PaymentEventPb_MINED, hash, w.mined[hash], 1))
+	}
+	w.hashes[height] = blkHash
+	w.next = height + 1
+
+	// the blocks confirmed by the requested number of blocks are final
+	if height < w.confirmations {
+		return evts
+	}
+	final := height - w.confirmations + 1
+	confirmed := []*pb.PaymentEventPb{}
+	for hash, tx := range w.mined {
+		if tx.height <= final {
+			confirmed = append(confirmed, minedEvent(pb.PaymentEventPb_CONFIRMED, hash, tx, height-tx.height+1))
+			delete(w.mined, hash)
+		}
+	}
+	for key, out := range w.outputs {
+		if out.spent > 0 && out.spent <= final {
+			delete(w.outputs, key)
+		}
+	}
+	for ; w.final < final; w.final++ {
+		delete(w.hashes, w.final+1)
+	}
+	sortPaymentEvents(confirmed)
+	return append(evts, confirmed...)
+}
+
+// disconnect disconnects the blocks replaced by a reorg at the height, down to the fork point, and returns the
+// REORGED events of the watched txs they mined
+// The blocks which are final to the watch are never disconnected.
+func (w *addressWatch) disconnect(height uint32) []*pb.PaymentEventPb {
+	if height <= w.final {
+		height = w.final + 1
+	}
+	// the blocks still on the chain are kept
+	for ; height < w.next; height++ {
+		if hash, err := w.bc.GetHashByHeight(height); err != nil || hash != w.hashes[height] {
+			break
+		}
+	}
+	evts := []*pb.PaymentEventPb{}
+	for hash, tx := range w.mined {
+		if tx.height >= height {
+			evts = append(evts, minedEvent(pb.PaymentEventPb_REORGED, hash, tx, 0))
+			delete(w.mined, hash)
+		}
+	}
+	for key, out := range w.outputs {
+		if out.height >= height {
+			delete(w.outputs, key)
+		} else if out.spent >= height {
+			out.spent = 0
+		}
+	}
+	for ; w.next > height; w.next-- {
+		delete(w.hashes, w.next-1)
+	}
+	sortPaymentEvents(evts)
+	return evts
+}
+
+func minedEvent(typ pb.PaymentEventPb_EventType, hash cp.Hash32B, tx *minedTx, confirmations uint32) *pb.PaymentEventPb {
+	return &pb.PaymentEventPb{
+		Type:          typ,
+		TxHash:        hash[:],
+		Addresses:     tx.addresses,
+		Height:        tx.height,
+		BlockHash:     tx.blockHash[:],
+		Confirmations: confirmations,
+	}
+}
+
+// sortPaymentEvents sorts the events of the txs of several blocks by height and tx hash
+func sortPaymentEvents(evts []*pb.PaymentEventPb) {
+	sort.Slice(evts, func(i, j int) bool {
+		if evts[i].Height != evts[j].Height {
+			return evts[i].Height < evts[j].Height
+		}
+		return bytes.Compare(evts[i].TxHash, evts[j].TxHash) < 0
+	})
+}
+
+// WatchAddresses streams the events of the txs paying or spending from the addresses, until the client goes away or
+// the chain is stopped
+// A tx is reported PENDING when it enters the txpool, MINED when a block including it is committed, and CONFIRMED
+// once it is confirmed by the requested number of blocks, after which it is no longer followed. A MINED tx is reported
+// REORGED if its block is replaced by a reorg, the block mining it again, if any, being reported afterwards. The txs
+// already in the txpool are reported PENDING first. The blocks are followed like StreamBlocks does, so a slow client
+// misses no block.
+func (s *Server) WatchAddresses(in *pb.WatchAddressesRequest, stream pb.ApiService_WatchAddressesServer) error {
+	bc, err := s.chain(stream.Context())
+	if err != nil {
+		return err
+	}
+	w, err := newAddressWatch(bc, in)
+	if err != nil {
+		return err
+	}
+	return s.watchAddresses(stream.Context(), w, stream.Send)
+}
+
+// watchAddresses runs the watch, sending its events until ctx is done or the chain is stopped
+func (s *Server) watchAddresses(ctx context.Context, w *addressWatch, send func(*pb.PaymentEventPb) error) error {
+	// subscribe before loading the watch, so no block committed or tx accepted meanwhile goes unnoticed
+	ch := w.bc.Subscribe()
+	defer w.bc.Unsubscribe(ch)
+	cursor := newBlockCursor()
+	go cursor.follow(ch)
+	var txs <-chan *txpool.TxDesc
+	// the txpool only holds the txs of the main chain
+	if s.txpool != nil && w.bc == s.blockchain {
+		txs = s.txpool.Subscribe()
+		defer s.txpool.Unsubscribe(txs)
+	}
+	if err := w.load(); err != nil {
+		return err
+	}
+	sendAll := func(evts []*pb.PaymentEventPb) error {
+		for _, evt := range evts {
+			if err := send(evt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if txs != nil {
+		descs := []*txpool.TxDesc{}
+		for addr := range w.keys {
+			descs = append(descs, s.txpool.PendingByAddress(addr)...)
+		}
+		// parents first, so the outputs they pay to the addresses are tracked before being spent
+		sort.SliceStable(descs, func(i, j int) bool { return descs[i].AddedTime.Before(descs[j].AddedTime) })
+		for _, desc := range descs {
+			if evt := w.pend(desc.Tx); evt != nil {
+				if err := send(evt); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for {
+		if height, ok := cursor.takeReorg(); ok && height < w.next {
+			if err := sendAll(w.disconnect(height)); err != nil {
+				return err
+			}
+			continue
+		}
+		if w.next <= w.bc.TipHeight() {
+			blk, err := w.bc.GetBlockByHeight(w.next)
+			if err != nil {
+				if w.next > w.bc.TipHeight() {
+					// rolled back since, which the reorg to come tells
+					continue
+				}
+				return err
+			}
+			if err := sendAll(w.connect(blk)); err != nil {
+				return err
+			}
+			continue
+		}
+		if cursor.isClosed() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-cursor.wake:
+		case desc, ok := <-txs:
+			if !ok {
+				txs = nil
+				continue
+			}
+			if evt := w.pend(desc.Tx); evt != nil {
+				if err := send(evt); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
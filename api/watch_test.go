@@ -0,0 +1,163 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package api
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_dispatcher"
+	"github.com/iotexproject/iotex-core/test/mock/mock_txpool"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/txpool"
+)
+
+type fakePaymentStream struct {
+	pb.ApiService_WatchAddressesServer
+	ctx    context.Context
+	events []*pb.PaymentEventPb
+	// onSend is called with the number of events sent so far
+	onSend func(n int)
+}
+
+func (s *fakePaymentStream) Context() context.Context { return s.ctx }
+
+func (s *fakePaymentStream) Send(evt *pb.PaymentEventPb) error {
+	s.events = append(s.events, evt)
+	s.onSend(len(s.events))
+	return nil
+}
+
+func TestWatchAddresses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	mtp := mock_txpool.NewMockTxPool(ctrl)
+	s, err := NewServer(config.API{}, mbc, mdp, func(proto.Message) error { return nil })
+	assert.Nil(t, err)
+	s.SetTxPool(mtp)
+
+	alfa, bravo, miner := ta.Addrinfo["alfa"].Address, ta.Addrinfo["bravo"].Address, ta.Addrinfo["miner"].Address
+	// the UTXO of alfa in the chain, spent by spendTx
+	utxoHash := cp.Hash32B{1}
+	payTx := blockchain.NewTx(1, []*blockchain.TxInput{blockchain.NewTxInput(cp.Hash32B{2}, 0, []byte{}, 0)},
+		[]*blockchain.TxOutput{blockchain.CreateTxOutput(alfa, 10)}, 0)
+	spendTx := blockchain.NewTx(1, []*blockchain.TxInput{blockchain.NewTxInput(utxoHash, 0, []byte{}, 0)},
+		[]*blockchain.TxOutput{blockchain.CreateTxOutput(bravo, 5)}, 0)
+	blk0 := testingBlocks()[0]
+	blk1 := blockchain.NewBlock(0, 1, blk0.HashBlock(), []*blockchain.Tx{payTx, blockchain.NewCoinbaseTx(miner, 5, "")})
+	blk2 := blockchain.NewBlock(0, 2, blk1.HashBlock(), []*blockchain.Tx{spendTx, blockchain.NewCoinbaseTx(miner, 5, "")})
+	fork2 := blockchain.NewBlock(0, 2, blk1.HashBlock(), []*blockchain.Tx{blockchain.NewCoinbaseTx(miner, 5, "")})
+	blk3 := blockchain.NewBlock(0, 3, fork2.HashBlock(), []*blockchain.Tx{spendTx, blockchain.NewCoinbaseTx(miner, 5, "")})
+
+	var mu sync.Mutex
+	chain := []*blockchain.Block{blk0}
+	mbc.EXPECT().TipHeight().DoAndReturn(func() uint32 {
+		mu.Lock()
+		defer mu.Unlock()
+		return uint32(len(chain) - 1)
+	}).AnyTimes()
+	mbc.EXPECT().GetBlockByHeight(gomock.Any()).DoAndReturn(func(height uint32) (*blockchain.Block, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if int(height) >= len(chain) {
+			return nil, errors.New("block not found")
+		}
+		return chain[height], nil
+	}).AnyTimes()
+	mbc.EXPECT().GetHashByHeight(gomock.Any()).DoAndReturn(func(height uint32) (cp.Hash32B, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if int(height) >= len(chain) {
+			return cp.ZeroHash32B, errors.New("block not found")
+		}
+		return chain[height].HashBlock(), nil
+	}).AnyTimes()
+	mbc.EXPECT().ListUnspent(alfa, uint32(0), uint32(0), uint32(0), uint32(0)).
+		Return([]*blockchain.Unspent{{Address: alfa, Hash: utxoHash, Index: 0, Value: 5}}, nil).Times(1)
+	ch := make(chan *blockchain.BlockEvent, 2)
+	mbc.EXPECT().Subscribe().Return((<-chan *blockchain.BlockEvent)(ch)).Times(1)
+	mbc.EXPECT().Unsubscribe(gomock.Any()).Times(1)
+	txs := make(chan *txpool.TxDesc, 1)
+	mtp.EXPECT().Subscribe().Return((<-chan *txpool.TxDesc)(txs)).Times(1)
+	mtp.EXPECT().Unsubscribe(gomock.Any()).Times(1)
+	mtp.EXPECT().PendingByAddress(alfa).Return([]*txpool.TxDesc{{Tx: payTx, AddedTime: time.Now()}}).Times(1)
+
+	// the txs are reported pending, mined and confirmed, and reorged out before being mined again
+	stream := &fakePaymentStream{ctx: context.Background()}
+	stream.onSend = func(n int) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch n {
+		case 1:
+			txs <- &txpool.TxDesc{Tx: spendTx, AddedTime: time.Now()}
+		case 2:
+			chain = append(chain, blk1)
+			ch <- &blockchain.BlockEvent{Type: blockchain.BlockCommitted, Block: blk1, OldTip: blk0.HashBlock()}
+		case 3:
+			chain = append(chain, blk2)
+			ch <- &blockchain.BlockEvent{Type: blockchain.BlockCommitted, Block: blk2, OldTip: blk1.HashBlock()}
+		case 5:
+			chain = []*blockchain.Block{blk0, blk1, fork2}
+			ch <- &blockchain.BlockEvent{Type: blockchain.ChainReorged, Block: fork2, OldTip: blk2.HashBlock()}
+		case 6:
+			chain = append(chain, blk3)
+			ch <- &blockchain.BlockEvent{Type: blockchain.BlockCommitted, Block: blk3, OldTip: fork2.HashBlock()}
+		case 7:
+			close(ch)
+		}
+	}
+	assert.Nil(t, s.WatchAddresses(&pb.WatchAddressesRequest{Addresses: []string{alfa}, Confirmations: 2}, stream))
+	payHash, spendHash := payTx.Hash(), spendTx.Hash()
+	expected := []struct {
+		typ           pb.PaymentEventPb_EventType
+		hash          cp.Hash32B
+		height        uint32
+		confirmations uint32
+	}{
+		{pb.PaymentEventPb_PENDING, payHash, 0, 0},
+		{pb.PaymentEventPb_PENDING, spendHash, 0, 0},
+		{pb.PaymentEventPb_MINED, payHash, 1, 1},
+		{pb.PaymentEventPb_MINED, spendHash, 2, 1},
+		{pb.PaymentEventPb_CONFIRMED, payHash, 1, 2},
+		{pb.PaymentEventPb_REORGED, spendHash, 2, 0},
+		{pb.PaymentEventPb_MINED, spendHash, 3, 1},
+	}
+	assert.Equal(t, len(expected), len(stream.events))
+	for i, e := range expected {
+		if i >= len(stream.events) {
+			break
+		}
+		evt := stream.events[i]
+		assert.Equal(t, e.typ, evt.Type, "event %d", i)
+		assert.Equal(t, e.hash[:], evt.TxHash, "event %d", i)
+		assert.Equal(t, e.height, evt.Height, "event %d", i)
+		assert.Equal(t, e.confirmations, evt.Confirmations, "event %d", i)
+		assert.Equal(t, []string{alfa}, evt.Addresses, "event %d", i)
+	}
+	blk2Hash := blk2.HashBlock()
+	assert.Equal(t, blk2Hash[:], stream.events[5].BlockHash)
+
+	// the addresses have to be valid
+	_, err = newAddressWatch(mbc, &pb.WatchAddressesRequest{})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+	_, err = newAddressWatch(mbc, &pb.WatchAddressesRequest{Addresses: []string{alfa, "Alice"}})
+	assert.Equal(t, ErrInvalidRequest, errors.Cause(err))
+}
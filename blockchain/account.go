@@ -0,0 +1,97 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/state"
+)
+
+// AccountState returns the nonce and balance of the address in the account-based state
+func (bc *Blockchain) AccountState(address string) *state.Account {
+	return bc.sf.Account(address)
+}
+
+// loadAccounts loads the account states persisted along with the UTXO
+func (bc *Blockchain) loadAccounts() error {
+	accounts, err := bc.blockDb.Accounts()
+	if err != nil {
+		return err
+	}
+	bc.sf.Clear()
+	for addr, buf := range accounts {
+		if err := bc.sf.LoadAccount(addr, buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeBlock verifies and executes the actions of the block in order on top of the current account states and
+// contracts, the genesis block credits the genesis accounts first
+// The returned working set holds the changes, which are only applied once the block is committed, and the receipts
+// record the results of the executions.
+func (bc *Blockchain) executeBlock(blk *Block) (*state.WorkingSet, []*Receipt, error) {
+	ws := bc.sf.NewWorkingSet()
+	if blk.Header.height == 0 && bc.genesis != nil {
+		for _, acct := range bc.genesis.Accounts {
+			if err := ws.Credit(acct.Address, acct.Amount); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	ctx := &ActionContext{ChainID: bc.chainID, Height: blk.Header.height, WS: ws}
+	for _, act := range blk.Actions() {
+		if err := act.Verify(); err != nil {
+			return nil, nil, err
+		}
+		if err := act.Execute(ctx); err != nil {
+			hash := act.Hash()
+			return nil, nil, errors.Wrapf(err, "Action %s %x", act.Kind(), hash)
+		}
+	}
+	return ws, ctx.Receipts, nil
+}
+
+// putAccounts adds the account states changed by the working set to the batch
+func putAccounts(batch *blockdb.Batch, ws *state.WorkingSet) {
+	for addr, acct := range ws.Changes() {
+		batch.PutAccount([]byte(addr), acct.Serialize())
+	}
+}
+
+// stateRoot returns the root committing to the UTXO pool and the account states once the block is applied, the view
+// holds the UTXO changed by the block and the working set the account states changed by it
+func (bc *Blockchain) stateRoot(view *UtxoView, ws *state.WorkingSet) (cp.Hash32B, error) {
+	utxoRoot, err := view.Root()
+	if err != nil {
+		return cp.ZeroHash32B, err
+	}
+	return cp.NewMerkleTree([]cp.Hash32B{utxoRoot, ws.Root()}).HashTree(), nil
+}
+
+// setStateRoot executes the block on top of the current states and sets the resulting state root in its header
+func (bc *Blockchain) setStateRoot(blk *Block) error {
+	ws, _, err := bc.executeBlock(blk)
+	if err != nil {
+		return err
+	}
+	// the supply is not checked, so the root can be set in a block breaking it, which validation rejects
+	view := bc.Utk.NewView()
+	if _, _, err := view.connectTxs(blk, nil); err != nil {
+		return err
+	}
+	root, err := bc.stateRoot(view, ws)
+	if err != nil {
+		return err
+	}
+	blk.Header.stateRoot = root
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
+)
+
+// ErrUnknownAction is the error returned when an action is of a kind which is not registered
+var ErrUnknownAction = errors.New("unknown action kind")
+
+// The kinds of the built-in actions, which a block carries in their own fields
+const (
+	TransferKind       = "transfer"
+	ContractDeployKind = "contractDeploy"
+	ContractInvokeKind = "contractInvoke"
+	VoteKind           = "vote"
+	EvidenceKind       = "evidence"
+	DepositKind        = "deposit"
+	WithdrawKind       = "withdraw"
+)
+
+// Action is an operation a block carries besides its UTXO transactions, which is executed against the account-based
+// state
+// Each kind of action serializes, validates and executes itself, so a new kind is added by registering it with
+// RegisterAction rather than changing the way blocks are processed.
+type Action interface {
+	// Kind returns the kind of the action, e.g., transfer
+	Kind() string
+	// Hash returns the hash of the action, which the merkle root of the block commits to
+	Hash() cp.Hash32B
+	// ByteStream returns the byte stream of the action, which the hash of the block is calculated over
+	ByteStream() []byte
+	// Serialize returns the serialized action, which the deserializer of its kind reads back
+	Serialize() ([]byte, error)
+	// Verify returns error if the action is malformed or not signed by its sender, regardless of the states
+	Verify() error
+	// Execute applies the verified action to the states the actions of the block are executed against
+	Execute(ctx *ActionContext) error
+}
+
+// NoncedAction is an action sent by an account, which is protected from replay by the nonce of the sender: the nonce of
+// each action of the sender has to follow the one of its previous action
+// The transfers, executions, votes and deposits are nonced actions.
+type NoncedAction interface {
+	Action
+	// SenderNonce returns the address sending the action and the nonce of the action
+	SenderNonce() (string, uint64)
+}
+
+// ActionContext is the block whose actions are executed, along with the states they are applied to
+type ActionContext struct {
+	ChainID uint32
+	Height  uint32
+	// WS holds the changes of the actions executed so far to the account states and contracts
+	WS *state.WorkingSet
+	// Receipts are the receipts of the actions executed so far, in their order
+	Receipts []*Receipt
+}
+
+// ActionDeserializer returns the action of its kind serialized in buf
+type ActionDeserializer func(buf []byte) (Action, error)
+
+var (
+	actionKindsMutex sync.RWMutex
+	// actionKinds are the deserializers of the registered kinds of actions by kind
+	actionKinds = map[string]ActionDeserializer{}
+	// builtinKinds are the kinds of the actions a block carries in their own fields, which cannot be registered
+	builtinKinds = map[string]bool{
+		TransferKind:       true,
+		ContractDeployKind: true,
+		ContractInvokeKind: true,
+		VoteKind:           true,
+		EvidenceKind:       true,
+		DepositKind:        true,
+		WithdrawKind:       true,
+	}
+)
+
+// RegisterAction registers a new kind of action along with its deserializer, so blocks carrying actions of the kind
+// can be read, returning error if the kind is a built-in one or already registered
+// The actions of the registered kinds are executed after the built-in ones, in the order the block carries them.
+func RegisterAction(kind string, deserialize ActionDeserializer) error {
+	actionKindsMutex.Lock()
+	defer actionKindsMutex.Unlock()
+
+	if builtinKinds[kind] {
+		return errors.Errorf("Action kind %s is built in", kind)
+	}
+	if _, ok := actionKinds[kind]; ok {
+		return errors.Errorf("Action kind %s is already registered", kind)
+	}
+	actionKinds[kind] = deserialize
+	return nil
+}
+
+// DeserializeAction returns the action of the registered kind serialized in buf, or ErrUnknownAction if the kind is
+// not registered
+func DeserializeAction(kind string, buf []byte) (Action, error) {
+	actionKindsMutex.RLock()
+	deserialize, ok := actionKinds[kind]
+	actionKindsMutex.RUnlock()
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownAction, "Kind %s", kind)
+	}
+	return deserialize(buf)
+}
+
+// NewBlockWithActions returns a new block with the transactions and the actions, the built-in ones being carried in
+// their own fields, see Actions for the order they are executed in
+func NewBlockWithActions(chainID uint32, height uint32, prevBlockHash cp.Hash32B, transactions []*Tx, actions []Action) *Block {
+	b := sortActions(actions)
+	return newBlock(chainID, height, prevBlockHash, transactions, b.Transfers, b.Executions, b.Votes, b.Evidences,
+		b.Deposits, b.Withdraws, b.OtherActions)
+}
+
+// sortActions returns a bare block, with neither a header nor transactions, carrying the actions in their fields
+func sortActions(actions []Action) *Block {
+	b := &Block{}
+	for _, act := range actions {
+		switch act := act.(type) {
+		case *Transfer:
+			b.Transfers = append(b.Transfers, act)
+		case *Execution:
+			b.Executions = append(b.Executions, act)
+		case *Vote:
+			b.Votes = append(b.Votes, act)
+		case *Evidence:
+			b.Evidences = append(b.Evidences, act)
+		case *Deposit:
+			b.Deposits = append(b.Deposits, act)
+		case *Withdraw:
+			b.Withdraws = append(b.Withdraws, act)
+		default:
+			b.OtherActions = append(b.OtherActions, act)
+		}
+	}
+	return b
+}
+
+// Actions returns the actions of the block in the order they are executed: the transfers, executions, votes,
+// evidences, deposits and withdraws, then the actions of the registered kinds
+func (b *Block) Actions() []Action {
+	var actions []Action
+	for _, tsf := range b.Transfers {
+		actions = append(actions, tsf)
+	}
+	for _, exec := range b.Executions {
+		actions = append(actions, exec)
+	}
+	for _, vote := range b.Votes {
+		actions = append(actions, vote)
+	}
+	for _, evidence := range b.Evidences {
+		actions = append(actions, evidence)
+	}
+	for _, deposit := range b.Deposits {
+		actions = append(actions, deposit)
+	}
+	for _, withdraw := range b.Withdraws {
+		actions = append(actions, withdraw)
+	}
+	return append(actions, b.OtherActions...)
+}
+
+// convertToActionPbs converts the actions of the registered kinds to ActionPb, skipping the ones which cannot be
+// serialized, which leaves the block failing to match its merkle root once it is read back
+func convertToActionPbs(actions []Action) []*iproto.ActionPb {
+	var pbActions []*iproto.ActionPb
+	for _, act := range actions {
+		payload, err := act.Serialize()
+		if err != nil {
+			hash := act.Hash()
+			log.WithFields(logger.Fields{"kind": act.Kind(), "hash": hash, "err": err}).Error("Cannot serialize action")
+			continue
+		}
+		pbActions = append(pbActions, &iproto.ActionPb{Kind: act.Kind(), Payload: payload})
+	}
+	return pbActions
+}
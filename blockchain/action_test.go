@@ -0,0 +1,117 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/state"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+const testCreditKind = "testCredit"
+
+// testCredit is an action of a registered kind crediting an address
+type testCredit struct {
+	Address string
+	Amount  uint64
+}
+
+var _ = RegisterAction(testCreditKind, func(buf []byte) (Action, error) {
+	act := &testCredit{}
+	if err := json.Unmarshal(buf, act); err != nil {
+		return nil, err
+	}
+	return act, nil
+})
+
+func (c *testCredit) Kind() string { return testCreditKind }
+
+func (c *testCredit) Hash() cp.Hash32B { return blake2b.Sum256(c.ByteStream()) }
+
+func (c *testCredit) ByteStream() []byte {
+	buf, _ := c.Serialize()
+	return buf
+}
+
+func (c *testCredit) Serialize() ([]byte, error) { return json.Marshal(c) }
+
+func (c *testCredit) Verify() error {
+	if c.Amount == 0 {
+		return errors.New("Zero amount")
+	}
+	return nil
+}
+
+func (c *testCredit) Execute(ctx *ActionContext) error { return ctx.WS.Credit(c.Address, c.Amount) }
+
+func TestRegisterAction(t *testing.T) {
+	assert := assert.New(t)
+
+	deserialize := func([]byte) (Action, error) { return &testCredit{}, nil }
+	assert.NotNil(RegisterAction(TransferKind, deserialize))
+	assert.NotNil(RegisterAction(testCreditKind, deserialize))
+
+	act, err := DeserializeAction(testCreditKind, []byte(`{"Address":"alfa","Amount":3}`))
+	assert.Nil(err)
+	assert.Equal(&testCredit{Address: "alfa", Amount: 3}, act)
+	_, err = DeserializeAction("unknown", nil)
+	assert.Equal(ErrUnknownAction, errors.Cause(err))
+}
+
+func TestBlockActions(t *testing.T) {
+	assert := assert.New(t)
+
+	alfa := ta.Addrinfo["alfa"].Address
+	credit := &testCredit{Address: alfa, Amount: 7}
+	tsf := NewTransfer(1, 2, alfa, ta.Addrinfo["bravo"].Address)
+	blk := NewBlock(1, 1, cp.ZeroHash32B, []*Tx{NewCoinbaseTx(ta.Addrinfo["miner"].Address, 5, "")})
+	blk.Transfers = []*Transfer{tsf}
+	blk.OtherActions = []Action{credit}
+	assert.Equal([]Action{tsf, credit}, blk.Actions())
+
+	// the registered actions are carried along with the built-in ones and committed to by the block
+	root := blk.MerkleRoot()
+	blk.Header.merkleRoot = root
+	buf, err := blk.Serialize()
+	assert.Nil(err)
+	read := &Block{}
+	assert.Nil(read.Deserialize(buf))
+	assert.Equal([]Action{credit}, read.OtherActions)
+	assert.Equal(root, read.MerkleRoot())
+	blk.OtherActions = nil
+	assert.NotEqual(root, blk.MerkleRoot())
+
+	// the registered actions are executed after the built-in ones, so the credit cannot fund the transfer
+	assert.Nil(tsf.Sign(wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+	blk.OtherActions = []Action{credit}
+	bc := &Blockchain{chainID: 1, sf: state.NewFactory()}
+	_, _, err = bc.executeBlock(blk)
+	assert.NotNil(err)
+	blk.Transfers = nil
+	ws, _, err := bc.executeBlock(blk)
+	assert.Nil(err)
+	assert.Equal(uint64(7), ws.Account(alfa).Balance)
+	blk.OtherActions = []Action{&testCredit{Address: alfa}}
+	_, _, err = bc.executeBlock(blk)
+	assert.NotNil(err)
+
+	// a block carrying an action of an unknown kind is malformed
+	pb := read.ConvertToBlockPb()
+	pb.Actions[0].Kind = "unknown"
+	buf, err = proto.Marshal(pb)
+	assert.Nil(err)
+	assert.Equal(ErrMalformedBlock, errors.Cause(read.Deserialize(buf)))
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	cm "github.com/iotexproject/iotex-core/common"
+)
+
+// The chain archive starts with a header of the magic, version, chain ID and the height range of the blocks, followed
+// by one record per block: the 4-byte size and the 4-byte CRC32 checksum of the serialized block, then the block.
+// All integers are in the machine endian.
+const (
+	// ArchiveVersion is the version of the chain archive format written by ExportChain
+	ArchiveVersion = uint32(1)
+
+	// archiveHeaderSize is the size of the magic, version, chain ID, start height and end height
+	archiveHeaderSize = 4 + 4 + 4 + 4 + 4
+)
+
+var (
+	// ErrInvalidArchive is the error returned when the chain archive is malformed or does not match the chain
+	ErrInvalidArchive = errors.New("invalid chain archive")
+
+	archiveMagic = []byte("IOTX")
+)
+
+// ExportChain writes the blocks with height in [start, end] to w in the chain archive format, stopping with the error
+// of ctx once it is done
+func (bc *Blockchain) ExportChain(ctx context.Context, w io.Writer, start uint32, end uint32) error {
+	if start > end || end > bc.height {
+		return errors.Errorf("Invalid block range [%d, %d], tip height is %d", start, end, bc.height)
+	}
+	if err := writeArchiveHeader(w, bc.chainID, start, end); err != nil {
+		return err
+	}
+	return bc.forEachBlock(ctx, start, end, func(blk *Block) error {
+		return writeArchiveRecord(w, blk)
+	})
+}
+
+// ImportChain reads the blocks of the chain archive from r and adds them to the chain in order
+// Every block is validated before it is added. Blocks the chain already has are skipped if they match, so an
+// archive overlapping the chain can be imported to restore the blocks after the tip. The import stops with the error
+// of ctx once it is done, keeping the blocks added so far.
+func (bc *Blockchain) ImportChain(ctx context.Context, r io.Reader) error {
+	chainID, start, end, err := readArchiveHeader(r)
+	if err != nil {
+		return err
+	}
+	if chainID != bc.chainID {
+		return errors.Wrapf(ErrInvalidArchive, "Wrong chain ID %d, expecting %d", chainID, bc.chainID)
+	}
+	if start > bc.height+1 {
+		return errors.Wrapf(ErrInvalidArchive, "Archive starts at height %d, beyond tip height %d", start, bc.height)
+	}
+
+	for height := start; ; height++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		blk, err := readArchiveRecord(r)
+		if err != nil {
+			return errors.Wrapf(err, "block %d", height)
+		}
+		if blk.Height() != height {
+			return errors.Wrapf(ErrInvalidArchive, "Wrong block height %d, expecting %d", blk.Height(), height)
+		}
+		if height <= bc.height {
+			hash, err := bc.GetHashByHeight(height)
+			if err != nil {
+				return err
+			}
+			if hash != blk.HashBlock() {
+				return errors.Wrapf(ErrInvalidArchive, "Block %d is %x, chain has %x", height, blk.HashBlock(), hash)
+			}
+		} else if err := bc.AddBlockCommit(blk); err != nil {
+			return err
+		}
+		if height == end {
+			return nil
+		}
+	}
+}
+
+// writeArchiveHeader writes the header of a chain archive of the blocks with height in [start, end]
+func writeArchiveHeader(w io.Writer, chainID uint32, start uint32, end uint32) error {
+	header := make([]byte, archiveHeaderSize)
+	copy(header, archiveMagic)
+	cm.MachineEndian.PutUint32(header[4:], ArchiveVersion)
+	cm.MachineEndian.PutUint32(header[8:], chainID)
+	cm.MachineEndian.PutUint32(header[12:], start)
+	cm.MachineEndian.PutUint32(header[16:], end)
+	_, err := w.Write(header)
+	return err
+}
+
+// readArchiveHeader reads the header of a chain archive and returns its chain ID and block range
+func readArchiveHeader(r io.Reader) (uint32, uint32, uint32, error) {
+	header := make([]byte, archiveHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, 0, 0, errors.Wrapf(ErrInvalidArchive, "Cannot read header: %v", err)
+	}
+	if !bytes.Equal(header[:4], archiveMagic) {
+		return 0, 0, 0, errors.Wrapf(ErrInvalidArchive, "Wrong magic %x", header[:4])
+	}
+	if version := cm.MachineEndian.Uint32(header[4:]); version != ArchiveVersion {
+		return 0, 0, 0, errors.Wrapf(ErrInvalidArchive, "Unsupported version %d, expecting %d", version, ArchiveVersion)
+	}
+	start := cm.MachineEndian.Uint32(header[12:])
+	end := cm.MachineEndian.Uint32(header[16:])
+	if start > end {
+		return 0, 0, 0, errors.Wrapf(ErrInvalidArchive, "Invalid block range [%d, %d]", start, end)
+	}
+	return cm.MachineEndian.Uint32(header[8:]), start, end, nil
+}
+
+// writeArchiveRecord writes the block prefixed by its size and checksum
+func writeArchiveRecord(w io.Writer, blk *Block) error {
+	data, err := blk.Serialize()
+	if err != nil {
+		return err
+	}
+	return writeArchiveData(w, data)
+}
+
+// writeArchiveData writes the serialized block prefixed by its size and checksum
+func writeArchiveData(w io.Writer, data []byte) error {
+	prefix := make([]byte, 8)
+	cm.MachineEndian.PutUint32(prefix, uint32(len(data)))
+	cm.MachineEndian.PutUint32(prefix[4:], crc32.ChecksumIEEE(data))
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readArchiveRecord reads the next block and verifies its checksum
+func readArchiveRecord(r io.Reader) (*Block, error) {
+	data, err := readArchiveData(r)
+	if err != nil {
+		return nil, err
+	}
+	return deserializeArchiveData(data)
+}
+
+// readArchiveData reads the next serialized block and verifies its checksum
+func readArchiveData(r io.Reader) ([]byte, error) {
+	prefix := make([]byte, 8)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, errors.Wrapf(ErrInvalidArchive, "Cannot read record: %v", err)
+	}
+	size := cm.MachineEndian.Uint32(prefix)
+	// bounds the memory allocated for a record, in case the size of a corrupt archive is garbage
+	if size > MaxBlockMsgSize {
+		return nil, errors.Wrapf(ErrInvalidArchive, "Record size %d exceeds the limit of %d", size, MaxBlockMsgSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errors.Wrapf(ErrInvalidArchive, "Cannot read record: %v", err)
+	}
+	if checksum := crc32.ChecksumIEEE(data); checksum != cm.MachineEndian.Uint32(prefix[4:]) {
+		return nil, errors.Wrapf(ErrInvalidArchive, "Wrong checksum %x", checksum)
+	}
+	return data, nil
+}
+
+// deserializeArchiveData deserializes the block of a record
+func deserializeArchiveData(data []byte) (*Block, error) {
+	blk := Block{}
+	if err := blk.Deserialize(data); err != nil {
+		return nil, errors.Wrapf(ErrInvalidArchive, "Cannot deserialize block: %v", err)
+	}
+	return &blk, nil
+}
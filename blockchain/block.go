@@ -8,11 +8,12 @@ package blockchain
 
 import (
 	"bytes"
-	"errors"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
 
 	cm "github.com/iotexproject/iotex-core/common"
 	cp "github.com/iotexproject/iotex-core/crypto"
@@ -22,6 +23,16 @@ import (
 const (
 	// Version of blockchain protocol
 	Version = 1
+	// MaxBlockMsgSize is the maximum size in bytes of a serialized block read from the Db, the block archive or peers,
+	// bounding the memory a malformed payload can make the node allocate
+	MaxBlockMsgSize = 1 << 26
+)
+
+var (
+	// ErrMalformedBlock indicates the serialized block cannot be parsed or its content does not match its header
+	ErrMalformedBlock = errors.New("malformed block")
+	// ErrMsgTooLarge indicates the serialized block or transaction exceeds its maximum size
+	ErrMsgTooLarge = errors.New("message too large")
 )
 
 // BlockHeader defines the struct of block header
@@ -33,8 +44,11 @@ type BlockHeader struct {
 	timestamp     uint64     // timestamp
 	prevBlockHash cp.Hash32B // hash of previous block
 	merkleRoot    cp.Hash32B // merkle root of all trn
+	stateRoot     cp.Hash32B // root of the UTXO pool and account states once the block is applied
 	trnxNumber    uint32     // number of transaction in this block
 	trnxDataSize  uint32     // size (in bytes) of transaction data in this block
+	pubkey        []byte     // public key of the block proposer
+	blockSig      []byte     // proposer's signature of the block hash
 }
 
 // Block defines the struct of block
@@ -42,13 +56,70 @@ type BlockHeader struct {
 type Block struct {
 	Header *BlockHeader
 	Tranxs []*Tx
+	// Transfers are executed against the account-based state, after the transactions are applied to the UTXO
+	Transfers []*Transfer
+	// Executions deploy and invoke contracts, after the transfers are executed
+	Executions []*Execution
+	// Votes stake the balances toward the candidates for the delegates, after the executions are executed
+	Votes []*Vote
+	// Evidences prove the misbehavior of block producers, which are penalized after the votes are applied
+	Evidences []*Evidence
+	// Deposits move balances out of the chain to other chains, after the evidences are applied
+	Deposits []*Deposit
+	// Withdraws credit the deposits committed on other chains to their recipients, after the deposits are executed
+	Withdraws []*Withdraw
+	// OtherActions are the actions of the kinds registered with RegisterAction, after the withdraws are executed
+	OtherActions []Action
+	// ReceivedAt is the time the block is received from a peer, which the trace of its processing starts at, zero if
+	// it is not received. It is not serialized.
+	ReceivedAt time.Time
 }
 
 // NewBlock returns a new block
 func NewBlock(chainID uint32, height uint32, prevBlockHash cp.Hash32B, transactions []*Tx) *Block {
+	return NewBlockWithTransfers(chainID, height, prevBlockHash, transactions, nil)
+}
+
+// NewBlockWithTransfers returns a new block with both transactions and transfers
+func NewBlockWithTransfers(chainID uint32, height uint32, prevBlockHash cp.Hash32B, transactions []*Tx, transfers []*Transfer) *Block {
+	return NewBlockWithExecutions(chainID, height, prevBlockHash, transactions, transfers, nil)
+}
+
+// NewBlockWithExecutions returns a new block with transactions, transfers and executions
+func NewBlockWithExecutions(chainID uint32, height uint32, prevBlockHash cp.Hash32B, transactions []*Tx, transfers []*Transfer, executions []*Execution) *Block {
+	return NewBlockWithVotes(chainID, height, prevBlockHash, transactions, transfers, executions, nil)
+}
+
+// NewBlockWithVotes returns a new block with transactions, transfers, executions and votes
+func NewBlockWithVotes(chainID uint32, height uint32, prevBlockHash cp.Hash32B, transactions []*Tx, transfers []*Transfer, executions []*Execution, votes []*Vote) *Block {
+	return NewBlockWithEvidences(chainID, height, prevBlockHash, transactions, transfers, executions, votes, nil)
+}
+
+// NewBlockWithEvidences returns a new block with transactions, transfers, executions, votes and evidences
+func NewBlockWithEvidences(chainID uint32, height uint32, prevBlockHash cp.Hash32B, transactions []*Tx, transfers []*Transfer, executions []*Execution, votes []*Vote, evidences []*Evidence) *Block {
+	return NewBlockWithDeposits(chainID, height, prevBlockHash, transactions, transfers, executions, votes, evidences, nil, nil)
+}
+
+// NewBlockWithDeposits returns a new block with transactions, transfers, executions, votes, evidences, deposits and
+// withdraws
+func NewBlockWithDeposits(chainID uint32, height uint32, prevBlockHash cp.Hash32B, transactions []*Tx, transfers []*Transfer, executions []*Execution, votes []*Vote, evidences []*Evidence, deposits []*Deposit, withdraws []*Withdraw) *Block {
+	return newBlock(chainID, height, prevBlockHash, transactions, transfers, executions, votes, evidences, deposits, withdraws, nil)
+}
+
+// newBlock returns a new block with the transactions, the built-in actions and the actions of the registered kinds
+func newBlock(chainID uint32, height uint32, prevBlockHash cp.Hash32B, transactions []*Tx, transfers []*Transfer, executions []*Execution, votes []*Vote, evidences []*Evidence, deposits []*Deposit, withdraws []*Withdraw, others []Action) *Block {
 	block := &Block{
-		Header: &BlockHeader{Version, chainID, height, uint64(time.Now().Unix()), prevBlockHash, cp.ZeroHash32B, uint32(len(transactions)), 0},
-		Tranxs: transactions,
+		Header:     &BlockHeader{Version, chainID, height, uint64(time.Now().Unix()), prevBlockHash, cp.ZeroHash32B, cp.ZeroHash32B, uint32(len(transactions)), 0, nil, nil},
+		Tranxs:     transactions,
+		Transfers:  transfers,
+		Executions: executions,
+		Votes:      votes,
+		Evidences:  evidences,
+		Deposits:   deposits,
+		Withdraws:  withdraws,
+	}
+	if len(others) > 0 {
+		block.OtherActions = others
 	}
 
 	block.Header.merkleRoot = block.MerkleRoot()
@@ -64,6 +135,11 @@ func (b *Block) Height() uint32 {
 	return b.Header.height
 }
 
+// Version returns the version of the block header, signaling the deployments it supports, see SignalsBit
+func (b *Block) Version() uint32 {
+	return b.Header.version
+}
+
 // TranxsSize returns the size of transactions in this block
 func (b *Block) TranxsSize() uint32 {
 	return b.Header.trnxDataSize
@@ -74,6 +150,43 @@ func (b *Block) PrevHash() cp.Hash32B {
 	return b.Header.prevBlockHash
 }
 
+// Timestamp returns the timestamp of this block
+func (b *Block) Timestamp() uint64 {
+	return b.Header.timestamp
+}
+
+// StateRoot returns the root of the UTXO pool and account states committed by this block
+func (b *Block) StateRoot() cp.Hash32B {
+	return b.Header.stateRoot
+}
+
+// ProposerPubKey returns the public key of the proposer who signed this block
+func (b *Block) ProposerPubKey() []byte {
+	return b.Header.pubkey
+}
+
+// SignBlock sets the proposer's public key and signs the block hash with the private key
+func (b *Block) SignBlock(pubkey, privkey []byte) {
+	b.Header.pubkey = pubkey
+	hash := b.HashBlock()
+	b.Header.blockSig = cp.Sign(privkey, hash[:])
+}
+
+// VerifySignature returns true if the block is signed by the private key of its proposer's public key
+func (b *Block) VerifySignature() bool {
+	if len(b.Header.pubkey) != ed25519.PublicKeySize || len(b.Header.blockSig) != ed25519.SignatureSize {
+		return false
+	}
+	hash := b.HashBlock()
+	return cp.Verify(b.Header.pubkey, hash[:], b.Header.blockSig)
+}
+
+// VerifyMerkleRoot returns true if the transactions, transfers, executions, votes, evidences, deposits and withdraws
+// of the block match the merkle root in its header
+func (b *Block) VerifyMerkleRoot() bool {
+	return b.MerkleRoot() == b.Header.merkleRoot
+}
+
 // ByteStream returns a byte stream of the block
 // used to calculate the block hash
 func (b *Block) ByteStream() []byte {
@@ -92,6 +205,7 @@ func (b *Block) ByteStream() []byte {
 
 	stream = append(stream, b.Header.prevBlockHash[:]...)
 	stream = append(stream, b.Header.merkleRoot[:]...)
+	stream = append(stream, b.Header.stateRoot[:]...)
 
 	cm.MachineEndian.PutUint32(temp, b.Header.trnxNumber)
 	stream = append(stream, temp...)
@@ -102,6 +216,9 @@ func (b *Block) ByteStream() []byte {
 	for _, tx := range b.Tranxs {
 		stream = append(stream, tx.ByteStream()...)
 	}
+	for _, act := range b.Actions() {
+		stream = append(stream, act.ByteStream()...)
+	}
 
 	return stream
 }
@@ -116,8 +233,11 @@ func (b *Block) ConvertToBlockHeaderPb() *iproto.BlockHeaderPb {
 	pbHeader.Timestamp = b.Header.timestamp
 	pbHeader.PrevBlockHash = b.Header.prevBlockHash[:]
 	pbHeader.MerkleRoot = b.Header.merkleRoot[:]
+	pbHeader.StateRoot = b.Header.stateRoot[:]
 	pbHeader.TrnxNumber = b.Header.trnxNumber
 	pbHeader.TrnxDataSize = b.Header.trnxDataSize
+	pbHeader.Pubkey = b.Header.pubkey
+	pbHeader.BlockSig = b.Header.blockSig
 
 	return &pbHeader
 }
@@ -128,8 +248,32 @@ func (b *Block) ConvertToBlockPb() *iproto.BlockPb {
 	for i, in := range b.Tranxs {
 		tx[i] = in.ConvertToTxPb()
 	}
+	var tsfs []*iproto.TransferPb
+	for _, tsf := range b.Transfers {
+		tsfs = append(tsfs, tsf.ConvertToTransferPb())
+	}
+	var execs []*iproto.ExecutionPb
+	for _, exec := range b.Executions {
+		execs = append(execs, exec.ConvertToExecutionPb())
+	}
+	var votes []*iproto.VotePb
+	for _, vote := range b.Votes {
+		votes = append(votes, vote.ConvertToVotePb())
+	}
+	var evidences []*iproto.EvidencePb
+	for _, evidence := range b.Evidences {
+		evidences = append(evidences, evidence.ConvertToEvidencePb())
+	}
+	var deposits []*iproto.DepositPb
+	for _, deposit := range b.Deposits {
+		deposits = append(deposits, deposit.ConvertToDepositPb())
+	}
+	var withdraws []*iproto.WithdrawPb
+	for _, withdraw := range b.Withdraws {
+		withdraws = append(withdraws, withdraw.ConvertToWithdrawPb())
+	}
 
-	return &iproto.BlockPb{b.ConvertToBlockHeaderPb(), tx}
+	return &iproto.BlockPb{b.ConvertToBlockHeaderPb(), tx, tsfs, execs, votes, evidences, deposits, withdraws, convertToActionPbs(b.OtherActions)}
 }
 
 // Serialize returns the serialized byte stream of the block
@@ -148,8 +292,11 @@ func (b *Block) ConvertFromBlockHeaderPb(pbBlock *iproto.BlockPb) {
 	b.Header.timestamp = pbBlock.GetHeader().GetTimestamp()
 	copy(b.Header.prevBlockHash[:], pbBlock.GetHeader().GetPrevBlockHash())
 	copy(b.Header.merkleRoot[:], pbBlock.GetHeader().GetMerkleRoot())
+	copy(b.Header.stateRoot[:], pbBlock.GetHeader().GetStateRoot())
 	b.Header.trnxNumber = pbBlock.GetHeader().GetTrnxNumber()
 	b.Header.trnxDataSize = pbBlock.GetHeader().GetTrnxDataSize()
+	b.Header.pubkey = pbBlock.GetHeader().GetPubkey()
+	b.Header.blockSig = pbBlock.GetHeader().GetBlockSig()
 }
 
 // ConvertFromBlockPb converts BlockPb to Block
@@ -162,12 +309,70 @@ func (b *Block) ConvertFromBlockPb(pbBlock *iproto.BlockPb) {
 		b.Tranxs[i] = &Tx{}
 		b.Tranxs[i].ConvertFromTxPb(tx)
 	}
+
+	b.Transfers = nil
+	for _, tsf := range pbBlock.Transfers {
+		transfer := &Transfer{}
+		transfer.ConvertFromTransferPb(tsf)
+		b.Transfers = append(b.Transfers, transfer)
+	}
+
+	b.Executions = nil
+	for _, exec := range pbBlock.Executions {
+		execution := &Execution{}
+		execution.ConvertFromExecutionPb(exec)
+		b.Executions = append(b.Executions, execution)
+	}
+
+	b.Votes = nil
+	for _, pbVote := range pbBlock.Votes {
+		vote := &Vote{}
+		vote.ConvertFromVotePb(pbVote)
+		b.Votes = append(b.Votes, vote)
+	}
+
+	b.Evidences = nil
+	for _, pbEvidence := range pbBlock.Evidences {
+		evidence := &Evidence{}
+		evidence.ConvertFromEvidencePb(pbEvidence)
+		b.Evidences = append(b.Evidences, evidence)
+	}
+
+	b.Deposits = nil
+	for _, pbDeposit := range pbBlock.Deposits {
+		deposit := &Deposit{}
+		deposit.ConvertFromDepositPb(pbDeposit)
+		b.Deposits = append(b.Deposits, deposit)
+	}
+
+	b.Withdraws = nil
+	for _, pbWithdraw := range pbBlock.Withdraws {
+		withdraw := &Withdraw{}
+		withdraw.ConvertFromWithdrawPb(pbWithdraw)
+		b.Withdraws = append(b.Withdraws, withdraw)
+	}
+
+	// the actions are checked to be of registered kinds by CheckBlockPb when the block is deserialized or received
+	b.OtherActions = nil
+	for _, pbAction := range pbBlock.Actions {
+		if act, err := DeserializeAction(pbAction.GetKind(), pbAction.GetPayload()); err == nil {
+			b.OtherActions = append(b.OtherActions, act)
+		}
+	}
 }
 
 // Deserialize parse the byte stream into Block
+// The byte stream is untrusted, so it has to fit in MaxBlockMsgSize and its counts and sizes have to match the content
+// they describe, otherwise ErrMsgTooLarge or ErrMalformedBlock is returned.
 func (b *Block) Deserialize(buf []byte) error {
+	if len(buf) > MaxBlockMsgSize {
+		return errors.Wrapf(ErrMsgTooLarge, "Block of %d bytes exceeds the limit of %d", len(buf), MaxBlockMsgSize)
+	}
 	pbBlock := iproto.BlockPb{}
 	if err := proto.Unmarshal(buf, &pbBlock); err != nil {
+		return errors.Wrapf(ErrMalformedBlock, "Cannot unmarshal block: %v", err)
+	}
+	if err := CheckBlockPb(&pbBlock); err != nil {
 		return err
 	}
 
@@ -182,18 +387,69 @@ func (b *Block) Deserialize(buf []byte) error {
 	return nil
 }
 
+// CheckBlockPb returns ErrMalformedBlock if the block has no header, its transaction count or one of its
+// transactions does not match the content, or one of its actions is not of a registered kind
+func CheckBlockPb(pbBlock *iproto.BlockPb) error {
+	if pbBlock.GetHeader() == nil {
+		return errors.Wrap(ErrMalformedBlock, "Block has no header")
+	}
+	if n := pbBlock.GetHeader().GetTrnxNumber(); int(n) != len(pbBlock.GetTransactions()) {
+		return errors.Wrapf(ErrMalformedBlock, "Block has %d transactions, header counts %d", len(pbBlock.GetTransactions()), n)
+	}
+	for i, pbTx := range pbBlock.GetTransactions() {
+		if err := CheckTxPb(pbTx); err != nil {
+			return errors.Wrapf(ErrMalformedBlock, "Tx %d: %v", i, err)
+		}
+	}
+	for i, pbAction := range pbBlock.GetActions() {
+		if _, err := DeserializeAction(pbAction.GetKind(), pbAction.GetPayload()); err != nil {
+			return errors.Wrapf(ErrMalformedBlock, "Action %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
 // MerkleRoot returns the Merkle root of this block.
 func (b *Block) MerkleRoot() cp.Hash32B {
-	// create hash list of all trnx
-	var txHash []cp.Hash32B
+	return cp.NewMerkleTree(b.leafHashes()).HashTree()
+}
+
+// leafHashes returns the hashes of all trnx followed by the ones of all actions in the order they are executed, which
+// the merkle tree is built on
+func (b *Block) leafHashes() []cp.Hash32B {
+	var hashes []cp.Hash32B
 	for _, tx := range b.Tranxs {
-		txHash = append(txHash, tx.Hash())
+		hashes = append(hashes, tx.Hash())
+	}
+	for _, act := range b.Actions() {
+		hashes = append(hashes, act.Hash())
+	}
+	return hashes
+}
+
+// MerkleProof returns the proof of the inclusion of the transaction or action in the block
+func (b *Block) MerkleProof(txHash cp.Hash32B) (*cp.MerkleProof, error) {
+	hashes := b.leafHashes()
+	index := -1
+	for i, hash := range hashes {
+		if hash == txHash {
+			index = i
+			break
+		}
 	}
+	if index < 0 {
+		return nil, errors.New("Transaction is not in the block")
+	}
+	return cp.NewMerkleTree(hashes).Proof(uint32(index)), nil
+}
 
-	return cp.NewMerkleTree(txHash).HashTree()
+// VerifyMerkleProof checks the transaction is included in the block of the given header, the block body is not needed
+func VerifyMerkleProof(txHash cp.Hash32B, proof *cp.MerkleProof, header *Block) bool {
+	return cp.VerifyMerkleProof(txHash, proof, header.Header.merkleRoot)
 }
 
 // HashBlock return the hash of this block (actually hash of block header)
+// the proposer's signature is not part of the hash since it signs the hash
 func (b *Block) HashBlock() cp.Hash32B {
 	stream := make([]byte, 4)
 	cm.MachineEndian.PutUint32(stream, b.Header.version)
@@ -207,10 +463,12 @@ func (b *Block) HashBlock() cp.Hash32B {
 	stream = append(stream, tmp8B...)
 	stream = append(stream, b.Header.prevBlockHash[:]...)
 	stream = append(stream, b.Header.merkleRoot[:]...)
+	stream = append(stream, b.Header.stateRoot[:]...)
 	cm.MachineEndian.PutUint32(tmp4B, b.Header.trnxNumber)
 	stream = append(stream, tmp4B...)
 	cm.MachineEndian.PutUint32(tmp4B, b.Header.trnxDataSize)
 	stream = append(stream, tmp4B...)
+	stream = append(stream, b.Header.pubkey...)
 
 	hash := blake2b.Sum256(stream)
 	hash = blake2b.Sum256(hash[:])
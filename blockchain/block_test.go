@@ -11,10 +11,13 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/blake2b"
 
 	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
 	ta "github.com/iotexproject/iotex-core/test/testaddress"
 )
 
@@ -99,5 +102,73 @@ func TestMerkle(t *testing.T) {
 	assert.Equal(hash07[:], hash[:])
 	t.Log("Merkle root match pass\n")
 
+	// verify proofs against the header only
+	pbHeader := &iproto.BlockPb{Header: block.ConvertToBlockHeaderPb()}
+	header := Block{}
+	header.ConvertFromBlockHeaderPb(pbHeader)
+	for _, tx := range block.Tranxs {
+		proof, err := block.MerkleProof(tx.Hash())
+		assert.Nil(err)
+		assert.True(VerifyMerkleProof(tx.Hash(), proof, &header))
+	}
+	proof, err := block.MerkleProof(cbtx2.Hash())
+	assert.Nil(err)
+	assert.Equal([]cp.Hash32B{byteToHash(hash3), hash01, hash47}, proof.Siblings)
+	assert.False(VerifyMerkleProof(cbtx3.Hash(), proof, &header))
+	_, err = block.MerkleProof(cp.ZeroHash32B)
+	assert.NotNil(err)
+
 	// serialize
 }
+
+func TestSignBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	cbtx := NewCoinbaseTx(ta.Addrinfo["miner"].Address, 10, GenesisCoinbaseData)
+	block := NewBlock(0, 1, cp.ZeroHash32B, []*Tx{cbtx})
+	assert.False(block.VerifySignature())
+
+	block.SignBlock(ta.Addrinfo["miner"].PublicKey, ta.Addrinfo["miner"].PrivateKey)
+	assert.True(block.VerifySignature())
+	assert.Equal(ta.Addrinfo["miner"].PublicKey, block.ProposerPubKey())
+
+	// signature survives serialization
+	buf, err := block.Serialize()
+	assert.Nil(err)
+	newblk := Block{}
+	assert.Nil(newblk.Deserialize(buf))
+	assert.Equal(block.HashBlock(), newblk.HashBlock())
+	assert.True(newblk.VerifySignature())
+
+	// the proposer key is covered by the block hash
+	newblk.Header.pubkey = ta.Addrinfo["alfa"].PublicKey
+	assert.NotEqual(block.HashBlock(), newblk.HashBlock())
+	assert.False(newblk.VerifySignature())
+}
+
+func TestDeserializeMalformedBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	cbtx := NewCoinbaseTx(ta.Addrinfo["miner"].Address, 10, GenesisCoinbaseData)
+	block := NewBlock(0, 1, cp.ZeroHash32B, []*Tx{cbtx})
+	deserialize := func(pbBlock *iproto.BlockPb) error {
+		buf, err := proto.Marshal(pbBlock)
+		assert.Nil(err)
+		return (&Block{}).Deserialize(buf)
+	}
+	assert.Nil(deserialize(block.ConvertToBlockPb()))
+
+	assert.Equal(ErrMsgTooLarge, errors.Cause((&Block{}).Deserialize(make([]byte, MaxBlockMsgSize+1))))
+	assert.Equal(ErrMalformedBlock, errors.Cause((&Block{}).Deserialize([]byte{0xff, 0xff, 0xff})))
+	assert.Equal(ErrMalformedBlock, errors.Cause(deserialize(&iproto.BlockPb{})))
+
+	// the header counts more transactions than the block has
+	pbBlock := block.ConvertToBlockPb()
+	pbBlock.Header.TrnxNumber = 1000000
+	assert.Equal(ErrMalformedBlock, errors.Cause(deserialize(pbBlock)))
+
+	// a transaction of the block is malformed
+	pbBlock = block.ConvertToBlockPb()
+	pbBlock.Transactions[0].TxIn[0].UnlockScriptSize = 1 << 30
+	assert.Equal(ErrMalformedBlock, errors.Cause(deserialize(pbBlock)))
+}
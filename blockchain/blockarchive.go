@@ -0,0 +1,350 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cm "github.com/iotexproject/iotex-core/common"
+)
+
+const (
+	// DefaultBlockArchiveDir is the directory of the block archive if the config leaves it empty
+	DefaultBlockArchiveDir = "blocks"
+	// DefaultArchiveSegmentSize is the number of heights a segment of the block archive covers if the config leaves
+	// it 0
+	DefaultArchiveSegmentSize = uint32(10000)
+)
+
+// BlockArchive keeps blocks in segment files of a directory, the segment n covering the heights from n times the
+// segment size on. Each segment is a chain archive of contiguous blocks, so it can be imported on its own by
+// ImportChain. The blocks are streamed to and from the segments one at a time, so neither the archived range nor the
+// index of a segment is ever held in memory.
+type BlockArchive struct {
+	dir         string
+	segmentSize uint32
+}
+
+// NewBlockArchive creates the block archive in the directory, which is created once blocks are written to it
+func NewBlockArchive(dir string, segmentSize uint32) *BlockArchive {
+	if dir == "" {
+		dir = DefaultBlockArchiveDir
+	}
+	if segmentSize == 0 {
+		segmentSize = DefaultArchiveSegmentSize
+	}
+	return &BlockArchive{dir: dir, segmentSize: segmentSize}
+}
+
+// Write stores the blocks of the chain archive read from r, replacing the archived blocks at the same heights
+// The blocks of a segment are written to a temporary file, along with the archived blocks of the segment before and
+// after them, which replaces the segment once complete, so an interrupted write leaves the segment as it was. The
+// blocks have to extend the archived blocks of the segment without a gap. Writing stops with the error of ctx once it
+// is done, keeping the segments completed so far.
+func (a *BlockArchive) Write(ctx context.Context, r io.Reader) (err error) {
+	chainID, start, end, err := readArchiveHeader(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(a.dir, 0700); err != nil {
+		return err
+	}
+
+	var seg *segmentWriter
+	defer func() {
+		if err != nil && seg != nil {
+			seg.abort()
+		}
+	}()
+	for height := start; ; height++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := readArchiveData(r)
+		if err != nil {
+			return errors.Wrapf(err, "block %d", height)
+		}
+		blk, err := deserializeArchiveData(data)
+		if err != nil {
+			return errors.Wrapf(err, "block %d", height)
+		}
+		if blk.Height() != height {
+			return errors.Wrapf(ErrInvalidArchive, "Wrong block height %d, expecting %d", blk.Height(), height)
+		}
+		if seg == nil || height/a.segmentSize != seg.segment {
+			if seg != nil {
+				err := seg.commit()
+				seg = nil
+				if err != nil {
+					return err
+				}
+			}
+			last := end
+			if next := (height/a.segmentSize + 1) * a.segmentSize; next != 0 && last >= next {
+				last = next - 1
+			}
+			if seg, err = a.openSegment(chainID, height, last); err != nil {
+				return err
+			}
+		}
+		if err := writeArchiveData(seg.buf, data); err != nil {
+			return err
+		}
+		if height == end {
+			err := seg.commit()
+			seg = nil
+			return err
+		}
+	}
+}
+
+// ReadBlock returns the archived block at the height
+func (a *BlockArchive) ReadBlock(height uint32) (*Block, error) {
+	var blk *Block
+	err := a.scan(context.Background(), height, height, func(_ uint32, data []byte) error {
+		var err error
+		blk, err = deserializeArchiveData(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
+
+// ReadBlocks calls fn on the archived blocks with height in [start, end] in order, until ctx is done
+func (a *BlockArchive) ReadBlocks(ctx context.Context, start uint32, end uint32, fn func(blk *Block) error) error {
+	return a.scan(ctx, start, end, func(_ uint32, data []byte) error {
+		blk, err := deserializeArchiveData(data)
+		if err != nil {
+			return err
+		}
+		return fn(blk)
+	})
+}
+
+// ReadTo writes the archived blocks with height in [start, end] to w in the chain archive format, until ctx is done
+func (a *BlockArchive) ReadTo(ctx context.Context, w io.Writer, start uint32, end uint32) error {
+	header := false
+	return a.scan(ctx, start, end, func(chainID uint32, data []byte) error {
+		if !header {
+			if err := writeArchiveHeader(w, chainID, start, end); err != nil {
+				return err
+			}
+			header = true
+		}
+		return writeArchiveData(w, data)
+	})
+}
+
+// scan calls fn with the chain ID and the serialized block of the heights in [start, end] in order, opening the
+// segment of each height in turn and seeking past the blocks before the range
+func (a *BlockArchive) scan(ctx context.Context, start uint32, end uint32, fn func(chainID uint32, data []byte) error) error {
+	if start > end {
+		return errors.Errorf("Invalid block range [%d, %d]", start, end)
+	}
+	for height := start; ; {
+		segment := height / a.segmentSize
+		file, err := os.Open(a.segmentPath(segment))
+		if os.IsNotExist(err) {
+			return errors.Wrapf(blockdb.ErrNotExist, "Block %d is not archived", height)
+		}
+		if err != nil {
+			return err
+		}
+		last, err := a.scanSegment(ctx, file, height, end, fn)
+		file.Close()
+		if err != nil {
+			return errors.Wrapf(err, "segment %d", segment)
+		}
+		if last == end {
+			return nil
+		}
+		height = last + 1
+	}
+}
+
+// scanSegment calls fn on the blocks of the segment with height in [start, end], returning the height of the last one
+func (a *BlockArchive) scanSegment(ctx context.Context, file *os.File, start uint32, end uint32, fn func(chainID uint32, data []byte) error) (uint32, error) {
+	chainID, first, last, err := readArchiveHeader(file)
+	if err != nil {
+		return 0, err
+	}
+	if start < first || start > last {
+		return 0, errors.Wrapf(blockdb.ErrNotExist, "Block %d is not archived, segment has [%d, %d]", start, first, last)
+	}
+	// skip the records before the range by their size
+	prefix := make([]byte, 8)
+	for height := first; height < start; height++ {
+		if _, err := io.ReadFull(file, prefix); err != nil {
+			return 0, errors.Wrapf(ErrInvalidArchive, "Cannot read record: %v", err)
+		}
+		size := cm.MachineEndian.Uint32(prefix)
+		if size > MaxBlockMsgSize {
+			return 0, errors.Wrapf(ErrInvalidArchive, "Record size %d exceeds the limit of %d", size, MaxBlockMsgSize)
+		}
+		if _, err := file.Seek(int64(size), io.SeekCurrent); err != nil {
+			return 0, err
+		}
+	}
+	if last > end {
+		last = end
+	}
+	r := bufio.NewReader(file)
+	for height := start; height <= last; height++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		data, err := readArchiveData(r)
+		if err != nil {
+			return 0, errors.Wrapf(err, "block %d", height)
+		}
+		if err := fn(chainID, data); err != nil {
+			return 0, err
+		}
+	}
+	return last, nil
+}
+
+// segmentPath returns the path of the segment file
+func (a *BlockArchive) segmentPath(segment uint32) string {
+	return filepath.Join(a.dir, fmt.Sprintf("blocks-%010d.dat", segment))
+}
+
+// segmentWriter rewrites a segment with the blocks with height in [start, end], keeping the archived blocks of the
+// segment out of the range
+type segmentWriter struct {
+	segment uint32
+	path    string
+	start   uint32
+	end     uint32
+	tmp     *os.File
+	buf     *bufio.Writer
+	// old is the archived segment, whose blocks have height in [oldStart, oldEnd], nil if there is none
+	old      *bufio.Reader
+	oldFile  *os.File
+	oldStart uint32
+	oldEnd   uint32
+}
+
+// openSegment starts rewriting the segment of the heights in [start, end], copying its archived blocks before start
+func (a *BlockArchive) openSegment(chainID uint32, start uint32, end uint32) (*segmentWriter, error) {
+	seg := &segmentWriter{segment: start / a.segmentSize, path: a.segmentPath(start / a.segmentSize), start: start, end: end}
+	first, last := start, end
+	oldFile, err := os.Open(seg.path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		seg.oldFile = oldFile
+		seg.old = bufio.NewReader(oldFile)
+		var oldChainID uint32
+		oldChainID, seg.oldStart, seg.oldEnd, err = readArchiveHeader(seg.old)
+		if err != nil {
+			seg.abort()
+			return nil, errors.Wrapf(err, "segment %d", seg.segment)
+		}
+		if oldChainID != chainID {
+			seg.abort()
+			return nil, errors.Wrapf(ErrInvalidArchive, "Wrong chain ID %d, segment %d has %d", chainID, seg.segment, oldChainID)
+		}
+		if seg.oldStart < start && seg.oldEnd+1 < start || seg.oldEnd > end && seg.oldStart > end+1 {
+			seg.abort()
+			return nil, errors.Errorf(
+				"Blocks [%d, %d] leave a gap to the archived blocks [%d, %d] of segment %d",
+				start, end, seg.oldStart, seg.oldEnd, seg.segment,
+			)
+		}
+		if seg.oldStart < first {
+			first = seg.oldStart
+		}
+		if seg.oldEnd > last {
+			last = seg.oldEnd
+		}
+	}
+
+	if seg.tmp, err = os.OpenFile(seg.path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil {
+		seg.abort()
+		return nil, err
+	}
+	seg.buf = bufio.NewWriter(seg.tmp)
+	if err := writeArchiveHeader(seg.buf, chainID, first, last); err != nil {
+		seg.abort()
+		return nil, err
+	}
+	if seg.old != nil {
+		for height := seg.oldStart; height < start && height <= seg.oldEnd; height++ {
+			if err := seg.copyOld(true); err != nil {
+				seg.abort()
+				return nil, err
+			}
+		}
+	}
+	return seg, nil
+}
+
+// copyOld reads the next archived block of the segment and copies it to the new segment if keep is true
+func (seg *segmentWriter) copyOld(keep bool) error {
+	data, err := readArchiveData(seg.old)
+	if err != nil {
+		return errors.Wrapf(err, "segment %d", seg.segment)
+	}
+	if !keep {
+		return nil
+	}
+	return writeArchiveData(seg.buf, data)
+}
+
+// commit copies the archived blocks of the segment after the range and replaces the segment with the new one
+func (seg *segmentWriter) commit() error {
+	if seg.old != nil {
+		height := seg.oldStart
+		if height < seg.start {
+			height = seg.start
+		}
+		for ; height <= seg.oldEnd; height++ {
+			if err := seg.copyOld(height > seg.end); err != nil {
+				seg.abort()
+				return err
+			}
+		}
+		seg.oldFile.Close()
+	}
+	if err := seg.buf.Flush(); err != nil {
+		seg.abort()
+		return err
+	}
+	if err := seg.tmp.Sync(); err != nil {
+		seg.abort()
+		return err
+	}
+	if err := seg.tmp.Close(); err != nil {
+		os.Remove(seg.tmp.Name())
+		return err
+	}
+	return os.Rename(seg.tmp.Name(), seg.path)
+}
+
+// abort discards the new segment, leaving the archived one as it was
+func (seg *segmentWriter) abort() {
+	if seg.oldFile != nil {
+		seg.oldFile.Close()
+	}
+	if seg.tmp != nil {
+		seg.tmp.Close()
+		os.Remove(seg.tmp.Name())
+	}
+}
@@ -7,53 +7,167 @@
 package blockchain
 
 import (
+	"bytes"
+	"io"
 	"math"
-	"os"
+	"sync"
+	"time"
 
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/net/context"
 
 	"github.com/iotexproject/iotex-core/blockdb"
-	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/routine"
 	"github.com/iotexproject/iotex-core/config"
 	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/election"
 	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/metrics"
 	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
 	"github.com/iotexproject/iotex-core/txvm"
+	"github.com/iotexproject/iotex-core/wallet"
 )
 
 const (
 	// GenesisCoinbaseData is the text in genesis block
 	GenesisCoinbaseData = "The Times 03/Jan/2009 Chancellor on brink of second bailout for banks"
+
+	// blockBatchSize is the number of blocks read from Db at a time when scanning a range of the chain
+	blockBatchSize = 64
 )
 
 var (
 	// ErrInvalidBlock is the error returned when the block is not vliad
 	ErrInvalidBlock = errors.New("failed to validate the block")
+	// ErrInsufficientFunds is the error returned when an address does not have enough balance to pay
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrSigningFailed is the error returned when a transaction input cannot be signed
+	ErrSigningFailed = errors.New("failed to sign the transaction")
+	// ErrDBOpen is the error returned when the blockchain DB cannot be opened
+	ErrDBOpen = errors.New("failed to open the blockchain DB")
+	// ErrBlockPruned is the error returned when the requested block body has been pruned
+	ErrBlockPruned = errors.New("block has been pruned")
+	// ErrSupplyInvariant is the error returned when the UTXO pool does not add up to the emitted supply
+	ErrSupplyInvariant = errors.New("total supply invariant is broken")
+	// ErrStopped is the error returned when adding a block to a blockchain which has been stopped
+	ErrStopped = errors.New("blockchain is stopped")
 )
 
+// log is the logger of the package, which a Blockchain uses unless another one is injected
+var log = logger.New("blockchain")
+
 // Blockchain implements the IBlockchain interface
 type Blockchain struct {
+	log     *logger.Logger
 	blockDb *blockdb.BlockDB
 	config  *config.Config
+	genesis *config.Genesis // nil if the chain is not bootstrapped from a genesis file
 	chainID uint32
 	height  uint32
 	tip     cp.Hash32B
-	Utk     *UtxoTracker // tracks the current UTXO pool
+	Utk     *UtxoTracker   // tracks the current UTXO pool
+	sf      *state.Factory // tracks the account-based state
+	epochs  *EpochManager
+	events  *eventHub
+
+	consensus Consensus
+	// txOrder orders the transactions packed into the minted blocks
+	txOrder TxOrderPolicy
+	// fees tracks the fee rates of the recent blocks, and mempool holds the backlog the fee estimation accounts for
+	fees    *feeEstimator
+	mempool Mempool
+
+	// checkpoints are the hashes pinned by the checkpoints of the config by height, see checkpointAt
+	checkpoints map[uint32]cp.Hash32B
+
+	// pruneHeight is the height below which the block bodies have been pruned
+	pruneHeight uint32
+	// finalHeight is the height of the highest block finalized, at or below which the chain is not reorganized
+	finalHeight uint32
+	// reorg tracks the fork halting the chain for being deeper than the max reorg depth, and the forks allowed anyway
+	reorg reorgGuard
+
+	// blockCache keeps the recently read blocks by hash, and hashCache the hashes of the recently read heights
+	blockCache *lruCache
+	hashCache  *lruCache
+
+	// archive keeps the blocks stored by StoreBlock
+	archive *BlockArchive
+
+	// commitMu serializes the commits, so stopping waits for the one in flight, after which stopped rejects them
+	commitMu sync.Mutex
+	stopped  bool
+
+	// stats are the statistics of the chain as of the tip
+	stats chainStats
+
+	// peers are the chains whose deposits can be withdrawn on the chain by ID, see SetPeerChain
+	peers map[uint32]IBlockchain
+
+	// deployments are the states of the soft-fork deployments signaled by the header versions
+	deployments *deploymentTracker
+
+	// now is the clock the timestamps of the minted blocks are taken from
+	now func() time.Time
+
+	// compactor compacts the UTXO pool in the background if the compaction interval is set
+	compactor *routine.RecurringTask
+	// storage offloads the oldest block bodies in the background if the storage budget is set
+	storage *routine.RecurringTask
 }
 
 // NewBlockchain creates a new blockchain instance
 func NewBlockchain(db *blockdb.BlockDB, cfg *config.Config) *Blockchain {
 	chain := &Blockchain{
+		log:     log,
 		blockDb: db,
 		config:  cfg,
-		Utk:     NewUtxoTracker()}
+		Utk:     NewUtxoTracker(),
+		sf:      state.NewFactory(),
+		epochs:  NewEpochManager(&cfg.Chain),
+		events:  newEventHub(),
+		txOrder: NewTxOrderPolicy(cfg.Chain.TxOrder),
+		fees:    &feeEstimator{},
+
+		deployments: newDeploymentTracker(&cfg.Chain),
+		checkpoints: parseCheckpoints(cfg.Chain.Checkpoints),
+
+		blockCache: newLRUCache(cfg.Chain.BlockCacheSize),
+		hashCache:  newLRUCache(cfg.Chain.HashCacheSize),
+
+		archive: NewBlockArchive(cfg.Chain.BlockArchiveDir, cfg.Chain.BlockArchiveSegmentSize),
+		now:     time.Now,
+	}
+	chain.Utk.SetCoinbaseMaturity(cfg.Chain.CoinbaseMaturity)
+	chain.Utk.SetVerifyWorkers(cfg.Chain.VerifyWorkers)
+	chain.Utk.SetScriptCacheSize(cfg.Chain.ScriptCacheSize)
 	return chain
 }
 
-// Init initializes the blockchain
-func (bc *Blockchain) Init() error {
+// SetLogger injects the logger of the blockchain, e.g., one with the fields identifying the node
+func (bc *Blockchain) SetLogger(l *logger.Logger) {
+	bc.log = l
+	bc.events.log = l
+}
+
+// SetTxOrderPolicy sets the policy ordering the transactions packed into the minted blocks, replacing the one of the
+// config
+func (bc *Blockchain) SetTxOrderPolicy(policy TxOrderPolicy) {
+	bc.txOrder = policy
+}
+
+// SetClock sets the clock the timestamps of the minted blocks are taken from, e.g., the fake clock of a simulation
+func (bc *Blockchain) SetClock(now func() time.Time) {
+	bc.now = now
+}
+
+// Init initializes the blockchain, rebuilding the UTXO pool from the blocks if it is not persisted up to the tip
+// The rebuild is aborted with the error of ctx once it is done.
+func (bc *Blockchain) Init(ctx context.Context) error {
 	tip, height, err := bc.blockDb.Init()
 	if err != nil {
 		return err
@@ -61,52 +175,389 @@ func (bc *Blockchain) Init() error {
 
 	copy(bc.tip[:], tip)
 	bc.height = height
+	if bc.pruneHeight, err = bc.blockDb.GetPruneHeight(); err != nil {
+		return err
+	}
+	if bc.finalHeight, err = bc.blockDb.GetFinalHeight(); err != nil {
+		return err
+	}
+
+	if err := bc.initUtxoCache(); err != nil {
+		return err
+	}
+	if err := bc.initStorage(); err != nil {
+		return err
+	}
 
-	// build UTXO pool
+	// resume the reindex interrupted, which leaves the indexes partially rebuilt
+	if _, err := bc.blockDb.GetReindexNext(); err == nil {
+		bc.log.WithField("height", bc.height).Warning("Resuming the reindex interrupted")
+		if err := bc.Reindex(ctx); err != nil {
+			return err
+		}
+		bc.updateMetrics()
+		return nil
+	}
+
+	// load UTXO pool, account states and contracts persisted along with the blocks
+	err = bc.loadUtxoPool(bc.height)
+	if err == nil {
+		err = bc.loadAccounts()
+	}
+	if err == nil {
+		err = bc.loadContracts()
+	}
+	if err == nil {
+		bc.updateMetrics()
+		return bc.loadStats(ctx)
+	}
+	bc.log.WithFields(logger.Fields{"height": bc.height, "err": err}).Warning("Rebuilding UTXO pool from blocks")
+
+	// build UTXO pool, account states and contracts
 	// Genesis block has height 0
+	bc.Utk.clearPool()
+	bc.sf.Clear()
+	commitments := []cp.Hash32B{}
 	for i := uint32(0); i <= bc.height; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		blk, err := bc.GetBlockByHeight(i)
 		if err != nil {
 			return err
 		}
-		bc.Utk.UpdateUtxoPool(blk)
+		if err := bc.Utk.UpdateUtxoPool(blk, bc.emission(i)); err != nil {
+			return errors.Wrapf(ErrSupplyInvariant, "%v", err)
+		}
+		commitments = append(commitments, bc.Utk.Commitment())
+		ws, _, err := bc.executeBlock(blk)
+		if err != nil {
+			return errors.Wrapf(err, "Executing block %d", i)
+		}
+		bc.sf.Apply(ws)
 	}
-	return nil
+	bc.updateMetrics()
+	if bc.blockDb.IsReadOnly() {
+		return bc.loadStats(ctx)
+	}
+
+	// persist the rebuilt UTXO pool so next startup can load it directly
+	batch := blockdb.NewBatch()
+	batch.ClearUtxo()
+	if err := putUtxoPool(batch, bc.Utk); err != nil {
+		return err
+	}
+	batch.ClearAccounts()
+	for addr, acct := range bc.sf.Accounts() {
+		batch.PutAccount([]byte(addr), acct.Serialize())
+	}
+	batch.ClearContracts()
+	for addr, code := range bc.sf.Codes() {
+		batch.PutCode([]byte(addr), code)
+	}
+	for addr, storage := range bc.sf.States() {
+		for key, value := range storage {
+			batch.PutState([]byte(addr), []byte(key), value)
+		}
+	}
+	for height, commitment := range commitments {
+		batch.PutUtxoCommitment(uint32(height), commitment[:])
+	}
+	batch.PutUtxoHeight(bc.height)
+	batch.PutSupply(bc.Utk.emitted, bc.Utk.burned)
+	if err := bc.blockDb.Commit(batch); err != nil {
+		return err
+	}
+	return bc.loadStats(ctx)
 }
 
-// Close closes the Db connection
-func (bc *Blockchain) Close() error {
-	return bc.blockDb.Close()
+// loadUtxoPool loads the UTXO pool from Db, it fails if the UTXO in Db is not updated to the height or does not add
+// up to the emitted supply
+func (bc *Blockchain) loadUtxoPool(height uint32) error {
+	utxos, utxoHeight, err := bc.blockDb.Utxos()
+	if err != nil {
+		return err
+	}
+	if utxoHeight != height {
+		return errors.Errorf("UTXO height %d does not match height %d", utxoHeight, height)
+	}
+
+	emitted, burned, err := bc.blockDb.Supply()
+	if err != nil {
+		return err
+	}
+
+	bc.Utk.clearPool()
+	for _, buf := range utxos {
+		if err := bc.Utk.loadUtxoEntry(buf); err != nil {
+			return err
+		}
+	}
+	bc.Utk.setSupply(emitted, burned)
+	return bc.Utk.verifySupply()
 }
 
-// commitBlock commits Block to Db
-func (bc *Blockchain) commitBlock(blk *Block) (err error) {
-	// post-commit actions
-	defer func() {
-		// update tip hash and height
-		if r := recover(); r != nil {
-			return
+// putUtxo adds the UTXO entries to the batch, a nil entry is deleted
+// coinbase holds the minting height of the coinbase entries
+func putUtxo(batch *blockdb.Batch, utxos map[cp.Hash32B][]*TxOutput, coinbase map[cp.Hash32B]uint32) error {
+	for hash, utxo := range utxos {
+		key := hash
+		if utxo == nil {
+			batch.DeleteUtxo(key[:])
+			continue
+		}
+		buf, err := serializeUtxoEntry(key, utxo, coinbase)
+		if err != nil {
+			return err
 		}
+		batch.PutUtxo(key[:], buf)
+	}
+	return nil
+}
 
-		// update tip hash/height
-		bc.tip = blk.HashBlock()
-		bc.height = blk.Header.height
+// putUtxoPool adds all entries of the UTXO pool of the tracker to the batch
+func putUtxoPool(batch *blockdb.Batch, tk *UtxoTracker) error {
+	var err error
+	tk.utxoPool.forEach(func(hash cp.Hash32B, utxo []*TxOutput) bool {
+		var buf []byte
+		if buf, err = serializeUtxoEntry(hash, utxo, tk.coinbaseHeights); err != nil {
+			return false
+		}
+		batch.PutUtxo(hash[:], buf)
+		return true
+	})
+	return err
+}
 
-		// update UTXO pool
-		bc.Utk.UpdateUtxoPool(blk)
-	}()
+// Start starts accepting blocks, which a blockchain does once created, and fails if it has been stopped as its Db is
+// closed
+func (bc *Blockchain) Start() error {
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	if bc.stopped {
+		return ErrStopped
+	}
+	return nil
+}
+
+// Stop waits for the commit in flight, rejects the later ones with ErrStopped, flushes the blocks and the UTXO to
+// disk and closes the Db connection
+// The subscriptions to block events are closed as no more block is committed. Stopping twice is a no-op.
+func (bc *Blockchain) Stop() error {
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	if bc.stopped {
+		return nil
+	}
+	bc.stopped = true
+	bc.events.close()
+	if bc.compactor != nil {
+		bc.compactor.Stop()
+	}
+	if bc.storage != nil {
+		bc.storage.Stop()
+	}
+
+	err := bc.blockDb.Sync()
+	if err != nil {
+		bc.log.WithField("err", err).Error("Cannot flush Blockchain Db")
+	}
+	if closeErr := bc.blockDb.Close(); err == nil {
+		err = closeErr
+	}
+	bc.log.WithField("height", bc.height).Info("Stopped blockchain")
+	return err
+}
+
+// Close stops the blockchain and closes the Db connection, see Stop
+func (bc *Blockchain) Close() error {
+	return bc.Stop()
+}
+
+// commitBlock commits Block to Db, failing with ErrStopped once the blockchain is stopped
+// the block, its hash/height and tx indexes and the UTXO changes are written to Db in a single batch, and the
+// in-memory tip and UTXO pool are only updated after the batch is committed, so a failed commit leaves the
+// blockchain untouched
+// The UTXO changes are the ones of the view the block has been validated against, or of a new view of the UTXO pool
+// if view is nil. The stages of the commit are added to the trace of processing the block.
+func (bc *Blockchain) commitBlock(blk *Block, view *UtxoView, trace *metrics.Trace) error {
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	if bc.stopped {
+		return errors.Wrapf(ErrStopped, "Cannot commit block %d", blk.Header.height)
+	}
+
+	start := time.Now()
+	// the view validated along with the block is stale if another block has been committed since
+	if view == nil || blk.Header.prevBlockHash != bc.tip {
+		view = bc.Utk.NewView()
+		// refuse to persist a block which would make the UTXO pool not add up to the emitted supply
+		if err := view.ConnectBlock(blk, bc.emission(blk.Header.height)); err != nil {
+			return err
+		}
+	}
+	// the account states, contracts and receipts are updated under the same commit as the UTXO
+	ws, receipts, err := bc.executeBlock(blk)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidBlock, "%v", err)
+	}
+	trace.Span("execute", executeLatency)
 
 	// serialize the block
 	serialized, err := blk.Serialize()
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	hash := blk.HashBlock()
-	if err = bc.blockDb.CheckInBlock(serialized, hash[:], blk.Header.height); err != nil {
-		panic(err)
+	batch := blockdb.NewBatch()
+	batch.PutBlock(serialized, hash[:], blk.Header.height)
+	if err := putBlockMeta(batch, newBlockMeta(blk, hash, len(serialized))); err != nil {
+		return err
+	}
+	putIndexes(batch, blk, hash)
+	feeRate := bc.blockFeeRate(blk)
+	coinbase := view.coinbaseHeights()
+	if err := putUtxo(batch, view.overlay, coinbase); err != nil {
+		return errors.Wrapf(err, "Failed to serialize UTXO of block %x", hash)
+	}
+	commitment := bc.Utk.commitmentAfter(view.overlay, coinbase).Sum()
+	batch.PutUtxoCommitment(blk.Header.height, commitment[:])
+	putAccounts(batch, ws)
+	putContracts(batch, ws)
+	if err := putReceipts(batch, blk.Header.height, receipts); err != nil {
+		return errors.Wrapf(err, "Failed to serialize receipts of block %x", hash)
+	}
+	if err := bc.putStateUndo(batch, blk.Header.height, view.overlay, ws); err != nil {
+		return errors.Wrapf(err, "Failed to serialize the states changed by block %x", hash)
+	}
+	if err := bc.putDelegates(batch, blk.Header.height, ws); err != nil {
+		return errors.Wrapf(err, "Failed to serialize delegates elected at block %x", hash)
+	}
+	batch.PutUtxoHeight(blk.Header.height)
+	batch.PutSupply(view.emitted, view.burned)
+	totalTxs := bc.putTxTotal(batch, blk)
+	pruneHeight, err := bc.prune(batch, blk.Header.height)
+	if err != nil {
+		return err
+	}
+	trace.Span("index", indexLatency)
+	if err := bc.blockDb.Commit(batch); err != nil {
+		return err
+	}
+	bc.pruneHeight = pruneHeight
+
+	// update UTXO pool
+	if err := bc.Utk.ApplyView(view); err != nil {
+		return err
+	}
+	bc.Utk.releaseSpentUtxo(blk)
+	bc.sf.Apply(ws)
+	if blk.Header.height > 0 {
+		bc.fees.add(feeRate)
+	}
+
+	// update tip hash/height
+	oldTip := bc.tip
+	bc.tip = hash
+	bc.height = blk.Header.height
+	bc.updateStats(blk, totalTxs)
+	trace.Span("write", writeLatency)
+
+	commitLatency.ObserveSince(start)
+	bc.updateMetrics()
+	bc.logSlowBlock(blk, trace)
+	bc.log.WithFields(logger.Fields{"height": bc.height, "hash": hash, "txs": len(blk.Tranxs)}).Debug("Committed block")
+	for _, evidence := range blk.Evidences {
+		bc.log.WithFields(logger.Fields{"height": bc.height, "offender": evidence.Offender}).Warning("Slashed block producer")
+	}
+
+	evt := &BlockEvent{Type: BlockCommitted, Block: blk, OldTip: oldTip}
+	if blk.PrevHash() != oldTip {
+		evt.Type = ChainReorged
+		reorgCounter.Inc()
+		// the heights of the old branch now map to other blocks
+		bc.blockCache.Purge()
+		bc.hashCache.Purge()
+		bc.deployments.purge()
+	}
+	bc.events.publish(evt)
+	return nil
+}
+
+// putIndexes adds the mappings from the hashes of the transactions, the evidences and the deposits withdrawn in the
+// block to the block hash to the batch
+func putIndexes(batch *blockdb.Batch, blk *Block, hash cp.Hash32B) {
+	for _, tx := range blk.Tranxs {
+		txHash := tx.Hash()
+		batch.PutTxIndex(txHash[:], hash[:])
 	}
-	return
+	for _, evidence := range blk.Evidences {
+		evidenceHash := evidence.Hash()
+		batch.PutEvidenceIndex(evidenceHash[:], hash[:])
+	}
+	for _, withdraw := range blk.Withdraws {
+		depositHash := withdraw.Deposit.Hash()
+		batch.PutWithdrawIndex(depositHash[:], hash[:])
+	}
+}
+
+// removeIndexes adds removing the mappings put by putIndexes and the receipts of the actions of the block to the batch
+func removeIndexes(batch *blockdb.Batch, blk *Block) {
+	for _, tx := range blk.Tranxs {
+		txHash := tx.Hash()
+		batch.DeleteTxIndex(txHash[:])
+	}
+	for _, evidence := range blk.Evidences {
+		evidenceHash := evidence.Hash()
+		batch.DeleteEvidenceIndex(evidenceHash[:])
+	}
+	for _, withdraw := range blk.Withdraws {
+		depositHash := withdraw.Deposit.Hash()
+		batch.DeleteWithdrawIndex(depositHash[:])
+	}
+	for _, act := range blk.Actions() {
+		actHash := act.Hash()
+		batch.DeleteReceipt(actHash[:])
+	}
+}
+
+// prune adds deleting the block bodies out of the retention window to the batch in pruning mode, and returns the
+// new prune height once the block at the given height is committed
+func (bc *Blockchain) prune(batch *blockdb.Batch, height uint32) (uint32, error) {
+	retention := bc.config.Chain.PruneRetention
+	if !bc.config.Chain.Pruning || height < retention {
+		return bc.pruneHeight, nil
+	}
+
+	// keep the bodies of the most recent retention blocks including the one being committed
+	pruneHeight := height - retention + 1
+	if err := bc.pruneBodies(batch, bc.pruneHeight, pruneHeight); err != nil {
+		return 0, err
+	}
+	if pruneHeight > bc.pruneHeight {
+		batch.PutPruneHeight(pruneHeight)
+		return pruneHeight, nil
+	}
+	return bc.pruneHeight, nil
+}
+
+// pruneBodies adds deleting the bodies of the blocks with height in [start, end) to the batch, keeping their headers
+func (bc *Blockchain) pruneBodies(batch *blockdb.Batch, start uint32, end uint32) error {
+	for h := start; h < end; h++ {
+		blk, err := bc.GetBlockByHeight(h)
+		if err != nil {
+			return err
+		}
+		header, err := proto.Marshal(blk.ConvertToBlockHeaderPb())
+		if err != nil {
+			return err
+		}
+		hash := blk.HashBlock()
+		batch.PruneBlock(hash[:], header)
+		batch.DeleteStateUndo(h)
+	}
+	return nil
 }
 
 // GetHeightByHash returns block's height by hash
@@ -116,15 +567,30 @@ func (bc *Blockchain) GetHeightByHash(hash cp.Hash32B) (uint32, error) {
 
 // GetHashByHeight returns block's hash by height
 func (bc *Blockchain) GetHashByHeight(height uint32) (cp.Hash32B, error) {
+	if hash, ok := bc.hashCache.Get(height); ok {
+		return hash.(cp.Hash32B), nil
+	}
 	hash := cp.ZeroHash32B
 	dbHash, err := bc.blockDb.GetBlockHash(height)
 	copy(hash[:], dbHash)
+	if err == nil {
+		bc.hashCache.Add(height, hash)
+	}
 	return hash, err
 }
 
 // GetBlockByHeight returns block from the blockchain hash by height
+// The body of a block below the prune height is read from the block archive, ErrBlockPruned is returned if it is not
+// archived.
 func (bc *Blockchain) GetBlockByHeight(height uint32) (*Block, error) {
 	hash, err := bc.GetHashByHeight(height)
+	if height < bc.pruneHeight {
+		// a chain imported from a UTXO snapshot has no hash below the snapshot height
+		if err != nil {
+			return nil, errors.Wrapf(ErrBlockPruned, "Block with height = %d, pruned below %d", height, bc.pruneHeight)
+		}
+		return bc.readArchived(height, hash)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -132,9 +598,20 @@ func (bc *Blockchain) GetBlockByHeight(height uint32) (*Block, error) {
 }
 
 // GetBlockByHash returns block from the blockchain hash by hash
+// The returned block may be shared with other callers through the block cache and must not be modified.
 func (bc *Blockchain) GetBlockByHash(hash cp.Hash32B) (*Block, error) {
+	if blk, ok := bc.blockCache.Get(hash); ok {
+		if height := blk.(*Block).Height(); height < bc.pruneHeight {
+			bc.blockCache.Remove(hash)
+			return bc.readArchived(height, hash)
+		}
+		return blk.(*Block), nil
+	}
 	serialized, err := bc.blockDb.CheckOutBlock(hash[:])
 	if err != nil {
+		if height, herr := bc.GetHeightByHash(hash); herr == nil && height < bc.pruneHeight {
+			return bc.readArchived(height, hash)
+		}
 		return nil, err
 	}
 
@@ -143,6 +620,97 @@ func (bc *Blockchain) GetBlockByHash(hash cp.Hash32B) (*Block, error) {
 	if err := blk.Deserialize(serialized); err != nil {
 		return nil, err
 	}
+	bc.blockCache.Add(hash, &blk)
+	return &blk, nil
+}
+
+// readArchived reads the block with the hash at the height, whose body is pruned, from the block archive
+// ErrBlockPruned is returned if the block is not archived.
+func (bc *Blockchain) readArchived(height uint32, hash cp.Hash32B) (*Block, error) {
+	blk, err := bc.archive.ReadBlock(height)
+	if errors.Cause(err) == blockdb.ErrNotExist {
+		return nil, errors.Wrapf(ErrBlockPruned, "Block with height = %d, pruned below %d", height, bc.pruneHeight)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if blk.HashBlock() != hash {
+		return nil, errors.Wrapf(ErrInvalidArchive, "Archived block %d is %x, chain has %x", height, blk.HashBlock(), hash)
+	}
+	return blk, nil
+}
+
+// GetBlocksByRange returns the blocks with height in [start, end] read from Db in one scan, which is aborted with the
+// error of ctx once it is done
+func (bc *Blockchain) GetBlocksByRange(ctx context.Context, start uint32, end uint32) ([]*Block, error) {
+	if start > end || end > bc.height {
+		return nil, errors.Errorf("Invalid block range [%d, %d], tip height is %d", start, end, bc.height)
+	}
+	if start < bc.pruneHeight {
+		return nil, errors.Wrapf(ErrBlockPruned, "Block with height = %d, pruned below %d", start, bc.pruneHeight)
+	}
+	serialized, err := bc.blockDb.CheckOutBlocks(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	blks := make([]*Block, 0, len(serialized))
+	for _, buf := range serialized {
+		blk := Block{}
+		if err := blk.Deserialize(buf); err != nil {
+			return nil, err
+		}
+		blks = append(blks, &blk)
+	}
+	return blks, nil
+}
+
+// forEachBlock calls fn on the blocks with height in [start, end] in order, reading them a batch at a time so the
+// whole range is never held in memory, until ctx is done
+func (bc *Blockchain) forEachBlock(ctx context.Context, start uint32, end uint32, fn func(blk *Block) error) error {
+	it, err := bc.NewBlockIterator(start, end)
+	if err != nil {
+		return err
+	}
+	for {
+		blk, err := it.Next(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(blk); err != nil {
+			return err
+		}
+	}
+}
+
+// GetBlockHeaderByHeight returns the block at the given height with only its header, which is kept even if the
+// block body has been pruned
+func (bc *Blockchain) GetBlockHeaderByHeight(height uint32) (*Block, error) {
+	hash, err := bc.GetHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if height >= bc.pruneHeight {
+		blk, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		return &Block{Header: blk.Header}, nil
+	}
+
+	serialized, err := bc.blockDb.CheckOutHeader(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	header := iproto.BlockHeaderPb{}
+	if err := proto.Unmarshal(serialized, &header); err != nil {
+		return nil, err
+	}
+	blk := Block{}
+	blk.ConvertFromBlockHeaderPb(&iproto.BlockPb{Header: &header})
 	return &blk, nil
 }
 
@@ -156,6 +724,11 @@ func (bc *Blockchain) TipHeight() uint32 {
 	return bc.height
 }
 
+// ChainID returns the ID of the chain, which all its blocks carry
+func (bc *Blockchain) ChainID() uint32 {
+	return bc.chainID
+}
+
 // Reset reset for next block
 func (bc *Blockchain) Reset() {
 	bc.Utk.Reset()
@@ -163,184 +736,931 @@ func (bc *Blockchain) Reset() {
 
 // ValidateBlock validates a new block before adding it to the blockchain
 func (bc *Blockchain) ValidateBlock(blk *Block) error {
+	_, err := bc.validateBlock(blk)
+	return err
+}
+
+// validateBlock validates the block like ValidateBlock, and returns the view of the UTXO pool the block is connected
+// to, which can be applied to the pool once the block is committed
+func (bc *Blockchain) validateBlock(blk *Block) (*UtxoView, error) {
 	if blk == nil {
-		return errors.Wrap(ErrInvalidBlock, "Block is nil")
+		return nil, errors.Wrap(ErrInvalidBlock, "Block is nil")
+	}
+	// verify new block belongs to this chain
+	if blk.Header.chainID != bc.chainID {
+		return nil, errors.Wrapf(ErrInvalidBlock, "Wrong chain ID %d, expecting %d", blk.Header.chainID, bc.chainID)
 	}
 	// verify new block has correctly linked to current tip
 	if blk.Header.prevBlockHash != bc.tip {
-		return errors.Wrapf(ErrInvalidBlock, "Wrong prev hash %x, expecting %x", blk.Header.prevBlockHash, bc.tip)
+		return nil, errors.Wrapf(ErrInvalidBlock, "Wrong prev hash %x, expecting %x", blk.Header.prevBlockHash, bc.tip)
 	}
 
 	// verify new block has height incremented by 1
 	if blk.Header.height != 0 && blk.Header.height != bc.height+1 {
-		return errors.Wrapf(ErrInvalidBlock, "Wrong block height %d, expecting %d", blk.Header.height, bc.height+1)
+		return nil, errors.Wrapf(ErrInvalidBlock, "Wrong block height %d, expecting %d", blk.Header.height, bc.height+1)
 	}
-
-	// validate all Tx conforms to blockchain protocol
-
-	// validate UXTO contained in this Tx
-	return bc.Utk.ValidateUtxo(blk)
-}
-
-// MintNewBlock creates a new block with given transactions.
-// Note: the coinbase transaction will be added to the given transactions
-// when minting a new block.
-func (bc *Blockchain) MintNewBlock(txs []*Tx, toaddr, data string) *Block {
-	txs = append(txs, NewCoinbaseTx(toaddr, bc.config.Chain.BlockReward, data))
-	return NewBlock(bc.chainID, bc.height+1, bc.tip, txs)
-}
-
-// AddBlockCommit adds a new block into blockchain
-func (bc *Blockchain) AddBlockCommit(blk *Block) error {
-	if err := bc.ValidateBlock(blk); err != nil {
-		return err
+	if err := bc.validateCheckpoint(blk); err != nil {
+		return nil, err
 	}
 
-	// commit block into blockchain DB
-	return bc.commitBlock(blk)
-}
-
-// AddBlockSync adds a past block into blockchain
-// used by block syncer when the chain in out-of-sync
-func (bc *Blockchain) AddBlockSync(blk *Block) error {
-	// directly commit block into blockchain DB
-	return bc.commitBlock(blk)
-}
+	// genesis block is created locally rather than received, hence not subject to the block limits
+	if blk.Header.height != 0 {
+		if err := bc.validateBlockLimits(blk); err != nil {
+			return nil, err
+		}
+	}
 
-// StoreBlock persists the blocks in the range to file on disk
-func (bc *Blockchain) StoreBlock(start, end uint32) error {
-	return bc.blockDb.StoreBlockToFile(start, end)
-}
+	// consult the consensus engine, genesis block is not produced by any proposer
+	if bc.consensus != nil && blk.Header.height != 0 {
+		// only the header of the parent is needed, which is kept even if its body is pruned
+		parent, err := bc.GetBlockHeaderByHeight(bc.height)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidBlock, "Cannot get parent block %x: %v", bc.tip, err)
+		}
+		if err := bc.consensus.ValidateHeader(blk, parent); err != nil {
+			return nil, errors.Wrapf(ErrInvalidBlock, "%v", err)
+		}
+		if err := bc.consensus.VerifyProposer(blk); err != nil {
+			return nil, errors.Wrapf(ErrInvalidBlock, "%v", err)
+		}
+	}
 
-// ReadBlock read the block from file on disk
-func (bc *Blockchain) ReadBlock(height uint32) *Block {
-	file, err := os.Open(blockdb.BlockData)
-	defer file.Close()
-	if err != nil {
-		glog.Error(err)
-		return nil
+	// verify merkle root matches the transactions in this block
+	if merkle := blk.MerkleRoot(); blk.Header.merkleRoot != merkle {
+		return nil, errors.Wrapf(ErrInvalidBlock, "Wrong merkle root %x, expecting %x", blk.Header.merkleRoot, merkle)
 	}
 
-	// read block index
-	indexSize := make([]byte, 4)
-	file.Read(indexSize)
-	size := cm.MachineEndian.Uint32(indexSize)
-	indexBytes := make([]byte, size)
-	if n, err := file.Read(indexBytes); err != nil || n != int(size) {
-		glog.Error(err)
-		return nil
+	// validate all Tx conforms to blockchain protocol
+	if err := bc.validateCoinbase(blk, bc.Utk); err != nil {
+		return nil, err
 	}
-	blkIndex := iproto.BlockIndex{}
-	if proto.Unmarshal(indexBytes, &blkIndex) != nil {
-		glog.Error(err)
-		return nil
+	for _, tx := range blk.Tranxs {
+		// the malformed transactions are not only kept out of the pool, but of the blocks too
+		if !tx.IsCoinbase() {
+			if err := bc.CheckTransaction(tx); err != nil {
+				return nil, errors.Wrapf(ErrInvalidBlock, "%v", err)
+			}
+		}
+		if err := bc.validateTxChainID(tx, blk.Header.height); err != nil {
+			return nil, errors.Wrapf(ErrInvalidBlock, "%v", err)
+		}
+		if err := bc.validateLockTime(tx, blk.Header.height, blk.Header.timestamp); err != nil {
+			return nil, errors.Wrapf(ErrInvalidBlock, "%v", err)
+		}
 	}
 
-	// read the specific block
-	index := height - blkIndex.Start
-	file.Seek(int64(4+size+blkIndex.Offset[index]), 0)
-	size = blkIndex.Offset[index+1] - blkIndex.Offset[index]
-	blkBytes := make([]byte, size)
-	if n, err := file.Read(blkBytes); err != nil || n != int(size) {
-		glog.Error(err)
-		return nil
+	if err := bc.validateEvidences(blk.Evidences); err != nil {
+		return nil, err
 	}
-	blk := Block{}
-	if blk.Deserialize(blkBytes) != nil {
-		glog.Error(err)
-		return nil
+	if err := bc.validateWithdraws(blk.Withdraws); err != nil {
+		return nil, err
 	}
-	return &blk
+	if err := bc.validateNetwork(blk); err != nil {
+		return nil, err
+	}
+
+	// validate the transfers and executions against the account states and contracts
+	ws, _, err := bc.executeBlock(blk)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidBlock, "%v", err)
+	}
+
+	// validate UXTO contained in this Tx on a view of the UTXO pool, including running the unlock script of every input
+	// unless the block is pinned by a checkpoint above it
+	view := bc.Utk.NewView()
+	if err := view.validateBlock(blk, bc.emission(blk.Header.height), !bc.belowCheckpoint(blk.Header.height)); err != nil {
+		return nil, err
+	}
+
+	// verify the state root matches the UTXO pool and account states resulting from this block
+	root, err := bc.stateRoot(view, ws)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidBlock, "%v", err)
+	}
+	if blk.Header.stateRoot != root {
+		return nil, errors.Wrapf(ErrInvalidBlock, "Wrong state root %x, expecting %x", blk.Header.stateRoot, root)
+	}
+	return view, nil
 }
 
-// CreateBlockchain creates a new blockchain and DB instance
-func CreateBlockchain(address string, cfg *config.Config) *Blockchain {
-	db, dbFileExist := blockdb.NewBlockDB(cfg)
-	if db == nil {
-		glog.Error("cannot find db")
+// validateBlockLimits verifies the block does not exceed the size, transaction count and gas limits of the config
+func (bc *Blockchain) validateBlockLimits(blk *Block) error {
+	if max := bc.config.Chain.MaxBlockTxs; max > 0 && uint32(len(blk.Tranxs)) > max {
+		return errors.Wrapf(ErrInvalidBlock, "Block has %d transactions, exceeding the limit of %d", len(blk.Tranxs), max)
+	}
+	if max := bc.config.Chain.MaxBlockSize; max > 0 {
+		if size := proto.Size(blk.ConvertToBlockPb()); size > int(max) {
+			return errors.Wrapf(ErrInvalidBlock, "Block size is %d bytes, exceeding the limit of %d bytes", size, max)
+		}
+	}
+	return bc.validateGasLimit(blk.Executions)
+}
+
+// validateGasLimit verifies the gas limits of the executions add up to no more than the block gas limit of the config
+func (bc *Blockchain) validateGasLimit(execs []*Execution) error {
+	max := bc.config.Chain.BlockGasLimit
+	if max == 0 {
 		return nil
 	}
+	gas := uint64(0)
+	for _, exec := range execs {
+		if exec.GasLimit > max-gas {
+			return errors.Wrapf(ErrInvalidBlock, "Executions exceed the block gas limit of %d", max)
+		}
+		gas += exec.GasLimit
+	}
+	return nil
+}
+
+// validateNetwork verifies the addresses in the transfers, votes, executions, deposits and withdraws of the block are of
+// the network of the chain, which the outputs of the transactions cannot be checked for since they only lock to public
+// key hashes
+// The recipients of the deposits are on other chains, and the senders of the withdraws on other chains.
+func (bc *Blockchain) validateNetwork(blk *Block) error {
+	addresses := []string{}
+	for _, tsf := range blk.Transfers {
+		addresses = append(addresses, tsf.Sender, tsf.Recipient)
+	}
+	for _, vote := range blk.Votes {
+		addresses = append(addresses, vote.Voter)
+		if vote.Votee != "" {
+			addresses = append(addresses, vote.Votee)
+		}
+	}
+	for _, exec := range blk.Executions {
+		addresses = append(addresses, exec.Executor)
+		if exec.Contract != "" {
+			addresses = append(addresses, exec.Contract)
+		}
+	}
+	for _, deposit := range blk.Deposits {
+		addresses = append(addresses, deposit.Sender)
+	}
+	for _, withdraw := range blk.Withdraws {
+		if withdraw.Deposit != nil {
+			addresses = append(addresses, withdraw.Deposit.Recipient)
+		}
+	}
+	for _, addr := range addresses {
+		if err := iotxaddress.ValidateNetwork(addr, bc.config.Chain.IsTestnet); err != nil {
+			return errors.Wrapf(ErrInvalidBlock, "Address %s: %v", addr, err)
+		}
+	}
+	return nil
+}
+
+// validateEvidences verifies none of the evidences is committed by an earlier block or repeated, so the misbehavior
+// is penalized only once
+func (bc *Blockchain) validateEvidences(evidences []*Evidence) error {
+	seen := make(map[cp.Hash32B]bool)
+	for _, evidence := range evidences {
+		hash := evidence.Hash()
+		if seen[hash] {
+			return errors.Wrapf(ErrInvalidBlock, "Evidence %x is repeated", hash)
+		}
+		seen[hash] = true
+		_, err := bc.blockDb.GetEvidenceBlockHash(hash[:])
+		if err == nil {
+			return errors.Wrapf(ErrInvalidBlock, "Evidence %x is already committed", hash)
+		}
+		if errors.Cause(err) != blockdb.ErrNotExist {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCoinbase verifies the block contains at most one coinbase transaction with the correct amount, the fees
+// are paid by the UTXO of the tracker
+// The outputs of the coinbase following the first one must pay the shares of the delegates in the block reward, and
+// its data has to follow the coinbase data policy.
+func (bc *Blockchain) validateCoinbase(blk *Block, tk *UtxoTracker) error {
+	// Genesis block's coinbase mints the total supply, other blocks are paid by block reward plus fees
+	reward := bc.totalSupply()
+	var shares []*Payee
+	if blk.Header.height != 0 {
+		fees, err := tk.totalFee(blk.Tranxs)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidBlock, "%v", err)
+		}
+		if reward, err = addValue(bc.blockReward(blk.Header.height), fees); err != nil {
+			return errors.Wrapf(ErrInvalidBlock, "%v", err)
+		}
+		if shares, err = bc.delegateRewards(blk.Header.height); err != nil {
+			return err
+		}
+	}
+
+	numCoinbase := 0
+	for _, tx := range blk.Tranxs {
+		if !tx.IsCoinbase() {
+			continue
+		}
+		if numCoinbase++; numCoinbase > 1 {
+			return errors.Wrapf(ErrInvalidBlock, "Block %d has more than one coinbase transaction", blk.Header.height)
+		}
+		amount, err := utxoValue(tx.TxOut)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidBlock, "Block %d: %v", blk.Header.height, err)
+		}
+		if amount != reward {
+			return errors.Wrapf(ErrInvalidBlock, "Wrong coinbase amount %d, expecting %d", amount, reward)
+		}
+		if blk.Header.height == 0 {
+			continue
+		}
+		if err := validateDelegateRewards(tx, shares); err != nil {
+			return errors.Wrapf(ErrInvalidBlock, "Block %d: %v", blk.Header.height, err)
+		}
+		if err := bc.validateCoinbaseData(blk, tx); err != nil {
+			return errors.Wrapf(ErrInvalidBlock, "Block %d: %v", blk.Header.height, err)
+		}
+	}
+	return nil
+}
+
+// validateDelegateRewards verifies the outputs of the coinbase following the first one pay the shares of the delegates
+func validateDelegateRewards(cbTx *Tx, shares []*Payee) error {
+	if len(cbTx.TxOut) != len(shares)+1 {
+		return errors.Errorf("Coinbase has %d outputs, expecting %d", len(cbTx.TxOut), len(shares)+1)
+	}
+	for i, share := range shares {
+		out := cbTx.TxOut[i+1]
+		lockScript, err := txvm.PayToAddrScript(share.Address)
+		if err != nil {
+			return err
+		}
+		if out.Value != share.Amount || !bytes.Equal(out.LockScript, lockScript) {
+			return errors.Errorf("Coinbase output %d does not pay %d to delegate %s", i+1, share.Amount, share.Address)
+		}
+	}
+	return nil
+}
+
+// totalSupply returns the amount minted by the genesis block
+func (bc *Blockchain) totalSupply() uint64 {
+	if bc.genesis != nil {
+		return bc.genesis.TotalSupply()
+	}
+	return bc.config.Chain.TotalSupply
+}
+
+// blockReward returns the block reward at the given height, the genesis reward schedule takes precedence over the
+// emission schedule of the config
+func (bc *Blockchain) blockReward(height uint32) uint64 {
+	if bc.genesis != nil {
+		if reward, ok := bc.genesis.BlockRewardAt(height); ok {
+			return reward
+		}
+	}
+	return bc.epochs.EpochReward(bc.epochs.EpochOf(height))
+}
+
+// delegateRewards returns the shares in the reward of the block at the given height paid to the delegates of its
+// epoch
+func (bc *Blockchain) delegateRewards(height uint32) ([]*Payee, error) {
+	if bc.config.Chain.DelegateRewardPercent == 0 {
+		return nil, nil
+	}
+	delegates, err := bc.GetDelegatesByEpoch(bc.epochs.EpochOf(height))
+	if err != nil {
+		return nil, err
+	}
+	return bc.epochs.DelegateRewards(bc.blockReward(height), election.Addresses(delegates)), nil
+}
+
+// coinbaseTx creates the coinbase transaction of the block at the given height, paying the block reward and the fees
+// to the producer, except for the shares of the delegates which are paid in the following outputs
+func (bc *Blockchain) coinbaseTx(height uint32, toaddr string, fees uint64, data string) (*Tx, error) {
+	shares, err := bc.delegateRewards(height)
+	if err != nil {
+		return nil, err
+	}
+	amount := bc.blockReward(height) + fees
+	for _, share := range shares {
+		amount -= share.Amount
+	}
+	if data, err = bc.coinbaseData(height, toaddr, data); err != nil {
+		return nil, err
+	}
+	cbTx := NewCoinbaseTxWithPayees(append([]*Payee{{Address: toaddr, Amount: amount}}, shares...), data)
+	if cbTx == nil {
+		return nil, errors.Wrapf(ErrInvalidBlock, "Cannot create coinbase transaction to %s", toaddr)
+	}
+	return cbTx, nil
+}
+
+// emission returns the amount emitted by the block at the given height, which is the total supply for genesis block
+func (bc *Blockchain) emission(height uint32) uint64 {
+	if height == 0 {
+		return bc.totalSupply()
+	}
+	return bc.blockReward(height)
+}
+
+// MintNewBlock creates a new block with given transactions.
+// Note: the coinbase transaction will be added to the given transactions
+// when minting a new block.
+// The transactions are packed in the order of the tx order policy, each one after the transactions it spends the outputs
+// of, and the ones not fitting in the block limits are skipped along with their descendants.
+func (bc *Blockchain) MintNewBlock(txs []*Tx, toaddr, data string) (*Block, error) {
+	return bc.MintNewBlockWithTransfers(txs, nil, toaddr, data)
+}
+
+// MintNewBlockWithTransfers creates a new block with given transactions and transfers, see MintNewBlock
+// All the transfers are packed into the block, which only the transactions make room for.
+func (bc *Blockchain) MintNewBlockWithTransfers(txs []*Tx, tsfs []*Transfer, toaddr, data string) (*Block, error) {
+	return bc.MintNewBlockWithExecutions(txs, tsfs, nil, toaddr, data)
+}
+
+// MintNewBlockWithExecutions creates a new block with given transactions, transfers and executions, see
+// MintNewBlockWithTransfers
+// The executions have to fit in the gas limit of the block, the results of running them are committed by the state
+// root of the block.
+func (bc *Blockchain) MintNewBlockWithExecutions(txs []*Tx, tsfs []*Transfer, execs []*Execution, toaddr, data string) (*Block, error) {
+	return bc.MintNewBlockWithVotes(txs, tsfs, execs, nil, toaddr, data)
+}
+
+// MintNewBlockWithVotes creates a new block with given transactions, transfers, executions and votes, see
+// MintNewBlockWithExecutions
+func (bc *Blockchain) MintNewBlockWithVotes(txs []*Tx, tsfs []*Transfer, execs []*Execution, votes []*Vote, toaddr, data string) (*Block, error) {
+	return bc.MintNewBlockWithEvidences(txs, tsfs, execs, votes, nil, toaddr, data)
+}
+
+// MintNewBlockWithEvidences creates a new block with given transactions, transfers, executions, votes and evidences,
+// see MintNewBlockWithExecutions
+// The evidences must not have been committed by earlier blocks.
+func (bc *Blockchain) MintNewBlockWithEvidences(txs []*Tx, tsfs []*Transfer, execs []*Execution, votes []*Vote, evidences []*Evidence, toaddr, data string) (*Block, error) {
+	return bc.MintNewBlockWithDeposits(txs, tsfs, execs, votes, evidences, nil, nil, toaddr, data)
+}
+
+// MintNewBlockWithDeposits creates a new block with given transactions, transfers, executions, votes, evidences,
+// deposits and withdraws, see MintNewBlockWithEvidences
+// The withdraws must carry the headers of confirmed blocks of the peer chains, and their deposits must not have been
+// withdrawn by earlier blocks.
+func (bc *Blockchain) MintNewBlockWithDeposits(txs []*Tx, tsfs []*Transfer, execs []*Execution, votes []*Vote, evidences []*Evidence, deposits []*Deposit, withdraws []*Withdraw, toaddr, data string) (*Block, error) {
+	if err := bc.validateGasLimit(execs); err != nil {
+		return nil, err
+	}
+	if err := bc.validateEvidences(evidences); err != nil {
+		return nil, err
+	}
+	if err := bc.validateWithdraws(withdraws); err != nil {
+		return nil, err
+	}
+	actions := (&Block{Transfers: tsfs, Executions: execs, Votes: votes, Evidences: evidences, Deposits: deposits, Withdraws: withdraws}).Actions()
+	return bc.mintNewBlock(txs, actions, toaddr, data)
+}
+
+// MintNewBlockWithActions creates a new block with given transactions and actions of any kind, see
+// MintNewBlockWithDeposits
+// The actions are executed in the order of the block on top of the current states, and the ones failing to, such as
+// the ones whose nonce does not follow the one of their sender, are left out of the block along with the executions
+// beyond the block gas limit.
+func (bc *Blockchain) MintNewBlockWithActions(txs []*Tx, actions []Action, toaddr, data string) (*Block, error) {
+	split := sortActions(actions)
+	if err := bc.validateEvidences(split.Evidences); err != nil {
+		return nil, err
+	}
+	if err := bc.validateWithdraws(split.Withdraws); err != nil {
+		return nil, err
+	}
+	return bc.mintNewBlock(txs, bc.executableActions(split.Actions()), toaddr, data)
+}
+
+// executableActions returns the actions, in the order of the block, which execute in order on top of the current
+// states within the block gas limit
+func (bc *Blockchain) executableActions(actions []Action) []Action {
+	ctx := &ActionContext{ChainID: bc.chainID, Height: bc.height + 1, WS: bc.sf.NewWorkingSet()}
+	gas := uint64(0)
+	executable := []Action{}
+	for _, act := range actions {
+		exec, isExec := act.(*Execution)
+		if max := bc.config.Chain.BlockGasLimit; isExec && max > 0 && exec.GasLimit > max-gas {
+			continue
+		}
+		err := act.Verify()
+		if err == nil {
+			err = act.Execute(ctx)
+		}
+		if err != nil {
+			hash := act.Hash()
+			bc.log.WithFields(logger.Fields{"kind": act.Kind(), "hash": hash, "err": err}).Debug("Leaving out action failing to execute")
+			continue
+		}
+		if isExec {
+			gas += exec.GasLimit
+		}
+		executable = append(executable, act)
+	}
+	return executable
+}
+
+// mintNewBlock creates a new block with the transactions fitting in the block limits and all the actions
+func (bc *Blockchain) mintNewBlock(txs []*Tx, actions []Action, toaddr, data string) (*Block, error) {
+	txs, err := bc.packTxs(txs, actions, toaddr, data)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		blk, err := bc.mintBlock(txs, actions, toaddr, data)
+		if err != nil {
+			return nil, err
+		}
+		if err := bc.validateBlockLimits(blk); err == nil || len(txs) == 0 {
+			return blk, err
+		}
+		// the size estimate of packTxs misses the signature the consensus adds to the header, so drop the last
+		// transaction and retry
+		txs = txs[:len(txs)-1]
+	}
+}
+
+// packTxs returns the transactions packed into a block along with the coinbase and the actions under the block limits,
+// ordered by the tx order policy with the parents before the children
+func (bc *Blockchain) packTxs(txs []*Tx, actions []Action, toaddr, data string) ([]*Tx, error) {
+	candidates, err := bc.txCandidates(txs)
+	if err != nil {
+		return nil, err
+	}
+	builder := &blockBuilder{policy: bc.txOrder, maxTxs: len(candidates)}
+	// the coinbase counts in the transactions of the block
+	if max := int(bc.config.Chain.MaxBlockTxs); max > 0 && max-1 < builder.maxTxs {
+		builder.maxTxs = max - 1
+	}
+	size := 0
+	if max := bc.config.Chain.MaxBlockSize; max > 0 {
+		builder.maxSize = int(max)
+		// the coinbase collecting the fees of all candidates is at least as large as the one of the packed block
+		fees := uint64(0)
+		for _, c := range candidates {
+			fees += c.Fee
+		}
+		cbTx, err := bc.coinbaseTx(bc.height+1, toaddr, fees, data)
+		if err != nil {
+			return nil, err
+		}
+		size = proto.Size(NewBlockWithActions(bc.chainID, bc.height+1, bc.tip, []*Tx{cbTx}, actions).ConvertToBlockPb())
+	}
+	return builder.build(candidates, size), nil
+}
+
+// mintBlock creates a new block with the transactions, the coinbase and the actions, committing to the states resulting
+// from them
+func (bc *Blockchain) mintBlock(txs []*Tx, actions []Action, toaddr, data string) (*Block, error) {
+	// fees paid by the transactions are collected by the coinbase on top of block reward
+	fees, err := bc.totalFee(txs)
+	if err != nil {
+		return nil, err
+	}
+	cbTx, err := bc.coinbaseTx(bc.height+1, toaddr, fees, data)
+	if err != nil {
+		return nil, err
+	}
+	blk := NewBlockWithActions(bc.chainID, bc.height+1, bc.tip, append(append([]*Tx{}, txs...), cbTx), actions)
+	blk.Header.timestamp = uint64(bc.now().Unix())
+	if blk.Header.version, err = bc.blockVersion(blk.Header.height); err != nil {
+		return nil, err
+	}
+	if err := bc.validateNetwork(blk); err != nil {
+		return nil, err
+	}
+	if err := bc.setStateRoot(blk); err != nil {
+		return nil, errors.Wrapf(ErrInvalidBlock, "%v", err)
+	}
+	if bc.consensus != nil {
+		if err := bc.consensus.FinalizeBlock(blk); err != nil {
+			return nil, err
+		}
+	}
+	return blk, nil
+}
+
+// totalFee returns the sum of fees paid by the given transactions
+func (bc *Blockchain) totalFee(txs []*Tx) (uint64, error) {
+	return bc.Utk.totalFee(txs)
+}
+
+// AddBlockCommit adds a new block into blockchain
+func (bc *Blockchain) AddBlockCommit(blk *Block) error {
+	if err := bc.checkHalted(); err != nil {
+		return err
+	}
+	trace := newBlockTrace(blk)
+	view, err := bc.validateBlock(blk)
+	if err != nil {
+		return err
+	}
+	trace.Span("validate", validateLatency)
+
+	// commit block into blockchain DB, applying the view it has been validated against
+	return bc.commitBlock(blk, view, trace)
+}
+
+// AddBlockSync adds a past block into blockchain
+// used by block syncer when the chain in out-of-sync
+// The block is not validated, except against the checkpoints, and is refused while the chain is halted like
+// AddBlockCommit.
+func (bc *Blockchain) AddBlockSync(blk *Block) error {
+	if err := bc.checkHalted(); err != nil {
+		return err
+	}
+	if err := bc.validateCheckpoint(blk); err != nil {
+		return err
+	}
+	// directly commit block into blockchain DB
+	return bc.commitBlock(blk, nil, newBlockTrace(blk))
+}
+
+// StoreBlock archives the blocks in the range to the block archive, streaming them from the chain
+func (bc *Blockchain) StoreBlock(ctx context.Context, start, end uint32) error {
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(bc.ExportChain(ctx, w, start, end))
+	}()
+	err := bc.archive.Write(ctx, r)
+	// stop the export if the archive did not read it through
+	r.CloseWithError(err)
+	return err
+}
+
+// ReadBlock reads the block at the height from the block archive
+func (bc *Blockchain) ReadBlock(height uint32) (*Block, error) {
+	return bc.archive.ReadBlock(height)
+}
+
+// CreateBlockchain creates a new blockchain and DB instance
+// Initializing an existing blockchain is aborted with the error of ctx once it is done.
+func CreateBlockchain(ctx context.Context, address string, cfg *config.Config) (*Blockchain, error) {
+	genesis, err := genesisOf(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return createBlockchain(ctx, address, cfg, genesis)
+}
+
+// genesisOf returns the genesis of the genesis file of the config, or of the preset of its network, or nil if there
+// is neither
+func genesisOf(cfg *config.Config) (*config.Genesis, error) {
+	if cfg.Chain.GenesisPath != "" {
+		genesis, err := config.LoadGenesis(cfg.Chain.GenesisPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to load genesis")
+		}
+		return genesis, nil
+	}
+	if preset, ok := config.Presets[cfg.Net]; ok {
+		return preset.Genesis, nil
+	}
+	return nil, nil
+}
+
+// CreateBlockchainWithGenesis creates a new blockchain and DB instance bootstrapped from the genesis, rather than the
+// genesis file of the config
+func CreateBlockchainWithGenesis(ctx context.Context, cfg *config.Config, genesis *config.Genesis) (*Blockchain, error) {
+	return createBlockchain(ctx, "", cfg, genesis)
+}
+
+// OpenBlockchainReadOnly opens the existing blockchain DB of the config read-only, to query the blocks, the txs and
+// the UTXO without risking writes to the DB
+// Adding a block fails with blockdb.ErrReadOnly, and the UTXO pool is rebuilt in memory if the DB does not have it
+// updated to the tip.
+func OpenBlockchainReadOnly(ctx context.Context, cfg *config.Config) (*Blockchain, error) {
+	genesis, err := genesisOf(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db, err := blockdb.OpenReadOnly(cfg)
+	if err != nil {
+		return nil, errors.Wrapf(ErrDBOpen, "%v", err)
+	}
 	chain := NewBlockchain(db, cfg)
+	if genesis != nil {
+		chain.genesis = genesis
+		chain.chainID = genesis.ChainID
+	}
+	if err := chain.Init(ctx); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "Failed to initialize Blockchain")
+	}
+	return chain, nil
+}
+
+// createBlockchain creates the blockchain bootstrapped from the genesis unless it is nil, in which case the genesis
+// block mints the total supply to address
+func createBlockchain(ctx context.Context, address string, cfg *config.Config, genesis *config.Genesis) (*Blockchain, error) {
+	db, dbFileExist, err := blockdb.NewBlockDB(cfg)
+	if err != nil {
+		return nil, errors.Wrapf(ErrDBOpen, "%v", err)
+	}
+	chain := NewBlockchain(db, cfg)
+	if genesis != nil {
+		chain.genesis = genesis
+		chain.chainID = genesis.ChainID
+	}
 
 	if dbFileExist {
-		glog.Info("Blockchain already exists.")
+		chain.log.Info("Blockchain already exists.")
 
-		if err := chain.Init(); err != nil {
-			glog.Fatalf("Failed to create Blockchain, error = %v", err)
-			return nil
+		if err := chain.Init(ctx); err != nil {
+			db.Close()
+			return nil, errors.Wrap(err, "Failed to initialize Blockchain")
 		}
-		return chain
+		return chain, nil
 	}
 
 	// create genesis block
-	cbtx := NewCoinbaseTx(address, cfg.Chain.TotalSupply, GenesisCoinbaseData)
-	genesis := NewBlock(chain.chainID, 0, cp.ZeroHash32B, []*Tx{cbtx})
-	genesis.Header.timestamp = 0
+	genesisBlk, err := chain.createGenesisBlock(address)
+	if err != nil {
+		return nil, err
+	}
 
 	// Genesis block has height 0
-	if genesis.Header.height != 0 {
-		glog.Errorf("Genesis block has height = %d, expecting 0", genesis.Height())
-		return nil
+	if genesisBlk.Header.height != 0 {
+		return nil, errors.Wrapf(ErrInvalidBlock, "Genesis block has height = %d, expecting 0", genesisBlk.Height())
 	}
 
+	if err := chain.initUtxoCache(); err != nil {
+		return nil, err
+	}
+	if err := chain.initStorage(); err != nil {
+		return nil, err
+	}
 	// add Genesis block as very first block
-	if err := chain.AddBlockCommit(genesis); err != nil {
-		glog.Error(err)
-		return nil
+	if err := chain.AddBlockCommit(genesisBlk); err != nil {
+		return nil, err
 	}
-	return chain
+	return chain, nil
+}
+
+// createGenesisBlock creates the genesis block from the genesis file, or mints the total supply to address if there
+// is no genesis file
+func (bc *Blockchain) createGenesisBlock(address string) (*Block, error) {
+	payees := []*Payee{{Address: address, Amount: bc.config.Chain.TotalSupply}}
+	data := GenesisCoinbaseData
+	timestamp := uint64(0)
+	if bc.genesis != nil {
+		payees = nil
+		for _, alloc := range bc.genesis.Allocations {
+			payees = append(payees, &Payee{Address: alloc.Address, Amount: alloc.Amount})
+		}
+		if bc.genesis.CoinbaseData != "" {
+			data = bc.genesis.CoinbaseData
+		}
+		timestamp = bc.genesis.Timestamp
+	}
+
+	cbtx := NewCoinbaseTxWithPayees(payees, data)
+	if cbtx == nil {
+		return nil, errors.Wrap(ErrInvalidBlock, "Cannot create genesis coinbase transaction")
+	}
+	genesis := NewBlock(bc.chainID, 0, cp.ZeroHash32B, []*Tx{cbtx})
+	genesis.Header.timestamp = timestamp
+	if err := bc.setStateRoot(genesis); err != nil {
+		return nil, errors.Wrapf(ErrInvalidBlock, "%v", err)
+	}
+	return genesis, nil
 }
 
-// BalanceOf returns the balance of an address
-func (bc *Blockchain) BalanceOf(address string) uint64 {
+// BalanceOf returns the balance of an address, only counting the UTXO confirmed by at least minConfirmations blocks
+// including the one containing it, so 0 or 1 counts all UTXO on the chain
+func (bc *Blockchain) BalanceOf(address string, minConfirmations uint32) uint64 {
 	_, balance := bc.Utk.UtxoEntries(address, math.MaxUint64)
+	if minConfirmations <= 1 {
+		return balance
+	}
+
+	balance = 0
+	key := iotxaddress.GetPubkeyHash(address)
+	bc.Utk.utxoPool.forEach(func(hash cp.Hash32B, txOut []*TxOutput) bool {
+		confirmed, err := bc.txHeight(hash)
+		if err != nil {
+			bc.log.WithFields(logger.Fields{"tx": hash, "err": err}).Error("Cannot find the block of UTXO")
+			return true
+		}
+		if confirmed > bc.height || bc.height-confirmed+1 < minConfirmations {
+			return true
+		}
+		for _, out := range txOut {
+			if out.IsLockedWithKey(key) {
+				balance += out.Value
+			}
+		}
+		return true
+	})
 	return balance
 }
 
+// CirculatingSupply returns the sum of all UTXO on the chain
+// It always equals the amount emitted by the genesis block and the block rewards minus the amount burned.
+func (bc *Blockchain) CirculatingSupply() uint64 {
+	return bc.Utk.circulating
+}
+
 // UtxoPool returns the UTXO pool of current blockchain
 func (bc *Blockchain) UtxoPool() map[cp.Hash32B][]*TxOutput {
-	return bc.Utk.utxoPool
+	return bc.Utk.GetPool()
 }
 
-// createTx creates a transaction paying 'amount' from 'from' to 'to'
-func (bc *Blockchain) createTx(from iotxaddress.Address, amount uint64, to []*Payee, isRaw bool) *Tx {
-	utxo, change := bc.Utk.UtxoEntries(from.Address, amount)
-	if utxo == nil {
-		glog.Errorf("Fail to get UTXO for %v", from.Address)
-		return nil
+// NewUtxoView returns a copy-on-write view of the UTXO pool, which must not be used once another block is committed
+func (bc *Blockchain) NewUtxoView() *UtxoView {
+	return bc.Utk.NewView()
+}
+
+// ValidateCoinbaseMaturity returns error if the transaction spends immature coinbase outputs in the next block
+func (bc *Blockchain) ValidateCoinbaseMaturity(tx *Tx) error {
+	return bc.Utk.ValidateCoinbaseMaturity(tx, bc.height+1)
+}
+
+// createTx creates a transaction paying 'amount' from 'from' to 'to', signed by 'signer' unless it is nil
+// The transaction pays the fee estimated to get it mined within the fee target blocks of the config, if any.
+// The payees have to be of the network of the chain.
+func (bc *Blockchain) createTx(from string, amount uint64, to []*Payee, signer wallet.Signer) (*Tx, error) {
+	for _, payee := range to {
+		if err := iotxaddress.ValidateNetwork(payee.Address, bc.config.Chain.IsTestnet); err != nil {
+			return nil, errors.Wrapf(err, "Payee %s", payee.Address)
+		}
+	}
+	rate := uint64(0)
+	if target := bc.config.Chain.FeeTargetBlocks; target > 0 {
+		var err error
+		if rate, err = bc.EstimateFee(target); err != nil {
+			return nil, err
+		}
+	}
+	// the fee depends on the size of the transaction, which depends on the UTXO selected to pay the fee
+	fee := uint64(0)
+	outIndex := bc.Utk.currOutIndex
+	for {
+		bc.Utk.currOutIndex = outIndex
+		tx, err := bc.buildTx(from, amount, fee, to, signer)
+		if err != nil {
+			return nil, err
+		}
+		serialized, err := tx.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		if need := rate * uint64(len(serialized)); fee < need {
+			fee = need
+			continue
+		}
+		// a transaction created right after must not spend the same UTXO while this one is in flight
+		if ttl := bc.config.Chain.UtxoReservationTTL; ttl > 0 {
+			bc.ReserveTxInputs(tx, ttl)
+		}
+		return tx, nil
+	}
+}
+
+// buildTx builds a transaction paying 'amount' from 'from' to 'to' along with the fee, signed by the signer if any
+func (bc *Blockchain) buildTx(from string, amount uint64, fee uint64, to []*Payee, signer wallet.Signer) (*Tx, error) {
+	utxo, change, err := bc.selectUtxo(from, amount+fee)
+	if err != nil {
+		return nil, err
+	}
+
+	// the keys of a multisig address are held by the cosigners, who sign the raw transaction with Tx.SignPartial
+	if signer != nil && iotxaddress.IsMultisigAddress(from) {
+		return nil, errors.Wrapf(ErrSigningFailed, "Multisig address %s needs to sign with partial signatures", from)
 	}
 
 	in := []*TxInput{}
 	for _, out := range utxo {
-		unlock := []byte(out.TxOutputPb.String())
-		if !isRaw {
-			var err error
-			unlock, err = txvm.SignatureScript([]byte(out.TxOutputPb.String()), from.PublicKey, from.PrivateKey)
+		input := bc.Utk.CreateTxInputUtxo(out.txHash, out.outIndex, nil)
+		// the sequence needs to reach the relative lock time of the UTXO for the unlock script to pass
+		input.Sequence = txvm.RelativeLockTime(out.LockScript)
+		in = append(in, input)
+	}
+
+	out := []*TxOutput{}
+	for _, payee := range to {
+		out = append(out, bc.Utk.CreateTxOutputUtxo(payee.Address, payee.Amount))
+	}
+	// dust change is left to the fee instead of creating an output not worth spending
+	if change > 0 && !isDust(change, bc.config.Chain.DustThreshold) {
+		out = append(out, bc.Utk.CreateTxOutputUtxo(from, change))
+	}
+
+	tx := NewTx(1, in, out, 0)
+	tx.ChainID = bc.chainID
+
+	// the inputs are signed once the transaction is complete, the unlock script of a raw transaction is the digest to
+	// sign
+	stream := tx.sigStream()
+	for i, input := range in {
+		hash := sigHash(stream, i, utxo[i].TxOutputPb)
+		unlock := hash[:]
+		if signer != nil {
+			sig, err := signTxIn(signer, unlock)
+			if err != nil {
+				return nil, err
+			}
+			unlock, err = txvm.SignatureScriptWithSig(sig, signer.PublicKey())
 			if err != nil {
-				return nil
+				return nil, errors.Wrapf(ErrSigningFailed, "%v", err)
 			}
 		}
+		input.UnlockScript = unlock
+		input.UnlockScriptSize = uint32(len(unlock))
+	}
+	return tx, nil
+}
 
-		in = append(in, bc.Utk.CreateTxInputUtxo(out.txHash, out.outIndex, unlock))
+// ReserveTxInputs reserves the UTXO spent by the transaction, excluding them from coin selection until released,
+// the reservation expires after ttl unless it is 0
+func (bc *Blockchain) ReserveTxInputs(tx *Tx, ttl time.Duration) {
+	if tx.IsCoinbase() {
+		return
+	}
+	for _, txIn := range tx.TxIn {
+		hash := cp.ZeroHash32B
+		copy(hash[:], txIn.TxHash)
+		bc.Utk.ReserveUtxo(hash, txIn.OutIndex, ttl)
 	}
+}
 
-	out := []*TxOutput{}
-	for _, payee := range to {
-		out = append(out, bc.Utk.CreateTxOutputUtxo(payee.Address, payee.Amount))
+// ReleaseTxInputs releases the UTXO reserved for the transaction
+func (bc *Blockchain) ReleaseTxInputs(tx *Tx) {
+	if tx.IsCoinbase() {
+		return
 	}
-	if change > 0 {
-		out = append(out, bc.Utk.CreateTxOutputUtxo(from.Address, change))
+	for _, txIn := range tx.TxIn {
+		hash := cp.ZeroHash32B
+		copy(hash[:], txIn.TxHash)
+		bc.Utk.ReleaseUtxo(hash, txIn.OutIndex)
 	}
+}
 
-	return NewTx(1, in, out, 0)
+// selectUtxo selects the UTXO of the address paying 'amount' with the coin selection of the config, and returns them
+// with the change
+func (bc *Blockchain) selectUtxo(address string, amount uint64) ([]*UtxoEntry, uint64, error) {
+	candidates := bc.Utk.AllUtxoEntries(address)
+	balance := uint64(0)
+	for _, utxo := range candidates {
+		balance += utxo.Value
+	}
+	if balance < amount || len(candidates) == 0 {
+		return nil, 0, errors.Wrapf(ErrInsufficientFunds, "Address %s has balance %d, requesting %d", address, balance, amount)
+	}
+
+	cfg := bc.config.Chain
+	utxo, change := SelectCoins(candidates, amount, cfg.CoinSelection, cfg.DustThreshold, int(cfg.MaxTxInputs))
+	if utxo == nil {
+		return nil, 0, errors.Wrapf(ErrInsufficientFunds, "Address %s cannot pay %d with at most %d inputs", address,
+			amount, cfg.MaxTxInputs)
+	}
+	return utxo, change, nil
 }
 
-// CreateTransaction creates a signed transaction paying 'amount' from 'from' to 'to'
-func (bc *Blockchain) CreateTransaction(from iotxaddress.Address, amount uint64, to []*Payee) *Tx {
-	return bc.createTx(from, amount, to, false)
+// CreateTransaction creates a transaction paying 'amount' from 'from' to 'to', signed by the handle of 'from'
+func (bc *Blockchain) CreateTransaction(from wallet.Signer, amount uint64, to []*Payee) (*Tx, error) {
+	return bc.createTx(from.Address(), amount, to, from)
 }
 
 // CreateRawTransaction creates a unsigned transaction paying 'amount' from 'from' to 'to'
-func (bc *Blockchain) CreateRawTransaction(from iotxaddress.Address, amount uint64, to []*Payee) *Tx {
-	return bc.createTx(from, amount, to, true)
+func (bc *Blockchain) CreateRawTransaction(from iotxaddress.Address, amount uint64, to []*Payee) (*Tx, error) {
+	return bc.createTx(from.Address, amount, to, nil)
+}
+
+// SignTransaction signs again the inputs of the transaction spending the UTXO of the signer, once the transaction has
+// been changed, e.g., to set its lock time, since the signatures commit to the whole transaction
+func (bc *Blockchain) SignTransaction(tx *Tx, signer wallet.Signer) error {
+	pkHash := iotxaddress.GetPubkeyHash(signer.Address())
+	stream := tx.sigStream()
+	unlocks := make(map[int][]byte)
+	for i, in := range tx.TxIn {
+		utxo := bc.Utk.TxInputUtxo(in)
+		if utxo == nil {
+			return errors.Wrapf(ErrSigningFailed, "Tx spends unknown UTXO %x:%d", in.TxHash, in.OutIndex)
+		}
+		if !utxo.IsLockedWithKey(pkHash) {
+			continue
+		}
+		hash := sigHash(stream, i, utxo.TxOutputPb)
+		sig, err := signTxIn(signer, hash[:])
+		if err != nil {
+			return err
+		}
+		if unlocks[i], err = txvm.SignatureScriptWithSig(sig, signer.PublicKey()); err != nil {
+			return errors.Wrapf(ErrSigningFailed, "%v", err)
+		}
+	}
+
+	// only replace the unlock scripts once all inputs are signed
+	for i, unlock := range unlocks {
+		tx.TxIn[i].UnlockScript = unlock
+		tx.TxIn[i].UnlockScriptSize = uint32(len(unlock))
+	}
+	return nil
+}
+
+// signTxIn signs the blake2b hash of the txin with the signer
+func signTxIn(signer wallet.Signer, txin []byte) ([]byte, error) {
+	hash := blake2b.Sum256(txin)
+	sig, err := signer.Sign(hash[:])
+	if err != nil {
+		return nil, errors.Wrapf(ErrSigningFailed, "%v", err)
+	}
+	return sig, nil
 }
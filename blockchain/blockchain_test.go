@@ -7,16 +7,33 @@
 package blockchain
 
 import (
-	"errors"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 
+	"github.com/iotexproject/iotex-core/blockdb"
 	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/contract"
 	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/election"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	iproto "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
 	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/txvm"
+	"github.com/iotexproject/iotex-core/wallet"
 )
 
 const (
@@ -34,11 +51,14 @@ func addTestingBlocks(bc *Blockchain) error {
 	payee = append(payee, &Payee{ta.Addrinfo["delta"].Address, 70})
 	payee = append(payee, &Payee{ta.Addrinfo["echo"].Address, 110})
 	payee = append(payee, &Payee{ta.Addrinfo["foxtrot"].Address, 50 << 20})
-	tx := bc.CreateTransaction(ta.Addrinfo["miner"], 280+(50<<20), payee)
-	if tx == nil {
-		return errors.New("empty tx for block 1")
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 280+(50<<20), payee)
+	if err != nil {
+		return err
+	}
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	if err != nil {
+		return err
 	}
-	blk := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
 	if err := bc.AddBlockCommit(blk); err != nil {
 		return err
 	}
@@ -52,8 +72,14 @@ func addTestingBlocks(bc *Blockchain) error {
 	payee = append(payee, &Payee{ta.Addrinfo["charlie"].Address, 1})
 	payee = append(payee, &Payee{ta.Addrinfo["delta"].Address, 1})
 	payee = append(payee, &Payee{ta.Addrinfo["miner"].Address, 1})
-	tx = bc.CreateTransaction(ta.Addrinfo["charlie"], 5, payee)
-	blk = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["charlie"]), 5, payee)
+	if err != nil {
+		return err
+	}
+	blk, err = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	if err != nil {
+		return err
+	}
 	if err := bc.AddBlockCommit(blk); err != nil {
 		return err
 	}
@@ -64,8 +90,14 @@ func addTestingBlocks(bc *Blockchain) error {
 	payee = payee[1:]
 	payee[1] = &Payee{ta.Addrinfo["echo"].Address, 1}
 	payee[2] = &Payee{ta.Addrinfo["foxtrot"].Address, 1}
-	tx = bc.CreateTransaction(ta.Addrinfo["delta"], 4, payee)
-	blk = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["delta"]), 4, payee)
+	if err != nil {
+		return err
+	}
+	blk, err = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	if err != nil {
+		return err
+	}
 	if err := bc.AddBlockCommit(blk); err != nil {
 		return err
 	}
@@ -80,8 +112,14 @@ func addTestingBlocks(bc *Blockchain) error {
 	payee = append(payee, &Payee{ta.Addrinfo["delta"].Address, 2})
 	payee = append(payee, &Payee{ta.Addrinfo["foxtrot"].Address, 2})
 	payee = append(payee, &Payee{ta.Addrinfo["miner"].Address, 2})
-	tx = bc.CreateTransaction(ta.Addrinfo["echo"], 12, payee)
-	blk = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["echo"]), 12, payee)
+	if err != nil {
+		return err
+	}
+	blk, err = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	if err != nil {
+		return err
+	}
 	if err := bc.AddBlockCommit(blk); err != nil {
 		return err
 	}
@@ -101,7 +139,8 @@ func TestCreateBlockchain(t *testing.T) {
 	config.Chain.BlockReward = 0
 
 	// create chain
-	bc := CreateBlockchain(ta.Addrinfo["miner"].Address, config)
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
 	assert.NotNil(bc)
 	assert.Equal(0, int(bc.height))
 	fmt.Printf("Create blockchain pass, height = %d\n", bc.height)
@@ -115,7 +154,7 @@ func TestCreateBlockchain(t *testing.T) {
 	assert.Nil(err)
 
 	stream := genesis.ByteStream()
-	assert.Equal(uint32(len(stream)), genesis.TranxsSize()+92)
+	assert.Equal(uint32(len(stream)), genesis.TranxsSize()+124)
 	fmt.Printf("Block size match pass\n")
 	fmt.Printf("Marshaling Block pass\n")
 
@@ -147,16 +186,22 @@ func TestLoadBlockchainfromDB(t *testing.T) {
 	config.Chain.ChainDBPath = testDBPath
 	// Disable block reward to make bookkeeping easier
 	config.Chain.BlockReward = 0
+	dir, err := ioutil.TempDir("", "blocks")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	config.Chain.BlockArchiveDir = dir
 
 	// Create a blockchain from scratch
-	bc := CreateBlockchain(ta.Addrinfo["miner"].Address, config)
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
 	assert.NotNil(bc)
 	fmt.Printf("Open blockchain pass, height = %d\n", bc.height)
 	assert.Nil(addTestingBlocks(bc))
 	bc.Close()
 
 	// Load a blockchain from DB
-	bc = CreateBlockchain(ta.Addrinfo["miner"].Address, config)
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
 	defer bc.Close()
 	assert.NotNil(bc)
 
@@ -240,25 +285,31 @@ func TestLoadBlockchainfromDB(t *testing.T) {
 	// cannot add existing block again
 	blk, err = bc.GetBlockByHeight(3)
 	assert.NotNil(blk)
-	err = bc.commitBlock(blk)
+	err = bc.commitBlock(blk, nil, newBlockTrace(blk))
 	assert.NotNil(err)
 	fmt.Printf("Cannot add block 3 again: %v\n", err)
 
 	// read/write blocks from/to storage
-	err = bc.StoreBlock(1, 4)
+	err = bc.StoreBlock(context.Background(), 1, 4)
+	assert.Nil(err)
+	blk, err = bc.ReadBlock(1)
 	assert.Nil(err)
-	blk = bc.ReadBlock(1)
 	assert.Equal(hash1, blk.HashBlock())
 	fmt.Printf("Read block 1 hash match\n")
-	blk = bc.ReadBlock(2)
+	blk, err = bc.ReadBlock(2)
+	assert.Nil(err)
 	assert.Equal(hash2, blk.HashBlock())
 	fmt.Printf("Read block 2 hash match\n")
-	blk = bc.ReadBlock(3)
+	blk, err = bc.ReadBlock(3)
+	assert.Nil(err)
 	assert.Equal(hash3, blk.HashBlock())
 	fmt.Printf("Read block 3 hash match\n")
-	blk = bc.ReadBlock(4)
+	blk, err = bc.ReadBlock(4)
+	assert.Nil(err)
 	assert.Equal(hash4, blk.HashBlock())
 	fmt.Printf("Read block 4 hash match\n")
+	_, err = bc.ReadBlock(5)
+	assert.Equal(blockdb.ErrNotExist, errors.Cause(err))
 }
 
 func TestEmptyBlockOnlyHasCoinbaseTx(t *testing.T) {
@@ -269,15 +320,2855 @@ func TestEmptyBlockOnlyHasCoinbaseTx(t *testing.T) {
 	config.Chain.ChainDBPath = testDBPath
 	config.Chain.BlockReward = 7777
 
-	bc := CreateBlockchain(ta.Addrinfo["miner"].Address, config)
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
 	defer bc.Close()
 	assert.NotNil(t, bc)
 
-	blk := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
 	assert.Equal(t, uint32(1), blk.Height())
 	assert.Equal(t, 1, len(blk.Tranxs))
 	assert.True(t, blk.Tranxs[0].IsCoinbase())
 	assert.Equal(t, uint32(1), blk.Tranxs[0].NumTxIn)
 	assert.Equal(t, uint32(1), blk.Tranxs[0].NumTxOut)
 	assert.Equal(t, uint64(7777), blk.Tranxs[0].TxOut[0].Value)
+
+	// a producer with an empty pool mints a block of the coinbase only as well
+	blk, err = bc.MintNewBlockWithActions(nil, nil, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(blk.Tranxs))
+	assert.Equal(t, 0, len(blk.Actions()))
+	assert.Nil(t, bc.AddBlockCommit(blk))
+}
+
+func TestCreateTransactionInsufficientFunds(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+
+	payee := []*Payee{{ta.Addrinfo["bravo"].Address, 1}}
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["alfa"]), 1, payee)
+	assert.Nil(t, tx)
+	assert.Equal(t, ErrInsufficientFunds, errors.Cause(err))
+	tx, err = bc.CreateRawTransaction(ta.Addrinfo["alfa"], 1, payee)
+	assert.Nil(t, tx)
+	assert.Equal(t, ErrInsufficientFunds, errors.Cause(err))
+}
+
+func TestWatchOnly(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	w := wallet.NewWallet(config.Wallet{KeystorePath: dir, ScryptN: wallet.LightScryptN, ScryptP: wallet.LightScryptP})
+	assert.Nil(w.ImportAddress(ta.Addrinfo["alfa"].Address))
+	_, err = w.ImportPublicKey(ta.Addrinfo["bravo"].PublicKey)
+	assert.Nil(err)
+
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 30, []*Payee{
+		NewPayee(ta.Addrinfo["alfa"].Address, 10),
+		NewPayee(ta.Addrinfo["bravo"].Address, 20),
+	})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	// the UTXO and balances of the watch-only accounts are tracked
+	unspent, err := bc.ListWalletUnspent(w, 0, 0)
+	assert.Nil(err)
+	assert.Equal(2, len(unspent))
+	values := map[string]uint64{}
+	for _, utxo := range unspent {
+		values[utxo.Address] = utxo.Value
+	}
+	assert.Equal(uint64(10), values[ta.Addrinfo["alfa"].Address])
+	balances, err := bc.WalletBalances(w, 0)
+	assert.Nil(err)
+	assert.Equal(uint64(10), balances[ta.Addrinfo["alfa"].Address])
+
+	// the raw transaction of a watch-only account is signed elsewhere with its key
+	raw, err := bc.CreateRawTransaction(iotxaddress.Address{Address: ta.Addrinfo["alfa"].Address}, 10, []*Payee{NewPayee(ta.Addrinfo["charlie"].Address, 10)})
+	assert.Nil(err)
+	assert.NotNil(bc.Utk.ValidateTxScripts(raw))
+	assert.Nil(raw.Sign(wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+	assert.Nil(bc.Utk.ValidateTxScripts(raw))
+	blk, err = bc.MintNewBlock([]*Tx{raw}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	balances, err = bc.WalletBalances(w, 0)
+	assert.Nil(err)
+	assert.Equal(uint64(0), balances[ta.Addrinfo["alfa"].Address])
+	assert.Equal(uint64(10), bc.BalanceOf(ta.Addrinfo["charlie"].Address, 0))
+}
+
+func TestWalletDescriptors(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	commit := func(txs []*Tx) {
+		blk, err := bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+		assert.Nil(err)
+		bc.Reset()
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	w := wallet.NewWallet(config.Wallet{KeystorePath: dir, ScryptN: wallet.LightScryptN, ScryptP: wallet.LightScryptP, ChainID: 0x04030201})
+	alfa, bravo := ta.Addrinfo["alfa"], ta.Addrinfo["bravo"]
+	preimage := []byte("atomic swap secret")
+	hashLock := sha256.Sum256(preimage)
+	htlc, err := w.ImportDescriptor(fmt.Sprintf("htlc(%s,%s,%x,2)", alfa.Address, ta.Addrinfo["miner"].Address, hashLock))
+	assert.Nil(err)
+	multi, err := w.ImportDescriptor(fmt.Sprintf("multi(2,%x,%x)", alfa.PublicKey, bravo.PublicKey))
+	assert.Nil(err)
+	multisig, err := iotxaddress.CreateMultisigAddress(2, [][]byte{alfa.PublicKey, bravo.PublicKey}, false, []byte{0x01, 0x02, 0x03, 0x04})
+	assert.Nil(err)
+
+	// the outputs of the HTLC and the multisig address are recognized
+	tx, err := bc.CreateHTLCTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 50, alfa.Address, hashLock[:], 2)
+	assert.Nil(err)
+	commit([]*Tx{tx})
+	htlcHash := tx.Hash()
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 30, []*Payee{{multisig.Address, 30}})
+	assert.Nil(err)
+	commit([]*Tx{tx})
+	multisigHash := tx.Hash()
+
+	unspent, err := bc.ListWalletUnspent(w, 0, 0)
+	assert.Nil(err)
+	assert.Equal(2, len(unspent))
+	assert.Equal("", unspent[0].Address)
+	assert.Equal(htlc, unspent[0].Descriptor)
+	assert.Equal(txvm.HTLCScriptType, unspent[0].ScriptType)
+	assert.Equal(multisig.Address, unspent[1].Address)
+	assert.Equal(multi, unspent[1].Descriptor)
+	balances, err := bc.WalletBalances(w, 0)
+	assert.Nil(err)
+	assert.Equal(map[string]uint64{htlc: 50, multisig.Address: 30}, balances)
+
+	// the outputs are signed once the keys they require are unlocked
+	spend := func(hash cp.Hash32B, value uint64) *Tx {
+		in := bc.Utk.CreateTxInputUtxo(hash, 0, nil)
+		out := bc.Utk.CreateTxOutputUtxo(ta.Addrinfo["charlie"].Address, value)
+		tx := NewTx(1, []*TxInput{in}, []*TxOutput{out}, 0)
+		tx.ChainID = bc.chainID
+		return tx
+	}
+	redeem, multispend := spend(htlcHash, 50), spend(multisigHash, 30)
+	assert.Nil(w.ImportKey(&alfa, "passphrase"))
+	assert.Nil(w.Unlock(alfa.Address, "passphrase", 0))
+	assert.Equal(ErrSigningFailed, errors.Cause(bc.SignWalletTransaction(multispend, w, nil)))
+	assert.Nil(bc.SignWalletTransaction(redeem, w, preimage))
+	assert.Nil(w.ImportKey(&bravo, "passphrase"))
+	assert.Nil(w.Unlock(bravo.Address, "passphrase", 0))
+	assert.Nil(bc.SignWalletTransaction(multispend, w, nil))
+	commit([]*Tx{redeem, multispend})
+	assert.Equal(uint64(80), bc.BalanceOf(ta.Addrinfo["charlie"].Address, 0))
+	unspent, err = bc.ListWalletUnspent(w, 0, 0)
+	assert.Nil(err)
+	assert.Equal(0, len(unspent))
+}
+
+func TestStateRoot(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+
+	genesis, err := bc.GetBlockByHeight(0)
+	assert.Nil(t, err)
+	assert.NotEqual(t, cp.ZeroHash32B, genesis.StateRoot())
+
+	payee := []*Payee{{ta.Addrinfo["bravo"].Address, 1}}
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 1, payee)
+	assert.Nil(t, err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.NotEqual(t, genesis.StateRoot(), blk.StateRoot())
+
+	// the state root is part of the block hash and survives serialization
+	hash := blk.HashBlock()
+	buf, err := blk.Serialize()
+	assert.Nil(t, err)
+	copied := &Block{}
+	assert.Nil(t, copied.Deserialize(buf))
+	assert.Equal(t, blk.StateRoot(), copied.StateRoot())
+	assert.Equal(t, hash, copied.HashBlock())
+	copied.Header.stateRoot = genesis.StateRoot()
+	assert.NotEqual(t, hash, copied.HashBlock())
+
+	// a block committing to the same transactions but other states is rejected
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(bc.ValidateBlock(copied)))
+	assert.Nil(t, bc.AddBlockCommit(blk))
+}
+
+func TestValidateBlock(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 7777
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+
+	payee := []*Payee{{ta.Addrinfo["bravo"].Address, 1}}
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 1, payee)
+	assert.Nil(t, err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.Nil(t, bc.ValidateBlock(blk))
+
+	// tampered state root
+	root := blk.StateRoot()
+	blk.Header.stateRoot = cp.ZeroHash32B
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+	blk.Header.stateRoot = root
+
+	// tampered merkle root
+	blk.Header.merkleRoot = cp.ZeroHash32B
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+
+	// coinbase paying more than block reward
+	cbTx := NewCoinbaseTx(ta.Addrinfo["miner"].Address, 7778, "")
+	blk = NewBlock(0, 1, bc.TipHash(), []*Tx{tx, cbTx})
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+
+	// more than one coinbase
+	cbTx = NewCoinbaseTx(ta.Addrinfo["miner"].Address, 7777, "")
+	blk = NewBlock(0, 1, bc.TipHash(), []*Tx{tx, cbTx, NewCoinbaseTx(ta.Addrinfo["miner"].Address, 7777, "")})
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+
+	// input signed with the wrong private key, spending the UTXO reserved by tx
+	bc.Reset()
+	bc.ReleaseTxInputs(tx)
+	forged := iotxaddress.Address{
+		PrivateKey: ta.Addrinfo["bravo"].PrivateKey,
+		PublicKey:  ta.Addrinfo["miner"].PublicKey,
+		Address:    ta.Addrinfo["miner"].Address,
+	}
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(forged), 1, payee)
+	assert.Nil(t, err)
+	blk, err = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.NotNil(t, bc.ValidateBlock(blk))
+}
+
+func TestOverflowingOutputs(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+
+	// a signed transaction whose outputs wrap around to the value of its inputs, paying no fee
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 1, []*Payee{{ta.Addrinfo["alfa"].Address, 1}})
+	assert.Nil(t, err)
+	inputs := uint64(0)
+	for _, in := range tx.TxIn {
+		inputs += bc.Utk.TxInputUtxo(in).Value
+	}
+	assert.Equal(t, 2, len(tx.TxOut))
+	tx.TxOut[0].Value = math.MaxUint64
+	tx.TxOut[1].Value = inputs + 1
+	assert.Nil(t, bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo["miner"])))
+	assert.Equal(t, ErrMalformedTx, errors.Cause(bc.CheckTransaction(tx)))
+	_, err = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(t, ErrSupplyInvariant, errors.Cause(err))
+
+	// nor is a block carrying it accepted
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	blk.Tranxs = []*Tx{tx, blk.Tranxs[0]}
+	blk.Header.trnxNumber = uint32(len(blk.Tranxs))
+	blk.Header.merkleRoot = blk.MerkleRoot()
+	err = bc.ValidateBlock(blk)
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(err))
+	assert.Contains(t, fmt.Sprint(err), "overflow")
+	err = bc.AddBlockCommit(blk)
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(err))
+	assert.Contains(t, fmt.Sprint(err), "overflow")
+	assert.Equal(t, uint32(0), bc.TipHeight())
+	assert.Equal(t, uint64(0), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	assert.Nil(t, bc.Utk.verifySupply())
+}
+
+func TestBlockEventSubscription(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+
+	ch := bc.Subscribe()
+	genesis := bc.TipHash()
+	blk1, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	fork, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["alfa"].Address, "fork")
+	assert.Nil(t, err)
+
+	assert.Nil(t, bc.AddBlockCommit(blk1))
+	evt := <-ch
+	assert.Equal(t, BlockCommitted, evt.Type)
+	assert.Equal(t, blk1, evt.Block)
+	assert.Equal(t, genesis, evt.OldTip)
+
+	// the fork block is built on genesis rather than the current tip
+	assert.Nil(t, bc.AddBlockSync(fork))
+	evt = <-ch
+	assert.Equal(t, ChainReorged, evt.Type)
+	assert.Equal(t, blk1.HashBlock(), evt.OldTip)
+
+	bc.Unsubscribe(ch)
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestUtxoCommittedWithBlock(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
+	assert.Nil(addTestingBlocks(bc))
+	balances := map[string]uint64{}
+	for name, addr := range ta.Addrinfo {
+		balances[name] = bc.BalanceOf(addr.Address, 0)
+	}
+
+	// tx index points to the block containing the tx
+	blk, err := bc.GetBlockByHeight(2)
+	assert.Nil(err)
+	txHash := blk.Tranxs[0].Hash()
+	blkHash, err := bc.blockDb.GetTxBlockHash(txHash[:])
+	assert.Nil(err)
+	assert.Equal(blk.HashBlock(), byteToHash(blkHash))
+
+	// UTXO in Db is updated to the tip
+	utxos, height, err := bc.blockDb.Utxos()
+	assert.Nil(err)
+	assert.Equal(bc.TipHeight(), height)
+	assert.Equal(len(bc.UtxoPool()), len(utxos))
+	bc.Close()
+
+	// UTXO pool is loaded from Db
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
+	for name, addr := range ta.Addrinfo {
+		assert.Equal(balances[name], bc.BalanceOf(addr.Address, 0))
+	}
+
+	// UTXO lagging behind the tip is rebuilt from blocks
+	batch := blockdb.NewBatch()
+	batch.ClearUtxo()
+	batch.PutUtxoHeight(1)
+	assert.Nil(bc.blockDb.Commit(batch))
+	bc.Close()
+
+	// the rebuild is aborted once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = CreateBlockchain(ctx, ta.Addrinfo["miner"].Address, config)
+	assert.Equal(context.Canceled, errors.Cause(err))
+
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
+	defer bc.Close()
+	for name, addr := range ta.Addrinfo {
+		assert.Equal(balances[name], bc.BalanceOf(addr.Address, 0))
+	}
+	_, height, err = bc.blockDb.Utxos()
+	assert.Nil(err)
+	assert.Equal(bc.TipHeight(), height)
+}
+
+func TestCreateBlockchainFromGenesis(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.GenesisPath = "../genesis.json"
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+	assert.Equal(t, uint32(1), bc.chainID)
+
+	// initial allocations are minted in the genesis block
+	genesis, err := bc.GetBlockByHeight(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(genesis.Tranxs))
+	assert.True(t, genesis.Tranxs[0].IsCoinbase())
+	assert.Equal(t, uint64(9000000000), bc.BalanceOf(ta.Addrinfo["miner"].Address, 0))
+	assert.Equal(t, uint64(500000000), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	assert.Equal(t, uint64(500000000), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0))
+
+	// the allocation in the last genesis output can be spent
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["bravo"]), 100, []*Payee{{ta.Addrinfo["charlie"].Address, 100}})
+	assert.Nil(t, err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), blk.Header.chainID)
+	assert.Nil(t, bc.AddBlockCommit(blk))
+	bc.Reset()
+	assert.Equal(t, uint64(100), bc.BalanceOf(ta.Addrinfo["charlie"].Address, 0))
+	assert.Equal(t, uint64(9000000005), bc.BalanceOf(ta.Addrinfo["miner"].Address, 0))
+
+	// block reward follows the schedule in genesis
+	assert.Equal(t, uint64(5), bc.blockReward(999999))
+	assert.Equal(t, uint64(3), bc.blockReward(1000000))
+
+	// block of another chain is rejected
+	blk = NewBlock(0, 2, bc.TipHash(), []*Tx{NewCoinbaseTx(ta.Addrinfo["miner"].Address, 5, "")})
+	err = bc.ValidateBlock(blk)
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(err))
+}
+
+func TestAccountTransfers(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	genesis := &config.Genesis{
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+		Accounts:    []config.Allocation{{Address: ta.Addrinfo["alfa"].Address, Amount: 50}},
+	}
+
+	bc, err := CreateBlockchainWithGenesis(context.Background(), cfg, genesis)
+	assert.Nil(err)
+	assert.Equal(uint64(50), bc.AccountState(ta.Addrinfo["alfa"].Address).Balance)
+
+	alfa := wallet.NewKeySigner(ta.Addrinfo["alfa"])
+	tsf := NewTransfer(1, 20, ta.Addrinfo["alfa"].Address, ta.Addrinfo["bravo"].Address)
+	assert.NotNil(tsf.Sign(wallet.NewKeySigner(ta.Addrinfo["bravo"])))
+	assert.Nil(tsf.Sign(alfa))
+	blk, err := bc.MintNewBlockWithTransfers([]*Tx{}, []*Transfer{tsf}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+	assert.Equal(&state.Account{Nonce: 1, Balance: 30}, bc.AccountState(ta.Addrinfo["alfa"].Address))
+	assert.Equal(&state.Account{Balance: 20}, bc.AccountState(ta.Addrinfo["bravo"].Address))
+
+	// the transfers are part of the block read back from Db
+	blk, err = bc.GetBlockByHeight(1)
+	assert.Nil(err)
+	assert.Equal(1, len(blk.Transfers))
+	assert.Equal(tsf.Hash(), blk.Transfers[0].Hash())
+
+	// replayed nonce, overdraft and forged signature are rejected, no block can commit to their states
+	tsf = NewTransfer(1, 10, ta.Addrinfo["alfa"].Address, ta.Addrinfo["bravo"].Address)
+	assert.Nil(tsf.Sign(alfa))
+	_, err = bc.MintNewBlockWithTransfers([]*Tx{}, []*Transfer{tsf}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+	tsf = NewTransfer(2, 31, ta.Addrinfo["alfa"].Address, ta.Addrinfo["bravo"].Address)
+	assert.Nil(tsf.Sign(alfa))
+	_, err = bc.MintNewBlockWithTransfers([]*Tx{}, []*Transfer{tsf}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+	tsf = NewTransfer(2, 10, ta.Addrinfo["alfa"].Address, ta.Addrinfo["bravo"].Address)
+	assert.Nil(tsf.Sign(alfa))
+	tsf.Amount = 30
+	assert.Equal(ErrInvalidTransfer, errors.Cause(tsf.Verify()))
+
+	// the addresses of the transfers and the payees have to be of the network of the chain
+	testnet, err := iotxaddress.ConvertNetwork(ta.Addrinfo["bravo"].Address, true)
+	assert.Nil(err)
+	tsf = NewTransfer(2, 10, ta.Addrinfo["alfa"].Address, testnet)
+	assert.Nil(tsf.Sign(alfa))
+	_, err = bc.MintNewBlockWithTransfers([]*Tx{}, []*Transfer{tsf}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+	_, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{NewPayee(testnet, 10)})
+	assert.Equal(iotxaddress.ErrInvalidNetwork, errors.Cause(err))
+
+	// the account states are persisted with the UTXO, and rebuilt from the blocks if the UTXO is not up to date
+	bc.Close()
+	bc, err = CreateBlockchainWithGenesis(context.Background(), cfg, genesis)
+	assert.Nil(err)
+	assert.Equal(&state.Account{Nonce: 1, Balance: 30}, bc.AccountState(ta.Addrinfo["alfa"].Address))
+	batch := blockdb.NewBatch()
+	batch.PutUtxoHeight(0)
+	batch.ClearAccounts()
+	assert.Nil(bc.blockDb.Commit(batch))
+	bc.Close()
+	bc, err = CreateBlockchainWithGenesis(context.Background(), cfg, genesis)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(&state.Account{Nonce: 1, Balance: 30}, bc.AccountState(ta.Addrinfo["alfa"].Address))
+	assert.Equal(&state.Account{Balance: 20}, bc.AccountState(ta.Addrinfo["bravo"].Address))
+}
+
+func TestCrossChainTransfer(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBBackend = "MEMORY"
+	cfg.Chain.CrossChainConfirmations = 1
+	src, err := CreateBlockchainWithGenesis(context.Background(), cfg, &config.Genesis{
+		ChainID:     1,
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+		Accounts:    []config.Allocation{{Address: ta.Addrinfo["alfa"].Address, Amount: 50}},
+	})
+	assert.Nil(err)
+	defer src.Close()
+	dst, err := CreateBlockchainWithGenesis(context.Background(), cfg, &config.Genesis{
+		ChainID:     2,
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+	})
+	assert.Nil(err)
+	defer dst.Close()
+	dst.SetPeerChain(src)
+
+	// the deposit leaves the source chain
+	alfa := wallet.NewKeySigner(ta.Addrinfo["alfa"])
+	deposit := NewDeposit(1, 20, ta.Addrinfo["alfa"].Address, 1, 2, ta.Addrinfo["bravo"].Address)
+	assert.Nil(deposit.Sign(alfa))
+	blk, err := src.MintNewBlockWithDeposits([]*Tx{}, nil, nil, nil, nil, []*Deposit{deposit}, nil, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(src.AddBlockCommit(blk))
+	assert.Equal(&state.Account{Nonce: 1, Balance: 30}, src.AccountState(ta.Addrinfo["alfa"].Address))
+	blk, err = src.GetBlockByHeight(1)
+	assert.Nil(err)
+	assert.Equal(1, len(blk.Deposits))
+	assert.Equal(deposit.Hash(), blk.Deposits[0].Hash())
+
+	// a deposit signed for another chain or staying on the chain is rejected
+	other := NewDeposit(2, 10, ta.Addrinfo["alfa"].Address, 2, 1, ta.Addrinfo["bravo"].Address)
+	assert.Nil(other.Sign(alfa))
+	_, err = src.MintNewBlockWithDeposits([]*Tx{}, nil, nil, nil, nil, []*Deposit{other}, nil, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+	other = NewDeposit(2, 10, ta.Addrinfo["alfa"].Address, 1, 1, ta.Addrinfo["bravo"].Address)
+	assert.Nil(other.Sign(alfa))
+	assert.Equal(ErrInvalidDeposit, errors.Cause(other.Verify()))
+	_, err = src.MintNewBlockWithDeposits([]*Tx{}, nil, nil, nil, nil, []*Deposit{other}, nil, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+
+	// the deposit is withdrawn on the destination chain once confirmed
+	withdraw, err := NewWithdraw(deposit, blk)
+	assert.Nil(err)
+	assert.Nil(withdraw.Verify())
+	_, err = dst.MintNewBlockWithDeposits([]*Tx{}, nil, nil, nil, nil, nil, []*Withdraw{withdraw}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+	blk, err = src.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(src.AddBlockCommit(blk))
+	withdrawn, err := dst.IsWithdrawn(deposit.Hash())
+	assert.Nil(err)
+	assert.False(withdrawn)
+	blk, err = dst.MintNewBlockWithDeposits([]*Tx{}, nil, nil, nil, nil, nil, []*Withdraw{withdraw}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(dst.AddBlockCommit(blk))
+	assert.Equal(&state.Account{Balance: 20}, dst.AccountState(ta.Addrinfo["bravo"].Address))
+	withdrawn, err = dst.IsWithdrawn(deposit.Hash())
+	assert.Nil(err)
+	assert.True(withdrawn)
+
+	// the withdraw is read back from Db
+	blk, err = dst.GetBlockByHeight(1)
+	assert.Nil(err)
+	assert.Equal(1, len(blk.Withdraws))
+	assert.Equal(withdraw.Hash(), blk.Withdraws[0].Hash())
+	assert.Nil(blk.Withdraws[0].Verify())
+
+	// a deposit is withdrawn only once
+	_, err = dst.MintNewBlockWithDeposits([]*Tx{}, nil, nil, nil, nil, nil, []*Withdraw{withdraw}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+
+	// a tampered deposit or a header off the source chain is rejected
+	deposit = NewDeposit(2, 10, ta.Addrinfo["alfa"].Address, 1, 2, ta.Addrinfo["bravo"].Address)
+	assert.Nil(deposit.Sign(alfa))
+	blk, err = src.MintNewBlockWithDeposits([]*Tx{}, nil, nil, nil, nil, []*Deposit{deposit}, nil, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	withdraw, err = NewWithdraw(deposit, blk)
+	assert.Nil(err)
+	_, err = dst.MintNewBlockWithDeposits([]*Tx{}, nil, nil, nil, nil, nil, []*Withdraw{withdraw}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+	withdraw.Deposit.Amount = 30
+	assert.Equal(ErrInvalidWithdraw, errors.Cause(withdraw.Verify()))
+}
+
+func TestDeployments(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBBackend = "MEMORY"
+	cfg.Chain.AcceptLegacyTxs = true
+	cfg.Chain.SignalWindow = 4
+	cfg.Chain.SignalThreshold = 3
+	cfg.Chain.Deployments = []config.Deployment{
+		{Name: DeploymentStrictChainID, Bit: 1, StartHeight: 4, TimeoutHeight: 40},
+		{Name: "unsignaled", Bit: 2, StartHeight: 4, TimeoutHeight: 12},
+	}
+	cfg.Chain.SignalDeployments = []string{DeploymentStrictChainID}
+	bc, err := CreateBlockchainWithGenesis(context.Background(), cfg, &config.Genesis{
+		ChainID:     1,
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+	})
+	assert.Nil(err)
+	defer bc.Close()
+
+	legacy, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["bravo"].Address, 10}})
+	assert.Nil(err)
+	legacy.ChainID = 0
+	states := func(height uint32) []DeploymentState {
+		strict, err := bc.DeploymentState(DeploymentStrictChainID, height)
+		assert.Nil(err)
+		unsignaled, err := bc.DeploymentState("unsignaled", height)
+		assert.Nil(err)
+		return []DeploymentState{strict, unsignaled}
+	}
+	for height := uint32(1); height < 12; height++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		// only the started deployments to signal are signaled
+		assert.Equal(height >= 4 && height < 8, SignalsBit(blk.Version(), 1))
+		assert.False(SignalsBit(blk.Version(), 2))
+		assert.Nil(bc.ValidateTxChainID(legacy))
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+	assert.Equal([]DeploymentState{DeploymentDefined, DeploymentDefined}, states(3))
+	assert.Equal([]DeploymentState{DeploymentStarted, DeploymentStarted}, states(7))
+	assert.Equal([]DeploymentState{DeploymentLockedIn, DeploymentStarted}, states(8))
+	assert.Equal([]DeploymentState{DeploymentActive, DeploymentFailed}, states(12))
+	_, err = bc.DeploymentState("unknown", 12)
+	assert.Equal(ErrUnknownDeployment, errors.Cause(err))
+
+	// the rule is enforced from the activation height
+	height, active, err := bc.ActivationHeight(DeploymentStrictChainID)
+	assert.Nil(err)
+	assert.True(active)
+	assert.Equal(uint32(12), height)
+	assert.Equal(ErrTxWrongChainID, errors.Cause(bc.ValidateTxChainID(legacy)))
+	_, active, err = bc.ActivationHeight("unsignaled")
+	assert.Nil(err)
+	assert.False(active)
+
+	// the blocks no longer signal once the deployment is locked in
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Equal(VersionBitsTop, blk.Version())
+}
+
+func TestContractExecution(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockGasLimit = 20000
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	alfa := wallet.NewKeySigner(ta.Addrinfo["alfa"])
+	mint := func(execs ...*Execution) (*Block, error) {
+		for _, exec := range execs {
+			assert.Nil(exec.Sign(alfa))
+		}
+		return bc.MintNewBlockWithExecutions([]*Tx{}, nil, execs, ta.Addrinfo["miner"].Address, "")
+	}
+
+	// the counter adds the first argument to the count in its storage and returns the new count
+	code, err := contract.NewCodeBuilder().
+		AddPush([]byte("count")).AddOps(contract.OpSLoad).
+		AddPushUint64(0).AddOps(contract.OpArg, contract.OpAdd, contract.OpDup).
+		AddPush([]byte("count")).AddOps(contract.OpSStore, contract.OpReturn).
+		Code()
+	assert.Nil(err)
+	deploy := NewDeployment(1, ta.Addrinfo["alfa"].Address, code, 10000)
+	blk, err := mint(deploy)
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	receipt, err := bc.GetReceipt(deploy.Hash())
+	assert.Nil(err)
+	assert.Equal(uint32(ReceiptSuccess), receipt.Status)
+	addr, err := iotxaddress.CreateContractAddress(ta.Addrinfo["alfa"].Address, 1)
+	assert.Nil(err)
+	assert.Equal(addr, receipt.ContractAddress)
+	assert.Equal(code, bc.ContractCode(addr))
+
+	// a successful invocation writes the storage, a failed one only consumes the nonce and the gas
+	invoke := NewInvocation(2, ta.Addrinfo["alfa"].Address, addr, [][]byte{contract.Uint64Bytes(5)}, 10000)
+	starved := NewInvocation(3, ta.Addrinfo["alfa"].Address, addr, [][]byte{contract.Uint64Bytes(5)}, contract.ExecutionGas+10)
+	blk, err = mint(invoke, starved)
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	receipt, err = bc.GetReceipt(invoke.Hash())
+	assert.Nil(err)
+	assert.Equal(uint32(ReceiptSuccess), receipt.Status)
+	assert.Equal(contract.Uint64Bytes(5), receipt.ReturnValue)
+	assert.True(receipt.GasUsed > contract.ExecutionGas)
+	receipt, err = bc.GetReceipt(starved.Hash())
+	assert.Nil(err)
+	assert.Equal(uint32(ReceiptFailure), receipt.Status)
+	assert.Equal(starved.GasLimit, receipt.GasUsed)
+	assert.Equal(contract.Uint64Bytes(5), bc.ContractState(addr, []byte("count")))
+	assert.Equal(uint64(3), bc.AccountState(ta.Addrinfo["alfa"].Address).Nonce)
+
+	// replayed nonce, tampered execution and the block gas limit are rejected
+	_, err = mint(NewInvocation(3, ta.Addrinfo["alfa"].Address, addr, nil, 10000))
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+	tampered := NewInvocation(4, ta.Addrinfo["alfa"].Address, addr, nil, 10000)
+	assert.Nil(tampered.Sign(alfa))
+	tampered.Args = [][]byte{contract.Uint64Bytes(1)}
+	assert.Equal(ErrInvalidExecution, errors.Cause(tampered.Verify()))
+	_, err = mint(NewInvocation(4, ta.Addrinfo["alfa"].Address, addr, nil, 15000), NewInvocation(5, ta.Addrinfo["alfa"].Address, addr, nil, 15000))
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+
+	// the executions are part of the block read back from Db
+	blk, err = bc.GetBlockByHeight(2)
+	assert.Nil(err)
+	assert.Equal(2, len(blk.Executions))
+	assert.Equal(invoke.Hash(), blk.Executions[0].Hash())
+
+	// the contracts are persisted with the UTXO, and rebuilt from the blocks if the UTXO is not up to date
+	bc.Close()
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	assert.Equal(contract.Uint64Bytes(5), bc.ContractState(addr, []byte("count")))
+	batch := blockdb.NewBatch()
+	batch.PutUtxoHeight(0)
+	batch.ClearContracts()
+	assert.Nil(bc.blockDb.Commit(batch))
+	bc.Close()
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(code, bc.ContractCode(addr))
+	assert.Equal(contract.Uint64Bytes(5), bc.ContractState(addr, []byte("count")))
+
+	// the storage written and the contracts deployed by the blocks rolled back are unwound along with their receipts
+	assert.Nil(bc.RollbackToHeight(context.Background(), 1))
+	assert.Nil(bc.ContractState(addr, []byte("count")))
+	assert.Equal(code, bc.ContractCode(addr))
+	_, err = bc.GetReceipt(invoke.Hash())
+	assert.NotNil(err)
+	assert.Equal(uint64(1), bc.AccountState(ta.Addrinfo["alfa"].Address).Nonce)
+	assert.Nil(bc.RollbackToHeight(context.Background(), 0))
+	assert.Nil(bc.ContractCode(addr))
+	codes, err := bc.blockDb.Codes()
+	assert.Nil(err)
+	assert.Equal(0, len(codes))
+}
+
+func TestContractLogs(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	alfa := wallet.NewKeySigner(ta.Addrinfo["alfa"])
+	commit := func(execs ...*Execution) {
+		for _, exec := range execs {
+			assert.Nil(exec.Sign(alfa))
+		}
+		blk, err := bc.MintNewBlockWithExecutions([]*Tx{}, nil, execs, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+
+	// the contract emits a log with the topics "transfer" and the first argument, and the second argument as data
+	code, err := contract.NewCodeBuilder().
+		AddPush([]byte("transfer")).AddPushUint64(0).AddOps(contract.OpArg).
+		AddPushUint64(1).AddOps(contract.OpArg, contract.OpLog2, contract.OpStop).
+		Code()
+	assert.Nil(err)
+	commit(NewDeployment(1, ta.Addrinfo["alfa"].Address, code, 10000))
+	addr, err := iotxaddress.CreateContractAddress(ta.Addrinfo["alfa"].Address, 1)
+	assert.Nil(err)
+	invoke := func(nonce uint64, to string, amount uint64) *Execution {
+		return NewInvocation(nonce, ta.Addrinfo["alfa"].Address, addr, [][]byte{[]byte(to), contract.Uint64Bytes(amount)}, 10000)
+	}
+	toBravo := invoke(2, "bravo", 5)
+	commit(toBravo)
+	commit()
+	commit(invoke(3, "charlie", 7), invoke(4, "bravo", 9))
+
+	// the receipt records the logs and the height of the block
+	receipt, err := bc.GetReceipt(toBravo.Hash())
+	assert.Nil(err)
+	assert.Equal(uint32(2), receipt.BlockHeight)
+	assert.Equal(1, len(receipt.Logs))
+	assert.Equal(&Log{
+		Address:     addr,
+		Topics:      [][]byte{[]byte("transfer"), []byte("bravo")},
+		Data:        contract.Uint64Bytes(5),
+		Hash:        toBravo.Hash(),
+		BlockHeight: 2,
+	}, receipt.Logs[0])
+
+	// the blocks without a log are told apart by their bloom filter
+	buf, err := bc.blockDb.GetBloom(3)
+	assert.Nil(err)
+	assert.Equal(make([]byte, BloomSize), buf)
+	buf, err = bc.blockDb.GetBloom(4)
+	assert.Nil(err)
+	bloom := &Bloom{}
+	copy(bloom[:], buf)
+	assert.True(bloom.Test([]byte(addr)))
+	assert.True(bloom.Test([]byte("charlie")))
+
+	logs, err := bc.GetLogs(context.Background(), 0, 4, &LogFilter{Topics: [][]byte{[]byte("bravo")}})
+	assert.Nil(err)
+	assert.Equal(2, len(logs))
+	assert.Equal(uint32(2), logs[0].BlockHeight)
+	assert.Equal(uint32(4), logs[1].BlockHeight)
+	assert.Equal(contract.Uint64Bytes(9), logs[1].Data)
+	logs, err = bc.GetLogs(context.Background(), 3, 4, &LogFilter{Addresses: []string{addr}})
+	assert.Nil(err)
+	assert.Equal(2, len(logs))
+	logs, err = bc.GetLogs(context.Background(), 0, 4, &LogFilter{Addresses: []string{addr}, Topics: [][]byte{[]byte("delta")}})
+	assert.Nil(err)
+	assert.Equal(0, len(logs))
+	logs, err = bc.GetLogs(context.Background(), 0, 4, &LogFilter{Addresses: []string{ta.Addrinfo["bravo"].Address}})
+	assert.Nil(err)
+	assert.Equal(0, len(logs))
+	_, err = bc.GetLogs(context.Background(), 0, 5, &LogFilter{})
+	assert.NotNil(err)
+
+	// the receipts of a block are not read if its bloom filter rules the query out
+	batch := blockdb.NewBatch()
+	batch.PutBloom(4, make([]byte, BloomSize))
+	assert.Nil(bc.blockDb.Commit(batch))
+	logs, err = bc.GetLogs(context.Background(), 4, 4, &LogFilter{})
+	assert.Nil(err)
+	assert.Equal(0, len(logs))
+}
+
+type fakeConsensus struct {
+	err       error
+	finalized int
+}
+
+func (c *fakeConsensus) ValidateHeader(blk *Block, parent *Block) error { return c.err }
+
+func (c *fakeConsensus) VerifyProposer(blk *Block) error { return c.err }
+
+func (c *fakeConsensus) FinalizeBlock(blk *Block) error {
+	c.finalized++
+	return nil
+}
+
+func TestValidateBlockWithConsensus(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+
+	engine := &fakeConsensus{}
+	bc.SetConsensus(engine)
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, engine.finalized)
+	assert.Nil(t, bc.ValidateBlock(blk))
+
+	engine.err = errors.New("rejected")
+	err = bc.ValidateBlock(blk)
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(err))
+}
+
+func TestCoinbaseMaturity(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 10
+	config.Chain.CoinbaseMaturity = 2
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+
+	// genesis allocation is spendable right away
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["bravo"].Address, 10}})
+	assert.Nil(t, err)
+	assert.Nil(t, bc.ValidateCoinbaseMaturity(tx))
+
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["alfa"].Address, "")
+	assert.Nil(t, err)
+	assert.Nil(t, bc.AddBlockCommit(blk))
+	bc.Reset()
+	height, ok := bc.Utk.CoinbaseHeight(blk.Tranxs[0].Hash())
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), height)
+
+	// coinbase minted at height 1 cannot be spent at height 2
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["alfa"]), 10, []*Payee{{ta.Addrinfo["bravo"].Address, 10}})
+	assert.Nil(t, err)
+	assert.NotNil(t, bc.ValidateCoinbaseMaturity(tx))
+	premature := NewBlock(0, 2, bc.TipHash(), []*Tx{tx, NewCoinbaseTx(ta.Addrinfo["miner"].Address, 10, "")})
+	err = bc.ValidateBlock(premature)
+	assert.NotNil(t, err)
+	bc.Close()
+
+	// coinbase height is persisted along with the UTXO
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+	height, ok = bc.Utk.CoinbaseHeight(blk.Tranxs[0].Hash())
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), height)
+
+	blk, err = bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.Nil(t, bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	// it can be spent at height 3
+	assert.Nil(t, bc.ValidateCoinbaseMaturity(tx))
+	blk, err = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.Nil(t, bc.AddBlockCommit(blk))
+	assert.Equal(t, uint64(10), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0))
+	// spent coinbase is no longer tracked
+	_, ok = bc.Utk.CoinbaseHeight(byteToHash(tx.TxIn[0].TxHash))
+	assert.False(t, ok)
+}
+
+func TestPruning(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 5
+	config.Chain.Pruning = true
+	config.Chain.PruneRetention = 2
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	hashes := []cp.Hash32B{bc.TipHash()}
+	for i := 0; i < 5; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["alfa"].Address, "")
+		assert.Nil(t, err)
+		assert.Nil(t, bc.AddBlockCommit(blk))
+		bc.Reset()
+		hashes = append(hashes, blk.HashBlock())
+	}
+	assert.Equal(t, uint32(4), bc.pruneHeight)
+	bc.Close()
+
+	// prune height survives restart
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+	assert.Equal(t, uint32(4), bc.pruneHeight)
+
+	// block bodies below prune height are gone, the headers are kept
+	for h := uint32(0); h < 4; h++ {
+		_, err := bc.GetBlockByHeight(h)
+		assert.Equal(t, ErrBlockPruned, errors.Cause(err))
+		_, err = bc.GetBlockByHash(hashes[h])
+		assert.Equal(t, ErrBlockPruned, errors.Cause(err))
+		header, err := bc.GetBlockHeaderByHeight(h)
+		assert.Nil(t, err)
+		assert.Equal(t, hashes[h], header.HashBlock())
+		// so is the metadata
+		meta, err := bc.GetBlockMetaByHeight(h)
+		assert.Nil(t, err)
+		assert.Equal(t, hashes[h], meta.Hash)
+		assert.NotZero(t, meta.Size)
+	}
+	for h := uint32(4); h <= 5; h++ {
+		blk, err := bc.GetBlockByHeight(h)
+		assert.Nil(t, err)
+		assert.Equal(t, hashes[h], blk.HashBlock())
+		header, err := bc.GetBlockHeaderByHeight(h)
+		assert.Nil(t, err)
+		assert.Equal(t, hashes[h], header.HashBlock())
+	}
+
+	// UTXO set is kept
+	assert.Equal(t, uint64(25), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+}
+
+func TestUtxoSnapshot(t *testing.T) {
+	defer os.Remove(testDBPath)
+	snapshotDBPath := "db.snapshot"
+	defer os.Remove(snapshotDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 5
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+	for i := 0; i < 3; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["alfa"].Address, "")
+		assert.Nil(t, err)
+		assert.Nil(t, bc.AddBlockCommit(blk))
+		bc.Reset()
+	}
+	_, _, err = bc.ExportUtxoSnapshot(2)
+	assert.Equal(t, ErrInvalidSnapshot, errors.Cause(err))
+	snapshot, commitment, err := bc.ExportUtxoSnapshot(3)
+	assert.Nil(t, err)
+
+	// the UTXO set of the snapshot is verified against the commitment of the chain
+	height, utxoCommitment, err := SnapshotUtxoCommitment(snapshot)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), height)
+	expected, err := bc.UtxoCommitment(3)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, utxoCommitment)
+
+	// the UTXO set round-trips through serialization
+	buf, err := bc.Utk.Serialize()
+	assert.Nil(t, err)
+	tk := NewUtxoTracker()
+	assert.Nil(t, tk.Deserialize(buf))
+	assert.Equal(t, bc.Utk.GetPool(), tk.GetPool())
+
+	// import into a fresh chain
+	config.Chain.ChainDBPath = snapshotDBPath
+	fresh, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer fresh.Close()
+	err = fresh.ImportUtxoSnapshot(snapshot, cp.ZeroHash32B)
+	assert.Equal(t, ErrInvalidSnapshot, errors.Cause(err))
+	assert.Equal(t, ErrInvalidSnapshot, errors.Cause(fresh.ImportUtxoSnapshot(snapshot[1:], commitment)))
+	assert.Nil(t, fresh.ImportUtxoSnapshot(snapshot, commitment))
+	assert.Equal(t, uint32(3), fresh.TipHeight())
+	utxoCommitment, err = fresh.UtxoCommitment(3)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, utxoCommitment)
+	assert.Equal(t, bc.TipHash(), fresh.TipHash())
+	assert.Equal(t, uint64(15), fresh.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	assert.Equal(t, bc.BalanceOf(ta.Addrinfo["miner"].Address, 0), fresh.BalanceOf(ta.Addrinfo["miner"].Address, 0))
+	_, err = fresh.GetBlockByHeight(2)
+	assert.Equal(t, ErrBlockPruned, errors.Cause(err))
+
+	// only an empty chain can import
+	assert.Equal(t, ErrInvalidSnapshot, errors.Cause(fresh.ImportUtxoSnapshot(snapshot, commitment)))
+
+	// blocks on top of the snapshot are accepted by both chains
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["bravo"].Address, "")
+	assert.Nil(t, err)
+	assert.Nil(t, bc.AddBlockCommit(blk))
+	assert.Nil(t, fresh.AddBlockCommit(blk))
+	assert.Equal(t, bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0), fresh.BalanceOf(ta.Addrinfo["bravo"].Address, 0))
+	expected, err = bc.UtxoCommitment(4)
+	assert.Nil(t, err)
+	utxoCommitment, err = fresh.UtxoCommitment(4)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, utxoCommitment)
+	fresh.Close()
+
+	// the snapshot survives restart
+	fresh, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(4), fresh.TipHeight())
+	assert.Equal(t, uint64(15), fresh.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+}
+
+func TestUtxoCommitment(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	for _, name := range []string{"alfa", "bravo", "charlie"} {
+		tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo[name].Address, 10}})
+		assert.Nil(err)
+		blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		bc.Reset()
+	}
+
+	// every block changes the commitment, and the rolling one of the tip matches the UTXO set hashed from scratch
+	commitments := map[cp.Hash32B]bool{}
+	for h := uint32(0); h <= bc.TipHeight(); h++ {
+		commitment, err := bc.UtxoCommitment(h)
+		assert.Nil(err)
+		commitments[commitment] = true
+	}
+	assert.Equal(4, len(commitments))
+	buf, err := bc.Utk.Serialize()
+	assert.Nil(err)
+	tk := NewUtxoTracker()
+	assert.Nil(tk.Deserialize(buf))
+	tip, err := bc.UtxoCommitment(bc.TipHeight())
+	assert.Nil(err)
+	assert.Equal(tip, tk.Commitment())
+	assert.Nil(bc.VerifyChain(context.Background(), 0))
+	_, err = bc.UtxoCommitment(bc.TipHeight() + 1)
+	assert.Equal(blockdb.ErrNotExist, errors.Cause(err))
+	bc.Close()
+
+	// the commitments survive restart
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(tip, bc.Utk.Commitment())
+	for h := uint32(0); h <= bc.TipHeight(); h++ {
+		commitment, err := bc.UtxoCommitment(h)
+		assert.Nil(err)
+		assert.True(commitments[commitment])
+	}
+}
+
+func TestChainStats(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	genesis, err := bc.GetBlockByHeight(0)
+	assert.Nil(err)
+	for i, name := range []string{"alfa", "bravo", "charlie"} {
+		tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), uint64(10*(i+1)), []*Payee{{ta.Addrinfo[name].Address, uint64(10 * (i + 1))}})
+		assert.Nil(err)
+		blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		bc.Reset()
+	}
+
+	meta := bc.ChainMeta()
+	assert.Equal(uint32(3), meta.Height)
+	assert.Equal(uint64(len(genesis.Tranxs)+6), meta.TotalTxs)
+	assert.Equal([]uint32{uint32(len(genesis.Tranxs)), 2, 2, 2}, meta.RecentTxCounts)
+	assert.Equal(bc.CirculatingSupply(), meta.CirculatingSupply)
+	total, err := bc.countTxs(context.Background())
+	assert.Nil(err)
+	assert.Equal(meta.TotalTxs, total)
+
+	// the holders are ranked by the balance of their addresses
+	holders := bc.TopHolders(MaxTopHolders + 1)
+	assert.Equal(int(meta.NumHolders), len(holders))
+	for i := 1; i < len(holders); i++ {
+		assert.True(holders[i-1].Balance >= holders[i].Balance)
+	}
+	balances := map[string]uint64{}
+	for _, holder := range holders {
+		balances[string(holder.PubKeyHash)] = holder.Balance
+	}
+	for _, name := range []string{"miner", "alfa", "bravo", "charlie"} {
+		addr := ta.Addrinfo[name].Address
+		assert.Equal(bc.BalanceOf(addr, 0), balances[string(iotxaddress.GetPubkeyHash(addr))])
+	}
+	assert.Equal(2, len(bc.TopHolders(2)))
+	bc.Close()
+
+	// the statistics survive restart
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(meta, bc.ChainMeta())
+	assert.Equal(holders, bc.TopHolders(MaxTopHolders))
+}
+
+func TestMultisigTransaction(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+
+	// 2-of-3 multisig of alfa, bravo and charlie
+	pubkeys := [][]byte{ta.Addrinfo["alfa"].PublicKey, ta.Addrinfo["bravo"].PublicKey, ta.Addrinfo["charlie"].PublicKey}
+	multisig, err := iotxaddress.CreateMultisigAddress(2, pubkeys, false, []byte{0x01, 0x02, 0x03, 0x04})
+	assert.Nil(t, err)
+
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 100, []*Payee{{multisig.Address, 100}})
+	assert.Nil(t, err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.Nil(t, bc.AddBlockCommit(blk))
+	bc.Reset()
+	assert.Equal(t, uint64(100), bc.BalanceOf(multisig.Address, 0))
+
+	// the multisig address cannot be spent with a single key
+	_, err = bc.CreateTransaction(wallet.NewKeySigner(*multisig), 30, []*Payee{{ta.Addrinfo["delta"].Address, 30}})
+	assert.Equal(t, ErrSigningFailed, errors.Cause(err))
+
+	tx, err = bc.CreateRawTransaction(*multisig, 30, []*Payee{{ta.Addrinfo["delta"].Address, 30}})
+	assert.Nil(t, err)
+	alfa, err := tx.SignPartial(wallet.NewKeySigner(ta.Addrinfo["alfa"]))
+	assert.Nil(t, err)
+	charlie, err := tx.SignPartial(wallet.NewKeySigner(ta.Addrinfo["charlie"]))
+	assert.Nil(t, err)
+	assert.NotNil(t, tx.SignMultisig(multisig.PublicKey, alfa))
+	assert.Nil(t, tx.SignMultisig(multisig.PublicKey, charlie, alfa))
+
+	blk, err = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	assert.Nil(t, bc.AddBlockCommit(blk))
+	assert.Equal(t, uint64(30), bc.BalanceOf(ta.Addrinfo["delta"].Address, 0))
+	assert.Equal(t, uint64(70), bc.BalanceOf(multisig.Address, 0))
+}
+
+// aggregateSigner signs jointly with the Schnorr keys of all cosigners of an aggregated address
+type aggregateSigner struct {
+	addr     *iotxaddress.Address
+	pubkeys  [][]byte
+	privkeys [][]byte
+}
+
+func (s *aggregateSigner) Address() string { return s.addr.Address }
+
+func (s *aggregateSigner) PublicKey() []byte { return s.addr.PublicKey }
+
+func (s *aggregateSigner) Sign(msg []byte) ([]byte, error) {
+	var secrets, nonces, partials [][]byte
+	for range s.privkeys {
+		secret, nonce, err := cp.NewSchnorrNonce()
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+		nonces = append(nonces, nonce)
+	}
+	aggNonce, err := cp.AggregateSchnorrNonces(nonces)
+	if err != nil {
+		return nil, err
+	}
+	for i, priv := range s.privkeys {
+		partial, err := cp.SchnorrPartialSign(priv, s.pubkeys, secrets[i], aggNonce, msg)
+		if err != nil {
+			return nil, err
+		}
+		partials = append(partials, partial)
+	}
+	return cp.AggregateSchnorrSignatures(aggNonce, partials)
+}
+
+func TestSchnorrTransaction(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	chainid := []byte{0x01, 0x02, 0x03, 0x04}
+	schnorr, err := iotxaddress.NewSchnorrAddress(false, chainid)
+	assert.Nil(err)
+	// the key aggregated from the Schnorr keys of two cosigners
+	signer := &aggregateSigner{}
+	for i := 0; i < 2; i++ {
+		key, err := iotxaddress.NewSchnorrAddress(false, chainid)
+		assert.Nil(err)
+		signer.pubkeys = append(signer.pubkeys, key.PublicKey)
+		signer.privkeys = append(signer.privkeys, key.PrivateKey)
+	}
+	signer.addr, err = iotxaddress.CreateAggregatedAddress(signer.pubkeys, false, chainid)
+	assert.Nil(err)
+
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 200, []*Payee{
+		{schnorr.Address, 100},
+		{signer.addr.Address, 100},
+	})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	single, err := bc.CreateTransaction(wallet.NewKeySigner(*schnorr), 30, []*Payee{{ta.Addrinfo["delta"].Address, 30}})
+	assert.Nil(err)
+	assert.Nil(bc.Utk.ValidateTxScripts(single))
+
+	raw, err := bc.CreateRawTransaction(*signer.addr, 40, []*Payee{{ta.Addrinfo["echo"].Address, 40}})
+	assert.Nil(err)
+	buf, err := raw.Serialize()
+	assert.Nil(err)
+	// the signature of another key with the public key of the aggregated address fails the batch
+	forged := &Tx{}
+	assert.Nil(forged.Deserialize(buf))
+	other := iotxaddress.Address{Address: signer.addr.Address, PublicKey: signer.addr.PublicKey, PrivateKey: schnorr.PrivateKey}
+	assert.Nil(forged.Sign(wallet.NewKeySigner(other)))
+	assert.NotNil(bc.Utk.ValidateTxScripts(forged))
+	blk, err = bc.MintNewBlock([]*Tx{single, forged}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.NotNil(bc.ValidateBlock(blk))
+
+	assert.Nil(raw.Sign(signer))
+	blk, err = bc.MintNewBlock([]*Tx{single, raw}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.ValidateBlock(blk))
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(uint64(70), bc.BalanceOf(schnorr.Address, 0))
+	assert.Equal(uint64(60), bc.BalanceOf(signer.addr.Address, 0))
+	assert.Equal(uint64(30), bc.BalanceOf(ta.Addrinfo["delta"].Address, 0))
+	assert.Equal(uint64(40), bc.BalanceOf(ta.Addrinfo["echo"].Address, 0))
+}
+
+func TestPartialTx(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	// 2-of-3 multisig of alfa, bravo and charlie
+	pubkeys := [][]byte{ta.Addrinfo["alfa"].PublicKey, ta.Addrinfo["bravo"].PublicKey, ta.Addrinfo["charlie"].PublicKey}
+	multisig, err := iotxaddress.CreateMultisigAddress(2, pubkeys, false, []byte{0x01, 0x02, 0x03, 0x04})
+	assert.Nil(err)
+
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 150, []*Payee{
+		{multisig.Address, 100},
+		{ta.Addrinfo["delta"].Address, 50},
+	})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	// each cosigner signs its own copy of the serialized partially signed transaction
+	raw, err := bc.CreateRawTransaction(*multisig, 30, []*Payee{{ta.Addrinfo["echo"].Address, 30}})
+	assert.Nil(err)
+	buf, err := NewPartialTx(raw, multisig.PublicKey).Serialize()
+	assert.Nil(err)
+	var ptxs []*PartialTx
+	for _, name := range []string{"alfa", "charlie"} {
+		ptx := &PartialTx{}
+		assert.Nil(ptx.Deserialize(buf))
+		assert.False(ptx.IsComplete())
+		assert.Nil(ptx.Sign(wallet.NewKeySigner(ta.Addrinfo[name])))
+		signed, err := ptx.Serialize()
+		assert.Nil(err)
+		ptx = &PartialTx{}
+		assert.Nil(ptx.Deserialize(signed))
+		ptxs = append(ptxs, ptx)
+	}
+	_, err = ptxs[0].Finalize()
+	assert.Equal(ErrIncompleteTx, errors.Cause(err))
+
+	// the partially signed transactions of different transactions cannot be combined
+	other := &PartialTx{}
+	assert.Nil(other.Deserialize(buf))
+	other.Tx.LockTime = 1
+	_, err = CombinePartialTxs(ptxs[0], other)
+	assert.Equal(ErrPartialTxMismatch, errors.Cause(err))
+
+	combined, err := CombinePartialTxs(ptxs...)
+	assert.Nil(err)
+	assert.True(combined.IsComplete())
+	signed, err := combined.Finalize()
+	assert.Nil(err)
+	assert.Equal(raw.Hash(), combined.Tx.Hash())
+	assert.Nil(bc.Utk.ValidateTxScripts(signed))
+
+	// an air-gapped wallet signs the raw transaction of a single-key address
+	raw, err = bc.CreateRawTransaction(iotxaddress.Address{Address: ta.Addrinfo["delta"].Address}, 50,
+		[]*Payee{{ta.Addrinfo["echo"].Address, 50}})
+	assert.Nil(err)
+	ptx := NewPartialTx(raw, nil)
+	_, err = ptx.Finalize()
+	assert.Equal(ErrIncompleteTx, errors.Cause(err))
+	assert.Nil(ptx.Sign(wallet.NewKeySigner(ta.Addrinfo["delta"])))
+	single, err := ptx.Finalize()
+	assert.Nil(err)
+
+	blk, err = bc.MintNewBlock([]*Tx{signed, single}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(uint64(70), bc.BalanceOf(multisig.Address, 0))
+	assert.Equal(uint64(80), bc.BalanceOf(ta.Addrinfo["echo"].Address, 0))
+}
+
+func TestTimeLockedTransaction(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+	commit := func(txs []*Tx) error {
+		blk, err := bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+		assert.Nil(t, err)
+		bc.Reset()
+		return bc.AddBlockCommit(blk)
+	}
+
+	// lock time by height
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["bravo"].Address, 10}})
+	assert.Nil(t, err)
+	tx.LockTime = 2
+	assert.Nil(t, bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo["miner"])))
+	assert.Equal(t, ErrTxLocked, errors.Cause(bc.ValidateLockTime(tx)))
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(commit([]*Tx{tx})))
+	assert.Nil(t, commit([]*Tx{}))
+	assert.Nil(t, bc.ValidateLockTime(tx))
+	assert.Nil(t, commit([]*Tx{tx}))
+	assert.Equal(t, uint64(10), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0))
+
+	// lock time by timestamp
+	tx.LockTime = 1600000000
+	assert.False(t, tx.IsFinal(1000, 1599999999))
+	assert.True(t, tx.IsFinal(0, 1600000000))
+
+	// relative lock time of 2 blocks, the output is confirmed at height 3
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["charlie"].Address, 10}})
+	assert.Nil(t, err)
+	locks, err := txvm.RelativeLockScript(ta.Addrinfo["charlie"].Address, 2)
+	assert.Nil(t, err)
+	tx.TxOut[0].LockScript = locks
+	tx.TxOut[0].LockScriptSize = uint32(len(locks))
+	assert.Nil(t, bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo["miner"])))
+	assert.Nil(t, commit([]*Tx{tx}))
+	assert.Equal(t, uint64(10), bc.BalanceOf(ta.Addrinfo["charlie"].Address, 0))
+
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["charlie"]), 10, []*Payee{{ta.Addrinfo["delta"].Address, 10}})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), tx.TxIn[0].Sequence)
+	assert.Equal(t, ErrTxLocked, errors.Cause(bc.ValidateLockTime(tx)))
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(commit([]*Tx{tx})))
+
+	// the unlock script fails if the sequence does not carry the relative lock time
+	tx.TxIn[0].Sequence = 0
+	assert.Nil(t, bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo["charlie"])))
+	assert.Nil(t, bc.ValidateLockTime(tx))
+	assert.NotNil(t, commit([]*Tx{tx}))
+
+	tx.TxIn[0].Sequence = 2
+	assert.Nil(t, bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo["charlie"])))
+	assert.Nil(t, commit([]*Tx{}))
+	assert.Nil(t, bc.ValidateLockTime(tx))
+	assert.Nil(t, commit([]*Tx{tx}))
+	assert.Equal(t, uint64(10), bc.BalanceOf(ta.Addrinfo["delta"].Address, 0))
+
+	// the tx cannot be included after its expiry height, which is part of its hash and serialization
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["delta"]), 10, []*Payee{{ta.Addrinfo["echo"].Address, 10}})
+	assert.Nil(t, err)
+	hash := tx.Hash()
+	tx.ExpiryHeight = bc.TipHeight()
+	assert.NotEqual(t, hash, tx.Hash())
+	assert.Nil(t, bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo["delta"])))
+	assert.Equal(t, ErrTxExpired, errors.Cause(bc.ValidateLockTime(tx)))
+	assert.Equal(t, ErrInvalidBlock, errors.Cause(commit([]*Tx{tx})))
+
+	tx.ExpiryHeight = bc.TipHeight() + 1
+	assert.Nil(t, bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo["delta"])))
+	data, err := tx.Serialize()
+	assert.Nil(t, err)
+	decoded := &Tx{}
+	assert.Nil(t, decoded.Deserialize(data))
+	assert.Equal(t, tx.ExpiryHeight, decoded.ExpiryHeight)
+	assert.Equal(t, tx.Hash(), decoded.Hash())
+	assert.Nil(t, bc.ValidateLockTime(tx))
+	assert.Nil(t, commit([]*Tx{tx}))
+}
+
+func TestHTLC(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+	commit := func(txs []*Tx) error {
+		blk, err := bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+		assert.Nil(t, err)
+		bc.Reset()
+		return bc.AddBlockCommit(blk)
+	}
+	preimage := []byte("atomic swap secret")
+	hashLock := sha256.Sum256(preimage)
+
+	// miner locks 50 for alfa
+	tx, err := bc.CreateHTLCTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 50, ta.Addrinfo["alfa"].Address, hashLock[:], 2)
+	assert.Nil(t, err)
+	assert.Nil(t, commit([]*Tx{tx}))
+	htlcHash := tx.Hash()
+
+	// only alfa can redeem with the right preimage
+	_, err = bc.RedeemHTLC(wallet.NewKeySigner(ta.Addrinfo["bravo"]), htlcHash, 0, preimage)
+	assert.Equal(t, ErrInvalidHTLC, errors.Cause(err))
+	redeem, err := bc.RedeemHTLC(wallet.NewKeySigner(ta.Addrinfo["alfa"]), htlcHash, 0, []byte("wrong secret"))
+	assert.Nil(t, err)
+	assert.NotNil(t, commit([]*Tx{redeem}))
+	redeem, err = bc.RedeemHTLC(wallet.NewKeySigner(ta.Addrinfo["alfa"]), htlcHash, 0, preimage)
+	assert.Nil(t, err)
+	assert.Nil(t, commit([]*Tx{redeem}))
+	assert.Equal(t, uint64(50), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+
+	// the preimage is revealed on chain
+	blk, err := bc.GetBlockByHeight(bc.TipHeight())
+	assert.Nil(t, err)
+	assert.Equal(t, preimage, txvm.HTLCPreimage(blk.Tranxs[0].TxIn[0].UnlockScript))
+
+	// miner refunds an unredeemed HTLC after the lock time
+	tx, err = bc.CreateHTLCTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 20, ta.Addrinfo["bravo"].Address, hashLock[:], 2)
+	assert.Nil(t, err)
+	assert.Nil(t, commit([]*Tx{tx}))
+	htlcHash = tx.Hash()
+	_, err = bc.RefundHTLC(wallet.NewKeySigner(ta.Addrinfo["bravo"]), htlcHash, 0)
+	assert.Equal(t, ErrInvalidHTLC, errors.Cause(err))
+	refund, err := bc.RefundHTLC(wallet.NewKeySigner(ta.Addrinfo["miner"]), htlcHash, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrTxLocked, errors.Cause(bc.ValidateLockTime(refund)))
+
+	// nor can the refund skip the lock time by flagging its sequence replaceable instead
+	data, err := refund.Serialize()
+	assert.Nil(t, err)
+	flagged := &Tx{}
+	assert.Nil(t, flagged.Deserialize(data))
+	flagged.TxIn[0].Sequence = SequenceReplaceable
+	flagged.TxIn[0].UnlockScript = nil
+	flagged.TxIn[0].UnlockScriptSize = 0
+	digest := flagged.SigHash(0, tx.TxOut[0].TxOutputPb)
+	sig, err := signTxIn(wallet.NewKeySigner(ta.Addrinfo["miner"]), digest[:])
+	assert.Nil(t, err)
+	unlock, err := txvm.HTLCRefundScriptWithSig(sig, ta.Addrinfo["miner"].PublicKey)
+	assert.Nil(t, err)
+	flagged.TxIn[0].UnlockScript = unlock
+	flagged.TxIn[0].UnlockScriptSize = uint32(len(unlock))
+	assert.Nil(t, bc.ValidateLockTime(flagged))
+	blk, err = bc.MintNewBlock([]*Tx{}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(t, err)
+	blk.Tranxs = []*Tx{flagged, blk.Tranxs[0]}
+	blk.Header.trnxNumber = uint32(len(blk.Tranxs))
+	blk.Header.merkleRoot = blk.MerkleRoot()
+	err = bc.ValidateBlock(blk)
+	assert.NotNil(t, err)
+	assert.Contains(t, fmt.Sprint(err), "cannot unlock")
+	assert.Nil(t, commit([]*Tx{}))
+	balance := bc.BalanceOf(ta.Addrinfo["miner"].Address, 0)
+	assert.Nil(t, commit([]*Tx{refund}))
+	assert.Equal(t, balance+20, bc.BalanceOf(ta.Addrinfo["miner"].Address, 0))
+	assert.Equal(t, uint64(0), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0))
+}
+
+func TestWalletTransaction(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	cfg.Chain.ChainDBPath = testDBPath
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(t, err)
+	defer bc.Close()
+
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	w := wallet.NewWallet(config.Wallet{KeystorePath: dir, ScryptN: wallet.LightScryptN, ScryptP: wallet.LightScryptP})
+	miner := ta.Addrinfo["miner"]
+	assert.Nil(t, w.ImportKey(&miner, "passphrase"))
+	assert.Nil(t, w.Unlock(miner.Address, "passphrase", 0))
+	signer, err := w.Signer(miner.Address)
+	assert.Nil(t, err)
+
+	payee := []*Payee{{ta.Addrinfo["alfa"].Address, 10}}
+	tx, err := bc.CreateTransaction(signer, 10, payee)
+	assert.Nil(t, err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(t, err)
+	bc.Reset()
+	assert.Nil(t, bc.AddBlockCommit(blk))
+	assert.Equal(t, uint64(10), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+
+	// the handle cannot sign once the account is locked
+	w.Lock(miner.Address)
+	_, err = bc.CreateTransaction(signer, 10, payee)
+	assert.Equal(t, ErrSigningFailed, errors.Cause(err))
+}
+
+func TestCoinSelection(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 0
+	config.Chain.CoinSelection = LargestFirst
+	config.Chain.DustThreshold = 3
+	config.Chain.MaxTxInputs = 2
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+	commit := func(txs []*Tx) {
+		blk, err := bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+		assert.Nil(t, err)
+		bc.Reset()
+		assert.Nil(t, bc.AddBlockCommit(blk))
+	}
+	miner := wallet.NewKeySigner(ta.Addrinfo["miner"])
+	alfa := wallet.NewKeySigner(ta.Addrinfo["alfa"])
+
+	payee := []*Payee{}
+	for _, amount := range []uint64{5, 10, 20} {
+		payee = append(payee, &Payee{ta.Addrinfo["alfa"].Address, amount})
+	}
+	tx, err := bc.CreateTransaction(miner, 35, payee)
+	assert.Nil(t, err)
+	commit([]*Tx{tx})
+
+	// 34 cannot be paid with 2 inputs
+	_, err = bc.CreateTransaction(alfa, 34, []*Payee{{ta.Addrinfo["bravo"].Address, 34}})
+	assert.Equal(t, ErrInsufficientFunds, errors.Cause(err))
+
+	// the change of 2 is dust and paid as fee
+	tx, err = bc.CreateTransaction(alfa, 28, []*Payee{{ta.Addrinfo["bravo"].Address, 28}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tx.TxIn))
+	assert.Equal(t, 1, len(tx.TxOut))
+	fee, err := bc.Utk.TxFee(tx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), fee)
+	commit([]*Tx{tx})
+	assert.Equal(t, uint64(5), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	assert.Equal(t, uint64(28), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0))
+}
+
+func TestUtxoReservation(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(t, err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.BlockReward = 0
+	config.Chain.UtxoReservationTTL = 100 * time.Millisecond
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(t, err)
+	defer bc.Close()
+	miner := wallet.NewKeySigner(ta.Addrinfo["miner"])
+	alfa := wallet.NewKeySigner(ta.Addrinfo["alfa"])
+
+	payee := []*Payee{{ta.Addrinfo["alfa"].Address, 10}, {ta.Addrinfo["alfa"].Address, 10}}
+	tx, err := bc.CreateTransaction(miner, 20, payee)
+	assert.Nil(t, err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(t, err)
+	bc.Reset()
+	assert.Nil(t, bc.AddBlockCommit(blk))
+
+	// the second transaction cannot spend the UTXO of the first one in flight
+	bravo := []*Payee{{ta.Addrinfo["bravo"].Address, 10}}
+	tx1, err := bc.CreateTransaction(alfa, 10, bravo)
+	assert.Nil(t, err)
+	tx2, err := bc.CreateTransaction(alfa, 10, bravo)
+	assert.Nil(t, err)
+	assert.NotEqual(t, tx1.TxIn[0].OutIndex, tx2.TxIn[0].OutIndex)
+	_, err = bc.CreateTransaction(alfa, 10, bravo)
+	assert.Equal(t, ErrInsufficientFunds, errors.Cause(err))
+
+	// released explicitly, or once the reservation expires
+	bc.ReleaseTxInputs(tx1)
+	tx3, err := bc.CreateTransaction(alfa, 10, bravo)
+	assert.Nil(t, err)
+	assert.Equal(t, tx1.TxIn[0].OutIndex, tx3.TxIn[0].OutIndex)
+	time.Sleep(200 * time.Millisecond)
+	tx4, err := bc.CreateTransaction(alfa, 20, bravo)
+	assert.Nil(t, err)
+	bc.ReleaseTxInputs(tx4)
+
+	// the reservation without ttl is held until the transaction is committed
+	bc.ReserveTxInputs(tx1, 0)
+	time.Sleep(200 * time.Millisecond)
+	_, err = bc.CreateTransaction(alfa, 20, bravo)
+	assert.Equal(t, ErrInsufficientFunds, errors.Cause(err))
+	blk, err = bc.MintNewBlock([]*Tx{tx1}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(t, err)
+	bc.Reset()
+	assert.Nil(t, bc.AddBlockCommit(blk))
+	hash := cp.ZeroHash32B
+	copy(hash[:], tx1.TxIn[0].TxHash)
+	assert.False(t, bc.Utk.IsReserved(hash, tx1.TxIn[0].OutIndex))
+	assert.Equal(t, uint64(10), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+}
+
+func TestBalanceConfirmations(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 10
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	for _, name := range []string{"alfa", "bravo"} {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo[name].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		bc.Reset()
+	}
+
+	// alfa's reward is confirmed by 2 blocks and bravo's by the tip only
+	assert.Equal(uint64(10), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	assert.Equal(uint64(10), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 2))
+	assert.Equal(uint64(0), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 3))
+	assert.Equal(uint64(10), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 1))
+	assert.Equal(uint64(0), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 2))
+	assert.Equal(bc.BalanceOf(ta.Addrinfo["miner"].Address, 0), bc.BalanceOf(ta.Addrinfo["miner"].Address, 3))
+}
+
+func TestListUnspent(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 10
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	hashes := []cp.Hash32B{}
+	for i := 0; i < 3; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["alfa"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		bc.Reset()
+		hashes = append(hashes, blk.Tranxs[0].Hash())
+	}
+
+	list, err := bc.ListUnspent(ta.Addrinfo["alfa"].Address, 0, 0, 0, 0)
+	assert.Nil(err)
+	assert.Equal(3, len(list))
+	for i, unspent := range list {
+		assert.Equal(hashes[i], unspent.Hash)
+		assert.Equal(int32(0), unspent.Index)
+		assert.Equal(uint64(10), unspent.Value)
+		assert.Equal(uint32(i+1), unspent.Height)
+		assert.Equal(uint32(3-i), unspent.Confirmations)
+		assert.Equal(txvm.PubKeyHashScriptType, unspent.ScriptType)
+	}
+
+	// filter by confirmations
+	list, err = bc.ListUnspent(ta.Addrinfo["alfa"].Address, 2, 2, 0, 0)
+	assert.Nil(err)
+	assert.Equal(1, len(list))
+	assert.Equal(hashes[1], list[0].Hash)
+	list, err = bc.ListUnspent(ta.Addrinfo["alfa"].Address, 2, 0, 0, 0)
+	assert.Nil(err)
+	assert.Equal(2, len(list))
+	_, err = bc.ListUnspent(ta.Addrinfo["alfa"].Address, 3, 2, 0, 0)
+	assert.NotNil(err)
+
+	// paginate
+	list, err = bc.ListUnspent(ta.Addrinfo["alfa"].Address, 0, 0, 1, 1)
+	assert.Nil(err)
+	assert.Equal(1, len(list))
+	assert.Equal(hashes[1], list[0].Hash)
+	list, err = bc.ListUnspent(ta.Addrinfo["alfa"].Address, 0, 0, 2, 5)
+	assert.Nil(err)
+	assert.Equal(1, len(list))
+	assert.Equal(hashes[2], list[0].Hash)
+	list, err = bc.ListUnspent(ta.Addrinfo["alfa"].Address, 0, 0, 3, 0)
+	assert.Nil(err)
+	assert.Equal(0, len(list))
+}
+
+func TestBlockLimits(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+	cfg.Chain.MaxBlockSize = 0
+	cfg.Chain.MaxBlockTxs = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	names := []string{"alfa", "bravo", "charlie"}
+	payees := []*Payee{}
+	for _, name := range names {
+		payees = append(payees, NewPayee(ta.Addrinfo[name].Address, 10))
+	}
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 30, payees)
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	txs := []*Tx{}
+	for _, name := range names {
+		tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo[name]), 10, []*Payee{NewPayee(ta.Addrinfo["delta"].Address, 10)})
+		assert.Nil(err)
+		txs = append(txs, tx)
+	}
+
+	// the coinbase counts towards the transaction limit
+	bc.config.Chain.MaxBlockTxs = 3
+	blk, err = bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	bc.Reset()
+	assert.Equal(3, len(blk.Tranxs))
+	assert.Equal(txs[1].Hash(), blk.Tranxs[1].Hash())
+	assert.Nil(bc.ValidateBlock(blk))
+	bc.Reset()
+	bc.config.Chain.MaxBlockTxs = 2
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+	bc.Reset()
+	bc.config.Chain.MaxBlockTxs = 0
+
+	size := proto.Size(blk.ConvertToBlockPb())
+	bc.config.Chain.MaxBlockSize = uint32(size)
+	blk, err = bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	bc.Reset()
+	assert.Equal(3, len(blk.Tranxs))
+	assert.Equal(size, proto.Size(blk.ConvertToBlockPb()))
+	bc.config.Chain.MaxBlockSize = uint32(size - 1)
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+	bc.Reset()
+	blk, err = bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	bc.Reset()
+	assert.Equal(2, len(blk.Tranxs))
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(uint64(10), bc.BalanceOf(ta.Addrinfo["delta"].Address, 0))
+}
+
+// reverseOrder packs the candidates in the reverse order they are given
+type reverseOrder struct{}
+
+func (reverseOrder) Less(a, b *TxCandidate) bool { return a.Index > b.Index }
+
+func TestTxOrder(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+	cfg.Chain.MaxBlockSize = 0
+	cfg.Chain.MaxBlockTxs = 0
+	cfg.Chain.TxOrder = FeeRateOrder
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 20, []*Payee{
+		NewPayee(ta.Addrinfo["alfa"].Address, 10),
+		NewPayee(ta.Addrinfo["bravo"].Address, 10),
+	})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	// spend returns a tx of the owner spending the output of the tx with the hash, paying 'value' to 'to'
+	spend := func(hash cp.Hash32B, index int32, utxo *iproto.TxOutputPb, owner string, to string, value uint64) *Tx {
+		tx := NewTx(1, []*TxInput{NewTxInput(hash, index, nil, 0)}, []*TxOutput{CreateTxOutput(ta.Addrinfo[to].Address, value)}, 0)
+		tx.ChainID = bc.ChainID()
+		digest := tx.SigHash(0, utxo)
+		unlock, err := txvm.SignatureScript(digest[:], ta.Addrinfo[owner].PublicKey, ta.Addrinfo[owner].PrivateKey)
+		assert.Nil(err)
+		tx.TxIn[0].UnlockScript = unlock
+		tx.TxIn[0].UnlockScriptSize = uint32(len(unlock))
+		return tx
+	}
+	spendUtxo := func(owner string, to string, value uint64) *Tx {
+		entries, _ := bc.Utk.UtxoEntries(ta.Addrinfo[owner].Address, 10)
+		assert.Equal(1, len(entries))
+		return spend(entries[0].txHash, entries[0].outIndex, entries[0].TxOutputPb, owner, to, value)
+	}
+	// low pays a fee of 1, the parent pays no fee and its child pays a fee of 5
+	low := spendUtxo("alfa", "delta", 9)
+	parent := spendUtxo("bravo", "charlie", 10)
+	child := spend(parent.Hash(), 0, parent.TxOut[0].TxOutputPb, "charlie", "delta", 5)
+	txs := []*Tx{child, parent, low}
+
+	// the candidates paying the highest fee rate are packed first, but a child is only packed after its parent
+	blk, err = bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	bc.Reset()
+	assert.Equal(4, len(blk.Tranxs))
+	assert.Equal(low.Hash(), blk.Tranxs[0].Hash())
+	assert.Equal(parent.Hash(), blk.Tranxs[1].Hash())
+	assert.Equal(child.Hash(), blk.Tranxs[2].Hash())
+	assert.Equal(uint64(6), blk.Tranxs[3].TxOut[0].Value)
+	assert.Nil(bc.ValidateBlock(blk))
+	bc.Reset()
+
+	// the transaction limit keeps the first candidates in the order
+	bc.config.Chain.MaxBlockTxs = 2
+	blk, err = bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	bc.Reset()
+	assert.Equal(2, len(blk.Tranxs))
+	assert.Equal(low.Hash(), blk.Tranxs[0].Hash())
+	bc.config.Chain.MaxBlockTxs = 0
+
+	// the given order is kept without a policy, and the policy can be replaced
+	bc.SetTxOrderPolicy(NewTxOrderPolicy(""))
+	blk, err = bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	bc.Reset()
+	assert.Equal(parent.Hash(), blk.Tranxs[0].Hash())
+	assert.Equal(child.Hash(), blk.Tranxs[1].Hash())
+	assert.Equal(low.Hash(), blk.Tranxs[2].Hash())
+	bc.SetTxOrderPolicy(reverseOrder{})
+	blk, err = bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	bc.Reset()
+	assert.Equal(low.Hash(), blk.Tranxs[0].Hash())
+	assert.Equal(parent.Hash(), blk.Tranxs[1].Hash())
+	assert.Equal(child.Hash(), blk.Tranxs[2].Hash())
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(uint64(14), bc.BalanceOf(ta.Addrinfo["delta"].Address, 0))
+}
+
+// testMempool is a mempool holding the fee samples
+type testMempool []FeeSample
+
+func (mp testMempool) PendingFees() []FeeSample { return append([]FeeSample{}, mp...) }
+
+func TestEstimateFee(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+	cfg.Chain.MaxBlockSize = 0
+	cfg.Chain.MaxBlockTxs = 0
+	cfg.TxPool.MinTxFeePerByte = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	_, err = bc.EstimateFee(0)
+	assert.Equal(ErrInvalidFeeTarget, errors.Cause(err))
+	rate, err := bc.EstimateFee(1)
+	assert.Nil(err)
+	assert.Equal(uint64(0), rate)
+
+	// a longer target accepts the lower rates included by fewer recent blocks
+	fe := &feeEstimator{}
+	for i := 1; i <= 10; i++ {
+		fe.add(uint64(i))
+	}
+	assert.Equal(uint64(10), fe.estimate(1))
+	assert.Equal(uint64(8), fe.estimate(2))
+	assert.Equal(uint64(1), fe.estimate(50))
+	for i := 0; i < feeHistoryBlocks; i++ {
+		fe.add(0)
+	}
+	assert.Equal(uint64(0), fe.estimate(1))
+
+	// the backlog of the mempool not fitting in the target blocks has to be outbid
+	bc.SetMempool(testMempool{{Fee: 300, Size: 100}, {Fee: 500, Size: 100}, {Fee: 100, Size: 100}})
+	rate, err = bc.EstimateFee(1)
+	assert.Nil(err)
+	assert.Equal(uint64(0), rate)
+	bc.config.Chain.MaxBlockSize = 250
+	rate, err = bc.EstimateFee(1)
+	assert.Nil(err)
+	assert.Equal(uint64(2), rate)
+	rate, err = bc.EstimateFee(2)
+	assert.Nil(err)
+	assert.Equal(uint64(0), rate)
+	bc.config.Chain.MaxBlockSize = 0
+	bc.config.Chain.MaxBlockTxs = 2
+	rate, err = bc.EstimateFee(1)
+	assert.Nil(err)
+	assert.Equal(uint64(4), rate)
+	bc.config.Chain.MaxBlockTxs = 0
+	bc.SetMempool(nil)
+
+	// the created transaction pays the estimated fee rate, which is at least the min fee rate of the txpool
+	bc.config.TxPool.MinTxFeePerByte = 1
+	bc.config.Chain.FeeTargetBlocks = 2
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 100, []*Payee{NewPayee(ta.Addrinfo["alfa"].Address, 100)})
+	assert.Nil(err)
+	fee, err := bc.Utk.TxFee(tx)
+	assert.Nil(err)
+	serialized, err := tx.Serialize()
+	assert.Nil(err)
+	assert.True(fee >= uint64(len(serialized)))
+	assert.Equal(uint64(100), tx.TxOut[0].Value)
+
+	// a full block records the lowest fee rate it included
+	bc.config.Chain.MaxBlockTxs = 2
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+	rate, err = bc.EstimateFee(1)
+	assert.Nil(err)
+	assert.Equal(fee/uint64(len(serialized)), rate)
+}
+
+func TestBlockCache(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockCacheSize = 4
+	cfg.Chain.HashCacheSize = 4
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	blk1, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	fork, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["alfa"].Address, "fork")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk1))
+
+	// the block is read from Db once and served from the cache afterwards
+	blk, err := bc.GetBlockByHeight(1)
+	assert.Nil(err)
+	assert.Equal(blk1.HashBlock(), blk.HashBlock())
+	cached, err := bc.GetBlockByHeight(1)
+	assert.Nil(err)
+	assert.True(blk == cached)
+	assert.Equal(1, bc.blockCache.Len())
+
+	// the height maps to the fork block after the reorg
+	assert.Nil(bc.AddBlockSync(fork))
+	assert.Equal(0, bc.blockCache.Len())
+	assert.Equal(0, bc.hashCache.Len())
+	blk, err = bc.GetBlockByHeight(1)
+	assert.Nil(err)
+	assert.Equal(fork.HashBlock(), blk.HashBlock())
+}
+
+func TestGetBlocksByRange(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	hashes := []cp.Hash32B{bc.TipHash()}
+	for i := 0; i < 3; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		hashes = append(hashes, blk.HashBlock())
+	}
+
+	blks, err := bc.GetBlocksByRange(context.Background(), 1, 3)
+	assert.Nil(err)
+	assert.Equal(3, len(blks))
+	for i, blk := range blks {
+		assert.Equal(uint32(i+1), blk.Height())
+		assert.Equal(hashes[i+1], blk.HashBlock())
+	}
+	blks, err = bc.GetBlocksByRange(context.Background(), 0, 0)
+	assert.Nil(err)
+	assert.Equal(hashes[0], blks[0].HashBlock())
+
+	_, err = bc.GetBlocksByRange(context.Background(), 2, 1)
+	assert.NotNil(err)
+	_, err = bc.GetBlocksByRange(context.Background(), 1, 4)
+	assert.NotNil(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = bc.GetBlocksByRange(ctx, 1, 3)
+	assert.Equal(context.Canceled, errors.Cause(err))
+}
+
+func TestBlockMeta(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	for i := 0; i < 2; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+
+	for h := uint32(0); h <= 2; h++ {
+		blk, err := bc.GetBlockByHeight(h)
+		assert.Nil(err)
+		serialized, err := blk.Serialize()
+		assert.Nil(err)
+		meta, err := bc.GetBlockMetaByHeight(h)
+		assert.Nil(err)
+		assert.Equal(blk.HashBlock(), meta.Hash)
+		assert.Equal(h, meta.Height)
+		assert.Equal(blk.Timestamp(), meta.Timestamp)
+		assert.Equal(uint32(len(blk.Tranxs)), meta.TxCount)
+		assert.Equal(blk.Header.pubkey, meta.Producer)
+		assert.Equal(uint32(len(serialized)), meta.Size)
+
+		byHash, err := bc.GetBlockMetaByHash(meta.Hash)
+		assert.Nil(err)
+		assert.Equal(meta, byHash)
+	}
+
+	_, err = bc.GetBlockMetaByHeight(3)
+	assert.NotNil(err)
+	_, err = bc.GetBlockMetaByHash(cp.ZeroHash32B)
+	assert.NotNil(err)
+}
+
+func TestBlockIterator(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	// more blocks than a batch read from Db
+	hashes := []cp.Hash32B{bc.TipHash()}
+	for i := 0; i < blockBatchSize+5; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		hashes = append(hashes, blk.HashBlock())
+	}
+
+	it, err := bc.NewBlockIterator(1, bc.TipHeight())
+	assert.Nil(err)
+	for height := uint32(1); height <= bc.TipHeight(); height++ {
+		blk, err := it.Next(context.Background())
+		assert.Nil(err)
+		assert.Equal(hashes[height], blk.HashBlock())
+	}
+	_, err = it.Next(context.Background())
+	assert.Equal(io.EOF, err)
+
+	it, err = bc.NewBlockIterator(0, 0)
+	assert.Nil(err)
+	blk, err := it.Next(context.Background())
+	assert.Nil(err)
+	assert.Equal(hashes[0], blk.HashBlock())
+
+	_, err = bc.NewBlockIterator(2, 1)
+	assert.NotNil(err)
+	_, err = bc.NewBlockIterator(1, bc.TipHeight()+1)
+	assert.NotNil(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it, err = bc.NewBlockIterator(1, 3)
+	assert.Nil(err)
+	_, err = it.Next(ctx)
+	assert.Nil(err)
+	cancel()
+	_, err = it.Next(ctx)
+	assert.Equal(context.Canceled, errors.Cause(err))
+}
+
+func TestExportImportChain(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	for i := 0; i < 3; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["alfa"].Address, 10}})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+	tip := bc.TipHash()
+
+	archive := bytes.Buffer{}
+	assert.Nil(bc.ExportChain(context.Background(), &archive, 0, bc.TipHeight()))
+	assert.NotNil(bc.ExportChain(context.Background(), &bytes.Buffer{}, 0, bc.TipHeight()+1))
+	bc.Close()
+	os.Remove(testDBPath)
+
+	// restore the chain into a fresh Db, where the genesis block is already in place
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	data := archive.Bytes()
+	assert.Nil(bc.ImportChain(context.Background(), bytes.NewReader(data)))
+	assert.Equal(uint32(4), bc.TipHeight())
+	assert.Equal(tip, bc.TipHash())
+	assert.Equal(uint64(10), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+
+	// importing again skips the existing blocks
+	assert.Nil(bc.ImportChain(context.Background(), bytes.NewReader(data)))
+	assert.Equal(uint32(4), bc.TipHeight())
+
+	// corrupt block data fails the checksum
+	corrupt := append([]byte{}, data...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	assert.Equal(ErrInvalidArchive, errors.Cause(bc.ImportChain(context.Background(), bytes.NewReader(corrupt))))
+	unknown := append([]byte{}, data...)
+	unknown[4] = 2
+	assert.Equal(ErrInvalidArchive, errors.Cause(bc.ImportChain(context.Background(), bytes.NewReader(unknown))))
+	assert.Equal(ErrInvalidArchive, errors.Cause(bc.ImportChain(context.Background(), bytes.NewReader(data[:len(data)-1]))))
+}
+
+func TestBlockArchive(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	dir, err := ioutil.TempDir("", "blocks")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	cfg.Chain.BlockArchiveDir = dir
+	cfg.Chain.BlockArchiveSegmentSize = 3
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	for i := 0; i < 7; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+	ctx := context.Background()
+
+	// blocks [2, 4] span the segments 0 and 1
+	assert.Nil(bc.StoreBlock(ctx, 2, 4))
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(err)
+	assert.Equal(2, len(files))
+	_, err = bc.ReadBlock(1)
+	assert.Equal(blockdb.ErrNotExist, errors.Cause(err))
+	_, err = bc.ReadBlock(5)
+	assert.Equal(blockdb.ErrNotExist, errors.Cause(err))
+
+	// extending the archived blocks on both ends keeps them, leaving a gap fails
+	assert.NotNil(bc.StoreBlock(ctx, 0, 0))
+	assert.Nil(bc.StoreBlock(ctx, 0, 2))
+	assert.Nil(bc.StoreBlock(ctx, 5, 5))
+	archive := NewBlockArchive(dir, 3)
+	heights := []uint32{}
+	assert.Nil(archive.ReadBlocks(ctx, 0, 5, func(blk *Block) error {
+		hash, err := bc.GetHashByHeight(blk.Height())
+		assert.Nil(err)
+		assert.Equal(hash, blk.HashBlock())
+		heights = append(heights, blk.Height())
+		return nil
+	}))
+	assert.Equal([]uint32{0, 1, 2, 3, 4, 5}, heights)
+	assert.NotNil(archive.ReadBlocks(ctx, 4, 6, func(*Block) error { return nil }))
+
+	// the archived range streams out in the chain archive format
+	exported := bytes.Buffer{}
+	assert.Nil(bc.ExportChain(ctx, &exported, 1, 5))
+	streamed := bytes.Buffer{}
+	assert.Nil(archive.ReadTo(ctx, &streamed, 1, 5))
+	assert.Equal(exported.Bytes(), streamed.Bytes())
+
+	// a failed write leaves the segment as it was
+	corrupt := append([]byte{}, exported.Bytes()...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	assert.Equal(ErrInvalidArchive, errors.Cause(archive.Write(ctx, bytes.NewReader(corrupt))))
+	blk, err := archive.ReadBlock(5)
+	assert.Nil(err)
+	assert.Equal(uint32(5), blk.Height())
+}
+
+func TestCirculatingSupply(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	assert.Equal(cfg.Chain.TotalSupply, bc.CirculatingSupply())
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(cfg.Chain.TotalSupply+cfg.Chain.BlockReward, bc.CirculatingSupply())
+
+	// a block without coinbase burns its reward
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["alfa"].Address, 10}})
+	assert.Nil(err)
+	blk = NewBlock(bc.chainID, 2, bc.TipHash(), []*Tx{tx})
+	assert.Nil(bc.AddBlockSync(blk))
+	assert.Equal(cfg.Chain.TotalSupply+cfg.Chain.BlockReward, bc.CirculatingSupply())
+	assert.Equal(cfg.Chain.BlockReward, bc.Utk.burned)
+
+	// a coinbase paying more than the reward is refused before it reaches Db
+	cbtx := NewCoinbaseTx(ta.Addrinfo["miner"].Address, cfg.Chain.BlockReward+1, "")
+	blk = NewBlock(bc.chainID, 3, bc.TipHash(), []*Tx{cbtx})
+	assert.Equal(ErrSupplyInvariant, errors.Cause(bc.AddBlockSync(blk)))
+	assert.Equal(uint32(2), bc.TipHeight())
+	bc.Close()
+
+	// the supply is loaded along with the UTXO pool
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(cfg.Chain.TotalSupply+cfg.Chain.BlockReward, bc.CirculatingSupply())
+	assert.Nil(bc.Utk.verifySupply())
+	assert.Nil(bc.VerifyChain(context.Background(), 0))
+}
+
+func TestNetworkPresets(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	for _, net := range []string{config.Mainnet, config.Testnet, config.Devnet} {
+		cfg := &config.Config{Net: net}
+		cfg.Chain.ChainDBBackend = "MEMORY"
+		bc, err := CreateBlockchain(context.Background(), "", cfg)
+		assert.Nil(err)
+		tip := bc.TipHash()
+		assert.Equal(config.Presets[net].GenesisHash, hex.EncodeToString(tip[:]), net)
+		assert.Equal(config.Presets[net].Genesis.ChainID, bc.ChainID())
+		bc.Close()
+	}
+
+	// the DB of a network cannot be opened for another one
+	cfg := &config.Config{Net: config.Testnet}
+	cfg.Chain.ChainDBPath = testDBPath
+	bc, err := CreateBlockchain(context.Background(), "", cfg)
+	assert.Nil(err)
+	assert.Nil(bc.Close())
+	cfg.Net = config.Mainnet
+	_, err = CreateBlockchain(context.Background(), "", cfg)
+	assert.Equal(ErrDBOpen, errors.Cause(err))
+	assert.Contains(err.Error(), blockdb.ErrGenesisMismatch.Error())
+	_, err = OpenBlockchainReadOnly(context.Background(), cfg)
+	assert.Contains(err.Error(), blockdb.ErrGenesisMismatch.Error())
+	cfg.Net = config.Testnet
+	bc, err = CreateBlockchain(context.Background(), "", cfg)
+	assert.Nil(err)
+	assert.Nil(bc.Close())
+}
+
+func TestOpenBlockchainReadOnly(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	// a DB which does not exist is not created
+	_, err = OpenBlockchainReadOnly(context.Background(), cfg)
+	assert.Equal(ErrDBOpen, errors.Cause(err))
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	assert.Nil(addTestingBlocks(bc))
+	balance := bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0)
+	bc.Close()
+
+	bc, err = OpenBlockchainReadOnly(context.Background(), cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(uint32(4), bc.TipHeight())
+	assert.Equal(balance, bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	blk, err := bc.GetBlockByHeight(4)
+	assert.Nil(err)
+	assert.Equal(bc.TipHash(), blk.HashBlock())
+
+	// the chain cannot be extended
+	blk, err = bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Equal(blockdb.ErrReadOnly, errors.Cause(bc.AddBlockCommit(blk)))
+	assert.Equal(uint32(4), bc.TipHeight())
+}
+
+func TestBlockchainStop(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.ChainDBNoSync = true
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	assert.Nil(bc.Start())
+	ch := bc.Subscribe()
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+
+	// the block is rejected once the chain is stopped, and the subscriptions are closed
+	assert.Nil(bc.Stop())
+	assert.Equal(ErrStopped, errors.Cause(bc.AddBlockCommit(blk)))
+	_, ok := <-ch
+	assert.False(ok)
+	assert.Equal(ErrStopped, bc.Start())
+	assert.Nil(bc.Stop())
+
+	// the blocks committed before stopping are flushed to disk
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(uint32(0), bc.TipHeight())
+	assert.Nil(bc.AddBlockCommit(blk))
+}
+
+func TestVoting(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.EpochLength = 2
+	cfg.Chain.NumDelegates = 1
+	genesis := &config.Genesis{
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+		Accounts: []config.Allocation{
+			{Address: ta.Addrinfo["alfa"].Address, Amount: 50},
+			{Address: ta.Addrinfo["bravo"].Address, Amount: 30},
+			{Address: ta.Addrinfo["charlie"].Address, Amount: 10},
+		},
+	}
+
+	bc, err := CreateBlockchainWithGenesis(context.Background(), cfg, genesis)
+	assert.Nil(err)
+	defer bc.Close()
+	vote := func(nonce uint64, voter string, votee string) *Vote {
+		v := NewVote(nonce, ta.Addrinfo[voter].Address, ta.Addrinfo[votee].Address)
+		assert.Nil(v.Sign(wallet.NewKeySigner(ta.Addrinfo[voter])))
+		return v
+	}
+	commit := func(votes ...*Vote) {
+		blk, err := bc.MintNewBlockWithVotes([]*Tx{}, nil, nil, votes, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+
+	// the candidates are the accounts voting for themselves, weighted by the balances of their voters
+	commit(vote(1, "alfa", "alfa"), vote(1, "bravo", "bravo"), vote(1, "charlie", "bravo"))
+	assert.Equal([]*election.Candidate{
+		{Address: ta.Addrinfo["alfa"].Address, Votes: 50},
+		{Address: ta.Addrinfo["bravo"].Address, Votes: 40},
+	}, bc.GetCandidates())
+	assert.Equal(ta.Addrinfo["bravo"].Address, bc.AccountState(ta.Addrinfo["charlie"].Address).Votee)
+	blk, err := bc.GetBlockByHeight(1)
+	assert.Nil(err)
+	assert.Equal(3, len(blk.Votes))
+
+	// the delegates of an epoch are elected at the end of the previous one
+	delegates, err := bc.GetDelegatesByEpoch(1)
+	assert.Nil(err)
+	assert.Nil(delegates)
+	commit()
+	delegates, err = bc.GetDelegatesByEpoch(1)
+	assert.Nil(err)
+	assert.Equal([]*election.Candidate{{Address: ta.Addrinfo["alfa"].Address, Votes: 50}}, delegates)
+
+	// voting for another candidate withdraws the candidacy
+	commit(vote(2, "alfa", "bravo"))
+	commit()
+	delegates, err = bc.GetDelegatesByEpoch(2)
+	assert.Nil(err)
+	assert.Equal([]*election.Candidate{{Address: ta.Addrinfo["bravo"].Address, Votes: 90}}, delegates)
+	assert.Equal(uint32(0), bc.EpochOf(2))
+	assert.Equal(uint32(1), bc.EpochOf(3))
+	assert.Equal(uint32(2), bc.EpochOf(bc.TipHeight()+1))
+
+	// a replayed vote is rejected
+	_, err = bc.MintNewBlockWithVotes([]*Tx{}, nil, nil, []*Vote{vote(1, "bravo", "alfa")}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+}
+
+func TestEpochRewards(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.EpochLength = 2
+	cfg.Chain.BlockReward = 100
+	cfg.Chain.RewardHalvingEpochs = 1
+	cfg.Chain.DelegateRewardPercent = 50
+	genesis := &config.Genesis{
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+		Accounts: []config.Allocation{
+			{Address: ta.Addrinfo["alfa"].Address, Amount: 50},
+			{Address: ta.Addrinfo["bravo"].Address, Amount: 30},
+		},
+	}
+
+	bc, err := CreateBlockchainWithGenesis(context.Background(), cfg, genesis)
+	assert.Nil(err)
+	defer bc.Close()
+	var votes []*Vote
+	for _, name := range []string{"alfa", "bravo"} {
+		v := NewVote(1, ta.Addrinfo[name].Address, ta.Addrinfo[name].Address)
+		assert.Nil(v.Sign(wallet.NewKeySigner(ta.Addrinfo[name])))
+		votes = append(votes, v)
+	}
+
+	// the producer collects the whole reward until delegates are elected
+	for i := 0; i < 2; i++ {
+		blk, err := bc.MintNewBlockWithVotes([]*Tx{}, nil, nil, votes, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Equal(1, len(blk.Tranxs[0].TxOut))
+		assert.Equal(uint64(100), blk.Tranxs[0].TxOut[0].Value)
+		assert.Nil(bc.AddBlockCommit(blk))
+		votes = nil
+	}
+
+	// the reward halves in epoch 1, and the delegates share half of it
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Equal(3, len(blk.Tranxs[0].TxOut))
+	assert.Equal(uint64(26), blk.Tranxs[0].TxOut[0].Value)
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(uint64(12), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	assert.Equal(uint64(12), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0))
+
+	// a block keeping the shares of the delegates is rejected
+	cbTx := NewCoinbaseTx(ta.Addrinfo["miner"].Address, 50, "")
+	blk = NewBlock(bc.chainID, 4, bc.TipHash(), []*Tx{cbTx})
+	assert.Nil(bc.setStateRoot(blk))
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+	cbTx = NewCoinbaseTxWithPayees([]*Payee{
+		{Address: ta.Addrinfo["miner"].Address, Amount: 38},
+		{Address: ta.Addrinfo["alfa"].Address, Amount: 12},
+		{Address: ta.Addrinfo["charlie"].Address, Amount: 12},
+	}, "")
+	blk = NewBlock(bc.chainID, 4, bc.TipHash(), []*Tx{cbTx})
+	assert.Nil(bc.setStateRoot(blk))
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+}
+
+func TestEvidence(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	genesis := &config.Genesis{
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+		Accounts:    []config.Allocation{{Address: ta.Addrinfo["alfa"].Address, Amount: 50}},
+	}
+
+	bc, err := CreateBlockchainWithGenesis(context.Background(), cfg, genesis)
+	assert.Nil(err)
+	defer bc.Close()
+	vote := NewVote(1, ta.Addrinfo["alfa"].Address, ta.Addrinfo["alfa"].Address)
+	assert.Nil(vote.Sign(wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+	blk, err := bc.MintNewBlockWithVotes([]*Tx{}, nil, nil, []*Vote{vote}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(1, len(bc.GetCandidates()))
+
+	// alfa signs two different blocks at height 2
+	signed := func(producer string, height uint32, data string) *Block {
+		blk := NewBlock(bc.chainID, height, bc.TipHash(), []*Tx{NewCoinbaseTx(ta.Addrinfo[producer].Address, 5, data)})
+		blk.SignBlock(ta.Addrinfo[producer].PublicKey, ta.Addrinfo[producer].PrivateKey)
+		return blk
+	}
+	blk1, blk2 := signed("alfa", 2, "1"), signed("alfa", 2, "2")
+	assert.Nil(NewEvidence(ta.Addrinfo["alfa"].Address, blk1, blk2).Verify())
+	assert.Equal(NewEvidence(ta.Addrinfo["alfa"].Address, blk1, blk2).Hash(), NewEvidence(ta.Addrinfo["alfa"].Address, blk2, blk1).Hash())
+
+	// the headers must conflict and be signed by the offender
+	for _, evidence := range []*Evidence{
+		NewEvidence(ta.Addrinfo["alfa"].Address, blk1, blk1),
+		NewEvidence(ta.Addrinfo["alfa"].Address, blk1, signed("alfa", 3, "2")),
+		NewEvidence(ta.Addrinfo["alfa"].Address, blk1, signed("bravo", 2, "2")),
+		NewEvidence(ta.Addrinfo["bravo"].Address, blk1, blk2),
+		NewEvidence(ta.Addrinfo["alfa"].Address, blk1, NewBlock(bc.chainID, 2, bc.TipHash(), blk2.Tranxs)),
+	} {
+		assert.Equal(ErrInvalidEvidence, errors.Cause(evidence.Verify()))
+		_, err = bc.MintNewBlockWithEvidences([]*Tx{}, nil, nil, nil, []*Evidence{evidence}, ta.Addrinfo["miner"].Address, "")
+		assert.Equal(ErrInvalidBlock, errors.Cause(err))
+	}
+
+	// committing the evidence burns the stake of alfa and revokes its candidacy
+	evidence := NewEvidence(ta.Addrinfo["alfa"].Address, blk1, blk2)
+	blk, err = bc.MintNewBlockWithEvidences([]*Tx{}, nil, nil, nil, []*Evidence{evidence}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(&state.Account{Nonce: 1}, bc.AccountState(ta.Addrinfo["alfa"].Address))
+	assert.Equal(0, len(bc.GetCandidates()))
+	blk, err = bc.GetBlockByHeight(2)
+	assert.Nil(err)
+	assert.Equal(1, len(blk.Evidences))
+	assert.Equal(evidence.Hash(), blk.Evidences[0].Hash())
+	assert.Nil(blk.Evidences[0].Verify())
+
+	// the misbehavior is penalized only once
+	_, err = bc.MintNewBlockWithEvidences([]*Tx{}, nil, nil, nil, []*Evidence{NewEvidence(ta.Addrinfo["alfa"].Address, blk2, blk1)}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+	blk1, blk2 = signed("alfa", 3, "1"), signed("alfa", 3, "2")
+	evidence = NewEvidence(ta.Addrinfo["alfa"].Address, blk1, blk2)
+	_, err = bc.MintNewBlockWithEvidences([]*Tx{}, nil, nil, nil, []*Evidence{evidence, evidence}, ta.Addrinfo["miner"].Address, "")
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+}
+
+func TestCheckpoints(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	mint := func() *Block {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		return blk
+	}
+	blk1, fork1 := mint(), mint()
+	assert.Nil(bc.AddBlockCommit(blk1))
+	blk2, fork2 := mint(), mint()
+	hash := blk2.HashBlock()
+	bc.checkpoints = parseCheckpoints([]config.Checkpoint{
+		{Height: 2, Hash: hex.EncodeToString(hash[:])},
+		{Height: 3, Hash: "invalid"},
+	})
+	assert.Equal(1, len(bc.checkpoints))
+	assert.True(bc.belowCheckpoint(2))
+	assert.False(bc.belowCheckpoint(3))
+
+	// no other block can be committed at the height of a checkpoint
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(fork2)))
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.AddBlockSync(fork2)))
+	assert.Nil(bc.AddBlockCommit(blk2))
+
+	// nor fork the chain below it
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.AddBlockSync(fork1)))
+	assert.Equal(uint32(2), bc.TipHeight())
+	assert.Equal(hash, bc.TipHash())
+	assert.Nil(bc.AddBlockCommit(mint()))
+}
+
+func TestBlockTrace(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Metrics.SlowBlock = time.Nanosecond
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	counts := func() []uint64 {
+		return []uint64{receiveLatency.Count(), validateLatency.Count(), executeLatency.Count(), indexLatency.Count(), writeLatency.Count()}
+	}
+
+	// the stages of a block received are traced from its receipt
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	blk.ReceivedAt = time.Now()
+	before := counts()
+	assert.Nil(bc.AddBlockCommit(blk))
+	for i, count := range counts() {
+		assert.Equal(before[i]+1, count)
+	}
+
+	// the ones of a block synced are neither received nor validated
+	blk, err = bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	before = counts()
+	assert.Nil(bc.AddBlockSync(blk))
+	assert.Equal([]uint64{before[0], before[1], before[2] + 1, before[3] + 1, before[4] + 1}, counts())
+
+	trace := newBlockTrace(blk)
+	assert.Equal(0, len(trace.Spans()))
+	blk.ReceivedAt = time.Now().Add(-time.Second)
+	trace = newBlockTrace(blk)
+	assert.Equal(1, len(trace.Spans()))
+	assert.True(trace.Duration() >= time.Second)
+}
+
+func TestReplayProtection(t *testing.T) {
+	assert := assert.New(t)
+	defer os.Remove(testDBPath)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.GenesisPath = "../genesis.json"
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
+	defer bc.Close()
+
+	// the created transaction is signed for the chain
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["bravo"]), 100, []*Payee{{ta.Addrinfo["charlie"].Address, 100}})
+	assert.Nil(err)
+	assert.Equal(uint32(1), tx.ChainID)
+	assert.Nil(bc.ValidateTxChainID(tx))
+	decoded := &Tx{}
+	decoded.ConvertFromTxPb(tx.ConvertToTxPb())
+	assert.Equal(tx.Hash(), decoded.Hash())
+
+	// it cannot be replayed on another chain, nor passed as a legacy transaction
+	replayed := &Tx{}
+	replayed.ConvertFromTxPb(tx.ConvertToTxPb())
+	replayed.ChainID = 2
+	assert.NotEqual(tx.Hash(), replayed.Hash())
+	assert.Equal(ErrTxWrongChainID, errors.Cause(bc.ValidateTxChainID(replayed)))
+	blk, err := bc.MintNewBlock([]*Tx{replayed}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+	replayed.ChainID = 0
+	assert.Equal(ErrTxWrongChainID, errors.Cause(bc.ValidateTxChainID(replayed)))
+
+	// legacy transactions are only accepted if configured to, and the signature commits to the chain ID
+	bc.config.Chain.AcceptLegacyTxs = true
+	assert.Nil(bc.ValidateTxChainID(replayed))
+	blk, err = bc.MintNewBlock([]*Tx{replayed}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.NotNil(bc.ValidateBlock(blk))
+	bc.Reset()
+
+	blk, err = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(uint64(100), bc.BalanceOf(ta.Addrinfo["charlie"].Address, 0))
+
+	out := NewTxOutput(100, 0)
+	tx.ChainID = 1
+	signed := tx.SigHash(0, out.TxOutputPb)
+	tx.ChainID = 2
+	assert.NotEqual(signed, tx.SigHash(0, out.TxOutputPb))
+}
+
+func byteToHash(b []byte) cp.Hash32B {
+	hash := cp.ZeroHash32B
+	copy(hash[:], b)
+	return hash
 }
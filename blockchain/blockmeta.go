@@ -0,0 +1,144 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+// BlockMeta is the header info of a block, which is stored apart from the block so it can be read without
+// deserializing the block
+type BlockMeta struct {
+	Hash      cp.Hash32B
+	Height    uint32
+	Timestamp uint64
+	TxCount   uint32
+	// Producer is the public key of the block proposer
+	Producer []byte
+	// Size is the size of the serialized block, 0 if it is unknown because the body of the block has been pruned
+	// before its metadata is stored
+	Size uint32
+	// CoinbaseData is the data of the coinbase transaction of the block, nil if only the header of the block is known
+	CoinbaseData []byte
+	// Coinbase is the structured payload of the coinbase data, nil if the data does not carry one
+	Coinbase *CoinbasePayload
+}
+
+// newBlockMeta returns the metadata of the block, which is of the given serialized size
+func newBlockMeta(blk *Block, hash cp.Hash32B, size int) *BlockMeta {
+	meta := &BlockMeta{
+		Hash:         hash,
+		Height:       blk.Header.height,
+		Timestamp:    blk.Header.timestamp,
+		TxCount:      blk.Header.trnxNumber,
+		Producer:     blk.Header.pubkey,
+		Size:         uint32(size),
+		CoinbaseData: coinbaseDataOf(blk),
+	}
+	// a malformed payload, which only the genesis block can carry since it is not validated, is left out
+	if payload, err := ParseCoinbasePayload(meta.CoinbaseData); err == nil {
+		meta.Coinbase = payload
+	}
+	return meta
+}
+
+// ConvertToBlockMetaPb converts BlockMeta to protobuf's BlockMetaPb
+func (m *BlockMeta) ConvertToBlockMetaPb() *iproto.BlockMetaPb {
+	pb := &iproto.BlockMetaPb{
+		Hash:           m.Hash[:],
+		Height:         m.Height,
+		Timestamp:      m.Timestamp,
+		TxCount:        m.TxCount,
+		ProducerPubKey: m.Producer,
+		Size:           m.Size,
+		CoinbaseData:   m.CoinbaseData,
+	}
+	if m.Coinbase != nil {
+		pb.CoinbasePayload = m.Coinbase.ConvertToCoinbasePayloadPb()
+	}
+	return pb
+}
+
+// ConvertFromBlockMetaPb converts protobuf's BlockMetaPb to BlockMeta
+func (m *BlockMeta) ConvertFromBlockMetaPb(pb *iproto.BlockMetaPb) {
+	copy(m.Hash[:], pb.Hash)
+	m.Height = pb.Height
+	m.Timestamp = pb.Timestamp
+	m.TxCount = pb.TxCount
+	m.Producer = pb.ProducerPubKey
+	m.Size = pb.Size
+	m.CoinbaseData = pb.CoinbaseData
+	m.Coinbase = nil
+	if pb.CoinbasePayload != nil {
+		m.Coinbase = &CoinbasePayload{}
+		m.Coinbase.ConvertFromCoinbasePayloadPb(pb.CoinbasePayload)
+	}
+}
+
+// putBlockMeta adds the metadata of the block to the batch
+func putBlockMeta(batch *blockdb.Batch, meta *BlockMeta) error {
+	serialized, err := proto.Marshal(meta.ConvertToBlockMetaPb())
+	if err != nil {
+		return err
+	}
+	batch.PutBlockMeta(meta.Hash[:], serialized)
+	return nil
+}
+
+// GetBlockMetaByHeight returns the metadata of the block at the height
+func (bc *Blockchain) GetBlockMetaByHeight(height uint32) (*BlockMeta, error) {
+	hash, err := bc.GetHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return bc.GetBlockMetaByHash(hash)
+}
+
+// GetBlockMetaByHash returns the metadata of the block with the hash
+// The blocks committed before their metadata is stored, which have yet to be reindexed, fall back to reading the
+// block.
+func (bc *Blockchain) GetBlockMetaByHash(hash cp.Hash32B) (*BlockMeta, error) {
+	serialized, err := bc.blockDb.GetBlockMeta(hash[:])
+	if err == nil {
+		pb := iproto.BlockMetaPb{}
+		if err := proto.Unmarshal(serialized, &pb); err != nil {
+			return nil, err
+		}
+		meta := BlockMeta{}
+		meta.ConvertFromBlockMetaPb(&pb)
+		return &meta, nil
+	}
+	if errors.Cause(err) != blockdb.ErrNotExist {
+		return nil, err
+	}
+
+	height, err := bc.GetHeightByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if height < bc.pruneHeight {
+		blk, err := bc.GetBlockHeaderByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		return newBlockMeta(blk, hash, 0), nil
+	}
+	blk, err := bc.GetBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	blkBytes, err := blk.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return newBlockMeta(blk, hash, len(blkBytes)), nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// BloomSize is the size in bytes of the bloom filter of a block
+	BloomSize = 256
+	// bloomHashes is the number of bits set in the bloom filter for each item
+	bloomHashes = 3
+)
+
+// Bloom is the bloom filter of the contract addresses and topics of the logs emitted in a block, which tells the
+// blocks surely not having a log matching a query apart without reading their receipts
+type Bloom [BloomSize]byte
+
+// Add adds the item to the bloom filter
+func (b *Bloom) Add(item []byte) {
+	for _, bit := range bloomBits(item) {
+		b[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Test returns false if the item has surely not been added to the bloom filter
+func (b *Bloom) Test(item []byte) bool {
+	for _, bit := range bloomBits(item) {
+		if b[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomBits returns the bits set for the item, which are taken from its blake2b hash
+func bloomBits(item []byte) [bloomHashes]uint {
+	hash := blake2b.Sum256(item)
+	var bits [bloomHashes]uint
+	for i := range bits {
+		bits[i] = uint(binary.BigEndian.Uint16(hash[2*i:])) % (BloomSize * 8)
+	}
+	return bits
+}
+
+// logsBloom returns the bloom filter of the logs in the receipts
+func logsBloom(receipts []*Receipt) *Bloom {
+	bloom := &Bloom{}
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			bloom.Add([]byte(l.Address))
+			for _, topic := range l.Topics {
+				bloom.Add(topic)
+			}
+		}
+	}
+	return bloom
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"container/heap"
+
+	"github.com/golang/protobuf/proto"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	iproto "github.com/iotexproject/iotex-core/proto"
+)
+
+const (
+	// FeeRateOrder packs the transactions paying the highest fee per byte first
+	FeeRateOrder = "FEE_RATE"
+)
+
+// TxCandidate is a transaction considered for packing into a block
+type TxCandidate struct {
+	Tx *Tx
+	// Fee is the fee paid by the transaction
+	Fee uint64
+	// Size is the number of bytes the transaction adds to the block
+	Size int
+	// Index is the position of the transaction among the candidates given to the block builder
+	Index int
+}
+
+// TxOrderPolicy orders the candidates ready to be packed into a block, which are the ones whose parents among the
+// candidates, i.e., the transactions they spend the outputs of, have been packed
+type TxOrderPolicy interface {
+	// Less returns true if the candidate a is packed before b
+	Less(a, b *TxCandidate) bool
+}
+
+// NewTxOrderPolicy returns the tx order policy of the given name, one of FEE_RATE, or the one packing the candidates
+// in the order they are given if it is empty
+func NewTxOrderPolicy(name string) TxOrderPolicy {
+	switch name {
+	case FeeRateOrder:
+		return feeRateOrder{}
+	default:
+		return givenOrder{}
+	}
+}
+
+// givenOrder packs the candidates in the order they are given
+type givenOrder struct{}
+
+func (givenOrder) Less(a, b *TxCandidate) bool {
+	return a.Index < b.Index
+}
+
+// feeRateOrder packs the candidates paying the highest fee per byte first, and the ones paying the same fee rate in
+// the order they are given
+type feeRateOrder struct{}
+
+func (feeRateOrder) Less(a, b *TxCandidate) bool {
+	// a.Fee / a.Size > b.Fee / b.Size
+	ra, rb := a.Fee*uint64(b.Size), b.Fee*uint64(a.Size)
+	if ra != rb {
+		return ra > rb
+	}
+	return a.Index < b.Index
+}
+
+// blockBuilder packs the candidates into a block in the order of the policy, the parents before the children, under
+// the limits of the block
+type blockBuilder struct {
+	policy TxOrderPolicy
+	// maxTxs is the maximum number of packed transactions
+	maxTxs int
+	// maxSize is the maximum size of the block in bytes, 0 for no limit
+	maxSize int
+}
+
+// build returns the transactions packed into a block of 'size' bytes without them
+// Among the candidates ready to be packed, the first one in the order of the policy is packed next if it fits in the
+// block, otherwise it is skipped along with its descendants.
+func (b *blockBuilder) build(candidates []*TxCandidate, size int) []*Tx {
+	byHash := make(map[cp.Hash32B]int, len(candidates))
+	for i, c := range candidates {
+		byHash[c.Tx.Hash()] = i
+	}
+	// pending counts the parents of each candidate yet to be packed
+	pending := make([]int, len(candidates))
+	children := make([][]int, len(candidates))
+	for i, c := range candidates {
+		parents := make(map[int]bool)
+		for _, txIn := range c.Tx.TxIn {
+			hash := cp.ZeroHash32B
+			copy(hash[:], txIn.TxHash)
+			if p, ok := byHash[hash]; ok && p != i && !parents[p] {
+				parents[p] = true
+				children[p] = append(children[p], i)
+			}
+		}
+		pending[i] = len(parents)
+	}
+
+	ready := &txCandidateQueue{policy: b.policy}
+	for i, c := range candidates {
+		if pending[i] == 0 {
+			ready.items = append(ready.items, c)
+		}
+	}
+	heap.Init(ready)
+	packed := []*Tx{}
+	for ready.Len() > 0 && len(packed) < b.maxTxs {
+		c := heap.Pop(ready).(*TxCandidate)
+		if b.maxSize > 0 && size+c.Size > b.maxSize {
+			continue
+		}
+		size += c.Size
+		packed = append(packed, c.Tx)
+		for _, child := range children[c.Index] {
+			pending[child]--
+			if pending[child] == 0 {
+				heap.Push(ready, candidates[child])
+			}
+		}
+	}
+	return packed
+}
+
+// txCandidates returns the candidates of the transactions with the fees they pay and their sizes in a block, a
+// transaction can spend the outputs of the other ones
+func (bc *Blockchain) txCandidates(txs []*Tx) ([]*TxCandidate, error) {
+	view := bc.Utk.NewView()
+	for _, tx := range txs {
+		if err := view.AddTx(tx); err != nil {
+			return nil, err
+		}
+	}
+	candidates := make([]*TxCandidate, 0, len(txs))
+	seen := make(map[cp.Hash32B]bool, len(txs))
+	for _, tx := range txs {
+		hash := tx.Hash()
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		fee, err := view.TxFee(tx)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, &TxCandidate{
+			Tx:    tx,
+			Fee:   fee,
+			Size:  proto.Size(&iproto.BlockPb{Transactions: []*iproto.TxPb{tx.ConvertToTxPb()}}),
+			Index: len(candidates),
+		})
+	}
+	return candidates, nil
+}
+
+// txCandidateQueue is a priority queue of the candidates, the first one in the order of the policy on top
+type txCandidateQueue struct {
+	policy TxOrderPolicy
+	items  []*TxCandidate
+}
+
+func (q *txCandidateQueue) Len() int           { return len(q.items) }
+func (q *txCandidateQueue) Less(i, j int) bool { return q.policy.Less(q.items[i], q.items[j]) }
+func (q *txCandidateQueue) Swap(i, j int)      { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *txCandidateQueue) Push(x interface{}) {
+	q.items = append(q.items, x.(*TxCandidate))
+}
+
+func (q *txCandidateQueue) Pop() interface{} {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
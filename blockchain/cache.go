@@ -0,0 +1,88 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache is a cache of fixed capacity evicting the least recently used entry, it is safe for concurrent use
+// A cache of capacity 0 keeps nothing.
+type lruCache struct {
+	mutex    sync.Mutex
+	capacity int
+	items    map[interface{}]*list.Element
+	order    *list.List // most recently used first
+}
+
+type lruEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+// newLRUCache returns a cache holding at most 'capacity' entries
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{capacity: capacity, items: map[interface{}]*list.Element{}, order: list.New()}
+}
+
+// Get returns the value of the key and marks it as the most recently used
+func (c *lruCache) Get(key interface{}) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true
+}
+
+// Add adds or updates the value of the key, evicting the least recently used entry if the cache is full
+func (c *lruCache) Add(key interface{}, value interface{}) {
+	if c.capacity <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry{key, value})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Remove removes the key from the cache
+func (c *lruCache) Remove(key interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.order.Remove(elem)
+		delete(c.items, key)
+	}
+}
+
+// Purge removes all entries from the cache
+func (c *lruCache) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.items = map[interface{}]*list.Element{}
+	c.order.Init()
+}
+
+// Len returns the number of entries in the cache
+func (c *lruCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCache(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newLRUCache(2)
+	c.Add(1, "a")
+	c.Add(2, "b")
+	v, ok := c.Get(1)
+	assert.True(ok)
+	assert.Equal("a", v)
+
+	// 2 is the least recently used
+	c.Add(3, "c")
+	assert.Equal(2, c.Len())
+	_, ok = c.Get(2)
+	assert.False(ok)
+	c.Add(1, "d")
+	v, _ = c.Get(1)
+	assert.Equal("d", v)
+
+	c.Remove(1)
+	_, ok = c.Get(1)
+	assert.False(ok)
+	c.Purge()
+	assert.Equal(0, c.Len())
+
+	disabled := newLRUCache(0)
+	disabled.Add(1, "a")
+	_, ok = disabled.Get(1)
+	assert.False(ok)
+}
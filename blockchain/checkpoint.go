@@ -0,0 +1,87 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"encoding/hex"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+// knownCheckpoints are the checkpoints hard-coded for the chains by chain ID, which the checkpoints of the config add
+// to
+var knownCheckpoints = map[uint32]map[uint32]cp.Hash32B{}
+
+// parseCheckpoints returns the hashes pinned by the checkpoints by height, the hashes are validated along with the
+// config so the invalid ones are skipped
+func parseCheckpoints(checkpoints []config.Checkpoint) map[uint32]cp.Hash32B {
+	hashes := make(map[uint32]cp.Hash32B)
+	for _, c := range checkpoints {
+		hash, err := hex.DecodeString(c.Hash)
+		if err != nil || len(hash) != len(cp.ZeroHash32B) {
+			log.WithField("height", c.Height).Warning("Skipping checkpoint with invalid hash")
+			continue
+		}
+		var pinned cp.Hash32B
+		copy(pinned[:], hash)
+		hashes[c.Height] = pinned
+	}
+	return hashes
+}
+
+// checkpointAt returns the hash pinned by the checkpoint at the height, false if there is none
+func (bc *Blockchain) checkpointAt(height uint32) (cp.Hash32B, bool) {
+	if hash, ok := bc.checkpoints[height]; ok {
+		return hash, true
+	}
+	hash, ok := knownCheckpoints[bc.chainID][height]
+	return hash, ok
+}
+
+// lowestCheckpoint returns the height of the lowest checkpoint in [start, end], false if there is none
+func (bc *Blockchain) lowestCheckpoint(start uint32, end uint32) (uint32, bool) {
+	lowest, found := uint32(0), false
+	for _, checkpoints := range []map[uint32]cp.Hash32B{bc.checkpoints, knownCheckpoints[bc.chainID]} {
+		for height := range checkpoints {
+			if height >= start && height <= end && (!found || height < lowest) {
+				lowest, found = height, true
+			}
+		}
+	}
+	return lowest, found
+}
+
+// belowCheckpoint returns true if the block at the height is at or below the highest checkpoint, so its input
+// scripts are not verified
+func (bc *Blockchain) belowCheckpoint(height uint32) bool {
+	_, ok := bc.lowestCheckpoint(height, math.MaxUint32)
+	return ok
+}
+
+// validateCheckpoint verifies the block matches the checkpoint at its height, and does not fork the chain at or
+// below a checkpoint the chain has reached or the finalized height
+func (bc *Blockchain) validateCheckpoint(blk *Block) error {
+	height := blk.Height()
+	if hash, ok := bc.checkpointAt(height); ok && blk.HashBlock() != hash {
+		return errors.Wrapf(ErrInvalidBlock, "Block %d does not match checkpoint %x", height, hash)
+	}
+	if blk.PrevHash() == bc.tip {
+		return nil
+	}
+	// the block replaces the blocks from its height up to the tip
+	if bc.finalHeight > 0 && height <= bc.finalHeight {
+		return errors.Wrapf(ErrFinalized, "Block %d forks the chain below the finalized height %d", height, bc.finalHeight)
+	}
+	if h, ok := bc.lowestCheckpoint(height, bc.height); ok {
+		return errors.Wrapf(ErrInvalidBlock, "Block %d forks the chain below checkpoint %d", height, h)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/txvm"
+)
+
+const (
+	// coinbasePayloadTag starts the coinbase data carrying a structured payload
+	coinbasePayloadTag = "iotx1"
+	// coinbasePayloadSep separates the fields of a structured payload, the memo being the last one may contain it
+	coinbasePayloadSep = "|"
+	// structuredCoinbase is the coinbase policy requiring the blocks to carry a structured payload
+	structuredCoinbase = "STRUCTURED"
+)
+
+// ClientVersion is the version of the client put in the structured coinbase payload of the blocks it mints, which can
+// be overridden at build time with -ldflags
+var ClientVersion = "iotex-core/1"
+
+// CoinbasePayload is the structured payload of the coinbase data of a block, which names the address its producer is
+// paid to, the epoch and height of the block and the client minting it
+// The height keeps the coinbase transactions paying the same amount to the same producer apart.
+type CoinbasePayload struct {
+	Producer      string
+	Epoch         uint32
+	Height        uint32
+	ClientVersion string
+	// Memo is the optional free-form memo of the operator
+	Memo string
+}
+
+// String encodes the payload into the coinbase data
+func (p *CoinbasePayload) String() string {
+	return strings.Join([]string{
+		coinbasePayloadTag,
+		p.Producer,
+		strconv.FormatUint(uint64(p.Epoch), 10),
+		strconv.FormatUint(uint64(p.Height), 10),
+		p.ClientVersion,
+		p.Memo,
+	}, coinbasePayloadSep)
+}
+
+// ParseCoinbasePayload decodes the structured payload of the coinbase data, it returns nil without error if the data
+// does not carry a structured payload
+func ParseCoinbasePayload(data []byte) (*CoinbasePayload, error) {
+	if !bytes.HasPrefix(data, []byte(coinbasePayloadTag+coinbasePayloadSep)) {
+		return nil, nil
+	}
+	fields := strings.SplitN(string(data), coinbasePayloadSep, 6)
+	if len(fields) != 6 {
+		return nil, errors.Errorf("Coinbase payload has %d fields, expecting 6", len(fields))
+	}
+	epoch, err := strconv.ParseUint(fields[2], 10, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid epoch %s in coinbase payload", fields[2])
+	}
+	height, err := strconv.ParseUint(fields[3], 10, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid height %s in coinbase payload", fields[3])
+	}
+	if fields[1] == "" || fields[4] == "" {
+		return nil, errors.New("Coinbase payload misses the producer or the client version")
+	}
+	return &CoinbasePayload{
+		Producer:      fields[1],
+		Epoch:         uint32(epoch),
+		Height:        uint32(height),
+		ClientVersion: fields[4],
+		Memo:          fields[5],
+	}, nil
+}
+
+// ConvertToCoinbasePayloadPb converts CoinbasePayload to protobuf's CoinbasePayloadPb
+func (p *CoinbasePayload) ConvertToCoinbasePayloadPb() *iproto.CoinbasePayloadPb {
+	return &iproto.CoinbasePayloadPb{
+		Producer:      p.Producer,
+		Epoch:         p.Epoch,
+		Height:        p.Height,
+		ClientVersion: p.ClientVersion,
+		Memo:          p.Memo,
+	}
+}
+
+// ConvertFromCoinbasePayloadPb converts protobuf's CoinbasePayloadPb to CoinbasePayload
+func (p *CoinbasePayload) ConvertFromCoinbasePayloadPb(pb *iproto.CoinbasePayloadPb) {
+	p.Producer = pb.Producer
+	p.Epoch = pb.Epoch
+	p.Height = pb.Height
+	p.ClientVersion = pb.ClientVersion
+	p.Memo = pb.Memo
+}
+
+// coinbaseDataOf returns the data of the coinbase transaction of the block, nil if the block has no coinbase, e.g.,
+// because only its header has been read
+func coinbaseDataOf(blk *Block) []byte {
+	for _, tx := range blk.Tranxs {
+		if tx.IsCoinbase() {
+			return tx.TxIn[0].UnlockScript
+		}
+	}
+	return nil
+}
+
+// coinbaseData returns the data of the coinbase of the block at the given height paying the producer at toaddr
+// The structured payload carrying the given data as memo, or the memo of the config if it is empty, is returned if the
+// coinbase policy requires it, the given data, or random data if it is empty, otherwise.
+func (bc *Blockchain) coinbaseData(height uint32, toaddr, data string) (string, error) {
+	if bc.config.Chain.CoinbasePolicy == structuredCoinbase {
+		if data == "" {
+			data = bc.config.Chain.CoinbaseMemo
+		}
+		payload := &CoinbasePayload{
+			Producer:      toaddr,
+			Epoch:         bc.epochs.EpochOf(height),
+			Height:        height,
+			ClientVersion: ClientVersion,
+			Memo:          data,
+		}
+		data = payload.String()
+	} else {
+		data = coinbaseData(data)
+	}
+	if max := bc.config.Chain.MaxCoinbaseDataSize; max > 0 && len(data) > int(max) {
+		return "", errors.Wrapf(ErrInvalidBlock, "Coinbase data of %d bytes exceeds the limit %d", len(data), max)
+	}
+	return data, nil
+}
+
+// validateCoinbaseData verifies the data of the coinbase of the block does not exceed the size limit and, if it
+// carries a structured payload, which the coinbase policy may require, that the payload names the address the
+// coinbase pays the producer at, and the epoch and height of the block
+func (bc *Blockchain) validateCoinbaseData(blk *Block, cbTx *Tx) error {
+	height := blk.Header.height
+	data := cbTx.TxIn[0].UnlockScript
+	if max := bc.config.Chain.MaxCoinbaseDataSize; max > 0 && len(data) > int(max) {
+		return errors.Errorf("Coinbase data of %d bytes exceeds the limit %d", len(data), max)
+	}
+	payload, err := ParseCoinbasePayload(data)
+	if err != nil {
+		return err
+	}
+	if payload == nil {
+		if bc.config.Chain.CoinbasePolicy == structuredCoinbase {
+			return errors.New("Coinbase data does not carry a structured payload")
+		}
+		return nil
+	}
+	lockScript, err := txvm.PayToAddrScript(payload.Producer)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid producer %s in coinbase payload", payload.Producer)
+	}
+	if !bytes.Equal(cbTx.TxOut[0].LockScript, lockScript) {
+		return errors.Errorf("Coinbase does not pay the producer %s of its payload", payload.Producer)
+	}
+	if epoch := bc.epochs.EpochOf(height); payload.Epoch != epoch || payload.Height != height {
+		return errors.Errorf("Coinbase payload of epoch %d height %d, expecting epoch %d height %d", payload.Epoch,
+			payload.Height, epoch, height)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/config"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestCoinbasePayload(t *testing.T) {
+	assert := assert.New(t)
+
+	payload := &CoinbasePayload{
+		Producer:      ta.Addrinfo["miner"].Address,
+		Epoch:         3,
+		Height:        25,
+		ClientVersion: ClientVersion,
+		Memo:          "pool|memo",
+	}
+	parsed, err := ParseCoinbasePayload([]byte(payload.String()))
+	assert.Nil(err)
+	assert.Equal(payload, parsed)
+
+	pb := payload.ConvertToCoinbasePayloadPb()
+	converted := &CoinbasePayload{}
+	converted.ConvertFromCoinbasePayloadPb(pb)
+	assert.Equal(payload, converted)
+
+	// data without the tag carries no payload
+	parsed, err = ParseCoinbasePayload([]byte(GenesisCoinbaseData))
+	assert.Nil(err)
+	assert.Nil(parsed)
+
+	for _, data := range []string{"iotx1|addr|1|2", "iotx1|addr|x|2|v|", "iotx1|addr|1|2||memo", "iotx1||1|2|v|"} {
+		_, err = ParseCoinbasePayload([]byte(data))
+		assert.NotNil(err, data)
+	}
+}
+
+func TestCoinbaseDataPolicy(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.CoinbasePolicy = structuredCoinbase
+	cfg.Chain.CoinbaseMemo = "operator"
+	cfg.Chain.MaxCoinbaseDataSize = 128
+
+	miner := ta.Addrinfo["miner"].Address
+	bc, err := CreateBlockchain(context.Background(), miner, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	// the minted block carries the structured payload with the memo of the config
+	blk, err := bc.MintNewBlock([]*Tx{}, miner, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	meta, err := bc.GetBlockMetaByHeight(1)
+	assert.Nil(err)
+	assert.Equal(&CoinbasePayload{
+		Producer:      miner,
+		Epoch:         bc.EpochOf(1),
+		Height:        1,
+		ClientVersion: ClientVersion,
+		Memo:          "operator",
+	}, meta.Coinbase)
+	fromPb := &BlockMeta{}
+	fromPb.ConvertFromBlockMetaPb(meta.ConvertToBlockMetaPb())
+	assert.Equal(meta, fromPb)
+
+	// the data given when minting replaces the memo, and has to fit in the size limit
+	blk, err = bc.MintNewBlock([]*Tx{}, miner, "custom")
+	assert.Nil(err)
+	payload, err := ParseCoinbasePayload(coinbaseDataOf(blk))
+	assert.Nil(err)
+	assert.Equal("custom", payload.Memo)
+	_, err = bc.MintNewBlock([]*Tx{}, miner, string(make([]byte, 128)))
+	assert.Equal(ErrInvalidBlock, errors.Cause(err))
+
+	// blocks minted under another policy are rejected
+	bc.config.Chain.CoinbasePolicy = ""
+	unstructured, err := bc.MintNewBlock([]*Tx{}, miner, "")
+	assert.Nil(err)
+	wrongEpoch := &CoinbasePayload{Producer: miner, Epoch: bc.EpochOf(2) + 1, Height: 2, ClientVersion: ClientVersion}
+	forged, err := bc.MintNewBlock([]*Tx{}, miner, wrongEpoch.String())
+	assert.Nil(err)
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(forged)))
+	bc.config.Chain.MaxCoinbaseDataSize = 0
+	oversized, err := bc.MintNewBlock([]*Tx{}, miner, string(make([]byte, 129)))
+	assert.Nil(err)
+	assert.Nil(bc.ValidateBlock(unstructured))
+
+	bc.config.Chain.CoinbasePolicy = structuredCoinbase
+	bc.config.Chain.MaxCoinbaseDataSize = 128
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(unstructured)))
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(oversized)))
+	assert.Nil(bc.ValidateBlock(blk))
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	// LargestFirst spends the largest UTXO first, which minimizes the number of inputs
+	LargestFirst = "LARGEST_FIRST"
+	// BranchAndBound searches for a set of UTXO paying the amount exactly or leaving only dust, so that no change
+	// output is created, and falls back to largest first
+	BranchAndBound = "BRANCH_AND_BOUND"
+	// RandomImprove picks random UTXO to pay the amount, then keeps adding random UTXO while it moves the change
+	// closer to the amount, which consolidates small UTXO and leaves change useful for future payments
+	RandomImprove = "RANDOM_IMPROVE"
+
+	// bnbMaxTries bounds the number of branches explored by BranchAndBound
+	bnbMaxTries = 100000
+)
+
+// SelectCoins selects the UTXO among the candidates to pay at least 'amount' with the coin selection algorithm,
+// spending at most maxInputs UTXO unless it is 0, and returns them with the change
+// The candidates are spent in the given order if the algorithm is empty. It returns nil if the amount cannot be paid.
+func SelectCoins(candidates []*UtxoEntry, amount uint64, algorithm string, dustThreshold uint64, maxInputs int) ([]*UtxoEntry, uint64) {
+	var selected []*UtxoEntry
+	switch algorithm {
+	case LargestFirst:
+		selected = selectInOrder(sortByValue(candidates), amount, maxInputs)
+	case BranchAndBound:
+		selected = branchAndBound(sortByValue(candidates), amount, dustThreshold, maxInputs)
+		if selected == nil {
+			selected = selectInOrder(sortByValue(candidates), amount, maxInputs)
+		}
+	case RandomImprove:
+		selected = randomImprove(candidates, amount, maxInputs)
+	default:
+		selected = selectInOrder(candidates, amount, maxInputs)
+	}
+	if selected == nil {
+		return nil, 0
+	}
+
+	total := uint64(0)
+	for _, utxo := range selected {
+		total += utxo.Value
+	}
+	return selected, total - amount
+}
+
+// isDust returns true if the change is too small to be worth an output
+func isDust(change uint64, dustThreshold uint64) bool {
+	return change < dustThreshold
+}
+
+// sortByValue returns the UTXO sorted from the largest to the smallest, ties broken by the outpoint
+func sortByValue(candidates []*UtxoEntry) []*UtxoEntry {
+	sorted := make([]*UtxoEntry, len(candidates))
+	copy(sorted, candidates)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Value != sorted[j].Value {
+			return sorted[i].Value > sorted[j].Value
+		}
+		if c := bytes.Compare(sorted[i].txHash[:], sorted[j].txHash[:]); c != 0 {
+			return c < 0
+		}
+		return sorted[i].outIndex < sorted[j].outIndex
+	})
+	return sorted
+}
+
+// selectInOrder spends the UTXO in order until the amount is paid
+func selectInOrder(candidates []*UtxoEntry, amount uint64, maxInputs int) []*UtxoEntry {
+	selected := []*UtxoEntry{}
+	total := uint64(0)
+	for _, utxo := range candidates {
+		if total >= amount && len(selected) > 0 {
+			break
+		}
+		if maxInputs > 0 && len(selected) == maxInputs {
+			return nil
+		}
+		selected = append(selected, utxo)
+		total += utxo.Value
+	}
+	if total < amount || len(selected) == 0 {
+		return nil
+	}
+	return selected
+}
+
+// branchAndBound searches the UTXO sorted by value depth first for the set paying the amount with the least change,
+// only accepting sets without change or with dust change
+func branchAndBound(sorted []*UtxoEntry, amount uint64, dustThreshold uint64, maxInputs int) []*UtxoEntry {
+	// remaining[i] is the total value of the UTXO from i on, for pruning branches which cannot reach the amount
+	remaining := make([]uint64, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + sorted[i].Value
+	}
+
+	var best []*UtxoEntry
+	bestChange := uint64(math.MaxUint64)
+	selected := []*UtxoEntry{}
+	tries := 0
+	var search func(i int, total uint64)
+	search = func(i int, total uint64) {
+		if tries >= bnbMaxTries || bestChange == 0 {
+			return
+		}
+		tries++
+		if total >= amount {
+			// adding more UTXO only increases the change
+			change := total - amount
+			if (change == 0 || isDust(change, dustThreshold)) && change < bestChange && len(selected) > 0 {
+				best = append([]*UtxoEntry{}, selected...)
+				bestChange = change
+			}
+			return
+		}
+		if i == len(sorted) || total+remaining[i] < amount || (maxInputs > 0 && len(selected) == maxInputs) {
+			return
+		}
+		selected = append(selected, sorted[i])
+		search(i+1, total+sorted[i].Value)
+		selected = selected[:len(selected)-1]
+		search(i+1, total)
+	}
+	search(0, 0)
+	return best
+}
+
+// randomImprove picks random UTXO until the amount is paid, then improves the selection by adding random UTXO which
+// bring the total closer to twice the amount without exceeding three times the amount
+func randomImprove(candidates []*UtxoEntry, amount uint64, maxInputs int) []*UtxoEntry {
+	perm := rand.Perm(len(candidates))
+	shuffled := make([]*UtxoEntry, len(candidates))
+	for i, j := range perm {
+		shuffled[i] = candidates[j]
+	}
+
+	selected := []*UtxoEntry{}
+	total := uint64(0)
+	i := 0
+	for ; i < len(shuffled) && (total < amount || len(selected) == 0); i++ {
+		if maxInputs > 0 && len(selected) == maxInputs {
+			break
+		}
+		selected = append(selected, shuffled[i])
+		total += shuffled[i].Value
+	}
+	if total < amount || len(selected) == 0 {
+		// random UTXO run out of inputs before paying the amount, which the largest UTXO may still do
+		return selectInOrder(sortByValue(candidates), amount, maxInputs)
+	}
+	if amount > math.MaxUint64/3 {
+		return selected
+	}
+
+	ideal, limit := 2*amount, 3*amount
+	for ; i < len(shuffled); i++ {
+		if maxInputs > 0 && len(selected) == maxInputs {
+			break
+		}
+		next := total + shuffled[i].Value
+		if next <= limit && distance(next, ideal) < distance(total, ideal) {
+			selected = append(selected, shuffled[i])
+			total = next
+		}
+	}
+	return selected
+}
+
+func distance(a uint64, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+// UtxoCommitment returns the commitment to the UTXO set once the block at the height is applied
+// The commitment is a rolling hash of the UTXO entries maintained as the blocks are committed, so two nodes can
+// compare their UTXO sets at a height by their commitments, e.g., to verify a UTXO snapshot against the chain of a
+// peer before importing it. The commitments of the blocks committed before they were recorded do not exist, except
+// for the tip.
+func (bc *Blockchain) UtxoCommitment(height uint32) (cp.Hash32B, error) {
+	buf, err := bc.blockDb.GetUtxoCommitment(height)
+	if errors.Cause(err) == blockdb.ErrNotExist && height == bc.height {
+		return bc.Utk.Commitment(), nil
+	}
+	if err != nil {
+		return cp.ZeroHash32B, err
+	}
+	var commitment cp.Hash32B
+	copy(commitment[:], buf)
+	return commitment, nil
+}
+
+// SnapshotUtxoCommitment returns the height of the UTXO snapshot and the commitment to its UTXO set, which matches
+// UtxoCommitment at the height of a chain having the same UTXO set
+func SnapshotUtxoCommitment(buf []byte) (uint32, cp.Hash32B, error) {
+	snapshot := iproto.UtxoSnapshotPb{}
+	if err := proto.Unmarshal(buf, &snapshot); err != nil {
+		return 0, cp.ZeroHash32B, errors.Wrapf(ErrInvalidSnapshot, "%v", err)
+	}
+	if snapshot.Utxo == nil {
+		return 0, cp.ZeroHash32B, errors.Wrap(ErrInvalidSnapshot, "Missing UTXO")
+	}
+	tk := NewUtxoTracker()
+	if err := tk.convertFromUtxoMapPb(snapshot.Utxo); err != nil {
+		return 0, cp.ZeroHash32B, errors.Wrapf(ErrInvalidSnapshot, "%v", err)
+	}
+	return snapshot.Height, tk.Commitment(), nil
+}
+
+// Commitment returns the commitment to the UTXO pool
+func (tk *UtxoTracker) Commitment() cp.Hash32B {
+	return tk.commitment.Sum()
+}
+
+// commitmentAfter returns the rolling hash of the UTXO pool once the UTXO entries changed in a view and their
+// coinbase heights are applied, without touching the pool
+func (tk *UtxoTracker) commitmentAfter(diff map[cp.Hash32B][]*TxOutput, coinbase map[cp.Hash32B]uint32) *cp.MultisetHash {
+	commitment := tk.commitment.Clone()
+	for hash, utxo := range diff {
+		if old, ok := tk.utxoPool.get(hash); ok {
+			commitment.Remove(utxoEntryStream(hash, old, tk.coinbaseHeights))
+		}
+		if utxo != nil {
+			commitment.Add(utxoEntryStream(hash, utxo, coinbase))
+		}
+	}
+	return commitment
+}
+
+// utxoEntryStream returns the byte stream of the unspent outputs of a transaction, the element of the entry in the
+// commitment to the UTXO pool
+// The stream is the transaction hash, the 1-byte coinbase flag and the 4-byte minting height of a coinbase, followed
+// by the 4-byte index, 8-byte value, 4-byte lock script size and the lock script of each output. All integers are in
+// the machine endian.
+func utxoEntryStream(hash cp.Hash32B, utxo []*TxOutput, coinbase map[cp.Hash32B]uint32) []byte {
+	stream := append([]byte{}, hash[:]...)
+	height, ok := coinbase[hash]
+	temp := make([]byte, 8)
+	if ok {
+		cm.MachineEndian.PutUint32(temp, height)
+		stream = append(append(stream, 1), temp[:4]...)
+	} else {
+		stream = append(stream, 0)
+	}
+	for _, out := range utxo {
+		cm.MachineEndian.PutUint32(temp, uint32(out.outIndex))
+		stream = append(stream, temp[:4]...)
+		cm.MachineEndian.PutUint64(temp, out.Value)
+		stream = append(stream, temp...)
+		cm.MachineEndian.PutUint32(temp, uint32(len(out.LockScript)))
+		stream = append(stream, temp[:4]...)
+		stream = append(stream, out.LockScript...)
+	}
+	return stream
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+// ShortIDLen is the length in bytes of the short IDs of the transactions in a compact block
+const ShortIDLen = 6
+
+// ErrInvalidCompactBlock is the error returned when the transactions do not rebuild the block of a compact block
+var ErrInvalidCompactBlock = errors.New("invalid compact block")
+
+// CompactBlock defines the struct of compact block, which relays a new block with the short IDs of its transactions
+// in place of the transactions, the peers rebuild the block from the transactions already in their tx pools
+// The coinbase is never in a tx pool, hence it is prefilled along with the actions.
+type CompactBlock struct {
+	Header *BlockHeader
+	// ShortIDs are the short IDs of the transactions following the prefilled ones, in the order of the block
+	ShortIDs   [][]byte
+	Prefilled  []*Tx
+	Transfers  []*Transfer
+	Executions []*Execution
+	Votes      []*Vote
+	Evidences  []*Evidence
+	Deposits   []*Deposit
+	Withdraws  []*Withdraw
+	// OtherActions are the actions of the kinds registered with RegisterAction
+	OtherActions []Action
+}
+
+// NewCompactBlock returns the compact block of the block, prefilled with its coinbase
+func NewCompactBlock(blk *Block) *CompactBlock {
+	cb := &CompactBlock{
+		Header:       blk.Header,
+		Transfers:    blk.Transfers,
+		Executions:   blk.Executions,
+		Votes:        blk.Votes,
+		Evidences:    blk.Evidences,
+		Deposits:     blk.Deposits,
+		Withdraws:    blk.Withdraws,
+		OtherActions: blk.OtherActions,
+	}
+	hash := blk.HashBlock()
+	for i, tx := range blk.Tranxs {
+		if i == 0 && tx.IsCoinbase() {
+			cb.Prefilled = append(cb.Prefilled, tx)
+			continue
+		}
+		cb.ShortIDs = append(cb.ShortIDs, ShortTxID(hash, tx.Hash()))
+	}
+	return cb
+}
+
+// ShortTxID returns the short ID of the transaction in the block, salted with the block hash so that colliding
+// short IDs cannot be crafted ahead of the block
+func ShortTxID(blkHash cp.Hash32B, txHash cp.Hash32B) []byte {
+	hash := blake2b.Sum256(append(blkHash[:], txHash[:]...))
+	return hash[:ShortIDLen]
+}
+
+// HashBlock returns the hash of the block relayed by the compact block
+func (cb *CompactBlock) HashBlock() cp.Hash32B {
+	return (&Block{Header: cb.Header}).HashBlock()
+}
+
+// Height returns the height of the block relayed by the compact block
+func (cb *CompactBlock) Height() uint32 {
+	return cb.Header.height
+}
+
+// FillTxs returns the transactions of the block found among the given ones, along with the indexes of the missing
+// ones in the block, which are left nil
+// A transaction whose short ID collides with another one's is treated as missing.
+func (cb *CompactBlock) FillTxs(txs []*Tx) ([]*Tx, []uint32) {
+	hash := cb.HashBlock()
+	found := make(map[string]*Tx)
+	collided := make(map[string]bool)
+	for _, tx := range txs {
+		id := string(ShortTxID(hash, tx.Hash()))
+		if _, ok := found[id]; ok {
+			collided[id] = true
+		}
+		found[id] = tx
+	}
+
+	tranxs := make([]*Tx, len(cb.Prefilled)+len(cb.ShortIDs))
+	copy(tranxs, cb.Prefilled)
+	var missing []uint32
+	for i, id := range cb.ShortIDs {
+		index := len(cb.Prefilled) + i
+		if tx, ok := found[string(id)]; ok && !collided[string(id)] {
+			tranxs[index] = tx
+			continue
+		}
+		missing = append(missing, uint32(index))
+	}
+	return tranxs, missing
+}
+
+// Block returns the block relayed by the compact block with the given transactions, which must match the merkle
+// root in the header
+func (cb *CompactBlock) Block(tranxs []*Tx) (*Block, error) {
+	if len(tranxs) != len(cb.Prefilled)+len(cb.ShortIDs) {
+		return nil, errors.Wrapf(ErrInvalidCompactBlock, "%d transactions in place of %d", len(tranxs), len(cb.Prefilled)+len(cb.ShortIDs))
+	}
+	for i, tx := range tranxs {
+		if tx == nil {
+			return nil, errors.Wrapf(ErrInvalidCompactBlock, "transaction %d is missing", i)
+		}
+	}
+	blk := &Block{
+		Header:       cb.Header,
+		Tranxs:       tranxs,
+		Transfers:    cb.Transfers,
+		Executions:   cb.Executions,
+		Votes:        cb.Votes,
+		Evidences:    cb.Evidences,
+		Deposits:     cb.Deposits,
+		Withdraws:    cb.Withdraws,
+		OtherActions: cb.OtherActions,
+	}
+	if blk.MerkleRoot() != cb.Header.merkleRoot {
+		return nil, errors.Wrap(ErrInvalidCompactBlock, "merkle root does not match")
+	}
+	return blk, nil
+}
+
+// ConvertToCompactBlockPb converts CompactBlock to CompactBlockPb
+func (cb *CompactBlock) ConvertToCompactBlockPb() *iproto.CompactBlockPb {
+	blk := &Block{
+		Header:       cb.Header,
+		Tranxs:       cb.Prefilled,
+		Transfers:    cb.Transfers,
+		Executions:   cb.Executions,
+		Votes:        cb.Votes,
+		Evidences:    cb.Evidences,
+		Deposits:     cb.Deposits,
+		Withdraws:    cb.Withdraws,
+		OtherActions: cb.OtherActions,
+	}
+	blkPb := blk.ConvertToBlockPb()
+	return &iproto.CompactBlockPb{
+		Header:     blkPb.Header,
+		ShortIDs:   cb.ShortIDs,
+		Prefilled:  blkPb.Transactions,
+		Transfers:  blkPb.Transfers,
+		Executions: blkPb.Executions,
+		Votes:      blkPb.Votes,
+		Evidences:  blkPb.Evidences,
+		Deposits:   blkPb.Deposits,
+		Withdraws:  blkPb.Withdraws,
+		Actions:    blkPb.Actions,
+	}
+}
+
+// ConvertFromCompactBlockPb converts CompactBlockPb to CompactBlock
+func (cb *CompactBlock) ConvertFromCompactBlockPb(pbBlock *iproto.CompactBlockPb) {
+	blk := &Block{}
+	blk.ConvertFromBlockPb(&iproto.BlockPb{
+		Header:       pbBlock.Header,
+		Transactions: pbBlock.Prefilled,
+		Transfers:    pbBlock.Transfers,
+		Executions:   pbBlock.Executions,
+		Votes:        pbBlock.Votes,
+		Evidences:    pbBlock.Evidences,
+		Deposits:     pbBlock.Deposits,
+		Withdraws:    pbBlock.Withdraws,
+		Actions:      pbBlock.Actions,
+	})
+	cb.Header = blk.Header
+	cb.ShortIDs = pbBlock.ShortIDs
+	cb.Prefilled = blk.Tranxs
+	cb.Transfers = blk.Transfers
+	cb.Executions = blk.Executions
+	cb.Votes = blk.Votes
+	cb.Evidences = blk.Evidences
+	cb.Deposits = blk.Deposits
+	cb.Withdraws = blk.Withdraws
+	cb.OtherActions = blk.OtherActions
+}
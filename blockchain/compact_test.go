@@ -0,0 +1,72 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestCompactBlock(t *testing.T) {
+	require := require.New(t)
+
+	amount := uint64(50 << 22)
+	cbtx := NewCoinbaseTx(ta.Addrinfo["miner"].Address, amount, GenesisCoinbaseData)
+	tx1 := NewCoinbaseTx(ta.Addrinfo["alfa"].Address, amount, GenesisCoinbaseData)
+	tx2 := NewCoinbaseTx(ta.Addrinfo["bravo"].Address, amount, GenesisCoinbaseData)
+	tx3 := NewCoinbaseTx(ta.Addrinfo["charlie"].Address, amount, GenesisCoinbaseData)
+	blk := NewBlock(1, 3, cp.ZeroHash32B, []*Tx{cbtx, tx1, tx2, tx3})
+	hash := blk.HashBlock()
+
+	cb := NewCompactBlock(blk)
+	require.Equal(1, len(cb.Prefilled))
+	require.Equal(3, len(cb.ShortIDs))
+	require.Equal(ShortTxID(hash, tx2.Hash()), cb.ShortIDs[1])
+	require.Equal(ShortIDLen, len(cb.ShortIDs[1]))
+
+	// compact block round trips through the wire format
+	buf, err := proto.Marshal(cb.ConvertToCompactBlockPb())
+	require.Nil(err)
+	cbPb := &iproto.CompactBlockPb{}
+	require.Nil(proto.Unmarshal(buf, cbPb))
+	cb = &CompactBlock{}
+	cb.ConvertFromCompactBlockPb(cbPb)
+	require.Equal(hash, cb.HashBlock())
+	require.Equal(uint32(3), cb.Height())
+
+	// tx2 is missing from the tx pool
+	tranxs, missing := cb.FillTxs([]*Tx{tx3, tx1})
+	require.Equal([]uint32{2}, missing)
+	require.Nil(tranxs[2])
+	_, err = cb.Block(tranxs)
+	require.Equal(ErrInvalidCompactBlock, errors.Cause(err))
+
+	// a transaction not in the block does not match the merkle root
+	tranxs[2] = NewCoinbaseTx(ta.Addrinfo["echo"].Address, amount, GenesisCoinbaseData)
+	_, err = cb.Block(tranxs)
+	require.Equal(ErrInvalidCompactBlock, errors.Cause(err))
+
+	tranxs[2] = tx2
+	rebuilt, err := cb.Block(tranxs)
+	require.Nil(err)
+	require.Equal(hash, rebuilt.HashBlock())
+	require.Equal(blk.MerkleRoot(), rebuilt.MerkleRoot())
+
+	// all transactions are in the tx pool
+	tranxs, missing = cb.FillTxs([]*Tx{tx1, tx2, tx3})
+	require.Nil(missing)
+	rebuilt, err = cb.Block(tranxs)
+	require.Nil(err)
+	require.Equal(hash, rebuilt.HashBlock())
+}
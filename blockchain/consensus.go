@@ -0,0 +1,24 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+// Consensus is the interface of a consensus engine that decides whether a block can be accepted on top of the
+// structural checks done by the blockchain
+type Consensus interface {
+	// ValidateHeader checks the consensus related fields of the block header against its parent
+	ValidateHeader(blk *Block, parent *Block) error
+	// VerifyProposer checks the block is signed by a proposer entitled to produce it
+	VerifyProposer(blk *Block) error
+	// FinalizeBlock completes a newly minted block, e.g. signing it, before it is proposed
+	FinalizeBlock(blk *Block) error
+}
+
+// SetConsensus sets the consensus engine consulted when validating and minting blocks
+// Blocks are only structurally validated if no engine is set
+func (bc *Blockchain) SetConsensus(c Consensus) {
+	bc.consensus = c
+}
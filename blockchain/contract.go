@@ -0,0 +1,306 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	"github.com/iotexproject/iotex-core/contract"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/state"
+)
+
+// contractStorage buffers the writes of an invocation to the storage of the contract, which are only written to the
+// working set once the invocation succeeds
+type contractStorage struct {
+	ws     *state.WorkingSet
+	addr   string
+	writes map[string][]byte
+}
+
+func newContractStorage(ws *state.WorkingSet, addr string) *contractStorage {
+	return &contractStorage{ws: ws, addr: addr, writes: make(map[string][]byte)}
+}
+
+// Get returns the value of the key, or nil if it is not set
+func (s *contractStorage) Get(key []byte) []byte {
+	if value, ok := s.writes[string(key)]; ok {
+		if len(value) == 0 {
+			return nil
+		}
+		return value
+	}
+	return s.ws.State(s.addr, key)
+}
+
+// Put sets the value of the key, an empty value deletes the key
+func (s *contractStorage) Put(key []byte, value []byte) {
+	s.writes[string(key)] = append([]byte{}, value...)
+}
+
+// flush writes the buffered writes to the working set
+func (s *contractStorage) flush() {
+	for key, value := range s.writes {
+		s.ws.SetState(s.addr, []byte(key), value)
+	}
+}
+
+// runExecution runs the verified execution on top of the working set and returns its receipt
+// An execution with the wrong nonce invalidates the block, whereas a failed execution still consumes the nonce and
+// its gas, but its changes to the contracts are discarded.
+func runExecution(ws *state.WorkingSet, exec *Execution) (*Receipt, error) {
+	if err := ws.UseNonce(exec.Executor, exec.Nonce); err != nil {
+		return nil, err
+	}
+	receipt := &Receipt{Hash: exec.Hash(), Status: ReceiptFailure, GasUsed: exec.GasLimit, ContractAddress: exec.Contract}
+	fail := func(err error) (*Receipt, error) {
+		receipt.ReturnValue = []byte(err.Error())
+		return receipt, nil
+	}
+
+	if exec.IsDeployment() {
+		addr, err := iotxaddress.CreateContractAddress(exec.Executor, exec.Nonce)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidExecution, "Executor %s: %v", exec.Executor, err)
+		}
+		receipt.ContractAddress = addr
+		gas := contract.ExecutionGas + uint64(len(exec.Code))*contract.CodeGasPerByte
+		if gas > exec.GasLimit {
+			return fail(contract.ErrOutOfGas)
+		}
+		if err := contract.Validate(exec.Code); err != nil {
+			return fail(err)
+		}
+		if err := ws.Deploy(addr, exec.Code); err != nil {
+			return fail(err)
+		}
+		receipt.Status, receipt.GasUsed = ReceiptSuccess, gas
+		return receipt, nil
+	}
+
+	if exec.GasLimit < contract.ExecutionGas {
+		return fail(contract.ErrOutOfGas)
+	}
+	code := ws.Code(exec.Contract)
+	if code == nil {
+		receipt.GasUsed = contract.ExecutionGas
+		return fail(errors.Errorf("Contract %s is not deployed", exec.Contract))
+	}
+	storage := newContractStorage(ws, exec.Contract)
+	ctx := &contract.Context{Caller: exec.Executor, Contract: exec.Contract, Args: exec.Args}
+	vm := contract.NewVM(ctx, code, storage, exec.GasLimit-contract.ExecutionGas)
+	ret, err := vm.Execute()
+	receipt.GasUsed = contract.ExecutionGas + vm.GasUsed()
+	if err != nil {
+		// a reverting contract returns the reason
+		if err == contract.ErrReverted && ret != nil {
+			receipt.ReturnValue = ret
+			return receipt, nil
+		}
+		return fail(err)
+	}
+	storage.flush()
+	receipt.Status, receipt.ReturnValue = ReceiptSuccess, ret
+	for i, l := range vm.Logs() {
+		receipt.Logs = append(receipt.Logs, &Log{
+			Address: l.Address,
+			Topics:  l.Topics,
+			Data:    l.Data,
+			Hash:    receipt.Hash,
+			Index:   uint32(i),
+		})
+	}
+	return receipt, nil
+}
+
+// putContracts adds the contracts deployed and the storage written by the working set to the batch
+func putContracts(batch *blockdb.Batch, ws *state.WorkingSet) {
+	for addr, code := range ws.CodeChanges() {
+		batch.PutCode([]byte(addr), code)
+	}
+	for addr, storage := range ws.StateChanges() {
+		for key, value := range storage {
+			if len(value) == 0 {
+				batch.DeleteState([]byte(addr), []byte(key))
+				continue
+			}
+			batch.PutState([]byte(addr), []byte(key), value)
+		}
+	}
+}
+
+// putReceipts adds the receipts of the block at the height and the bloom filter of their logs to the batch
+func putReceipts(batch *blockdb.Batch, height uint32, receipts []*Receipt) error {
+	for _, receipt := range receipts {
+		buf, err := receipt.Serialize()
+		if err != nil {
+			return err
+		}
+		batch.PutReceipt(receipt.Hash[:], buf)
+	}
+	bloom := logsBloom(receipts)
+	batch.PutBloom(height, bloom[:])
+	return nil
+}
+
+// loadContracts loads the code and storage of the contracts persisted along with the UTXO
+func (bc *Blockchain) loadContracts() error {
+	codes, err := bc.blockDb.Codes()
+	if err != nil {
+		return err
+	}
+	states, err := bc.blockDb.States()
+	if err != nil {
+		return err
+	}
+	for addr, code := range codes {
+		bc.sf.LoadCode(addr, code)
+	}
+	for addr, storage := range states {
+		for key, value := range storage {
+			bc.sf.LoadState(addr, []byte(key), value)
+		}
+	}
+	return nil
+}
+
+// GetReceipt returns the receipt of the execution in a committed block
+func (bc *Blockchain) GetReceipt(hash cp.Hash32B) (*Receipt, error) {
+	buf, err := bc.blockDb.GetReceipt(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	receipt := &Receipt{}
+	if err := receipt.Deserialize(buf); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// LogFilter selects the logs emitted by any of the contracts and carrying all of the topics
+type LogFilter struct {
+	// Addresses are the addresses of the contracts, the logs of any contract are selected if it is empty
+	Addresses []string
+	Topics    [][]byte
+}
+
+// mayMatch returns false if the block of the bloom filter surely has no log selected by the filter
+func (f *LogFilter) mayMatch(bloom *Bloom) bool {
+	if *bloom == (Bloom{}) {
+		return false
+	}
+	for _, topic := range f.Topics {
+		if !bloom.Test(topic) {
+			return false
+		}
+	}
+	if len(f.Addresses) == 0 {
+		return true
+	}
+	for _, addr := range f.Addresses {
+		if bloom.Test([]byte(addr)) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns true if the log is selected by the filter
+func (f *LogFilter) match(l *Log) bool {
+	if len(f.Addresses) > 0 {
+		found := false
+		for _, addr := range f.Addresses {
+			if addr == l.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, topic := range f.Topics {
+		found := false
+		for _, t := range l.Topics {
+			if string(t) == string(topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// GetLogs returns the logs selected by the filter emitted in the blocks with height in [start, end], in the order
+// they were emitted
+// Only the receipts of the blocks whose bloom filter may match the filter are read, and the query is aborted with
+// the error of ctx once it is done.
+func (bc *Blockchain) GetLogs(ctx context.Context, start uint32, end uint32, filter *LogFilter) ([]*Log, error) {
+	if start > end || end > bc.height {
+		return nil, errors.Errorf("Invalid block range [%d, %d], tip height is %d", start, end, bc.height)
+	}
+	var logs []*Log
+	for height := start; ; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// blocks committed without a bloom filter are always read
+		buf, err := bc.blockDb.GetBloom(height)
+		if err != nil && errors.Cause(err) != blockdb.ErrNotExist {
+			return nil, err
+		}
+		bloom := &Bloom{}
+		copy(bloom[:], buf)
+		if err != nil || filter.mayMatch(bloom) {
+			matched, err := bc.blockLogs(height, filter)
+			if err != nil {
+				return nil, err
+			}
+			logs = append(logs, matched...)
+		}
+		if height == end {
+			return logs, nil
+		}
+	}
+}
+
+// blockLogs returns the logs selected by the filter in the receipts of the block at the height
+func (bc *Blockchain) blockLogs(height uint32, filter *LogFilter) ([]*Log, error) {
+	blk, err := bc.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	var logs []*Log
+	for _, exec := range blk.Executions {
+		receipt, err := bc.GetReceipt(exec.Hash())
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range receipt.Logs {
+			if filter.match(l) {
+				logs = append(logs, l)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// ContractCode returns the code of the contract, or nil if no contract is deployed to the address
+func (bc *Blockchain) ContractCode(address string) []byte {
+	return bc.sf.Code(address)
+}
+
+// ContractState returns the value of the key in the storage of the contract, or nil if the key is not set
+func (bc *Blockchain) ContractState(address string, key []byte) []byte {
+	return bc.sf.State(address, key)
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+// ErrInvalidDeposit is the error returned when a deposit is not signed by its sender or does not leave its chain
+var ErrInvalidDeposit = errors.New("invalid deposit")
+
+// Deposit moves balance out of the account-based state of the source chain, the amount is credited to the recipient
+// on the destination chain by the withdraw of the deposit once the block committing it is confirmed
+// It is ordered by the nonce of the sender like a transfer. The chain IDs are signed along, so the deposit cannot be
+// replayed on another chain.
+type Deposit struct {
+	Version    uint32
+	Nonce      uint64
+	Amount     uint64
+	Sender     string
+	SrcChainID uint32
+	DstChainID uint32
+	// Recipient is the address on the destination chain
+	Recipient string
+	// SenderPubKey is the public key of the sender, which signs the hash of the deposit into Signature
+	SenderPubKey []byte
+	Signature    []byte
+}
+
+// NewDeposit returns an unsigned deposit of the amount from the sender on the source chain to the recipient on the
+// destination chain
+func NewDeposit(nonce uint64, amount uint64, sender string, srcChainID uint32, dstChainID uint32, recipient string) *Deposit {
+	return &Deposit{
+		Version:    1,
+		Nonce:      nonce,
+		Amount:     amount,
+		Sender:     sender,
+		SrcChainID: srcChainID,
+		DstChainID: dstChainID,
+		Recipient:  recipient,
+	}
+}
+
+// ByteStream returns a raw byte stream of the deposit without the signature
+func (d *Deposit) ByteStream() []byte {
+	stream := make([]byte, 4)
+	cm.MachineEndian.PutUint32(stream, d.Version)
+
+	temp := make([]byte, 8)
+	cm.MachineEndian.PutUint64(temp, d.Nonce)
+	stream = append(stream, temp...)
+	cm.MachineEndian.PutUint64(temp, d.Amount)
+	stream = append(stream, temp...)
+	stream = append(stream, d.Sender...)
+	cm.MachineEndian.PutUint32(temp, d.SrcChainID)
+	stream = append(stream, temp[:4]...)
+	cm.MachineEndian.PutUint32(temp, d.DstChainID)
+	stream = append(stream, temp[:4]...)
+	stream = append(stream, d.Recipient...)
+	stream = append(stream, d.SenderPubKey...)
+	return stream
+}
+
+// Hash returns the hash of the deposit, which is not changed by signing it
+func (d *Deposit) Hash() cp.Hash32B {
+	hash := blake2b.Sum256(d.ByteStream())
+	return blake2b.Sum256(hash[:])
+}
+
+// Sign signs the deposit with the handle of its sender
+func (d *Deposit) Sign(signer wallet.Signer) error {
+	if signer.Address() != d.Sender {
+		return errors.Wrapf(ErrSigningFailed, "Signer %s is not the sender %s", signer.Address(), d.Sender)
+	}
+	d.SenderPubKey = signer.PublicKey()
+	hash := d.Hash()
+	sig, err := signer.Sign(hash[:])
+	if err != nil {
+		return errors.Wrapf(ErrSigningFailed, "%v", err)
+	}
+	d.Signature = sig
+	return nil
+}
+
+// Verify checks the deposit moves the amount to another chain and is signed by the key of its sender's address
+func (d *Deposit) Verify() error {
+	if d.SrcChainID == d.DstChainID {
+		return errors.Wrapf(ErrInvalidDeposit, "Deposit does not leave chain %d", d.SrcChainID)
+	}
+	if !iotxaddress.ValidateAddress(d.Recipient) {
+		return errors.Wrapf(ErrInvalidDeposit, "Invalid recipient %s", d.Recipient)
+	}
+	if len(d.SenderPubKey) != ed25519.PublicKeySize || len(d.Signature) != ed25519.SignatureSize {
+		return errors.Wrap(ErrInvalidDeposit, "Deposit is not signed")
+	}
+	pkHash := iotxaddress.GetPubkeyHash(d.Sender)
+	if pkHash == nil || !bytes.Equal(pkHash, iotxaddress.HashPubKey(d.SenderPubKey)) {
+		return errors.Wrapf(ErrInvalidDeposit, "Public key does not match sender %s", d.Sender)
+	}
+	hash := d.Hash()
+	if !cp.Verify(d.SenderPubKey, hash[:], d.Signature) {
+		return errors.Wrapf(ErrInvalidDeposit, "Wrong signature of deposit %x", hash)
+	}
+	return nil
+}
+
+// Kind returns the kind of the deposit action
+func (d *Deposit) Kind() string {
+	return DepositKind
+}
+
+// SenderNonce returns the sender of the deposit and its nonce
+func (d *Deposit) SenderNonce() (string, uint64) {
+	return d.Sender, d.Nonce
+}
+
+// Execute debits the amount from the sender, consuming its nonce, the deposit has to be made from the chain
+func (d *Deposit) Execute(ctx *ActionContext) error {
+	if d.SrcChainID != ctx.ChainID {
+		return errors.Wrapf(ErrInvalidDeposit, "Deposit from chain %d on chain %d", d.SrcChainID, ctx.ChainID)
+	}
+	return ctx.WS.Debit(d.Sender, d.Amount, d.Nonce)
+}
+
+// ConvertToDepositPb creates a protobuf's Deposit using type Deposit
+func (d *Deposit) ConvertToDepositPb() *iproto.DepositPb {
+	return &iproto.DepositPb{
+		Version:      d.Version,
+		Nonce:        d.Nonce,
+		Amount:       d.Amount,
+		Sender:       d.Sender,
+		SrcChainID:   d.SrcChainID,
+		DstChainID:   d.DstChainID,
+		Recipient:    d.Recipient,
+		SenderPubKey: d.SenderPubKey,
+		Signature:    d.Signature,
+	}
+}
+
+// ConvertFromDepositPb converts a protobuf's Deposit back to type Deposit
+func (d *Deposit) ConvertFromDepositPb(pbDeposit *iproto.DepositPb) {
+	d.Version = pbDeposit.GetVersion()
+	d.Nonce = pbDeposit.GetNonce()
+	d.Amount = pbDeposit.GetAmount()
+	d.Sender = pbDeposit.GetSender()
+	d.SrcChainID = pbDeposit.GetSrcChainID()
+	d.DstChainID = pbDeposit.GetDstChainID()
+	d.Recipient = pbDeposit.GetRecipient()
+	d.SenderPubKey = pbDeposit.GetSenderPubKey()
+	d.Signature = pbDeposit.GetSignature()
+}
+
+// Serialize returns a serialized byte stream for the Deposit
+func (d *Deposit) Serialize() ([]byte, error) {
+	return proto.Marshal(d.ConvertToDepositPb())
+}
+
+// Deserialize parses the byte stream into the Deposit
+func (d *Deposit) Deserialize(buf []byte) error {
+	pbDeposit := iproto.DepositPb{}
+	if err := proto.Unmarshal(buf, &pbDeposit); err != nil {
+		return err
+	}
+	d.ConvertFromDepositPb(&pbDeposit)
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	"github.com/iotexproject/iotex-core/election"
+	"github.com/iotexproject/iotex-core/state"
+)
+
+// EpochOf returns the epoch of the block at the height, see EpochManager
+func (bc *Blockchain) EpochOf(height uint32) uint32 {
+	return bc.epochs.EpochOf(height)
+}
+
+// GetCandidates returns the candidates with their votes in the current account states, the most voted first
+func (bc *Blockchain) GetCandidates() []*election.Candidate {
+	return election.Tally(bc.sf.Accounts())
+}
+
+// GetDelegatesByEpoch returns the delegates elected for the epoch, or nil if no delegate has been elected for it
+func (bc *Blockchain) GetDelegatesByEpoch(epoch uint32) ([]*election.Candidate, error) {
+	buf, err := bc.blockDb.GetDelegates(epoch)
+	if err != nil {
+		if errors.Cause(err) == blockdb.ErrNotExist {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return election.Deserialize(buf)
+}
+
+// putDelegates adds to the batch the delegates of the next epoch if the block at the height is the last of an epoch,
+// they are elected from the account states changed by the working set
+// No delegate is elected for epoch 0, since there is no vote before the genesis block.
+func (bc *Blockchain) putDelegates(batch *blockdb.Batch, height uint32, ws *state.WorkingSet) error {
+	if !bc.epochs.IsLastOfEpoch(height) {
+		return nil
+	}
+	delegates := election.Elect(election.Tally(ws.Accounts()), int(bc.config.Chain.NumDelegates))
+	buf, err := election.Serialize(delegates)
+	if err != nil {
+		return err
+	}
+	batch.PutDelegates(bc.EpochOf(height+1), buf)
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/iotexproject/iotex-core/config"
+)
+
+// EpochManager divides the chain into epochs of EpochLength blocks, and computes the block reward of each epoch from
+// the emission schedule of the config
+//
+// The reward of the blocks of epoch 0 is BlockReward. It halves every RewardHalvingEpochs epochs, and decreases by
+// RewardDecayPercent from an epoch to the next, rounding down, until it reaches 0. DelegateRewardPercent of the reward
+// of a block is shared equally by the delegates of its epoch.
+type EpochManager struct {
+	length          uint32
+	reward          uint64
+	halvingEpochs   uint32
+	decayPercent    uint32
+	delegatePercent uint32
+}
+
+// NewEpochManager creates an epoch manager with the epoch length and the emission schedule of the config
+func NewEpochManager(cfg *config.Chain) *EpochManager {
+	return &EpochManager{
+		length:          cfg.EpochLength,
+		reward:          cfg.BlockReward,
+		halvingEpochs:   cfg.RewardHalvingEpochs,
+		decayPercent:    cfg.RewardDecayPercent,
+		delegatePercent: cfg.DelegateRewardPercent,
+	}
+}
+
+// EpochOf returns the epoch of the block at the height, the genesis block and the blocks of the first epoch are in
+// epoch 0, and all blocks are in epoch 0 if the epoch length is 0
+func (em *EpochManager) EpochOf(height uint32) uint32 {
+	if em.length == 0 || height == 0 {
+		return 0
+	}
+	return (height - 1) / em.length
+}
+
+// IsLastOfEpoch returns true if the block at the height is the last block of its epoch, it is never the case for
+// the genesis block or if the epoch length is 0
+func (em *EpochManager) IsLastOfEpoch(height uint32) bool {
+	return em.length > 0 && height > 0 && height%em.length == 0
+}
+
+// EpochReward returns the reward of each block of the epoch
+func (em *EpochManager) EpochReward(epoch uint32) uint64 {
+	reward := em.reward
+	if em.halvingEpochs > 0 {
+		halvings := epoch / em.halvingEpochs
+		if halvings >= 64 {
+			return 0
+		}
+		reward >>= halvings
+	}
+	if em.decayPercent == 0 {
+		return reward
+	}
+	for i := uint32(0); i < epoch; i++ {
+		decay := percentOf(reward, em.decayPercent)
+		if decay == 0 {
+			// the reward is too small to decrease any further
+			break
+		}
+		reward -= decay
+	}
+	return reward
+}
+
+// DelegateRewards returns the shares of the delegates in the block reward, or nil if there is no delegate or their
+// shares round down to 0
+func (em *EpochManager) DelegateRewards(reward uint64, delegates []string) []*Payee {
+	if len(delegates) == 0 {
+		return nil
+	}
+	share := percentOf(reward, em.delegatePercent) / uint64(len(delegates))
+	if share == 0 {
+		return nil
+	}
+	payees := make([]*Payee, 0, len(delegates))
+	for _, addr := range delegates {
+		payees = append(payees, &Payee{Address: addr, Amount: share})
+	}
+	return payees
+}
+
+// percentOf returns percent% of the amount rounded down, without overflowing
+func percentOf(amount uint64, percent uint32) uint64 {
+	return amount/100*uint64(percent) + amount%100*uint64(percent)/100
+}
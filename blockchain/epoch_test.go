@@ -0,0 +1,52 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func TestEpochManager(t *testing.T) {
+	assert := assert.New(t)
+
+	em := NewEpochManager(&config.Chain{EpochLength: 10, BlockReward: 1000, RewardHalvingEpochs: 2})
+	assert.Equal(uint32(0), em.EpochOf(0))
+	assert.Equal(uint32(0), em.EpochOf(10))
+	assert.Equal(uint32(1), em.EpochOf(11))
+	assert.True(em.IsLastOfEpoch(20))
+	assert.False(em.IsLastOfEpoch(0))
+	assert.False(em.IsLastOfEpoch(21))
+	assert.Equal(uint64(1000), em.EpochReward(1))
+	assert.Equal(uint64(500), em.EpochReward(2))
+	assert.Equal(uint64(250), em.EpochReward(5))
+	assert.Equal(uint64(0), em.EpochReward(200))
+
+	// the reward decays on top of the halvings until it is too small to decrease
+	em = NewEpochManager(&config.Chain{EpochLength: 10, BlockReward: 1000, RewardHalvingEpochs: 2, RewardDecayPercent: 10})
+	assert.Equal(uint64(900), em.EpochReward(1))
+	assert.Equal(uint64(405), em.EpochReward(2))
+	em = NewEpochManager(&config.Chain{EpochLength: 10, BlockReward: 1000, RewardDecayPercent: 10})
+	assert.Equal(uint64(810), em.EpochReward(2))
+	assert.Equal(uint64(9), em.EpochReward(1000000))
+
+	// all blocks are in epoch 0 without an epoch length
+	em = NewEpochManager(&config.Chain{BlockReward: 1000, RewardHalvingEpochs: 1})
+	assert.Equal(uint32(0), em.EpochOf(100))
+	assert.False(em.IsLastOfEpoch(100))
+
+	em = NewEpochManager(&config.Chain{DelegateRewardPercent: 50})
+	assert.Nil(em.DelegateRewards(1000, nil))
+	assert.Nil(em.DelegateRewards(1, []string{"alfa"}))
+	assert.Equal([]*Payee{{Address: "alfa", Amount: 166}, {Address: "bravo", Amount: 166}, {Address: "charlie", Amount: 166}},
+		em.DelegateRewards(1000, []string{"alfa", "bravo", "charlie"}))
+	assert.Equal(uint64(13835058055282163711), percentOf(math.MaxUint64, 75))
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"sync"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+const (
+	// eventChanSize is the buffer size of each subscriber's channel
+	eventChanSize = 64
+)
+
+// EventType is the type of a block event
+type EventType int
+
+const (
+	// BlockCommitted is emitted when a block extending the current tip is committed
+	BlockCommitted EventType = iota
+	// ChainReorged is emitted when a committed block does not extend the previous tip
+	ChainReorged
+)
+
+// BlockEvent is emitted to subscribers whenever a block is committed into the blockchain
+type BlockEvent struct {
+	Type  EventType
+	Block *Block
+	// OldTip is the tip hash before the block was committed
+	OldTip cp.Hash32B
+}
+
+// eventHub fans out block events to its subscribers
+type eventHub struct {
+	mu   sync.RWMutex
+	subs map[chan *BlockEvent]struct{}
+	log  *logger.Logger
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[chan *BlockEvent]struct{}), log: log}
+}
+
+func (h *eventHub) subscribe() chan *BlockEvent {
+	ch := make(chan *BlockEvent, eventChanSize)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[ch] = struct{}{}
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch <-chan *BlockEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		if sub == ch {
+			delete(h.subs, sub)
+			close(sub)
+			return
+		}
+	}
+}
+
+// close closes the channels of all subscribers, which are then removed
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub)
+	}
+}
+
+// publish sends the event to all subscribers without blocking; a subscriber whose buffer is full misses the event
+func (h *eventHub) publish(evt *BlockEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs {
+		select {
+		case sub <- evt:
+		default:
+			h.log.WithField("height", evt.Block.Height()).Warning("Subscriber is too slow, dropping event")
+		}
+	}
+}
+
+// Subscribe returns a channel on which the block events are delivered
+func (bc *Blockchain) Subscribe() <-chan *BlockEvent {
+	return bc.events.subscribe()
+}
+
+// Unsubscribe stops delivering block events to the channel and closes it
+func (bc *Blockchain) Unsubscribe(ch <-chan *BlockEvent) {
+	bc.events.unsubscribe(ch)
+}
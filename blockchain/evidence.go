@@ -0,0 +1,139 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+// ErrInvalidEvidence is the error returned when an evidence does not prove the misbehavior of its offender
+var ErrInvalidEvidence = errors.New("invalid evidence")
+
+// Evidence proves the offender signed two different block headers at the same height of the chain, i.e., produced
+// conflicting blocks
+// The signed headers prove the misbehavior by themselves, hence the evidence is not signed by whoever reports it.
+// Committing the evidence burns the balance of the offender in the account-based state and revokes its candidacy.
+type Evidence struct {
+	Version  uint32
+	Offender string
+	// Header1 and Header2 are the blocks with only the conflicting headers
+	Header1 *Block
+	Header2 *Block
+}
+
+// NewEvidence returns the evidence of the offender signing the headers of both blocks
+func NewEvidence(offender string, blk1 *Block, blk2 *Block) *Evidence {
+	return &Evidence{
+		Version:  1,
+		Offender: offender,
+		Header1:  &Block{Header: blk1.Header},
+		Header2:  &Block{Header: blk2.Header},
+	}
+}
+
+// ByteStream returns a raw byte stream of the evidence
+// The hashes of the headers are in ascending order, so the evidence of the same blocks in either order is the same.
+func (e *Evidence) ByteStream() []byte {
+	stream := make([]byte, 4)
+	cm.MachineEndian.PutUint32(stream, e.Version)
+	stream = append(stream, e.Offender...)
+	hash1, hash2 := e.Header1.HashBlock(), e.Header2.HashBlock()
+	if bytes.Compare(hash1[:], hash2[:]) > 0 {
+		hash1, hash2 = hash2, hash1
+	}
+	stream = append(stream, hash1[:]...)
+	stream = append(stream, hash2[:]...)
+	return stream
+}
+
+// Hash returns the hash of the evidence
+func (e *Evidence) Hash() cp.Hash32B {
+	hash := blake2b.Sum256(e.ByteStream())
+	return blake2b.Sum256(hash[:])
+}
+
+// Verify checks both headers are at the same height of the same chain, are different and are signed by the key of
+// the offender's address
+func (e *Evidence) Verify() error {
+	if e.Header1 == nil || e.Header1.Header == nil || e.Header2 == nil || e.Header2.Header == nil {
+		return errors.Wrap(ErrInvalidEvidence, "Missing header")
+	}
+	h1, h2 := e.Header1.Header, e.Header2.Header
+	if h1.chainID != h2.chainID || h1.height != h2.height {
+		return errors.Wrapf(ErrInvalidEvidence, "Headers at %d/%d and %d/%d do not conflict",
+			h1.chainID, h1.height, h2.chainID, h2.height)
+	}
+	if e.Header1.HashBlock() == e.Header2.HashBlock() {
+		return errors.Wrapf(ErrInvalidEvidence, "Headers at height %d are the same", h1.height)
+	}
+	pkHash := iotxaddress.GetPubkeyHash(e.Offender)
+	for _, blk := range []*Block{e.Header1, e.Header2} {
+		if pkHash == nil || !bytes.Equal(pkHash, iotxaddress.HashPubKey(blk.ProposerPubKey())) {
+			return errors.Wrapf(ErrInvalidEvidence, "Header at height %d is not signed by %s", h1.height, e.Offender)
+		}
+		if !blk.VerifySignature() {
+			return errors.Wrapf(ErrInvalidEvidence, "Wrong signature of header %x", blk.HashBlock())
+		}
+	}
+	return nil
+}
+
+// Kind returns the kind of the evidence action
+func (e *Evidence) Kind() string {
+	return EvidenceKind
+}
+
+// Execute slashes the offender
+func (e *Evidence) Execute(ctx *ActionContext) error {
+	ctx.WS.Slash(e.Offender)
+	return nil
+}
+
+// ConvertToEvidencePb creates a protobuf's Evidence using type Evidence
+func (e *Evidence) ConvertToEvidencePb() *iproto.EvidencePb {
+	return &iproto.EvidencePb{
+		Version:  e.Version,
+		Offender: e.Offender,
+		Header1:  e.Header1.ConvertToBlockHeaderPb(),
+		Header2:  e.Header2.ConvertToBlockHeaderPb(),
+	}
+}
+
+// ConvertFromEvidencePb converts a protobuf's Evidence back to type Evidence, a missing header is converted to an
+// empty one which fails Verify
+func (e *Evidence) ConvertFromEvidencePb(pbEvidence *iproto.EvidencePb) {
+	e.Version = pbEvidence.GetVersion()
+	e.Offender = pbEvidence.GetOffender()
+	e.Header1 = &Block{}
+	e.Header1.ConvertFromBlockHeaderPb(&iproto.BlockPb{Header: pbEvidence.GetHeader1()})
+	e.Header2 = &Block{}
+	e.Header2.ConvertFromBlockHeaderPb(&iproto.BlockPb{Header: pbEvidence.GetHeader2()})
+}
+
+// Serialize returns a serialized byte stream for the Evidence
+func (e *Evidence) Serialize() ([]byte, error) {
+	return proto.Marshal(e.ConvertToEvidencePb())
+}
+
+// Deserialize parses the byte stream into the Evidence
+func (e *Evidence) Deserialize(buf []byte) error {
+	pbEvidence := iproto.EvidencePb{}
+	if err := proto.Unmarshal(buf, &pbEvidence); err != nil {
+		return err
+	}
+	e.ConvertFromEvidencePb(&pbEvidence)
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+// ErrInvalidExecution is the error returned when an execution is malformed or not signed by its executor
+var ErrInvalidExecution = errors.New("invalid execution")
+
+// Execution deploys the code as a new contract if Contract is empty, or invokes the contract with the arguments
+// otherwise, running at most GasLimit gas. It is ordered by the nonce of the executor, like a transfer.
+type Execution struct {
+	Version  uint32
+	Nonce    uint64
+	Executor string
+	Contract string
+	Code     []byte
+	Args     [][]byte
+	GasLimit uint64
+	// ExecutorPubKey is the public key of the executor, which signs the hash of the execution into Signature
+	ExecutorPubKey []byte
+	Signature      []byte
+}
+
+// NewDeployment returns an unsigned execution deploying the code as a contract owned by the executor
+func NewDeployment(nonce uint64, executor string, code []byte, gasLimit uint64) *Execution {
+	return &Execution{Version: 1, Nonce: nonce, Executor: executor, Code: code, GasLimit: gasLimit}
+}
+
+// NewInvocation returns an unsigned execution invoking the contract with the arguments
+func NewInvocation(nonce uint64, executor string, contract string, args [][]byte, gasLimit uint64) *Execution {
+	return &Execution{Version: 1, Nonce: nonce, Executor: executor, Contract: contract, Args: args, GasLimit: gasLimit}
+}
+
+// IsDeployment returns true if the execution deploys a contract rather than invoking one
+func (exec *Execution) IsDeployment() bool {
+	return exec.Contract == ""
+}
+
+// ByteStream returns a raw byte stream of the execution without the signature
+// Variable-length fields are preceded by their length so different executions never have the same stream.
+func (exec *Execution) ByteStream() []byte {
+	stream := make([]byte, 4)
+	cm.MachineEndian.PutUint32(stream, exec.Version)
+
+	temp := make([]byte, 8)
+	cm.MachineEndian.PutUint64(temp, exec.Nonce)
+	stream = append(stream, temp...)
+	cm.MachineEndian.PutUint64(temp, exec.GasLimit)
+	stream = append(stream, temp...)
+
+	size := make([]byte, 4)
+	appendBytes := func(b []byte) {
+		cm.MachineEndian.PutUint32(size, uint32(len(b)))
+		stream = append(append(stream, size...), b...)
+	}
+	appendBytes([]byte(exec.Executor))
+	appendBytes([]byte(exec.Contract))
+	appendBytes(exec.Code)
+	cm.MachineEndian.PutUint32(size, uint32(len(exec.Args)))
+	stream = append(stream, size...)
+	for _, arg := range exec.Args {
+		appendBytes(arg)
+	}
+	stream = append(stream, exec.ExecutorPubKey...)
+	return stream
+}
+
+// Hash returns the hash of the execution, which is not changed by signing it
+func (exec *Execution) Hash() cp.Hash32B {
+	hash := blake2b.Sum256(exec.ByteStream())
+	return blake2b.Sum256(hash[:])
+}
+
+// Sign signs the execution with the handle of its executor
+func (exec *Execution) Sign(signer wallet.Signer) error {
+	if signer.Address() != exec.Executor {
+		return errors.Wrapf(ErrSigningFailed, "Signer %s is not the executor %s", signer.Address(), exec.Executor)
+	}
+	exec.ExecutorPubKey = signer.PublicKey()
+	hash := exec.Hash()
+	sig, err := signer.Sign(hash[:])
+	if err != nil {
+		return errors.Wrapf(ErrSigningFailed, "%v", err)
+	}
+	exec.Signature = sig
+	return nil
+}
+
+// Verify checks the execution either deploys code or invokes a contract, and is signed by the key of its executor's
+// address
+func (exec *Execution) Verify() error {
+	if exec.IsDeployment() == (len(exec.Code) == 0) {
+		return errors.Wrap(ErrInvalidExecution, "Execution has to either deploy code or invoke a contract")
+	}
+	if !exec.IsDeployment() && !iotxaddress.IsContractAddress(exec.Contract) {
+		return errors.Wrapf(ErrInvalidExecution, "Invalid contract %s", exec.Contract)
+	}
+	if len(exec.ExecutorPubKey) != ed25519.PublicKeySize || len(exec.Signature) != ed25519.SignatureSize {
+		return errors.Wrap(ErrInvalidExecution, "Execution is not signed")
+	}
+	pkHash := iotxaddress.GetPubkeyHash(exec.Executor)
+	if pkHash == nil || !bytes.Equal(pkHash, iotxaddress.HashPubKey(exec.ExecutorPubKey)) {
+		return errors.Wrapf(ErrInvalidExecution, "Public key does not match executor %s", exec.Executor)
+	}
+	hash := exec.Hash()
+	if !cp.Verify(exec.ExecutorPubKey, hash[:], exec.Signature) {
+		return errors.Wrapf(ErrInvalidExecution, "Wrong signature of execution %x", hash)
+	}
+	return nil
+}
+
+// Kind returns the kind of the execution action, either deploying or invoking a contract
+func (exec *Execution) Kind() string {
+	if exec.IsDeployment() {
+		return ContractDeployKind
+	}
+	return ContractInvokeKind
+}
+
+// SenderNonce returns the executor of the execution and its nonce
+func (exec *Execution) SenderNonce() (string, uint64) {
+	return exec.Executor, exec.Nonce
+}
+
+// Execute runs the execution and records its receipt, a failed execution is not an error, see runExecution
+func (exec *Execution) Execute(ctx *ActionContext) error {
+	receipt, err := runExecution(ctx.WS, exec)
+	if err != nil {
+		return err
+	}
+	receipt.BlockHeight = ctx.Height
+	for _, l := range receipt.Logs {
+		l.BlockHeight = ctx.Height
+	}
+	ctx.Receipts = append(ctx.Receipts, receipt)
+	return nil
+}
+
+// ConvertToExecutionPb creates a protobuf's Execution using type Execution
+func (exec *Execution) ConvertToExecutionPb() *iproto.ExecutionPb {
+	return &iproto.ExecutionPb{
+		Version:        exec.Version,
+		Nonce:          exec.Nonce,
+		Executor:       exec.Executor,
+		Contract:       exec.Contract,
+		Code:           exec.Code,
+		Args:           exec.Args,
+		GasLimit:       exec.GasLimit,
+		ExecutorPubKey: exec.ExecutorPubKey,
+		Signature:      exec.Signature,
+	}
+}
+
+// ConvertFromExecutionPb converts a protobuf's Execution back to type Execution
+func (exec *Execution) ConvertFromExecutionPb(pbExec *iproto.ExecutionPb) {
+	exec.Version = pbExec.GetVersion()
+	exec.Nonce = pbExec.GetNonce()
+	exec.Executor = pbExec.GetExecutor()
+	exec.Contract = pbExec.GetContract()
+	exec.Code = pbExec.GetCode()
+	exec.Args = pbExec.GetArgs()
+	exec.GasLimit = pbExec.GetGasLimit()
+	exec.ExecutorPubKey = pbExec.GetExecutorPubKey()
+	exec.Signature = pbExec.GetSignature()
+}
+
+// Serialize returns a serialized byte stream for the Execution
+func (exec *Execution) Serialize() ([]byte, error) {
+	return proto.Marshal(exec.ConvertToExecutionPb())
+}
+
+// Deserialize parses the byte stream into the Execution
+func (exec *Execution) Deserialize(buf []byte) error {
+	pbExec := iproto.ExecutionPb{}
+	if err := proto.Unmarshal(buf, &pbExec); err != nil {
+		return err
+	}
+	exec.ConvertFromExecutionPb(&pbExec)
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// feeHistoryBlocks is the number of recent blocks the fee estimation is based on
+	feeHistoryBlocks = 100
+	// feeSuccessRate is the probability at which a transaction paying the estimated fee is mined within the target
+	feeSuccessRate = 0.95
+)
+
+// ErrInvalidFeeTarget indicates the confirmation target of the fee estimation is invalid
+var ErrInvalidFeeTarget = errors.New("invalid fee confirmation target")
+
+// FeeSample is the fee paid by a transaction of the size in bytes
+type FeeSample struct {
+	Fee  uint64
+	Size uint32
+}
+
+// Mempool is the pool of the transactions waiting to be mined, whose backlog the fee estimation accounts for
+type Mempool interface {
+	// PendingFees returns the fees paid by the transactions waiting in the pool
+	PendingFees() []FeeSample
+}
+
+// SetMempool sets the mempool whose backlog the fee estimation accounts for
+func (bc *Blockchain) SetMempool(mp Mempool) {
+	bc.mempool = mp
+}
+
+// EstimateFee returns the fee per byte a transaction should pay to be mined within 'targetBlocks' blocks
+// It is based on the lowest fee rates the recent blocks included and on the backlog of the mempool, and is at least the
+// minimum fee rate of the txpool.
+func (bc *Blockchain) EstimateFee(targetBlocks uint32) (uint64, error) {
+	if targetBlocks == 0 {
+		return 0, errors.Wrap(ErrInvalidFeeTarget, "Target should be at least 1 block")
+	}
+	rate := bc.fees.estimate(targetBlocks)
+	if backlog := bc.backlogFeeRate(targetBlocks); backlog > rate {
+		rate = backlog
+	}
+	if min := bc.config.TxPool.MinTxFeePerByte; min > rate {
+		rate = min
+	}
+	return rate, nil
+}
+
+// backlogFeeRate returns the fee per byte outbidding the backlog of the mempool which does not fit in 'target' blocks,
+// 0 if the whole backlog fits
+func (bc *Blockchain) backlogFeeRate(target uint32) uint64 {
+	if bc.mempool == nil {
+		return 0
+	}
+	samples := bc.mempool.PendingFees()
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Fee*uint64(samples[j].Size) > samples[j].Fee*uint64(samples[i].Size)
+	})
+	// the coinbase counts in the transactions of a block
+	maxTxs := 0
+	if max := bc.config.Chain.MaxBlockTxs; max > 0 {
+		maxTxs = int(max-1) * int(target)
+	}
+	maxSize := uint64(bc.config.Chain.MaxBlockSize) * uint64(target)
+	size := uint64(0)
+	for i, s := range samples {
+		size += uint64(s.Size)
+		if (maxTxs > 0 && i >= maxTxs) || (maxSize > 0 && size > maxSize) {
+			if s.Size == 0 {
+				return 0
+			}
+			return s.Fee/uint64(s.Size) + 1
+		}
+	}
+	return 0
+}
+
+// blockFeeRate returns the lowest fee per byte paid by the transactions of the block, or 0 if the block had room for
+// another transaction, since it would have included one paying any fee
+// It has to be called before the UTXO spent by the block are removed from the pool.
+func (bc *Blockchain) blockFeeRate(blk *Block) uint64 {
+	min, smallest := uint64(math.MaxUint64), math.MaxInt32
+	view := bc.Utk.NewView()
+	for _, tx := range blk.Tranxs {
+		if tx.IsCoinbase() {
+			continue
+		}
+		fee, err := view.TxFee(tx)
+		if err == nil {
+			err = view.AddTx(tx)
+		}
+		serialized, serr := tx.Serialize()
+		if err != nil || serr != nil || len(serialized) == 0 {
+			continue
+		}
+		if rate := fee / uint64(len(serialized)); rate < min {
+			min = rate
+		}
+		if len(serialized) < smallest {
+			smallest = len(serialized)
+		}
+	}
+	if min == math.MaxUint64 {
+		return 0
+	}
+	full := false
+	if max := bc.config.Chain.MaxBlockTxs; max > 0 && uint32(len(blk.Tranxs)) >= max {
+		full = true
+	}
+	if max := bc.config.Chain.MaxBlockSize; max > 0 && proto.Size(blk.ConvertToBlockPb())+smallest > int(max) {
+		full = true
+	}
+	if !full {
+		return 0
+	}
+	return min
+}
+
+// feeEstimator tracks the lowest fee rates included by the recent blocks
+type feeEstimator struct {
+	mutex sync.RWMutex
+	// rates are the fee rates of the recent blocks returned by blockFeeRate, the oldest first
+	rates []uint64
+}
+
+// add records the fee rate of a new block, forgetting the oldest block beyond the history
+func (fe *feeEstimator) add(rate uint64) {
+	fe.mutex.Lock()
+	defer fe.mutex.Unlock()
+	fe.rates = append(fe.rates, rate)
+	if len(fe.rates) > feeHistoryBlocks {
+		fe.rates = fe.rates[len(fe.rates)-feeHistoryBlocks:]
+	}
+}
+
+// drop forgets the fee rates of the n newest blocks, e.g., once they are rolled back
+func (fe *feeEstimator) drop(n int) {
+	fe.mutex.Lock()
+	defer fe.mutex.Unlock()
+	if n > len(fe.rates) {
+		n = len(fe.rates)
+	}
+	fe.rates = fe.rates[:len(fe.rates)-n]
+}
+
+// estimate returns the lowest fee rate which is mined within 'target' blocks at the success rate, 0 without history
+// A block includes a transaction paying the rate r with the probability p of the recent blocks having included r, so
+// the transaction is mined within 'target' blocks with the probability 1-(1-p)^target.
+func (fe *feeEstimator) estimate(target uint32) uint64 {
+	fe.mutex.RLock()
+	rates := append([]uint64{}, fe.rates...)
+	fe.mutex.RUnlock()
+	if len(rates) == 0 {
+		return 0
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i] < rates[j] })
+	p := 1 - math.Pow(1-feeSuccessRate, 1/float64(target))
+	k := int(math.Ceil(p * float64(len(rates))))
+	if k < 1 {
+		k = 1
+	}
+	if k > len(rates) {
+		k = len(rates)
+	}
+	return rates[k-1]
+}
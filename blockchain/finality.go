@@ -0,0 +1,59 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+// ErrFinalized is the error returned when removing or replacing a block which has been finalized
+var ErrFinalized = errors.New("block has been finalized")
+
+// Finalize marks the block with the hash at the height final, so the chain can no longer be reorganized or rolled
+// back below it
+// Finalizing a height at or below the finalized one is a no-op.
+func (bc *Blockchain) Finalize(height uint32, hash cp.Hash32B) error {
+	if bc.blockDb.IsReadOnly() {
+		return blockdb.ErrReadOnly
+	}
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	if bc.stopped {
+		return errors.Wrap(ErrStopped, "Cannot finalize")
+	}
+	if height <= bc.finalHeight {
+		return nil
+	}
+	if height > bc.height {
+		return errors.Errorf("Cannot finalize height %d above the tip %d", height, bc.height)
+	}
+	indexed, err := bc.GetHashByHeight(height)
+	if err != nil {
+		return err
+	}
+	if indexed != hash {
+		return errors.Errorf("Cannot finalize block %x, which is not the block %x at height %d", hash, indexed, height)
+	}
+
+	batch := blockdb.NewBatch()
+	batch.PutFinalHeight(height)
+	if err := bc.blockDb.Commit(batch); err != nil {
+		return err
+	}
+	bc.finalHeight = height
+	finalHeightGauge.Set(int64(height))
+	bc.log.WithField("height", height).Info("Finalized the chain")
+	return nil
+}
+
+// FinalizedHeight returns the height of the highest block finalized, 0 if no block is finalized
+func (bc *Blockchain) FinalizedHeight() uint32 {
+	return bc.finalHeight
+}
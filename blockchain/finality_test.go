@@ -0,0 +1,64 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/config"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestFinalize(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	mint := func() *Block {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		return blk
+	}
+	blk1, fork1 := mint(), mint()
+	assert.Nil(bc.AddBlockCommit(blk1))
+	blk2 := mint()
+	assert.Nil(bc.AddBlockCommit(blk2))
+	assert.Nil(bc.AddBlockCommit(mint()))
+	assert.Equal(uint32(0), bc.FinalizedHeight())
+
+	// only a block on the chain can be finalized
+	assert.NotNil(bc.Finalize(4, blk2.HashBlock()))
+	assert.NotNil(bc.Finalize(1, blk2.HashBlock()))
+	assert.Nil(bc.Finalize(2, blk2.HashBlock()))
+	assert.Equal(uint32(2), bc.FinalizedHeight())
+	// finalizing a lower height is a no-op
+	assert.Nil(bc.Finalize(1, blk1.HashBlock()))
+	assert.Equal(uint32(2), bc.FinalizedHeight())
+
+	// the chain can neither be forked nor rolled back below the finalized height
+	assert.Equal(ErrFinalized, errors.Cause(bc.AddBlockSync(fork1)))
+	assert.Equal(ErrFinalized, errors.Cause(bc.RollbackToHeight(context.Background(), 1)))
+	assert.Nil(bc.RollbackToHeight(context.Background(), 2))
+	assert.Equal(uint32(2), bc.TipHeight())
+
+	// the finalized height is persisted
+	assert.Nil(bc.Stop())
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(uint32(2), bc.FinalizedHeight())
+}
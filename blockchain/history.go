@@ -0,0 +1,267 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
+	"github.com/iotexproject/iotex-core/txvm"
+)
+
+var (
+	// ErrStateNotArchived is the error returned when the states at a height out of the state history are queried
+	ErrStateNotArchived = errors.New("state is not archived at the height")
+)
+
+// stateUndo is the states changed by the blocks above a height as they are at the height, merged from the undo
+// records of the blocks
+type stateUndo struct {
+	// utxo keeps the entries at the height, a nil entry means the entry does not exist at the height, and coinbase
+	// the minting height of the coinbase ones
+	utxo     map[cp.Hash32B][]*TxOutput
+	coinbase map[cp.Hash32B]uint32
+	// accounts keeps the account states at the height, a nil account means the address has no state at the height
+	accounts map[string]*state.Account
+	// deployed keeps the contracts deployed above the height, and storage the values of the keys written above it,
+	// where an empty value is a key not set at the height
+	deployed map[string]bool
+	storage  map[string]map[string][]byte
+	// emitted and burned are the supply at the height
+	emitted uint64
+	burned  uint64
+}
+
+// putStateUndo adds the UTXO entries, accounts, contracts and supply changed by the block at the height as they are
+// before it to the batch, the UTXO entries changed are the ones in the overlay of the view of the block and the
+// accounts and contracts the ones in the working set executing it
+// The states before the block are the current ones, so it has to be called before they are updated.
+func (bc *Blockchain) putStateUndo(batch *blockdb.Batch, height uint32, overlay map[cp.Hash32B][]*TxOutput, ws *state.WorkingSet) error {
+	undo := &iproto.StateUndoPb{Emitted: bc.Utk.emitted, Burned: bc.Utk.burned}
+	for hash := range overlay {
+		utxo, _ := bc.Utk.utxoPool.get(hash)
+		undo.UtxoEntry = append(undo.UtxoEntry, convertToUtxoEntryPb(hash, utxo, bc.Utk.coinbaseHeights))
+	}
+	for addr := range ws.Changes() {
+		acct := &iproto.AccountUndoPb{Address: addr}
+		if bc.sf.HasAccount(addr) {
+			acct.Account = bc.sf.Account(addr).Serialize()
+		}
+		undo.Account = append(undo.Account, acct)
+	}
+	for addr := range ws.CodeChanges() {
+		undo.Deployed = append(undo.Deployed, addr)
+	}
+	for addr, storage := range ws.StateChanges() {
+		for key := range storage {
+			undo.Storage = append(undo.Storage, &iproto.StorageUndoPb{Address: addr, Key: []byte(key), Value: bc.sf.State(addr, []byte(key))})
+		}
+	}
+	buf, err := proto.Marshal(undo)
+	if err != nil {
+		return err
+	}
+	batch.PutStateUndo(height, buf)
+	return nil
+}
+
+// mergeStateUndo returns the states changed by the blocks from the tip down to the one above the height as they are
+// at the height, failing with ErrStateNotArchived if the undo record of one of the blocks is missing, e.g., as it is
+// committed before the records are kept or pruned
+// The caller has to hold commitMu so that no block is committed meanwhile.
+func (bc *Blockchain) mergeStateUndo(height uint32) (*stateUndo, error) {
+	su := &stateUndo{
+		utxo:     map[cp.Hash32B][]*TxOutput{},
+		coinbase: map[cp.Hash32B]uint32{},
+		accounts: map[string]*state.Account{},
+		deployed: map[string]bool{},
+		storage:  map[string]map[string][]byte{},
+		emitted:  bc.Utk.emitted,
+		burned:   bc.Utk.burned,
+	}
+	for h := bc.height; h > height; h-- {
+		buf, err := bc.blockDb.GetStateUndo(h)
+		if errors.Cause(err) == blockdb.ErrNotExist {
+			return nil, errors.Wrapf(ErrStateNotArchived, "Missing the states changed by block %d", h)
+		}
+		if err != nil {
+			return nil, err
+		}
+		undo := iproto.StateUndoPb{}
+		if err := proto.Unmarshal(buf, &undo); err != nil {
+			return nil, errors.Wrapf(err, "States changed by block %d", h)
+		}
+		// the states before a lower block override the ones before a higher one
+		for _, entry := range undo.UtxoEntry {
+			hash, utxo := convertFromUtxoEntryPb(entry)
+			delete(su.coinbase, hash)
+			if len(utxo) == 0 {
+				su.utxo[hash] = nil
+				continue
+			}
+			su.utxo[hash] = utxo
+			if entry.Coinbase {
+				su.coinbase[hash] = entry.Height
+			}
+		}
+		for _, acct := range undo.Account {
+			if len(acct.Account) == 0 {
+				su.accounts[acct.Address] = nil
+				continue
+			}
+			account := &state.Account{}
+			if err := account.Deserialize(acct.Account); err != nil {
+				return nil, errors.Wrapf(err, "Account %s changed by block %d", acct.Address, h)
+			}
+			su.accounts[acct.Address] = account
+		}
+		for _, addr := range undo.Deployed {
+			su.deployed[addr] = true
+		}
+		for _, value := range undo.Storage {
+			if _, ok := su.storage[value.Address]; !ok {
+				su.storage[value.Address] = map[string][]byte{}
+			}
+			su.storage[value.Address][string(value.Key)] = value.Value
+		}
+		su.emitted, su.burned = undo.Emitted, undo.Burned
+	}
+	return su, nil
+}
+
+// stateAt returns the states at the height within the state history, the caller has to hold commitMu
+func (bc *Blockchain) stateAt(height uint32) (*stateUndo, error) {
+	if bc.stopped {
+		return nil, errors.Wrapf(ErrStopped, "Cannot query the states at height %d", height)
+	}
+	if height > bc.height {
+		return nil, errors.Errorf("Height %d is above the tip %d", height, bc.height)
+	}
+	if bc.height-height > bc.config.Chain.StateHistory {
+		return nil, errors.Wrapf(ErrStateNotArchived, "Height %d is more than %d blocks below the tip %d", height, bc.config.Chain.StateHistory, bc.height)
+	}
+	return bc.mergeStateUndo(height)
+}
+
+// forEachUtxoAt calls fn on the unspent outputs of each transaction in the states at a height until it returns false
+func (bc *Blockchain) forEachUtxoAt(su *stateUndo, fn func(hash cp.Hash32B, utxo []*TxOutput) bool) {
+	more := true
+	bc.Utk.utxoPool.forEach(func(hash cp.Hash32B, utxo []*TxOutput) bool {
+		if _, ok := su.utxo[hash]; ok {
+			return true
+		}
+		more = fn(hash, utxo)
+		return more
+	})
+	for hash, utxo := range su.utxo {
+		if !more {
+			return
+		}
+		if utxo != nil {
+			more = fn(hash, utxo)
+		}
+	}
+}
+
+// BalanceOfAt returns the balance of the address once the block at the height is applied, which has to be within the
+// state history
+func (bc *Blockchain) BalanceOfAt(address string, height uint32) (uint64, error) {
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	su, err := bc.stateAt(height)
+	if err != nil {
+		return 0, err
+	}
+	balance := uint64(0)
+	key := iotxaddress.GetPubkeyHash(address)
+	bc.forEachUtxoAt(su, func(hash cp.Hash32B, utxo []*TxOutput) bool {
+		for _, out := range utxo {
+			if out.IsLockedWithKey(key) {
+				balance += out.Value
+			}
+		}
+		return true
+	})
+	return balance, nil
+}
+
+// ListUnspentAt returns the UTXO of the address once the block at the height is applied, which has to be within the
+// state history, oldest first, skipping the first 'offset' of them and returning at most 'limit' unless it is 0
+// The confirmations of the UTXO are counted up to the height.
+func (bc *Blockchain) ListUnspentAt(address string, height uint32, offset uint32, limit uint32) ([]*Unspent, error) {
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	su, err := bc.stateAt(height)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*Unspent{}
+	key := iotxaddress.GetPubkeyHash(address)
+	bc.forEachUtxoAt(su, func(hash cp.Hash32B, utxo []*TxOutput) bool {
+		var confirmed uint32
+		found := false
+		for _, out := range utxo {
+			if !out.IsLockedWithKey(key) {
+				continue
+			}
+			if !found {
+				if confirmed, err = bc.txHeight(hash); err != nil {
+					err = errors.Wrapf(err, "Cannot find the block of UTXO %x", hash)
+					return false
+				}
+				found = true
+			}
+			list = append(list, &Unspent{
+				Address:       address,
+				Hash:          hash,
+				Index:         out.outIndex,
+				Value:         out.Value,
+				Height:        confirmed,
+				Confirmations: height - confirmed + 1,
+				ScriptType:    txvm.ScriptType(out.LockScript),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortUnspent(list)
+	if offset >= uint32(len(list)) {
+		return []*Unspent{}, nil
+	}
+	list = list[offset:]
+	if limit > 0 && limit < uint32(len(list)) {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// GetAccountAt returns the nonce and balance of the address in the account-based state once the block at the height
+// is applied, which has to be within the state history
+func (bc *Blockchain) GetAccountAt(address string, height uint32) (*state.Account, error) {
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	su, err := bc.stateAt(height)
+	if err != nil {
+		return nil, err
+	}
+	if acct, ok := su.accounts[address]; ok {
+		if acct == nil {
+			return &state.Account{}, nil
+		}
+		return acct, nil
+	}
+	return bc.sf.Account(address), nil
+}
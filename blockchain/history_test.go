@@ -0,0 +1,113 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/state"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+func TestStateHistory(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.StateHistory = 2
+	genesis := &config.Genesis{
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+		Accounts:    []config.Allocation{{Address: ta.Addrinfo["alfa"].Address, Amount: 50}},
+	}
+	bc, err := CreateBlockchainWithGenesis(context.Background(), cfg, genesis)
+	assert.Nil(err)
+	defer bc.Stop()
+
+	miner := wallet.NewKeySigner(ta.Addrinfo["miner"])
+	alfa := ta.Addrinfo["alfa"].Address
+	bravo := ta.Addrinfo["bravo"].Address
+	for i, amount := range []uint64{20, 5} {
+		tx, err := bc.CreateTransaction(miner, 10-uint64(5*i), []*Payee{{alfa, 10 - uint64(5*i)}})
+		assert.Nil(err)
+		tsf := NewTransfer(uint64(i+1), amount, alfa, bravo)
+		assert.Nil(tsf.Sign(wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+		blk, err := bc.MintNewBlockWithTransfers([]*Tx{tx}, []*Transfer{tsf}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		bc.Reset()
+	}
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+
+	check := func() {
+		balance, err := bc.BalanceOfAt(alfa, 1)
+		assert.Nil(err)
+		assert.Equal(uint64(10), balance)
+		balance, err = bc.BalanceOfAt(alfa, 3)
+		assert.Nil(err)
+		assert.Equal(bc.BalanceOf(alfa, 0), balance)
+		assert.Equal(uint64(15), balance)
+
+		acct, err := bc.GetAccountAt(alfa, 1)
+		assert.Nil(err)
+		assert.Equal(&state.Account{Nonce: 1, Balance: 30}, acct)
+		acct, err = bc.GetAccountAt(bravo, 1)
+		assert.Nil(err)
+		assert.Equal(&state.Account{Balance: 20}, acct)
+		acct, err = bc.GetAccountAt(bravo, 2)
+		assert.Nil(err)
+		assert.Equal(bc.AccountState(bravo), acct)
+		assert.Equal(&state.Account{Balance: 25}, acct)
+
+		unspent, err := bc.ListUnspentAt(alfa, 1, 0, 0)
+		assert.Nil(err)
+		assert.Equal(1, len(unspent))
+		assert.Equal(uint64(10), unspent[0].Value)
+		assert.Equal(uint32(1), unspent[0].Height)
+		assert.Equal(uint32(1), unspent[0].Confirmations)
+		unspent, err = bc.ListUnspentAt(alfa, 3, 1, 0)
+		assert.Nil(err)
+		assert.Equal(1, len(unspent))
+		assert.Equal(uint64(5), unspent[0].Value)
+		assert.Equal(uint32(2), unspent[0].Confirmations)
+
+		// the states out of the history or above the tip cannot be queried
+		_, err = bc.BalanceOfAt(alfa, 0)
+		assert.Equal(ErrStateNotArchived, errors.Cause(err))
+		_, err = bc.GetAccountAt(alfa, 4)
+		assert.NotNil(err)
+	}
+	check()
+
+	// the history of the blocks replayed is kept once reindexed
+	assert.Nil(bc.Reindex(context.Background()))
+	check()
+
+	// the states are unwound with the undo records of the blocks rolled back
+	commitment, err := bc.UtxoCommitment(1)
+	assert.Nil(err)
+	assert.Nil(bc.RollbackToHeight(context.Background(), 1))
+	assert.Equal(commitment, bc.Utk.Commitment())
+	assert.Equal(uint64(10), bc.BalanceOf(alfa, 0))
+	assert.Equal(&state.Account{Nonce: 1, Balance: 30}, bc.AccountState(alfa))
+	assert.Equal(&state.Account{Balance: 20}, bc.AccountState(bravo))
+	assert.Nil(bc.VerifyChain(context.Background(), 0))
+	assert.Nil(bc.RollbackToHeight(context.Background(), 0))
+	assert.False(bc.sf.HasAccount(bravo))
+	assert.Equal(&state.Account{Balance: 50}, bc.AccountState(alfa))
+	assert.Nil(bc.VerifyChain(context.Background(), 0))
+}
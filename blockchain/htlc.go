@@ -0,0 +1,118 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/txvm"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+var (
+	// ErrInvalidHTLC is the error returned when a HTLC output cannot be created or spent
+	ErrInvalidHTLC = errors.New("invalid HTLC")
+)
+
+// CreateHTLCTransaction creates a signed transaction locking 'amount' from 'from' in a hash time locked contract,
+// which 'recipient' can redeem with the preimage of 'hashLock', or 'from' can refund after 'lockTime' blocks
+func (bc *Blockchain) CreateHTLCTransaction(from wallet.Signer, amount uint64, recipient string, hashLock []byte,
+	lockTime uint32) (*Tx, error) {
+	locks, err := txvm.HTLCScript(recipient, from.Address(), hashLock, lockTime)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidHTLC, "%v", err)
+	}
+	tx, err := bc.createTx(from.Address(), amount, []*Payee{{Address: recipient, Amount: amount}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the inputs sign the HTLC output
+	tx.TxOut[0].LockScript = locks
+	tx.TxOut[0].LockScriptSize = uint32(len(locks))
+	if err := bc.SignTransaction(tx, from); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// RedeemHTLC creates a signed transaction redeeming the HTLC output to its recipient by revealing the preimage
+func (bc *Blockchain) RedeemHTLC(recipient wallet.Signer, hash cp.Hash32B, index int32, preimage []byte) (*Tx, error) {
+	utxo, htlc, err := bc.htlcUtxo(hash, index)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(htlc.Recipient, iotxaddress.GetPubkeyHash(recipient.Address())) {
+		return nil, errors.Wrapf(ErrInvalidHTLC, "%s is not the recipient", recipient.Address())
+	}
+
+	in := bc.Utk.CreateTxInputUtxo(hash, index, nil)
+	out := bc.Utk.CreateTxOutputUtxo(recipient.Address(), utxo.Value)
+	tx := NewTx(1, []*TxInput{in}, []*TxOutput{out}, 0)
+	tx.ChainID = bc.chainID
+	digest := tx.SigHash(0, utxo.TxOutputPb)
+	sig, err := signTxIn(recipient, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := txvm.HTLCRedeemScriptWithSig(sig, recipient.PublicKey(), preimage)
+	if err != nil {
+		return nil, errors.Wrapf(ErrSigningFailed, "%v", err)
+	}
+	in.UnlockScript = unlock
+	in.UnlockScriptSize = uint32(len(unlock))
+	return tx, nil
+}
+
+// RefundHTLC creates a signed transaction refunding the HTLC output to its sender, which is valid once the output
+// is confirmed for the lock time of the contract
+func (bc *Blockchain) RefundHTLC(sender wallet.Signer, hash cp.Hash32B, index int32) (*Tx, error) {
+	utxo, htlc, err := bc.htlcUtxo(hash, index)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(htlc.Sender, iotxaddress.GetPubkeyHash(sender.Address())) {
+		return nil, errors.Wrapf(ErrInvalidHTLC, "%s is not the sender", sender.Address())
+	}
+
+	in := bc.Utk.CreateTxInputUtxo(hash, index, nil)
+	in.Sequence = htlc.LockTime
+	out := bc.Utk.CreateTxOutputUtxo(sender.Address(), utxo.Value)
+	tx := NewTx(1, []*TxInput{in}, []*TxOutput{out}, 0)
+	tx.ChainID = bc.chainID
+	digest := tx.SigHash(0, utxo.TxOutputPb)
+	sig, err := signTxIn(sender, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := txvm.HTLCRefundScriptWithSig(sig, sender.PublicKey())
+	if err != nil {
+		return nil, errors.Wrapf(ErrSigningFailed, "%v", err)
+	}
+	in.UnlockScript = unlock
+	in.UnlockScriptSize = uint32(len(unlock))
+	return tx, nil
+}
+
+// htlcUtxo returns the unspent HTLC output and its contract
+func (bc *Blockchain) htlcUtxo(hash cp.Hash32B, index int32) (*TxOutput, *txvm.HTLC, error) {
+	unspent, _ := bc.Utk.utxoPool.get(hash)
+	for _, utxo := range unspent {
+		if utxo.outIndex != index {
+			continue
+		}
+		htlc, err := txvm.ParseHTLCScript(utxo.LockScript)
+		if err != nil {
+			return nil, nil, errors.Wrapf(ErrInvalidHTLC, "UTXO %x:%d: %v", hash, index, err)
+		}
+		return utxo, htlc, nil
+	}
+	return nil, nil, errors.Wrapf(ErrInvalidHTLC, "UTXO %x:%d does not exist", hash, index)
+}
@@ -7,14 +7,26 @@
 package blockchain
 
 import (
+	"io"
+	"time"
+
+	"golang.org/x/net/context"
+
 	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/election"
 	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/state"
+	"github.com/iotexproject/iotex-core/wallet"
 )
 
 // IBlockchain defines the interface of blockchain
 type IBlockchain interface {
 	// Init initializes the blockchain
-	Init() error
+	Init(ctx context.Context) error
+	// Start starts accepting blocks, failing if the blockchain has been stopped
+	Start() error
+	// Stop waits for the commit in flight, rejects later ones, flushes the Db and closes it
+	Stop() error
 	// Close closes the Db connection
 	Close() error
 	// GetHeightByHash returns block's height by hash
@@ -25,10 +37,22 @@ type IBlockchain interface {
 	GetBlockByHeight(height uint32) (*Block, error)
 	// GetBlockByHash returns block from the blockchain hash by hash
 	GetBlockByHash(hash cp.Hash32B) (*Block, error)
+	// GetBlocksByRange returns the blocks with height in [start, end]
+	GetBlocksByRange(ctx context.Context, start uint32, end uint32) ([]*Block, error)
+	// NewBlockIterator returns an iterator over the blocks with height in [start, end]
+	NewBlockIterator(start uint32, end uint32) (*BlockIterator, error)
+	// GetBlockHeaderByHeight returns the block at the given height with only its header
+	GetBlockHeaderByHeight(height uint32) (*Block, error)
+	// GetBlockMetaByHeight returns the metadata of the block at the height without deserializing the block
+	GetBlockMetaByHeight(height uint32) (*BlockMeta, error)
+	// GetBlockMetaByHash returns the metadata of the block with the hash without deserializing the block
+	GetBlockMetaByHash(hash cp.Hash32B) (*BlockMeta, error)
 	// TipHash returns tip block's hash
 	TipHash() cp.Hash32B
 	// TipHeight returns tip block's height
 	TipHeight() uint32
+	// ChainID returns the ID of the chain, which all its blocks carry
+	ChainID() uint32
 	// Reset reset for next block
 	Reset()
 	// ValidateBlock validates a new block before adding it to the blockchain
@@ -36,18 +60,109 @@ type IBlockchain interface {
 	// MintNewBlock creates a new block with given transactions.
 	// Note: the coinbase transaction will be added to the given transactions
 	// when minting a new block.
-	MintNewBlock([]*Tx, string, string) *Block
+	MintNewBlock([]*Tx, string, string) (*Block, error)
+	// MintNewBlockWithTransfers creates a new block with given transactions and account transfers
+	MintNewBlockWithTransfers([]*Tx, []*Transfer, string, string) (*Block, error)
+	// MintNewBlockWithExecutions creates a new block with given transactions, account transfers and contract executions
+	MintNewBlockWithExecutions([]*Tx, []*Transfer, []*Execution, string, string) (*Block, error)
+	// MintNewBlockWithVotes creates a new block with given transactions, account transfers, contract executions and
+	// votes
+	MintNewBlockWithVotes([]*Tx, []*Transfer, []*Execution, []*Vote, string, string) (*Block, error)
+	// MintNewBlockWithEvidences creates a new block with given transactions, account transfers, contract executions,
+	// votes and evidences of misbehavior
+	MintNewBlockWithEvidences([]*Tx, []*Transfer, []*Execution, []*Vote, []*Evidence, string, string) (*Block, error)
+	// MintNewBlockWithDeposits creates a new block with given transactions, account transfers, contract executions,
+	// votes, evidences of misbehavior, deposits to other chains and withdraws of the deposits from other chains
+	MintNewBlockWithDeposits([]*Tx, []*Transfer, []*Execution, []*Vote, []*Evidence, []*Deposit, []*Withdraw, string, string) (*Block, error)
+	// MintNewBlockWithActions creates a new block with given transactions and the actions executing on top of the
+	// current states
+	MintNewBlockWithActions([]*Tx, []Action, string, string) (*Block, error)
 	// AddBlockCommit adds a new block into blockchain
 	AddBlockCommit(blk *Block) error
 	// AddBlockSync adds a past block into blockchain
 	// used by block syncer when the chain in out-of-sync
 	AddBlockSync(blk *Block) error
-	// BalanceOf returns the balance of a given address
-	BalanceOf(string) uint64
+	// ExportChain writes the blocks with height in [start, end] to w in the chain archive format
+	ExportChain(ctx context.Context, w io.Writer, start uint32, end uint32) error
+	// ImportChain reads the blocks of the chain archive from r and adds them to the chain
+	ImportChain(ctx context.Context, r io.Reader) error
+	// RollbackToHeight resets the chain to the block at the height, removing the blocks above it and unwinding the
+	// states and indexes derived from them
+	RollbackToHeight(ctx context.Context, height uint32) error
+	// Finalize marks the block with the hash at the height final, so the chain is no longer reorganized below it
+	Finalize(height uint32, hash cp.Hash32B) error
+	// FinalizedHeight returns the height of the highest block finalized, 0 if no block is finalized
+	FinalizedHeight() uint32
+	// ReorgHalt returns the fork halting the chain for being deeper than the max reorg depth, nil if it is not halted
+	ReorgHalt() *ReorgHalt
+	// AllowReorg allows the fork starting with the block with the hash to be deeper than the max reorg depth, and
+	// resumes the chain if it is halted
+	AllowReorg(hash cp.Hash32B)
+	// VerifyChain re-validates the last 'depth' blocks, or the whole chain if depth is 0, and recomputes the UTXO set
+	VerifyChain(ctx context.Context, depth uint32) error
+	// BalanceOf returns the balance of a given address confirmed by at least minConfirmations blocks
+	BalanceOf(address string, minConfirmations uint32) uint64
+	// BalanceOfAt returns the balance of the address once the block at the height within the state history is applied
+	BalanceOfAt(address string, height uint32) (uint64, error)
+	// AccountState returns the nonce and balance of the address in the account-based state
+	AccountState(address string) *state.Account
+	// GetAccountAt returns the nonce and balance of the address in the account-based state once the block at the
+	// height within the state history is applied
+	GetAccountAt(address string, height uint32) (*state.Account, error)
+	// ContractState returns the value of the key in the storage of the contract
+	ContractState(address string, key []byte) []byte
+	// IsWithdrawn returns true if the withdraw of the deposit is committed
+	IsWithdrawn(depositHash cp.Hash32B) (bool, error)
+	// GetReceipt returns the receipt of the execution in a committed block
+	GetReceipt(hash cp.Hash32B) (*Receipt, error)
+	// GetLogs returns the logs selected by the filter emitted in the blocks with height in [start, end]
+	GetLogs(ctx context.Context, start uint32, end uint32, filter *LogFilter) ([]*Log, error)
+	// EpochOf returns the epoch of the block at the height
+	EpochOf(height uint32) uint32
+	// GetCandidates returns the candidates with their votes in the current account states, the most voted first
+	GetCandidates() []*election.Candidate
+	// GetDelegatesByEpoch returns the delegates elected for the epoch, or nil if no delegate has been elected for it
+	GetDelegatesByEpoch(epoch uint32) ([]*election.Candidate, error)
+	// ListUnspent returns a page of the UTXO of the address confirmed by minConf to maxConf blocks, oldest first
+	ListUnspent(address string, minConf uint32, maxConf uint32, offset uint32, limit uint32) ([]*Unspent, error)
+	// ListUnspentAt returns a page of the UTXO of the address once the block at the height within the state history is
+	// applied, oldest first
+	ListUnspentAt(address string, height uint32, offset uint32, limit uint32) ([]*Unspent, error)
+	// UtxoCommitment returns the commitment to the UTXO set once the block at the height is applied
+	UtxoCommitment(height uint32) (cp.Hash32B, error)
+	// ChainMeta returns the statistics of the chain as of the tip
+	ChainMeta() *ChainMeta
+	// TopHolders returns up to n of the holders with the most balance, the richest first
+	TopHolders(n uint32) []*Holder
+	// CirculatingSupply returns the sum of all UTXO on the chain
+	CirculatingSupply() uint64
 	// UtxoPool returns the UTXO pool of current blockchain
 	UtxoPool() map[cp.Hash32B][]*TxOutput
-	// CreateTransaction creates a signed transaction paying 'amount' from 'from' to 'to'
-	CreateTransaction(from iotxaddress.Address, amount uint64, to []*Payee) *Tx
+	// NewUtxoView returns a copy-on-write view of the UTXO pool, to validate transactions against without touching it
+	NewUtxoView() *UtxoView
+	// ValidateCoinbaseMaturity returns error if the transaction spends immature coinbase outputs in the next block
+	ValidateCoinbaseMaturity(tx *Tx) error
+	// ValidateLockTime returns error if the transaction cannot be included in the next block due to its lock time or
+	// expiry height
+	ValidateLockTime(tx *Tx) error
+	// CheckTransaction returns error if the transaction is malformed regardless of the chain state
+	CheckTransaction(tx *Tx) error
+	// ValidateTxChainID returns error if the transaction is not signed for the chain
+	ValidateTxChainID(tx *Tx) error
+	// EstimateFee returns the fee per byte a transaction should pay to be mined within 'targetBlocks' blocks
+	EstimateFee(targetBlocks uint32) (uint64, error)
+	// CreateTransaction creates a transaction paying 'amount' from 'from' to 'to', signed by the handle of 'from'
+	CreateTransaction(from wallet.Signer, amount uint64, to []*Payee) (*Tx, error)
 	// CreateRawTransaction creates a signed transaction paying 'amount' from 'from' to 'to'
-	CreateRawTransaction(from iotxaddress.Address, amount uint64, to []*Payee) *Tx
+	CreateRawTransaction(from iotxaddress.Address, amount uint64, to []*Payee) (*Tx, error)
+	// SignTransaction signs again the inputs of the transaction spending the UTXO of the signer
+	SignTransaction(tx *Tx, signer wallet.Signer) error
+	// ReserveTxInputs reserves the UTXO spent by the transaction, excluding them from coin selection until released
+	ReserveTxInputs(tx *Tx, ttl time.Duration)
+	// ReleaseTxInputs releases the UTXO reserved for the transaction
+	ReleaseTxInputs(tx *Tx)
+	// Subscribe returns a channel on which block events are delivered
+	Subscribe() <-chan *BlockEvent
+	// Unsubscribe stops delivering block events to the given channel
+	Unsubscribe(ch <-chan *BlockEvent)
 }
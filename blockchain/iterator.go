@@ -0,0 +1,62 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// BlockIterator walks the blocks of a height range of the chain in order, reading them from Db a batch at a time so
+// the whole range is never held in memory
+// It is not safe for concurrent use, and the blocks it returns must not be modified.
+type BlockIterator struct {
+	bc    *Blockchain
+	next  uint32 // height of the first block not read from Db yet
+	end   uint32
+	done  bool // whether the block at end has been read from Db
+	batch []*Block
+}
+
+// NewBlockIterator returns an iterator over the blocks with height in [start, end]
+func (bc *Blockchain) NewBlockIterator(start uint32, end uint32) (*BlockIterator, error) {
+	if start > end || end > bc.height {
+		return nil, errors.Errorf("Invalid block range [%d, %d], tip height is %d", start, end, bc.height)
+	}
+	if start < bc.pruneHeight {
+		return nil, errors.Wrapf(ErrBlockPruned, "Block with height = %d, pruned below %d", start, bc.pruneHeight)
+	}
+	return &BlockIterator{bc: bc, next: start, end: end}, nil
+}
+
+// Next returns the next block of the range, io.EOF once all of them have been returned, or the error of ctx once it
+// is done
+func (it *BlockIterator) Next(ctx context.Context) (*Block, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(it.batch) == 0 {
+		if it.done {
+			return nil, io.EOF
+		}
+		last := it.end
+		if it.end-it.next >= blockBatchSize {
+			last = it.next + blockBatchSize - 1
+		}
+		blks, err := it.bc.GetBlocksByRange(ctx, it.next, last)
+		if err != nil {
+			return nil, err
+		}
+		it.batch = blks
+		it.done = last == it.end
+		it.next = last + 1
+	}
+	blk := it.batch[0]
+	it.batch = it.batch[1:]
+	return blk, nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/txvm"
+)
+
+const (
+	// LockTimeThreshold is the boundary of lock time, below which the lock time is a block height, otherwise a unix
+	// timestamp in seconds
+	LockTimeThreshold = 500000000
+	// SequenceReplaceable is the flag of an input sequence signaling that the transaction may be replaced in the
+	// txpool by a conflicting one paying a higher fee, the other bits of the sequence are the relative lock time
+	SequenceReplaceable = txvm.SequenceReplaceable
+)
+
+var (
+	// ErrTxLocked is the error returned when a transaction is included before its lock time
+	ErrTxLocked = errors.New("transaction is time-locked")
+	// ErrTxExpired is the error returned when a transaction is included after its expiry height
+	ErrTxExpired = errors.New("transaction is expired")
+)
+
+// IsFinal returns true if the transaction can be included in a block of the given height and timestamp
+// A transaction with lock time 0 is always final.
+func (tx *Tx) IsFinal(height uint32, timestamp uint64) bool {
+	if tx.LockTime == 0 {
+		return true
+	}
+	if tx.LockTime < LockTimeThreshold {
+		return tx.LockTime <= height
+	}
+	return uint64(tx.LockTime) <= timestamp
+}
+
+// IsExpired returns true if the transaction can no longer be included in a block of the given height
+// A transaction with expiry height 0 never expires.
+func (tx *Tx) IsExpired(height uint32) bool {
+	return tx.ExpiryHeight != 0 && height > tx.ExpiryHeight
+}
+
+// ValidateLockTime returns error if the transaction cannot be included in the next block due to its lock time, the
+// relative lock time of its inputs, or its expiry height
+func (bc *Blockchain) ValidateLockTime(tx *Tx) error {
+	return bc.validateLockTime(tx, bc.height+1, uint64(time.Now().Unix()))
+}
+
+// validateLockTime checks the transaction is final and not expired at the given height and timestamp, and every input
+// is confirmed for at least the number of blocks of its sequence, ignoring the replaceable flag
+func (bc *Blockchain) validateLockTime(tx *Tx, height uint32, timestamp uint64) error {
+	if tx.IsCoinbase() {
+		return nil
+	}
+	if !tx.IsFinal(height, timestamp) {
+		return errors.Wrapf(ErrTxLocked, "Tx %x is locked until %d", tx.Hash(), tx.LockTime)
+	}
+	if tx.IsExpired(height) {
+		return errors.Wrapf(ErrTxExpired, "Tx %x expired at height %d", tx.Hash(), tx.ExpiryHeight)
+	}
+
+	for _, txIn := range tx.TxIn {
+		lock := txIn.Sequence &^ SequenceReplaceable
+		if lock == 0 {
+			continue
+		}
+		hash := cp.ZeroHash32B
+		copy(hash[:], txIn.TxHash)
+		confirmed, err := bc.txHeight(hash)
+		if err != nil {
+			return errors.Wrapf(ErrTxLocked, "Cannot find the block of UTXO %x: %v", hash, err)
+		}
+		if height < confirmed || height-confirmed < lock {
+			return errors.Wrapf(ErrTxLocked, "UTXO %x confirmed at height %d is locked for %d blocks",
+				hash, confirmed, lock)
+		}
+	}
+	return nil
+}
+
+// txHeight returns the height of the block containing the transaction
+func (bc *Blockchain) txHeight(hash cp.Hash32B) (uint32, error) {
+	blkHash, err := bc.blockDb.GetTxBlockHash(hash[:])
+	if err != nil {
+		return 0, err
+	}
+	return bc.blockDb.GetBlockHeight(blkHash)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"time"
+
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/metrics"
+)
+
+var (
+	tipHeightGauge   = metrics.NewGauge("iotex_chain_tip_height", "Height of the tip of the blockchain")
+	utxoPoolGauge    = metrics.NewGauge("iotex_chain_utxo_pool_size", "Number of txs with unspent outputs in the UTXO pool")
+	commitLatency    = metrics.NewHistogram("iotex_chain_block_commit_seconds", "Latency of committing a block", metrics.DefaultBuckets)
+	reorgCounter     = metrics.NewCounter("iotex_chain_reorgs_total", "Number of blocks committed on top of a block other than the tip")
+	finalHeightGauge = metrics.NewGauge("iotex_chain_final_height", "Height of the highest block finalized")
+	reorgHaltedGauge = metrics.NewGauge("iotex_chain_reorg_halted", "1 if the chain is halted by a fork deeper than the max reorg depth")
+	scriptCacheHits  = metrics.NewCounter("iotex_chain_script_cache_hits_total", "Number of input scripts not verified again as they are in the script cache")
+
+	utxoSlackGauge       = metrics.NewGauge("iotex_chain_utxo_pool_slack", "Number of removed entries whose memory the UTXO pool holds until it is compacted")
+	utxoCompactions      = metrics.NewCounter("iotex_chain_utxo_compactions_total", "Number of compactions of the UTXO pool")
+	utxoReclaimedEntries = metrics.NewCounter("iotex_chain_utxo_reclaimed_entries_total", "Number of removed entries whose memory is reclaimed by the compactions")
+	utxoCompactedEntries = metrics.NewCounter("iotex_chain_utxo_compacted_entries_total", "Number of entries moved by the compactions")
+	utxoColdDeleted      = metrics.NewCounter("iotex_chain_utxo_cold_deleted_total", "Number of spent entries deleted from the cold UTXO store")
+
+	receiveLatency  = metrics.NewHistogram("iotex_chain_block_receive_seconds", "Time from receiving a block to validating it", metrics.DefaultBuckets)
+	validateLatency = metrics.NewHistogram("iotex_chain_block_validate_seconds", "Latency of validating a block", metrics.DefaultBuckets)
+	executeLatency  = metrics.NewHistogram("iotex_chain_block_execute_seconds", "Latency of connecting a block to the UTXO pool and executing its actions", metrics.DefaultBuckets)
+	indexLatency    = metrics.NewHistogram("iotex_chain_block_index_seconds", "Latency of writing a block, its indexes and the states it changes to a batch", metrics.DefaultBuckets)
+	writeLatency    = metrics.NewHistogram("iotex_chain_block_write_seconds", "Latency of writing the batch of a block to the chain DB and applying it in memory", metrics.DefaultBuckets)
+
+	dbSizeGauge     = metrics.NewGauge("iotex_chain_db_size_bytes", "Size of the data of the chain DB as of the last storage check")
+	offloadedBlocks = metrics.NewCounter("iotex_chain_offloaded_blocks_total", "Number of block bodies offloaded to keep the chain DB within the storage budget")
+)
+
+// updateMetrics sets the gauges of the tip and the UTXO pool
+func (bc *Blockchain) updateMetrics() {
+	tipHeightGauge.Set(int64(bc.height))
+	utxoPoolGauge.Set(int64(bc.Utk.utxoPool.Len()))
+	utxoSlackGauge.Set(int64(bc.Utk.utxoPool.peak - bc.Utk.utxoPool.Len()))
+}
+
+// newBlockTrace starts the trace of processing the block, from its receipt if it is received from a peer
+func newBlockTrace(blk *Block) *metrics.Trace {
+	if blk.ReceivedAt.IsZero() {
+		return metrics.NewTrace(time.Now())
+	}
+	trace := metrics.NewTrace(blk.ReceivedAt)
+	trace.Span("receive", receiveLatency)
+	return trace
+}
+
+// logSlowBlock logs the durations of the stages of processing the block if it is slower than the config allows
+func (bc *Blockchain) logSlowBlock(blk *Block, trace *metrics.Trace) {
+	if slow := bc.config.Metrics.SlowBlock; slow > 0 && trace.Duration() > slow {
+		bc.log.WithFields(logger.Fields{"height": blk.Header.height, "txs": len(blk.Tranxs), "stages": trace.String()}).Warning("Slow block")
+	}
+}
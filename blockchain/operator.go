@@ -0,0 +1,169 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+// OperatorKind is the kind of the action registering the operator of a candidate
+const OperatorKind = "operator"
+
+// ErrInvalidOperator is the error returned when an operator registration is malformed or not signed by its owner
+var ErrInvalidOperator = errors.New("invalid operator registration")
+
+func init() {
+	if err := RegisterAction(OperatorKind, DeserializeOperatorRegistration); err != nil {
+		panic(err)
+	}
+}
+
+// OperatorRegistration registers the operator of a candidate, whose key signs the blocks and checkpoints of the
+// candidate once it is elected as a delegate, so the key of the owner staking the candidate can be kept offline
+// The owner has to be a candidate when the registration is executed, and an empty operator revokes the one registered.
+// It is ordered by the nonce of the owner like a vote.
+type OperatorRegistration struct {
+	Version  uint32
+	Nonce    uint64
+	Owner    string
+	Operator string
+	// OwnerPubKey is the public key of the owner, which signs the hash of the registration into Signature
+	OwnerPubKey []byte
+	Signature   []byte
+}
+
+// NewOperatorRegistration returns an unsigned registration of the operator of the owner
+func NewOperatorRegistration(nonce uint64, owner string, operator string) *OperatorRegistration {
+	return &OperatorRegistration{Version: 1, Nonce: nonce, Owner: owner, Operator: operator}
+}
+
+// DeserializeOperatorRegistration returns the registration serialized in buf
+func DeserializeOperatorRegistration(buf []byte) (Action, error) {
+	r := &OperatorRegistration{}
+	if len(buf) < 16 {
+		return nil, errors.Wrapf(ErrInvalidOperator, "Registration has %d bytes", len(buf))
+	}
+	r.Version = cm.MachineEndian.Uint32(buf)
+	r.Nonce = cm.MachineEndian.Uint64(buf[4:])
+	buf = buf[12:]
+	var err error
+	if r.Owner, buf, err = readSized(buf); err != nil {
+		return nil, err
+	}
+	if r.Operator, buf, err = readSized(buf); err != nil {
+		return nil, err
+	}
+	if len(buf) != ed25519.PublicKeySize+ed25519.SignatureSize {
+		return nil, errors.Wrapf(ErrInvalidOperator, "Registration has %d bytes of keys and signature", len(buf))
+	}
+	r.OwnerPubKey = buf[:ed25519.PublicKeySize]
+	r.Signature = buf[ed25519.PublicKeySize:]
+	return r, nil
+}
+
+// readSized returns the string preceded by its length at the start of buf, and the rest of buf
+func readSized(buf []byte) (string, []byte, error) {
+	if len(buf) < 4 {
+		return "", nil, errors.Wrap(ErrInvalidOperator, "Registration is truncated")
+	}
+	size := cm.MachineEndian.Uint32(buf)
+	if uint32(len(buf)-4) < size {
+		return "", nil, errors.Wrap(ErrInvalidOperator, "Registration is truncated")
+	}
+	return string(buf[4 : 4+size]), buf[4+size:], nil
+}
+
+// ByteStream returns a raw byte stream of the registration without the signature
+// The owner and the operator are preceded by their lengths since the operator may be empty.
+func (r *OperatorRegistration) ByteStream() []byte {
+	stream := make([]byte, 4)
+	cm.MachineEndian.PutUint32(stream, r.Version)
+
+	temp := make([]byte, 8)
+	cm.MachineEndian.PutUint64(temp, r.Nonce)
+	stream = append(stream, temp...)
+	for _, field := range []string{r.Owner, r.Operator} {
+		size := make([]byte, 4)
+		cm.MachineEndian.PutUint32(size, uint32(len(field)))
+		stream = append(stream, size...)
+		stream = append(stream, field...)
+	}
+	return append(stream, r.OwnerPubKey...)
+}
+
+// Serialize returns the byte stream of the registration followed by the signature
+func (r *OperatorRegistration) Serialize() ([]byte, error) {
+	return append(r.ByteStream(), r.Signature...), nil
+}
+
+// Hash returns the hash of the registration, which is not changed by signing it
+func (r *OperatorRegistration) Hash() cp.Hash32B {
+	hash := blake2b.Sum256(r.ByteStream())
+	return blake2b.Sum256(hash[:])
+}
+
+// Sign signs the registration with the handle of its owner
+func (r *OperatorRegistration) Sign(signer wallet.Signer) error {
+	if signer.Address() != r.Owner {
+		return errors.Wrapf(ErrSigningFailed, "Signer %s is not the owner %s", signer.Address(), r.Owner)
+	}
+	r.OwnerPubKey = signer.PublicKey()
+	hash := r.Hash()
+	sig, err := signer.Sign(hash[:])
+	if err != nil {
+		return errors.Wrapf(ErrSigningFailed, "%v", err)
+	}
+	r.Signature = sig
+	return nil
+}
+
+// Verify checks the operator is either empty or a valid address other than the owner, and the registration is signed
+// by the key of its owner's address
+func (r *OperatorRegistration) Verify() error {
+	if r.Operator != "" && !iotxaddress.ValidateAddress(r.Operator) {
+		return errors.Wrapf(ErrInvalidOperator, "Invalid operator %s", r.Operator)
+	}
+	if r.Operator == r.Owner {
+		return errors.Wrapf(ErrInvalidOperator, "Owner %s cannot be its own operator", r.Owner)
+	}
+	if len(r.OwnerPubKey) != ed25519.PublicKeySize || len(r.Signature) != ed25519.SignatureSize {
+		return errors.Wrap(ErrInvalidOperator, "Registration is not signed")
+	}
+	pkHash := iotxaddress.GetPubkeyHash(r.Owner)
+	if pkHash == nil || !bytes.Equal(pkHash, iotxaddress.HashPubKey(r.OwnerPubKey)) {
+		return errors.Wrapf(ErrInvalidOperator, "Public key does not match owner %s", r.Owner)
+	}
+	hash := r.Hash()
+	if !cp.Verify(r.OwnerPubKey, hash[:], r.Signature) {
+		return errors.Wrapf(ErrInvalidOperator, "Wrong signature of registration %x", hash)
+	}
+	return nil
+}
+
+// Kind returns the kind of the operator registration action
+func (r *OperatorRegistration) Kind() string {
+	return OperatorKind
+}
+
+// SenderNonce returns the owner of the registration and its nonce
+func (r *OperatorRegistration) SenderNonce() (string, uint64) {
+	return r.Owner, r.Nonce
+}
+
+// Execute registers the operator of the owner, consuming the nonce of the owner
+func (r *OperatorRegistration) Execute(ctx *ActionContext) error {
+	return ctx.WS.SetOperator(r.Owner, r.Operator, r.Nonce)
+}
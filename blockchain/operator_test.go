@@ -0,0 +1,104 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/state"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+func TestOperatorRegistration(t *testing.T) {
+	assert := assert.New(t)
+
+	alfa := ta.Addrinfo["alfa"].Address
+	echo := ta.Addrinfo["echo"].Address
+	r := NewOperatorRegistration(2, alfa, echo)
+	assert.Equal(ErrInvalidOperator, errors.Cause(r.Verify()))
+	assert.Equal(ErrSigningFailed, errors.Cause(r.Sign(wallet.NewKeySigner(ta.Addrinfo["bravo"]))))
+	assert.Nil(r.Sign(wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+	assert.Nil(r.Verify())
+
+	// the registration is read back from its serialization
+	buf, err := r.Serialize()
+	assert.Nil(err)
+	read, err := DeserializeAction(OperatorKind, buf)
+	assert.Nil(err)
+	assert.Equal(r, read)
+	_, err = DeserializeAction(OperatorKind, buf[:len(buf)-1])
+	assert.Equal(ErrInvalidOperator, errors.Cause(err))
+	_, err = DeserializeAction(OperatorKind, buf[:20])
+	assert.Equal(ErrInvalidOperator, errors.Cause(err))
+
+	// an empty operator revokes the registered one, while the owner cannot be its own operator
+	revoke := NewOperatorRegistration(3, alfa, "")
+	assert.Nil(revoke.Sign(wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+	assert.Nil(revoke.Verify())
+	self := NewOperatorRegistration(3, alfa, alfa)
+	assert.Nil(self.Sign(wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+	assert.Equal(ErrInvalidOperator, errors.Cause(self.Verify()))
+	r.Operator = "echo"
+	assert.Equal(ErrInvalidOperator, errors.Cause(r.Verify()))
+	r.Operator = ta.Addrinfo["foxtrot"].Address
+	assert.Equal(ErrInvalidOperator, errors.Cause(r.Verify()))
+}
+
+func TestRegisterOperator(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	genesis := &config.Genesis{
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+		Accounts:    []config.Allocation{{Address: ta.Addrinfo["alfa"].Address, Amount: 50}},
+	}
+	bc, err := CreateBlockchainWithGenesis(context.Background(), cfg, genesis)
+	assert.Nil(err)
+	defer bc.Close()
+
+	alfa := ta.Addrinfo["alfa"].Address
+	echo := ta.Addrinfo["echo"].Address
+	register := func(nonce uint64, operator string) *OperatorRegistration {
+		r := NewOperatorRegistration(nonce, alfa, operator)
+		assert.Nil(r.Sign(wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+		return r
+	}
+	// commit commits a block of the actions, and returns the actions of the registered kinds the block carries
+	commit := func(actions ...Action) []Action {
+		blk, err := bc.MintNewBlockWithActions([]*Tx{}, actions, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		return blk.OtherActions
+	}
+
+	// only a candidate registers an operator
+	assert.Nil(commit(register(1, echo)))
+	assert.Equal("", bc.AccountState(alfa).Operator)
+	vote := NewVote(1, alfa, alfa)
+	assert.Nil(vote.Sign(wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+	assert.Equal([]Action{register(2, echo)}, commit(vote, register(2, echo)))
+	assert.Equal(&state.Account{Nonce: 2, Balance: 50, Votee: alfa, Operator: echo}, bc.AccountState(alfa))
+	blk, err := bc.GetBlockByHeight(2)
+	assert.Nil(err)
+	assert.Equal([]Action{register(2, echo)}, blk.OtherActions)
+
+	// a replayed registration is left out
+	assert.Nil(commit(register(2, "")))
+	assert.Equal(echo, bc.AccountState(alfa).Operator)
+	commit(register(3, ""))
+	assert.Equal("", bc.AccountState(alfa).Operator)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/txvm"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+var (
+	// ErrPartialTxMismatch indicates the partially signed transactions are not of the same transaction
+	ErrPartialTxMismatch = errors.New("partially signed transactions mismatch")
+	// ErrIncompleteTx indicates an input of the partially signed transaction lacks the signatures to spend it
+	ErrIncompleteTx = errors.New("transaction not fully signed")
+)
+
+// PartialTxInput holds the signatures collected for an input of a partially signed transaction
+type PartialTxInput struct {
+	// MultisigKeys are the multisig keys of the address spent by the input, nil if it spends a single-key address
+	MultisigKeys []byte
+	Signatures   []*txvm.PartialSignature
+}
+
+// PartialTx is a partially signed transaction, which carries a raw transaction created by CreateRawTransaction along
+// with the signatures of its inputs collected so far
+// It is serialized to be passed to the signers, e.g., the cosigners of a multisig address or an air-gapped wallet,
+// then the partially signed transactions they return are combined and finalized into the signed transaction.
+type PartialTx struct {
+	// Tx is the raw transaction, the unlock script of each input is the message to sign
+	Tx     *Tx
+	Inputs []*PartialTxInput
+}
+
+// NewPartialTx creates a partially signed transaction of the raw transaction without any signature, multisigKeys are
+// the multisig keys of the address spent by the raw transaction, or nil if it spends a single-key address
+func NewPartialTx(tx *Tx, multisigKeys []byte) *PartialTx {
+	inputs := make([]*PartialTxInput, len(tx.TxIn))
+	for i := range inputs {
+		inputs[i] = &PartialTxInput{MultisigKeys: multisigKeys}
+	}
+	return &PartialTx{Tx: tx, Inputs: inputs}
+}
+
+// Sign adds the signatures of all inputs by the handle of a key, replacing the ones previously added by the same key
+func (ptx *PartialTx) Sign(signer wallet.Signer) error {
+	if len(ptx.Inputs) != len(ptx.Tx.TxIn) {
+		return errors.Wrapf(ErrPartialTxMismatch, "%d inputs with signatures for %d inputs", len(ptx.Tx.TxIn),
+			len(ptx.Inputs))
+	}
+	sigs, err := ptx.Tx.SignPartial(signer)
+	if err != nil {
+		return err
+	}
+	for i, input := range ptx.Inputs {
+		input.addSignature(sigs[i])
+	}
+	return nil
+}
+
+// CombinePartialTxs merges the signatures of the partially signed transactions of the same raw transaction into a new one
+func CombinePartialTxs(ptxs ...*PartialTx) (*PartialTx, error) {
+	if len(ptxs) == 0 {
+		return nil, errors.Wrap(ErrPartialTxMismatch, "nothing to combine")
+	}
+	hash := ptxs[0].Tx.Hash()
+	combined := NewPartialTx(ptxs[0].Tx, nil)
+	for _, ptx := range ptxs {
+		if ptx.Tx.Hash() != hash || len(ptx.Inputs) != len(combined.Inputs) {
+			return nil, errors.Wrapf(ErrPartialTxMismatch, "cannot combine tx %x with tx %x", ptx.Tx.Hash(), hash)
+		}
+		for i, input := range ptx.Inputs {
+			if input.MultisigKeys != nil {
+				if combined.Inputs[i].MultisigKeys != nil &&
+					!bytes.Equal(combined.Inputs[i].MultisigKeys, input.MultisigKeys) {
+					return nil, errors.Wrapf(ErrPartialTxMismatch, "input %d of tx %x has different multisig keys", i,
+						hash)
+				}
+				combined.Inputs[i].MultisigKeys = input.MultisigKeys
+			}
+			for _, sig := range input.Signatures {
+				combined.Inputs[i].addSignature(sig)
+			}
+		}
+	}
+	return combined, nil
+}
+
+// Finalize builds the unlock scripts of all inputs from the signatures collected, and returns the signed transaction
+// The partially signed transaction is left unchanged.
+func (ptx *PartialTx) Finalize() (*Tx, error) {
+	hash := ptx.Tx.Hash()
+	if len(ptx.Inputs) != len(ptx.Tx.TxIn) {
+		return nil, errors.Wrapf(ErrPartialTxMismatch, "tx %x has %d inputs with signatures for %d inputs", hash,
+			len(ptx.Tx.TxIn), len(ptx.Inputs))
+	}
+	tx := &Tx{}
+	tx.ConvertFromTxPb(proto.Clone(ptx.Tx.ConvertToTxPb()).(*iproto.TxPb))
+	for i, in := range tx.TxIn {
+		input := ptx.Inputs[i]
+		var unlock []byte
+		var err error
+		if input.MultisigKeys != nil {
+			unlock, err = txvm.MultisigSignatureScript(in.UnlockScript, input.MultisigKeys, input.Signatures)
+		} else {
+			unlock, err = singleSignatureScript(in.UnlockScript, input.Signatures)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(ErrIncompleteTx, "input %d of tx %x: %v", i, hash, err)
+		}
+		in.UnlockScript = unlock
+		in.UnlockScriptSize = uint32(len(unlock))
+	}
+	return tx, nil
+}
+
+// IsComplete returns true if all inputs have the signatures required to finalize the transaction
+func (ptx *PartialTx) IsComplete() bool {
+	_, err := ptx.Finalize()
+	return err == nil
+}
+
+// ConvertToPartialTxPb creates a protobuf's PartialTx using type PartialTx
+func (ptx *PartialTx) ConvertToPartialTxPb() *iproto.PartialTxPb {
+	inputs := make([]*iproto.PartialTxInputPb, len(ptx.Inputs))
+	for i, input := range ptx.Inputs {
+		sigs := make([]*iproto.PartialSignaturePb, len(input.Signatures))
+		for j, sig := range input.Signatures {
+			sigs[j] = &iproto.PartialSignaturePb{PubKey: sig.PubKey, Signature: sig.Signature}
+		}
+		inputs[i] = &iproto.PartialTxInputPb{MultisigKeys: input.MultisigKeys, Signatures: sigs}
+	}
+	return &iproto.PartialTxPb{Tx: ptx.Tx.ConvertToTxPb(), Inputs: inputs}
+}
+
+// ConvertFromPartialTxPb converts a protobuf's PartialTx back to type PartialTx
+func (ptx *PartialTx) ConvertFromPartialTxPb(pbPtx *iproto.PartialTxPb) {
+	ptx.Tx = &Tx{}
+	if pbPtx.GetTx() != nil {
+		ptx.Tx.ConvertFromTxPb(pbPtx.GetTx())
+	}
+	ptx.Inputs = make([]*PartialTxInput, len(pbPtx.GetInputs()))
+	for i, input := range pbPtx.GetInputs() {
+		sigs := make([]*txvm.PartialSignature, len(input.GetSignatures()))
+		for j, sig := range input.GetSignatures() {
+			sigs[j] = &txvm.PartialSignature{PubKey: sig.GetPubKey(), Signature: sig.GetSignature()}
+		}
+		ptx.Inputs[i] = &PartialTxInput{MultisigKeys: input.GetMultisigKeys(), Signatures: sigs}
+	}
+}
+
+// Serialize returns a serialized byte stream for the PartialTx
+func (ptx *PartialTx) Serialize() ([]byte, error) {
+	return proto.Marshal(ptx.ConvertToPartialTxPb())
+}
+
+// Deserialize parses the byte stream into the PartialTx
+func (ptx *PartialTx) Deserialize(buf []byte) error {
+	pbPtx := iproto.PartialTxPb{}
+	if err := proto.Unmarshal(buf, &pbPtx); err != nil {
+		return err
+	}
+	ptx.ConvertFromPartialTxPb(&pbPtx)
+	return nil
+}
+
+// addSignature adds the signature to the input, replacing the one of the same key
+func (input *PartialTxInput) addSignature(sig *txvm.PartialSignature) {
+	for i, s := range input.Signatures {
+		if bytes.Equal(s.PubKey, sig.PubKey) {
+			input.Signatures[i] = sig
+			return
+		}
+	}
+	input.Signatures = append(input.Signatures, sig)
+}
+
+// singleSignatureScript builds the unlock script of an input spending a single-key or Schnorr address from the first
+// valid signature of the txin
+func singleSignatureScript(txin []byte, sigs []*txvm.PartialSignature) ([]byte, error) {
+	hash := blake2b.Sum256(txin)
+	for _, sig := range sigs {
+		if cp.Verify(sig.PubKey, hash[:], sig.Signature) || cp.SchnorrVerify(sig.PubKey, hash[:], sig.Signature) {
+			return txvm.SignatureScriptWithSig(sig.Signature, sig.PubKey)
+		}
+	}
+	return nil, errors.Errorf("no valid signature among %d", len(sigs))
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/golang/protobuf/proto"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+const (
+	// ReceiptFailure is the status of an execution which failed, whose changes to the contracts are discarded
+	ReceiptFailure = 0
+	// ReceiptSuccess is the status of an execution which succeeded
+	ReceiptSuccess = 1
+)
+
+// Receipt records the result of an execution in a committed block
+type Receipt struct {
+	// Hash is the hash of the execution
+	Hash    cp.Hash32B
+	Status  uint32
+	GasUsed uint64
+	// ContractAddress is the address of the contract deployed or invoked by the execution
+	ContractAddress string
+	// ReturnValue is returned by the invoked contract, or the reason of a failure
+	ReturnValue []byte
+	// BlockHeight is the height of the block containing the execution
+	BlockHeight uint32
+	// Logs are emitted by the invoked contract, a failed execution has no logs
+	Logs []*Log
+}
+
+// Log is emitted by a contract during an execution
+type Log struct {
+	// Address is the address of the contract emitting the log
+	Address string
+	Topics  [][]byte
+	Data    []byte
+	// Hash is the hash of the execution emitting the log
+	Hash        cp.Hash32B
+	BlockHeight uint32
+	// Index is the position of the log among the logs of the execution
+	Index uint32
+}
+
+// ConvertToLogPb creates a protobuf's Log using type Log
+func (l *Log) ConvertToLogPb() *iproto.LogPb {
+	return &iproto.LogPb{
+		Address:     l.Address,
+		Topics:      l.Topics,
+		Data:        l.Data,
+		Hash:        l.Hash[:],
+		BlockHeight: l.BlockHeight,
+		Index:       l.Index,
+	}
+}
+
+// ConvertFromLogPb converts a protobuf's Log back to type Log
+func (l *Log) ConvertFromLogPb(pbLog *iproto.LogPb) {
+	l.Address = pbLog.GetAddress()
+	l.Topics = pbLog.GetTopics()
+	l.Data = pbLog.GetData()
+	copy(l.Hash[:], pbLog.GetHash())
+	l.BlockHeight = pbLog.GetBlockHeight()
+	l.Index = pbLog.GetIndex()
+}
+
+// ConvertToReceiptPb creates a protobuf's Receipt using type Receipt
+func (r *Receipt) ConvertToReceiptPb() *iproto.ReceiptPb {
+	pbReceipt := &iproto.ReceiptPb{
+		Hash:            r.Hash[:],
+		Status:          r.Status,
+		GasUsed:         r.GasUsed,
+		ContractAddress: r.ContractAddress,
+		ReturnValue:     r.ReturnValue,
+		BlockHeight:     r.BlockHeight,
+	}
+	for _, l := range r.Logs {
+		pbReceipt.Logs = append(pbReceipt.Logs, l.ConvertToLogPb())
+	}
+	return pbReceipt
+}
+
+// ConvertFromReceiptPb converts a protobuf's Receipt back to type Receipt
+func (r *Receipt) ConvertFromReceiptPb(pbReceipt *iproto.ReceiptPb) {
+	copy(r.Hash[:], pbReceipt.GetHash())
+	r.Status = pbReceipt.GetStatus()
+	r.GasUsed = pbReceipt.GetGasUsed()
+	r.ContractAddress = pbReceipt.GetContractAddress()
+	r.ReturnValue = pbReceipt.GetReturnValue()
+	r.BlockHeight = pbReceipt.GetBlockHeight()
+	r.Logs = nil
+	for _, pbLog := range pbReceipt.GetLogs() {
+		l := &Log{}
+		l.ConvertFromLogPb(pbLog)
+		r.Logs = append(r.Logs, l)
+	}
+}
+
+// Serialize returns a serialized byte stream for the Receipt
+func (r *Receipt) Serialize() ([]byte, error) {
+	return proto.Marshal(r.ConvertToReceiptPb())
+}
+
+// Deserialize parses the byte stream into the Receipt
+func (r *Receipt) Deserialize(buf []byte) error {
+	pbReceipt := iproto.ReceiptPb{}
+	if err := proto.Unmarshal(buf, &pbReceipt); err != nil {
+		return err
+	}
+	r.ConvertFromReceiptPb(&pbReceipt)
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+// reindexBatchBlocks is the number of blocks reindexed in one commit, after which the progress is reported
+var reindexBatchBlocks = uint32(1000)
+
+// Reindex rebuilds the hash <-> height mapping, the tx, evidence and withdraw indexes, the UTXO, the account states,
+// contracts and receipts derived from the blocks, as well as the UTXO commitments and statistics, by replaying the
+// blocks in Db from genesis, e.g., once an index is corrupted
+// The blocks are found by following the previous block hashes from the tip, so only the blocks and the tip have to be
+// intact. The progress is committed every reindexBatchBlocks blocks, so a reindex interrupted, e.g., by the error of
+// ctx once it is done or a crash, resumes from the last commit, which Init does on the next start. A pruned chain
+// cannot be reindexed since its blocks cannot be replayed.
+func (bc *Blockchain) Reindex(ctx context.Context) error {
+	if bc.pruneHeight > 0 {
+		return errors.Wrapf(ErrBlockPruned, "Cannot replay the blocks below %d to reindex", bc.pruneHeight)
+	}
+	if bc.blockDb.IsReadOnly() {
+		return blockdb.ErrReadOnly
+	}
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	if bc.stopped {
+		return errors.Wrap(ErrStopped, "Cannot reindex")
+	}
+	return bc.reindex(ctx)
+}
+
+// reindex replays the blocks from the height the reindex in progress is at, or from genesis otherwise, which the
+// caller has to serialize with the commits
+func (bc *Blockchain) reindex(ctx context.Context) error {
+	hashes, err := bc.chainHashes(ctx)
+	if err != nil {
+		return err
+	}
+	next, err := bc.blockDb.GetReindexNext()
+	switch {
+	case err == nil && next > 0:
+		// the states are persisted as of the last block reindexed
+		if err := bc.loadUtxoPool(next - 1); err != nil {
+			return err
+		}
+		if err := bc.loadAccounts(); err != nil {
+			return err
+		}
+		if err := bc.loadContracts(); err != nil {
+			return err
+		}
+	case err == nil || errors.Cause(err) == blockdb.ErrNotExist:
+		next = 0
+		batch := blockdb.NewBatch()
+		batch.StartReindex()
+		if err := bc.blockDb.Commit(batch); err != nil {
+			return err
+		}
+		bc.Utk.clearPool()
+		bc.sf.Clear()
+	default:
+		return err
+	}
+	bc.blockCache.Purge()
+	bc.hashCache.Purge()
+
+	totalTxs := uint64(0)
+	if next > 0 {
+		if totalTxs, err = bc.blockDb.GetTxTotal(next - 1); err != nil {
+			return err
+		}
+	}
+	bc.log.WithFields(logger.Fields{"from": next, "tip": bc.height}).Info("Reindexing blocks")
+	batch := blockdb.NewBatch()
+	for height := next; height <= bc.height; height++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		blk, err := bc.blockByHash(hashes[height])
+		if err != nil {
+			return err
+		}
+		if totalTxs, err = bc.reindexBlock(batch, blk, hashes[height], totalTxs); err != nil {
+			return errors.Wrapf(err, "Reindexing block %d", height)
+		}
+		if height == bc.height || (height+1)%reindexBatchBlocks == 0 {
+			batch.PutUtxoHeight(height)
+			batch.PutSupply(bc.Utk.emitted, bc.Utk.burned)
+			if height == bc.height {
+				batch.FinishReindex()
+			} else {
+				batch.PutReindexNext(height + 1)
+			}
+			if err := bc.blockDb.Commit(batch); err != nil {
+				return err
+			}
+			batch = blockdb.NewBatch()
+			bc.log.WithFields(logger.Fields{"height": height, "tip": bc.height}).Info("Reindexed blocks")
+		}
+	}
+	return bc.loadStats(ctx)
+}
+
+// chainHashes returns the hashes of the blocks from genesis to the tip by height, found by following the previous
+// block hashes from the tip in the blocks read from Db
+func (bc *Blockchain) chainHashes(ctx context.Context) ([]cp.Hash32B, error) {
+	hashes := make([]cp.Hash32B, bc.height+1)
+	hash := bc.tip
+	for height := int64(bc.height); height >= 0; height-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		blk, err := bc.blockByHash(hash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Block %d", height)
+		}
+		if blk.Height() != uint32(height) {
+			return nil, errors.Wrapf(ErrInconsistentChain, "Block %x has height %d, expecting %d", hash, blk.Height(), height)
+		}
+		hashes[height] = hash
+		hash = blk.PrevHash()
+	}
+	if hash != cp.ZeroHash32B {
+		return nil, errors.Wrapf(ErrInconsistentChain, "Genesis block has prev hash %x", hash)
+	}
+	return hashes, nil
+}
+
+// blockByHash reads the block of the hash from Db, bypassing the block cache, and checks the block hashes to it
+func (bc *Blockchain) blockByHash(hash cp.Hash32B) (*Block, error) {
+	serialized, err := bc.blockDb.CheckOutBlock(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	blk := &Block{}
+	if err := blk.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	if blk.HashBlock() != hash {
+		return nil, errors.Wrapf(ErrInconsistentChain, "Block %x hashes to %x", hash, blk.HashBlock())
+	}
+	return blk, nil
+}
+
+// reindexBlock adds the indexes and the states of the block to the batch like committing it, applies the block to
+// the UTXO pool and the account states, and returns the number of transactions in the blocks up to it
+func (bc *Blockchain) reindexBlock(batch *blockdb.Batch, blk *Block, hash cp.Hash32B, totalTxs uint64) (uint64, error) {
+	height := blk.Header.height
+	batch.PutHeightIndex(hash[:], height)
+	putIndexes(batch, blk, hash)
+	// the blocks committed before their metadata is stored get it once reindexed
+	serialized, err := blk.Serialize()
+	if err != nil {
+		return 0, err
+	}
+	if err := putBlockMeta(batch, newBlockMeta(blk, hash, len(serialized))); err != nil {
+		return 0, err
+	}
+
+	view := bc.Utk.NewView()
+	if err := view.ConnectBlock(blk, bc.emission(height)); err != nil {
+		return 0, err
+	}
+	coinbase := view.coinbaseHeights()
+	if err := putUtxo(batch, view.overlay, coinbase); err != nil {
+		return 0, err
+	}
+	commitment := bc.Utk.commitmentAfter(view.overlay, coinbase).Sum()
+	batch.PutUtxoCommitment(height, commitment[:])
+	ws, receipts, err := bc.executeBlock(blk)
+	if err != nil {
+		return 0, err
+	}
+	putAccounts(batch, ws)
+	putContracts(batch, ws)
+	if err := putReceipts(batch, height, receipts); err != nil {
+		return 0, err
+	}
+	if err := bc.putStateUndo(batch, height, view.overlay, ws); err != nil {
+		return 0, err
+	}
+	if err := bc.putDelegates(batch, height, ws); err != nil {
+		return 0, err
+	}
+	totalTxs += uint64(blk.Header.trnxNumber)
+	batch.PutTxTotal(height, totalTxs)
+
+	if err := bc.Utk.ApplyView(view); err != nil {
+		return 0, err
+	}
+	bc.sf.Apply(ws)
+	return totalTxs, nil
+}
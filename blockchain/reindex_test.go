@@ -0,0 +1,118 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	"github.com/iotexproject/iotex-core/config"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+// countdownContext is done once its Err has been called n times
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (ctx *countdownContext) Err() error {
+	if ctx.n == 0 {
+		return context.Canceled
+	}
+	ctx.n--
+	return nil
+}
+
+func TestReindex(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["alfa"].Address, 10}})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+	for i := 0; i < 3; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+	tip := bc.TipHash()
+	balance := bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0)
+	totalTxs := bc.ChainMeta().TotalTxs
+	commitment, err := bc.UtxoCommitment(4)
+	assert.Nil(err)
+
+	// corrupt the tx and height indexes
+	txHash := tx.Hash()
+	batch := blockdb.NewBatch()
+	batch.PutTxIndex(txHash[:], tip[:])
+	batch.PutHeightIndex(tip[:], 1)
+	assert.Nil(bc.blockDb.Commit(batch))
+	bc.hashCache.Purge()
+	hash, err := bc.GetHashByHeight(1)
+	assert.Nil(err)
+	assert.Equal(tip, hash)
+
+	assert.Nil(bc.Reindex(context.Background()))
+	hash, err = bc.GetHashByHeight(1)
+	assert.Nil(err)
+	assert.Equal(blk.HashBlock(), hash)
+	height, err := bc.GetHeightByHash(tip)
+	assert.Nil(err)
+	assert.Equal(uint32(4), height)
+	blkHash, err := bc.blockDb.GetTxBlockHash(txHash[:])
+	assert.Nil(err)
+	assert.Equal(hash[:], blkHash)
+	assert.Equal(balance, bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	recomputed, err := bc.UtxoCommitment(4)
+	assert.Nil(err)
+	assert.Equal(commitment, recomputed)
+	assert.Nil(bc.VerifyChain(context.Background(), 0))
+	_, err = bc.blockDb.GetReindexNext()
+	assert.Equal(blockdb.ErrNotExist, errors.Cause(err))
+
+	// the reindex interrupted after the first 2 blocks, once the 5 blocks are found, resumes on the next start
+	defer func(n uint32) { reindexBatchBlocks = n }(reindexBatchBlocks)
+	reindexBatchBlocks = 2
+	assert.Equal(context.Canceled, errors.Cause(bc.Reindex(&countdownContext{context.Background(), 7})))
+	next, err := bc.blockDb.GetReindexNext()
+	assert.Nil(err)
+	assert.Equal(uint32(2), next)
+	assert.Nil(bc.Stop())
+
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(tip, bc.TipHash())
+	_, err = bc.blockDb.GetReindexNext()
+	assert.Equal(blockdb.ErrNotExist, errors.Cause(err))
+	assert.Equal(balance, bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	blkHash, err = bc.blockDb.GetTxBlockHash(txHash[:])
+	assert.Nil(err)
+	assert.Equal(hash[:], blkHash)
+	assert.Nil(bc.VerifyChain(context.Background(), 0))
+	assert.Equal(totalTxs, bc.ChainMeta().TotalTxs)
+
+	// the pruned chain cannot be replayed
+	bc.pruneHeight = 1
+	assert.Equal(ErrBlockPruned, errors.Cause(bc.Reindex(context.Background())))
+	bc.pruneHeight = 0
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+var (
+	// ErrReorgTooDeep is the error returned when a block forks the chain deeper than the max reorg depth, which halts
+	// the chain
+	ErrReorgTooDeep = errors.New("reorg deeper than the max reorg depth")
+	// ErrChainHalted is the error returned when a block is added to the chain halted by a fork deeper than the max
+	// reorg depth
+	ErrChainHalted = errors.New("chain halted by a deep reorg")
+)
+
+// ReorgHalt is the fork which halted the chain, replacing more blocks than the max reorg depth
+type ReorgHalt struct {
+	// Height and Hash are the ones of the first block of the fork
+	Height uint32
+	Hash   cp.Hash32B
+	// Depth is the number of blocks the fork replaces
+	Depth uint32
+}
+
+// reorgGuard tracks the fork halting the chain and the forks allowed beyond the max reorg depth
+type reorgGuard struct {
+	mu      sync.Mutex
+	halt    *ReorgHalt
+	allowed map[cp.Hash32B]bool
+}
+
+// CheckReorg returns ErrChainHalted if the chain is halted, or ErrReorgTooDeep if the block forks the chain replacing
+// more blocks than the max reorg depth of the config, unless the fork is allowed by AllowReorg
+// The chain never switches branches by itself, the blocks added have to extend the tip. A caller switching to another
+// branch by rolling the chain back to the parent of the fork, e.g., a simulated node, checks the first block of the
+// fork here beforehand. A block forks the chain if its parent is on the chain below the tip, and replaces the blocks
+// above its parent. A fork whose parent is not on the chain is refused with ErrInvalidBlock. A fork too deep only halts
+// the chain once its header passes the checks of the blockchain and is verified by the consensus, so a block forged
+// by a peer cannot halt it, and it is merely refused without a consensus to verify it. The chain stays halted,
+// refusing every block, until a fork is allowed by AllowReorg, e.g., on the admin service, or the node is restarted
+// with a checkpoint pinning one of the branches.
+func (bc *Blockchain) CheckReorg(blk *Block) error {
+	bc.reorg.mu.Lock()
+	defer bc.reorg.mu.Unlock()
+	if err := bc.haltedErr(); err != nil {
+		return err
+	}
+	max := bc.config.Chain.MaxReorgDepth
+	if max == 0 || blk.PrevHash() == bc.tip {
+		return nil
+	}
+	// the depth is counted from the ancestor the fork branches off
+	ancestor, err := bc.GetHeightByHash(blk.PrevHash())
+	if err != nil {
+		return errors.Wrapf(ErrInvalidBlock, "Parent %x of the fork is not on the chain: %v", blk.PrevHash(), err)
+	}
+	// the index of the hashes may still hold the blocks of a branch reorganized away
+	if hash, err := bc.GetHashByHeight(ancestor); err != nil || hash != blk.PrevHash() {
+		return errors.Wrapf(ErrInvalidBlock, "Parent %x of the fork is not on the chain", blk.PrevHash())
+	}
+	if ancestor >= bc.height {
+		return nil
+	}
+	depth := bc.height - ancestor
+	hash := blk.HashBlock()
+	if depth <= max || bc.reorg.allowed[hash] {
+		return nil
+	}
+	if bc.consensus == nil {
+		return errors.Wrapf(ErrReorgTooDeep, "Block %x at height %d forks %d blocks, the max is %d, without a consensus to verify it",
+			hash, blk.Height(), depth, max)
+	}
+	if err := bc.verifyForkHeader(blk, ancestor); err != nil {
+		return err
+	}
+	bc.reorg.halt = &ReorgHalt{Height: blk.Height(), Hash: hash, Depth: depth}
+	reorgHaltedGauge.Set(1)
+	bc.log.WithFields(logger.Fields{"height": blk.Height(), "hash": hash, "depth": depth, "max": max}).Error(
+		"Halted the chain on a fork deeper than the max reorg depth, allow it on the admin service or pin a checkpoint")
+	return errors.Wrapf(ErrReorgTooDeep, "Block %x at height %d forks %d blocks, the max is %d", hash, blk.Height(), depth, max)
+}
+
+// checkHalted returns ErrChainHalted if the chain is halted
+func (bc *Blockchain) checkHalted() error {
+	bc.reorg.mu.Lock()
+	defer bc.reorg.mu.Unlock()
+	return bc.haltedErr()
+}
+
+// haltedErr is checkHalted with the lock of the reorg guard held
+func (bc *Blockchain) haltedErr() error {
+	if halt := bc.reorg.halt; halt != nil {
+		return errors.Wrapf(ErrChainHalted, "Block %x at height %d forks %d blocks", halt.Hash, halt.Height, halt.Depth)
+	}
+	return nil
+}
+
+// verifyForkHeader returns ErrInvalidBlock if the first block of a fork does not belong to the chain, is not on top of
+// its parent at the height of the ancestor, does not match its merkle root, or is refused by the consensus, which has
+// to be set
+func (bc *Blockchain) verifyForkHeader(blk *Block, ancestor uint32) error {
+	if blk.Header.chainID != bc.chainID {
+		return errors.Wrapf(ErrInvalidBlock, "Wrong chain ID %d, expecting %d", blk.Header.chainID, bc.chainID)
+	}
+	if blk.Header.height != ancestor+1 {
+		return errors.Wrapf(ErrInvalidBlock, "Wrong block height %d, expecting %d", blk.Header.height, ancestor+1)
+	}
+	if !blk.VerifyMerkleRoot() {
+		return errors.Wrapf(ErrInvalidBlock, "Wrong merkle root %x", blk.Header.merkleRoot)
+	}
+	parent, err := bc.GetBlockHeaderByHeight(ancestor)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidBlock, "Cannot get parent block %x: %v", blk.PrevHash(), err)
+	}
+	if err := bc.consensus.ValidateHeader(blk, parent); err != nil {
+		return errors.Wrapf(ErrInvalidBlock, "%v", err)
+	}
+	if err := bc.consensus.VerifyProposer(blk); err != nil {
+		return errors.Wrapf(ErrInvalidBlock, "%v", err)
+	}
+	return nil
+}
+
+// ReorgHalt returns the fork halting the chain, nil if the chain is not halted
+func (bc *Blockchain) ReorgHalt() *ReorgHalt {
+	bc.reorg.mu.Lock()
+	defer bc.reorg.mu.Unlock()
+	if bc.reorg.halt == nil {
+		return nil
+	}
+	halt := *bc.reorg.halt
+	return &halt
+}
+
+// AllowReorg allows the fork starting with the block with the hash to replace more blocks than the max reorg depth,
+// and resumes the chain if it is halted
+// The fork halting the chain is not applied by resuming it, but once its blocks are received again.
+func (bc *Blockchain) AllowReorg(hash cp.Hash32B) {
+	bc.reorg.mu.Lock()
+	defer bc.reorg.mu.Unlock()
+	if bc.reorg.allowed == nil {
+		bc.reorg.allowed = make(map[cp.Hash32B]bool)
+	}
+	bc.reorg.allowed[hash] = true
+	if bc.reorg.halt != nil {
+		bc.log.WithFields(logger.Fields{"hash": hash, "halt": bc.reorg.halt.Hash}).Warning("Resumed the chain halted by a deep reorg")
+	}
+	bc.reorg.halt = nil
+	reorgHaltedGauge.Set(0)
+}
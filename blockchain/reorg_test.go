@@ -0,0 +1,85 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestMaxReorgDepth(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.MaxReorgDepth = 1
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	mint := func() *Block {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		return blk
+	}
+	// the blocks are signed as far as the consensus tells
+	bc.SetConsensus(&fakeConsensus{})
+	blk1, fork1 := mint(), mint()
+	assert.Nil(bc.AddBlockCommit(blk1))
+	blk2, fork2 := mint(), mint()
+	assert.Nil(bc.AddBlockCommit(blk2))
+	assert.Nil(bc.ReorgHalt())
+
+	// a fork as deep as the max reorg depth is checked as usual
+	assert.Nil(bc.CheckReorg(fork2))
+
+	// a stale proposal is refused without halting the chain, as well as a deeper fork off an unknown parent, not
+	// matching its merkle root, refused by the consensus or without a consensus to verify it
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(fork1)))
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.AddBlockCommit(fork1)))
+	orphan := NewBlock(bc.ChainID(), 1, cp.Hash32B{1}, fork1.Tranxs)
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.CheckReorg(orphan)))
+	forged := NewBlock(bc.ChainID(), 1, fork1.PrevHash(), fork1.Tranxs)
+	forged.Header.merkleRoot = cp.Hash32B{1}
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.CheckReorg(forged)))
+	bc.SetConsensus(&fakeConsensus{err: errors.New("unsigned")})
+	assert.Equal(ErrInvalidBlock, errors.Cause(bc.CheckReorg(fork1)))
+	bc.SetConsensus(nil)
+	assert.Equal(ErrReorgTooDeep, errors.Cause(bc.CheckReorg(fork1)))
+	assert.Nil(bc.ReorgHalt())
+	bc.SetConsensus(&fakeConsensus{})
+
+	// a deeper one verified by the consensus halts the chain
+	assert.Equal(ErrReorgTooDeep, errors.Cause(bc.CheckReorg(fork1)))
+	halt := bc.ReorgHalt()
+	assert.NotNil(halt)
+	assert.Equal(uint32(1), halt.Height)
+	assert.Equal(fork1.HashBlock(), halt.Hash)
+	assert.Equal(uint32(2), halt.Depth)
+	assert.Equal(ErrChainHalted, errors.Cause(bc.AddBlockCommit(mint())))
+	assert.Equal(ErrChainHalted, errors.Cause(bc.AddBlockSync(mint())))
+	assert.Equal(uint32(2), bc.TipHeight())
+
+	// until the fork is allowed, which the chain then switches to
+	bc.AllowReorg(fork1.HashBlock())
+	assert.Nil(bc.ReorgHalt())
+	assert.Nil(bc.CheckReorg(fork1))
+	assert.Nil(bc.RollbackToHeight(context.Background(), fork1.Height()-1))
+	assert.Nil(bc.AddBlockCommit(fork1))
+	assert.Equal(uint32(1), bc.TipHeight())
+	assert.Equal(fork1.HashBlock(), bc.TipHash())
+	assert.Nil(bc.AddBlockCommit(mint()))
+}
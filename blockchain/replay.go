@@ -0,0 +1,37 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrTxWrongChainID is the error returned when a transaction is not signed for the chain
+var ErrTxWrongChainID = errors.New("transaction is signed for another chain")
+
+// ValidateTxChainID returns error if the transaction is not signed for the chain, the ones signed without chain ID
+// are only accepted if configured to, until the strictchainid deployment is active
+func (bc *Blockchain) ValidateTxChainID(tx *Tx) error {
+	return bc.validateTxChainID(tx, bc.height+1)
+}
+
+// validateTxChainID returns error if the transaction is not signed for the chain in the block at the height
+func (bc *Blockchain) validateTxChainID(tx *Tx, height uint32) error {
+	if tx.IsCoinbase() || tx.ChainID == bc.chainID {
+		return nil
+	}
+	if tx.ChainID == 0 && bc.config.Chain.AcceptLegacyTxs {
+		strict, err := bc.IsDeploymentActive(DeploymentStrictChainID, height)
+		if err != nil {
+			return err
+		}
+		if !strict {
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrTxWrongChainID, "Tx %x is signed for chain %d, expecting %d", tx.Hash(), tx.ChainID, bc.chainID)
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+// RollbackToHeight resets the chain to the block at the height, e.g., to recover from bad blocks or to test a fork
+// The blocks above the height are removed from Db along with their indexes, and the UTXO, the account states, the
+// contracts and every index derived from the blocks are unwound, which emits a ChainReorged event once done. The
+// transactions of the removed blocks are not returned to the mempool.
+// The blocks are unwound with their undo records, which are written along with them, and the removal is committed at
+// once. If the record of one of them is missing, e.g., as the block is committed before the records are kept, the
+// chain is reindexed up to the new tip instead. The removal and the start of the reindex are committed at once, so a
+// rollback interrupted, e.g., by the error of ctx once it is done or a crash, is completed by the reindex Init resumes
+// on the next start. A pruned chain can only be rolled back while the blocks removed are not pruned, since it cannot
+// be replayed otherwise.
+func (bc *Blockchain) RollbackToHeight(ctx context.Context, height uint32) error {
+	if bc.blockDb.IsReadOnly() {
+		return blockdb.ErrReadOnly
+	}
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	if bc.stopped {
+		return errors.Wrap(ErrStopped, "Cannot roll back")
+	}
+	if height >= bc.height {
+		return errors.Errorf("Cannot roll back to height %d, which is not below the tip %d", height, bc.height)
+	}
+	if height < bc.finalHeight {
+		return errors.Wrapf(ErrFinalized, "Cannot roll back below the finalized height %d", bc.finalHeight)
+	}
+	if height+1 < bc.pruneHeight {
+		return errors.Wrapf(ErrBlockPruned, "Cannot unwind the blocks below %d to roll back", bc.pruneHeight)
+	}
+
+	oldTip, oldHeight := bc.tip, bc.height
+	su, err := bc.mergeStateUndo(height)
+	switch {
+	case err == nil:
+		if err := bc.unwind(ctx, height, su); err != nil {
+			return err
+		}
+	case errors.Cause(err) == ErrStateNotArchived:
+		if bc.pruneHeight > 0 {
+			return errors.Wrapf(ErrBlockPruned, "Cannot replay the blocks below %d to roll back", bc.pruneHeight)
+		}
+		bc.log.WithFields(logger.Fields{"height": height, "err": err}).Warning("Replaying the chain to roll back")
+		if err := bc.replayToHeight(ctx, height); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+	bc.log.WithFields(logger.Fields{"height": height, "from": oldHeight, "hash": bc.tip}).Warning("Rolled back the chain")
+
+	reorgCounter.Inc()
+	bc.updateMetrics()
+	blk, err := bc.blockByHash(bc.tip)
+	if err != nil {
+		return err
+	}
+	bc.events.publish(&BlockEvent{Type: ChainReorged, Block: blk, OldTip: oldTip})
+	return nil
+}
+
+// unwind removes the blocks above the height along with their indexes, and restores the states at the height merged
+// from their undo records, the caller has to hold commitMu
+// The Db is updated in a single batch, and the in-memory states are only restored once it is committed.
+func (bc *Blockchain) unwind(ctx context.Context, height uint32, su *stateUndo) error {
+	tip, err := bc.blockDb.GetBlockHash(height)
+	if err != nil {
+		return err
+	}
+	batch := blockdb.NewBatch()
+	removed := [][]byte{}
+	for h := height + 1; h <= bc.height; h++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hash := cp.ZeroHash32B
+		buf, err := bc.blockDb.GetBlockHash(h)
+		if err != nil {
+			return err
+		}
+		copy(hash[:], buf)
+		blk, err := bc.blockByHash(hash)
+		if err != nil {
+			return err
+		}
+		removeIndexes(batch, blk)
+		if bc.epochs.IsLastOfEpoch(h) {
+			batch.DeleteDelegates(bc.EpochOf(h + 1))
+		}
+		removed = append(removed, hash[:])
+	}
+	batch.RemoveBlocks(removed, height, tip)
+
+	if err := putUtxo(batch, su.utxo, su.coinbase); err != nil {
+		return err
+	}
+	for addr, acct := range su.accounts {
+		if acct == nil {
+			batch.DeleteAccount([]byte(addr))
+			continue
+		}
+		batch.PutAccount([]byte(addr), acct.Serialize())
+	}
+	for addr := range su.deployed {
+		batch.DeleteCode([]byte(addr))
+	}
+	for addr, storage := range su.storage {
+		for key, value := range storage {
+			if len(value) == 0 {
+				batch.DeleteState([]byte(addr), []byte(key))
+				continue
+			}
+			batch.PutState([]byte(addr), []byte(key), value)
+		}
+	}
+	batch.PutUtxoHeight(height)
+	batch.PutSupply(su.emitted, su.burned)
+	if err := bc.blockDb.Commit(batch); err != nil {
+		return err
+	}
+
+	if err := bc.Utk.applyDiff(su.utxo, su.coinbase); err != nil {
+		return err
+	}
+	bc.Utk.setSupply(su.emitted, su.burned)
+	for addr, acct := range su.accounts {
+		if acct == nil {
+			bc.sf.RemoveAccount(addr)
+			continue
+		}
+		if err := bc.sf.LoadAccount(addr, acct.Serialize()); err != nil {
+			return err
+		}
+	}
+	// the storage of the contracts removed goes along with them
+	for addr, storage := range su.storage {
+		for key, value := range storage {
+			bc.sf.LoadState(addr, []byte(key), value)
+		}
+	}
+	for addr := range su.deployed {
+		bc.sf.RemoveCode(addr)
+	}
+	copy(bc.tip[:], tip)
+	bc.height = height
+	bc.blockCache.Purge()
+	bc.hashCache.Purge()
+	bc.deployments.purge()
+	bc.fees.drop(len(removed))
+	return bc.loadStats(ctx)
+}
+
+// replayToHeight removes the blocks above the height along with their indexes, and reindexes the chain up to the new
+// tip, the caller has to hold commitMu
+func (bc *Blockchain) replayToHeight(ctx context.Context, height uint32) error {
+	hashes, err := bc.chainHashes(ctx)
+	if err != nil {
+		return err
+	}
+	removed := [][]byte{}
+	for _, hash := range hashes[height+1:] {
+		removed = append(removed, hash[:])
+	}
+	batch := blockdb.NewBatch()
+	batch.RemoveBlocks(removed, height, hashes[height][:])
+	batch.StartReindex()
+	if err := bc.blockDb.Commit(batch); err != nil {
+		return err
+	}
+	bc.tip = hashes[height]
+	bc.height = height
+	bc.deployments.purge()
+	bc.fees.drop(len(removed))
+	return bc.reindex(ctx)
+}
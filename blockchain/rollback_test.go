@@ -0,0 +1,123 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+func TestRollbackToHeight(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	hashes := []cp.Hash32B{bc.TipHash()}
+	for i := 0; i < 2; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		hashes = append(hashes, blk.HashBlock())
+	}
+	balance := bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0)
+	totalTxs := bc.ChainMeta().TotalTxs
+	commitment, err := bc.UtxoCommitment(2)
+	assert.Nil(err)
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["alfa"].Address, 10}})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+	blk, err = bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	oldTip := bc.TipHash()
+	assert.Equal(balance+10, bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+
+	assert.NotNil(bc.RollbackToHeight(context.Background(), 4))
+	events := bc.Subscribe()
+	defer bc.Unsubscribe(events)
+	assert.Nil(bc.RollbackToHeight(context.Background(), 2))
+	evt := <-events
+	assert.Equal(ChainReorged, evt.Type)
+	assert.Equal(hashes[2], evt.Block.HashBlock())
+	assert.Equal(oldTip, evt.OldTip)
+
+	// the blocks above the height are gone along with their indexes and the states derived from them
+	assert.Equal(uint32(2), bc.TipHeight())
+	assert.Equal(hashes[2], bc.TipHash())
+	_, err = bc.GetBlockByHeight(3)
+	assert.NotNil(err)
+	_, err = bc.GetHeightByHash(oldTip)
+	assert.NotNil(err)
+	_, err = bc.GetBlockMetaByHash(oldTip)
+	assert.NotNil(err)
+	txHash := tx.Hash()
+	_, err = bc.blockDb.GetTxBlockHash(txHash[:])
+	assert.Equal(blockdb.ErrNotExist, errors.Cause(err))
+	_, err = bc.UtxoCommitment(3)
+	assert.NotNil(err)
+	recomputed, err := bc.UtxoCommitment(2)
+	assert.Nil(err)
+	assert.Equal(commitment, recomputed)
+	assert.Equal(balance, bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	assert.Equal(totalTxs, bc.ChainMeta().TotalTxs)
+	assert.Nil(bc.VerifyChain(context.Background(), 0))
+
+	// the chain is extended from the new tip, so the transaction rolled back can be mined again
+	blk, err = bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+	assert.Equal(balance+10, bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+
+	// the rollback interrupted while unwinding leaves the chain untouched
+	assert.Equal(context.Canceled, errors.Cause(bc.RollbackToHeight(&countdownContext{context.Background(), 1}, 1)))
+	assert.Equal(uint32(3), bc.TipHeight())
+	assert.Equal(balance+10, bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+
+	// the chain is replayed without the undo record of a removed block, and the rollback interrupted while reindexing
+	// the 2nd block, once the blocks are found twice, completes on the next start
+	batch := blockdb.NewBatch()
+	batch.DeleteStateUndo(3)
+	assert.Nil(bc.blockDb.Commit(batch))
+	assert.Equal(context.Canceled, errors.Cause(bc.RollbackToHeight(&countdownContext{context.Background(), 7}, 1)))
+	assert.Nil(bc.Stop())
+	bc, err = CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	assert.Equal(uint32(1), bc.TipHeight())
+	assert.Equal(hashes[1], bc.TipHash())
+	_, err = bc.blockDb.GetReindexNext()
+	assert.Equal(blockdb.ErrNotExist, errors.Cause(err))
+	assert.Equal(balance, bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	assert.Nil(bc.VerifyChain(context.Background(), 0))
+
+	// the pruned blocks cannot be unwound
+	blk, err = bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.pruneHeight = 2
+	assert.Equal(ErrBlockPruned, errors.Cause(bc.RollbackToHeight(context.Background(), 0)))
+	bc.pruneHeight = 0
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+var (
+	// ErrInvalidSnapshot is the error returned when a UTXO snapshot cannot be exported or imported
+	ErrInvalidSnapshot = errors.New("invalid UTXO snapshot")
+)
+
+// ExportUtxoSnapshot returns the serialized snapshot of the UTXO set at the given height and its commitment hash
+// The UTXO set is only kept at the tip, so the height has to be the tip height
+func (bc *Blockchain) ExportUtxoSnapshot(height uint32) ([]byte, cp.Hash32B, error) {
+	if height != bc.height {
+		return nil, cp.ZeroHash32B, errors.Wrapf(ErrInvalidSnapshot, "UTXO is at tip height %d, requesting %d", bc.height, height)
+	}
+	blk, err := bc.GetBlockHeaderByHeight(height)
+	if err != nil {
+		return nil, cp.ZeroHash32B, err
+	}
+
+	snapshot := &iproto.UtxoSnapshotPb{Height: height, Header: blk.ConvertToBlockHeaderPb(), Utxo: bc.Utk.convertToUtxoMapPb()}
+	commitment, err := snapshotCommitment(snapshot)
+	if err != nil {
+		return nil, cp.ZeroHash32B, err
+	}
+	snapshot.Commitment = commitment[:]
+	buf, err := proto.Marshal(snapshot)
+	if err != nil {
+		return nil, cp.ZeroHash32B, err
+	}
+	return buf, commitment, nil
+}
+
+// ImportUtxoSnapshot starts the blockchain from the snapshot if it matches the trusted commitment
+// It is only allowed on a blockchain which has nothing but the genesis block. Afterwards the blocks on top of the
+// snapshot can be synced as usual, while the bodies of the blocks up to the snapshot are not available.
+func (bc *Blockchain) ImportUtxoSnapshot(buf []byte, commitment cp.Hash32B) error {
+	if bc.height != 0 {
+		return errors.Wrapf(ErrInvalidSnapshot, "Blockchain is already at height %d", bc.height)
+	}
+
+	snapshot := iproto.UtxoSnapshotPb{}
+	if err := proto.Unmarshal(buf, &snapshot); err != nil {
+		return errors.Wrapf(ErrInvalidSnapshot, "%v", err)
+	}
+	if snapshot.Header == nil || snapshot.Utxo == nil {
+		return errors.Wrap(ErrInvalidSnapshot, "Missing header or UTXO")
+	}
+	if snapshot.Height == 0 || snapshot.Header.Height != snapshot.Height {
+		return errors.Wrapf(ErrInvalidSnapshot, "Snapshot at height %d has header of height %d", snapshot.Height, snapshot.Header.Height)
+	}
+	if snapshot.Header.ChainID != bc.chainID {
+		return errors.Wrapf(ErrInvalidSnapshot, "Wrong chain ID %d, expecting %d", snapshot.Header.ChainID, bc.chainID)
+	}
+	hash, err := snapshotCommitment(&snapshot)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidSnapshot, "%v", err)
+	}
+	if hash != commitment || !bytes.Equal(snapshot.Commitment, commitment[:]) {
+		return errors.Wrapf(ErrInvalidSnapshot, "Commitment %x does not match trusted %x", hash, commitment)
+	}
+
+	tk := NewUtxoTracker()
+	if err := tk.convertFromUtxoMapPb(snapshot.Utxo); err != nil {
+		return errors.Wrapf(ErrInvalidSnapshot, "%v", err)
+	}
+	// the burned amount cannot be told from the UTXO set alone, so it is whatever the snapshot lacks of the emission
+	emitted := uint64(0)
+	for h := uint32(0); h <= snapshot.Height; h++ {
+		emitted += bc.emission(h)
+	}
+	if tk.circulating > emitted {
+		return errors.Wrapf(ErrInvalidSnapshot, "UTXO of %d exceeds the emitted %d", tk.circulating, emitted)
+	}
+	tk.setSupply(emitted, emitted-tk.circulating)
+	header, err := proto.Marshal(snapshot.Header)
+	if err != nil {
+		return err
+	}
+	blk := Block{}
+	blk.ConvertFromBlockHeaderPb(&iproto.BlockPb{Header: snapshot.Header})
+	blkHash := blk.HashBlock()
+
+	// the snapshot block becomes the tip, and all block bodies up to it are treated as pruned
+	batch := blockdb.NewBatch()
+	batch.PutBlockHeader(header, blkHash[:], snapshot.Height)
+	batch.ClearUtxo()
+	if err := putUtxoPool(batch, tk); err != nil {
+		return err
+	}
+	utxoCommitment := tk.Commitment()
+	batch.PutUtxoCommitment(snapshot.Height, utxoCommitment[:])
+	batch.PutUtxoHeight(snapshot.Height)
+	batch.PutSupply(tk.emitted, tk.burned)
+	batch.PutPruneHeight(snapshot.Height + 1)
+	// the transactions are counted from the snapshot on
+	batch.PutTxTotal(snapshot.Height, 0)
+	if err := bc.blockDb.Commit(batch); err != nil {
+		return err
+	}
+
+	tk.utxoPool.takeStore(bc.Utk.utxoPool)
+	bc.Utk.utxoPool = tk.utxoPool
+	bc.Utk.coinbaseHeights = tk.coinbaseHeights
+	bc.Utk.circulating = tk.circulating
+	bc.Utk.setSupply(tk.emitted, tk.burned)
+	bc.Utk.commitment = tk.commitment
+	bc.Utk.balances = tk.balances
+	bc.tip = blkHash
+	bc.height = snapshot.Height
+	bc.pruneHeight = snapshot.Height + 1
+	return bc.loadStats(context.Background())
+}
+
+// snapshotCommitment returns the hash committing to the block hash, height and UTXO set of the snapshot
+func snapshotCommitment(snapshot *iproto.UtxoSnapshotPb) (cp.Hash32B, error) {
+	utxo, err := proto.Marshal(snapshot.Utxo)
+	if err != nil {
+		return cp.ZeroHash32B, err
+	}
+	blk := Block{}
+	blk.ConvertFromBlockHeaderPb(&iproto.BlockPb{Header: snapshot.Header})
+	hash := blk.HashBlock()
+
+	stream := make([]byte, 4)
+	cm.MachineEndian.PutUint32(stream, snapshot.Height)
+	stream = append(stream, hash[:]...)
+	stream = append(stream, utxo...)
+	return blake2b.Sum256(stream), nil
+}
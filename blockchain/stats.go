@@ -0,0 +1,249 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+const (
+	// StatsWindow is the number of the most recent blocks whose transaction counts and timestamps are kept by the chain
+	// statistics
+	StatsWindow = 100
+	// MaxTopHolders is the number of the holders with the most balance kept by the chain statistics
+	MaxTopHolders = 100
+)
+
+// ChainMeta is the statistics of the chain maintained as the blocks are committed
+type ChainMeta struct {
+	Height uint32
+	// TotalTxs is the number of transactions in the blocks up to the height, coinbase included, not counting the
+	// blocks below a UTXO snapshot the chain is started from
+	TotalTxs uint64
+	// RecentTxCounts are the numbers of transactions in the most recent blocks, up to StatsWindow of them, the oldest
+	// first
+	RecentTxCounts []uint32
+	// AverageBlockInterval is the average interval between the timestamps of the most recent blocks
+	AverageBlockInterval time.Duration
+	CirculatingSupply    uint64
+	// NumHolders is the number of keys holding UTXO of a positive value
+	NumHolders uint64
+}
+
+// Holder is a key holding UTXO, along with their value
+type Holder struct {
+	// PubKeyHash is the public key hash the UTXO are locked with, which BalanceOf matches an address against
+	PubKeyHash []byte
+	Balance    uint64
+}
+
+// chainStats are the statistics of the chain as of the tip
+type chainStats struct {
+	mu          sync.RWMutex
+	height      uint32
+	circulating uint64
+	totalTxs    uint64
+	recent      []recentBlock
+	numHolders  uint64
+	topHolders  []*Holder
+}
+
+// recentBlock is the transaction count and timestamp of one of the most recent blocks
+type recentBlock struct {
+	height    uint32
+	timestamp uint64
+	txs       uint32
+}
+
+// ChainMeta returns the statistics of the chain as of the tip
+func (bc *Blockchain) ChainMeta() *ChainMeta {
+	bc.stats.mu.RLock()
+	defer bc.stats.mu.RUnlock()
+	meta := &ChainMeta{
+		Height:            bc.stats.height,
+		TotalTxs:          bc.stats.totalTxs,
+		CirculatingSupply: bc.stats.circulating,
+		NumHolders:        bc.stats.numHolders,
+	}
+	for _, blk := range bc.stats.recent {
+		meta.RecentTxCounts = append(meta.RecentTxCounts, blk.txs)
+	}
+	if n := len(bc.stats.recent); n > 1 {
+		first, last := bc.stats.recent[0], bc.stats.recent[n-1]
+		if last.timestamp > first.timestamp {
+			meta.AverageBlockInterval = time.Duration(last.timestamp-first.timestamp) * time.Second / time.Duration(n-1)
+		}
+	}
+	return meta
+}
+
+// TopHolders returns up to n of the holders with the most balance, the richest first, n is capped at MaxTopHolders
+func (bc *Blockchain) TopHolders(n uint32) []*Holder {
+	bc.stats.mu.RLock()
+	defer bc.stats.mu.RUnlock()
+	if int(n) > len(bc.stats.topHolders) {
+		n = uint32(len(bc.stats.topHolders))
+	}
+	return append([]*Holder{}, bc.stats.topHolders[:n]...)
+}
+
+// putTxTotal adds the number of transactions up to the block to the batch and returns it
+func (bc *Blockchain) putTxTotal(batch *blockdb.Batch, blk *Block) uint64 {
+	total := uint64(0)
+	switch {
+	case blk.Header.height == 0:
+	case blk.Header.height == bc.height+1:
+		bc.stats.mu.RLock()
+		total = bc.stats.totalTxs
+		bc.stats.mu.RUnlock()
+	default:
+		// the block replaces a committed one
+		total, _ = bc.blockDb.GetTxTotal(blk.Header.height - 1)
+	}
+	total += uint64(blk.Header.trnxNumber)
+	batch.PutTxTotal(blk.Header.height, total)
+	return total
+}
+
+// updateStats updates the statistics once the block with the given number of transactions up to it is committed
+func (bc *Blockchain) updateStats(blk *Block, totalTxs uint64) {
+	holders, top := bc.Utk.topHolders(MaxTopHolders)
+	bc.stats.mu.Lock()
+	defer bc.stats.mu.Unlock()
+	bc.stats.height = blk.Header.height
+	bc.stats.circulating = bc.Utk.circulating
+	bc.stats.totalTxs = totalTxs
+	// the blocks replaced by the committed one are forgotten
+	recent := bc.stats.recent[:0]
+	for _, r := range bc.stats.recent {
+		if r.height < blk.Header.height {
+			recent = append(recent, r)
+		}
+	}
+	recent = append(recent, recentBlock{height: blk.Header.height, timestamp: blk.Header.timestamp, txs: blk.Header.trnxNumber})
+	if len(recent) > StatsWindow {
+		recent = recent[len(recent)-StatsWindow:]
+	}
+	bc.stats.recent = recent
+	bc.stats.numHolders = holders
+	bc.stats.topHolders = top
+}
+
+// loadStats loads the statistics of the chain, counting the transactions in the headers of the blocks if the chain
+// was committed before they were recorded
+func (bc *Blockchain) loadStats(ctx context.Context) error {
+	total, err := bc.blockDb.GetTxTotal(bc.height)
+	if errors.Cause(err) == blockdb.ErrNotExist {
+		total, err = bc.countTxs(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	start := uint32(0)
+	if bc.height >= StatsWindow {
+		start = bc.height - StatsWindow + 1
+	}
+	var recent []recentBlock
+	for h := start; h <= bc.height; h++ {
+		blk, err := bc.GetBlockHeaderByHeight(h)
+		if err != nil {
+			// the blocks below a UTXO snapshot do not exist
+			recent = nil
+			continue
+		}
+		recent = append(recent, recentBlock{height: h, timestamp: blk.Header.timestamp, txs: blk.Header.trnxNumber})
+	}
+
+	holders, top := bc.Utk.topHolders(MaxTopHolders)
+	bc.stats.mu.Lock()
+	defer bc.stats.mu.Unlock()
+	bc.stats.height = bc.height
+	bc.stats.circulating = bc.Utk.circulating
+	bc.stats.totalTxs = total
+	bc.stats.recent = recent
+	bc.stats.numHolders = holders
+	bc.stats.topHolders = top
+	return nil
+}
+
+// countTxs counts the transactions in the headers of the blocks up to the tip and records the totals, the count
+// restarts after the blocks missing below a UTXO snapshot
+func (bc *Blockchain) countTxs(ctx context.Context) (uint64, error) {
+	bc.log.WithField("height", bc.height).Info("Counting transactions of the blocks")
+	batch := blockdb.NewBatch()
+	total := uint64(0)
+	for h := uint32(0); h <= bc.height; h++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		blk, err := bc.GetBlockHeaderByHeight(h)
+		if err != nil {
+			bc.log.WithFields(logger.Fields{"height": h, "err": err}).Debug("Missing block header")
+			total = 0
+			continue
+		}
+		total += uint64(blk.Header.trnxNumber)
+		batch.PutTxTotal(h, total)
+	}
+	if bc.blockDb.IsReadOnly() {
+		return total, nil
+	}
+	return total, bc.blockDb.Commit(batch)
+}
+
+// holderKey returns the public key hash the output is locked with, the one IsLockedWithKey matches
+func holderKey(out *TxOutput) (string, bool) {
+	if len(out.LockScript) < 23 {
+		return "", false
+	}
+	return string(out.LockScript[3:23]), true
+}
+
+// updateBalances takes the values of the old outputs off the balances of their holders and adds the values of the
+// new ones
+func (tk *UtxoTracker) updateBalances(old []*TxOutput, new []*TxOutput) {
+	for _, out := range old {
+		if key, ok := holderKey(out); ok && out.Value > 0 {
+			if tk.balances[key] -= out.Value; tk.balances[key] == 0 {
+				delete(tk.balances, key)
+			}
+		}
+	}
+	for _, out := range new {
+		if key, ok := holderKey(out); ok && out.Value > 0 {
+			tk.balances[key] += out.Value
+		}
+	}
+}
+
+// topHolders returns the number of holders in the pool and up to n of them with the most balance, the richest first
+func (tk *UtxoTracker) topHolders(n int) (uint64, []*Holder) {
+	holders := make([]*Holder, 0, len(tk.balances))
+	for key, balance := range tk.balances {
+		holders = append(holders, &Holder{PubKeyHash: []byte(key), Balance: balance})
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if holders[i].Balance != holders[j].Balance {
+			return holders[i].Balance > holders[j].Balance
+		}
+		return bytes.Compare(holders[i].PubKeyHash, holders[j].PubKeyHash) < 0
+	})
+	if len(holders) > n {
+		holders = holders[:n]
+	}
+	return uint64(len(tk.balances)), holders
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	"github.com/iotexproject/iotex-core/common/routine"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+const (
+	// storageOffloadStep is the number of block bodies offloaded at a time, each step being committed on its own
+	storageOffloadStep = uint32(1000)
+	// defaultStorageCheckInterval is the interval of the storage checks if the config leaves it 0
+	defaultStorageCheckInterval = time.Minute
+)
+
+// storageManager checks the size of the chain DB against the storage budget on each tick
+type storageManager struct {
+	bc *Blockchain
+}
+
+// Do offloads the oldest block bodies until the chain DB is within the storage budget
+func (m *storageManager) Do() {
+	if err := m.bc.enforceStorageBudget(context.Background()); err != nil {
+		m.bc.log.WithField("err", err).Error("Cannot offload block bodies")
+	}
+}
+
+// initStorage starts checking the size of the chain DB in the background if the storage budget is set and the Db is
+// not read-only
+func (bc *Blockchain) initStorage() error {
+	if bc.config.Chain.StorageBudget == 0 || bc.blockDb.IsReadOnly() {
+		return nil
+	}
+	interval := bc.config.Chain.StorageCheckInterval
+	if interval == 0 {
+		interval = defaultStorageCheckInterval
+	}
+	bc.storage = routine.NewRecurringTask(&storageManager{bc}, interval)
+	return bc.storage.Start()
+}
+
+// enforceStorageBudget offloads the oldest block bodies out of the storage retention a step at a time while the chain
+// DB exceeds the storage budget, until ctx is done
+func (bc *Blockchain) enforceStorageBudget(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		size, err := bc.blockDb.Size()
+		if err != nil {
+			return err
+		}
+		dbSizeGauge.Set(size)
+		if uint64(size) <= bc.config.Chain.StorageBudget {
+			return nil
+		}
+		done, err := bc.offloadBlocks(ctx, storageOffloadStep)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// offloadBlocks offloads the bodies of up to max blocks from the prune height on, keeping the ones of the storage
+// retention, and returns true if there is none to offload
+// In ARCHIVE mode the blocks are archived between the commits before their bodies are deleted from the Db, so they are
+// still read from the block archive, unless the chain is reorganized below the last of them meanwhile.
+func (bc *Blockchain) offloadBlocks(ctx context.Context, max uint32) (bool, error) {
+	bc.commitMu.Lock()
+	start, height, stopped := bc.pruneHeight, bc.height, bc.stopped
+	bc.commitMu.Unlock()
+	retention := bc.config.Chain.StorageRetention
+	if stopped || height < retention || start > height-retention {
+		return true, nil
+	}
+	end := height - retention
+	if end-start >= max {
+		end = start + max - 1
+	}
+	endHash, err := bc.GetHashByHeight(end)
+	if err != nil {
+		return false, err
+	}
+	if bc.config.Chain.StorageOffload != "PRUNE" {
+		if err := bc.StoreBlock(ctx, start, end); err != nil {
+			return false, err
+		}
+	}
+
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	if bc.stopped {
+		return true, nil
+	}
+	if hash, err := bc.GetHashByHeight(end); err != nil || hash != endHash || bc.pruneHeight != start {
+		// reorganized or pruned by a commit meanwhile, the next step starts over
+		return false, err
+	}
+	batch := blockdb.NewBatch()
+	if err := bc.pruneBodies(batch, start, end+1); err != nil {
+		return false, err
+	}
+	batch.PutPruneHeight(end + 1)
+	if err := bc.blockDb.Commit(batch); err != nil {
+		return false, err
+	}
+	bc.pruneHeight = end + 1
+	offloadedBlocks.Add(uint64(end - start + 1))
+	bc.log.WithFields(logger.Fields{"start": start, "end": end}).Info("Offloaded block bodies")
+	return false, nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestStorageBudget(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	dir, err := ioutil.TempDir("", "blocks")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	cfg.Chain.BlockArchiveDir = dir
+	cfg.Chain.BlockArchiveSegmentSize = 4
+	cfg.Chain.StorageRetention = 3
+	// the budget is set once the blocks are added, so the storage manager is not started
+	cfg.Chain.StorageBudget = 0
+
+	bc, err := CreateBlockchain(ctx, ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	hashes := []cp.Hash32B{bc.TipHash()}
+	for i := 0; i < 9; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+		hashes = append(hashes, blk.HashBlock())
+	}
+	size, err := bc.blockDb.Size()
+	assert.Nil(err)
+
+	// nothing is offloaded within the budget
+	cfg.Chain.StorageBudget = uint64(size)
+	assert.Nil(bc.enforceStorageBudget(ctx))
+	assert.Equal(uint32(0), bc.pruneHeight)
+
+	// the bodies out of the retention are offloaded a step at a time, and still read from the archive
+	cfg.Chain.StorageBudget = 1
+	done, err := bc.offloadBlocks(ctx, 4)
+	assert.Nil(err)
+	assert.False(done)
+	assert.Equal(uint32(4), bc.pruneHeight)
+	assert.Nil(bc.enforceStorageBudget(ctx))
+	assert.Equal(uint32(7), bc.pruneHeight)
+	done, err = bc.offloadBlocks(ctx, 4)
+	assert.Nil(err)
+	assert.True(done)
+	assert.Nil(bc.Stop())
+
+	// the prune height survives restart
+	bc, err = CreateBlockchain(ctx, ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	assert.Equal(uint32(7), bc.pruneHeight)
+	for h, hash := range hashes {
+		blk, err := bc.GetBlockByHeight(uint32(h))
+		assert.Nil(err)
+		assert.Equal(hash, blk.HashBlock())
+		blk, err = bc.GetBlockByHash(hash)
+		assert.Nil(err)
+		assert.Equal(uint32(h), blk.Height())
+	}
+	_, err = bc.blockDb.CheckOutBlock(hashes[6][:])
+	assert.NotNil(err)
+	assert.Nil(bc.Stop())
+
+	// the bodies are gone once the archive is
+	assert.Nil(os.RemoveAll(dir))
+	bc, err = CreateBlockchain(ctx, ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Stop()
+	_, err = bc.GetBlockByHeight(6)
+	assert.Equal(ErrBlockPruned, errors.Cause(err))
+	_, err = bc.GetBlockByHash(hashes[6])
+	assert.Equal(ErrBlockPruned, errors.Cause(err))
+	blk, err := bc.GetBlockByHeight(7)
+	assert.Nil(err)
+	assert.Equal(hashes[7], blk.HashBlock())
+}
+
+func TestStoragePruneOffload(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	dir, err := ioutil.TempDir("", "blocks")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	cfg.Chain.BlockArchiveDir = dir
+	cfg.Chain.StorageRetention = 2
+	cfg.Chain.StorageOffload = "PRUNE"
+
+	bc, err := CreateBlockchain(ctx, ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Stop()
+	for i := 0; i < 4; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+	cfg.Chain.StorageBudget = 1
+	assert.Nil(bc.enforceStorageBudget(ctx))
+	assert.Equal(uint32(3), bc.pruneHeight)
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(err)
+	assert.Equal(0, len(files))
+	_, err = bc.GetBlockByHeight(2)
+	assert.Equal(ErrBlockPruned, errors.Cause(err))
+	header, err := bc.GetBlockHeaderByHeight(2)
+	assert.Nil(err)
+	assert.Equal(uint32(2), header.Height())
+}
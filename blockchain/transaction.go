@@ -10,15 +10,18 @@ import (
 	"bytes"
 	"crypto/rand"
 	"fmt"
+	"time"
 
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 	"golang.org/x/crypto/blake2b"
 
 	cm "github.com/iotexproject/iotex-core/common"
 	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
 	"github.com/iotexproject/iotex-core/proto"
 	"github.com/iotexproject/iotex-core/txvm"
+	"github.com/iotexproject/iotex-core/wallet"
 )
 
 const (
@@ -39,6 +42,13 @@ const (
 	NumTxOutSizeInBytes = 4
 	//LockTimeSizeInBytes defines the size of lock time in byte units
 	LockTimeSizeInBytes = 4
+	//ExpiryHeightSizeInBytes defines the size of expiry height in byte units
+	ExpiryHeightSizeInBytes = 4
+	//ChainIDSizeInBytes defines the size of chain ID in byte units
+	ChainIDSizeInBytes = 4
+
+	// MaxTxMsgSize is the maximum size in bytes of a serialized transaction read from untrusted inputs
+	MaxTxMsgSize = 1 << 20
 )
 
 // TxInput defines the transaction input protocol buffer
@@ -61,6 +71,10 @@ type Tx struct {
 	NumTxOut uint32 // number of transaction output
 	TxOut    []*TxOutput
 	LockTime uint32 // UTXO to be locked until this time
+	// ExpiryHeight is the last block height the transaction can be included at, 0 if it never expires
+	ExpiryHeight uint32
+	// ChainID is the chain the inputs are signed for, 0 if they are signed without it before replay protection
+	ChainID uint32
 }
 
 // NewTxInput returns a TxInput instance
@@ -87,7 +101,9 @@ func NewTx(version uint32, in []*TxInput, out []*TxOutput, lockTime uint32) *Tx
 		uint32(len(in)),
 		in, uint32(len(out)),
 		out,
-		lockTime}
+		lockTime,
+		0,
+		0}
 }
 
 // Payee defines the struct of payee
@@ -103,24 +119,47 @@ func NewPayee(address string, amount uint64) *Payee {
 
 // NewCoinbaseTx creates the coinbase transaction - a special type of transaction that does not require previously outputs.
 func NewCoinbaseTx(toaddr string, amount uint64, data string) *Tx {
-	if data == "" {
-		randData := make([]byte, 20)
-		_, err := rand.Read(randData)
-		if err != nil {
-			glog.Fatal(err)
-		}
+	return NewCoinbaseTxWithPayees([]*Payee{{Address: toaddr, Amount: amount}}, coinbaseData(data))
+}
 
-		data = fmt.Sprintf("%x", randData)
+// coinbaseData returns the data of a coinbase transaction, which is random if not given
+func coinbaseData(data string) string {
+	if data != "" {
+		return data
+	}
+	randData := make([]byte, 20)
+	if _, err := rand.Read(randData); err != nil {
+		// the data only keeps the coinbase txs of the same amount to the same address apart
+		log.WithField("err", err).Warning("Cannot read random coinbase data, using the time instead")
+		cm.MachineEndian.PutUint64(randData, uint64(time.Now().UnixNano()))
+	}
+	return fmt.Sprintf("%x", randData)
+}
+
+// NewCoinbaseTxWithPayees creates a coinbase transaction paying each payee in its own output
+// It is used by the genesis block to mint the initial allocations, and by the blocks sharing their reward with the
+// delegates
+func NewCoinbaseTxWithPayees(payees []*Payee, data string) *Tx {
+	if len(payees) == 0 {
+		return nil
 	}
 
 	txin := NewTxInput(cp.ZeroHash32B, -1, []byte(data), 0xffffffff)
-	txout := CreateTxOutput(toaddr, amount)
-	return NewTx(1, []*TxInput{txin}, []*TxOutput{txout}, 0)
+	txout := []*TxOutput{}
+	for i, payee := range payees {
+		out := CreateTxOutput(payee.Address, payee.Amount)
+		if out == nil {
+			return nil
+		}
+		out.outIndex = int32(i)
+		txout = append(txout, out)
+	}
+	return NewTx(1, []*TxInput{txin}, txout, 0)
 }
 
 // IsCoinbase checks if it is a coinbase transaction by checking if Vin is empty
 func (tx *Tx) IsCoinbase() bool {
-	return len(tx.TxIn) == 1 && len(tx.TxOut) == 1 && tx.TxIn[0].OutIndex == -1 && tx.TxIn[0].Sequence == 0xffffffff &&
+	return len(tx.TxIn) == 1 && len(tx.TxOut) > 0 && tx.TxIn[0].OutIndex == -1 && tx.TxIn[0].Sequence == 0xffffffff &&
 		bytes.Compare(tx.TxIn[0].TxHash[:], cp.ZeroHash32B[:]) == 0
 }
 
@@ -137,6 +176,14 @@ func (tx *Tx) TotalSize() uint32 {
 	for _, out := range tx.TxOut {
 		size += out.TotalSize()
 	}
+
+	// the expiry height is only serialized when set, which keeps the hash of transactions without it
+	if tx.ExpiryHeight != 0 || tx.ChainID != 0 {
+		size += ExpiryHeightSizeInBytes
+	}
+	if tx.ChainID != 0 {
+		size += ChainIDSizeInBytes
+	}
 	return size
 }
 
@@ -163,10 +210,48 @@ func (tx *Tx) ByteStream() []byte {
 	}
 	cm.MachineEndian.PutUint32(temp, tx.LockTime)
 	stream = append(stream, temp...)
+	// the chain ID follows the expiry height, which is then serialized even if not set
+	if tx.ExpiryHeight != 0 || tx.ChainID != 0 {
+		cm.MachineEndian.PutUint32(temp, tx.ExpiryHeight)
+		stream = append(stream, temp...)
+	}
+	if tx.ChainID != 0 {
+		cm.MachineEndian.PutUint32(temp, tx.ChainID)
+		stream = append(stream, temp...)
+	}
 
 	return stream
 }
 
+// SigHash returns the digest signed by the unlock script of the input at index, which spends the given UTXO
+// The digest is the blake2b hash of the input index and the byte stream of the UTXO, followed by the byte stream of the
+// transaction hashed by Hash with the unlock scripts left out. The signature thus commits to the version, inputs,
+// outputs, lock time, expiry height and chain ID of the transaction in a canonical binary form, the chain ID only if it
+// is not 0, so that the transaction cannot be replayed on another chain.
+func (tx *Tx) SigHash(index int, utxo *iproto.TxOutputPb) cp.Hash32B {
+	return sigHash(tx.sigStream(), index, utxo)
+}
+
+// sigStream returns the byte stream of the transaction without the unlock scripts, which carry the signatures
+func (tx *Tx) sigStream() []byte {
+	stripped := *tx
+	stripped.TxIn = make([]*TxInput, len(tx.TxIn))
+	for i, in := range tx.TxIn {
+		stripped.TxIn[i] = &TxInput{TxHash: in.TxHash, OutIndex: in.OutIndex, Sequence: in.Sequence}
+	}
+	return stripped.ByteStream()
+}
+
+// sigHash returns the digest signed by the input at index from the byte stream of the transaction without the unlock
+// scripts, which is shared by all its inputs
+func sigHash(stream []byte, index int, utxo *iproto.TxOutputPb) cp.Hash32B {
+	preimage := make([]byte, 4)
+	cm.MachineEndian.PutUint32(preimage, uint32(index))
+	preimage = append(preimage, (&TxOutput{TxOutputPb: utxo}).ByteStream()...)
+	preimage = append(preimage, stream...)
+	return blake2b.Sum256(preimage)
+}
+
 // ConvertToTxPb creates a protobuf's Tx using type Tx
 func (tx *Tx) ConvertToTxPb() *iproto.TxPb {
 	pbOut := make([]*iproto.TxOutputPb, len(tx.TxOut))
@@ -180,7 +265,9 @@ func (tx *Tx) ConvertToTxPb() *iproto.TxPb {
 		tx.TxIn,
 		tx.NumTxOut,
 		pbOut,
-		tx.LockTime}
+		tx.LockTime,
+		tx.ExpiryHeight,
+		tx.ChainID}
 }
 
 // Serialize returns a serialized byte stream for the Tx
@@ -195,6 +282,8 @@ func (tx *Tx) ConvertFromTxPb(pbTx *iproto.TxPb) {
 	tx.NumTxIn = pbTx.GetNumTxIn()
 	tx.NumTxOut = pbTx.GetNumTxOut()
 	tx.LockTime = pbTx.GetLockTime()
+	tx.ExpiryHeight = pbTx.GetExpiryHeight()
+	tx.ChainID = pbTx.GetChainID()
 
 	tx.TxIn = nil
 	tx.TxIn = pbTx.TxIn
@@ -207,22 +296,124 @@ func (tx *Tx) ConvertFromTxPb(pbTx *iproto.TxPb) {
 }
 
 // Deserialize parse the byte stream into the Tx
+// The byte stream has to fit in MaxTxMsgSize and its counts and sizes have to match the content they describe,
+// otherwise ErrMsgTooLarge or ErrMalformedTx is returned.
 func (tx *Tx) Deserialize(buf []byte) error {
+	if len(buf) > MaxTxMsgSize {
+		return errors.Wrapf(ErrMsgTooLarge, "Tx of %d bytes exceeds the limit of %d", len(buf), MaxTxMsgSize)
+	}
 	pbTx := iproto.TxPb{}
 	if err := proto.Unmarshal(buf, &pbTx); err != nil {
-		panic(err)
+		return errors.Wrapf(ErrMalformedTx, "Cannot unmarshal tx: %v", err)
+	}
+	if err := CheckTxPb(&pbTx); err != nil {
+		return err
 	}
 
 	tx.ConvertFromTxPb(&pbTx)
 	return nil
 }
 
+// CheckTxPb returns ErrMalformedTx if the input or output count of the transaction, or the script size of one of them,
+// does not match the content, or an input does not reference a UTXO by a full hash and a valid index
+func CheckTxPb(pbTx *iproto.TxPb) error {
+	if int(pbTx.GetNumTxIn()) != len(pbTx.GetTxIn()) || int(pbTx.GetNumTxOut()) != len(pbTx.GetTxOut()) {
+		return errors.Wrapf(ErrMalformedTx, "Tx has %d inputs and %d outputs, counting %d and %d",
+			len(pbTx.GetTxIn()), len(pbTx.GetTxOut()), pbTx.GetNumTxIn(), pbTx.GetNumTxOut())
+	}
+	for i, in := range pbTx.GetTxIn() {
+		if len(in.GetTxHash()) != cp.HashSize {
+			return errors.Wrapf(ErrMalformedTx, "Input %d has a hash of %d bytes", i, len(in.GetTxHash()))
+		}
+		// -1 is the index of the input of a coinbase
+		if in.GetOutIndex() < -1 {
+			return errors.Wrapf(ErrMalformedTx, "Input %d has index %d", i, in.GetOutIndex())
+		}
+		if int(in.GetUnlockScriptSize()) != len(in.GetUnlockScript()) {
+			return errors.Wrapf(ErrMalformedTx, "Input %d has an unlock script of %d bytes, sized %d", i, len(in.GetUnlockScript()), in.GetUnlockScriptSize())
+		}
+	}
+	for i, out := range pbTx.GetTxOut() {
+		if int(out.GetLockScriptSize()) != len(out.GetLockScript()) {
+			return errors.Wrapf(ErrMalformedTx, "Output %d has a lock script of %d bytes, sized %d", i, len(out.GetLockScript()), out.GetLockScriptSize())
+		}
+	}
+	return nil
+}
+
 // Hash returns the hash of the Tx
 func (tx *Tx) Hash() cp.Hash32B {
 	hash := blake2b.Sum256(tx.ByteStream())
 	return blake2b.Sum256(hash[:])
 }
 
+// Sign signs all inputs of a raw transaction spending a single-key address with the handle of its key, e.g., a raw
+// transaction created for a watch-only account of another wallet
+func (tx *Tx) Sign(signer wallet.Signer) error {
+	unlocks := make([][]byte, len(tx.TxIn))
+	for i, in := range tx.TxIn {
+		// the unlock script of a raw transaction is the message to sign
+		sig, err := signTxIn(signer, in.UnlockScript)
+		if err != nil {
+			return err
+		}
+		unlock, err := txvm.SignatureScriptWithSig(sig, signer.PublicKey())
+		if err != nil {
+			return errors.Wrapf(ErrSigningFailed, "%v", err)
+		}
+		unlocks[i] = unlock
+	}
+
+	// only replace the unlock scripts once all inputs are signed
+	for i, in := range tx.TxIn {
+		in.UnlockScript = unlocks[i]
+		in.UnlockScriptSize = uint32(len(unlocks[i]))
+	}
+	return nil
+}
+
+// SignPartial returns the partial signatures of all inputs of a raw transaction spending a multisig address, signed
+// by the handle of one of the multisig keys
+func (tx *Tx) SignPartial(signer wallet.Signer) ([]*txvm.PartialSignature, error) {
+	sigs := []*txvm.PartialSignature{}
+	for _, in := range tx.TxIn {
+		// the unlock script of a raw transaction is the message to sign
+		sig, err := signTxIn(signer, in.UnlockScript)
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, &txvm.PartialSignature{PubKey: signer.PublicKey(), Signature: sig})
+	}
+	return sigs, nil
+}
+
+// SignMultisig signs a raw transaction spending a multisig address, by aggregating the partial signatures collected
+// from the cosigners into the unlock script of each input
+func (tx *Tx) SignMultisig(multisigKeys []byte, partials ...[]*txvm.PartialSignature) error {
+	unlocks := make([][]byte, len(tx.TxIn))
+	for i, in := range tx.TxIn {
+		sigs := []*txvm.PartialSignature{}
+		for _, partial := range partials {
+			if len(partial) != len(tx.TxIn) {
+				return fmt.Errorf("Got %d partial signatures for %d inputs", len(partial), len(tx.TxIn))
+			}
+			sigs = append(sigs, partial[i])
+		}
+		unlock, err := txvm.MultisigSignatureScript(in.UnlockScript, multisigKeys, sigs)
+		if err != nil {
+			return err
+		}
+		unlocks[i] = unlock
+	}
+
+	// only replace the unlock scripts once all inputs are signed
+	for i, in := range tx.TxIn {
+		in.UnlockScript = unlocks[i]
+		in.UnlockScriptSize = uint32(len(unlocks[i]))
+	}
+	return nil
+}
+
 //
 // below are transaction output functions
 //
@@ -233,7 +424,7 @@ func CreateTxOutput(toaddr string, value uint64) *TxOutput {
 
 	locks, err := txvm.PayToAddrScript(toaddr)
 	if err != nil {
-		glog.Error(err)
+		log.WithFields(logger.Fields{"address": toaddr, "err": err}).Error("Cannot create the lock script")
 		return nil
 	}
 	out.LockScript = locks
@@ -245,7 +436,7 @@ func CreateTxOutput(toaddr string, value uint64) *TxOutput {
 // IsLockedWithKey checks if the UTXO in output is locked with script
 func (out *TxOutput) IsLockedWithKey(lockScript []byte) bool {
 	if len(out.LockScript) < 23 {
-		glog.Error("LockScript too short")
+		log.WithField("size", len(out.LockScript)).Error("LockScript too short")
 		return false
 	}
 	// TODO: avoid hard-coded extraction of public key hash
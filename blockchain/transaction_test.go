@@ -7,13 +7,18 @@
 package blockchain
 
 import (
+	"bytes"
 	"encoding/hex"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/blake2b"
 
+	cp "github.com/iotexproject/iotex-core/crypto"
 	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/proto"
 	ta "github.com/iotexproject/iotex-core/test/testaddress"
 )
 
@@ -102,3 +107,81 @@ func TestIsLockedWithKey(t *testing.T) {
 	assert.False(t, cbtx.TxOut[0].IsLockedWithKey([]byte("tooshort")))
 	assert.True(t, cbtx.TxOut[0].IsLockedWithKey(iotxaddress.GetPubkeyHash(addr)))
 }
+
+func TestSigHash(t *testing.T) {
+	assert := assert.New(t)
+
+	hash := byteToHash(bytes.Repeat([]byte{0x11}, 32))
+	in := []*TxInput{NewTxInput(hash, 0, nil, 0), NewTxInput(hash, 1, nil, 10)}
+	out := []*TxOutput{NewTxOutput(10, 0), NewTxOutput(20, 1)}
+	out[0].LockScript = []byte{0xaa, 0xbb}
+	out[0].LockScriptSize = 2
+	tx := NewTx(1, in, out, 5)
+	tx.ChainID = 1
+	utxo := NewTxOutput(30, 0)
+	utxo.LockScript = []byte{0xcc}
+	utxo.LockScriptSize = 1
+
+	// the digest is the hash of the index and the UTXO followed by the transaction without unlock scripts
+	preimage, err := hex.DecodeString("01000000" + "1e00000000000000" + "01000000" + "cc" +
+		"01000000" + "02000000" +
+		"1111111111111111111111111111111111111111111111111111111111111111" + "00000000" + "00000000" + "00000000" +
+		"1111111111111111111111111111111111111111111111111111111111111111" + "01000000" + "00000000" + "0a000000" +
+		"02000000" + "0a00000000000000" + "02000000" + "aabb" + "1400000000000000" + "00000000" +
+		"05000000" + "00000000" + "01000000")
+	assert.Nil(err)
+	digest := cp.Hash32B(blake2b.Sum256(preimage))
+	assert.Equal(digest, tx.SigHash(1, utxo.TxOutputPb))
+	assert.Equal("d67b5a338cc063f9e97de99b8f8bbb421cef9ac4db194e89d00c795cb0796983", hex.EncodeToString(digest[:]))
+
+	// the unlock scripts are not signed, while any other field is
+	in[1].UnlockScript = []byte{0x01, 0x02}
+	in[1].UnlockScriptSize = 2
+	assert.Equal(digest, tx.SigHash(1, utxo.TxOutputPb))
+	assert.NotEqual(digest, tx.SigHash(0, utxo.TxOutputPb))
+	in[1].Sequence = 11
+	assert.NotEqual(digest, tx.SigHash(1, utxo.TxOutputPb))
+	in[1].Sequence = 10
+	out[1].Value = 21
+	assert.NotEqual(digest, tx.SigHash(1, utxo.TxOutputPb))
+	out[1].Value = 20
+	tx.LockTime = 6
+	assert.NotEqual(digest, tx.SigHash(1, utxo.TxOutputPb))
+	tx.LockTime = 5
+	tx.ChainID = 2
+	assert.NotEqual(digest, tx.SigHash(1, utxo.TxOutputPb))
+	tx.ChainID = 1
+	utxo.Value = 31
+	assert.NotEqual(digest, tx.SigHash(1, utxo.TxOutputPb))
+	utxo.Value = 30
+	assert.Equal(digest, tx.SigHash(1, utxo.TxOutputPb))
+}
+
+func TestDeserializeMalformedTx(t *testing.T) {
+	assert := assert.New(t)
+
+	hash := byteToHash(bytes.Repeat([]byte{0x11}, 32))
+	tx := NewTx(1, []*TxInput{NewTxInput(hash, 0, []byte{0x01}, 0)}, []*TxOutput{NewTxOutput(10, 0)}, 0)
+	deserialize := func(pbTx *iproto.TxPb) error {
+		buf, err := proto.Marshal(pbTx)
+		assert.Nil(err)
+		return (&Tx{}).Deserialize(buf)
+	}
+	assert.Nil(deserialize(tx.ConvertToTxPb()))
+
+	assert.Equal(ErrMsgTooLarge, errors.Cause((&Tx{}).Deserialize(make([]byte, MaxTxMsgSize+1))))
+	assert.Equal(ErrMalformedTx, errors.Cause((&Tx{}).Deserialize([]byte{0xff, 0xff, 0xff})))
+
+	for _, malform := range []func(pbTx *iproto.TxPb){
+		func(pbTx *iproto.TxPb) { pbTx.NumTxIn = 2 },
+		func(pbTx *iproto.TxPb) { pbTx.NumTxOut = 1 << 31 },
+		func(pbTx *iproto.TxPb) { pbTx.TxIn[0].TxHash = hash[:8] },
+		func(pbTx *iproto.TxPb) { pbTx.TxIn[0].OutIndex = -2 },
+		func(pbTx *iproto.TxPb) { pbTx.TxIn[0].UnlockScriptSize = 2 },
+		func(pbTx *iproto.TxPb) { pbTx.TxOut[0].LockScriptSize = 1 << 30 },
+	} {
+		pbTx := tx.ConvertToTxPb()
+		malform(pbTx)
+		assert.Equal(ErrMalformedTx, errors.Cause(deserialize(pbTx)))
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+// ErrInvalidTransfer is the error returned when a transfer is not signed by its sender
+var ErrInvalidTransfer = errors.New("invalid transfer")
+
+// Transfer moves balance between two accounts of the account-based state, it is ordered by the nonce of the sender
+// rather than spending any UTXO
+type Transfer struct {
+	Version   uint32
+	Nonce     uint64
+	Amount    uint64
+	Sender    string
+	Recipient string
+	// SenderPubKey is the public key of the sender, which signs the hash of the transfer into Signature
+	SenderPubKey []byte
+	Signature    []byte
+}
+
+// NewTransfer returns an unsigned transfer of the amount from the sender to the recipient
+func NewTransfer(nonce uint64, amount uint64, sender string, recipient string) *Transfer {
+	return &Transfer{Version: 1, Nonce: nonce, Amount: amount, Sender: sender, Recipient: recipient}
+}
+
+// ByteStream returns a raw byte stream of the transfer without the signature
+func (tsf *Transfer) ByteStream() []byte {
+	stream := make([]byte, 4)
+	cm.MachineEndian.PutUint32(stream, tsf.Version)
+
+	temp := make([]byte, 8)
+	cm.MachineEndian.PutUint64(temp, tsf.Nonce)
+	stream = append(stream, temp...)
+	cm.MachineEndian.PutUint64(temp, tsf.Amount)
+	stream = append(stream, temp...)
+	stream = append(stream, tsf.Sender...)
+	stream = append(stream, tsf.Recipient...)
+	stream = append(stream, tsf.SenderPubKey...)
+	return stream
+}
+
+// Hash returns the hash of the transfer, which is not changed by signing it
+func (tsf *Transfer) Hash() cp.Hash32B {
+	hash := blake2b.Sum256(tsf.ByteStream())
+	return blake2b.Sum256(hash[:])
+}
+
+// Sign signs the transfer with the handle of its sender
+func (tsf *Transfer) Sign(signer wallet.Signer) error {
+	if signer.Address() != tsf.Sender {
+		return errors.Wrapf(ErrSigningFailed, "Signer %s is not the sender %s", signer.Address(), tsf.Sender)
+	}
+	tsf.SenderPubKey = signer.PublicKey()
+	hash := tsf.Hash()
+	sig, err := signer.Sign(hash[:])
+	if err != nil {
+		return errors.Wrapf(ErrSigningFailed, "%v", err)
+	}
+	tsf.Signature = sig
+	return nil
+}
+
+// Verify checks the transfer is signed by the key of its sender's address
+func (tsf *Transfer) Verify() error {
+	if !iotxaddress.ValidateAddress(tsf.Recipient) {
+		return errors.Wrapf(ErrInvalidTransfer, "Invalid recipient %s", tsf.Recipient)
+	}
+	if len(tsf.SenderPubKey) != ed25519.PublicKeySize || len(tsf.Signature) != ed25519.SignatureSize {
+		return errors.Wrap(ErrInvalidTransfer, "Transfer is not signed")
+	}
+	pkHash := iotxaddress.GetPubkeyHash(tsf.Sender)
+	if pkHash == nil || !bytes.Equal(pkHash, iotxaddress.HashPubKey(tsf.SenderPubKey)) {
+		return errors.Wrapf(ErrInvalidTransfer, "Public key does not match sender %s", tsf.Sender)
+	}
+	hash := tsf.Hash()
+	if !cp.Verify(tsf.SenderPubKey, hash[:], tsf.Signature) {
+		return errors.Wrapf(ErrInvalidTransfer, "Wrong signature of transfer %x", hash)
+	}
+	return nil
+}
+
+// Kind returns the kind of the transfer action
+func (tsf *Transfer) Kind() string {
+	return TransferKind
+}
+
+// SenderNonce returns the sender of the transfer and its nonce
+func (tsf *Transfer) SenderNonce() (string, uint64) {
+	return tsf.Sender, tsf.Nonce
+}
+
+// Execute moves the amount from the sender to the recipient, consuming the nonce of the sender
+func (tsf *Transfer) Execute(ctx *ActionContext) error {
+	return ctx.WS.Transfer(tsf.Sender, tsf.Recipient, tsf.Amount, tsf.Nonce)
+}
+
+// ConvertToTransferPb creates a protobuf's Transfer using type Transfer
+func (tsf *Transfer) ConvertToTransferPb() *iproto.TransferPb {
+	return &iproto.TransferPb{
+		Version:      tsf.Version,
+		Nonce:        tsf.Nonce,
+		Amount:       tsf.Amount,
+		Sender:       tsf.Sender,
+		Recipient:    tsf.Recipient,
+		SenderPubKey: tsf.SenderPubKey,
+		Signature:    tsf.Signature,
+	}
+}
+
+// ConvertFromTransferPb converts a protobuf's Transfer back to type Transfer
+func (tsf *Transfer) ConvertFromTransferPb(pbTsf *iproto.TransferPb) {
+	tsf.Version = pbTsf.GetVersion()
+	tsf.Nonce = pbTsf.GetNonce()
+	tsf.Amount = pbTsf.GetAmount()
+	tsf.Sender = pbTsf.GetSender()
+	tsf.Recipient = pbTsf.GetRecipient()
+	tsf.SenderPubKey = pbTsf.GetSenderPubKey()
+	tsf.Signature = pbTsf.GetSignature()
+}
+
+// Serialize returns a serialized byte stream for the Transfer
+func (tsf *Transfer) Serialize() ([]byte, error) {
+	return proto.Marshal(tsf.ConvertToTransferPb())
+}
+
+// Deserialize parses the byte stream into the Transfer
+func (tsf *Transfer) Deserialize(buf []byte) error {
+	pbTsf := iproto.TransferPb{}
+	if err := proto.Unmarshal(buf, &pbTsf); err != nil {
+		return err
+	}
+	tsf.ConvertFromTransferPb(&pbTsf)
+	return nil
+}
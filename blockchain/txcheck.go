@@ -0,0 +1,50 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// ErrMalformedTx indicates the transaction fails the checks independent of the chain state
+var ErrMalformedTx = errors.New("malformed transaction")
+
+// CheckTransaction returns error if the transaction is malformed regardless of the chain state, i.e., if it is a
+// coinbase, spends or creates nothing, spends the same UTXO twice, overflows the sum of its outputs or does not fit in
+// a block
+func (bc *Blockchain) CheckTransaction(tx *Tx) error {
+	hash := tx.Hash()
+	if tx.IsCoinbase() {
+		return errors.Wrapf(ErrMalformedTx, "Tx %x is a coinbase", hash)
+	}
+	if len(tx.TxIn) == 0 || len(tx.TxOut) == 0 {
+		return errors.Wrapf(ErrMalformedTx, "Tx %x has %d inputs and %d outputs", hash, len(tx.TxIn), len(tx.TxOut))
+	}
+	spent := make(map[outpoint]bool, len(tx.TxIn))
+	for _, txIn := range tx.TxIn {
+		key := outpoint{index: txIn.OutIndex}
+		copy(key.hash[:], txIn.TxHash)
+		if spent[key] {
+			return errors.Wrapf(ErrMalformedTx, "Tx %x spends UTXO %x:%d twice", hash, txIn.TxHash, txIn.OutIndex)
+		}
+		spent[key] = true
+	}
+	total := uint64(0)
+	for _, txOut := range tx.TxOut {
+		if txOut.Value > math.MaxUint64-total {
+			return errors.Wrapf(ErrMalformedTx, "Tx %x outputs overflow", hash)
+		}
+		total += txOut.Value
+	}
+	if max := bc.config.Chain.MaxBlockSize; max > 0 && proto.Size(tx.ConvertToTxPb()) > int(max) {
+		return errors.Wrapf(ErrMalformedTx, "Tx %x is larger than the max block size %d", hash, max)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/txvm"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+// Unspent is a UTXO listed by ListUnspent
+type Unspent struct {
+	Address       string     // address the output is locked to
+	Hash          cp.Hash32B // hash of the transaction of the output
+	Index         int32      // index of the output in the transaction
+	Value         uint64
+	Height        uint32 // height of the block containing the transaction
+	Confirmations uint32
+	ScriptType    string // type of the lock script, one of the txvm script types
+	Descriptor    string // descriptor of the wallet the lock script matches, empty unless listed for a wallet
+}
+
+// ListUnspent returns the UTXO of the address confirmed by minConf to maxConf blocks, oldest first, skipping the
+// first 'offset' of them and returning at most 'limit' unless it is 0
+// A maxConf of 0 sets no upper bound on the confirmations.
+func (bc *Blockchain) ListUnspent(address string, minConf uint32, maxConf uint32, offset uint32, limit uint32) ([]*Unspent, error) {
+	if maxConf > 0 && minConf > maxConf {
+		return nil, errors.Errorf("min confirmations %d exceeds max confirmations %d", minConf, maxConf)
+	}
+
+	list := []*Unspent{}
+	key := iotxaddress.GetPubkeyHash(address)
+	var err error
+	bc.Utk.utxoPool.forEach(func(hash cp.Hash32B, txOut []*TxOutput) bool {
+		var height, confirmations uint32
+		for _, out := range txOut {
+			if !out.IsLockedWithKey(key) {
+				continue
+			}
+			if confirmations == 0 {
+				if height, err = bc.txHeight(hash); err != nil {
+					err = errors.Wrapf(err, "Cannot find the block of UTXO %x", hash)
+					return false
+				}
+				confirmations = bc.height - height + 1
+			}
+			if confirmations < minConf || (maxConf > 0 && confirmations > maxConf) {
+				break
+			}
+			list = append(list, &Unspent{
+				Address:       address,
+				Hash:          hash,
+				Index:         out.outIndex,
+				Value:         out.Value,
+				Height:        height,
+				Confirmations: confirmations,
+				ScriptType:    txvm.ScriptType(out.LockScript),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortUnspent(list)
+	if offset >= uint32(len(list)) {
+		return []*Unspent{}, nil
+	}
+	list = list[offset:]
+	if limit > 0 && limit < uint32(len(list)) {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// ListWalletUnspent returns the UTXO locked by the descriptors of the wallet, which include the accounts of the
+// wallet and its watch-only ones, confirmed by minConf to maxConf blocks, oldest first, see ListUnspent
+// An output is recognized if its lock script is exactly the one of a descriptor. The address of the UTXO of a HTLC is
+// empty, their descriptor telling the contract.
+func (bc *Blockchain) ListWalletUnspent(w *wallet.Wallet, minConf uint32, maxConf uint32) ([]*Unspent, error) {
+	if maxConf > 0 && minConf > maxConf {
+		return nil, errors.Errorf("min confirmations %d exceeds max confirmations %d", minConf, maxConf)
+	}
+	scripts, err := walletLockScripts(w)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*Unspent{}
+	bc.Utk.utxoPool.forEach(func(hash cp.Hash32B, txOut []*TxOutput) bool {
+		var height, confirmations uint32
+		for _, out := range txOut {
+			d, ok := scripts[string(out.LockScript)]
+			if !ok {
+				continue
+			}
+			if confirmations == 0 {
+				if height, err = bc.txHeight(hash); err != nil {
+					err = errors.Wrapf(err, "Cannot find the block of UTXO %x", hash)
+					return false
+				}
+				confirmations = bc.height - height + 1
+			}
+			if confirmations < minConf || (maxConf > 0 && confirmations > maxConf) {
+				break
+			}
+			list = append(list, &Unspent{
+				Address:       d.Address,
+				Hash:          hash,
+				Index:         out.outIndex,
+				Value:         out.Value,
+				Height:        height,
+				Confirmations: confirmations,
+				ScriptType:    txvm.ScriptType(out.LockScript),
+				Descriptor:    d.String(),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortUnspent(list)
+	return list, nil
+}
+
+// WalletBalances returns the balances of the descriptors of the wallet confirmed by at least minConfirmations blocks,
+// by their address, or by the descriptor itself for a HTLC, which pays to no address
+func (bc *Blockchain) WalletBalances(w *wallet.Wallet, minConfirmations uint32) (map[string]uint64, error) {
+	descs, err := w.Descriptors()
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]uint64, len(descs))
+	for _, d := range descs {
+		balances[balanceKey(d.Address, d.String())] = 0
+	}
+	unspent, err := bc.ListWalletUnspent(w, minConfirmations, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, utxo := range unspent {
+		balances[balanceKey(utxo.Address, utxo.Descriptor)] += utxo.Value
+	}
+	return balances, nil
+}
+
+// SignWalletTransaction signs the inputs of the transaction spending the UTXO locked by the descriptors of the wallet
+// with the keys of its unlocked accounts, the preimage redeems the UTXO of a HTLC, which is refunded to the sender
+// without it
+// The inputs spending other UTXO are left untouched, and ErrSigningFailed is returned without signing any input if
+// the unlocked accounts cannot unlock one of the UTXO of the wallet.
+func (bc *Blockchain) SignWalletTransaction(tx *Tx, w *wallet.Wallet, preimage []byte) error {
+	scripts, err := walletLockScripts(w)
+	if err != nil {
+		return err
+	}
+	signers := w.Signers()
+	stream := tx.sigStream()
+	unlocks := make(map[int][]byte)
+	for i, in := range tx.TxIn {
+		utxo := bc.Utk.TxInputUtxo(in)
+		if utxo == nil {
+			return errors.Wrapf(ErrSigningFailed, "Tx spends unknown UTXO %x:%d", in.TxHash, in.OutIndex)
+		}
+		d, ok := scripts[string(utxo.LockScript)]
+		if !ok {
+			continue
+		}
+		hash := sigHash(stream, i, utxo.TxOutputPb)
+		sigs := make([]*txvm.PartialSignature, 0, len(signers))
+		for _, signer := range signers {
+			sig, err := signTxIn(signer, hash[:])
+			if err != nil {
+				return err
+			}
+			sigs = append(sigs, &txvm.PartialSignature{PubKey: signer.PublicKey(), Signature: sig})
+		}
+		if unlocks[i], err = d.UnlockScript(hash[:], sigs, preimage); err != nil {
+			return errors.Wrapf(ErrSigningFailed, "Input %d spending %s: %v", i, d, err)
+		}
+	}
+
+	// only replace the unlock scripts once all inputs are signed
+	for i, unlock := range unlocks {
+		tx.TxIn[i].UnlockScript = unlock
+		tx.TxIn[i].UnlockScriptSize = uint32(len(unlock))
+	}
+	return nil
+}
+
+// walletLockScripts returns the descriptors of the wallet by their lock scripts
+func walletLockScripts(w *wallet.Wallet) (map[string]*wallet.Descriptor, error) {
+	descs, err := w.Descriptors()
+	if err != nil {
+		return nil, err
+	}
+	scripts := make(map[string]*wallet.Descriptor, len(descs))
+	for _, d := range descs {
+		lockScript, err := d.LockScript()
+		if err != nil {
+			return nil, err
+		}
+		scripts[string(lockScript)] = d
+	}
+	return scripts, nil
+}
+
+// balanceKey returns the key of the balance of a descriptor, its address unless it pays to none
+func balanceKey(address string, descriptor string) string {
+	if address != "" {
+		return address
+	}
+	return descriptor
+}
+
+// sortUnspent sorts the UTXO by the height of their blocks, then by the hash of their transactions and their index
+func sortUnspent(list []*Unspent) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Height != list[j].Height {
+			return list[i].Height < list[j].Height
+		}
+		if c := bytes.Compare(list[i].Hash[:], list[j].Hash[:]); c != 0 {
+			return c < 0
+		}
+		return list[i].Index < list[j].Index
+	})
+}
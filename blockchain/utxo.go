@@ -8,6 +8,13 @@ package blockchain
 
 import (
 	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
 
 	cp "github.com/iotexproject/iotex-core/crypto"
 	"github.com/iotexproject/iotex-core/iotxaddress"
@@ -24,15 +31,144 @@ type UtxoEntry struct {
 	outIndex int32 // outIndex is needed when spending UTXO
 }
 
+// TxHash returns the hash of the transaction creating the UTXO
+func (u *UtxoEntry) TxHash() cp.Hash32B {
+	return u.txHash
+}
+
+// OutIndex returns the index of the UTXO among the outputs of its transaction
+func (u *UtxoEntry) OutIndex() int32 {
+	return u.outIndex
+}
+
 // UtxoTracker tracks the active UTXO pool
 type UtxoTracker struct {
 	currOutIndex int32 // newly created output index
-	utxoPool     map[cp.Hash32B][]*TxOutput
+	utxoPool     *utxoSet
+
+	// coinbaseHeights keeps the height of the block minting each coinbase entry in the pool
+	coinbaseHeights map[cp.Hash32B]uint32
+	// coinbaseMaturity is the number of confirmations before coinbase outputs can be spent
+	coinbaseMaturity uint32
+	// verifyWorkers is the number of workers running the input scripts of a block, 0 for one per CPU
+	verifyWorkers int
+	// scripts caches the keys of the script checks passed, see verifyScripts
+	scripts *lruCache
+
+	// circulating is the sum of the values in the pool, emitted is the amount minted by the emission schedule of the
+	// blocks applied to the pool, and burned is the part of the emitted amount and fees not paid to any coinbase
+	// circulating + burned == emitted holds as long as the pool is consistent
+	circulating uint64
+	emitted     uint64
+	burned      uint64
+
+	// commitment is the rolling hash of the entries in the pool, see utxoEntryStream
+	commitment *cp.MultisetHash
+	// balances are the sums of the values in the pool by the key they are locked with, see holderKey
+	balances map[string]uint64
+
+	// reserved keeps the UTXO spent by in-flight transactions and when their reservation expires, a zero time never
+	// expires
+	reservedMu sync.Mutex
+	reserved   map[outpoint]time.Time
+}
+
+// outpoint identifies a UTXO by the hash of its transaction and its output index
+type outpoint struct {
+	hash  cp.Hash32B
+	index int32
 }
 
 // NewUtxoTracker returns a UTXO tracker instance
 func NewUtxoTracker() *UtxoTracker {
-	return &UtxoTracker{0, map[cp.Hash32B][]*TxOutput{}}
+	return &UtxoTracker{
+		utxoPool:        newUtxoSet(),
+		coinbaseHeights: map[cp.Hash32B]uint32{},
+		reserved:        map[outpoint]time.Time{},
+		commitment:      cp.NewMultisetHash(),
+		balances:        map[string]uint64{},
+		scripts:         newLRUCache(0),
+	}
+}
+
+// ReserveUtxo reserves the UTXO for an in-flight transaction so that it is not selected again, the reservation is
+// released after ttl unless it is 0
+func (tk *UtxoTracker) ReserveUtxo(hash cp.Hash32B, index int32, ttl time.Duration) {
+	tk.reservedMu.Lock()
+	defer tk.reservedMu.Unlock()
+	expiry := time.Time{}
+	if ttl > 0 {
+		expiry = time.Now().Add(ttl)
+	}
+	tk.reserved[outpoint{hash, index}] = expiry
+}
+
+// ReleaseUtxo releases the reservation of the UTXO
+func (tk *UtxoTracker) ReleaseUtxo(hash cp.Hash32B, index int32) {
+	tk.reservedMu.Lock()
+	defer tk.reservedMu.Unlock()
+	delete(tk.reserved, outpoint{hash, index})
+}
+
+// IsReserved returns true if the UTXO is reserved by an in-flight transaction
+func (tk *UtxoTracker) IsReserved(hash cp.Hash32B, index int32) bool {
+	tk.reservedMu.Lock()
+	defer tk.reservedMu.Unlock()
+	return tk.isReserved(outpoint{hash, index}, time.Now())
+}
+
+// isReserved checks the reservation of the UTXO and drops it if it has expired, the caller has to hold reservedMu
+func (tk *UtxoTracker) isReserved(op outpoint, now time.Time) bool {
+	expiry, ok := tk.reserved[op]
+	if !ok {
+		return false
+	}
+	if !expiry.IsZero() && now.After(expiry) {
+		delete(tk.reserved, op)
+		return false
+	}
+	return true
+}
+
+// SetColdStore spills the UTXO entries written the least recently to the store once more than maxHot of them are in
+// memory, 0 to keep all of them in memory
+func (tk *UtxoTracker) SetColdStore(store UtxoStore, maxHot int) {
+	if maxHot <= 0 {
+		store = nil
+	}
+	tk.utxoPool.setStore(store, maxHot)
+}
+
+// SetCoinbaseMaturity sets the number of confirmations before coinbase outputs can be spent
+func (tk *UtxoTracker) SetCoinbaseMaturity(maturity uint32) {
+	tk.coinbaseMaturity = maturity
+}
+
+// SetVerifyWorkers sets the number of workers running the input scripts of a block, 0 for one per CPU
+func (tk *UtxoTracker) SetVerifyWorkers(workers int) {
+	tk.verifyWorkers = workers
+}
+
+// SetScriptCacheSize sets the number of script checks passed whose keys are cached, 0 to verify every script
+func (tk *UtxoTracker) SetScriptCacheSize(size int) {
+	tk.scripts = newLRUCache(size)
+}
+
+// SetCoinbaseHeight records the entry of hash as a coinbase minted at the given height
+func (tk *UtxoTracker) SetCoinbaseHeight(hash cp.Hash32B, height uint32) {
+	tk.coinbaseHeights[hash] = height
+}
+
+// CoinbaseHeight returns the height of the block minting the coinbase entry, false if the entry is not a coinbase
+func (tk *UtxoTracker) CoinbaseHeight(hash cp.Hash32B) (uint32, bool) {
+	height, ok := tk.coinbaseHeights[hash]
+	return height, ok
+}
+
+// ValidateCoinbaseMaturity returns error if the transaction spends a coinbase output that is not yet mature at the
+// given height, i.e., it is minted less than coinbaseMaturity blocks ago
+func (tk *UtxoTracker) ValidateCoinbaseMaturity(tx *Tx, height uint32) error {
+	return tk.NewView().ValidateCoinbaseMaturity(tx, height)
 }
 
 // UtxoEntries returns list of UTXO entries containing >= requested amount, and
@@ -43,8 +179,7 @@ func (tk *UtxoTracker) UtxoEntries(address string, reqamount uint64) ([]*UtxoEnt
 	key := iotxaddress.GetPubkeyHash(address)
 	hasEnoughFund := false
 
-found:
-	for hash, txOut := range tk.utxoPool {
+	tk.utxoPool.forEach(func(hash cp.Hash32B, txOut []*TxOutput) bool {
 		for _, out := range txOut {
 			if out.IsLockedWithKey(key) {
 				utxo := UtxoEntry{out.TxOutputPb, hash, out.outIndex}
@@ -54,11 +189,12 @@ found:
 				if reqamount <= balance {
 					balance -= reqamount
 					hasEnoughFund = true
-					break found
+					return false
 				}
 			}
 		}
-	}
+		return true
+	})
 
 	if hasEnoughFund {
 		return list, balance
@@ -66,6 +202,42 @@ found:
 	return nil, balance
 }
 
+// AllUtxoEntries returns all UTXO entries spendable by the address, except those reserved by in-flight transactions
+func (tk *UtxoTracker) AllUtxoEntries(address string) []*UtxoEntry {
+	tk.reservedMu.Lock()
+	defer tk.reservedMu.Unlock()
+	now := time.Now()
+	list := []*UtxoEntry{}
+	key := iotxaddress.GetPubkeyHash(address)
+	tk.utxoPool.forEach(func(hash cp.Hash32B, txOut []*TxOutput) bool {
+		for _, out := range txOut {
+			if out.IsLockedWithKey(key) && !tk.isReserved(outpoint{hash, out.outIndex}, now) {
+				list = append(list, &UtxoEntry{out.TxOutputPb, hash, out.outIndex})
+			}
+		}
+		return true
+	})
+	return list
+}
+
+// Iterate calls fn on every UTXO of the pool, ordered by transaction hash then output index, until fn returns error or
+// ctx is done
+// Unlike GetPool, the pool is not exposed to fn, but it must not be updated until Iterate returns.
+func (tk *UtxoTracker) Iterate(ctx context.Context, fn func(entry *UtxoEntry) error) error {
+	for _, hash := range tk.utxoPool.sortedHashes() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		utxo, _ := tk.utxoPool.get(hash)
+		for _, out := range utxo {
+			if err := fn(&UtxoEntry{out.TxOutputPb, hash, out.outIndex}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // CreateTxInputUtxo returns a UTXO transaction input
 func (tk *UtxoTracker) CreateTxInputUtxo(hash cp.Hash32B, index int32, unlockScript []byte) *TxInput {
 	return NewTxInput(hash, index, unlockScript, 0)
@@ -87,12 +259,13 @@ func (tk *UtxoTracker) CreateTxOutputUtxo(address string, amount uint64) *TxOutp
 	return out
 }
 
-// ValidateTxInputUtxo validates the UTXO in transaction input
+// ValidateTxInputUtxo validates the UTXO in the transaction input at index
 // return amount of UTXO if pass, 0 otherwise
-func (tk *UtxoTracker) ValidateTxInputUtxo(txIn *TxInput) uint64 {
+func (tk *UtxoTracker) ValidateTxInputUtxo(tx *Tx, index int) uint64 {
+	txIn := tx.TxIn[index]
 	hash := cp.ZeroHash32B
 	copy(hash[:], txIn.TxHash)
-	unspent, exist := tk.utxoPool[hash]
+	unspent, exist := tk.utxoPool.get(hash)
 
 	// if hash does not exist in UTXO pool, it is spoof/fraudulent spending
 	if !exist {
@@ -101,9 +274,12 @@ func (tk *UtxoTracker) ValidateTxInputUtxo(txIn *TxInput) uint64 {
 	}
 
 	// check transaction input, including unlock script can pass authentication
-
+	// the unlock script carries the signature of the digest of the transaction and the UTXO being spent
 	for _, utxo := range unspent {
-		if utxo.outIndex == txIn.OutIndex && txIn.UnlockSuccess(utxo.LockScript) {
+		if utxo.outIndex != txIn.OutIndex {
+			continue
+		}
+		if digest := tx.SigHash(index, utxo.TxOutputPb); txIn.UnlockSuccess(digest[:], utxo.LockScript) {
 			return utxo.Value
 		}
 	}
@@ -111,41 +287,43 @@ func (tk *UtxoTracker) ValidateTxInputUtxo(txIn *TxInput) uint64 {
 	return 0
 }
 
-// ValidateUtxo validates all UTXO in the block
-func (tk *UtxoTracker) ValidateUtxo(blk *Block) error {
-	// iterate thru all transactions of this block
-	for _, tx := range blk.Tranxs {
-		txHash := tx.Hash()
+// TxInputUtxo returns the UTXO spent by the transaction input, nil if it is not in the pool
+func (tk *UtxoTracker) TxInputUtxo(txIn *TxInput) *TxOutput {
+	return tk.NewView().TxInputUtxo(txIn)
+}
 
-		// coinbase has 1 output which becomes UTXO
-		if tx.IsCoinbase() {
-			continue
-		}
+// ValidateUtxo validates all UTXO in the block, running the input scripts in parallel
+func (tk *UtxoTracker) ValidateUtxo(blk *Block) error {
+	checks := []*scriptCheck{}
+	if _, _, err := tk.NewView().connectTxs(blk, &checks); err != nil {
+		return err
+	}
+	return verifyScripts(checks, tk.verifyWorkers, tk.scripts)
+}
 
-		credit := uint64(0)
-		for _, txIn := range tx.TxIn {
-			// verify UTXO before they can be spent
-			amount := tk.ValidateTxInputUtxo(txIn)
-			if amount == 0 {
-				return fmt.Errorf("Cannot validate UTXO %x", txIn.TxHash)
-			}
+// TxFee returns the fee of a transaction, which is the sum of its inputs minus the sum of its outputs
+// coinbase transaction does not pay fee
+func (tk *UtxoTracker) TxFee(tx *Tx) (uint64, error) {
+	return tk.NewView().TxFee(tx)
+}
 
-			// sum up all UTXO
-			credit += uint64(amount)
+// totalFee returns the sum of fees paid by the given transactions, which can spend the outputs of the earlier ones
+func (tk *UtxoTracker) totalFee(txs []*Tx) (uint64, error) {
+	fees := uint64(0)
+	view := tk.NewView()
+	for _, tx := range txs {
+		fee, err := view.TxFee(tx)
+		if err != nil {
+			return 0, err
 		}
-
-		debit := uint64(0)
-		for _, txOut := range tx.TxOut {
-			debit += uint64(txOut.Value)
+		if fees, err = addValue(fees, fee); err != nil {
+			return 0, err
 		}
-
-		// make sure we have enough fund to spend
-		if credit < debit {
-			return fmt.Errorf("Tx %x does not have enough UTXO to spend", txHash)
+		if err := view.connectTx(tx); err != nil {
+			return 0, err
 		}
 	}
-
-	return nil
+	return fees, nil
 }
 
 // Reset reset the out index
@@ -154,80 +332,259 @@ func (tk *UtxoTracker) Reset() {
 	tk.currOutIndex = 0
 }
 
-// UpdateUtxoPool updates the UTXO pool according to transactions in the block
-func (tk *UtxoTracker) UpdateUtxoPool(blk *Block) error {
-	// iterate thru all transactions of this block
-	for _, tx := range blk.Tranxs {
-		txHash := tx.Hash()
+// UpdateUtxoPool updates the UTXO pool according to transactions in the block, which emits 'reward'
+// It returns error and leaves the pool untouched if the block spends missing UTXO or breaks the total supply
+// invariant.
+func (tk *UtxoTracker) UpdateUtxoPool(blk *Block, reward uint64) error {
+	view := tk.NewView()
+	if err := view.ConnectBlock(blk, reward); err != nil {
+		return err
+	}
+	if err := tk.ApplyView(view); err != nil {
+		return err
+	}
+	tk.releaseSpentUtxo(blk)
+	return nil
+}
 
-		// coinbase has 1 output which becomes UTXO
-		if tx.IsCoinbase() {
-			tk.utxoPool[txHash] = []*TxOutput{tx.TxOut[0]}
-			continue
-		}
+// setSupply sets the emitted and burned amounts of the pool
+func (tk *UtxoTracker) setSupply(emitted uint64, burned uint64) {
+	tk.emitted = emitted
+	tk.burned = burned
+}
+
+// verifySupply returns error if the pool breaks the total supply invariant
+func (tk *UtxoTracker) verifySupply() error {
+	if total, err := addValue(tk.circulating, tk.burned); err != nil || total != tk.emitted {
+		return fmt.Errorf("Circulating %d plus burned %d does not match emitted %d", tk.circulating, tk.burned, tk.emitted)
+	}
+	return nil
+}
+
+// clearPool empties the pool and its supply
+func (tk *UtxoTracker) clearPool() {
+	tk.utxoPool.clear()
+	tk.coinbaseHeights = map[cp.Hash32B]uint32{}
+	tk.circulating = 0
+	tk.setSupply(0, 0)
+	tk.commitment = cp.NewMultisetHash()
+	tk.balances = map[string]uint64{}
+}
 
-		// add new TxOutput into pool
-		utxo := []*TxOutput{}
-		for _, txOut := range tx.TxOut {
-			utxo = append(utxo, txOut)
+// utxoValue returns the sum of the values of the outputs, or ErrSupplyInvariant if it overflows
+func utxoValue(utxo []*TxOutput) (uint64, error) {
+	value := uint64(0)
+	for _, out := range utxo {
+		var err error
+		if value, err = addValue(value, out.Value); err != nil {
+			return 0, err
 		}
-		tk.utxoPool[txHash] = utxo
+	}
+	return value, nil
+}
 
-		// remove TxInput from pool
+// addValue returns the sum of the amounts, or ErrSupplyInvariant if it overflows
+func addValue(a uint64, b uint64) (uint64, error) {
+	if b > math.MaxUint64-a {
+		return 0, errors.Wrapf(ErrSupplyInvariant, "Sum of %d and %d overflows", a, b)
+	}
+	return a + b, nil
+}
+
+// replaceValue returns the sum of the values in a pool once the entry 'old' is replaced by 'utxo', or
+// ErrSupplyInvariant if it overflows or the entry is worth more than the pool
+func replaceValue(total uint64, old []*TxOutput, utxo []*TxOutput) (uint64, error) {
+	oldValue, err := utxoValue(old)
+	if err != nil {
+		return 0, err
+	}
+	value, err := utxoValue(utxo)
+	if err != nil {
+		return 0, err
+	}
+	if oldValue > total {
+		return 0, errors.Wrapf(ErrSupplyInvariant, "Entry of %d exceeds the pool of %d", oldValue, total)
+	}
+	return addValue(total-oldValue, value)
+}
+
+// releaseSpentUtxo drops the reservations of the UTXO spent by the block, which are no longer needed
+func (tk *UtxoTracker) releaseSpentUtxo(blk *Block) {
+	tk.reservedMu.Lock()
+	defer tk.reservedMu.Unlock()
+	for _, tx := range blk.Tranxs {
+		if tx.IsCoinbase() {
+			continue
+		}
 		for _, txIn := range tx.TxIn {
 			hash := cp.ZeroHash32B
 			copy(hash[:], txIn.TxHash)
-			unspent, _ := tk.utxoPool[hash]
-
-			if len(unspent) == 1 {
-				// this is the only UTXO so remove this entry
-				delete(tk.utxoPool, hash)
-			} else {
-				// remove this UTXO from the entry
-				newUnspent := []*TxOutput{}
-				for _, entry := range unspent {
-					if entry.outIndex != txIn.OutIndex {
-						newUnspent = append(newUnspent, entry)
-					}
-				}
-				tk.utxoPool[hash] = newUnspent
-			}
+			delete(tk.reserved, outpoint{hash, txIn.OutIndex})
 		}
 	}
+}
 
+// applyDiff applies the UTXO entries changed in a view and their coinbase heights to the pool, a nil entry removing it
+// It returns ErrSupplyInvariant and leaves the pool untouched if the values of the pool would overflow.
+func (tk *UtxoTracker) applyDiff(diff map[cp.Hash32B][]*TxOutput, coinbase map[cp.Hash32B]uint32) error {
+	circulating := tk.circulating
+	for hash, utxo := range diff {
+		old, _ := tk.utxoPool.get(hash)
+		var err error
+		if circulating, err = replaceValue(circulating, old, utxo); err != nil {
+			return err
+		}
+	}
+	tk.circulating = circulating
+	tk.commitment = tk.commitmentAfter(diff, coinbase)
+	for hash, utxo := range diff {
+		old, _ := tk.utxoPool.get(hash)
+		tk.updateBalances(old, utxo)
+		if utxo == nil {
+			tk.utxoPool.remove(hash)
+			delete(tk.coinbaseHeights, hash)
+			continue
+		}
+		tk.utxoPool.put(hash, utxo)
+	}
+	for hash, height := range coinbase {
+		tk.coinbaseHeights[hash] = height
+	}
+	return nil
+}
+
+// serializeUtxoEntry returns the serialized unspent outputs of a transaction
+// coinbase holds the minting height of the entry if it is a coinbase
+func serializeUtxoEntry(hash cp.Hash32B, utxo []*TxOutput, coinbase map[cp.Hash32B]uint32) ([]byte, error) {
+	return proto.Marshal(convertToUtxoEntryPb(hash, utxo, coinbase))
+}
+
+// convertToUtxoEntryPb converts the unspent outputs of a transaction to protobuf's UTXO entry
+func convertToUtxoEntryPb(hash cp.Hash32B, utxo []*TxOutput, coinbase map[cp.Hash32B]uint32) *iproto.UtxoEntryPb {
+	entry := &iproto.UtxoEntryPb{Hash: hash[:]}
+	entry.Height, entry.Coinbase = coinbase[hash]
+	for _, out := range utxo {
+		entry.Utxo = append(entry.Utxo, &iproto.UtxoPb{
+			Value:          out.Value,
+			Index:          out.outIndex,
+			LockScriptSize: out.LockScriptSize,
+			LockScript:     out.LockScript,
+		})
+	}
+	return entry
+}
+
+// loadUtxoEntry parses the byte stream into the unspent outputs of a transaction and adds them to the pool
+func (tk *UtxoTracker) loadUtxoEntry(buf []byte) error {
+	entry := iproto.UtxoEntryPb{}
+	if err := proto.Unmarshal(buf, &entry); err != nil {
+		return err
+	}
+	return tk.addUtxoEntryPb(&entry)
+}
+
+// addUtxoEntryPb adds the unspent outputs of a protobuf's UTXO entry to the pool, or returns ErrSupplyInvariant if the
+// values of the pool overflow
+func (tk *UtxoTracker) addUtxoEntryPb(entry *iproto.UtxoEntryPb) error {
+	hash, utxo := convertFromUtxoEntryPb(entry)
+	old, ok := tk.utxoPool.get(hash)
+	circulating, err := replaceValue(tk.circulating, old, utxo)
+	if err != nil {
+		return err
+	}
+	if ok {
+		tk.commitment.Remove(utxoEntryStream(hash, old, tk.coinbaseHeights))
+	}
+	tk.circulating = circulating
+	tk.updateBalances(old, utxo)
+	tk.utxoPool.put(hash, utxo)
+	if entry.Coinbase {
+		tk.coinbaseHeights[hash] = entry.Height
+	}
+	tk.commitment.Add(utxoEntryStream(hash, utxo, tk.coinbaseHeights))
 	return nil
 }
 
+// convertFromUtxoEntryPb converts protobuf's UTXO entry to the hash of the transaction and its unspent outputs
+func convertFromUtxoEntryPb(entry *iproto.UtxoEntryPb) (cp.Hash32B, []*TxOutput) {
+	hash := cp.ZeroHash32B
+	copy(hash[:], entry.Hash)
+
+	utxo := []*TxOutput{}
+	for _, out := range entry.Utxo {
+		txOut := &iproto.TxOutputPb{Value: out.Value, LockScriptSize: out.LockScriptSize, LockScript: out.LockScript}
+		utxo = append(utxo, &TxOutput{txOut, out.Index})
+	}
+	return hash, utxo
+}
+
 // ConvertToUtxoPb creates a protobuf's UTXO
 func (tx *Tx) ConvertToUtxoPb() *iproto.UtxoMapPb {
 	return nil
 }
 
-// Serialize returns a serialized byte stream for the Tx
+// Serialize returns a serialized byte stream of the UTXO pool
 func (tk *UtxoTracker) Serialize() ([]byte, error) {
-	return nil, nil
+	return proto.Marshal(tk.convertToUtxoMapPb())
 }
 
-// Deserialize parse the byte stream into the Tx
+// Deserialize parse the byte stream into the UTXO pool, replacing the current one
 func (tk *UtxoTracker) Deserialize(buf []byte) error {
+	utxoMap := iproto.UtxoMapPb{}
+	if err := proto.Unmarshal(buf, &utxoMap); err != nil {
+		return err
+	}
+	return tk.convertFromUtxoMapPb(&utxoMap)
+}
+
+// convertToUtxoMapPb converts the UTXO pool to protobuf's UTXO map, the entries are sorted by hash so the result is
+// deterministic
+func (tk *UtxoTracker) convertToUtxoMapPb() *iproto.UtxoMapPb {
+	utxoMap := &iproto.UtxoMapPb{}
+	for _, hash := range tk.utxoPool.sortedHashes() {
+		utxo, _ := tk.utxoPool.get(hash)
+		utxoMap.UtxoEntry = append(utxoMap.UtxoEntry, convertToUtxoEntryPb(hash, utxo, tk.coinbaseHeights))
+	}
+	return utxoMap
+}
+
+// convertFromUtxoMapPb replaces the UTXO pool with protobuf's UTXO map
+func (tk *UtxoTracker) convertFromUtxoMapPb(utxoMap *iproto.UtxoMapPb) error {
+	tk.clearPool()
+	for _, entry := range utxoMap.UtxoEntry {
+		if err := tk.addUtxoEntryPb(entry); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// GetPool returns the UTXO pool
+// GetPool returns a copy of the UTXO pool, which decodes every entry of the pool
 func (tk *UtxoTracker) GetPool() map[cp.Hash32B][]*TxOutput {
-	return tk.utxoPool
+	pool := make(map[cp.Hash32B][]*TxOutput, tk.utxoPool.Len())
+	tk.utxoPool.forEach(func(hash cp.Hash32B, utxo []*TxOutput) bool {
+		pool[hash] = utxo
+		return true
+	})
+	return pool
 }
 
-// AddTx is called by TxPool to add a transaction
-func (tk *UtxoTracker) AddTx(tx *Tx, height uint32) {
+// AddTx is called by TxPool to add a transaction, it returns ErrSupplyInvariant and leaves the pool untouched if the
+// outputs overflow the values of the pool
+func (tk *UtxoTracker) AddTx(tx *Tx, height uint32) error {
 	hash := tx.Hash()
-	outputs, exists := tk.utxoPool[hash]
-	if !exists {
-		outputs = []*TxOutput{}
+	old, exists := tk.utxoPool.get(hash)
+	outputs := append(append([]*TxOutput{}, old...), tx.TxOut...)
+	circulating, err := replaceValue(tk.circulating, old, outputs)
+	if err != nil {
+		return err
 	}
-	for _, out := range tx.TxOut {
-		// check script lock
-		outputs = append(outputs, out)
+	if exists {
+		tk.commitment.Remove(utxoEntryStream(hash, old, tk.coinbaseHeights))
 	}
-	tk.utxoPool[hash] = outputs
+	tk.circulating = circulating
+	tk.updateBalances(nil, tx.TxOut)
+	tk.utxoPool.put(hash, outputs)
+	tk.commitment.Add(utxoEntryStream(hash, outputs, tk.coinbaseHeights))
+	return nil
 }
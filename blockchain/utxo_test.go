@@ -7,14 +7,24 @@
 package blockchain
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"math"
 	"os"
+	"runtime"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 
 	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
 	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/txvm"
+	"github.com/iotexproject/iotex-core/wallet"
 )
 
 func TestUTXO(t *testing.T) {
@@ -22,8 +32,9 @@ func TestUTXO(t *testing.T) {
 
 	// create chain
 	totalSupply := uint64(100000000)
-	bc := CreateBlockchain(ta.Addrinfo["miner"].Address,
-		&config.Config{Chain: config.Chain{ChainDBPath: testDBPath, TotalSupply: totalSupply}})
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address,
+		&config.Config{Chain: config.Chain{ChainDBPath: testDBPath, TotalSupply: totalSupply, UtxoCacheSize: 2}})
+	assert.Nil(t, err)
 	assert.NotNil(t, bc)
 	fmt.Println("Create blockchain pass")
 
@@ -33,30 +44,30 @@ func TestUTXO(t *testing.T) {
 	assert.Nil(t, addTestingBlocks(bc))
 
 	// check all UTXO
-	total := bc.BalanceOf(ta.Addrinfo["alfa"].Address)
+	total := bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0)
 	fmt.Printf("Alfa balance = %d\n", total)
 
-	beta := bc.BalanceOf(ta.Addrinfo["bravo"].Address)
+	beta := bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0)
 	fmt.Printf("Bravo balance = %d\n", beta)
 	total += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["charlie"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["charlie"].Address, 0)
 	fmt.Printf("Charlie balance = %d\n", beta)
 	total += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["delta"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["delta"].Address, 0)
 	fmt.Printf("Delta balance = %d\n", beta)
 	total += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["echo"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["echo"].Address, 0)
 	fmt.Printf("Echo balance = %d\n", beta)
 	total += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["foxtrot"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["foxtrot"].Address, 0)
 	fmt.Printf("Foxtrot balance = %d\n", beta)
 	total += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["miner"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["miner"].Address, 0)
 	fmt.Printf("test balance = %d\n", beta)
 	utxo, _ := bc.Utk.UtxoEntries(ta.Addrinfo["miner"].Address, beta)
 	assert.NotNil(t, utxo)
@@ -152,3 +163,377 @@ func VerifySol(x, y, z float64, sol *map[float64]bool) bool {
 
 	return false
 }
+
+func TestSelectCoins(t *testing.T) {
+	assert := assert.New(t)
+
+	candidates := []*UtxoEntry{}
+	for i, value := range []uint64{5, 1, 20, 2, 10} {
+		candidates = append(candidates, &UtxoEntry{&iproto.TxOutputPb{Value: value}, byteToHash([]byte{byte(i)}), 0})
+	}
+	values := func(utxo []*UtxoEntry) []uint64 {
+		list := []uint64{}
+		for _, u := range utxo {
+			list = append(list, u.Value)
+		}
+		return list
+	}
+
+	// spent as given without an algorithm
+	utxo, change := SelectCoins(candidates, 6, "", 0, 0)
+	assert.Equal([]uint64{5, 1}, values(utxo))
+	assert.Equal(uint64(0), change)
+
+	utxo, change = SelectCoins(candidates, 12, LargestFirst, 0, 0)
+	assert.Equal([]uint64{20}, values(utxo))
+	assert.Equal(uint64(8), change)
+
+	// branch and bound finds the exact match, or leaves only dust
+	utxo, change = SelectCoins(candidates, 12, BranchAndBound, 0, 0)
+	assert.Equal([]uint64{10, 2}, values(utxo))
+	assert.Equal(uint64(0), change)
+	utxo, change = SelectCoins(candidates, 14, BranchAndBound, 2, 0)
+	assert.Equal([]uint64{10, 5}, values(utxo))
+	assert.Equal(uint64(1), change)
+	// and falls back to largest first
+	utxo, change = SelectCoins(candidates, 34, BranchAndBound, 0, 0)
+	assert.Equal([]uint64{20, 10, 5}, values(utxo))
+	assert.Equal(uint64(1), change)
+
+	for i := 0; i < 20; i++ {
+		utxo, change = SelectCoins(candidates, 3, RandomImprove, 0, 2)
+		assert.True(len(utxo) > 0 && len(utxo) <= 2)
+		total := uint64(0)
+		for _, u := range utxo {
+			total += u.Value
+		}
+		assert.Equal(total-3, change)
+	}
+
+	// limit the number of inputs
+	utxo, _ = SelectCoins(candidates, 36, LargestFirst, 0, 3)
+	assert.Nil(utxo)
+	utxo, _ = SelectCoins(candidates, 36, LargestFirst, 0, 4)
+	assert.Equal([]uint64{20, 10, 5, 2}, values(utxo))
+	utxo, _ = SelectCoins(candidates, 36, RandomImprove, 0, 3)
+	assert.Nil(utxo)
+	utxo, _ = SelectCoins(candidates, 39, BranchAndBound, 0, 0)
+	assert.Nil(utxo)
+}
+
+func TestUtxoTrackerIterate(t *testing.T) {
+	assert := assert.New(t)
+
+	tk := NewUtxoTracker()
+	txs := []*Tx{
+		NewCoinbaseTxWithPayees([]*Payee{{ta.Addrinfo["alfa"].Address, 10}, {ta.Addrinfo["bravo"].Address, 20}}, "1"),
+		NewCoinbaseTx(ta.Addrinfo["charlie"].Address, 30, "2"),
+	}
+	for _, tx := range txs {
+		assert.Nil(tk.AddTx(tx, 0))
+	}
+
+	// the entries are ordered by hash then index
+	entries := []*UtxoEntry{}
+	assert.Nil(tk.Iterate(context.Background(), func(entry *UtxoEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	if assert.Equal(3, len(entries)) {
+		first := 0
+		if h0, h1 := txs[0].Hash(), txs[1].Hash(); bytes.Compare(h0[:], h1[:]) > 0 {
+			first = 1
+		}
+		assert.Equal(txs[first].Hash(), entries[0].TxHash())
+		assert.Equal(txs[1-first].Hash(), entries[len(entries)-1].TxHash())
+		for _, entry := range entries {
+			if entry.TxHash() == txs[0].Hash() {
+				assert.Equal(uint64(10*(entry.OutIndex()+1)), entry.Value)
+			}
+		}
+	}
+
+	// the error of fn stops the iteration
+	stop := errors.New("stop")
+	count := 0
+	assert.Equal(stop, tk.Iterate(context.Background(), func(entry *UtxoEntry) error {
+		count++
+		return stop
+	}))
+	assert.Equal(1, count)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(context.Canceled, tk.Iterate(ctx, func(entry *UtxoEntry) error {
+		return nil
+	}))
+}
+
+func TestUtxoView(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address,
+		&config.Config{Chain: config.Chain{ChainDBPath: testDBPath, TotalSupply: uint64(100000000)}})
+	assert.Nil(err)
+	defer bc.Close()
+
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["alfa"].Address, 10}})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	commitment := bc.Utk.Commitment()
+
+	// connecting the block to a view leaves the pool untouched
+	view := bc.NewUtxoView()
+	assert.Nil(view.ConnectBlock(blk, bc.emission(1)))
+	assert.Equal(commitment, bc.Utk.Commitment())
+	assert.NotEqual(commitment, view.Commitment())
+	assert.Nil(view.TxInputUtxo(tx.TxIn[0]))
+	assert.NotNil(bc.Utk.TxInputUtxo(tx.TxIn[0]))
+	assert.Equal(tx.TxOut, view.Entry(tx.Hash()))
+	assert.Nil(bc.Utk.NewView().Entry(tx.Hash()))
+
+	// the inputs spent in the view cannot be spent again
+	_, err = view.TxFee(tx)
+	assert.NotNil(err)
+
+	// the outputs of an unconfirmed transaction are only added to the view
+	other := bc.NewUtxoView()
+	assert.Nil(other.AddTx(tx))
+	assert.Equal(tx.TxOut[0], other.TxInputUtxo(NewTxInput(tx.Hash(), 0, nil, 0)))
+	assert.NotNil(other.TxInputUtxo(tx.TxIn[0]))
+	assert.Nil(view.TxInputUtxo(NewTxInput(tx.Hash(), 1<<20, nil, 0)))
+
+	// committing the block applies the view
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(view.Commitment(), bc.Utk.Commitment())
+	assert.Equal(uint64(10), bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+
+	// a block spending the same UTXO twice is rejected
+	spend := []*TxInput{NewTxInput(tx.Hash(), 0, nil, 0)}
+	double := NewBlock(bc.chainID, 2, bc.TipHash(), []*Tx{
+		NewCoinbaseTx(ta.Addrinfo["miner"].Address, bc.emission(2), ""),
+		NewTx(1, spend, []*TxOutput{NewTxOutput(5, 0)}, 0),
+		NewTx(1, spend, []*TxOutput{NewTxOutput(4, 0)}, 0),
+	})
+	assert.NotNil(bc.NewUtxoView().ConnectBlock(double, bc.emission(2)))
+	assert.Equal(view.Commitment(), bc.Utk.Commitment())
+
+	// nor is a block whose outputs wrap around to the value of its inputs
+	overflow := NewBlock(bc.chainID, 2, bc.TipHash(), []*Tx{
+		NewCoinbaseTx(ta.Addrinfo["miner"].Address, bc.emission(2), ""),
+		NewTx(1, spend, []*TxOutput{NewTxOutput(math.MaxUint64, 0), NewTxOutput(11, 1)}, 0),
+	})
+	assert.Equal(ErrSupplyInvariant, errors.Cause(bc.NewUtxoView().ConnectBlock(overflow, bc.emission(2))))
+	_, err = bc.Utk.totalFee(overflow.Tranxs)
+	assert.Equal(ErrSupplyInvariant, errors.Cause(err))
+}
+
+// testUtxoStore is the UtxoStore kept in a map
+type testUtxoStore map[string][]byte
+
+func (s testUtxoStore) Get(txHash []byte) ([]byte, error) {
+	entry, ok := s[string(txHash)]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return entry, nil
+}
+
+func (s testUtxoStore) Write(put map[string][]byte, del [][]byte) error {
+	for _, txHash := range del {
+		delete(s, string(txHash))
+	}
+	for txHash, entry := range put {
+		s[txHash] = entry
+	}
+	return nil
+}
+
+func TestPackUtxo(t *testing.T) {
+	assert := assert.New(t)
+
+	p2pkh, err := txvm.PayToAddrScript(ta.Addrinfo["alfa"].Address)
+	assert.Nil(err)
+	htlc, err := txvm.HTLCScript(ta.Addrinfo["alfa"].Address, ta.Addrinfo["bravo"].Address, make([]byte, 32), 10)
+	assert.Nil(err)
+	schnorr := append(append([]byte{}, p2pkh[:24]...), txvm.OpCheckSchnorrSig)
+	utxo := []*TxOutput{
+		{&iproto.TxOutputPb{Value: 1, LockScriptSize: uint32(len(p2pkh)), LockScript: p2pkh}, 0},
+		{&iproto.TxOutputPb{Value: 1 << 40, LockScriptSize: uint32(len(htlc)), LockScript: htlc}, 2},
+		{&iproto.TxOutputPb{Value: 3, LockScriptSize: uint32(len(schnorr)), LockScript: schnorr}, 3},
+		{&iproto.TxOutputPb{Value: 0}, 1 << 20},
+	}
+
+	// a pay-to-address script is reduced to its key hash
+	buf := packUtxo(utxo[:1])
+	assert.Equal(1+1+1+1+20, len(buf))
+	for _, outputs := range [][]*TxOutput{utxo, {}} {
+		unpacked, err := unpackUtxo(packUtxo(outputs))
+		assert.Nil(err)
+		assert.Equal(outputs, unpacked)
+	}
+
+	// malformed entries are rejected
+	buf = packUtxo(utxo)
+	for _, malformed := range [][]byte{nil, buf[:len(buf)-1], append(buf, 0), {1, 0, 0, 9}, {200}} {
+		_, err := unpackUtxo(malformed)
+		assert.NotNil(err)
+	}
+}
+
+func TestUtxoSetSpill(t *testing.T) {
+	assert := assert.New(t)
+
+	store := testUtxoStore{}
+	tk := NewUtxoTracker()
+	tk.SetColdStore(store, 4)
+	txs := []*Tx{}
+	for i := 0; i < 8; i++ {
+		tx := NewCoinbaseTx(ta.Addrinfo["alfa"].Address, uint64(i+1), fmt.Sprint(i))
+		txs = append(txs, tx)
+		assert.Nil(tk.AddTx(tx, 0))
+	}
+	commitment := tk.Commitment()
+
+	// the oldest entries are spilled once more than 4 are in memory
+	assert.Equal(8, tk.utxoPool.Len())
+	assert.True(len(tk.utxoPool.hot) <= 4)
+	assert.Equal(len(tk.utxoPool.cold), len(store))
+	_, cold := tk.utxoPool.cold[txs[0].Hash()]
+	assert.True(cold)
+	_, cold = tk.utxoPool.cold[txs[7].Hash()]
+	assert.False(cold)
+	_, balance := tk.UtxoEntries(ta.Addrinfo["alfa"].Address, math.MaxUint64)
+	assert.Equal(uint64(36), balance)
+	assert.Equal(txs[0].TxOut, tk.GetPool()[txs[0].Hash()])
+
+	// a spilled entry written again is back in memory, and its copy is deleted from the store by the next spill
+	view := tk.NewView()
+	assert.Nil(view.setEntry(txs[0].Hash(), nil))
+	assert.Nil(tk.ApplyView(view))
+	assert.Equal(7, tk.utxoPool.Len())
+	assert.False(tk.utxoPool.has(txs[0].Hash()))
+	assert.Nil(tk.AddTx(NewCoinbaseTx(ta.Addrinfo["bravo"].Address, 100, "8"), 0))
+	assert.Nil(tk.AddTx(NewCoinbaseTx(ta.Addrinfo["bravo"].Address, 100, "9"), 0))
+	hash := txs[0].Hash()
+	_, stored := store[string(hash[:])]
+	assert.False(stored)
+	assert.Equal(len(tk.utxoPool.cold), len(store))
+
+	// the pool is the same as if it were kept in memory
+	mem := NewUtxoTracker()
+	for _, tx := range txs {
+		assert.Nil(mem.AddTx(tx, 0))
+	}
+	assert.Equal(commitment, mem.Commitment())
+	assert.Nil(mem.ApplyView(view))
+	assert.Nil(mem.AddTx(NewCoinbaseTx(ta.Addrinfo["bravo"].Address, 100, "8"), 0))
+	assert.Nil(mem.AddTx(NewCoinbaseTx(ta.Addrinfo["bravo"].Address, 100, "9"), 0))
+	assert.Equal(mem.Commitment(), tk.Commitment())
+	assert.Equal(mem.GetPool(), tk.GetPool())
+	assert.Nil(verifyUtxoPool(mem, tk))
+
+	// clearing the pool leaves the spilled entries to be deleted by the next spill
+	tk.clearPool()
+	assert.Equal(0, tk.utxoPool.Len())
+	assert.Equal(len(store), len(tk.utxoPool.stale))
+}
+
+// benchmarkUtxoPool adds b.N transactions with a pay-to-address output each to the pool by put, and reports the heap
+// the pool retains per entry
+func benchmarkUtxoPool(b *testing.B, put func(hash cp.Hash32B, utxo []*TxOutput)) {
+	script, err := txvm.PayToAddrScript(ta.Addrinfo["alfa"].Address)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var hash cp.Hash32B
+		binary.BigEndian.PutUint64(hash[:], uint64(i))
+		lock := append([]byte{}, script...)
+		put(hash, []*TxOutput{{&iproto.TxOutputPb{Value: uint64(i), LockScriptSize: uint32(len(lock)), LockScript: lock}, 0}})
+	}
+	b.StopTimer()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(put)
+	b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/float64(b.N), "B/entry")
+}
+
+func BenchmarkUtxoPoolMap(b *testing.B) {
+	pool := map[cp.Hash32B][]*TxOutput{}
+	benchmarkUtxoPool(b, func(hash cp.Hash32B, utxo []*TxOutput) {
+		pool[hash] = utxo
+	})
+}
+
+func BenchmarkUtxoSet(b *testing.B) {
+	set := newUtxoSet()
+	benchmarkUtxoPool(b, set.put)
+}
+
+func TestUtxoSetCompact(t *testing.T) {
+	assert := assert.New(t)
+
+	store := testUtxoStore{}
+	set := newUtxoSet()
+	set.setStore(store, minUtxoCompaction)
+	hashes := []cp.Hash32B{}
+	for i := 0; i < 2*minUtxoCompaction; i++ {
+		var hash cp.Hash32B
+		binary.BigEndian.PutUint64(hash[:], uint64(i))
+		hashes = append(hashes, hash)
+		set.put(hash, []*TxOutput{NewTxOutput(uint64(i), 0)})
+	}
+	assert.NotEqual(0, len(set.cold))
+
+	// nothing to compact until more than half of the entries are removed
+	c := set.compact(100)
+	assert.Equal(utxoCompaction{done: true}, c)
+	for _, hash := range hashes[:minUtxoCompaction+1] {
+		set.remove(hash)
+	}
+	remaining := minUtxoCompaction - 1
+	assert.Equal(remaining, set.Len())
+
+	// the compaction moves the entries a step at a time, and deletes the spent entries from the store
+	c = set.compact(100)
+	assert.True(c.started)
+	assert.Equal(minUtxoCompaction+1, c.reclaimed)
+	assert.Equal(100, c.moved)
+	assert.False(c.done)
+	assert.NotEqual(0, c.deleted)
+	assert.Equal(len(set.cold)+len(set.oldCold), len(store))
+	assert.Equal(remaining, set.Len())
+
+	// the entries can be read and written while being compacted
+	utxo, ok := set.get(hashes[len(hashes)-1])
+	assert.True(ok)
+	assert.Equal(uint64(len(hashes)-1), utxo[0].Value)
+	set.remove(hashes[len(hashes)-1])
+	set.put(hashes[0], []*TxOutput{NewTxOutput(1, 0)})
+	assert.Equal(remaining, set.Len())
+
+	steps := 1
+	for !set.compact(100).done {
+		steps++
+	}
+	assert.True(steps <= (remaining+99)/100)
+	assert.Nil(set.oldHot)
+	assert.Nil(set.oldCold)
+	assert.Equal(remaining, set.Len())
+	assert.Equal(remaining, set.peak)
+	for _, hash := range hashes[minUtxoCompaction+1 : len(hashes)-1] {
+		assert.True(set.has(hash))
+	}
+	utxo, ok = set.get(hashes[0])
+	assert.True(ok)
+	assert.Equal(uint64(1), utxo[0].Value)
+	assert.True(set.compact(100).done)
+	assert.Equal(len(set.cold), len(store))
+}
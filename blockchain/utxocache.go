@@ -0,0 +1,61 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"github.com/iotexproject/iotex-core/common/routine"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+// utxoCompactStep is the number of entries a step of the UTXO compaction moves
+const utxoCompactStep = 10000
+
+// utxoCompactor runs a step of the UTXO compaction on each tick
+type utxoCompactor struct {
+	bc *Blockchain
+}
+
+// Do runs a step of the UTXO compaction
+func (c *utxoCompactor) Do() {
+	c.bc.compactUtxo(utxoCompactStep)
+}
+
+// initUtxoCache spills the UTXO pool beyond the cache size of the config to the Db unless it is read-only, and starts
+// compacting the pool in the background if the compaction interval is set
+func (bc *Blockchain) initUtxoCache() error {
+	if bc.config.Chain.UtxoCacheSize > 0 && !bc.blockDb.IsReadOnly() {
+		store, err := bc.blockDb.ColdUtxos()
+		if err != nil {
+			return err
+		}
+		bc.Utk.SetColdStore(store, bc.config.Chain.UtxoCacheSize)
+	}
+	if bc.config.Chain.UtxoCompactInterval > 0 {
+		bc.compactor = routine.NewRecurringTask(&utxoCompactor{bc}, bc.config.Chain.UtxoCompactInterval)
+		return bc.compactor.Start()
+	}
+	return nil
+}
+
+// compactUtxo runs a step of the UTXO compaction moving up to max entries between the commits, and returns true once
+// the compaction is done
+func (bc *Blockchain) compactUtxo(max int) bool {
+	bc.commitMu.Lock()
+	defer bc.commitMu.Unlock()
+	if bc.stopped {
+		return true
+	}
+	c := bc.Utk.utxoPool.compact(max)
+	if c.started {
+		bc.log.WithFields(logger.Fields{"entries": bc.Utk.utxoPool.Len(), "reclaimed": c.reclaimed}).Info("Compacting UTXO pool")
+		utxoCompactions.Inc()
+		utxoReclaimedEntries.Add(uint64(c.reclaimed))
+	}
+	utxoCompactedEntries.Add(uint64(c.moved))
+	utxoColdDeleted.Add(uint64(c.deleted))
+	bc.updateMetrics()
+	return c.done
+}
@@ -0,0 +1,462 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/txvm"
+)
+
+// UtxoStore is the store the UTXO entries least recently written are spilled to once too many of them are in memory,
+// keyed by transaction hash, see UtxoTracker.SetColdStore
+type UtxoStore interface {
+	// Get returns the entry of the transaction hash
+	Get(txHash []byte) ([]byte, error)
+	// Write deletes the entries of the hashes in del then puts the entries in put at once
+	Write(put map[string][]byte, del [][]byte) error
+}
+
+// kinds of the lock scripts in the compact encoding of the UTXO, the pay-to-address scripts are reduced to the hash
+// of the key they are locked with
+const (
+	rawScript byte = iota
+	payToKeyHash
+	payToMultisigHash
+	payToSchnorrKeyHash
+)
+
+// payToAddrScriptLen is the length of the pay-to-address scripts, see txvm.PayToAddrScript
+const payToAddrScriptLen = 25
+
+// minUtxoCompaction is the number of entries the set has to have held before it is compacted
+const minUtxoCompaction = 1024
+
+// utxoSet is the UTXO pool by transaction hash, where the unspent outputs of each transaction are kept in the compact
+// encoding of packUtxo rather than as protobuf objects
+// Once a store is set and more than maxHot entries are in memory, the entries written the least recently are spilled
+// to it, and read back from it on access. Reading never moves an entry, hence the set can be read concurrently as
+// long as it is not written.
+type utxoSet struct {
+	hot  map[cp.Hash32B]packedUtxo
+	cold map[cp.Hash32B]struct{}
+	// oldHot and oldCold are the maps a compaction moves the entries out of, see compact
+	oldHot  map[cp.Hash32B]packedUtxo
+	oldCold map[cp.Hash32B]struct{}
+	// peak is the most entries the maps have held since they were created
+	peak int
+	// stale are the hashes of the spilled entries removed from the set, deleted from the store by the next spill or
+	// compaction
+	stale [][]byte
+	// seq orders the writes of the entries, the oldest ones being spilled first
+	seq uint64
+
+	store  UtxoStore
+	maxHot int
+}
+
+// packedUtxo is an entry of the set in memory
+type packedUtxo struct {
+	buf []byte
+	seq uint64
+}
+
+func newUtxoSet() *utxoSet {
+	return &utxoSet{
+		hot:  map[cp.Hash32B]packedUtxo{},
+		cold: map[cp.Hash32B]struct{}{},
+	}
+}
+
+// Len returns the number of transactions with unspent outputs in the set
+func (s *utxoSet) Len() int {
+	return len(s.hot) + len(s.oldHot) + len(s.cold) + len(s.oldCold)
+}
+
+// has returns true if the transaction has an entry in the set
+func (s *utxoSet) has(hash cp.Hash32B) bool {
+	_, ok := s.hotEntry(hash)
+	return ok || s.isCold(hash)
+}
+
+// hotEntry returns the entry of the transaction in memory
+func (s *utxoSet) hotEntry(hash cp.Hash32B) (packedUtxo, bool) {
+	if entry, ok := s.hot[hash]; ok {
+		return entry, true
+	}
+	entry, ok := s.oldHot[hash]
+	return entry, ok
+}
+
+// isCold returns true if the entry of the transaction is spilled to the store
+func (s *utxoSet) isCold(hash cp.Hash32B) bool {
+	if _, ok := s.cold[hash]; ok {
+		return true
+	}
+	_, ok := s.oldCold[hash]
+	return ok
+}
+
+// get returns the unspent outputs of the transaction, decoded afresh so the caller may modify them, and false if the
+// transaction has no entry
+func (s *utxoSet) get(hash cp.Hash32B) ([]*TxOutput, bool) {
+	var buf []byte
+	if entry, ok := s.hotEntry(hash); ok {
+		buf = entry.buf
+	} else if s.isCold(hash) {
+		var err error
+		if buf, err = s.store.Get(hash[:]); err != nil {
+			log.WithFields(logger.Fields{"tx": hash, "err": err}).Error("Cannot read cold UTXO")
+			return nil, false
+		}
+	} else {
+		return nil, false
+	}
+	utxo, err := unpackUtxo(buf)
+	if err != nil {
+		log.WithFields(logger.Fields{"tx": hash, "err": err}).Error("Cannot decode UTXO")
+		return nil, false
+	}
+	return utxo, true
+}
+
+// put sets the unspent outputs of the transaction, spilling the oldest entries if too many are in memory
+func (s *utxoSet) put(hash cp.Hash32B, utxo []*TxOutput) {
+	s.dropCold(hash)
+	delete(s.oldHot, hash)
+	s.seq++
+	s.hot[hash] = packedUtxo{packUtxo(utxo), s.seq}
+	if n := s.Len(); n > s.peak {
+		s.peak = n
+	}
+	if s.store != nil && len(s.hot) > s.maxHot {
+		s.spill()
+	}
+}
+
+// remove removes the entry of the transaction
+func (s *utxoSet) remove(hash cp.Hash32B) {
+	s.dropCold(hash)
+	delete(s.hot, hash)
+	delete(s.oldHot, hash)
+}
+
+// dropCold drops the spilled copy of the entry of the transaction
+func (s *utxoSet) dropCold(hash cp.Hash32B) {
+	if s.isCold(hash) {
+		delete(s.cold, hash)
+		delete(s.oldCold, hash)
+		s.stale = append(s.stale, append([]byte{}, hash[:]...))
+	}
+}
+
+// clear removes all entries, keeping the store
+func (s *utxoSet) clear() {
+	for hash := range s.cold {
+		s.dropCold(hash)
+	}
+	for hash := range s.oldCold {
+		s.dropCold(hash)
+	}
+	s.hot = map[cp.Hash32B]packedUtxo{}
+	s.cold = map[cp.Hash32B]struct{}{}
+	s.oldHot, s.oldCold = nil, nil
+	s.peak = 0
+}
+
+// forEach calls fn with every entry of the set in no particular order until it returns false
+func (s *utxoSet) forEach(fn func(hash cp.Hash32B, utxo []*TxOutput) bool) {
+	for _, hash := range s.hashes() {
+		if utxo, ok := s.get(hash); ok && !fn(hash, utxo) {
+			return
+		}
+	}
+}
+
+// hashes returns the hashes of the transactions with entries in no particular order
+func (s *utxoSet) hashes() []cp.Hash32B {
+	hashes := make([]cp.Hash32B, 0, s.Len())
+	for hash := range s.hot {
+		hashes = append(hashes, hash)
+	}
+	for hash := range s.oldHot {
+		hashes = append(hashes, hash)
+	}
+	for hash := range s.cold {
+		hashes = append(hashes, hash)
+	}
+	for hash := range s.oldCold {
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+// sortedHashes returns the hashes of the transactions with entries, in byte order
+func (s *utxoSet) sortedHashes() []cp.Hash32B {
+	hashes := s.hashes()
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+	return hashes
+}
+
+// setStore sets the store the set spills to beyond maxHot entries in memory, and spills them right away if needed
+// The entries spilled to the previous store are kept, hence the store can only be set on an empty set or moved from
+// the set being replaced, see takeStore.
+func (s *utxoSet) setStore(store UtxoStore, maxHot int) {
+	s.store = store
+	s.maxHot = maxHot
+	if s.store != nil && len(s.hot) > s.maxHot {
+		s.spill()
+	}
+}
+
+// takeStore moves the store of the set being replaced by s to s, the entries spilled by the old set becoming stale
+func (s *utxoSet) takeStore(old *utxoSet) {
+	old.clear()
+	s.stale = append(s.stale, old.stale...)
+	s.setStore(old.store, old.maxHot)
+	old.store = nil
+}
+
+// spill writes the entries written the least recently to the store until a quarter of maxHot is free, and deletes
+// the stale entries from it
+// The entries stay in memory if the store cannot be written.
+func (s *utxoSet) spill() {
+	// the entries being compacted are the oldest, so they are moved back first to be spilled along with the others
+	for hash, entry := range s.oldHot {
+		s.hot[hash] = entry
+	}
+	s.oldHot = nil
+	target := s.maxHot - s.maxHot/4
+	hashes := make([]cp.Hash32B, 0, len(s.hot))
+	for hash := range s.hot {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return s.hot[hashes[i]].seq < s.hot[hashes[j]].seq
+	})
+	hashes = hashes[:len(hashes)-target]
+
+	put := make(map[string][]byte, len(hashes))
+	for _, hash := range hashes {
+		put[string(hash[:])] = s.hot[hash].buf
+	}
+	if err := s.store.Write(put, s.stale); err != nil {
+		log.WithFields(logger.Fields{"entries": len(hashes), "err": err}).Error("Cannot spill UTXO")
+		return
+	}
+	s.stale = nil
+	for _, hash := range hashes {
+		delete(s.hot, hash)
+		s.cold[hash] = struct{}{}
+	}
+}
+
+// utxoCompaction is the outcome of a step of compaction
+type utxoCompaction struct {
+	// started is set if the step starts a compaction, which reclaims the memory of 'reclaimed' removed entries
+	started   bool
+	reclaimed int
+	// moved is the number of entries moved to the compacted maps, and done is set once all entries are moved
+	moved int
+	done  bool
+	// deleted is the number of stale entries deleted from the store
+	deleted int
+}
+
+// compact runs a step of compaction, which moves up to max entries to the compacted maps and deletes the stale entries
+// from the store
+// As a Go map never shrinks, a compaction starts once less than half of the peak number of entries remain by moving
+// them to new maps a step at a time, after which the old maps are released. The entries are read from both the new and
+// the old maps until the compaction is done.
+func (s *utxoSet) compact(max int) utxoCompaction {
+	c := utxoCompaction{}
+	if s.store != nil && len(s.stale) > 0 {
+		if err := s.store.Write(nil, s.stale); err != nil {
+			log.WithFields(logger.Fields{"entries": len(s.stale), "err": err}).Error("Cannot delete stale UTXO")
+		} else {
+			c.deleted = len(s.stale)
+			s.stale = nil
+		}
+	}
+
+	if s.oldHot == nil && s.oldCold == nil {
+		if s.peak < minUtxoCompaction || s.Len() >= s.peak/2 {
+			c.done = true
+			return c
+		}
+		c.started = true
+		c.reclaimed = s.peak - s.Len()
+		s.oldHot, s.hot = s.hot, make(map[cp.Hash32B]packedUtxo, len(s.hot))
+		s.oldCold, s.cold = s.cold, make(map[cp.Hash32B]struct{}, len(s.cold))
+		s.peak = s.Len()
+	}
+	for hash, entry := range s.oldHot {
+		if c.moved == max {
+			return c
+		}
+		s.hot[hash] = entry
+		delete(s.oldHot, hash)
+		c.moved++
+	}
+	for hash := range s.oldCold {
+		if c.moved == max {
+			return c
+		}
+		s.cold[hash] = struct{}{}
+		delete(s.oldCold, hash)
+		c.moved++
+	}
+	s.oldHot, s.oldCold = nil, nil
+	c.done = true
+	return c
+}
+
+// packUtxo encodes the unspent outputs of a transaction compactly
+// The encoding is the number of outputs followed by the index, value and lock script of each output, where the
+// integers are unsigned varints. A pay-to-address script is its kind byte followed by the 20-byte key hash, and any
+// other script is the rawScript byte followed by its length and the script.
+func packUtxo(utxo []*TxOutput) []byte {
+	size := binary.MaxVarintLen32
+	for _, out := range utxo {
+		size += binary.MaxVarintLen32 + binary.MaxVarintLen64 + 1 + binary.MaxVarintLen32 + len(out.LockScript)
+	}
+	buf := make([]byte, size)
+	n := binary.PutUvarint(buf, uint64(len(utxo)))
+	for _, out := range utxo {
+		n += binary.PutUvarint(buf[n:], uint64(uint32(out.outIndex)))
+		n += binary.PutUvarint(buf[n:], out.Value)
+		if kind := scriptKind(out.LockScript); kind != rawScript {
+			buf[n] = kind
+			n++
+			n += copy(buf[n:], out.LockScript[3:23])
+			continue
+		}
+		buf[n] = rawScript
+		n++
+		n += binary.PutUvarint(buf[n:], uint64(len(out.LockScript)))
+		n += copy(buf[n:], out.LockScript)
+	}
+	return append([]byte{}, buf[:n]...)
+}
+
+// unpackUtxo decodes the unspent outputs of a transaction encoded by packUtxo
+func unpackUtxo(buf []byte) ([]*TxOutput, error) {
+	r := &utxoReader{buf: buf}
+	count := r.uvarint()
+	if r.err == nil && count > uint64(len(buf)) {
+		r.err = errors.Errorf("Invalid number of outputs %d", count)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	utxo := make([]*TxOutput, 0, count)
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		index := int32(uint32(r.uvarint()))
+		value := r.uvarint()
+		var script []byte
+		switch kind := r.readByte(); {
+		case kind == rawScript:
+			script = r.bytes(r.uvarint())
+		case kind <= payToSchnorrKeyHash:
+			script = payToAddrScript(kind, r.bytes(20))
+		case r.err == nil:
+			r.err = errors.Errorf("Unknown script kind %d", kind)
+		}
+		out := &iproto.TxOutputPb{Value: value, LockScriptSize: uint32(len(script)), LockScript: script}
+		utxo = append(utxo, &TxOutput{out, index})
+	}
+	if r.err == nil && len(r.buf) > 0 {
+		r.err = errors.Errorf("%d trailing bytes", len(r.buf))
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return utxo, nil
+}
+
+// scriptKind returns the kind of the pay-to-address script, or rawScript if it is not one
+func scriptKind(script []byte) byte {
+	if len(script) != payToAddrScriptLen || script[0] != txvm.OpDup || script[1] != txvm.OpHash160 ||
+		script[2] != txvm.OpData20 || script[23] != txvm.OpEqualVerify {
+		return rawScript
+	}
+	switch script[24] {
+	case txvm.OpCheckSig:
+		return payToKeyHash
+	case txvm.OpCheckMultiSig:
+		return payToMultisigHash
+	case txvm.OpCheckSchnorrSig:
+		return payToSchnorrKeyHash
+	}
+	return rawScript
+}
+
+// payToAddrScript rebuilds the pay-to-address script of the kind locked with the key hash, nil if the hash is missing
+func payToAddrScript(kind byte, keyHash []byte) []byte {
+	if keyHash == nil {
+		return nil
+	}
+	checksig := map[byte]byte{
+		payToKeyHash:        txvm.OpCheckSig,
+		payToMultisigHash:   txvm.OpCheckMultiSig,
+		payToSchnorrKeyHash: txvm.OpCheckSchnorrSig,
+	}[kind]
+	script := make([]byte, 0, payToAddrScriptLen)
+	script = append(script, txvm.OpDup, txvm.OpHash160, txvm.OpData20)
+	script = append(script, keyHash...)
+	return append(script, txvm.OpEqualVerify, checksig)
+}
+
+// utxoReader reads the fields of a packed UTXO entry, recording the first error
+type utxoReader struct {
+	buf []byte
+	err error
+}
+
+func (r *utxoReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = errors.New("Truncated varint")
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *utxoReader) readByte() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *utxoReader) bytes(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(len(r.buf)) {
+		r.err = errors.Errorf("Truncated %d bytes", n)
+		return nil
+	}
+	if n == 0 {
+		return nil
+	}
+	b := append([]byte{}, r.buf[:n]...)
+	r.buf = r.buf[n:]
+	return b
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+// UtxoView is a copy-on-write view of the UTXO pool of a tracker, layering an overlay of the entries changed in the
+// view over the pool
+// Reads look up the overlay before the pool, while changes only go to the overlay, so a candidate block or
+// transaction is validated against the view without touching the pool. The view is then either discarded, or applied
+// to the tracker at once by ApplyView. The pool must not be updated while the view is in use, and the view is not
+// safe for concurrent use.
+type UtxoView struct {
+	base *UtxoTracker
+	// overlay keeps the entries changed in the view, a nil entry means the entry is removed from the pool
+	overlay map[cp.Hash32B][]*TxOutput
+	// minted keeps the height of the blocks minting the coinbase entries added in the view
+	minted map[cp.Hash32B]uint32
+
+	// circulating, emitted and burned are the supply of the pool once the view is applied
+	circulating uint64
+	emitted     uint64
+	burned      uint64
+}
+
+// NewView returns an empty view of the UTXO pool, which is cheap since nothing is copied until it is changed
+func (tk *UtxoTracker) NewView() *UtxoView {
+	return &UtxoView{
+		base:        tk,
+		overlay:     map[cp.Hash32B][]*TxOutput{},
+		minted:      map[cp.Hash32B]uint32{},
+		circulating: tk.circulating,
+		emitted:     tk.emitted,
+		burned:      tk.burned,
+	}
+}
+
+// ApplyView applies the entries changed in the view and its supply to the pool, which must not have been updated
+// since the view was created
+func (tk *UtxoTracker) ApplyView(v *UtxoView) error {
+	if err := tk.applyDiff(v.overlay, v.coinbaseHeights()); err != nil {
+		return err
+	}
+	tk.setSupply(v.emitted, v.burned)
+	return nil
+}
+
+// Entry returns the unspent outputs of the transaction in the view, nil if there is none
+func (v *UtxoView) Entry(hash cp.Hash32B) []*TxOutput {
+	if utxo, ok := v.overlay[hash]; ok {
+		return utxo
+	}
+	utxo, _ := v.base.utxoPool.get(hash)
+	return utxo
+}
+
+// setEntry replaces the entry in the overlay, a nil entry removing it, or returns ErrSupplyInvariant if the values of
+// the view overflow
+func (v *UtxoView) setEntry(hash cp.Hash32B, utxo []*TxOutput) error {
+	circulating, err := replaceValue(v.circulating, v.Entry(hash), utxo)
+	if err != nil {
+		return err
+	}
+	v.circulating = circulating
+	if utxo == nil {
+		delete(v.minted, hash)
+	}
+	v.overlay[hash] = utxo
+	return nil
+}
+
+// TxInputUtxo returns the UTXO spent by the transaction input, nil if it is not in the view
+func (v *UtxoView) TxInputUtxo(txIn *TxInput) *TxOutput {
+	hash := cp.ZeroHash32B
+	copy(hash[:], txIn.TxHash)
+	for _, utxo := range v.Entry(hash) {
+		if utxo.outIndex == txIn.OutIndex {
+			return utxo
+		}
+	}
+	return nil
+}
+
+// CoinbaseHeight returns the height of the block minting the coinbase entry, false if the entry is not a coinbase
+func (v *UtxoView) CoinbaseHeight(hash cp.Hash32B) (uint32, bool) {
+	if utxo, ok := v.overlay[hash]; ok && utxo == nil {
+		return 0, false
+	}
+	if height, ok := v.minted[hash]; ok {
+		return height, true
+	}
+	return v.base.CoinbaseHeight(hash)
+}
+
+// coinbaseHeights returns the minting height of the coinbase entries changed in the view
+func (v *UtxoView) coinbaseHeights() map[cp.Hash32B]uint32 {
+	coinbase := map[cp.Hash32B]uint32{}
+	for hash, utxo := range v.overlay {
+		if height, ok := v.CoinbaseHeight(hash); ok && utxo != nil {
+			coinbase[hash] = height
+		}
+	}
+	return coinbase
+}
+
+// AddTx adds the outputs of the transaction to the view without spending its inputs, e.g., the outputs of an
+// unconfirmed transaction spent by another one
+// It returns ErrSupplyInvariant and leaves the view untouched if the outputs overflow the values of the view.
+func (v *UtxoView) AddTx(tx *Tx) error {
+	hash := tx.Hash()
+	return v.setEntry(hash, append(append([]*TxOutput{}, v.Entry(hash)...), tx.TxOut...))
+}
+
+// connectTx adds the outputs of the transaction to the view and removes the UTXO spent by its inputs, or returns
+// ErrSupplyInvariant if the outputs overflow the values of the view
+func (v *UtxoView) connectTx(tx *Tx) error {
+	if err := v.setEntry(tx.Hash(), append([]*TxOutput{}, tx.TxOut...)); err != nil {
+		return err
+	}
+	if tx.IsCoinbase() {
+		return nil
+	}
+	for _, txIn := range tx.TxIn {
+		hash := cp.ZeroHash32B
+		copy(hash[:], txIn.TxHash)
+		var unspent []*TxOutput
+		for _, utxo := range v.Entry(hash) {
+			if utxo.outIndex != txIn.OutIndex {
+				unspent = append(unspent, utxo)
+			}
+		}
+		if err := v.setEntry(hash, unspent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateCoinbaseMaturity returns error if the transaction spends a coinbase output that is not yet mature at the
+// given height, i.e., it is minted less than coinbaseMaturity blocks ago
+func (v *UtxoView) ValidateCoinbaseMaturity(tx *Tx, height uint32) error {
+	maturity := v.base.coinbaseMaturity
+	for _, txIn := range tx.TxIn {
+		hash := cp.ZeroHash32B
+		copy(hash[:], txIn.TxHash)
+		minted, ok := v.CoinbaseHeight(hash)
+		if !ok {
+			continue
+		}
+		if height < minted || height-minted < maturity {
+			return fmt.Errorf("Tx %x spends coinbase %x minted at height %d before maturity at height %d",
+				tx.Hash(), hash, minted, minted+maturity)
+		}
+	}
+	return nil
+}
+
+// TxFee returns the fee of a transaction, which is the sum of its inputs minus the sum of its outputs
+// coinbase transaction does not pay fee
+func (v *UtxoView) TxFee(tx *Tx) (uint64, error) {
+	if tx.IsCoinbase() {
+		return 0, nil
+	}
+
+	credit := uint64(0)
+	for _, txIn := range tx.TxIn {
+		utxo := v.TxInputUtxo(txIn)
+		if utxo == nil {
+			return 0, fmt.Errorf("UTXO %x:%d does not exist", txIn.TxHash, txIn.OutIndex)
+		}
+		var err error
+		if credit, err = addValue(credit, utxo.Value); err != nil {
+			return 0, err
+		}
+	}
+
+	debit, err := utxoValue(tx.TxOut)
+	if err != nil {
+		return 0, err
+	}
+
+	if credit < debit {
+		return 0, fmt.Errorf("Tx %x spends %d more than its inputs", tx.Hash(), debit-credit)
+	}
+	return credit - debit, nil
+}
+
+// ValidateTxScripts returns error if an input of the transaction cannot unlock the UTXO it spends, which has to be in
+// the view
+func (v *UtxoView) ValidateTxScripts(tx *Tx) error {
+	checks, err := v.scriptChecks(tx)
+	if err != nil {
+		return err
+	}
+	return verifyScripts(checks, v.base.verifyWorkers, v.base.scripts)
+}
+
+// UnsignedInputs returns the indexes of the inputs of the transaction which cannot unlock the UTXO they spend, e.g.,
+// because they are not signed yet, the UTXO have to be in the view
+func (v *UtxoView) UnsignedInputs(tx *Tx) ([]int, error) {
+	checks, err := v.scriptChecks(tx)
+	if err != nil {
+		return nil, err
+	}
+	unsigned := []int{}
+	for _, check := range checks {
+		if check.verify(nil) != nil {
+			unsigned = append(unsigned, check.index)
+		}
+	}
+	return unsigned, nil
+}
+
+// scriptChecks returns the checks of the input scripts of the transaction against the UTXO they spend in the view
+func (v *UtxoView) scriptChecks(tx *Tx) ([]*scriptCheck, error) {
+	hash := tx.Hash()
+	stream := tx.sigStream()
+	checks := make([]*scriptCheck, 0, len(tx.TxIn))
+	for i, txIn := range tx.TxIn {
+		utxo := v.TxInputUtxo(txIn)
+		if utxo == nil {
+			return nil, fmt.Errorf("Tx %x spends unknown UTXO %x:%d", hash, txIn.TxHash, txIn.OutIndex)
+		}
+		checks = append(checks, &scriptCheck{hash, stream, i, txIn, utxo})
+	}
+	return checks, nil
+}
+
+// ConnectBlock applies the transactions of the block emitting 'reward' to the view in order, so a transaction can
+// spend the outputs of the earlier ones but not the UTXO they already spent
+// It returns error if a transaction spends a UTXO missing from the view or more than its inputs, or ErrSupplyInvariant
+// if the block breaks the total supply invariant, in which case the view has to be discarded.
+func (v *UtxoView) ConnectBlock(blk *Block, reward uint64) error {
+	return v.connectBlock(blk, reward, nil)
+}
+
+// validateBlock validates the UTXO spent by the block against the view while connecting it like ConnectBlock, which
+// includes the coinbase maturity, and runs the input scripts of the block at once if runScripts is set
+func (v *UtxoView) validateBlock(blk *Block, reward uint64, runScripts bool) error {
+	checks := []*scriptCheck{}
+	if err := v.connectBlock(blk, reward, &checks); err != nil {
+		return err
+	}
+	if !runScripts {
+		return nil
+	}
+	return verifyScripts(checks, v.base.verifyWorkers, v.base.scripts)
+}
+
+// connectBlock connects the block like ConnectBlock, validating the inputs and collecting their script checks if
+// checks is not nil
+func (v *UtxoView) connectBlock(blk *Block, reward uint64, checks *[]*scriptCheck) error {
+	fees, paid, err := v.connectTxs(blk, checks)
+	if err != nil {
+		return err
+	}
+	income, err := addValue(reward, fees)
+	if err != nil {
+		return err
+	}
+	if paid > income {
+		return errors.Wrapf(ErrSupplyInvariant, "Block %d pays %d to coinbase, more than its reward %d and fees %d", blk.Height(), paid, reward, fees)
+	}
+	if v.emitted, err = addValue(v.emitted, reward); err != nil {
+		return err
+	}
+	if v.burned, err = addValue(v.burned, income-paid); err != nil {
+		return err
+	}
+	if total, err := addValue(v.circulating, v.burned); err != nil || total != v.emitted {
+		return errors.Wrapf(ErrSupplyInvariant, "Circulating %d plus burned %d does not match emitted %d at block %d", v.circulating, v.burned, v.emitted, blk.Height())
+	}
+	return nil
+}
+
+// connectTxs applies the transactions of the block to the view in order, and returns the fees they pay and the
+// amount paid to the coinbase
+func (v *UtxoView) connectTxs(blk *Block, checks *[]*scriptCheck) (uint64, uint64, error) {
+	fees, paid := uint64(0), uint64(0)
+	for _, tx := range blk.Tranxs {
+		if tx.IsCoinbase() {
+			value, err := utxoValue(tx.TxOut)
+			if err != nil {
+				return 0, 0, err
+			}
+			if paid, err = addValue(paid, value); err != nil {
+				return 0, 0, err
+			}
+			if err := v.connectTx(tx); err != nil {
+				return 0, 0, err
+			}
+			// genesis allocations are spendable right away hence not tracked as coinbase
+			if blk.Height() > 0 {
+				v.minted[tx.Hash()] = blk.Height()
+			}
+			continue
+		}
+
+		if checks != nil {
+			if err := v.ValidateCoinbaseMaturity(tx, blk.Height()); err != nil {
+				return 0, 0, err
+			}
+			txChecks, err := v.scriptChecks(tx)
+			if err != nil {
+				return 0, 0, err
+			}
+			for _, check := range txChecks {
+				if check.utxo.Value == 0 {
+					return 0, 0, fmt.Errorf("Cannot validate UTXO %x", check.txIn.TxHash)
+				}
+			}
+			*checks = append(*checks, txChecks...)
+		}
+		fee, err := v.TxFee(tx)
+		if err != nil {
+			return 0, 0, err
+		}
+		if fees, err = addValue(fees, fee); err != nil {
+			return 0, 0, err
+		}
+		if err := v.connectTx(tx); err != nil {
+			return 0, 0, err
+		}
+	}
+	return fees, paid, nil
+}
+
+// Commitment returns the commitment to the UTXO pool once the view is applied, see UtxoTracker.Commitment
+func (v *UtxoView) Commitment() cp.Hash32B {
+	return v.base.commitmentAfter(v.overlay, v.coinbaseHeights()).Sum()
+}
+
+// Root returns the merkle root of the UTXO pool once the view is applied, or the zero hash if the pool is empty
+// The leaves are the hashes of the serialized UTXO entries, sorted by transaction hash.
+func (v *UtxoView) Root() (cp.Hash32B, error) {
+	coinbase := v.coinbaseHeights()
+	hashes := []cp.Hash32B{}
+	for _, hash := range v.base.utxoPool.sortedHashes() {
+		if _, ok := v.overlay[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+	}
+	for hash, utxo := range v.overlay {
+		if utxo != nil {
+			hashes = append(hashes, hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return cp.ZeroHash32B, nil
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+
+	leaves := make([]cp.Hash32B, len(hashes))
+	for i, hash := range hashes {
+		utxo, heights := v.overlay[hash], coinbase
+		if _, ok := v.overlay[hash]; !ok {
+			utxo, _ = v.base.utxoPool.get(hash)
+			heights = v.base.coinbaseHeights
+		}
+		buf, err := serializeUtxoEntry(hash, utxo, heights)
+		if err != nil {
+			return cp.ZeroHash32B, err
+		}
+		leaves[i] = blake2b.Sum256(buf)
+	}
+	return cp.NewMerkleTree(leaves).HashTree(), nil
+}
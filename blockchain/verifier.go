@@ -0,0 +1,268 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/net/context"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+var (
+	// ErrInconsistentChain is the error returned when the chain in Db fails verification
+	ErrInconsistentChain = errors.New("chain is inconsistent")
+)
+
+// scriptCheck runs the unlock script of a transaction input against the lock script of the UTXO it spends
+type scriptCheck struct {
+	txHash cp.Hash32B
+	stream []byte // byte stream of the transaction without the unlock scripts
+	index  int
+	txIn   *TxInput
+	utxo   *TxOutput
+}
+
+// verify returns error if the input cannot unlock the UTXO
+// the unlock script carries the signature of the digest of the transaction and the UTXO being spent, and the Schnorr
+// signatures are added to the batch if it is not nil
+func (c *scriptCheck) verify(batch *cp.SchnorrBatch) error {
+	hash := sigHash(c.stream, c.index, c.utxo.TxOutputPb)
+	if !c.txIn.UnlockSuccessBatch(hash[:], c.utxo.LockScript, batch) {
+		return fmt.Errorf("Tx %x cannot unlock UTXO %x:%d", c.txHash, c.txIn.TxHash, c.txIn.OutIndex)
+	}
+	return nil
+}
+
+// ValidateTxScripts returns error if an input of the transaction cannot unlock the UTXO it spends, which has to be in
+// the pool of the tracker
+func (tk *UtxoTracker) ValidateTxScripts(tx *Tx) error {
+	return tk.NewView().ValidateTxScripts(tx)
+}
+
+// UnsignedInputs returns the indexes of the inputs of the transaction which cannot unlock the UTXO they spend, e.g.,
+// because they are not signed yet, the UTXO have to be in the pool of the tracker
+func (tk *UtxoTracker) UnsignedInputs(tx *Tx) ([]int, error) {
+	return tk.NewView().UnsignedInputs(tx)
+}
+
+// cacheKey returns the key of the check in the script cache, which is the hash of the transaction hash, committing to
+// the unlock scripts, the input index and the hash of the UTXO spent, committing to its lock script and value
+// The scripts do not depend on the chain state, so a check passes whenever its key is cached.
+func (c *scriptCheck) cacheKey() cp.Hash32B {
+	utxo := blake2b.Sum256(c.utxo.ByteStream())
+	preimage := make([]byte, 0, len(c.txHash)+4+len(utxo))
+	preimage = append(preimage, c.txHash[:]...)
+	index := make([]byte, 4)
+	cm.MachineEndian.PutUint32(index, uint32(c.index))
+	preimage = append(preimage, index...)
+	preimage = append(preimage, utxo[:]...)
+	return blake2b.Sum256(preimage)
+}
+
+// verifyScripts runs the script checks whose keys are not in the cache like batchVerifyScripts, and adds their keys to
+// the cache once they all pass, so the inputs verified when their transaction enters the txpool are not verified again
+// when a block includes it. The cache is not used if it is nil.
+func verifyScripts(checks []*scriptCheck, workers int, cache *lruCache) error {
+	if cache == nil || cache.capacity <= 0 {
+		return batchVerifyScripts(checks, workers)
+	}
+	pending := make([]*scriptCheck, 0, len(checks))
+	keys := make([]cp.Hash32B, 0, len(checks))
+	for _, check := range checks {
+		key := check.cacheKey()
+		if _, ok := cache.Get(key); ok {
+			scriptCacheHits.Inc()
+			continue
+		}
+		pending = append(pending, check)
+		keys = append(keys, key)
+	}
+	if err := batchVerifyScripts(pending, workers); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		cache.Add(key, struct{}{})
+	}
+	return nil
+}
+
+// batchVerifyScripts runs the script checks concurrently on the given number of workers, or one per CPU if it is not
+// positive, and returns the first failure, after which the remaining checks are skipped
+// The Schnorr signatures of all checks are verified at once in a batch, each being assumed valid while the scripts run.
+// Since the batch only tells whether one of them is invalid, the checks are run again verifying each signature unless
+// the scripts and the batch all succeed.
+func batchVerifyScripts(checks []*scriptCheck, workers int) error {
+	batch := cp.NewSchnorrBatch()
+	err := runScriptChecks(checks, workers, batch)
+	if batch.Len() == 0 && batch.Verify() {
+		// no signature has been added to the batch
+		return err
+	}
+	if err == nil && batch.Verify() {
+		return nil
+	}
+	return runScriptChecks(checks, workers, nil)
+}
+
+// runScriptChecks runs the script checks on the workers with the Schnorr batch, and returns the first failure
+func runScriptChecks(checks []*scriptCheck, workers int, batch *cp.SchnorrBatch) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(checks) {
+		workers = len(checks)
+	}
+
+	jobs := make(chan *scriptCheck)
+	abort := make(chan struct{})
+	var failure error
+	var once sync.Once
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for check := range jobs {
+				if err := check.verify(batch); err != nil {
+					once.Do(func() {
+						failure = err
+						close(abort)
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, check := range checks {
+		select {
+		case jobs <- check:
+		case <-abort:
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return failure
+}
+
+// VerifyChain re-validates the last 'depth' blocks, or the whole chain if depth is 0, and returns the first
+// inconsistency found
+// The linkage and hash index of every block are checked. The UTXO set is recomputed by replaying all blocks from
+// genesis, which is why a pruned chain cannot be verified, and the merkle root, coinbase and input scripts of the
+// blocks within the depth are validated against it, as well as the UTXO commitments recorded for them. The recomputed
+// UTXO set has to match the one of the tracker.
+// The verification is aborted with the error of ctx once it is done.
+func (bc *Blockchain) VerifyChain(ctx context.Context, depth uint32) error {
+	if bc.pruneHeight > 0 {
+		return errors.Wrapf(ErrBlockPruned, "Cannot replay the blocks below %d to recompute UTXO", bc.pruneHeight)
+	}
+	start := uint32(0)
+	if depth > 0 && depth <= bc.height {
+		start = bc.height - depth + 1
+	}
+
+	tk := NewUtxoTracker()
+	tk.SetCoinbaseMaturity(bc.Utk.coinbaseMaturity)
+	tk.SetVerifyWorkers(bc.Utk.verifyWorkers)
+	height := uint32(0)
+	prev := cp.ZeroHash32B
+	err := bc.forEachBlock(ctx, 0, bc.height, func(blk *Block) error {
+		if err := bc.verifyBlock(blk, height, prev, tk, height >= start); err != nil {
+			return errors.Wrapf(ErrInconsistentChain, "Block %d: %v", height, err)
+		}
+		if err := tk.UpdateUtxoPool(blk, bc.emission(height)); err != nil {
+			return errors.Wrapf(ErrInconsistentChain, "Block %d: %v", height, err)
+		}
+		if height >= start {
+			recorded, err := bc.blockDb.GetUtxoCommitment(height)
+			if commitment := tk.Commitment(); err == nil && !bytes.Equal(recorded, commitment[:]) {
+				return errors.Wrapf(ErrInconsistentChain, "Block %d: UTXO commitment %x, recomputed %x", height, recorded, commitment)
+			}
+		}
+		prev = blk.HashBlock()
+		height++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if prev != bc.tip {
+		return errors.Wrapf(ErrInconsistentChain, "Last block %x is not the tip %x", prev, bc.tip)
+	}
+	return verifyUtxoPool(tk, bc.Utk)
+}
+
+// verifyBlock checks the block is the one at the given height linked to the previous block, and if 'full' is set,
+// validates its content against the UTXO set replayed up to the previous block
+func (bc *Blockchain) verifyBlock(blk *Block, height uint32, prev cp.Hash32B, tk *UtxoTracker, full bool) error {
+	hash := blk.HashBlock()
+	if blk.Height() != height {
+		return errors.Errorf("Wrong block height %d", blk.Height())
+	}
+	if blk.Header.chainID != bc.chainID {
+		return errors.Errorf("Wrong chain ID %d, expecting %d", blk.Header.chainID, bc.chainID)
+	}
+	if blk.PrevHash() != prev {
+		return errors.Errorf("Wrong prev hash %x, expecting %x", blk.PrevHash(), prev)
+	}
+	indexed, err := bc.GetHashByHeight(height)
+	if err != nil {
+		return err
+	}
+	if indexed != hash {
+		return errors.Errorf("Block hash %x does not match the hash %x indexed by height", hash, indexed)
+	}
+	if !full {
+		return nil
+	}
+
+	if merkle := blk.MerkleRoot(); blk.Header.merkleRoot != merkle {
+		return errors.Errorf("Wrong merkle root %x, expecting %x", blk.Header.merkleRoot, merkle)
+	}
+	if err := bc.validateCoinbase(blk, tk); err != nil {
+		return err
+	}
+	for _, tx := range blk.Tranxs {
+		if err := bc.validateTxChainID(tx, blk.Header.height); err != nil {
+			return err
+		}
+	}
+	return tk.ValidateUtxo(blk)
+}
+
+// verifyUtxoPool checks the recomputed UTXO set and coinbase heights match the ones of the tracker
+func verifyUtxoPool(recomputed *UtxoTracker, tk *UtxoTracker) error {
+	if recomputed.utxoPool.Len() != tk.utxoPool.Len() {
+		return errors.Wrapf(ErrInconsistentChain, "UTXO pool has %d entries, recomputed %d", tk.utxoPool.Len(), recomputed.utxoPool.Len())
+	}
+	var err error
+	recomputed.utxoPool.forEach(func(hash cp.Hash32B, utxo []*TxOutput) bool {
+		var expected, actual []byte
+		if expected, err = serializeUtxoEntry(hash, utxo, recomputed.coinbaseHeights); err != nil {
+			return false
+		}
+		current, _ := tk.utxoPool.get(hash)
+		if actual, err = serializeUtxoEntry(hash, current, tk.coinbaseHeights); err != nil {
+			return false
+		}
+		if !bytes.Equal(expected, actual) {
+			err = errors.Wrapf(ErrInconsistentChain, "UTXO entry %x does not match the recomputed one", hash)
+			return false
+		}
+		return true
+	})
+	return err
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/txvm"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+func TestVerifyScripts(t *testing.T) {
+	assert := assert.New(t)
+
+	alfa := ta.Addrinfo["alfa"]
+	in := []*TxInput{}
+	for i := 0; i < 20; i++ {
+		in = append(in, NewTxInput(cp.ZeroHash32B, int32(i), nil, 0))
+	}
+	tx := NewTx(1, in, nil, 0)
+	stream := tx.sigStream()
+	checks := []*scriptCheck{}
+	for i := range in {
+		utxo := CreateTxOutput(alfa.Address, uint64(i+1))
+		digest := tx.SigHash(i, utxo.TxOutputPb)
+		unlock, err := txvm.SignatureScript(digest[:], alfa.PublicKey, alfa.PrivateKey)
+		assert.Nil(err)
+		checks = append(checks, &scriptCheck{tx.Hash(), stream, i, NewTxInput(cp.ZeroHash32B, int32(i), unlock, 0), utxo})
+	}
+	for _, workers := range []int{0, 1, 4, 64} {
+		assert.Nil(verifyScripts(checks, workers, nil))
+	}
+	assert.Nil(verifyScripts(nil, 4, nil))
+
+	// a single input signed by another key fails the whole batch
+	bravo := ta.Addrinfo["bravo"]
+	digest := tx.SigHash(7, checks[7].utxo.TxOutputPb)
+	unlock, err := txvm.SignatureScript(digest[:], bravo.PublicKey, bravo.PrivateKey)
+	assert.Nil(err)
+	checks[7] = &scriptCheck{tx.Hash(), stream, 7, NewTxInput(cp.ZeroHash32B, 7, unlock, 0), checks[7].utxo}
+	for _, workers := range []int{0, 1, 4, 64} {
+		assert.NotNil(verifyScripts(checks, workers, nil))
+	}
+}
+
+func TestScriptCache(t *testing.T) {
+	assert := assert.New(t)
+
+	alfa := ta.Addrinfo["alfa"]
+	in := []*TxInput{}
+	for i := 0; i < 4; i++ {
+		in = append(in, NewTxInput(cp.ZeroHash32B, int32(i), nil, 0))
+	}
+	tx := NewTx(1, in, nil, 0)
+	stream := tx.sigStream()
+	checks := []*scriptCheck{}
+	for i := range in {
+		utxo := CreateTxOutput(alfa.Address, uint64(i+1))
+		digest := tx.SigHash(i, utxo.TxOutputPb)
+		unlock, err := txvm.SignatureScript(digest[:], alfa.PublicKey, alfa.PrivateKey)
+		assert.Nil(err)
+		checks = append(checks, &scriptCheck{tx.Hash(), stream, i, NewTxInput(cp.ZeroHash32B, int32(i), unlock, 0), utxo})
+	}
+
+	// the checks passed are cached
+	cache := newLRUCache(3)
+	assert.Nil(verifyScripts(checks[:2], 2, cache))
+	assert.Equal(2, cache.Len())
+	hits := scriptCacheHits.Value()
+
+	// so a cached check is not run again, which only a forged check with the key of a cached one tells
+	forged := *checks[1]
+	forged.txIn = NewTxInput(cp.ZeroHash32B, 1, nil, 0)
+	assert.NotNil(verifyScripts([]*scriptCheck{&forged}, 2, nil))
+	assert.Nil(verifyScripts([]*scriptCheck{&forged}, 2, cache))
+	assert.Equal(hits+1, scriptCacheHits.Value())
+
+	// the checks of a failure are not cached
+	bad := *checks[3]
+	bad.utxo = CreateTxOutput(alfa.Address, 100)
+	assert.NotNil(verifyScripts([]*scriptCheck{checks[2], &bad}, 2, cache))
+	assert.Equal(2, cache.Len())
+
+	// the same input spending another UTXO is another check
+	assert.NotEqual(checks[3].cacheKey(), (&scriptCheck{tx.Hash(), stream, 3, checks[3].txIn, checks[2].utxo}).cacheKey())
+
+	// the cache is bounded
+	assert.Nil(verifyScripts(checks, 2, cache))
+	assert.Equal(3, cache.Len())
+
+	// the inputs verified on entering the txpool are not verified again when a block includes them
+	defer os.Remove(testDBPath)
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.ScriptCacheSize = 100
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 30, []*Payee{NewPayee(alfa.Address, 30)})
+	assert.Nil(err)
+	assert.Nil(bc.NewUtxoView().ValidateTxScripts(tx))
+	hits = scriptCacheHits.Value()
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Equal(hits+uint64(len(tx.TxIn)), scriptCacheHits.Value())
+}
+
+func TestVerifyChain(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	for i := 0; i < 2; i++ {
+		blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+		assert.Nil(err)
+		assert.Nil(bc.AddBlockCommit(blk))
+	}
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{{ta.Addrinfo["alfa"].Address, 10}})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	assert.Nil(bc.VerifyChain(context.Background(), 0))
+	assert.Nil(bc.VerifyChain(context.Background(), 1))
+	assert.Nil(bc.VerifyChain(context.Background(), 10))
+
+	// a UTXO entry missing from the pool is found by recomputing the UTXO set
+	txHash := tx.Hash()
+	utxo, _ := bc.Utk.utxoPool.get(txHash)
+	bc.Utk.utxoPool.remove(txHash)
+	assert.Equal(ErrInconsistentChain, errors.Cause(bc.VerifyChain(context.Background(), 1)))
+	bc.Utk.utxoPool.put(txHash, utxo)
+	assert.Nil(bc.VerifyChain(context.Background(), 1))
+
+	// the height index pointing to another block breaks the linkage
+	bc.hashCache.Add(uint32(2), blk.HashBlock())
+	assert.Equal(ErrInconsistentChain, errors.Cause(bc.VerifyChain(context.Background(), 0)))
+}
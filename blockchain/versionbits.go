@@ -0,0 +1,221 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+const (
+	// VersionBitsTop are the top 3 bits of a header version signaling the deployments by its low 29 bits, the headers
+	// of Version signal none
+	VersionBitsTop = uint32(0x20000000)
+	// versionBitsTopMask masks the top 3 bits of a header version
+	versionBitsTopMask = uint32(0xe0000000)
+
+	// DeploymentStrictChainID is the deployment rejecting the transactions signed without chain ID once active,
+	// regardless of AcceptLegacyTxs
+	DeploymentStrictChainID = "strictchainid"
+)
+
+// DeploymentState is the state of a deployment in a window of blocks
+type DeploymentState int
+
+const (
+	// DeploymentDefined is the state of a deployment before its start height
+	DeploymentDefined DeploymentState = iota
+	// DeploymentStarted is the state of a deployment whose signaling blocks are counted
+	DeploymentStarted
+	// DeploymentLockedIn is the state of a deployment in the window following the one it reached the threshold in
+	DeploymentLockedIn
+	// DeploymentActive is the state of a deployment whose rules are enforced
+	DeploymentActive
+	// DeploymentFailed is the state of a deployment not locked in by its timeout height
+	DeploymentFailed
+)
+
+func (s DeploymentState) String() string {
+	switch s {
+	case DeploymentDefined:
+		return "DEFINED"
+	case DeploymentStarted:
+		return "STARTED"
+	case DeploymentLockedIn:
+		return "LOCKED_IN"
+	case DeploymentActive:
+		return "ACTIVE"
+	case DeploymentFailed:
+		return "FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ErrUnknownDeployment is the error returned when a deployment is not in the config
+var ErrUnknownDeployment = errors.New("unknown deployment")
+
+// SignalsBit returns true if the header version signals the bit of a deployment
+func SignalsBit(version uint32, bit uint32) bool {
+	return version&versionBitsTopMask == VersionBitsTop && version&(1<<bit) != 0
+}
+
+// deploymentTracker keeps the states of the deployments of the config in each window of SignalWindow blocks
+// The state of a deployment only changes at the first block of a window, based on its state in the previous window
+// and the number of blocks of the previous window signaling it. The genesis block is in window 0, where all the
+// deployments are defined.
+type deploymentTracker struct {
+	window      uint32
+	threshold   uint32
+	deployments map[string]config.Deployment
+
+	mutex sync.Mutex
+	// states are the states of the deployments by window, in the windows computed so far
+	states map[string][]DeploymentState
+}
+
+func newDeploymentTracker(cfg *config.Chain) *deploymentTracker {
+	dt := &deploymentTracker{
+		window:      cfg.SignalWindow,
+		threshold:   cfg.SignalThreshold,
+		deployments: make(map[string]config.Deployment),
+		states:      make(map[string][]DeploymentState),
+	}
+	for _, d := range cfg.Deployments {
+		dt.deployments[d.Name] = d
+	}
+	return dt
+}
+
+// enabled returns true if the blocks signal deployments
+func (dt *deploymentTracker) enabled() bool {
+	return dt.window > 0 && len(dt.deployments) > 0
+}
+
+// purge forgets the states computed so far, e.g., once the chain reorgs
+func (dt *deploymentTracker) purge() {
+	dt.mutex.Lock()
+	defer dt.mutex.Unlock()
+	dt.states = make(map[string][]DeploymentState)
+}
+
+// DeploymentState returns the state of the deployment of the config for the block at the height, which can be the
+// height following the tip at most
+func (bc *Blockchain) DeploymentState(name string, height uint32) (DeploymentState, error) {
+	dt := bc.deployments
+	d, ok := dt.deployments[name]
+	if !ok {
+		return DeploymentDefined, errors.Wrapf(ErrUnknownDeployment, "%s", name)
+	}
+	if dt.window == 0 {
+		return DeploymentDefined, nil
+	}
+
+	dt.mutex.Lock()
+	defer dt.mutex.Unlock()
+	states := dt.states[name]
+	if len(states) == 0 {
+		states = []DeploymentState{DeploymentDefined}
+	}
+	for w := uint32(len(states)); w <= height/dt.window; w++ {
+		state, err := bc.nextDeploymentState(d, w, states[w-1])
+		if err != nil {
+			return DeploymentDefined, err
+		}
+		states = append(states, state)
+	}
+	dt.states[name] = states
+	return states[height/dt.window], nil
+}
+
+// nextDeploymentState returns the state of the deployment in the window given its state in the previous window
+func (bc *Blockchain) nextDeploymentState(d config.Deployment, w uint32, prev DeploymentState) (DeploymentState, error) {
+	dt := bc.deployments
+	start := w * dt.window
+	timedOut := d.TimeoutHeight > 0 && start >= d.TimeoutHeight
+	switch prev {
+	case DeploymentDefined:
+		if timedOut {
+			return DeploymentFailed, nil
+		}
+		if start >= d.StartHeight {
+			return DeploymentStarted, nil
+		}
+		return DeploymentDefined, nil
+	case DeploymentStarted:
+		signaling := uint32(0)
+		for h := start - dt.window; h < start; h++ {
+			blk, err := bc.GetBlockHeaderByHeight(h)
+			if err != nil {
+				return prev, errors.Wrapf(err, "Failed to count the signals of deployment %s", d.Name)
+			}
+			if SignalsBit(blk.Header.version, d.Bit) {
+				signaling++
+			}
+		}
+		if signaling >= dt.threshold {
+			return DeploymentLockedIn, nil
+		}
+		if timedOut {
+			return DeploymentFailed, nil
+		}
+		return DeploymentStarted, nil
+	case DeploymentLockedIn:
+		return DeploymentActive, nil
+	default:
+		return prev, nil
+	}
+}
+
+// IsDeploymentActive returns true if the rules of the deployment are enforced on the block at the height, which is
+// never the case for a deployment not in the config
+func (bc *Blockchain) IsDeploymentActive(name string, height uint32) (bool, error) {
+	state, err := bc.DeploymentState(name, height)
+	if errors.Cause(err) == ErrUnknownDeployment {
+		return false, nil
+	}
+	return state == DeploymentActive, err
+}
+
+// ActivationHeight returns the height from which the deployment is active, false if it is not active as of the
+// height following the tip
+func (bc *Blockchain) ActivationHeight(name string) (uint32, bool, error) {
+	active, err := bc.IsDeploymentActive(name, bc.height+1)
+	if err != nil || !active {
+		return 0, false, err
+	}
+	// the states of the windows up to the one following the tip are computed
+	bc.deployments.mutex.Lock()
+	defer bc.deployments.mutex.Unlock()
+	states := bc.deployments.states[name]
+	w := len(states) - 1
+	for w > 0 && states[w-1] == DeploymentActive {
+		w--
+	}
+	return uint32(w) * bc.deployments.window, true, nil
+}
+
+// blockVersion returns the version of the block minted at the height, signaling the deployments of the config which
+// are started, or Version if the deployments are disabled
+func (bc *Blockchain) blockVersion(height uint32) (uint32, error) {
+	if !bc.deployments.enabled() {
+		return Version, nil
+	}
+	version := VersionBitsTop
+	for _, name := range bc.config.Chain.SignalDeployments {
+		state, err := bc.DeploymentState(name, height)
+		if err != nil {
+			return 0, err
+		}
+		if state == DeploymentStarted {
+			version |= 1 << bc.deployments.deployments[name].Bit
+		}
+	}
+	return version, nil
+}
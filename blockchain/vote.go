@@ -0,0 +1,154 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+// ErrInvalidVote is the error returned when a vote is malformed or not signed by its voter
+var ErrInvalidVote = errors.New("invalid vote")
+
+// Vote stakes the balance of the voter in the account-based state toward the votee, it is ordered by the nonce of
+// the voter like a transfer
+// Voting for itself nominates the voter as a candidate for the delegates, and an empty votee withdraws the vote.
+type Vote struct {
+	Version uint32
+	Nonce   uint64
+	Voter   string
+	Votee   string
+	// VoterPubKey is the public key of the voter, which signs the hash of the vote into Signature
+	VoterPubKey []byte
+	Signature   []byte
+}
+
+// NewVote returns an unsigned vote of the voter for the votee
+func NewVote(nonce uint64, voter string, votee string) *Vote {
+	return &Vote{Version: 1, Nonce: nonce, Voter: voter, Votee: votee}
+}
+
+// ByteStream returns a raw byte stream of the vote without the signature
+// The votee is preceded by its length since it may be empty.
+func (v *Vote) ByteStream() []byte {
+	stream := make([]byte, 4)
+	cm.MachineEndian.PutUint32(stream, v.Version)
+
+	temp := make([]byte, 8)
+	cm.MachineEndian.PutUint64(temp, v.Nonce)
+	stream = append(stream, temp...)
+	stream = append(stream, v.Voter...)
+	size := make([]byte, 4)
+	cm.MachineEndian.PutUint32(size, uint32(len(v.Votee)))
+	stream = append(stream, size...)
+	stream = append(stream, v.Votee...)
+	stream = append(stream, v.VoterPubKey...)
+	return stream
+}
+
+// Hash returns the hash of the vote, which is not changed by signing it
+func (v *Vote) Hash() cp.Hash32B {
+	hash := blake2b.Sum256(v.ByteStream())
+	return blake2b.Sum256(hash[:])
+}
+
+// Sign signs the vote with the handle of its voter
+func (v *Vote) Sign(signer wallet.Signer) error {
+	if signer.Address() != v.Voter {
+		return errors.Wrapf(ErrSigningFailed, "Signer %s is not the voter %s", signer.Address(), v.Voter)
+	}
+	v.VoterPubKey = signer.PublicKey()
+	hash := v.Hash()
+	sig, err := signer.Sign(hash[:])
+	if err != nil {
+		return errors.Wrapf(ErrSigningFailed, "%v", err)
+	}
+	v.Signature = sig
+	return nil
+}
+
+// Verify checks the votee is either empty or a valid address, and the vote is signed by the key of its voter's
+// address
+func (v *Vote) Verify() error {
+	if v.Votee != "" && !iotxaddress.ValidateAddress(v.Votee) {
+		return errors.Wrapf(ErrInvalidVote, "Invalid votee %s", v.Votee)
+	}
+	if len(v.VoterPubKey) != ed25519.PublicKeySize || len(v.Signature) != ed25519.SignatureSize {
+		return errors.Wrap(ErrInvalidVote, "Vote is not signed")
+	}
+	pkHash := iotxaddress.GetPubkeyHash(v.Voter)
+	if pkHash == nil || !bytes.Equal(pkHash, iotxaddress.HashPubKey(v.VoterPubKey)) {
+		return errors.Wrapf(ErrInvalidVote, "Public key does not match voter %s", v.Voter)
+	}
+	hash := v.Hash()
+	if !cp.Verify(v.VoterPubKey, hash[:], v.Signature) {
+		return errors.Wrapf(ErrInvalidVote, "Wrong signature of vote %x", hash)
+	}
+	return nil
+}
+
+// Kind returns the kind of the vote action
+func (v *Vote) Kind() string {
+	return VoteKind
+}
+
+// SenderNonce returns the voter of the vote and its nonce
+func (v *Vote) SenderNonce() (string, uint64) {
+	return v.Voter, v.Nonce
+}
+
+// Execute stakes the balance of the voter toward the votee, consuming the nonce of the voter
+func (v *Vote) Execute(ctx *ActionContext) error {
+	return ctx.WS.Vote(v.Voter, v.Votee, v.Nonce)
+}
+
+// ConvertToVotePb creates a protobuf's Vote using type Vote
+func (v *Vote) ConvertToVotePb() *iproto.VotePb {
+	return &iproto.VotePb{
+		Version:     v.Version,
+		Nonce:       v.Nonce,
+		Voter:       v.Voter,
+		Votee:       v.Votee,
+		VoterPubKey: v.VoterPubKey,
+		Signature:   v.Signature,
+	}
+}
+
+// ConvertFromVotePb converts a protobuf's Vote back to type Vote
+func (v *Vote) ConvertFromVotePb(pbVote *iproto.VotePb) {
+	v.Version = pbVote.GetVersion()
+	v.Nonce = pbVote.GetNonce()
+	v.Voter = pbVote.GetVoter()
+	v.Votee = pbVote.GetVotee()
+	v.VoterPubKey = pbVote.GetVoterPubKey()
+	v.Signature = pbVote.GetSignature()
+}
+
+// Serialize returns a serialized byte stream for the Vote
+func (v *Vote) Serialize() ([]byte, error) {
+	return proto.Marshal(v.ConvertToVotePb())
+}
+
+// Deserialize parses the byte stream into the Vote
+func (v *Vote) Deserialize(buf []byte) error {
+	pbVote := iproto.VotePb{}
+	if err := proto.Unmarshal(buf, &pbVote); err != nil {
+		return err
+	}
+	v.ConvertFromVotePb(&pbVote)
+	return nil
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockchain
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/iotexproject/iotex-core/blockdb"
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+// ErrInvalidWithdraw is the error returned when a withdraw does not prove its deposit is committed on the source chain
+var ErrInvalidWithdraw = errors.New("invalid withdraw")
+
+// Withdraw credits the amount of a deposit committed on the source chain to its recipient on the destination chain
+// It carries the header of the source block committing the deposit and the merkle proof of the deposit's inclusion,
+// which prove the deposit by themselves once the header is found on the source chain, hence the withdraw is not signed
+// by whoever relays it. A deposit is withdrawn only once.
+type Withdraw struct {
+	Version uint32
+	Deposit *Deposit
+	// Header is the block of the source chain with only the header
+	Header *Block
+	Proof  *cp.MerkleProof
+}
+
+// NewWithdraw returns the withdraw of the deposit committed by the block
+func NewWithdraw(deposit *Deposit, blk *Block) (*Withdraw, error) {
+	proof, err := blk.MerkleProof(deposit.Hash())
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidWithdraw, "%v", err)
+	}
+	return &Withdraw{Version: 1, Deposit: deposit, Header: &Block{Header: blk.Header}, Proof: proof}, nil
+}
+
+// ByteStream returns a raw byte stream of the withdraw, the proof is implied by the deposit and the header
+func (w *Withdraw) ByteStream() []byte {
+	stream := make([]byte, 4)
+	cm.MachineEndian.PutUint32(stream, w.Version)
+	depositHash, headerHash := w.Deposit.Hash(), w.Header.HashBlock()
+	stream = append(stream, depositHash[:]...)
+	stream = append(stream, headerHash[:]...)
+	return stream
+}
+
+// Hash returns the hash of the withdraw
+func (w *Withdraw) Hash() cp.Hash32B {
+	hash := blake2b.Sum256(w.ByteStream())
+	return blake2b.Sum256(hash[:])
+}
+
+// Verify checks the deposit is valid and is included in the block of the header, which is on the source chain of the
+// deposit, the header is not checked against the source chain
+func (w *Withdraw) Verify() error {
+	if w.Deposit == nil || w.Header == nil || w.Header.Header == nil {
+		return errors.Wrap(ErrInvalidWithdraw, "Missing deposit or header")
+	}
+	if err := w.Deposit.Verify(); err != nil {
+		return errors.Wrapf(ErrInvalidWithdraw, "%v", err)
+	}
+	if w.Header.Header.chainID != w.Deposit.SrcChainID {
+		return errors.Wrapf(ErrInvalidWithdraw, "Header of chain %d is not on the source chain %d of the deposit",
+			w.Header.Header.chainID, w.Deposit.SrcChainID)
+	}
+	if !VerifyMerkleProof(w.Deposit.Hash(), w.Proof, w.Header) {
+		return errors.Wrapf(ErrInvalidWithdraw, "Deposit %x is not in block %x", w.Deposit.Hash(), w.Header.HashBlock())
+	}
+	return nil
+}
+
+// Kind returns the kind of the withdraw action
+func (w *Withdraw) Kind() string {
+	return WithdrawKind
+}
+
+// Execute credits the amount of the deposit to its recipient, the withdraw is checked against the peer chains by
+// validateWithdraws before the block is committed
+func (w *Withdraw) Execute(ctx *ActionContext) error {
+	return ctx.WS.Credit(w.Deposit.Recipient, w.Deposit.Amount)
+}
+
+// ConvertToWithdrawPb creates a protobuf's Withdraw using type Withdraw
+func (w *Withdraw) ConvertToWithdrawPb() *iproto.WithdrawPb {
+	pbWithdraw := &iproto.WithdrawPb{
+		Version: w.Version,
+		Deposit: w.Deposit.ConvertToDepositPb(),
+		Header:  w.Header.ConvertToBlockHeaderPb(),
+	}
+	if w.Proof != nil {
+		pbWithdraw.Index = w.Proof.Index
+		for _, sibling := range w.Proof.Siblings {
+			pbWithdraw.Siblings = append(pbWithdraw.Siblings, sibling[:])
+		}
+	}
+	return pbWithdraw
+}
+
+// ConvertFromWithdrawPb converts a protobuf's Withdraw back to type Withdraw, a missing deposit or header is converted
+// to an empty one which fails Verify
+func (w *Withdraw) ConvertFromWithdrawPb(pbWithdraw *iproto.WithdrawPb) {
+	w.Version = pbWithdraw.GetVersion()
+	w.Deposit = &Deposit{}
+	if pbWithdraw.GetDeposit() != nil {
+		w.Deposit.ConvertFromDepositPb(pbWithdraw.GetDeposit())
+	}
+	w.Header = &Block{}
+	w.Header.ConvertFromBlockHeaderPb(&iproto.BlockPb{Header: pbWithdraw.GetHeader()})
+	w.Proof = &cp.MerkleProof{Index: pbWithdraw.GetIndex()}
+	for _, sibling := range pbWithdraw.GetSiblings() {
+		hash := cp.ZeroHash32B
+		copy(hash[:], sibling)
+		w.Proof.Siblings = append(w.Proof.Siblings, hash)
+	}
+}
+
+// Serialize returns a serialized byte stream for the Withdraw
+func (w *Withdraw) Serialize() ([]byte, error) {
+	return proto.Marshal(w.ConvertToWithdrawPb())
+}
+
+// Deserialize parses the byte stream into the Withdraw
+func (w *Withdraw) Deserialize(buf []byte) error {
+	pbWithdraw := iproto.WithdrawPb{}
+	if err := proto.Unmarshal(buf, &pbWithdraw); err != nil {
+		return err
+	}
+	w.ConvertFromWithdrawPb(&pbWithdraw)
+	return nil
+}
+
+// SetPeerChain lets the blockchain accept the withdraws of the deposits committed on the peer chain, which are checked
+// against the headers of the peer chain
+func (bc *Blockchain) SetPeerChain(peer IBlockchain) {
+	if bc.peers == nil {
+		bc.peers = make(map[uint32]IBlockchain)
+	}
+	bc.peers[peer.ChainID()] = peer
+}
+
+// IsWithdrawn returns true if the withdraw of the deposit is committed
+func (bc *Blockchain) IsWithdrawn(depositHash cp.Hash32B) (bool, error) {
+	_, err := bc.blockDb.GetWithdrawBlockHash(depositHash[:])
+	if err == nil {
+		return true, nil
+	}
+	if errors.Cause(err) != blockdb.ErrNotExist {
+		return false, err
+	}
+	return false, nil
+}
+
+// validateWithdraws verifies the withdraws are to the chain, none of their deposits is withdrawn by an earlier block
+// or repeated, and the headers they carry are on the peer chains with at least CrossChainConfirmations blocks on top
+func (bc *Blockchain) validateWithdraws(withdraws []*Withdraw) error {
+	seen := make(map[cp.Hash32B]bool)
+	for _, w := range withdraws {
+		if err := w.Verify(); err != nil {
+			return errors.Wrapf(ErrInvalidBlock, "%v", err)
+		}
+		if w.Deposit.DstChainID != bc.chainID {
+			return errors.Wrapf(ErrInvalidBlock, "Deposit to chain %d is withdrawn on chain %d", w.Deposit.DstChainID, bc.chainID)
+		}
+		hash := w.Deposit.Hash()
+		if seen[hash] {
+			return errors.Wrapf(ErrInvalidBlock, "Deposit %x is withdrawn twice", hash)
+		}
+		seen[hash] = true
+		withdrawn, err := bc.IsWithdrawn(hash)
+		if err != nil {
+			return err
+		}
+		if withdrawn {
+			return errors.Wrapf(ErrInvalidBlock, "Deposit %x is already withdrawn", hash)
+		}
+
+		header := w.Header.Header
+		peer, ok := bc.peers[header.chainID]
+		if !ok {
+			return errors.Wrapf(ErrInvalidBlock, "Chain %d of deposit %x is not a peer chain", header.chainID, hash)
+		}
+		if peer.TipHeight() < header.height+bc.config.Chain.CrossChainConfirmations {
+			return errors.Wrapf(ErrInvalidBlock, "Block %d of chain %d is not confirmed", header.height, header.chainID)
+		}
+		peerHash, err := peer.GetHashByHeight(header.height)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidBlock, "Cannot get block %d of chain %d: %v", header.height, header.chainID, err)
+		}
+		if peerHash != w.Header.HashBlock() {
+			return errors.Wrapf(ErrInvalidBlock, "Block %x is not on chain %d", w.Header.HashBlock(), header.chainID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"github.com/pkg/errors"
+
+	cm "github.com/iotexproject/iotex-core/common"
+)
+
+// Batch collects writes to the DB so they are committed in a single atomic transaction
+// Either all of the writes in a batch are persisted or none of them is, so a crash in the middle of a commit
+// never leaves the block data, the indexes and the UTXO out of sync
+type Batch struct {
+	kv *KVBatch
+	// blocks added by the batch, which must not collide with any existing blocks
+	blocks []blockEntry
+	// blocks the batch moves the tip to, which are rolled back if the commit does not complete
+	tips []tipEntry
+}
+
+type blockEntry struct {
+	hash []byte
+	blk  []byte
+}
+
+type tipEntry struct {
+	height uint32
+	hash   []byte
+}
+
+// NewBatch returns an empty batch
+func NewBatch() *Batch {
+	return &Batch{kv: NewKVBatch()}
+}
+
+// PutBlock adds a block, the tip and the hash <-> height mapping to the batch
+func (b *Batch) PutBlock(blk []byte, hash []byte, h uint32) {
+	b.blocks = append(b.blocks, blockEntry{hash, blk})
+	b.putTip(hash, h)
+}
+
+// PutBlockHeader adds a block without its body, e.g., the block of an imported UTXO snapshot, as the tip to the
+// batch, only the header and the hash <-> height mapping of the block are kept
+func (b *Batch) PutBlockHeader(header []byte, hash []byte, h uint32) {
+	b.putTip(hash, h)
+	b.kv.Put(headersBucket, hash, header)
+}
+
+// PutBlockMeta adds the metadata of a block, which is kept when the block is pruned
+func (b *Batch) PutBlockMeta(hash []byte, meta []byte) {
+	b.kv.Put(blockMetaBucket, hash, meta)
+}
+
+// putTip adds the tip hash/height and the hash <-> height mapping of the block
+func (b *Batch) putTip(hash []byte, h uint32) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.tips = append(b.tips, tipEntry{h, hash})
+	b.kv.Put(blocksBucket, tipHash, hash)
+	b.kv.Put(blocksBucket, tipHeight, height)
+	b.kv.Put(hashHeightBucket, hash, height)
+	b.kv.Put(hashHeightBucket, height, hash)
+}
+
+// PutTxIndex adds the mapping from a tx hash to the hash of the block containing it
+func (b *Batch) PutTxIndex(txHash []byte, blkHash []byte) {
+	b.kv.Put(txIndexBucket, txHash, blkHash)
+}
+
+// DeleteTxIndex removes the mapping from a tx hash to the hash of the block containing it
+func (b *Batch) DeleteTxIndex(txHash []byte) {
+	b.kv.Delete(txIndexBucket, txHash)
+}
+
+// PutEvidenceIndex adds the mapping from an evidence hash to the hash of the block committing it
+func (b *Batch) PutEvidenceIndex(evidenceHash []byte, blkHash []byte) {
+	b.kv.Put(evidenceIndexBucket, evidenceHash, blkHash)
+}
+
+// DeleteEvidenceIndex removes the mapping from an evidence hash to the hash of the block committing it
+func (b *Batch) DeleteEvidenceIndex(evidenceHash []byte) {
+	b.kv.Delete(evidenceIndexBucket, evidenceHash)
+}
+
+// PutWithdrawIndex adds the mapping from a deposit hash to the hash of the block committing its withdraw
+func (b *Batch) PutWithdrawIndex(depositHash []byte, blkHash []byte) {
+	b.kv.Put(withdrawIndexBucket, depositHash, blkHash)
+}
+
+// DeleteWithdrawIndex removes the mapping from a deposit hash to the hash of the block committing its withdraw
+func (b *Batch) DeleteWithdrawIndex(depositHash []byte) {
+	b.kv.Delete(withdrawIndexBucket, depositHash)
+}
+
+// PutUtxo sets the serialized unspent outputs of a tx
+func (b *Batch) PutUtxo(txHash []byte, utxo []byte) {
+	b.kv.Put(utxoBucket, txHash, utxo)
+}
+
+// DeleteUtxo removes the unspent outputs of a tx
+func (b *Batch) DeleteUtxo(txHash []byte) {
+	b.kv.Delete(utxoBucket, txHash)
+}
+
+// ClearUtxo removes all the unspent outputs
+func (b *Batch) ClearUtxo() {
+	b.kv.Clear(utxoBucket)
+}
+
+// PutAccount sets the serialized state of an account
+func (b *Batch) PutAccount(addr []byte, account []byte) {
+	b.kv.Put(accountBucket, addr, account)
+}
+
+// DeleteAccount removes the state of an account
+func (b *Batch) DeleteAccount(addr []byte) {
+	b.kv.Delete(accountBucket, addr)
+}
+
+// ClearAccounts removes all the account states
+func (b *Batch) ClearAccounts() {
+	b.kv.Clear(accountBucket)
+}
+
+// PutCode sets the code of a contract
+func (b *Batch) PutCode(addr []byte, code []byte) {
+	b.kv.Put(contractBucket, addr, code)
+}
+
+// DeleteCode removes the code of a contract
+func (b *Batch) DeleteCode(addr []byte) {
+	b.kv.Delete(contractBucket, addr)
+}
+
+// PutState sets the value of a key in the storage of a contract
+func (b *Batch) PutState(addr []byte, key []byte, value []byte) {
+	b.kv.Put(storageBucket, storageKey(addr, key), value)
+}
+
+// DeleteState removes a key from the storage of a contract
+func (b *Batch) DeleteState(addr []byte, key []byte) {
+	b.kv.Delete(storageBucket, storageKey(addr, key))
+}
+
+// ClearContracts removes the code and storage of all contracts
+func (b *Batch) ClearContracts() {
+	b.kv.Clear(contractBucket)
+	b.kv.Clear(storageBucket)
+}
+
+// PutReceipt sets the serialized receipt of an execution
+func (b *Batch) PutReceipt(hash []byte, receipt []byte) {
+	b.kv.Put(receiptBucket, hash, receipt)
+}
+
+// DeleteReceipt removes the receipt of an execution
+func (b *Batch) DeleteReceipt(hash []byte) {
+	b.kv.Delete(receiptBucket, hash)
+}
+
+// PutBloom sets the bloom filter of the logs emitted in the block at the height
+func (b *Batch) PutBloom(h uint32, bloom []byte) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Put(bloomBucket, height, bloom)
+}
+
+// PutUtxoCommitment sets the commitment to the UTXO set once the block at the height is applied
+func (b *Batch) PutUtxoCommitment(h uint32, commitment []byte) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Put(utxoCommitmentBucket, height, commitment)
+}
+
+// PutStateUndo sets the UTXO entries and accounts changed by the block at the height as they are before it
+func (b *Batch) PutStateUndo(h uint32, undo []byte) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Put(stateUndoBucket, height, undo)
+}
+
+// DeleteStateUndo adds deleting the state undo of the block at the height to the batch
+func (b *Batch) DeleteStateUndo(h uint32) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Delete(stateUndoBucket, height)
+}
+
+// PutTxTotal sets the number of transactions in the blocks up to the height
+func (b *Batch) PutTxTotal(h uint32, total uint64) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	v := make([]byte, 8)
+	cm.MachineEndian.PutUint64(v, total)
+	b.kv.Put(txTotalBucket, height, v)
+}
+
+// PutDelegates sets the serialized list of the delegates elected for the epoch
+func (b *Batch) PutDelegates(e uint32, delegates []byte) {
+	epoch := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(epoch, e)
+	b.kv.Put(delegatesBucket, epoch, delegates)
+}
+
+// DeleteDelegates removes the list of the delegates elected for the epoch
+func (b *Batch) DeleteDelegates(e uint32) {
+	epoch := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(epoch, e)
+	b.kv.Delete(delegatesBucket, epoch)
+}
+
+// storageKey returns the key in storageBucket of the key in the storage of a contract
+func storageKey(addr []byte, key []byte) []byte {
+	return append(append(append([]byte{}, addr...), storageSeparator), key...)
+}
+
+// PutUtxoHeight records the height of the block the UTXO is updated to
+func (b *Batch) PutUtxoHeight(h uint32) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Put(blocksBucket, utxoHeight, height)
+}
+
+// PutSupply records the amount emitted and burned as of the block the UTXO is updated to
+func (b *Batch) PutSupply(emitted uint64, burned uint64) {
+	v := make([]byte, 16)
+	cm.MachineEndian.PutUint64(v, emitted)
+	cm.MachineEndian.PutUint64(v[8:], burned)
+	b.kv.Put(blocksBucket, supply, v)
+}
+
+// PruneBlock adds deleting the block body to the batch, only the serialized header of the block is kept
+func (b *Batch) PruneBlock(hash []byte, header []byte) {
+	b.kv.Put(headersBucket, hash, header)
+	b.kv.Delete(blocksBucket, hash)
+}
+
+// RemoveBlocks adds removing the blocks above height h, whose hashes are given from h+1 up, along with their headers,
+// metadata, hash <-> height mapping and the records kept by their heights, and moving the tip back to the block at h
+// to the batch
+func (b *Batch) RemoveBlocks(hashes [][]byte, h uint32, tip []byte) {
+	for i, hash := range hashes {
+		height := []byte{0, 0, 0, 0}
+		cm.MachineEndian.PutUint32(height, h+1+uint32(i))
+		b.kv.Delete(hashHeightBucket, height)
+		b.kv.Delete(hashHeightBucket, hash)
+		b.kv.Delete(blocksBucket, hash)
+		b.kv.Delete(headersBucket, hash)
+		b.kv.Delete(blockMetaBucket, hash)
+		b.kv.Delete(bloomBucket, height)
+		b.kv.Delete(utxoCommitmentBucket, height)
+		b.kv.Delete(txTotalBucket, height)
+		b.kv.Delete(stateUndoBucket, height)
+	}
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Put(blocksBucket, tipHash, tip)
+	b.kv.Put(blocksBucket, tipHeight, height)
+}
+
+// PutPruneHeight adds the height below which the block bodies have been pruned to the batch
+func (b *Batch) PutPruneHeight(h uint32) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Put(blocksBucket, pruneHeight, height)
+}
+
+// PutFinalHeight adds the height of the highest block finalized to the batch
+func (b *Batch) PutFinalHeight(h uint32) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Put(blocksBucket, finalHeight, height)
+}
+
+// PutHeightIndex adds the hash <-> height mapping of a block already in DB without moving the tip, e.g., when
+// reindexing the blocks
+func (b *Batch) PutHeightIndex(hash []byte, h uint32) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Put(hashHeightBucket, hash, height)
+	b.kv.Put(hashHeightBucket, height, hash)
+}
+
+// StartReindex adds removing all the indexes, the UTXO, the states derived from the blocks and the statistics to the
+// batch, which are rebuilt from the blocks starting with the genesis block
+// Only the blocks, their headers and the tip are kept.
+func (b *Batch) StartReindex() {
+	for _, bucket := range [][]byte{
+		hashHeightBucket, txIndexBucket, utxoBucket, accountBucket, contractBucket, storageBucket, receiptBucket,
+		bloomBucket, delegatesBucket, evidenceIndexBucket, withdrawIndexBucket, utxoCommitmentBucket, txTotalBucket,
+		stateUndoBucket,
+	} {
+		b.kv.Clear(bucket)
+	}
+	b.kv.Delete(blocksBucket, utxoHeight)
+	b.kv.Delete(blocksBucket, supply)
+	b.PutReindexNext(0)
+}
+
+// PutReindexNext records the height of the next block to reindex, so an interrupted reindex resumes from it
+func (b *Batch) PutReindexNext(h uint32) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, h)
+	b.kv.Put(blocksBucket, reindexNext, height)
+}
+
+// FinishReindex adds removing the reindex progress to the batch once all the blocks are reindexed
+func (b *Batch) FinishReindex() {
+	b.kv.Delete(blocksBucket, reindexNext)
+}
+
+// Commit writes all the writes in the batch into DB in a single transaction
+// The blocks are checked against collision and compressed if enabled before, which relies on the caller serializing the commits. A batch
+// moving the tip is preceded by a pending commit marker, which the batch removes, so a commit interrupted by a crash
+// is rolled back on the next start. ErrReadOnly is returned if the DB is opened read-only.
+func (db *BlockDB) Commit(b *Batch) error {
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	kv := &KVBatch{append([]kvWrite{}, b.kv.writes...)}
+	for _, entry := range b.blocks {
+		_, err := db.kv.Get(blocksBucket, entry.hash)
+		if err == nil {
+			return errors.Wrapf(ErrAlreadyExist, "New block hash %x", entry.hash)
+		}
+		if errors.Cause(err) != ErrNotExist {
+			return err
+		}
+		blk, err := db.encodeBlock(entry.blk)
+		if err != nil {
+			return errors.Wrapf(err, "Compressing block %x", entry.hash)
+		}
+		kv.Put(blocksBucket, entry.hash, blk)
+	}
+	if len(b.tips) > 0 {
+		if err := db.markPendingCommit(b.tips); err != nil {
+			return err
+		}
+		kv.Delete(blocksBucket, pendingCommit)
+	}
+	return db.kv.Commit(kv)
+}
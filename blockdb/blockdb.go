@@ -7,236 +7,487 @@
 package blockdb
 
 import (
-	"io/ioutil"
+	"bytes"
+	"encoding/hex"
 	"os"
 
-	"github.com/boltdb/bolt"
-	"github.com/golang/glog"
-	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
+	"golang.org/x/net/context"
 
 	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/config"
 	cp "github.com/iotexproject/iotex-core/crypto"
-	"github.com/iotexproject/iotex-core/proto"
-)
-
-// Directory of block data
-const (
-	BlockData = "../block.dat"
+	"github.com/iotexproject/iotex-core/logger"
 )
 
 var (
 	tipHash    = []byte("tip.hash")
 	tipHeight  = []byte("tip.height")
 	utxoHeight = []byte("utxo.height")
+	// block bodies below prune height have been deleted
+	pruneHeight = []byte("prune.height")
+	// blocks at and below final height have been finalized by the delegates, so the chain is never reorganized below
+	finalHeight = []byte("final.height")
+	// amount emitted by the block rewards and the part of it burned, as of the UTXO height
+	supply = []byte("supply")
+	// previous tip and the blocks of a commit moving the tip, which is removed once the commit completes
+	pendingCommit = []byte("commit.pending")
+	// version of the DB layout
+	schemaVersion = []byte("schema.version")
+	// height of the next block to reindex while the indexes are being rebuilt from the blocks
+	reindexNext = []byte("reindex.next")
 
 	// bucket to store serialized block
 	blocksBucket = []byte("blocks")
 
 	// bucket to store block height <-> hash
 	hashHeightBucket = []byte("hash<->height")
+
+	// bucket to store tx hash -> block hash
+	txIndexBucket = []byte("tx->block")
+
+	// bucket to store tx hash -> serialized unspent outputs of the tx
+	utxoBucket = []byte("utxo")
+
+	// bucket to store block hash -> serialized header of pruned blocks
+	headersBucket = []byte("headers")
+
+	// bucket to store address -> serialized account state, updated along with the UTXO
+	accountBucket = []byte("account")
+
+	// bucket to store contract address -> code, updated along with the UTXO
+	contractBucket = []byte("contract")
+
+	// bucket to store contract address/key -> value of the contract storage, updated along with the UTXO
+	storageBucket = []byte("storage")
+
+	// bucket to store execution hash -> serialized receipt
+	receiptBucket = []byte("receipt")
+
+	// bucket to store block height -> bloom filter of the logs emitted in the block
+	bloomBucket = []byte("bloom")
+
+	// bucket to store epoch -> serialized list of the delegates elected for the epoch
+	delegatesBucket = []byte("delegates")
+
+	// bucket to store evidence hash -> hash of the block committing the evidence
+	evidenceIndexBucket = []byte("evidence->block")
+
+	// bucket to store deposit hash -> hash of the block committing the withdraw of the deposit
+	withdrawIndexBucket = []byte("withdraw->block")
+
+	// bucket to store block height -> commitment to the UTXO set once the block is applied
+	utxoCommitmentBucket = []byte("utxo.commitment")
+
+	// bucket to store block height -> number of transactions in the blocks up to the height
+	txTotalBucket = []byte("tx.total")
+
+	// bucket to store tx hash -> unspent outputs of the tx spilled out of memory, only valid for the process writing it
+	coldUtxoBucket = []byte("utxo.cold")
+
+	// bucket to store block hash -> metadata of the block, which is read without deserializing the block
+	blockMetaBucket = []byte("block.meta")
+
+	// bucket to store block height -> UTXO entries and accounts changed by the block as they are before it
+	stateUndoBucket = []byte("state.undo")
 )
 
+// storageSeparator separates the contract address from the key in storageBucket, it is not a character of addresses
+const storageSeparator = '/'
+
 var (
 	// ErrNotExist indicates certain item does not exist in Blockchain database
 	ErrNotExist = errors.New("not exist in DB")
 	// ErrAlreadyExist indicates certain item already exists in Blockchain database
 	ErrAlreadyExist = errors.New("already exist in DB")
+	// ErrGenesisMismatch indicates the DB stores the chain of another network than the one of the config
+	ErrGenesisMismatch = errors.New("genesis block does not match the network")
 )
 
+// log is the logger of the package, which a BlockDB uses unless another one is injected
+var log = logger.New("blockdb")
+
 // BlockDB defines the DB interface to read/store/persist blocks
 type BlockDB struct {
-	*bolt.DB
+	log *logger.Logger
+	kv  KVStore
+	// compress compresses the blocks written
+	compress bool
+	// readOnly rejects all the writes
+	readOnly bool
 }
 
-// NewBlockDB returns a new BlockDB instance
-func NewBlockDB(cfg *config.Config) (*BlockDB, bool) {
-	exist := fileExists(cfg.Chain.ChainDBPath)
-
-	// create/open database file
-	db, err := bolt.Open(cfg.Chain.ChainDBPath, 0600, nil)
+// NewBlockDB returns a new BlockDB instance on the KV store of the config, and whether the DB already exists
+// An existing DB is migrated to the schema version of the binary, and ErrSchemaTooNew is returned if it is newer.
+func NewBlockDB(cfg *config.Config) (*BlockDB, bool, error) {
+	kv, exist, err := NewKVStore(&cfg.Chain)
 	if err != nil {
-		glog.Fatalf("Failed to open Blockchain Db, error = %v", err)
-		return nil, exist
+		return nil, exist, err
 	}
 
+	db := &BlockDB{log: log, kv: meteredStore{kv}, compress: cfg.Chain.CompressBlock}
 	if !exist {
-		// create buckets
-		if err := db.Update(func(tx *bolt.Tx) error {
-			b, err := tx.CreateBucket(blocksBucket)
-			if err != nil {
-				return errors.Wrap(err, "Creating bucket for blocks")
-			}
-
-			// set init value for tip hash and height
-			if err := b.Put(tipHash, cp.ZeroHash32B[:]); err != nil {
-				return errors.Wrap(err, "Writing init value for tipHash")
-			}
-
-			zero := []byte{0, 0, 0, 0}
-			if err := b.Put(tipHeight, zero); err != nil {
-				return errors.Wrap(err, "Writing init value for tipHeight")
-			}
-
-			if b, err = tx.CreateBucket(hashHeightBucket); err != nil {
-				return errors.Wrap(err, "Creating bucket for hash <-> height mapping")
-			}
-			return nil
-		}); err != nil {
-			glog.Fatal(err)
-			return nil, exist
+		// set init value for tip hash and height
+		version := []byte{0, 0, 0, 0}
+		cm.MachineEndian.PutUint32(version, SchemaVersion)
+		batch := NewKVBatch()
+		batch.Put(blocksBucket, tipHash, cp.ZeroHash32B[:])
+		batch.Put(blocksBucket, tipHeight, []byte{0, 0, 0, 0})
+		batch.Put(blocksBucket, schemaVersion, version)
+		if err := kv.Commit(batch); err != nil {
+			kv.Close()
+			return nil, exist, errors.Wrap(err, "Writing init value for tip")
 		}
+	} else if err := db.migrate(); err != nil {
+		kv.Close()
+		return nil, exist, err
+	} else if err := db.checkGenesis(cfg); err != nil {
+		kv.Close()
+		return nil, exist, err
+	}
+	return db, exist, nil
+}
+
+// checkGenesis returns ErrGenesisMismatch if the DB stores a genesis block other than the one of the network of the
+// config, a DB without genesis block yet is of any network
+func (db *BlockDB) checkGenesis(cfg *config.Config) error {
+	preset, ok := config.Presets[cfg.Net]
+	if !ok {
+		return nil
+	}
+	hash, err := db.GetBlockHash(0)
+	if errors.Cause(err) == ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(hash) != preset.GenesisHash {
+		return errors.Wrapf(ErrGenesisMismatch, "genesis block %x, %s expecting %s", hash, cfg.Net, preset.GenesisHash)
 	}
-	return &BlockDB{db}, exist
+	return nil
 }
 
-// Init initializes the BlockDB instance
+// Init initializes the BlockDB instance, after recovering from a commit interrupted by a crash unless opened read-only
 func (db *BlockDB) Init() (hash []byte, height uint32, err error) {
-	// verify all buckets are properly created
-	// so from this point on later calls don't need to sanity check again
-	err = db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(hashHeightBucket)
-		if b == nil {
-			return errors.Wrap(bolt.ErrBucketNotFound, "Bucket for hash <-> height mapping")
+	// the tip is moved along with the blocks in a single transaction, so a DB opened read-only is consistent as it is
+	if !db.readOnly {
+		if err = db.recover(); err != nil {
+			err = errors.Wrap(err, "Recovering Blockchain Db")
+			return
 		}
+	}
 
-		b = tx.Bucket(blocksBucket)
-		if b == nil {
-			return errors.Wrap(bolt.ErrBucketNotFound, "Bucket for blocks")
-		}
+	// get tip hash and height
+	if hash, err = db.kv.Get(blocksBucket, tipHash); err != nil {
+		err = errors.Wrap(err, "Blockchain tip")
+		return
+	}
+	h, err := db.kv.Get(blocksBucket, tipHeight)
+	if err != nil {
+		err = errors.Wrap(err, "Blockchain height")
+		return
+	}
+	height = cm.MachineEndian.Uint32(h)
+	return
+}
 
-		// get tip hash and height
-		if hash = b.Get(tipHash); hash == nil {
-			return errors.Wrap(ErrNotExist, "Blockchain tip")
-		}
+// SetLogger injects the logger of the DB
+func (db *BlockDB) SetLogger(l *logger.Logger) {
+	db.log = l
+}
 
-		h := b.Get(tipHeight)
-		if h == nil {
-			return errors.Wrap(ErrNotExist, "Blockchain height")
-		}
-		height = cm.MachineEndian.Uint32(h)
+// Sync flushes the blocks and the UTXO committed so far to the disk
+func (db *BlockDB) Sync() error {
+	if db.readOnly {
 		return nil
-	})
-	return
+	}
+	return db.kv.Sync()
+}
+
+// Close closes the KV store
+func (db *BlockDB) Close() error {
+	return db.kv.Close()
 }
 
 // GetBlockHash returns the block hash by height
-func (db *BlockDB) GetBlockHash(height uint32) (hash []byte, err error) {
-	err = db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(hashHeightBucket)
-		// get block hash at passed-in height
-		dbHeight := []byte{0, 0, 0, 0}
-		cm.MachineEndian.PutUint32(dbHeight, height)
-		if hash = b.Get(dbHeight); hash == nil {
-			return errors.Wrapf(ErrNotExist, "Block with height = %d", height)
-		}
-		return nil
-	})
-	return
+func (db *BlockDB) GetBlockHash(height uint32) ([]byte, error) {
+	dbHeight := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(dbHeight, height)
+	hash, err := db.kv.Get(hashHeightBucket, dbHeight)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Block with height = %d", height)
+	}
+	return hash, nil
 }
 
 // GetBlockHeight returns the block height by hash
-func (db *BlockDB) GetBlockHeight(hash []byte) (height uint32, err error) {
-	err = db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(hashHeightBucket)
-		// get the height corresponding to passed in hash
-		var dbHeight []byte
-		if dbHeight = b.Get(hash); dbHeight == nil {
-			return errors.Wrapf(ErrNotExist, "Block with hash = %x", hash)
-		}
-		height = cm.MachineEndian.Uint32(dbHeight)
-		return nil
-	})
-	return
+func (db *BlockDB) GetBlockHeight(hash []byte) (uint32, error) {
+	dbHeight, err := db.kv.Get(hashHeightBucket, hash)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Block with hash = %x", hash)
+	}
+	return cm.MachineEndian.Uint32(dbHeight), nil
 }
 
 // CheckOutBlock checks a block out of DB
-func (db *BlockDB) CheckOutBlock(hash []byte) (blk []byte, err error) {
-	err = db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(blocksBucket)
+func (db *BlockDB) CheckOutBlock(hash []byte) ([]byte, error) {
+	blk, err := db.kv.Get(blocksBucket, hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Block with hash = %x", hash)
+	}
+	return decodeBlock(blk)
+}
 
-		// get the block with passed-in hash
-		if blk = b.Get(hash); blk == nil {
-			return errors.Wrapf(ErrNotExist, "Block with hash = %x", hash)
+// CheckOutBlocks checks the blocks with height in [start, end] out of DB, stopping with the error of ctx once it is
+// done
+func (db *BlockDB) CheckOutBlocks(ctx context.Context, start uint32, end uint32) ([][]byte, error) {
+	blks := [][]byte{}
+	for height := start; height >= start && height <= end; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-		return nil
-	})
-	return
+		hash, err := db.GetBlockHash(height)
+		if err != nil {
+			return nil, err
+		}
+		blk, err := db.CheckOutBlock(hash)
+		if err != nil {
+			return nil, err
+		}
+		blks = append(blks, blk)
+	}
+	return blks, nil
+}
+
+// CheckOutHeader returns the header of a pruned block
+func (db *BlockDB) CheckOutHeader(hash []byte) ([]byte, error) {
+	header, err := db.kv.Get(headersBucket, hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Header with hash = %x", hash)
+	}
+	return header, nil
+}
+
+// GetBlockMeta returns the metadata of the block
+func (db *BlockDB) GetBlockMeta(hash []byte) ([]byte, error) {
+	meta, err := db.kv.Get(blockMetaBucket, hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Block meta with hash = %x", hash)
+	}
+	return meta, nil
+}
+
+// Supply returns the amount emitted and burned as of the UTXO height
+// ErrNotExist is returned if the supply has never been persisted
+func (db *BlockDB) Supply() (emitted uint64, burned uint64, err error) {
+	v, err := db.kv.Get(blocksBucket, supply)
+	if err != nil && errors.Cause(err) != ErrNotExist {
+		return 0, 0, err
+	}
+	if len(v) != 16 {
+		return 0, 0, errors.Wrap(ErrNotExist, "supply")
+	}
+	return cm.MachineEndian.Uint64(v), cm.MachineEndian.Uint64(v[8:]), nil
+}
+
+// GetPruneHeight returns the height below which the block bodies have been pruned, 0 if nothing is pruned
+func (db *BlockDB) GetPruneHeight() (uint32, error) {
+	h, err := db.kv.Get(blocksBucket, pruneHeight)
+	if errors.Cause(err) == ErrNotExist {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return cm.MachineEndian.Uint32(h), nil
+}
+
+// Size returns the number of bytes the data of the DB takes on its backend
+func (db *BlockDB) Size() (int64, error) {
+	return db.kv.Size()
+}
+
+// GetFinalHeight returns the height of the highest block finalized, 0 if no block is finalized
+func (db *BlockDB) GetFinalHeight() (uint32, error) {
+	h, err := db.kv.Get(blocksBucket, finalHeight)
+	if errors.Cause(err) == ErrNotExist {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return cm.MachineEndian.Uint32(h), nil
+}
+
+// GetReindexNext returns the height of the next block to reindex if the indexes are being rebuilt from the blocks,
+// ErrNotExist is returned if no reindex is in progress
+func (db *BlockDB) GetReindexNext() (uint32, error) {
+	h, err := db.kv.Get(blocksBucket, reindexNext)
+	if err != nil {
+		return 0, err
+	}
+	return cm.MachineEndian.Uint32(h), nil
 }
 
 // CheckInBlock checks a block into DB
 func (db *BlockDB) CheckInBlock(blk []byte, hash []byte, h uint32) error {
-	return db.Update(func(tx *bolt.Tx) error {
-		// new block hash should not collide with any existing blocks
-		b := tx.Bucket(blocksBucket)
-		if collide := b.Get(hash); collide != nil {
-			return errors.Wrapf(ErrAlreadyExist, "New block hash %x", hash)
-		}
+	batch := NewBatch()
+	batch.PutBlock(blk, hash, h)
+	return db.Commit(batch)
+}
 
-		// prepare tip height
-		height := []byte{0, 0, 0, 0}
-		cm.MachineEndian.PutUint32(height, h)
+// GetTxBlockHash returns the hash of the block containing the transaction
+func (db *BlockDB) GetTxBlockHash(txHash []byte) ([]byte, error) {
+	hash, err := db.kv.Get(txIndexBucket, txHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Tx with hash = %x", txHash)
+	}
+	return hash, nil
+}
 
-		// update tip hash/height
-		if err := b.Put(tipHash, hash); err != nil {
-			return errors.Wrapf(err, "Writing tipHash = %x", hash)
-		}
+// GetEvidenceBlockHash returns the hash of the block committing the evidence
+func (db *BlockDB) GetEvidenceBlockHash(evidenceHash []byte) ([]byte, error) {
+	hash, err := db.kv.Get(evidenceIndexBucket, evidenceHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Evidence with hash = %x", evidenceHash)
+	}
+	return hash, nil
+}
 
-		if err := b.Put(tipHeight, height); err != nil {
-			return errors.Wrapf(err, "Writing tipHeight = %v", height)
-		}
+// GetWithdrawBlockHash returns the hash of the block committing the withdraw of the deposit
+func (db *BlockDB) GetWithdrawBlockHash(depositHash []byte) ([]byte, error) {
+	hash, err := db.kv.Get(withdrawIndexBucket, depositHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Withdraw of deposit with hash = %x", depositHash)
+	}
+	return hash, nil
+}
 
-		// commit the block data into Db
-		if err := b.Put(hash, blk); err != nil {
-			return errors.Wrapf(err, "Writing block = %x", hash)
-		}
+// Utxos returns all serialized unspent outputs keyed by tx hash, and the height of the block they are updated to
+// ErrNotExist is returned if the UTXO has never been persisted
+func (db *BlockDB) Utxos() (map[string][]byte, uint32, error) {
+	h, err := db.kv.Get(blocksBucket, utxoHeight)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "UTXO height")
+	}
 
-		// update hash <-> height mapping
-		b = tx.Bucket(hashHeightBucket)
-		if err := b.Put(hash, height); err != nil {
-			return errors.Wrapf(err, "Updating hash <-> height mapping height = %v", height)
-		}
+	utxos := make(map[string][]byte)
+	if err := db.kv.Iterate(utxoBucket, func(k, v []byte) error {
+		utxos[string(k)] = append([]byte{}, v...)
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+	return utxos, cm.MachineEndian.Uint32(h), nil
+}
 
-		if err := b.Put(height, hash); err != nil {
-			return errors.Wrapf(err, "Updating hash <-> height mapping hash = %x", hash)
-		}
+// Accounts returns the serialized account states by address, which are updated to the UTXO height
+func (db *BlockDB) Accounts() (map[string][]byte, error) {
+	accounts := make(map[string][]byte)
+	if err := db.kv.Iterate(accountBucket, func(k, v []byte) error {
+		accounts[string(k)] = append([]byte{}, v...)
 		return nil
-	})
+	}); err != nil {
+		return nil, err
+	}
+	return accounts, nil
 }
 
-// StoreBlockToFile writes block raw data into file
-func (db *BlockDB) StoreBlockToFile(start, end uint32) error {
-	data := []byte{}
-	offset := []uint32{}
-	seek := uint32(0)
-	for height := start; height <= end; height++ {
-		hash, err := db.GetBlockHash(height)
-		if err != nil {
-			return err
+// Codes returns the code of the contracts by address, which are updated to the UTXO height
+func (db *BlockDB) Codes() (map[string][]byte, error) {
+	codes := make(map[string][]byte)
+	if err := db.kv.Iterate(contractBucket, func(k, v []byte) error {
+		codes[string(k)] = append([]byte{}, v...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// States returns the storage of the contracts by address then key, which are updated to the UTXO height
+func (db *BlockDB) States() (map[string]map[string][]byte, error) {
+	states := make(map[string]map[string][]byte)
+	if err := db.kv.Iterate(storageBucket, func(k, v []byte) error {
+		i := bytes.IndexByte(k, storageSeparator)
+		if i < 0 {
+			return errors.Errorf("Storage key %x has no contract address", k)
 		}
-		bytes, err := db.CheckOutBlock(hash[:])
-		if err != nil {
-			return err
+		addr := string(k[:i])
+		if states[addr] == nil {
+			states[addr] = make(map[string][]byte)
 		}
-		offset = append(offset, seek)
-		seek += uint32(len(bytes))
-		data = append(data, bytes...)
+		states[addr][string(k[i+1:])] = append([]byte{}, v...)
+		return nil
+	}); err != nil {
+		return nil, err
 	}
-	offset = append(offset, seek)
+	return states, nil
+}
 
-	// create block index
-	index, err := proto.Marshal(&iproto.BlockIndex{start, end, offset})
+// GetReceipt returns the serialized receipt of the execution
+func (db *BlockDB) GetReceipt(hash []byte) ([]byte, error) {
+	receipt, err := db.kv.Get(receiptBucket, hash)
 	if err != nil {
-		return err
+		return nil, errors.Wrapf(err, "Receipt of execution %x", hash)
+	}
+	return receipt, nil
+}
+
+// GetBloom returns the bloom filter of the logs emitted in the block at the height
+func (db *BlockDB) GetBloom(height uint32) ([]byte, error) {
+	dbHeight := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(dbHeight, height)
+	bloom, err := db.kv.Get(bloomBucket, dbHeight)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Bloom of block with height = %d", height)
+	}
+	return bloom, nil
+}
+
+// GetUtxoCommitment returns the commitment to the UTXO set once the block at the height is applied
+func (db *BlockDB) GetUtxoCommitment(height uint32) ([]byte, error) {
+	dbHeight := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(dbHeight, height)
+	commitment, err := db.kv.Get(utxoCommitmentBucket, dbHeight)
+	if err != nil {
+		return nil, errors.Wrapf(err, "UTXO commitment of block with height = %d", height)
+	}
+	return commitment, nil
+}
+
+// GetStateUndo returns the UTXO entries and accounts changed by the block at the height as they are before it
+func (db *BlockDB) GetStateUndo(height uint32) ([]byte, error) {
+	dbHeight := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(dbHeight, height)
+	undo, err := db.kv.Get(stateUndoBucket, dbHeight)
+	if err != nil {
+		return nil, errors.Wrapf(err, "state undo of block with height = %d", height)
 	}
+	return undo, nil
+}
 
-	// first 4-byte of file is the size of block index
-	size := []byte{0, 0, 0, 0}
-	cm.MachineEndian.PutUint32(size, uint32(len(index)))
-	file := append(size, index...)
-	file = append(file, data...)
-	return ioutil.WriteFile(BlockData, file, 0600)
+// GetTxTotal returns the number of transactions in the blocks up to the height
+func (db *BlockDB) GetTxTotal(height uint32) (uint64, error) {
+	dbHeight := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(dbHeight, height)
+	total, err := db.kv.Get(txTotalBucket, dbHeight)
+	if err != nil {
+		return 0, errors.Wrapf(err, "transaction total of block with height = %d", height)
+	}
+	return cm.MachineEndian.Uint64(total), nil
+}
+
+// GetDelegates returns the serialized list of the delegates elected for the epoch
+func (db *BlockDB) GetDelegates(epoch uint32) ([]byte, error) {
+	dbEpoch := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(dbEpoch, epoch)
+	delegates, err := db.kv.Get(delegatesBucket, dbEpoch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Delegates of epoch %d", epoch)
+	}
+	return delegates, nil
 }
 
 // fileExists checks if a file already exists
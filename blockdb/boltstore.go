@@ -0,0 +1,132 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+)
+
+// boltStore is the KVStore of a BoltDB file, with a bolt bucket per bucket
+type boltStore struct {
+	db *bolt.DB
+}
+
+// newBoltStore opens the BoltDB file at the path, and returns whether the file already existed
+func newBoltStore(path string, noSync bool) (*boltStore, bool, error) {
+	exist := fileExists(path)
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, exist, errors.Wrapf(err, "Opening Blockchain Db %s", path)
+	}
+	db.NoSync = noSync
+	return &boltStore{db}, exist, nil
+}
+
+// openBoltStoreReadOnly opens the existing BoltDB file at the path without writing it
+// BoltDB locks the file for the process writing it, so opening a file in use by a node times out.
+func openBoltStoreReadOnly(path string, timeout time.Duration) (*boltStore, error) {
+	if !fileExists(path) {
+		return nil, errors.Wrapf(ErrNotExist, "Blockchain Db %s", path)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: timeout})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Opening Blockchain Db %s read-only", path)
+	}
+	return &boltStore{db}, nil
+}
+
+// Get returns a copy of the value, as bolt values are only valid during the transaction
+func (s *boltStore) Get(bucket []byte, key []byte) (value []byte, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return ErrNotExist
+		}
+		v := b.Get(key)
+		if v == nil {
+			return ErrNotExist
+		}
+		value = append([]byte{}, v...)
+		return nil
+	})
+	return
+}
+
+func (s *boltStore) Put(bucket []byte, key []byte, value []byte) error {
+	batch := NewKVBatch()
+	batch.Put(bucket, key, value)
+	return s.Commit(batch)
+}
+
+func (s *boltStore) Delete(bucket []byte, key []byte) error {
+	batch := NewKVBatch()
+	batch.Delete(bucket, key)
+	return s.Commit(batch)
+}
+
+func (s *boltStore) Iterate(bucket []byte, fn func(k []byte, v []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(fn)
+	})
+}
+
+// Commit writes the batch in a single bolt transaction, creating the buckets on demand
+func (s *boltStore) Commit(batch *KVBatch) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, write := range batch.writes {
+			switch write.op {
+			case putOp:
+				b, err := tx.CreateBucketIfNotExists(write.bucket)
+				if err != nil {
+					return errors.Wrapf(err, "Creating bucket %s", write.bucket)
+				}
+				if err := b.Put(write.key, write.value); err != nil {
+					return errors.Wrapf(err, "Writing %x to bucket %s", write.key, write.bucket)
+				}
+			case deleteOp:
+				if b := tx.Bucket(write.bucket); b != nil {
+					if err := b.Delete(write.key); err != nil {
+						return errors.Wrapf(err, "Deleting %x from bucket %s", write.key, write.bucket)
+					}
+				}
+			case clearOp:
+				if err := tx.DeleteBucket(write.bucket); err != nil && err != bolt.ErrBucketNotFound {
+					return errors.Wrapf(err, "Deleting bucket %s", write.bucket)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Sync fsyncs the file, which is only needed if the commits are not synced as in NoSync mode
+func (s *boltStore) Sync() error {
+	if !s.db.NoSync {
+		return nil
+	}
+	return s.db.Sync()
+}
+
+// Size returns the size of the file less the free pages, which the file does not give back once allocated
+func (s *boltStore) Size() (size int64, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		return nil
+	})
+	return size - int64(s.db.Stats().FreeAlloc), err
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package blockdb
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ColdUtxoStore keeps the UTXO entries a chain spills out of memory, keyed by transaction hash
+// The entries are in the in-memory encoding of the chain and are only meaningful to the process writing them, hence
+// the store is emptied when it is opened.
+type ColdUtxoStore struct {
+	kv KVStore
+}
+
+// ColdUtxos returns the store of the UTXO entries spilled out of memory, dropping the ones left by the last run
+func (db *BlockDB) ColdUtxos() (*ColdUtxoStore, error) {
+	if db.readOnly {
+		return nil, ErrReadOnly
+	}
+	batch := NewKVBatch()
+	batch.Clear(coldUtxoBucket)
+	if err := db.kv.Commit(batch); err != nil {
+		return nil, errors.Wrap(err, "Clearing cold UTXO")
+	}
+	return &ColdUtxoStore{db.kv}, nil
+}
+
+// Get returns the entry of the transaction hash, or ErrNotExist
+func (s *ColdUtxoStore) Get(txHash []byte) ([]byte, error) {
+	return s.kv.Get(coldUtxoBucket, txHash)
+}
+
+// Write deletes the entries of the hashes in del then puts the entries in put at once
+func (s *ColdUtxoStore) Write(put map[string][]byte, del [][]byte) error {
+	batch := NewKVBatch()
+	for _, txHash := range del {
+		batch.Delete(coldUtxoBucket, txHash)
+	}
+	for txHash, entry := range put {
+		batch.Put(coldUtxoBucket, []byte(txHash), entry)
+	}
+	return s.kv.Commit(batch)
+}
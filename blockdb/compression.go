@@ -0,0 +1,64 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"bytes"
+	"compress/flate"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// A compressed block record starts with the compressed flag and the codec, followed by the compressed block. The
+// flag is a zero byte, which no serialized block starts with as it is not a valid protobuf tag, so blocks written
+// without compression are stored as they are and still read.
+const (
+	compressedFlag = byte(0)
+	// flateCodec is the DEFLATE codec
+	flateCodec = byte(1)
+)
+
+// encodeBlock compresses the serialized block if compression is enabled and it makes the record smaller
+func (db *BlockDB) encodeBlock(blk []byte) ([]byte, error) {
+	if !db.compress || len(blk) == 0 {
+		return blk, nil
+	}
+	var buf bytes.Buffer
+	buf.Write([]byte{compressedFlag, flateCodec})
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(blk); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() >= len(blk) {
+		return blk, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeBlock returns the serialized block of the record, decompressing it if flagged
+func decodeBlock(record []byte) ([]byte, error) {
+	if len(record) == 0 || record[0] != compressedFlag {
+		return record, nil
+	}
+	if len(record) < 2 || record[1] != flateCodec {
+		return nil, errors.Errorf("Unknown block compression codec %x", record[1:2])
+	}
+	r := flate.NewReader(bytes.NewReader(record[2:]))
+	defer r.Close()
+	blk, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decompressing block")
+	}
+	return blk, nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func TestBlockCompression(t *testing.T) {
+	assert := assert.New(t)
+	db, _, err := NewBlockDB(&config.Config{Chain: config.Chain{ChainDBBackend: MemoryBackend}})
+	assert.Nil(err)
+	defer db.Close()
+
+	// a block written without compression
+	raw := bytes.Repeat([]byte{0x0a, 0x20, 0x01}, 100)
+	assert.Nil(db.CheckInBlock(raw, []byte("hash1"), 1))
+
+	// blocks written with compression are flagged, unless compressing does not make them smaller
+	db.compress = true
+	compressible := bytes.Repeat([]byte{0x0a, 0x20, 0x02}, 100)
+	assert.Nil(db.CheckInBlock(compressible, []byte("hash2"), 2))
+	record, err := db.kv.Get(blocksBucket, []byte("hash2"))
+	assert.Nil(err)
+	assert.Equal(compressedFlag, record[0])
+	assert.True(len(record) < len(compressible))
+	small := []byte{0x0a, 0x01, 0x03}
+	assert.Nil(db.CheckInBlock(small, []byte("hash3"), 3))
+	record, err = db.kv.Get(blocksBucket, []byte("hash3"))
+	assert.Nil(err)
+	assert.Equal(small, record)
+
+	// both are read back as they were written
+	blks, err := db.CheckOutBlocks(context.Background(), 1, 3)
+	assert.Nil(err)
+	assert.Equal([][]byte{raw, compressible, small}, blks)
+
+	// a record of an unknown codec is rejected
+	assert.Nil(db.kv.Put(blocksBucket, []byte("hash4"), []byte{compressedFlag, 0xff, 0x00}))
+	_, err = db.CheckOutBlock([]byte("hash4"))
+	assert.NotNil(err)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+const (
+	// BoltBackend stores the chain in a BoltDB file, which syncs every commit to disk unless ChainDBNoSync is set
+	BoltBackend = "BOLT"
+	// MemoryBackend keeps the chain in memory only, which is the fastest and loses everything on exit
+	MemoryBackend = "MEMORY"
+)
+
+// KVStore is the key-value store the BlockDB persists to, where the keys are grouped in buckets
+type KVStore interface {
+	// Get returns the value of the key in the bucket, or ErrNotExist
+	Get(bucket []byte, key []byte) ([]byte, error)
+	// Put sets the value of the key in the bucket
+	Put(bucket []byte, key []byte, value []byte) error
+	// Delete removes the key from the bucket
+	Delete(bucket []byte, key []byte) error
+	// Iterate calls fn with every key and value of the bucket in key order, until fn returns error
+	// The key and value are only valid during the call.
+	Iterate(bucket []byte, fn func(k []byte, v []byte) error) error
+	// Commit writes all the writes of the batch atomically
+	Commit(batch *KVBatch) error
+	// Sync flushes the committed writes to the disk
+	Sync() error
+	// Size returns the number of bytes the data of the store takes, not counting the space freed for reuse
+	Size() (int64, error)
+	// Close closes the store
+	Close() error
+}
+
+type kvOp int
+
+const (
+	putOp kvOp = iota
+	deleteOp
+	clearOp
+)
+
+type kvWrite struct {
+	op     kvOp
+	bucket []byte
+	key    []byte
+	value  []byte
+}
+
+// KVBatch collects writes to a KVStore so they are committed atomically
+type KVBatch struct {
+	writes []kvWrite
+}
+
+// NewKVBatch returns an empty KV batch
+func NewKVBatch() *KVBatch {
+	return &KVBatch{}
+}
+
+// Put adds setting the value of the key in the bucket to the batch
+func (b *KVBatch) Put(bucket []byte, key []byte, value []byte) {
+	b.writes = append(b.writes, kvWrite{putOp, bucket, key, value})
+}
+
+// Delete adds removing the key from the bucket to the batch
+func (b *KVBatch) Delete(bucket []byte, key []byte) {
+	b.writes = append(b.writes, kvWrite{deleteOp, bucket, key, nil})
+}
+
+// Clear adds removing all the keys of the bucket to the batch
+func (b *KVBatch) Clear(bucket []byte) {
+	b.writes = append(b.writes, kvWrite{clearOp, bucket, nil, nil})
+}
+
+// NewKVStore opens the store of the backend in the config, and returns whether it already existed
+func NewKVStore(cfg *config.Chain) (KVStore, bool, error) {
+	switch cfg.ChainDBBackend {
+	case "", BoltBackend:
+		return newBoltStore(cfg.ChainDBPath, cfg.ChainDBNoSync)
+	case MemoryBackend:
+		return newMemStore(), false, nil
+	default:
+		return nil, false, errors.Errorf("Unknown DB backend %s", cfg.ChainDBBackend)
+	}
+}
+
+// OpenKVStoreReadOnly opens the existing store of the backend in the config without writing it
+// Only the BOLT backend can be opened read-only, as a MEMORY store does not outlive the process creating it.
+func OpenKVStoreReadOnly(cfg *config.Chain) (KVStore, error) {
+	switch cfg.ChainDBBackend {
+	case "", BoltBackend:
+		return openBoltStoreReadOnly(cfg.ChainDBPath, readOnlyOpenTimeout)
+	default:
+		return nil, errors.Errorf("DB backend %s cannot be opened read-only", cfg.ChainDBBackend)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+const testDBPath = "db.test"
+
+func TestKVStore(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	for _, backend := range []string{BoltBackend, MemoryBackend} {
+		assert := assert.New(t)
+		kv, exist, err := NewKVStore(&config.Chain{ChainDBPath: testDBPath, ChainDBBackend: backend})
+		assert.Nil(err)
+		assert.False(exist)
+
+		bucket := []byte("bucket")
+		_, err = kv.Get(bucket, []byte("a"))
+		assert.Equal(ErrNotExist, errors.Cause(err))
+		assert.Nil(kv.Put(bucket, []byte("b"), []byte("2")))
+		batch := NewKVBatch()
+		batch.Put(bucket, []byte("a"), []byte("1"))
+		batch.Put(bucket, []byte("c"), []byte("3"))
+		batch.Delete(bucket, []byte("b"))
+		assert.Nil(kv.Commit(batch))
+
+		v, err := kv.Get(bucket, []byte("a"))
+		assert.Nil(err)
+		assert.Equal([]byte("1"), v)
+		_, err = kv.Get(bucket, []byte("b"))
+		assert.Equal(ErrNotExist, errors.Cause(err))
+
+		// keys are iterated in order
+		keys := []string{}
+		assert.Nil(kv.Iterate(bucket, func(k, v []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		}))
+		assert.Equal([]string{"a", "c"}, keys)
+
+		size, err := kv.Size()
+		assert.Nil(err)
+		assert.True(size > 0)
+
+		batch = NewKVBatch()
+		batch.Clear(bucket)
+		batch.Put(bucket, []byte("d"), []byte("4"))
+		assert.Nil(kv.Commit(batch))
+		assert.Nil(kv.Delete(bucket, []byte("d")))
+		assert.Nil(kv.Iterate(bucket, func(k, v []byte) error {
+			return errors.New("bucket should be empty")
+		}))
+		assert.Nil(kv.Sync())
+		assert.Nil(kv.Close())
+		os.Remove(testDBPath)
+	}
+
+	_, _, err := NewKVStore(&config.Chain{ChainDBBackend: "UNKNOWN"})
+	assert.NotNil(t, err)
+}
+
+func TestBlockDBBackends(t *testing.T) {
+	defer os.Remove(testDBPath)
+
+	for _, backend := range []string{BoltBackend, MemoryBackend} {
+		assert := assert.New(t)
+		db, exist, err := NewBlockDB(&config.Config{Chain: config.Chain{ChainDBPath: testDBPath, ChainDBBackend: backend}})
+		assert.Nil(err)
+		assert.False(exist)
+		_, height, err := db.Init()
+		assert.Nil(err)
+		assert.Equal(uint32(0), height)
+
+		assert.Nil(db.CheckInBlock([]byte("block"), []byte("hash"), 1))
+		assert.Equal(ErrAlreadyExist, errors.Cause(db.CheckInBlock([]byte("block"), []byte("hash"), 2)))
+		hash, height, err := db.Init()
+		assert.Nil(err)
+		assert.Equal([]byte("hash"), hash)
+		assert.Equal(uint32(1), height)
+		hash, err = db.GetBlockHash(1)
+		assert.Nil(err)
+		assert.Equal([]byte("hash"), hash)
+		blks, err := db.CheckOutBlocks(context.Background(), 1, 1)
+		assert.Nil(err)
+		assert.Equal([][]byte{[]byte("block")}, blks)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = db.CheckOutBlocks(ctx, 1, 1)
+		assert.Equal(context.Canceled, err)
+		_, err = db.GetBlockHash(2)
+		assert.Equal(ErrNotExist, errors.Cause(err))
+		assert.Nil(db.Close())
+		os.Remove(testDBPath)
+	}
+}
+
+func TestColdUtxoStore(t *testing.T) {
+	assert := assert.New(t)
+
+	db, _, err := NewBlockDB(&config.Config{Chain: config.Chain{ChainDBBackend: MemoryBackend}})
+	assert.Nil(err)
+	store, err := db.ColdUtxos()
+	assert.Nil(err)
+	assert.Nil(store.Write(map[string][]byte{"a": []byte("1"), "b": []byte("2")}, nil))
+	// the deletes are applied before the puts
+	assert.Nil(store.Write(map[string][]byte{"a": []byte("3")}, [][]byte{[]byte("a"), []byte("b")}))
+	entry, err := store.Get([]byte("a"))
+	assert.Nil(err)
+	assert.Equal([]byte("3"), entry)
+	_, err = store.Get([]byte("b"))
+	assert.Equal(ErrNotExist, errors.Cause(err))
+
+	// opening the store again drops the entries
+	store, err = db.ColdUtxos()
+	assert.Nil(err)
+	_, err = store.Get([]byte("a"))
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	assert.Nil(db.Close())
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"sort"
+	"sync"
+)
+
+// memStore is the KVStore kept in memory, e.g., for tests
+type memStore struct {
+	mutex   sync.RWMutex
+	buckets map[string]map[string][]byte
+}
+
+func newMemStore() *memStore {
+	return &memStore{buckets: make(map[string]map[string][]byte)}
+}
+
+func (s *memStore) Get(bucket []byte, key []byte) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	v, ok := s.buckets[string(bucket)][string(key)]
+	if !ok {
+		return nil, ErrNotExist
+	}
+	return append([]byte{}, v...), nil
+}
+
+func (s *memStore) Put(bucket []byte, key []byte, value []byte) error {
+	batch := NewKVBatch()
+	batch.Put(bucket, key, value)
+	return s.Commit(batch)
+}
+
+func (s *memStore) Delete(bucket []byte, key []byte) error {
+	batch := NewKVBatch()
+	batch.Delete(bucket, key)
+	return s.Commit(batch)
+}
+
+// Iterate iterates a snapshot of the bucket, so fn may write to the store
+func (s *memStore) Iterate(bucket []byte, fn func(k []byte, v []byte) error) error {
+	s.mutex.RLock()
+	b := s.buckets[string(bucket)]
+	keys := make([]string, 0, len(b))
+	values := make(map[string][]byte, len(b))
+	for k, v := range b {
+		keys = append(keys, k)
+		values[k] = v
+	}
+	s.mutex.RUnlock()
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fn([]byte(k), values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Commit applies the batch under the write lock, so readers never see a partial batch
+func (s *memStore) Commit(batch *KVBatch) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, write := range batch.writes {
+		switch write.op {
+		case putOp:
+			b, ok := s.buckets[string(write.bucket)]
+			if !ok {
+				b = make(map[string][]byte)
+				s.buckets[string(write.bucket)] = b
+			}
+			b[string(write.key)] = append([]byte{}, write.value...)
+		case deleteOp:
+			delete(s.buckets[string(write.bucket)], string(write.key))
+		case clearOp:
+			delete(s.buckets, string(write.bucket))
+		}
+	}
+	return nil
+}
+
+func (s *memStore) Sync() error {
+	return nil
+}
+
+// Size returns the total size of the keys and values
+func (s *memStore) Size() (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	size := 0
+	for _, b := range s.buckets {
+		for k, v := range b {
+			size += len(k) + len(v)
+		}
+	}
+	return int64(size), nil
+}
+
+func (s *memStore) Close() error {
+	return nil
+}
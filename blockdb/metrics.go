@@ -0,0 +1,48 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"time"
+
+	"github.com/iotexproject/iotex-core/metrics"
+)
+
+var (
+	readLatency  = metrics.NewHistogram("iotex_blockdb_read_seconds", "Latency of the reads from the chain DB", metrics.DefaultBuckets)
+	writeLatency = metrics.NewHistogram("iotex_blockdb_write_seconds", "Latency of the writes to the chain DB", metrics.DefaultBuckets)
+)
+
+// meteredStore measures the latencies of the reads and the writes of the KV store
+type meteredStore struct {
+	KVStore
+}
+
+func (s meteredStore) Get(bucket []byte, key []byte) ([]byte, error) {
+	defer readLatency.ObserveSince(time.Now())
+	return s.KVStore.Get(bucket, key)
+}
+
+func (s meteredStore) Put(bucket []byte, key []byte, value []byte) error {
+	defer writeLatency.ObserveSince(time.Now())
+	return s.KVStore.Put(bucket, key, value)
+}
+
+func (s meteredStore) Delete(bucket []byte, key []byte) error {
+	defer writeLatency.ObserveSince(time.Now())
+	return s.KVStore.Delete(bucket, key)
+}
+
+func (s meteredStore) Iterate(bucket []byte, fn func(k []byte, v []byte) error) error {
+	defer readLatency.ObserveSince(time.Now())
+	return s.KVStore.Iterate(bucket, fn)
+}
+
+func (s meteredStore) Commit(batch *KVBatch) error {
+	defer writeLatency.ObserveSince(time.Now())
+	return s.KVStore.Commit(batch)
+}
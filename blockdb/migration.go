@@ -0,0 +1,90 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"github.com/pkg/errors"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+// SchemaVersion is the version of the DB layout written by this binary
+// Bump it along with registering a migration whenever the layout changes.
+const SchemaVersion = uint32(2)
+
+var (
+	// ErrSchemaTooNew is the error returned when the DB is written by a newer binary with a layout it cannot read
+	ErrSchemaTooNew = errors.New("DB schema is newer than supported")
+)
+
+// migration upgrades the DB layout from the previous version to version
+type migration struct {
+	version     uint32
+	description string
+	migrate     func(db *BlockDB) error
+}
+
+// migrations is the registry of migrations, sorted by version up to SchemaVersion
+var migrations = []migration{
+	{
+		version:     1,
+		description: "record the schema version of DBs created before versioning, whose layout is otherwise unchanged",
+		migrate:     func(db *BlockDB) error { return nil },
+	},
+	{
+		version: 2,
+		// blocks are flagged per record, so the existing ones are read as they are without rewriting
+		description: "allow compressed block records, which older binaries cannot read",
+		migrate:     func(db *BlockDB) error { return nil },
+	},
+}
+
+// GetSchemaVersion returns the version of the DB layout, 0 if the DB was created before versioning
+func (db *BlockDB) GetSchemaVersion() (uint32, error) {
+	v, err := db.kv.Get(blocksBucket, schemaVersion)
+	if errors.Cause(err) == ErrNotExist {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return cm.MachineEndian.Uint32(v), nil
+}
+
+// putSchemaVersion sets the version of the DB layout
+func (db *BlockDB) putSchemaVersion(version uint32) error {
+	v := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(v, version)
+	return db.kv.Put(blocksBucket, schemaVersion, v)
+}
+
+// migrate runs the migrations from the version of the DB to SchemaVersion in order, recording the version after each
+// of them so an interrupted upgrade resumes from where it stopped
+func (db *BlockDB) migrate() error {
+	version, err := db.GetSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version > SchemaVersion {
+		return errors.Wrapf(ErrSchemaTooNew, "DB schema version %d, supporting up to %d", version, SchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		db.log.WithFields(logger.Fields{"version": m.version, "migration": m.description}).Info("Migrating DB schema")
+		if err := m.migrate(db); err != nil {
+			return errors.Wrapf(err, "Migrating DB schema to version %d", m.version)
+		}
+		if err := db.putSchemaVersion(m.version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func TestMigration(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+	cfg := &config.Config{Chain: config.Chain{ChainDBPath: testDBPath}}
+
+	// a new DB is created with the latest version
+	db, _, err := NewBlockDB(cfg)
+	assert.Nil(err)
+	version, err := db.GetSchemaVersion()
+	assert.Nil(err)
+	assert.Equal(SchemaVersion, version)
+
+	// a DB created before versioning is migrated on open
+	assert.Nil(db.kv.Delete(blocksBucket, schemaVersion))
+	version, err = db.GetSchemaVersion()
+	assert.Nil(err)
+	assert.Equal(uint32(0), version)
+	assert.Nil(db.Close())
+
+	// record the migrations run, in order
+	migrated := []uint32{}
+	registry := migrations
+	defer func() { migrations = registry }()
+	migrations = nil
+	for _, m := range registry {
+		version := m.version
+		migrations = append(migrations, migration{version, m.description, func(db *BlockDB) error {
+			migrated = append(migrated, version)
+			return nil
+		}})
+	}
+	db, exist, err := NewBlockDB(cfg)
+	assert.Nil(err)
+	assert.True(exist)
+	version, err = db.GetSchemaVersion()
+	assert.Nil(err)
+	assert.Equal(SchemaVersion, version)
+	assert.Equal([]uint32{1, 2}, migrated)
+
+	// a DB of a newer binary is rejected
+	assert.Nil(db.putSchemaVersion(SchemaVersion + 1))
+	assert.Nil(db.Close())
+	_, _, err = NewBlockDB(cfg)
+	assert.Equal(ErrSchemaTooNew, errors.Cause(err))
+}
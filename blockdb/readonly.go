@@ -0,0 +1,54 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+// readOnlyOpenTimeout is how long opening a DB read-only waits for the process writing it to release the file
+const readOnlyOpenTimeout = time.Second
+
+var (
+	// ErrReadOnly is the error returned when writing to a DB opened read-only
+	ErrReadOnly = errors.New("DB is opened read-only")
+)
+
+// OpenReadOnly opens the existing DB of the config for the tools reading it, e.g., explorers and auditors
+// All the queries are served as with NewBlockDB, and every commit fails with ErrReadOnly. Neither an interrupted
+// commit is rolled back nor the schema is migrated, as both write, so a DB of an older schema version is read as it
+// is and ErrSchemaTooNew is returned for a newer one. The BOLT backend does not allow opening the file of a running
+// node, for which the open times out, so a copy of it is read instead.
+func OpenReadOnly(cfg *config.Config) (*BlockDB, error) {
+	kv, err := OpenKVStoreReadOnly(&cfg.Chain)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &BlockDB{log: log, kv: meteredStore{kv}, readOnly: true}
+	version, err := db.GetSchemaVersion()
+	if err == nil && version > SchemaVersion {
+		err = errors.Wrapf(ErrSchemaTooNew, "DB schema version %d, supporting up to %d", version, SchemaVersion)
+	}
+	if err == nil {
+		err = db.checkGenesis(cfg)
+	}
+	if err != nil {
+		kv.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// IsReadOnly returns whether the DB is opened read-only
+func (db *BlockDB) IsReadOnly() bool {
+	return db.readOnly
+}
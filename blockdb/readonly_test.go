@@ -0,0 +1,58 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func TestOpenReadOnly(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+	cfg := &config.Config{Chain: config.Chain{ChainDBPath: testDBPath}}
+
+	_, err := OpenReadOnly(cfg)
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	_, err = os.Stat(testDBPath)
+	assert.True(os.IsNotExist(err))
+
+	db, _, err := NewBlockDB(cfg)
+	assert.Nil(err)
+	assert.Nil(db.CheckInBlock([]byte("block1"), []byte("hash1"), 1))
+	assert.Nil(db.Close())
+
+	db, err = OpenReadOnly(cfg)
+	assert.Nil(err)
+	assert.True(db.IsReadOnly())
+	hash, height, err := db.Init()
+	assert.Nil(err)
+	assert.Equal([]byte("hash1"), hash)
+	assert.Equal(uint32(1), height)
+	blk, err := db.CheckOutBlock(hash)
+	assert.Nil(err)
+	assert.Equal([]byte("block1"), blk)
+	assert.Equal(ErrReadOnly, errors.Cause(db.CheckInBlock([]byte("block2"), []byte("hash2"), 2)))
+	assert.Nil(db.Close())
+
+	// a DB of a newer binary is rejected
+	db, _, err = NewBlockDB(cfg)
+	assert.Nil(err)
+	assert.Nil(db.putSchemaVersion(SchemaVersion + 1))
+	assert.Nil(db.Close())
+	_, err = OpenReadOnly(cfg)
+	assert.Equal(ErrSchemaTooNew, errors.Cause(err))
+
+	// the MEMORY backend cannot be opened read-only
+	_, err = OpenReadOnly(&config.Config{Chain: config.Chain{ChainDBBackend: MemoryBackend}})
+	assert.NotNil(err)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+// The pending commit marker is a list of entries of the 4-byte height, the 4-byte size of the hash and the hash,
+// where the first entry is the tip before the commit and the others are the blocks the commit moves the tip to.
+
+// markPendingCommit records the current tip and the blocks the commit moves the tip to
+func (db *BlockDB) markPendingCommit(tips []tipEntry) error {
+	hash, err := db.kv.Get(blocksBucket, tipHash)
+	if err != nil {
+		return errors.Wrap(err, "Blockchain tip")
+	}
+	h, err := db.kv.Get(blocksBucket, tipHeight)
+	if err != nil {
+		return errors.Wrap(err, "Blockchain height")
+	}
+	marker := encodeTipEntry(nil, tipEntry{cm.MachineEndian.Uint32(h), hash})
+	for _, tip := range tips {
+		marker = encodeTipEntry(marker, tip)
+	}
+	if err := db.kv.Put(blocksBucket, pendingCommit, marker); err != nil {
+		return errors.Wrap(err, "Writing pending commit")
+	}
+	return nil
+}
+
+// recover rolls back the commit interrupted by a crash, if any, then moves the tip back to the last consistent block
+// unless a reindex is in progress
+// The UTXO is invalidated on a rollback, since it may be partially updated, so the blockchain rebuilds it from the
+// blocks, unless the chain is pruned and cannot be replayed.
+func (db *BlockDB) recover() error {
+	marker, err := db.kv.Get(blocksBucket, pendingCommit)
+	if err != nil && errors.Cause(err) != ErrNotExist {
+		return err
+	}
+	if err == nil {
+		tips, err := decodeTipEntries(marker)
+		if err != nil {
+			return err
+		}
+		db.log.WithField("height", tips[len(tips)-1].height).Warning("Rolling back the commit interrupted")
+
+		batch := NewKVBatch()
+		for _, tip := range tips[1:] {
+			height := []byte{0, 0, 0, 0}
+			cm.MachineEndian.PutUint32(height, tip.height)
+			if hash, err := db.kv.Get(hashHeightBucket, height); err == nil && bytes.Equal(hash, tip.hash) {
+				batch.Delete(hashHeightBucket, height)
+			}
+			batch.Delete(hashHeightBucket, tip.hash)
+			batch.Delete(blocksBucket, tip.hash)
+			batch.Delete(headersBucket, tip.hash)
+		}
+		db.putTip(batch, tips[0])
+		batch.Delete(blocksBucket, pendingCommit)
+		if err := db.kv.Commit(batch); err != nil {
+			return err
+		}
+	}
+	// the hash <-> height mapping is partially rebuilt while reindexing, and the reindex checks the blocks linked to
+	// the tip once resumed
+	if _, err := db.GetReindexNext(); err == nil {
+		return nil
+	}
+	return db.repairTip()
+}
+
+// repairTip moves the tip back to the highest block whose hash <-> height mapping and body or header are in DB
+func (db *BlockDB) repairTip() error {
+	hash, err := db.kv.Get(blocksBucket, tipHash)
+	if err != nil {
+		return errors.Wrap(err, "Blockchain tip")
+	}
+	h, err := db.kv.Get(blocksBucket, tipHeight)
+	if err != nil {
+		return errors.Wrap(err, "Blockchain height")
+	}
+	tip := tipEntry{cm.MachineEndian.Uint32(h), hash}
+	// the DB is created with a zero tip before the genesis block is committed
+	if tip.height == 0 && bytes.Equal(tip.hash, cp.ZeroHash32B[:]) {
+		return nil
+	}
+	if db.isConsistent(tip) {
+		return nil
+	}
+
+	for height := tip.height; height > 0; height-- {
+		hash, err := db.GetBlockHash(height - 1)
+		if err != nil {
+			continue
+		}
+		if prev := (tipEntry{height - 1, hash}); db.isConsistent(prev) {
+			db.log.WithFields(logger.Fields{"height": tip.height, "hash": tip.hash, "to": prev.height}).Warning("Tip is inconsistent, rolling back")
+			batch := NewKVBatch()
+			db.putTip(batch, prev)
+			return db.kv.Commit(batch)
+		}
+	}
+	return errors.Errorf("No consistent block below height %d", tip.height)
+}
+
+// isConsistent checks the hash <-> height mapping of the block goes both ways and its body or header is in DB
+func (db *BlockDB) isConsistent(tip tipEntry) bool {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, tip.height)
+	if hash, err := db.kv.Get(hashHeightBucket, height); err != nil || !bytes.Equal(hash, tip.hash) {
+		return false
+	}
+	if h, err := db.kv.Get(hashHeightBucket, tip.hash); err != nil || !bytes.Equal(h, height) {
+		return false
+	}
+	if _, err := db.kv.Get(blocksBucket, tip.hash); err == nil {
+		return true
+	}
+	_, err := db.kv.Get(headersBucket, tip.hash)
+	return err == nil
+}
+
+// putTip adds moving the tip to the block and invalidating the UTXO to the batch
+func (db *BlockDB) putTip(batch *KVBatch, tip tipEntry) {
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, tip.height)
+	batch.Put(blocksBucket, tipHash, tip.hash)
+	batch.Put(blocksBucket, tipHeight, height)
+	if pruned, err := db.GetPruneHeight(); err == nil && pruned > 0 {
+		db.log.Warning("Keeping the UTXO of the pruned chain, which cannot be rebuilt from the blocks")
+		return
+	}
+	batch.Delete(blocksBucket, utxoHeight)
+}
+
+func encodeTipEntry(buf []byte, tip tipEntry) []byte {
+	prefix := make([]byte, 8)
+	cm.MachineEndian.PutUint32(prefix, tip.height)
+	cm.MachineEndian.PutUint32(prefix[4:], uint32(len(tip.hash)))
+	return append(append(buf, prefix...), tip.hash...)
+}
+
+func decodeTipEntries(buf []byte) ([]tipEntry, error) {
+	tips := []tipEntry{}
+	for len(buf) > 0 {
+		if len(buf) < 8 {
+			return nil, errors.New("Truncated pending commit")
+		}
+		size := cm.MachineEndian.Uint32(buf[4:])
+		if uint32(len(buf)-8) < size {
+			return nil, errors.New("Truncated pending commit")
+		}
+		tips = append(tips, tipEntry{cm.MachineEndian.Uint32(buf), append([]byte{}, buf[8:8+size]...)})
+		buf = buf[8+size:]
+	}
+	if len(tips) < 2 {
+		return nil, errors.New("Pending commit without blocks")
+	}
+	return tips, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func TestRecovery(t *testing.T) {
+	assert := assert.New(t)
+	db, _, err := NewBlockDB(&config.Config{Chain: config.Chain{ChainDBBackend: MemoryBackend}})
+	assert.Nil(err)
+	defer db.Close()
+
+	batch := NewBatch()
+	batch.PutBlock([]byte("block1"), []byte("hash1"), 1)
+	batch.PutUtxoHeight(1)
+	assert.Nil(db.Commit(batch))
+	_, err = db.kv.Get(blocksBucket, pendingCommit)
+	assert.Equal(ErrNotExist, errors.Cause(err))
+
+	// a crash in the middle of committing block 2 leaves the marker and a part of the writes
+	assert.Nil(db.markPendingCommit([]tipEntry{{2, []byte("hash2")}}))
+	height := []byte{0, 0, 0, 0}
+	cm.MachineEndian.PutUint32(height, 2)
+	kvBatch := NewKVBatch()
+	kvBatch.Put(hashHeightBucket, height, []byte("hash2"))
+	kvBatch.Put(blocksBucket, []byte("hash2"), []byte("block2"))
+	kvBatch.Put(blocksBucket, tipHeight, height)
+	assert.Nil(db.kv.Commit(kvBatch))
+
+	// the commit is rolled back and the UTXO is invalidated to be rebuilt
+	hash, h, err := db.Init()
+	assert.Nil(err)
+	assert.Equal([]byte("hash1"), hash)
+	assert.Equal(uint32(1), h)
+	_, err = db.GetBlockHash(2)
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	_, err = db.CheckOutBlock([]byte("hash2"))
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	_, _, err = db.Utxos()
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	_, err = db.kv.Get(blocksBucket, pendingCommit)
+	assert.Equal(ErrNotExist, errors.Cause(err))
+
+	// block 2 can be committed again
+	assert.Nil(db.CheckInBlock([]byte("block2"), []byte("hash2"), 2))
+	hash, h, err = db.Init()
+	assert.Nil(err)
+	assert.Equal([]byte("hash2"), hash)
+	assert.Equal(uint32(2), h)
+
+	// a tip without its block is moved back to the last consistent block
+	cm.MachineEndian.PutUint32(height, 3)
+	kvBatch = NewKVBatch()
+	kvBatch.Put(blocksBucket, tipHash, []byte("hash3"))
+	kvBatch.Put(blocksBucket, tipHeight, height)
+	assert.Nil(db.kv.Commit(kvBatch))
+	hash, h, err = db.Init()
+	assert.Nil(err)
+	assert.Equal([]byte("hash2"), hash)
+	assert.Equal(uint32(2), h)
+
+	// the tip is kept while its hash <-> height mapping is rebuilt by a reindex
+	batch = NewBatch()
+	batch.StartReindex()
+	assert.Nil(db.Commit(batch))
+	next, err := db.GetReindexNext()
+	assert.Nil(err)
+	assert.Equal(uint32(0), next)
+	hash, h, err = db.Init()
+	assert.Nil(err)
+	assert.Equal([]byte("hash2"), hash)
+	assert.Equal(uint32(2), h)
+	_, err = db.GetBlockHash(2)
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	_, err = db.CheckOutBlock([]byte("hash2"))
+	assert.Nil(err)
+
+	batch = NewBatch()
+	batch.PutHeightIndex([]byte("hash2"), 2)
+	batch.FinishReindex()
+	assert.Nil(db.Commit(batch))
+	_, err = db.GetReindexNext()
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	h, err = db.GetBlockHeight([]byte("hash2"))
+	assert.Nil(err)
+	assert.Equal(uint32(2), h)
+}
@@ -9,21 +9,25 @@ package blocksync
 import (
 	"fmt"
 	"sync"
-	"syscall"
 	"time"
 
-	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
 
 	bc "github.com/iotexproject/iotex-core/blockchain"
 	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/common/routine"
 	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/delegate"
+	"github.com/iotexproject/iotex-core/logger"
 	"github.com/iotexproject/iotex-core/network"
 	pb "github.com/iotexproject/iotex-core/proto"
 	"github.com/iotexproject/iotex-core/txpool"
 )
 
+var log = logger.New("blocksync")
+
 const (
 	// Idle indicates an idle state
 	Idle = iota
@@ -31,6 +35,9 @@ const (
 	Init = Idle + 1
 	// Active indicates the state after first block has been processed
 	Active = Init + 1
+
+	// MaxBlocksPerMsg is the max number of blocks read at a time and sent back in one message for a sync request
+	MaxBlocksPerMsg = 16
 )
 
 // BlockSync defines the interface of blocksyncer
@@ -38,9 +45,15 @@ type BlockSync interface {
 	Start() error
 	Stop() error
 	P2P() *network.Overlay
-	ProcessSyncRequest(sender string, sync *pb.BlockSync) error
+	ProcessSyncRequest(ctx context.Context, sender string, sync *pb.BlockSync) error
 	ProcessBlock(blk *bc.Block) error
 	ProcessBlockSync(blk *bc.Block) error
+	ProcessHeaderSyncRequest(ctx context.Context, sender string, sync *pb.BlockHeaderSync) error
+	ProcessHeaders(headers *pb.BlockHeaderContainer) error
+	ProcessCompactBlock(cb *pb.CompactBlockPb) error
+	ProcessBlockTxsSyncRequest(sender string, sync *pb.BlockTxsSync) error
+	ProcessBlockTxs(txs *pb.BlockTxsContainer) error
+	Resync(addr string, height uint32)
 }
 
 // blockSyncer implements BlockSync interface
@@ -54,7 +67,7 @@ type blockSyncer struct {
 	dropHeight     uint32               // height of most recent block being dropped
 	currRcvdHeight uint32               // height of most recent incoming block
 	lastRcvdHeight uint32               // height of last incoming block
-	rcvdBlocks     map[uint32]*bc.Block // buffer of received blocks
+	rcvdBlocks     map[uint32]*bc.Block // buffer of received blocks, guarded by bufMu
 	actionTime     time.Time
 	sw             *SlidingWindow
 	bc             *bc.Blockchain
@@ -63,6 +76,18 @@ type blockSyncer struct {
 	task           *routine.RecurringTask
 	fnd            string
 	dp             delegate.Pool
+	headersFirst   bool           // download and verify headers before fetching block bodies
+	headerTarget   uint32         // height of the last header requested in headers-first mode
+	hc             *headerChain   // verified headers whose block bodies are not committed yet
+	maxMsgSize     int            // max size of a message sent back for a sync request
+	cbs            *compactPool   // compact blocks waiting for their missing transactions
+	sched          *bodyScheduler // downloads of the block bodies of the verified headers in headers-first mode
+
+	bufMu    sync.Mutex
+	commitMu sync.Mutex    // serializes the commits of the buffered blocks
+	commitCh chan struct{} // hands the received blocks over to the commit pipeline in headers-first mode
+	quit     chan struct{}
+	wg       sync.WaitGroup
 }
 
 // SyncTaskInterval returns the recurring sync task interval, or 0 if this config should not need to run sync task
@@ -88,7 +113,7 @@ func SyncTaskInterval(cfg *config.Config) time.Duration {
 }
 
 // NewBlockSyncer returns a new block syncer instance
-func NewBlockSyncer(cfg *config.Config, chain *bc.Blockchain, tp txpool.TxPool, p2p *network.Overlay, dp delegate.Pool) BlockSync {
+func NewBlockSyncer(cfg *config.Config, chain *bc.Blockchain, tp txpool.TxPool, p2p *network.Overlay, dp delegate.Pool) (BlockSync, error) {
 	sync := &blockSyncer{
 		state:      Idle,
 		rcvdBlocks: map[uint32]*bc.Block{},
@@ -96,7 +121,15 @@ func NewBlockSyncer(cfg *config.Config, chain *bc.Blockchain, tp txpool.TxPool,
 		bc:         chain,
 		tp:         tp,
 		p2p:        p2p,
-		dp:         dp}
+		dp:         dp,
+		hc:         newHeaderChain(),
+		cbs:        newCompactPool(),
+		commitCh:   make(chan struct{}, 1),
+		quit:       make(chan struct{})}
+
+	sync.headersFirst = cfg.BlockSync.HeadersFirst
+	sync.sched = newBodyScheduler(cfg.BlockSync.BodyBatchSize, cfg.BlockSync.DownloadWindow, cfg.BlockSync.BodyTimeout)
+	sync.maxMsgSize = cfg.Network.MaxMsgSize
 
 	sync.ackBlockCommit = cfg.IsDelegate() || cfg.IsFullnode()
 	sync.ackBlockSync = cfg.IsDelegate() || cfg.IsFullnode()
@@ -107,13 +140,11 @@ func NewBlockSyncer(cfg *config.Config, chain *bc.Blockchain, tp txpool.TxPool,
 	}
 
 	delegates, err := dp.AllDelegates()
-	if err != nil || len(delegates) == 0 {
-		if err != nil {
-			glog.Fatal(err)
-		} else {
-			glog.Fatal("No delegates found")
-		}
-		syscall.Exit(syscall.SYS_EXIT)
+	if err != nil {
+		return nil, err
+	}
+	if len(delegates) == 0 {
+		return nil, fmt.Errorf("no delegates found")
 	}
 
 	switch cfg.NodeType {
@@ -128,10 +159,9 @@ func NewBlockSyncer(cfg *config.Config, chain *bc.Blockchain, tp txpool.TxPool,
 			sync.fnd = dlg.String()
 		}
 	default:
-		glog.Fatal("Unexpected node type ", cfg.NodeType)
-		return nil
+		return nil, fmt.Errorf("unexpected node type %s", cfg.NodeType)
 	}
-	return sync
+	return sync, nil
 }
 
 // P2P returns the network overlay object
@@ -141,7 +171,11 @@ func (bs *blockSyncer) P2P() *network.Overlay {
 
 // Start starts a block syncer
 func (bs *blockSyncer) Start() error {
-	glog.Info("Starting block syncer")
+	log.Info("Starting block syncer")
+	if bs.headersFirst {
+		bs.wg.Add(1)
+		go bs.commitPipeline()
+	}
 	if bs.task != nil {
 		bs.task.Init()
 		bs.task.Start()
@@ -151,15 +185,21 @@ func (bs *blockSyncer) Start() error {
 
 // Stop stops a block syncer
 func (bs *blockSyncer) Stop() error {
-	glog.Infof("Stopping block syncer")
+	log.Infof("Stopping block syncer")
 	if bs.task != nil {
 		bs.task.Stop()
 	}
+	close(bs.quit)
+	bs.wg.Wait()
 	return nil
 }
 
 // Do checks the sliding window and send more sync request if needed
 func (bs *blockSyncer) Do() {
+	if bs.headersFirst {
+		// request again the bodies the peers have not sent in time
+		bs.tellBodies(bs.sched.expire(bs.bc.TipHeight(), time.Now()))
+	}
 	if bs.state == Idle {
 		// simple exit if we haven't received any blocks
 		return
@@ -176,12 +216,12 @@ func (bs *blockSyncer) Do() {
 	// This handles the case where a sync takes long time. By the time the window is closing, enough new
 	// blocks are being dropped, so we check the window range and issue a new sync request
 	if bs.state == Active && bs.sw.State != Open && bs.syncHeight < bs.dropHeight {
-		bs.p2p.Tell(cm.NewTCPNode(bs.fnd), &pb.BlockSync{bs.syncHeight + 1, bs.dropHeight})
-		glog.Warningf("++++++ [%s] Send start = %d end = %d to %s", bs.p2p.PRC.Addr, bs.syncHeight+1, bs.dropHeight, bs.fnd)
+		bs.requestSync(bs.syncHeight+1, bs.dropHeight)
+		log.Warningf("++++++ [%s] Send start = %d end = %d to %s", bs.p2p.PRC.Addr, bs.syncHeight+1, bs.dropHeight, bs.fnd)
 		if bs.dropHeight-bs.syncHeight > WindowSize {
 			// trigger ProcessBlock() to drop incoming blocks, preventing too many blocks piling up in the buffer
 			bs.sw.Update(bs.dropHeight)
-			glog.Warningf("++++++ reopen window to [%d  %d]", bs.syncHeight+1, bs.dropHeight)
+			log.Warningf("++++++ reopen window to [%d  %d]", bs.syncHeight+1, bs.dropHeight)
 		}
 		bs.syncHeight = bs.dropHeight
 		return
@@ -190,26 +230,164 @@ func (bs *blockSyncer) Do() {
 	// health check if blocks keep coming in
 	if bs.lastRcvdHeight == bs.currRcvdHeight {
 		bs.state = Idle
-		glog.Warning(">>>>>> No longer receiving blocks. Last received block ", bs.lastRcvdHeight)
+		log.Warning(">>>>>> No longer receiving blocks. Last received block ", bs.lastRcvdHeight)
 	}
 	bs.lastRcvdHeight = bs.currRcvdHeight
 }
 
-// ProcessSyncRequest processes a block sync request
-func (bs *blockSyncer) ProcessSyncRequest(sender string, sync *pb.BlockSync) error {
+// Resync makes the peer at the address the one syncing from, and requests from it the blocks above the blockchain tip up
+// to the height, e.g., once the tip stops advancing while the peer tells a higher tip
+func (bs *blockSyncer) Resync(addr string, height uint32) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	tip := bs.bc.TipHeight()
+	if height <= tip {
+		return
+	}
+	bs.fnd = addr
+	log.Warningf("++++++ [%s] Resync start = %d end = %d from %s", bs.p2p.PRC.Addr, tip+1, height, addr)
+	bs.requestSync(tip+1, height)
+	if bs.syncHeight < height {
+		bs.syncHeight = height
+	}
+}
+
+// ProcessSyncRequest processes a block sync request, no more blocks are read or sent once ctx is done
+func (bs *blockSyncer) ProcessSyncRequest(ctx context.Context, sender string, sync *pb.BlockSync) error {
+	if !bs.ackSyncReq {
+		// node is not meant to handle sync request, simply exit
+		return nil
+	}
+
+	last := sync.End
+	if tip := bs.bc.TipHeight(); last > tip {
+		last = tip
+	}
+	// send back the blocks in batches, each read from Db in one scan and sent in one message
+	for start := sync.Start; start >= sync.Start && start <= last; start += MaxBlocksPerMsg {
+		end := last
+		if end-start >= MaxBlocksPerMsg {
+			end = start + MaxBlocksPerMsg - 1
+		}
+		blks, err := bs.bc.GetBlocksByRange(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		if err := bs.tellBlocks(sender, blks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tellBlocks sends the blocks to the peer in as few messages as the max message size allows
+func (bs *blockSyncer) tellBlocks(sender string, blks []*bc.Block) error {
+	container := &pb.BlockContainer{}
+	for _, blk := range blks {
+		blkPb := blk.ConvertToBlockPb()
+		size := proto.Size(&pb.BlockContainer{Blocks: []*pb.BlockPb{blkPb}})
+		if len(container.Blocks) > 0 && bs.maxMsgSize > 0 && proto.Size(container)+size > bs.maxMsgSize {
+			if err := bs.p2p.Tell(cm.NewTCPNode(sender), container); err != nil {
+				return err
+			}
+			container = &pb.BlockContainer{}
+		}
+		container.Blocks = append(container.Blocks, blkPb)
+	}
+	if len(container.Blocks) == 0 {
+		return nil
+	}
+	return bs.p2p.Tell(cm.NewTCPNode(sender), container)
+}
+
+// requestSync asks the peer for the blocks in the range [start, end]
+// In headers-first mode only the headers are requested, the bodies are fetched once the headers are verified
+func (bs *blockSyncer) requestSync(start, end uint32) {
+	if !bs.headersFirst {
+		bs.p2p.Tell(cm.NewTCPNode(bs.fnd), &pb.BlockSync{start, end})
+		return
+	}
+
+	if tip := bs.bc.TipHeight(); bs.hc.tip() <= tip {
+		// no pending headers, restart the header chain from the blockchain tip
+		bs.hc.reset(tip, bs.bc.TipHash())
+	}
+	if start = bs.hc.tip() + 1; start > end {
+		return
+	}
+	bs.headerTarget = end
+	bs.p2p.Tell(cm.NewTCPNode(bs.fnd), &pb.BlockHeaderSync{Start: start, End: end})
+}
+
+// ProcessHeaderSyncRequest processes a block header sync request, which is dropped if ctx is done before all the
+// headers are read
+func (bs *blockSyncer) ProcessHeaderSyncRequest(ctx context.Context, sender string, sync *pb.BlockHeaderSync) error {
 	if !bs.ackSyncReq {
 		// node is not meant to handle sync request, simply exit
 		return nil
 	}
 
-	for i := sync.Start; i <= sync.End; i++ {
-		blk, err := bs.bc.GetBlockByHeight(i)
+	end := sync.End
+	if tip := bs.bc.TipHeight(); end > tip {
+		end = tip
+	}
+	if end >= sync.Start+MaxHeadersPerMsg {
+		end = sync.Start + MaxHeadersPerMsg - 1
+	}
+	headers := &pb.BlockHeaderContainer{}
+	for i := sync.Start; i <= end; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// headers are still available on a pruned node
+		blk, err := bs.bc.GetBlockHeaderByHeight(i)
 		if err != nil {
 			return err
 		}
-		// TODO: send back multiple blocks in one shot
-		bs.p2p.Tell(cm.NewTCPNode(sender), &pb.BlockContainer{blk.ConvertToBlockPb()})
-		//time.Sleep(time.Millisecond << 8)
+		headers.Headers = append(headers.Headers, blk.ConvertToBlockHeaderPb())
+	}
+	return bs.p2p.Tell(cm.NewTCPNode(sender), headers)
+}
+
+// ProcessHeaders processes incoming block headers, and requests the bodies of the verified ones in parallel batches
+// from all delegates, as far as the download window allows
+func (bs *blockSyncer) ProcessHeaders(headers *pb.BlockHeaderContainer) error {
+	if !bs.ackBlockSync || !bs.headersFirst {
+		// node is not meant to handle sync block, simply exit
+		return nil
+	}
+
+	start, end, err := bs.hc.addHeaders(headers.Headers)
+	if err != nil {
+		return err
+	}
+	if start == 0 {
+		return nil
+	}
+	log.Warningf("------ [%s] verified headers %d to %d", bs.p2p.PRC.Addr, start, end)
+
+	// spread the body requests over all delegates
+	delegates, err := bs.dp.AllDelegates()
+	if err != nil {
+		return err
+	}
+	var peers []string
+	for _, dlg := range delegates {
+		if dlg.String() != bs.p2p.PRC.Addr {
+			peers = append(peers, dlg.String())
+		}
+	}
+	if len(peers) == 0 {
+		peers = append(peers, bs.fnd)
+	}
+	tip := bs.bc.TipHeight()
+	bs.sched.setPeers(peers)
+	bs.sched.extend(tip, end)
+	bs.tellBodies(bs.sched.schedule(tip, time.Now()))
+
+	// the peer caps the number of headers per message, ask for the rest
+	if end < bs.headerTarget {
+		bs.p2p.Tell(cm.NewTCPNode(bs.fnd), &pb.BlockHeaderSync{Start: end + 1, End: bs.headerTarget})
 	}
 	return nil
 }
@@ -217,8 +395,8 @@ func (bs *blockSyncer) ProcessSyncRequest(sender string, sync *pb.BlockSync) err
 // processFirstBlock processes an incoming latest committed block
 func (bs *blockSyncer) processFirstBlock() error {
 	if bs.syncHeight = bs.bc.TipHeight(); bs.currRcvdHeight > bs.syncHeight+1 {
-		glog.Warningf("++++++ [%s] Send first start = %d end = %d to %s", bs.p2p.PRC.Addr, bs.syncHeight+1, bs.currRcvdHeight, bs.fnd)
-		bs.p2p.Tell(cm.NewTCPNode(bs.fnd), &pb.BlockSync{bs.syncHeight + 1, bs.currRcvdHeight})
+		log.Warningf("++++++ [%s] Send first start = %d end = %d to %s", bs.p2p.PRC.Addr, bs.syncHeight+1, bs.currRcvdHeight, bs.fnd)
+		bs.requestSync(bs.syncHeight+1, bs.currRcvdHeight)
 	}
 	if err := bs.sw.SetRange(bs.syncHeight, bs.currRcvdHeight); err != nil {
 		return err
@@ -234,6 +412,7 @@ func (bs *blockSyncer) ProcessBlock(blk *bc.Block) error {
 		// node is not meant to handle latest committed block, simply exit
 		return nil
 	}
+	blk.ReceivedAt = time.Now()
 
 	bs.mu.Lock()
 	if bs.currRcvdHeight = blk.Height(); bs.currRcvdHeight <= bs.bc.TipHeight() {
@@ -252,7 +431,7 @@ func (bs *blockSyncer) ProcessBlock(blk *bc.Block) error {
 			return err
 		}
 		bs.state = Active
-		glog.Warningf("====== receive tip block %d", bs.currRcvdHeight)
+		log.Warningf("====== receive tip block %d", bs.currRcvdHeight)
 	}
 
 	if bs.state == Idle || bs.state == Init {
@@ -265,7 +444,7 @@ func (bs *blockSyncer) ProcessBlock(blk *bc.Block) error {
 	if bs.state == Active && bs.sw.State == Open {
 		// when window is open we are still WIP to sync old blocks, so simply drop incoming blocks
 		bs.dropHeight = bs.currRcvdHeight
-		glog.Warningf("****** [%s] drop block %d", bs.p2p.PRC.Addr, bs.currRcvdHeight)
+		log.Warningf("****** [%s] drop block %d", bs.p2p.PRC.Addr, bs.currRcvdHeight)
 		bs.mu.Unlock()
 		return nil
 	}
@@ -273,7 +452,7 @@ func (bs *blockSyncer) ProcessBlock(blk *bc.Block) error {
 
 	// check-in incoming block to the buffer
 	if err := bs.checkBlockIntoBuffer(blk); err != nil {
-		glog.Warning(err)
+		log.Warning(err)
 		return nil
 	}
 
@@ -287,9 +466,32 @@ func (bs *blockSyncer) ProcessBlockSync(blk *bc.Block) error {
 		// node is not meant to handle sync block, simply exit
 		return nil
 	}
+	blk.ReceivedAt = time.Now()
 
 	if blk.Height() <= bs.bc.TipHeight() {
-		glog.Warningf("****** [%s] Received block height %d <= Blockchain tip height %d", bs.p2p.PRC.Addr, blk.Height(), bs.bc.TipHeight())
+		log.Warningf("****** [%s] Received block height %d <= Blockchain tip height %d", bs.p2p.PRC.Addr, blk.Height(), bs.bc.TipHeight())
+		return nil
+	}
+
+	if bs.headersFirst {
+		// only accept the block matching its verified header, checked as soon as it is received
+		if err := bs.hc.verifyBody(blk); err != nil {
+			log.Warning(err)
+			if errors.Cause(err) == ErrBodyMismatch {
+				bs.tellBodies(bs.sched.retry(blk.Height(), time.Now()))
+			}
+			return nil
+		}
+		bs.sched.received(blk.Height())
+		if err := bs.checkBlockIntoBuffer(blk); err != nil {
+			log.Warning(err)
+			return nil
+		}
+		// the commit pipeline commits the buffered blocks in order, while the next bodies keep arriving
+		select {
+		case bs.commitCh <- struct{}{}:
+		default:
+		}
 		return nil
 	}
 
@@ -303,35 +505,82 @@ func (bs *blockSyncer) ProcessBlockSync(blk *bc.Block) error {
 // checkBlockIntoBuffer adds a received blocks into the buffer
 func (bs *blockSyncer) checkBlockIntoBuffer(blk *bc.Block) error {
 	height := blk.Height()
+	bs.bufMu.Lock()
 	if bs.rcvdBlocks[height] != nil {
+		bs.bufMu.Unlock()
 		return fmt.Errorf("|||||| [%s] discard existing block %d", bs.p2p.PRC.Addr, height)
 	}
 	bs.rcvdBlocks[height] = blk
+	bs.bufMu.Unlock()
 
-	glog.Warningf("------ [%s] receive block %d in %v", bs.p2p.PRC.Addr, height, time.Since(bs.actionTime))
+	log.Warningf("------ [%s] receive block %d in %v", bs.p2p.PRC.Addr, height, time.Since(bs.actionTime))
 	bs.actionTime = time.Now()
 	return nil
 }
 
 // commitBlocksInBuffer commits all blocks in the buffer that can be added to Blockchain
 func (bs *blockSyncer) commitBlocksInBuffer() error {
+	bs.commitMu.Lock()
+	defer bs.commitMu.Unlock()
 	next := bs.bc.TipHeight() + 1
-	for blk := bs.rcvdBlocks[next]; blk != nil; {
+	for blk := bs.bufferedBlock(next); blk != nil; {
 		if err := bs.bc.AddBlockCommit(blk); err != nil {
 			return err
 		}
+		bs.bufMu.Lock()
 		delete(bs.rcvdBlocks, next)
+		bs.bufMu.Unlock()
+		bs.hc.remove(next)
 
 		// remove transactions in this block from TxPool
 		bs.tp.RemoveTxInBlock(blk)
 
-		glog.Warningf("------ commit block %d time = %v\n\n", next, time.Since(bs.actionTime))
+		log.Warningf("------ commit block %d time = %v\n\n", next, time.Since(bs.actionTime))
 		bs.actionTime = time.Now()
 
 		// update sliding window
 		bs.sw.Update(next)
 		next = bs.bc.TipHeight() + 1
-		blk = bs.rcvdBlocks[next]
+		blk = bs.bufferedBlock(next)
 	}
 	return nil
 }
+
+// bufferedBlock returns the received block at the height, nil if it has not been received
+func (bs *blockSyncer) bufferedBlock(height uint32) *bc.Block {
+	bs.bufMu.Lock()
+	defer bs.bufMu.Unlock()
+	return bs.rcvdBlocks[height]
+}
+
+// commitPipeline commits the blocks received in headers-first mode in order as they are handed over, and slides the
+// download window forward as the tip moves, so the next bodies download while the blocks are committed
+// A block failing to commit, although it matches its verified header, means the header chain is invalid, hence the
+// header chain, the pending downloads and the received blocks are dropped, and the next sync starts over from the tip.
+func (bs *blockSyncer) commitPipeline() {
+	defer bs.wg.Done()
+	for {
+		select {
+		case <-bs.quit:
+			return
+		case <-bs.commitCh:
+		}
+		if err := bs.commitBlocksInBuffer(); err != nil {
+			log.Errorf("Cannot commit synced block: %v", err)
+			bs.bufMu.Lock()
+			bs.rcvdBlocks = map[uint32]*bc.Block{}
+			bs.bufMu.Unlock()
+			bs.hc.reset(bs.bc.TipHeight(), bs.bc.TipHash())
+			bs.sched.reset()
+			continue
+		}
+		bs.tellBodies(bs.sched.schedule(bs.bc.TipHeight(), time.Now()))
+	}
+}
+
+// tellBodies sends the requests for the batches of block bodies to their peers
+func (bs *blockSyncer) tellBodies(reqs []bodyAssignment) {
+	for _, req := range reqs {
+		bs.p2p.Tell(cm.NewTCPNode(req.peer), req.sync)
+	}
+}
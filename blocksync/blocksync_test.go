@@ -0,0 +1,44 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	bc "github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/network"
+	pb "github.com/iotexproject/iotex-core/proto"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+const (
+	testingConfigPath = "../config.yaml"
+	testDBPath        = "db.test"
+)
+
+func TestProcessSyncRequestPastTip(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	chain, err := bc.CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer chain.Close()
+	bs := &blockSyncer{ackSyncReq: true, bc: chain, p2p: network.NewOverlay(&cfg.Network)}
+
+	// a peer behind asks up to the height it heard of, which may be past the tip
+	tip := chain.TipHeight()
+	assert.Nil(bs.ProcessSyncRequest(context.Background(), "127.0.0.1:10001", &pb.BlockSync{Start: 0, End: tip + MaxBlocksPerMsg + 1}))
+	assert.Nil(bs.ProcessSyncRequest(context.Background(), "127.0.0.1:10001", &pb.BlockSync{Start: tip + 1, End: tip + 2}))
+}
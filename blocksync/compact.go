@@ -0,0 +1,162 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	bc "github.com/iotexproject/iotex-core/blockchain"
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	pb "github.com/iotexproject/iotex-core/proto"
+)
+
+// MaxPendingCompactBlocks is the max number of compact blocks waiting for their missing transactions
+const MaxPendingCompactBlocks = 16
+
+// ErrTxsMismatch indicates the transactions sent back do not match the ones missing from a compact block
+var ErrTxsMismatch = errors.New("transactions do not match the missing ones")
+
+// pendingBlock is a compact block whose transactions have been partly rebuilt from the tx pool
+type pendingBlock struct {
+	cb      *bc.CompactBlock
+	tranxs  []*bc.Tx
+	missing []uint32
+}
+
+// compactPool keeps the compact blocks waiting for the transactions requested from their senders
+//
+// Once the transactions arrive they fill the holes of the compact block in the order of the request, and the
+// rebuilt block must match the merkle root in its header. The pool holds a few blocks only, the lowest ones are
+// dropped first since the block syncer catches up on them anyway.
+type compactPool struct {
+	mu     sync.Mutex
+	blocks map[cp.Hash32B]*pendingBlock
+}
+
+func newCompactPool() *compactPool {
+	return &compactPool{blocks: make(map[cp.Hash32B]*pendingBlock)}
+}
+
+// add keeps the compact block until its missing transactions arrive
+func (p *compactPool) add(cb *bc.CompactBlock, tranxs []*bc.Tx, missing []uint32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.blocks) >= MaxPendingCompactBlocks {
+		var lowest cp.Hash32B
+		height := ^uint32(0)
+		for hash, pending := range p.blocks {
+			if pending.cb.Height() <= height {
+				lowest, height = hash, pending.cb.Height()
+			}
+		}
+		delete(p.blocks, lowest)
+	}
+	p.blocks[cb.HashBlock()] = &pendingBlock{cb, tranxs, missing}
+}
+
+// fill fills the missing transactions of the compact block and returns the rebuilt block, or nil if the compact
+// block is not pending
+func (p *compactPool) fill(txs *pb.BlockTxsContainer) (*bc.Block, error) {
+	var hash cp.Hash32B
+	copy(hash[:], txs.BlockHash)
+
+	p.mu.Lock()
+	pending, ok := p.blocks[hash]
+	delete(p.blocks, hash)
+	p.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	if len(txs.Txs) != len(pending.missing) {
+		return nil, errors.Wrapf(ErrTxsMismatch, "%d transactions in place of %d", len(txs.Txs), len(pending.missing))
+	}
+	for i, txPb := range txs.Txs {
+		tx := &bc.Tx{}
+		tx.ConvertFromTxPb(txPb)
+		pending.tranxs[pending.missing[i]] = tx
+	}
+	return pending.cb.Block(pending.tranxs)
+}
+
+// prune drops the compact blocks at or below the blockchain tip
+func (p *compactPool) prune(tip uint32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for hash, pending := range p.blocks {
+		if pending.cb.Height() <= tip {
+			delete(p.blocks, hash)
+		}
+	}
+}
+
+// ProcessCompactBlock processes an incoming compact block, the block is rebuilt from the tx pool and processed as
+// a latest committed block, unless some transactions are missing, which are then requested from the sender
+func (bs *blockSyncer) ProcessCompactBlock(cbPb *pb.CompactBlockPb) error {
+	if !bs.ackBlockCommit {
+		// node is not meant to handle latest committed block, simply exit
+		return nil
+	}
+
+	cb := &bc.CompactBlock{}
+	cb.ConvertFromCompactBlockPb(cbPb)
+	tip := bs.bc.TipHeight()
+	bs.cbs.prune(tip)
+	if cb.Height() <= tip {
+		return nil
+	}
+
+	tranxs, missing := cb.FillTxs(bs.tp.Txs())
+	if len(missing) == 0 {
+		blk, err := cb.Block(tranxs)
+		if err != nil {
+			return err
+		}
+		return bs.ProcessBlock(blk)
+	}
+
+	log.Warningf("------ [%s] request %d transactions of compact block %d from %s", bs.p2p.PRC.Addr, len(missing), cb.Height(), cbPb.SenderAddr)
+	bs.cbs.add(cb, tranxs, missing)
+	hash := cb.HashBlock()
+	return bs.p2p.Tell(cm.NewTCPNode(cbPb.SenderAddr), &pb.BlockTxsSync{BlockHash: hash[:], Indexes: missing})
+}
+
+// ProcessBlockTxsSyncRequest processes a request for the transactions of a block at the given indexes
+func (bs *blockSyncer) ProcessBlockTxsSyncRequest(sender string, sync *pb.BlockTxsSync) error {
+	if !bs.ackSyncReq {
+		// node is not meant to handle sync request, simply exit
+		return nil
+	}
+
+	var hash cp.Hash32B
+	copy(hash[:], sync.BlockHash)
+	blk, err := bs.bc.GetBlockByHash(hash)
+	if err != nil {
+		return err
+	}
+	txs := &pb.BlockTxsContainer{BlockHash: sync.BlockHash}
+	for _, i := range sync.Indexes {
+		if i >= uint32(len(blk.Tranxs)) {
+			return errors.Errorf("block %x has no transaction %d", hash, i)
+		}
+		txs.Txs = append(txs.Txs, blk.Tranxs[i].ConvertToTxPb())
+	}
+	return bs.p2p.Tell(cm.NewTCPNode(sender), txs)
+}
+
+// ProcessBlockTxs processes the transactions missing from a pending compact block, the rebuilt block is processed
+// as a latest committed block
+func (bs *blockSyncer) ProcessBlockTxs(txs *pb.BlockTxsContainer) error {
+	blk, err := bs.cbs.fill(txs)
+	if err != nil || blk == nil {
+		return err
+	}
+	return bs.ProcessBlock(blk)
+}
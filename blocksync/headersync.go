@@ -0,0 +1,154 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	bc "github.com/iotexproject/iotex-core/blockchain"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	pb "github.com/iotexproject/iotex-core/proto"
+)
+
+const (
+	// MaxHeadersPerMsg is the max number of headers sent back for one header sync request
+	MaxHeadersPerMsg = 2000
+	// DefaultBodyBatchSize is the number of block bodies requested in one batch if not configured
+	DefaultBodyBatchSize = 16
+)
+
+var (
+	// ErrHeaderNotLinked indicates a header does not link to its predecessor
+	ErrHeaderNotLinked = errors.New("header does not link to the previous header")
+	// ErrUnknownHeader indicates a block body arrives for a height whose header has not been verified
+	ErrUnknownHeader = errors.New("no verified header for block")
+	// ErrBodyMismatch indicates a block body does not match its verified header
+	ErrBodyMismatch = errors.New("block does not match verified header")
+)
+
+// headerChain keeps the hashes of the headers that have been downloaded and verified, but whose
+// block bodies have not been committed yet
+//
+// A header is only accepted when its height follows the previous one and its prevBlockHash equals the
+// hash of the previous header, the very first one linking to the local blockchain tip. Block bodies
+// received afterwards must hash to the verified header at the same height before they are buffered.
+type headerChain struct {
+	mu       sync.RWMutex
+	hashes   map[uint32]cp.Hash32B
+	last     uint32
+	lastHash cp.Hash32B
+}
+
+func newHeaderChain() *headerChain {
+	return &headerChain{hashes: make(map[uint32]cp.Hash32B)}
+}
+
+// reset drops all verified headers and restarts the header chain from the given tip
+func (hc *headerChain) reset(height uint32, hash cp.Hash32B) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.hashes = make(map[uint32]cp.Hash32B)
+	hc.last = height
+	hc.lastHash = hash
+}
+
+// tip returns the height of the last verified header
+func (hc *headerChain) tip() uint32 {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	return hc.last
+}
+
+// addHeaders verifies the linkage of the headers and appends them to the chain
+// It returns the height range [start, end] that has been newly verified
+func (hc *headerChain) addHeaders(headers []*pb.BlockHeaderPb) (uint32, uint32, error) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	start := hc.last + 1
+	// headers are verified as a whole, the chain is untouched if any of them fails
+	last, lastHash := hc.last, hc.lastHash
+	verified := make([]cp.Hash32B, 0, len(headers))
+	for _, header := range headers {
+		if header.GetHeight() <= hc.last && len(verified) == 0 {
+			// already have it
+			start = header.GetHeight() + 1
+			continue
+		}
+		if header.GetHeight() != last+1 {
+			return 0, 0, errors.Wrapf(ErrHeaderNotLinked, "expect height %d, got %d", last+1, header.GetHeight())
+		}
+		var prev cp.Hash32B
+		copy(prev[:], header.GetPrevBlockHash())
+		if prev != lastHash {
+			return 0, 0, errors.Wrapf(ErrHeaderNotLinked, "height %d", header.GetHeight())
+		}
+		last = header.GetHeight()
+		lastHash = hashHeader(header)
+		verified = append(verified, lastHash)
+	}
+	if len(verified) == 0 {
+		return 0, 0, nil
+	}
+
+	for i, hash := range verified {
+		hc.hashes[start+uint32(i)] = hash
+	}
+	hc.last, hc.lastHash = last, lastHash
+	return start, last, nil
+}
+
+// verifyBody checks the block against the verified header at the same height
+func (hc *headerChain) verifyBody(blk *bc.Block) error {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	hash, ok := hc.hashes[blk.Height()]
+	if !ok {
+		return errors.Wrapf(ErrUnknownHeader, "height %d", blk.Height())
+	}
+	if blk.HashBlock() != hash {
+		return errors.Wrapf(ErrBodyMismatch, "height %d", blk.Height())
+	}
+	// the transactions are checked against the merkle root in the header ahead of the commit, so the body is
+	// requested again from another peer right away
+	if !blk.VerifyMerkleRoot() {
+		return errors.Wrapf(ErrBodyMismatch, "height %d has wrong transactions", blk.Height())
+	}
+	return nil
+}
+
+// remove drops the header at the given height once its block has been committed
+func (hc *headerChain) remove(height uint32) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	delete(hc.hashes, height)
+}
+
+// hashHeader computes the block hash from a header, the block hash only covers the header
+func hashHeader(header *pb.BlockHeaderPb) cp.Hash32B {
+	blk := bc.Block{}
+	blk.ConvertFromBlockHeaderPb(&pb.BlockPb{Header: header})
+	return blk.HashBlock()
+}
+
+// bodyBatches splits the range [start, end] into block sync requests of at most size blocks each
+func bodyBatches(start, end, size uint32) []*pb.BlockSync {
+	if size == 0 {
+		size = DefaultBodyBatchSize
+	}
+	var batches []*pb.BlockSync
+	for s := start; s <= end && s >= start; s += size {
+		e := s + size - 1
+		if e > end || e < s {
+			e = end
+		}
+		batches = append(batches, &pb.BlockSync{Start: s, End: e})
+	}
+	return batches
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	bc "github.com/iotexproject/iotex-core/blockchain"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	pb "github.com/iotexproject/iotex-core/proto"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func testingChain(n int) []*bc.Block {
+	blks := []*bc.Block{}
+	prev := cp.ZeroHash32B
+	for i := 0; i < n; i++ {
+		cbtx := bc.NewCoinbaseTx(ta.Addrinfo["miner"].Address, uint64(i+1), "")
+		blk := bc.NewBlock(0, uint32(i), prev, []*bc.Tx{cbtx})
+		blks = append(blks, blk)
+		prev = blk.HashBlock()
+	}
+	return blks
+}
+
+func TestHeaderChain(t *testing.T) {
+	assert := assert.New(t)
+
+	blks := testingChain(6)
+	headers := []*pb.BlockHeaderPb{}
+	for _, blk := range blks {
+		headers = append(headers, blk.ConvertToBlockHeaderPb())
+	}
+
+	hc := newHeaderChain()
+	hc.reset(0, blks[0].HashBlock())
+
+	// headers must link to the tip
+	_, _, err := hc.addHeaders(headers[2:4])
+	assert.Equal(ErrHeaderNotLinked, errors.Cause(err))
+	assert.Equal(uint32(0), hc.tip())
+
+	start, end, err := hc.addHeaders(headers[1:4])
+	assert.Nil(err)
+	assert.Equal(uint32(1), start)
+	assert.Equal(uint32(3), end)
+
+	// overlapping headers are skipped
+	start, end, err = hc.addHeaders(headers[2:])
+	assert.Nil(err)
+	assert.Equal(uint32(4), start)
+	assert.Equal(uint32(5), end)
+	assert.Equal(uint32(5), hc.tip())
+
+	// a tampered header breaks the linkage
+	fake := bc.NewBlock(0, 6, blks[4].HashBlock(), blks[5].Tranxs)
+	_, _, err = hc.addHeaders([]*pb.BlockHeaderPb{fake.ConvertToBlockHeaderPb()})
+	assert.Equal(ErrHeaderNotLinked, errors.Cause(err))
+
+	// bodies are checked against the verified headers
+	assert.Nil(hc.verifyBody(blks[3]))
+	other := bc.NewBlock(0, 3, blks[2].HashBlock(), []*bc.Tx{bc.NewCoinbaseTx(ta.Addrinfo["alfa"].Address, 1, "")})
+	assert.Equal(ErrBodyMismatch, errors.Cause(hc.verifyBody(other)))
+	hc.remove(3)
+	assert.Equal(ErrUnknownHeader, errors.Cause(hc.verifyBody(blks[3])))
+}
+
+func TestBodyBatches(t *testing.T) {
+	assert := assert.New(t)
+
+	batches := bodyBatches(1, 10, 4)
+	assert.Equal([]*pb.BlockSync{{Start: 1, End: 4}, {Start: 5, End: 8}, {Start: 9, End: 10}}, batches)
+	batches = bodyBatches(5, 5, 0)
+	assert.Equal([]*pb.BlockSync{{Start: 5, End: 5}}, batches)
+}
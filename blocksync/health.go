@@ -0,0 +1,155 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotexproject/iotex-core/common/routine"
+	"github.com/iotexproject/iotex-core/config"
+)
+
+// The health states of the tip of the blockchain
+const (
+	// TipHealthy means a block has been committed within the stale tip threshold
+	TipHealthy = "healthy"
+	// TipStale means no block has been committed within the threshold, and no peer tells a higher tip
+	TipStale = "stale"
+	// TipResyncing means no block has been committed within the threshold, and the blocks up to the higher tip of a
+	// peer are requested
+	TipResyncing = "resyncing"
+)
+
+// HealthStatus is the health of the tip of the blockchain as of the last check
+type HealthStatus struct {
+	Status    string
+	TipHeight uint32
+	// LastAdvance is the time the tip was last seen advancing
+	LastAdvance time.Time
+	// PeerTipHeight is the highest tip height told by the peers at the last probe
+	PeerTipHeight uint32
+	// Resyncs is the number of resyncs triggered by a stale tip
+	Resyncs uint64
+}
+
+// TipProber probes the peers for their tips
+type TipProber interface {
+	// ProbeTips returns the address and tip height of the peer with the highest tip, or an empty address if there is
+	// no peer
+	ProbeTips() (string, uint32)
+}
+
+// Tip provides the tip of the blockchain
+type Tip interface {
+	TipHeight() uint32
+}
+
+// TipMonitor checks periodically whether the tip of the blockchain advances, and once it has not for the threshold,
+// probes the peers for a higher tip and resyncs from the peer telling it
+type TipMonitor struct {
+	mu        sync.RWMutex
+	chain     Tip
+	prober    TipProber
+	resync    func(addr string, height uint32)
+	threshold time.Duration
+	status    HealthStatus
+	task      *routine.RecurringTask
+}
+
+// StaleTipThreshold returns how long the tip may not advance before it is stale, which is StaleTipIntervals block
+// intervals, or 0 if the check is disabled
+func StaleTipThreshold(cfg *config.Config) time.Duration {
+	var interval time.Duration
+	switch cfg.Consensus.Scheme {
+	case "RDPOS":
+		interval = cfg.Consensus.RDPoS.ProposerRotation.Interval
+	case "DPOS":
+		interval = cfg.Consensus.DPoS.BlockInterval
+	default:
+		interval = cfg.Consensus.BlockCreationInterval
+	}
+	return time.Duration(cfg.BlockSync.StaleTipIntervals) * interval
+}
+
+// NewTipMonitor creates a monitor taking the tip as stale once it has not advanced for the threshold, and calling
+// resync with the peer telling the highest tip if it is above the stale one
+// The tip is checked every tenth of the threshold, or every second if that is shorter.
+func NewTipMonitor(chain Tip, prober TipProber, resync func(addr string, height uint32), threshold time.Duration) *TipMonitor {
+	m := &TipMonitor{chain: chain, prober: prober, resync: resync, threshold: threshold}
+	interval := threshold / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+	m.task = routine.NewRecurringTask(m, interval)
+	return m
+}
+
+// Start starts checking the tip periodically
+func (m *TipMonitor) Start() error {
+	m.check(time.Now())
+	return m.task.Start()
+}
+
+// Stop stops checking the tip
+func (m *TipMonitor) Stop() error {
+	return m.task.Stop()
+}
+
+// Do checks the tip, see check
+func (m *TipMonitor) Do() {
+	m.check(time.Now())
+}
+
+// Health returns the health of the tip as of the last check
+func (m *TipMonitor) Health() HealthStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// check records the tip if it advanced since the last check, otherwise, once it has not advanced for the threshold,
+// probes the peers and resyncs from the one telling a higher tip
+// The peers are probed again at every check while the tip stays stale.
+func (m *TipMonitor) check(now time.Time) {
+	tip := m.chain.TipHeight()
+	m.mu.Lock()
+	if tip != m.status.TipHeight || m.status.LastAdvance.IsZero() {
+		m.status.Status = TipHealthy
+		m.status.TipHeight = tip
+		m.status.LastAdvance = now
+		m.mu.Unlock()
+		staleTipGauge.Set(0)
+		return
+	}
+	stale := now.Sub(m.status.LastAdvance) >= m.threshold
+	m.mu.Unlock()
+	if !stale {
+		return
+	}
+
+	staleTipGauge.Set(1)
+	addr, height := m.prober.ProbeTips()
+	peerTipGauge.Set(int64(height))
+	status := TipStale
+	if addr != "" && height > tip {
+		log.Warningf("No block committed since %d for %v, resync up to %d from %s", tip, m.threshold, height, addr)
+		m.resync(addr, height)
+		resyncCounter.Inc()
+		status = TipResyncing
+	} else {
+		log.Warningf("No block committed since %d for %v, no peer is ahead", tip, m.threshold)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.Status = status
+	m.status.PeerTipHeight = height
+	if status == TipResyncing {
+		m.status.Resyncs++
+	}
+}
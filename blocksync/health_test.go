@@ -0,0 +1,87 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+type testTip struct {
+	height uint32
+}
+
+func (t *testTip) TipHeight() uint32 { return t.height }
+
+type testProber struct {
+	addr   string
+	height uint32
+	probes int
+}
+
+func (p *testProber) ProbeTips() (string, uint32) {
+	p.probes++
+	return p.addr, p.height
+}
+
+func TestTipMonitor(t *testing.T) {
+	assert := assert.New(t)
+
+	tip := &testTip{height: 10}
+	prober := &testProber{}
+	var resyncs []uint32
+	m := NewTipMonitor(tip, prober, func(addr string, height uint32) {
+		assert.Equal("peer", addr)
+		resyncs = append(resyncs, height)
+	}, time.Minute)
+
+	now := time.Now()
+	m.check(now)
+	assert.Equal(HealthStatus{Status: TipHealthy, TipHeight: 10, LastAdvance: now}, m.Health())
+
+	// the peers are not probed until the tip has not advanced for the threshold
+	m.check(now.Add(time.Minute / 2))
+	assert.Equal(0, prober.probes)
+	tip.height = 11
+	m.check(now.Add(time.Minute))
+	assert.Equal(TipHealthy, m.Health().Status)
+	m.check(now.Add(2 * time.Minute))
+	assert.Equal(1, prober.probes)
+	assert.Equal(TipStale, m.Health().Status)
+	assert.Nil(resyncs)
+
+	// a peer telling a higher tip is resynced from at every check until the tip advances
+	prober.addr, prober.height = "peer", 20
+	m.check(now.Add(2 * time.Minute))
+	m.check(now.Add(3 * time.Minute))
+	assert.Equal([]uint32{20, 20}, resyncs)
+	health := m.Health()
+	assert.Equal(TipResyncing, health.Status)
+	assert.Equal(uint32(20), health.PeerTipHeight)
+	assert.Equal(uint64(2), health.Resyncs)
+	tip.height = 20
+	m.check(now.Add(4 * time.Minute))
+	assert.Equal(TipHealthy, m.Health().Status)
+	assert.Equal(int64(0), staleTipGauge.Value())
+}
+
+func TestStaleTipThreshold(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &config.Config{}
+	cfg.Consensus.BlockCreationInterval = 10 * time.Second
+	assert.Equal(time.Duration(0), StaleTipThreshold(cfg))
+	cfg.BlockSync.StaleTipIntervals = 5
+	assert.Equal(50*time.Second, StaleTipThreshold(cfg))
+	cfg.Consensus.Scheme = "DPOS"
+	cfg.Consensus.DPoS.BlockInterval = 2 * time.Second
+	assert.Equal(10*time.Second, StaleTipThreshold(cfg))
+}
@@ -0,0 +1,17 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"github.com/iotexproject/iotex-core/metrics"
+)
+
+var (
+	staleTipGauge = metrics.NewGauge("iotex_blocksync_tip_stale", "1 if no block has been committed within the stale tip threshold, 0 otherwise")
+	peerTipGauge  = metrics.NewGauge("iotex_blocksync_peer_tip_height", "Highest tip height told by the peers at the last probe")
+	resyncCounter = metrics.NewCounter("iotex_blocksync_resyncs_total", "Number of resyncs triggered by a stale tip")
+)
@@ -0,0 +1,192 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"sync"
+	"time"
+
+	pb "github.com/iotexproject/iotex-core/proto"
+)
+
+const (
+	// DefaultDownloadWindow is the number of heights past the blockchain tip whose bodies are downloaded at a time if
+	// not configured
+	DefaultDownloadWindow = 256
+	// DefaultBodyTimeout is how long a peer has to send back the bodies of a batch if not configured
+	DefaultBodyTimeout = 10 * time.Second
+)
+
+// bodyRequest is a batch of block bodies requested from a peer
+type bodyRequest struct {
+	sync *pb.BlockSync
+	peer string
+	sent time.Time
+	// rcvd holds the heights of the batch whose bodies have been received
+	rcvd map[uint32]bool
+}
+
+// bodyAssignment is a batch of block bodies to request from a peer
+type bodyAssignment struct {
+	peer string
+	sync *pb.BlockSync
+}
+
+// bodyScheduler spreads the downloads of the block bodies of the verified headers over the peers
+//
+// Only the bodies of the heights within the download window past the blockchain tip are requested, so the bodies
+// waiting for their turn to be committed are bounded, and the window slides forward as the blocks are committed. A
+// batch which is not completely received within the timeout is requested again from the next peer.
+type bodyScheduler struct {
+	mu        sync.Mutex
+	batchSize uint32
+	window    uint32
+	timeout   time.Duration
+	peers     []string
+	nextPeer  int
+	// next is the lowest height whose body has not been requested yet, and target the height of the last verified
+	// header
+	next     uint32
+	target   uint32
+	inflight []*bodyRequest
+}
+
+func newBodyScheduler(batchSize uint32, window uint32, timeout time.Duration) *bodyScheduler {
+	if batchSize == 0 {
+		batchSize = DefaultBodyBatchSize
+	}
+	if window == 0 {
+		window = DefaultDownloadWindow
+	}
+	if timeout == 0 {
+		timeout = DefaultBodyTimeout
+	}
+	return &bodyScheduler{batchSize: batchSize, window: window, timeout: timeout}
+}
+
+// setPeers sets the peers the bodies are requested from
+func (s *bodyScheduler) setPeers(peers []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.peers = peers
+}
+
+// extend adds the bodies up to the height of the last verified header to the downloads, starting after the tip if
+// nothing is pending
+func (s *bodyScheduler) extend(tip uint32, target uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next <= tip {
+		s.next = tip + 1
+	}
+	if target > s.target {
+		s.target = target
+	}
+}
+
+// reset drops all pending downloads
+func (s *bodyScheduler) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next, s.target = 0, 0
+	s.inflight = nil
+}
+
+// schedule returns the batches to request for the heights of the download window past the tip which have not been
+// requested yet
+func (s *bodyScheduler) schedule(tip uint32, now time.Time) []bodyAssignment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.peers) == 0 {
+		return nil
+	}
+	if s.next <= tip {
+		s.next = tip + 1
+	}
+	end := s.target
+	if tip+s.window < end {
+		end = tip + s.window
+	}
+	if s.next > end {
+		return nil
+	}
+	var reqs []bodyAssignment
+	for _, batch := range bodyBatches(s.next, end, s.batchSize) {
+		req := &bodyRequest{sync: batch, peer: s.pickPeer(""), sent: now, rcvd: map[uint32]bool{}}
+		s.inflight = append(s.inflight, req)
+		reqs = append(reqs, bodyAssignment{req.peer, req.sync})
+	}
+	s.next = end + 1
+	return reqs
+}
+
+// received marks the body at the height received, completing its batch once all its bodies are received
+func (s *bodyScheduler) received(height uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, req := range s.inflight {
+		if height < req.sync.Start || height > req.sync.End {
+			continue
+		}
+		req.rcvd[height] = true
+		if uint32(len(req.rcvd)) == req.sync.End-req.sync.Start+1 {
+			s.inflight = append(s.inflight[:i], s.inflight[i+1:]...)
+		}
+		return
+	}
+}
+
+// expire returns the batches which have not been completely received within the timeout, or whose bodies at or
+// below the tip are committed otherwise, to request again from the next peers
+func (s *bodyScheduler) expire(tip uint32, now time.Time) []bodyAssignment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var reqs []bodyAssignment
+	inflight := s.inflight[:0]
+	for _, req := range s.inflight {
+		if req.sync.End <= tip {
+			// committed from the bodies received elsewhere
+			continue
+		}
+		inflight = append(inflight, req)
+		if now.Sub(req.sent) < s.timeout || len(s.peers) == 0 {
+			continue
+		}
+		req.peer = s.pickPeer(req.peer)
+		req.sent = now
+		reqs = append(reqs, bodyAssignment{req.peer, req.sync})
+	}
+	s.inflight = inflight
+	return reqs
+}
+
+// retry returns the batch of the height to request again from the next peer right away, e.g., because a peer sent a
+// body not matching the verified header, none if the height is not being downloaded
+func (s *bodyScheduler) retry(height uint32, now time.Time) []bodyAssignment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, req := range s.inflight {
+		if height < req.sync.Start || height > req.sync.End || len(s.peers) == 0 {
+			continue
+		}
+		req.peer = s.pickPeer(req.peer)
+		req.sent = now
+		return []bodyAssignment{{req.peer, req.sync}}
+	}
+	return nil
+}
+
+// pickPeer returns the next peer in turn, which differs from the given one if there is another peer
+func (s *bodyScheduler) pickPeer(not string) string {
+	peer := s.peers[s.nextPeer%len(s.peers)]
+	s.nextPeer++
+	if peer == not && len(s.peers) > 1 {
+		peer = s.peers[s.nextPeer%len(s.peers)]
+		s.nextPeer++
+	}
+	return peer
+}
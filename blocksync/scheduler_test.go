@@ -0,0 +1,60 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/iotexproject/iotex-core/proto"
+)
+
+func TestBodyScheduler(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	s := newBodyScheduler(4, 10, time.Second)
+	assert.Nil(s.schedule(0, now))
+
+	// only the bodies within the window past the tip are requested, spread over the peers
+	s.setPeers([]string{"a", "b"})
+	s.extend(0, 20)
+	reqs := s.schedule(0, now)
+	assert.Equal([]bodyAssignment{
+		{"a", &pb.BlockSync{Start: 1, End: 4}},
+		{"b", &pb.BlockSync{Start: 5, End: 8}},
+		{"a", &pb.BlockSync{Start: 9, End: 10}},
+	}, reqs)
+	assert.Nil(s.schedule(0, now))
+
+	// the window slides forward with the tip
+	reqs = s.schedule(4, now)
+	assert.Equal([]bodyAssignment{{"b", &pb.BlockSync{Start: 11, End: 14}}}, reqs)
+
+	// a batch received in full is done, the others are requested again from another peer once they time out
+	for h := uint32(5); h <= 8; h++ {
+		s.received(h)
+	}
+	s.received(9)
+	assert.Nil(s.expire(8, now))
+	reqs = s.expire(8, now.Add(time.Second))
+	assert.Equal([]bodyAssignment{
+		{"b", &pb.BlockSync{Start: 9, End: 10}},
+		{"a", &pb.BlockSync{Start: 11, End: 14}},
+	}, reqs)
+
+	// a bad body has its batch requested again right away
+	reqs = s.retry(12, now)
+	assert.Equal([]bodyAssignment{{"b", &pb.BlockSync{Start: 11, End: 14}}}, reqs)
+	assert.Nil(s.retry(30, now))
+
+	s.reset()
+	assert.Nil(s.schedule(14, now))
+	assert.Nil(s.expire(14, now.Add(time.Hour)))
+}
@@ -10,7 +10,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/pkg/errors"
 
 	"github.com/iotexproject/iotex-core/blockchain"
@@ -96,7 +95,7 @@ func (sw *SlidingWindow) updateState() {
 	default:
 		sw.State = Open
 	}
-	glog.Infof("window = [%d  %d], state = %d | %d", sw.close, sw.open, sw.prevState, sw.State)
+	log.Infof("window = [%d  %d], state = %d | %d", sw.close, sw.open, sw.prevState, sw.State)
 }
 
 // Update updates the window [close, open]
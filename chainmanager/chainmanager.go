@@ -0,0 +1,144 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package chainmanager
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+var log = logger.New("chainmanager")
+
+// ErrDuplicateChain is the error returned when two of the chains hosted by the node have the same chain ID
+var ErrDuplicateChain = errors.New("duplicate chain ID")
+
+// ChainManager hosts the independent chains of the node keyed by their chain IDs, the main chain of the config along
+// with its subchains
+// A subchain is created with the config of the node whose chain config is replaced by that of the subchain, so that
+// it has its own genesis and chain DB. Only the main chain is fed by the txpool, the consensus and the block syncer of
+// the node, the blocks of a subchain are added to it directly, e.g., by importing them or by relaying deposits.
+// The main chain and each subchain are peer chains of each other, so the deposits committed on either one can be
+// withdrawn on the other.
+type ChainManager struct {
+	main   *blockchain.Blockchain
+	chains map[uint32]*blockchain.Blockchain
+	// confirmations are the CrossChainConfirmations of the chains by ID
+	confirmations map[uint32]uint32
+	relayers      []*Relayer
+}
+
+// New creates the main chain of the config, whose genesis block mints the total supply to address without a genesis
+// file, and the subchains of the config
+func New(ctx context.Context, address string, cfg *config.Config) (*ChainManager, error) {
+	main, err := blockchain.CreateBlockchain(ctx, address, cfg)
+	if err != nil {
+		return nil, err
+	}
+	m := &ChainManager{
+		main:          main,
+		chains:        map[uint32]*blockchain.Blockchain{main.ChainID(): main},
+		confirmations: map[uint32]uint32{main.ChainID(): cfg.Chain.CrossChainConfirmations},
+	}
+	for i := range cfg.SubChains {
+		subCfg := *cfg
+		subCfg.Chain = cfg.SubChains[i]
+		// the network preset only applies to the main chain
+		subCfg.Net = ""
+		if err := m.addChain(ctx, &subCfg); err != nil {
+			m.Stop()
+			return nil, errors.Wrapf(err, "Failed to create subchain %d", i)
+		}
+	}
+	return m, nil
+}
+
+// addChain creates the chain of the config and hosts it unless a chain of the same ID is already hosted
+func (m *ChainManager) addChain(ctx context.Context, cfg *config.Config) error {
+	bc, err := blockchain.CreateBlockchain(ctx, "", cfg)
+	if err != nil {
+		return err
+	}
+	id := bc.ChainID()
+	if _, ok := m.chains[id]; ok {
+		bc.Stop()
+		return errors.Wrapf(ErrDuplicateChain, "chain ID %d", id)
+	}
+	m.chains[id] = bc
+	m.confirmations[id] = cfg.Chain.CrossChainConfirmations
+	m.main.SetPeerChain(bc)
+	bc.SetPeerChain(m.main)
+	log.Infof("Hosting chain %d at height %d", id, bc.TipHeight())
+	return nil
+}
+
+// MainChain returns the main chain
+func (m *ChainManager) MainChain() *blockchain.Blockchain {
+	return m.main
+}
+
+// Chain returns the chain of the ID, or nil if it is not hosted
+func (m *ChainManager) Chain(chainID uint32) blockchain.IBlockchain {
+	if bc, ok := m.chains[chainID]; ok {
+		return bc
+	}
+	return nil
+}
+
+// ChainIDs returns the sorted IDs of the hosted chains
+func (m *ChainManager) ChainIDs() []uint32 {
+	ids := make([]uint32, 0, len(m.chains))
+	for id := range m.chains {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// StartRelayers starts relaying the deposits from the main chain to each subchain and back, the blocks committed to
+// the destination chains pay the producer, see Relayer
+func (m *ChainManager) StartRelayers(producer string) error {
+	for _, id := range m.ChainIDs() {
+		if id == m.main.ChainID() {
+			continue
+		}
+		sub := m.chains[id]
+		for _, r := range []*Relayer{
+			NewRelayer(m.main, sub, producer, m.confirmations[id]),
+			NewRelayer(sub, m.main, producer, m.confirmations[m.main.ChainID()]),
+		} {
+			if err := r.Start(); err != nil {
+				return err
+			}
+			m.relayers = append(m.relayers, r)
+		}
+	}
+	return nil
+}
+
+// Stop stops the relayers and all the hosted chains, and returns the first error
+func (m *ChainManager) Stop() error {
+	for _, r := range m.relayers {
+		r.Stop()
+	}
+	m.relayers = nil
+	var firstErr error
+	for _, id := range m.ChainIDs() {
+		if err := m.chains[id].Stop(); err != nil {
+			log.Errorf("Failed to stop chain %d: %v", id, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
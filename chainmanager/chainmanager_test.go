@@ -0,0 +1,135 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package chainmanager
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+const (
+	testingConfigPath = "../config.yaml"
+	testDBPath        = "db.test"
+	testSubDBPath     = "subdb.test"
+)
+
+func TestChainManager(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	defer os.Remove(testDBPath)
+	defer os.Remove(testSubDBPath)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.GenesisPath = ""
+	sub := cfg.Chain
+	sub.ChainDBPath = testSubDBPath
+	sub.GenesisPath = "../genesis.json"
+	cfg.SubChains = []config.Chain{sub}
+
+	m, err := New(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	require.Nil(err)
+	assert.Equal([]uint32{0, 1}, m.ChainIDs())
+	assert.Equal(uint32(0), m.MainChain().ChainID())
+	assert.Equal(m.MainChain(), m.Chain(0))
+	require.NotNil(m.Chain(1))
+	assert.Equal(uint32(1), m.Chain(1).ChainID())
+	assert.Equal(uint64(500000000), m.Chain(1).BalanceOf(ta.Addrinfo["alfa"].Address, 0))
+	assert.Nil(m.Chain(2))
+	require.Nil(m.Stop())
+
+	// the chains are reopened from their own DB
+	m, err = New(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	require.Nil(err)
+	assert.Equal([]uint32{0, 1}, m.ChainIDs())
+	require.Nil(m.Stop())
+
+	// two subchains of the same genesis have the same chain ID
+	dup := sub
+	dup.ChainDBBackend = "MEMORY"
+	cfg.SubChains = append(cfg.SubChains, dup)
+	_, err = New(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Equal(ErrDuplicateChain, errors.Cause(err))
+}
+
+func TestRelayer(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	cfg.Chain.ChainDBBackend = "MEMORY"
+	cfg.Chain.CrossChainConfirmations = 1
+	src, err := blockchain.CreateBlockchainWithGenesis(context.Background(), cfg, &config.Genesis{
+		ChainID:     1,
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+		Accounts:    []config.Allocation{{Address: ta.Addrinfo["alfa"].Address, Amount: 50}},
+	})
+	require.Nil(err)
+	defer src.Stop()
+	dst, err := blockchain.CreateBlockchainWithGenesis(context.Background(), cfg, &config.Genesis{
+		ChainID:     2,
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+	})
+	require.Nil(err)
+	defer dst.Stop()
+	dst.SetPeerChain(src)
+
+	alfa := wallet.NewKeySigner(ta.Addrinfo["alfa"])
+	commit := func(deposits ...*blockchain.Deposit) {
+		blk, err := src.MintNewBlockWithDeposits(nil, nil, nil, nil, nil, deposits, nil, ta.Addrinfo["miner"].Address, "")
+		require.Nil(err)
+		require.Nil(src.AddBlockCommit(blk))
+	}
+	deposit := blockchain.NewDeposit(1, 20, ta.Addrinfo["alfa"].Address, 1, 2, ta.Addrinfo["bravo"].Address)
+	require.Nil(deposit.Sign(alfa))
+	commit(deposit)
+
+	// the deposit is relayed once it is confirmed
+	r := NewRelayer(src, dst, ta.Addrinfo["miner"].Address, 1)
+	n, err := r.Relay()
+	require.Nil(err)
+	assert.Equal(0, n)
+	commit()
+	n, err = r.Relay()
+	require.Nil(err)
+	assert.Equal(1, n)
+	assert.Equal(uint32(1), dst.TipHeight())
+	assert.Equal(uint64(20), dst.AccountState(ta.Addrinfo["bravo"].Address).Balance)
+	n, err = r.Relay()
+	require.Nil(err)
+	assert.Equal(0, n)
+
+	// a new relayer skips the deposits already withdrawn
+	r = NewRelayer(src, dst, ta.Addrinfo["miner"].Address, 1)
+	n, err = r.Relay()
+	require.Nil(err)
+	assert.Equal(0, n)
+
+	// the started relayer watches the new blocks of the source chain
+	require.Nil(r.Start())
+	deposit = blockchain.NewDeposit(2, 5, ta.Addrinfo["alfa"].Address, 1, 2, ta.Addrinfo["bravo"].Address)
+	require.Nil(deposit.Sign(alfa))
+	commit(deposit)
+	commit()
+	for i := 0; i < 100 && dst.AccountState(ta.Addrinfo["bravo"].Address).Balance != 25; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Nil(r.Stop())
+	assert.Equal(uint64(25), dst.AccountState(ta.Addrinfo["bravo"].Address).Balance)
+}
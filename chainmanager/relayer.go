@@ -0,0 +1,116 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package chainmanager
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+)
+
+// Relayer watches the deposits committed on the source chain and finalizes the ones to the destination chain by
+// committing blocks of their withdraws to it, once the blocks of the deposits have enough confirmations
+// It produces the blocks of the destination chain itself, hence it suits a subchain whose blocks are only added
+// directly. On a chain run by a consensus engine the blocks it produces are rejected unless the node is the proposer,
+// the withdraws are then retried on the next block of the source chain.
+type Relayer struct {
+	src blockchain.IBlockchain
+	dst blockchain.IBlockchain
+	// producer is the address the coinbase of the blocks committed to the destination chain pays
+	producer string
+	// confirmations is the number of blocks on top of a deposit before it is withdrawn, at least the
+	// CrossChainConfirmations of the destination chain
+	confirmations uint32
+
+	// next is the height of the source chain to scan from, the deposits below it are all withdrawn
+	next   uint32
+	events <-chan *blockchain.BlockEvent
+	wg     sync.WaitGroup
+}
+
+// NewRelayer returns a relayer of the deposits from the source chain to the destination chain
+func NewRelayer(src blockchain.IBlockchain, dst blockchain.IBlockchain, producer string, confirmations uint32) *Relayer {
+	return &Relayer{src: src, dst: dst, producer: producer, confirmations: confirmations}
+}
+
+// Start relays the deposits committed so far, and then the new ones on each block committed to the source chain
+func (r *Relayer) Start() error {
+	r.events = r.src.Subscribe()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.relayLogged()
+		for range r.events {
+			r.relayLogged()
+		}
+	}()
+	return nil
+}
+
+// Stop stops watching the source chain
+func (r *Relayer) Stop() error {
+	r.src.Unsubscribe(r.events)
+	r.wg.Wait()
+	return nil
+}
+
+func (r *Relayer) relayLogged() {
+	n, err := r.Relay()
+	if err != nil {
+		log.Errorf("Failed to relay deposits from chain %d to chain %d: %v", r.src.ChainID(), r.dst.ChainID(), err)
+		return
+	}
+	if n > 0 {
+		log.Infof("Relayed %d deposits from chain %d to chain %d", n, r.src.ChainID(), r.dst.ChainID())
+	}
+}
+
+// Relay commits a block withdrawing the confirmed deposits to the destination chain which are not withdrawn yet, and
+// returns the number of them
+func (r *Relayer) Relay() (int, error) {
+	tip := r.src.TipHeight()
+	if tip < r.confirmations {
+		return 0, nil
+	}
+	confirmed := tip - r.confirmations
+	var withdraws []*blockchain.Withdraw
+	for h := r.next; h <= confirmed; h++ {
+		blk, err := r.src.GetBlockByHeight(h)
+		if err != nil {
+			return 0, errors.Wrapf(err, "Failed to get block %d", h)
+		}
+		for _, deposit := range blk.Deposits {
+			if deposit.DstChainID != r.dst.ChainID() {
+				continue
+			}
+			withdrawn, err := r.dst.IsWithdrawn(deposit.Hash())
+			if err != nil {
+				return 0, err
+			}
+			if withdrawn {
+				continue
+			}
+			withdraw, err := blockchain.NewWithdraw(deposit, blk)
+			if err != nil {
+				return 0, err
+			}
+			withdraws = append(withdraws, withdraw)
+		}
+	}
+	if len(withdraws) > 0 {
+		blk, err := r.dst.MintNewBlockWithDeposits(nil, nil, nil, nil, nil, nil, withdraws, r.producer, "")
+		if err != nil {
+			return 0, err
+		}
+		if err := r.dst.AddBlockCommit(blk); err != nil {
+			return 0, err
+		}
+	}
+	r.next = confirmed + 1
+	return len(withdraws), nil
+}
@@ -19,8 +19,9 @@ type Dispatcher interface {
 	// Stop stops a dispatcher
 	Stop() error
 	// HandleBroadcast handles the incoming broadcast message. The transportation layer semantics is at least once.
-	// That said, the handler is likely to receive duplicate messages.
-	HandleBroadcast(proto.Message, chan bool)
+	// That said, the handler is likely to receive duplicate messages. The sender is the peer relaying the message, which
+	// is held accountable for it, or nil if the message originates from the node itself
+	HandleBroadcast(net.Addr, proto.Message, chan bool)
 	// HandleTell handles the incoming tell message. The transportation layer semantics is exact once. The sender is
 	// given for the sake of replying the message
 	HandleTell(net.Addr, proto.Message, chan bool)
@@ -62,15 +62,10 @@ func (c *SlidingWindowCounter) refresh() {
 	duration := int(now.Sub(c.lastUpdateTime) / c.SlotGranularity)
 	if duration >= len(c.window) {
 		for i := 0; i < len(c.window); i++ {
-			if i == 0 {
-				c.window[i] = 1
-			} else {
-				c.window[i] = 0
-			}
+			c.window[i] = 0
 		}
 		c.headIdx = 0
 		c.count = 0
-
 	} else {
 		for i := 0; i < duration; i++ {
 			c.headIdx++
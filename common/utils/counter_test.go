@@ -48,3 +48,15 @@ func TestSlidingWindowCounter3(t *testing.T) {
 		assert.True(t, slot > 0)
 	}
 }
+
+func TestSlidingWindowCounterIdle(t *testing.T) {
+	c := NewSlidingWindowCounter(300*time.Millisecond, 100*time.Millisecond)
+	c.Increment()
+	time.Sleep(400 * time.Millisecond)
+	assert.Equal(t, uint64(0), c.Count())
+	// the window slides over the slots cleared while idle without counting below zero
+	for i := 0; i < 4; i++ {
+		time.Sleep(110 * time.Millisecond)
+		assert.Equal(t, uint64(0), c.Count())
+	}
+}
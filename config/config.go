@@ -7,15 +7,16 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"time"
 
-	"github.com/golang/glog"
 	"google.golang.org/grpc/keepalive"
 	"gopkg.in/yaml.v2"
 
 	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
 )
 
 const (
@@ -58,29 +59,234 @@ type Network struct {
 	MaxMsgSize              int
 	PeerDiscovery           bool
 	TopologyPath            string
+	// DNSSeeds are the host names resolving to the addresses of the nodes to bootstrap from besides BootstrapNodes,
+	// a seed without port uses the port of Addr
+	DNSSeeds []string
+	// BanScore is the misbehavior score at which a peer is banned, peers are never banned if it is 0
+	BanScore uint
+	// BanDuration is how long a misbehaving peer is banned, 24 hours if not set
+	BanDuration time.Duration
+	// PeerStorePath is the file persisting the known good peers across restarts, disabled if empty
+	PeerStorePath string
 }
 
 // Chain is the config struct for blockchain package
 type Chain struct {
 	ChainDBPath string
+	// ChainDBBackend is the key-value store of the chain DB, BOLT (default) persisting to the file at ChainDBPath or
+	// MEMORY keeping it in memory only
+	ChainDBBackend string
+	// ChainDBNoSync skips syncing each commit to disk with the BOLT backend, trading the durability of the latest
+	// blocks on a crash for write throughput
+	ChainDBNoSync bool
+	// CompressBlock compresses the blocks written to the chain DB, blocks already written are read either way
+	CompressBlock bool
+
 	TotalSupply uint64
 	BlockReward uint64
+	// RewardHalvingEpochs is the number of epochs after which the block reward halves, 0 to never halve
+	RewardHalvingEpochs uint32
+	// RewardDecayPercent is the percentage by which the block reward decreases from an epoch to the next on top of
+	// the halvings, 0 for no decay
+	RewardDecayPercent uint32
+	// DelegateRewardPercent is the percentage of the block reward shared equally by the delegates elected for the
+	// epoch of the block, the rest of it and the fees are paid to the producer
+	DelegateRewardPercent uint32
 
 	// MinerAddr is an address where the block rewards will be sent to.
 	MinerAddr string
+	// IsTestnet is the network of the chain, whose prefix the addresses in the transfers, votes and executions of its
+	// blocks and the payees of the transactions it creates have to carry
+	IsTestnet bool
+
+	// CoinbaseMaturity is the number of confirmations before the outputs of a coinbase transaction can be spent
+	CoinbaseMaturity uint32
+	// MaxCoinbaseDataSize is the maximum size in bytes of the data of the coinbase transaction of a block other than
+	// the genesis block, 0 for no limit
+	MaxCoinbaseDataSize uint32
+	// CoinbasePolicy is the policy on the data of the coinbase transactions, STRUCTURED requires the blocks to carry
+	// the structured payload naming their producer address, epoch, height and client version. Any data is accepted if
+	// it is empty, though the structured payloads are still validated.
+	CoinbasePolicy string
+	// CoinbaseMemo is the operator memo put in the structured coinbase payload of the minted blocks, unless the data
+	// is given when minting them
+	CoinbaseMemo string
+	// AcceptLegacyTxs accepts the transactions signed without the chain ID before replay protection, which is needed
+	// to sync or verify the blocks produced before then
+	AcceptLegacyTxs bool
+
+	// Pruning enables deleting the bodies of blocks out of the retention window, headers and UTXO are kept
+	Pruning bool
+	// PruneRetention is the number of most recent blocks whose bodies are kept in pruning mode
+	PruneRetention uint32
+	// StorageBudget is the size in bytes the chain DB is kept within by offloading the bodies of the oldest blocks,
+	// 0 to never offload them
+	StorageBudget uint64
+	// StorageRetention is the number of most recent blocks whose bodies are never offloaded
+	StorageRetention uint32
+	// StorageOffload is what is done with the offloaded block bodies, ARCHIVE moves them to the block archive, from
+	// which the blocks are still read, and PRUNE deletes them. They are archived if it is empty.
+	StorageOffload string
+	// StorageCheckInterval is the interval of the checks of the chain DB size against the storage budget, 0 for a
+	// minute
+	StorageCheckInterval time.Duration
+	// StateHistory is the number of blocks below the tip at whose heights the UTXO and account states can be queried,
+	// bounding the undo records of the blocks a query goes through, 0 to only query the states at the tip
+	StateHistory uint32
+
+	// GenesisPath is the path of the genesis file. The genesis block mints TotalSupply to the miner if it is empty.
+	GenesisPath string
+	// Checkpoints pin the hashes of blocks on top of the ones hard-coded for the chain, no other block can be
+	// committed at their heights nor fork the chain below them, and the input scripts of the blocks up to the highest
+	// checkpoint are not verified
+	Checkpoints []Checkpoint
+	// MaxReorgDepth is the most blocks a fork may replace, a deeper fork verified by the consensus halts the chain until
+	// it is allowed on the admin service or a checkpoint pins the chain, 0 for no limit
+	// The chain does not switch branches by itself, the limit applies to the callers of Blockchain.CheckReorg switching
+	// to another branch, e.g., the simulated nodes.
+	MaxReorgDepth uint32
+
+	// CoinSelection is the algorithm selecting the UTXO to spend when creating a transaction, one of LARGEST_FIRST,
+	// BRANCH_AND_BOUND and RANDOM_IMPROVE. The UTXO are spent in the order they are found if it is empty.
+	CoinSelection string
+	// FeeTargetBlocks is the number of blocks a created transaction is targeted to be mined within, paying the fee
+	// estimated for the target, 0 to pay no fee
+	FeeTargetBlocks uint32
+	// DustThreshold is the amount of change below which the change is paid as fee rather than creating an output
+	DustThreshold uint64
+	// MaxTxInputs is the maximum number of UTXO a created transaction spends, 0 for no limit
+	MaxTxInputs uint32
+	// UtxoReservationTTL is how long the UTXO spent by a created transaction are excluded from coin selection before
+	// the transaction enters the txpool, 0 to not reserve them
+	UtxoReservationTTL time.Duration
+
+	// MaxBlockSize is the maximum serialized size of a block in bytes, 0 for no limit
+	MaxBlockSize uint32
+	// MaxBlockTxs is the maximum number of transactions of a block including the coinbase, 0 for no limit
+	MaxBlockTxs uint32
+	// TxOrder is the policy ordering the transactions packed into a minted block, FEE_RATE packs the ones paying the
+	// highest fee per byte first. The transactions are packed in the order they are given if it is empty. Either way
+	// a transaction is packed after the ones it spends the outputs of.
+	TxOrder string
+	// BlockGasLimit is the maximum sum of the gas limits of the contract executions of a block, 0 for no limit
+	BlockGasLimit uint64
+	// EpochLength is the number of blocks of an epoch, the delegates of an epoch are elected from the votes at the end
+	// of the previous one, 0 to disable the election
+	EpochLength uint32
+	// NumDelegates is the number of candidates with the most votes elected as the delegates of an epoch, 0 for all
+	NumDelegates uint32
+	// VerifyWorkers is the number of workers verifying the input scripts of a block in parallel, 0 for one per CPU
+	VerifyWorkers int
+	// CrossChainConfirmations is the number of blocks on top of the block committing a deposit on the source chain
+	// before the deposit can be withdrawn on the chain
+	CrossChainConfirmations uint32
+
+	// SignalWindow is the number of blocks of a window, over which the blocks signaling each deployment are counted
+	// at the end of the window, 0 to disable the deployments
+	SignalWindow uint32
+	// SignalThreshold is the number of blocks of a window that have to signal a deployment to lock it in
+	SignalThreshold uint32
+	// Deployments are the soft forks of the consensus rules activated by the signaling of the block producers
+	Deployments []Deployment
+	// SignalDeployments are the names of the deployments the minted blocks signal while they are started
+	SignalDeployments []string
+
+	// BlockCacheSize is the number of recently read blocks kept in memory, 0 to disable the cache
+	BlockCacheSize int
+	// HashCacheSize is the number of recently read block hashes by height kept in memory, 0 to disable the cache
+	HashCacheSize int
+	// ScriptCacheSize is the number of input scripts verified successfully kept in memory, so the inputs of the
+	// transactions verified when they enter the txpool are not verified again when a block includes them, 0 to disable
+	// the cache
+	ScriptCacheSize int
+	// UtxoCacheSize is the number of transactions whose unspent outputs are kept in memory, the ones written the least
+	// recently being spilled to the chain DB beyond it, 0 to keep the whole UTXO pool in memory
+	UtxoCacheSize int
+	// UtxoCompactInterval is the interval of the steps compacting the UTXO pool in the background, which reclaims the
+	// memory of the spent entries, 0 to disable the compaction
+	UtxoCompactInterval time.Duration
+
+	// BlockArchiveDir is the directory of the block archive the blocks are stored to by StoreBlock, "blocks" if empty
+	BlockArchiveDir string
+	// BlockArchiveSegmentSize is the number of heights each segment file of the block archive covers, 0 for 10000
+	BlockArchiveSegmentSize uint32
+}
+
+// Checkpoint pins the hash of the block at the height
+type Checkpoint struct {
+	Height uint32
+	// Hash is the hex encoded hash of the block
+	Hash string
+}
+
+// Deployment is a soft fork of the consensus rules signaled by a bit of the block header version
+// It starts at the first window from StartHeight, is locked in once SignalThreshold blocks of a window signal it and
+// is active from the following window, or fails if it is not locked in by TimeoutHeight.
+type Deployment struct {
+	Name string
+	// Bit is the bit of the header version signaling the deployment, from 0 to 28
+	Bit         uint32
+	StartHeight uint32
+	// TimeoutHeight is the height from which the deployment fails if it is not locked in, 0 for no timeout
+	TimeoutHeight uint32
+}
+
+// TxPool is the config struct for txpool package
+type TxPool struct {
+	// MinTxFeePerByte is the minimum fee rate a transaction has to pay to be accepted into the pool
+	MinTxFeePerByte uint64
+	// MinReplacementFeePerByte is the minimum fee rate, on top of the fees of the transactions it evicts, a
+	// transaction replacing conflicting ones has to pay
+	MinReplacementFeePerByte uint64
+	// PersistPath is the file the pending transactions are saved to on shutdown and reloaded from on startup, and
+	// an empty path disables the persistence
+	PersistPath string
+	// PersistInterval is the interval of saving the pending transactions, so they survive a crash, and 0 only saves
+	// them on shutdown
+	PersistInterval time.Duration
+	// TxTTL is the time an accepted transaction stays in the pool before it is evicted if not mined, and 0 keeps it
+	// until mined
+	TxTTL time.Duration
+	// MaxPoolSize is the max total size in bytes of the accepted transactions, beyond which the ones paying the lowest
+	// fee rate are evicted, and 0 is unlimited
+	MaxPoolSize uint64
+	// MaxQueuedActions is the max number of actions of a sender held in the pool ahead of its confirmed nonce,
+	// including the ones waiting for the missing nonces before them, and 0 is unlimited
+	MaxQueuedActions uint64
 }
 
 // Consensus is the config struct for consensus package
 type Consensus struct {
-	// There are three schemes that are supported:
+	// There are four schemes that are supported:
 	// RDPOS -- Randomized Delegated Proof of Stake
 	// STANDALONE -- The node creates a block periodically regardless of others (if there is any)
 	// NOOP -- The node does not create only block
+	// DPOS -- The node creates a block in each DPoS time slot of its delegate
 	Scheme                string
 	RDPoS                 RDPoS
+	DPoS                  DPoS
 	BlockCreationInterval time.Duration
 }
 
+// BlockSync is the config struct for blocksync package
+type BlockSync struct {
+	// HeadersFirst enables downloading and verifying the header chain before fetching block bodies
+	HeadersFirst bool
+	// BodyBatchSize is the number of block bodies requested from a peer at a time in headers-first mode
+	BodyBatchSize uint32
+	// DownloadWindow is the number of heights past the blockchain tip whose bodies are downloaded at a time in
+	// headers-first mode, 0 for 256
+	DownloadWindow uint32
+	// BodyTimeout is how long a peer has to send back the requested bodies before they are requested from the next
+	// peer in headers-first mode, 0 for 10s
+	BodyTimeout time.Duration
+	// CompactBlocks enables relaying new blocks as compact blocks, whose transactions are rebuilt from the tx pools
+	CompactBlocks bool
+	// StaleTipIntervals is the number of block intervals without a new block after which the tip is stale, hence the
+	// peers are probed for a higher tip to resync from, and 0 disables the check
+	StaleTipIntervals uint32
+}
+
 // RDPoS is the config struct for RDPoS consensus package
 type RDPoS struct {
 	ProposerRotation  ProposerRotation
@@ -90,6 +296,35 @@ type RDPoS struct {
 	AcceptVote        AcceptVote
 }
 
+// DPoS is the config struct for the DPoS consensus engine which validates the block proposers
+type DPoS struct {
+	// Enabled flags whether a block is only accepted if it is signed by the delegate of its time slot
+	Enabled bool
+	// Delegates are the addresses of the block producers, taking turns in the order listed
+	Delegates []string
+	// BlockInterval is the length of a block time slot
+	BlockInterval time.Duration
+	// ProducerPubKey and ProducerPrivKey are the hex encoded keys this node signs its blocks with, which are either the
+	// keys of a delegate or of the operator the delegate has registered on the chain
+	ProducerPubKey  string
+	ProducerPrivKey string
+	// RewardAddr is the address the rewards of the blocks this node produces are sent to, instead of the miner's
+	// address if it is not empty
+	RewardAddr string
+	// SignRecordPath is the file the last block signed by the producer is recorded in, so a restarted producer refuses
+	// to sign a block conflicting with it. The record is only kept in memory if it is empty.
+	SignRecordPath string
+	// MinTxWait is how long the DPOS scheme waits into the slot of the producer for the transactions broadcast at its
+	// start before assembling the block
+	MinTxWait time.Duration
+	// AssembleBudget is the time assembling a block from the mempool may take, an empty block is produced instead if
+	// less than that is left in the slot
+	AssembleBudget time.Duration
+	// CheckpointInterval is how often a delegate signs the tip and gossips the vote, a block signed by more than two
+	// thirds of its delegates is finalized. The votes are neither signed nor collected if it is 0.
+	CheckpointInterval time.Duration
+}
+
 // ProposerRotation is the RDPoS ProposerRotation config
 type ProposerRotation struct {
 	// Interval determines how long to propose another round of RDPoS.
@@ -129,14 +364,116 @@ type RPC struct {
 	Port string
 }
 
+// API is the config struct for the node API service
+type API struct {
+	// Addr is the address the API server binds to. The service is disabled when it is empty.
+	Addr       string
+	TLSEnabled bool
+	CertPath   string
+	KeyPath    string
+	// JSONRPCAddr is the address the JSON-RPC over HTTP and WebSocket gateway binds to. The gateway is disabled when
+	// it is empty.
+	JSONRPCAddr string
+	// CORSOrigins are the origins of the web pages allowed to call the gateway, "*" allows any origin
+	CORSOrigins []string
+	// MaxRequestSize is the max size in bytes of a request sent to the API server, or of a WebSocket message sent to
+	// the gateway
+	MaxRequestSize int64
+	// MaxBlocksPerRange and MaxBlocksPerLogQuery are the max numbers of blocks returned by one GetBlocksByRange request
+	// and searched by one GetLogs request, the defaults of the api package apply if they are zero
+	MaxBlocksPerRange    uint32
+	MaxBlocksPerLogQuery uint32
+	// RateLimitEnabled limits the requests sent by each client IP to RateLimitPerSec requests per second in total,
+	// and to MethodRateLimits requests per second to the methods, e.g., SendRawTransaction: 1, all averaged over the
+	// window
+	RateLimitEnabled    bool
+	RateLimitPerSec     uint64
+	MethodRateLimits    map[string]uint64
+	RateLimitWindowSize time.Duration
+}
+
+// Admin is the config struct for the node admin service
+type Admin struct {
+	// Addr is the address the admin HTTP server binds to. The service is disabled when it is empty.
+	Addr string
+	// Token is the bearer token every admin request must carry, required once the service is enabled
+	Token      string
+	TLSEnabled bool
+	CertPath   string
+	KeyPath    string
+	// MaxRollbackDepth is the most blocks a rollback requested on the admin service may remove, rollbacks are refused
+	// if it is 0
+	MaxRollbackDepth uint32
+}
+
+// Faucet is the config struct for the faucet service of a testnet
+type Faucet struct {
+	// Addr is the address the faucet HTTP server binds to. The service is disabled when it is empty, and can only be
+	// enabled on a testnet.
+	Addr string
+	// Account is the address of the account in the keystore of the wallet paying the faucet, unlocked with Passphrase
+	Account    string
+	Passphrase string
+	// Amount is paid to an address on each request
+	Amount uint64
+	// Interval is the minimum interval between two payments to the same address
+	Interval time.Duration
+}
+
+// Metrics is the config struct for the metrics package
+type Metrics struct {
+	// Addr is the address the HTTP server exporting the metrics binds to. The service is disabled when it is empty.
+	Addr string
+	// Pprof serves the runtime profiles of net/http/pprof on the metrics server, which should not be reachable by
+	// untrusted peers when it is enabled
+	Pprof bool
+	// SlowBlock is the time of processing a block, from its receipt to its commit, above which the durations of its
+	// stages are logged, 0 to never log them
+	SlowBlock time.Duration
+}
+
+// Log is the config struct for the logger package
+type Log struct {
+	// Level is the level of the modules without their own, one of debug, info, warn and error
+	Level string
+	// ModuleLevels are the levels by module, e.g., blockchain: debug
+	ModuleLevels map[string]string
+}
+
+// Wallet is the config struct for the wallet package
+type Wallet struct {
+	// KeystorePath is the directory where the encrypted key files of the accounts are stored
+	KeystorePath string
+	// ScryptN and ScryptP are the scrypt cost parameters deriving the key encryption keys from the passphrases
+	ScryptN int
+	ScryptP int
+	// IsTestnet and ChainID determine the addresses of the accounts created by the wallet
+	IsTestnet bool
+	ChainID   uint32
+}
+
 // Config is the root config struct, each package's config should be put as its sub struct
 type Config struct {
-	NodeType  string
-	Network   Network
-	Chain     Chain
+	NodeType string
+	// Net is the network the node joins, one of mainnet, testnet and devnet, whose preset is applied to the config
+	// when it is loaded, see Presets. The config is used as it is if Net is empty.
+	Net     string
+	Network Network
+	Chain   Chain
+	// SubChains are the chains hosted by the node along with its main chain, each with its own genesis file, whose
+	// chain ID tells the chains apart, and its own chain DB
+	SubChains []Chain
+	TxPool    TxPool
 	Consensus Consensus
+	BlockSync BlockSync
 	Delegate  Delegate
 	RPC       RPC
+	API       API
+	Admin     Admin
+	Faucet    Faucet
+	Metrics   Metrics
+	Log       Log
+	Wallet    Wallet
 }
 
 // IsDelegate returns true if the node type is Delegate
@@ -154,6 +491,14 @@ func (cfg *Config) IsLightweight() bool {
 	return cfg.NodeType == LightweightType
 }
 
+// RewardAddr returns the address the rewards of the blocks minted by the node are sent to
+func (cfg *Config) RewardAddr() string {
+	if cfg.Consensus.Scheme == "DPOS" && cfg.Consensus.DPoS.RewardAddr != "" {
+		return cfg.Consensus.DPoS.RewardAddr
+	}
+	return cfg.Chain.MinerAddr
+}
+
 // LoadConfig loads the config instance from the default config path
 func LoadConfig() (*Config, error) {
 	return LoadConfigWithPath(DefaultConfigPath)
@@ -161,34 +506,45 @@ func LoadConfig() (*Config, error) {
 
 // LoadConfigWithPath loads the config instance and validates fields
 func LoadConfigWithPath(path string) (*Config, error) {
-	return loadConfigWithPathInternal(path, true)
+	return loadConfigWithPathInternal(path, "", true)
+}
+
+// LoadConfigForNetwork loads the config instance for the network, overriding the one of the config unless it is
+// empty, and validates fields
+func LoadConfigForNetwork(path string, net string) (*Config, error) {
+	return loadConfigWithPathInternal(path, net, true)
 }
 
 // LoadConfigWithPathWithoutValidation loads the config instance but doesn't validate fields
 func LoadConfigWithPathWithoutValidation(path string) (*Config, error) {
-	return loadConfigWithPathInternal(path, false)
+	return loadConfigWithPathInternal(path, "", false)
 }
 
-// loadConfigWithPathInternal loads the config instance. If validation is true, the function will check if the fields
-// are valid or not.
-func loadConfigWithPathInternal(path string, validate bool) (*Config, error) {
+// loadConfigWithPathInternal loads the config instance and applies the preset of the network, overriding the one of
+// the config unless it is empty. If validation is true, the function will check if the fields are valid or not.
+func loadConfigWithPathInternal(path string, net string, validate bool) (*Config, error) {
 	configBytes, err := ioutil.ReadFile(path)
 	if err != nil {
-		glog.Fatalf("Error when reading the config file: %v\n", err)
-		return nil, err
+		return nil, fmt.Errorf("error when reading the config file: %v", err)
 	}
 
 	config := Config{}
 	err = yaml.Unmarshal(configBytes, &config)
 	if err != nil {
-		glog.Fatalf("Error when decoding the config file: %v\n", err)
-		return nil, err
+		return nil, fmt.Errorf("error when decoding the config file: %v", err)
+	}
+	if net != "" {
+		config.Net = net
+	}
+	if config.Net != "" {
+		if err := applyPreset(&config); err != nil {
+			return nil, fmt.Errorf("error when applying the network preset: %v", err)
+		}
 	}
 
 	if validate {
 		if err = validateConfig(&config); err != nil {
-			glog.Fatalf("Error when validating config: %v\n", err)
-			return nil, err
+			return nil, fmt.Errorf("error when validating config: %v", err)
 		}
 	}
 	return &config, nil
@@ -200,6 +556,9 @@ func validateConfig(cfg *Config) error {
 	if len(cfg.Chain.MinerAddr) > 0 && !iotxaddress.ValidateAddress(cfg.Chain.MinerAddr) {
 		return fmt.Errorf("invalid miner's address")
 	}
+	if len(cfg.Chain.MinerAddr) > 0 && iotxaddress.ValidateNetwork(cfg.Chain.MinerAddr, cfg.Chain.IsTestnet) != nil {
+		return fmt.Errorf("miner's address is not of the network of the chain")
+	}
 
 	// Validate node type
 	switch cfg.NodeType {
@@ -217,9 +576,156 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("unknown node type %s", cfg.NodeType)
 	}
 
+	if cfg.Consensus.Scheme == "DPOS" {
+		if !cfg.Consensus.DPoS.Enabled {
+			return fmt.Errorf("DPoS should be enabled for the DPOS consensus scheme")
+		}
+		if cfg.Consensus.DPoS.MinTxWait >= cfg.Consensus.DPoS.BlockInterval {
+			return fmt.Errorf("min tx wait should be shorter than the block interval")
+		}
+	}
+	if addr := cfg.Consensus.DPoS.RewardAddr; addr != "" {
+		if !iotxaddress.ValidateAddress(addr) {
+			return fmt.Errorf("invalid reward address")
+		}
+		if iotxaddress.ValidateNetwork(addr, cfg.Chain.IsTestnet) != nil {
+			return fmt.Errorf("reward address is not of the network of the chain")
+		}
+	}
+
+	if cfg.Chain.RewardDecayPercent >= 100 {
+		return fmt.Errorf("reward decay percent should be less than 100")
+	}
+	if cfg.Chain.DelegateRewardPercent > 100 {
+		return fmt.Errorf("delegate reward percent should not exceed 100")
+	}
+
+	for _, c := range cfg.Chain.Checkpoints {
+		if hash, err := hex.DecodeString(c.Hash); err != nil || len(hash) != 32 {
+			return fmt.Errorf("invalid hash %s of checkpoint %d", c.Hash, c.Height)
+		}
+	}
+
+	if len(cfg.Chain.Deployments) > 0 && cfg.Chain.SignalWindow > 0 {
+		if cfg.Chain.SignalThreshold == 0 || cfg.Chain.SignalThreshold > cfg.Chain.SignalWindow {
+			return fmt.Errorf("signal threshold should be between 1 and the signal window")
+		}
+	}
+	deployments := make(map[string]bool)
+	bits := make(map[uint32]bool)
+	for _, d := range cfg.Chain.Deployments {
+		if d.Bit > 28 {
+			return fmt.Errorf("bit %d of deployment %s should be at most 28", d.Bit, d.Name)
+		}
+		if deployments[d.Name] || bits[d.Bit] {
+			return fmt.Errorf("deployment %s shares its name or bit with another one", d.Name)
+		}
+		deployments[d.Name] = true
+		bits[d.Bit] = true
+	}
+	for _, name := range cfg.Chain.SignalDeployments {
+		if !deployments[name] {
+			return fmt.Errorf("unknown deployment %s to signal", name)
+		}
+	}
+
+	if cfg.Chain.Pruning && cfg.Chain.PruneRetention == 0 {
+		return fmt.Errorf("prune retention should be positive in pruning mode")
+	}
+	switch cfg.Chain.StorageOffload {
+	case "", "ARCHIVE", "PRUNE":
+		break
+	default:
+		return fmt.Errorf("unknown storage offload %s", cfg.Chain.StorageOffload)
+	}
+	if cfg.Chain.StorageBudget > 0 && cfg.Chain.StorageRetention == 0 {
+		return fmt.Errorf("storage retention should be positive with a storage budget")
+	}
+	if cfg.Chain.StorageBudget > 0 && cfg.Chain.Pruning && cfg.Chain.StorageOffload != "PRUNE" {
+		return fmt.Errorf("block bodies cannot be archived in pruning mode, which deletes them")
+	}
+
+	switch cfg.Chain.ChainDBBackend {
+	case "", "BOLT", "MEMORY":
+		break
+	default:
+		return fmt.Errorf("unknown chain DB backend %s", cfg.Chain.ChainDBBackend)
+	}
+
+	dbPaths := map[string]bool{cfg.Chain.ChainDBPath: true}
+	for i, c := range cfg.SubChains {
+		if c.GenesisPath == "" {
+			return fmt.Errorf("subchain %d should have a genesis file", i)
+		}
+		if c.ChainDBBackend == "MEMORY" {
+			continue
+		}
+		if dbPaths[c.ChainDBPath] {
+			return fmt.Errorf("chain DB path %s is shared by several chains", c.ChainDBPath)
+		}
+		dbPaths[c.ChainDBPath] = true
+	}
+
+	switch cfg.Chain.CoinSelection {
+	case "", "LARGEST_FIRST", "BRANCH_AND_BOUND", "RANDOM_IMPROVE":
+		break
+	default:
+		return fmt.Errorf("unknown coin selection %s", cfg.Chain.CoinSelection)
+	}
+
+	switch cfg.Chain.CoinbasePolicy {
+	case "", "STRUCTURED":
+		break
+	default:
+		return fmt.Errorf("unknown coinbase policy %s", cfg.Chain.CoinbasePolicy)
+	}
+
+	if max := cfg.Chain.MaxCoinbaseDataSize; max > 0 && len(cfg.Chain.CoinbaseMemo) > int(max) {
+		return fmt.Errorf("coinbase memo should not exceed max coinbase data size")
+	}
+
+	switch cfg.Chain.TxOrder {
+	case "", "FEE_RATE":
+		break
+	default:
+		return fmt.Errorf("unknown tx order %s", cfg.Chain.TxOrder)
+	}
+
+	if cfg.Chain.MaxBlockSize > 0 && cfg.Network.MaxMsgSize > 0 && int(cfg.Chain.MaxBlockSize) > cfg.Network.MaxMsgSize {
+		return fmt.Errorf("max block size should not exceed max message size")
+	}
+
 	if !cfg.Network.PeerDiscovery && cfg.Network.TopologyPath == "" {
 		return fmt.Errorf("either peer discover should be enabled or a topology should be given")
 	}
+
+	if cfg.API.RateLimitEnabled && cfg.API.RateLimitWindowSize < time.Second {
+		return fmt.Errorf("API rate limit window should be at least a second")
+	}
+
+	if cfg.Admin.Addr != "" && cfg.Admin.Token == "" {
+		return fmt.Errorf("admin token should be given when the admin service is enabled")
+	}
+
+	if cfg.Faucet.Addr != "" {
+		if !cfg.Chain.IsTestnet {
+			return fmt.Errorf("faucet should only be enabled on a testnet")
+		}
+		if cfg.Faucet.Account == "" || cfg.Faucet.Amount == 0 {
+			return fmt.Errorf("faucet account and amount should be given when the faucet is enabled")
+		}
+	}
+
+	if cfg.Log.Level != "" {
+		if _, err := logger.ParseLevel(cfg.Log.Level); err != nil {
+			return err
+		}
+	}
+	for module, level := range cfg.Log.ModuleLevels {
+		if _, err := logger.ParseLevel(level); err != nil {
+			return fmt.Errorf("module %s: %v", module, err)
+		}
+	}
 	return nil
 }
 
@@ -233,15 +739,13 @@ type Topology struct {
 func LoadTopology(path string) (*Topology, error) {
 	topologyBytes, err := ioutil.ReadFile(path)
 	if err != nil {
-		glog.Fatalf("Error when reading the topology file: %v\n", err)
-		return nil, err
+		return nil, fmt.Errorf("error when reading the topology file: %v", err)
 	}
 
 	topology := Topology{}
 	err = yaml.Unmarshal(topologyBytes, &topology)
 	if err != nil {
-		glog.Fatalf("Error when decoding the topology file: %v\n", err)
-		return nil, err
+		return nil, fmt.Errorf("error when decoding the topology file: %v", err)
 	}
 
 	return &topology, nil
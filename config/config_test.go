@@ -14,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/keepalive"
 	"gopkg.in/yaml.v2"
@@ -53,6 +54,13 @@ func TestValidateConfig(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "invalid miner's address", err.Error())
 
+	cfg = LoadTestConfig()
+	cfg.Chain.MinerAddr = "io1qyqsyqcy6nm58gjd2wr035wz5eyd5uq47zyqpng3gxe7nh"
+	cfg.Chain.IsTestnet = true
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "miner's address is not of the network of the chain", err.Error())
+
 	cfg = LoadTestConfig()
 	cfg.Chain.MinerAddr = ""
 	cfg.NodeType = "invalid_type"
@@ -60,12 +68,161 @@ func TestValidateConfig(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "unknown node type invalid_type", err.Error())
 
+	cfg = LoadTestConfig()
+	cfg.Consensus.DPoS.RewardAddr = "invalid_address"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid reward address", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Consensus.DPoS.RewardAddr = "io1qyqsyqcy6nm58gjd2wr035wz5eyd5uq47zyqpng3gxe7nh"
+	cfg.Chain.IsTestnet = true
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "reward address is not of the network of the chain", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.RewardDecayPercent = 100
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "reward decay percent should be less than 100", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.DelegateRewardPercent = 101
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "delegate reward percent should not exceed 100", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.Checkpoints = []Checkpoint{{Height: 10, Hash: "abcd"}}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid hash abcd of checkpoint 10", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.SignalWindow = 10
+	cfg.Chain.SignalThreshold = 11
+	cfg.Chain.Deployments = []Deployment{{Name: "a", Bit: 1}}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "signal threshold should be between 1 and the signal window", err.Error())
+
+	cfg.Chain.SignalThreshold = 9
+	cfg.Chain.Deployments = []Deployment{{Name: "a", Bit: 29}}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "bit 29 of deployment a should be at most 28", err.Error())
+
+	cfg.Chain.Deployments = []Deployment{{Name: "a", Bit: 1}, {Name: "b", Bit: 1}}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "deployment b shares its name or bit with another one", err.Error())
+
+	cfg.Chain.Deployments = []Deployment{{Name: "a", Bit: 1}}
+	cfg.Chain.SignalDeployments = []string{"b"}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "unknown deployment b to signal", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.Pruning = true
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "prune retention should be positive in pruning mode", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.StorageOffload = "COMPRESS"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "unknown storage offload COMPRESS", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.StorageBudget = 1 << 30
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "storage retention should be positive with a storage budget", err.Error())
+
+	cfg.Chain.StorageRetention = 1000
+	cfg.Chain.Pruning = true
+	cfg.Chain.PruneRetention = 100
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "block bodies cannot be archived in pruning mode, which deletes them", err.Error())
+	cfg.Chain.StorageOffload = "PRUNE"
+	assert.Nil(t, validateConfig(cfg))
+
+	cfg = LoadTestConfig()
+	cfg.Chain.CoinSelection = "SMALLEST_FIRST"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "unknown coin selection SMALLEST_FIRST", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.CoinbasePolicy = "FREEFORM"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "unknown coinbase policy FREEFORM", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.MaxCoinbaseDataSize = 4
+	cfg.Chain.CoinbaseMemo = "pool memo"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "coinbase memo should not exceed max coinbase data size", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.TxOrder = "FIFO"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "unknown tx order FIFO", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.SubChains = []Chain{{ChainDBPath: "./subchain.db"}}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "subchain 0 should have a genesis file", err.Error())
+
+	cfg.SubChains = []Chain{{ChainDBPath: cfg.Chain.ChainDBPath, GenesisPath: "./genesis.json"}}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "chain DB path "+cfg.Chain.ChainDBPath+" is shared by several chains", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Network.MaxMsgSize = 1024
+	cfg.Chain.MaxBlockSize = 2048
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "max block size should not exceed max message size", err.Error())
+
 	cfg = LoadTestConfig()
 	cfg.Network.PeerDiscovery = false
 	err = validateConfig(cfg)
 	assert.NotNil(t, err)
 	assert.Equal(t, "either peer discover should be enabled or a topology should be given", err.Error())
 
+	cfg = LoadTestConfig()
+	cfg.API.RateLimitEnabled = true
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "API rate limit window should be at least a second", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Admin.Addr = "127.0.0.1:0"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "admin token should be given when the admin service is enabled", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Faucet.Addr = "127.0.0.1:0"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "faucet should only be enabled on a testnet", err.Error())
+
+	cfg.Chain.IsTestnet = true
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "faucet account and amount should be given when the faucet is enabled", err.Error())
+
 	cfg = LoadTestConfig()
 	cfg.NodeType = FullNodeType
 	cfg.Consensus.Scheme = "RDPOS"
@@ -106,12 +263,28 @@ func LoadTestConfig() *Config {
 			MaxMsgSize:              1024 * 1024 * 10,
 			PeerDiscovery:           true,
 			TopologyPath:            "",
+			DNSSeeds:                []string{},
 		},
 		Chain: Chain{
-			ChainDBPath: "./a/fake/path",
+			ChainDBPath:       "./a/fake/path",
+			Checkpoints:       []Checkpoint{},
+			Deployments:       []Deployment{},
+			SignalDeployments: []string{},
 		},
+		SubChains: []Chain{},
 		Consensus: Consensus{
 			Scheme: "NOOP",
+			DPoS: DPoS{
+				Delegates: []string{},
+			},
+		},
+		API: API{
+			CORSOrigins:      []string{},
+			MethodRateLimits: map[string]uint64{},
+		},
+		Log: Log{
+			Level:        "info",
+			ModuleLevels: map[string]string{},
 		},
 		Delegate: Delegate{
 			Addrs: []string{"127.0.0.1:10001"},
@@ -119,6 +292,17 @@ func LoadTestConfig() *Config {
 	}
 }
 
+func TestRewardAddr(t *testing.T) {
+	cfg := LoadTestConfig()
+	cfg.Chain.MinerAddr = "miner"
+	cfg.Consensus.DPoS.RewardAddr = "reward"
+	assert.Equal(t, "miner", cfg.RewardAddr())
+	cfg.Consensus.Scheme = "DPOS"
+	assert.Equal(t, "reward", cfg.RewardAddr())
+	cfg.Consensus.DPoS.RewardAddr = ""
+	assert.Equal(t, "miner", cfg.RewardAddr())
+}
+
 func TestLoadTestTopology(t *testing.T) {
 	topology1 := LoadTestTopology()
 	topologyStr, err := yaml.Marshal(topology1)
@@ -148,3 +332,90 @@ func LoadTestTopology() *Topology {
 		},
 	}
 }
+
+func TestLoadGenesis(t *testing.T) {
+	genesis, err := LoadGenesis("../genesis.json")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), genesis.ChainID)
+	assert.Equal(t, 3, len(genesis.Allocations))
+	assert.Equal(t, uint64(10000000000), genesis.TotalSupply())
+
+	reward, ok := genesis.BlockRewardAt(0)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), reward)
+	reward, ok = genesis.BlockRewardAt(2000000)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), reward)
+
+	genesis.BlockRewards = []BlockReward{{Height: 10, Reward: 1}}
+	_, ok = genesis.BlockRewardAt(9)
+	assert.False(t, ok)
+
+	genesis.BlockRewards = append(genesis.BlockRewards, BlockReward{Height: 10, Reward: 2})
+	assert.NotNil(t, validateGenesis(genesis))
+
+	genesis.BlockRewards = nil
+	genesis.Allocations[0].Address = "Alice"
+	err = validateGenesis(genesis)
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid genesis allocation address Alice", err.Error())
+
+	genesis.Allocations = nil
+	assert.NotNil(t, validateGenesis(genesis))
+
+	_, err = LoadGenesis("/a/fake/path")
+	assert.NotNil(t, err)
+}
+
+func TestCheckReloadable(t *testing.T) {
+	cur := LoadTestConfig()
+
+	next := *cur
+	next.TxPool.MinTxFeePerByte++
+	next.TxPool.MaxPoolSize = 1 << 20
+	next.API.RateLimitEnabled = true
+	next.API.MethodRateLimits = map[string]uint64{"SendRawTransaction": 1}
+	next.Log.Level = "debug"
+	assert.Nil(t, CheckReloadable(cur, &next))
+
+	next = *cur
+	next.TxPool.PersistPath = "/tmp/txpool"
+	err := CheckReloadable(cur, &next)
+	assert.Equal(t, ErrNotReloadable, errors.Cause(err))
+	assert.Contains(t, err.Error(), "TxPool")
+
+	next = *cur
+	next.Chain.BlockReward++
+	next.API.Addr = ":14014"
+	err = CheckReloadable(cur, &next)
+	assert.Equal(t, ErrNotReloadable, errors.Cause(err))
+	assert.Contains(t, err.Error(), "consensus-critical sections [Chain]")
+}
+
+func TestApplyPreset(t *testing.T) {
+	cfg := LoadTestConfig()
+	cfg.Net = Testnet
+	cfg.Network.Addr = "0.0.0.0"
+	cfg.API.Addr = "127.0.0.1:4000"
+	cfg.API.JSONRPCAddr = "127.0.0.1"
+	cfg.TxPool.PersistPath = "/var/lib/iotex/txpool"
+	assert.Nil(t, applyPreset(cfg))
+	assert.True(t, cfg.Chain.IsTestnet)
+	assert.True(t, cfg.Wallet.IsTestnet)
+	assert.Equal(t, uint32(2), cfg.Wallet.ChainID)
+	assert.Equal(t, "data/testnet/a/fake/path", cfg.Chain.ChainDBPath)
+	assert.Equal(t, "data/testnet/blocks", cfg.Chain.BlockArchiveDir)
+	assert.Equal(t, "/var/lib/iotex/txpool", cfg.TxPool.PersistPath)
+	assert.Equal(t, "", cfg.Network.PeerStorePath)
+	assert.Equal(t, "0.0.0.0:4690", cfg.Network.Addr)
+	assert.Equal(t, "127.0.0.1:4000", cfg.API.Addr)
+	assert.Equal(t, "127.0.0.1:15015", cfg.API.JSONRPCAddr)
+	assert.Nil(t, validateConfig(cfg))
+
+	cfg = LoadTestConfig()
+	cfg.Net = "moonnet"
+	assert.NotNil(t, applyPreset(cfg))
+	cfg.Net = Mainnet
+	cfg.Chain.GenesisPath = "genesis.json"
+	assert.NotNil(t, applyPreset(cfg))
+}
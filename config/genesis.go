@@ -0,0 +1,109 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/iotexproject/iotex-core/iotxaddress"
+)
+
+// Genesis is the genesis configuration used to bootstrap a chain
+type Genesis struct {
+	ChainID uint32 `json:"chainID"`
+	// Timestamp is the timestamp of the genesis block in unix seconds
+	Timestamp    uint64 `json:"timestamp"`
+	CoinbaseData string `json:"coinbaseData"`
+	// Allocations are the initial balances minted in the genesis block
+	Allocations []Allocation `json:"allocations"`
+	// Accounts are the initial balances of the account-based state credited by the genesis block, which are not part
+	// of the UTXO supply
+	Accounts []Allocation `json:"accounts"`
+	// BlockRewards is the block reward schedule, sorted by the height each reward starts to apply
+	BlockRewards []BlockReward `json:"blockRewards"`
+}
+
+// Allocation is an initial balance of an address
+type Allocation struct {
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+}
+
+// BlockReward is the reward paid to the miner of each block starting from the given height
+type BlockReward struct {
+	Height uint32 `json:"height"`
+	Reward uint64 `json:"reward"`
+}
+
+// TotalSupply returns the sum of all initial allocations
+func (g *Genesis) TotalSupply() uint64 {
+	total := uint64(0)
+	for _, alloc := range g.Allocations {
+		total += alloc.Amount
+	}
+	return total
+}
+
+// BlockRewardAt returns the block reward at the given height, false if the schedule does not cover the height
+func (g *Genesis) BlockRewardAt(height uint32) (uint64, bool) {
+	reward, found := uint64(0), false
+	for _, r := range g.BlockRewards {
+		if r.Height > height {
+			break
+		}
+		reward, found = r.Reward, true
+	}
+	return reward, found
+}
+
+// LoadGenesis loads the genesis configuration from the given json file and validates it
+func LoadGenesis(path string) (*Genesis, error) {
+	genesisBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	genesis := Genesis{}
+	if err := json.Unmarshal(genesisBytes, &genesis); err != nil {
+		return nil, err
+	}
+	if err := validateGenesis(&genesis); err != nil {
+		return nil, err
+	}
+	return &genesis, nil
+}
+
+// validateGenesis validates the given genesis configuration
+func validateGenesis(g *Genesis) error {
+	if len(g.Allocations) == 0 {
+		return fmt.Errorf("genesis has no allocation")
+	}
+	for _, alloc := range g.Allocations {
+		if !iotxaddress.ValidateAddress(alloc.Address) {
+			return fmt.Errorf("invalid genesis allocation address %s", alloc.Address)
+		}
+		if alloc.Amount == 0 {
+			return fmt.Errorf("genesis allocation to %s has zero amount", alloc.Address)
+		}
+	}
+	for _, acct := range g.Accounts {
+		if !iotxaddress.ValidateAddress(acct.Address) {
+			return fmt.Errorf("invalid genesis account address %s", acct.Address)
+		}
+		if acct.Amount == 0 {
+			return fmt.Errorf("genesis account %s has zero balance", acct.Address)
+		}
+	}
+	for i := 1; i < len(g.BlockRewards); i++ {
+		if g.BlockRewards[i].Height <= g.BlockRewards[i-1].Height {
+			return fmt.Errorf("block reward schedule is not sorted by height")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+)
+
+// The networks a node can join by name, see Config.Net
+const (
+	Mainnet = "mainnet"
+	Testnet = "testnet"
+	Devnet  = "devnet"
+)
+
+// Preset is the chain parameters of a network, along with the defaults keeping the nodes of different networks on
+// a host apart
+type Preset struct {
+	// Genesis is the genesis of the chain, whose chain ID the chain and the wallet use
+	Genesis *Genesis
+	// GenesisHash is the hex encoded hash of the genesis block, which the chain DB of the network has to store
+	GenesisHash string
+	// IsTestnet is the prefix of the addresses of the network
+	IsTestnet bool
+	// DataDir is the directory the relative paths of the chain DBs, block archives, txpool, peer store, sign record
+	// and keystore are placed under
+	DataDir string
+	// P2PPort, APIPort and JSONRPCPort are the ports of the addresses of the config without one
+	P2PPort     int
+	APIPort     int
+	JSONRPCPort int
+}
+
+// Presets are the presets of the networks by name
+var Presets = map[string]Preset{
+	Mainnet: {
+		Genesis: &Genesis{
+			ChainID:      1,
+			CoinbaseData: "The Times 03/Jan/2009 Chancellor on brink of second bailout for banks",
+			Allocations: []Allocation{
+				{Address: "io1qyqsyqcy6nm58gjd2wr035wz5eyd5uq47zyqpng3gxe7nh", Amount: 9000000000},
+				{Address: "io1qyqsyqcy497w3em2m6dnrqwxxse2vges5h9g82umw6axg6", Amount: 500000000},
+				{Address: "io1qyqsyqcy4uesjly064cuds00c54s4qp9zn4qlwcrlkuwsg", Amount: 500000000},
+			},
+			BlockRewards: []BlockReward{{Height: 0, Reward: 5}, {Height: 1000000, Reward: 3}},
+		},
+		GenesisHash: "6e5af8b31a03a47321f1e7864f6f4a495f507f912b3bffe0a3fd3c1eff799d0a",
+		DataDir:     "data/mainnet",
+		P2PPort:     4689,
+		APIPort:     14014,
+		JSONRPCPort: 15014,
+	},
+	Testnet: {
+		Genesis: &Genesis{
+			ChainID:      2,
+			CoinbaseData: "IoTeX testnet",
+			Allocations: []Allocation{
+				{Address: "it1qyqsyqcy6nm58gjd2wr035wz5eyd5uq47zyqpng3l2ylta", Amount: 9000000000},
+				{Address: "it1qyqsyqcy497w3em2m6dnrqwxxse2vges5h9g82umekq8ss", Amount: 500000000},
+				{Address: "it1qyqsyqcy4uesjly064cuds00c54s4qp9zn4qlwcrg6p0gz", Amount: 500000000},
+			},
+			BlockRewards: []BlockReward{{Height: 0, Reward: 5}},
+		},
+		GenesisHash: "71c9d3738e6702808a34bab1d67fea55e85cf0d06ca4fb77b28fc70c55e8cde3",
+		IsTestnet:   true,
+		DataDir:     "data/testnet",
+		P2PPort:     4690,
+		APIPort:     14015,
+		JSONRPCPort: 15015,
+	},
+	Devnet: {
+		Genesis: &Genesis{
+			ChainID:      3,
+			CoinbaseData: "IoTeX devnet",
+			Allocations: []Allocation{
+				{Address: "it1qyqsyqcy6nm58gjd2wr035wz5eyd5uq47zyqpng3l2ylta", Amount: 10000000000},
+			},
+			BlockRewards: []BlockReward{{Height: 0, Reward: 5}},
+		},
+		GenesisHash: "c80dc3db42cebdfaf526dba711f796ad4a77e851cdd985607ab3282652f16cde",
+		IsTestnet:   true,
+		DataDir:     "data/devnet",
+		P2PPort:     4691,
+		APIPort:     14016,
+		JSONRPCPort: 15016,
+	},
+}
+
+// applyPreset applies the preset of the network of the config, which has to be known, overriding the chain ID, the
+// address prefix and the genesis of the main chain, placing the relative paths under the data directory of the network
+// and adding the default ports to the addresses without one
+func applyPreset(cfg *Config) error {
+	preset, ok := Presets[cfg.Net]
+	if !ok {
+		return fmt.Errorf("unknown network %s", cfg.Net)
+	}
+	if cfg.Chain.GenesisPath != "" {
+		return fmt.Errorf("genesis path should not be given along with network %s", cfg.Net)
+	}
+	cfg.Chain.IsTestnet = preset.IsTestnet
+	cfg.Wallet.IsTestnet = preset.IsTestnet
+	cfg.Wallet.ChainID = preset.Genesis.ChainID
+
+	inDataDir := func(path *string) {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(preset.DataDir, *path)
+		}
+	}
+	chains := []*Chain{&cfg.Chain}
+	for i := range cfg.SubChains {
+		chains = append(chains, &cfg.SubChains[i])
+	}
+	for _, c := range chains {
+		inDataDir(&c.ChainDBPath)
+		if c.BlockArchiveDir == "" {
+			c.BlockArchiveDir = "blocks"
+		}
+		inDataDir(&c.BlockArchiveDir)
+	}
+	inDataDir(&cfg.TxPool.PersistPath)
+	inDataDir(&cfg.Network.PeerStorePath)
+	inDataDir(&cfg.Consensus.DPoS.SignRecordPath)
+	inDataDir(&cfg.Wallet.KeystorePath)
+
+	withPort := func(addr *string, port int) {
+		if *addr == "" {
+			return
+		}
+		if _, _, err := net.SplitHostPort(*addr); err != nil {
+			*addr = net.JoinHostPort(*addr, strconv.Itoa(port))
+		}
+	}
+	withPort(&cfg.Network.Addr, preset.P2PPort)
+	withPort(&cfg.API.Addr, preset.APIPort)
+	withPort(&cfg.API.JSONRPCAddr, preset.JSONRPCPort)
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package config
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotReloadable indicates the config changes fields which cannot be applied while the node is running
+var ErrNotReloadable = errors.New("config cannot be reloaded at runtime")
+
+// consensusSections are the sections of the config which the blocks produced or accepted by the node depend on
+var consensusSections = map[string]bool{"Chain": true, "SubChains": true, "Consensus": true}
+
+// CheckReloadable returns ErrNotReloadable, naming the changed sections, if the next config changes fields of the
+// current one other than the hot reloadable ones, which are:
+// - TxPool: MinTxFeePerByte, MinReplacementFeePerByte, TxTTL and MaxPoolSize
+// - API: RateLimitEnabled, RateLimitPerSec, MethodRateLimits and RateLimitWindowSize
+// - Log: all fields
+func CheckReloadable(cur *Config, next *Config) error {
+	masked := *next
+	masked.TxPool.MinTxFeePerByte = cur.TxPool.MinTxFeePerByte
+	masked.TxPool.MinReplacementFeePerByte = cur.TxPool.MinReplacementFeePerByte
+	masked.TxPool.TxTTL = cur.TxPool.TxTTL
+	masked.TxPool.MaxPoolSize = cur.TxPool.MaxPoolSize
+	masked.API.RateLimitEnabled = cur.API.RateLimitEnabled
+	masked.API.RateLimitPerSec = cur.API.RateLimitPerSec
+	masked.API.MethodRateLimits = cur.API.MethodRateLimits
+	masked.API.RateLimitWindowSize = cur.API.RateLimitWindowSize
+	masked.Log = cur.Log
+
+	var consensus, others []string
+	curValue, nextValue := reflect.ValueOf(*cur), reflect.ValueOf(masked)
+	for i := 0; i < curValue.NumField(); i++ {
+		if reflect.DeepEqual(curValue.Field(i).Interface(), nextValue.Field(i).Interface()) {
+			continue
+		}
+		name := curValue.Type().Field(i).Name
+		if consensusSections[name] {
+			consensus = append(consensus, name)
+		} else {
+			others = append(others, name)
+		}
+	}
+	if len(consensus) > 0 {
+		return errors.Wrapf(ErrNotReloadable, "consensus-critical sections %v changed", consensus)
+	}
+	if len(others) > 0 {
+		return errors.Wrapf(ErrNotReloadable, "sections %v changed, only the TxPool limits, the API rate limits "+
+			"and the log levels can be reloaded", others)
+	}
+	return nil
+}
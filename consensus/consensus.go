@@ -7,42 +7,63 @@
 package consensus
 
 import (
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/blocksync"
 	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/consensus/dpos"
 	"github.com/iotexproject/iotex-core/consensus/scheme"
 	"github.com/iotexproject/iotex-core/consensus/scheme/rdpos"
 	"github.com/iotexproject/iotex-core/delegate"
+	"github.com/iotexproject/iotex-core/logger"
+	pb "github.com/iotexproject/iotex-core/proto"
 	"github.com/iotexproject/iotex-core/txpool"
 )
 
+var log = logger.New("consensus")
+
 // Consensus is the interface for handling consensus view change.
 type Consensus interface {
 	Start() error
 	Stop() error
 	HandleViewChange(proto.Message, chan bool) error
 	HandleBlockPropose(proto.Message, chan bool) error
+	HandleCheckpointVote(proto.Message, chan bool) error
 }
 
 type consensus struct {
 	cfg    *config.Consensus
 	scheme scheme.Scheme
+	// finality collects the checkpoint votes of the delegates, nil if the finality gadget is disabled
+	finality *dpos.Finality
 }
 
 // NewConsensus creates a consensus struct.
-func NewConsensus(cfg *config.Config, bc blockchain.IBlockchain, tp txpool.TxPool, bs blocksync.BlockSync, dlg delegate.Pool) Consensus {
+func NewConsensus(cfg *config.Config, bc blockchain.IBlockchain, tp txpool.TxPool, bs blocksync.BlockSync, dlg delegate.Pool) (Consensus, error) {
 	if bc == nil || bs == nil {
-		glog.Fatal("Try to attach to chain or bs == nil")
-		return nil
+		return nil, errors.New("Try to attach to chain or bs == nil")
 	}
 
 	cs := &consensus{cfg: &cfg.Consensus}
 	mintBlockCB := func() (*blockchain.Block, error) {
-		blk := bc.MintNewBlock(tp.Txs(), cfg.Chain.MinerAddr, "")
-		glog.Infof("created a new block at height %v with %v txs", blk.Height(), len(blk.Tranxs))
+		blk, err := bc.MintNewBlockWithActions(tp.Txs(), tp.Actions(), cfg.RewardAddr(), "")
+		if err != nil {
+			log.Errorf("failed to create a new block: %v", err)
+			return nil, err
+		}
+		log.Infof("created a new block at height %v with %v txs and %v actions", blk.Height(), len(blk.Tranxs), len(blk.Actions()))
+		return blk, nil
+	}
+
+	emptyBlockCB := func() (*blockchain.Block, error) {
+		blk, err := bc.MintNewBlock(nil, cfg.RewardAddr(), "")
+		if err != nil {
+			log.Errorf("failed to create a new empty block: %v", err)
+			return nil, err
+		}
+		log.Infof("created a new empty block at height %v", blk.Height())
 		return blk, nil
 	}
 
@@ -55,6 +76,12 @@ func NewConsensus(cfg *config.Config, bc blockchain.IBlockchain, tp txpool.TxPoo
 	}
 
 	broadcastBlockCB := func(blk *blockchain.Block) error {
+		if cfg.BlockSync.CompactBlocks {
+			// peers ask back for the transactions missing from their tx pools
+			cbPb := blockchain.NewCompactBlock(blk).ConvertToCompactBlockPb()
+			cbPb.SenderAddr = bs.P2P().Self().String()
+			return bs.P2P().Broadcast(cbPb)
+		}
 		if blkPb := blk.ConvertToBlockPb(); blkPb != nil {
 			return bs.P2P().Broadcast(blkPb)
 		}
@@ -63,29 +90,57 @@ func NewConsensus(cfg *config.Config, bc blockchain.IBlockchain, tp txpool.TxPoo
 
 	switch cfg.Consensus.Scheme {
 	case "RDPOS":
-		cs.scheme = rdpos.NewRDPoS(cfg.Consensus.RDPoS, mintBlockCB, tellBlockCB, commitBlockCB, broadcastBlockCB, bc, bs.P2P().Self(), dlg)
+		sc, err := rdpos.NewRDPoS(cfg.Consensus.RDPoS, mintBlockCB, tellBlockCB, commitBlockCB, broadcastBlockCB, bc, bs.P2P().Self(), dlg)
+		if err != nil {
+			return nil, err
+		}
+		cs.scheme = sc
 	case "NOOP":
 		cs.scheme = scheme.NewNoop()
 	case "STANDALONE":
 		cs.scheme = scheme.NewStandalone(mintBlockCB, commitBlockCB, broadcastBlockCB, bc, cfg.Consensus.BlockCreationInterval)
+	case "DPOS":
+		// the schedule is read from an engine of the same config as the one of the chain signing the blocks
+		engine, err := dpos.NewDPoS(cfg.Consensus.DPoS)
+		if err != nil {
+			return nil, err
+		}
+		engine.SetElection(bc)
+		cs.scheme = scheme.NewProducer(cfg.Consensus.DPoS, engine, mintBlockCB, emptyBlockCB, commitBlockCB, broadcastBlockCB, bc)
 	default:
-		glog.Fatal("unexpected consensus scheme", cfg.Consensus.Scheme)
+		return nil, errors.Errorf("unexpected consensus scheme %s", cfg.Consensus.Scheme)
 	}
 
-	return cs
+	// every node collects the checkpoint votes to finalize the blocks, while only the delegates sign them
+	if cfg.Consensus.DPoS.Enabled && cfg.Consensus.DPoS.CheckpointInterval > 0 {
+		engine, err := dpos.NewDPoS(cfg.Consensus.DPoS)
+		if err != nil {
+			return nil, err
+		}
+		engine.SetElection(bc)
+		cs.finality = dpos.NewFinality(engine, bc, tellBlockCB, cfg.Consensus.DPoS.CheckpointInterval)
+	}
+
+	return cs, nil
 }
 
 func (c *consensus) Start() error {
-	glog.Infof("Starting consensus scheme %v", c.cfg.Scheme)
+	log.Infof("Starting consensus scheme %v", c.cfg.Scheme)
 
 	c.scheme.Start()
+	if c.finality != nil {
+		return c.finality.Start()
+	}
 	return nil
 }
 
 func (c *consensus) Stop() error {
-	glog.Infof("Stopping consensus scheme %v", c.cfg.Scheme)
+	log.Infof("Stopping consensus scheme %v", c.cfg.Scheme)
 
 	c.scheme.Stop()
+	if c.finality != nil {
+		return c.finality.Stop()
+	}
 	return nil
 }
 
@@ -98,3 +153,15 @@ func (c *consensus) HandleViewChange(m proto.Message, done chan bool) error {
 func (c *consensus) HandleBlockPropose(m proto.Message, done chan bool) error {
 	return nil
 }
+
+// HandleCheckpointVote collects the checkpoint vote of a delegate if the finality gadget is enabled
+func (c *consensus) HandleCheckpointVote(m proto.Message, done chan bool) error {
+	if c.finality == nil {
+		return nil
+	}
+	vote, ok := m.(*pb.CheckpointVotePb)
+	if !ok {
+		return errors.Errorf("unexpected checkpoint vote %T", m)
+	}
+	return c.finality.HandleVote(vote)
+}
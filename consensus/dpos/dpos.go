@@ -0,0 +1,227 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dpos
+
+import (
+	"bytes"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/election"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/state"
+)
+
+var (
+	// ErrInvalidConfig indicates the DPoS config is not valid
+	ErrInvalidConfig = errors.New("invalid DPoS config")
+	// ErrInvalidTimestamp indicates the block timestamp does not fall into a valid time slot
+	ErrInvalidTimestamp = errors.New("invalid block timestamp")
+	// ErrInvalidSignature indicates the block is not signed, or the signature does not match its proposer
+	ErrInvalidSignature = errors.New("invalid proposer signature")
+	// ErrWrongProposer indicates the block is not proposed by the delegate of its time slot
+	ErrWrongProposer = errors.New("wrong block proposer")
+	// ErrDoubleSign indicates the producer has signed another block at the same height or in the same slot
+	ErrDoubleSign = errors.New("conflicting block already signed")
+)
+
+// Election provides the delegates elected by the votes on the chain
+type Election interface {
+	// EpochOf returns the epoch of the block at the height
+	EpochOf(height uint32) uint32
+	// GetDelegatesByEpoch returns the delegates elected for the epoch, or nil if no delegate has been elected for it
+	GetDelegatesByEpoch(epoch uint32) ([]*election.Candidate, error)
+	// AccountState returns the state of the address, which holds the operator registered by a delegate
+	AccountState(address string) *state.Account
+}
+
+// DPoS is the delegated proof of stake consensus engine
+//
+// Time is divided into slots of BlockInterval, and the delegates take turns to produce one block in each slot in
+// the order they are listed, i.e., the delegate of the slot is delegates[(timestamp / interval) % len(delegates)].
+// A block is accepted only if it is signed by the delegate of its slot, and its slot is later than its parent's.
+// The delegates of a block are the ones elected for its epoch, or the configured ones if there is no election or no
+// delegate has been elected for the epoch.
+// A delegate which has registered an operator on the chain signs with the key of either its own address or the
+// operator, so the producer keys configured on the node can be the operator's while the owner's are kept offline.
+type DPoS struct {
+	delegates []string // addresses of the configured delegates
+	interval  uint64   // length of a time slot in seconds
+	pubkey    []byte
+	privkey   []byte
+	election  Election
+	now       func() time.Time
+	// signed is the last block signed by the producer
+	signed *signRecord
+}
+
+// NewDPoS creates a DPoS consensus engine
+func NewDPoS(cfg config.DPoS) (*DPoS, error) {
+	if len(cfg.Delegates) == 0 {
+		return nil, errors.Wrap(ErrInvalidConfig, "no delegate")
+	}
+	d := &DPoS{interval: uint64(cfg.BlockInterval / time.Second), now: time.Now}
+	if d.interval == 0 {
+		return nil, errors.Wrapf(ErrInvalidConfig, "block interval %v is shorter than 1s", cfg.BlockInterval)
+	}
+	for _, addr := range cfg.Delegates {
+		if !iotxaddress.ValidateAddress(addr) {
+			return nil, errors.Wrapf(ErrInvalidConfig, "invalid delegate address %s", addr)
+		}
+		d.delegates = append(d.delegates, addr)
+	}
+
+	// the producer keys are only needed by a delegate to sign the blocks it mints
+	var err error
+	if d.pubkey, err = hex.DecodeString(cfg.ProducerPubKey); err != nil {
+		return nil, errors.Wrapf(ErrInvalidConfig, "producer public key: %v", err)
+	}
+	if d.privkey, err = hex.DecodeString(cfg.ProducerPrivKey); err != nil {
+		return nil, errors.Wrapf(ErrInvalidConfig, "producer private key: %v", err)
+	}
+	if d.signed, err = loadSignRecord(cfg.SignRecordPath); err != nil {
+		return nil, errors.Wrapf(ErrInvalidConfig, "%v", err)
+	}
+	return d, nil
+}
+
+// SetElection sets the election the delegates of each epoch are taken from
+func (d *DPoS) SetElection(e Election) {
+	d.election = e
+}
+
+// slot returns the time slot the timestamp falls into
+func (d *DPoS) slot(timestamp uint64) uint64 {
+	return timestamp / d.interval
+}
+
+// delegatesAt returns the addresses of the delegates of the block at the height
+func (d *DPoS) delegatesAt(height uint32) ([]string, error) {
+	if d.election == nil {
+		return d.delegates, nil
+	}
+	epoch := d.election.EpochOf(height)
+	elected, err := d.election.GetDelegatesByEpoch(epoch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the delegates of epoch %d", epoch)
+	}
+	if len(elected) == 0 {
+		return d.delegates, nil
+	}
+	delegates := make([]string, 0, len(elected))
+	for _, c := range elected {
+		delegates = append(delegates, c.Address)
+	}
+	return delegates, nil
+}
+
+// proposer returns the address of the delegate scheduled for the time slot of the block
+func (d *DPoS) proposer(blk *blockchain.Block) (string, error) {
+	delegates, err := d.delegatesAt(blk.Height())
+	if err != nil {
+		return "", err
+	}
+	return delegates[d.slot(blk.Timestamp())%uint64(len(delegates))], nil
+}
+
+// signsFor returns true if the key of the public key hash signs for the delegate, i.e., it is the key of the delegate
+// or of the operator the delegate has registered
+func (d *DPoS) signsFor(delegate string, pkHash []byte) bool {
+	if bytes.Equal(iotxaddress.GetPubkeyHash(delegate), pkHash) {
+		return true
+	}
+	if d.election == nil {
+		return false
+	}
+	operator := d.election.AccountState(delegate).Operator
+	return operator != "" && bytes.Equal(iotxaddress.GetPubkeyHash(operator), pkHash)
+}
+
+// delegateOf returns the delegate the key of the public key hash signs for, or "" if it signs for none of them
+func (d *DPoS) delegateOf(delegates []string, pkHash []byte) string {
+	for _, delegate := range delegates {
+		if d.signsFor(delegate, pkHash) {
+			return delegate
+		}
+	}
+	return ""
+}
+
+// NextSlot returns the start and the end of the earliest slot not over at 'now' and later than the slot of the parent,
+// which has the timestamp, in which the producer is the delegate of the block at the height
+func (d *DPoS) NextSlot(height uint32, parentTimestamp uint64, now time.Time) (time.Time, time.Time, error) {
+	if len(d.privkey) == 0 {
+		return time.Time{}, time.Time{}, errors.Wrap(ErrInvalidSignature, "producer keys are not configured")
+	}
+	delegates, err := d.delegatesAt(height)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	producer := iotxaddress.HashPubKey(d.pubkey)
+	slot := d.slot(uint64(now.Unix()))
+	if parent := d.slot(parentTimestamp); slot <= parent {
+		slot = parent + 1
+	}
+	for i := 0; i < len(delegates); i, slot = i+1, slot+1 {
+		if d.signsFor(delegates[slot%uint64(len(delegates))], producer) {
+			start := time.Unix(int64(slot*d.interval), 0)
+			return start, start.Add(time.Duration(d.interval) * time.Second), nil
+		}
+	}
+	return time.Time{}, time.Time{}, errors.Wrapf(ErrWrongProposer, "producer is not a delegate of block %d", height)
+}
+
+// ValidateHeader checks the block falls into a time slot later than its parent and not in the future
+func (d *DPoS) ValidateHeader(blk *blockchain.Block, parent *blockchain.Block) error {
+	// allow the proposer's clock to be ahead by less than one slot
+	if now := uint64(d.now().Unix()); blk.Timestamp() >= now+d.interval {
+		return errors.Wrapf(ErrInvalidTimestamp, "block %d is at %d, too far ahead of %d", blk.Height(), blk.Timestamp(), now)
+	}
+	if parent != nil && d.slot(blk.Timestamp()) <= d.slot(parent.Timestamp()) {
+		return errors.Wrapf(ErrInvalidTimestamp, "block %d is at slot %d, parent is at slot %d",
+			blk.Height(), d.slot(blk.Timestamp()), d.slot(parent.Timestamp()))
+	}
+	return nil
+}
+
+// VerifyProposer checks the block is signed by the delegate of its time slot, or by the operator it has registered
+func (d *DPoS) VerifyProposer(blk *blockchain.Block) error {
+	if !blk.VerifySignature() {
+		return errors.Wrapf(ErrInvalidSignature, "block %d", blk.Height())
+	}
+	proposer, err := d.proposer(blk)
+	if err != nil {
+		return err
+	}
+	if !d.signsFor(proposer, iotxaddress.HashPubKey(blk.ProposerPubKey())) {
+		return errors.Wrapf(ErrWrongProposer, "block %d at slot %d", blk.Height(), d.slot(blk.Timestamp()))
+	}
+	return nil
+}
+
+// FinalizeBlock signs the block with the producer keys, the producer must be the delegate of the block's time slot or
+// its operator
+// The block is recorded as signed before it is released, and ErrDoubleSign is returned if the producer has signed
+// another block at its height or slot, or above them, in which case the block must be discarded.
+func (d *DPoS) FinalizeBlock(blk *blockchain.Block) error {
+	if len(d.privkey) == 0 {
+		return errors.Wrap(ErrInvalidSignature, "producer keys are not configured")
+	}
+	proposer, err := d.proposer(blk)
+	if err != nil {
+		return err
+	}
+	if !d.signsFor(proposer, iotxaddress.HashPubKey(d.pubkey)) {
+		return errors.Wrapf(ErrWrongProposer, "producer is not the delegate of slot %d", d.slot(blk.Timestamp()))
+	}
+	blk.SignBlock(d.pubkey, d.privkey)
+	return d.signed.sign(blk.Height(), d.slot(blk.Timestamp()), blk.HashBlock())
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dpos
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/election"
+	"github.com/iotexproject/iotex-core/state"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+var delegates = []string{"alfa", "bravo", "charlie"}
+
+func testDPoS(t *testing.T, producer string) *DPoS {
+	cfg := config.DPoS{Enabled: true, BlockInterval: 10 * time.Second}
+	for _, name := range delegates {
+		cfg.Delegates = append(cfg.Delegates, ta.Addrinfo[name].Address)
+	}
+	cfg.ProducerPubKey = hex.EncodeToString(ta.Addrinfo[producer].PublicKey)
+	cfg.ProducerPrivKey = hex.EncodeToString(ta.Addrinfo[producer].PrivateKey)
+	d, err := NewDPoS(cfg)
+	assert.Nil(t, err)
+	return d
+}
+
+func newBlock(height uint32) *blockchain.Block {
+	cbtx := blockchain.NewCoinbaseTx(ta.Addrinfo["miner"].Address, 5, "")
+	return blockchain.NewBlock(0, height, cp.ZeroHash32B, []*blockchain.Tx{cbtx})
+}
+
+// slotDelegate returns the name of the delegate scheduled for the block's slot
+func slotDelegate(blk *blockchain.Block) string {
+	return delegates[(blk.Timestamp()/10)%uint64(len(delegates))]
+}
+
+func TestNewDPoS(t *testing.T) {
+	_, err := NewDPoS(config.DPoS{BlockInterval: 10 * time.Second})
+	assert.Equal(t, ErrInvalidConfig, errors.Cause(err))
+	_, err = NewDPoS(config.DPoS{Delegates: []string{ta.Addrinfo["alfa"].Address}})
+	assert.Equal(t, ErrInvalidConfig, errors.Cause(err))
+	_, err = NewDPoS(config.DPoS{Delegates: []string{"Alice"}, BlockInterval: time.Second})
+	assert.Equal(t, ErrInvalidConfig, errors.Cause(err))
+	d, err := NewDPoS(config.DPoS{Delegates: []string{ta.Addrinfo["alfa"].Address}, BlockInterval: time.Second})
+	assert.Nil(t, err)
+	// a non-delegate node cannot sign blocks
+	assert.Equal(t, ErrInvalidSignature, errors.Cause(d.FinalizeBlock(newBlock(1))))
+}
+
+func TestFinalizeAndVerifyProposer(t *testing.T) {
+	blk := newBlock(1)
+	d := testDPoS(t, slotDelegate(blk))
+	assert.Equal(t, ErrInvalidSignature, errors.Cause(d.VerifyProposer(blk)))
+	assert.Nil(t, d.FinalizeBlock(blk))
+	assert.Nil(t, d.VerifyProposer(blk))
+
+	// a delegate cannot produce the block of another delegate's slot
+	for _, name := range delegates {
+		if name == slotDelegate(blk) {
+			continue
+		}
+		other := testDPoS(t, name)
+		blk := newBlock(1)
+		assert.Equal(t, ErrWrongProposer, errors.Cause(other.FinalizeBlock(blk)))
+		blk.SignBlock(ta.Addrinfo[name].PublicKey, ta.Addrinfo[name].PrivateKey)
+		assert.Equal(t, ErrWrongProposer, errors.Cause(d.VerifyProposer(blk)))
+	}
+}
+
+func TestValidateHeader(t *testing.T) {
+	d := testDPoS(t, "alfa")
+	blk := newBlock(1)
+	// parent in the previous slot
+	genesisPb := newBlock(0).ConvertToBlockPb()
+	genesisPb.Header.Timestamp = blk.Timestamp() - 10
+	genesis := &blockchain.Block{}
+	genesis.ConvertFromBlockPb(genesisPb)
+	assert.Nil(t, d.ValidateHeader(blk, genesis))
+
+	// block in the same slot as its parent
+	assert.Equal(t, ErrInvalidTimestamp, errors.Cause(d.ValidateHeader(blk, blk)))
+
+	// block from the future
+	d.now = func() time.Time { return time.Unix(int64(blk.Timestamp())-10, 0) }
+	assert.Equal(t, ErrInvalidTimestamp, errors.Cause(d.ValidateHeader(blk, genesis)))
+}
+
+type testElection map[uint32][]*election.Candidate
+
+func (e testElection) EpochOf(height uint32) uint32 { return height / 10 }
+
+func (e testElection) GetDelegatesByEpoch(epoch uint32) ([]*election.Candidate, error) {
+	return e[epoch], nil
+}
+
+func (e testElection) AccountState(address string) *state.Account { return &state.Account{} }
+
+// operatorElection is an election of the configured delegates, in which the delegates have registered the operators
+type operatorElection map[string]string
+
+func (e operatorElection) EpochOf(height uint32) uint32 { return 0 }
+
+func (e operatorElection) GetDelegatesByEpoch(epoch uint32) ([]*election.Candidate, error) {
+	return nil, nil
+}
+
+func (e operatorElection) AccountState(address string) *state.Account {
+	return &state.Account{Votee: address, Operator: e[address]}
+}
+
+func TestElectedDelegates(t *testing.T) {
+	// delta, who is not a configured delegate, is the only delegate elected for epoch 1
+	d := testDPoS(t, "delta")
+	d.SetElection(testElection{1: {{Address: ta.Addrinfo["delta"].Address, Votes: 1}}})
+	blk := newBlock(10)
+	assert.Nil(t, d.FinalizeBlock(blk))
+	assert.Nil(t, d.VerifyProposer(blk))
+
+	// the configured delegates take turns in the epochs without an elected delegate
+	blk = newBlock(1)
+	assert.Equal(t, ErrWrongProposer, errors.Cause(d.FinalizeBlock(blk)))
+	blk.SignBlock(ta.Addrinfo[slotDelegate(blk)].PublicKey, ta.Addrinfo[slotDelegate(blk)].PrivateKey)
+	assert.Nil(t, d.VerifyProposer(blk))
+}
+
+func TestOperator(t *testing.T) {
+	assert := assert.New(t)
+	blk := newBlock(1)
+	owner := slotDelegate(blk)
+	operators := operatorElection{ta.Addrinfo[owner].Address: ta.Addrinfo["echo"].Address}
+
+	// echo signs the blocks of the delegate once it is registered as its operator
+	d := testDPoS(t, "echo")
+	assert.Equal(ErrWrongProposer, errors.Cause(d.FinalizeBlock(blk)))
+	d.SetElection(operators)
+	assert.Nil(d.FinalizeBlock(blk))
+	assert.Nil(d.VerifyProposer(blk))
+	start, _, err := d.NextSlot(1, 0, time.Unix(int64(blk.Timestamp()), 0))
+	assert.Nil(err)
+	assert.Equal(time.Unix(int64(blk.Timestamp()/10*10), 0), start)
+
+	// the delegate still signs with its own key
+	owned := newBlock(1)
+	owned.SignBlock(ta.Addrinfo[owner].PublicKey, ta.Addrinfo[owner].PrivateKey)
+	assert.Nil(d.VerifyProposer(owned))
+
+	// but the operator does not sign for the other delegates
+	d.SetElection(operatorElection{})
+	assert.Equal(ErrWrongProposer, errors.Cause(d.VerifyProposer(blk)))
+}
+
+func TestDoubleSignProtection(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "dpos")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.DPoS{
+		Enabled:         true,
+		Delegates:       []string{ta.Addrinfo["alfa"].Address},
+		BlockInterval:   10 * time.Second,
+		ProducerPubKey:  hex.EncodeToString(ta.Addrinfo["alfa"].PublicKey),
+		ProducerPrivKey: hex.EncodeToString(ta.Addrinfo["alfa"].PrivateKey),
+		SignRecordPath:  filepath.Join(dir, "signed.json"),
+	}
+	// newBlockAt returns a block at the height in the slot, paying the coinbase reward
+	newBlockAt := func(height uint32, slot uint64, reward uint64) *blockchain.Block {
+		cbtx := blockchain.NewCoinbaseTx(ta.Addrinfo["miner"].Address, reward, "")
+		pb := blockchain.NewBlock(0, height, cp.ZeroHash32B, []*blockchain.Tx{cbtx}).ConvertToBlockPb()
+		pb.Header.Timestamp = slot * 10
+		blk := &blockchain.Block{}
+		blk.ConvertFromBlockPb(pb)
+		return blk
+	}
+	clone := func(blk *blockchain.Block) *blockchain.Block {
+		c := &blockchain.Block{}
+		c.ConvertFromBlockPb(blk.ConvertToBlockPb())
+		return c
+	}
+
+	d, err := NewDPoS(cfg)
+	assert.Nil(err)
+	blk := newBlockAt(1, 100, 5)
+	assert.Nil(d.FinalizeBlock(blk))
+	// the same block can be signed again
+	assert.Nil(d.FinalizeBlock(clone(blk)))
+	// but not another block at the same height, in the same slot or below them
+	assert.Equal(ErrDoubleSign, errors.Cause(d.FinalizeBlock(newBlockAt(1, 101, 5))))
+	assert.Equal(ErrDoubleSign, errors.Cause(d.FinalizeBlock(newBlockAt(2, 100, 5))))
+	assert.Equal(ErrDoubleSign, errors.Cause(d.FinalizeBlock(newBlockAt(0, 99, 5))))
+
+	// the record survives restart
+	d, err = NewDPoS(cfg)
+	assert.Nil(err)
+	assert.Equal(ErrDoubleSign, errors.Cause(d.FinalizeBlock(newBlockAt(1, 100, 6))))
+	assert.Nil(d.FinalizeBlock(clone(blk)))
+	next := newBlockAt(2, 101, 5)
+	assert.Nil(d.FinalizeBlock(next))
+	d, err = NewDPoS(cfg)
+	assert.Nil(err)
+	assert.Nil(d.FinalizeBlock(clone(next)))
+	assert.Equal(ErrDoubleSign, errors.Cause(d.FinalizeBlock(clone(blk))))
+
+	// a corrupted record is refused
+	assert.Nil(ioutil.WriteFile(cfg.SignRecordPath, []byte("{\"hash\":\"00\"}"), 0600))
+	_, err = NewDPoS(cfg)
+	assert.Equal(ErrInvalidConfig, errors.Cause(err))
+}
+
+func TestNextSlot(t *testing.T) {
+	assert := assert.New(t)
+	d := testDPoS(t, "bravo")
+	// bravo is the delegate of the slots 3k+1
+	now := time.Unix(301*10+5, 0)
+	start, end, err := d.NextSlot(1, 0, now)
+	assert.Nil(err)
+	assert.Equal(time.Unix(301*10, 0), start)
+	assert.Equal(time.Unix(302*10, 0), end)
+	start, _, err = d.NextSlot(1, 0, now.Add(10*time.Second))
+	assert.Nil(err)
+	assert.Equal(time.Unix(304*10, 0), start)
+	// the slot has to be later than the parent's
+	start, _, err = d.NextSlot(1, 301*10, now)
+	assert.Nil(err)
+	assert.Equal(time.Unix(304*10, 0), start)
+
+	// a non-delegate has no slot
+	_, _, err = testDPoS(t, "delta").NextSlot(1, 0, now)
+	assert.Equal(ErrWrongProposer, errors.Cause(err))
+	d.SetElection(testElection{0: {{Address: ta.Addrinfo["delta"].Address, Votes: 1}}})
+	_, _, err = d.NextSlot(1, 0, now)
+	assert.Equal(ErrWrongProposer, errors.Cause(err))
+}
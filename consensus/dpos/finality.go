@@ -0,0 +1,251 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dpos
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/routine"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
+	pb "github.com/iotexproject/iotex-core/proto"
+)
+
+// maxVoteLead is how far above the tip a checkpoint vote is collected, the votes further ahead are ignored
+const maxVoteLead = 16
+
+// ErrInvalidVote indicates the checkpoint vote is malformed, not signed by a delegate of its height or the signature
+// does not match
+var ErrInvalidVote = errors.New("invalid checkpoint vote")
+
+var log = logger.New("dpos")
+
+// Finality is the finality gadget of the DPoS chain
+//
+// Every CheckpointInterval, a delegate signs the hash of the tip and gossips the vote. The votes of the delegates are
+// collected by height, each delegate counting once per height, and the highest block signed by more than two thirds
+// of the delegates of its height is finalized on the chain, which is then no longer reorganized below it.
+type Finality struct {
+	dpos      *DPoS
+	bc        blockchain.IBlockchain
+	broadcast func(proto.Message) error
+	task      *routine.RecurringTask
+
+	mu sync.Mutex
+	// votes are the hashes voted for at each height above the finalized one, by the address of the delegate
+	votes map[uint32]map[string]cp.Hash32B
+	// voted is the highest height the producer has voted for
+	voted uint32
+}
+
+// NewFinality creates the finality gadget signing the tip every interval with the producer keys of the engine, if the
+// producer is a delegate, and finalizing the blocks on the chain
+func NewFinality(d *DPoS, bc blockchain.IBlockchain, broadcast func(proto.Message) error, interval time.Duration) *Finality {
+	f := &Finality{
+		dpos:      d,
+		bc:        bc,
+		broadcast: broadcast,
+		votes:     make(map[uint32]map[string]cp.Hash32B),
+	}
+	f.task = routine.NewRecurringTask(f, interval)
+	return f
+}
+
+// Start starts signing the tip
+func (f *Finality) Start() error {
+	return f.task.Start()
+}
+
+// Stop stops signing the tip
+func (f *Finality) Stop() error {
+	return f.task.Stop()
+}
+
+// Do signs the tip and finalizes the blocks the votes collected have reached a supermajority for since the last tick
+func (f *Finality) Do() {
+	if err := f.vote(); err != nil {
+		log.Errorf("failed to vote for the tip: %v", err)
+	}
+	f.finalize()
+}
+
+// voteDigest returns the digest a delegate signs to vote for the block with the hash at the height of the chain
+func voteDigest(chainID uint32, height uint32, hash []byte) cp.Hash32B {
+	preimage := make([]byte, 8, 8+len(hash))
+	cm.MachineEndian.PutUint32(preimage[0:], chainID)
+	cm.MachineEndian.PutUint32(preimage[4:], height)
+	return blake2b.Sum256(append(preimage, hash...))
+}
+
+// vote signs the tip if the producer is one of its delegates and has not voted for it yet
+func (f *Finality) vote() error {
+	if len(f.dpos.privkey) == 0 {
+		return nil
+	}
+	height := f.bc.TipHeight()
+	f.mu.Lock()
+	voted := f.voted
+	f.mu.Unlock()
+	if height <= voted || height <= f.bc.FinalizedHeight() {
+		return nil
+	}
+	delegates, err := f.dpos.delegatesAt(height)
+	if err != nil {
+		return err
+	}
+	delegate := f.dpos.delegateOf(delegates, iotxaddress.HashPubKey(f.dpos.pubkey))
+	if delegate == "" {
+		return nil
+	}
+	hash, err := f.bc.GetHashByHeight(height)
+	if err != nil {
+		return err
+	}
+
+	digest := voteDigest(f.bc.ChainID(), height, hash[:])
+	vote := &pb.CheckpointVotePb{
+		Height:    height,
+		Hash:      hash[:],
+		PubKey:    f.dpos.pubkey,
+		Signature: cp.Sign(f.dpos.privkey, digest[:]),
+	}
+	f.mu.Lock()
+	f.voted = height
+	f.record(vote, delegate)
+	f.mu.Unlock()
+	return f.broadcast(vote)
+}
+
+// HandleVote collects the checkpoint vote of a delegate, finalizing the block it votes for once the votes for it reach
+// a supermajority
+// The votes at or below the finalized height, or too far above the tip, are ignored. ErrInvalidVote is returned if the
+// vote is not signed by a delegate of its height.
+func (f *Finality) HandleVote(vote *pb.CheckpointVotePb) error {
+	if len(vote.Hash) != len(cp.ZeroHash32B) || len(vote.PubKey) != ed25519.PublicKeySize ||
+		len(vote.Signature) != ed25519.SignatureSize {
+		return errors.Wrap(ErrInvalidVote, "malformed vote")
+	}
+	if vote.Height <= f.bc.FinalizedHeight() || vote.Height > f.bc.TipHeight()+maxVoteLead {
+		return nil
+	}
+	delegates, err := f.dpos.delegatesAt(vote.Height)
+	if err != nil {
+		return err
+	}
+	delegate := f.dpos.delegateOf(delegates, iotxaddress.HashPubKey(vote.PubKey))
+	if delegate == "" {
+		return errors.Wrapf(ErrInvalidVote, "voter is not a delegate of block %d", vote.Height)
+	}
+	digest := voteDigest(f.bc.ChainID(), vote.Height, vote.Hash)
+	if !cp.Verify(vote.PubKey, digest[:], vote.Signature) {
+		return errors.Wrapf(ErrInvalidVote, "wrong signature for block %d", vote.Height)
+	}
+
+	f.mu.Lock()
+	recorded := f.record(vote, delegate)
+	f.mu.Unlock()
+	if recorded {
+		f.finalize()
+	}
+	return nil
+}
+
+// record adds the vote of the delegate to the votes of its height unless the delegate has voted at the height, with
+// either its key or its operator's, and returns whether the vote is added
+func (f *Finality) record(vote *pb.CheckpointVotePb, delegate string) bool {
+	voters, ok := f.votes[vote.Height]
+	if !ok {
+		voters = make(map[string]cp.Hash32B)
+		f.votes[vote.Height] = voters
+	}
+	if _, ok := voters[delegate]; ok {
+		return false
+	}
+	var hash cp.Hash32B
+	copy(hash[:], vote.Hash)
+	voters[delegate] = hash
+	return true
+}
+
+// finalize finalizes the highest block on the chain with the votes of a supermajority of its delegates, and drops the
+// votes at or below the finalized height
+func (f *Finality) finalize() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	heights := make([]uint32, 0, len(f.votes))
+	for height := range f.votes {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+	for _, height := range heights {
+		if height > f.bc.TipHeight() {
+			continue
+		}
+		hash, ok := f.supermajority(height)
+		if !ok {
+			continue
+		}
+		// the votes are for a block on another branch until the chain is reorganized
+		if indexed, err := f.bc.GetHashByHeight(height); err != nil || indexed != hash {
+			continue
+		}
+		if err := f.bc.Finalize(height, hash); err != nil {
+			log.Errorf("failed to finalize block %d: %v", height, err)
+			continue
+		}
+		break
+	}
+
+	final := f.bc.FinalizedHeight()
+	for height := range f.votes {
+		if height <= final {
+			delete(f.votes, height)
+		}
+	}
+}
+
+// supermajority returns the hash more than two thirds of the delegates of the height have voted for, false if there
+// is none
+func (f *Finality) supermajority(height uint32) (cp.Hash32B, bool) {
+	delegates, err := f.dpos.delegatesAt(height)
+	if err != nil {
+		return cp.ZeroHash32B, false
+	}
+	counts := make(map[cp.Hash32B]int)
+	for voter, hash := range f.votes[height] {
+		// the delegates of the height may have been elected after the vote is recorded
+		if !isDelegate(delegates, voter) {
+			continue
+		}
+		counts[hash]++
+		if counts[hash]*3 > len(delegates)*2 {
+			return hash, true
+		}
+	}
+	return cp.ZeroHash32B, false
+}
+
+// isDelegate returns true if the address is one of the delegates
+func isDelegate(delegates []string, addr string) bool {
+	for _, d := range delegates {
+		if d == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dpos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestFinality(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hash := cp.Hash32B{1, 2, 3}
+	final := uint32(0)
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mbc.EXPECT().TipHeight().Return(uint32(5)).AnyTimes()
+	mbc.EXPECT().ChainID().Return(uint32(1)).AnyTimes()
+	mbc.EXPECT().GetHashByHeight(uint32(5)).Return(hash, nil).AnyTimes()
+	mbc.EXPECT().FinalizedHeight().DoAndReturn(func() uint32 { return final }).AnyTimes()
+	mbc.EXPECT().Finalize(uint32(5), hash).DoAndReturn(func(height uint32, _ cp.Hash32B) error {
+		final = height
+		return nil
+	}).Times(1)
+
+	// each delegate signs the tip once
+	votes := make(map[string]*pb.CheckpointVotePb)
+	gadget := func(name string) *Finality {
+		return NewFinality(testDPoS(t, name), mbc, func(msg proto.Message) error {
+			votes[name] = msg.(*pb.CheckpointVotePb)
+			return nil
+		}, time.Second)
+	}
+	f := gadget("alfa")
+	f.Do()
+	f.Do()
+	assert.Equal(uint32(5), votes["alfa"].Height)
+	assert.Equal(hash[:], votes["alfa"].Hash)
+	gadget("bravo").Do()
+	gadget("charlie").Do()
+
+	// the votes not signed by a delegate of the height are rejected
+	forged := *votes["bravo"]
+	forged.Hash = cp.ZeroHash32B[:]
+	assert.Equal(ErrInvalidVote, errors.Cause(f.HandleVote(&forged)))
+	outsider := testDPoS(t, "echo")
+	digest := voteDigest(1, 5, hash[:])
+	assert.Equal(ErrInvalidVote, errors.Cause(f.HandleVote(&pb.CheckpointVotePb{
+		Height:    5,
+		Hash:      hash[:],
+		PubKey:    outsider.pubkey,
+		Signature: cp.Sign(outsider.privkey, digest[:]),
+	})))
+	assert.Equal(ErrInvalidVote, errors.Cause(f.HandleVote(&pb.CheckpointVotePb{Height: 5})))
+
+	// two of three delegates are not a supermajority
+	assert.Nil(f.HandleVote(votes["bravo"]))
+	assert.Nil(f.HandleVote(votes["bravo"]))
+	assert.Equal(uint32(0), final)
+	assert.Nil(f.HandleVote(votes["charlie"]))
+	assert.Equal(uint32(5), final)
+	assert.Equal(0, len(f.votes))
+
+	// the votes at or below the finalized height are ignored
+	assert.Nil(f.HandleVote(votes["charlie"]))
+	assert.Equal(0, len(f.votes))
+}
+
+func TestOperatorVote(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hash := cp.Hash32B{1, 2, 3}
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mbc.EXPECT().TipHeight().Return(uint32(5)).AnyTimes()
+	mbc.EXPECT().ChainID().Return(uint32(1)).AnyTimes()
+	mbc.EXPECT().GetHashByHeight(uint32(5)).Return(hash, nil).AnyTimes()
+	mbc.EXPECT().FinalizedHeight().Return(uint32(0)).AnyTimes()
+
+	// echo votes for alfa, which has registered it as its operator
+	operators := operatorElection{ta.Addrinfo["alfa"].Address: ta.Addrinfo["echo"].Address}
+	var vote *pb.CheckpointVotePb
+	d := testDPoS(t, "echo")
+	d.SetElection(operators)
+	NewFinality(d, mbc, func(msg proto.Message) error {
+		vote = msg.(*pb.CheckpointVotePb)
+		return nil
+	}, time.Second).Do()
+	assert.NotNil(vote)
+
+	d = testDPoS(t, "alfa")
+	d.SetElection(operators)
+	f := NewFinality(d, mbc, func(proto.Message) error { return nil }, time.Second)
+	f.Do()
+	// the vote of the operator counts as the one alfa has already cast
+	assert.Nil(f.HandleVote(vote))
+	assert.Equal(1, len(f.votes[5]))
+	assert.Equal(hash, f.votes[5][ta.Addrinfo["alfa"].Address])
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package dpos
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+// signRecord is the last block the producer has signed, which is persisted to a file before the signed block is
+// released, so a producer restarted mid-slot does not sign another block at the same height or in the same slot and
+// get itself slashed
+// The record is only kept in memory if the path is empty.
+type signRecord struct {
+	path   string
+	signed bool
+	height uint32
+	slot   uint64
+	hash   cp.Hash32B
+}
+
+// signRecordFile is the JSON content of the file a signRecord is persisted to
+type signRecordFile struct {
+	Height uint32 `json:"height"`
+	Slot   uint64 `json:"slot"`
+	Hash   string `json:"hash"`
+}
+
+// loadSignRecord reads the record persisted to the file at the path, a missing file means nothing is signed yet
+func loadSignRecord(path string) (*signRecord, error) {
+	r := &signRecord{path: path}
+	if path == "" {
+		return r, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f signRecordFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, errors.Wrapf(err, "malformed sign record %s", path)
+	}
+	hash, err := hex.DecodeString(f.Hash)
+	if err != nil || len(hash) != len(r.hash) {
+		return nil, errors.Errorf("malformed block hash %q in sign record %s", f.Hash, path)
+	}
+	r.signed = true
+	r.height = f.Height
+	r.slot = f.Slot
+	copy(r.hash[:], hash)
+	return r, nil
+}
+
+// sign records the block at the height in the slot as signed, returning ErrDoubleSign if it conflicts with the last
+// block signed, i.e., it is another block at the same height or in the same slot, or below them
+// Signing the same block again is allowed.
+func (r *signRecord) sign(height uint32, slot uint64, hash cp.Hash32B) error {
+	if r.signed {
+		if hash == r.hash {
+			return nil
+		}
+		if height <= r.height || slot <= r.slot {
+			return errors.Wrapf(ErrDoubleSign, "block %d at slot %d conflicts with block %x signed at %d, slot %d",
+				height, slot, r.hash, r.height, r.slot)
+		}
+	}
+	if err := r.save(height, slot, hash); err != nil {
+		return errors.Wrap(err, "failed to persist the sign record")
+	}
+	r.signed = true
+	r.height = height
+	r.slot = slot
+	r.hash = hash
+	return nil
+}
+
+// save writes the record to the file, replacing the previous one, and syncs it to disk
+func (r *signRecord) save(height uint32, slot uint64, hash cp.Hash32B) error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(signRecordFile{Height: height, Slot: slot, Hash: hex.EncodeToString(hash[:])})
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, so a crash while saving keeps the previous record
+	tmpPath := r.path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, r.path)
+}
@@ -7,10 +7,13 @@
 package scheme
 
 import (
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
+
+	"github.com/iotexproject/iotex-core/logger"
 )
 
+var log = logger.New("scheme")
+
 // Noop is the consensus scheme that does NOT create blocks
 type Noop struct {
 }
@@ -32,6 +35,6 @@ func (n *Noop) Stop() error {
 
 // Handle handles incoming requests
 func (n *Noop) Handle(message proto.Message) error {
-	glog.Warning("Noop scheme does not handle incoming requests")
+	log.Warning("Noop scheme does not handle incoming requests")
 	return nil
 }
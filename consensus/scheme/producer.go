@@ -0,0 +1,153 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package scheme
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+)
+
+// Schedule tells the time slots the node produces blocks in, e.g., the DPoS engine
+type Schedule interface {
+	// NextSlot returns the start and the end of the earliest slot not over at 'now' in which the node produces the
+	// block at the height on top of the parent with the timestamp
+	NextSlot(height uint32, parentTimestamp uint64, now time.Time) (time.Time, time.Time, error)
+}
+
+// Producer is the consensus scheme that creates a block in each time slot the node is scheduled for
+// It waits MinTxWait into the slot for the transactions broadcast at its start, and assembles the block from the
+// mempool unless less than AssembleBudget is left in the slot or assembling fails, in which case an empty block is
+// created instead. The block is committed and broadcast if it is created before the slot is over.
+type Producer struct {
+	cfg           config.DPoS
+	schedule      Schedule
+	bc            blockchain.IBlockchain
+	createCb      CreateBlockCB
+	createEmptyCb CreateBlockCB
+	commitCb      ConsensusDoneCB
+	pubCb         BroadcastCB
+	now           func() time.Time
+	quit          chan struct{}
+	wg            sync.WaitGroup
+}
+
+// NewProducer creates a Producer creating the blocks from the mempool with create, or empty ones with createEmpty
+func NewProducer(cfg config.DPoS, schedule Schedule, create CreateBlockCB, createEmpty CreateBlockCB, commit ConsensusDoneCB, pub BroadcastCB, bc blockchain.IBlockchain) Scheme {
+	return &Producer{
+		cfg:           cfg,
+		schedule:      schedule,
+		bc:            bc,
+		createCb:      create,
+		createEmptyCb: createEmpty,
+		commitCb:      commit,
+		pubCb:         pub,
+		now:           time.Now,
+		quit:          make(chan struct{}),
+	}
+}
+
+// Start starts producing the blocks in the slots of the node
+func (p *Producer) Start() error {
+	p.wg.Add(1)
+	go p.run()
+	return nil
+}
+
+// Stop stops producing blocks, waiting for the block in production
+func (p *Producer) Stop() error {
+	close(p.quit)
+	p.wg.Wait()
+	return nil
+}
+
+// Handle handles incoming requests
+func (p *Producer) Handle(message proto.Message) error {
+	log.Warning("Producer scheme does not handle incoming requests")
+	return nil
+}
+
+func (p *Producer) run() {
+	defer p.wg.Done()
+	for {
+		height := p.bc.TipHeight() + 1
+		start, end, err := p.nextSlot(height)
+		if err != nil {
+			log.Errorf("Failed to schedule block %d: %v", height, err)
+			if !p.sleep(p.cfg.BlockInterval) {
+				return
+			}
+			continue
+		}
+		if !p.sleep(start.Add(p.cfg.MinTxWait).Sub(p.now())) {
+			return
+		}
+		// the parent has changed if a block has been committed meanwhile
+		if p.bc.TipHeight()+1 != height {
+			continue
+		}
+		p.produce(height, end)
+		// one block per slot, even if it fails to be committed
+		if !p.sleep(end.Sub(p.now())) {
+			return
+		}
+	}
+}
+
+// nextSlot returns the next slot of the node to produce the block at the height in
+func (p *Producer) nextSlot(height uint32) (time.Time, time.Time, error) {
+	parent, err := p.bc.GetBlockMetaByHeight(height - 1)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return p.schedule.NextSlot(height, parent.Timestamp, p.now())
+}
+
+// produce creates, commits and broadcasts the block at the height in the slot ending at the time
+func (p *Producer) produce(height uint32, end time.Time) {
+	var blk *blockchain.Block
+	var err error
+	if end.Sub(p.now()) >= p.cfg.AssembleBudget {
+		if blk, err = p.createCb(); err != nil {
+			log.Warningf("Failed to assemble block %d from the mempool, creating an empty block: %v", height, err)
+			blk = nil
+		}
+	}
+	if blk == nil {
+		if blk, err = p.createEmptyCb(); err != nil {
+			log.Errorf("Failed to create block %d: %v", height, err)
+			return
+		}
+	}
+	if !p.now().Before(end) {
+		log.Warningf("Missed the slot of block %d, which ended at %v", height, end)
+		return
+	}
+	if err := p.commitCb(blk); err != nil {
+		log.Errorf("Failed to commit block %d: %v", height, err)
+		return
+	}
+	if err := p.pubCb(blk); err != nil {
+		log.Errorf("Failed to broadcast block %d: %v", height, err)
+	}
+}
+
+// sleep waits for the duration, returning false if the producer is stopped meanwhile
+func (p *Producer) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-p.quit:
+		return false
+	}
+}
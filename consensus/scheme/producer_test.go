@@ -0,0 +1,114 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package scheme
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+// testSchedule schedules the node for a slot of the duration starting right away
+type testSchedule time.Duration
+
+func (s testSchedule) NextSlot(height uint32, parentTimestamp uint64, now time.Time) (time.Time, time.Time, error) {
+	return now, now.Add(time.Duration(s)), nil
+}
+
+// testChain records the blocks produced
+type testChain struct {
+	mu        sync.Mutex
+	created   int
+	empty     int
+	committed int
+	done      chan struct{}
+}
+
+func (c *testChain) counts() (int, int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.created, c.empty, c.committed
+}
+
+func testProducer(t *testing.T, ctrl *gomock.Controller, slot time.Duration, createErr error) (Scheme, *testChain) {
+	c := &testChain{done: make(chan struct{})}
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mbc.EXPECT().TipHeight().DoAndReturn(func() uint32 {
+		_, _, committed := c.counts()
+		return uint32(committed)
+	}).AnyTimes()
+	mbc.EXPECT().GetBlockMetaByHeight(gomock.Any()).Return(&blockchain.BlockMeta{}, nil).AnyTimes()
+
+	cbtx := blockchain.NewCoinbaseTx(ta.Addrinfo["miner"].Address, 5, "")
+	blk := blockchain.NewBlock(0, 1, cp.ZeroHash32B, []*blockchain.Tx{cbtx})
+	create := func() (*blockchain.Block, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.created++
+		return blk, createErr
+	}
+	createEmpty := func() (*blockchain.Block, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.empty++
+		return blk, nil
+	}
+	commit := func(*blockchain.Block) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.committed++
+		if c.committed == 2 {
+			close(c.done)
+		}
+		return nil
+	}
+	cfg := config.DPoS{BlockInterval: time.Second, MinTxWait: 10 * time.Millisecond, AssembleBudget: 100 * time.Millisecond}
+	return NewProducer(cfg, testSchedule(slot), create, createEmpty, commit, func(*blockchain.Block) error { return nil }, mbc), c
+}
+
+func TestProducer(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, test := range []struct {
+		slot      time.Duration
+		createErr error
+		created   int
+		empty     int
+	}{
+		// blocks are assembled from the mempool
+		{200 * time.Millisecond, nil, 2, 0},
+		// an empty block is created if the assembling fails
+		{200 * time.Millisecond, errors.New("invalid tx"), 2, 2},
+		// or too little is left in the slot
+		{50 * time.Millisecond, nil, 0, 2},
+	} {
+		p, c := testProducer(t, ctrl, test.slot, test.createErr)
+		assert.Nil(p.Start())
+		select {
+		case <-c.done:
+		case <-time.After(5 * time.Second):
+			assert.Fail("Blocks are not produced")
+		}
+		assert.Nil(p.Stop())
+		created, empty, committed := c.counts()
+		assert.Equal(test.created, created)
+		assert.Equal(test.empty, empty)
+		assert.Equal(2, committed)
+	}
+}
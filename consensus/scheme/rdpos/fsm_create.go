@@ -16,10 +16,12 @@ const (
 	stateAcceptVote    fsm.State = "VOTE"
 )
 
-func fsmCreate(r *RDPoS) fsm.Machine {
+func fsmCreate(r *RDPoS) (fsm.Machine, error) {
 	sm := fsm.NewMachine()
 
-	sm.SetInitialState(stateStart, &start{RDPoS: r})
+	if err := sm.SetInitialState(stateStart, &start{RDPoS: r}); err != nil {
+		return sm, err
+	}
 	sm.AddState(stateInitPropose, &initPropose{RDPoS: r})
 	sm.AddState(stateAcceptPropose, &acceptPropose{RDPoS: r})
 	sm.AddState(stateAcceptPrevote, &acceptPrevote{RDPoS: r})
@@ -32,5 +34,5 @@ func fsmCreate(r *RDPoS) fsm.Machine {
 	sm.AddTransition(stateAcceptPrevote, stateAcceptVote, &ruleVote{RDPoS: r})
 	sm.AddTransition(stateAcceptVote, stateStart, &ruleCommit{RDPoS: r})
 
-	return sm
+	return sm, nil
 }
@@ -7,8 +7,6 @@
 package rdpos
 
 import (
-	"github.com/golang/glog"
-
 	"github.com/iotexproject/iotex-core/common/routine"
 	"github.com/iotexproject/iotex-core/consensus/fsm"
 )
@@ -27,7 +25,7 @@ func (s *proposerRotation) Do() {
 		return
 	}
 
-	glog.Warningf("[%s] propose a new block height %v", s.self, s.bc.TipHeight()+1)
+	log.Warningf("[%s] propose a new block height %v", s.self, s.bc.TipHeight()+1)
 	s.fsm.HandleTransition(&fsm.Event{
 		State: stateInitPropose,
 	})
@@ -37,7 +37,7 @@ func TestProposerRotation(t *testing.T) {
 		mcks.bc.EXPECT().TipHeight().AnyTimes()
 		cbtx := bc.NewCoinbaseTx(ta.Addrinfo["miner"].Address, 888, bc.GenesisCoinbaseData)
 		genesis := bc.NewBlock(0, 0, cp.ZeroHash32B, []*bc.Tx{cbtx})
-		mcks.bc.EXPECT().MintNewBlock(gomock.Any(), gomock.Any(), gomock.Any()).Return(genesis).AnyTimes()
+		mcks.bc.EXPECT().MintNewBlock(gomock.Any(), gomock.Any(), gomock.Any()).Return(genesis, nil).AnyTimes()
 	}
 	cs := createTestRDPoS(ctrl, delegates[0], delegates, m, true)
 	cs.Start()
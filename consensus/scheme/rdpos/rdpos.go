@@ -9,10 +9,8 @@ package rdpos
 import (
 	"net"
 	"sync"
-	"syscall"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 
@@ -23,9 +21,12 @@ import (
 	"github.com/iotexproject/iotex-core/consensus/scheme"
 	cp "github.com/iotexproject/iotex-core/crypto"
 	"github.com/iotexproject/iotex-core/delegate"
+	"github.com/iotexproject/iotex-core/logger"
 	pb "github.com/iotexproject/iotex-core/proto"
 )
 
+var log = logger.New("rdpos")
+
 var (
 	// ErrInvalidViewChangeMsg is the error that ViewChangeMsg is invalid
 	ErrInvalidViewChangeMsg = errors.New("ViewChangeMsg is invalid")
@@ -67,11 +68,10 @@ type RDPoS struct {
 }
 
 // NewRDPoS creates a RDPoS struct
-func NewRDPoS(cfg config.RDPoS, prop scheme.CreateBlockCB, vote scheme.TellPeerCB, cons scheme.ConsensusDoneCB, pub scheme.BroadcastCB, bc blockchain.IBlockchain, myaddr net.Addr, dlg delegate.Pool) *RDPoS {
+func NewRDPoS(cfg config.RDPoS, prop scheme.CreateBlockCB, vote scheme.TellPeerCB, cons scheme.ConsensusDoneCB, pub scheme.BroadcastCB, bc blockchain.IBlockchain, myaddr net.Addr, dlg delegate.Pool) (*RDPoS, error) {
 	delegates, err := dlg.AllDelegates()
 	if err != nil {
-		glog.Fatal(err.Error())
-		syscall.Exit(syscall.SYS_EXIT)
+		return nil, errors.Wrap(err, "Failed to get the delegates")
 	}
 	sc := &RDPoS{
 		propCb:    prop,
@@ -87,13 +87,15 @@ func NewRDPoS(cfg config.RDPoS, prop scheme.CreateBlockCB, vote scheme.TellPeerC
 		cfg:       cfg,
 	}
 	sc.pr = NewProposerRotation(sc)
-	sc.fsm = fsmCreate(sc)
-	return sc
+	if sc.fsm, err = fsmCreate(sc); err != nil {
+		return nil, err
+	}
+	return sc, nil
 }
 
 // Start initialize the RDPoS and start to consume requests from request channel.
 func (n *RDPoS) Start() error {
-	glog.Info("Starting RDPoS")
+	log.Info("Starting RDPoS")
 	n.wg.Add(1)
 	go n.consume()
 	if n.cfg.ProposerRotation.Enabled {
@@ -104,7 +106,7 @@ func (n *RDPoS) Start() error {
 
 // Stop stops the RDPoS and stop consuming requests from request channel.
 func (n *RDPoS) Stop() error {
-	glog.Infof("RDPoS is shutting down")
+	log.Infof("RDPoS is shutting down")
 	close(n.quit)
 	n.wg.Wait()
 	return nil
@@ -112,7 +114,7 @@ func (n *RDPoS) Stop() error {
 
 // Handle handles incoming messages and publish to the channel.
 func (n *RDPoS) Handle(m proto.Message) error {
-	glog.Info("RDPoS scheme handles incoming requests")
+	log.Info("RDPoS scheme handles incoming requests")
 
 	event, err := eventFromProto(m)
 	if err != nil {
@@ -145,7 +147,7 @@ loop:
 				}
 			case fsm.ErrNoTransitionApplied:
 			default:
-				glog.Errorf("%s failed to fsm.HandleTransition: %s", n.self, err)
+				log.Errorf("%s failed to fsm.HandleTransition: %s", n.self, err)
 			}
 		case <-n.quit:
 			break loop
@@ -153,7 +155,7 @@ loop:
 	}
 
 	n.wg.Done()
-	glog.Info("consume done")
+	log.Info("consume done")
 }
 
 func (n *RDPoS) tellDelegates(msg *pb.ViewChangeMsg) {
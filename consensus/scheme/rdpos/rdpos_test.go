@@ -11,7 +11,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
@@ -42,10 +41,13 @@ type mockFn func(mcks mocks)
 func createTestRDPoS(ctrl *gomock.Controller, self net.Addr, delegates []net.Addr, mockFn mockFn, enableProposerRotation bool) *RDPoS {
 	bc := mock_blockchain.NewMockIBlockchain(ctrl)
 
-	tp := txpool.New(bc)
+	tp := txpool.New(bc, &config.TxPool{})
 	createblockCB := func() (*blockchain.Block, error) {
-		blk := bc.MintNewBlock(tp.Txs(), "", "")
-		glog.Infof("created a new block at height %v with %v txs", blk.Height(), len(blk.Tranxs))
+		blk, err := bc.MintNewBlock(tp.Txs(), "", "")
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("created a new block at height %v with %v txs", blk.Height(), len(blk.Tranxs))
 		return blk, nil
 	}
 	commitBlockCB := func(blk *blockchain.Block) error {
@@ -82,7 +84,11 @@ func createTestRDPoS(ctrl *gomock.Controller, self net.Addr, delegates []net.Add
 		bc:   bc,
 		dp:   dp,
 	})
-	return NewRDPoS(csCfg, createblockCB, tellblockCB, commitBlockCB, broadcastBlockCB, bc, dNet.Self(), dp)
+	cs, err := NewRDPoS(csCfg, createblockCB, tellblockCB, commitBlockCB, broadcastBlockCB, bc, dNet.Self(), dp)
+	if err != nil {
+		panic(err)
+	}
+	return cs
 }
 
 type testCs struct {
@@ -138,7 +144,7 @@ func testByzantineFault(t *testing.T, proposerNode int) {
 		m := func(mcks mocks) {
 			mcks.dp.EXPECT().AllDelegates().Return(delegates, nil).AnyTimes()
 			mcks.dNet.EXPECT().Self().Return(cur).AnyTimes()
-			mcks.bc.EXPECT().MintNewBlock(gomock.Any(), gomock.Any(), gomock.Any()).Return(genesis).AnyTimes()
+			mcks.bc.EXPECT().MintNewBlock(gomock.Any(), gomock.Any(), gomock.Any()).Return(genesis, nil).AnyTimes()
 			mcks.bc.EXPECT().ValidateBlock(gomock.Any()).Do(func(blk *Block) error {
 				if blk == nil {
 					return errors.New("invalid block")
@@ -260,7 +266,7 @@ func TestRDPoSFourTrustyNodes(t *testing.T) {
 		m := func(mcks mocks) {
 			mcks.dp.EXPECT().AllDelegates().Return(delegates, nil).AnyTimes()
 			mcks.dNet.EXPECT().Self().Return(cur).AnyTimes()
-			mcks.bc.EXPECT().MintNewBlock(gomock.Any(), gomock.Any(), gomock.Any()).Return(genesis).AnyTimes()
+			mcks.bc.EXPECT().MintNewBlock(gomock.Any(), gomock.Any(), gomock.Any()).Return(genesis, nil).AnyTimes()
 			mcks.bc.EXPECT().ValidateBlock(gomock.Any()).AnyTimes()
 
 			// =====================
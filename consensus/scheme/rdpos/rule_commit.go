@@ -7,7 +7,6 @@
 package rdpos
 
 import (
-	"github.com/golang/glog"
 	"github.com/iotexproject/iotex-core/consensus/fsm"
 )
 
@@ -24,7 +23,7 @@ func (r ruleCommit) Condition(event *fsm.Event) bool {
 
 	// no consensus reached
 	if event.StateTimedOut || event.Err != nil || !r.reachedMaj() {
-		glog.Warningf("|||||| node %s no consensus agreed: state time out %+v, event error %+v, r.reachedMaj() %+v", r.self.String(), event.StateTimedOut, event.Err, r.reachedMaj())
+		log.Warningf("|||||| node %s no consensus agreed: state time out %+v, event error %+v, r.reachedMaj() %+v", r.self.String(), event.StateTimedOut, event.Err, r.reachedMaj())
 		return true
 	}
 
@@ -35,7 +34,7 @@ func (r ruleCommit) Condition(event *fsm.Event) bool {
 
 		// only proposer needs to broadcast the consensus block
 		if r.proposer {
-			glog.Warningf("|||||| node %s, brodcast block", r.self.String())
+			log.Warningf("|||||| node %s, brodcast block", r.self.String())
 			r.pubCb(r.roundCtx.block)
 		}
 	}
@@ -9,7 +9,6 @@ package scheme
 import (
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 
 	"github.com/iotexproject/iotex-core/blockchain"
@@ -29,15 +28,15 @@ type standaloneHandler struct {
 }
 
 func (s *standaloneHandler) Do() {
-	glog.Info("create a new block at ", time.Now())
+	log.Info("create a new block at ", time.Now())
 	blk, err := s.createCb()
 	if err != nil {
-		glog.Error(err)
+		log.Error(err)
 		return
 	}
 
 	if err := s.commitCb(blk); err != nil {
-		glog.Error(err)
+		log.Error(err)
 		return
 	}
 
@@ -68,6 +67,6 @@ func (n *Standalone) Stop() error {
 
 // Handle handles incoming requests
 func (n *Standalone) Handle(message proto.Message) error {
-	glog.Warning("Standalone scheme does not handle incoming requests")
+	log.Warning("Standalone scheme does not handle incoming requests")
 	return nil
 }
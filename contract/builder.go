@@ -0,0 +1,59 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contract
+
+import "github.com/pkg/errors"
+
+// CodeBuilder assembles the code of a contract, the first error is kept and returned by Code
+type CodeBuilder struct {
+	code []byte
+	err  error
+}
+
+// NewCodeBuilder returns a builder with empty code
+func NewCodeBuilder() *CodeBuilder {
+	return &CodeBuilder{}
+}
+
+// AddOps adds the opcodes
+func (b *CodeBuilder) AddOps(ops ...byte) *CodeBuilder {
+	b.code = append(b.code, ops...)
+	return b
+}
+
+// AddPush adds an OpPush of the data, which has to be 1 to 255 bytes long
+func (b *CodeBuilder) AddPush(data []byte) *CodeBuilder {
+	if len(data) == 0 || len(data) > 255 {
+		if b.err == nil {
+			b.err = errors.Wrapf(ErrInvalidCode, "Cannot push %d bytes", len(data))
+		}
+		return b
+	}
+	b.code = append(append(b.code, OpPush, byte(len(data))), data...)
+	return b
+}
+
+// AddPushUint64 adds an OpPush of the 8-byte integer
+func (b *CodeBuilder) AddPushUint64(v uint64) *CodeBuilder {
+	return b.AddPush(Uint64Bytes(v))
+}
+
+// Len returns the size of the code so far, which is the position of the next instruction, e.g., a OpJumpDest
+func (b *CodeBuilder) Len() int {
+	return len(b.code)
+}
+
+// Code returns the code once it passes Validate
+func (b *CodeBuilder) Code() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := Validate(b.code); err != nil {
+		return nil, err
+	}
+	return b.code, nil
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contract
+
+import "github.com/pkg/errors"
+
+var (
+	// ErrOutOfGas is the error returned when an execution uses up its gas limit
+	ErrOutOfGas = errors.New("out of gas")
+	// ErrInvalidCode is the error returned when deploying code which cannot be run
+	ErrInvalidCode = errors.New("invalid code")
+	// ErrInvalidOpcode is the error returned when running an undefined opcode
+	ErrInvalidOpcode = errors.New("invalid opcode")
+	// ErrStackUnderflow is the error returned when an instruction pops more items than the stack has
+	ErrStackUnderflow = errors.New("stack underflow")
+	// ErrStackOverflow is the error returned when the stack grows beyond MaxStackDepth
+	ErrStackOverflow = errors.New("stack overflow")
+	// ErrInvalidOperand is the error returned when an arithmetic operand is longer than 8 bytes
+	ErrInvalidOperand = errors.New("invalid operand")
+	// ErrInvalidJump is the error returned when jumping to a position which is not a OpJumpDest
+	ErrInvalidJump = errors.New("invalid jump destination")
+	// ErrReverted is the error returned when the code reverts the execution
+	ErrReverted = errors.New("execution reverted")
+)
@@ -0,0 +1,379 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contract
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Opcodes of the contract VM, integers are big-endian unsigned integers of at most 8 bytes and arithmetic wraps around
+const (
+	// OpStop halts the execution successfully without returning anything
+	OpStop = 0x00
+	// OpAdd pops a and b and pushes a + b
+	OpAdd = 0x01
+	// OpSub pops a and b and pushes a - b, where a is the item below b
+	OpSub = 0x02
+	// OpMul pops a and b and pushes a * b
+	OpMul = 0x03
+	// OpDiv pops a and b and pushes a / b, or 0 if b is 0
+	OpDiv = 0x04
+	// OpMod pops a and b and pushes a % b, or 0 if b is 0
+	OpMod = 0x05
+
+	// OpLt pops a and b and pushes 1 if a < b, 0 otherwise
+	OpLt = 0x10
+	// OpGt pops a and b and pushes 1 if a > b, 0 otherwise
+	OpGt = 0x11
+	// OpEq pops a and b and pushes 1 if they are the same bytes, 0 otherwise
+	OpEq = 0x12
+	// OpIsZero pops a and pushes 1 if all its bytes are zero, 0 otherwise
+	OpIsZero = 0x13
+
+	// OpHash pops a and pushes its blake2b-256 hash
+	OpHash = 0x20
+
+	// OpCaller pushes the address of the executor
+	OpCaller = 0x30
+	// OpAddress pushes the address of the contract
+	OpAddress = 0x31
+	// OpArg pops i and pushes the i-th argument of the invocation, or an empty item if there is no such argument
+	OpArg = 0x32
+	// OpArgCount pushes the number of arguments of the invocation
+	OpArgCount = 0x33
+
+	// OpDrop pops the top item
+	OpDrop = 0x50
+	// OpDup pushes a copy of the top item
+	OpDup = 0x51
+	// OpSwap swaps the top two items
+	OpSwap = 0x52
+	// OpSLoad pops a key and pushes its value in the storage of the contract, or an empty item if it is not set
+	OpSLoad = 0x54
+	// OpSStore pops a key and a value, where the value is the item below the key, and stores the value in the storage
+	// of the contract, an empty value deletes the key
+	OpSStore = 0x55
+	// OpJump pops a position and continues from it, which must be a OpJumpDest
+	OpJump = 0x56
+	// OpJumpI pops a position and a condition and jumps to the position if the condition is not zero
+	OpJumpI = 0x57
+	// OpJumpDest marks a valid jump destination
+	OpJumpDest = 0x5b
+
+	// OpPush is followed by 1 byte of data length and the data, which it pushes
+	OpPush = 0x60
+
+	// OpLog0 pops the data and emits a log with no topic
+	OpLog0 = 0xa0
+	// OpLog1 pops the data and 1 topic below it and emits a log
+	OpLog1 = 0xa1
+	// OpLog2 pops the data and 2 topics below it and emits a log, the topics are in the order they were pushed
+	OpLog2 = 0xa2
+	// OpLog3 pops the data and 3 topics below it and emits a log
+	OpLog3 = 0xa3
+	// OpLog4 pops the data and 4 topics below it and emits a log
+	OpLog4 = 0xa4
+
+	// OpReturn pops the top item and halts the execution successfully, returning the item
+	OpReturn = 0xf3
+	// OpRevert pops the top item and halts the execution with ErrReverted, returning the item
+	OpRevert = 0xfd
+)
+
+// Gas consumed by the instructions
+const (
+	gasBase     = 1
+	gasJump     = 8
+	gasHash     = 30
+	gasSLoad    = 50
+	gasSStore   = 200
+	gasPerStore = 4 // per byte of key and value written by OpSStore
+	gasLog      = 100
+	gasLogTopic = 50
+	gasPerLog   = 2 // per byte of topics and data emitted by OpLog
+)
+
+type opinfo struct {
+	name string
+	gas  uint64
+	run  func(vm *VM) error
+}
+
+var opinfoArray [256]opinfo
+
+func init() {
+	opinfoArray[OpStop] = opinfo{"OpStop", 0, opStop}
+	opinfoArray[OpAdd] = opinfo{"OpAdd", gasBase, arith(func(a, b uint64) uint64 { return a + b })}
+	opinfoArray[OpSub] = opinfo{"OpSub", gasBase, arith(func(a, b uint64) uint64 { return a - b })}
+	opinfoArray[OpMul] = opinfo{"OpMul", gasBase, arith(func(a, b uint64) uint64 { return a * b })}
+	opinfoArray[OpDiv] = opinfo{"OpDiv", gasBase, arith(func(a, b uint64) uint64 {
+		if b == 0 {
+			return 0
+		}
+		return a / b
+	})}
+	opinfoArray[OpMod] = opinfo{"OpMod", gasBase, arith(func(a, b uint64) uint64 {
+		if b == 0 {
+			return 0
+		}
+		return a % b
+	})}
+	opinfoArray[OpLt] = opinfo{"OpLt", gasBase, arith(func(a, b uint64) uint64 { return boolToUint64(a < b) })}
+	opinfoArray[OpGt] = opinfo{"OpGt", gasBase, arith(func(a, b uint64) uint64 { return boolToUint64(a > b) })}
+	opinfoArray[OpEq] = opinfo{"OpEq", gasBase, opEq}
+	opinfoArray[OpIsZero] = opinfo{"OpIsZero", gasBase, opIsZero}
+	opinfoArray[OpHash] = opinfo{"OpHash", gasHash, opHash}
+	opinfoArray[OpCaller] = opinfo{"OpCaller", gasBase, opCaller}
+	opinfoArray[OpAddress] = opinfo{"OpAddress", gasBase, opAddress}
+	opinfoArray[OpArg] = opinfo{"OpArg", gasBase, opArg}
+	opinfoArray[OpArgCount] = opinfo{"OpArgCount", gasBase, opArgCount}
+	opinfoArray[OpDrop] = opinfo{"OpDrop", gasBase, opDrop}
+	opinfoArray[OpDup] = opinfo{"OpDup", gasBase, opDup}
+	opinfoArray[OpSwap] = opinfo{"OpSwap", gasBase, opSwap}
+	opinfoArray[OpSLoad] = opinfo{"OpSLoad", gasSLoad, opSLoad}
+	opinfoArray[OpSStore] = opinfo{"OpSStore", gasSStore, opSStore}
+	opinfoArray[OpJump] = opinfo{"OpJump", gasJump, opJump}
+	opinfoArray[OpJumpI] = opinfo{"OpJumpI", gasJump, opJumpI}
+	opinfoArray[OpJumpDest] = opinfo{"OpJumpDest", gasBase, opJumpDest}
+	opinfoArray[OpPush] = opinfo{"OpPush", gasBase, opPush}
+	for n := 0; n <= MaxLogTopics; n++ {
+		opinfoArray[OpLog0+n] = opinfo{fmt.Sprintf("OpLog%d", n), gasLog + uint64(n)*gasLogTopic, opLog(n)}
+	}
+	opinfoArray[OpReturn] = opinfo{"OpReturn", 0, opReturn}
+	opinfoArray[OpRevert] = opinfo{"OpRevert", 0, opRevert}
+}
+
+// Uint64 returns the integer encoded by the item, or ErrInvalidOperand if it is longer than 8 bytes
+func Uint64(item []byte) (uint64, error) {
+	if len(item) > 8 {
+		return 0, ErrInvalidOperand
+	}
+	v := uint64(0)
+	for _, b := range item {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+// Uint64Bytes returns the 8-byte item encoding the integer
+func Uint64Bytes(v uint64) []byte {
+	item := make([]byte, 8)
+	binary.BigEndian.PutUint64(item, v)
+	return item
+}
+
+func boolToUint64(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func isZero(item []byte) bool {
+	for _, b := range item {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func opStop(vm *VM) error {
+	vm.halted = true
+	return nil
+}
+
+// arith returns the instruction popping two integers and pushing the result of the function
+func arith(fn func(a, b uint64) uint64) func(vm *VM) error {
+	return func(vm *VM) error {
+		items, err := vm.pop(2)
+		if err != nil {
+			return err
+		}
+		a, err := Uint64(items[0])
+		if err != nil {
+			return err
+		}
+		b, err := Uint64(items[1])
+		if err != nil {
+			return err
+		}
+		return vm.push(Uint64Bytes(fn(a, b)))
+	}
+}
+
+func opEq(vm *VM) error {
+	items, err := vm.pop(2)
+	if err != nil {
+		return err
+	}
+	return vm.push(Uint64Bytes(boolToUint64(string(items[0]) == string(items[1]))))
+}
+
+func opIsZero(vm *VM) error {
+	items, err := vm.pop(1)
+	if err != nil {
+		return err
+	}
+	return vm.push(Uint64Bytes(boolToUint64(isZero(items[0]))))
+}
+
+func opHash(vm *VM) error {
+	items, err := vm.pop(1)
+	if err != nil {
+		return err
+	}
+	hash := blake2b.Sum256(items[0])
+	return vm.push(hash[:])
+}
+
+func opCaller(vm *VM) error {
+	return vm.push([]byte(vm.ctx.Caller))
+}
+
+func opAddress(vm *VM) error {
+	return vm.push([]byte(vm.ctx.Contract))
+}
+
+func opArg(vm *VM) error {
+	items, err := vm.pop(1)
+	if err != nil {
+		return err
+	}
+	i, err := Uint64(items[0])
+	if err != nil {
+		return err
+	}
+	if i >= uint64(len(vm.ctx.Args)) {
+		return vm.push([]byte{})
+	}
+	return vm.push(vm.ctx.Args[i])
+}
+
+func opArgCount(vm *VM) error {
+	return vm.push(Uint64Bytes(uint64(len(vm.ctx.Args))))
+}
+
+func opDrop(vm *VM) error {
+	_, err := vm.pop(1)
+	return err
+}
+
+func opDup(vm *VM) error {
+	if len(vm.stack) == 0 {
+		return ErrStackUnderflow
+	}
+	return vm.push(vm.stack[len(vm.stack)-1])
+}
+
+func opSwap(vm *VM) error {
+	n := len(vm.stack)
+	if n < 2 {
+		return ErrStackUnderflow
+	}
+	vm.stack[n-1], vm.stack[n-2] = vm.stack[n-2], vm.stack[n-1]
+	return nil
+}
+
+func opSLoad(vm *VM) error {
+	items, err := vm.pop(1)
+	if err != nil {
+		return err
+	}
+	value := vm.storage.Get(items[0])
+	if value == nil {
+		value = []byte{}
+	}
+	return vm.push(value)
+}
+
+func opSStore(vm *VM) error {
+	items, err := vm.pop(2)
+	if err != nil {
+		return err
+	}
+	value, key := items[0], items[1]
+	if err := vm.useGas(uint64(len(key)+len(value)) * gasPerStore); err != nil {
+		return err
+	}
+	vm.storage.Put(key, value)
+	return nil
+}
+
+func opJump(vm *VM) error {
+	items, err := vm.pop(1)
+	if err != nil {
+		return err
+	}
+	return vm.jump(items[0])
+}
+
+func opJumpI(vm *VM) error {
+	items, err := vm.pop(2)
+	if err != nil {
+		return err
+	}
+	if isZero(items[0]) {
+		return nil
+	}
+	return vm.jump(items[1])
+}
+
+func opJumpDest(vm *VM) error {
+	return nil
+}
+
+func opPush(vm *VM) error {
+	// the code is validated so the data is never truncated
+	size := int(vm.code[vm.pc])
+	data := vm.code[vm.pc+1 : vm.pc+1+size]
+	vm.pc += 1 + size
+	return vm.push(data)
+}
+
+// opLog returns the instruction emitting a log with n topics
+func opLog(n int) func(vm *VM) error {
+	return func(vm *VM) error {
+		items, err := vm.pop(n + 1)
+		if err != nil {
+			return err
+		}
+		l := &Log{Address: vm.ctx.Contract, Data: items[n]}
+		size := len(l.Data)
+		for _, topic := range items[:n] {
+			l.Topics = append(l.Topics, topic)
+			size += len(topic)
+		}
+		if err := vm.useGas(uint64(size) * gasPerLog); err != nil {
+			return err
+		}
+		vm.logs = append(vm.logs, l)
+		return nil
+	}
+}
+
+func opReturn(vm *VM) error {
+	items, err := vm.pop(1)
+	if err != nil {
+		return err
+	}
+	vm.ret = items[0]
+	vm.halted = true
+	return nil
+}
+
+func opRevert(vm *VM) error {
+	items, err := vm.pop(1)
+	if err != nil {
+		return err
+	}
+	vm.ret = items[0]
+	return ErrReverted
+}
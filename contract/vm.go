@@ -0,0 +1,188 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contract
+
+import "github.com/pkg/errors"
+
+const (
+	// ExecutionGas is the gas every execution consumes before running any code
+	ExecutionGas = 1000
+	// CodeGasPerByte is the gas a deployment consumes per byte of the deployed code
+	CodeGasPerByte = 20
+	// MaxCodeSize is the maximum size of the code of a contract
+	MaxCodeSize = 24 * 1024
+	// MaxStackDepth is the maximum number of items on the stack
+	MaxStackDepth = 1024
+	// MaxLogTopics is the maximum number of topics of a log
+	MaxLogTopics = 4
+)
+
+// Storage is the key-value storage of a contract
+type Storage interface {
+	// Get returns the value of the key, or nil if the key is not set
+	Get(key []byte) []byte
+	// Put sets the value of the key, an empty value deletes the key
+	Put(key []byte, value []byte)
+}
+
+// Context is the environment a contract is invoked in
+type Context struct {
+	// Caller is the address of the executor invoking the contract
+	Caller string
+	// Contract is the address of the invoked contract
+	Contract string
+	// Args are the arguments of the invocation
+	Args [][]byte
+}
+
+// Log is emitted by a contract to record an event, which can be looked up by the address of the contract and the
+// topics
+type Log struct {
+	Address string
+	Topics  [][]byte
+	Data    []byte
+}
+
+// VM runs the code of a contract on a stack of byte items, every instruction consumes gas and the execution fails with
+// ErrOutOfGas once the gas limit is used up
+type VM struct {
+	ctx      *Context
+	code     []byte
+	storage  Storage
+	gasLimit uint64
+	gasUsed  uint64
+
+	stack  [][]byte
+	pc     int
+	dests  map[int]bool
+	halted bool
+	ret    []byte
+	logs   []*Log
+}
+
+// NewVM returns a VM running the code against the storage of the contract, the code must have passed Validate
+func NewVM(ctx *Context, code []byte, storage Storage, gasLimit uint64) *VM {
+	return &VM{
+		ctx:      ctx,
+		code:     code,
+		storage:  storage,
+		gasLimit: gasLimit,
+		dests:    jumpDests(code),
+	}
+}
+
+// Execute runs the code from the start until it stops, returns or fails, and returns the item returned by the code
+// The writes to the storage are not undone on failure, hence the storage should buffer them until it succeeds.
+func (vm *VM) Execute() ([]byte, error) {
+	for vm.pc < len(vm.code) && !vm.halted {
+		op := vm.code[vm.pc]
+		info := opinfoArray[op]
+		if info.run == nil {
+			return nil, errors.Wrapf(ErrInvalidOpcode, "Opcode 0x%02x at %d", op, vm.pc)
+		}
+		if err := vm.useGas(info.gas); err != nil {
+			return nil, err
+		}
+		vm.pc++
+		if err := info.run(vm); err != nil {
+			if err == ErrReverted {
+				return vm.ret, err
+			}
+			return nil, errors.Wrapf(err, "%s at %d", info.name, vm.pc-1)
+		}
+	}
+	return vm.ret, nil
+}
+
+// GasUsed returns the gas consumed by the execution so far, which is the gas limit once it runs out of gas
+func (vm *VM) GasUsed() uint64 {
+	return vm.gasUsed
+}
+
+// Logs returns the logs emitted by the execution so far, which should be discarded if it fails
+func (vm *VM) Logs() []*Log {
+	return vm.logs
+}
+
+// useGas consumes the gas, or all the remaining gas and returns ErrOutOfGas if there is not enough
+func (vm *VM) useGas(gas uint64) error {
+	if vm.gasLimit-vm.gasUsed < gas {
+		vm.gasUsed = vm.gasLimit
+		return ErrOutOfGas
+	}
+	vm.gasUsed += gas
+	return nil
+}
+
+// push pushes the item onto the stack
+func (vm *VM) push(item []byte) error {
+	if len(vm.stack) >= MaxStackDepth {
+		return ErrStackOverflow
+	}
+	vm.stack = append(vm.stack, item)
+	return nil
+}
+
+// pop pops n items from the stack and returns them in the order they were pushed
+func (vm *VM) pop(n int) ([][]byte, error) {
+	if len(vm.stack) < n {
+		return nil, ErrStackUnderflow
+	}
+	items := vm.stack[len(vm.stack)-n:]
+	vm.stack = vm.stack[:len(vm.stack)-n]
+	return items, nil
+}
+
+// jump continues the execution from the position, which must be a OpJumpDest
+func (vm *VM) jump(item []byte) error {
+	pos, err := Uint64(item)
+	if err != nil {
+		return err
+	}
+	if pos >= uint64(len(vm.code)) || !vm.dests[int(pos)] {
+		return errors.Wrapf(ErrInvalidJump, "Position %d", pos)
+	}
+	vm.pc = int(pos)
+	return nil
+}
+
+// Validate checks the code only has defined opcodes and no truncated OpPush data, and is within MaxCodeSize
+func Validate(code []byte) error {
+	if len(code) == 0 || len(code) > MaxCodeSize {
+		return errors.Wrapf(ErrInvalidCode, "Code size %d", len(code))
+	}
+	for pc := 0; pc < len(code); pc++ {
+		op := code[pc]
+		if opinfoArray[op].run == nil {
+			return errors.Wrapf(ErrInvalidCode, "Opcode 0x%02x at %d", op, pc)
+		}
+		if op != OpPush {
+			continue
+		}
+		if pc+1 >= len(code) || pc+2+int(code[pc+1]) > len(code) {
+			return errors.Wrapf(ErrInvalidCode, "Truncated OpPush at %d", pc)
+		}
+		pc += 1 + int(code[pc+1])
+	}
+	return nil
+}
+
+// jumpDests returns the positions of the OpJumpDest instructions of the code, skipping the data of OpPush
+func jumpDests(code []byte) map[int]bool {
+	dests := map[int]bool{}
+	for pc := 0; pc < len(code); pc++ {
+		switch code[pc] {
+		case OpJumpDest:
+			dests[pc] = true
+		case OpPush:
+			if pc+1 < len(code) {
+				pc += 1 + int(code[pc+1])
+			}
+		}
+	}
+	return dests
+}
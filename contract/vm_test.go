@@ -0,0 +1,142 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package contract
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapStorage map[string][]byte
+
+func (s mapStorage) Get(key []byte) []byte {
+	return s[string(key)]
+}
+
+func (s mapStorage) Put(key []byte, value []byte) {
+	if len(value) == 0 {
+		delete(s, string(key))
+		return
+	}
+	s[string(key)] = value
+}
+
+// counterCode adds the first argument to the counter in storage and returns the new count
+func counterCode(t *testing.T) []byte {
+	code, err := NewCodeBuilder().
+		AddPush([]byte("count")).AddOps(OpSLoad).
+		AddPushUint64(0).AddOps(OpArg, OpAdd, OpDup).
+		AddPush([]byte("count")).AddOps(OpSStore, OpReturn).
+		Code()
+	assert.Nil(t, err)
+	return code
+}
+
+func TestVMExecute(t *testing.T) {
+	assert := assert.New(t)
+
+	code := counterCode(t)
+	storage := mapStorage{}
+	ctx := &Context{Caller: "alfa", Contract: "counter", Args: [][]byte{Uint64Bytes(5)}}
+	vm := NewVM(ctx, code, storage, 10000)
+	ret, err := vm.Execute()
+	assert.Nil(err)
+	assert.Equal(Uint64Bytes(5), ret)
+	assert.Equal(Uint64Bytes(5), storage["count"])
+	gasUsed := vm.GasUsed()
+	assert.True(gasUsed > gasSLoad+gasSStore)
+
+	ret, err = NewVM(ctx, code, storage, 10000).Execute()
+	assert.Nil(err)
+	assert.Equal(Uint64Bytes(10), ret)
+
+	// running out of gas consumes all of it
+	vm = NewVM(ctx, code, storage, gasUsed-1)
+	_, err = vm.Execute()
+	assert.Equal(ErrOutOfGas, errors.Cause(err))
+	assert.Equal(gasUsed-1, vm.GasUsed())
+
+	// an argument longer than an integer
+	ctx.Args = [][]byte{make([]byte, 9)}
+	_, err = NewVM(ctx, code, storage, 10000).Execute()
+	assert.Equal(ErrInvalidOperand, errors.Cause(err))
+}
+
+func TestVMJump(t *testing.T) {
+	assert := assert.New(t)
+
+	// reverts unless the caller is the owner
+	b := NewCodeBuilder().AddOps(OpCaller).AddPush([]byte("owner")).AddOps(OpEq)
+	b.AddPushUint64(uint64(b.Len() + 10 + 1 + 10 + 1)).AddOps(OpJumpI)
+	b.AddPushUint64(0).AddOps(OpRevert)
+	b.AddOps(OpJumpDest).AddPushUint64(1).AddOps(OpReturn)
+	code, err := b.Code()
+	assert.Nil(err)
+
+	ret, err := NewVM(&Context{Caller: "owner"}, code, mapStorage{}, 1000).Execute()
+	assert.Nil(err)
+	assert.Equal(Uint64Bytes(1), ret)
+	ret, err = NewVM(&Context{Caller: "other"}, code, mapStorage{}, 1000).Execute()
+	assert.Equal(ErrReverted, err)
+	assert.Equal(Uint64Bytes(0), ret)
+
+	// jumping into the data of a push
+	code, err = NewCodeBuilder().AddPush([]byte{OpJumpDest}).AddPushUint64(2).AddOps(OpJump).Code()
+	assert.Nil(err)
+	_, err = NewVM(&Context{}, code, mapStorage{}, 1000).Execute()
+	assert.Equal(ErrInvalidJump, errors.Cause(err))
+
+	// an infinite loop runs out of gas
+	code, err = NewCodeBuilder().AddOps(OpJumpDest).AddPushUint64(0).AddOps(OpJump).Code()
+	assert.Nil(err)
+	_, err = NewVM(&Context{}, code, mapStorage{}, 1000).Execute()
+	assert.Equal(ErrOutOfGas, errors.Cause(err))
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(ErrInvalidCode, errors.Cause(Validate(nil)))
+	assert.Equal(ErrInvalidCode, errors.Cause(Validate([]byte{0xee})))
+	assert.Equal(ErrInvalidCode, errors.Cause(Validate([]byte{OpPush})))
+	assert.Equal(ErrInvalidCode, errors.Cause(Validate([]byte{OpPush, 2, 0x01})))
+	assert.Nil(Validate([]byte{OpPush, 2, 0x01, 0x02}))
+	assert.Equal(ErrInvalidCode, errors.Cause(Validate(make([]byte, MaxCodeSize+1))))
+	_, err := NewCodeBuilder().AddPush(nil).Code()
+	assert.Equal(ErrInvalidCode, errors.Cause(err))
+
+	// stack underflow
+	_, err = NewVM(&Context{}, []byte{OpAdd}, mapStorage{}, 1000).Execute()
+	assert.Equal(ErrStackUnderflow, errors.Cause(err))
+}
+
+func TestVMLog(t *testing.T) {
+	assert := assert.New(t)
+
+	code, err := NewCodeBuilder().
+		AddOps(OpCaller).AddPush([]byte("transfer")).AddPushUint64(0).AddOps(OpArg, OpLog2).
+		AddPush([]byte("data")).AddOps(OpLog0, OpStop).
+		Code()
+	assert.Nil(err)
+	ctx := &Context{Caller: "alfa", Contract: "token", Args: [][]byte{Uint64Bytes(7)}}
+	vm := NewVM(ctx, code, mapStorage{}, 10000)
+	_, err = vm.Execute()
+	assert.Nil(err)
+	logs := vm.Logs()
+	assert.Equal(2, len(logs))
+	assert.Equal(&Log{Address: "token", Topics: [][]byte{[]byte("alfa"), []byte("transfer")}, Data: Uint64Bytes(7)}, logs[0])
+	assert.Equal(&Log{Address: "token", Data: []byte("data")}, logs[1])
+	assert.True(vm.GasUsed() > 2*gasLog+2*gasLogTopic)
+
+	// not enough topics on the stack
+	code, err = NewCodeBuilder().AddPush([]byte("data")).AddOps(OpLog1).Code()
+	assert.Nil(err)
+	_, err = NewVM(ctx, code, mapStorage{}, 10000).Execute()
+	assert.Equal(ErrStackUnderflow, errors.Cause(err))
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// fieldElement is an element of the field of the P-256 curve in Montgomery form x*2^256 mod p, as little-endian
+// 64-bit limbs, which multiplies an order of magnitude faster than big.Int
+type fieldElement [4]uint64
+
+var (
+	// fieldPrime is p = 2^256 - 2^224 + 2^192 + 2^96 - 1
+	fieldPrime = fieldElement{0xffffffffffffffff, 0x00000000ffffffff, 0x0000000000000000, 0xffffffff00000001}
+	// fieldOne is 1 in Montgomery form
+	fieldOne = newFieldElement(big.NewInt(1))
+)
+
+// newFieldElement returns the field element of x, which has to be less than p
+func newFieldElement(x *big.Int) fieldElement {
+	mont := new(big.Int).Lsh(x, 256)
+	mont.Mod(mont, curve.Params().P)
+	var e fieldElement
+	for i, word := range mont.Bits() {
+		// big.Word is 64 bits on the supported platforms
+		e[i] = uint64(word)
+	}
+	return e
+}
+
+// toBig returns the value of the field element out of Montgomery form
+func (e *fieldElement) toBig() *big.Int {
+	var v fieldElement
+	v.mul(e, &fieldElement{1})
+	x := new(big.Int)
+	for i := len(v) - 1; i >= 0; i-- {
+		x.Lsh(x, 64).Or(x, new(big.Int).SetUint64(v[i]))
+	}
+	return x
+}
+
+func (e *fieldElement) isZero() bool {
+	return e[0]|e[1]|e[2]|e[3] == 0
+}
+
+// add sets e = a + b mod p
+func (e *fieldElement) add(a, b *fieldElement) {
+	var carry uint64
+	var sum fieldElement
+	for i := range sum {
+		sum[i], carry = bits.Add64(a[i], b[i], carry)
+	}
+	e.reduce(&sum, carry)
+}
+
+// sub sets e = a - b mod p
+func (e *fieldElement) sub(a, b *fieldElement) {
+	var borrow uint64
+	var diff fieldElement
+	for i := range diff {
+		diff[i], borrow = bits.Sub64(a[i], b[i], borrow)
+	}
+	// add p back on borrow
+	mask := -borrow
+	var carry uint64
+	for i := range diff {
+		diff[i], carry = bits.Add64(diff[i], fieldPrime[i]&mask, carry)
+	}
+	*e = diff
+}
+
+// mul sets e = a * b / 2^256 mod p with the Montgomery multiplication, -p^-1 mod 2^64 being 1
+func (e *fieldElement) mul(a, b *fieldElement) {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			carry, t[j] = mulAdd(a[j], b[i], t[j], carry)
+		}
+		t[4], t[5] = bits.Add64(t[4], carry, 0)
+
+		m := t[0]
+		carry, _ = mulAdd(m, fieldPrime[0], t[0], 0)
+		for j := 1; j < 4; j++ {
+			carry, t[j-1] = mulAdd(m, fieldPrime[j], t[j], carry)
+		}
+		var c uint64
+		t[3], c = bits.Add64(t[4], carry, 0)
+		t[4] = t[5] + c
+	}
+	e.reduce(&fieldElement{t[0], t[1], t[2], t[3]}, t[4])
+}
+
+// reduce sets e = x mod p for x = carry*2^256 + x less than 2p
+func (e *fieldElement) reduce(x *fieldElement, carry uint64) {
+	var borrow uint64
+	var diff fieldElement
+	for i := range diff {
+		diff[i], borrow = bits.Sub64(x[i], fieldPrime[i], borrow)
+	}
+	// keep x if it is less than p
+	_, borrow = bits.Sub64(carry, 0, borrow)
+	mask := -borrow
+	for i := range e {
+		e[i] = x[i]&mask | diff[i]&^mask
+	}
+}
+
+// mulAdd returns the high and low words of a*b + c + carry, which cannot overflow 128 bits
+func mulAdd(a, b, c, carry uint64) (uint64, uint64) {
+	hi, lo := bits.Mul64(a, b)
+	var cc uint64
+	lo, cc = bits.Add64(lo, c, 0)
+	hi += cc
+	lo, cc = bits.Add64(lo, carry, 0)
+	hi += cc
+	return hi, lo
+}
@@ -7,11 +7,13 @@
 package crypto
 
 import (
-	"github.com/golang/glog"
-
 	"golang.org/x/crypto/blake2b"
+
+	"github.com/iotexproject/iotex-core/logger"
 )
 
+var log = logger.New("crypto")
+
 // HashSize defines the size of hash
 const (
 	HashSize = 32
@@ -36,7 +38,7 @@ type Merkle struct {
 func NewMerkleTree(leaves []Hash32B) *Merkle {
 	size := len(leaves)
 	if size == 0 {
-		glog.Warning("Try to create merkle tree with empty leaf list!")
+		log.Warning("Try to create merkle tree with empty leaf list!")
 		return nil
 	}
 
@@ -97,3 +99,59 @@ func (mk *Merkle) HashTree() Hash32B {
 	mk.root = merkle[0]
 	return mk.root
 }
+
+// MerkleProof is the proof of a leaf's inclusion in a merkle tree, it consists of the leaf's index and the hashes of
+// the siblings on the path from the leaf up to the root
+type MerkleProof struct {
+	Index    uint32
+	Siblings []Hash32B
+}
+
+// Proof returns the merkle proof of the leaf at the given index, or nil if the index is out of range
+func (mk *Merkle) Proof(index uint32) *MerkleProof {
+	if int(index) >= mk.size {
+		return nil
+	}
+
+	proof := &MerkleProof{Index: index}
+	level := make([]Hash32B, mk.size)
+	copy(level, mk.leaf[:mk.size])
+	for len(level) > 1 {
+		// copy the last hash if the level has odd number of hashes, same as HashTree
+		if len(level)&1 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+		proof.Siblings = append(proof.Siblings, level[index^1])
+
+		for i := 0; i < len(level)>>1; i++ {
+			level[i] = hashPair(level[i<<1], level[i<<1+1])
+		}
+		level = level[0 : len(level)>>1]
+		index >>= 1
+	}
+	return proof
+}
+
+// VerifyMerkleProof checks the leaf with the proof leads to the given merkle root
+func VerifyMerkleProof(leaf Hash32B, proof *MerkleProof, root Hash32B) bool {
+	if proof == nil {
+		return false
+	}
+	hash, index := leaf, proof.Index
+	for _, sibling := range proof.Siblings {
+		if index&1 == 0 {
+			hash = hashPair(hash, sibling)
+		} else {
+			hash = hashPair(sibling, hash)
+		}
+		index >>= 1
+	}
+	return index == 0 && hash == root
+}
+
+// hashPair returns the hash of two concatenated child hashes
+func hashPair(left, right Hash32B) Hash32B {
+	h := left[:]
+	h = append(h, right[:]...)
+	return blake2b.Sum256(h)
+}
@@ -67,3 +67,31 @@ func TestMerkleTree(t *testing.T) {
 	assert.Equal(t, 0, bytes.Compare(expected[:], actual5[:]))
 	assert.Equal(t, -1, bytes.Compare(actual5[:], actual4[:]))
 }
+
+func TestMerkleProof(t *testing.T) {
+	var inputs []Hash32B
+	inputs = append(inputs, decodeHash("aeedd06eb44f08abbcc72a2293aff580f13662fa59cc1b0aa4a15ee7c118e4eb"))
+	inputs = append(inputs, decodeHash("9de6306b08158c423330f7a27243a1a5cbe39bfd764f07818437882d21241567"))
+	inputs = append(inputs, decodeHash("7959228bfdb316949973c08d8bb7bea2a21227a7b4ed85c35d247bf3d6b15a11"))
+	inputs = append(inputs, decodeHash("6368616e676520746869732070617373776f726420746f206120736563726574"))
+	inputs = append(inputs, decodeHash("0bbcd13e801fdf4d70c62b3788173edaf52113e7bfca4603eb5d486f4a011411"))
+
+	for size := 1; size <= len(inputs); size++ {
+		m := NewMerkleTree(inputs[:size])
+		root := m.HashTree()
+		for i := 0; i < size; i++ {
+			proof := m.Proof(uint32(i))
+			assert.True(t, VerifyMerkleProof(inputs[i], proof, root))
+			// the proof does not apply to other leaves
+			assert.False(t, VerifyMerkleProof(inputs[(i+1)%len(inputs)], proof, root))
+		}
+		assert.Nil(t, m.Proof(uint32(size+1)))
+	}
+
+	// alter the index of the proof
+	m := NewMerkleTree(inputs)
+	proof := m.Proof(1)
+	proof.Index = 0
+	assert.False(t, VerifyMerkleProof(inputs[1], proof, m.HashTree()))
+	assert.False(t, VerifyMerkleProof(inputs[1], nil, m.HashTree()))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"math/big"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// muHashBytes is the size of the elements of the group MultisetHash works in
+const muHashBytes = 384
+
+// muHashPrime is the 3072-bit prime 2^3072 - 1103717, the modulus of the group
+var muHashPrime = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 3072), big.NewInt(1103717))
+
+// MultisetHash is a rolling hash of a multiset of byte strings
+//
+// Each element is hashed to a number of the multiplicative group modulo a 3072-bit prime, and the hash of the set is
+// the product of the numbers of its elements (MuHash). Adding or removing an element costs one modular
+// multiplication regardless of the size of the set, and the same set hashes the same whichever order it is built in.
+// The removed elements are multiplied into a separate denominator, so the costly inversion only happens in Sum.
+type MultisetHash struct {
+	numerator   *big.Int
+	denominator *big.Int
+}
+
+// NewMultisetHash returns the hash of the empty set
+func NewMultisetHash() *MultisetHash {
+	return &MultisetHash{numerator: big.NewInt(1), denominator: big.NewInt(1)}
+}
+
+// Clone returns a copy of the hash, which is updated independently
+func (h *MultisetHash) Clone() *MultisetHash {
+	return &MultisetHash{numerator: new(big.Int).Set(h.numerator), denominator: new(big.Int).Set(h.denominator)}
+}
+
+// Add adds the element to the set
+func (h *MultisetHash) Add(data []byte) {
+	h.numerator.Mod(h.numerator.Mul(h.numerator, muHashElement(data)), muHashPrime)
+}
+
+// Remove removes the element from the set, which has to be in the set for the hash to be meaningful
+func (h *MultisetHash) Remove(data []byte) {
+	h.denominator.Mod(h.denominator.Mul(h.denominator, muHashElement(data)), muHashPrime)
+}
+
+// Sum returns the 32-byte digest of the set
+func (h *MultisetHash) Sum() Hash32B {
+	value := new(big.Int).ModInverse(h.denominator, muHashPrime)
+	value.Mod(value.Mul(value, h.numerator), muHashPrime)
+	buf := make([]byte, muHashBytes)
+	b := value.Bytes()
+	copy(buf[muHashBytes-len(b):], b)
+	return blake2b.Sum256(buf)
+}
+
+// muHashElement maps the data to a number of the group, expanding its hash to 3072 bits
+func muHashElement(data []byte) *big.Int {
+	digest := blake2b.Sum256(data)
+	buf := make([]byte, 0, muHashBytes)
+	for i := byte(0); len(buf) < muHashBytes; i++ {
+		block := blake2b.Sum512(append([]byte{i}, digest[:]...))
+		buf = append(buf, block[:]...)
+	}
+	x := new(big.Int).SetBytes(buf[:muHashBytes])
+	return x.Mod(x, muHashPrime)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultisetHash(t *testing.T) {
+	assert := assert.New(t)
+
+	empty := NewMultisetHash().Sum()
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+
+	h1 := NewMultisetHash()
+	h1.Add(a)
+	h1.Add(b)
+	h1.Add(c)
+	h2 := NewMultisetHash()
+	h2.Add(c)
+	h2.Add(a)
+	h2.Add(b)
+	assert.Equal(h1.Sum(), h2.Sum())
+	assert.NotEqual(empty, h1.Sum())
+
+	// removing an element gives the hash of the set without it, in any order
+	clone := h1.Clone()
+	clone.Remove(b)
+	assert.NotEqual(h1.Sum(), clone.Sum())
+	h3 := NewMultisetHash()
+	h3.Remove(b)
+	h3.Add(a)
+	h3.Add(b)
+	h3.Add(c)
+	assert.Equal(clone.Sum(), h3.Sum())
+	clone.Remove(a)
+	clone.Remove(c)
+	assert.Equal(empty, clone.Sum())
+
+	// it is a multiset
+	h1.Add(a)
+	assert.NotEqual(h2.Sum(), h1.Sum())
+	h1.Remove(a)
+	assert.Equal(h2.Sum(), h1.Sum())
+}
@@ -0,0 +1,502 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Schnorr signatures are over the NIST P-256 curve, with the public key and the nonce point R in compressed form and
+// the signature being R followed by the 32-byte scalar s, such that s*G = R + H(R || P || msg)*P
+// Unlike ed25519 signatures, they can be verified in batch, and the keys of cosigners aggregate into a single key
+// whose signature is produced jointly without any of them knowing the aggregated private key.
+const (
+	// SchnorrPubKeySize is the size of a compressed Schnorr public key
+	SchnorrPubKeySize = 33
+	// SchnorrPrivKeySize is the size of a Schnorr private key
+	SchnorrPrivKeySize = 32
+	// SchnorrSignatureSize is the size of a Schnorr signature
+	SchnorrSignatureSize = SchnorrPubKeySize + 32
+)
+
+// ErrInvalidSchnorrKey is returned when a Schnorr key, nonce or signature is malformed
+var ErrInvalidSchnorrKey = errors.New("invalid Schnorr key")
+
+var curve = elliptic.P256()
+
+// NewSchnorrKeyPair returns a new Schnorr public and private key pair
+func NewSchnorrKeyPair() ([]byte, []byte, error) {
+	priv, x, y, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return elliptic.MarshalCompressed(curve, x, y), priv, nil
+}
+
+// SchnorrPubKey returns the public key of the Schnorr private key
+func SchnorrPubKey(priv []byte) ([]byte, error) {
+	if len(priv) != SchnorrPrivKeySize {
+		return nil, ErrInvalidSchnorrKey
+	}
+	x, y := curve.ScalarBaseMult(priv)
+	return elliptic.MarshalCompressed(curve, x, y), nil
+}
+
+// SchnorrSign signs the message with the Schnorr private key, the nonce is derived from the key and the message
+func SchnorrSign(priv []byte, msg []byte) ([]byte, error) {
+	pub, err := SchnorrPubKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	nonce := blake2b.Sum512(append(append([]byte{}, priv...), msg...))
+	k := new(big.Int).Mod(new(big.Int).SetBytes(nonce[:]), curve.Params().N)
+	rx, ry := curve.ScalarBaseMult(k.Bytes())
+	r := elliptic.MarshalCompressed(curve, rx, ry)
+	e := schnorrChallenge(r, pub, msg)
+	s := e.Mul(e, new(big.Int).SetBytes(priv))
+	s.Add(s, k).Mod(s, curve.Params().N)
+	return append(r, scalarBytes(s)...), nil
+}
+
+// SchnorrVerify returns true if the signature of the message is valid for the Schnorr public key
+func SchnorrVerify(pub []byte, msg []byte, sig []byte) bool {
+	px, py, rx, ry, s, ok := parseSchnorr(pub, sig)
+	if !ok {
+		return false
+	}
+	e := schnorrChallenge(sig[:SchnorrPubKeySize], pub, msg)
+	ex, ey := curve.ScalarMult(px, py, e.Bytes())
+	x, y := curve.Add(rx, ry, ex, ey)
+	sx, sy := curve.ScalarBaseMult(s.Bytes())
+	return x.Cmp(sx) == 0 && y.Cmp(sy) == 0
+}
+
+// SchnorrBatch collects Schnorr signatures to verify them at once, it is safe for concurrent use
+type SchnorrBatch struct {
+	mu     sync.Mutex
+	points []*jacobianPoint
+	coeffs []*big.Int
+	s      *big.Int
+	failed bool
+}
+
+// NewSchnorrBatch returns an empty batch of Schnorr signatures
+func NewSchnorrBatch() *SchnorrBatch {
+	return &SchnorrBatch{s: new(big.Int)}
+}
+
+// Add adds the signature of the message by the public key to the batch
+func (b *SchnorrBatch) Add(pub []byte, msg []byte, sig []byte) {
+	px, py, rx, ry, s, ok := parseSchnorr(pub, sig)
+	var a, e *big.Int
+	if ok {
+		// the random weight keeps invalid signatures from canceling each other out
+		weight := make([]byte, 16)
+		_, err := rand.Read(weight)
+		ok = err == nil
+		a = new(big.Int).SetBytes(weight)
+		e = schnorrChallenge(sig[:SchnorrPubKeySize], pub, msg)
+		e.Mul(e, a).Mod(e, curve.Params().N)
+		s.Mul(s, a)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !ok {
+		b.failed = true
+		return
+	}
+	b.points = append(b.points, newJacobianPoint(rx, ry), newJacobianPoint(px, py))
+	b.coeffs = append(b.coeffs, a, e)
+	b.s.Add(b.s, s).Mod(b.s, curve.Params().N)
+}
+
+// Len returns the number of signatures in the batch
+func (b *SchnorrBatch) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.points) / 2
+}
+
+// Verify returns true if all signatures of the batch are valid, checking the sum of the random multiples of their
+// equations s*G = R + e*P with a single multi-scalar multiplication
+func (b *SchnorrBatch) Verify() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failed {
+		return false
+	}
+	if len(b.points) == 0 {
+		return true
+	}
+	params := curve.Params()
+	points := append(b.points, newJacobianPoint(params.Gx, params.Gy))
+	coeffs := append(b.coeffs, new(big.Int).Sub(params.N, b.s))
+	return multiScalarMult(points, coeffs).isInfinity()
+}
+
+// AggregateSchnorrPubKeys returns the key aggregated from the Schnorr public keys of the cosigners, each weighted by
+// the hash of all keys to keep a cosigner from canceling the others' keys, the order of the keys matters
+func AggregateSchnorrPubKeys(pubkeys [][]byte) ([]byte, error) {
+	points := make([]*jacobianPoint, len(pubkeys))
+	coeffs := make([]*big.Int, len(pubkeys))
+	for i, pub := range pubkeys {
+		x, y := elliptic.UnmarshalCompressed(curve, pub)
+		if x == nil {
+			return nil, ErrInvalidSchnorrKey
+		}
+		points[i] = newJacobianPoint(x, y)
+		coeffs[i] = keyAggCoeff(pubkeys, pub)
+	}
+	return compressPoint(multiScalarMult(points, coeffs))
+}
+
+// NewSchnorrNonce returns the secret and public nonce of a cosigner for a joint signature
+// The public nonces are only to be exchanged after all cosigners have committed to theirs, and a nonce must never be
+// used for more than one signature.
+func NewSchnorrNonce() ([]byte, []byte, error) {
+	public, secret, err := NewSchnorrKeyPair()
+	return secret, public, err
+}
+
+// AggregateSchnorrNonces returns the nonce point R of the joint signature from the public nonces of the cosigners
+func AggregateSchnorrNonces(nonces [][]byte) ([]byte, error) {
+	sum := &jacobianPoint{}
+	for _, nonce := range nonces {
+		x, y := elliptic.UnmarshalCompressed(curve, nonce)
+		if x == nil {
+			return nil, ErrInvalidSchnorrKey
+		}
+		sum = sum.add(newJacobianPoint(x, y))
+	}
+	return compressPoint(sum)
+}
+
+// SchnorrPartialSign returns the partial signature of the message by a cosigner of the keys, with its private key,
+// its secret nonce and the aggregated nonce
+func SchnorrPartialSign(priv []byte, pubkeys [][]byte, secretNonce []byte, aggNonce []byte, msg []byte) ([]byte, error) {
+	pub, err := SchnorrPubKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	aggPub, err := AggregateSchnorrPubKeys(pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(secretNonce) != SchnorrPrivKeySize {
+		return nil, ErrInvalidSchnorrKey
+	}
+	n := curve.Params().N
+	s := schnorrChallenge(aggNonce, aggPub, msg)
+	s.Mul(s, keyAggCoeff(pubkeys, pub)).Mul(s, new(big.Int).SetBytes(priv))
+	s.Add(s, new(big.Int).SetBytes(secretNonce)).Mod(s, n)
+	return scalarBytes(s), nil
+}
+
+// AggregateSchnorrSignatures returns the joint signature valid for the aggregated key from the partial signatures of
+// all cosigners
+func AggregateSchnorrSignatures(aggNonce []byte, partials [][]byte) ([]byte, error) {
+	if len(aggNonce) != SchnorrPubKeySize {
+		return nil, ErrInvalidSchnorrKey
+	}
+	s := new(big.Int)
+	for _, partial := range partials {
+		if len(partial) != SchnorrPrivKeySize {
+			return nil, ErrInvalidSchnorrKey
+		}
+		s.Add(s, new(big.Int).SetBytes(partial))
+	}
+	s.Mod(s, curve.Params().N)
+	return append(append([]byte{}, aggNonce...), scalarBytes(s)...), nil
+}
+
+// schnorrChallenge returns the challenge e = H(R || P || msg) of the signature
+func schnorrChallenge(r []byte, pub []byte, msg []byte) *big.Int {
+	hash := blake2b.Sum256(append(append(append([]byte{}, r...), pub...), msg...))
+	return new(big.Int).Mod(new(big.Int).SetBytes(hash[:]), curve.Params().N)
+}
+
+// keyAggCoeff returns the weight of the key in the aggregated key of all keys
+func keyAggCoeff(pubkeys [][]byte, pub []byte) *big.Int {
+	all := []byte{}
+	for _, key := range pubkeys {
+		all = append(all, key...)
+	}
+	l := blake2b.Sum256(all)
+	hash := blake2b.Sum256(append(l[:], pub...))
+	return new(big.Int).Mod(new(big.Int).SetBytes(hash[:]), curve.Params().N)
+}
+
+// parseSchnorr decodes the public key, the nonce point R and the scalar s of the signature
+func parseSchnorr(pub []byte, sig []byte) (*big.Int, *big.Int, *big.Int, *big.Int, *big.Int, bool) {
+	if len(pub) != SchnorrPubKeySize || len(sig) != SchnorrSignatureSize {
+		return nil, nil, nil, nil, nil, false
+	}
+	px, py := elliptic.UnmarshalCompressed(curve, pub)
+	rx, ry := elliptic.UnmarshalCompressed(curve, sig[:SchnorrPubKeySize])
+	s := new(big.Int).SetBytes(sig[SchnorrPubKeySize:])
+	if px == nil || rx == nil || s.Cmp(curve.Params().N) >= 0 {
+		return nil, nil, nil, nil, nil, false
+	}
+	return px, py, rx, ry, s, true
+}
+
+// scalarBytes returns the 32-byte big-endian encoding of the scalar
+func scalarBytes(s *big.Int) []byte {
+	buf := make([]byte, 32)
+	return s.FillBytes(buf)
+}
+
+// compressPoint returns the compressed form of the point, which cannot be the point at infinity
+func compressPoint(p *jacobianPoint) ([]byte, error) {
+	x, y := p.affine()
+	if x == nil {
+		return nil, ErrInvalidSchnorrKey
+	}
+	return elliptic.MarshalCompressed(curve, x, y), nil
+}
+
+// jacobianPoint is a point of the curve in Jacobian coordinates (X/Z^2, Y/Z^3), Z = 0 at infinity, which adds points
+// without a modular inversion each time
+type jacobianPoint struct {
+	x, y, z fieldElement
+}
+
+func newJacobianPoint(x *big.Int, y *big.Int) *jacobianPoint {
+	return &jacobianPoint{x: newFieldElement(x), y: newFieldElement(y), z: fieldOne}
+}
+
+func (p *jacobianPoint) isInfinity() bool {
+	return p.z.isZero()
+}
+
+// affine returns the affine coordinates of the point, nil at infinity
+func (p *jacobianPoint) affine() (*big.Int, *big.Int) {
+	if p.isInfinity() {
+		return nil, nil
+	}
+	prime := curve.Params().P
+	zinv := new(big.Int).ModInverse(p.z.toBig(), prime)
+	zinv2 := new(big.Int).Mul(zinv, zinv)
+	x := new(big.Int).Mul(p.x.toBig(), zinv2)
+	y := new(big.Int).Mul(p.y.toBig(), zinv2.Mul(zinv2, zinv))
+	return x.Mod(x, prime), y.Mod(y, prime)
+}
+
+// double returns 2p, with the formulas for a = -3
+func (p *jacobianPoint) double() *jacobianPoint {
+	if p.isInfinity() || p.y.isZero() {
+		return &jacobianPoint{}
+	}
+	var delta, gamma, beta, alpha, t fieldElement
+	delta.mul(&p.z, &p.z)
+	gamma.mul(&p.y, &p.y)
+	beta.mul(&p.x, &gamma)
+	alpha.sub(&p.x, &delta)
+	t.add(&p.x, &delta)
+	alpha.mul(&alpha, &t)
+	t.add(&alpha, &alpha)
+	alpha.add(&alpha, &t)
+
+	r := &jacobianPoint{}
+	// X3 = alpha^2 - 8*beta
+	r.x.mul(&alpha, &alpha)
+	t.add(&beta, &beta)
+	t.add(&t, &t)
+	beta = t
+	t.add(&t, &t)
+	r.x.sub(&r.x, &t)
+	// Z3 = (Y1 + Z1)^2 - gamma - delta
+	r.z.add(&p.y, &p.z)
+	r.z.mul(&r.z, &r.z)
+	r.z.sub(&r.z, &gamma)
+	r.z.sub(&r.z, &delta)
+	// Y3 = alpha*(4*beta - X3) - 8*gamma^2
+	r.y.sub(&beta, &r.x)
+	r.y.mul(&r.y, &alpha)
+	gamma.mul(&gamma, &gamma)
+	gamma.add(&gamma, &gamma)
+	gamma.add(&gamma, &gamma)
+	gamma.add(&gamma, &gamma)
+	r.y.sub(&r.y, &gamma)
+	return r
+}
+
+// negate returns -p
+func (p *jacobianPoint) negate() *jacobianPoint {
+	r := *p
+	r.y.sub(&fieldElement{}, &p.y)
+	return &r
+}
+
+// add returns p + q
+func (p *jacobianPoint) add(q *jacobianPoint) *jacobianPoint {
+	if p.isInfinity() {
+		return q
+	}
+	if q.isInfinity() {
+		return p
+	}
+	if q.z == fieldOne {
+		return p.addAffine(q)
+	}
+	var z1z1, z2z2, u1, u2, s1, s2, h, r, i, j, v fieldElement
+	z1z1.mul(&p.z, &p.z)
+	z2z2.mul(&q.z, &q.z)
+	u1.mul(&p.x, &z2z2)
+	u2.mul(&q.x, &z1z1)
+	s1.mul(&p.y, &q.z)
+	s1.mul(&s1, &z2z2)
+	s2.mul(&q.y, &p.z)
+	s2.mul(&s2, &z1z1)
+	h.sub(&u2, &u1)
+	r.sub(&s2, &s1)
+	r.add(&r, &r)
+	if h.isZero() {
+		if r.isZero() {
+			return p.double()
+		}
+		return &jacobianPoint{}
+	}
+
+	i.add(&h, &h)
+	i.mul(&i, &i)
+	j.mul(&h, &i)
+	v.mul(&u1, &i)
+	res := &jacobianPoint{}
+	// X3 = r^2 - J - 2*V
+	res.x.mul(&r, &r)
+	res.x.sub(&res.x, &j)
+	res.x.sub(&res.x, &v)
+	res.x.sub(&res.x, &v)
+	// Y3 = r*(V - X3) - 2*S1*J
+	res.y.sub(&v, &res.x)
+	res.y.mul(&res.y, &r)
+	s1.mul(&s1, &j)
+	s1.add(&s1, &s1)
+	res.y.sub(&res.y, &s1)
+	// Z3 = ((Z1 + Z2)^2 - Z1Z1 - Z2Z2)*H
+	res.z.add(&p.z, &q.z)
+	res.z.mul(&res.z, &res.z)
+	res.z.sub(&res.z, &z1z1)
+	res.z.sub(&res.z, &z2z2)
+	res.z.mul(&res.z, &h)
+	return res
+}
+
+// addAffine returns p + q for q with Z = 1, which saves a third of the multiplications of add
+func (p *jacobianPoint) addAffine(q *jacobianPoint) *jacobianPoint {
+	var z1z1, u2, s2, h, r, i, j, v, t fieldElement
+	z1z1.mul(&p.z, &p.z)
+	u2.mul(&q.x, &z1z1)
+	s2.mul(&q.y, &p.z)
+	s2.mul(&s2, &z1z1)
+	h.sub(&u2, &p.x)
+	r.sub(&s2, &p.y)
+	r.add(&r, &r)
+	if h.isZero() {
+		if r.isZero() {
+			return p.double()
+		}
+		return &jacobianPoint{}
+	}
+
+	i.add(&h, &h)
+	i.mul(&i, &i)
+	j.mul(&h, &i)
+	v.mul(&p.x, &i)
+	res := &jacobianPoint{}
+	// X3 = r^2 - J - 2*V
+	res.x.mul(&r, &r)
+	res.x.sub(&res.x, &j)
+	res.x.sub(&res.x, &v)
+	res.x.sub(&res.x, &v)
+	// Y3 = r*(V - X3) - 2*Y1*J
+	res.y.sub(&v, &res.x)
+	res.y.mul(&res.y, &r)
+	t.mul(&p.y, &j)
+	t.add(&t, &t)
+	res.y.sub(&res.y, &t)
+	// Z3 = 2*Z1*H
+	res.z.mul(&p.z, &h)
+	res.z.add(&res.z, &res.z)
+	return res
+}
+
+// multiScalarMult returns the sum of the points multiplied by their scalars, with the bucket method of Pippenger which
+// shares the doublings among all points and only adds each point once per window
+func multiScalarMult(points []*jacobianPoint, scalars []*big.Int) *jacobianPoint {
+	// the window grows with the number of points, roughly balancing the additions of points and of buckets
+	c := 2
+	for c < 12 && 1<<uint(c+1) < len(points) {
+		c++
+	}
+	digits := make([][]int, len(scalars))
+	negated := make([]*jacobianPoint, len(points))
+	for i, k := range scalars {
+		digits[i] = signedDigits(k, c)
+		negated[i] = points[i].negate()
+	}
+	sum := &jacobianPoint{}
+	for w := len(digits[0]) - 1; w >= 0; w-- {
+		for i := 0; i < c; i++ {
+			sum = sum.double()
+		}
+		// the digits are in [-2^(c-1), 2^(c-1)], a negative digit adds the negated point to the bucket of its magnitude
+		buckets := make([]*jacobianPoint, 1<<uint(c-1)+1)
+		for i := range scalars {
+			digit, point := digits[i][w], points[i]
+			if digit < 0 {
+				digit, point = -digit, negated[i]
+			}
+			if digit == 0 {
+				continue
+			}
+			if buckets[digit] == nil {
+				buckets[digit] = point
+			} else {
+				buckets[digit] = buckets[digit].add(point)
+			}
+		}
+		// the running sum adds each bucket as many times as its digit
+		running, window := &jacobianPoint{}, &jacobianPoint{}
+		for digit := len(buckets) - 1; digit > 0; digit-- {
+			if buckets[digit] != nil {
+				running = running.add(buckets[digit])
+			}
+			window = window.add(running)
+		}
+		sum = sum.add(window)
+	}
+	return sum
+}
+
+// signedDigits returns the digits of the scalar in base 2^c, least significant first, each in [-2^(c-1), 2^(c-1)]
+func signedDigits(k *big.Int, c int) []int {
+	bits := curve.Params().N.BitLen()
+	digits := make([]int, bits/c+1)
+	carry := 0
+	for w := range digits {
+		digit := carry
+		for j := c - 1; j >= 0; j-- {
+			digit += int(k.Bit(w*c+j)) << uint(j)
+		}
+		carry = 0
+		if digit > 1<<uint(c-1) {
+			digit -= 1 << uint(c)
+			carry = 1
+		}
+		digits[w] = digit
+	}
+	return digits
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchnorrSignVerify(t *testing.T) {
+	assert := assert.New(t)
+	pub, priv, err := NewSchnorrKeyPair()
+	assert.Nil(err)
+	assert.Equal(SchnorrPubKeySize, len(pub))
+	derived, err := SchnorrPubKey(priv)
+	assert.Nil(err)
+	assert.Equal(pub, derived)
+
+	message := []byte("hello iotex message")
+	sig, err := SchnorrSign(priv, message)
+	assert.Nil(err)
+	assert.Equal(SchnorrSignatureSize, len(sig))
+	assert.True(SchnorrVerify(pub, message, sig))
+	assert.False(SchnorrVerify(pub, []byte("wrong message"), sig))
+	other, _, err := NewSchnorrKeyPair()
+	assert.Nil(err)
+	assert.False(SchnorrVerify(other, message, sig))
+	sig[len(sig)-1] ^= 0x01
+	assert.False(SchnorrVerify(pub, message, sig))
+	assert.False(SchnorrVerify(pub, message, sig[1:]))
+}
+
+func TestSchnorrBatchVerify(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(NewSchnorrBatch().Verify())
+
+	batch := NewSchnorrBatch()
+	var pubs, msgs, sigs [][]byte
+	for i := 0; i < 20; i++ {
+		pub, priv, err := NewSchnorrKeyPair()
+		assert.Nil(err)
+		msg := []byte(fmt.Sprintf("message %d", i))
+		sig, err := SchnorrSign(priv, msg)
+		assert.Nil(err)
+		batch.Add(pub, msg, sig)
+		pubs, msgs, sigs = append(pubs, pub), append(msgs, msg), append(sigs, sig)
+	}
+	assert.Equal(20, batch.Len())
+	assert.True(batch.Verify())
+
+	// a single wrong signature fails the batch
+	batch = NewSchnorrBatch()
+	for i := range pubs {
+		msg := msgs[i]
+		if i == 7 {
+			msg = []byte("wrong message")
+		}
+		batch.Add(pubs[i], msg, sigs[i])
+	}
+	assert.False(batch.Verify())
+	batch = NewSchnorrBatch()
+	batch.Add(pubs[0], msgs[0], sigs[0])
+	batch.Add(pubs[1], msgs[1], sigs[1][1:])
+	assert.False(batch.Verify())
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	assert := assert.New(t)
+	params := curve.Params()
+	for _, n := range []int{1, 3, 40} {
+		points := []*jacobianPoint{}
+		scalars := []*big.Int{}
+		var ex, ey *big.Int
+		for i := 0; i < n; i++ {
+			_, priv, err := NewSchnorrKeyPair()
+			assert.Nil(err)
+			x, y := curve.ScalarBaseMult(priv)
+			k, err := rand.Int(rand.Reader, params.N)
+			assert.Nil(err)
+			points = append(points, newJacobianPoint(x, y))
+			scalars = append(scalars, k)
+			kx, ky := curve.ScalarMult(x, y, k.Bytes())
+			if ex == nil {
+				ex, ey = kx, ky
+			} else {
+				ex, ey = curve.Add(ex, ey, kx, ky)
+			}
+		}
+		x, y := multiScalarMult(points, scalars).affine()
+		assert.Equal(ex, x)
+		assert.Equal(ey, y)
+	}
+}
+
+func TestSchnorrAggregateSignature(t *testing.T) {
+	assert := assert.New(t)
+	var pubs, privs, secretNonces, nonces [][]byte
+	for i := 0; i < 3; i++ {
+		pub, priv, err := NewSchnorrKeyPair()
+		assert.Nil(err)
+		secret, nonce, err := NewSchnorrNonce()
+		assert.Nil(err)
+		pubs, privs = append(pubs, pub), append(privs, priv)
+		secretNonces, nonces = append(secretNonces, secret), append(nonces, nonce)
+	}
+	aggPub, err := AggregateSchnorrPubKeys(pubs)
+	assert.Nil(err)
+	aggNonce, err := AggregateSchnorrNonces(nonces)
+	assert.Nil(err)
+
+	message := []byte("hello iotex message")
+	partials := [][]byte{}
+	for i := range privs {
+		partial, err := SchnorrPartialSign(privs[i], pubs, secretNonces[i], aggNonce, message)
+		assert.Nil(err)
+		partials = append(partials, partial)
+	}
+	sig, err := AggregateSchnorrSignatures(aggNonce, partials)
+	assert.Nil(err)
+	assert.True(SchnorrVerify(aggPub, message, sig))
+	batch := NewSchnorrBatch()
+	batch.Add(aggPub, message, sig)
+	assert.True(batch.Verify())
+
+	// all cosigners have to sign
+	sig, err = AggregateSchnorrSignatures(aggNonce, partials[:2])
+	assert.Nil(err)
+	assert.False(SchnorrVerify(aggPub, message, sig))
+	_, err = AggregateSchnorrPubKeys([][]byte{pubs[0], pubs[1][1:]})
+	assert.Equal(ErrInvalidSchnorrKey, err)
+}
@@ -29,6 +29,10 @@ func Sign(priv []byte, msg []byte) []byte {
 
 // Verify wraps ed25519.Verify(0 for now.
 func Verify(pub []byte, msg, sig []byte) bool {
+	// a key of another scheme, such as a Schnorr key, is never valid
+	if len(pub) != ed25519.PublicKeySize {
+		return false
+	}
 	p := ed25519.PublicKey(pub)
 	return ed25519.Verify(p, msg, sig)
 }
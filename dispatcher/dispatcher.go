@@ -11,28 +11,35 @@ import (
 	"sync"
 	"sync/atomic"
 
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
+	"golang.org/x/net/context"
 
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/blocksync"
 	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/consensus"
+	"github.com/iotexproject/iotex-core/consensus/dpos"
 	"github.com/iotexproject/iotex-core/delegate"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/network"
 	pb "github.com/iotexproject/iotex-core/proto"
 	"github.com/iotexproject/iotex-core/txpool"
 )
 
+var log = logger.New("dispatcher")
+
 // txMsg packages a proto tx message.
 type txMsg struct {
-	tx   *pb.TxPb
-	done chan bool
+	sender string
+	tx     *pb.TxPb
+	done   chan bool
 }
 
 // blockMsg packages a proto block message.
 type blockMsg struct {
+	sender  string
 	block   *pb.BlockPb
 	blkType uint32
 	done    chan bool
@@ -45,6 +52,48 @@ type blockSyncMsg struct {
 	done   chan bool
 }
 
+// headerSyncMsg packages a proto block header sync request.
+type headerSyncMsg struct {
+	sender string
+	sync   *pb.BlockHeaderSync
+	done   chan bool
+}
+
+// headersMsg packages a proto block header container.
+type headersMsg struct {
+	sender  string
+	headers *pb.BlockHeaderContainer
+	done    chan bool
+}
+
+// compactBlockMsg packages a proto compact block message.
+type compactBlockMsg struct {
+	sender string
+	block  *pb.CompactBlockPb
+	done   chan bool
+}
+
+// blockTxsSyncMsg packages a proto request for the transactions of a block.
+type blockTxsSyncMsg struct {
+	sender string
+	sync   *pb.BlockTxsSync
+	done   chan bool
+}
+
+// blockTxsMsg packages a proto transaction container.
+type blockTxsMsg struct {
+	sender string
+	txs    *pb.BlockTxsContainer
+	done   chan bool
+}
+
+// checkpointVoteMsg packages a proto checkpoint vote message.
+type checkpointVoteMsg struct {
+	sender string
+	vote   *pb.CheckpointVotePb
+	done   chan bool
+}
+
 // dispatcher implements Dispatcher interface.
 type dispatcher struct {
 	started  int32
@@ -52,6 +101,9 @@ type dispatcher struct {
 	newsChan chan interface{}
 	wg       sync.WaitGroup
 	quit     chan struct{}
+	// ctx is cancelled on shutdown to abort the DB work of the requests in flight
+	ctx    context.Context
+	cancel context.CancelFunc
 
 	bs blocksync.BlockSync
 	cs consensus.Consensus
@@ -59,10 +111,9 @@ type dispatcher struct {
 }
 
 // NewDispatcher creates a new dispatcher
-func NewDispatcher(cfg *config.Config, bc blockchain.IBlockchain, tp txpool.TxPool, bs blocksync.BlockSync, dp delegate.Pool) cm.Dispatcher {
+func NewDispatcher(cfg *config.Config, bc blockchain.IBlockchain, tp txpool.TxPool, bs blocksync.BlockSync, dp delegate.Pool) (cm.Dispatcher, error) {
 	if bc == nil || bs == nil {
-		glog.Fatal("Try to attach to a nil blockchain or a nil P2P")
-		return nil
+		return nil, errors.New("Try to attach to a nil blockchain or a nil P2P")
 	}
 
 	d := &dispatcher{
@@ -71,9 +122,14 @@ func NewDispatcher(cfg *config.Config, bc blockchain.IBlockchain, tp txpool.TxPo
 		tp:       tp,
 		bs:       bs,
 	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
 
-	d.cs = consensus.NewConsensus(cfg, bc, tp, bs, dp)
-	return d
+	cs, err := consensus.NewConsensus(cfg, bc, tp, bs, dp)
+	if err != nil {
+		return nil, err
+	}
+	d.cs = cs
+	return d, nil
 }
 
 // Start starts the dispatcher.
@@ -82,7 +138,7 @@ func (d *dispatcher) Start() error {
 		return errors.New("Dispatcher already started")
 	}
 
-	glog.Info("Starting dispatcher")
+	log.Info("Starting dispatcher")
 	if err := d.cs.Start(); err != nil {
 		return err
 	}
@@ -99,11 +155,12 @@ func (d *dispatcher) Start() error {
 // Stop gracefully shuts down the dispatcher by stopping all handlers and waiting for them to finish.
 func (d *dispatcher) Stop() error {
 	if atomic.AddInt32(&d.shutdown, 1) != 1 {
-		glog.Warning("Dispatcher already in the process of shutting down")
+		log.Warning("Dispatcher already in the process of shutting down")
 		return nil
 	}
 
-	glog.Infof("Dispatcher is shutting down")
+	log.Infof("Dispatcher is shutting down")
+	d.cancel()
 	if err := d.cs.Stop(); err != nil {
 		return err
 	}
@@ -133,8 +190,26 @@ loop:
 			case *blockSyncMsg:
 				d.handleBlockSyncMsg(msg)
 
+			case *headerSyncMsg:
+				d.handleHeaderSyncMsg(msg)
+
+			case *headersMsg:
+				d.handleHeadersMsg(msg)
+
+			case *compactBlockMsg:
+				d.handleCompactBlockMsg(msg)
+
+			case *blockTxsSyncMsg:
+				d.handleBlockTxsSyncMsg(msg)
+
+			case *blockTxsMsg:
+				d.handleBlockTxsMsg(msg)
+
+			case *checkpointVoteMsg:
+				d.handleCheckpointVoteMsg(msg)
+
 			default:
-				glog.Warning("Invalid message type in block handler: %T", msg)
+				log.Warningf("Invalid message type in block handler: %T", msg)
 			}
 
 		case <-d.quit:
@@ -143,18 +218,21 @@ loop:
 	}
 
 	d.wg.Done()
-	glog.Info("News handler done")
+	log.Info("News handler done")
 }
 
 // handleTxMsg handles txMsg from all peers.
 func (d *dispatcher) handleTxMsg(m *txMsg) {
 	tx := &blockchain.Tx{}
 	tx.ConvertFromTxPb(m.tx)
-	glog.Infof("receive txMsg, hash = %x", tx.Hash())
+	log.Infof("receive txMsg, hash = %x", tx.Hash())
 
 	// dispatch to TxPool
 	if _, err := d.tp.ProcessTx(tx, true, true, 0); err != nil {
-		glog.Error(err)
+		log.Error(err)
+		if errors.Cause(err) == txpool.ErrInvalidTx {
+			d.penalize(m.sender, network.PenaltyInvalidTx)
+		}
 	}
 
 	// signal to let caller know we are done
@@ -169,16 +247,17 @@ func (d *dispatcher) handleTxMsg(m *txMsg) {
 func (d *dispatcher) handleBlockMsg(m *blockMsg) {
 	blk := &blockchain.Block{}
 	blk.ConvertFromBlockPb(m.block)
-	glog.Infof("receive blockMsg, block %d, hash = %x", blk.Height(), blk.HashBlock())
+	log.Infof("receive blockMsg, block %d, hash = %x", blk.Height(), blk.HashBlock())
 
+	var err error
 	if m.blkType == pb.MsgBlockProtoMsgType {
-		if err := d.bs.ProcessBlock(blk); err != nil {
-			glog.Error(err)
-		}
+		err = d.bs.ProcessBlock(blk)
 	} else if m.blkType == pb.MsgBlockSyncDataType {
-		if err := d.bs.ProcessBlockSync(blk); err != nil {
-			glog.Error(err)
-		}
+		err = d.bs.ProcessBlockSync(blk)
+	}
+	if err != nil {
+		log.Error(err)
+		d.penalizeInvalidBlock(m.sender, err)
 	}
 
 	// signal to let caller know we are done
@@ -191,11 +270,99 @@ func (d *dispatcher) handleBlockMsg(m *blockMsg) {
 
 // handleBlockSyncMsg handles block messages from peers.
 func (d *dispatcher) handleBlockSyncMsg(m *blockSyncMsg) {
-	glog.Infof("receive blockSyncMsg, addr = %s, start = %d, end = %d", m.sender, m.sync.Start, m.sync.End)
+	log.Infof("receive blockSyncMsg, addr = %s, start = %d, end = %d", m.sender, m.sync.Start, m.sync.End)
+
+	// dispatch to block sync
+	if err := d.bs.ProcessSyncRequest(d.ctx, m.sender, m.sync); err != nil {
+		log.Error(err)
+	}
+
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+
+	return
+}
+
+// handleHeaderSyncMsg handles block header sync requests from peers.
+func (d *dispatcher) handleHeaderSyncMsg(m *headerSyncMsg) {
+	log.Infof("receive headerSyncMsg, addr = %s, start = %d, end = %d", m.sender, m.sync.Start, m.sync.End)
+
+	// dispatch to block sync
+	if err := d.bs.ProcessHeaderSyncRequest(d.ctx, m.sender, m.sync); err != nil {
+		log.Error(err)
+	}
+
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+
+	return
+}
+
+// handleHeadersMsg handles block headers from peers.
+func (d *dispatcher) handleHeadersMsg(m *headersMsg) {
+	log.Infof("receive headersMsg, %d headers", len(m.headers.Headers))
+
+	// dispatch to block sync
+	if err := d.bs.ProcessHeaders(m.headers); err != nil {
+		log.Error(err)
+		d.penalizeInvalidBlock(m.sender, err)
+	}
+
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+
+	return
+}
+
+// handleCompactBlockMsg handles compact blocks from peers.
+func (d *dispatcher) handleCompactBlockMsg(m *compactBlockMsg) {
+	log.Infof("receive compactBlockMsg, addr = %s, %d short IDs", m.block.SenderAddr, len(m.block.ShortIDs))
+
+	// dispatch to block sync
+	if err := d.bs.ProcessCompactBlock(m.block); err != nil {
+		log.Error(err)
+		d.penalizeInvalidBlock(m.sender, err)
+	}
+
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+
+	return
+}
+
+// handleBlockTxsSyncMsg handles requests for the transactions of a block from peers.
+func (d *dispatcher) handleBlockTxsSyncMsg(m *blockTxsSyncMsg) {
+	log.Infof("receive blockTxsSyncMsg, addr = %s, %d transactions", m.sender, len(m.sync.Indexes))
+
+	// dispatch to block sync
+	if err := d.bs.ProcessBlockTxsSyncRequest(m.sender, m.sync); err != nil {
+		log.Error(err)
+	}
+
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+
+	return
+}
+
+// handleBlockTxsMsg handles the transactions of a compact block from peers.
+func (d *dispatcher) handleBlockTxsMsg(m *blockTxsMsg) {
+	log.Infof("receive blockTxsMsg, %d transactions", len(m.txs.Txs))
 
 	// dispatch to block sync
-	if err := d.bs.ProcessSyncRequest(m.sender, m.sync); err != nil {
-		glog.Error(err)
+	if err := d.bs.ProcessBlockTxs(m.txs); err != nil {
+		log.Error(err)
+		d.penalizeInvalidBlock(m.sender, err)
 	}
 
 	// signal to let caller know we are done
@@ -206,8 +373,49 @@ func (d *dispatcher) handleBlockSyncMsg(m *blockSyncMsg) {
 	return
 }
 
+// handleCheckpointVoteMsg handles the checkpoint votes of the delegates from peers.
+func (d *dispatcher) handleCheckpointVoteMsg(m *checkpointVoteMsg) {
+	log.Infof("receive checkpointVoteMsg, height = %d, hash = %x", m.vote.Height, m.vote.Hash)
+
+	// dispatch to consensus
+	if err := d.cs.HandleCheckpointVote(m.vote, m.done); err != nil {
+		log.Error(err)
+		if errors.Cause(err) == dpos.ErrInvalidVote {
+			d.penalize(m.sender, network.PenaltyInvalidMsg)
+		}
+	}
+
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+
+	return
+}
+
+// penalize adds the penalty to the misbehavior score of the peer which sent an invalid message, the node itself is
+// never penalized
+func (d *dispatcher) penalize(sender string, penalty uint) {
+	if sender == "" {
+		return
+	}
+	if p2p := d.bs.P2P(); p2p != nil {
+		p2p.PM.Penalize(sender, penalty)
+	}
+}
+
+// penalizeInvalidBlock penalizes the peer which sent the block data if the error proves the data invalid, as opposed
+// to a block which is stale or out of order
+func (d *dispatcher) penalizeInvalidBlock(sender string, err error) {
+	switch errors.Cause(err) {
+	case blockchain.ErrInvalidBlock, blockchain.ErrInvalidCompactBlock, blocksync.ErrHeaderNotLinked,
+		blocksync.ErrTxsMismatch:
+		d.penalize(sender, network.PenaltyInvalidBlock)
+	}
+}
+
 // dispatchTx adds the passed transaction message to the news handling queue.
-func (d *dispatcher) dispatchTx(msg proto.Message, done chan bool) {
+func (d *dispatcher) dispatchTx(sender string, msg proto.Message, done chan bool) {
 	if atomic.LoadInt32(&d.shutdown) != 0 {
 		if done != nil {
 			close(done)
@@ -215,11 +423,11 @@ func (d *dispatcher) dispatchTx(msg proto.Message, done chan bool) {
 		return
 	}
 
-	d.newsChan <- &txMsg{(msg).(*pb.TxPb), done}
+	d.newsChan <- &txMsg{sender, (msg).(*pb.TxPb), done}
 }
 
 // dispatchBlockCommit adds the passed block message to the news handling queue.
-func (d *dispatcher) dispatchBlockCommit(msg proto.Message, done chan bool) {
+func (d *dispatcher) dispatchBlockCommit(sender string, msg proto.Message, done chan bool) {
 	if atomic.LoadInt32(&d.shutdown) != 0 {
 		if done != nil {
 			close(done)
@@ -227,7 +435,7 @@ func (d *dispatcher) dispatchBlockCommit(msg proto.Message, done chan bool) {
 		return
 	}
 
-	d.newsChan <- &blockMsg{(msg).(*pb.BlockPb), pb.MsgBlockProtoMsgType, done}
+	d.newsChan <- &blockMsg{sender, (msg).(*pb.BlockPb), pb.MsgBlockProtoMsgType, done}
 }
 
 // dispatchBlockSyncReq adds the passed block sync request to the news handling queue.
@@ -243,7 +451,7 @@ func (d *dispatcher) dispatchBlockSyncReq(sender string, msg proto.Message, done
 }
 
 // dispatchBlockSyncData handles block sync data
-func (d *dispatcher) dispatchBlockSyncData(msg proto.Message, done chan bool) {
+func (d *dispatcher) dispatchBlockSyncData(sender string, msg proto.Message, done chan bool) {
 	if atomic.LoadInt32(&d.shutdown) != 0 {
 		if done != nil {
 			close(done)
@@ -252,15 +460,103 @@ func (d *dispatcher) dispatchBlockSyncData(msg proto.Message, done chan bool) {
 	}
 
 	data := (msg).(*pb.BlockContainer)
-	d.newsChan <- &blockMsg{data.Block, pb.MsgBlockSyncDataType, done}
+	if len(data.Blocks) == 0 {
+		d.newsChan <- &blockMsg{sender, data.Block, pb.MsgBlockSyncDataType, done}
+		return
+	}
+	// the blocks are handled in order, so the caller is signaled once the last one is done
+	for i, blk := range data.Blocks {
+		var blkDone chan bool
+		if i == len(data.Blocks)-1 {
+			blkDone = done
+		}
+		d.newsChan <- &blockMsg{sender, blk, pb.MsgBlockSyncDataType, blkDone}
+	}
+}
+
+// dispatchHeaderSyncReq adds the passed block header sync request to the news handling queue.
+func (d *dispatcher) dispatchHeaderSyncReq(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+
+	d.newsChan <- &headerSyncMsg{sender, (msg).(*pb.BlockHeaderSync), done}
+}
+
+// dispatchHeaderSyncData adds the passed block headers to the news handling queue.
+func (d *dispatcher) dispatchHeaderSyncData(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+
+	d.newsChan <- &headersMsg{sender, (msg).(*pb.BlockHeaderContainer), done}
+}
+
+// dispatchCompactBlock adds the passed compact block message to the news handling queue.
+func (d *dispatcher) dispatchCompactBlock(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+
+	d.newsChan <- &compactBlockMsg{sender, (msg).(*pb.CompactBlockPb), done}
+}
+
+// dispatchBlockTxsSyncReq adds the passed request for the transactions of a block to the news handling queue.
+func (d *dispatcher) dispatchBlockTxsSyncReq(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+
+	d.newsChan <- &blockTxsSyncMsg{sender, (msg).(*pb.BlockTxsSync), done}
+}
+
+// dispatchBlockTxsSyncData adds the passed transactions of a compact block to the news handling queue.
+func (d *dispatcher) dispatchBlockTxsSyncData(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+
+	d.newsChan <- &blockTxsMsg{sender, (msg).(*pb.BlockTxsContainer), done}
+}
+
+// dispatchCheckpointVote adds the passed checkpoint vote to the news handling queue.
+func (d *dispatcher) dispatchCheckpointVote(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+
+	d.newsChan <- &checkpointVoteMsg{sender, (msg).(*pb.CheckpointVotePb), done}
 }
 
 // HandleBroadcast handles incoming broadcast message
 
-func (d *dispatcher) HandleBroadcast(message proto.Message, done chan bool) {
+func (d *dispatcher) HandleBroadcast(sender net.Addr, message proto.Message, done chan bool) {
 	msgType, err := pb.GetTypeFromProtoMsg(message)
 	if err != nil {
-		glog.Warning("unexpected message handled by HandleBroadcast: ", err.Error())
+		log.Warning("unexpected message handled by HandleBroadcast: ", err.Error())
+	}
+
+	var addr string
+	if sender != nil {
+		addr = sender.String()
 	}
 
 	switch msgType {
@@ -268,13 +564,19 @@ func (d *dispatcher) HandleBroadcast(message proto.Message, done chan bool) {
 		d.cs.HandleViewChange(message, done)
 		break
 	case pb.MsgTxProtoMsgType:
-		d.dispatchTx(message, done)
+		d.dispatchTx(addr, message, done)
 		break
 	case pb.MsgBlockProtoMsgType:
-		d.dispatchBlockCommit(message, done)
+		d.dispatchBlockCommit(addr, message, done)
+		break
+	case pb.MsgCompactBlockType:
+		d.dispatchCompactBlock(addr, message, done)
+		break
+	case pb.MsgCheckpointVoteType:
+		d.dispatchCheckpointVote(addr, message, done)
 		break
 	default:
-		glog.Warning("unexpected msgType %v handled by HandleBroadcast", msgType)
+		log.Warningf("unexpected msgType %v handled by HandleBroadcast", msgType)
 	}
 }
 
@@ -282,18 +584,26 @@ func (d *dispatcher) HandleBroadcast(message proto.Message, done chan bool) {
 func (d *dispatcher) HandleTell(sender net.Addr, message proto.Message, done chan bool) {
 	msgType, err := pb.GetTypeFromProtoMsg(message)
 	if err != nil {
-		glog.Warning("unexpected message handled by HandleTell: ", err.Error())
+		log.Warning("unexpected message handled by HandleTell: ", err.Error())
 	}
 
-	glog.Info("dispatcher.HandleTell from", sender, message)
+	log.Info("dispatcher.HandleTell from", sender, message)
 	switch msgType {
 	case pb.MsgBlockSyncReqType:
 		d.dispatchBlockSyncReq(sender.String(), message, done)
 	case pb.MsgBlockSyncDataType:
-		d.dispatchBlockSyncData(message, done)
+		d.dispatchBlockSyncData(sender.String(), message, done)
+	case pb.MsgBlockHeaderSyncReqType:
+		d.dispatchHeaderSyncReq(sender.String(), message, done)
+	case pb.MsgBlockHeaderSyncDataType:
+		d.dispatchHeaderSyncData(sender.String(), message, done)
+	case pb.MsgBlockTxsSyncReqType:
+		d.dispatchBlockTxsSyncReq(sender.String(), message, done)
+	case pb.MsgBlockTxsSyncDataType:
+		d.dispatchBlockTxsSyncData(sender.String(), message, done)
 	case pb.MsgBlockProtoMsgType:
 		d.cs.HandleBlockPropose(message, done)
 	default:
-		glog.Warning("unexpected msgType %v handled by HandleTell", msgType)
+		log.Warningf("unexpected msgType %v handled by HandleTell", msgType)
 	}
 }
@@ -31,7 +31,8 @@ func TestNewDispatcher(t *testing.T) {
 	bs := mock_blocksync.NewMockBlockSync(ctrl)
 	dp := mock_delegate.NewMockPool(ctrl)
 
-	d := NewDispatcher(cfg, bc, tp, bs, dp)
+	d, err := NewDispatcher(cfg, bc, tp, bs, dp)
+	assert.Nil(t, err)
 	assert.NotNil(t, d)
 
 	bs.EXPECT().Start().Times(1)
@@ -50,7 +51,8 @@ func TestDispatchTxMsg(t *testing.T) {
 	bs := mock_blocksync.NewMockBlockSync(ctrl)
 	dp := mock_delegate.NewMockPool(ctrl)
 
-	d := NewDispatcher(cfg, bc, tp, bs, dp)
+	d, err := NewDispatcher(cfg, bc, tp, bs, dp)
+	assert.Nil(t, err)
 	assert.NotNil(t, d)
 
 	bs.EXPECT().Start().Times(1)
@@ -61,7 +63,7 @@ func TestDispatchTxMsg(t *testing.T) {
 	done := make(chan bool, 1000)
 	tp.EXPECT().ProcessTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1000).Return(nil, nil)
 	for i := 0; i < 1000; i++ {
-		d.HandleBroadcast(&iproto.TxPb{}, done)
+		d.HandleBroadcast(cm.NewTCPNode("192.168.0.0:10000"), &iproto.TxPb{}, done)
 	}
 	for i := 0; i < 1000; i++ {
 		<-done
@@ -78,7 +80,8 @@ func TestDispatchBlockMsg(t *testing.T) {
 	bs := mock_blocksync.NewMockBlockSync(ctrl)
 	dp := mock_delegate.NewMockPool(ctrl)
 
-	d := NewDispatcher(cfg, bc, tp, bs, dp)
+	d, err := NewDispatcher(cfg, bc, tp, bs, dp)
+	assert.Nil(t, err)
 	assert.NotNil(t, d)
 
 	bs.EXPECT().Start().Times(1)
@@ -89,7 +92,7 @@ func TestDispatchBlockMsg(t *testing.T) {
 	done := make(chan bool, 1000)
 	bs.EXPECT().ProcessBlock(gomock.Any()).Times(1000).Return(nil)
 	for i := 0; i < 1000; i++ {
-		d.HandleBroadcast(&iproto.BlockPb{}, done)
+		d.HandleBroadcast(cm.NewTCPNode("192.168.0.0:10000"), &iproto.BlockPb{}, done)
 	}
 	for i := 0; i < 1000; i++ {
 		<-done
@@ -106,7 +109,8 @@ func TestDispatchBlockSyncReq(t *testing.T) {
 	bs := mock_blocksync.NewMockBlockSync(ctrl)
 	dp := mock_delegate.NewMockPool(ctrl)
 
-	d := NewDispatcher(cfg, bc, tp, bs, dp)
+	d, err := NewDispatcher(cfg, bc, tp, bs, dp)
+	assert.Nil(t, err)
 	assert.NotNil(t, d)
 
 	bs.EXPECT().Start().Times(1)
@@ -115,7 +119,7 @@ func TestDispatchBlockSyncReq(t *testing.T) {
 	defer d.Stop()
 
 	done := make(chan bool, 1000)
-	bs.EXPECT().ProcessSyncRequest(gomock.Any(), gomock.Any()).Times(1000).Return(nil)
+	bs.EXPECT().ProcessSyncRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(1000).Return(nil)
 	for i := 0; i < 1000; i++ {
 		d.HandleTell(cm.NewTCPNode("192.168.0.0:10000"), &iproto.BlockSync{}, done)
 	}
@@ -134,7 +138,8 @@ func TestDispatchBlockSyncData(t *testing.T) {
 	bs := mock_blocksync.NewMockBlockSync(ctrl)
 	dp := mock_delegate.NewMockPool(ctrl)
 
-	d := NewDispatcher(cfg, bc, tp, bs, dp)
+	d, err := NewDispatcher(cfg, bc, tp, bs, dp)
+	assert.Nil(t, err)
 	assert.NotNil(t, d)
 
 	bs.EXPECT().Start().Times(1)
@@ -151,3 +156,67 @@ func TestDispatchBlockSyncData(t *testing.T) {
 		<-done
 	}
 }
+
+func TestDispatchHeaderSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Consensus: config.Consensus{Scheme: "NOOP"}}
+	bc := mock_blockchain.NewMockIBlockchain(ctrl)
+	tp := mock_txpool.NewMockTxPool(ctrl)
+	bs := mock_blocksync.NewMockBlockSync(ctrl)
+	dp := mock_delegate.NewMockPool(ctrl)
+
+	d, err := NewDispatcher(cfg, bc, tp, bs, dp)
+	assert.Nil(t, err)
+	assert.NotNil(t, d)
+
+	bs.EXPECT().Start().Times(1)
+	bs.EXPECT().Stop().Times(1)
+	d.Start()
+	defer d.Stop()
+
+	done := make(chan bool, 2000)
+	bs.EXPECT().ProcessHeaderSyncRequest(gomock.Any(), gomock.Any(), gomock.Any()).Times(1000).Return(nil)
+	bs.EXPECT().ProcessHeaders(gomock.Any()).Times(1000).Return(nil)
+	for i := 0; i < 1000; i++ {
+		d.HandleTell(cm.NewTCPNode("192.168.0.0:10000"), &iproto.BlockHeaderSync{}, done)
+		d.HandleTell(cm.NewTCPNode("192.168.0.0:10000"), &iproto.BlockHeaderContainer{}, done)
+	}
+	for i := 0; i < 2000; i++ {
+		<-done
+	}
+}
+
+func TestDispatchCompactBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Consensus: config.Consensus{Scheme: "NOOP"}}
+	bc := mock_blockchain.NewMockIBlockchain(ctrl)
+	tp := mock_txpool.NewMockTxPool(ctrl)
+	bs := mock_blocksync.NewMockBlockSync(ctrl)
+	dp := mock_delegate.NewMockPool(ctrl)
+
+	d, err := NewDispatcher(cfg, bc, tp, bs, dp)
+	assert.Nil(t, err)
+	assert.NotNil(t, d)
+
+	bs.EXPECT().Start().Times(1)
+	bs.EXPECT().Stop().Times(1)
+	d.Start()
+	defer d.Stop()
+
+	done := make(chan bool, 3000)
+	bs.EXPECT().ProcessCompactBlock(gomock.Any()).Times(1000).Return(nil)
+	bs.EXPECT().ProcessBlockTxsSyncRequest(gomock.Any(), gomock.Any()).Times(1000).Return(nil)
+	bs.EXPECT().ProcessBlockTxs(gomock.Any()).Times(1000).Return(nil)
+	for i := 0; i < 1000; i++ {
+		d.HandleBroadcast(cm.NewTCPNode("192.168.0.0:10000"), &iproto.CompactBlockPb{}, done)
+		d.HandleTell(cm.NewTCPNode("192.168.0.0:10000"), &iproto.BlockTxsSync{}, done)
+		d.HandleTell(cm.NewTCPNode("192.168.0.0:10000"), &iproto.BlockTxsContainer{}, done)
+	}
+	for i := 0; i < 3000; i++ {
+		<-done
+	}
+}
@@ -38,9 +38,10 @@ func TestLocalRDPoS(t *testing.T) {
 		cfg.Chain.ChainDBPath = "./test_fullnode_chain" + strconv.Itoa(i) + ".db"
 		cfg.NodeType = config.FullNodeType
 		cfg.Network.Addr = "127.0.0.1:5000" + strconv.Itoa(i)
-		svr := itx.NewServer(*cfg)
-		svr.Init()
-		svr.Start()
+		svr, err := itx.NewServer(*cfg)
+		assert.Nil(t, err)
+		assert.Nil(t, svr.Init())
+		assert.Nil(t, svr.Start())
 		svrs = append(svrs, svr)
 	}
 
@@ -49,9 +50,10 @@ func TestLocalRDPoS(t *testing.T) {
 		cfg.NodeType = config.DelegateType
 		cfg.Network.Addr = "127.0.0.1:4000" + strconv.Itoa(i)
 		cfg.Consensus.Scheme = "RDPOS"
-		svr := itx.NewServer(*cfg)
-		svr.Init()
-		svr.Start()
+		svr, err := itx.NewServer(*cfg)
+		assert.Nil(t, err)
+		assert.Nil(t, svr.Init())
+		assert.Nil(t, svr.Start())
 		svrs = append(svrs, svr)
 	}
 
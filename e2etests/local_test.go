@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/blocksync"
@@ -23,6 +24,7 @@ import (
 	"github.com/iotexproject/iotex-core/proto"
 	ta "github.com/iotexproject/iotex-core/test/testaddress"
 	"github.com/iotexproject/iotex-core/txpool"
+	"github.com/iotexproject/iotex-core/wallet"
 )
 
 const (
@@ -44,7 +46,8 @@ func TestLocalCommit(t *testing.T) {
 	config.Delegate.Addrs = []string{"127.0.0.1:10000"}
 
 	// create Blockchain
-	bc := blockchain.CreateBlockchain(ta.Addrinfo["miner"].Address, config)
+	bc, err := blockchain.CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
 	assert.NotNil(bc)
 	t.Log("Create blockchain pass")
 	defer bc.Close()
@@ -52,7 +55,7 @@ func TestLocalCommit(t *testing.T) {
 	assert.Nil(addTestingBlocks(bc))
 
 	// create TxPool
-	tp := txpool.New(bc)
+	tp := txpool.New(bc, &config.TxPool)
 	assert.NotNil(tp)
 
 	p1 := network.NewOverlay(&config.Network)
@@ -74,11 +77,13 @@ func TestLocalCommit(t *testing.T) {
 	defer pool.Stop()
 
 	// create block sync
-	bs := blocksync.NewBlockSyncer(config, bc, tp, p2, pool)
+	bs, err := blocksync.NewBlockSyncer(config, bc, tp, p2, pool)
+	assert.Nil(err)
 	assert.NotNil(bs)
 
 	// create dispatcher
-	dp := dispatcher.NewDispatcher(config, bc, tp, bs, pool)
+	dp, err := dispatcher.NewDispatcher(config, bc, tp, bs, pool)
+	assert.Nil(err)
 	assert.NotNil(dp)
 	p1.AttachDispatcher(dp)
 	p2.AttachDispatcher(dp)
@@ -88,30 +93,30 @@ func TestLocalCommit(t *testing.T) {
 	time.Sleep(time.Second)
 
 	// check UTXO
-	change := bc.BalanceOf(ta.Addrinfo["alfa"].Address)
+	change := bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0)
 	t.Logf("Alfa balance = %d", change)
 
-	beta := bc.BalanceOf(ta.Addrinfo["bravo"].Address)
+	beta := bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0)
 	t.Logf("Bravo balance = %d", beta)
 	change += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["charlie"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["charlie"].Address, 0)
 	t.Logf("Charlie balance = %d", beta)
 	change += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["delta"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["delta"].Address, 0)
 	t.Logf("Delta balance = %d", beta)
 	change += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["echo"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["echo"].Address, 0)
 	t.Logf("Echo balance = %d", beta)
 	change += beta
 
-	fox := bc.BalanceOf(ta.Addrinfo["foxtrot"].Address)
+	fox := bc.BalanceOf(ta.Addrinfo["foxtrot"].Address, 0)
 	t.Logf("Foxtrot balance = %d", fox)
 	change += fox
 
-	test := bc.BalanceOf(ta.Addrinfo["miner"].Address)
+	test := bc.BalanceOf(ta.Addrinfo["miner"].Address, 0)
 	t.Logf("test balance = %d", test)
 	change += test
 
@@ -128,19 +133,22 @@ func TestLocalCommit(t *testing.T) {
 	// C --> A
 	payee := []*blockchain.Payee{}
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["alfa"].Address, 1})
-	tx := bc.CreateTransaction(ta.Addrinfo["charlie"], 1, payee)
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["charlie"]), 1, payee)
+	assert.Nil(err)
 	bc.Reset()
 	p1.Broadcast(tx.ConvertToTxPb())
 	time.Sleep(time.Second << 1)
 
-	blk1 := bc.MintNewBlock(tp.Txs(), ta.Addrinfo["miner"].Address, "")
+	blk1, err := bc.MintNewBlock(tp.Txs(), ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
 	hash1 := blk1.HashBlock()
 
 	// transaction 2
 	// F --> D
 	payee = nil
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["delta"].Address, 1})
-	tx2 := bc.CreateTransaction(ta.Addrinfo["foxtrot"], 1, payee)
+	tx2, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["foxtrot"]), 1, payee)
+	assert.Nil(err)
 	blk2 := blockchain.NewBlock(0, height+2, hash1, []*blockchain.Tx{tx2})
 	hash2 := blk2.HashBlock()
 	bc.Reset()
@@ -150,7 +158,8 @@ func TestLocalCommit(t *testing.T) {
 	// B --> B
 	payee = nil
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["bravo"].Address, 1})
-	tx3 := bc.CreateTransaction(ta.Addrinfo["bravo"], 1, payee)
+	tx3, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["bravo"]), 1, payee)
+	assert.Nil(err)
 	blk3 := blockchain.NewBlock(0, height+3, hash2, []*blockchain.Tx{tx3})
 	hash3 := blk3.HashBlock()
 	bc.Reset()
@@ -160,7 +169,8 @@ func TestLocalCommit(t *testing.T) {
 	// test --> E
 	payee = nil
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["echo"].Address, 1})
-	tx4 := bc.CreateTransaction(ta.Addrinfo["miner"], 1, payee)
+	tx4, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 1, payee)
+	assert.Nil(err)
 	blk4 := blockchain.NewBlock(0, height+4, hash3, []*blockchain.Tx{tx4})
 	bc.Reset()
 	p2.Broadcast(tx4.ConvertToTxPb())
@@ -175,30 +185,30 @@ func TestLocalCommit(t *testing.T) {
 	t.Log("----- Block height = ", bc.TipHeight())
 
 	// check UTXO
-	change = bc.BalanceOf(ta.Addrinfo["alfa"].Address)
+	change = bc.BalanceOf(ta.Addrinfo["alfa"].Address, 0)
 	t.Logf("Alfa balance = %d", change)
 
-	beta = bc.BalanceOf(ta.Addrinfo["bravo"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0)
 	t.Logf("Bravo balance = %d", beta)
 	change += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["charlie"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["charlie"].Address, 0)
 	t.Logf("Charlie balance = %d", beta)
 	change += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["delta"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["delta"].Address, 0)
 	t.Logf("Delta balance = %d", beta)
 	change += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["echo"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["echo"].Address, 0)
 	t.Logf("Echo balance = %d", beta)
 	change += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["foxtrot"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["foxtrot"].Address, 0)
 	t.Logf("Foxtrot balance = %d", beta)
 	change += beta
 
-	beta = bc.BalanceOf(ta.Addrinfo["miner"].Address)
+	beta = bc.BalanceOf(ta.Addrinfo["miner"].Address, 0)
 	t.Logf("test balance = %d", beta)
 	change += beta
 
@@ -217,14 +227,15 @@ func TestLocalSync(t *testing.T) {
 	config.Consensus.Scheme = "NOOP"
 
 	// create Blockchain
-	bc := blockchain.CreateBlockchain(ta.Addrinfo["miner"].Address, config)
+	bc, err := blockchain.CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
 	assert.NotNil(bc)
 	t.Log("Create blockchain pass")
 	defer bc.Close()
 	assert.Nil(addTestingBlocks(bc))
 
 	// create TxPool
-	tp := txpool.New(bc)
+	tp := txpool.New(bc, &config.TxPool)
 	assert.NotNil(tp)
 
 	// create 2 peers
@@ -248,11 +259,13 @@ func TestLocalSync(t *testing.T) {
 	defer pool.Stop()
 
 	// create block sync
-	bs := blocksync.NewBlockSyncer(config, bc, tp, p2, pool)
+	bs, err := blocksync.NewBlockSyncer(config, bc, tp, p2, pool)
+	assert.Nil(err)
 	assert.NotNil(bs)
 
 	// create dispatcher
-	dp := dispatcher.NewDispatcher(config, bc, nil, bs, pool)
+	dp, err := dispatcher.NewDispatcher(config, bc, nil, bs, pool)
+	assert.Nil(err)
 	assert.NotNil(dp)
 	p2.AttachDispatcher(dp)
 	p1.AttachDispatcher(dp)
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/blocksync"
@@ -39,13 +40,14 @@ func TestNetSync(t *testing.T) {
 
 	// create Blockchain
 	// create Blockchain
-	bc := blockchain.CreateBlockchain(ta.Addrinfo["miner"].Address, config)
+	bc, err := blockchain.CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
 	assert.NotNil(bc)
 	t.Log("Create blockchain pass")
 	defer bc.Close()
 
 	// create TxPool
-	tp := txpool.New(bc)
+	tp := txpool.New(bc, &config.TxPool)
 	assert.NotNil(tp)
 
 	// create client
@@ -61,11 +63,13 @@ func TestNetSync(t *testing.T) {
 	defer pool.Stop()
 
 	// create block sync
-	bs := blocksync.NewBlockSyncer(config, bc, tp, p1, pool)
+	bs, err := blocksync.NewBlockSyncer(config, bc, tp, p1, pool)
+	assert.Nil(err)
 	assert.NotNil(bs)
 
 	// create dispatcher
-	dp := dispatcher.NewDispatcher(config, bc, nil, bs, pool)
+	dp, err := dispatcher.NewDispatcher(config, bc, nil, bs, pool)
+	assert.Nil(err)
 	assert.NotNil(dp)
 	p1.AttachDispatcher(dp)
 	dp.Start()
@@ -3,6 +3,7 @@ package e2etests
 import (
 	"github.com/iotexproject/iotex-core/blockchain"
 	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/wallet"
 )
 
 func addTestingBlocks(bc *blockchain.Blockchain) error {
@@ -15,8 +16,14 @@ func addTestingBlocks(bc *blockchain.Blockchain) error {
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["delta"].Address, 70})
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["echo"].Address, 110})
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["foxtrot"].Address, 50 << 20})
-	tx := bc.CreateTransaction(ta.Addrinfo["miner"], 280+50<<20, payee)
-	blk := bc.MintNewBlock([]*blockchain.Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 280+50<<20, payee)
+	if err != nil {
+		return err
+	}
+	blk, err := bc.MintNewBlock([]*blockchain.Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	if err != nil {
+		return err
+	}
 	if err := bc.AddBlockCommit(blk); err != nil {
 		return err
 	}
@@ -30,8 +37,14 @@ func addTestingBlocks(bc *blockchain.Blockchain) error {
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["delta"].Address, 1})
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["echo"].Address, 1})
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["miner"].Address, 1})
-	tx = bc.CreateTransaction(ta.Addrinfo["charlie"], 5, payee)
-	blk = bc.MintNewBlock([]*blockchain.Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["charlie"]), 5, payee)
+	if err != nil {
+		return err
+	}
+	blk, err = bc.MintNewBlock([]*blockchain.Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	if err != nil {
+		return err
+	}
 	if err := bc.AddBlockCommit(blk); err != nil {
 		return err
 	}
@@ -42,8 +55,14 @@ func addTestingBlocks(bc *blockchain.Blockchain) error {
 	payee = payee[1:]
 	payee[1] = &blockchain.Payee{ta.Addrinfo["echo"].Address, 1}
 	payee[2] = &blockchain.Payee{ta.Addrinfo["foxtrot"].Address, 1}
-	tx = bc.CreateTransaction(ta.Addrinfo["delta"], 4, payee)
-	blk = bc.MintNewBlock([]*blockchain.Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["delta"]), 4, payee)
+	if err != nil {
+		return err
+	}
+	blk, err = bc.MintNewBlock([]*blockchain.Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	if err != nil {
+		return err
+	}
 	if err := bc.AddBlockCommit(blk); err != nil {
 		return err
 	}
@@ -58,8 +77,14 @@ func addTestingBlocks(bc *blockchain.Blockchain) error {
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["delta"].Address, 2})
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["foxtrot"].Address, 2})
 	payee = append(payee, &blockchain.Payee{ta.Addrinfo["miner"].Address, 2})
-	tx = bc.CreateTransaction(ta.Addrinfo["echo"], 12, payee)
-	blk = bc.MintNewBlock([]*blockchain.Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	tx, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["echo"]), 12, payee)
+	if err != nil {
+		return err
+	}
+	blk, err = bc.MintNewBlock([]*blockchain.Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	if err != nil {
+		return err
+	}
 	if err := bc.AddBlockCommit(blk); err != nil {
 		return err
 	}
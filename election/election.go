@@ -0,0 +1,92 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package election
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
+)
+
+// Candidate is an address nominated for the delegates by voting for itself, along with the votes staked toward it
+type Candidate struct {
+	Address string
+	// Votes is the sum of the balances of the accounts voting for the candidate, including its own
+	Votes uint64
+}
+
+// Tally returns the candidates among the accounts along with their votes, sorted by the votes in descending order and
+// then by address
+// The balance of an account voting for an address which is not a candidate is not counted.
+func Tally(accounts map[string]*state.Account) []*Candidate {
+	votes := make(map[string]uint64)
+	for addr, acct := range accounts {
+		if acct.IsCandidate(addr) {
+			votes[addr] = 0
+		}
+	}
+	for _, acct := range accounts {
+		if _, ok := votes[acct.Votee]; ok {
+			votes[acct.Votee] += acct.Balance
+		}
+	}
+
+	candidates := make([]*Candidate, 0, len(votes))
+	for addr, v := range votes {
+		candidates = append(candidates, &Candidate{Address: addr, Votes: v})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Votes != candidates[j].Votes {
+			return candidates[i].Votes > candidates[j].Votes
+		}
+		return candidates[i].Address < candidates[j].Address
+	})
+	return candidates
+}
+
+// Elect returns the n candidates with the most votes, or all of them if n is 0, the candidates must be in the order
+// of Tally
+func Elect(candidates []*Candidate, n int) []*Candidate {
+	if n == 0 || n > len(candidates) {
+		n = len(candidates)
+	}
+	return candidates[:n]
+}
+
+// Addresses returns the addresses of the candidates
+func Addresses(candidates []*Candidate) []string {
+	addrs := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		addrs = append(addrs, c.Address)
+	}
+	return addrs
+}
+
+// Serialize returns the serialized list of the candidates
+func Serialize(candidates []*Candidate) ([]byte, error) {
+	pbList := &iproto.CandidateListPb{}
+	for _, c := range candidates {
+		pbList.Candidates = append(pbList.Candidates, &iproto.CandidatePb{Address: c.Address, Votes: c.Votes})
+	}
+	return proto.Marshal(pbList)
+}
+
+// Deserialize parses the serialized list of candidates
+func Deserialize(buf []byte) ([]*Candidate, error) {
+	pbList := iproto.CandidateListPb{}
+	if err := proto.Unmarshal(buf, &pbList); err != nil {
+		return nil, err
+	}
+	candidates := make([]*Candidate, 0, len(pbList.Candidates))
+	for _, pbCandidate := range pbList.Candidates {
+		candidates = append(candidates, &Candidate{Address: pbCandidate.GetAddress(), Votes: pbCandidate.GetVotes()})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package election
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/state"
+)
+
+func TestTallyAndElect(t *testing.T) {
+	assert := assert.New(t)
+
+	accounts := map[string]*state.Account{
+		"alfa":    {Balance: 10, Votee: "alfa"},
+		"bravo":   {Balance: 5, Votee: "bravo"},
+		"charlie": {Balance: 30, Votee: "bravo"},
+		"delta":   {Balance: 20, Votee: "echo"},
+		"echo":    {Balance: 1, Votee: "alfa"},
+		"foxtrot": {Votee: "foxtrot"},
+		"golf":    {Balance: 100},
+	}
+	candidates := Tally(accounts)
+	assert.Equal([]*Candidate{
+		{Address: "bravo", Votes: 35},
+		{Address: "alfa", Votes: 11},
+		{Address: "foxtrot", Votes: 0},
+	}, candidates)
+
+	assert.Equal([]string{"bravo", "alfa"}, Addresses(Elect(candidates, 2)))
+	assert.Equal([]string{"bravo", "alfa", "foxtrot"}, Addresses(Elect(candidates, 0)))
+	assert.Equal([]string{"bravo", "alfa", "foxtrot"}, Addresses(Elect(candidates, 5)))
+	assert.Equal(0, len(Elect(nil, 3)))
+
+	buf, err := Serialize(candidates)
+	assert.Nil(err)
+	decoded, err := Deserialize(buf)
+	assert.Nil(err)
+	assert.Equal(candidates, decoded)
+}
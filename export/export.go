@@ -0,0 +1,387 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// Package export writes the data of a chain as records of newline-delimited JSON or CSV, so it can be loaded into
+// external systems for analytics.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/txvm"
+)
+
+// The kinds of records the chain is exported as
+const (
+	// BlockRecords are one record per block
+	BlockRecords = "block"
+	// TxRecords are one record per transaction, in the order of its block
+	TxRecords = "tx"
+	// UtxoRecords are one record per output created and one per output spent by a transaction, the spends of a
+	// transaction before its outputs
+	UtxoRecords = "utxo"
+)
+
+// The formats the records are written in
+const (
+	// JSON writes each record as a JSON object of the fields in a line
+	JSON = "json"
+	// CSV writes a header of the fields followed by a row per record
+	CSV = "csv"
+)
+
+// The events of the UTXO records
+const (
+	// CreateEvent is an output created by a transaction
+	CreateEvent = "create"
+	// SpendEvent is an output spent by an input of a transaction, the value and script of the output are in the record
+	// of the event creating it
+	SpendEvent = "spend"
+)
+
+// ErrInvalidOptions is the error returned when the options of an export are not valid
+var ErrInvalidOptions = errors.New("invalid export options")
+
+// Options select the records an export writes
+type Options struct {
+	// Records is the kind of the records
+	Records string
+	// Format is the format the records are written in
+	Format string
+	// Fields are the fields of the records in the order they are written, all fields of the kind if it is empty
+	Fields []string
+	// Start and End are the heights of the first and the last block exported
+	Start uint32
+	End   uint32
+}
+
+// record is a record being exported, the fields of its kind read the ones they need
+type record struct {
+	blk   *blockchain.Block
+	tx    *blockchain.Tx
+	index int // index of the tx in the block, or of the input or output in the tx
+	event string
+	in    *blockchain.TxInput
+	out   *blockchain.TxOutput
+}
+
+// field is a field of the records of a kind, returning a JSON string, number or boolean
+type field struct {
+	name  string
+	value func(r *record) interface{}
+}
+
+// hexHash returns the hex encoded hash
+func hexHash(hash cp.Hash32B) string {
+	return hex.EncodeToString(hash[:])
+}
+
+var blockFields = []field{
+	{"height", func(r *record) interface{} { return r.blk.Height() }},
+	{"hash", func(r *record) interface{} { return hexHash(r.blk.HashBlock()) }},
+	{"prev_hash", func(r *record) interface{} { return hexHash(r.blk.PrevHash()) }},
+	{"timestamp", func(r *record) interface{} { return r.blk.Timestamp() }},
+	{"version", func(r *record) interface{} { return r.blk.Version() }},
+	{"merkle_root", func(r *record) interface{} { return hexHash(r.blk.MerkleRoot()) }},
+	{"state_root", func(r *record) interface{} { return hexHash(r.blk.StateRoot()) }},
+	{"num_txs", func(r *record) interface{} { return len(r.blk.Tranxs) }},
+	{"num_actions", func(r *record) interface{} { return len(r.blk.Actions()) }},
+	{"txs_size", func(r *record) interface{} { return r.blk.TranxsSize() }},
+	{"proposer", func(r *record) interface{} {
+		return hex.EncodeToString(iotxaddress.HashPubKey(r.blk.ProposerPubKey()))
+	}},
+}
+
+var txFields = []field{
+	{"height", func(r *record) interface{} { return r.blk.Height() }},
+	{"block_hash", func(r *record) interface{} { return hexHash(r.blk.HashBlock()) }},
+	{"index", func(r *record) interface{} { return r.index }},
+	{"hash", func(r *record) interface{} { return hexHash(r.tx.Hash()) }},
+	{"version", func(r *record) interface{} { return r.tx.Version }},
+	{"lock_time", func(r *record) interface{} { return r.tx.LockTime }},
+	{"coinbase", func(r *record) interface{} { return r.tx.IsCoinbase() }},
+	{"num_inputs", func(r *record) interface{} { return len(r.tx.TxIn) }},
+	{"num_outputs", func(r *record) interface{} { return len(r.tx.TxOut) }},
+	{"value_out", func(r *record) interface{} {
+		value := uint64(0)
+		for _, out := range r.tx.TxOut {
+			value += out.Value
+		}
+		return value
+	}},
+	{"size", func(r *record) interface{} { return r.tx.TotalSize() }},
+}
+
+var utxoFields = []field{
+	{"height", func(r *record) interface{} { return r.blk.Height() }},
+	{"tx_hash", func(r *record) interface{} { return hexHash(r.tx.Hash()) }},
+	{"event", func(r *record) interface{} { return r.event }},
+	{"index", func(r *record) interface{} { return r.index }},
+	{"out_tx_hash", func(r *record) interface{} {
+		if r.in != nil {
+			return hex.EncodeToString(r.in.TxHash)
+		}
+		return hexHash(r.tx.Hash())
+	}},
+	{"out_index", func(r *record) interface{} {
+		if r.in != nil {
+			return r.in.OutIndex
+		}
+		return r.index
+	}},
+	{"value", func(r *record) interface{} {
+		if r.out == nil {
+			return nil
+		}
+		return r.out.Value
+	}},
+	{"script_type", func(r *record) interface{} {
+		if r.out == nil {
+			return nil
+		}
+		return txvm.ScriptType(r.out.LockScript)
+	}},
+	{"key_hash", func(r *record) interface{} {
+		if r.out == nil {
+			return nil
+		}
+		return keyHash(r.out.LockScript)
+	}},
+	{"script", func(r *record) interface{} {
+		if r.out == nil {
+			return nil
+		}
+		return hex.EncodeToString(r.out.LockScript)
+	}},
+}
+
+// keyHash returns the hex encoded key hash a pay-to-address script is locked with, nil for the other scripts
+func keyHash(script []byte) interface{} {
+	switch txvm.ScriptType(script) {
+	case txvm.PubKeyHashScriptType, txvm.MultisigScriptType, txvm.SchnorrScriptType, txvm.RelativeLockScriptType:
+		return hex.EncodeToString(script[3:23])
+	}
+	return nil
+}
+
+var fieldsByKind = map[string][]field{
+	BlockRecords: blockFields,
+	TxRecords:    txFields,
+	UtxoRecords:  utxoFields,
+}
+
+// Fields returns the names of all fields of the records of the kind, in the order they are written by default
+func Fields(records string) ([]string, error) {
+	fields, ok := fieldsByKind[records]
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidOptions, "unknown records %s", records)
+	}
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.name)
+	}
+	return names, nil
+}
+
+// selectFields returns the fields of the kind selected by the options
+func selectFields(opts *Options) ([]field, error) {
+	all, ok := fieldsByKind[opts.Records]
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidOptions, "unknown records %s", opts.Records)
+	}
+	if len(opts.Fields) == 0 {
+		return all, nil
+	}
+	selected := make([]field, 0, len(opts.Fields))
+	for _, name := range opts.Fields {
+		found := false
+		for _, f := range all {
+			if f.name == name {
+				selected = append(selected, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Wrapf(ErrInvalidOptions, "unknown field %s of %s records", name, opts.Records)
+		}
+	}
+	return selected, nil
+}
+
+// Export writes the records of the blocks with height in [opts.Start, opts.End] of the chain to w, and returns the
+// number of records written
+// The blocks are read a batch at a time, so a range of any size can be exported. The export stops with the error of
+// ctx once it is done, leaving the records written so far.
+func Export(ctx context.Context, bc blockchain.IBlockchain, w io.Writer, opts Options) (int, error) {
+	fields, err := selectFields(&opts)
+	if err != nil {
+		return 0, err
+	}
+	var enc encoder
+	switch opts.Format {
+	case JSON:
+		enc = &jsonEncoder{w: bufio.NewWriter(w)}
+	case CSV:
+		enc = &csvEncoder{w: csv.NewWriter(w)}
+	default:
+		return 0, errors.Wrapf(ErrInvalidOptions, "unknown format %s", opts.Format)
+	}
+	it, err := bc.NewBlockIterator(opts.Start, opts.End)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	write := func(r *record) error {
+		count++
+		return enc.encode(fields, r)
+	}
+	if err := enc.header(fields); err != nil {
+		return 0, err
+	}
+	for {
+		blk, err := it.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		if err := walkBlock(blk, opts.Records, write); err != nil {
+			return count, err
+		}
+	}
+	return count, enc.flush()
+}
+
+// walkBlock calls write with each record of the kind of the block
+func walkBlock(blk *blockchain.Block, records string, write func(r *record) error) error {
+	if records == BlockRecords {
+		return write(&record{blk: blk})
+	}
+	for i, tx := range blk.Tranxs {
+		if records == TxRecords {
+			if err := write(&record{blk: blk, tx: tx, index: i}); err != nil {
+				return err
+			}
+			continue
+		}
+		if !tx.IsCoinbase() {
+			for j, in := range tx.TxIn {
+				if err := write(&record{blk: blk, tx: tx, index: j, event: SpendEvent, in: in}); err != nil {
+					return err
+				}
+			}
+		}
+		for j, out := range tx.TxOut {
+			if err := write(&record{blk: blk, tx: tx, index: j, event: CreateEvent, out: out}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encoder writes the records in a format
+type encoder interface {
+	header(fields []field) error
+	encode(fields []field, r *record) error
+	flush() error
+}
+
+// jsonEncoder writes a JSON object per line, the fields in the selected order and the missing ones as null
+type jsonEncoder struct {
+	w *bufio.Writer
+}
+
+func (e *jsonEncoder) header([]field) error {
+	return nil
+}
+
+func (e *jsonEncoder) encode(fields []field, r *record) error {
+	var line bytes.Buffer
+	line.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.name)
+		value, err := json.Marshal(f.value(r))
+		if err != nil {
+			return err
+		}
+		line.Write(name)
+		line.WriteByte(':')
+		line.Write(value)
+	}
+	line.WriteString("}\n")
+	_, err := e.w.Write(line.Bytes())
+	return err
+}
+
+func (e *jsonEncoder) flush() error {
+	return e.w.Flush()
+}
+
+// csvEncoder writes a header row of the field names followed by a row per record, the missing fields being empty
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) header(fields []field) error {
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.name)
+	}
+	return e.w.Write(names)
+}
+
+func (e *csvEncoder) encode(fields []field, r *record) error {
+	row := make([]string, 0, len(fields))
+	for _, f := range fields {
+		row = append(row, csvValue(f.value(r)))
+	}
+	return e.w.Write(row)
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvValue formats the value of a field as a CSV cell
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	}
+	return ""
+}
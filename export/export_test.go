@@ -0,0 +1,104 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package export
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/test/testutil"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+func TestExport(t *testing.T) {
+	assert := assert.New(t)
+
+	bc, err := testutil.NewBlockchain(config.Chain{}, 10)
+	assert.Nil(err)
+	defer bc.Close()
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["alfa"]), 3,
+		[]*blockchain.Payee{blockchain.NewPayee(ta.Addrinfo["bravo"].Address, 3)})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*blockchain.Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+
+	run := func(opts Options) (string, int, error) {
+		var buf bytes.Buffer
+		count, err := Export(context.Background(), bc, &buf, opts)
+		return buf.String(), count, err
+	}
+
+	// a JSON object per block
+	out, count, err := run(Options{Records: BlockRecords, Format: JSON, Start: 0, End: 1})
+	assert.Nil(err)
+	assert.Equal(2, count)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	assert.Equal(2, len(lines))
+	var record map[string]interface{}
+	assert.Nil(json.Unmarshal([]byte(lines[1]), &record))
+	hash := blk.HashBlock()
+	assert.Equal(hex.EncodeToString(hash[:]), record["hash"])
+	assert.Equal(float64(1), record["height"])
+	assert.Equal(float64(2), record["num_txs"])
+	names, err := Fields(BlockRecords)
+	assert.Nil(err)
+	assert.Equal(len(names), len(record))
+
+	// the selected fields of the txs in CSV, in the order selected
+	out, count, err = run(Options{Records: TxRecords, Format: CSV, Fields: []string{"index", "coinbase", "hash"}, Start: 1, End: 1})
+	assert.Nil(err)
+	assert.Equal(2, count)
+	expected := "index,coinbase,hash\n"
+	for i, tx := range blk.Tranxs {
+		expected += fmt.Sprintf("%d,%t,%s\n", i, tx.IsCoinbase(), hexOf(tx))
+	}
+	assert.Equal(expected, out)
+
+	// the spends of a tx come before its outputs, which are joined to by the outpoint
+	out, count, err = run(Options{Records: UtxoRecords, Format: CSV, Fields: []string{"event", "out_tx_hash", "out_index", "value", "key_hash"}, Start: 1, End: 1})
+	assert.Nil(err)
+	lines = strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	assert.Equal(len(lines)-1, count)
+	assert.Equal(len(tx.TxIn)+len(tx.TxOut)+len(blk.Tranxs[1].TxOut), count)
+	assert.Equal("spend,"+hex.EncodeToString(tx.TxIn[0].TxHash)+",0,,", lines[1])
+	bravo := hex.EncodeToString(iotxaddress.GetPubkeyHash(ta.Addrinfo["bravo"].Address))
+	assert.Equal("create,"+hexOf(tx)+",0,3,"+bravo, lines[1+len(tx.TxIn)])
+
+	// invalid options
+	_, _, err = run(Options{Records: "receipt", Format: JSON})
+	assert.Equal(ErrInvalidOptions, errors.Cause(err))
+	_, _, err = run(Options{Records: TxRecords, Format: "xml"})
+	assert.Equal(ErrInvalidOptions, errors.Cause(err))
+	_, _, err = run(Options{Records: TxRecords, Format: JSON, Fields: []string{"event"}})
+	assert.Equal(ErrInvalidOptions, errors.Cause(err))
+	_, _, err = run(Options{Records: TxRecords, Format: JSON, Start: 1, End: 2})
+	assert.NotNil(err)
+
+	// the export stops once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Export(ctx, bc, &bytes.Buffer{}, Options{Records: BlockRecords, Format: JSON, End: 1})
+	assert.Equal(context.Canceled, err)
+}
+
+func hexOf(tx *blockchain.Tx) string {
+	hash := tx.Hash()
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package faucet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/txpool"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+var log = logger.New("faucet")
+
+// FundPath is the path paying the amount of the config to the address of a POST request, e.g., POST ?address=io1...
+const FundPath = "/fund"
+
+// Payment is the transaction paying the faucet amount to an address, as replied by the faucet
+type Payment struct {
+	TxHash  string `json:"txHash"`
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+}
+
+// Server pays the amount of the config from the faucet account to the testnet addresses requesting it over HTTP, at
+// most once per interval of the config to the same address
+type Server struct {
+	config      config.Faucet
+	blockchain  blockchain.IBlockchain
+	txpool      txpool.TxPool
+	signer      wallet.Signer
+	broadcastcb func(proto.Message) error
+	httpserver  *http.Server
+
+	// mu serializes the payments, so that they neither spend the same UTXO nor pay the same address twice
+	mu       sync.Mutex
+	lastPaid map[string]time.Time
+}
+
+// NewServer creates an instance of the faucet server paying with the signer of the faucet account, the transactions
+// accepted into the txpool are broadcast with the broadcast callback
+func NewServer(c config.Faucet, bc blockchain.IBlockchain, tp txpool.TxPool, signer wallet.Signer,
+	bcb func(proto.Message) error) (*Server, error) {
+	if signer == nil || bcb == nil {
+		return nil, errors.New("cannot new faucet server with nil signer or broadcast callback")
+	}
+	if signer.Address() != c.Account {
+		return nil, errors.Errorf("signer of %s cannot pay from faucet account %s", signer.Address(), c.Account)
+	}
+	return &Server{
+		config:      c,
+		blockchain:  bc,
+		txpool:      tp,
+		signer:      signer,
+		broadcastcb: bcb,
+		lastPaid:    make(map[string]time.Time),
+	}, nil
+}
+
+// Handler serves the faucet requests
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(FundPath, s.handleFund)
+	return mux
+}
+
+func (s *Server) handleFund(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	address := r.FormValue("address")
+	if !iotxaddress.ValidateAddress(address) || iotxaddress.ValidateNetwork(address, true) != nil {
+		http.Error(w, "invalid testnet address "+address, http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.prune(now)
+	if last, ok := s.lastPaid[address]; ok {
+		wait := last.Add(s.config.Interval).Sub(now)
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait/time.Second)+1))
+		http.Error(w, "address has been paid recently", http.StatusTooManyRequests)
+		return
+	}
+	hash, err := s.pay(address)
+	if err != nil {
+		log.Errorf("Failed to pay %s: %v", address, err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	s.lastPaid[address] = now
+	log.Infof("Paid %d to %s in tx %x", s.config.Amount, address, hash)
+	w.Header().Set("Content-Type", "application/json")
+	payment := Payment{TxHash: hex.EncodeToString(hash[:]), Address: address, Amount: s.config.Amount}
+	if err := json.NewEncoder(w).Encode(payment); err != nil {
+		log.Errorf("Failed to write faucet reply: %v", err)
+	}
+}
+
+// pay creates the transaction paying the faucet amount to the address, and hands it to the txpool before broadcasting
+// it, the caller has to hold the lock
+func (s *Server) pay(address string) ([]byte, error) {
+	payees := []*blockchain.Payee{{Address: address, Amount: s.config.Amount}}
+	tx, err := s.blockchain.CreateTransaction(s.signer, s.config.Amount, payees)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.txpool.AcceptTransaction(tx); err != nil {
+		s.blockchain.ReleaseTxInputs(tx)
+		return nil, err
+	}
+	if err := s.broadcastcb(tx.ConvertToTxPb()); err != nil {
+		return nil, err
+	}
+	hash := tx.Hash()
+	return hash[:], nil
+}
+
+// prune drops the addresses paid before the interval, which may request again, the caller has to hold the lock
+func (s *Server) prune(now time.Time) {
+	for address, last := range s.lastPaid {
+		if now.Sub(last) >= s.config.Interval {
+			delete(s.lastPaid, address)
+		}
+	}
+}
+
+// Start starts the faucet server
+func (s *Server) Start() error {
+	if s.config.Addr == "" {
+		log.Warning("Faucet service is not configured")
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return errors.Wrap(err, "faucet server failed to listen")
+	}
+	log.Infof("Faucet server is listening on %v, paying %d from %s", lis.Addr().String(), s.config.Amount,
+		s.config.Account)
+
+	s.httpserver = &http.Server{Handler: s.Handler()}
+	go func() {
+		if err := s.httpserver.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Faucet server failed to serve: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop stops the faucet server
+func (s *Server) Stop() error {
+	if s.httpserver != nil {
+		return s.httpserver.Close()
+	}
+	return nil
+}
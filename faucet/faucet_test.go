@@ -0,0 +1,118 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package faucet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_txpool"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+func fund(t *testing.T, server *httptest.Server, address string) (int, string) {
+	resp, err := http.PostForm(server.URL+FundPath, url.Values{"address": {address}})
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func testnetAddress(t *testing.T) string {
+	chainid := make([]byte, 4)
+	cm.MachineEndian.PutUint32(chainid, 1)
+	addr, err := iotxaddress.NewAddress(true, 0x01, chainid)
+	require.Nil(t, err)
+	return addr.Address
+}
+
+func TestFaucet(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mbc := mock_blockchain.NewMockIBlockchain(ctrl)
+	mtp := mock_txpool.NewMockTxPool(ctrl)
+	signer := wallet.NewKeySigner(ta.Addrinfo["miner"])
+	broadcast := 0
+	bcb := func(proto.Message) error {
+		broadcast++
+		return nil
+	}
+	cfg := config.Faucet{Account: ta.Addrinfo["miner"].Address, Amount: 10, Interval: time.Hour}
+	_, err := NewServer(cfg, mbc, mtp, wallet.NewKeySigner(ta.Addrinfo["alfa"]), bcb)
+	assert.NotNil(err)
+	s, err := NewServer(cfg, mbc, mtp, signer, bcb)
+	require.Nil(err)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	// an address of the testnet is paid once per interval
+	address := testnetAddress(t)
+	tx := blockchain.NewTx(1, nil, []*blockchain.TxOutput{blockchain.NewTxOutput(10, 0)}, 0)
+	payees := []*blockchain.Payee{{Address: address, Amount: 10}}
+	mbc.EXPECT().CreateTransaction(signer, uint64(10), payees).Return(tx, nil).Times(2)
+	mtp.EXPECT().AcceptTransaction(tx).Return(nil, nil).Times(2)
+	code, body := fund(t, server, address)
+	require.Equal(http.StatusOK, code, body)
+	payment := Payment{}
+	require.Nil(json.Unmarshal([]byte(body), &payment))
+	hash := tx.Hash()
+	assert.Equal(Payment{TxHash: hex.EncodeToString(hash[:]), Address: address, Amount: 10}, payment)
+	assert.Equal(1, broadcast)
+
+	code, _ = fund(t, server, address)
+	assert.Equal(http.StatusTooManyRequests, code)
+	s.mu.Lock()
+	s.lastPaid[address] = time.Now().Add(-time.Hour)
+	s.mu.Unlock()
+	code, _ = fund(t, server, address)
+	assert.Equal(http.StatusOK, code)
+	assert.Equal(2, broadcast)
+
+	// an address is not rate limited after a failed payment
+	other := testnetAddress(t)
+	mbc.EXPECT().CreateTransaction(signer, uint64(10), gomock.Any()).Return(tx, nil).Times(2)
+	mtp.EXPECT().AcceptTransaction(tx).Return(nil, errors.New("rejected")).Times(1)
+	mbc.EXPECT().ReleaseTxInputs(tx).Times(1)
+	code, _ = fund(t, server, other)
+	assert.Equal(http.StatusServiceUnavailable, code)
+	mtp.EXPECT().AcceptTransaction(tx).Return(nil, nil).Times(1)
+	code, _ = fund(t, server, other)
+	assert.Equal(http.StatusOK, code)
+	assert.Equal(3, broadcast)
+
+	// invalid requests
+	code, _ = fund(t, server, "Alice")
+	assert.Equal(http.StatusBadRequest, code)
+	code, _ = fund(t, server, ta.Addrinfo["alfa"].Address)
+	assert.Equal(http.StatusBadRequest, code)
+	resp, err := http.Get(server.URL + FundPath + "?address=" + address)
+	require.Nil(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+}
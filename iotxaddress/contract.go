@@ -0,0 +1,34 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package iotxaddress
+
+import (
+	"encoding/binary"
+)
+
+// ContractVersion is the address version of smart contracts
+const ContractVersion = 0x03
+
+// CreateContractAddress returns the address of the contract deployed by the owner with the given nonce, which is on the
+// same network and chain as the owner
+// A contract has no key pair, its address is derived from the public key hash of the owner followed by the nonce.
+func CreateContractAddress(owner string, nonce uint64) (string, error) {
+	info, err := DecodeAddress(owner)
+	if err != nil {
+		return "", ErrInvalidAddress
+	}
+	seed := make([]byte, 8)
+	binary.BigEndian.PutUint64(seed, nonce)
+	seed = append(append([]byte{}, info.PubKeyHash...), seed...)
+	return GetAddress(seed, info.IsTestnet, ContractVersion, info.ChainID)
+}
+
+// IsContractAddress checks if the address is the address of a smart contract
+func IsContractAddress(address string) bool {
+	info, err := DecodeAddress(address)
+	return err == nil && info.Version == ContractVersion
+}
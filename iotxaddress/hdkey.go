@@ -0,0 +1,123 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package iotxaddress
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key
+	HardenedKeyStart = 0x80000000
+	// CoinType is the BIP44 coin type of IoTeX
+	CoinType = 304
+
+	masterKeySeed = "ed25519 seed"
+)
+
+var (
+	// ErrInvalidDerivation is returned when the derivation path or child index is invalid.
+	ErrInvalidDerivation = errors.New("invalid derivation")
+)
+
+// HDKey is an extended key of SLIP-0010 hierarchical deterministic derivation for ed25519
+// Only hardened child keys can be derived from an ed25519 key.
+type HDKey struct {
+	Key       []byte
+	ChainCode []byte
+	Depth     byte
+	Index     uint32
+}
+
+// NewMasterKey returns the master key of the seed
+func NewMasterKey(seed []byte) (*HDKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("seed is %d bytes, expecting 16 to 64", len(seed))
+	}
+	mac := hmac.New(sha512.New, []byte(masterKeySeed))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	return &HDKey{Key: sum[:32], ChainCode: sum[32:]}, nil
+}
+
+// Child derives the hardened child key of the index
+func (k *HDKey) Child(index uint32) (*HDKey, error) {
+	if index < HardenedKeyStart {
+		return nil, ErrInvalidDerivation
+	}
+	data := make([]byte, 0, 37)
+	data = append(data, 0x00)
+	data = append(data, k.Key...)
+	i := make([]byte, 4)
+	binary.BigEndian.PutUint32(i, index)
+	data = append(data, i...)
+
+	mac := hmac.New(sha512.New, k.ChainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return &HDKey{Key: sum[:32], ChainCode: sum[32:], Depth: k.Depth + 1, Index: index}, nil
+}
+
+// Derive derives the key of the path relative to the key, e.g. "m/44'/304'/0'/0'/0'"
+func (k *HDKey) Derive(path string) (*HDKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indexes {
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Address returns the key pair and address of the key
+func (k *HDKey) Address(isTestnet bool, version byte, chainid []byte) (*Address, error) {
+	priv := ed25519.NewKeyFromSeed(k.Key)
+	pub := []byte(priv.Public().(ed25519.PublicKey))
+	addr, err := GetAddress(pub, isTestnet, version, chainid)
+	if err != nil {
+		return nil, err
+	}
+	return &Address{PublicKey: pub, PrivateKey: []byte(priv), Address: addr}, nil
+}
+
+// ParseDerivationPath parses the derivation path into child indexes, where every level has to be hardened
+func ParseDerivationPath(path string) ([]uint32, error) {
+	levels := strings.Split(path, "/")
+	if len(levels) == 0 || levels[0] != "m" {
+		return nil, ErrInvalidDerivation
+	}
+	indexes := []uint32{}
+	for _, level := range levels[1:] {
+		if !strings.HasSuffix(level, "'") {
+			return nil, ErrInvalidDerivation
+		}
+		index, err := strconv.ParseUint(strings.TrimSuffix(level, "'"), 10, 31)
+		if err != nil {
+			return nil, ErrInvalidDerivation
+		}
+		indexes = append(indexes, uint32(index)+HardenedKeyStart)
+	}
+	return indexes, nil
+}
+
+// DerivationPath returns the BIP44 path of the address of the account, where change selects the external chain of
+// receive addresses (0) or the internal chain of change addresses (1)
+func DerivationPath(account uint32, change uint32, index uint32) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/%d'/%d'", CoinType, account, change, index)
+}
@@ -18,6 +18,7 @@ package iotxaddress
 
 import (
 	"errors"
+	"strings"
 
 	"golang.org/x/crypto/blake2b"
 
@@ -30,11 +31,17 @@ var (
 	ErrInvalidVersion = errors.New("invalid version")
 	// ErrInvalidChainID is returned when invalid chain ID has been detected.
 	ErrInvalidChainID = errors.New("invalid chain ID")
+	// ErrInvalidAddress is returned when an address cannot be decoded.
+	ErrInvalidAddress = errors.New("invalid address")
+	// ErrInvalidNetwork is returned when the address is not of the expected network.
+	ErrInvalidNetwork = errors.New("invalid network")
 )
 
 const (
 	mainnetPrefix = "io"
 	testnetPrefix = "it"
+	// payloadLength is the length of the version, the chain ID and the public key hash
+	payloadLength = 25
 )
 
 // Address contains a pair of key and a string address
@@ -68,73 +75,70 @@ func GetAddress(pub []byte, isTestnet bool, version byte, chainid []byte) (strin
 		return "", ErrInvalidChainID
 	}
 
-	hrp := mainnetPrefix
-	if isTestnet {
-		hrp = testnetPrefix
-	}
+	return encodeAddress(isTestnet, append([]byte{version}, append(chainid, HashPubKey(pub)...)...))
+}
 
-	payload := append([]byte{version}, append(chainid, HashPubKey(pub)...)...)
-	// Group the payload into 5 bit groups.
-	grouped, err := bech32.ConvertBits(payload, 8, 5, true)
-	if err != nil {
-		return "", err
-	}
-	addr, err := bech32.Encode(hrp, grouped)
-	if err != nil {
-		return "", err
-	}
-	return addr, nil
+// AddressInfo is the decoded content of an address
+type AddressInfo struct {
+	IsTestnet  bool
+	Version    byte
+	ChainID    []byte
+	PubKeyHash []byte
 }
 
-// GetPubkeyHash extracts public key hash from address
-func GetPubkeyHash(address string) []byte {
-	hrp, grouped, err := bech32.Decode(address)
-	if err != nil {
-		return nil
+// DecodeAddress decodes the address strictly, which has to be in lower case with a valid checksum, the prefix of
+// either network and exactly the version, chain ID and public key hash in its data part
+func DecodeAddress(address string) (*AddressInfo, error) {
+	if strings.ToLower(address) != address {
+		return nil, ErrInvalidAddress
 	}
-
-	// Exclude the separator, version and chainID
-	payload, err := bech32.ConvertBits(grouped[:], 5, 8, false)
+	hrp, grouped, err := bech32.Decode(address)
 	if err != nil {
-		return nil
+		return nil, ErrInvalidAddress
 	}
-
 	if hrp != mainnetPrefix && hrp != testnetPrefix {
-		return nil
+		return nil, ErrInvalidNetwork
 	}
-	if !isValidVersion(payload[0]) {
-		return nil
-	}
-	if !isValidChainID(payload[1:5]) {
-		return nil
+	payload, err := bech32.ConvertBits(grouped, 5, 8, false)
+	if err != nil || len(payload) != payloadLength {
+		return nil, ErrInvalidAddress
 	}
-
-	return payload[5:25]
+	if !isValidVersion(payload[0]) {
+		return nil, ErrInvalidVersion
+	}
+	return &AddressInfo{
+		IsTestnet:  hrp == testnetPrefix,
+		Version:    payload[0],
+		ChainID:    payload[1:5],
+		PubKeyHash: payload[5:],
+	}, nil
 }
 
-// ValidateAddress check if address if valid.
-func ValidateAddress(address string) bool {
-	hrp, grouped, err := bech32.Decode(address)
+// ValidateNetwork returns error if the address is invalid or not of the given network
+func ValidateNetwork(address string, isTestnet bool) error {
+	info, err := DecodeAddress(address)
 	if err != nil {
-		return false
+		return err
 	}
+	if info.IsTestnet != isTestnet {
+		return ErrInvalidNetwork
+	}
+	return nil
+}
 
-	// Exclude the separator, version and chainID
-	payload, err := bech32.ConvertBits(grouped[:], 5, 8, false)
+// GetPubkeyHash extracts public key hash from address
+func GetPubkeyHash(address string) []byte {
+	info, err := DecodeAddress(address)
 	if err != nil {
-		return false
+		return nil
 	}
+	return info.PubKeyHash
+}
 
-	if hrp != mainnetPrefix && hrp != testnetPrefix {
-		return false
-	}
-	if !isValidVersion(payload[0]) {
-		return false
-	}
-	if !isValidChainID(payload[1:5]) {
-		return false
-	}
-	return true
+// ValidateAddress check if address if valid.
+func ValidateAddress(address string) bool {
+	_, err := DecodeAddress(address)
+	return err == nil
 }
 
 // HashPubKey returns the hash of public key
@@ -144,6 +148,20 @@ func HashPubKey(pubKey []byte) []byte {
 	return digest[7:27]
 }
 
+// encodeAddress encodes the payload of the version, chain ID and public key hash with the prefix of the network
+func encodeAddress(isTestnet bool, payload []byte) (string, error) {
+	hrp := mainnetPrefix
+	if isTestnet {
+		hrp = testnetPrefix
+	}
+	// Group the payload into 5 bit groups.
+	grouped, err := bech32.ConvertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.Encode(hrp, grouped)
+}
+
 func isValidVersion(version byte) bool {
 	if version >= 0x01 {
 		return true
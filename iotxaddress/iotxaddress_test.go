@@ -8,11 +8,14 @@ package iotxaddress
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/ed25519"
+
+	"github.com/iotexproject/iotex-core/iotxaddress/bech32"
 )
 
 // TestNewAddress tests create new asset address.
@@ -54,3 +57,237 @@ func TestGetandValidateAddress(t *testing.T) {
 	addr = strings.Replace(addr, "1", "?", -1)
 	assert.False(ValidateAddress(addr))
 }
+
+func TestDecodeAddress(t *testing.T) {
+	assert := assert.New(t)
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(err)
+	chainid := []byte{0x00, 0x00, 0x00, 0x01}
+
+	addr, err := GetAddress(pub, true, byte(0x01), chainid)
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(addr, testnetPrefix))
+	info, err := DecodeAddress(addr)
+	assert.Nil(err)
+	assert.Equal(&AddressInfo{IsTestnet: true, Version: 0x01, ChainID: chainid, PubKeyHash: HashPubKey(pub)}, info)
+	assert.Nil(ValidateNetwork(addr, true))
+	assert.Equal(ErrInvalidNetwork, ValidateNetwork(addr, false))
+
+	// only the canonical lower case encoding of the expected length and prefix is accepted
+	_, err = DecodeAddress(strings.ToUpper(addr))
+	assert.Equal(ErrInvalidAddress, err)
+	_, err = DecodeAddress(addr[:len(addr)-1] + "q")
+	assert.Equal(ErrInvalidAddress, err)
+	grouped, err := bech32.ConvertBits(append([]byte{0x01}, chainid...), 8, 5, true)
+	assert.Nil(err)
+	short, err := bech32.Encode(mainnetPrefix, grouped)
+	assert.Nil(err)
+	_, err = DecodeAddress(short)
+	assert.Equal(ErrInvalidAddress, err)
+	assert.Nil(GetPubkeyHash(short))
+	grouped, err = bech32.ConvertBits(append(append([]byte{0x01}, chainid...), HashPubKey(pub)...), 8, 5, true)
+	assert.Nil(err)
+	other, err := bech32.Encode("ix", grouped)
+	assert.Nil(err)
+	_, err = DecodeAddress(other)
+	assert.Equal(ErrInvalidNetwork, err)
+
+	// the address is converted to the legacy public key hash and to the other network
+	legacy, err := ToLegacyAddress(addr)
+	assert.Nil(err)
+	assert.Equal("0x"+hex.EncodeToString(HashPubKey(pub)), legacy)
+	converted, err := FromLegacyAddress(legacy, true, 0x01, chainid)
+	assert.Nil(err)
+	assert.Equal(addr, converted)
+	_, err = FromLegacyAddress(legacy[:10], true, 0x01, chainid)
+	assert.Equal(ErrInvalidAddress, err)
+	mainnet, err := ConvertNetwork(addr, false)
+	assert.Nil(err)
+	assert.Nil(ValidateNetwork(mainnet, false))
+	assert.Equal(GetPubkeyHash(addr), GetPubkeyHash(mainnet))
+	converted, err = FromLegacyAddress(legacy, false, 0x01, chainid)
+	assert.Nil(err)
+	assert.Equal(mainnet, converted)
+}
+
+func TestCreateMultisigAddress(t *testing.T) {
+	assert := assert.New(t)
+	var pubkeys [][]byte
+	for i := 0; i < 3; i++ {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		assert.Nil(err)
+		pubkeys = append(pubkeys, pub)
+	}
+
+	addr, err := CreateMultisigAddress(2, pubkeys, false, []byte{0x00, 0x00, 0x00, 0x01})
+	assert.Nil(err)
+	assert.True(ValidateAddress(addr.Address))
+	assert.True(IsMultisigAddress(addr.Address))
+	assert.Equal(HashPubKey(addr.PublicKey), GetPubkeyHash(addr.Address))
+	assert.Nil(addr.PrivateKey)
+
+	single, err := GetAddress(pubkeys[0], false, byte(0x01), []byte{0x00, 0x00, 0x00, 0x01})
+	assert.Nil(err)
+	assert.False(IsMultisigAddress(single))
+
+	m, keys, err := ParseMultisigKeys(addr.PublicKey)
+	assert.Nil(err)
+	assert.Equal(2, m)
+	assert.Equal(pubkeys, keys)
+
+	// order of the keys matters
+	swapped, err := CreateMultisigAddress(2, [][]byte{pubkeys[1], pubkeys[0], pubkeys[2]}, false, []byte{0x00, 0x00, 0x00, 0x01})
+	assert.Nil(err)
+	assert.NotEqual(addr.Address, swapped.Address)
+
+	_, err = CreateMultisigAddress(0, pubkeys, false, []byte{0x00, 0x00, 0x00, 0x01})
+	assert.Equal(ErrInvalidMultisig, err)
+	_, err = CreateMultisigAddress(4, pubkeys, false, []byte{0x00, 0x00, 0x00, 0x01})
+	assert.Equal(ErrInvalidMultisig, err)
+	_, err = CreateMultisigAddress(1, [][]byte{{0x01, 0x02}}, false, []byte{0x00, 0x00, 0x00, 0x01})
+	assert.Equal(ErrInvalidMultisig, err)
+	_, _, err = ParseMultisigKeys(addr.PublicKey[:len(addr.PublicKey)-1])
+	assert.Equal(ErrInvalidMultisig, err)
+}
+
+func TestCreateContractAddress(t *testing.T) {
+	assert := assert.New(t)
+	owner, err := NewAddress(true, byte(0x01), []byte{0x00, 0x00, 0x00, 0x01})
+	assert.Nil(err)
+
+	addr, err := CreateContractAddress(owner.Address, 1)
+	assert.Nil(err)
+	assert.True(ValidateAddress(addr))
+	assert.True(IsContractAddress(addr))
+	assert.False(IsContractAddress(owner.Address))
+	assert.True(strings.HasPrefix(addr, testnetPrefix))
+
+	// the address only depends on the owner and the nonce
+	same, err := CreateContractAddress(owner.Address, 1)
+	assert.Nil(err)
+	assert.Equal(addr, same)
+	other, err := CreateContractAddress(owner.Address, 2)
+	assert.Nil(err)
+	assert.NotEqual(addr, other)
+
+	_, err = CreateContractAddress("io1invalid", 1)
+	assert.Equal(ErrInvalidAddress, err)
+}
+
+func TestSchnorrAddress(t *testing.T) {
+	assert := assert.New(t)
+	chainid := []byte{0x00, 0x00, 0x00, 0x01}
+	addr, err := NewSchnorrAddress(true, chainid)
+	assert.Nil(err)
+	assert.True(ValidateAddress(addr.Address))
+	assert.True(IsSchnorrAddress(addr.Address))
+	assert.False(IsMultisigAddress(addr.Address))
+	other, err := NewAddress(true, byte(0x01), chainid)
+	assert.Nil(err)
+	assert.False(IsSchnorrAddress(other.Address))
+
+	// the aggregated address depends on the order of the keys
+	cosigner, err := NewSchnorrAddress(true, chainid)
+	assert.Nil(err)
+	agg, err := CreateAggregatedAddress([][]byte{addr.PublicKey, cosigner.PublicKey}, true, chainid)
+	assert.Nil(err)
+	assert.True(IsSchnorrAddress(agg.Address))
+	assert.Empty(agg.PrivateKey)
+	same, err := CreateAggregatedAddress([][]byte{addr.PublicKey, cosigner.PublicKey}, true, chainid)
+	assert.Nil(err)
+	assert.Equal(agg.Address, same.Address)
+	swapped, err := CreateAggregatedAddress([][]byte{cosigner.PublicKey, addr.PublicKey}, true, chainid)
+	assert.Nil(err)
+	assert.NotEqual(agg.Address, swapped.Address)
+
+	_, err = CreateAggregatedAddress([][]byte{other.PublicKey}, true, chainid)
+	assert.NotNil(err)
+}
+
+func TestMnemonic(t *testing.T) {
+	assert := assert.New(t)
+
+	// BIP39 test vectors
+	mnemonic, err := EntropyToMnemonic(make([]byte, 32))
+	assert.Nil(err)
+	assert.Equal(strings.Repeat("abandon ", 23)+"art", mnemonic)
+	seed, err := MnemonicToSeed(mnemonic, "TREZOR")
+	assert.Nil(err)
+	assert.Equal("bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8", hex.EncodeToString(seed))
+
+	entropy, _ := hex.DecodeString("7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f")
+	mnemonic, err = EntropyToMnemonic(entropy)
+	assert.Nil(err)
+	assert.Equal("legal winner thank year wave sausage worth useful legal winner thank yellow", mnemonic)
+	decoded, err := MnemonicToEntropy(mnemonic)
+	assert.Nil(err)
+	assert.Equal(entropy, decoded)
+
+	mnemonic, err = NewMnemonic()
+	assert.Nil(err)
+	assert.Equal(24, len(strings.Fields(mnemonic)))
+	assert.True(ValidateMnemonic(mnemonic))
+
+	// wrong checksum, unknown word and wrong length
+	assert.False(ValidateMnemonic(strings.Repeat("abandon ", 24)))
+	assert.False(ValidateMnemonic(strings.Repeat("abandon ", 23) + "iotex"))
+	assert.False(ValidateMnemonic(strings.Repeat("abandon ", 11)))
+	_, err = EntropyToMnemonic(make([]byte, 15))
+	assert.Equal(ErrInvalidEntropy, err)
+}
+
+func TestHDKey(t *testing.T) {
+	assert := assert.New(t)
+
+	// SLIP-0010 ed25519 test vector 1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	assert.Nil(err)
+	assert.Equal("2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7", hex.EncodeToString(master.Key))
+	assert.Equal("90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb", hex.EncodeToString(master.ChainCode))
+	addr, err := master.Address(false, 0x01, []byte{0x00, 0x00, 0x00, 0x01})
+	assert.Nil(err)
+	assert.Equal("a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed", hex.EncodeToString(addr.PublicKey))
+
+	child, err := master.Derive("m/0'")
+	assert.Nil(err)
+	assert.Equal("68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3", hex.EncodeToString(child.Key))
+	assert.Equal("8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69", hex.EncodeToString(child.ChainCode))
+	assert.Equal(byte(1), child.Depth)
+	assert.Equal(uint32(HardenedKeyStart), child.Index)
+
+	// ed25519 keys only derive hardened children
+	_, err = master.Child(0)
+	assert.Equal(ErrInvalidDerivation, err)
+	_, err = master.Derive("m/0")
+	assert.Equal(ErrInvalidDerivation, err)
+	_, err = master.Derive("44'/0'")
+	assert.Equal(ErrInvalidDerivation, err)
+
+	indexes, err := ParseDerivationPath(DerivationPath(0, 1, 2))
+	assert.Nil(err)
+	assert.Equal([]uint32{HardenedKeyStart + 44, HardenedKeyStart + CoinType, HardenedKeyStart, HardenedKeyStart + 1, HardenedKeyStart + 2}, indexes)
+}
+
+func TestSignMessage(t *testing.T) {
+	assert := assert.New(t)
+	chainid := []byte{0x00, 0x00, 0x00, 0x01}
+	addr, err := NewAddress(false, 0x01, chainid)
+	assert.Nil(err)
+	other, err := NewAddress(false, 0x01, chainid)
+	assert.Nil(err)
+
+	msg := []byte("I own this address")
+	sig := SignMessage(addr, msg)
+	assert.True(VerifyMessage(addr.Address, msg, sig))
+	assert.False(VerifyMessage(addr.Address, []byte("I own another address"), sig))
+	assert.False(VerifyMessage(other.Address, msg, sig))
+	assert.False(VerifyMessage(addr.Address, msg, sig[1:]))
+	assert.False(VerifyMessage("io1invalid", msg, sig))
+
+	// the key of another address cannot sign for the address, and the prefix separates messages from raw data
+	forged := MessageSignature(addr.PublicKey, SignMessage(other, msg)[ed25519.PublicKeySize:])
+	assert.False(VerifyMessage(addr.Address, msg, forged))
+	raw := MessageSignature(addr.PublicKey, ed25519.Sign(addr.PrivateKey, msg))
+	assert.False(VerifyMessage(addr.Address, msg, raw))
+}
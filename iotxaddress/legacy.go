@@ -0,0 +1,50 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package iotxaddress
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// legacyPrefix is the prefix of the hex encoding of a legacy address
+const legacyPrefix = "0x"
+
+// ToLegacyAddress returns the legacy form of the address, the hex encoding of its public key hash which identified the
+// account before the addresses carried the network, version and chain ID
+func ToLegacyAddress(address string) (string, error) {
+	info, err := DecodeAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return legacyPrefix + hex.EncodeToString(info.PubKeyHash), nil
+}
+
+// FromLegacyAddress returns the address of the network and chain for the public key hash of the legacy address
+func FromLegacyAddress(legacy string, isTestnet bool, version byte, chainid []byte) (string, error) {
+	pkHash, err := hex.DecodeString(strings.TrimPrefix(legacy, legacyPrefix))
+	if err != nil || len(pkHash) != payloadLength-5 {
+		return "", ErrInvalidAddress
+	}
+	if !isValidVersion(version) {
+		return "", ErrInvalidVersion
+	}
+	if !isValidChainID(chainid) {
+		return "", ErrInvalidChainID
+	}
+	return encodeAddress(isTestnet, append([]byte{version}, append(chainid, pkHash...)...))
+}
+
+// ConvertNetwork returns the address of the same version, chain ID and public key hash on the given network, e.g., to
+// carry the addresses of a testnet over to the mainnet
+func ConvertNetwork(address string, isTestnet bool) (string, error) {
+	info, err := DecodeAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return encodeAddress(isTestnet, append([]byte{info.Version}, append(info.ChainID, info.PubKeyHash...)...))
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package iotxaddress
+
+import (
+	"bytes"
+	"strconv"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+// MessagePrefix is prepended to the signed messages, so that a message signature cannot pass for the signature of a
+// transaction or of any other data signed by the key
+const MessagePrefix = "\x19IoTeX Signed Message:\n"
+
+// MessageHash returns the hash signed for the message, which is the blake2b hash of MessagePrefix followed by the
+// length of the message in decimal and the message
+func MessageHash(msg []byte) []byte {
+	data := append([]byte(MessagePrefix+strconv.Itoa(len(msg))), msg...)
+	hash := blake2b.Sum256(data)
+	return hash[:]
+}
+
+// MessageSignature returns the signature of a message proving the ownership of the address of the public key, which is
+// the public key followed by its signature of the message hash
+func MessageSignature(pubKey []byte, sig []byte) []byte {
+	return append(append([]byte{}, pubKey...), sig...)
+}
+
+// SignMessage signs the message with the key pair of the address, see MessageSignature
+func SignMessage(addr *Address, msg []byte) []byte {
+	return MessageSignature(addr.PublicKey, cp.Sign(addr.PrivateKey, MessageHash(msg)))
+}
+
+// VerifyMessage checks that the message is signed by the key pair of the address, without involving the chain
+func VerifyMessage(address string, msg []byte, sig []byte) bool {
+	if len(sig) != ed25519.PublicKeySize+ed25519.SignatureSize {
+		return false
+	}
+	pubKey := sig[:ed25519.PublicKeySize]
+	pkHash := GetPubkeyHash(address)
+	if pkHash == nil || !bytes.Equal(pkHash, HashPubKey(pubKey)) {
+		return false
+	}
+	return cp.Verify(pubKey, MessageHash(msg), sig[ed25519.PublicKeySize:])
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package iotxaddress
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// MnemonicEntropySize is the size of entropy in bytes of a 24-word mnemonic
+	MnemonicEntropySize = 32
+	// mnemonicSeedIterations is the number of PBKDF2 iterations stretching a mnemonic into a seed
+	mnemonicSeedIterations = 2048
+)
+
+var (
+	// ErrInvalidMnemonic is returned when the mnemonic has unknown words or a wrong checksum.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+	// ErrInvalidEntropy is returned when the entropy size is not a multiple of 4 bytes between 16 and 32.
+	ErrInvalidEntropy = errors.New("invalid entropy")
+
+	wordIndex map[string]int
+)
+
+func init() {
+	wordIndex = make(map[string]int, len(englishWords))
+	for i, word := range englishWords {
+		wordIndex[word] = i
+	}
+}
+
+// NewMnemonic returns a 24-word BIP39 mnemonic of new random entropy
+func NewMnemonic() (string, error) {
+	entropy := make([]byte, MnemonicEntropySize)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return EntropyToMnemonic(entropy)
+}
+
+// EntropyToMnemonic encodes the entropy as BIP39 mnemonic words, every 11 bits of the entropy followed by its
+// checksum maps to one word
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", ErrInvalidEntropy
+	}
+	checksum := sha256.Sum256(entropy)
+	bits := append(append([]byte{}, entropy...), checksum[0])
+	numWords := (len(entropy)*8 + len(entropy)/4) / 11
+
+	words := make([]string, numWords)
+	for i := range words {
+		words[i] = englishWords[readBits(bits, i*11, 11)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes the BIP39 mnemonic words into the entropy and checks its checksum
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, ErrInvalidMnemonic
+	}
+	bits := make([]byte, (len(words)*11+7)/8)
+	for i, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return nil, ErrInvalidMnemonic
+		}
+		writeBits(bits, i*11, 11, index)
+	}
+
+	size := len(words) * 4 / 3
+	entropy := bits[:size]
+	checksum := sha256.Sum256(entropy)
+	checksumBits := size / 4
+	if readBits(bits, size*8, checksumBits) != int(checksum[0]>>uint(8-checksumBits)) {
+		return nil, ErrInvalidMnemonic
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic checks if the mnemonic consists of known words with a valid checksum
+func ValidateMnemonic(mnemonic string) bool {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err == nil
+}
+
+// MnemonicToSeed returns the 64-byte seed of the mnemonic and the optional passphrase, from which the HD keys are
+// derived
+func MnemonicToSeed(mnemonic string, passphrase string) ([]byte, error) {
+	if !ValidateMnemonic(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), mnemonicSeedIterations, 64, sha512.New), nil
+}
+
+// readBits reads n big-endian bits starting at bit offset
+func readBits(buf []byte, offset int, n int) int {
+	v := 0
+	for i := offset; i < offset+n; i++ {
+		v = v<<1 | int(buf[i/8]>>uint(7-i%8)&1)
+	}
+	return v
+}
+
+// writeBits writes the lowest n bits of v big-endian starting at bit offset
+func writeBits(buf []byte, offset int, n int, v int) {
+	for i := 0; i < n; i++ {
+		if v>>uint(n-1-i)&1 == 1 {
+			pos := offset + i
+			buf[pos/8] |= 1 << uint(7-pos%8)
+		}
+	}
+}
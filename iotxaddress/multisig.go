@@ -0,0 +1,79 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package iotxaddress
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// MultisigVersion is the address version of M-of-N multisig addresses
+	MultisigVersion = 0x02
+	// MaxMultisigKeys is the maximum number of public keys of a multisig address
+	MaxMultisigKeys = 16
+)
+
+var (
+	// ErrInvalidMultisig is returned when the M-of-N multisig keys are invalid.
+	ErrInvalidMultisig = errors.New("invalid multisig keys")
+)
+
+// CreateMultisigAddress returns the address requiring M of the N public keys to spend, the order of the keys matters
+// The public key of the returned address is the encoded multisig keys and the private key is empty, since the
+// address is spent with signatures of the individual keys.
+func CreateMultisigAddress(m int, pubkeys [][]byte, isTestnet bool, chainid []byte) (*Address, error) {
+	keys, err := MultisigKeys(m, pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := GetAddress(keys, isTestnet, MultisigVersion, chainid)
+	if err != nil {
+		return nil, err
+	}
+	return &Address{PublicKey: keys, Address: addr}, nil
+}
+
+// IsMultisigAddress checks if the address is a multisig address
+func IsMultisigAddress(address string) bool {
+	info, err := DecodeAddress(address)
+	return err == nil && info.Version == MultisigVersion
+}
+
+// MultisigKeys encodes the M-of-N multisig keys as M, N followed by the N public keys
+func MultisigKeys(m int, pubkeys [][]byte) ([]byte, error) {
+	n := len(pubkeys)
+	if m < 1 || m > n || n > MaxMultisigKeys {
+		return nil, ErrInvalidMultisig
+	}
+	keys := []byte{byte(m), byte(n)}
+	for _, pubkey := range pubkeys {
+		if len(pubkey) != ed25519.PublicKeySize {
+			return nil, ErrInvalidMultisig
+		}
+		keys = append(keys, pubkey...)
+	}
+	return keys, nil
+}
+
+// ParseMultisigKeys decodes the M-of-N multisig keys encoded by MultisigKeys
+func ParseMultisigKeys(keys []byte) (int, [][]byte, error) {
+	if len(keys) < 2 {
+		return 0, nil, ErrInvalidMultisig
+	}
+	m, n := int(keys[0]), int(keys[1])
+	if m < 1 || m > n || n > MaxMultisigKeys || len(keys) != 2+n*ed25519.PublicKeySize {
+		return 0, nil, ErrInvalidMultisig
+	}
+	pubkeys := make([][]byte, n)
+	for i := range pubkeys {
+		start := 2 + i*ed25519.PublicKeySize
+		pubkeys[i] = keys[start : start+ed25519.PublicKeySize]
+	}
+	return m, pubkeys, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package iotxaddress
+
+import (
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+// SchnorrVersion is the address version of Schnorr public keys, whose outputs are spent with Schnorr signatures
+const SchnorrVersion = 0x04
+
+// NewSchnorrAddress returns a newly created Schnorr key pair together with the address derived.
+func NewSchnorrAddress(isTestnet bool, chainid []byte) (*Address, error) {
+	pub, pri, err := cp.NewSchnorrKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	addr, err := GetAddress(pub, isTestnet, SchnorrVersion, chainid)
+	if err != nil {
+		return nil, err
+	}
+	return &Address{PublicKey: pub, PrivateKey: pri, Address: addr}, nil
+}
+
+// CreateAggregatedAddress returns the Schnorr address of the key aggregated from the Schnorr public keys of the
+// cosigners, the order of the keys matters
+// Unlike a multisig address, all cosigners have to sign, and the output is spent with a single signature produced
+// jointly. The private key of the returned address is empty, since no one knows the aggregated private key.
+func CreateAggregatedAddress(pubkeys [][]byte, isTestnet bool, chainid []byte) (*Address, error) {
+	pub, err := cp.AggregateSchnorrPubKeys(pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := GetAddress(pub, isTestnet, SchnorrVersion, chainid)
+	if err != nil {
+		return nil, err
+	}
+	return &Address{PublicKey: pub, Address: addr}, nil
+}
+
+// IsSchnorrAddress checks if the address is a Schnorr address
+func IsSchnorrAddress(address string) bool {
+	info, err := DecodeAddress(address)
+	return err == nil && info.Version == SchnorrVersion
+}
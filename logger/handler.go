@@ -0,0 +1,48 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// LevelHandler serves the log levels, and sets the level of the module, or the default level without a module, to
+// the level of a POST request, e.g., POST ?module=blockchain&level=debug
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			level, err := ParseLevel(r.FormValue("level"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if module := r.FormValue("module"); module != "" {
+				SetModuleLevel(module, level)
+			} else {
+				SetLevel(level)
+			}
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		level, levels := Levels()
+		modules := make([]string, 0, len(levels))
+		for module := range levels {
+			modules = append(modules, module)
+		}
+		sort.Strings(modules)
+		fmt.Fprintf(w, "default=%s\n", level)
+		for _, module := range modules {
+			fmt.Fprintf(w, "%s=%s\n", module, levels[module])
+		}
+	})
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Level is the severity of a log line, the lines below the level of their module are dropped
+type Level int32
+
+// The levels in increasing severity
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// String returns the name of the level
+func (l Level) String() string {
+	if l < DebugLevel || l > ErrorLevel {
+		return strconv.Itoa(int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level of the name, case insensitive
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return Level(i), nil
+		}
+	}
+	if strings.EqualFold(name, "warning") {
+		return WarnLevel, nil
+	}
+	return 0, errors.Errorf("unknown log level %s", name)
+}
+
+var (
+	mutex        sync.RWMutex
+	output       io.Writer = os.Stderr
+	defaultLevel           = InfoLevel
+	moduleLevels           = map[string]Level{}
+)
+
+// SetOutput sets where the log lines are written to, stderr by default
+func SetOutput(w io.Writer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	output = w
+}
+
+// SetLevel sets the level of the modules without their own level
+func SetLevel(level Level) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	defaultLevel = level
+}
+
+// SetModuleLevel sets the level of the module, which takes effect on the loggers already created
+func SetModuleLevel(module string, level Level) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	moduleLevels[module] = level
+}
+
+// ModuleLevel returns the level of the module
+func ModuleLevel(module string) Level {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if level, ok := moduleLevels[module]; ok {
+		return level
+	}
+	return defaultLevel
+}
+
+// Levels returns the default level and the levels set per module
+func Levels() (Level, map[string]Level) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	levels := make(map[string]Level, len(moduleLevels))
+	for module, level := range moduleLevels {
+		levels[module] = level
+	}
+	return defaultLevel, levels
+}
+
+// Configure sets the default level and the levels per module by name, e.g., from the config
+func Configure(level string, modules map[string]string) error {
+	if level != "" {
+		l, err := ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		SetLevel(l)
+	}
+	for module, name := range modules {
+		l, err := ParseLevel(name)
+		if err != nil {
+			return errors.Wrapf(err, "module %s", module)
+		}
+		SetModuleLevel(module, l)
+	}
+	return nil
+}
+
+// Fields are the key-value pairs attached to the log lines, e.g., the height and the hash of a block
+type Fields map[string]interface{}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+// Logger writes the log lines of a module as key=value pairs, along with its fields
+type Logger struct {
+	module string
+	fields []field
+}
+
+// New returns the logger of the module
+func New(module string) *Logger {
+	return &Logger{module: module}
+}
+
+// WithField returns a logger adding the field to its lines
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	return l.WithFields(Fields{key: value})
+}
+
+// WithFields returns a logger adding the fields to its lines, sorted by key
+func (l *Logger) WithFields(fields Fields) *Logger {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	child := &Logger{module: l.module, fields: append([]field{}, l.fields...)}
+	for _, key := range keys {
+		child.fields = append(child.fields, field{key, fields[key]})
+	}
+	return child
+}
+
+// Enabled returns whether the lines of the level are written
+func (l *Logger) Enabled(level Level) bool {
+	return level >= ModuleLevel(l.module)
+}
+
+// Debug logs at the debug level
+func (l *Logger) Debug(args ...interface{}) { l.log(DebugLevel, fmt.Sprint(args...)) }
+
+// Debugf logs at the debug level
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.log(DebugLevel, fmt.Sprintf(format, args...))
+}
+
+// Info logs at the info level
+func (l *Logger) Info(args ...interface{}) { l.log(InfoLevel, fmt.Sprint(args...)) }
+
+// Infof logs at the info level
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log(InfoLevel, fmt.Sprintf(format, args...))
+}
+
+// Warning logs at the warn level
+func (l *Logger) Warning(args ...interface{}) { l.log(WarnLevel, fmt.Sprint(args...)) }
+
+// Warningf logs at the warn level
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.log(WarnLevel, fmt.Sprintf(format, args...))
+}
+
+// Error logs at the error level
+func (l *Logger) Error(args ...interface{}) { l.log(ErrorLevel, fmt.Sprint(args...)) }
+
+// Errorf logs at the error level
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log(ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+// Fatal logs at the error level and exits the process, which is only for the main packages, library code returns
+// the error instead
+func (l *Logger) Fatal(args ...interface{}) {
+	l.log(ErrorLevel, fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+// Fatalf logs at the error level and exits the process, which is only for the main packages, library code returns
+// the error instead
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.log(ErrorLevel, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+func (l *Logger) log(level Level, msg string) {
+	if !l.Enabled(level) {
+		return
+	}
+	var buf bytes.Buffer
+	buf.WriteString(time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+	writeField(&buf, "level", level.String())
+	writeField(&buf, "module", l.module)
+	writeField(&buf, "msg", msg)
+	for _, f := range l.fields {
+		writeField(&buf, f.key, f.value)
+	}
+	buf.WriteByte('\n')
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	output.Write(buf.Bytes())
+}
+
+// writeField writes the field as key=value, where the bytes and byte arrays, e.g., the hashes, are hex encoded and
+// the values with spaces are quoted
+func writeField(buf *bytes.Buffer, key string, value interface{}) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		if rv := reflect.ValueOf(value); rv.IsValid() && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) &&
+			rv.Type().Elem().Kind() == reflect.Uint8 {
+			s = fmt.Sprintf("%x", value)
+		} else {
+			s = fmt.Sprint(value)
+		}
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	if s == "" || strings.ContainsAny(s, " \"=\n") {
+		s = strconv.Quote(s)
+	}
+	buf.WriteString(s)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+	defer SetLevel(InfoLevel)
+
+	log := New("test")
+	log.Debug("dropped")
+	log.WithFields(Fields{"height": uint32(3), "hash": [2]byte{0xab, 0xcd}}).Infof("Committed %s", "block")
+	line := buf.String()
+	assert.True(strings.HasSuffix(line, " level=info module=test msg=\"Committed block\" hash=abcd height=3\n"), line)
+
+	// levels are set per module at runtime
+	buf.Reset()
+	SetModuleLevel("test", ErrorLevel)
+	log.Warning("dropped")
+	log.WithField("err", errors.New("failed")).Error("Error")
+	assert.True(strings.HasSuffix(buf.String(), " level=error module=test msg=Error err=failed\n"), buf.String())
+	assert.Equal(InfoLevel, ModuleLevel("other"))
+
+	assert.Nil(Configure("warn", map[string]string{"test": "debug"}))
+	assert.Equal(WarnLevel, ModuleLevel("other"))
+	assert.Equal(DebugLevel, ModuleLevel("test"))
+	assert.NotNil(Configure("verbose", nil))
+
+	server := httptest.NewServer(LevelHandler())
+	defer server.Close()
+	resp, err := http.PostForm(server.URL, url.Values{"module": {"test"}, "level": {"error"}})
+	assert.Nil(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal(ErrorLevel, ModuleLevel("test"))
+	resp, err = http.PostForm(server.URL, url.Values{"level": {"verbose"}})
+	assert.Nil(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+}
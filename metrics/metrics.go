@@ -0,0 +1,193 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the histogram buckets of the latencies
+var DefaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// metric is a metric exported in the Prometheus text format
+type metric interface {
+	name() string
+	write(w io.Writer)
+}
+
+var (
+	mutex    sync.RWMutex
+	registry = map[string]metric{}
+)
+
+// register adds the metric to the registry exported, the names must be unique
+func register(m metric) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if _, ok := registry[m.name()]; ok {
+		panic(fmt.Sprintf("metric %s is already registered", m.name()))
+	}
+	registry[m.name()] = m
+}
+
+// WriteTo writes all the metrics registered in the Prometheus text format, sorted by name
+func WriteTo(w io.Writer) error {
+	mutex.RLock()
+	metrics := make([]metric, 0, len(registry))
+	for _, m := range registry {
+		metrics = append(metrics, m)
+	}
+	mutex.RUnlock()
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name() < metrics[j].name() })
+
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		m.write(&buf)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Counter is a metric only going up, e.g., the number of reorgs
+type Counter struct {
+	n, help string
+	value   uint64
+}
+
+// NewCounter creates and registers a counter
+func NewCounter(name, help string) *Counter {
+	c := &Counter{n: name, help: help}
+	register(c)
+	return c
+}
+
+// Inc increments the counter by 1
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add increments the counter by delta
+func (c *Counter) Add(delta uint64) {
+	atomic.AddUint64(&c.value, delta)
+}
+
+// Value returns the count
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.value)
+}
+
+func (c *Counter) name() string { return c.n }
+
+func (c *Counter) write(w io.Writer) {
+	writeHeader(w, c.n, c.help, "counter")
+	fmt.Fprintf(w, "%s %d\n", c.n, c.Value())
+}
+
+// Gauge is a metric going up and down, e.g., the tip height
+type Gauge struct {
+	n, help string
+	value   int64
+}
+
+// NewGauge creates and registers a gauge
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{n: name, help: help}
+	register(g)
+	return g
+}
+
+// Set sets the value of the gauge
+func (g *Gauge) Set(v int64) {
+	atomic.StoreInt64(&g.value, v)
+}
+
+// Value returns the value of the gauge
+func (g *Gauge) Value() int64 {
+	return atomic.LoadInt64(&g.value)
+}
+
+func (g *Gauge) name() string { return g.n }
+
+func (g *Gauge) write(w io.Writer) {
+	writeHeader(w, g.n, g.help, "gauge")
+	fmt.Fprintf(w, "%s %d\n", g.n, g.Value())
+}
+
+// Histogram counts the observations, e.g., latencies in seconds, in cumulative buckets by upper bound
+type Histogram struct {
+	n, help string
+	mutex   sync.Mutex
+	bounds  []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// NewHistogram creates and registers a histogram of the buckets, whose upper bounds are sorted in increasing order
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := &Histogram{n: name, help: help, bounds: buckets, counts: make([]uint64, len(buckets))}
+	register(h)
+	return h
+}
+
+// Observe adds an observation to the histogram
+func (h *Histogram) Observe(v float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if i := sort.SearchFloat64s(h.bounds, v); i < len(h.bounds) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// ObserveSince adds the time elapsed since start in seconds to the histogram
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.count
+}
+
+func (h *Histogram) name() string { return h.n }
+
+func (h *Histogram) write(w io.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	writeHeader(w, h.n, h.help, "histogram")
+	cumulative := uint64(0)
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.n, formatFloat(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.n, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n", h.n, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count %d\n", h.n, h.count)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func TestMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	counter := NewCounter("test_counter_total", "A test counter")
+	counter.Inc()
+	counter.Add(2)
+	assert.Equal(uint64(3), counter.Value())
+	gauge := NewGauge("test_gauge", "A test gauge")
+	gauge.Set(42)
+	gauge.Set(-1)
+	assert.Equal(int64(-1), gauge.Value())
+	histogram := NewHistogram("test_seconds", "A test histogram", []float64{0.1, 1})
+	histogram.Observe(0.05)
+	histogram.Observe(0.1)
+	histogram.Observe(0.5)
+	histogram.Observe(2)
+	assert.Equal(uint64(4), histogram.Count())
+
+	// names are unique
+	assert.Panics(func() { NewGauge("test_gauge", "A duplicate") })
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + Path)
+	assert.Nil(err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(err)
+	for _, line := range []string{
+		"# TYPE test_counter_total counter",
+		"test_counter_total 3",
+		"# HELP test_gauge A test gauge",
+		"test_gauge -1",
+		"# TYPE test_seconds histogram",
+		"test_seconds_bucket{le=\"0.1\"} 2",
+		"test_seconds_bucket{le=\"1\"} 3",
+		"test_seconds_bucket{le=\"+Inf\"} 4",
+		"test_seconds_sum 2.65",
+		"test_seconds_count 4",
+	} {
+		assert.Contains(strings.Split(string(body), "\n"), line)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	assert := assert.New(t)
+
+	histogram := NewHistogram("test_stage_seconds", "A test stage", DefaultBuckets)
+	start := time.Now().Add(-time.Second)
+	trace := NewTrace(start)
+	trace.Span("receive", nil)
+	trace.Span("validate", histogram)
+	spans := trace.Spans()
+	assert.Equal(2, len(spans))
+	assert.Equal("receive", spans[0].Stage)
+	assert.Equal(start, spans[0].Start)
+	assert.True(spans[0].Duration >= time.Second)
+	assert.Equal("validate", spans[1].Stage)
+	assert.True(spans[1].Start.After(start))
+	assert.Equal(spans[0].Duration+spans[1].Duration, trace.Duration())
+	assert.Equal(uint64(1), histogram.Count())
+	assert.True(strings.HasPrefix(trace.String(), "receive="))
+	assert.Contains(trace.String(), " validate=")
+	assert.Contains(trace.String(), " total=")
+}
+
+func TestPprof(t *testing.T) {
+	assert := assert.New(t)
+
+	get := func(s *Server, path string) int {
+		server := httptest.NewServer(s.handler())
+		defer server.Close()
+		resp, err := server.Client().Get(server.URL + path)
+		assert.Nil(err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(http.StatusNotFound, get(NewServer(config.Metrics{}), PprofPath))
+	assert.Equal(http.StatusOK, get(NewServer(config.Metrics{Pprof: true}), PprofPath))
+	assert.Equal(http.StatusOK, get(NewServer(config.Metrics{Pprof: true}), PprofPath+"goroutine?debug=1"))
+	assert.Equal(http.StatusOK, get(NewServer(config.Metrics{Pprof: true}), Path))
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+var log = logger.New("metrics")
+
+const (
+	// Path is the HTTP path the metrics are exported on
+	Path = "/metrics"
+	// LogLevelPath is the HTTP path the log levels are read and set on at runtime
+	LogLevelPath = "/loglevel"
+	// PprofPath is the HTTP path prefix the runtime profiles are served on when they are enabled
+	PprofPath = "/debug/pprof/"
+)
+
+// Server exports the metrics over HTTP for Prometheus to scrape, along with the log levels
+type Server struct {
+	config     config.Metrics
+	httpserver *http.Server
+}
+
+// NewServer creates an instance of the metrics server
+func NewServer(c config.Metrics) *Server {
+	return &Server{config: c}
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := WriteTo(w); err != nil {
+			log.Errorf("Failed to write metrics: %v", err)
+		}
+	})
+}
+
+// handler serves the metrics and the log levels, and the runtime profiles if they are enabled
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	mux.Handle(LogLevelPath, logger.LevelHandler())
+	if s.config.Pprof {
+		mux.HandleFunc(PprofPath, pprof.Index)
+		mux.HandleFunc(PprofPath+"cmdline", pprof.Cmdline)
+		mux.HandleFunc(PprofPath+"profile", pprof.Profile)
+		mux.HandleFunc(PprofPath+"symbol", pprof.Symbol)
+		mux.HandleFunc(PprofPath+"trace", pprof.Trace)
+	}
+	return mux
+}
+
+// Start starts the metrics server
+func (s *Server) Start() error {
+	if s.config.Addr == "" {
+		log.Warning("Metrics service is not configured")
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return errors.Wrap(err, "metrics server failed to listen")
+	}
+	log.Infof("Metrics server is listening on %v", lis.Addr().String())
+
+	s.httpserver = &http.Server{Handler: s.handler()}
+	go func() {
+		if err := s.httpserver.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Metrics server failed to serve: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop stops the metrics server
+func (s *Server) Stop() error {
+	if s.httpserver != nil {
+		return s.httpserver.Close()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Span is the stage of a trace, starting where the previous stage ends
+type Span struct {
+	Stage    string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Trace records the spans of the successive stages of processing an item, e.g., a block from its receipt to its
+// commit, and observes the duration of each stage in the histogram of the stage
+// A trace is not safe for concurrent use.
+type Trace struct {
+	start time.Time
+	end   time.Time
+	spans []Span
+}
+
+// NewTrace starts a trace at the given time, which the first stage starts at
+func NewTrace(start time.Time) *Trace {
+	return &Trace{start: start, end: start}
+}
+
+// Span ends the stage started by the end of the previous stage, or by the start of the trace, and observes its
+// duration in seconds in h unless h is nil
+func (t *Trace) Span(stage string, h *Histogram) {
+	now := time.Now()
+	span := Span{Stage: stage, Start: t.end, Duration: now.Sub(t.end)}
+	t.spans = append(t.spans, span)
+	t.end = now
+	if h != nil {
+		h.Observe(span.Duration.Seconds())
+	}
+}
+
+// Spans returns the stages ended so far, in the order they are ended
+func (t *Trace) Spans() []Span {
+	return t.spans
+}
+
+// Duration returns the time from the start of the trace to the end of its last stage
+func (t *Trace) Duration() time.Duration {
+	return t.end.Sub(t.start)
+}
+
+// String formats the durations of the stages, e.g., "validate=1.2ms execute=3ms total=4.2ms"
+func (t *Trace) String() string {
+	var buf bytes.Buffer
+	for _, span := range t.spans {
+		fmt.Fprintf(&buf, "%s=%v ", span.Stage, span.Duration)
+	}
+	fmt.Fprintf(&buf, "total=%v", t.Duration())
+	return buf.String()
+}
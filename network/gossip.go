@@ -8,6 +8,7 @@ package network
 
 import (
 	"encoding/hex"
+	"net"
 	"sync"
 	"time"
 
@@ -17,7 +18,7 @@ import (
 	"github.com/iotexproject/iotex-core/common/routine"
 	"github.com/iotexproject/iotex-core/common/service"
 	pb "github.com/iotexproject/iotex-core/network/proto"
-	pb1 "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/wire"
 )
 
 // Gossip relays messages in the overlay (at least once semantics)
@@ -53,8 +54,9 @@ func (g *Gossip) OnReceivingMsg(msg *pb.BroadcastReq) error {
 		return nil
 	}
 	// Call dispatch to notify that a new message comes in
-	err := g.processMsg(msg.MsgType, msg.MsgBody)
+	err := g.processMsg(msg.Addr, msg.MsgType, msg.MsgBody)
 	if err != nil {
+		g.Overlay.PM.Penalize(msg.Addr, PenaltyInvalidMsg)
 		return err
 	}
 	// Relay the message to the neighbors
@@ -65,13 +67,17 @@ func (g *Gossip) OnReceivingMsg(msg *pb.BroadcastReq) error {
 	return nil
 }
 
-func (g *Gossip) processMsg(msgType uint32, msgBody []byte) error {
-	protoMsg, err := pb1.TypifyProtoMsg(msgType, msgBody)
+func (g *Gossip) processMsg(sender string, msgType uint32, msgBody []byte) error {
+	protoMsg, err := wire.Decode(msgType, msgBody)
 	if err != nil {
 		return err
 	}
 	if g.Dispatcher != nil {
-		g.Dispatcher.HandleBroadcast(protoMsg, nil)
+		var addr net.Addr
+		if sender != "" {
+			addr = cm.NewTCPNode(sender)
+		}
+		g.Dispatcher.HandleBroadcast(addr, protoMsg, nil)
 	}
 	return nil
 }
@@ -81,8 +87,16 @@ func (g *Gossip) relayMsg(msgType uint32, msgBody []byte, checksum string) error
 	g.storeBroadcastMsgChecksum(checksum)
 	// Send the message to all neighbors
 	g.Overlay.PM.Peers.Range(func(_, value interface{}) bool {
+		peer := value.(*Peer)
+		// Peers not supporting the message would not understand it
+		if !peer.supports(msgType) {
+			return true
+		}
 		go func() {
-			value.(*Peer).BroadcastMsg(&pb.BroadcastReq{MsgType: msgType, MsgBody: msgBody})
+			_, err := peer.BroadcastMsg(&pb.BroadcastReq{MsgType: msgType, MsgBody: msgBody, Addr: g.Overlay.PRC.String()})
+			if err != nil {
+				g.Overlay.PM.Penalize(peer.String(), PenaltyTimeout)
+			}
 		}()
 		return true
 	})
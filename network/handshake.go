@@ -0,0 +1,83 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	pb "github.com/iotexproject/iotex-core/network/proto"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+// ProtocolVersion is the version of the P2P protocol, peers on another version are disconnected
+const ProtocolVersion uint32 = 1
+
+// Bit flags of the optional features a node supports, which it only receives messages of if supported
+const (
+	// CapCompactBlocks means the node takes blocks relayed as compact blocks and serves the transactions missing from
+	// them
+	CapCompactBlocks uint64 = 1 << iota
+	// CapSnapshots means the node serves UTXO snapshots
+	CapSnapshots
+	// CapCheckpointVotes means the node takes the checkpoint votes of the delegates finalizing the blocks
+	CapCheckpointVotes
+)
+
+// DefaultCapabilities are the optional features supported by the node unless configured otherwise
+const DefaultCapabilities = CapCompactBlocks | CapCheckpointVotes
+
+// ErrHandshakeMismatch means the peer is not on the same protocol version or chain
+var ErrHandshakeMismatch = errors.New("Peer handshake mismatch")
+
+// msgCapabilities are the capabilities a peer must support to be relayed the messages of the type
+var msgCapabilities = map[uint32]uint64{
+	iproto.MsgCompactBlockType:   CapCompactBlocks,
+	iproto.MsgCheckpointVoteType: CapCheckpointVotes,
+}
+
+// Chain is the blockchain the node is on, which the peers must be on as well
+type Chain interface {
+	ChainID() uint32
+	GetHashByHeight(height uint32) (cp.Hash32B, error)
+	TipHeight() uint32
+}
+
+// handshake returns the handshake telling the peers the protocol version, chain and capabilities of the node
+func (o *Overlay) handshake() *pb.Handshake {
+	hs := &pb.Handshake{Version: ProtocolVersion, Capabilities: o.Capabilities, Addr: o.PRC.String()}
+	if o.Chain != nil {
+		hs.ChainId = o.Chain.ChainID()
+		hs.TipHeight = o.Chain.TipHeight()
+		if hash, err := o.Chain.GetHashByHeight(0); err == nil {
+			hs.GenesisHash = hash[:]
+		}
+	}
+	return hs
+}
+
+// checkHandshake returns error if the remote peer is not on the same protocol version and chain as the local node
+func checkHandshake(local *pb.Handshake, remote *pb.Handshake) error {
+	if remote.Version != local.Version {
+		return errors.Wrapf(ErrHandshakeMismatch, "protocol version %d, expecting %d", remote.Version, local.Version)
+	}
+	if remote.ChainId != local.ChainId {
+		return errors.Wrapf(ErrHandshakeMismatch, "chain ID %d, expecting %d", remote.ChainId, local.ChainId)
+	}
+	if !bytes.Equal(remote.GenesisHash, local.GenesisHash) {
+		return errors.Wrapf(ErrHandshakeMismatch, "genesis hash %x, expecting %x", remote.GenesisHash, local.GenesisHash)
+	}
+	return nil
+}
+
+// supports returns true if the peer supports the capabilities required to be relayed the message of the type
+func (p *Peer) supports(msgType uint32) bool {
+	caps, ok := msgCapabilities[msgType]
+	return !ok || p.Capabilities()&caps == caps
+}
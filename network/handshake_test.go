@@ -0,0 +1,104 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	pb "github.com/iotexproject/iotex-core/network/proto"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+type testChain struct {
+	chainID uint32
+	genesis cp.Hash32B
+	height  uint32
+}
+
+func (c *testChain) ChainID() uint32 {
+	return c.chainID
+}
+
+func (c *testChain) GetHashByHeight(height uint32) (cp.Hash32B, error) {
+	return c.genesis, nil
+}
+
+func (c *testChain) TipHeight() uint32 {
+	return c.height
+}
+
+func TestCheckHandshake(t *testing.T) {
+	local := &pb.Handshake{Version: ProtocolVersion, ChainId: 1, GenesisHash: []byte{1, 2, 3}, TipHeight: 10}
+	remote := &pb.Handshake{Version: ProtocolVersion, ChainId: 1, GenesisHash: []byte{1, 2, 3}, TipHeight: 20}
+	assert.Nil(t, checkHandshake(local, remote))
+
+	remote.Version = ProtocolVersion + 1
+	assert.Equal(t, ErrHandshakeMismatch, errors.Cause(checkHandshake(local, remote)))
+	remote.Version = ProtocolVersion
+	remote.ChainId = 2
+	assert.Equal(t, ErrHandshakeMismatch, errors.Cause(checkHandshake(local, remote)))
+	remote.ChainId = 1
+	remote.GenesisHash = []byte{3, 2, 1}
+	assert.Equal(t, ErrHandshakeMismatch, errors.Cause(checkHandshake(local, remote)))
+}
+
+func TestPeerSupports(t *testing.T) {
+	p := NewTCPPeer("127.0.0.1:10001")
+	assert.True(t, p.supports(iproto.MsgBlockProtoMsgType))
+	assert.False(t, p.supports(iproto.MsgCompactBlockType))
+	p.setHandshake(&pb.Handshake{Capabilities: CapCompactBlocks, TipHeight: 5})
+	assert.True(t, p.supports(iproto.MsgCompactBlockType))
+	assert.Equal(t, uint32(5), p.TipHeight())
+
+	// a peer not taking the checkpoint votes is not relayed them
+	assert.False(t, p.supports(iproto.MsgCheckpointVoteType))
+	p.setHandshake(&pb.Handshake{Capabilities: DefaultCapabilities})
+	assert.True(t, p.supports(iproto.MsgCheckpointVoteType))
+}
+
+func TestHandshake(t *testing.T) {
+	config := LoadTestConfig("", true)
+	o := &Overlay{Config: config, Capabilities: DefaultCapabilities, Chain: &testChain{chainID: 1, height: 10}}
+	o.PM = NewPeerManager(o, 1, 1)
+	s := NewRPCServer(o)
+	o.PRC = s
+	s.Start()
+	defer s.Stop()
+
+	// the peer on the same chain is connected along with its capabilities
+	config2 := LoadTestConfig("", true)
+	o2 := &Overlay{Config: config2, Chain: &testChain{chainID: 1}}
+	o2.PM = NewPeerManager(o2, 1, 1)
+	o2.PRC = NewRPCServer(o2)
+	o2.PM.AddPeer(s.String())
+	time.Sleep(100 * time.Millisecond)
+	value, ok := o2.PM.Peers.Load(s.String())
+	assert.True(t, ok)
+	assert.Equal(t, DefaultCapabilities, value.(*Peer).Capabilities())
+	assert.Equal(t, uint32(10), value.(*Peer).TipHeight())
+	o2.PM.RemovePeer(s.String())
+
+	// the peer on another chain is disconnected
+	o2.Chain = &testChain{chainID: 2}
+	o2.PM.AddPeer(s.String())
+	time.Sleep(100 * time.Millisecond)
+	_, ok = o2.PM.Peers.Load(s.String())
+	assert.False(t, ok)
+
+	p := NewPeer(s.Network(), s.String())
+	p.Connect(config)
+	defer p.Close()
+	_, err := p.Handshake(&pb.Handshake{Version: ProtocolVersion, ChainId: 1, GenesisHash: cp.ZeroHash32B[:]})
+	assert.Nil(t, err)
+	_, err = p.Handshake(&pb.Handshake{Version: ProtocolVersion + 1, ChainId: 1, GenesisHash: cp.ZeroHash32B[:]})
+	assert.NotNil(t, err)
+}
@@ -8,9 +8,7 @@ package network
 
 import (
 	"net"
-	"syscall"
 
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 
@@ -18,13 +16,18 @@ import (
 	"github.com/iotexproject/iotex-core/common/routine"
 	"github.com/iotexproject/iotex-core/common/service"
 	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
 	pb "github.com/iotexproject/iotex-core/network/proto"
 	"github.com/iotexproject/iotex-core/proto"
 )
 
+var log = logger.New("network")
+
 var (
 	// ErrPeerNotFound means the peer is not found
 	ErrPeerNotFound = errors.New("Peer not found")
+	// ErrPeerBanned means the peer is banned for misbehaving
+	ErrPeerBanned = errors.New("Peer is banned")
 )
 
 // Overlay represents the peer-to-peer network
@@ -36,11 +39,18 @@ type Overlay struct {
 	Tasks      []*routine.RecurringTask
 	Config     *config.Network
 	Dispatcher cm.Dispatcher
+	// Chain is the blockchain the node is on, which is checked against the peers in the handshake
+	Chain Chain
+	// Capabilities are the optional features supported by the node, which are told to the peers in the handshake
+	Capabilities uint64
+
+	// err is the error creating the overlay, which Init returns
+	err error
 }
 
 // NewOverlay creates an instance of Overlay
 func NewOverlay(config *config.Network) *Overlay {
-	o := &Overlay{Config: config}
+	o := &Overlay{Config: config, Capabilities: DefaultCapabilities}
 	o.PRC = NewRPCServer(o)
 	o.PM = NewPeerManager(o, config.NumPeersLowerBound, config.NumPeersUpperBound)
 	o.Gossip = NewGossip(o)
@@ -58,6 +68,19 @@ func NewOverlay(config *config.Network) *Overlay {
 	return o
 }
 
+// Init initializes the services of the overlay, failing if the overlay could not be created
+func (o *Overlay) Init() error {
+	if o.err != nil {
+		return o.err
+	}
+	return o.CompositeService.Init()
+}
+
+// AttachChain attaches to the blockchain the node is on, peers on another chain are disconnected
+func (o *Overlay) AttachChain(chain Chain) {
+	o.Chain = chain
+}
+
 // AttachDispatcher attaches to a Dispatcher instance
 func (o *Overlay) AttachDispatcher(dispatcher cm.Dispatcher) {
 	o.Dispatcher = dispatcher
@@ -88,8 +111,8 @@ func (o *Overlay) addPeerMaintainer() {
 func (o *Overlay) addConfigBasedPeerMaintainer() {
 	topology, err := config.LoadTopology(o.Config.TopologyPath)
 	if err != nil {
-		glog.Fatal(err.Error())
-		syscall.Exit(syscall.SYS_EXIT)
+		o.err = err
+		return
 	}
 	cbpm := NewConfigBasedPeerMaintainer(o, topology)
 	cbpmTask := routine.NewRecurringTask(cbpm, o.Config.PeerMaintainerInterval)
@@ -128,7 +151,7 @@ func (o *Overlay) Tell(node net.Addr, msg proto.Message) error {
 	if peer == nil {
 		return ErrPeerNotFound
 	}
-	glog.Info("node addr = ", peer.Addr)
+	log.Info("node addr = ", peer.Addr)
 	msgType, err := iproto.GetTypeFromProtoMsg(msg)
 	if err != nil {
 		return err
@@ -137,8 +160,12 @@ func (o *Overlay) Tell(node net.Addr, msg proto.Message) error {
 	if err != nil {
 		return err
 	}
-	glog.Info("request addr = ", o.PRC.String())
-	go peer.Tell(&pb.TellReq{Addr: o.PRC.String(), MsgType: msgType, MsgBody: msgBody})
+	log.Info("request addr = ", o.PRC.String())
+	go func() {
+		if _, err := peer.Tell(&pb.TellReq{Addr: o.PRC.String(), MsgType: msgType, MsgBody: msgBody}); err != nil {
+			o.PM.Penalize(peer.String(), PenaltyTimeout)
+		}
+	}()
 	return nil
 }
 
@@ -71,7 +71,7 @@ func (d *MockDispatcher) Stop() error {
 	return nil
 }
 
-func (d *MockDispatcher) HandleBroadcast(proto.Message, chan bool) {
+func (d *MockDispatcher) HandleBroadcast(net.Addr, proto.Message, chan bool) {
 }
 
 func (d *MockDispatcher) HandleTell(net.Addr, proto.Message, chan bool) {
@@ -82,7 +82,7 @@ type MockDispatcher1 struct {
 	Count uint32
 }
 
-func (d1 *MockDispatcher1) HandleBroadcast(proto.Message, chan bool) {
+func (d1 *MockDispatcher1) HandleBroadcast(net.Addr, proto.Message, chan bool) {
 	d1.Count++
 }
 
@@ -285,7 +285,7 @@ func (d3 *MockDispatcher3) HandleTell(net.Addr, proto.Message, chan bool) {
 	d3.C <- true
 }
 
-func (d3 *MockDispatcher3) HandleBroadcast(proto.Message, chan bool) {
+func (d3 *MockDispatcher3) HandleBroadcast(net.Addr, proto.Message, chan bool) {
 	d3.C <- true
 }
 
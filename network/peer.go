@@ -7,9 +7,9 @@
 package network
 
 import (
+	"sync/atomic"
 	"time"
 
-	"github.com/golang/glog"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
@@ -26,6 +26,11 @@ type Peer struct {
 	Conn        *grpc.ClientConn
 	Ctx         context.Context
 	LastResTime time.Time
+
+	// capabilities are the optional features supported by the peer, told in the handshake
+	capabilities uint64
+	// tipHeight is the tip height of the chain of the peer at the handshake
+	tipHeight uint32
 }
 
 // NewTCPPeer creates an instance of Peer with tcp transportation
@@ -64,7 +69,7 @@ func (p *Peer) Connect(config *config.Network) error {
 	}
 
 	if err != nil {
-		glog.Errorf("Peer did not connect: %v", err)
+		log.Errorf("Peer did not connect: %v", err)
 		return err
 	}
 	p.Conn = conn
@@ -108,6 +113,28 @@ func (p *Peer) Tell(req *pb.TellReq) (*pb.TellRes, error) {
 	return res, e
 }
 
+// Handshake implements the client side RPC
+func (p *Peer) Handshake(hs *pb.Handshake) (*pb.Handshake, error) {
+	res, e := p.Client.Handshake(p.Ctx, hs)
+	p.updateLastResTime()
+	return res, e
+}
+
+// Capabilities returns the optional features supported by the peer, none before the handshake completes
+func (p *Peer) Capabilities() uint64 {
+	return atomic.LoadUint64(&p.capabilities)
+}
+
+// TipHeight returns the tip height of the chain of the peer told in the handshake
+func (p *Peer) TipHeight() uint32 {
+	return atomic.LoadUint32(&p.tipHeight)
+}
+
+func (p *Peer) setHandshake(hs *pb.Handshake) {
+	atomic.StoreUint64(&p.capabilities, hs.Capabilities)
+	atomic.StoreUint32(&p.tipHeight, hs.TipHeight)
+}
+
 // Update the last time when successfully getting an response from the peer
 func (p *Peer) updateLastResTime() {
 	p.LastResTime = time.Now()
@@ -17,29 +17,35 @@ import (
 
 // PeerMaintainer helps maintain enough connections to other peers in the P2P networks
 type PeerMaintainer struct {
-	Overlay *Overlay
+	Overlay    *Overlay
+	lookupHost func(host string) ([]string, error)
 }
 
 // NewPeerMaintainer creates an instance of PeerMaintainer
 func NewPeerMaintainer(o *Overlay) *PeerMaintainer {
-	return &PeerMaintainer{Overlay: o}
+	return &PeerMaintainer{Overlay: o, lookupHost: net.LookupHost}
 }
 
-// Do maintain peer connection. Current strategy is to get the (upper_bound - count) peer addresses from one of the
-// current peer if the count is lower than the lower bound
+// Do maintain peer connection. Current strategy is to dial the bootstrap nodes if there is no peer, and to get the
+// (upper_bound - count) peer addresses from one of the current peer if the count is lower than the lower bound
 func (pm *PeerMaintainer) Do() {
 	count := lenSyncMap(pm.Overlay.PM.Peers)
 	if count == 0 {
-		// TODO: Now we simply read the bootstrap nodes from the config. This needs to be changed in the future
-		bns1 := pm.Overlay.Config.BootstrapNodes
-		bns2 := make([]string, len(bns1))
-		copy(bns2, bns1)
-		stringsAreShuffled(bns2)
-		for i, bn := range bns2 {
-			if uint(i) >= pm.Overlay.PM.NumPeersLowerBound {
+		addrs := pm.bootstrapAddrs()
+		stringsAreShuffled(addrs)
+		var dialed []*Peer
+		for _, addr := range addrs {
+			if uint(len(dialed)) >= pm.Overlay.PM.NumPeersLowerBound {
 				break
 			}
-			pm.Overlay.PM.AddPeer(bn)
+			pm.Overlay.PM.AddPeer(addr)
+			if peer, ok := pm.Overlay.PM.Peers.Load(addr); ok {
+				dialed = append(dialed, peer.(*Peer))
+			}
+		}
+		// Exchange the peer addresses with one of the bootstrap nodes rather than waiting for the next round
+		if len(dialed) > 0 {
+			go pm.requestPeers(dialed[rand.Intn(len(dialed))], pm.Overlay.PM.NumPeersUpperBound-uint(len(dialed)))
 		}
 	} else if count < pm.Overlay.PM.NumPeersLowerBound {
 		targetIdx := rand.Intn(int(count))
@@ -55,17 +61,9 @@ func (pm *PeerMaintainer) Do() {
 			idx++
 			return true
 		})
-		go func() {
-			if target != nil {
-				res, error := target.GetPeers(
-					&pb.GetPeersReq{Count: uint32(pm.Overlay.PM.NumPeersUpperBound - count)})
-				if res != nil && error == nil {
-					for _, addr := range res.Addr {
-						pm.Overlay.PM.AddPeer(addr)
-					}
-				}
-			}
-		}()
+		if target != nil {
+			go pm.requestPeers(target, pm.Overlay.PM.NumPeersUpperBound-count)
+		}
 	} else if count > pm.Overlay.PM.NumPeersUpperBound {
 		for count > pm.Overlay.PM.NumPeersUpperBound {
 			pm.Overlay.PM.RemoveLRUPeer()
@@ -74,6 +72,66 @@ func (pm *PeerMaintainer) Do() {
 	}
 }
 
+// requestPeers gets up to count peer addresses from the target and dials them
+func (pm *PeerMaintainer) requestPeers(target *Peer, count uint) {
+	res, err := target.GetPeers(&pb.GetPeersReq{Count: uint32(count)})
+	if res != nil && err == nil {
+		for _, addr := range res.Addr {
+			pm.Overlay.PM.AddPeer(addr)
+		}
+	}
+}
+
+// bootstrapAddrs returns the addresses of the static bootstrap nodes and of the nodes listed by the DNS seeds, with
+// the host names resolved, excluding the current node
+func (pm *PeerMaintainer) bootstrapAddrs() []string {
+	// the seeds without port are skipped if the node has no port configured either
+	_, port, _ := net.SplitHostPort(pm.Overlay.Config.Addr)
+	seen := map[string]bool{pm.Overlay.Config.Addr: true}
+	if pm.Overlay.PRC != nil {
+		seen[pm.Overlay.PRC.String()] = true
+	}
+	candidates := pm.resolve(pm.Overlay.Config.BootstrapNodes, "")
+	candidates = append(candidates, pm.resolve(pm.Overlay.Config.DNSSeeds, port)...)
+	var addrs []string
+	for _, addr := range candidates {
+		if !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// resolve looks up the IP addresses of the host of each address, an address without port gets the default port if
+// given. Addresses failing to resolve are skipped.
+func (pm *PeerMaintainer) resolve(addrs []string, defaultPort string) []string {
+	var res []string
+	for _, addr := range addrs {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			if defaultPort == "" {
+				log.Errorf("Node address %s is invalid", addr)
+				continue
+			}
+			host, port = addr, defaultPort
+		}
+		if net.ParseIP(host) != nil {
+			res = append(res, net.JoinHostPort(host, port))
+			continue
+		}
+		ips, err := pm.lookupHost(host)
+		if err != nil {
+			log.Warningf("Failed to resolve %s: %v", host, err)
+			continue
+		}
+		for _, ip := range ips {
+			res = append(res, net.JoinHostPort(ip, port))
+		}
+	}
+	return res
+}
+
 // ConfigBasedPeerMaintainer maintain the neighbors by reading the topology file
 type ConfigBasedPeerMaintainer struct {
 	Overlay *Overlay
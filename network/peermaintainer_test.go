@@ -0,0 +1,43 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func TestPeerMaintainerBootstrapAddrs(t *testing.T) {
+	o := &Overlay{Config: &config.Network{
+		Addr:           "127.0.0.1:4689",
+		BootstrapNodes: []string{"127.0.0.1:4689", "127.0.0.2:4689", "boot.iotex.io:4690", "invalid"},
+		DNSSeeds:       []string{"seed1.iotex.io", "seed2.iotex.io:4691", "unknown.iotex.io"},
+	}}
+	pm := NewPeerMaintainer(o)
+	pm.lookupHost = func(host string) ([]string, error) {
+		switch host {
+		case "boot.iotex.io":
+			return []string{"10.0.0.1"}, nil
+		case "seed1.iotex.io":
+			return []string{"10.0.1.1", "10.0.1.2", "127.0.0.2"}, nil
+		case "seed2.iotex.io":
+			return []string{"10.0.2.1"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	assert.Equal(t, []string{
+		"127.0.0.2:4689",
+		"10.0.0.1:4690",
+		"10.0.1.1:4689",
+		"10.0.1.2:4689",
+		"10.0.2.1:4691",
+	}, pm.bootstrapAddrs())
+}
@@ -8,13 +8,41 @@ package network
 
 import (
 	"net"
+	"sort"
 	"sync"
+	"time"
 
-	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/iotexproject/iotex-core/common/service"
 )
 
+// Misbehavior scores added to a peer, which is banned once its score reaches the configured ban score
+const (
+	// PenaltyInvalidBlock is added for sending an invalid block or block data
+	PenaltyInvalidBlock uint = 50
+	// PenaltyInvalidMsg is added for sending a message that cannot be decoded
+	PenaltyInvalidMsg uint = 20
+	// PenaltyInvalidTx is added for sending an invalid transaction
+	PenaltyInvalidTx uint = 10
+	// PenaltyTimeout is added for failing to respond to a request, a timely response takes one point off
+	PenaltyTimeout uint = 2
+
+	// DefaultBanDuration is how long a misbehaving peer is banned if not configured
+	DefaultBanDuration = 24 * time.Hour
+)
+
+// PeerInfo describes a peer that is connected or banned
+type PeerInfo struct {
+	Addr        string
+	Connected   bool
+	Score       uint      // misbehavior score
+	LastResTime time.Time // last time the peer responded, zero if not connected
+	BannedUntil time.Time // zero if not banned
+}
+
 // PeerManager represents the outgoing neighbor list
 // TODO: We should decouple peer address and peer. Node can know more nodes than it connects to
 type PeerManager struct {
@@ -24,6 +52,10 @@ type PeerManager struct {
 	Overlay            *Overlay
 	NumPeersLowerBound uint
 	NumPeersUpperBound uint
+
+	mu     sync.Mutex
+	scores map[string]uint      // misbehavior scores of the peers by address
+	banned map[string]time.Time // ban expiry of the peers by address
 }
 
 // NewPeerManager creates an instance of PeerManager
@@ -31,25 +63,60 @@ func NewPeerManager(o *Overlay, lb uint, ub uint) *PeerManager {
 	return &PeerManager{Overlay: o, NumPeersLowerBound: lb, NumPeersUpperBound: ub}
 }
 
+// Start reconnects to the known good peers persisted by the last run if peer discovery is enabled
+func (pm *PeerManager) Start() error {
+	if pm.Overlay.Config.PeerStorePath == "" || !pm.Overlay.Config.PeerDiscovery {
+		return pm.CompositeService.Start()
+	}
+	addrs, err := NewPeerStore(pm.Overlay.Config.PeerStorePath).Load()
+	if err != nil {
+		log.Errorf("Failed to load the peer store: %v", err)
+	}
+	for _, addr := range addrs {
+		pm.AddPeer(addr)
+	}
+	return pm.CompositeService.Start()
+}
+
+// Stop persists the known good peers, which are the connected ones with no misbehavior
+func (pm *PeerManager) Stop() error {
+	if pm.Overlay.Config.PeerStorePath != "" {
+		var addrs []string
+		for _, info := range pm.PeerInfos() {
+			if info.Connected && info.Score == 0 {
+				addrs = append(addrs, info.Addr)
+			}
+		}
+		if err := NewPeerStore(pm.Overlay.Config.PeerStorePath).Save(addrs); err != nil {
+			log.Errorf("Failed to save the peer store: %v", err)
+		}
+	}
+	return pm.CompositeService.Stop()
+}
+
 // AddPeer adds a new peer
 func (pm *PeerManager) AddPeer(addr string) {
+	if pm.IsBanned(addr) {
+		log.Infof("Node at address %s is banned", addr)
+		return
+	}
 	if lenSyncMap(pm.Peers) >= pm.NumPeersUpperBound {
-		glog.Infof("Node already reaches the max number of peers: %d", pm.NumPeersUpperBound)
+		log.Infof("Node already reaches the max number of peers: %d", pm.NumPeersUpperBound)
 		return
 	}
 	if pm.Overlay.PRC.String() == addr {
-		glog.Infof("Node at address %s is the current node", addr)
+		log.Infof("Node at address %s is the current node", addr)
 		return
 	}
 	_, ok := pm.Peers.Load(addr)
 	if ok {
-		glog.Infof("Node at address %s is already the peer", addr)
+		log.Infof("Node at address %s is already the peer", addr)
 		return
 	}
 	if !pm.Overlay.Config.AllowMultiConnsPerIP {
 		nHost, _, err := net.SplitHostPort(addr)
 		if err != nil {
-			glog.Errorf("Node address %s is invalid", addr)
+			log.Errorf("Node address %s is invalid", addr)
 			return
 		}
 		found := false
@@ -57,7 +124,7 @@ func (pm *PeerManager) AddPeer(addr string) {
 			host, _, err := net.SplitHostPort(value.(*Peer).String())
 			// This should be impossible, otherwise the connection couldn't be established
 			if err != nil {
-				glog.Errorf("Node address %s is invalid", addr)
+				log.Errorf("Node address %s is invalid", addr)
 				return true
 			}
 			if host == nHost {
@@ -67,20 +134,75 @@ func (pm *PeerManager) AddPeer(addr string) {
 			return true
 		})
 		if found {
-			glog.Infof("Another node on the same IP %s is already the peer", nHost)
+			log.Infof("Another node on the same IP %s is already the peer", nHost)
 			return
 		}
 	}
 	p := NewTCPPeer(addr)
-	p.Connect(pm.Overlay.Config)
+	if err := p.Connect(pm.Overlay.Config); err != nil {
+		return
+	}
 	pm.Peers.Store(addr, p)
+	go pm.handshake(p)
+}
+
+// handshake exchanges the handshakes with the peer, which is disconnected if it is not on the same protocol version
+// and chain. Until then, the peer is taken as supporting no capability.
+func (pm *PeerManager) handshake(p *Peer) {
+	local := pm.Overlay.handshake()
+	remote, err := p.Handshake(local)
+	if err != nil {
+		s, _ := status.FromError(err)
+		switch s.Code() {
+		case codes.Unimplemented:
+			err = errors.Wrap(ErrHandshakeMismatch, "handshake unsupported")
+		case codes.FailedPrecondition:
+			err = errors.Wrap(ErrHandshakeMismatch, s.Message())
+		default:
+			// Unreachable peers are left to the health checker
+			log.Warningf("Failed to handshake with node at address %s: %v", p.String(), err)
+			return
+		}
+	} else {
+		err = checkHandshake(local, remote)
+	}
+	if err != nil {
+		log.Warningf("Disconnect node at address %s: %v", p.String(), err)
+		pm.RemovePeer(p.String())
+		return
+	}
+	p.setHandshake(remote)
+}
+
+// ProbeTips exchanges the handshakes again with all the peers to refresh the tip heights they tell, and returns the
+// address and tip height of the peer with the highest tip, or an empty address if no peer is connected
+func (pm *PeerManager) ProbeTips() (string, uint32) {
+	var wg sync.WaitGroup
+	pm.Peers.Range(func(_, value interface{}) bool {
+		wg.Add(1)
+		go func(p *Peer) {
+			defer wg.Done()
+			pm.handshake(p)
+		}(value.(*Peer))
+		return true
+	})
+	wg.Wait()
+
+	addr, height := "", uint32(0)
+	pm.Peers.Range(func(key, value interface{}) bool {
+		if tip := value.(*Peer).TipHeight(); addr == "" || tip > height {
+			addr, height = key.(string), tip
+		}
+		return true
+	})
+	return addr, height
 }
 
 // RemovePeer removes an existing peer
 func (pm *PeerManager) RemovePeer(addr string) {
 	p, found := pm.Peers.Load(addr)
 	if !found {
-		glog.Infof("Node at address %s is not a peer", addr)
+		log.Infof("Node at address %s is not a peer", addr)
 		return
 	}
 	pm.Peers.Delete(p.(*Peer).String())
@@ -121,3 +243,108 @@ func (pm *PeerManager) GetOrAddPeer(addr string) *Peer {
 	}
 	return nil
 }
+
+// Penalize adds the penalty to the misbehavior score of the peer, which is banned once the score reaches the ban
+// score, if configured
+func (pm *PeerManager) Penalize(addr string, penalty uint) {
+	if addr == "" {
+		return
+	}
+	pm.mu.Lock()
+	if pm.scores == nil {
+		pm.scores = make(map[string]uint)
+	}
+	pm.scores[addr] += penalty
+	score := pm.scores[addr]
+	pm.mu.Unlock()
+
+	log.Warningf("Peer %s misbehaved, score = %d", addr, score)
+	if banScore := pm.Overlay.Config.BanScore; banScore > 0 && score >= banScore {
+		duration := pm.Overlay.Config.BanDuration
+		if duration == 0 {
+			duration = DefaultBanDuration
+		}
+		pm.Ban(addr, duration)
+	}
+}
+
+// Reward takes one point off the misbehavior score of the peer responding in time
+func (pm *PeerManager) Reward(addr string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if score := pm.scores[addr]; score > 1 {
+		pm.scores[addr] = score - 1
+	} else {
+		delete(pm.scores, addr)
+	}
+}
+
+// Ban disconnects the peer and refuses it for the duration
+func (pm *PeerManager) Ban(addr string, duration time.Duration) {
+	pm.mu.Lock()
+	if pm.banned == nil {
+		pm.banned = make(map[string]time.Time)
+	}
+	pm.banned[addr] = time.Now().Add(duration)
+	delete(pm.scores, addr)
+	pm.mu.Unlock()
+
+	log.Warningf("Ban peer %s for %v", addr, duration)
+	if _, ok := pm.Peers.Load(addr); ok {
+		pm.RemovePeer(addr)
+	}
+}
+
+// Unban lifts the ban of the peer
+func (pm *PeerManager) Unban(addr string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	delete(pm.banned, addr)
+}
+
+// IsBanned returns true if the peer is banned, an expired ban is lifted
+func (pm *PeerManager) IsBanned(addr string) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	until, ok := pm.banned[addr]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(pm.banned, addr)
+		return false
+	}
+	return true
+}
+
+// PeerInfos returns the connected peers along with the banned ones, ordered by address
+func (pm *PeerManager) PeerInfos() []PeerInfo {
+	infos := make(map[string]*PeerInfo)
+	pm.Peers.Range(func(key, value interface{}) bool {
+		infos[key.(string)] = &PeerInfo{Addr: key.(string), Connected: true, LastResTime: value.(*Peer).LastResTime}
+		return true
+	})
+
+	pm.mu.Lock()
+	now := time.Now()
+	for addr, until := range pm.banned {
+		if now.After(until) {
+			continue
+		}
+		if _, ok := infos[addr]; !ok {
+			infos[addr] = &PeerInfo{Addr: addr}
+		}
+		infos[addr].BannedUntil = until
+	}
+	for addr, info := range infos {
+		info.Score = pm.scores[addr]
+	}
+	pm.mu.Unlock()
+
+	var res []PeerInfo
+	for _, info := range infos {
+		res = append(res, *info)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Addr < res[j].Addr })
+	return res
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func TestPeerManagerPenalize(t *testing.T) {
+	pm := &PeerManager{Overlay: &Overlay{Config: &config.Network{BanScore: 30, BanDuration: time.Hour}}}
+	addr := "127.0.0.1:10001"
+
+	pm.Penalize(addr, PenaltyTimeout)
+	pm.Penalize(addr, PenaltyInvalidTx)
+	assert.False(t, pm.IsBanned(addr))
+	assert.Equal(t, []PeerInfo(nil), pm.PeerInfos())
+	assert.Equal(t, uint(12), pm.scores[addr])
+	pm.Reward(addr)
+	assert.Equal(t, uint(11), pm.scores[addr])
+
+	pm.Penalize(addr, PenaltyInvalidMsg)
+	assert.True(t, pm.IsBanned(addr))
+	infos := pm.PeerInfos()
+	assert.Equal(t, 1, len(infos))
+	assert.Equal(t, addr, infos[0].Addr)
+	assert.False(t, infos[0].Connected)
+	assert.Equal(t, uint(0), infos[0].Score)
+	assert.True(t, infos[0].BannedUntil.After(time.Now()))
+
+	pm.Unban(addr)
+	assert.False(t, pm.IsBanned(addr))
+	assert.Equal(t, []PeerInfo(nil), pm.PeerInfos())
+
+	// ban expires
+	pm.Ban(addr, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, pm.IsBanned(addr))
+
+	// no ban score configured
+	pm.Overlay.Config.BanScore = 0
+	pm.Penalize(addr, PenaltyInvalidBlock)
+	pm.Penalize(addr, PenaltyInvalidBlock)
+	assert.False(t, pm.IsBanned(addr))
+}
+
+func TestPeerStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peerstore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	ps := NewPeerStore(filepath.Join(dir, "peers"))
+
+	// missing store
+	addrs, err := ps.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(addrs))
+
+	assert.Nil(t, ps.Save([]string{"127.0.0.1:10001", "127.0.0.1:10002"}))
+	addrs, err = ps.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"127.0.0.1:10001", "127.0.0.1:10002"}, addrs)
+
+	// malformed addresses are skipped
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "peers"), []byte("127.0.0.1:10001\nfoo\n\n127.0.0.1:10003\n"), 0600))
+	addrs, err = ps.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"127.0.0.1:10001", "127.0.0.1:10003"}, addrs)
+}
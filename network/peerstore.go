@@ -0,0 +1,77 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+)
+
+// PeerStore persists the addresses of the known good peers to a file, one address per line, so that a restarted
+// node reconnects to them besides the bootstrap nodes
+type PeerStore struct {
+	path string
+}
+
+// NewPeerStore creates an instance of PeerStore persisting to the file at the path
+func NewPeerStore(path string) *PeerStore {
+	return &PeerStore{path: path}
+}
+
+// Load reads the addresses saved in the file, skipping the malformed ones. A missing file is not an error.
+func (ps *PeerStore) Load() ([]string, error) {
+	file, err := os.Open(ps.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var addrs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		addr := strings.TrimSpace(scanner.Text())
+		if addr == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			log.Warningf("Skip malformed peer address %q in %s", addr, ps.path)
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, scanner.Err()
+}
+
+// Save writes the addresses to the file, replacing the previous one
+func (ps *PeerStore) Save(addrs []string) error {
+	// write to a temporary file first, so a crash while saving keeps the previous file
+	tmpPath := ps.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	for _, addr := range addrs {
+		if _, err := w.WriteString(addr + "\n"); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, ps.path)
+}
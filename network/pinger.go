@@ -27,9 +27,14 @@ func (h *Pinger) Do() {
 	h.Overlay.PM.Peers.Range(func(_, value interface{}) bool {
 		go func() {
 			n := rand.Uint64()
-			pong, error := value.(*Peer).Ping(&pb.Ping{Nonce: n, Addr: h.Overlay.PRC.String()})
-			if error == nil && pong.AckNonce == n {
-				// TODO: We need to handle wrong response case too
+			peer := value.(*Peer)
+			pong, error := peer.Ping(&pb.Ping{Nonce: n, Addr: h.Overlay.PRC.String()})
+			if error != nil {
+				h.Overlay.PM.Penalize(peer.String(), PenaltyTimeout)
+				return
+			}
+			if pong.AckNonce == n {
+				h.Overlay.PM.Reward(peer.String())
 			}
 		}()
 		return true
@@ -16,6 +16,7 @@ It has these top-level messages:
 	BroadcastRes
 	TellReq
 	TellRes
+	Handshake
 */
 package network
 
@@ -117,6 +118,8 @@ type BroadcastReq struct {
 	Header  uint32 `protobuf:"varint,1,opt,name=header" json:"header,omitempty"`
 	MsgType uint32 `protobuf:"varint,2,opt,name=msg_type,json=msgType" json:"msg_type,omitempty"`
 	MsgBody []byte `protobuf:"bytes,3,opt,name=msg_body,json=msgBody,proto3" json:"msg_body,omitempty"`
+	// Every one relaying the message tells its address, which the receiver holds accountable for the message
+	Addr string `protobuf:"bytes,4,opt,name=addr" json:"addr,omitempty"`
 }
 
 func (m *BroadcastReq) Reset()                    { *m = BroadcastReq{} }
@@ -145,6 +148,13 @@ func (m *BroadcastReq) GetMsgBody() []byte {
 	return nil
 }
 
+func (m *BroadcastReq) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
 type BroadcastRes struct {
 	Header uint32 `protobuf:"varint,1,opt,name=header" json:"header,omitempty"`
 }
@@ -217,6 +227,62 @@ func (m *TellRes) GetHeader() uint32 {
 	return 0
 }
 
+type Handshake struct {
+	Version      uint32 `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	ChainId      uint32 `protobuf:"varint,2,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+	GenesisHash  []byte `protobuf:"bytes,3,opt,name=genesis_hash,json=genesisHash,proto3" json:"genesis_hash,omitempty"`
+	TipHeight    uint32 `protobuf:"varint,4,opt,name=tip_height,json=tipHeight" json:"tip_height,omitempty"`
+	Capabilities uint64 `protobuf:"varint,5,opt,name=capabilities" json:"capabilities,omitempty"`
+	Addr         string `protobuf:"bytes,6,opt,name=addr" json:"addr,omitempty"`
+}
+
+func (m *Handshake) Reset()                    { *m = Handshake{} }
+func (m *Handshake) String() string            { return proto.CompactTextString(m) }
+func (*Handshake) ProtoMessage()               {}
+func (*Handshake) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *Handshake) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Handshake) GetChainId() uint32 {
+	if m != nil {
+		return m.ChainId
+	}
+	return 0
+}
+
+func (m *Handshake) GetGenesisHash() []byte {
+	if m != nil {
+		return m.GenesisHash
+	}
+	return nil
+}
+
+func (m *Handshake) GetTipHeight() uint32 {
+	if m != nil {
+		return m.TipHeight
+	}
+	return 0
+}
+
+func (m *Handshake) GetCapabilities() uint64 {
+	if m != nil {
+		return m.Capabilities
+	}
+	return 0
+}
+
+func (m *Handshake) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Ping)(nil), "network.Ping")
 	proto.RegisterType((*Pong)(nil), "network.Pong")
@@ -226,6 +292,7 @@ func init() {
 	proto.RegisterType((*BroadcastRes)(nil), "network.BroadcastRes")
 	proto.RegisterType((*TellReq)(nil), "network.TellReq")
 	proto.RegisterType((*TellRes)(nil), "network.TellRes")
+	proto.RegisterType((*Handshake)(nil), "network.Handshake")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -243,6 +310,7 @@ type PeerClient interface {
 	GetPeers(ctx context.Context, in *GetPeersReq, opts ...grpc.CallOption) (*GetPeersRes, error)
 	Broadcast(ctx context.Context, in *BroadcastReq, opts ...grpc.CallOption) (*BroadcastRes, error)
 	Tell(ctx context.Context, in *TellReq, opts ...grpc.CallOption) (*TellRes, error)
+	Handshake(ctx context.Context, in *Handshake, opts ...grpc.CallOption) (*Handshake, error)
 }
 
 type peerClient struct {
@@ -289,6 +357,15 @@ func (c *peerClient) Tell(ctx context.Context, in *TellReq, opts ...grpc.CallOpt
 	return out, nil
 }
 
+func (c *peerClient) Handshake(ctx context.Context, in *Handshake, opts ...grpc.CallOption) (*Handshake, error) {
+	out := new(Handshake)
+	err := grpc.Invoke(ctx, "/network.Peer/handshake", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Peer service
 
 type PeerServer interface {
@@ -296,6 +373,7 @@ type PeerServer interface {
 	GetPeers(context.Context, *GetPeersReq) (*GetPeersRes, error)
 	Broadcast(context.Context, *BroadcastReq) (*BroadcastRes, error)
 	Tell(context.Context, *TellReq) (*TellRes, error)
+	Handshake(context.Context, *Handshake) (*Handshake, error)
 }
 
 func RegisterPeerServer(s *grpc.Server, srv PeerServer) {
@@ -374,6 +452,24 @@ func _Peer_Tell_Handler(srv interface{}, ctx context.Context, dec func(interface
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Peer_Handshake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Handshake)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServer).Handshake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.Peer/Handshake",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).Handshake(ctx, req.(*Handshake))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Peer_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "network.Peer",
 	HandlerType: (*PeerServer)(nil),
@@ -394,6 +490,10 @@ var _Peer_serviceDesc = grpc.ServiceDesc{
 			MethodName: "tell",
 			Handler:    _Peer_Tell_Handler,
 		},
+		{
+			MethodName: "handshake",
+			Handler:    _Peer_Handshake_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "network/proto/rpc.proto",
@@ -402,27 +502,33 @@ var _Peer_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("network/proto/rpc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 340 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x52, 0xcd, 0x4a, 0xf3, 0x40,
-	0x14, 0x6d, 0xbe, 0xce, 0xd7, 0x9f, 0x6b, 0x0b, 0x72, 0xa9, 0x5a, 0xe3, 0x26, 0x9d, 0x42, 0xe9,
-	0x42, 0x5a, 0xd1, 0x8d, 0xe0, 0xae, 0x1b, 0x77, 0x52, 0x42, 0x97, 0x42, 0x49, 0x93, 0x4b, 0x94,
-	0xa4, 0x33, 0x71, 0x66, 0x44, 0xf2, 0x9c, 0xbe, 0x90, 0x4c, 0x9a, 0x86, 0x54, 0x5a, 0x77, 0x73,
-	0xee, 0xcf, 0xb9, 0x87, 0x73, 0x06, 0xae, 0x04, 0x99, 0x2f, 0xa9, 0x92, 0x79, 0xa6, 0xa4, 0x91,
-	0x73, 0x95, 0x85, 0xb3, 0xe2, 0x85, 0xed, 0xb2, 0xc1, 0xef, 0x80, 0x2d, 0xdf, 0x45, 0x8c, 0x03,
-	0xf8, 0x2f, 0xa4, 0x08, 0x69, 0xe8, 0x78, 0xce, 0x94, 0xf9, 0x3b, 0x80, 0x08, 0x2c, 0x88, 0x22,
-	0x35, 0xfc, 0xe7, 0x39, 0xd3, 0xae, 0x5f, 0xbc, 0xf9, 0x18, 0xd8, 0x52, 0x8a, 0x18, 0x6f, 0xa0,
-	0x1b, 0x84, 0xc9, 0xba, 0xbe, 0xd5, 0x09, 0xc2, 0xe4, 0xc5, 0x62, 0x3e, 0x86, 0xb3, 0x67, 0x32,
-	0x4b, 0x22, 0xa5, 0x7d, 0xfa, 0xb0, 0xec, 0xa1, 0xfc, 0x14, 0xa6, 0x98, 0xeb, 0xfb, 0x3b, 0xc0,
-	0x47, 0xf5, 0x21, 0x5d, 0x1d, 0x73, 0xbc, 0x66, 0x75, 0xec, 0x15, 0x7a, 0x0b, 0x25, 0x83, 0x28,
-	0x0c, 0xb4, 0xb1, 0x44, 0x97, 0xd0, 0x7a, 0xa3, 0x20, 0x22, 0x55, 0x32, 0x95, 0x08, 0xaf, 0xa1,
-	0xb3, 0xd5, 0xf1, 0xda, 0xe4, 0x19, 0x15, 0x62, 0xfb, 0x7e, 0x7b, 0xab, 0xe3, 0x55, 0x9e, 0xd1,
-	0xbe, 0xb5, 0x91, 0x51, 0x3e, 0x6c, 0x7a, 0xce, 0xb4, 0x57, 0xb4, 0x16, 0x32, 0xca, 0xf9, 0xe4,
-	0x80, 0x5d, 0x9f, 0x62, 0xe7, 0x09, 0xb4, 0x57, 0x94, 0xa6, 0x7f, 0x09, 0x38, 0xe2, 0xd4, 0x81,
-	0xa8, 0xe6, 0x69, 0x51, 0xec, 0x50, 0xd4, 0x68, 0x7f, 0xec, 0xa4, 0x9e, 0xfb, 0x6f, 0x07, 0x98,
-	0xb5, 0x0d, 0x27, 0xc0, 0x32, 0x9b, 0x5e, 0x7f, 0x56, 0xe6, 0x39, 0xb3, 0x61, 0xba, 0x35, 0x28,
-	0x45, 0xcc, 0x1b, 0xf8, 0x08, 0x9d, 0xb8, 0x74, 0x1a, 0x07, 0x55, 0xb3, 0x96, 0x90, 0x7b, 0xac,
-	0xaa, 0x79, 0x03, 0x9f, 0xa0, 0xbb, 0xd9, 0x5b, 0x84, 0x17, 0xd5, 0x50, 0x3d, 0x14, 0xf7, 0x68,
-	0xd9, 0x2e, 0xdf, 0x02, 0x33, 0x94, 0xa6, 0x78, 0x5e, 0x0d, 0x94, 0x36, 0xba, 0xbf, 0x2b, 0x9a,
-	0x37, 0x36, 0xad, 0xe2, 0x6b, 0x3e, 0xfc, 0x04, 0x00, 0x00, 0xff, 0xff, 0x16, 0x62, 0xc1, 0x08,
-	0xb5, 0x02, 0x00, 0x00,
+	// 442 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7d, 0x53, 0x4d, 0x4b, 0xc3, 0x40,
+	0x10, 0xb5, 0x36, 0xb6, 0xcd, 0xd8, 0x82, 0x2c, 0x7e, 0xc4, 0x88, 0xa0, 0x11, 0xc4, 0x83, 0x54,
+	0x51, 0x04, 0xc1, 0x9b, 0x17, 0xeb, 0x45, 0x24, 0x78, 0x0f, 0xdb, 0x64, 0x49, 0x96, 0xd6, 0xdd,
+	0x98, 0x5d, 0x15, 0xff, 0x82, 0x3f, 0xc9, 0x5f, 0xe7, 0x64, 0xdd, 0xae, 0xa9, 0xb4, 0xde, 0xf6,
+	0xbd, 0x79, 0xf3, 0x91, 0x99, 0x17, 0xd8, 0x11, 0x4c, 0xbf, 0xcb, 0x6a, 0x72, 0x56, 0x56, 0x52,
+	0xcb, 0xb3, 0xaa, 0x4c, 0x87, 0xe6, 0x45, 0xba, 0x36, 0x10, 0x9d, 0x83, 0xf7, 0xc8, 0x45, 0x4e,
+	0x36, 0x61, 0x4d, 0x48, 0x91, 0xb2, 0xa0, 0x75, 0xd0, 0x3a, 0xf1, 0xe2, 0x1f, 0x40, 0x08, 0x78,
+	0x34, 0xcb, 0xaa, 0x60, 0x15, 0x49, 0x3f, 0x36, 0xef, 0xe8, 0x08, 0x33, 0x24, 0x66, 0xec, 0x81,
+	0x4f, 0xd3, 0x49, 0xd2, 0xcc, 0xea, 0x21, 0xf1, 0x50, 0x63, 0x14, 0xad, 0xdf, 0x31, 0xfd, 0xc8,
+	0x58, 0xa5, 0x62, 0xf6, 0x52, 0x57, 0x4f, 0xe5, 0xab, 0xd0, 0x46, 0x37, 0x88, 0x7f, 0x40, 0x74,
+	0xd8, 0x14, 0x29, 0xd7, 0xac, 0x75, 0xd0, 0x76, 0xcd, 0x4a, 0xe8, 0xdf, 0x56, 0x92, 0x66, 0x29,
+	0x55, 0xba, 0x2e, 0xb4, 0x0d, 0x9d, 0x82, 0xd1, 0x8c, 0x55, 0xb6, 0x92, 0x45, 0x64, 0x17, 0x7a,
+	0xcf, 0x2a, 0x4f, 0xf4, 0x47, 0xc9, 0xcc, 0xb0, 0x83, 0xb8, 0x8b, 0xf8, 0x09, 0xe1, 0x2c, 0x34,
+	0x96, 0xd9, 0x47, 0xd0, 0xc6, 0x50, 0xdf, 0x84, 0x6e, 0x11, 0xba, 0x8e, 0x5e, 0xe3, 0xf3, 0x8e,
+	0xe7, 0x3a, 0xaa, 0x65, 0x1d, 0xa3, 0x09, 0x74, 0x9f, 0xd8, 0x74, 0xfa, 0xdf, 0x50, 0x0b, 0xb6,
+	0x37, 0x37, 0x68, 0x7b, 0xf9, 0xa0, 0xde, 0xdc, 0xa0, 0xb8, 0x29, 0xdb, 0x6c, 0xf9, 0x3c, 0x5f,
+	0x2d, 0xf0, 0x47, 0x54, 0x64, 0xaa, 0xa0, 0x13, 0x46, 0x02, 0xe8, 0xbe, 0xe1, 0x5a, 0xb9, 0x14,
+	0x56, 0x36, 0x83, 0x75, 0x97, 0xb4, 0xa0, 0x5c, 0x24, 0x3c, 0x9b, 0x6d, 0xca, 0xe0, 0xfb, 0x8c,
+	0x1c, 0x42, 0x3f, 0x67, 0x82, 0x29, 0xae, 0x92, 0x82, 0xaa, 0xc2, 0x6e, 0x6b, 0xdd, 0x72, 0x23,
+	0xa4, 0xc8, 0x3e, 0x80, 0xe6, 0x65, 0x52, 0x30, 0x9e, 0x17, 0xda, 0x4c, 0x39, 0x88, 0x7d, 0x64,
+	0x46, 0x86, 0x20, 0x11, 0xf4, 0x53, 0x5a, 0xd2, 0x31, 0x9f, 0x72, 0xcd, 0x99, 0x0a, 0xd6, 0x8c,
+	0x2d, 0xe6, 0x38, 0xb7, 0x95, 0xce, 0xef, 0x56, 0x2e, 0x3e, 0x57, 0xd1, 0x54, 0xe8, 0x03, 0x72,
+	0x0c, 0x5e, 0x59, 0xdb, 0x71, 0x30, 0xb4, 0x06, 0x1d, 0xd6, 0xee, 0x0c, 0x1b, 0x10, 0xad, 0x17,
+	0xad, 0x90, 0x6b, 0xe8, 0xe5, 0xd6, 0x3a, 0x64, 0xd3, 0x05, 0x1b, 0x96, 0x0b, 0x17, 0xb1, 0x0a,
+	0x33, 0x6f, 0xc0, 0x1f, 0xcf, 0xee, 0x4b, 0xb6, 0x9c, 0xa8, 0xe9, 0xb2, 0x70, 0x21, 0x5d, 0x27,
+	0x9f, 0x82, 0xa7, 0xf1, 0x0e, 0x64, 0xc3, 0x09, 0xac, 0x07, 0xc2, 0xbf, 0x4c, 0xad, 0xbe, 0x02,
+	0xbf, 0x70, 0x17, 0x21, 0x4e, 0xe0, 0xae, 0x14, 0x2e, 0xe0, 0xa2, 0x95, 0x71, 0xc7, 0xfc, 0xa2,
+	0x97, 0xdf, 0x41, 0x5a, 0xbc, 0xe1, 0xbd, 0x03, 0x00, 0x00,
 }
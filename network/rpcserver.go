@@ -12,17 +12,20 @@ import (
 	"sync"
 	"time"
 
-	"github.com/golang/glog"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/common/service"
 	"github.com/iotexproject/iotex-core/common/utils"
 	pb "github.com/iotexproject/iotex-core/network/proto"
 	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/wire"
 )
 
 // RPCServer represents the listener at the transportation layer
@@ -52,6 +55,9 @@ func (s *RPCServer) Ping(ctx context.Context, ping *pb.Ping) (*pb.Pong, error) {
 	if drop {
 		return nil, fmt.Errorf("sended requests too frequently")
 	}
+	if s.Overlay.PM.IsBanned(ping.Addr) {
+		return nil, ErrPeerBanned
+	}
 	s.Overlay.PM.AddPeer(ping.Addr)
 	return &pb.Pong{AckNonce: ping.Nonce}, nil
 }
@@ -89,6 +95,9 @@ func (s *RPCServer) Broadcast(ctx context.Context, req *pb.BroadcastReq) (*pb.Br
 	if drop {
 		return nil, fmt.Errorf("sended requests too frequently")
 	}
+	if s.Overlay.PM.IsBanned(req.Addr) {
+		return nil, ErrPeerBanned
+	}
 	err = s.Overlay.Gossip.OnReceivingMsg(req)
 	if err == nil {
 		return &pb.BroadcastRes{Header: iproto.MagicBroadcastMsgHeader}, nil
@@ -105,8 +114,12 @@ func (s *RPCServer) Tell(ctx context.Context, req *pb.TellReq) (*pb.TellRes, err
 	if drop {
 		return nil, fmt.Errorf("sended requests too frequently")
 	}
-	protoMsg, err := iproto.TypifyProtoMsg(req.MsgType, req.MsgBody)
+	if s.Overlay.PM.IsBanned(req.Addr) {
+		return nil, ErrPeerBanned
+	}
+	protoMsg, err := wire.Decode(req.MsgType, req.MsgBody)
 	if err != nil {
+		s.Overlay.PM.Penalize(req.Addr, PenaltyInvalidMsg)
 		return nil, err
 	}
 	if s.Overlay.Dispatcher != nil {
@@ -115,12 +128,32 @@ func (s *RPCServer) Tell(ctx context.Context, req *pb.TellReq) (*pb.TellRes, err
 	return &pb.TellRes{Header: iproto.MagicBroadcastMsgHeader}, nil
 }
 
+// Handshake implements the server side RPC logic
+func (s *RPCServer) Handshake(ctx context.Context, hs *pb.Handshake) (*pb.Handshake, error) {
+	drop, err := s.shouldDropRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if drop {
+		return nil, fmt.Errorf("sended requests too frequently")
+	}
+	if s.Overlay.PM.IsBanned(hs.Addr) {
+		return nil, ErrPeerBanned
+	}
+	local := s.Overlay.handshake()
+	if err := checkHandshake(local, hs); err != nil {
+		log.Warningf("Handshake from node at address %s failed: %v", hs.Addr, err)
+		// tell the peer to disconnect rather than retrying
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return local, nil
+}
+
 // Start starts the rpc server
 func (s *RPCServer) Start() error {
 	lis, err := net.Listen(s.Network(), s.String())
 	if err != nil {
-		glog.Fatalf("Node failed to listen: %v", err)
-		return err
+		return errors.Wrap(err, "Node failed to listen")
 	}
 	s.Addr = lis.Addr().String()
 	// Create the gRPC server with the credentials
@@ -146,7 +179,7 @@ func (s *RPCServer) Start() error {
 	reflection.Register(s.Server)
 	go func() {
 		if err := s.Server.Serve(lis); err != nil {
-			glog.Fatalf("Node failed to serve: %v", err)
+			log.Errorf("Node failed to serve: %v", err)
 		}
 	}()
 	return nil
@@ -118,6 +118,7 @@ func TestRPCTell(t *testing.T) {
 
 	config := LoadTestConfig("", true)
 	o := &Overlay{Dispatcher: dp, Config: config}
+	o.PM = NewPeerManager(o, 1, 1)
 	s := NewRPCServer(o)
 	o.PRC = s
 	s.Start()
@@ -151,6 +152,7 @@ func TestRateLimit(t *testing.T) {
 	config.RateLimitPerSec = 5
 	config.RateLimitWindowSize = time.Second
 	o := &Overlay{Dispatcher: dp, Config: config}
+	o.PM = NewPeerManager(o, 1, 1)
 	s := NewRPCServer(o)
 	o.PRC = s
 	s.Start()
@@ -9,12 +9,11 @@ package network
 import (
 	"crypto/tls"
 	"crypto/x509"
-	"errors"
 	"io/ioutil"
 	"math/rand"
 	"sync"
 
-	"github.com/golang/glog"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc/credentials"
 
 	"github.com/iotexproject/iotex-core/config"
@@ -42,16 +41,14 @@ func loadCertAndCertPool(config *config.Network) (*tls.Certificate, *x509.CertPo
 	// Load the certificates from disk
 	cert, err := tls.LoadX509KeyPair(config.PeerCrtPath, config.PeerKeyPath)
 	if err != nil {
-		glog.Fatalf("could not load peer key pair: %v", err)
-		return nil, nil, err
+		return nil, nil, errors.Wrap(err, "could not load peer key pair")
 	}
 
 	// Create a certificate pool from the certificate authority
 	certPool := x509.NewCertPool()
 	caCert, err := ioutil.ReadFile(config.CACrtPath)
 	if err != nil {
-		glog.Fatalf("could not read ca certificate: %v", err)
-		return nil, nil, err
+		return nil, nil, errors.Wrap(err, "could not read ca certificate")
 	}
 
 	// Append the peer certificates from the CA
@@ -1,35 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // source: blockchain.proto
 
-/*
-Package iproto is a generated protocol buffer package.
-
-It is generated from these files:
-	blockchain.proto
-	rpc.proto
-	utxo.proto
-
-It has these top-level messages:
-	TxInputPb
-	TxOutputPb
-	TxPb
-	BlockHeaderPb
-	BlockPb
-	BlockIndex
-	PingMsg
-	PongMsg
-	BlockSync
-	BlockContainer
-	ViewChangeMsg
-	TestPayload
-	CreateRawTxRequest
-	CreateRawTxReply
-	SendTxRequest
-	SendTxReply
-	UtxoPb
-	UtxoEntryPb
-	UtxoMapPb
-*/
 package iproto
 
 import proto "github.com/golang/protobuf/proto"
@@ -41,12 +12,6 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the proto package it is being compiled against.
-// A compilation error at this line likely means your copy of the
-// proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
-
 type ViewChangeMsg_ViewChangeType int32
 
 const (
@@ -73,7 +38,7 @@ func (x ViewChangeMsg_ViewChangeType) String() string {
 	return proto.EnumName(ViewChangeMsg_ViewChangeType_name, int32(x))
 }
 func (ViewChangeMsg_ViewChangeType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{10, 0}
+	return fileDescriptor1, []int{29, 0}
 }
 
 type TxInputPb struct {
@@ -87,7 +52,7 @@ type TxInputPb struct {
 func (m *TxInputPb) Reset()                    { *m = TxInputPb{} }
 func (m *TxInputPb) String() string            { return proto.CompactTextString(m) }
 func (*TxInputPb) ProtoMessage()               {}
-func (*TxInputPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+func (*TxInputPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
 
 func (m *TxInputPb) GetTxHash() []byte {
 	if m != nil {
@@ -136,7 +101,7 @@ type TxOutputPb struct {
 func (m *TxOutputPb) Reset()                    { *m = TxOutputPb{} }
 func (m *TxOutputPb) String() string            { return proto.CompactTextString(m) }
 func (*TxOutputPb) ProtoMessage()               {}
-func (*TxOutputPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*TxOutputPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
 
 func (m *TxOutputPb) GetValue() uint64 {
 	if m != nil {
@@ -160,18 +125,20 @@ func (m *TxOutputPb) GetLockScript() []byte {
 }
 
 type TxPb struct {
-	Version  uint32        `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
-	NumTxIn  uint32        `protobuf:"varint,2,opt,name=numTxIn" json:"numTxIn,omitempty"`
-	TxIn     []*TxInputPb  `protobuf:"bytes,3,rep,name=txIn" json:"txIn,omitempty"`
-	NumTxOut uint32        `protobuf:"varint,4,opt,name=numTxOut" json:"numTxOut,omitempty"`
-	TxOut    []*TxOutputPb `protobuf:"bytes,5,rep,name=txOut" json:"txOut,omitempty"`
-	LockTime uint32        `protobuf:"varint,6,opt,name=lockTime" json:"lockTime,omitempty"`
+	Version      uint32        `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	NumTxIn      uint32        `protobuf:"varint,2,opt,name=numTxIn" json:"numTxIn,omitempty"`
+	TxIn         []*TxInputPb  `protobuf:"bytes,3,rep,name=txIn" json:"txIn,omitempty"`
+	NumTxOut     uint32        `protobuf:"varint,4,opt,name=numTxOut" json:"numTxOut,omitempty"`
+	TxOut        []*TxOutputPb `protobuf:"bytes,5,rep,name=txOut" json:"txOut,omitempty"`
+	LockTime     uint32        `protobuf:"varint,6,opt,name=lockTime" json:"lockTime,omitempty"`
+	ExpiryHeight uint32        `protobuf:"varint,7,opt,name=expiryHeight" json:"expiryHeight,omitempty"`
+	ChainID      uint32        `protobuf:"varint,8,opt,name=chainID" json:"chainID,omitempty"`
 }
 
 func (m *TxPb) Reset()                    { *m = TxPb{} }
 func (m *TxPb) String() string            { return proto.CompactTextString(m) }
 func (*TxPb) ProtoMessage()               {}
-func (*TxPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*TxPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
 
 func (m *TxPb) GetVersion() uint32 {
 	if m != nil {
@@ -215,251 +182,1351 @@ func (m *TxPb) GetLockTime() uint32 {
 	return 0
 }
 
-// header of a block
-type BlockHeaderPb struct {
-	Version       uint32 `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
-	ChainID       uint32 `protobuf:"varint,2,opt,name=chainID" json:"chainID,omitempty"`
-	Height        uint32 `protobuf:"varint,3,opt,name=height" json:"height,omitempty"`
-	Timestamp     uint64 `protobuf:"varint,4,opt,name=timestamp" json:"timestamp,omitempty"`
-	PrevBlockHash []byte `protobuf:"bytes,5,opt,name=prevBlockHash,proto3" json:"prevBlockHash,omitempty"`
-	MerkleRoot    []byte `protobuf:"bytes,6,opt,name=merkleRoot,proto3" json:"merkleRoot,omitempty"`
-	TrnxNumber    uint32 `protobuf:"varint,7,opt,name=trnxNumber" json:"trnxNumber,omitempty"`
-	TrnxDataSize  uint32 `protobuf:"varint,8,opt,name=trnxDataSize" json:"trnxDataSize,omitempty"`
+func (m *TxPb) GetExpiryHeight() uint32 {
+	if m != nil {
+		return m.ExpiryHeight
+	}
+	return 0
 }
 
-func (m *BlockHeaderPb) Reset()                    { *m = BlockHeaderPb{} }
-func (m *BlockHeaderPb) String() string            { return proto.CompactTextString(m) }
-func (*BlockHeaderPb) ProtoMessage()               {}
-func (*BlockHeaderPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (m *TxPb) GetChainID() uint32 {
+	if m != nil {
+		return m.ChainID
+	}
+	return 0
+}
 
-func (m *BlockHeaderPb) GetVersion() uint32 {
+type TransferPb struct {
+	Version      uint32 `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Nonce        uint64 `protobuf:"varint,2,opt,name=nonce" json:"nonce,omitempty"`
+	Amount       uint64 `protobuf:"varint,3,opt,name=amount" json:"amount,omitempty"`
+	Sender       string `protobuf:"bytes,4,opt,name=sender" json:"sender,omitempty"`
+	Recipient    string `protobuf:"bytes,5,opt,name=recipient" json:"recipient,omitempty"`
+	SenderPubKey []byte `protobuf:"bytes,6,opt,name=senderPubKey,proto3" json:"senderPubKey,omitempty"`
+	Signature    []byte `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *TransferPb) Reset()                    { *m = TransferPb{} }
+func (m *TransferPb) String() string            { return proto.CompactTextString(m) }
+func (*TransferPb) ProtoMessage()               {}
+func (*TransferPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+
+func (m *TransferPb) GetVersion() uint32 {
 	if m != nil {
 		return m.Version
 	}
 	return 0
 }
 
-func (m *BlockHeaderPb) GetChainID() uint32 {
+func (m *TransferPb) GetNonce() uint64 {
 	if m != nil {
-		return m.ChainID
+		return m.Nonce
 	}
 	return 0
 }
 
-func (m *BlockHeaderPb) GetHeight() uint32 {
+func (m *TransferPb) GetAmount() uint64 {
 	if m != nil {
-		return m.Height
+		return m.Amount
 	}
 	return 0
 }
 
-func (m *BlockHeaderPb) GetTimestamp() uint64 {
+func (m *TransferPb) GetSender() string {
 	if m != nil {
-		return m.Timestamp
+		return m.Sender
 	}
-	return 0
+	return ""
 }
 
-func (m *BlockHeaderPb) GetPrevBlockHash() []byte {
+func (m *TransferPb) GetRecipient() string {
 	if m != nil {
-		return m.PrevBlockHash
+		return m.Recipient
+	}
+	return ""
+}
+
+func (m *TransferPb) GetSenderPubKey() []byte {
+	if m != nil {
+		return m.SenderPubKey
 	}
 	return nil
 }
 
-func (m *BlockHeaderPb) GetMerkleRoot() []byte {
+func (m *TransferPb) GetSignature() []byte {
 	if m != nil {
-		return m.MerkleRoot
+		return m.Signature
 	}
 	return nil
 }
 
-func (m *BlockHeaderPb) GetTrnxNumber() uint32 {
+// execution deploys or invokes a smart contract, signed by the executor
+type ExecutionPb struct {
+	Version        uint32   `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Nonce          uint64   `protobuf:"varint,2,opt,name=nonce" json:"nonce,omitempty"`
+	Executor       string   `protobuf:"bytes,3,opt,name=executor" json:"executor,omitempty"`
+	Contract       string   `protobuf:"bytes,4,opt,name=contract" json:"contract,omitempty"`
+	Code           []byte   `protobuf:"bytes,5,opt,name=code,proto3" json:"code,omitempty"`
+	Args           [][]byte `protobuf:"bytes,6,rep,name=args,proto3" json:"args,omitempty"`
+	GasLimit       uint64   `protobuf:"varint,7,opt,name=gasLimit" json:"gasLimit,omitempty"`
+	ExecutorPubKey []byte   `protobuf:"bytes,8,opt,name=executorPubKey,proto3" json:"executorPubKey,omitempty"`
+	Signature      []byte   `protobuf:"bytes,9,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *ExecutionPb) Reset()                    { *m = ExecutionPb{} }
+func (m *ExecutionPb) String() string            { return proto.CompactTextString(m) }
+func (*ExecutionPb) ProtoMessage()               {}
+func (*ExecutionPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+
+func (m *ExecutionPb) GetVersion() uint32 {
 	if m != nil {
-		return m.TrnxNumber
+		return m.Version
 	}
 	return 0
 }
 
-func (m *BlockHeaderPb) GetTrnxDataSize() uint32 {
+func (m *ExecutionPb) GetNonce() uint64 {
 	if m != nil {
-		return m.TrnxDataSize
+		return m.Nonce
 	}
 	return 0
 }
 
-// block consists of header followed by transactions
-// hash of current block can be computed from header hence not stored
-type BlockPb struct {
-	Header       *BlockHeaderPb `protobuf:"bytes,1,opt,name=Header" json:"Header,omitempty"`
-	Transactions []*TxPb        `protobuf:"bytes,2,rep,name=Transactions" json:"Transactions,omitempty"`
+func (m *ExecutionPb) GetExecutor() string {
+	if m != nil {
+		return m.Executor
+	}
+	return ""
 }
 
-func (m *BlockPb) Reset()                    { *m = BlockPb{} }
-func (m *BlockPb) String() string            { return proto.CompactTextString(m) }
-func (*BlockPb) ProtoMessage()               {}
-func (*BlockPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (m *ExecutionPb) GetContract() string {
+	if m != nil {
+		return m.Contract
+	}
+	return ""
+}
 
-func (m *BlockPb) GetHeader() *BlockHeaderPb {
+func (m *ExecutionPb) GetCode() []byte {
 	if m != nil {
-		return m.Header
+		return m.Code
 	}
 	return nil
 }
 
-func (m *BlockPb) GetTransactions() []*TxPb {
+func (m *ExecutionPb) GetArgs() [][]byte {
 	if m != nil {
-		return m.Transactions
+		return m.Args
 	}
 	return nil
 }
 
-// index of block raw data file
-type BlockIndex struct {
-	Start  uint32   `protobuf:"varint,1,opt,name=start" json:"start,omitempty"`
-	End    uint32   `protobuf:"varint,2,opt,name=end" json:"end,omitempty"`
-	Offset []uint32 `protobuf:"varint,3,rep,packed,name=offset" json:"offset,omitempty"`
+func (m *ExecutionPb) GetGasLimit() uint64 {
+	if m != nil {
+		return m.GasLimit
+	}
+	return 0
 }
 
-func (m *BlockIndex) Reset()                    { *m = BlockIndex{} }
-func (m *BlockIndex) String() string            { return proto.CompactTextString(m) }
-func (*BlockIndex) ProtoMessage()               {}
-func (*BlockIndex) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (m *ExecutionPb) GetExecutorPubKey() []byte {
+	if m != nil {
+		return m.ExecutorPubKey
+	}
+	return nil
+}
 
-func (m *BlockIndex) GetStart() uint32 {
+func (m *ExecutionPb) GetSignature() []byte {
 	if m != nil {
-		return m.Start
+		return m.Signature
+	}
+	return nil
+}
+
+// vote stakes the balance of the voter toward the votee, signed by the voter
+type VotePb struct {
+	Version     uint32 `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Nonce       uint64 `protobuf:"varint,2,opt,name=nonce" json:"nonce,omitempty"`
+	Voter       string `protobuf:"bytes,3,opt,name=voter" json:"voter,omitempty"`
+	Votee       string `protobuf:"bytes,4,opt,name=votee" json:"votee,omitempty"`
+	VoterPubKey []byte `protobuf:"bytes,5,opt,name=voterPubKey,proto3" json:"voterPubKey,omitempty"`
+	Signature   []byte `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *VotePb) Reset()                    { *m = VotePb{} }
+func (m *VotePb) String() string            { return proto.CompactTextString(m) }
+func (*VotePb) ProtoMessage()               {}
+func (*VotePb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+
+func (m *VotePb) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
 	}
 	return 0
 }
 
-func (m *BlockIndex) GetEnd() uint32 {
+func (m *VotePb) GetNonce() uint64 {
 	if m != nil {
-		return m.End
+		return m.Nonce
 	}
 	return 0
 }
 
-func (m *BlockIndex) GetOffset() []uint32 {
+func (m *VotePb) GetVoter() string {
 	if m != nil {
-		return m.Offset
+		return m.Voter
+	}
+	return ""
+}
+
+func (m *VotePb) GetVotee() string {
+	if m != nil {
+		return m.Votee
+	}
+	return ""
+}
+
+func (m *VotePb) GetVoterPubKey() []byte {
+	if m != nil {
+		return m.VoterPubKey
 	}
 	return nil
 }
 
-// //////////////////////////////////////////////////////////////////////////////////////////////////
-// BELOW ARE DEFINITIONS FOR ON-WIRE MESSAGES!
-// //////////////////////////////////////////////////////////////////////////////////////////////////
-type PingMsg struct {
-	Nonce uint64 `protobuf:"varint,1,opt,name=nonce" json:"nonce,omitempty"`
+func (m *VotePb) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
 }
 
-func (m *PingMsg) Reset()                    { *m = PingMsg{} }
-func (m *PingMsg) String() string            { return proto.CompactTextString(m) }
-func (*PingMsg) ProtoMessage()               {}
-func (*PingMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+// evidence of the offender signing two different block headers at the same height
+type EvidencePb struct {
+	Version  uint32         `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Offender string         `protobuf:"bytes,2,opt,name=offender" json:"offender,omitempty"`
+	Header1  *BlockHeaderPb `protobuf:"bytes,3,opt,name=header1" json:"header1,omitempty"`
+	Header2  *BlockHeaderPb `protobuf:"bytes,4,opt,name=header2" json:"header2,omitempty"`
+}
 
-func (m *PingMsg) GetNonce() uint64 {
+func (m *EvidencePb) Reset()                    { *m = EvidencePb{} }
+func (m *EvidencePb) String() string            { return proto.CompactTextString(m) }
+func (*EvidencePb) ProtoMessage()               {}
+func (*EvidencePb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+
+func (m *EvidencePb) GetVersion() uint32 {
 	if m != nil {
-		return m.Nonce
+		return m.Version
 	}
 	return 0
 }
 
-type PongMsg struct {
-	AckNonce uint64 `protobuf:"varint,1,opt,name=ack_nonce,json=ackNonce" json:"ack_nonce,omitempty"`
+func (m *EvidencePb) GetOffender() string {
+	if m != nil {
+		return m.Offender
+	}
+	return ""
 }
 
-func (m *PongMsg) Reset()                    { *m = PongMsg{} }
-func (m *PongMsg) String() string            { return proto.CompactTextString(m) }
-func (*PongMsg) ProtoMessage()               {}
-func (*PongMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (m *EvidencePb) GetHeader1() *BlockHeaderPb {
+	if m != nil {
+		return m.Header1
+	}
+	return nil
+}
 
-func (m *PongMsg) GetAckNonce() uint64 {
+func (m *EvidencePb) GetHeader2() *BlockHeaderPb {
 	if m != nil {
-		return m.AckNonce
+		return m.Header2
+	}
+	return nil
+}
+
+// deposit moves the amount of the sender out of the source chain, to be credited to the recipient on the destination
+// chain by a withdraw, signed by the sender
+type DepositPb struct {
+	Version      uint32 `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Nonce        uint64 `protobuf:"varint,2,opt,name=nonce" json:"nonce,omitempty"`
+	Amount       uint64 `protobuf:"varint,3,opt,name=amount" json:"amount,omitempty"`
+	Sender       string `protobuf:"bytes,4,opt,name=sender" json:"sender,omitempty"`
+	SrcChainID   uint32 `protobuf:"varint,5,opt,name=srcChainID" json:"srcChainID,omitempty"`
+	DstChainID   uint32 `protobuf:"varint,6,opt,name=dstChainID" json:"dstChainID,omitempty"`
+	Recipient    string `protobuf:"bytes,7,opt,name=recipient" json:"recipient,omitempty"`
+	SenderPubKey []byte `protobuf:"bytes,8,opt,name=senderPubKey,proto3" json:"senderPubKey,omitempty"`
+	Signature    []byte `protobuf:"bytes,9,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *DepositPb) Reset()                    { *m = DepositPb{} }
+func (m *DepositPb) String() string            { return proto.CompactTextString(m) }
+func (*DepositPb) ProtoMessage()               {}
+func (*DepositPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{7} }
+
+func (m *DepositPb) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
 	}
 	return 0
 }
 
-type BlockSync struct {
-	Start uint32 `protobuf:"varint,2,opt,name=start" json:"start,omitempty"`
-	End   uint32 `protobuf:"varint,3,opt,name=end" json:"end,omitempty"`
+func (m *DepositPb) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
 }
 
-func (m *BlockSync) Reset()                    { *m = BlockSync{} }
-func (m *BlockSync) String() string            { return proto.CompactTextString(m) }
-func (*BlockSync) ProtoMessage()               {}
-func (*BlockSync) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (m *DepositPb) GetAmount() uint64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
 
-func (m *BlockSync) GetStart() uint32 {
+func (m *DepositPb) GetSender() string {
 	if m != nil {
-		return m.Start
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *DepositPb) GetSrcChainID() uint32 {
+	if m != nil {
+		return m.SrcChainID
 	}
 	return 0
 }
 
-func (m *BlockSync) GetEnd() uint32 {
+func (m *DepositPb) GetDstChainID() uint32 {
 	if m != nil {
-		return m.End
+		return m.DstChainID
 	}
 	return 0
 }
 
-// block container
-// used to send old/existing blocks in block sync
-type BlockContainer struct {
-	Block *BlockPb `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+func (m *DepositPb) GetRecipient() string {
+	if m != nil {
+		return m.Recipient
+	}
+	return ""
 }
 
-func (m *BlockContainer) Reset()                    { *m = BlockContainer{} }
-func (m *BlockContainer) String() string            { return proto.CompactTextString(m) }
-func (*BlockContainer) ProtoMessage()               {}
-func (*BlockContainer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (m *DepositPb) GetSenderPubKey() []byte {
+	if m != nil {
+		return m.SenderPubKey
+	}
+	return nil
+}
 
-func (m *BlockContainer) GetBlock() *BlockPb {
+func (m *DepositPb) GetSignature() []byte {
 	if m != nil {
-		return m.Block
+		return m.Signature
 	}
 	return nil
 }
 
-type ViewChangeMsg struct {
-	Vctype     ViewChangeMsg_ViewChangeType `protobuf:"varint,1,opt,name=vctype,enum=iproto.ViewChangeMsg_ViewChangeType" json:"vctype,omitempty"`
-	Block      *BlockPb                     `protobuf:"bytes,2,opt,name=block" json:"block,omitempty"`
-	BlockHash  []byte                       `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
-	SenderAddr string                       `protobuf:"bytes,4,opt,name=senderAddr" json:"senderAddr,omitempty"`
+// withdraw credits the amount of a deposit committed on the source chain to its recipient, with the proof of the
+// deposit's inclusion in the block of the header
+type WithdrawPb struct {
+	Version  uint32         `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Deposit  *DepositPb     `protobuf:"bytes,2,opt,name=deposit" json:"deposit,omitempty"`
+	Header   *BlockHeaderPb `protobuf:"bytes,3,opt,name=header" json:"header,omitempty"`
+	Index    uint32         `protobuf:"varint,4,opt,name=index" json:"index,omitempty"`
+	Siblings [][]byte       `protobuf:"bytes,5,rep,name=siblings,proto3" json:"siblings,omitempty"`
 }
 
-func (m *ViewChangeMsg) Reset()                    { *m = ViewChangeMsg{} }
-func (m *ViewChangeMsg) String() string            { return proto.CompactTextString(m) }
-func (*ViewChangeMsg) ProtoMessage()               {}
-func (*ViewChangeMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (m *WithdrawPb) Reset()                    { *m = WithdrawPb{} }
+func (m *WithdrawPb) String() string            { return proto.CompactTextString(m) }
+func (*WithdrawPb) ProtoMessage()               {}
+func (*WithdrawPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{8} }
 
-func (m *ViewChangeMsg) GetVctype() ViewChangeMsg_ViewChangeType {
+func (m *WithdrawPb) GetVersion() uint32 {
 	if m != nil {
-		return m.Vctype
+		return m.Version
 	}
-	return ViewChangeMsg_INVALID_VIEW_CHANGE_TYPE
+	return 0
 }
 
-func (m *ViewChangeMsg) GetBlock() *BlockPb {
+func (m *WithdrawPb) GetDeposit() *DepositPb {
 	if m != nil {
-		return m.Block
+		return m.Deposit
 	}
 	return nil
 }
 
-func (m *ViewChangeMsg) GetBlockHash() []byte {
+func (m *WithdrawPb) GetHeader() *BlockHeaderPb {
 	if m != nil {
-		return m.BlockHash
+		return m.Header
 	}
 	return nil
 }
 
-func (m *ViewChangeMsg) GetSenderAddr() string {
+func (m *WithdrawPb) GetIndex() uint32 {
 	if m != nil {
-		return m.SenderAddr
+		return m.Index
+	}
+	return 0
+}
+
+func (m *WithdrawPb) GetSiblings() [][]byte {
+	if m != nil {
+		return m.Siblings
+	}
+	return nil
+}
+
+// action of a kind registered by the node, serialized by the kind
+type ActionPb struct {
+	Kind    string `protobuf:"bytes,1,opt,name=kind" json:"kind,omitempty"`
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (m *ActionPb) Reset()                    { *m = ActionPb{} }
+func (m *ActionPb) String() string            { return proto.CompactTextString(m) }
+func (*ActionPb) ProtoMessage()               {}
+func (*ActionPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{9} }
+
+func (m *ActionPb) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *ActionPb) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+// candidate for the delegates along with the votes staked toward it
+type CandidatePb struct {
+	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Votes   uint64 `protobuf:"varint,2,opt,name=votes" json:"votes,omitempty"`
+}
+
+func (m *CandidatePb) Reset()                    { *m = CandidatePb{} }
+func (m *CandidatePb) String() string            { return proto.CompactTextString(m) }
+func (*CandidatePb) ProtoMessage()               {}
+func (*CandidatePb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{10} }
+
+func (m *CandidatePb) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *CandidatePb) GetVotes() uint64 {
+	if m != nil {
+		return m.Votes
+	}
+	return 0
+}
+
+// delegates elected for an epoch
+type CandidateListPb struct {
+	Candidates []*CandidatePb `protobuf:"bytes,1,rep,name=candidates" json:"candidates,omitempty"`
+}
+
+func (m *CandidateListPb) Reset()                    { *m = CandidateListPb{} }
+func (m *CandidateListPb) String() string            { return proto.CompactTextString(m) }
+func (*CandidateListPb) ProtoMessage()               {}
+func (*CandidateListPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{11} }
+
+func (m *CandidateListPb) GetCandidates() []*CandidatePb {
+	if m != nil {
+		return m.Candidates
+	}
+	return nil
+}
+
+// log emitted by a contract
+type LogPb struct {
+	Address     string   `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Topics      [][]byte `protobuf:"bytes,2,rep,name=topics,proto3" json:"topics,omitempty"`
+	Data        []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Hash        []byte   `protobuf:"bytes,4,opt,name=hash,proto3" json:"hash,omitempty"`
+	BlockHeight uint32   `protobuf:"varint,5,opt,name=blockHeight" json:"blockHeight,omitempty"`
+	Index       uint32   `protobuf:"varint,6,opt,name=index" json:"index,omitempty"`
+}
+
+func (m *LogPb) Reset()                    { *m = LogPb{} }
+func (m *LogPb) String() string            { return proto.CompactTextString(m) }
+func (*LogPb) ProtoMessage()               {}
+func (*LogPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{12} }
+
+func (m *LogPb) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *LogPb) GetTopics() [][]byte {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+func (m *LogPb) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *LogPb) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *LogPb) GetBlockHeight() uint32 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
+}
+
+func (m *LogPb) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+// receipt records the result of an execution
+type ReceiptPb struct {
+	Hash            []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Status          uint32   `protobuf:"varint,2,opt,name=status" json:"status,omitempty"`
+	GasUsed         uint64   `protobuf:"varint,3,opt,name=gasUsed" json:"gasUsed,omitempty"`
+	ContractAddress string   `protobuf:"bytes,4,opt,name=contractAddress" json:"contractAddress,omitempty"`
+	ReturnValue     []byte   `protobuf:"bytes,5,opt,name=returnValue,proto3" json:"returnValue,omitempty"`
+	BlockHeight     uint32   `protobuf:"varint,6,opt,name=blockHeight" json:"blockHeight,omitempty"`
+	Logs            []*LogPb `protobuf:"bytes,7,rep,name=logs" json:"logs,omitempty"`
+}
+
+func (m *ReceiptPb) Reset()                    { *m = ReceiptPb{} }
+func (m *ReceiptPb) String() string            { return proto.CompactTextString(m) }
+func (*ReceiptPb) ProtoMessage()               {}
+func (*ReceiptPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{13} }
+
+func (m *ReceiptPb) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *ReceiptPb) GetStatus() uint32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *ReceiptPb) GetGasUsed() uint64 {
+	if m != nil {
+		return m.GasUsed
+	}
+	return 0
+}
+
+func (m *ReceiptPb) GetContractAddress() string {
+	if m != nil {
+		return m.ContractAddress
+	}
+	return ""
+}
+
+func (m *ReceiptPb) GetReturnValue() []byte {
+	if m != nil {
+		return m.ReturnValue
+	}
+	return nil
+}
+
+func (m *ReceiptPb) GetBlockHeight() uint32 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
+}
+
+func (m *ReceiptPb) GetLogs() []*LogPb {
+	if m != nil {
+		return m.Logs
+	}
+	return nil
+}
+
+// header of a block
+type BlockHeaderPb struct {
+	Version       uint32 `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	ChainID       uint32 `protobuf:"varint,2,opt,name=chainID" json:"chainID,omitempty"`
+	Height        uint32 `protobuf:"varint,3,opt,name=height" json:"height,omitempty"`
+	Timestamp     uint64 `protobuf:"varint,4,opt,name=timestamp" json:"timestamp,omitempty"`
+	PrevBlockHash []byte `protobuf:"bytes,5,opt,name=prevBlockHash,proto3" json:"prevBlockHash,omitempty"`
+	MerkleRoot    []byte `protobuf:"bytes,6,opt,name=merkleRoot,proto3" json:"merkleRoot,omitempty"`
+	TrnxNumber    uint32 `protobuf:"varint,7,opt,name=trnxNumber" json:"trnxNumber,omitempty"`
+	TrnxDataSize  uint32 `protobuf:"varint,8,opt,name=trnxDataSize" json:"trnxDataSize,omitempty"`
+	Pubkey        []byte `protobuf:"bytes,9,opt,name=pubkey,proto3" json:"pubkey,omitempty"`
+	BlockSig      []byte `protobuf:"bytes,10,opt,name=blockSig,proto3" json:"blockSig,omitempty"`
+	StateRoot     []byte `protobuf:"bytes,11,opt,name=stateRoot,proto3" json:"stateRoot,omitempty"`
+}
+
+func (m *BlockHeaderPb) Reset()                    { *m = BlockHeaderPb{} }
+func (m *BlockHeaderPb) String() string            { return proto.CompactTextString(m) }
+func (*BlockHeaderPb) ProtoMessage()               {}
+func (*BlockHeaderPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{14} }
+
+func (m *BlockHeaderPb) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *BlockHeaderPb) GetChainID() uint32 {
+	if m != nil {
+		return m.ChainID
+	}
+	return 0
+}
+
+func (m *BlockHeaderPb) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *BlockHeaderPb) GetTimestamp() uint64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *BlockHeaderPb) GetPrevBlockHash() []byte {
+	if m != nil {
+		return m.PrevBlockHash
+	}
+	return nil
+}
+
+func (m *BlockHeaderPb) GetMerkleRoot() []byte {
+	if m != nil {
+		return m.MerkleRoot
+	}
+	return nil
+}
+
+func (m *BlockHeaderPb) GetTrnxNumber() uint32 {
+	if m != nil {
+		return m.TrnxNumber
+	}
+	return 0
+}
+
+func (m *BlockHeaderPb) GetTrnxDataSize() uint32 {
+	if m != nil {
+		return m.TrnxDataSize
+	}
+	return 0
+}
+
+func (m *BlockHeaderPb) GetPubkey() []byte {
+	if m != nil {
+		return m.Pubkey
+	}
+	return nil
+}
+
+func (m *BlockHeaderPb) GetBlockSig() []byte {
+	if m != nil {
+		return m.BlockSig
+	}
+	return nil
+}
+
+func (m *BlockHeaderPb) GetStateRoot() []byte {
+	if m != nil {
+		return m.StateRoot
+	}
+	return nil
+}
+
+// block consists of header followed by transactions
+// hash of current block can be computed from header hence not stored
+type BlockPb struct {
+	Header       *BlockHeaderPb `protobuf:"bytes,1,opt,name=Header" json:"Header,omitempty"`
+	Transactions []*TxPb        `protobuf:"bytes,2,rep,name=Transactions" json:"Transactions,omitempty"`
+	Transfers    []*TransferPb  `protobuf:"bytes,3,rep,name=Transfers" json:"Transfers,omitempty"`
+	Executions   []*ExecutionPb `protobuf:"bytes,4,rep,name=Executions" json:"Executions,omitempty"`
+	Votes        []*VotePb      `protobuf:"bytes,5,rep,name=Votes" json:"Votes,omitempty"`
+	Evidences    []*EvidencePb  `protobuf:"bytes,6,rep,name=Evidences" json:"Evidences,omitempty"`
+	Deposits     []*DepositPb   `protobuf:"bytes,7,rep,name=Deposits" json:"Deposits,omitempty"`
+	Withdraws    []*WithdrawPb  `protobuf:"bytes,8,rep,name=Withdraws" json:"Withdraws,omitempty"`
+	Actions      []*ActionPb    `protobuf:"bytes,9,rep,name=Actions" json:"Actions,omitempty"`
+}
+
+func (m *BlockPb) Reset()                    { *m = BlockPb{} }
+func (m *BlockPb) String() string            { return proto.CompactTextString(m) }
+func (*BlockPb) ProtoMessage()               {}
+func (*BlockPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{15} }
+
+func (m *BlockPb) GetHeader() *BlockHeaderPb {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *BlockPb) GetTransactions() []*TxPb {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+func (m *BlockPb) GetTransfers() []*TransferPb {
+	if m != nil {
+		return m.Transfers
+	}
+	return nil
+}
+
+func (m *BlockPb) GetExecutions() []*ExecutionPb {
+	if m != nil {
+		return m.Executions
+	}
+	return nil
+}
+
+func (m *BlockPb) GetVotes() []*VotePb {
+	if m != nil {
+		return m.Votes
+	}
+	return nil
+}
+
+func (m *BlockPb) GetEvidences() []*EvidencePb {
+	if m != nil {
+		return m.Evidences
+	}
+	return nil
+}
+
+func (m *BlockPb) GetDeposits() []*DepositPb {
+	if m != nil {
+		return m.Deposits
+	}
+	return nil
+}
+
+func (m *BlockPb) GetWithdraws() []*WithdrawPb {
+	if m != nil {
+		return m.Withdraws
+	}
+	return nil
+}
+
+func (m *BlockPb) GetActions() []*ActionPb {
+	if m != nil {
+		return m.Actions
+	}
+	return nil
+}
+
+// index of block raw data file
+type BlockIndex struct {
+	Start  uint32   `protobuf:"varint,1,opt,name=start" json:"start,omitempty"`
+	End    uint32   `protobuf:"varint,2,opt,name=end" json:"end,omitempty"`
+	Offset []uint32 `protobuf:"varint,3,rep,packed,name=offset" json:"offset,omitempty"`
+}
+
+func (m *BlockIndex) Reset()                    { *m = BlockIndex{} }
+func (m *BlockIndex) String() string            { return proto.CompactTextString(m) }
+func (*BlockIndex) ProtoMessage()               {}
+func (*BlockIndex) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{16} }
+
+func (m *BlockIndex) GetStart() uint32 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *BlockIndex) GetEnd() uint32 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *BlockIndex) GetOffset() []uint32 {
+	if m != nil {
+		return m.Offset
+	}
+	return nil
+}
+
+// //////////////////////////////////////////////////////////////////////////////////////////////////
+// BELOW ARE DEFINITIONS FOR ON-WIRE MESSAGES!
+// //////////////////////////////////////////////////////////////////////////////////////////////////
+type PingMsg struct {
+	Nonce uint64 `protobuf:"varint,1,opt,name=nonce" json:"nonce,omitempty"`
+}
+
+func (m *PingMsg) Reset()                    { *m = PingMsg{} }
+func (m *PingMsg) String() string            { return proto.CompactTextString(m) }
+func (*PingMsg) ProtoMessage()               {}
+func (*PingMsg) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{17} }
+
+func (m *PingMsg) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+type PongMsg struct {
+	AckNonce uint64 `protobuf:"varint,1,opt,name=ack_nonce,json=ackNonce" json:"ack_nonce,omitempty"`
+}
+
+func (m *PongMsg) Reset()                    { *m = PongMsg{} }
+func (m *PongMsg) String() string            { return proto.CompactTextString(m) }
+func (*PongMsg) ProtoMessage()               {}
+func (*PongMsg) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{18} }
+
+func (m *PongMsg) GetAckNonce() uint64 {
+	if m != nil {
+		return m.AckNonce
+	}
+	return 0
+}
+
+type BlockSync struct {
+	Start uint32 `protobuf:"varint,2,opt,name=start" json:"start,omitempty"`
+	End   uint32 `protobuf:"varint,3,opt,name=end" json:"end,omitempty"`
+}
+
+func (m *BlockSync) Reset()                    { *m = BlockSync{} }
+func (m *BlockSync) String() string            { return proto.CompactTextString(m) }
+func (*BlockSync) ProtoMessage()               {}
+func (*BlockSync) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{19} }
+
+func (m *BlockSync) GetStart() uint32 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *BlockSync) GetEnd() uint32 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+// block container
+// used to send old/existing blocks in block sync, a batch of consecutive blocks is sent in blocks
+type BlockContainer struct {
+	Block  *BlockPb   `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+	Blocks []*BlockPb `protobuf:"bytes,2,rep,name=blocks" json:"blocks,omitempty"`
+}
+
+func (m *BlockContainer) Reset()                    { *m = BlockContainer{} }
+func (m *BlockContainer) String() string            { return proto.CompactTextString(m) }
+func (*BlockContainer) ProtoMessage()               {}
+func (*BlockContainer) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{20} }
+
+func (m *BlockContainer) GetBlock() *BlockPb {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+func (m *BlockContainer) GetBlocks() []*BlockPb {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+// request for block headers in the range [start, end]
+// used by headers-first block sync
+type BlockHeaderSync struct {
+	Start uint32 `protobuf:"varint,1,opt,name=start" json:"start,omitempty"`
+	End   uint32 `protobuf:"varint,2,opt,name=end" json:"end,omitempty"`
+}
+
+func (m *BlockHeaderSync) Reset()                    { *m = BlockHeaderSync{} }
+func (m *BlockHeaderSync) String() string            { return proto.CompactTextString(m) }
+func (*BlockHeaderSync) ProtoMessage()               {}
+func (*BlockHeaderSync) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{21} }
+
+func (m *BlockHeaderSync) GetStart() uint32 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *BlockHeaderSync) GetEnd() uint32 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+// header container
+// used to send back the headers requested by BlockHeaderSync
+type BlockHeaderContainer struct {
+	Headers []*BlockHeaderPb `protobuf:"bytes,1,rep,name=headers" json:"headers,omitempty"`
+}
+
+func (m *BlockHeaderContainer) Reset()                    { *m = BlockHeaderContainer{} }
+func (m *BlockHeaderContainer) String() string            { return proto.CompactTextString(m) }
+func (*BlockHeaderContainer) ProtoMessage()               {}
+func (*BlockHeaderContainer) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{22} }
+
+func (m *BlockHeaderContainer) GetHeaders() []*BlockHeaderPb {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+// compact block
+// used to relay a new block with the short IDs of its transactions, which are rebuilt from the tx pool of the peer
+type CompactBlockPb struct {
+	Header     *BlockHeaderPb `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	ShortIDs   [][]byte       `protobuf:"bytes,2,rep,name=shortIDs,proto3" json:"shortIDs,omitempty"`
+	Prefilled  []*TxPb        `protobuf:"bytes,3,rep,name=prefilled" json:"prefilled,omitempty"`
+	Transfers  []*TransferPb  `protobuf:"bytes,4,rep,name=transfers" json:"transfers,omitempty"`
+	Executions []*ExecutionPb `protobuf:"bytes,5,rep,name=executions" json:"executions,omitempty"`
+	Votes      []*VotePb      `protobuf:"bytes,6,rep,name=votes" json:"votes,omitempty"`
+	Evidences  []*EvidencePb  `protobuf:"bytes,7,rep,name=evidences" json:"evidences,omitempty"`
+	SenderAddr string         `protobuf:"bytes,8,opt,name=senderAddr" json:"senderAddr,omitempty"`
+	Deposits   []*DepositPb   `protobuf:"bytes,9,rep,name=deposits" json:"deposits,omitempty"`
+	Withdraws  []*WithdrawPb  `protobuf:"bytes,10,rep,name=withdraws" json:"withdraws,omitempty"`
+	Actions    []*ActionPb    `protobuf:"bytes,11,rep,name=actions" json:"actions,omitempty"`
+}
+
+func (m *CompactBlockPb) Reset()                    { *m = CompactBlockPb{} }
+func (m *CompactBlockPb) String() string            { return proto.CompactTextString(m) }
+func (*CompactBlockPb) ProtoMessage()               {}
+func (*CompactBlockPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{23} }
+
+func (m *CompactBlockPb) GetHeader() *BlockHeaderPb {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *CompactBlockPb) GetShortIDs() [][]byte {
+	if m != nil {
+		return m.ShortIDs
+	}
+	return nil
+}
+
+func (m *CompactBlockPb) GetPrefilled() []*TxPb {
+	if m != nil {
+		return m.Prefilled
+	}
+	return nil
+}
+
+func (m *CompactBlockPb) GetTransfers() []*TransferPb {
+	if m != nil {
+		return m.Transfers
+	}
+	return nil
+}
+
+func (m *CompactBlockPb) GetExecutions() []*ExecutionPb {
+	if m != nil {
+		return m.Executions
+	}
+	return nil
+}
+
+func (m *CompactBlockPb) GetVotes() []*VotePb {
+	if m != nil {
+		return m.Votes
+	}
+	return nil
+}
+
+func (m *CompactBlockPb) GetEvidences() []*EvidencePb {
+	if m != nil {
+		return m.Evidences
+	}
+	return nil
+}
+
+func (m *CompactBlockPb) GetSenderAddr() string {
+	if m != nil {
+		return m.SenderAddr
+	}
+	return ""
+}
+
+func (m *CompactBlockPb) GetDeposits() []*DepositPb {
+	if m != nil {
+		return m.Deposits
+	}
+	return nil
+}
+
+func (m *CompactBlockPb) GetWithdraws() []*WithdrawPb {
+	if m != nil {
+		return m.Withdraws
+	}
+	return nil
+}
+
+func (m *CompactBlockPb) GetActions() []*ActionPb {
+	if m != nil {
+		return m.Actions
+	}
+	return nil
+}
+
+// request for the transactions of a compact block at the given indexes
+// used when the transactions are missing from the tx pool
+type BlockTxsSync struct {
+	BlockHash []byte   `protobuf:"bytes,1,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	Indexes   []uint32 `protobuf:"varint,2,rep,packed,name=indexes" json:"indexes,omitempty"`
+}
+
+func (m *BlockTxsSync) Reset()                    { *m = BlockTxsSync{} }
+func (m *BlockTxsSync) String() string            { return proto.CompactTextString(m) }
+func (*BlockTxsSync) ProtoMessage()               {}
+func (*BlockTxsSync) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{24} }
+
+func (m *BlockTxsSync) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *BlockTxsSync) GetIndexes() []uint32 {
+	if m != nil {
+		return m.Indexes
+	}
+	return nil
+}
+
+// transaction container
+// used to send back the transactions requested by BlockTxsSync
+type BlockTxsContainer struct {
+	BlockHash []byte  `protobuf:"bytes,1,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	Txs       []*TxPb `protobuf:"bytes,2,rep,name=txs" json:"txs,omitempty"`
+}
+
+func (m *BlockTxsContainer) Reset()                    { *m = BlockTxsContainer{} }
+func (m *BlockTxsContainer) String() string            { return proto.CompactTextString(m) }
+func (*BlockTxsContainer) ProtoMessage()               {}
+func (*BlockTxsContainer) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{25} }
+
+func (m *BlockTxsContainer) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *BlockTxsContainer) GetTxs() []*TxPb {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
+
+// partially signed transaction
+// used to pass an unsigned transaction around to collect the signatures of its inputs offline
+type PartialTxPb struct {
+	Tx     *TxPb               `protobuf:"bytes,1,opt,name=tx" json:"tx,omitempty"`
+	Inputs []*PartialTxInputPb `protobuf:"bytes,2,rep,name=inputs" json:"inputs,omitempty"`
+}
+
+func (m *PartialTxPb) Reset()                    { *m = PartialTxPb{} }
+func (m *PartialTxPb) String() string            { return proto.CompactTextString(m) }
+func (*PartialTxPb) ProtoMessage()               {}
+func (*PartialTxPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{26} }
+
+func (m *PartialTxPb) GetTx() *TxPb {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+func (m *PartialTxPb) GetInputs() []*PartialTxInputPb {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+// signatures collected for an input of a partially signed transaction
+// multisigKeys is only set if the input spends a multisig address
+type PartialTxInputPb struct {
+	MultisigKeys []byte                `protobuf:"bytes,1,opt,name=multisigKeys,proto3" json:"multisigKeys,omitempty"`
+	Signatures   []*PartialSignaturePb `protobuf:"bytes,2,rep,name=signatures" json:"signatures,omitempty"`
+}
+
+func (m *PartialTxInputPb) Reset()                    { *m = PartialTxInputPb{} }
+func (m *PartialTxInputPb) String() string            { return proto.CompactTextString(m) }
+func (*PartialTxInputPb) ProtoMessage()               {}
+func (*PartialTxInputPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{27} }
+
+func (m *PartialTxInputPb) GetMultisigKeys() []byte {
+	if m != nil {
+		return m.MultisigKeys
+	}
+	return nil
+}
+
+func (m *PartialTxInputPb) GetSignatures() []*PartialSignaturePb {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+type PartialSignaturePb struct {
+	PubKey    []byte `protobuf:"bytes,1,opt,name=pubKey,proto3" json:"pubKey,omitempty"`
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *PartialSignaturePb) Reset()                    { *m = PartialSignaturePb{} }
+func (m *PartialSignaturePb) String() string            { return proto.CompactTextString(m) }
+func (*PartialSignaturePb) ProtoMessage()               {}
+func (*PartialSignaturePb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{28} }
+
+func (m *PartialSignaturePb) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+func (m *PartialSignaturePb) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type ViewChangeMsg struct {
+	Vctype     ViewChangeMsg_ViewChangeType `protobuf:"varint,1,opt,name=vctype,enum=iproto.ViewChangeMsg_ViewChangeType" json:"vctype,omitempty"`
+	Block      *BlockPb                     `protobuf:"bytes,2,opt,name=block" json:"block,omitempty"`
+	BlockHash  []byte                       `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	SenderAddr string                       `protobuf:"bytes,4,opt,name=senderAddr" json:"senderAddr,omitempty"`
+}
+
+func (m *ViewChangeMsg) Reset()                    { *m = ViewChangeMsg{} }
+func (m *ViewChangeMsg) String() string            { return proto.CompactTextString(m) }
+func (*ViewChangeMsg) ProtoMessage()               {}
+func (*ViewChangeMsg) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{29} }
+
+func (m *ViewChangeMsg) GetVctype() ViewChangeMsg_ViewChangeType {
+	if m != nil {
+		return m.Vctype
+	}
+	return ViewChangeMsg_INVALID_VIEW_CHANGE_TYPE
+}
+
+func (m *ViewChangeMsg) GetBlock() *BlockPb {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+func (m *ViewChangeMsg) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *ViewChangeMsg) GetSenderAddr() string {
+	if m != nil {
+		return m.SenderAddr
+	}
+	return ""
+}
+
+// signature of a delegate on the block at the height of its chain, gossiped to finalize the block
+type CheckpointVotePb struct {
+	Height    uint32 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Hash      []byte `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	PubKey    []byte `protobuf:"bytes,3,opt,name=pubKey,proto3" json:"pubKey,omitempty"`
+	Signature []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *CheckpointVotePb) Reset()                    { *m = CheckpointVotePb{} }
+func (m *CheckpointVotePb) String() string            { return proto.CompactTextString(m) }
+func (*CheckpointVotePb) ProtoMessage()               {}
+func (*CheckpointVotePb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{30} }
+
+func (m *CheckpointVotePb) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *CheckpointVotePb) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *CheckpointVotePb) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+func (m *CheckpointVotePb) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// block metadata
+// stored apart from the block so the header info can be read without deserializing the block
+type BlockMetaPb struct {
+	Hash            []byte             `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Height          uint32             `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+	Timestamp       uint64             `protobuf:"varint,3,opt,name=timestamp" json:"timestamp,omitempty"`
+	TxCount         uint32             `protobuf:"varint,4,opt,name=txCount" json:"txCount,omitempty"`
+	ProducerPubKey  []byte             `protobuf:"bytes,5,opt,name=producerPubKey,proto3" json:"producerPubKey,omitempty"`
+	Size            uint32             `protobuf:"varint,6,opt,name=size" json:"size,omitempty"`
+	CoinbaseData    []byte             `protobuf:"bytes,7,opt,name=coinbaseData,proto3" json:"coinbaseData,omitempty"`
+	CoinbasePayload *CoinbasePayloadPb `protobuf:"bytes,8,opt,name=coinbasePayload" json:"coinbasePayload,omitempty"`
+}
+
+func (m *BlockMetaPb) Reset()                    { *m = BlockMetaPb{} }
+func (m *BlockMetaPb) String() string            { return proto.CompactTextString(m) }
+func (*BlockMetaPb) ProtoMessage()               {}
+func (*BlockMetaPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{31} }
+
+func (m *BlockMetaPb) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *BlockMetaPb) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *BlockMetaPb) GetTimestamp() uint64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *BlockMetaPb) GetTxCount() uint32 {
+	if m != nil {
+		return m.TxCount
+	}
+	return 0
+}
+
+func (m *BlockMetaPb) GetProducerPubKey() []byte {
+	if m != nil {
+		return m.ProducerPubKey
+	}
+	return nil
+}
+
+func (m *BlockMetaPb) GetSize() uint32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *BlockMetaPb) GetCoinbaseData() []byte {
+	if m != nil {
+		return m.CoinbaseData
+	}
+	return nil
+}
+
+func (m *BlockMetaPb) GetCoinbasePayload() *CoinbasePayloadPb {
+	if m != nil {
+		return m.CoinbasePayload
+	}
+	return nil
+}
+
+// structured payload of the coinbase data of a block
+type CoinbasePayloadPb struct {
+	Producer      string `protobuf:"bytes,1,opt,name=producer" json:"producer,omitempty"`
+	Epoch         uint32 `protobuf:"varint,2,opt,name=epoch" json:"epoch,omitempty"`
+	Height        uint32 `protobuf:"varint,3,opt,name=height" json:"height,omitempty"`
+	ClientVersion string `protobuf:"bytes,4,opt,name=clientVersion" json:"clientVersion,omitempty"`
+	Memo          string `protobuf:"bytes,5,opt,name=memo" json:"memo,omitempty"`
+}
+
+func (m *CoinbasePayloadPb) Reset()                    { *m = CoinbasePayloadPb{} }
+func (m *CoinbasePayloadPb) String() string            { return proto.CompactTextString(m) }
+func (*CoinbasePayloadPb) ProtoMessage()               {}
+func (*CoinbasePayloadPb) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{32} }
+
+func (m *CoinbasePayloadPb) GetProducer() string {
+	if m != nil {
+		return m.Producer
+	}
+	return ""
+}
+
+func (m *CoinbasePayloadPb) GetEpoch() uint32 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *CoinbasePayloadPb) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *CoinbasePayloadPb) GetClientVersion() string {
+	if m != nil {
+		return m.ClientVersion
+	}
+	return ""
+}
+
+func (m *CoinbasePayloadPb) GetMemo() string {
+	if m != nil {
+		return m.Memo
 	}
 	return ""
 }
@@ -474,7 +1541,7 @@ type TestPayload struct {
 func (m *TestPayload) Reset()                    { *m = TestPayload{} }
 func (m *TestPayload) String() string            { return proto.CompactTextString(m) }
 func (*TestPayload) ProtoMessage()               {}
-func (*TestPayload) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*TestPayload) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{33} }
 
 func (m *TestPayload) GetMsgBody() []byte {
 	if m != nil {
@@ -487,6 +1554,17 @@ func init() {
 	proto.RegisterType((*TxInputPb)(nil), "iproto.TxInputPb")
 	proto.RegisterType((*TxOutputPb)(nil), "iproto.TxOutputPb")
 	proto.RegisterType((*TxPb)(nil), "iproto.TxPb")
+	proto.RegisterType((*TransferPb)(nil), "iproto.TransferPb")
+	proto.RegisterType((*ExecutionPb)(nil), "iproto.ExecutionPb")
+	proto.RegisterType((*VotePb)(nil), "iproto.VotePb")
+	proto.RegisterType((*EvidencePb)(nil), "iproto.EvidencePb")
+	proto.RegisterType((*DepositPb)(nil), "iproto.DepositPb")
+	proto.RegisterType((*WithdrawPb)(nil), "iproto.WithdrawPb")
+	proto.RegisterType((*ActionPb)(nil), "iproto.ActionPb")
+	proto.RegisterType((*CandidatePb)(nil), "iproto.CandidatePb")
+	proto.RegisterType((*CandidateListPb)(nil), "iproto.CandidateListPb")
+	proto.RegisterType((*LogPb)(nil), "iproto.LogPb")
+	proto.RegisterType((*ReceiptPb)(nil), "iproto.ReceiptPb")
 	proto.RegisterType((*BlockHeaderPb)(nil), "iproto.BlockHeaderPb")
 	proto.RegisterType((*BlockPb)(nil), "iproto.BlockPb")
 	proto.RegisterType((*BlockIndex)(nil), "iproto.BlockIndex")
@@ -494,60 +1572,145 @@ func init() {
 	proto.RegisterType((*PongMsg)(nil), "iproto.PongMsg")
 	proto.RegisterType((*BlockSync)(nil), "iproto.BlockSync")
 	proto.RegisterType((*BlockContainer)(nil), "iproto.BlockContainer")
+	proto.RegisterType((*BlockHeaderSync)(nil), "iproto.BlockHeaderSync")
+	proto.RegisterType((*BlockHeaderContainer)(nil), "iproto.BlockHeaderContainer")
+	proto.RegisterType((*CompactBlockPb)(nil), "iproto.CompactBlockPb")
+	proto.RegisterType((*BlockTxsSync)(nil), "iproto.BlockTxsSync")
+	proto.RegisterType((*BlockTxsContainer)(nil), "iproto.BlockTxsContainer")
+	proto.RegisterType((*PartialTxPb)(nil), "iproto.PartialTxPb")
+	proto.RegisterType((*PartialTxInputPb)(nil), "iproto.PartialTxInputPb")
+	proto.RegisterType((*PartialSignaturePb)(nil), "iproto.PartialSignaturePb")
 	proto.RegisterType((*ViewChangeMsg)(nil), "iproto.ViewChangeMsg")
+	proto.RegisterType((*CheckpointVotePb)(nil), "iproto.CheckpointVotePb")
+	proto.RegisterType((*BlockMetaPb)(nil), "iproto.BlockMetaPb")
+	proto.RegisterType((*CoinbasePayloadPb)(nil), "iproto.CoinbasePayloadPb")
 	proto.RegisterType((*TestPayload)(nil), "iproto.TestPayload")
 	proto.RegisterEnum("iproto.ViewChangeMsg_ViewChangeType", ViewChangeMsg_ViewChangeType_name, ViewChangeMsg_ViewChangeType_value)
 }
 
-func init() { proto.RegisterFile("blockchain.proto", fileDescriptor0) }
-
-var fileDescriptor0 = []byte{
-	// 737 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0x5d, 0x6f, 0xe2, 0x46,
-	0x14, 0x2d, 0x06, 0xf3, 0x71, 0xf9, 0x28, 0x1d, 0xa5, 0x95, 0xdb, 0x46, 0x2d, 0xb2, 0x92, 0x08,
-	0x55, 0x6a, 0x54, 0x25, 0x0f, 0x7d, 0xe9, 0x4b, 0x3e, 0x50, 0x83, 0x94, 0x82, 0x35, 0x58, 0x54,
-	0xfb, 0x84, 0xc6, 0xf6, 0x04, 0x1c, 0xf0, 0x98, 0xb5, 0xc7, 0x2c, 0xec, 0xeb, 0x4a, 0xfb, 0x67,
-	0xf6, 0x67, 0xec, 0x1f, 0x5b, 0xcd, 0xb5, 0x01, 0x3b, 0xbb, 0xda, 0x7d, 0x82, 0x73, 0xe6, 0xce,
-	0xbd, 0x67, 0xce, 0x9c, 0x31, 0x74, 0x9d, 0x55, 0xe8, 0x2e, 0xdd, 0x05, 0xf3, 0xc5, 0xe5, 0x3a,
-	0x0a, 0x65, 0x48, 0xaa, 0x3e, 0xfe, 0x9a, 0x1f, 0x4a, 0xd0, 0xb0, 0xb7, 0x43, 0xb1, 0x4e, 0xa4,
-	0xe5, 0x90, 0x9f, 0xa0, 0x2a, 0xb7, 0x0f, 0x2c, 0x5e, 0x18, 0xa5, 0x5e, 0xa9, 0xdf, 0xa2, 0x19,
-	0x22, 0xbf, 0x40, 0x3d, 0x4c, 0xe4, 0x50, 0x78, 0x7c, 0x6b, 0x68, 0xbd, 0x52, 0x5f, 0xa7, 0x07,
-	0x4c, 0xfe, 0x80, 0x6e, 0x22, 0x54, 0xfb, 0x89, 0x1b, 0xf9, 0x6b, 0x39, 0xf1, 0xdf, 0x72, 0xa3,
-	0xdc, 0x2b, 0xf5, 0xdb, 0xf4, 0x33, 0x9e, 0x98, 0xd0, 0xca, 0x73, 0x46, 0x05, 0xa7, 0x14, 0x38,
-	0x35, 0x2b, 0xe6, 0xaf, 0x13, 0x2e, 0x5c, 0x6e, 0xe8, 0xd8, 0xe7, 0x80, 0xcd, 0x67, 0x00, 0x7b,
-	0x3b, 0x4e, 0x64, 0xaa, 0xf6, 0x04, 0xf4, 0x0d, 0x5b, 0x25, 0x1c, 0xc5, 0x56, 0x68, 0x0a, 0xc8,
-	0x05, 0x74, 0x5e, 0xa8, 0xd1, 0xb0, 0xcb, 0x0b, 0x96, 0xfc, 0x06, 0x90, 0x53, 0x52, 0x46, 0x25,
-	0x39, 0xc6, 0xfc, 0x58, 0x82, 0x8a, 0xbd, 0xb5, 0x1c, 0x62, 0x40, 0x6d, 0xc3, 0xa3, 0xd8, 0x0f,
-	0x05, 0x0e, 0x6a, 0xd3, 0x3d, 0x54, 0x2b, 0x22, 0x09, 0x94, 0x7d, 0xd9, 0x8c, 0x3d, 0x24, 0xe7,
-	0x50, 0x91, 0x8a, 0x2e, 0xf7, 0xca, 0xfd, 0xe6, 0xd5, 0x0f, 0x97, 0xa9, 0xdb, 0x97, 0x07, 0xa7,
-	0x29, 0x2e, 0xab, 0xb3, 0xe2, 0x8e, 0x71, 0x92, 0x7a, 0xd1, 0xa6, 0x07, 0x4c, 0xfa, 0xa0, 0x4b,
-	0x5c, 0xd0, 0xb1, 0x07, 0x39, 0xf6, 0xd8, 0x1b, 0x40, 0xd3, 0x02, 0xd5, 0x45, 0xe9, 0xb6, 0xfd,
-	0x80, 0x1b, 0xd5, 0xb4, 0xcb, 0x1e, 0x9b, 0xef, 0x34, 0x68, 0xdf, 0x2a, 0xf4, 0xc0, 0x99, 0xc7,
-	0xa3, 0x6f, 0x1d, 0x07, 0x23, 0x32, 0xbc, 0xdf, 0x1f, 0x27, 0x83, 0x2a, 0x17, 0x0b, 0xee, 0xcf,
-	0x17, 0x32, 0xbb, 0xd9, 0x0c, 0x91, 0x53, 0x68, 0x48, 0x3f, 0xe0, 0xb1, 0x64, 0xc1, 0x1a, 0x0f,
-	0x50, 0xa1, 0x47, 0x82, 0x9c, 0x41, 0x7b, 0x1d, 0xf1, 0x4d, 0x3a, 0x5e, 0x85, 0x4a, 0x47, 0x93,
-	0x8b, 0xa4, 0xba, 0x87, 0x80, 0x47, 0xcb, 0x15, 0xa7, 0x61, 0x28, 0x51, 0x7f, 0x8b, 0xe6, 0x18,
-	0xb5, 0x2e, 0x23, 0xb1, 0x1d, 0x25, 0x81, 0xc3, 0x23, 0xa3, 0x86, 0xf3, 0x73, 0x8c, 0xca, 0x94,
-	0x42, 0xf7, 0x4c, 0x32, 0xbc, 0xed, 0x3a, 0x56, 0x14, 0x38, 0xf3, 0x19, 0x6a, 0x38, 0xd0, 0x72,
-	0xc8, 0x9f, 0x50, 0x4d, 0xad, 0xc0, 0xd3, 0x37, 0xaf, 0x7e, 0xdc, 0xfb, 0x5a, 0x70, 0x89, 0x66,
-	0x45, 0xe4, 0x2f, 0x68, 0xd9, 0x11, 0x13, 0x31, 0x73, 0xa5, 0x1f, 0x8a, 0xd8, 0xd0, 0xf0, 0x32,
-	0x5a, 0xc7, 0xcb, 0xb0, 0x1c, 0x5a, 0xa8, 0x30, 0x1f, 0x01, 0xb0, 0x55, 0xfa, 0x3a, 0x4e, 0x40,
-	0x8f, 0x25, 0x8b, 0x64, 0xe6, 0x75, 0x0a, 0x48, 0x17, 0xca, 0x5c, 0x78, 0x99, 0xcb, 0xea, 0xaf,
-	0x72, 0x38, 0x7c, 0x7a, 0x8a, 0xb9, 0xc4, 0xc8, 0xb4, 0x69, 0x86, 0xcc, 0xdf, 0xa1, 0x66, 0xf9,
-	0x62, 0xfe, 0x5f, 0x3c, 0x57, 0xad, 0x44, 0xa8, 0x5e, 0x45, 0x16, 0x77, 0x04, 0xe6, 0x05, 0xd4,
-	0xac, 0x30, 0x2d, 0xf8, 0x15, 0x1a, 0xcc, 0x5d, 0xce, 0xf2, 0x45, 0x75, 0xe6, 0x2e, 0x47, 0x58,
-	0x77, 0x0d, 0x0d, 0x94, 0x35, 0xd9, 0x09, 0xf7, 0xa8, 0x4a, 0xfb, 0x82, 0xaa, 0xf2, 0x41, 0x95,
-	0xf9, 0x37, 0x74, 0x70, 0xd3, 0x5d, 0x28, 0x24, 0xf3, 0x05, 0x8f, 0xc8, 0x39, 0xe8, 0xf8, 0x2d,
-	0xc9, 0xdc, 0xfb, 0xbe, 0xe0, 0x9e, 0x8a, 0x24, 0xae, 0x9a, 0xef, 0x35, 0x68, 0x4f, 0x7d, 0xfe,
-	0xe6, 0x6e, 0xc1, 0xc4, 0x9c, 0x2b, 0x71, 0xff, 0x40, 0x75, 0xe3, 0xca, 0xdd, 0x3a, 0x55, 0xd6,
-	0xb9, 0x3a, 0xdb, 0xef, 0x2c, 0x94, 0xe5, 0x90, 0xbd, 0x5b, 0x73, 0x9a, 0xed, 0x39, 0x8e, 0xd5,
-	0xbe, 0x36, 0x56, 0xe5, 0xd1, 0x39, 0xa4, 0x2d, 0x7d, 0xd2, 0x47, 0x42, 0x25, 0x29, 0xe6, 0xc2,
-	0xe3, 0xd1, 0x8d, 0xe7, 0x45, 0x18, 0xd7, 0x06, 0xcd, 0x31, 0x26, 0x85, 0x4e, 0x71, 0x3c, 0x39,
-	0x05, 0x63, 0x38, 0x9a, 0xde, 0x3c, 0x0e, 0xef, 0x67, 0xd3, 0xe1, 0xe0, 0xff, 0xd9, 0xdd, 0xc3,
-	0xcd, 0xe8, 0xdf, 0xc1, 0xcc, 0x7e, 0x65, 0x0d, 0xba, 0xdf, 0x91, 0x26, 0xd4, 0x2c, 0x3a, 0xb6,
-	0xc6, 0x93, 0x41, 0xb7, 0x94, 0x82, 0xc1, 0x74, 0x6c, 0x0f, 0xba, 0x1a, 0xa9, 0x43, 0x05, 0xff,
-	0x95, 0xcd, 0x3e, 0x34, 0x6d, 0x1e, 0x4b, 0x8b, 0xed, 0x56, 0x21, 0xf3, 0xc8, 0xcf, 0x50, 0x0f,
-	0xe2, 0xf9, 0xcc, 0x09, 0xbd, 0x5d, 0xf6, 0x89, 0xad, 0x05, 0xf1, 0xfc, 0x36, 0xf4, 0x76, 0x4e,
-	0x15, 0x4f, 0x74, 0xfd, 0x29, 0x00, 0x00, 0xff, 0xff, 0x76, 0x07, 0x9e, 0xb6, 0xac, 0x05, 0x00,
-	0x00,
+func init() { proto.RegisterFile("blockchain.proto", fileDescriptor1) }
+
+var fileDescriptor1 = []byte{
+	// 1929 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbd, 0x58, 0x4b, 0x73, 0x1b, 0x45,
+	0x10, 0x46, 0x6f, 0xa9, 0xe5, 0x87, 0xb2, 0x04, 0x4a, 0x09, 0xa9, 0x10, 0xb6, 0x92, 0x90, 0x82,
+	0x22, 0x80, 0x73, 0x01, 0x0a, 0x0e, 0x8e, 0x6d, 0x62, 0x83, 0x93, 0x98, 0xb5, 0x70, 0x8a, 0x03,
+	0x65, 0x56, 0xab, 0x89, 0xb4, 0x58, 0xda, 0x15, 0xbb, 0x23, 0x5b, 0xe6, 0xc6, 0x85, 0x0b, 0x77,
+	0x28, 0xee, 0xdc, 0xb8, 0x52, 0xfc, 0x0a, 0xae, 0xfc, 0x01, 0xfe, 0x02, 0x3f, 0x00, 0xba, 0x7b,
+	0x66, 0xf6, 0x21, 0x59, 0x0a, 0x95, 0x03, 0x27, 0x6d, 0xf7, 0xf4, 0xcc, 0xf4, 0xeb, 0xeb, 0xee,
+	0x11, 0xb4, 0xba, 0xc3, 0xd0, 0x3b, 0xf1, 0x06, 0xae, 0x1f, 0xdc, 0x1d, 0x47, 0xa1, 0x0c, 0xad,
+	0xaa, 0xcf, 0xbf, 0xf6, 0xaf, 0x05, 0x68, 0x74, 0xa6, 0x7b, 0xc1, 0x78, 0x22, 0x0f, 0xba, 0xd6,
+	0xcb, 0x50, 0x95, 0xd3, 0x5d, 0x37, 0x1e, 0xb4, 0x0b, 0x37, 0x0a, 0x77, 0x56, 0x1c, 0x4d, 0x59,
+	0x57, 0xa1, 0x1e, 0x4e, 0xe4, 0x5e, 0xd0, 0x13, 0xd3, 0x76, 0x11, 0x57, 0x2a, 0x4e, 0x42, 0x5b,
+	0x6f, 0x40, 0x6b, 0x12, 0xd0, 0xf1, 0x87, 0x5e, 0xe4, 0x8f, 0xe5, 0xa1, 0xff, 0xad, 0x68, 0x97,
+	0x50, 0x66, 0xd5, 0x99, 0xe3, 0x5b, 0x36, 0xac, 0x64, 0x79, 0xed, 0x32, 0xdf, 0x92, 0xe3, 0xd1,
+	0x5d, 0xb1, 0xf8, 0x66, 0x22, 0x02, 0x4f, 0xb4, 0x2b, 0x7c, 0x4e, 0x42, 0xdb, 0x5f, 0x03, 0x74,
+	0xa6, 0x8f, 0x27, 0x52, 0x69, 0x7b, 0x19, 0x2a, 0xa7, 0xee, 0x70, 0x22, 0x58, 0xd9, 0xb2, 0xa3,
+	0x08, 0xeb, 0x36, 0xac, 0xcd, 0x68, 0x53, 0xe4, 0x53, 0x66, 0xb8, 0xd6, 0x75, 0x80, 0x8c, 0x26,
+	0x25, 0xd6, 0x24, 0xc3, 0xb1, 0xbf, 0x2b, 0x42, 0xb9, 0x33, 0xc5, 0x6b, 0xda, 0x50, 0x3b, 0x15,
+	0x51, 0xec, 0x87, 0x01, 0x5f, 0xb4, 0xea, 0x18, 0x92, 0x56, 0x82, 0xc9, 0x88, 0xdc, 0xa7, 0xef,
+	0x30, 0xa4, 0x75, 0x0b, 0xca, 0x92, 0xd8, 0xa5, 0x1b, 0xa5, 0x3b, 0xcd, 0x8d, 0x4b, 0x77, 0x95,
+	0xb7, 0xef, 0x26, 0x9e, 0x76, 0x78, 0x99, 0x6c, 0xe5, 0x1d, 0x68, 0x12, 0xfb, 0x02, 0x6d, 0x35,
+	0xb4, 0x75, 0x07, 0x2a, 0x92, 0x17, 0x2a, 0x7c, 0x86, 0x95, 0x9e, 0x61, 0x1c, 0xe0, 0x28, 0x01,
+	0x3a, 0x85, 0xf4, 0xee, 0xf8, 0x23, 0xd1, 0xae, 0xaa, 0x53, 0x0c, 0x4d, 0x1e, 0x17, 0xd3, 0xb1,
+	0x1f, 0x9d, 0xef, 0x0a, 0xbf, 0x3f, 0x90, 0xed, 0x1a, 0xaf, 0xe7, 0x78, 0x64, 0x06, 0xa7, 0xc6,
+	0xde, 0x76, 0xbb, 0xae, 0xcc, 0xd0, 0xa4, 0xfd, 0x47, 0x01, 0x1d, 0x1e, 0xb9, 0x41, 0xfc, 0x54,
+	0x44, 0x4b, 0x3d, 0x81, 0xa1, 0x08, 0x42, 0x8a, 0x58, 0x51, 0x85, 0x82, 0x09, 0x4a, 0x27, 0x77,
+	0x14, 0x4e, 0x02, 0xe5, 0xde, 0xb2, 0xa3, 0x29, 0xe2, 0xc7, 0x02, 0x93, 0x27, 0x62, 0xa3, 0x1b,
+	0x8e, 0xa6, 0xac, 0x6b, 0xd0, 0x88, 0x84, 0xe7, 0x8f, 0x7d, 0x11, 0x48, 0x8e, 0x7d, 0xc3, 0x49,
+	0x19, 0x64, 0x8a, 0x92, 0x3b, 0x98, 0x74, 0x3f, 0x15, 0xe7, 0x6c, 0x2a, 0x26, 0x4f, 0x96, 0x47,
+	0x27, 0xc4, 0x7e, 0x3f, 0x70, 0xe5, 0x24, 0x12, 0x6c, 0xeb, 0x8a, 0x93, 0x32, 0xec, 0x7f, 0x0a,
+	0xd0, 0xdc, 0x99, 0x0a, 0x6f, 0x22, 0x51, 0xe7, 0xe7, 0xb0, 0x07, 0x1d, 0x2d, 0x78, 0x7b, 0x18,
+	0xb1, 0x45, 0x0d, 0x27, 0xa1, 0x69, 0xcd, 0x0b, 0x03, 0x19, 0xb9, 0x9e, 0xd4, 0x56, 0x25, 0xb4,
+	0x65, 0x41, 0xd9, 0x0b, 0x7b, 0x2a, 0x9d, 0x57, 0x1c, 0xfe, 0x26, 0x9e, 0x1b, 0xf5, 0x63, 0xb4,
+	0xa2, 0x44, 0x3c, 0xfa, 0xa6, 0x33, 0xfa, 0x6e, 0xbc, 0xef, 0x8f, 0x7c, 0x15, 0xa8, 0xb2, 0x93,
+	0xd0, 0x94, 0xd6, 0xe6, 0x2e, 0x6d, 0x7f, 0x9d, 0x4f, 0x9b, 0xe1, 0xe6, 0x3d, 0xd0, 0x98, 0xf5,
+	0xc0, 0x2f, 0x05, 0xa8, 0x1e, 0x85, 0x52, 0x3c, 0x87, 0xf1, 0x84, 0x36, 0xdc, 0x69, 0x2c, 0x57,
+	0x84, 0xe1, 0x0a, 0x6d, 0xb3, 0x22, 0xac, 0x1b, 0xd0, 0xe4, 0x65, 0xad, 0xa9, 0xb2, 0x3b, 0xcb,
+	0xca, 0xab, 0x59, 0xbd, 0x40, 0x4d, 0xd8, 0x39, 0xf5, 0x7b, 0x04, 0xfa, 0xa5, 0xaa, 0x52, 0x61,
+	0x7a, 0xfa, 0x54, 0xe5, 0x52, 0x51, 0x79, 0xdd, 0xd0, 0xd6, 0xdb, 0x50, 0x1b, 0x08, 0x17, 0xbf,
+	0xde, 0x65, 0x95, 0x9b, 0x1b, 0x2f, 0x19, 0x08, 0xdd, 0x27, 0x78, 0xec, 0xf2, 0x1a, 0xa2, 0xc8,
+	0x48, 0xa5, 0x1b, 0x36, 0xd8, 0x9a, 0x67, 0x6d, 0xd8, 0xb0, 0x7f, 0x28, 0x42, 0x63, 0x5b, 0x8c,
+	0xc3, 0xd8, 0x97, 0xff, 0x03, 0x3a, 0xb0, 0x60, 0xc5, 0x91, 0xb7, 0xa5, 0x91, 0xaa, 0x4a, 0x63,
+	0x86, 0x43, 0xeb, 0xbd, 0x58, 0x9a, 0x75, 0x55, 0x08, 0x32, 0x9c, 0x3c, 0xba, 0x6a, 0xcf, 0x42,
+	0x57, 0xfd, 0x59, 0xe8, 0x9a, 0xcb, 0xad, 0xdf, 0x30, 0x68, 0x4f, 0x7c, 0x39, 0xe8, 0x45, 0xee,
+	0xd9, 0x52, 0x77, 0xbc, 0x09, 0xb5, 0x9e, 0xf2, 0x1a, 0x3b, 0x24, 0x53, 0x1f, 0x13, 0x67, 0x3a,
+	0x46, 0xc2, 0x7a, 0x0b, 0xaa, 0xca, 0xdd, 0xcb, 0x83, 0xa8, 0x85, 0xc8, 0xd5, 0x3e, 0xb7, 0x29,
+	0x55, 0x4e, 0x15, 0xc1, 0x3d, 0xc5, 0xef, 0x0e, 0xfd, 0x00, 0x01, 0x57, 0x61, 0xc0, 0x25, 0xb4,
+	0xfd, 0x1e, 0xd4, 0x37, 0x3d, 0x5d, 0x10, 0x10, 0x94, 0x27, 0xb8, 0x83, 0x15, 0x6e, 0x38, 0xfc,
+	0x4d, 0x76, 0x8c, 0xdd, 0xf3, 0x61, 0xe8, 0xf6, 0x58, 0xdb, 0x15, 0xc7, 0x90, 0xf6, 0x47, 0xd0,
+	0xdc, 0x72, 0x83, 0x9e, 0xdf, 0x73, 0x0d, 0xa0, 0xdc, 0x5e, 0x2f, 0x12, 0x71, 0xac, 0xf7, 0x1b,
+	0xd2, 0x80, 0x24, 0x36, 0xf1, 0x67, 0xc2, 0xfe, 0x18, 0xd6, 0x93, 0xed, 0xfb, 0x7e, 0x4c, 0x29,
+	0x74, 0x0f, 0xc0, 0x33, 0x2c, 0x3a, 0x85, 0x0a, 0xff, 0x8b, 0xc6, 0xe0, 0xcc, 0x5d, 0x4e, 0x46,
+	0xcc, 0xfe, 0xb9, 0x00, 0x95, 0xfd, 0xb0, 0xbf, 0x54, 0x03, 0x6a, 0xec, 0xe1, 0xd8, 0xf7, 0x48,
+	0x85, 0x12, 0x37, 0x76, 0xa6, 0xc8, 0x60, 0x3c, 0xc4, 0xd5, 0xed, 0x8f, 0xbf, 0x89, 0x37, 0xa0,
+	0x11, 0x40, 0x35, 0x67, 0xfe, 0x26, 0x40, 0x77, 0x95, 0xbf, 0xb9, 0x8b, 0xa8, 0xe4, 0xcb, 0xb2,
+	0x52, 0xc7, 0x57, 0x33, 0x8e, 0xb7, 0xff, 0xc2, 0xf1, 0xc2, 0x11, 0x9e, 0xc0, 0x86, 0xaa, 0xdc,
+	0x3b, 0x48, 0x87, 0x0b, 0x75, 0x32, 0x65, 0xbb, 0xc4, 0x04, 0x8a, 0x75, 0x0b, 0xd5, 0x14, 0xd9,
+	0x82, 0xb5, 0xef, 0xf3, 0x58, 0xf4, 0x34, 0x3c, 0x0c, 0x89, 0x8d, 0x71, 0xdd, 0x54, 0xd6, 0x4d,
+	0x6d, 0xad, 0x02, 0xca, 0x2c, 0x9b, 0xb4, 0x8e, 0x04, 0xe6, 0x66, 0x70, 0xc4, 0x63, 0x82, 0x2e,
+	0x43, 0x19, 0xd6, 0xac, 0x5d, 0xd5, 0x79, 0xbb, 0x5e, 0x83, 0xf2, 0x30, 0xc4, 0xb4, 0xa9, 0x71,
+	0x30, 0x56, 0x4d, 0x30, 0xd8, 0xe1, 0x0e, 0x2f, 0xd9, 0x7f, 0x16, 0x61, 0x35, 0x97, 0x8d, 0xcb,
+	0x47, 0x06, 0xd3, 0x6b, 0x8b, 0xb9, 0x5e, 0x4b, 0x8e, 0x18, 0x28, 0x2d, 0xd4, 0xf4, 0xa4, 0x29,
+	0x02, 0x9d, 0xc4, 0x4e, 0x8e, 0x6e, 0x19, 0x8d, 0xd9, 0xd0, 0xb2, 0x93, 0x32, 0xac, 0x9b, 0xb0,
+	0x3a, 0x8e, 0xc4, 0xa9, 0xba, 0x9e, 0x7c, 0xab, 0x8c, 0xcc, 0x33, 0xa9, 0x34, 0x8c, 0x44, 0x74,
+	0x32, 0x14, 0x4e, 0x18, 0x4a, 0x5d, 0x6e, 0x33, 0x1c, 0x5a, 0x97, 0x51, 0x30, 0x7d, 0x34, 0x19,
+	0x75, 0x11, 0x68, 0x6a, 0x46, 0xc8, 0x70, 0xa8, 0x38, 0x10, 0xb5, 0x8d, 0xe9, 0xc1, 0x13, 0x95,
+	0x1a, 0x13, 0x72, 0x3c, 0xd2, 0x7f, 0x3c, 0xe9, 0x9e, 0x60, 0xe9, 0x50, 0x95, 0x41, 0x53, 0x84,
+	0x3d, 0xf6, 0xe7, 0xa1, 0xdf, 0x6f, 0x03, 0xaf, 0x24, 0x34, 0x17, 0x14, 0x0c, 0xb7, 0x52, 0xab,
+	0xa9, 0x0b, 0x8a, 0x61, 0xd8, 0xbf, 0x97, 0xa0, 0xc6, 0x36, 0xa0, 0x47, 0xb1, 0x0c, 0x28, 0xef,
+	0xb2, 0x43, 0x17, 0x97, 0x01, 0xf5, 0x65, 0xbd, 0x03, 0x2b, 0x3c, 0xb7, 0xb8, 0x8c, 0x6c, 0x95,
+	0xf5, 0xcd, 0x8d, 0x95, 0x74, 0x86, 0x42, 0xd9, 0x9c, 0x04, 0xee, 0x68, 0x98, 0x49, 0x27, 0xd6,
+	0x63, 0x5b, 0x3a, 0x72, 0x25, 0x23, 0x90, 0x93, 0x0a, 0x11, 0x58, 0x93, 0x61, 0x82, 0x52, 0x30,
+	0x07, 0xd6, 0xcc, 0x98, 0xe1, 0x64, 0xc4, 0x30, 0x5e, 0x95, 0x23, 0x2e, 0x05, 0x6a, 0xaa, 0x5b,
+	0x33, 0xf2, 0xaa, 0x29, 0x3b, 0x6a, 0x91, 0x94, 0x31, 0xed, 0x4f, 0x4d, 0x08, 0x19, 0x65, 0xd2,
+	0xbe, 0xe8, 0xa4, 0x42, 0xe8, 0x9f, 0xba, 0x2e, 0x9e, 0x26, 0x55, 0x2f, 0x28, 0xaa, 0x89, 0x08,
+	0x5d, 0x60, 0x4a, 0x75, 0x8c, 0xd1, 0xcc, 0x5d, 0x90, 0xd6, 0x70, 0x27, 0x15, 0xc2, 0x31, 0xbf,
+	0xb6, 0xa9, 0x9d, 0xd9, 0x60, 0xf9, 0x96, 0x91, 0x37, 0xd5, 0xd3, 0x31, 0x02, 0xf6, 0x3e, 0x00,
+	0x87, 0x45, 0x3d, 0x10, 0xb0, 0x32, 0x60, 0x4c, 0x23, 0xa9, 0xa1, 0xa0, 0x08, 0xab, 0x05, 0x25,
+	0x6c, 0x2d, 0x1a, 0x04, 0xf4, 0x49, 0x09, 0x84, 0xbd, 0x3b, 0x16, 0x92, 0xdd, 0x8f, 0x00, 0x50,
+	0x94, 0xfd, 0x2a, 0xd4, 0x0e, 0xb0, 0x52, 0x3f, 0x8c, 0xfb, 0x69, 0x23, 0x2d, 0x64, 0x1a, 0xa9,
+	0x7d, 0x1b, 0x05, 0x42, 0x25, 0xf0, 0x0a, 0x34, 0x5c, 0xef, 0xe4, 0x38, 0x2b, 0x54, 0x47, 0xc6,
+	0x23, 0x96, 0xbb, 0x07, 0x0d, 0x56, 0xeb, 0xf0, 0x3c, 0xf0, 0x52, 0xad, 0x8a, 0x17, 0x68, 0x55,
+	0x4a, 0xb4, 0xb2, 0xbf, 0x82, 0x35, 0xde, 0xb4, 0x85, 0xb5, 0x05, 0x81, 0x8a, 0xb9, 0x75, 0x0b,
+	0x2a, 0x9c, 0xc0, 0x3a, 0x13, 0xd7, 0x73, 0x99, 0x48, 0x31, 0xe4, 0x55, 0xeb, 0x75, 0xa8, 0xf2,
+	0x87, 0x49, 0xbe, 0x39, 0x39, 0xbd, 0x6c, 0xbf, 0x0f, 0xeb, 0x99, 0x24, 0xce, 0x2b, 0xb7, 0xdc,
+	0x65, 0xf6, 0x03, 0xb8, 0x9c, 0xd9, 0x9a, 0xaa, 0x98, 0x4c, 0x32, 0xa6, 0x89, 0x2c, 0x9f, 0x64,
+	0x62, 0xfb, 0xef, 0x12, 0xac, 0x6d, 0x85, 0xa3, 0x31, 0xa2, 0x21, 0x83, 0xb8, 0xc1, 0x7f, 0x41,
+	0x9c, 0x6e, 0xbc, 0xd4, 0x62, 0x07, 0x61, 0x24, 0xf7, 0xb6, 0x4d, 0x8f, 0x49, 0x68, 0xcc, 0x9d,
+	0x06, 0xd6, 0xa3, 0xa7, 0xfe, 0x70, 0xc8, 0xd5, 0x7c, 0x1e, 0x8a, 0xe9, 0x32, 0x65, 0xa6, 0x4c,
+	0x70, 0x58, 0x5e, 0x8c, 0x43, 0x99, 0xc5, 0xa1, 0x48, 0x71, 0x58, 0x59, 0x82, 0x43, 0x91, 0xc3,
+	0xa1, 0x6a, 0xc9, 0xd5, 0x8b, 0x71, 0x78, 0x6a, 0x70, 0x28, 0x12, 0x1c, 0xd6, 0x16, 0xe3, 0x30,
+	0x11, 0xe2, 0x21, 0x8d, 0x47, 0x26, 0xea, 0x41, 0x5c, 0x27, 0x1b, 0x4e, 0x86, 0x43, 0x38, 0xed,
+	0x19, 0x9c, 0x36, 0x16, 0xe2, 0xb4, 0x97, 0xc1, 0xe9, 0x59, 0x82, 0x53, 0x58, 0x8c, 0xd3, 0xb3,
+	0x2c, 0x4e, 0x4d, 0xd1, 0x6b, 0x2e, 0xc2, 0xa9, 0x16, 0xc0, 0x09, 0x64, 0x85, 0x83, 0xd9, 0x99,
+	0xc6, 0x9c, 0x76, 0x58, 0x8e, 0xbb, 0x49, 0x23, 0x51, 0x4d, 0x3a, 0x65, 0x50, 0xeb, 0xe2, 0xa6,
+	0x2e, 0x54, 0x80, 0xb1, 0x75, 0x69, 0xd2, 0xfe, 0x0c, 0x2e, 0x99, 0x73, 0xd2, 0x1c, 0x5c, 0x7e,
+	0xd8, 0x75, 0x28, 0xc9, 0xe9, 0xc5, 0x75, 0x99, 0x16, 0xec, 0x2f, 0xa1, 0x79, 0x80, 0x39, 0xef,
+	0xbb, 0x43, 0x7e, 0x83, 0x5f, 0x83, 0xa2, 0x9c, 0xea, 0x44, 0xcc, 0x4b, 0x23, 0x1f, 0xbd, 0x54,
+	0xf5, 0xe9, 0x5d, 0x6d, 0xce, 0x6b, 0x1b, 0x89, 0xe4, 0x08, 0xf3, 0xec, 0xd6, 0x72, 0x76, 0x04,
+	0xad, 0xd9, 0x35, 0x6a, 0x72, 0xa3, 0xc9, 0x50, 0xfa, 0x38, 0xd1, 0xe2, 0xb0, 0x1b, 0x6b, 0x9d,
+	0x73, 0x3c, 0xeb, 0x03, 0x0c, 0xaf, 0x19, 0x78, 0xcd, 0x6d, 0x57, 0x67, 0x6e, 0x3b, 0x34, 0x02,
+	0x94, 0x72, 0xa9, 0xb4, 0xfd, 0x09, 0x58, 0xf3, 0x12, 0xba, 0x6d, 0xd2, 0xc4, 0x5d, 0x48, 0xda,
+	0xe6, 0xdc, 0xac, 0x5d, 0x9c, 0x9d, 0xb5, 0xbf, 0xc7, 0x91, 0xe3, 0xc8, 0x17, 0x67, 0x38, 0xdb,
+	0x07, 0x7d, 0x41, 0x95, 0xef, 0x43, 0xa8, 0x9e, 0x7a, 0xf2, 0x7c, 0xac, 0xca, 0xde, 0xda, 0xc6,
+	0xcd, 0x24, 0xa3, 0xb3, 0x62, 0x19, 0xaa, 0x83, 0xb2, 0x8e, 0xde, 0x93, 0xd6, 0xb4, 0xe2, 0xd2,
+	0x9a, 0x96, 0x8b, 0x69, 0x69, 0x3e, 0xa6, 0xd9, 0xdc, 0x2f, 0xcf, 0xe6, 0xbe, 0xed, 0xc0, 0x5a,
+	0xfe, 0x7a, 0x3c, 0xaf, 0xbd, 0xf7, 0xe8, 0x68, 0x73, 0x7f, 0x6f, 0xfb, 0xf8, 0x68, 0x6f, 0xe7,
+	0xc9, 0xf1, 0xd6, 0xee, 0xe6, 0xa3, 0x07, 0x3b, 0xc7, 0x9d, 0x2f, 0x0e, 0x76, 0x5a, 0x2f, 0x58,
+	0x4d, 0xac, 0xeb, 0xce, 0xe3, 0x83, 0xc7, 0x87, 0x3b, 0xad, 0x82, 0x22, 0x76, 0x8e, 0x1e, 0x77,
+	0x76, 0x5a, 0x45, 0xab, 0x0e, 0x65, 0xfe, 0x2a, 0xd9, 0x12, 0x5a, 0x5b, 0x03, 0xe1, 0x9d, 0x8c,
+	0x43, 0x3f, 0x90, 0xfa, 0x65, 0x9b, 0x4e, 0x52, 0x85, 0xdc, 0x24, 0x65, 0xc6, 0xcf, 0x62, 0x7e,
+	0xfc, 0xd4, 0xee, 0x2f, 0x2d, 0x76, 0x7f, 0x79, 0xd6, 0xfd, 0x3f, 0x16, 0xa1, 0xc9, 0xae, 0x79,
+	0x28, 0xa4, 0xbb, 0x78, 0xb0, 0xd5, 0x5a, 0x14, 0x17, 0xcf, 0x73, 0xa5, 0xd9, 0x79, 0x0e, 0x41,
+	0x26, 0xa7, 0x5b, 0xfc, 0x2a, 0x54, 0x2f, 0x18, 0x43, 0xd2, 0x1f, 0x00, 0x18, 0x93, 0xde, 0xc4,
+	0x9b, 0x79, 0x56, 0xcf, 0x70, 0x49, 0x97, 0x98, 0x66, 0x34, 0x35, 0xcb, 0xf2, 0x37, 0xa5, 0xb6,
+	0x87, 0x0e, 0xea, 0xba, 0xb1, 0xa0, 0x79, 0x4d, 0xff, 0x33, 0x92, 0xe3, 0x59, 0x5b, 0x34, 0x56,
+	0x2b, 0xfa, 0x40, 0xbf, 0x77, 0xea, 0x9c, 0x0c, 0x57, 0x92, 0x07, 0x48, 0x7e, 0x19, 0xd3, 0x62,
+	0x76, 0x87, 0xfd, 0x53, 0x01, 0x2e, 0xcd, 0x89, 0x51, 0x6f, 0x30, 0x4a, 0xea, 0x87, 0x49, 0x42,
+	0x53, 0xab, 0xc3, 0x62, 0xe7, 0x0d, 0x4c, 0x1f, 0x66, 0x62, 0xe1, 0x30, 0x8c, 0xe3, 0xae, 0x37,
+	0xa4, 0xf7, 0xea, 0x91, 0x1e, 0xaf, 0x55, 0x96, 0xe5, 0x99, 0xe4, 0x82, 0x91, 0x18, 0x85, 0xfa,
+	0x2f, 0x24, 0xfe, 0xb6, 0xef, 0x40, 0xb3, 0x83, 0x3e, 0xd6, 0x4a, 0x59, 0x57, 0xa0, 0x3e, 0x8a,
+	0xfb, 0xc7, 0xdd, 0xb0, 0x67, 0x80, 0x57, 0x43, 0xfa, 0x3e, 0x92, 0xdd, 0x2a, 0x5b, 0x7b, 0xef,
+	0x5f, 0x3f, 0x52, 0x78, 0x3d, 0x35, 0x15, 0x00, 0x00,
 }
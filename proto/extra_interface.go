@@ -6,6 +6,7 @@ package iproto
 
 import (
 	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
 	"github.com/iotexproject/iotex-core/txvm"
 )
 
@@ -34,11 +35,26 @@ func (in *TxInputPb) ByteStream() []byte {
 }
 
 // UnlockSuccess checks whether the TxInput can unlock the provided script
-func (in *TxInputPb) UnlockSuccess(lockScript []byte) bool {
-	v, err := txvm.NewIVM(in.ByteStream(), append(in.UnlockScript, lockScript...))
+// the signature in unlock script should sign the message 'txin' of the UTXO being spent
+func (in *TxInputPb) UnlockSuccess(txin []byte, lockScript []byte) bool {
+	return in.UnlockSuccessBatch(txin, lockScript, nil)
+}
+
+// UnlockSuccessBatch checks whether the TxInput can unlock the provided script, adding the Schnorr signatures to the
+// batch if it is not nil, in which case the result only holds if the batch verifies
+func (in *TxInputPb) UnlockSuccessBatch(txin []byte, lockScript []byte, batch *cp.SchnorrBatch) bool {
+	script := make([]byte, 0, len(in.UnlockScript)+len(lockScript))
+	script = append(script, in.UnlockScript...)
+	script = append(script, lockScript...)
+	v, err := txvm.NewIVM(txin, script)
 	if err != nil {
 		return false
 	}
+	// the replaceable flag would otherwise satisfy any relative lock time
+	v.SetSequence(in.Sequence &^ txvm.SequenceReplaceable)
+	if batch != nil {
+		v.SetSchnorrBatch(batch)
+	}
 	if err := v.Execute(); err != nil {
 		return false
 	}
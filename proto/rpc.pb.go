@@ -28,7 +28,7 @@ type CreateRawTxRequest struct {
 func (m *CreateRawTxRequest) Reset()                    { *m = CreateRawTxRequest{} }
 func (m *CreateRawTxRequest) String() string            { return proto.CompactTextString(m) }
 func (*CreateRawTxRequest) ProtoMessage()               {}
-func (*CreateRawTxRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+func (*CreateRawTxRequest) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
 
 func (m *CreateRawTxRequest) GetFrom() string {
 	if m != nil {
@@ -72,7 +72,7 @@ type CreateRawTxReply struct {
 func (m *CreateRawTxReply) Reset()                    { *m = CreateRawTxReply{} }
 func (m *CreateRawTxReply) String() string            { return proto.CompactTextString(m) }
 func (*CreateRawTxReply) ProtoMessage()               {}
-func (*CreateRawTxReply) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+func (*CreateRawTxReply) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
 
 func (m *CreateRawTxReply) GetSerializedTx() []byte {
 	if m != nil {
@@ -88,7 +88,7 @@ type SendTxRequest struct {
 func (m *SendTxRequest) Reset()                    { *m = SendTxRequest{} }
 func (m *SendTxRequest) String() string            { return proto.CompactTextString(m) }
 func (*SendTxRequest) ProtoMessage()               {}
-func (*SendTxRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+func (*SendTxRequest) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
 
 func (m *SendTxRequest) GetSerializedTx() []byte {
 	if m != nil {
@@ -103,7 +103,7 @@ type SendTxReply struct {
 func (m *SendTxReply) Reset()                    { *m = SendTxReply{} }
 func (m *SendTxReply) String() string            { return proto.CompactTextString(m) }
 func (*SendTxReply) ProtoMessage()               {}
-func (*SendTxReply) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+func (*SendTxReply) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
 
 func init() {
 	proto.RegisterType((*CreateRawTxRequest)(nil), "iproto.CreateRawTxRequest")
@@ -217,9 +217,9 @@ var _ChainService_serviceDesc = grpc.ServiceDesc{
 	Metadata: "rpc.proto",
 }
 
-func init() { proto.RegisterFile("rpc.proto", fileDescriptor1) }
+func init() { proto.RegisterFile("rpc.proto", fileDescriptor2) }
 
-var fileDescriptor1 = []byte{
+var fileDescriptor2 = []byte{
 	// 252 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x90, 0x41, 0x4e, 0xc3, 0x30,
 	0x10, 0x45, 0x71, 0x9a, 0x46, 0xea, 0x34, 0x41, 0xd5, 0x00, 0x92, 0x95, 0x55, 0x14, 0x36, 0x59,
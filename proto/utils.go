@@ -29,6 +29,18 @@ const (
 	MsgBlockSyncReqType uint32 = 4
 	// MsgBlockSyncDataType is the response to messages of type MsgBlockSyncReqType
 	MsgBlockSyncDataType uint32 = 5
+	// MsgBlockHeaderSyncReqType is for requests among peers to sync block headers
+	MsgBlockHeaderSyncReqType uint32 = 6
+	// MsgBlockHeaderSyncDataType is the response to messages of type MsgBlockHeaderSyncReqType
+	MsgBlockHeaderSyncDataType uint32 = 7
+	// MsgCompactBlockType is for compact blocks broadcasted within the network
+	MsgCompactBlockType uint32 = 8
+	// MsgBlockTxsSyncReqType is for requests among peers to sync the transactions missing from a compact block
+	MsgBlockTxsSyncReqType uint32 = 9
+	// MsgBlockTxsSyncDataType is the response to messages of type MsgBlockTxsSyncReqType
+	MsgBlockTxsSyncDataType uint32 = 10
+	// MsgCheckpointVoteType is for the signatures of the delegates on their tips gossiped within the network
+	MsgCheckpointVoteType uint32 = 11
 	// TestPayloadType is a test payload message type
 	TestPayloadType uint32 = 10001
 )
@@ -46,6 +58,18 @@ func GetTypeFromProtoMsg(msg proto.Message) (uint32, error) {
 		return MsgBlockSyncReqType, nil
 	case *BlockContainer:
 		return MsgBlockSyncDataType, nil
+	case *BlockHeaderSync:
+		return MsgBlockHeaderSyncReqType, nil
+	case *BlockHeaderContainer:
+		return MsgBlockHeaderSyncDataType, nil
+	case *CompactBlockPb:
+		return MsgCompactBlockType, nil
+	case *BlockTxsSync:
+		return MsgBlockTxsSyncReqType, nil
+	case *BlockTxsContainer:
+		return MsgBlockTxsSyncDataType, nil
+	case *CheckpointVotePb:
+		return MsgCheckpointVoteType, nil
 	case *TestPayload:
 		return TestPayloadType, nil
 	default:
@@ -55,6 +79,18 @@ func GetTypeFromProtoMsg(msg proto.Message) (uint32, error) {
 
 // TypifyProtoMsg unmarshal a proto message based on the given MessageType
 func TypifyProtoMsg(tp uint32, msg []byte) (proto.Message, error) {
+	m, err := NewProtoMsg(tp)
+	if err != nil {
+		return nil, err
+	}
+	if err := proto.Unmarshal(msg, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewProtoMsg returns an empty proto message of the given MessageType
+func NewProtoMsg(tp uint32) (proto.Message, error) {
 	var m proto.Message
 	switch tp {
 	case MsgTxProtoMsgType:
@@ -67,15 +103,22 @@ func TypifyProtoMsg(tp uint32, msg []byte) (proto.Message, error) {
 		m = &BlockSync{}
 	case MsgBlockSyncDataType:
 		m = &BlockContainer{}
+	case MsgBlockHeaderSyncReqType:
+		m = &BlockHeaderSync{}
+	case MsgBlockHeaderSyncDataType:
+		m = &BlockHeaderContainer{}
+	case MsgCompactBlockType:
+		m = &CompactBlockPb{}
+	case MsgBlockTxsSyncReqType:
+		m = &BlockTxsSync{}
+	case MsgBlockTxsSyncDataType:
+		m = &BlockTxsContainer{}
+	case MsgCheckpointVoteType:
+		m = &CheckpointVotePb{}
 	case TestPayloadType:
 		m = &TestPayload{}
 	default:
 		return nil, errors.New("UnknownProtoMsgType proto message type")
 	}
-
-	err := proto.Unmarshal(msg, m)
-	if err != nil {
-		return nil, err
-	}
 	return m, nil
 }
@@ -22,7 +22,7 @@ type UtxoPb struct {
 func (m *UtxoPb) Reset()                    { *m = UtxoPb{} }
 func (m *UtxoPb) String() string            { return proto.CompactTextString(m) }
 func (*UtxoPb) ProtoMessage()               {}
-func (*UtxoPb) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
+func (*UtxoPb) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
 
 func (m *UtxoPb) GetValue() uint64 {
 	if m != nil {
@@ -55,12 +55,15 @@ func (m *UtxoPb) GetLockScript() []byte {
 type UtxoEntryPb struct {
 	Hash []byte    `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
 	Utxo []*UtxoPb `protobuf:"bytes,2,rep,name=utxo" json:"utxo,omitempty"`
+	// coinbase entries keep the height of the block minting them to enforce coinbase maturity
+	Coinbase bool   `protobuf:"varint,3,opt,name=coinbase" json:"coinbase,omitempty"`
+	Height   uint32 `protobuf:"varint,4,opt,name=height" json:"height,omitempty"`
 }
 
 func (m *UtxoEntryPb) Reset()                    { *m = UtxoEntryPb{} }
 func (m *UtxoEntryPb) String() string            { return proto.CompactTextString(m) }
 func (*UtxoEntryPb) ProtoMessage()               {}
-func (*UtxoEntryPb) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
+func (*UtxoEntryPb) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
 
 func (m *UtxoEntryPb) GetHash() []byte {
 	if m != nil {
@@ -76,6 +79,20 @@ func (m *UtxoEntryPb) GetUtxo() []*UtxoPb {
 	return nil
 }
 
+func (m *UtxoEntryPb) GetCoinbase() bool {
+	if m != nil {
+		return m.Coinbase
+	}
+	return false
+}
+
+func (m *UtxoEntryPb) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
 type UtxoMapPb struct {
 	UtxoEntry []*UtxoEntryPb `protobuf:"bytes,1,rep,name=utxoEntry" json:"utxoEntry,omitempty"`
 }
@@ -83,7 +100,7 @@ type UtxoMapPb struct {
 func (m *UtxoMapPb) Reset()                    { *m = UtxoMapPb{} }
 func (m *UtxoMapPb) String() string            { return proto.CompactTextString(m) }
 func (*UtxoMapPb) ProtoMessage()               {}
-func (*UtxoMapPb) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
+func (*UtxoMapPb) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
 
 func (m *UtxoMapPb) GetUtxoEntry() []*UtxoEntryPb {
 	if m != nil {
@@ -92,27 +109,204 @@ func (m *UtxoMapPb) GetUtxoEntry() []*UtxoEntryPb {
 	return nil
 }
 
+// snapshot of the UTXO set at a block, the commitment is the hash of the block hash, height and UTXO set
+type UtxoSnapshotPb struct {
+	Height     uint32         `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Header     *BlockHeaderPb `protobuf:"bytes,2,opt,name=header" json:"header,omitempty"`
+	Utxo       *UtxoMapPb     `protobuf:"bytes,3,opt,name=utxo" json:"utxo,omitempty"`
+	Commitment []byte         `protobuf:"bytes,4,opt,name=commitment,proto3" json:"commitment,omitempty"`
+}
+
+func (m *UtxoSnapshotPb) Reset()                    { *m = UtxoSnapshotPb{} }
+func (m *UtxoSnapshotPb) String() string            { return proto.CompactTextString(m) }
+func (*UtxoSnapshotPb) ProtoMessage()               {}
+func (*UtxoSnapshotPb) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{3} }
+
+func (m *UtxoSnapshotPb) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *UtxoSnapshotPb) GetHeader() *BlockHeaderPb {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *UtxoSnapshotPb) GetUtxo() *UtxoMapPb {
+	if m != nil {
+		return m.Utxo
+	}
+	return nil
+}
+
+func (m *UtxoSnapshotPb) GetCommitment() []byte {
+	if m != nil {
+		return m.Commitment
+	}
+	return nil
+}
+
+// serialized state of an account before a block changes it, an empty account means the account does not exist before
+type AccountUndoPb struct {
+	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Account []byte `protobuf:"bytes,2,opt,name=account,proto3" json:"account,omitempty"`
+}
+
+func (m *AccountUndoPb) Reset()                    { *m = AccountUndoPb{} }
+func (m *AccountUndoPb) String() string            { return proto.CompactTextString(m) }
+func (*AccountUndoPb) ProtoMessage()               {}
+func (*AccountUndoPb) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{4} }
+
+func (m *AccountUndoPb) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *AccountUndoPb) GetAccount() []byte {
+	if m != nil {
+		return m.Account
+	}
+	return nil
+}
+
+// value of a key in the storage of a contract before a block writes it, an empty value means the key is not set before
+type StorageUndoPb struct {
+	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Key     []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Value   []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *StorageUndoPb) Reset()                    { *m = StorageUndoPb{} }
+func (m *StorageUndoPb) String() string            { return proto.CompactTextString(m) }
+func (*StorageUndoPb) ProtoMessage()               {}
+func (*StorageUndoPb) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{5} }
+
+func (m *StorageUndoPb) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *StorageUndoPb) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *StorageUndoPb) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+// UTXO entries, accounts, contracts and supply changed by a block as they are before it, to restore the states at the
+// previous block
+// an entry without UTXO does not exist before the block, and the contracts deployed by the block are removed to undo it
+type StateUndoPb struct {
+	UtxoEntry []*UtxoEntryPb   `protobuf:"bytes,1,rep,name=utxoEntry" json:"utxoEntry,omitempty"`
+	Account   []*AccountUndoPb `protobuf:"bytes,2,rep,name=account" json:"account,omitempty"`
+	Deployed  []string         `protobuf:"bytes,3,rep,name=deployed" json:"deployed,omitempty"`
+	Storage   []*StorageUndoPb `protobuf:"bytes,4,rep,name=storage" json:"storage,omitempty"`
+	Emitted   uint64           `protobuf:"varint,5,opt,name=emitted" json:"emitted,omitempty"`
+	Burned    uint64           `protobuf:"varint,6,opt,name=burned" json:"burned,omitempty"`
+}
+
+func (m *StateUndoPb) Reset()                    { *m = StateUndoPb{} }
+func (m *StateUndoPb) String() string            { return proto.CompactTextString(m) }
+func (*StateUndoPb) ProtoMessage()               {}
+func (*StateUndoPb) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{6} }
+
+func (m *StateUndoPb) GetUtxoEntry() []*UtxoEntryPb {
+	if m != nil {
+		return m.UtxoEntry
+	}
+	return nil
+}
+
+func (m *StateUndoPb) GetAccount() []*AccountUndoPb {
+	if m != nil {
+		return m.Account
+	}
+	return nil
+}
+
+func (m *StateUndoPb) GetDeployed() []string {
+	if m != nil {
+		return m.Deployed
+	}
+	return nil
+}
+
+func (m *StateUndoPb) GetStorage() []*StorageUndoPb {
+	if m != nil {
+		return m.Storage
+	}
+	return nil
+}
+
+func (m *StateUndoPb) GetEmitted() uint64 {
+	if m != nil {
+		return m.Emitted
+	}
+	return 0
+}
+
+func (m *StateUndoPb) GetBurned() uint64 {
+	if m != nil {
+		return m.Burned
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*UtxoPb)(nil), "iproto.utxoPb")
 	proto.RegisterType((*UtxoEntryPb)(nil), "iproto.utxoEntryPb")
 	proto.RegisterType((*UtxoMapPb)(nil), "iproto.utxoMapPb")
+	proto.RegisterType((*UtxoSnapshotPb)(nil), "iproto.utxoSnapshotPb")
+	proto.RegisterType((*AccountUndoPb)(nil), "iproto.accountUndoPb")
+	proto.RegisterType((*StorageUndoPb)(nil), "iproto.storageUndoPb")
+	proto.RegisterType((*StateUndoPb)(nil), "iproto.stateUndoPb")
 }
 
-func init() { proto.RegisterFile("utxo.proto", fileDescriptor2) }
-
-var fileDescriptor2 = []byte{
-	// 207 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x8e, 0x31, 0x6b, 0x87, 0x30,
-	0x10, 0xc5, 0xc9, 0xdf, 0x28, 0xf4, 0xb4, 0x0e, 0xd7, 0x0e, 0x99, 0x4a, 0xc8, 0x50, 0x32, 0x09,
-	0x6d, 0xf7, 0x6e, 0x8e, 0x05, 0x89, 0x9f, 0xc0, 0x58, 0xc1, 0x50, 0x31, 0x62, 0x63, 0xb1, 0xa5,
-	0x1f, 0xbe, 0x24, 0x29, 0x28, 0xff, 0xe9, 0xf2, 0x7e, 0x77, 0x2f, 0xef, 0x01, 0x6c, 0x6e, 0xb7,
-	0xd5, 0xb2, 0x5a, 0x67, 0x31, 0x33, 0x61, 0x8a, 0x5f, 0xc8, 0x3c, 0x6d, 0x34, 0xde, 0x43, 0xfa,
-	0xd5, 0x4d, 0xdb, 0xc0, 0x08, 0x27, 0x92, 0xaa, 0x28, 0x3c, 0x35, 0xf3, 0xfb, 0xb0, 0xb3, 0x0b,
-	0x27, 0x32, 0x55, 0x51, 0xe0, 0x23, 0x94, 0x93, 0xed, 0x3f, 0xda, 0x7e, 0x35, 0x8b, 0x6b, 0xcd,
-	0xcf, 0xc0, 0x12, 0x4e, 0xe4, 0xad, 0xba, 0xa2, 0xf8, 0x00, 0x70, 0x10, 0x46, 0x39, 0x91, 0x85,
-	0x3a, 0x11, 0x51, 0x43, 0xee, 0xd3, 0xeb, 0xd9, 0xad, 0xdf, 0x8d, 0x46, 0x04, 0x3a, 0x76, 0x9f,
-	0x63, 0x68, 0x50, 0xa8, 0xf0, 0x46, 0x01, 0xd4, 0x9f, 0xb0, 0x0b, 0x4f, 0x64, 0xfe, 0x5c, 0x56,
-	0xb1, 0x77, 0x15, 0x4b, 0xab, 0xb0, 0x13, 0xaf, 0x70, 0xe3, 0xe7, 0x5b, 0xb7, 0x34, 0x1a, 0x9f,
-	0xa2, 0x08, 0x7f, 0x32, 0x12, 0x5c, 0x77, 0x67, 0xd7, 0x7f, 0x98, 0x3a, 0xae, 0x74, 0x16, 0xb6,
-	0x2f, 0x7f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x40, 0x3f, 0x78, 0x33, 0x21, 0x01, 0x00, 0x00,
+func init() { proto.RegisterFile("utxo.proto", fileDescriptor3) }
+
+var fileDescriptor3 = []byte{
+	// 440 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x95, 0x53, 0xcb, 0x4e, 0x83, 0x40,
+	0x14, 0x0d, 0xd2, 0x62, 0x7b, 0x29, 0x8d, 0x8e, 0x8f, 0x90, 0x2e, 0x8c, 0x21, 0xd1, 0x74, 0x63,
+	0x8d, 0x75, 0xef, 0x42, 0x63, 0xe2, 0xc6, 0x44, 0xa7, 0xf1, 0x03, 0x06, 0x98, 0x14, 0xd2, 0x76,
+	0x86, 0xc0, 0xd4, 0x14, 0xe3, 0xb7, 0xf8, 0x8d, 0x7e, 0x82, 0xf3, 0x80, 0x42, 0xdd, 0x18, 0x57,
+	0xcc, 0xb9, 0xf7, 0x72, 0xce, 0xb9, 0x87, 0x01, 0x60, 0x2d, 0x36, 0x7c, 0x92, 0xe5, 0x5c, 0x70,
+	0xe4, 0xa4, 0xfa, 0x39, 0x3a, 0x08, 0x97, 0x3c, 0x5a, 0x44, 0x09, 0x49, 0x99, 0xe9, 0x04, 0x9f,
+	0xe0, 0xa8, 0xb9, 0x97, 0x10, 0x1d, 0x43, 0xf7, 0x9d, 0x2c, 0xd7, 0xd4, 0xb7, 0xce, 0xad, 0x71,
+	0x07, 0x1b, 0xa0, 0xaa, 0x29, 0x8b, 0xe9, 0xc6, 0xdf, 0x93, 0xd5, 0x2e, 0x36, 0x00, 0x5d, 0xc2,
+	0x50, 0x11, 0xcd, 0xa2, 0x3c, 0xcd, 0xc4, 0x2c, 0xfd, 0xa0, 0xbe, 0x2d, 0xdb, 0x1e, 0xfe, 0x55,
+	0x45, 0x67, 0x00, 0x4d, 0xc5, 0xef, 0xc8, 0x99, 0x01, 0x6e, 0x55, 0x82, 0x12, 0x5c, 0xa5, 0xfe,
+	0xc8, 0x44, 0x5e, 0x4a, 0x0b, 0x08, 0x3a, 0x09, 0x29, 0x12, 0xed, 0x60, 0x80, 0xf5, 0x19, 0x05,
+	0xd0, 0x51, 0x23, 0x52, 0xdf, 0x1e, 0xbb, 0xd3, 0xe1, 0xc4, 0x6c, 0x32, 0x31, 0xa6, 0xb1, 0xee,
+	0xa1, 0x11, 0xf4, 0x22, 0x9e, 0xb2, 0x90, 0x14, 0xc6, 0x48, 0x0f, 0x6f, 0x31, 0x3a, 0x05, 0x27,
+	0xa1, 0xe9, 0x3c, 0x31, 0xf2, 0x1e, 0xae, 0x50, 0x70, 0x07, 0x7d, 0xf5, 0xee, 0x33, 0xc9, 0xa4,
+	0xf0, 0x8d, 0x01, 0xda, 0x87, 0x54, 0x57, 0x4a, 0x47, 0x6d, 0xa5, 0xca, 0x20, 0x6e, 0xa6, 0x82,
+	0x2f, 0x0b, 0x86, 0x0a, 0xcd, 0x18, 0xc9, 0x8a, 0x84, 0x0b, 0xc9, 0xd2, 0x48, 0x59, 0x6d, 0x29,
+	0x74, 0xa5, 0xea, 0x24, 0xa6, 0xb9, 0x0e, 0xd1, 0x9d, 0x9e, 0xd4, 0xd4, 0xf7, 0x2a, 0x8a, 0x27,
+	0xdd, 0x92, 0xe4, 0xd5, 0x10, 0xba, 0xa8, 0x36, 0xb6, 0xf5, 0xf0, 0x61, 0xdb, 0x87, 0x76, 0x5b,
+	0x2d, 0x2d, 0xb3, 0x8d, 0xf8, 0x6a, 0x95, 0x8a, 0x15, 0x65, 0xdb, 0x6c, 0x9b, 0x4a, 0xf0, 0x00,
+	0x1e, 0x89, 0x22, 0xbe, 0x66, 0xe2, 0x8d, 0xc5, 0xea, 0x03, 0xfb, 0xb0, 0x4f, 0xe2, 0x38, 0xa7,
+	0x45, 0xa1, 0xfd, 0xf5, 0x71, 0x0d, 0x75, 0xc7, 0x8c, 0x6a, 0x87, 0x03, 0x5c, 0xc3, 0xe0, 0x15,
+	0xbc, 0x42, 0xf0, 0x9c, 0xcc, 0xe9, 0x9f, 0x24, 0x07, 0x60, 0x2f, 0x68, 0x59, 0x11, 0xa8, 0x63,
+	0x73, 0xa3, 0x6c, 0x5d, 0x33, 0x20, 0xf8, 0xb6, 0xc0, 0x2d, 0x04, 0x11, 0x35, 0xe3, 0xff, 0xb3,
+	0x47, 0xd7, 0x6d, 0xbf, 0x76, 0x3b, 0xd1, 0x9d, 0x8d, 0xb7, 0x6b, 0xa8, 0x0b, 0x12, 0xd3, 0x6c,
+	0xc9, 0x4b, 0x1a, 0x4b, 0x33, 0xb6, 0xb4, 0xbd, 0xc5, 0x8a, 0xac, 0x5a, 0x51, 0x86, 0xb8, 0x43,
+	0xb6, 0xb3, 0x39, 0xae, 0xa7, 0x54, 0x04, 0x54, 0x86, 0x2c, 0x24, 0x57, 0x57, 0xff, 0x2a, 0x35,
+	0x54, 0x17, 0x20, 0x5c, 0xe7, 0x4c, 0x36, 0x1c, 0xdd, 0xa8, 0x50, 0xe8, 0x68, 0xbe, 0xdb, 0x1f,
+	0x4c, 0x86, 0x5f, 0x99, 0x93, 0x03, 0x00, 0x00,
 }
@@ -9,7 +9,6 @@ package rpcservice
 import (
 	"net"
 
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
@@ -20,9 +19,12 @@ import (
 	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
 	pb "github.com/iotexproject/iotex-core/proto"
 )
 
+var log = logger.New("rpcservice")
+
 // Chainserver is used to implement Chain Service
 type Chainserver struct {
 	blockchain  blockchain.IBlockchain
@@ -33,11 +35,11 @@ type Chainserver struct {
 }
 
 // NewChainServer creates an instance of chainserver
-func NewChainServer(c config.RPC, b blockchain.IBlockchain, dp cm.Dispatcher, cb func(proto.Message) error) *Chainserver {
+func NewChainServer(c config.RPC, b blockchain.IBlockchain, dp cm.Dispatcher, cb func(proto.Message) error) (*Chainserver, error) {
 	if cb == nil {
-		glog.Fatal("cannot new chain server with nil callback")
+		return nil, errors.New("cannot new chain server with nil callback")
 	}
-	return &Chainserver{blockchain: b, config: c, dispatcher: dp, broadcastcb: cb}
+	return &Chainserver{blockchain: b, config: c, dispatcher: dp, broadcastcb: cb}, nil
 }
 
 // CreateRawTx creates a unsigned raw transaction
@@ -46,13 +48,16 @@ func (s *Chainserver) CreateRawTx(ctx context.Context, in *pb.CreateRawTxRequest
 		return nil, errors.New("invalid CreateRawTxRequest")
 	}
 
-	bal := s.blockchain.BalanceOf(in.From)
+	bal := s.blockchain.BalanceOf(in.From, 0)
 	if bal < in.Value {
 		return nil, errors.New("not enough balance from address: " + in.From)
 	}
 
 	p := []*blockchain.Payee{{in.To, in.Value}}
-	tx := s.blockchain.CreateRawTransaction(iotxaddress.Address{Address: in.From}, in.Value, p)
+	tx, err := s.blockchain.CreateRawTransaction(iotxaddress.Address{Address: in.From}, in.Value, p)
+	if err != nil {
+		return nil, err
+	}
 	stx, err := proto.Marshal(tx.ConvertToTxPb())
 	if err != nil {
 		return nil, err
@@ -75,23 +80,22 @@ func (s *Chainserver) SendTx(ctx context.Context, in *pb.SendTxRequest) (*pb.Sen
 		return nil, err
 	}
 	// send to txpool via dispatcher
-	s.dispatcher.HandleBroadcast(tx, nil)
+	s.dispatcher.HandleBroadcast(nil, tx, nil)
 	return &pb.SendTxReply{}, nil
 }
 
 // Start starts the chain server
 func (s *Chainserver) Start() error {
 	if s.config == (config.RPC{}) {
-		glog.Warning("Chain service is not configured")
+		log.Warning("Chain service is not configured")
 		return nil
 	}
 
 	lis, err := net.Listen("tcp", s.config.Port)
 	if err != nil {
-		glog.Fatalf("Chain server failed to listen: %v", err)
-		return err
+		return errors.Wrap(err, "Chain server failed to listen")
 	}
-	glog.Infof("Chain server is listening on %v", lis.Addr().String())
+	log.Infof("Chain server is listening on %v", lis.Addr().String())
 
 	s.grpcserver = grpc.NewServer()
 	pb.RegisterChainServiceServer(s.grpcserver, s)
@@ -99,7 +103,7 @@ func (s *Chainserver) Start() error {
 
 	go func() {
 		if err := s.grpcserver.Serve(lis); err != nil {
-			glog.Fatalf("Node failed to serve: %v", err)
+			log.Errorf("Chain server failed to serve: %v", err)
 		}
 	}()
 	return nil
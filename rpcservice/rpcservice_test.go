@@ -85,7 +85,8 @@ func TestCreateRawTx(t *testing.T) {
 		return nil
 	}
 
-	s := NewChainServer(cfg.RPC, mbc, mdp, bcb)
+	s, err := NewChainServer(cfg.RPC, mbc, mdp, bcb)
+	assert.Nil(t, err)
 	s.Start()
 	defer s.Stop()
 
@@ -99,9 +100,9 @@ func TestCreateRawTx(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	mbc.EXPECT().BalanceOf(gomock.Any()).Return(uint64(101)).Times(1)
-	mbc.EXPECT().CreateRawTransaction(gomock.Any(), gomock.Any(), gomock.Any()).Return(testingTx()).Times(1)
-	mdp.EXPECT().HandleBroadcast(gomock.Any(), gomock.Any()).Times(0)
+	mbc.EXPECT().BalanceOf(gomock.Any(), gomock.Any()).Return(uint64(101)).Times(1)
+	mbc.EXPECT().CreateRawTransaction(gomock.Any(), gomock.Any(), gomock.Any()).Return(testingTx(), nil).Times(1)
+	mdp.EXPECT().HandleBroadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 	r, err := c.CreateRawTx(ctx, &pb.CreateRawTxRequest{From: "Alice", To: "Bob", Value: 100})
 	assert.Nil(t, err)
 	assert.Equal(t, 380, len(r.SerializedTx))
@@ -126,7 +127,8 @@ func TestSendTx(t *testing.T) {
 		return nil
 	}
 
-	s := NewChainServer(cfg.RPC, mbc, mdp, bcb)
+	s, err := NewChainServer(cfg.RPC, mbc, mdp, bcb)
+	assert.Nil(t, err)
 	s.Start()
 	defer s.Stop()
 
@@ -143,7 +145,7 @@ func TestSendTx(t *testing.T) {
 	stx, err := proto.Marshal(testingTx().ConvertToTxPb())
 	assert.Nil(t, err)
 
-	mdp.EXPECT().HandleBroadcast(gomock.Any(), gomock.Any()).Times(1)
+	mdp.EXPECT().HandleBroadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
 	_, err = c.SendTx(ctx, &pb.SendTxRequest{SerializedTx: stx})
 	assert.Nil(t, err)
 	assert.True(t, cbinvoked)
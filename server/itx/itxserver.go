@@ -9,7 +9,8 @@ package itx
 import (
 	"os"
 
-	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
 
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/blocksync"
@@ -27,52 +28,69 @@ import (
 type Server struct {
 	service.Service
 	bc  blockchain.IBlockchain
+	tp  txpool.TxPool
 	o   *network.Overlay
 	dp  cm.Dispatcher
 	cfg config.Config
 }
 
 // NewServer creates a new server
-func NewServer(cfg config.Config) Server {
-	bc := blockchain.CreateBlockchain(ta.Addrinfo["miner"].Address, &cfg)
-	tp := txpool.New(bc)
+func NewServer(cfg config.Config) (Server, error) {
+	bc, err := blockchain.CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, &cfg)
+	if err != nil {
+		return Server{}, errors.Wrap(err, "Failed to create Blockchain")
+	}
+	tp := txpool.New(bc, &cfg.TxPool)
+	bc.SetMempool(tp)
 
 	// server use first BootstrapNodes addr
 	o := network.NewOverlay(&cfg.Network)
 	pool := delegate.NewConfigBasedPool(&cfg.Delegate)
-	bs := blocksync.NewBlockSyncer(&cfg, bc, tp, o, pool)
+	bs, err := blocksync.NewBlockSyncer(&cfg, bc, tp, o, pool)
+	if err != nil {
+		bc.Close()
+		return Server{}, errors.Wrap(err, "Failed to create block syncer")
+	}
 
 	// create dispatcher instance
-	dp := dispatcher.NewDispatcher(&cfg, bc, tp, bs, pool)
+	dp, err := dispatcher.NewDispatcher(&cfg, bc, tp, bs, pool)
+	if err != nil {
+		bc.Close()
+		return Server{}, errors.Wrap(err, "Failed to create dispatcher")
+	}
 	o.AttachDispatcher(dp)
+	o.AttachChain(bc)
 
 	return Server{
 		bc:  bc,
+		tp:  tp,
 		o:   o,
 		dp:  dp,
 		cfg: cfg,
-	}
+	}, nil
 }
 
 // Init initialize the server
-func (s *Server) Init() {
-	s.dp.Start()
-	if err := s.o.Init(); err != nil {
-		glog.Fatal(err)
+func (s *Server) Init() error {
+	if err := s.tp.Start(); err != nil {
+		return err
+	}
+	if err := s.dp.Start(); err != nil {
+		return err
 	}
+	return s.o.Init()
 }
 
 // Start starts the server
-func (s *Server) Start() {
-	if err := s.o.Start(); err != nil {
-		glog.Fatal(err)
-	}
+func (s *Server) Start() error {
+	return s.o.Start()
 }
 
-// Stop stops the server
+// Stop stops the server, stopping the components feeding the tx pool and the blockchain before them
 func (s *Server) Stop() {
 	s.o.Stop()
 	s.dp.Stop()
-	s.bc.Close()
+	s.tp.Stop()
+	s.bc.Stop()
 	os.Remove(s.cfg.Chain.ChainDBPath)
 }
@@ -6,7 +6,8 @@
 
 // Usage:
 //   make build
-//   ./bin/server -stderrthreshold=WARNING -log_dir=./log -config=./config.yaml
+//   ./bin/server -config=./config.yaml -network=testnet
+//   kill -HUP <pid> reloads the mempool limits, API rate limits and log levels of the config file
 //
 
 package main
@@ -15,29 +16,47 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
 	"github.com/iotexproject/iotex-core/server/run"
 )
 
 var configFile = flag.String("config", "./config.yaml", "specify configuration file path")
+var network = flag.String("network", "", "specify the network to join, one of mainnet, testnet and devnet, "+
+	"overriding the one of the configuration file")
 
 func init() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr,
-			"usage: server -stderrthreshold=[INFO|WARN|FATAL] -log_dir=[string] -config=[string]\n")
+			"usage: server -config=[string] -network=[string]\n")
 		flag.PrintDefaults()
 		os.Exit(2)
 	}
 	flag.Parse()
 }
 
-func main() {
-	cfg, err := config.LoadConfigWithPath(*configFile)
+var log = logger.New("server")
 
+func main() {
+	cfg, err := config.LoadConfigForNetwork(*configFile, *network)
 	if err != nil {
-		os.Exit(1)
+		log.Fatal(err)
 	}
 
-	run.Run(cfg, nil)
+	// an interrupt stops the node, aborting the DB work in flight
+	stop := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		log.Info("Stopping the node")
+		close(stop)
+	}()
+
+	if err := run.Run(cfg, *configFile, stop); err != nil {
+		log.Fatal(err)
+	}
 }
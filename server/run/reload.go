@@ -0,0 +1,74 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package run
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/api"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/txpool"
+)
+
+// reloader re-reads the config file and applies its hot reloadable fields to the running node, see
+// config.CheckReloadable
+type reloader struct {
+	path  string
+	mutex sync.Mutex
+	cfg   *config.Config
+	tp    txpool.TxPool
+	as    *api.Server
+}
+
+// reload applies the config file to the node, or returns the error why it cannot, in which case nothing is applied
+func (r *reloader) reload() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	next, err := config.LoadConfigForNetwork(r.path, r.cfg.Net)
+	if err != nil {
+		return err
+	}
+	if err := config.CheckReloadable(r.cfg, next); err != nil {
+		return err
+	}
+	if err := logger.Configure(next.Log.Level, next.Log.ModuleLevels); err != nil {
+		return err
+	}
+	r.tp.SetLimits(next.TxPool)
+	if r.as != nil {
+		r.as.SetRateLimits(next.API)
+	}
+	r.cfg = next
+	log.Infof("Reloaded the config from %s", r.path)
+	return nil
+}
+
+// watch reloads the config on SIGHUP until ctx is done
+func (r *reloader) watch(ctx context.Context) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sig)
+		for {
+			select {
+			case <-sig:
+				if err := r.reload(); err != nil {
+					log.Errorf("Failed to reload the config: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -9,66 +9,220 @@ package run
 import (
 	"os"
 
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
 
-	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/admin"
+	"github.com/iotexproject/iotex-core/api"
 	"github.com/iotexproject/iotex-core/blocksync"
+	"github.com/iotexproject/iotex-core/chainmanager"
 	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/consensus/dpos"
 	"github.com/iotexproject/iotex-core/delegate"
 	"github.com/iotexproject/iotex-core/dispatcher"
+	"github.com/iotexproject/iotex-core/faucet"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/metrics"
 	"github.com/iotexproject/iotex-core/network"
 	"github.com/iotexproject/iotex-core/rpcservice"
 	ta "github.com/iotexproject/iotex-core/test/testaddress"
 	"github.com/iotexproject/iotex-core/txpool"
+	"github.com/iotexproject/iotex-core/wallet"
 )
 
-// Run starts the iotex node and block on the stop chan.
-func Run(cfg *config.Config, stop chan struct{}) {
+var log = logger.New("server")
+
+// Run starts the iotex node and block on the stop chan, returning the error if the node cannot be started
+// Closing the stop chan while the blockchain is still initializing aborts the initialization. The hot reloadable fields
+// of the config file at configPath are applied on SIGHUP or on the admin request, unless configPath is empty.
+func Run(cfg *config.Config, configPath string, stop chan struct{}) error {
+	if err := logger.Configure(cfg.Log.Level, cfg.Log.ModuleLevels); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if preset, ok := config.Presets[cfg.Net]; ok {
+		if err := os.MkdirAll(preset.DataDir, 0700); err != nil {
+			return errors.Wrapf(err, "Failed to create the data directory of %s", cfg.Net)
+		}
+		log.Infof("Joining %s with data directory %s", cfg.Net, preset.DataDir)
+	}
+
 	// create Blockchain and TxPool instance
 	defer os.Remove(cfg.Chain.ChainDBPath)
-	bc := blockchain.CreateBlockchain(ta.Addrinfo["miner"].Address, cfg)
-	tp := txpool.New(bc)
-	defer bc.Close()
+	chains, err := chainmanager.New(ctx, ta.Addrinfo["miner"].Address, cfg)
+	if ctx.Err() != nil {
+		// stopped before the node is started
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "Failed to create Blockchain")
+	}
+	bc := chains.MainChain()
+	tp := txpool.New(bc, &cfg.TxPool)
+	bc.SetMempool(tp)
+	// stopped after all the components feeding them, draining the commits in flight and flushing the chains to disk
+	defer func() {
+		if err := chains.Stop(); err != nil {
+			log.Errorf("Failed to stop Blockchain: %v", err)
+		}
+	}()
+	if err := chains.StartRelayers(cfg.Chain.MinerAddr); err != nil {
+		return errors.Wrap(err, "Failed to start relayers")
+	}
+	if err := tp.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		if err := tp.Stop(); err != nil {
+			log.Errorf("Failed to stop TxPool: %v", err)
+		}
+	}()
+
+	if cfg.Consensus.DPoS.Enabled {
+		engine, err := dpos.NewDPoS(cfg.Consensus.DPoS)
+		if err != nil {
+			return errors.Wrap(err, "Failed to create DPoS engine")
+		}
+		engine.SetElection(bc)
+		bc.SetConsensus(engine)
+	}
 
 	overlay := network.NewOverlay(&cfg.Network)
 	pool := delegate.NewConfigBasedPool(&cfg.Delegate)
-	bs := blocksync.NewBlockSyncer(cfg, bc, tp, overlay, pool)
+	bs, err := blocksync.NewBlockSyncer(cfg, bc, tp, overlay, pool)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create block syncer")
+	}
 
 	// create dispatcher instance
-	dp := dispatcher.NewDispatcher(cfg, bc, tp, bs, pool)
+	dp, err := dispatcher.NewDispatcher(cfg, bc, tp, bs, pool)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create dispatcher")
+	}
 	overlay.AttachDispatcher(dp)
-	dp.Start()
+	overlay.AttachChain(bc)
+	if err := dp.Start(); err != nil {
+		return err
+	}
 	defer dp.Stop()
 
 	if err := overlay.Init(); err != nil {
-		glog.Fatal(err)
+		return err
 	}
 
 	if err := overlay.Start(); err != nil {
-		glog.Fatal(err)
+		return err
 	}
 	defer overlay.Stop()
 
 	if err := pool.Init(); err != nil {
-		glog.Fatal(err)
+		return err
 	}
 
 	if err := pool.Start(); err != nil {
-		glog.Fatal(err)
+		return err
 	}
 	defer pool.Stop()
 
+	bcb := func(msg proto.Message) error {
+		return bs.P2P().Broadcast(msg)
+	}
 	if cfg.RPC != (config.RPC{}) {
-		bcb := func(msg proto.Message) error {
-			return bs.P2P().Broadcast(msg)
+		cs, err := rpcservice.NewChainServer(cfg.RPC, bc, dp, bcb)
+		if err != nil {
+			return err
+		}
+		if err := cs.Start(); err != nil {
+			return err
 		}
-		cs := rpcservice.NewChainServer(cfg.RPC, bc, dp, bcb)
-		cs.Start()
 		defer cs.Stop()
 	}
 
-	select {
-	case <-stop:
+	rl := &reloader{path: configPath, cfg: cfg, tp: tp}
+	if cfg.API.Addr != "" || cfg.API.JSONRPCAddr != "" {
+		as, err := api.NewServer(cfg.API, bc, dp, bcb)
+		if err != nil {
+			return err
+		}
+		rl.as = as
+		as.SetPeerManager(overlay.PM)
+		as.SetTxPool(tp)
+		as.SetChainRouter(chains)
+		if err := as.Start(); err != nil {
+			return err
+		}
+		defer as.Stop()
+	}
+
+	var monitor *blocksync.TipMonitor
+	if threshold := blocksync.StaleTipThreshold(cfg); threshold > 0 {
+		monitor = blocksync.NewTipMonitor(bc, overlay.PM, bs.Resync, threshold)
+		if err := monitor.Start(); err != nil {
+			return err
+		}
+		defer monitor.Stop()
+	}
+
+	if cfg.Admin.Addr != "" {
+		ads, err := admin.NewServer(cfg.Admin, bc, tp, cancel)
+		if err != nil {
+			return err
+		}
+		ads.SetPeerManager(overlay.PM)
+		if monitor != nil {
+			ads.SetHealthMonitor(monitor)
+		}
+		if configPath != "" {
+			ads.SetReloader(rl.reload)
+		}
+		if err := ads.Start(); err != nil {
+			return err
+		}
+		defer ads.Stop()
+	}
+
+	if configPath != "" {
+		rl.watch(ctx)
 	}
+
+	if cfg.Faucet.Addr != "" {
+		w := wallet.NewWallet(cfg.Wallet)
+		if err := w.Unlock(cfg.Faucet.Account, cfg.Faucet.Passphrase, 0); err != nil {
+			return errors.Wrap(err, "Failed to unlock the faucet account")
+		}
+		signer, err := w.Signer(cfg.Faucet.Account)
+		if err != nil {
+			return err
+		}
+		fs, err := faucet.NewServer(cfg.Faucet, bc, tp, signer, bcb)
+		if err != nil {
+			return err
+		}
+		if err := fs.Start(); err != nil {
+			return err
+		}
+		defer fs.Stop()
+	}
+
+	if cfg.Metrics.Addr != "" {
+		ms := metrics.NewServer(cfg.Metrics)
+		if err := ms.Start(); err != nil {
+			return err
+		}
+		defer ms.Stop()
+	}
+
+	<-ctx.Done()
+	return nil
 }
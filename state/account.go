@@ -0,0 +1,69 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package state
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	cm "github.com/iotexproject/iotex-core/common"
+)
+
+// accountSize is the size of a serialized account, the 8-byte nonce followed by the 8-byte balance, the votee
+// follows if the account votes, then a zero byte and the operator if the account registers one
+const accountSize = 8 + 8
+
+// operatorSeparator separates the votee from the operator, it never appears in an address
+const operatorSeparator = 0
+
+// Account is the state of an address in the account-based model
+type Account struct {
+	// Nonce is the nonce of the last transfer sent from the account, the next transfer must have Nonce + 1
+	Nonce   uint64
+	Balance uint64
+	// Votee is the address the balance of the account is staked toward, an account voting for itself is a candidate
+	// for the delegates
+	Votee string
+	// Operator is the address whose key signs the blocks of the candidate in place of the key of the account, so the
+	// latter can be kept offline
+	Operator string
+}
+
+// Serialize returns the serialized account
+func (a *Account) Serialize() []byte {
+	buf := make([]byte, accountSize, accountSize+len(a.Votee)+1+len(a.Operator))
+	cm.MachineEndian.PutUint64(buf, a.Nonce)
+	cm.MachineEndian.PutUint64(buf[8:], a.Balance)
+	buf = append(buf, a.Votee...)
+	if a.Operator == "" {
+		return buf
+	}
+	buf = append(buf, operatorSeparator)
+	return append(buf, a.Operator...)
+}
+
+// Deserialize parses the serialized account
+func (a *Account) Deserialize(buf []byte) error {
+	if len(buf) < accountSize {
+		return errors.Errorf("Account has %d bytes, expecting at least %d", len(buf), accountSize)
+	}
+	a.Nonce = cm.MachineEndian.Uint64(buf)
+	a.Balance = cm.MachineEndian.Uint64(buf[8:])
+	a.Votee = string(buf[accountSize:])
+	a.Operator = ""
+	if i := bytes.IndexByte(buf[accountSize:], operatorSeparator); i >= 0 {
+		a.Votee = string(buf[accountSize : accountSize+i])
+		a.Operator = string(buf[accountSize+i+1:])
+	}
+	return nil
+}
+
+// IsCandidate returns true if the account at the address votes for itself
+func (a *Account) IsCandidate(addr string) bool {
+	return a.Votee != "" && a.Votee == addr
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package state
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+// ErrContractExists is the error returned when deploying a contract to the address of a deployed one
+var ErrContractExists = errors.New("contract already exists")
+
+// Code returns the code of the contract, or nil if no contract is deployed to the address
+func (f *Factory) Code(addr string) []byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.codes[addr]
+}
+
+// State returns the value of the key in the storage of the contract, or nil if the key is not set
+func (f *Factory) State(addr string, key []byte) []byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.storage[addr][string(key)]
+}
+
+// Codes returns the code of all contracts by address
+func (f *Factory) Codes() map[string][]byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	codes := make(map[string][]byte, len(f.codes))
+	for addr, code := range f.codes {
+		codes[addr] = code
+	}
+	return codes
+}
+
+// States returns a copy of the storage of all contracts by address then key
+func (f *Factory) States() map[string]map[string][]byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	states := make(map[string]map[string][]byte, len(f.storage))
+	for addr, storage := range f.storage {
+		states[addr] = make(map[string][]byte, len(storage))
+		for key, value := range storage {
+			states[addr][key] = value
+		}
+	}
+	return states
+}
+
+// LoadCode sets the code of the contract, e.g., when loading the contracts from Db
+func (f *Factory) LoadCode(addr string, code []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.codes[addr] = code
+}
+
+// RemoveCode removes the contract along with its storage, e.g., when unwinding the block deploying it
+func (f *Factory) RemoveCode(addr string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.codes, addr)
+	delete(f.storage, addr)
+}
+
+// LoadState sets the value of the key in the storage of the contract, e.g., when loading the contracts from Db
+func (f *Factory) LoadState(addr string, key []byte, value []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setState(addr, string(key), value)
+}
+
+// setState sets the value of the key in the storage of the contract, an empty value deletes the key
+// The caller has to hold mu.
+func (f *Factory) setState(addr string, key string, value []byte) {
+	storage, ok := f.storage[addr]
+	if !ok {
+		storage = make(map[string][]byte)
+		f.storage[addr] = storage
+	}
+	if len(value) == 0 {
+		delete(storage, key)
+		return
+	}
+	storage[key] = value
+}
+
+// applyContracts writes the contracts deployed and the storage written by the working set, the caller has to hold mu
+func (f *Factory) applyContracts(ws *WorkingSet) {
+	for addr, code := range ws.codes {
+		f.codes[addr] = code
+	}
+	for addr, storage := range ws.storage {
+		for key, value := range storage {
+			f.setState(addr, key, value)
+		}
+	}
+}
+
+// Code returns the code of the contract as deployed by the working set
+func (ws *WorkingSet) Code(addr string) []byte {
+	if code, ok := ws.codes[addr]; ok {
+		return code
+	}
+	return ws.f.Code(addr)
+}
+
+// Deploy deploys the code as the contract at the address
+func (ws *WorkingSet) Deploy(addr string, code []byte) error {
+	if ws.Code(addr) != nil {
+		return errors.Wrapf(ErrContractExists, "Contract %s", addr)
+	}
+	ws.codes[addr] = code
+	return nil
+}
+
+// State returns the value of the key in the storage of the contract as written by the working set
+func (ws *WorkingSet) State(addr string, key []byte) []byte {
+	if value, ok := ws.storage[addr][string(key)]; ok {
+		if len(value) == 0 {
+			return nil
+		}
+		return value
+	}
+	return ws.f.State(addr, key)
+}
+
+// SetState sets the value of the key in the storage of the contract, an empty value deletes the key
+func (ws *WorkingSet) SetState(addr string, key []byte, value []byte) {
+	storage, ok := ws.storage[addr]
+	if !ok {
+		storage = make(map[string][]byte)
+		ws.storage[addr] = storage
+	}
+	storage[string(key)] = append([]byte{}, value...)
+}
+
+// CodeChanges returns the code of the contracts deployed by the working set
+func (ws *WorkingSet) CodeChanges() map[string][]byte {
+	return ws.codes
+}
+
+// StateChanges returns the storage written by the working set by contract, where an empty value is a deleted key
+func (ws *WorkingSet) StateChanges() map[string]map[string][]byte {
+	return ws.storage
+}
+
+// contractLeaves returns the merkle leaves of the contracts as changed by the working set, sorted by address
+// A leaf is the hash of the address, the hash of the code and the storage sorted by key, where each key and value is
+// preceded by its length.
+func (ws *WorkingSet) contractLeaves() []cp.Hash32B {
+	ws.f.mu.RLock()
+	defer ws.f.mu.RUnlock()
+
+	codes := make(map[string][]byte, len(ws.f.codes)+len(ws.codes))
+	for addr, code := range ws.f.codes {
+		codes[addr] = code
+	}
+	for addr, code := range ws.codes {
+		codes[addr] = code
+	}
+	addrs := make([]string, 0, len(codes))
+	for addr := range codes {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	leaves := make([]cp.Hash32B, 0, len(addrs))
+	for _, addr := range addrs {
+		storage := make(map[string][]byte)
+		for key, value := range ws.f.storage[addr] {
+			storage[key] = value
+		}
+		for key, value := range ws.storage[addr] {
+			if len(value) == 0 {
+				delete(storage, key)
+				continue
+			}
+			storage[key] = value
+		}
+		keys := make([]string, 0, len(storage))
+		for key := range storage {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		codeHash := blake2b.Sum256(codes[addr])
+		stream := append([]byte(addr), codeHash[:]...)
+		size := make([]byte, 4)
+		for _, key := range keys {
+			cm.MachineEndian.PutUint32(size, uint32(len(key)))
+			stream = append(append(stream, size...), key...)
+			cm.MachineEndian.PutUint32(size, uint32(len(storage[key])))
+			stream = append(append(stream, size...), storage[key]...)
+		}
+		leaves = append(leaves, blake2b.Sum256(stream))
+	}
+	return leaves
+}
@@ -0,0 +1,285 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package state
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+var (
+	// ErrNotEnoughBalance is the error returned when an account does not have enough balance to transfer
+	ErrNotEnoughBalance = errors.New("not enough balance")
+	// ErrInvalidNonce is the error returned when the nonce of a transfer does not follow the one of its sender
+	ErrInvalidNonce = errors.New("invalid nonce")
+	// ErrBalanceOverflow is the error returned when crediting an account overflows its balance
+	ErrBalanceOverflow = errors.New("balance overflow")
+	// ErrNotCandidate is the error returned when an account which is not a candidate registers an operator
+	ErrNotCandidate = errors.New("not a candidate")
+)
+
+// Factory keeps the account states of all addresses, an address which never received anything has the zero account,
+// along with the code and storage of the deployed contracts
+// The states are only changed by applying a working set, so a block is either executed as a whole or not at all.
+type Factory struct {
+	mu       sync.RWMutex
+	accounts map[string]*Account
+	codes    map[string][]byte
+	storage  map[string]map[string][]byte
+}
+
+// NewFactory returns a factory without any account
+func NewFactory() *Factory {
+	return &Factory{
+		accounts: make(map[string]*Account),
+		codes:    make(map[string][]byte),
+		storage:  make(map[string]map[string][]byte),
+	}
+}
+
+// Account returns a copy of the state of the address
+func (f *Factory) Account(addr string) *Account {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if acct, ok := f.accounts[addr]; ok {
+		clone := *acct
+		return &clone
+	}
+	return &Account{}
+}
+
+// Len returns the number of accounts
+func (f *Factory) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.accounts)
+}
+
+// Accounts returns a copy of the states of all accounts by address
+func (f *Factory) Accounts() map[string]*Account {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	accounts := make(map[string]*Account, len(f.accounts))
+	for addr, acct := range f.accounts {
+		clone := *acct
+		accounts[addr] = &clone
+	}
+	return accounts
+}
+
+// LoadAccount sets the state of the address from the serialized account, e.g., when loading the states from Db
+func (f *Factory) LoadAccount(addr string, buf []byte) error {
+	acct := &Account{}
+	if err := acct.Deserialize(buf); err != nil {
+		return errors.Wrapf(err, "Account of %s", addr)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accounts[addr] = acct
+	return nil
+}
+
+// HasAccount returns true if the address has a state, i.e., it has ever received anything
+func (f *Factory) HasAccount(addr string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.accounts[addr]
+	return ok
+}
+
+// RemoveAccount removes the state of the address, e.g., when unwinding the block creating it
+func (f *Factory) RemoveAccount(addr string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.accounts, addr)
+}
+
+// Clear removes all accounts and contracts
+func (f *Factory) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accounts = make(map[string]*Account)
+	f.codes = make(map[string][]byte)
+	f.storage = make(map[string]map[string][]byte)
+}
+
+// NewWorkingSet returns an empty working set on top of the current states
+func (f *Factory) NewWorkingSet() *WorkingSet {
+	return &WorkingSet{
+		f:       f,
+		dirty:   make(map[string]*Account),
+		codes:   make(map[string][]byte),
+		storage: make(map[string]map[string][]byte),
+	}
+}
+
+// Apply writes the changes of the working set to the states
+func (f *Factory) Apply(ws *WorkingSet) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for addr, acct := range ws.dirty {
+		clone := *acct
+		f.accounts[addr] = &clone
+	}
+	f.applyContracts(ws)
+}
+
+// WorkingSet collects the changes to the account states and the contracts made by a block on top of a factory
+type WorkingSet struct {
+	f     *Factory
+	dirty map[string]*Account
+	// codes and storage are the deployed contracts and the written storage, where an empty value is a deleted key
+	codes   map[string][]byte
+	storage map[string]map[string][]byte
+}
+
+// Account returns the state of the address as changed by the working set
+func (ws *WorkingSet) Account(addr string) *Account {
+	if acct, ok := ws.dirty[addr]; ok {
+		clone := *acct
+		return &clone
+	}
+	return ws.f.Account(addr)
+}
+
+// Credit adds the amount to the balance of the address
+func (ws *WorkingSet) Credit(addr string, amount uint64) error {
+	acct := ws.Account(addr)
+	if acct.Balance > math.MaxUint64-amount {
+		return errors.Wrapf(ErrBalanceOverflow, "Crediting %d to %s", amount, addr)
+	}
+	acct.Balance += amount
+	ws.dirty[addr] = acct
+	return nil
+}
+
+// Transfer moves the amount from the sender to the recipient, the nonce must be the one following the sender's
+func (ws *WorkingSet) Transfer(sender string, recipient string, amount uint64, nonce uint64) error {
+	from := ws.Account(sender)
+	if nonce != from.Nonce+1 {
+		return errors.Wrapf(ErrInvalidNonce, "Nonce %d of %s, expecting %d", nonce, sender, from.Nonce+1)
+	}
+	if from.Balance < amount {
+		return errors.Wrapf(ErrNotEnoughBalance, "Transferring %d from %s with balance %d", amount, sender, from.Balance)
+	}
+	from.Nonce = nonce
+	from.Balance -= amount
+	ws.dirty[sender] = from
+	return ws.Credit(recipient, amount)
+}
+
+// Debit subtracts the amount from the balance of the address, e.g., for the amount leaving the chain, the nonce must be
+// the one following the address's
+func (ws *WorkingSet) Debit(addr string, amount uint64, nonce uint64) error {
+	acct := ws.Account(addr)
+	if nonce != acct.Nonce+1 {
+		return errors.Wrapf(ErrInvalidNonce, "Nonce %d of %s, expecting %d", nonce, addr, acct.Nonce+1)
+	}
+	if acct.Balance < amount {
+		return errors.Wrapf(ErrNotEnoughBalance, "Debiting %d from %s with balance %d", amount, addr, acct.Balance)
+	}
+	acct.Nonce = nonce
+	acct.Balance -= amount
+	ws.dirty[addr] = acct
+	return nil
+}
+
+// UseNonce bumps the nonce of the address without transferring anything, e.g., for a contract execution, the nonce
+// must be the one following the address's
+func (ws *WorkingSet) UseNonce(addr string, nonce uint64) error {
+	acct := ws.Account(addr)
+	if nonce != acct.Nonce+1 {
+		return errors.Wrapf(ErrInvalidNonce, "Nonce %d of %s, expecting %d", nonce, addr, acct.Nonce+1)
+	}
+	acct.Nonce = nonce
+	ws.dirty[addr] = acct
+	return nil
+}
+
+// Vote stakes the balance of the voter toward the votee, voting for itself nominates the voter as a candidate and an
+// empty votee withdraws the vote, the nonce must be the one following the voter's
+func (ws *WorkingSet) Vote(voter string, votee string, nonce uint64) error {
+	acct := ws.Account(voter)
+	if nonce != acct.Nonce+1 {
+		return errors.Wrapf(ErrInvalidNonce, "Nonce %d of %s, expecting %d", nonce, voter, acct.Nonce+1)
+	}
+	acct.Nonce = nonce
+	acct.Votee = votee
+	ws.dirty[voter] = acct
+	return nil
+}
+
+// SetOperator registers the operator signing the blocks of the candidate, an empty operator revokes the one registered,
+// the nonce must be the one following the candidate's
+func (ws *WorkingSet) SetOperator(candidate string, operator string, nonce uint64) error {
+	acct := ws.Account(candidate)
+	if nonce != acct.Nonce+1 {
+		return errors.Wrapf(ErrInvalidNonce, "Nonce %d of %s, expecting %d", nonce, candidate, acct.Nonce+1)
+	}
+	if operator != "" && !acct.IsCandidate(candidate) {
+		return errors.Wrapf(ErrNotCandidate, "Registering operator of %s", candidate)
+	}
+	acct.Nonce = nonce
+	acct.Operator = operator
+	ws.dirty[candidate] = acct
+	return nil
+}
+
+// Slash burns the balance of the offender and revokes its vote and operator, so it is no longer a candidate, and
+// returns the amount burned
+func (ws *WorkingSet) Slash(offender string) uint64 {
+	acct := ws.Account(offender)
+	burned := acct.Balance
+	acct.Balance = 0
+	acct.Votee = ""
+	acct.Operator = ""
+	ws.dirty[offender] = acct
+	return burned
+}
+
+// Changes returns the accounts changed by the working set
+func (ws *WorkingSet) Changes() map[string]*Account {
+	return ws.dirty
+}
+
+// Accounts returns the states of all accounts by address as changed by the working set
+func (ws *WorkingSet) Accounts() map[string]*Account {
+	accounts := ws.f.Accounts()
+	for addr, acct := range ws.dirty {
+		accounts[addr] = acct
+	}
+	return accounts
+}
+
+// Root returns the merkle root of the account states and contracts as changed by the working set, or the zero hash if
+// there is neither account nor contract
+// The leaves are the hashes of the address followed by the serialized account, sorted by address, followed by the
+// leaves of the contracts, see contractLeaves.
+func (ws *WorkingSet) Root() cp.Hash32B {
+	accounts := ws.Accounts()
+	addrs := make([]string, 0, len(accounts))
+	for addr := range accounts {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	leaves := make([]cp.Hash32B, 0, len(addrs))
+	for _, addr := range addrs {
+		leaves = append(leaves, blake2b.Sum256(append([]byte(addr), accounts[addr].Serialize()...)))
+	}
+	leaves = append(leaves, ws.contractLeaves()...)
+	if len(leaves) == 0 {
+		return cp.ZeroHash32B
+	}
+	return cp.NewMerkleTree(leaves).HashTree()
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+func TestAccountSerialization(t *testing.T) {
+	assert := assert.New(t)
+
+	acct := &Account{Nonce: 3, Balance: 1000}
+	decoded := &Account{}
+	assert.Nil(decoded.Deserialize(acct.Serialize()))
+	assert.Equal(acct, decoded)
+	assert.NotNil(decoded.Deserialize([]byte{1, 2, 3}))
+
+	acct.Votee = "bravo"
+	assert.Nil(decoded.Deserialize(acct.Serialize()))
+	assert.Equal(acct, decoded)
+
+	acct.Operator = "charlie"
+	assert.Nil(decoded.Deserialize(acct.Serialize()))
+	assert.Equal(acct, decoded)
+	acct.Votee = ""
+	assert.Nil(decoded.Deserialize(acct.Serialize()))
+	assert.Equal(acct, decoded)
+}
+
+func TestWorkingSet(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFactory()
+	ws := f.NewWorkingSet()
+	assert.Nil(ws.Credit("alfa", 100))
+	assert.Nil(ws.Transfer("alfa", "bravo", 30, 1))
+	assert.Equal(&Account{Nonce: 1, Balance: 70}, ws.Account("alfa"))
+	assert.Equal(&Account{Balance: 30}, ws.Account("bravo"))
+
+	// nothing is changed until the working set is applied
+	assert.Equal(&Account{}, f.Account("alfa"))
+	f.Apply(ws)
+	assert.Equal(&Account{Nonce: 1, Balance: 70}, f.Account("alfa"))
+	assert.Equal(2, f.Len())
+
+	ws = f.NewWorkingSet()
+	assert.Equal(ErrInvalidNonce, errors.Cause(ws.Transfer("alfa", "bravo", 10, 1)))
+	assert.Equal(ErrInvalidNonce, errors.Cause(ws.Transfer("alfa", "bravo", 10, 3)))
+	assert.Equal(ErrNotEnoughBalance, errors.Cause(ws.Transfer("alfa", "bravo", 71, 2)))
+	// a transfer to self only bumps the nonce
+	assert.Nil(ws.Transfer("alfa", "alfa", 70, 2))
+	assert.Equal(&Account{Nonce: 2, Balance: 70}, ws.Account("alfa"))
+	assert.Equal(1, len(ws.Changes()))
+
+	// a debit bumps the nonce as well
+	assert.Equal(ErrNotEnoughBalance, errors.Cause(ws.Debit("alfa", 71, 3)))
+	assert.Equal(ErrInvalidNonce, errors.Cause(ws.Debit("alfa", 10, 2)))
+	assert.Nil(ws.Debit("alfa", 10, 3))
+	assert.Equal(&Account{Nonce: 3, Balance: 60}, ws.Account("alfa"))
+}
+
+func TestVote(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFactory()
+	ws := f.NewWorkingSet()
+	assert.Nil(ws.Credit("alfa", 100))
+	assert.Nil(ws.Vote("alfa", "alfa", 1))
+	assert.Nil(ws.Vote("bravo", "alfa", 1))
+	assert.True(ws.Account("alfa").IsCandidate("alfa"))
+	assert.False(ws.Account("bravo").IsCandidate("bravo"))
+	assert.Equal(&Account{Nonce: 1, Votee: "alfa"}, ws.Account("bravo"))
+	assert.Equal(ErrInvalidNonce, errors.Cause(ws.Vote("bravo", "", 1)))
+
+	// withdrawing the vote
+	assert.Nil(ws.Vote("alfa", "", 2))
+	assert.False(ws.Account("alfa").IsCandidate("alfa"))
+	assert.Equal(&Account{Nonce: 2, Balance: 100}, ws.Account("alfa"))
+
+	// slashing burns the stake of the candidate
+	assert.Nil(ws.Vote("alfa", "alfa", 3))
+	assert.Equal(uint64(100), ws.Slash("alfa"))
+	assert.False(ws.Account("alfa").IsCandidate("alfa"))
+	assert.Equal(&Account{Nonce: 3}, ws.Account("alfa"))
+	assert.Equal(uint64(0), ws.Slash("alfa"))
+}
+
+func TestSetOperator(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFactory()
+	ws := f.NewWorkingSet()
+	assert.Nil(ws.Credit("alfa", 100))
+	// only a candidate registers an operator
+	assert.Equal(ErrNotCandidate, errors.Cause(ws.SetOperator("alfa", "bravo", 1)))
+	assert.Nil(ws.Vote("alfa", "alfa", 1))
+	assert.Equal(ErrInvalidNonce, errors.Cause(ws.SetOperator("alfa", "bravo", 1)))
+	assert.Nil(ws.SetOperator("alfa", "bravo", 2))
+	assert.Equal(&Account{Nonce: 2, Balance: 100, Votee: "alfa", Operator: "bravo"}, ws.Account("alfa"))
+
+	// revoking the operator
+	assert.Nil(ws.SetOperator("alfa", "", 3))
+	assert.Equal("", ws.Account("alfa").Operator)
+
+	// slashing revokes the operator as well
+	assert.Nil(ws.SetOperator("alfa", "bravo", 4))
+	ws.Slash("alfa")
+	assert.Equal(&Account{Nonce: 4}, ws.Account("alfa"))
+}
+
+func TestWorkingSetRoot(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFactory()
+	ws := f.NewWorkingSet()
+	assert.Equal(cp.ZeroHash32B, ws.Root())
+	assert.Nil(ws.Credit("alfa", 100))
+	assert.Nil(ws.Transfer("alfa", "bravo", 30, 1))
+	root := ws.Root()
+	assert.NotEqual(cp.ZeroHash32B, root)
+
+	// the root does not depend on whether the states are applied or not
+	f.Apply(ws)
+	assert.Equal(root, f.NewWorkingSet().Root())
+
+	ws = f.NewWorkingSet()
+	assert.Nil(ws.Transfer("bravo", "alfa", 1, 1))
+	assert.NotEqual(root, ws.Root())
+	assert.Equal(root, f.NewWorkingSet().Root())
+}
+
+func TestContracts(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFactory()
+	ws := f.NewWorkingSet()
+	assert.Nil(ws.UseNonce("alfa", 1))
+	assert.Equal(ErrInvalidNonce, errors.Cause(ws.UseNonce("alfa", 1)))
+	assert.Nil(ws.Deploy("contract", []byte{0x01}))
+	assert.Equal(ErrContractExists, errors.Cause(ws.Deploy("contract", []byte{0x02})))
+	ws.SetState("contract", []byte("k1"), []byte("v1"))
+	ws.SetState("contract", []byte("k2"), []byte("v2"))
+	assert.Equal([]byte("v1"), ws.State("contract", []byte("k1")))
+	assert.Nil(f.Code("contract"))
+	root := ws.Root()
+
+	f.Apply(ws)
+	assert.Equal([]byte{0x01}, f.Code("contract"))
+	assert.Equal([]byte("v2"), f.State("contract", []byte("k2")))
+	assert.Equal(&Account{Nonce: 1}, f.Account("alfa"))
+	assert.Equal(root, f.NewWorkingSet().Root())
+
+	// deleting a key changes the root, and restoring it brings the root back
+	ws = f.NewWorkingSet()
+	ws.SetState("contract", []byte("k2"), nil)
+	assert.Nil(ws.State("contract", []byte("k2")))
+	assert.NotEqual(root, ws.Root())
+	ws.SetState("contract", []byte("k2"), []byte("v2"))
+	assert.Equal(root, ws.Root())
+
+	ws.SetState("contract", []byte("k2"), nil)
+	f.Apply(ws)
+	assert.Nil(f.State("contract", []byte("k2")))
+	f.Clear()
+	assert.Nil(f.Code("contract"))
+}
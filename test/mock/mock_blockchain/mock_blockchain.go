@@ -8,8 +8,14 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	blockchain "github.com/iotexproject/iotex-core/blockchain"
 	crypto "github.com/iotexproject/iotex-core/crypto"
+	election "github.com/iotexproject/iotex-core/election"
 	iotxaddress "github.com/iotexproject/iotex-core/iotxaddress"
+	state "github.com/iotexproject/iotex-core/state"
+	wallet "github.com/iotexproject/iotex-core/wallet"
+	context "golang.org/x/net/context"
+	io "io"
 	reflect "reflect"
+	time "time"
 )
 
 // MockIBlockchain is a mock of IBlockchain interface
@@ -36,15 +42,39 @@ func (m *MockIBlockchain) EXPECT() *MockIBlockchainMockRecorder {
 }
 
 // Init mocks base method
-func (m *MockIBlockchain) Init() error {
-	ret := m.ctrl.Call(m, "Init")
+func (m *MockIBlockchain) Init(ctx context.Context) error {
+	ret := m.ctrl.Call(m, "Init", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Init indicates an expected call of Init
-func (mr *MockIBlockchainMockRecorder) Init() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockIBlockchain)(nil).Init))
+func (mr *MockIBlockchainMockRecorder) Init(ctx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockIBlockchain)(nil).Init), ctx)
+}
+
+// Start mocks base method
+func (m *MockIBlockchain) Start() error {
+	ret := m.ctrl.Call(m, "Start")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start
+func (mr *MockIBlockchainMockRecorder) Start() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockIBlockchain)(nil).Start))
+}
+
+// Stop mocks base method
+func (m *MockIBlockchain) Stop() error {
+	ret := m.ctrl.Call(m, "Stop")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop
+func (mr *MockIBlockchainMockRecorder) Stop() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockIBlockchain)(nil).Stop))
 }
 
 // Close mocks base method
@@ -111,6 +141,71 @@ func (mr *MockIBlockchainMockRecorder) GetBlockByHash(hash interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockByHash", reflect.TypeOf((*MockIBlockchain)(nil).GetBlockByHash), hash)
 }
 
+// GetBlocksByRange mocks base method
+func (m *MockIBlockchain) GetBlocksByRange(ctx context.Context, start, end uint32) ([]*blockchain.Block, error) {
+	ret := m.ctrl.Call(m, "GetBlocksByRange", ctx, start, end)
+	ret0, _ := ret[0].([]*blockchain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksByRange indicates an expected call of GetBlocksByRange
+func (mr *MockIBlockchainMockRecorder) GetBlocksByRange(ctx, start, end interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksByRange", reflect.TypeOf((*MockIBlockchain)(nil).GetBlocksByRange), ctx, start, end)
+}
+
+// NewBlockIterator mocks base method
+func (m *MockIBlockchain) NewBlockIterator(start, end uint32) (*blockchain.BlockIterator, error) {
+	ret := m.ctrl.Call(m, "NewBlockIterator", start, end)
+	ret0, _ := ret[0].(*blockchain.BlockIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewBlockIterator indicates an expected call of NewBlockIterator
+func (mr *MockIBlockchainMockRecorder) NewBlockIterator(start, end interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewBlockIterator", reflect.TypeOf((*MockIBlockchain)(nil).NewBlockIterator), start, end)
+}
+
+// GetBlockHeaderByHeight mocks base method
+func (m *MockIBlockchain) GetBlockHeaderByHeight(height uint32) (*blockchain.Block, error) {
+	ret := m.ctrl.Call(m, "GetBlockHeaderByHeight", height)
+	ret0, _ := ret[0].(*blockchain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockHeaderByHeight indicates an expected call of GetBlockHeaderByHeight
+func (mr *MockIBlockchainMockRecorder) GetBlockHeaderByHeight(height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockHeaderByHeight", reflect.TypeOf((*MockIBlockchain)(nil).GetBlockHeaderByHeight), height)
+}
+
+// GetBlockMetaByHeight mocks base method
+func (m *MockIBlockchain) GetBlockMetaByHeight(height uint32) (*blockchain.BlockMeta, error) {
+	ret := m.ctrl.Call(m, "GetBlockMetaByHeight", height)
+	ret0, _ := ret[0].(*blockchain.BlockMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockMetaByHeight indicates an expected call of GetBlockMetaByHeight
+func (mr *MockIBlockchainMockRecorder) GetBlockMetaByHeight(height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockMetaByHeight", reflect.TypeOf((*MockIBlockchain)(nil).GetBlockMetaByHeight), height)
+}
+
+// GetBlockMetaByHash mocks base method
+func (m *MockIBlockchain) GetBlockMetaByHash(hash crypto.Hash32B) (*blockchain.BlockMeta, error) {
+	ret := m.ctrl.Call(m, "GetBlockMetaByHash", hash)
+	ret0, _ := ret[0].(*blockchain.BlockMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockMetaByHash indicates an expected call of GetBlockMetaByHash
+func (mr *MockIBlockchainMockRecorder) GetBlockMetaByHash(hash interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockMetaByHash", reflect.TypeOf((*MockIBlockchain)(nil).GetBlockMetaByHash), hash)
+}
+
 // TipHash mocks base method
 func (m *MockIBlockchain) TipHash() crypto.Hash32B {
 	ret := m.ctrl.Call(m, "TipHash")
@@ -135,6 +230,18 @@ func (mr *MockIBlockchainMockRecorder) TipHeight() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TipHeight", reflect.TypeOf((*MockIBlockchain)(nil).TipHeight))
 }
 
+// ChainID mocks base method
+func (m *MockIBlockchain) ChainID() uint32 {
+	ret := m.ctrl.Call(m, "ChainID")
+	ret0, _ := ret[0].(uint32)
+	return ret0
+}
+
+// ChainID indicates an expected call of ChainID
+func (mr *MockIBlockchainMockRecorder) ChainID() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainID", reflect.TypeOf((*MockIBlockchain)(nil).ChainID))
+}
+
 // Reset mocks base method
 func (m *MockIBlockchain) Reset() {
 	m.ctrl.Call(m, "Reset")
@@ -158,10 +265,11 @@ func (mr *MockIBlockchainMockRecorder) ValidateBlock(blk interface{}) *gomock.Ca
 }
 
 // MintNewBlock mocks base method
-func (m *MockIBlockchain) MintNewBlock(arg0 []*blockchain.Tx, arg1, arg2 string) *blockchain.Block {
+func (m *MockIBlockchain) MintNewBlock(arg0 []*blockchain.Tx, arg1, arg2 string) (*blockchain.Block, error) {
 	ret := m.ctrl.Call(m, "MintNewBlock", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*blockchain.Block)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // MintNewBlock indicates an expected call of MintNewBlock
@@ -169,6 +277,84 @@ func (mr *MockIBlockchainMockRecorder) MintNewBlock(arg0, arg1, arg2 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MintNewBlock", reflect.TypeOf((*MockIBlockchain)(nil).MintNewBlock), arg0, arg1, arg2)
 }
 
+// MintNewBlockWithTransfers mocks base method
+func (m *MockIBlockchain) MintNewBlockWithTransfers(arg0 []*blockchain.Tx, arg1 []*blockchain.Transfer, arg2, arg3 string) (*blockchain.Block, error) {
+	ret := m.ctrl.Call(m, "MintNewBlockWithTransfers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*blockchain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MintNewBlockWithTransfers indicates an expected call of MintNewBlockWithTransfers
+func (mr *MockIBlockchainMockRecorder) MintNewBlockWithTransfers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MintNewBlockWithTransfers", reflect.TypeOf((*MockIBlockchain)(nil).MintNewBlockWithTransfers), arg0, arg1, arg2, arg3)
+}
+
+// MintNewBlockWithExecutions mocks base method
+func (m *MockIBlockchain) MintNewBlockWithExecutions(arg0 []*blockchain.Tx, arg1 []*blockchain.Transfer, arg2 []*blockchain.Execution, arg3, arg4 string) (*blockchain.Block, error) {
+	ret := m.ctrl.Call(m, "MintNewBlockWithExecutions", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*blockchain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MintNewBlockWithExecutions indicates an expected call of MintNewBlockWithExecutions
+func (mr *MockIBlockchainMockRecorder) MintNewBlockWithExecutions(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MintNewBlockWithExecutions", reflect.TypeOf((*MockIBlockchain)(nil).MintNewBlockWithExecutions), arg0, arg1, arg2, arg3, arg4)
+}
+
+// MintNewBlockWithVotes mocks base method
+func (m *MockIBlockchain) MintNewBlockWithVotes(arg0 []*blockchain.Tx, arg1 []*blockchain.Transfer, arg2 []*blockchain.Execution, arg3 []*blockchain.Vote, arg4, arg5 string) (*blockchain.Block, error) {
+	ret := m.ctrl.Call(m, "MintNewBlockWithVotes", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*blockchain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MintNewBlockWithVotes indicates an expected call of MintNewBlockWithVotes
+func (mr *MockIBlockchainMockRecorder) MintNewBlockWithVotes(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MintNewBlockWithVotes", reflect.TypeOf((*MockIBlockchain)(nil).MintNewBlockWithVotes), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// MintNewBlockWithEvidences mocks base method
+func (m *MockIBlockchain) MintNewBlockWithEvidences(arg0 []*blockchain.Tx, arg1 []*blockchain.Transfer, arg2 []*blockchain.Execution, arg3 []*blockchain.Vote, arg4 []*blockchain.Evidence, arg5, arg6 string) (*blockchain.Block, error) {
+	ret := m.ctrl.Call(m, "MintNewBlockWithEvidences", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(*blockchain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MintNewBlockWithEvidences indicates an expected call of MintNewBlockWithEvidences
+func (mr *MockIBlockchainMockRecorder) MintNewBlockWithEvidences(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MintNewBlockWithEvidences", reflect.TypeOf((*MockIBlockchain)(nil).MintNewBlockWithEvidences), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// MintNewBlockWithDeposits mocks base method
+func (m *MockIBlockchain) MintNewBlockWithDeposits(arg0 []*blockchain.Tx, arg1 []*blockchain.Transfer, arg2 []*blockchain.Execution, arg3 []*blockchain.Vote, arg4 []*blockchain.Evidence, arg5 []*blockchain.Deposit, arg6 []*blockchain.Withdraw, arg7, arg8 string) (*blockchain.Block, error) {
+	ret := m.ctrl.Call(m, "MintNewBlockWithDeposits", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+	ret0, _ := ret[0].(*blockchain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MintNewBlockWithDeposits indicates an expected call of MintNewBlockWithDeposits
+func (mr *MockIBlockchainMockRecorder) MintNewBlockWithDeposits(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MintNewBlockWithDeposits", reflect.TypeOf((*MockIBlockchain)(nil).MintNewBlockWithDeposits), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+}
+
+// MintNewBlockWithActions mocks base method
+func (m *MockIBlockchain) MintNewBlockWithActions(arg0 []*blockchain.Tx, arg1 []blockchain.Action, arg2, arg3 string) (*blockchain.Block, error) {
+	ret := m.ctrl.Call(m, "MintNewBlockWithActions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*blockchain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MintNewBlockWithActions indicates an expected call of MintNewBlockWithActions
+func (mr *MockIBlockchainMockRecorder) MintNewBlockWithActions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MintNewBlockWithActions", reflect.TypeOf((*MockIBlockchain)(nil).MintNewBlockWithActions), arg0, arg1, arg2, arg3)
+}
+
 // AddBlockCommit mocks base method
 func (m *MockIBlockchain) AddBlockCommit(blk *blockchain.Block) error {
 	ret := m.ctrl.Call(m, "AddBlockCommit", blk)
@@ -193,16 +379,311 @@ func (mr *MockIBlockchainMockRecorder) AddBlockSync(blk interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBlockSync", reflect.TypeOf((*MockIBlockchain)(nil).AddBlockSync), blk)
 }
 
+// ExportChain mocks base method
+func (m *MockIBlockchain) ExportChain(ctx context.Context, w io.Writer, start, end uint32) error {
+	ret := m.ctrl.Call(m, "ExportChain", ctx, w, start, end)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportChain indicates an expected call of ExportChain
+func (mr *MockIBlockchainMockRecorder) ExportChain(ctx, w, start, end interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportChain", reflect.TypeOf((*MockIBlockchain)(nil).ExportChain), ctx, w, start, end)
+}
+
+// ImportChain mocks base method
+func (m *MockIBlockchain) ImportChain(ctx context.Context, r io.Reader) error {
+	ret := m.ctrl.Call(m, "ImportChain", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportChain indicates an expected call of ImportChain
+func (mr *MockIBlockchainMockRecorder) ImportChain(ctx, r interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportChain", reflect.TypeOf((*MockIBlockchain)(nil).ImportChain), ctx, r)
+}
+
+// RollbackToHeight mocks base method
+func (m *MockIBlockchain) RollbackToHeight(ctx context.Context, height uint32) error {
+	ret := m.ctrl.Call(m, "RollbackToHeight", ctx, height)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackToHeight indicates an expected call of RollbackToHeight
+func (mr *MockIBlockchainMockRecorder) RollbackToHeight(ctx, height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackToHeight", reflect.TypeOf((*MockIBlockchain)(nil).RollbackToHeight), ctx, height)
+}
+
+// Finalize mocks base method
+func (m *MockIBlockchain) Finalize(height uint32, hash crypto.Hash32B) error {
+	ret := m.ctrl.Call(m, "Finalize", height, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Finalize indicates an expected call of Finalize
+func (mr *MockIBlockchainMockRecorder) Finalize(height, hash interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finalize", reflect.TypeOf((*MockIBlockchain)(nil).Finalize), height, hash)
+}
+
+// FinalizedHeight mocks base method
+func (m *MockIBlockchain) FinalizedHeight() uint32 {
+	ret := m.ctrl.Call(m, "FinalizedHeight")
+	ret0, _ := ret[0].(uint32)
+	return ret0
+}
+
+// FinalizedHeight indicates an expected call of FinalizedHeight
+func (mr *MockIBlockchainMockRecorder) FinalizedHeight() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinalizedHeight", reflect.TypeOf((*MockIBlockchain)(nil).FinalizedHeight))
+}
+
+// ReorgHalt mocks base method
+func (m *MockIBlockchain) ReorgHalt() *blockchain.ReorgHalt {
+	ret := m.ctrl.Call(m, "ReorgHalt")
+	ret0, _ := ret[0].(*blockchain.ReorgHalt)
+	return ret0
+}
+
+// ReorgHalt indicates an expected call of ReorgHalt
+func (mr *MockIBlockchainMockRecorder) ReorgHalt() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorgHalt", reflect.TypeOf((*MockIBlockchain)(nil).ReorgHalt))
+}
+
+// AllowReorg mocks base method
+func (m *MockIBlockchain) AllowReorg(hash crypto.Hash32B) {
+	m.ctrl.Call(m, "AllowReorg", hash)
+}
+
+// AllowReorg indicates an expected call of AllowReorg
+func (mr *MockIBlockchainMockRecorder) AllowReorg(hash interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowReorg", reflect.TypeOf((*MockIBlockchain)(nil).AllowReorg), hash)
+}
+
+// VerifyChain mocks base method
+func (m *MockIBlockchain) VerifyChain(ctx context.Context, depth uint32) error {
+	ret := m.ctrl.Call(m, "VerifyChain", ctx, depth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyChain indicates an expected call of VerifyChain
+func (mr *MockIBlockchainMockRecorder) VerifyChain(ctx, depth interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChain", reflect.TypeOf((*MockIBlockchain)(nil).VerifyChain), ctx, depth)
+}
+
 // BalanceOf mocks base method
-func (m *MockIBlockchain) BalanceOf(arg0 string) uint64 {
-	ret := m.ctrl.Call(m, "BalanceOf", arg0)
+func (m *MockIBlockchain) BalanceOf(address string, minConfirmations uint32) uint64 {
+	ret := m.ctrl.Call(m, "BalanceOf", address, minConfirmations)
 	ret0, _ := ret[0].(uint64)
 	return ret0
 }
 
 // BalanceOf indicates an expected call of BalanceOf
-func (mr *MockIBlockchainMockRecorder) BalanceOf(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalanceOf", reflect.TypeOf((*MockIBlockchain)(nil).BalanceOf), arg0)
+func (mr *MockIBlockchainMockRecorder) BalanceOf(address, minConfirmations interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalanceOf", reflect.TypeOf((*MockIBlockchain)(nil).BalanceOf), address, minConfirmations)
+}
+
+// BalanceOfAt mocks base method
+func (m *MockIBlockchain) BalanceOfAt(address string, height uint32) (uint64, error) {
+	ret := m.ctrl.Call(m, "BalanceOfAt", address, height)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BalanceOfAt indicates an expected call of BalanceOfAt
+func (mr *MockIBlockchainMockRecorder) BalanceOfAt(address, height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalanceOfAt", reflect.TypeOf((*MockIBlockchain)(nil).BalanceOfAt), address, height)
+}
+
+// AccountState mocks base method
+func (m *MockIBlockchain) AccountState(address string) *state.Account {
+	ret := m.ctrl.Call(m, "AccountState", address)
+	ret0, _ := ret[0].(*state.Account)
+	return ret0
+}
+
+// AccountState indicates an expected call of AccountState
+func (mr *MockIBlockchainMockRecorder) AccountState(address interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountState", reflect.TypeOf((*MockIBlockchain)(nil).AccountState), address)
+}
+
+// GetAccountAt mocks base method
+func (m *MockIBlockchain) GetAccountAt(address string, height uint32) (*state.Account, error) {
+	ret := m.ctrl.Call(m, "GetAccountAt", address, height)
+	ret0, _ := ret[0].(*state.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountAt indicates an expected call of GetAccountAt
+func (mr *MockIBlockchainMockRecorder) GetAccountAt(address, height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountAt", reflect.TypeOf((*MockIBlockchain)(nil).GetAccountAt), address, height)
+}
+
+// ContractState mocks base method
+func (m *MockIBlockchain) ContractState(address string, key []byte) []byte {
+	ret := m.ctrl.Call(m, "ContractState", address, key)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// ContractState indicates an expected call of ContractState
+func (mr *MockIBlockchainMockRecorder) ContractState(address, key interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContractState", reflect.TypeOf((*MockIBlockchain)(nil).ContractState), address, key)
+}
+
+// IsWithdrawn mocks base method
+func (m *MockIBlockchain) IsWithdrawn(depositHash crypto.Hash32B) (bool, error) {
+	ret := m.ctrl.Call(m, "IsWithdrawn", depositHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsWithdrawn indicates an expected call of IsWithdrawn
+func (mr *MockIBlockchainMockRecorder) IsWithdrawn(depositHash interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsWithdrawn", reflect.TypeOf((*MockIBlockchain)(nil).IsWithdrawn), depositHash)
+}
+
+// GetReceipt mocks base method
+func (m *MockIBlockchain) GetReceipt(hash crypto.Hash32B) (*blockchain.Receipt, error) {
+	ret := m.ctrl.Call(m, "GetReceipt", hash)
+	ret0, _ := ret[0].(*blockchain.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceipt indicates an expected call of GetReceipt
+func (mr *MockIBlockchainMockRecorder) GetReceipt(hash interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceipt", reflect.TypeOf((*MockIBlockchain)(nil).GetReceipt), hash)
+}
+
+// GetLogs mocks base method
+func (m *MockIBlockchain) GetLogs(ctx context.Context, start, end uint32, filter *blockchain.LogFilter) ([]*blockchain.Log, error) {
+	ret := m.ctrl.Call(m, "GetLogs", ctx, start, end, filter)
+	ret0, _ := ret[0].([]*blockchain.Log)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLogs indicates an expected call of GetLogs
+func (mr *MockIBlockchainMockRecorder) GetLogs(ctx, start, end, filter interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogs", reflect.TypeOf((*MockIBlockchain)(nil).GetLogs), ctx, start, end, filter)
+}
+
+// EpochOf mocks base method
+func (m *MockIBlockchain) EpochOf(height uint32) uint32 {
+	ret := m.ctrl.Call(m, "EpochOf", height)
+	ret0, _ := ret[0].(uint32)
+	return ret0
+}
+
+// EpochOf indicates an expected call of EpochOf
+func (mr *MockIBlockchainMockRecorder) EpochOf(height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EpochOf", reflect.TypeOf((*MockIBlockchain)(nil).EpochOf), height)
+}
+
+// GetCandidates mocks base method
+func (m *MockIBlockchain) GetCandidates() []*election.Candidate {
+	ret := m.ctrl.Call(m, "GetCandidates")
+	ret0, _ := ret[0].([]*election.Candidate)
+	return ret0
+}
+
+// GetCandidates indicates an expected call of GetCandidates
+func (mr *MockIBlockchainMockRecorder) GetCandidates() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCandidates", reflect.TypeOf((*MockIBlockchain)(nil).GetCandidates))
+}
+
+// GetDelegatesByEpoch mocks base method
+func (m *MockIBlockchain) GetDelegatesByEpoch(epoch uint32) ([]*election.Candidate, error) {
+	ret := m.ctrl.Call(m, "GetDelegatesByEpoch", epoch)
+	ret0, _ := ret[0].([]*election.Candidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelegatesByEpoch indicates an expected call of GetDelegatesByEpoch
+func (mr *MockIBlockchainMockRecorder) GetDelegatesByEpoch(epoch interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelegatesByEpoch", reflect.TypeOf((*MockIBlockchain)(nil).GetDelegatesByEpoch), epoch)
+}
+
+// ListUnspent mocks base method
+func (m *MockIBlockchain) ListUnspent(address string, minConf, maxConf, offset, limit uint32) ([]*blockchain.Unspent, error) {
+	ret := m.ctrl.Call(m, "ListUnspent", address, minConf, maxConf, offset, limit)
+	ret0, _ := ret[0].([]*blockchain.Unspent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnspent indicates an expected call of ListUnspent
+func (mr *MockIBlockchainMockRecorder) ListUnspent(address, minConf, maxConf, offset, limit interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnspent", reflect.TypeOf((*MockIBlockchain)(nil).ListUnspent), address, minConf, maxConf, offset, limit)
+}
+
+// ListUnspentAt mocks base method
+func (m *MockIBlockchain) ListUnspentAt(address string, height, offset, limit uint32) ([]*blockchain.Unspent, error) {
+	ret := m.ctrl.Call(m, "ListUnspentAt", address, height, offset, limit)
+	ret0, _ := ret[0].([]*blockchain.Unspent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnspentAt indicates an expected call of ListUnspentAt
+func (mr *MockIBlockchainMockRecorder) ListUnspentAt(address, height, offset, limit interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnspentAt", reflect.TypeOf((*MockIBlockchain)(nil).ListUnspentAt), address, height, offset, limit)
+}
+
+// UtxoCommitment mocks base method
+func (m *MockIBlockchain) UtxoCommitment(height uint32) (crypto.Hash32B, error) {
+	ret := m.ctrl.Call(m, "UtxoCommitment", height)
+	ret0, _ := ret[0].(crypto.Hash32B)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UtxoCommitment indicates an expected call of UtxoCommitment
+func (mr *MockIBlockchainMockRecorder) UtxoCommitment(height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UtxoCommitment", reflect.TypeOf((*MockIBlockchain)(nil).UtxoCommitment), height)
+}
+
+// ChainMeta mocks base method
+func (m *MockIBlockchain) ChainMeta() *blockchain.ChainMeta {
+	ret := m.ctrl.Call(m, "ChainMeta")
+	ret0, _ := ret[0].(*blockchain.ChainMeta)
+	return ret0
+}
+
+// ChainMeta indicates an expected call of ChainMeta
+func (mr *MockIBlockchainMockRecorder) ChainMeta() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainMeta", reflect.TypeOf((*MockIBlockchain)(nil).ChainMeta))
+}
+
+// TopHolders mocks base method
+func (m *MockIBlockchain) TopHolders(n uint32) []*blockchain.Holder {
+	ret := m.ctrl.Call(m, "TopHolders", n)
+	ret0, _ := ret[0].([]*blockchain.Holder)
+	return ret0
+}
+
+// TopHolders indicates an expected call of TopHolders
+func (mr *MockIBlockchainMockRecorder) TopHolders(n interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopHolders", reflect.TypeOf((*MockIBlockchain)(nil).TopHolders), n)
+}
+
+// CirculatingSupply mocks base method
+func (m *MockIBlockchain) CirculatingSupply() uint64 {
+	ret := m.ctrl.Call(m, "CirculatingSupply")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// CirculatingSupply indicates an expected call of CirculatingSupply
+func (mr *MockIBlockchainMockRecorder) CirculatingSupply() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CirculatingSupply", reflect.TypeOf((*MockIBlockchain)(nil).CirculatingSupply))
 }
 
 // UtxoPool mocks base method
@@ -217,11 +698,85 @@ func (mr *MockIBlockchainMockRecorder) UtxoPool() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UtxoPool", reflect.TypeOf((*MockIBlockchain)(nil).UtxoPool))
 }
 
+// NewUtxoView mocks base method
+func (m *MockIBlockchain) NewUtxoView() *blockchain.UtxoView {
+	ret := m.ctrl.Call(m, "NewUtxoView")
+	ret0, _ := ret[0].(*blockchain.UtxoView)
+	return ret0
+}
+
+// NewUtxoView indicates an expected call of NewUtxoView
+func (mr *MockIBlockchainMockRecorder) NewUtxoView() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewUtxoView", reflect.TypeOf((*MockIBlockchain)(nil).NewUtxoView))
+}
+
+// ValidateCoinbaseMaturity mocks base method
+func (m *MockIBlockchain) ValidateCoinbaseMaturity(tx *blockchain.Tx) error {
+	ret := m.ctrl.Call(m, "ValidateCoinbaseMaturity", tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateCoinbaseMaturity indicates an expected call of ValidateCoinbaseMaturity
+func (mr *MockIBlockchainMockRecorder) ValidateCoinbaseMaturity(tx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateCoinbaseMaturity", reflect.TypeOf((*MockIBlockchain)(nil).ValidateCoinbaseMaturity), tx)
+}
+
+// ValidateLockTime mocks base method
+func (m *MockIBlockchain) ValidateLockTime(tx *blockchain.Tx) error {
+	ret := m.ctrl.Call(m, "ValidateLockTime", tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateLockTime indicates an expected call of ValidateLockTime
+func (mr *MockIBlockchainMockRecorder) ValidateLockTime(tx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateLockTime", reflect.TypeOf((*MockIBlockchain)(nil).ValidateLockTime), tx)
+}
+
+// CheckTransaction mocks base method
+func (m *MockIBlockchain) CheckTransaction(tx *blockchain.Tx) error {
+	ret := m.ctrl.Call(m, "CheckTransaction", tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckTransaction indicates an expected call of CheckTransaction
+func (mr *MockIBlockchainMockRecorder) CheckTransaction(tx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckTransaction", reflect.TypeOf((*MockIBlockchain)(nil).CheckTransaction), tx)
+}
+
+// ValidateTxChainID mocks base method
+func (m *MockIBlockchain) ValidateTxChainID(tx *blockchain.Tx) error {
+	ret := m.ctrl.Call(m, "ValidateTxChainID", tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateTxChainID indicates an expected call of ValidateTxChainID
+func (mr *MockIBlockchainMockRecorder) ValidateTxChainID(tx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateTxChainID", reflect.TypeOf((*MockIBlockchain)(nil).ValidateTxChainID), tx)
+}
+
+// EstimateFee mocks base method
+func (m *MockIBlockchain) EstimateFee(targetBlocks uint32) (uint64, error) {
+	ret := m.ctrl.Call(m, "EstimateFee", targetBlocks)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateFee indicates an expected call of EstimateFee
+func (mr *MockIBlockchainMockRecorder) EstimateFee(targetBlocks interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateFee", reflect.TypeOf((*MockIBlockchain)(nil).EstimateFee), targetBlocks)
+}
+
 // CreateTransaction mocks base method
-func (m *MockIBlockchain) CreateTransaction(from iotxaddress.Address, amount uint64, to []*blockchain.Payee) *blockchain.Tx {
+func (m *MockIBlockchain) CreateTransaction(from wallet.Signer, amount uint64, to []*blockchain.Payee) (*blockchain.Tx, error) {
 	ret := m.ctrl.Call(m, "CreateTransaction", from, amount, to)
 	ret0, _ := ret[0].(*blockchain.Tx)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateTransaction indicates an expected call of CreateTransaction
@@ -229,14 +784,69 @@ func (mr *MockIBlockchainMockRecorder) CreateTransaction(from, amount, to interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockIBlockchain)(nil).CreateTransaction), from, amount, to)
 }
 
+// ReserveTxInputs mocks base method
+func (m *MockIBlockchain) ReserveTxInputs(tx *blockchain.Tx, ttl time.Duration) {
+	m.ctrl.Call(m, "ReserveTxInputs", tx, ttl)
+}
+
+// ReserveTxInputs indicates an expected call of ReserveTxInputs
+func (mr *MockIBlockchainMockRecorder) ReserveTxInputs(tx, ttl interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveTxInputs", reflect.TypeOf((*MockIBlockchain)(nil).ReserveTxInputs), tx, ttl)
+}
+
+// ReleaseTxInputs mocks base method
+func (m *MockIBlockchain) ReleaseTxInputs(tx *blockchain.Tx) {
+	m.ctrl.Call(m, "ReleaseTxInputs", tx)
+}
+
+// ReleaseTxInputs indicates an expected call of ReleaseTxInputs
+func (mr *MockIBlockchainMockRecorder) ReleaseTxInputs(tx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseTxInputs", reflect.TypeOf((*MockIBlockchain)(nil).ReleaseTxInputs), tx)
+}
+
 // CreateRawTransaction mocks base method
-func (m *MockIBlockchain) CreateRawTransaction(from iotxaddress.Address, amount uint64, to []*blockchain.Payee) *blockchain.Tx {
+func (m *MockIBlockchain) CreateRawTransaction(from iotxaddress.Address, amount uint64, to []*blockchain.Payee) (*blockchain.Tx, error) {
 	ret := m.ctrl.Call(m, "CreateRawTransaction", from, amount, to)
 	ret0, _ := ret[0].(*blockchain.Tx)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateRawTransaction indicates an expected call of CreateRawTransaction
 func (mr *MockIBlockchainMockRecorder) CreateRawTransaction(from, amount, to interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRawTransaction", reflect.TypeOf((*MockIBlockchain)(nil).CreateRawTransaction), from, amount, to)
 }
+
+// SignTransaction mocks base method
+func (m *MockIBlockchain) SignTransaction(tx *blockchain.Tx, signer wallet.Signer) error {
+	ret := m.ctrl.Call(m, "SignTransaction", tx, signer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SignTransaction indicates an expected call of SignTransaction
+func (mr *MockIBlockchainMockRecorder) SignTransaction(tx, signer interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignTransaction", reflect.TypeOf((*MockIBlockchain)(nil).SignTransaction), tx, signer)
+}
+
+// Subscribe mocks base method
+func (m *MockIBlockchain) Subscribe() <-chan *blockchain.BlockEvent {
+	ret := m.ctrl.Call(m, "Subscribe")
+	ret0, _ := ret[0].(<-chan *blockchain.BlockEvent)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe
+func (mr *MockIBlockchainMockRecorder) Subscribe() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockIBlockchain)(nil).Subscribe))
+}
+
+// Unsubscribe mocks base method
+func (m *MockIBlockchain) Unsubscribe(ch <-chan *blockchain.BlockEvent) {
+	m.ctrl.Call(m, "Unsubscribe", ch)
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe
+func (mr *MockIBlockchainMockRecorder) Unsubscribe(ch interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockIBlockchain)(nil).Unsubscribe), ch)
+}
@@ -9,6 +9,7 @@ import (
 	blockchain "github.com/iotexproject/iotex-core/blockchain"
 	network "github.com/iotexproject/iotex-core/network"
 	proto "github.com/iotexproject/iotex-core/proto"
+	context "golang.org/x/net/context"
 	reflect "reflect"
 )
 
@@ -72,15 +73,15 @@ func (mr *MockBlockSyncMockRecorder) P2P() *gomock.Call {
 }
 
 // ProcessSyncRequest mocks base method
-func (m *MockBlockSync) ProcessSyncRequest(sender string, sync *proto.BlockSync) error {
-	ret := m.ctrl.Call(m, "ProcessSyncRequest", sender, sync)
+func (m *MockBlockSync) ProcessSyncRequest(ctx context.Context, sender string, sync *proto.BlockSync) error {
+	ret := m.ctrl.Call(m, "ProcessSyncRequest", ctx, sender, sync)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ProcessSyncRequest indicates an expected call of ProcessSyncRequest
-func (mr *MockBlockSyncMockRecorder) ProcessSyncRequest(sender, sync interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSyncRequest", reflect.TypeOf((*MockBlockSync)(nil).ProcessSyncRequest), sender, sync)
+func (mr *MockBlockSyncMockRecorder) ProcessSyncRequest(ctx, sender, sync interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSyncRequest", reflect.TypeOf((*MockBlockSync)(nil).ProcessSyncRequest), ctx, sender, sync)
 }
 
 // ProcessBlock mocks base method
@@ -106,3 +107,73 @@ func (m *MockBlockSync) ProcessBlockSync(blk *blockchain.Block) error {
 func (mr *MockBlockSyncMockRecorder) ProcessBlockSync(blk interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBlockSync", reflect.TypeOf((*MockBlockSync)(nil).ProcessBlockSync), blk)
 }
+
+// ProcessHeaderSyncRequest mocks base method
+func (m *MockBlockSync) ProcessHeaderSyncRequest(ctx context.Context, sender string, sync *proto.BlockHeaderSync) error {
+	ret := m.ctrl.Call(m, "ProcessHeaderSyncRequest", ctx, sender, sync)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessHeaderSyncRequest indicates an expected call of ProcessHeaderSyncRequest
+func (mr *MockBlockSyncMockRecorder) ProcessHeaderSyncRequest(ctx, sender, sync interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessHeaderSyncRequest", reflect.TypeOf((*MockBlockSync)(nil).ProcessHeaderSyncRequest), ctx, sender, sync)
+}
+
+// ProcessHeaders mocks base method
+func (m *MockBlockSync) ProcessHeaders(headers *proto.BlockHeaderContainer) error {
+	ret := m.ctrl.Call(m, "ProcessHeaders", headers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessHeaders indicates an expected call of ProcessHeaders
+func (mr *MockBlockSyncMockRecorder) ProcessHeaders(headers interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessHeaders", reflect.TypeOf((*MockBlockSync)(nil).ProcessHeaders), headers)
+}
+
+// ProcessCompactBlock mocks base method
+func (m *MockBlockSync) ProcessCompactBlock(cb *proto.CompactBlockPb) error {
+	ret := m.ctrl.Call(m, "ProcessCompactBlock", cb)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessCompactBlock indicates an expected call of ProcessCompactBlock
+func (mr *MockBlockSyncMockRecorder) ProcessCompactBlock(cb interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessCompactBlock", reflect.TypeOf((*MockBlockSync)(nil).ProcessCompactBlock), cb)
+}
+
+// ProcessBlockTxsSyncRequest mocks base method
+func (m *MockBlockSync) ProcessBlockTxsSyncRequest(sender string, sync *proto.BlockTxsSync) error {
+	ret := m.ctrl.Call(m, "ProcessBlockTxsSyncRequest", sender, sync)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessBlockTxsSyncRequest indicates an expected call of ProcessBlockTxsSyncRequest
+func (mr *MockBlockSyncMockRecorder) ProcessBlockTxsSyncRequest(sender, sync interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBlockTxsSyncRequest", reflect.TypeOf((*MockBlockSync)(nil).ProcessBlockTxsSyncRequest), sender, sync)
+}
+
+// ProcessBlockTxs mocks base method
+func (m *MockBlockSync) ProcessBlockTxs(txs *proto.BlockTxsContainer) error {
+	ret := m.ctrl.Call(m, "ProcessBlockTxs", txs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessBlockTxs indicates an expected call of ProcessBlockTxs
+func (mr *MockBlockSyncMockRecorder) ProcessBlockTxs(txs interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBlockTxs", reflect.TypeOf((*MockBlockSync)(nil).ProcessBlockTxs), txs)
+}
+
+// Resync mocks base method
+func (m *MockBlockSync) Resync(addr string, height uint32) {
+	m.ctrl.Call(m, "Resync", addr, height)
+}
+
+// Resync indicates an expected call of Resync
+func (mr *MockBlockSyncMockRecorder) Resync(addr, height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resync", reflect.TypeOf((*MockBlockSync)(nil).Resync), addr, height)
+}
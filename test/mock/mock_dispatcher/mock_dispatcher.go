@@ -59,13 +59,13 @@ func (mr *MockDispatcherMockRecorder) Stop() *gomock.Call {
 }
 
 // HandleBroadcast mocks base method
-func (m *MockDispatcher) HandleBroadcast(arg0 proto.Message, arg1 chan bool) {
-	m.ctrl.Call(m, "HandleBroadcast", arg0, arg1)
+func (m *MockDispatcher) HandleBroadcast(arg0 net.Addr, arg1 proto.Message, arg2 chan bool) {
+	m.ctrl.Call(m, "HandleBroadcast", arg0, arg1, arg2)
 }
 
 // HandleBroadcast indicates an expected call of HandleBroadcast
-func (mr *MockDispatcherMockRecorder) HandleBroadcast(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleBroadcast", reflect.TypeOf((*MockDispatcher)(nil).HandleBroadcast), arg0, arg1)
+func (mr *MockDispatcherMockRecorder) HandleBroadcast(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleBroadcast", reflect.TypeOf((*MockDispatcher)(nil).HandleBroadcast), arg0, arg1, arg2)
 }
 
 // HandleTell mocks base method
@@ -7,6 +7,7 @@ package mock_txpool
 import (
 	gomock "github.com/golang/mock/gomock"
 	blockchain "github.com/iotexproject/iotex-core/blockchain"
+	config "github.com/iotexproject/iotex-core/config"
 	crypto "github.com/iotexproject/iotex-core/crypto"
 	txpool "github.com/iotexproject/iotex-core/txpool"
 	reflect "reflect"
@@ -36,6 +37,30 @@ func (m *MockTxPool) EXPECT() *MockTxPoolMockRecorder {
 	return m.recorder
 }
 
+// Start mocks base method
+func (m *MockTxPool) Start() error {
+	ret := m.ctrl.Call(m, "Start")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start
+func (mr *MockTxPoolMockRecorder) Start() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockTxPool)(nil).Start))
+}
+
+// Stop mocks base method
+func (m *MockTxPool) Stop() error {
+	ret := m.ctrl.Call(m, "Stop")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop
+func (mr *MockTxPoolMockRecorder) Stop() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockTxPool)(nil).Stop))
+}
+
 // RemoveOrphanTx mocks base method
 func (m *MockTxPool) RemoveOrphanTx(tx *blockchain.Tx) {
 	m.ctrl.Call(m, "RemoveOrphanTx", tx)
@@ -201,3 +226,170 @@ func (m *MockTxPool) LastTimePoolUpdated() time.Time {
 func (mr *MockTxPoolMockRecorder) LastTimePoolUpdated() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastTimePoolUpdated", reflect.TypeOf((*MockTxPool)(nil).LastTimePoolUpdated))
 }
+
+// PendingBalanceOf mocks base method
+func (m *MockTxPool) PendingBalanceOf(arg0 string) uint64 {
+	ret := m.ctrl.Call(m, "PendingBalanceOf", arg0)
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// PendingBalanceOf indicates an expected call of PendingBalanceOf
+func (mr *MockTxPoolMockRecorder) PendingBalanceOf(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingBalanceOf", reflect.TypeOf((*MockTxPool)(nil).PendingBalanceOf), arg0)
+}
+
+// PendingByAddress mocks base method
+func (m *MockTxPool) PendingByAddress(address string) []*txpool.TxDesc {
+	ret := m.ctrl.Call(m, "PendingByAddress", address)
+	ret0, _ := ret[0].([]*txpool.TxDesc)
+	return ret0
+}
+
+// PendingByAddress indicates an expected call of PendingByAddress
+func (mr *MockTxPoolMockRecorder) PendingByAddress(address interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingByAddress", reflect.TypeOf((*MockTxPool)(nil).PendingByAddress), address)
+}
+
+// AncestorPackage mocks base method
+func (m *MockTxPool) AncestorPackage(hash crypto.Hash32B) (*txpool.TxPackage, error) {
+	ret := m.ctrl.Call(m, "AncestorPackage", hash)
+	ret0, _ := ret[0].(*txpool.TxPackage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AncestorPackage indicates an expected call of AncestorPackage
+func (mr *MockTxPoolMockRecorder) AncestorPackage(hash interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AncestorPackage", reflect.TypeOf((*MockTxPool)(nil).AncestorPackage), hash)
+}
+
+// DescendantPackage mocks base method
+func (m *MockTxPool) DescendantPackage(hash crypto.Hash32B) (*txpool.TxPackage, error) {
+	ret := m.ctrl.Call(m, "DescendantPackage", hash)
+	ret0, _ := ret[0].(*txpool.TxPackage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescendantPackage indicates an expected call of DescendantPackage
+func (mr *MockTxPoolMockRecorder) DescendantPackage(hash interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescendantPackage", reflect.TypeOf((*MockTxPool)(nil).DescendantPackage), hash)
+}
+
+// Conflicts mocks base method
+func (m *MockTxPool) Conflicts(tx *blockchain.Tx) *txpool.TxPackage {
+	ret := m.ctrl.Call(m, "Conflicts", tx)
+	ret0, _ := ret[0].(*txpool.TxPackage)
+	return ret0
+}
+
+// Conflicts indicates an expected call of Conflicts
+func (mr *MockTxPoolMockRecorder) Conflicts(tx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Conflicts", reflect.TypeOf((*MockTxPool)(nil).Conflicts), tx)
+}
+
+// PendingFees mocks base method
+func (m *MockTxPool) PendingFees() []blockchain.FeeSample {
+	ret := m.ctrl.Call(m, "PendingFees")
+	ret0, _ := ret[0].([]blockchain.FeeSample)
+	return ret0
+}
+
+// PendingFees indicates an expected call of PendingFees
+func (mr *MockTxPoolMockRecorder) PendingFees() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingFees", reflect.TypeOf((*MockTxPool)(nil).PendingFees))
+}
+
+// AcceptTransaction mocks base method
+func (m *MockTxPool) AcceptTransaction(tx *blockchain.Tx) (*txpool.TxDesc, error) {
+	ret := m.ctrl.Call(m, "AcceptTransaction", tx)
+	ret0, _ := ret[0].(*txpool.TxDesc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptTransaction indicates an expected call of AcceptTransaction
+func (mr *MockTxPoolMockRecorder) AcceptTransaction(tx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptTransaction", reflect.TypeOf((*MockTxPool)(nil).AcceptTransaction), tx)
+}
+
+// ValidateTransaction mocks base method
+func (m *MockTxPool) ValidateTransaction(tx *blockchain.Tx) *txpool.TxVerdict {
+	ret := m.ctrl.Call(m, "ValidateTransaction", tx)
+	ret0, _ := ret[0].(*txpool.TxVerdict)
+	return ret0
+}
+
+// ValidateTransaction indicates an expected call of ValidateTransaction
+func (mr *MockTxPoolMockRecorder) ValidateTransaction(tx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateTransaction", reflect.TypeOf((*MockTxPool)(nil).ValidateTransaction), tx)
+}
+
+// AcceptAction mocks base method
+func (m *MockTxPool) AcceptAction(act blockchain.Action) error {
+	ret := m.ctrl.Call(m, "AcceptAction", act)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcceptAction indicates an expected call of AcceptAction
+func (mr *MockTxPoolMockRecorder) AcceptAction(act interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptAction", reflect.TypeOf((*MockTxPool)(nil).AcceptAction), act)
+}
+
+// PendingNonce mocks base method
+func (m *MockTxPool) PendingNonce(address string) uint64 {
+	ret := m.ctrl.Call(m, "PendingNonce", address)
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// PendingNonce indicates an expected call of PendingNonce
+func (mr *MockTxPoolMockRecorder) PendingNonce(address interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingNonce", reflect.TypeOf((*MockTxPool)(nil).PendingNonce), address)
+}
+
+// Actions mocks base method
+func (m *MockTxPool) Actions() []blockchain.Action {
+	ret := m.ctrl.Call(m, "Actions")
+	ret0, _ := ret[0].([]blockchain.Action)
+	return ret0
+}
+
+// Actions indicates an expected call of Actions
+func (mr *MockTxPoolMockRecorder) Actions() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Actions", reflect.TypeOf((*MockTxPool)(nil).Actions))
+}
+
+// SetLimits mocks base method
+func (m *MockTxPool) SetLimits(cfg config.TxPool) {
+	m.ctrl.Call(m, "SetLimits", cfg)
+}
+
+// SetLimits indicates an expected call of SetLimits
+func (mr *MockTxPoolMockRecorder) SetLimits(cfg interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimits", reflect.TypeOf((*MockTxPool)(nil).SetLimits), cfg)
+}
+
+// Subscribe mocks base method
+func (m *MockTxPool) Subscribe() <-chan *txpool.TxDesc {
+	ret := m.ctrl.Call(m, "Subscribe")
+	ret0, _ := ret[0].(<-chan *txpool.TxDesc)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe
+func (mr *MockTxPoolMockRecorder) Subscribe() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockTxPool)(nil).Subscribe))
+}
+
+// Unsubscribe mocks base method
+func (m *MockTxPool) Unsubscribe(ch <-chan *txpool.TxDesc) {
+	m.ctrl.Call(m, "Unsubscribe", ch)
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe
+func (mr *MockTxPoolMockRecorder) Unsubscribe(ch interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockTxPool)(nil).Unsubscribe), ch)
+}
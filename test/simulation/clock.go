@@ -0,0 +1,113 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Clock is the fake clock of a simulation, which only moves when it is advanced, running the events scheduled up to
+// the new time in the order of their times, and of their scheduling for the same time
+// The events run on the goroutine advancing the clock, so a simulation is deterministic. A clock is not safe for
+// concurrent use.
+type Clock struct {
+	now    time.Time
+	seq    uint64
+	events eventQueue
+}
+
+// NewClock returns a clock starting at the time
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the current time of the clock, which is the time of the event running while the clock is advanced
+func (c *Clock) Now() time.Time {
+	return c.now
+}
+
+// Schedule schedules fn to run once the clock is advanced by d from now, a negative d being taken as 0
+func (c *Clock) Schedule(d time.Duration, fn func()) {
+	if d < 0 {
+		d = 0
+	}
+	c.seq++
+	heap.Push(&c.events, &event{at: c.now.Add(d), seq: c.seq, fn: fn})
+}
+
+// Advance moves the clock forward by d, running the events scheduled up to the new time including the ones scheduled
+// by the events themselves
+func (c *Clock) Advance(d time.Duration) {
+	end := c.now.Add(d)
+	for len(c.events) > 0 && !c.events[0].at.After(end) {
+		c.runNext()
+	}
+	c.now = end
+}
+
+// Step moves the clock to the next event and runs it, and returns false if no event is scheduled
+func (c *Clock) Step() bool {
+	if len(c.events) == 0 {
+		return false
+	}
+	c.runNext()
+	return true
+}
+
+// StepUntil runs the next event if it is scheduled at or before the deadline, and returns whether it is
+func (c *Clock) StepUntil(deadline time.Time) bool {
+	if len(c.events) == 0 || c.events[0].at.After(deadline) {
+		return false
+	}
+	c.runNext()
+	return true
+}
+
+// Pending returns the number of events scheduled
+func (c *Clock) Pending() int {
+	return len(c.events)
+}
+
+// runNext runs the earliest event at its time
+func (c *Clock) runNext() {
+	e := heap.Pop(&c.events).(*event)
+	if e.at.After(c.now) {
+		c.now = e.at
+	}
+	e.fn()
+}
+
+// event is a function scheduled to run at a time, seq ordering the events of the same time
+type event struct {
+	at  time.Time
+	seq uint64
+	fn  func()
+}
+
+// eventQueue is the min-heap of the scheduled events
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*event)) }
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
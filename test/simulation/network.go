@@ -0,0 +1,97 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"time"
+)
+
+// link is the direction of the messages from a node to another
+type link struct {
+	from string
+	to   string
+}
+
+// Network is the in-memory transport of a simulation, delivering the messages between the nodes on the clock after
+// the latency of their link
+// The network can be partitioned into groups of nodes, the messages between the groups being dropped until it is
+// healed.
+type Network struct {
+	clock   *Clock
+	latency time.Duration
+	// links are the latencies set for the links, overriding the default one
+	links map[link]time.Duration
+	// group is the group of each node while the network is partitioned, nil if it is not
+	group map[string]int
+	// sent and dropped are the numbers of messages sent and dropped
+	sent    int
+	dropped int
+}
+
+// NewNetwork returns a network delivering the messages on the clock after the default latency
+func NewNetwork(clock *Clock, latency time.Duration) *Network {
+	return &Network{clock: clock, latency: latency, links: make(map[link]time.Duration)}
+}
+
+// SetLatency sets the latency of the messages from a node to another, which is the default one unless it is set
+func (n *Network) SetLatency(from string, to string, d time.Duration) {
+	n.links[link{from, to}] = d
+}
+
+// Latency returns the latency of the messages from a node to another
+func (n *Network) Latency(from string, to string) time.Duration {
+	if d, ok := n.links[link{from, to}]; ok {
+		return d
+	}
+	return n.latency
+}
+
+// Partition splits the network into the groups of nodes, dropping the messages between the groups, the nodes in no
+// group being cut off from all the others
+func (n *Network) Partition(groups ...[]string) {
+	n.group = make(map[string]int)
+	for i, names := range groups {
+		for _, name := range names {
+			n.group[name] = i + 1
+		}
+	}
+}
+
+// Heal removes the partition, the messages sent before being dropped still
+func (n *Network) Heal() {
+	n.group = nil
+}
+
+// Connected returns whether the messages from a node reach the other
+func (n *Network) Connected(from string, to string) bool {
+	if n.group == nil {
+		return true
+	}
+	return n.group[from] != 0 && n.group[from] == n.group[to]
+}
+
+// Send schedules delivering the message from a node to the other after the latency of their link, unless the network
+// is partitioned between them at the time it is sent or delivered
+func (n *Network) Send(from string, to string, deliver func()) {
+	n.sent++
+	if !n.Connected(from, to) {
+		n.dropped++
+		return
+	}
+	n.clock.Schedule(n.Latency(from, to), func() {
+		if !n.Connected(from, to) {
+			n.dropped++
+			return
+		}
+		deliver()
+	})
+}
+
+// Stats returns the numbers of messages sent and dropped
+func (n *Network) Stats() (sent int, dropped int) {
+	return n.sent, n.dropped
+}
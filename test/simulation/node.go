@@ -0,0 +1,226 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+// locatorDenseEntries is the number of the most recent heights a sync locator lists one by one, before doubling the
+// step between the heights down to the genesis block
+const locatorDenseEntries = 10
+
+// locatorEntry is the hash of the block at a height of the chain requesting a sync
+type locatorEntry struct {
+	height uint32
+	hash   cp.Hash32B
+}
+
+// Node is a node of a simulation, whose chain is kept in memory and mints its blocks at the time of the fake clock
+// A node relays the blocks extending its tip to the other nodes, and syncs the chain of a node sending a block beyond
+// its tip from their last common block, rolling back its own blocks after it, so the nodes converge on the longest
+// chain. A chain of the same length as the tip is not adopted, the first block seen at a height being kept.
+type Node struct {
+	Name  string
+	Chain *blockchain.Blockchain
+	// Address is the address the block rewards of the blocks minted by the node are paid to
+	Address string
+
+	sim *Simulation
+	log *logger.Logger
+}
+
+// Produce mints a block on top of the tip, commits it and sends it to the other nodes
+// The coinbase data names the node and the height, so the blocks minted at the same time on the same parent by
+// different nodes differ.
+func (n *Node) Produce() (*blockchain.Block, error) {
+	data := fmt.Sprintf("%s/%d", n.Name, n.Chain.TipHeight()+1)
+	blk, err := n.Chain.MintNewBlock(nil, n.Address, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.Chain.AddBlockCommit(blk); err != nil {
+		return nil, err
+	}
+	n.log.WithFields(logger.Fields{"height": blk.Height(), "hash": blk.HashBlock()}).Debug("Produced block")
+	n.broadcast(blk, "")
+	return blk, nil
+}
+
+// Announce sends the tip block to the other nodes, which sync the chain of the node if it is longer than theirs
+func (n *Node) Announce() error {
+	blk, err := n.Chain.GetBlockByHash(n.Chain.TipHash())
+	if err != nil {
+		return err
+	}
+	n.broadcast(blk, "")
+	return nil
+}
+
+// broadcast sends the block to the other nodes except the one it is received from
+func (n *Node) broadcast(blk *blockchain.Block, except string) {
+	data, err := blk.Serialize()
+	if err != nil {
+		n.fail(err)
+		return
+	}
+	for _, peer := range n.sim.nodes {
+		if peer == n || peer.Name == except {
+			continue
+		}
+		peer := peer
+		n.sim.Net.Send(n.Name, peer.Name, func() {
+			peer.receiveBlock(n.Name, data)
+		})
+	}
+}
+
+// receiveBlock commits the block sent by the node if it extends the tip, and syncs the chain of the node if the block
+// is beyond the tip on another branch
+func (n *Node) receiveBlock(from string, data []byte) {
+	blk := &blockchain.Block{}
+	if err := blk.Deserialize(data); err != nil {
+		n.fail(errors.Wrapf(err, "block from %s", from))
+		return
+	}
+	if _, err := n.Chain.GetHeightByHash(blk.HashBlock()); err == nil {
+		return
+	}
+	if blk.PrevHash() == n.Chain.TipHash() {
+		if err := n.Chain.AddBlockCommit(blk); err != nil {
+			n.fail(errors.Wrapf(err, "block %d from %s", blk.Height(), from))
+			return
+		}
+		n.broadcast(blk, from)
+		return
+	}
+	if blk.Height() > n.Chain.TipHeight() {
+		n.requestSync(from)
+	}
+}
+
+// requestSync asks the node for its blocks after the last block in common, listed by the locator of the chain
+func (n *Node) requestSync(to string) {
+	locator := n.locator()
+	peer := n.sim.Node(to)
+	n.sim.Net.Send(n.Name, to, func() {
+		peer.serveSync(n.Name, locator)
+	})
+}
+
+// locator returns the hashes of the recent blocks one by one, then at doubling distances down to the genesis block
+func (n *Node) locator() []locatorEntry {
+	entries := []locatorEntry{}
+	step := uint32(1)
+	for height := n.Chain.TipHeight(); ; height -= step {
+		hash, err := n.Chain.GetHashByHeight(height)
+		if err != nil {
+			n.fail(err)
+			return entries
+		}
+		entries = append(entries, locatorEntry{height, hash})
+		if height == 0 {
+			return entries
+		}
+		if len(entries) >= locatorDenseEntries {
+			step *= 2
+		}
+		if step > height {
+			step = height
+		}
+	}
+}
+
+// serveSync sends the blocks after the highest block of the locator on the chain to the node requesting them
+func (n *Node) serveSync(from string, locator []locatorEntry) {
+	tip := n.Chain.TipHeight()
+	for _, entry := range locator {
+		hash, err := n.Chain.GetHashByHeight(entry.height)
+		if err != nil || hash != entry.hash {
+			continue
+		}
+		if entry.height >= tip {
+			return
+		}
+		blks, err := n.Chain.GetBlocksByRange(context.Background(), entry.height+1, tip)
+		if err != nil {
+			n.fail(err)
+			return
+		}
+		data := make([][]byte, 0, len(blks))
+		for _, blk := range blks {
+			buf, err := blk.Serialize()
+			if err != nil {
+				n.fail(err)
+				return
+			}
+			data = append(data, buf)
+		}
+		peer := n.sim.Node(from)
+		n.sim.Net.Send(n.Name, from, func() {
+			peer.receiveSync(n.Name, data)
+		})
+		return
+	}
+}
+
+// receiveSync switches to the branch of the blocks sent by the node if it is longer than the chain, rolling back the
+// blocks after their parent
+func (n *Node) receiveSync(from string, data [][]byte) {
+	blks := make([]*blockchain.Block, 0, len(data))
+	for _, buf := range data {
+		blk := &blockchain.Block{}
+		if err := blk.Deserialize(buf); err != nil {
+			n.fail(errors.Wrapf(err, "block from %s", from))
+			return
+		}
+		blks = append(blks, blk)
+	}
+	first, last := blks[0], blks[len(blks)-1]
+	parent := first.Height() - 1
+	// the chain has changed since the sync was requested, which a later block resyncs from
+	if hash, err := n.Chain.GetHashByHeight(parent); err != nil || hash != first.PrevHash() {
+		return
+	}
+	if last.Height() <= n.Chain.TipHeight() {
+		return
+	}
+	if parent < n.Chain.TipHeight() {
+		// a branch forking deeper than the max reorg depth is refused, or halts the node once verified by the
+		// consensus, rather than failing the simulation
+		if err := n.Chain.CheckReorg(first); err != nil {
+			n.log.WithFields(logger.Fields{"err": err, "peer": from}).Warning("Refused a branch")
+			return
+		}
+		n.log.WithFields(logger.Fields{"height": parent, "from": n.Chain.TipHeight(), "peer": from}).Info("Switching to a longer branch")
+		if err := n.Chain.RollbackToHeight(context.Background(), parent); err != nil {
+			n.fail(err)
+			return
+		}
+	}
+	for _, blk := range blks {
+		if err := n.Chain.AddBlockCommit(blk); err != nil {
+			n.fail(errors.Wrapf(err, "block %d from %s", blk.Height(), from))
+			return
+		}
+	}
+	n.broadcast(last, from)
+}
+
+// fail records the error of the node, which the simulation reports
+func (n *Node) fail(err error) {
+	err = errors.Wrapf(err, "node %s", n.Name)
+	n.log.WithField("err", err).Warning("Simulated node failed")
+	n.sim.errs = append(n.sim.errs, err)
+}
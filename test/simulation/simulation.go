@@ -0,0 +1,202 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/test/testutil"
+)
+
+var log = logger.New("simulation")
+
+// Config is the config of a simulation
+type Config struct {
+	// Nodes is the number of nodes, named node0, node1 and so on
+	Nodes int
+	// Chain is the config of the chains of the nodes, which are kept in memory and bootstrapped from the same genesis
+	// block
+	Chain config.Chain
+	// Allocation is the amount the genesis block allocates to each test address
+	Allocation uint64
+	// BlockInterval is the length of the slots the nodes take turns to produce a block in, in the order of their
+	// names, once the production is started
+	BlockInterval time.Duration
+	// Latency is the latency of the messages between the nodes unless it is set for their link
+	Latency time.Duration
+	// Start is the time the clock starts at
+	Start time.Time
+}
+
+// Simulation runs in-process nodes connected by an in-memory network on a fake clock
+// Nothing happens but when the clock is advanced, all the events running in order on the goroutine advancing it, so a
+// simulation driven the same way always ends in the same state, down to the hashes of the blocks. The blocks are only
+// structurally validated, as no consensus engine is set on the chains.
+type Simulation struct {
+	Clock *Clock
+	Net   *Network
+
+	cfg   Config
+	nodes []*Node
+	// slot is the number of the last production slot, and producing whether the production is started
+	slot      uint64
+	producing bool
+	errs      []error
+}
+
+// New creates the simulation of the config, whose nodes all have the genesis block
+func New(cfg Config) (*Simulation, error) {
+	if cfg.Nodes <= 0 {
+		return nil, errors.Errorf("invalid number of nodes %d", cfg.Nodes)
+	}
+	clock := NewClock(cfg.Start)
+	sim := &Simulation{Clock: clock, Net: NewNetwork(clock, cfg.Latency), cfg: cfg}
+	for i := 0; i < cfg.Nodes; i++ {
+		chain, err := testutil.NewBlockchain(cfg.Chain, cfg.Allocation)
+		if err != nil {
+			sim.Close()
+			return nil, errors.Wrapf(err, "failed to create the chain of node %d", i)
+		}
+		chain.SetClock(clock.Now)
+		name := fmt.Sprintf("node%d", i)
+		sim.nodes = append(sim.nodes, &Node{
+			Name:    name,
+			Chain:   chain,
+			Address: ta.Addrinfo["miner"].Address,
+			sim:     sim,
+			log:     log.WithField("node", name),
+		})
+	}
+	return sim, nil
+}
+
+// Nodes returns the nodes in the order of their names
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+// Node returns the node with the name, nil if there is none
+func (s *Simulation) Node(name string) *Node {
+	for _, n := range s.nodes {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// StartProduction makes the node of each slot of the block interval produce a block at the end of the slot
+func (s *Simulation) StartProduction() error {
+	if s.cfg.BlockInterval <= 0 {
+		return errors.Errorf("invalid block interval %v", s.cfg.BlockInterval)
+	}
+	if s.producing {
+		return nil
+	}
+	s.producing = true
+	s.scheduleSlot()
+	return nil
+}
+
+// StopProduction stops producing blocks once the current slot is over
+func (s *Simulation) StopProduction() {
+	s.producing = false
+}
+
+// scheduleSlot schedules the production of the block of the next slot
+func (s *Simulation) scheduleSlot() {
+	s.Clock.Schedule(s.cfg.BlockInterval, func() {
+		if !s.producing {
+			return
+		}
+		s.slot++
+		n := s.nodes[s.slot%uint64(len(s.nodes))]
+		if _, err := n.Produce(); err != nil {
+			n.fail(err)
+		}
+		s.scheduleSlot()
+	})
+}
+
+// Run advances the clock by d
+func (s *Simulation) Run(d time.Duration) {
+	s.Clock.Advance(d)
+}
+
+// Partition splits the network into the groups of nodes, see Network.Partition
+func (s *Simulation) Partition(groups ...[]string) {
+	s.Net.Partition(groups...)
+}
+
+// Heal removes the partition of the network and makes every node announce its tip, so the nodes on shorter branches
+// sync the longest one without waiting for the next block
+func (s *Simulation) Heal() error {
+	s.Net.Heal()
+	for _, n := range s.nodes {
+		if err := n.Announce(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Converged returns whether all the nodes have the same tip
+func (s *Simulation) Converged() bool {
+	tip := s.nodes[0].Chain.TipHash()
+	for _, n := range s.nodes[1:] {
+		if n.Chain.TipHash() != tip {
+			return false
+		}
+	}
+	return true
+}
+
+// Converge runs the events until all the nodes have the same tip, and returns an error listing the tips if they do
+// not within the timeout
+func (s *Simulation) Converge(timeout time.Duration) error {
+	deadline := s.Clock.Now().Add(timeout)
+	for !s.Converged() {
+		if !s.Clock.StepUntil(deadline) {
+			s.Clock.Advance(deadline.Sub(s.Clock.Now()))
+			if s.Converged() {
+				return nil
+			}
+			return errors.Errorf("nodes have not converged in %v: %s", timeout, s.tips())
+		}
+	}
+	return nil
+}
+
+// tips describes the tips of the nodes
+func (s *Simulation) tips() string {
+	tips := make([]string, 0, len(s.nodes))
+	for _, n := range s.nodes {
+		tips = append(tips, fmt.Sprintf("%s at %d %x", n.Name, n.Chain.TipHeight(), n.Chain.TipHash()))
+	}
+	return strings.Join(tips, ", ")
+}
+
+// Errors returns the errors of the nodes, e.g., blocks they failed to commit, in the order they occurred
+func (s *Simulation) Errors() []error {
+	return s.errs
+}
+
+// Close stops the chains of the nodes
+func (s *Simulation) Close() {
+	for _, n := range s.nodes {
+		if err := n.Chain.Stop(); err != nil {
+			log.WithFields(logger.Fields{"node": n.Name, "err": err}).Error("Failed to stop the chain")
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+func newTestSimulation(t *testing.T, nodes int) *Simulation {
+	sim, err := New(Config{
+		Nodes:         nodes,
+		Chain:         config.Chain{BlockReward: 5},
+		Allocation:    100,
+		BlockInterval: 10 * time.Second,
+		Latency:       100 * time.Millisecond,
+		Start:         time.Unix(1540000000, 0),
+	})
+	require.Nil(t, err)
+	return sim
+}
+
+func TestClock(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1540000000, 0)
+	c := NewClock(start)
+	order := []string{}
+	c.Schedule(2*time.Second, func() {
+		order = append(order, "b")
+		assert.Equal(start.Add(2*time.Second), c.Now())
+		// scheduled by an event within the advance
+		c.Schedule(time.Second, func() { order = append(order, "d") })
+	})
+	c.Schedule(time.Second, func() { order = append(order, "a") })
+	c.Schedule(2*time.Second, func() { order = append(order, "c") })
+	c.Schedule(10*time.Second, func() { order = append(order, "e") })
+
+	c.Advance(3 * time.Second)
+	assert.Equal([]string{"a", "b", "c", "d"}, order)
+	assert.Equal(start.Add(3*time.Second), c.Now())
+	assert.Equal(1, c.Pending())
+	assert.False(c.StepUntil(start.Add(5 * time.Second)))
+	assert.True(c.Step())
+	assert.Equal(start.Add(10*time.Second), c.Now())
+	assert.False(c.Step())
+}
+
+func TestSimulationConverges(t *testing.T) {
+	assert := assert.New(t)
+
+	run := func() cp.Hash32B {
+		sim := newTestSimulation(t, 4)
+		defer sim.Close()
+		sim.Net.SetLatency("node1", "node3", 3*time.Second)
+		assert.Nil(sim.StartProduction())
+		sim.Run(time.Minute)
+		sim.StopProduction()
+		assert.Nil(sim.Converge(time.Minute))
+		assert.Empty(sim.Errors())
+		for _, n := range sim.Nodes() {
+			assert.Equal(uint32(6), n.Chain.TipHeight())
+		}
+		// the block timestamps follow the clock
+		blk, err := sim.Node("node2").Chain.GetBlockByHeight(6)
+		assert.Nil(err)
+		assert.Equal(uint64(1540000060), blk.Timestamp())
+		return sim.Node("node0").Chain.TipHash()
+	}
+	// a simulation driven the same way ends in the same state
+	assert.Equal(run(), run())
+}
+
+func TestSimulationForks(t *testing.T) {
+	assert := assert.New(t)
+
+	sim := newTestSimulation(t, 3)
+	defer sim.Close()
+	assert.Nil(sim.StartProduction())
+	sim.Run(20 * time.Second)
+	assert.Nil(sim.Converge(time.Second))
+	assert.Equal(uint32(2), sim.Node("node0").Chain.TipHeight())
+
+	// node2 forks off on its own, producing 1 of every 3 blocks while the others produce 2
+	sim.Partition([]string{"node0", "node1"}, []string{"node2"})
+	sim.Run(time.Minute)
+	assert.False(sim.Converged())
+	assert.Equal(uint32(6), sim.Node("node0").Chain.TipHeight())
+	assert.Equal(uint32(4), sim.Node("node2").Chain.TipHeight())
+	forked, err := sim.Node("node2").Chain.GetHashByHeight(3)
+	assert.Nil(err)
+
+	// node2 switches to the longer branch once the partition heals
+	sim.StopProduction()
+	assert.Nil(sim.Heal())
+	assert.Nil(sim.Converge(time.Minute))
+	assert.Empty(sim.Errors())
+	assert.Equal(uint32(6), sim.Node("node2").Chain.TipHeight())
+	hash, err := sim.Node("node2").Chain.GetHashByHeight(3)
+	assert.Nil(err)
+	assert.NotEqual(forked, hash)
+	expected, err := sim.Node("node0").Chain.GetHashByHeight(3)
+	assert.Nil(err)
+	assert.Equal(expected, hash)
+	_, dropped := sim.Net.Stats()
+	assert.NotZero(dropped)
+
+	// nodes cut off from all the others do not converge
+	sim.Partition()
+	_, err = sim.Node("node1").Produce()
+	assert.Nil(err)
+	assert.NotNil(sim.Converge(time.Minute))
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package testutil
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/blockdb"
+	"github.com/iotexproject/iotex-core/config"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+// NewBlockchain returns a blockchain kept in memory, whose genesis block allocates the amount to each test address
+// in its own output, and otherwise follows the chain config
+// Nothing is written to disk, so tests using it can run in parallel and need no cleanup beyond closing the chain.
+func NewBlockchain(cfg config.Chain, amount uint64) (*blockchain.Blockchain, error) {
+	cfg.ChainDBBackend = blockdb.MemoryBackend
+	cfg.GenesisPath = ""
+
+	names := make([]string, 0, len(ta.Addrinfo))
+	for name := range ta.Addrinfo {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	genesis := &config.Genesis{CoinbaseData: blockchain.GenesisCoinbaseData}
+	for _, name := range names {
+		genesis.Allocations = append(genesis.Allocations, config.Allocation{Address: ta.Addrinfo[name].Address, Amount: amount})
+	}
+	return blockchain.CreateBlockchainWithGenesis(context.Background(), &config.Config{Chain: cfg}, genesis)
+}
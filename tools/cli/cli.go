@@ -11,10 +11,16 @@ import (
 	"fmt"
 	"os"
 
+	"golang.org/x/net/context"
+
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/export"
+	"github.com/iotexproject/iotex-core/logger"
 )
 
+var log = logger.New("cli")
+
 // CLI defines the struct of command line interface
 type CLI struct {
 	bc *blockchain.Blockchain
@@ -26,6 +32,10 @@ func (cli *CLI) printUsage() {
 	fmt.Println("  createchain -address ADDRESS          # create a new blockchain with an address")
 	fmt.Println("  getbalance -address ADDRESS           # get the balance of the address")
 	fmt.Println("  send -from FROM -to TO -amount AMOUNT # send from one address to another")
+	fmt.Println("  verifychain -depth DEPTH              # verify the last DEPTH blocks, or all blocks if DEPTH is 0")
+	fmt.Println("  reindex                               # rebuild the indexes and the UTXO from the blocks")
+	fmt.Println("  export -records block|tx|utxo -format json|csv [-fields F1,F2] [-start START] [-end END] [-out FILE]")
+	fmt.Println("                                        # export the records of the blocks in [START, END] for analytics")
 }
 
 func (cli *CLI) validateArgs() {
@@ -52,6 +62,19 @@ func (cli *CLI) Run() {
 	sendCmdTo := sendCmd.String("to", "", "send to address")
 	sendCmdAmount := sendCmd.Int("amount", 0, "send amount")
 
+	verifyChainCmd := flag.NewFlagSet("verifychain", flag.ExitOnError)
+	verifyChainDepth := verifyChainCmd.Uint("depth", 0, "number of most recent blocks to verify, 0 for all")
+
+	reindexCmd := flag.NewFlagSet("reindex", flag.ExitOnError)
+
+	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
+	exportRecords := exportCmd.String("records", export.BlockRecords, "kind of the records, block, tx or utxo")
+	exportFormat := exportCmd.String("format", export.JSON, "format of the records, json or csv")
+	exportFields := exportCmd.String("fields", "", "comma separated fields of the records, all fields if empty")
+	exportStart := exportCmd.Uint("start", 0, "height of the first block exported")
+	exportEnd := exportCmd.Int64("end", -1, "height of the last block exported, the tip if negative")
+	exportOut := exportCmd.String("out", "", "file the records are written to, stdout if empty")
+
 	switch os.Args[1] {
 	case "printchain":
 		printChainCmd.Parse(os.Args[2:])
@@ -61,6 +84,12 @@ func (cli *CLI) Run() {
 		getBalanceCmd.Parse(os.Args[2:])
 	case "send":
 		sendCmd.Parse(os.Args[2:])
+	case "verifychain":
+		verifyChainCmd.Parse(os.Args[2:])
+	case "reindex":
+		reindexCmd.Parse(os.Args[2:])
+	case "export":
+		exportCmd.Parse(os.Args[2:])
 	default:
 		cli.printUsage()
 		os.Exit(1)
@@ -78,7 +107,9 @@ func (cli *CLI) Run() {
 		if *createChainAddress == "" {
 			os.Exit(1)
 		}
-		cli.bc = blockchain.CreateBlockchain(*createChainAddress, config)
+		if cli.bc, err = blockchain.CreateBlockchain(context.Background(), *createChainAddress, config); err != nil {
+			log.Fatal(err)
+		}
 		defer cli.bc.Close()
 	}
 	if getBalanceCmd.Parsed() {
@@ -91,4 +122,13 @@ func (cli *CLI) Run() {
 		}
 		cli.send(*sendCmdFrom, *sendCmdTo, uint64(*sendCmdAmount), config)
 	}
+	if verifyChainCmd.Parsed() {
+		cli.verifyChain(uint32(*verifyChainDepth), config)
+	}
+	if reindexCmd.Parsed() {
+		cli.reindex(config)
+	}
+	if exportCmd.Parsed() {
+		cli.exportChain(*exportRecords, *exportFormat, *exportFields, uint32(*exportStart), *exportEnd, *exportOut, config)
+	}
 }
@@ -0,0 +1,52 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/export"
+)
+
+// exportChain writes the records of the blocks in [start, end] to the file at path, or to stdout if path is empty
+// The range ends at the tip if end is negative, and all the fields of the records are written if fields is empty.
+func (cli *CLI) exportChain(records, format, fields string, start uint32, end int64, path string, config *config.Config) {
+	bc, err := blockchain.CreateBlockchain(context.Background(), config.Chain.MinerAddr, config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer bc.Close()
+
+	opts := export.Options{Records: records, Format: format, Start: start, End: bc.TipHeight()}
+	if fields != "" {
+		opts.Fields = strings.Split(fields, ",")
+	}
+	if end >= 0 {
+		opts.End = uint32(end)
+	}
+	var w io.Writer = os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	count, err := export.Export(context.Background(), bc, w, opts)
+	if err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d %s records of blocks %d to %d\n", count, records, opts.Start, opts.End)
+}
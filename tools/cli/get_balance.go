@@ -9,7 +9,7 @@ package cli
 import (
 	"fmt"
 
-	"github.com/golang/glog"
+	"golang.org/x/net/context"
 
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/config"
@@ -18,11 +18,14 @@ import (
 
 func (cli *CLI) getBalance(address string, config *config.Config) {
 	if !iotxaddress.ValidateAddress(address) {
-		glog.Fatal("ERROR: Address is not valid")
+		log.Fatal("ERROR: Address is not valid")
+	}
+	bc, err := blockchain.CreateBlockchain(context.Background(), address, config)
+	if err != nil {
+		log.Fatal(err)
 	}
-	bc := blockchain.CreateBlockchain(address, config)
 	defer bc.Close()
 
-	balance := bc.BalanceOf(address)
+	balance := bc.BalanceOf(address, 0)
 	fmt.Printf("Balance of '%s': %d\n", address, balance)
 }
@@ -7,12 +7,17 @@
 package cli
 
 import (
+	"golang.org/x/net/context"
+
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/config"
 )
 
 func (cli *CLI) printChain(config *config.Config) {
-	cli.bc = blockchain.CreateBlockchain("it1qyqqqqqpj74jttuw2wdu2vlejv3xg6adu3v743w049htcg", config)
+	var err error
+	if cli.bc, err = blockchain.CreateBlockchain(context.Background(), "it1qyqqqqqpj74jttuw2wdu2vlejv3xg6adu3v743w049htcg", config); err != nil {
+		log.Fatal(err)
+	}
 	defer cli.bc.Close()
 
 	/*
@@ -9,7 +9,7 @@ package cli
 import (
 	"fmt"
 
-	"github.com/golang/glog"
+	"golang.org/x/net/context"
 
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/config"
@@ -18,13 +18,16 @@ import (
 
 func (cli *CLI) send(from, to string, amount uint64, config *config.Config) {
 	if !iotxaddress.ValidateAddress(from) {
-		glog.Fatal("ERROR: Sender address is not valid")
+		log.Fatal("ERROR: Sender address is not valid")
 	}
 	if !iotxaddress.ValidateAddress(to) {
-		glog.Fatal("ERROR: Recipient address is not valid")
+		log.Fatal("ERROR: Recipient address is not valid")
 	}
 
-	bc := blockchain.CreateBlockchain(from, config)
+	bc, err := blockchain.CreateBlockchain(context.Background(), from, config)
+	if err != nil {
+		log.Fatal(err)
+	}
 	defer bc.Close()
 
 	//tx := blockchain.NewUTXOTransaction(from, to, amount, bc)
@@ -0,0 +1,29 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func (cli *CLI) verifyChain(depth uint32, config *config.Config) {
+	bc, err := blockchain.CreateBlockchain(context.Background(), config.Chain.MinerAddr, config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer bc.Close()
+
+	if err := bc.VerifyChain(context.Background(), depth); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+	fmt.Printf("Verified the chain up to tip height %d\n", bc.TipHeight())
+}
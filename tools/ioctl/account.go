@@ -0,0 +1,94 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+func createAccount(c *client, args []string) error {
+	fs := flag.NewFlagSet("createaccount", flag.ExitOnError)
+	passphrase := fs.String("passphrase", "", "passphrase encrypting the key, read from the standard input if not given")
+	schnorr := fs.Bool("schnorr", false, "create an account with a Schnorr key pair")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	pass, err := readPassphrase(*passphrase)
+	if err != nil {
+		return err
+	}
+	w := c.wallet()
+	var address string
+	if *schnorr {
+		address, err = w.NewSchnorrAccount(pass)
+	} else {
+		address, err = w.NewAccount(pass)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(address)
+	return nil
+}
+
+func listAccounts(c *client, args []string) error {
+	fs := flag.NewFlagSet("listaccounts", flag.ExitOnError)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	w := c.wallet()
+	accounts, err := w.Accounts()
+	if err != nil {
+		return err
+	}
+	for _, address := range accounts {
+		watchOnly, err := w.IsWatchOnly(address)
+		if err != nil {
+			return err
+		}
+		if watchOnly {
+			fmt.Printf("%s (watch-only)\n", address)
+		} else {
+			fmt.Println(address)
+		}
+	}
+	return nil
+}
+
+func importDescriptor(c *client, args []string) error {
+	fs := flag.NewFlagSet("importdescriptor", flag.ExitOnError)
+	desc := fs.String("descriptor", "", "output script descriptor, e.g., timelock(ADDRESS,BLOCKS)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	imported, err := c.wallet().ImportDescriptor(*desc)
+	if err != nil {
+		return err
+	}
+	fmt.Println(imported)
+	return nil
+}
+
+func listDescriptors(c *client, args []string) error {
+	fs := flag.NewFlagSet("listdescriptors", flag.ExitOnError)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	descs, err := c.wallet().ExportDescriptors()
+	if err != nil {
+		return err
+	}
+	for _, desc := range descs {
+		fmt.Println(desc)
+	}
+	return nil
+}
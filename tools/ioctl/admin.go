@@ -0,0 +1,134 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/admin"
+)
+
+func peers(c *client, args []string) error {
+	fs := flag.NewFlagSet("peers", flag.ExitOnError)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return c.adminRequest(http.MethodGet, admin.PeersPath, nil)
+}
+
+func mempool(c *client, args []string) error {
+	fs := flag.NewFlagSet("mempool", flag.ExitOnError)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return c.adminRequest(http.MethodGet, admin.MempoolPath, nil)
+}
+
+func logLevel(c *client, args []string) error {
+	fs := flag.NewFlagSet("loglevel", flag.ExitOnError)
+	module := fs.String("module", "", "module whose level is set, the default level is set if not given")
+	level := fs.String("level", "", "level to set, the levels are printed if not given")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *level == "" {
+		return c.adminRequest(http.MethodGet, admin.LogLevelPath, nil)
+	}
+	form := url.Values{"level": {*level}}
+	if *module != "" {
+		form.Set("module", *module)
+	}
+	return c.adminRequest(http.MethodPost, admin.LogLevelPath, form)
+}
+
+func verifyChain(c *client, args []string) error {
+	fs := flag.NewFlagSet("verifychain", flag.ExitOnError)
+	depth := fs.Uint("depth", 0, "number of most recent blocks to verify, 0 for all")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return c.adminRequest(http.MethodPost, admin.VerifyChainPath, url.Values{"depth": {strconv.FormatUint(uint64(*depth), 10)}})
+}
+
+func stopNode(c *client, args []string) error {
+	fs := flag.NewFlagSet("stopnode", flag.ExitOnError)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return c.adminRequest(http.MethodPost, admin.StopPath, nil)
+}
+
+// adminRequest sends the request to the admin service with the token of the config, and prints the response
+func (c *client) adminRequest(method string, path string, form url.Values) error {
+	base, httpClient, err := c.adminClient()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, base+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.Admin.Token)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if len(body) == 0 {
+		fmt.Println(resp.Status)
+		return nil
+	}
+	fmt.Print(string(body))
+	return nil
+}
+
+// adminClient returns the base URL of the admin service and the HTTP client trusting its certificate if TLS is enabled
+func (c *client) adminClient() (string, *http.Client, error) {
+	if *adminURL != "" {
+		return strings.TrimRight(*adminURL, "/"), http.DefaultClient, nil
+	}
+	addr := c.cfg.Admin.Addr
+	if addr == "" {
+		return "", nil, errors.New("no admin address is given or configured")
+	}
+	if strings.HasPrefix(addr, ":") {
+		addr = "127.0.0.1" + addr
+	}
+	if !c.cfg.Admin.TLSEnabled {
+		return "http://" + addr, http.DefaultClient, nil
+	}
+	cert, err := ioutil.ReadFile(c.cfg.Admin.CertPath)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to load the admin certificate")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(cert) {
+		return "", nil, errors.Errorf("no certificate in %s", c.cfg.Admin.CertPath)
+	}
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	return "https://" + addr, httpClient, nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	pb "github.com/iotexproject/iotex-core/proto"
+)
+
+func getBalance(c *client, args []string) error {
+	fs := flag.NewFlagSet("getbalance", flag.ExitOnError)
+	address := fs.String("address", "", "address")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *address == "" {
+		fs.Usage()
+		return errors.New("no address is given")
+	}
+
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	r, err := api.GetBalance(ctx, &pb.GetBalanceRequest{Address: *address})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Balance of '%s': %d\n", *address, r.Balance)
+	return nil
+}
+
+// send has the node create a raw transaction spending the UTXO of the sender, signs it with the key of the sender in
+// the keystore and sends it to the node
+func send(c *client, args []string) error {
+	fs := flag.NewFlagSet("send", flag.ExitOnError)
+	from := fs.String("from", "", "send from address, which has to be an account of the keystore")
+	to := fs.String("to", "", "send to address")
+	amount := fs.Uint64("amount", 0, "send amount")
+	passphrase := fs.String("passphrase", "", "passphrase of the sender, read from the standard input if not given")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" || *amount == 0 {
+		fs.Usage()
+		return errors.New("sender, recipient and amount have to be given")
+	}
+
+	pass, err := readPassphrase(*passphrase)
+	if err != nil {
+		return err
+	}
+	w := c.wallet()
+	if err := w.Unlock(*from, pass, 0); err != nil {
+		return err
+	}
+	defer w.Lock(*from)
+	signer, err := w.Signer(*from)
+	if err != nil {
+		return err
+	}
+
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	raw, err := api.CreateRawTransaction(ctx, &pb.CreateRawTransactionRequest{From: *from, To: *to, Amount: *amount})
+	if err != nil {
+		return err
+	}
+	txPb := &pb.TxPb{}
+	if err := proto.Unmarshal(raw.SerializedTx, txPb); err != nil {
+		return err
+	}
+	tx := &blockchain.Tx{}
+	tx.ConvertFromTxPb(txPb)
+	if err := tx.Sign(signer); err != nil {
+		return err
+	}
+	stx, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	r, err := api.SendRawTransaction(ctx, &pb.SendRawTransactionRequest{SerializedTx: stx})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Sent transaction %x\n", r.TxHash)
+	return nil
+}
+
+func getBlock(c *client, args []string) error {
+	fs := flag.NewFlagSet("getblock", flag.ExitOnError)
+	height := fs.Int64("height", -1, "height of the block, the tip height by default")
+	hash := fs.String("hash", "", "hex-encoded hash of the block")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if *hash != "" {
+		h, err := hex.DecodeString(*hash)
+		if err != nil {
+			return errors.Wrapf(err, "invalid hash %s", *hash)
+		}
+		r, err := api.GetBlockByHash(ctx, &pb.GetBlockByHashRequest{Hash: h})
+		if err != nil {
+			return err
+		}
+		return printMessage(r)
+	}
+	if *height < 0 {
+		tip, err := api.GetTipInfo(ctx, &pb.GetTipInfoRequest{})
+		if err != nil {
+			return err
+		}
+		*height = int64(tip.Height)
+	}
+	r, err := api.GetBlockByHeight(ctx, &pb.GetBlockByHeightRequest{Height: uint32(*height)})
+	if err != nil {
+		return err
+	}
+	return printMessage(r)
+}
+
+func getTx(c *client, args []string) error {
+	fs := flag.NewFlagSet("gettx", flag.ExitOnError)
+	hash := fs.String("hash", "", "hex-encoded hash of the transaction")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	h, err := hex.DecodeString(*hash)
+	if err != nil || len(h) == 0 {
+		fs.Usage()
+		return errors.Errorf("invalid hash %s", *hash)
+	}
+
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	r, err := api.GetTransaction(ctx, &pb.GetTransactionRequest{Hash: h})
+	if err != nil {
+		return err
+	}
+	return printMessage(r)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+// This is a command line client operating a wallet and a node through the node API and the admin service
+// To use, run "make build" and "./bin/ioctl -config=./config.yaml COMMAND [FLAGS]"
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/iotexproject/iotex-core/config"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/wallet"
+)
+
+// requestTimeout is the timeout of a request to the node
+const requestTimeout = 10 * time.Second
+
+var (
+	configFile = flag.String("config", "./config.yaml", "specify configuration file path")
+	endpoint   = flag.String("endpoint", "", "address of the node API, the API address of the config by default")
+	adminURL   = flag.String("admin", "", "URL of the admin service, the admin address of the config by default")
+)
+
+// command is a subcommand of ioctl, run with the arguments following its name
+type command struct {
+	name  string
+	flags string
+	desc  string
+	run   func(c *client, args []string) error
+}
+
+var commands = []*command{
+	{"createaccount", "[-passphrase PASSPHRASE] [-schnorr]", "create an account in the keystore", createAccount},
+	{"listaccounts", "", "list the accounts in the keystore", listAccounts},
+	{"importdescriptor", "-descriptor DESCRIPTOR", "import the descriptor of the outputs to track", importDescriptor},
+	{"listdescriptors", "", "list the descriptors of the outputs tracked by the keystore", listDescriptors},
+	{"getbalance", "-address ADDRESS", "get the balance of the address", getBalance},
+	{"send", "-from FROM -to TO -amount AMOUNT [-passphrase PASSPHRASE]", "send from an account of the keystore", send},
+	{"getblock", "[-height HEIGHT | -hash HASH]", "print a block, the tip block by default", getBlock},
+	{"gettx", "-hash HASH", "print a transaction along with its block", getTx},
+	{"peers", "", "list the peers of the node", peers},
+	{"mempool", "", "list the transactions in the txpool of the node", mempool},
+	{"loglevel", "[-module MODULE] [-level LEVEL]", "print the log levels of the node, or set one with a level", logLevel},
+	{"verifychain", "[-depth DEPTH]", "verify the last DEPTH blocks of the node, or all blocks if DEPTH is 0", verifyChain},
+	{"stopnode", "", "stop the node", stopNode},
+}
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: ioctl [-config=string] [-endpoint=string] [-admin=string] COMMAND [FLAGS]\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "commands:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(os.Stderr, "  %-62s # %s\n", cmd.name+" "+cmd.flags, cmd.desc)
+		}
+		os.Exit(2)
+	}
+	flag.Parse()
+}
+
+func main() {
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+	var cmd *command
+	for _, c := range commands {
+		if c.name == flag.Arg(0) {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		flag.Usage()
+	}
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(*configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	c := &client{cfg: cfg}
+	defer c.close()
+	if err := cmd.run(c, flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+		os.Exit(1)
+	}
+}
+
+// client holds the config of the node along with the connection to its API, which is only dialed once needed
+type client struct {
+	cfg  *config.Config
+	conn *grpc.ClientConn
+}
+
+// api returns the client of the node API
+func (c *client) api() (pb.ApiServiceClient, error) {
+	if c.conn == nil {
+		addr := *endpoint
+		if addr == "" {
+			addr = c.cfg.API.Addr
+		}
+		if addr == "" {
+			return nil, errors.New("no API address is given or configured")
+		}
+		opt := grpc.WithInsecure()
+		if c.cfg.API.TLSEnabled {
+			creds, err := credentials.NewClientTLSFromFile(c.cfg.API.CertPath, "")
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to load the API certificate")
+			}
+			opt = grpc.WithTransportCredentials(creds)
+		}
+		conn, err := grpc.Dial(addr, opt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to dial the API at %s", addr)
+		}
+		c.conn = conn
+	}
+	return pb.NewApiServiceClient(c.conn), nil
+}
+
+// wallet returns the wallet on the keystore of the config
+func (c *client) wallet() *wallet.Wallet {
+	return wallet.NewWallet(c.cfg.Wallet)
+}
+
+func (c *client) close() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// parseFlags parses the arguments of the command with its flag set, failing on positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.Errorf("unexpected arguments %v", fs.Args())
+	}
+	return nil
+}
+
+// readPassphrase returns the passphrase given by the flag, or reads it from the standard input otherwise
+func readPassphrase(passphrase string) (string, error) {
+	if passphrase != "" {
+		return passphrase, nil
+	}
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.Wrap(err, "failed to read the passphrase")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// printMessage prints the message as indented JSON
+func printMessage(msg proto.Message) error {
+	m := jsonpb.Marshaler{Indent: "  "}
+	s, err := m.MarshalToString(msg)
+	if err != nil {
+		return err
+	}
+	fmt.Println(s)
+	return nil
+}
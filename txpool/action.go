@@ -0,0 +1,127 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package txpool
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+)
+
+var (
+	// ErrNonceTooLow is the error returned when adding an action whose nonce is not above the confirmed nonce of its
+	// sender, which replays or conflicts with a confirmed action
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrNonceTooHigh is the error returned when adding an action whose nonce is too far ahead of the confirmed nonce
+	// of its sender to be queued
+	ErrNonceTooHigh = errors.New("nonce too high")
+)
+
+// AcceptAction validates the action and adds it to the pool, where it is pending once the actions of its sender with
+// all the nonces before its own are confirmed or pending, and is queued until then
+// The cause of the returned error is ErrInvalidTx if the action is malformed or not nonced, ErrNonceTooLow,
+// ErrNonceTooHigh, ErrDuplicateTx, or ErrDoubleSpend if another action of the sender with the same nonce is in the pool.
+func (tp *txPool) AcceptAction(act blockchain.Action) error {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+	if tp.stopped {
+		return ErrPoolStopped
+	}
+
+	hash := act.Hash()
+	nonced, ok := act.(blockchain.NoncedAction)
+	if !ok {
+		return errors.Wrapf(ErrInvalidTx, "action %s %x has no sender nonce", act.Kind(), hash)
+	}
+	if err := act.Verify(); err != nil {
+		return errors.Wrap(ErrInvalidTx, err.Error())
+	}
+	sender, nonce := nonced.SenderNonce()
+	confirmed := tp.bc.AccountState(sender).Nonce
+	if nonce <= confirmed {
+		return errors.Wrapf(ErrNonceTooLow, "action %x has nonce %d, %s has confirmed nonce %d", hash, nonce, sender, confirmed)
+	}
+	if max := tp.cfg.MaxQueuedActions; max > 0 && nonce-confirmed > max {
+		return errors.Wrapf(ErrNonceTooHigh, "action %x has nonce %d, more than %d ahead of confirmed nonce %d of %s", hash, nonce, max, confirmed, sender)
+	}
+	queue, ok := tp.actions[sender]
+	if !ok {
+		queue = make(map[uint64]blockchain.NoncedAction)
+		tp.actions[sender] = queue
+	}
+	if queued, ok := queue[nonce]; ok {
+		if queued.Hash() == hash {
+			return errors.Wrapf(ErrDuplicateTx, "action %x", hash)
+		}
+		return errors.Wrapf(ErrDoubleSpend, "nonce %d of %s is already used by action %x", nonce, sender, queued.Hash())
+	}
+	queue[nonce] = nonced
+	tp.setLastUpdateUnixTime()
+	return nil
+}
+
+// PendingNonce returns the nonce of the address once its pending actions are confirmed, which is its confirmed nonce
+// followed by the nonces of its actions in the pool up to the first gap, the next action of the address has to carry
+// the nonce after it
+func (tp *txPool) PendingNonce(address string) uint64 {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	nonce := tp.bc.AccountState(address).Nonce
+	for queue := tp.actions[address]; queue[nonce+1] != nil; nonce++ {
+	}
+	return nonce
+}
+
+// Actions returns the pending actions ready to be executed, which are the actions of each sender whose nonces follow
+// its confirmed nonce up to the first gap, in the order of their nonces
+// The actions after a gap stay queued until the actions with the missing nonces are added.
+func (tp *txPool) Actions() []blockchain.Action {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	senders := make([]string, 0, len(tp.actions))
+	for sender := range tp.actions {
+		senders = append(senders, sender)
+	}
+	sort.Strings(senders)
+	actions := []blockchain.Action{}
+	for _, sender := range senders {
+		queue := tp.actions[sender]
+		for nonce := tp.bc.AccountState(sender).Nonce + 1; queue[nonce] != nil; nonce++ {
+			actions = append(actions, queue[nonce])
+		}
+	}
+	return actions
+}
+
+// removeConfirmedActions removes the actions of the senders of the nonced actions in the block whose nonces are no
+// longer above the confirmed nonces of their senders
+func (tp *txPool) removeConfirmedActions(block *blockchain.Block) {
+	for _, act := range block.Actions() {
+		nonced, ok := act.(blockchain.NoncedAction)
+		if !ok {
+			continue
+		}
+		sender, _ := nonced.SenderNonce()
+		queue, ok := tp.actions[sender]
+		if !ok {
+			continue
+		}
+		confirmed := tp.bc.AccountState(sender).Nonce
+		for nonce := range queue {
+			if nonce <= confirmed {
+				delete(queue, nonce)
+			}
+		}
+		if len(queue) == 0 {
+			delete(tp.actions, sender)
+		}
+	}
+}
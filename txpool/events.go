@@ -0,0 +1,67 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package txpool
+
+import (
+	"sync"
+)
+
+// txChanSize is the buffer size of each subscriber's channel
+const txChanSize = 256
+
+// txHub fans out the txs accepted into the pool to its subscribers
+type txHub struct {
+	mu   sync.RWMutex
+	subs map[chan *TxDesc]struct{}
+}
+
+func newTxHub() *txHub {
+	return &txHub{subs: make(map[chan *TxDesc]struct{})}
+}
+
+func (h *txHub) subscribe() chan *TxDesc {
+	ch := make(chan *TxDesc, txChanSize)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[ch] = struct{}{}
+	return ch
+}
+
+func (h *txHub) unsubscribe(ch <-chan *TxDesc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		if sub == ch {
+			delete(h.subs, sub)
+			close(sub)
+			return
+		}
+	}
+}
+
+// publish sends the tx to all subscribers without blocking; a subscriber whose buffer is full misses the tx
+func (h *txHub) publish(desc *TxDesc) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs {
+		select {
+		case sub <- desc:
+		default:
+			log.Warningf("Subscriber is too slow, dropping accepted tx %x", desc.Tx.Hash())
+		}
+	}
+}
+
+// Subscribe returns a channel on which the txs accepted into the pool are delivered
+func (tp *txPool) Subscribe() <-chan *TxDesc {
+	return tp.txs.subscribe()
+}
+
+// Unsubscribe stops delivering the accepted txs to the channel and closes it
+func (tp *txPool) Unsubscribe(ch <-chan *TxDesc) {
+	tp.txs.unsubscribe(ch)
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package txpool
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+)
+
+var (
+	// ErrTxNotInPool is the error returned when querying the dependencies of a tx which is not accepted in the pool
+	ErrTxNotInPool = errors.New("transaction is not in the pool")
+)
+
+// TxPackage is a set of accepted txs depending on each other, which are mined or evicted together
+type TxPackage struct {
+	// Txs are ordered by the time they are added to the pool, so the parents come before their children
+	Txs []*TxDesc
+	// Fee is the fee all the txs pay together, and Size the sum of their serialized sizes
+	Fee  int64
+	Size uint32
+}
+
+// FeeRate returns the fee per byte of the package, which is what a miner gets for including all its txs
+func (pkg *TxPackage) FeeRate() float64 {
+	if pkg.Size == 0 {
+		return 0
+	}
+	return float64(pkg.Fee) / float64(pkg.Size)
+}
+
+// newTxPackage returns the package of the descs, ordered by added time
+func newTxPackage(descs map[cp.Hash32B]*TxDesc) *TxPackage {
+	pkg := &TxPackage{Txs: make([]*TxDesc, 0, len(descs))}
+	for _, desc := range descs {
+		serialize, err := desc.Tx.Serialize()
+		if err != nil {
+			continue
+		}
+		pkg.Txs = append(pkg.Txs, desc)
+		pkg.Fee += desc.Fee
+		pkg.Size += uint32(len(serialize))
+	}
+	sortTxDescs(pkg.Txs)
+	return pkg
+}
+
+// sortTxDescs orders the descs by added time, then by hash for the txs added at the same time
+func sortTxDescs(descs []*TxDesc) {
+	sort.Slice(descs, func(i, j int) bool {
+		if !descs[i].AddedTime.Equal(descs[j].AddedTime) {
+			return descs[i].AddedTime.Before(descs[j].AddedTime)
+		}
+		hi, hj := descs[i].Tx.Hash(), descs[j].Tx.Hash()
+		return string(hi[:]) < string(hj[:])
+	})
+}
+
+// collectAncestors adds the accepted tx with the hash and all accepted txs whose outputs it spends, recursively, to
+// descs
+func (tp *txPool) collectAncestors(hash cp.Hash32B, descs map[cp.Hash32B]*TxDesc) {
+	desc, ok := tp.txDescs[hash]
+	if !ok {
+		return
+	}
+	if _, ok := descs[hash]; ok {
+		return
+	}
+	descs[hash] = desc
+	for _, txIn := range desc.Tx.TxIn {
+		tp.collectAncestors(NewTxSourcePointer(txIn).Hash, descs)
+	}
+}
+
+// PendingByAddress returns the accepted txs paying the address or spending its UTXO, oldest first
+func (tp *txPool) PendingByAddress(address string) []*TxDesc {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	key := iotxaddress.GetPubkeyHash(address)
+	descs := []*TxDesc{}
+	for _, desc := range tp.txDescs {
+		if tp.involves(desc.Tx, key) {
+			descs = append(descs, desc)
+		}
+	}
+	sortTxDescs(descs)
+	return descs
+}
+
+// involves checks whether an output of the tx, or a UTXO it spends, is locked with the key
+func (tp *txPool) involves(tx *blockchain.Tx, key []byte) bool {
+	for _, txOut := range tx.TxOut {
+		if txOut.IsLockedWithKey(key) {
+			return true
+		}
+	}
+	view := tp.fetchInputUtxos(tx)
+	for _, txIn := range tx.TxIn {
+		if utxo := view.TxInputUtxo(txIn); utxo != nil && utxo.IsLockedWithKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// AncestorPackage returns the package of the accepted tx with the hash and all its unmined ancestors, which have to be
+// mined before it
+func (tp *txPool) AncestorPackage(hash cp.Hash32B) (*TxPackage, error) {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	if !tp.hasTx(hash) {
+		return nil, errors.Wrapf(ErrTxNotInPool, "tx %x", hash)
+	}
+	descs := make(map[cp.Hash32B]*TxDesc)
+	tp.collectAncestors(hash, descs)
+	return newTxPackage(descs), nil
+}
+
+// DescendantPackage returns the package of the accepted tx with the hash and all its descendants, which are evicted
+// along with it
+func (tp *txPool) DescendantPackage(hash cp.Hash32B) (*TxPackage, error) {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	if !tp.hasTx(hash) {
+		return nil, errors.Wrapf(ErrTxNotInPool, "tx %x", hash)
+	}
+	descs := make(map[cp.Hash32B]*TxDesc)
+	tp.collectDescendants(hash, descs)
+	return newTxPackage(descs), nil
+}
+
+// Conflicts returns the package of the accepted txs spending the same UTXO as the tx, other than the tx itself, with
+// all their descendants, which the tx has to outbid to replace them
+func (tp *txPool) Conflicts(tx *blockchain.Tx) *TxPackage {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	hash := tx.Hash()
+	descs := make(map[cp.Hash32B]*TxDesc)
+	for _, txIn := range tx.TxIn {
+		if txSpend, ok := tp.txSourcePointers[NewTxSourcePointer(txIn)]; ok && txSpend.Hash() != hash {
+			tp.collectDescendants(txSpend.Hash(), descs)
+		}
+	}
+	return newTxPackage(descs)
+}
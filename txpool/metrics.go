@@ -0,0 +1,24 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package txpool
+
+import (
+	"github.com/iotexproject/iotex-core/metrics"
+)
+
+var (
+	poolTxsGauge   = metrics.NewGauge("iotex_txpool_txs", "Number of txs accepted into the pool")
+	poolSizeGauge  = metrics.NewGauge("iotex_txpool_size_bytes", "Total size of the txs accepted into the pool")
+	orphanTxsGauge = metrics.NewGauge("iotex_txpool_orphan_txs", "Number of orphan txs in the pool")
+)
+
+// updateMetrics sets the gauges of the depth of the pool
+func (tp *txPool) updateMetrics() {
+	poolTxsGauge.Set(int64(len(tp.txDescs)))
+	poolSizeGauge.Set(int64(tp.size))
+	orphanTxsGauge.Set(int64(len(tp.orphanTxs)))
+}
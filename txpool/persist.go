@@ -0,0 +1,228 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package txpool
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cm "github.com/iotexproject/iotex-core/common"
+)
+
+// The pool file starts with a header of the magic, version, number of accepted txs and number of orphan txs, followed
+// by one record per tx, accepted txs first: the 4-byte size and the 4-byte CRC32 checksum of the serialized tx, then
+// the tx. All integers are in the machine endian.
+const (
+	// PoolFileVersion is the version of the pool file format written by the pool
+	PoolFileVersion = uint32(1)
+
+	poolFileHeaderSize = 4 + 4 + 4 + 4
+)
+
+var poolFileMagic = []byte("IOTP")
+
+// persister saves the pool on each tick
+type persister struct {
+	tp *txPool
+}
+
+// Do saves the pool
+func (p *persister) Do() {
+	if err := p.tp.save(); err != nil {
+		log.Errorf("Cannot save the tx pool: %v", err)
+	}
+}
+
+// Start loads the txs saved at the persist path, then saves the pool periodically if the persist interval is set
+func (tp *txPool) Start() error {
+	tp.mutex.Lock()
+	tp.stopped = false
+	tp.mutex.Unlock()
+	if tp.cfg.PersistPath == "" {
+		return nil
+	}
+	if err := tp.load(); err != nil {
+		log.Errorf("Cannot load the tx pool from %s: %v", tp.cfg.PersistPath, err)
+	}
+	if tp.task != nil {
+		tp.task.Init()
+		tp.task.Start()
+	}
+	return nil
+}
+
+// Stop rejects the txs received from now on with ErrPoolStopped, stops saving the pool periodically and saves it a
+// last time
+func (tp *txPool) Stop() error {
+	tp.mutex.Lock()
+	tp.stopped = true
+	tp.mutex.Unlock()
+	if tp.cfg.PersistPath == "" {
+		return nil
+	}
+	if tp.task != nil {
+		tp.task.Stop()
+	}
+	return tp.save()
+}
+
+// save writes the accepted and orphan txs to the persist path, replacing the previous file
+// The accepted txs are written in the order they were added, so that parents come before their children.
+func (tp *txPool) save() error {
+	if tp.cfg.PersistPath == "" {
+		return nil
+	}
+	tp.mutex.RLock()
+	descs := make([]*TxDesc, 0, len(tp.txDescs))
+	for _, desc := range tp.txDescs {
+		descs = append(descs, desc)
+	}
+	orphans := make([]*orphanTx, 0, len(tp.orphanTxs))
+	for _, orphan := range tp.orphanTxs {
+		orphans = append(orphans, orphan)
+	}
+	tp.mutex.RUnlock()
+
+	sort.SliceStable(descs, func(i, j int) bool { return descs[i].AddedTime.Before(descs[j].AddedTime) })
+	txs := make([]*blockchain.Tx, 0, len(descs))
+	for _, desc := range descs {
+		txs = append(txs, desc.Tx)
+	}
+	orphanTxs := make([]*blockchain.Tx, 0, len(orphans))
+	for _, orphan := range orphans {
+		orphanTxs = append(orphanTxs, orphan.Tx)
+	}
+
+	// write to a temporary file first, so a crash while saving keeps the previous file
+	tmpPath := tp.cfg.PersistPath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if err := writePoolFile(w, txs, orphanTxs); err != nil {
+		file.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, tp.cfg.PersistPath)
+}
+
+// load reads the txs saved at the persist path and processes them as new txs, so they are revalidated against the
+// current UTXO set
+// Accepted txs which have been mined or became invalid meanwhile are dropped, as their inputs are gone, while saved
+// orphans are kept as orphans. A missing file is not an error.
+func (tp *txPool) load() error {
+	if tp.cfg.PersistPath == "" {
+		return nil
+	}
+	file, err := os.Open(tp.cfg.PersistPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	txs, orphanTxs, err := readPoolFile(bufio.NewReader(file))
+	if err != nil {
+		return err
+	}
+	dropped := 0
+	for i, tx := range append(txs, orphanTxs...) {
+		if _, err := tp.ProcessTx(tx, i >= len(txs), false, 0); err != nil {
+			log.Infof("Drop saved tx %x: %v", tx.Hash(), err)
+			dropped++
+		}
+	}
+	log.Infof("Loaded %d txs from %s, dropped %d", len(txs)+len(orphanTxs)-dropped, tp.cfg.PersistPath, dropped)
+	return nil
+}
+
+// writePoolFile writes the header and one record per accepted and orphan tx
+func writePoolFile(w io.Writer, txs []*blockchain.Tx, orphanTxs []*blockchain.Tx) error {
+	header := make([]byte, poolFileHeaderSize)
+	copy(header, poolFileMagic)
+	cm.MachineEndian.PutUint32(header[4:], PoolFileVersion)
+	cm.MachineEndian.PutUint32(header[8:], uint32(len(txs)))
+	cm.MachineEndian.PutUint32(header[12:], uint32(len(orphanTxs)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	for _, tx := range append(txs, orphanTxs...) {
+		data, err := tx.Serialize()
+		if err != nil {
+			return err
+		}
+		prefix := make([]byte, 8)
+		cm.MachineEndian.PutUint32(prefix, uint32(len(data)))
+		cm.MachineEndian.PutUint32(prefix[4:], crc32.ChecksumIEEE(data))
+		if _, err := w.Write(prefix); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPoolFile reads the header and the accepted and orphan txs, verifying the checksum of each record
+func readPoolFile(r io.Reader) ([]*blockchain.Tx, []*blockchain.Tx, error) {
+	header := make([]byte, poolFileHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, fmt.Errorf("cannot read header: %v", err)
+	}
+	if !bytes.Equal(header[:4], poolFileMagic) {
+		return nil, nil, fmt.Errorf("wrong magic %x", header[:4])
+	}
+	if version := cm.MachineEndian.Uint32(header[4:]); version != PoolFileVersion {
+		return nil, nil, fmt.Errorf("unsupported version %d, expecting %d", version, PoolFileVersion)
+	}
+
+	count := cm.MachineEndian.Uint32(header[8:])
+	orphanCount := cm.MachineEndian.Uint32(header[12:])
+	txs := []*blockchain.Tx{}
+	// the counts are summed in 64 bits, so garbage counts cannot wrap around and split the records out of range
+	for i := uint64(0); i < uint64(count)+uint64(orphanCount); i++ {
+		prefix := make([]byte, 8)
+		if _, err := io.ReadFull(r, prefix); err != nil {
+			return nil, nil, fmt.Errorf("cannot read record %d: %v", i, err)
+		}
+		size := cm.MachineEndian.Uint32(prefix)
+		// bounds the memory allocated for a record, in case the size of a corrupt file is garbage
+		if size > blockchain.MaxTxMsgSize {
+			return nil, nil, fmt.Errorf("record %d is %d bytes, exceeding the limit of %d", i, size, blockchain.MaxTxMsgSize)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, nil, fmt.Errorf("cannot read record %d: %v", i, err)
+		}
+		if checksum := crc32.ChecksumIEEE(data); checksum != cm.MachineEndian.Uint32(prefix[4:]) {
+			return nil, nil, fmt.Errorf("wrong checksum %x of record %d", checksum, i)
+		}
+		tx := &blockchain.Tx{}
+		if err := tx.Deserialize(data); err != nil {
+			return nil, nil, fmt.Errorf("cannot deserialize record %d: %v", i, err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs[:count], txs[count:], nil
+}
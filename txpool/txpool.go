@@ -10,14 +10,39 @@ import (
 	"container/heap"
 	"container/list"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/golang/glog"
+	"github.com/pkg/errors"
 
 	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/common/routine"
+	"github.com/iotexproject/iotex-core/config"
 	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+var log = logger.New("txpool")
+
+var (
+	// ErrPoolStopped is the error returned when adding a tx to the pool which has been stopped
+	ErrPoolStopped = errors.New("tx pool is stopped")
+	// ErrInvalidTx is the error returned when adding a tx which can never be valid, as opposed to a tx rejected by the
+	// pool policy or depending on the current UTXO set
+	ErrInvalidTx = errors.New("invalid transaction")
+	// ErrDuplicateTx is the error returned when adding a tx already in the pool
+	ErrDuplicateTx = errors.New("duplicate transaction")
+	// ErrMissingInputs is the error returned when accepting a tx spending UTXO neither confirmed nor created by the
+	// accepted txs
+	ErrMissingInputs = errors.New("transaction inputs are missing")
+	// ErrInsufficientFee is the error returned when adding a tx not paying the fee required by the pool
+	ErrInsufficientFee = errors.New("insufficient transaction fee")
+	// ErrDoubleSpend is the error returned when adding a tx spending UTXO already spent by the accepted txs, which it
+	// cannot replace
+	ErrDoubleSpend = errors.New("transaction double spends")
 )
 
 // Basic constant settings for TxPool
@@ -28,6 +53,8 @@ const (
 	maxOrphanTxSize            = 8192
 	enableTagIndex             = false
 	DefaultBlockPrioritySize   = 12345
+	// maxReplacedTxNum is the max number of txs, including the descendants, a replacing tx may evict
+	maxReplacedTxNum = 100
 )
 
 // Tag for OrphanTx
@@ -44,6 +71,21 @@ type TxDesc struct {
 	idx         int
 }
 
+// TxVerdict is the outcome of validating a tx against the chain and the pool without adding it, see ValidateTransaction
+type TxVerdict struct {
+	// Err is nil if the tx would be accepted, otherwise its cause tells why the tx would be rejected like the error of
+	// AcceptTransaction
+	Err error
+	// Size is the size of the tx, Fee the fee it pays and MinFee the one the pool requires for its size
+	Size   uint32
+	Fee    int64
+	MinFee int64
+	// MissingInputs are the txs whose outputs the tx spends, which are neither confirmed nor in the pool
+	MissingInputs []cp.Hash32B
+	// UnsignedInputs are the indexes of the inputs which cannot unlock the UTXO they spend, e.g., not signed yet
+	UnsignedInputs []int
+}
+
 type orphanTx struct {
 	Tag            Tag
 	Tx             *blockchain.Tx
@@ -68,6 +110,10 @@ func NewTxSourcePointer(in *blockchain.TxInput) TxSourcePointer {
 
 // TxPool is a pool of received txs
 type TxPool interface {
+	// Start loads the txs saved by the last run and starts saving the pool periodically
+	Start() error
+	// Stop rejects the txs received from now on, stops saving the pool periodically and saves it for the next run
+	Stop() error
 	// RemoveOrphanTx remove an orphan transaction, but not its descendants
 	RemoveOrphanTx(tx *blockchain.Tx)
 	// RemoveOrphanTxsByTag remove all the orphan transactions with tag
@@ -90,18 +136,53 @@ type TxPool interface {
 	ProcessTx(tx *blockchain.Tx, allowOrphan bool, rateLimit bool, tag Tag) ([]*TxDesc, error)
 	// TxDescs return all the transaction descs
 	TxDescs() []*TxDesc
-	// Txs return all Transactions
+	// Txs return the accepted transactions ready to be mined, holding back those spending outputs of other accepted
+	// transactions until their parents are mined
 	Txs() []*blockchain.Tx
-	// RemoveTxInBlock remove all transactions in a block
+	// RemoveTxInBlock remove all transactions in a block and the ones conflicting with them, admit the orphan
+	// transactions waiting for them and evict the expired transactions
 	RemoveTxInBlock(block *blockchain.Block) error
 	// LastTimePoolUpdated get the last time the pool got updated
 	LastTimePoolUpdated() time.Time
+	// PendingBalanceOf returns the balance of the address once the accepted transactions are confirmed
+	PendingBalanceOf(address string) uint64
+	// PendingByAddress returns the accepted transactions paying the address or spending its UTXO, oldest first
+	PendingByAddress(address string) []*TxDesc
+	// AncestorPackage returns the accepted transaction with the hash along with its unmined ancestors
+	AncestorPackage(hash cp.Hash32B) (*TxPackage, error)
+	// DescendantPackage returns the accepted transaction with the hash along with its descendants
+	DescendantPackage(hash cp.Hash32B) (*TxPackage, error)
+	// Conflicts returns the accepted transactions spending the same UTXO as the transaction along with their
+	// descendants
+	Conflicts(tx *blockchain.Tx) *TxPackage
+	// PendingFees returns the fees paid by the accepted transactions along with their sizes
+	PendingFees() []blockchain.FeeSample
+	// AcceptTransaction validates the transaction received from outside the network and adds it to the pool, the
+	// cause of the returned error tells why it is rejected
+	AcceptTransaction(tx *blockchain.Tx) (*TxDesc, error)
+	// ValidateTransaction runs the checks of AcceptTransaction on the transaction without adding it to the pool
+	ValidateTransaction(tx *blockchain.Tx) *TxVerdict
+	// AcceptAction validates the nonced action and adds it to the pool, queuing it until the actions of its sender with
+	// the nonces before its own are added, the cause of the returned error tells why it is rejected
+	AcceptAction(act blockchain.Action) error
+	// PendingNonce returns the nonce of the address once its confirmed and pending actions are executed
+	PendingNonce(address string) uint64
+	// Actions returns the pending actions ready to be executed in the next block, in the order of their nonces
+	Actions() []blockchain.Action
+	// SetLimits applies the fee, TTL and size limits of the config to the txs accepted from now on, the txs already in
+	// the pool are evicted by the new limits as they are checked next
+	SetLimits(cfg config.TxPool)
+	// Subscribe returns a channel on which the txs accepted into the pool are delivered
+	Subscribe() <-chan *TxDesc
+	// Unsubscribe stops delivering the accepted txs to the channel
+	Unsubscribe(ch <-chan *TxDesc)
 }
 
 // txPool implements TxPool interface
 // Note that all locks should be placed in public functions (no lock inside of any private function)
 type txPool struct {
-	bc blockchain.IBlockchain
+	bc  blockchain.IBlockchain
+	cfg *config.TxPool
 
 	lastUpdatedUnixTime    int64
 	mutex                  sync.RWMutex
@@ -112,18 +193,33 @@ type txPool struct {
 	txSourcePointers       map[TxSourcePointer]*blockchain.Tx
 	tags                   map[Tag]map[cp.Hash32B]*blockchain.Tx
 	nextExpirationScanTime time.Time
+	size                   uint64
+	task                   *routine.RecurringTask
+	// actions are the nonced actions, pending or queued, by sender and nonce
+	actions map[string]map[uint64]blockchain.NoncedAction
+	// stopped rejects the txs received once the pool is stopped, so the pool saved on stopping is final
+	stopped bool
+	// txs delivers the accepted txs to the subscribers
+	txs *txHub
 }
 
 // New creates a TxPool instance
-func New(bc blockchain.IBlockchain) TxPool {
-	return &txPool{
+func New(bc blockchain.IBlockchain, cfg *config.TxPool) TxPool {
+	tp := &txPool{
 		bc:                     bc,
+		cfg:                    cfg,
 		tags:                   make(map[Tag]map[cp.Hash32B]*blockchain.Tx),
 		txDescs:                make(map[cp.Hash32B]*TxDesc),
 		txSourcePointers:       make(map[TxSourcePointer]*blockchain.Tx),
 		orphanTxs:              make(map[cp.Hash32B]*orphanTx),
 		orphanTxSourcePointers: make(map[TxSourcePointer]map[cp.Hash32B]*blockchain.Tx),
+		actions:                make(map[string]map[uint64]blockchain.NoncedAction),
+		txs:                    newTxHub(),
+	}
+	if cfg.PersistPath != "" && cfg.PersistInterval > 0 {
+		tp.task = routine.NewRecurringTask(&persister{tp}, cfg.PersistInterval)
 	}
+	return tp
 }
 
 // remove an orphan transaction, and all the descendant orphan transactions if removeDescendants is true
@@ -131,7 +227,7 @@ func (tp *txPool) removeOrphanTx(tx *blockchain.Tx, removeDescendants bool) {
 	hash := tx.Hash()
 	orphanTx, ok := tp.orphanTxs[hash]
 	if !ok {
-		glog.Info("cannot find orphan tx: ", hash)
+		log.Info("cannot find orphan tx: ", hash)
 		return
 	}
 
@@ -169,6 +265,7 @@ func (tp *txPool) removeOrphanTx(tx *blockchain.Tx, removeDescendants bool) {
 	}
 	// WARNING: is it possible that the hash is deleted twice?
 	delete(tp.orphanTxs, hash)
+	tp.updateMetrics()
 }
 
 // RemoveOrphanTx Remove an orphan transaction, but not its descendants
@@ -214,7 +311,7 @@ func (tp *txPool) deleteExpiredOrphanTxs() {
 		}
 	}
 	tp.nextExpirationScanTime = now.Add(orphanTxExpireScanInterval)
-	glog.Info("scan and delete expired orphan transactions")
+	log.Info("scan and delete expired orphan transactions")
 }
 
 func (tp *txPool) emptyASpaceForNewOrphanTx() error {
@@ -256,7 +353,8 @@ func (tp *txPool) addOrphanTx(tx *blockchain.Tx, tag Tag) {
 		}
 		tp.orphanTxSourcePointers[txSourcePointer][hash] = tx
 	}
-	glog.Info("Add orphan tx %x to pool", hash)
+	tp.updateMetrics()
+	log.Infof("Add orphan tx %x to pool", hash)
 }
 
 func (tp *txPool) maybeAddOrphanTx(tx *blockchain.Tx, tag Tag) error {
@@ -346,11 +444,14 @@ func (tp *txPool) removeTx(tx *blockchain.Tx, removeDescendants bool) {
 	for _, txIn := range desc.Tx.TxIn {
 		delete(tp.txSourcePointers, NewTxSourcePointer(txIn))
 	}
+	tp.bc.ReleaseTxInputs(desc.Tx)
+	tp.size -= uint64(desc.Tx.TotalSize())
 
 	// Use the heap built-in Remove() to remove TxDesc pointer from txDescPriorityQueue
 	heap.Remove(&tp.txDescPriorityQueue, desc.idx)
 	delete(tp.txDescs, hash)
 	tp.setLastUpdateUnixTime()
+	tp.updateMetrics()
 }
 
 // RemoveTx removes tx from the pool
@@ -375,7 +476,16 @@ func (tp *txPool) RemoveDoubleSpends(tx *blockchain.Tx) {
 	tp.mutex.Unlock()
 }
 
-func (tp *txPool) addTx(utxoTracker *blockchain.UtxoTracker, tx *blockchain.Tx, height uint32, fee int64) *TxDesc {
+// feeRate returns the fee per byte of the serialized tx, which is the priority of the tx in the pool
+func feeRate(tx *blockchain.Tx, fee int64) float64 {
+	serialize, err := tx.Serialize()
+	if err != nil || len(serialize) == 0 {
+		return 0
+	}
+	return float64(fee) / float64(len(serialize))
+}
+
+func (tp *txPool) addTx(tx *blockchain.Tx, height uint32, fee int64) *TxDesc {
 	serialize, err := tx.Serialize()
 	if err != nil {
 		return nil
@@ -386,28 +496,170 @@ func (tp *txPool) addTx(utxoTracker *blockchain.UtxoTracker, tx *blockchain.Tx,
 		BlockHeight: height,
 		Fee:         fee,
 		FeePerKB:    fee * 1000 / int64(len(serialize)),
-		Priority:    float64(fee),
+		Priority:    float64(fee) / float64(len(serialize)),
 	}
 	tp.txDescs[tx.Hash()] = &desc
 	heap.Push(&tp.txDescPriorityQueue, &desc)
+	tp.size += uint64(tx.TotalSize())
 	for _, txIn := range tx.TxIn {
 		tp.txSourcePointers[NewTxSourcePointer(txIn)] = tx
 	}
+	// the UTXO spent by pending transactions are held until the transaction leaves the pool
+	tp.bc.ReserveTxInputs(tx, 0)
 	tp.setLastUpdateUnixTime()
+	tp.updateMetrics()
+	tp.txs.publish(&desc)
 
 	return &desc
 }
 
-// Check whether any of tx's inputs have been spent by other transactions
-func (tp *txPool) checkPoolDoubleSpend(tx *blockchain.Tx) error {
+// Check whether any of tx's inputs have been spent by other transactions, and return those transactions
+// The double spends are allowed only if all of them signal replaceability, in which case tx may replace them.
+func (tp *txPool) checkPoolDoubleSpend(tx *blockchain.Tx) ([]*blockchain.Tx, error) {
+	conflicts := []*blockchain.Tx{}
+	found := make(map[cp.Hash32B]bool)
 	for _, txIn := range tx.TxIn {
 		txSourcePointer := NewTxSourcePointer(txIn)
-		if txSpend, ok := tp.txSourcePointers[txSourcePointer]; ok {
-			return fmt.Errorf("%v has already been spent by %v", txSourcePointer, txSpend.Hash())
+		txSpend, ok := tp.txSourcePointers[txSourcePointer]
+		if !ok || found[txSpend.Hash()] {
+			continue
+		}
+		if !IsReplaceable(txSpend) {
+			return nil, errors.Wrapf(ErrDoubleSpend, "%v has already been spent by %x", txSourcePointer, txSpend.Hash())
 		}
+		found[txSpend.Hash()] = true
+		conflicts = append(conflicts, txSpend)
 	}
 
-	return nil
+	return conflicts, nil
+}
+
+// IsReplaceable checks whether the tx opts in to be replaced in the pool, by setting the replaceable flag in the
+// sequence of any of its inputs
+func IsReplaceable(tx *blockchain.Tx) bool {
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence&blockchain.SequenceReplaceable != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReplacement returns the txs to evict for tx paying the fee to replace the conflicting txs, which are the
+// conflicts and all their descendants
+// The tx has to pay a higher fee rate than each conflict, and more fee than all the evicted txs together by at least
+// the min replacement fee rate for its own size.
+func (tp *txPool) checkReplacement(tx *blockchain.Tx, fee int64, size uint32, conflicts []*blockchain.Tx) ([]*TxDesc, error) {
+	evicted := make(map[cp.Hash32B]*TxDesc)
+	for _, conflict := range conflicts {
+		tp.collectDescendants(conflict.Hash(), evicted)
+	}
+	if len(evicted) > maxReplacedTxNum {
+		return nil, errors.Wrapf(ErrDoubleSpend, "tx %x would evict %d txs, more than the limit %d", tx.Hash(), len(evicted), maxReplacedTxNum)
+	}
+	for _, txIn := range tx.TxIn {
+		if _, ok := evicted[NewTxSourcePointer(txIn).Hash]; ok {
+			return nil, errors.Wrapf(ErrDoubleSpend, "tx %x spends an output of the tx it replaces", tx.Hash())
+		}
+	}
+
+	priority := feeRate(tx, fee)
+	total := int64(0)
+	descs := make([]*TxDesc, 0, len(evicted))
+	for _, desc := range evicted {
+		total += desc.Fee
+		descs = append(descs, desc)
+	}
+	for _, conflict := range conflicts {
+		if desc := evicted[conflict.Hash()]; priority <= desc.Priority {
+			return nil, errors.Wrapf(ErrInsufficientFee, "tx %x does not pay a higher fee rate than tx %x it replaces", tx.Hash(), conflict.Hash())
+		}
+	}
+	if minFee := total + int64(tp.cfg.MinReplacementFeePerByte)*int64(size); fee <= total || fee < minFee {
+		return nil, errors.Wrapf(ErrInsufficientFee, "fee %d is lower than min replacement fee %d", fee, minFee)
+	}
+	return descs, nil
+}
+
+// collectDescendants adds the accepted tx with the hash and all txs spending its outputs, recursively, to descs
+func (tp *txPool) collectDescendants(hash cp.Hash32B, descs map[cp.Hash32B]*TxDesc) {
+	desc, ok := tp.txDescs[hash]
+	if !ok {
+		return
+	}
+	if _, ok := descs[hash]; ok {
+		return
+	}
+	descs[hash] = desc
+	txSourcePointer := TxSourcePointer{Hash: hash}
+	for index := range desc.Tx.TxOut {
+		txSourcePointer.Index = int32(index)
+		if tx, ok := tp.txSourcePointers[txSourcePointer]; ok {
+			tp.collectDescendants(tx.Hash(), descs)
+		}
+	}
+}
+
+// isFull checks whether a tx of the size exceeds the max pool size
+func (tp *txPool) isFull(size uint32) bool {
+	return tp.cfg.MaxPoolSize > 0 && tp.size+uint64(size) > tp.cfg.MaxPoolSize
+}
+
+// checkSpace adds to evicted the txs paying the lowest fee rate, with their descendants, until tx of the size and
+// fee rate fits in the pool, on top of the txs already evicted
+// It returns error if tx does not pay a higher fee rate than the txs to evict, or would lose its parents.
+func (tp *txPool) checkSpace(tx *blockchain.Tx, priority float64, size uint32, evicted map[cp.Hash32B]*TxDesc) error {
+	if tp.cfg.MaxPoolSize == 0 {
+		return nil
+	}
+	freed := uint64(0)
+	for _, desc := range evicted {
+		freed += uint64(desc.Tx.TotalSize())
+	}
+	if tp.size-freed+uint64(size) <= tp.cfg.MaxPoolSize {
+		return nil
+	}
+
+	candidates := make([]*TxDesc, len(tp.txDescPriorityQueue))
+	copy(candidates, tp.txDescPriorityQueue)
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Priority < candidates[j].Priority })
+	for _, desc := range candidates {
+		if _, ok := evicted[desc.Tx.Hash()]; ok {
+			continue
+		}
+		if desc.Priority >= priority {
+			break
+		}
+		descendants := make(map[cp.Hash32B]*TxDesc)
+		tp.collectDescendants(desc.Tx.Hash(), descendants)
+		for hash, descendant := range descendants {
+			if _, ok := evicted[hash]; !ok {
+				evicted[hash] = descendant
+				freed += uint64(descendant.Tx.TotalSize())
+			}
+		}
+		if tp.size-freed+uint64(size) <= tp.cfg.MaxPoolSize {
+			for _, txIn := range tx.TxIn {
+				if _, ok := evicted[NewTxSourcePointer(txIn).Hash]; ok {
+					return fmt.Errorf("tx %x spends an output of the tx evicted to make space", tx.Hash())
+				}
+			}
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrInsufficientFee, "pool is full, tx %x does not pay a higher fee rate than the txs to evict", tx.Hash())
+}
+
+// deleteExpiredTxs removes the accepted txs, with their descendants, which stayed in the pool longer than the tx TTL
+// or cannot be included at the height any more
+func (tp *txPool) deleteExpiredTxs(height uint32) {
+	now := time.Now()
+	for _, desc := range tp.txDescs {
+		if desc.Tx.IsExpired(height) || (tp.cfg.TxTTL > 0 && now.Sub(desc.AddedTime) > tp.cfg.TxTTL) {
+			log.Infof("Evict expired tx %x", desc.Tx.Hash())
+			tp.removeTx(desc.Tx, true)
+		}
+	}
 }
 
 // IsFullySpent Check whether the output txs have been fully spent
@@ -415,27 +667,26 @@ func IsFullySpent(outputs []*blockchain.TxOutput) bool {
 	return false
 }
 
-func (tp *txPool) fetchInputUtxos(tx *blockchain.Tx) (*blockchain.UtxoTracker, error) {
-	utxoTracker := blockchain.NewUtxoTracker()
-	utxoPool := tp.bc.UtxoPool()
+// fetchInputUtxos returns a view of the UTXO pool of the chain, with the outputs of the txs in the pool added for the
+// inputs of tx spending them
+// The view leaves the UTXO pool untouched, and is dropped once the tx is checked.
+func (tp *txPool) fetchInputUtxos(tx *blockchain.Tx) *blockchain.UtxoView {
+	view := tp.bc.NewUtxoView()
 	for _, txIn := range tx.TxIn {
 		hash := cp.ZeroHash32B
 		copy(hash[:], txIn.TxHash)
-		outputs := utxoPool[hash]
-		utxoTracker.GetPool()[hash] = outputs
-	}
-
-	// attempt to populate any missing input from the transaction pool
-	for hash, outputs := range utxoTracker.GetPool() {
-		if outputs != nil && !IsFullySpent(outputs) {
+		if outputs := view.Entry(hash); outputs != nil && !IsFullySpent(outputs) {
 			continue
 		}
+		// attempt to populate any missing input from the transaction pool
+		// the input is left missing if the outputs of the tx in the pool overflow the view
 		if desc, ok := tp.txDescs[hash]; ok {
-			utxoTracker.AddTx(desc.Tx, 0)
+			if err := view.AddTx(desc.Tx); err != nil {
+				log.Warningf("cannot add tx %x to the view: %v", hash, err)
+			}
 		}
 	}
-
-	return utxoTracker, nil
+	return view
 }
 
 // FetchTx gets the tx with the given hash
@@ -450,59 +701,110 @@ func (tp *txPool) FetchTx(hash *cp.Hash32B) (*blockchain.Tx, error) {
 	return nil, fmt.Errorf("cannot find transaction in the pool")
 }
 
-func calculateMinFee(size uint32) int64 {
-	return 0
+// calculateMinFee returns the minimum fee a transaction of given size has to pay
+func (tp *txPool) calculateMinFee(size uint32) int64 {
+	return int64(tp.cfg.MinTxFeePerByte) * int64(size)
 }
 
-func (tp *txPool) maybeAcceptTx(tx *blockchain.Tx, isNew bool, rateLimit bool, rejectDuplicateOrphanTxs bool) ([]cp.Hash32B, *TxDesc, error) {
+// txCheck is the outcome of checking a tx against the chain and the pool, filled in as far as the checks went
+type txCheck struct {
+	size           uint32
+	utxoView       *blockchain.UtxoView
+	missingParents []cp.Hash32B
+	fee            int64
+	// evicted are the txs to remove from the pool to accept the tx, the ones it replaces or the ones making space
+	evicted map[cp.Hash32B]*TxDesc
+}
+
+// checkTx runs all the checks of accepting the tx into the pool without changing the pool, the tx is an orphan if the
+// returned check has missing parents
+func (tp *txPool) checkTx(tx *blockchain.Tx, rejectDuplicateOrphanTxs bool) (*txCheck, error) {
 	hash := tx.Hash()
+	c := &txCheck{size: tx.TotalSize()}
 	if tp.hasTx(hash) || (rejectDuplicateOrphanTxs && tp.hasOrphanTx(hash)) {
-		return nil, nil, fmt.Errorf("duplicate transaction")
+		return c, errors.Wrapf(ErrDuplicateTx, "tx %x", hash)
 	}
-	if tx.IsCoinbase() {
-		return nil, nil, fmt.Errorf("unexpected coinbase transaction")
+	if err := tp.bc.CheckTransaction(tx); err != nil {
+		return c, errors.Wrap(ErrInvalidTx, err.Error())
 	}
 
-	err := tp.checkPoolDoubleSpend(tx)
+	conflicts, err := tp.checkPoolDoubleSpend(tx)
 	if err != nil {
-		return nil, nil, err
+		return c, err
 	}
 
-	utxoTracker, err := tp.fetchInputUtxos(tx)
-	if err != nil {
-		// if it is chain rule error
-		//   return chain rule error
-		return nil, nil, err
+	view := tp.fetchInputUtxos(tx)
+	missing := make(map[cp.Hash32B]bool)
+	for _, txIn := range tx.TxIn {
+		originHash := cp.ZeroHash32B
+		copy(originHash[:], txIn.TxHash)
+		if originHash == hash || missing[originHash] {
+			continue
+		}
+		if outputs := view.Entry(originHash); outputs == nil || IsFullySpent(outputs) {
+			missing[originHash] = true
+			c.missingParents = append(c.missingParents, originHash)
+		}
 	}
-
-	outputs := utxoTracker.GetPool()[hash]
-	if outputs != nil && !IsFullySpent(outputs) {
-		// return nil, nil, fmt.Errorf("duplicate transaction")
+	if len(c.missingParents) > 0 {
+		return c, nil
+	}
+	c.utxoView = view
+	if err := tp.bc.ValidateTxChainID(tx); err != nil {
+		return c, errors.Wrap(ErrInvalidTx, err.Error())
+	}
+	if err := view.ValidateTxScripts(tx); err != nil {
+		return c, errors.Wrap(ErrInvalidTx, err.Error())
+	}
+	if err := tp.bc.ValidateLockTime(tx); err != nil {
+		return c, err
+	}
+	if err := tp.bc.ValidateCoinbaseMaturity(tx); err != nil {
+		return c, err
 	}
-	delete(utxoTracker.GetPool(), hash)
 
-	var missingParents []cp.Hash32B
-	for originHash, outputs := range utxoTracker.GetPool() {
-		if outputs == nil || IsFullySpent(outputs) {
-			missingParents = append(missingParents, originHash)
-		}
+	txFee, err := view.TxFee(tx)
+	if err != nil {
+		return c, errors.Wrap(ErrInvalidTx, err.Error())
 	}
-	if len(missingParents) > 0 {
-		return missingParents, nil, nil
+	c.fee = int64(txFee)
+	if minFee := tp.calculateMinFee(c.size); c.fee < minFee {
+		return c, errors.Wrapf(ErrInsufficientFee, "fee %d is lower than min requirement fee %d", c.fee, minFee)
 	}
-	if int64(tx.LockTime) > time.Now().Unix() {
-		return nil, nil, fmt.Errorf("tx %s is still in lock", hash)
+	c.evicted = make(map[cp.Hash32B]*TxDesc)
+	if len(conflicts) > 0 {
+		replaced, err := tp.checkReplacement(tx, c.fee, c.size, conflicts)
+		if err != nil {
+			return c, err
+		}
+		for _, desc := range replaced {
+			c.evicted[desc.Tx.Hash()] = desc
+		}
 	}
+	if err := tp.checkSpace(tx, feeRate(tx, c.fee), c.size, c.evicted); err != nil {
+		return c, err
+	}
+	return c, nil
+}
 
-	fee := int64(0)
-	size := tx.TotalSize()
-	minFee := calculateMinFee(size)
-	if (size >= DefaultBlockPrioritySize-1000) && fee < minFee {
-		return nil, nil, fmt.Errorf("fee is lower than min requirement fee")
+func (tp *txPool) maybeAcceptTx(tx *blockchain.Tx, isNew bool, rateLimit bool, rejectDuplicateOrphanTxs bool) ([]cp.Hash32B, *TxDesc, error) {
+	if tp.isFull(tx.TotalSize()) {
+		tp.deleteExpiredTxs(tp.bc.TipHeight() + 1)
+	}
+	c, err := tp.checkTx(tx, rejectDuplicateOrphanTxs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(c.missingParents) > 0 {
+		return c.missingParents, nil, nil
+	}
+	for _, desc := range c.evicted {
+		log.Infof("Evict tx %x to accept tx %x", desc.Tx.Hash(), tx.Hash())
+		tp.removeTx(desc.Tx, false)
 	}
 
 	height := tp.bc.TipHeight()
-	txDesc := tp.addTx(utxoTracker, tx, height, fee)
+	txDesc := tp.addTx(tx, height, c.fee)
 
 	return nil, txDesc, nil
 }
@@ -510,6 +812,10 @@ func (tp *txPool) maybeAcceptTx(tx *blockchain.Tx, isNew bool, rateLimit bool, r
 // MaybeAcceptTx Add Tx into pool if it will be accepted
 func (tp *txPool) MaybeAcceptTx(tx *blockchain.Tx, isNew bool, rateLimit bool) ([]cp.Hash32B, *TxDesc, error) {
 	tp.mutex.Lock()
+	if tp.stopped {
+		tp.mutex.Unlock()
+		return nil, nil, ErrPoolStopped
+	}
 	hashes, desc, error := tp.maybeAcceptTx(tx, isNew, rateLimit, true)
 	tp.mutex.Unlock()
 
@@ -575,6 +881,9 @@ func (tp *txPool) ProcessTx(tx *blockchain.Tx, allowOrphan bool, rateLimit bool,
 	// Protect concurrent access.
 	tp.mutex.Lock()
 	defer tp.mutex.Unlock()
+	if tp.stopped {
+		return nil, ErrPoolStopped
+	}
 
 	missingParents, desc, err := tp.maybeAcceptTx(
 		tx,
@@ -607,6 +916,60 @@ func (tp *txPool) ProcessTx(tx *blockchain.Tx, allowOrphan bool, rateLimit bool,
 	return nil, err
 }
 
+// AcceptTransaction validates the tx received from outside the network, such as one signed by a wallet, and adds it to
+// the pool along with the orphan txs waiting for it
+// Unlike a tx relayed by the peers, the tx is not held as orphan if its inputs are missing. The cause of the returned
+// error is ErrInvalidTx if the tx fails the stateless checks or the script verification, ErrMissingInputs,
+// ErrInsufficientFee, ErrDoubleSpend, ErrDuplicateTx, or the error of the blockchain if the tx is locked, expired or
+// spends immature coinbase.
+func (tp *txPool) AcceptTransaction(tx *blockchain.Tx) (*TxDesc, error) {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+	if tp.stopped {
+		return nil, ErrPoolStopped
+	}
+	missingParents, desc, err := tp.maybeAcceptTx(tx, true, false, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(missingParents) > 0 {
+		return nil, errors.Wrapf(ErrMissingInputs, "tx %x spends outputs of unknown tx %x", tx.Hash(), missingParents[0])
+	}
+	tp.processOrphanTxs(tx)
+	return desc, nil
+}
+
+// ValidateTransaction runs all the checks of AcceptTransaction on the tx against the chain and the pool without adding
+// it anywhere, and returns the verdict detailing why it would be rejected
+func (tp *txPool) ValidateTransaction(tx *blockchain.Tx) *TxVerdict {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	v := &TxVerdict{}
+	if tp.stopped {
+		v.Err = ErrPoolStopped
+		return v
+	}
+	c, err := tp.checkTx(tx, true)
+	v.Size = c.size
+	v.MinFee = tp.calculateMinFee(c.size)
+	v.MissingInputs = c.missingParents
+	v.Err = err
+	if len(c.missingParents) > 0 {
+		v.Err = errors.Wrapf(ErrMissingInputs, "tx %x spends outputs of unknown tx %x", tx.Hash(), c.missingParents[0])
+	}
+	if c.utxoView == nil {
+		return v
+	}
+	// the fee and the signatures are told even if the tx fails on them or an earlier check
+	if fee, err := c.utxoView.TxFee(tx); err == nil {
+		v.Fee = int64(fee)
+	}
+	if unsigned, err := c.utxoView.UnsignedInputs(tx); err == nil {
+		v.UnsignedInputs = unsigned
+	}
+	return v
+}
+
 // Count The number of accepted txs in the pool
 func (tp *txPool) count() int {
 	tp.mutex.RLock()
@@ -630,27 +993,77 @@ func (tp *txPool) TxDescs() []*TxDesc {
 	return txDescs
 }
 
-// Txs returns the list of accepted txs
-func (tp *txPool) Txs() []*blockchain.Tx {
+// PendingFees returns the fees paid by the accepted txs along with their serialized sizes, which is the backlog the fee
+// estimation of the blockchain accounts for
+func (tp *txPool) PendingFees() []blockchain.FeeSample {
 	tp.mutex.RLock()
-	tx := make([]*blockchain.Tx, len(tp.txDescs))
-	i := 0
+	defer tp.mutex.RUnlock()
+	samples := make([]blockchain.FeeSample, 0, len(tp.txDescs))
 	for _, desc := range tp.txDescs {
-		tx[i] = desc.Tx
-		i++
+		serialize, err := desc.Tx.Serialize()
+		if err != nil {
+			continue
+		}
+		samples = append(samples, blockchain.FeeSample{Fee: uint64(desc.Fee), Size: uint32(len(serialize))})
+	}
+	return samples
+}
+
+// Txs returns the list of accepted txs ready to be mined, ordered by fee rate from high to low
+// A block can only spend confirmed UTXO, so the txs spending outputs of other accepted txs are held in the pool until
+// their parents are mined.
+func (tp *txPool) Txs() []*blockchain.Tx {
+	tp.mutex.RLock()
+	pq := make(txDescPriorityQueue, 0, len(tp.txDescPriorityQueue))
+	for _, desc := range tp.txDescPriorityQueue {
+		if !tp.hasUnminedParent(desc.Tx) {
+			pq = append(pq, desc)
+		}
 	}
 	tp.mutex.RUnlock()
 
+	sort.SliceStable(pq, func(i, j int) bool { return pq[i].Priority > pq[j].Priority })
+	tx := make([]*blockchain.Tx, len(pq))
+	for i, desc := range pq {
+		tx[i] = desc.Tx
+	}
 	return tx
 }
 
+// hasUnminedParent checks whether the tx spends an output of another accepted tx
+func (tp *txPool) hasUnminedParent(tx *blockchain.Tx) bool {
+	for _, txIn := range tx.TxIn {
+		if tp.hasTx(NewTxSourcePointer(txIn).Hash) {
+			return true
+		}
+	}
+	return false
+}
+
 // RemoveTxInBlock removes the transaction in the block from pool
+// The descendants of the mined txs stay in the pool, as their inputs are now confirmed, while the txs spending the
+// same UTXO as the mined txs are removed along with their descendants. Orphan txs waiting for the outputs of the
+// mined txs are admitted, and the txs which expired or outlived the tx TTL are evicted. The actions whose nonces are
+// confirmed by the block are removed.
 func (tp *txPool) RemoveTxInBlock(block *blockchain.Block) error {
 	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
 	for _, tx := range block.Tranxs {
-		tp.removeTx(tx, true)
+		hash := tx.Hash()
+		tp.removeTx(tx, false)
+		for _, txIn := range tx.TxIn {
+			if txSpend, ok := tp.txSourcePointers[NewTxSourcePointer(txIn)]; ok && txSpend.Hash() != hash {
+				tp.removeTx(txSpend, true)
+			}
+		}
 	}
-	tp.mutex.Unlock()
+	for _, tx := range block.Tranxs {
+		for _, desc := range tp.processOrphanTxs(tx) {
+			log.Infof("Admit orphan tx %x once its parent %x is mined", desc.Tx.Hash(), tx.Hash())
+		}
+	}
+	tp.deleteExpiredTxs(block.Height() + 1)
+	tp.removeConfirmedActions(block)
 	return nil
 }
 
@@ -658,3 +1071,44 @@ func (tp *txPool) RemoveTxInBlock(block *blockchain.Block) error {
 func (tp *txPool) LastTimePoolUpdated() time.Time {
 	return time.Unix(atomic.LoadInt64(&tp.lastUpdatedUnixTime), 0)
 }
+
+// SetLimits replaces MinTxFeePerByte, MinReplacementFeePerByte, TxTTL and MaxPoolSize of the pool config the pool
+// was created with, the other fields only apply on startup
+func (tp *txPool) SetLimits(cfg config.TxPool) {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	tp.cfg.MinTxFeePerByte = cfg.MinTxFeePerByte
+	tp.cfg.MinReplacementFeePerByte = cfg.MinReplacementFeePerByte
+	tp.cfg.TxTTL = cfg.TxTTL
+	tp.cfg.MaxPoolSize = cfg.MaxPoolSize
+}
+
+// PendingBalanceOf returns the confirmed balance of the address plus the outputs paying it and minus the UTXO it
+// spends in the accepted transactions
+func (tp *txPool) PendingBalanceOf(address string) uint64 {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	key := iotxaddress.GetPubkeyHash(address)
+	credit, debit := uint64(0), uint64(0)
+	for _, desc := range tp.txDescs {
+		for _, txOut := range desc.Tx.TxOut {
+			if txOut.IsLockedWithKey(key) {
+				credit += txOut.Value
+			}
+		}
+		view := tp.fetchInputUtxos(desc.Tx)
+		for _, txIn := range desc.Tx.TxIn {
+			if utxo := view.TxInputUtxo(txIn); utxo != nil && utxo.IsLockedWithKey(key) {
+				debit += utxo.Value
+			}
+		}
+	}
+
+	balance := tp.bc.BalanceOf(address, 0) + credit
+	if balance < debit {
+		return 0
+	}
+	return balance - debit
+}
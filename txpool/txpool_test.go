@@ -7,16 +7,26 @@
 package txpool
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
+	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/net/context"
 
 	. "github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
 	"github.com/iotexproject/iotex-core/proto"
 	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/test/testutil"
+	"github.com/iotexproject/iotex-core/txvm"
+	"github.com/iotexproject/iotex-core/wallet"
 )
 
 const (
@@ -24,6 +34,18 @@ const (
 	testDBPath        = "db.test"
 )
 
+// signTx signs the only input of the tx, spending the UTXO, with the key of the owner
+func signTx(tx *Tx, utxo *iproto.TxOutputPb, owner string) (*Tx, error) {
+	hash := tx.SigHash(0, utxo)
+	unlock, err := txvm.SignatureScript(hash[:], ta.Addrinfo[owner].PublicKey, ta.Addrinfo[owner].PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	tx.TxIn[0].UnlockScript = unlock
+	tx.TxIn[0].UnlockScriptSize = uint32(len(unlock))
+	return tx, nil
+}
+
 func decodeHash(in string) []byte {
 	hash, _ := hex.DecodeString(in)
 	return hash
@@ -40,8 +62,9 @@ func TestTxPool(t *testing.T) {
 	config.Chain.BlockReward = 0
 
 	// Create a blockchain from scratch
-	// bc := CreateBlockchain(Addrinfo["miner"].Address, &config.Config{Chain: config.Chain{ChainDBPath: testDBPath}})
-	bc := CreateBlockchain(ta.Addrinfo["miner"].Address, config)
+	// bc := CreateBlockchain(context.Background(), Addrinfo["miner"].Address, &config.Config{Chain: config.Chain{ChainDBPath: testDBPath}})
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, config)
+	assert.Nil(err)
 	//ctrl := gomock.NewController(t)
 	//defer ctrl.Finish()
 
@@ -51,7 +74,7 @@ func TestTxPool(t *testing.T) {
 	}
 	defer bc.Close()
 
-	tp := New(bc)
+	tp := New(bc, &config.TxPool)
 	cbTx := NewCoinbaseTx(ta.Addrinfo["miner"].Address, 50, GenesisCoinbaseData)
 	if _, err := tp.ProcessTx(cbTx, true, false, 13245); assert.NotNil(err) {
 		t.Logf("Coinbase Tx cannot be processed")
@@ -111,10 +134,8 @@ func TestTxPool(t *testing.T) {
 			return
 		} //*/
 	txIn1_0 := &iproto.TxInputPb{
-		TxHash:           decodeHash("9de6306b08158c423330f7a27243a1a5cbe39bfd764f07818437882d21241567"),
-		OutIndex:         0,
-		UnlockScriptSize: 98,
-		UnlockScript:     decodeHash("40f9ea2b1357dde55519246a6ad82c466b9f2b988ff81a7c2fb114c932d44f322ba2edd178c2326739638b536e5f803977c24332b8f5b8ebc5f6683ff2bcaad90720b9b8d7316705dc4ff62bb323e610f3f5072abedc9834e999d6537f6681284ea2"),
+		TxHash:   decodeHash("9de6306b08158c423330f7a27243a1a5cbe39bfd764f07818437882d21241567"),
+		OutIndex: 0,
 	}
 	txOut1_0 := NewTxOutput(10, 0)
 	txOut1_0.LockScriptSize = 25
@@ -145,12 +166,15 @@ func TestTxPool(t *testing.T) {
 		TxOut:    []*TxOutput{txOut1_0, txOut1_1, txOut1_2, txOut1_3, txOut1_4, txOut1_5, txOut1_6},
 		LockTime: 0,
 	}
+	var genesis cp.Hash32B
+	copy(genesis[:], txIn1_0.TxHash)
+	_, err = signTx(tx1, bc.UtxoPool()[genesis][0].TxOutputPb, "miner")
+	assert.Nil(err)
+	tx1Hash := tx1.Hash()
 
 	txIn2_0 := &iproto.TxInputPb{
-		TxHash:           decodeHash("aeedd06eb44f08abbcc72a2293aff580f13662fa59cc1b0aa4a15ee7c118e4eb"),
-		OutIndex:         0,
-		UnlockScriptSize: 98,
-		UnlockScript:     decodeHash("40535e20b5c5075fa80d6bff220aea755737e3787bfbc7122c0c45015f6c249fbca28d069dc028fad01fda2766ea90411aad38ce9a9de7c59a30e4bebc80b940002009d8c6fc6f5cb0a03df112da90486fad7cdece1501aaab658551f8afbe7f59ee"),
+		TxHash:   tx1Hash[:],
+		OutIndex: 0,
 	}
 	txOut2_0 := NewTxOutput(3, 0)
 	txOut2_0.LockScriptSize = 25
@@ -172,6 +196,8 @@ func TestTxPool(t *testing.T) {
 		TxOut:    []*TxOutput{txOut2_0, txOut2_1, txOut2_2, txOut2_3},
 		LockTime: 0,
 	}
+	_, err = signTx(tx2, txOut1_0.TxOutputPb, "alfa")
+	assert.Nil(err)
 
 	t.Logf("tx1 hash: %x", tx1.Hash())
 	t.Logf("tx2 hash: %x", tx2.Hash())
@@ -186,3 +212,734 @@ func TestTxPool(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal(2, len(tp.TxDescs()))
 }
+
+func TestTxPoolFee(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	// fund alfa and bravo
+	payees := []*Payee{NewPayee(ta.Addrinfo["alfa"].Address, 10), NewPayee(ta.Addrinfo["bravo"].Address, 20)}
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 30, payees)
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{tx}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	// alfa pays 1 as fee, bravo pays 3 as fee, by lowering the change
+	payees = []*Payee{NewPayee(ta.Addrinfo["charlie"].Address, 4)}
+	tx1, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["alfa"]), 4, payees)
+	assert.Nil(err)
+	tx1.TxOut[1].Value--
+	assert.Nil(bc.SignTransaction(tx1, wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+	tx2, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["bravo"]), 4, payees)
+	assert.Nil(err)
+	tx2.TxOut[1].Value -= 3
+	assert.Nil(bc.SignTransaction(tx2, wallet.NewKeySigner(ta.Addrinfo["bravo"])))
+
+	// transactions paying less than min fee are rejected
+	tp := New(bc, &config.TxPool{MinTxFeePerByte: 1})
+	_, err = tp.ProcessTx(tx1, false, false, 0)
+	assert.NotNil(err)
+	assert.Equal(0, len(tp.Txs()))
+
+	// transactions are ordered by fee rate
+	tp = New(bc, &config.TxPool{})
+	_, err = tp.ProcessTx(tx1, false, false, 0)
+	assert.Nil(err)
+	descs, err := tp.ProcessTx(tx2, false, false, 0)
+	assert.Nil(err)
+	assert.Equal(int64(3), descs[0].Fee)
+	txs := tp.Txs()
+	assert.Equal(2, len(txs))
+	assert.Equal(tx2.Hash(), txs[0].Hash())
+	assert.Equal(tx1.Hash(), txs[1].Hash())
+	fees := uint64(0)
+	for _, sample := range tp.PendingFees() {
+		fees += sample.Fee
+		assert.NotEqual(uint32(0), sample.Size)
+	}
+	assert.Equal(uint64(4), fees)
+
+	// fees are collected by the coinbase
+	blk, err = bc.MintNewBlock(txs, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Equal(uint64(4), blk.Tranxs[2].TxOut[0].Value)
+	assert.Nil(bc.ValidateBlock(blk))
+}
+
+func TestTxPoolCoinbaseMaturity(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 10
+	cfg.Chain.CoinbaseMaturity = 2
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["alfa"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	// alfa's block reward is not mature for the next block
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["alfa"]), 10, []*Payee{NewPayee(ta.Addrinfo["bravo"].Address, 10)})
+	assert.Nil(err)
+	tp := New(bc, &config.TxPool{})
+	_, err = tp.ProcessTx(tx, false, false, 0)
+	assert.NotNil(err)
+	assert.Equal(0, len(tp.Txs()))
+
+	blk, err = bc.MintNewBlock([]*Tx{}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	_, err = tp.ProcessTx(tx, false, false, 0)
+	assert.Nil(err)
+	assert.Equal(1, len(tp.Txs()))
+}
+
+func TestTxPoolLockTime(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 10
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	// the tx cannot be included before height 2
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{NewPayee(ta.Addrinfo["bravo"].Address, 10)})
+	assert.Nil(err)
+	tx.LockTime = 2
+	assert.Nil(bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo["miner"])))
+	tp := New(bc, &config.TxPool{})
+	_, err = tp.ProcessTx(tx, false, false, 0)
+	assert.Equal(ErrTxLocked, errors.Cause(err))
+	assert.Equal(0, len(tp.Txs()))
+
+	blk, err := bc.MintNewBlock([]*Tx{}, ta.Addrinfo["alfa"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	_, err = tp.ProcessTx(tx, false, false, 0)
+	assert.Nil(err)
+	assert.Equal(1, len(tp.Txs()))
+}
+
+func TestTxPoolReplaceableRelativeLock(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+	hashLock := sha256.Sum256([]byte("secret"))
+	htlc, err := bc.CreateHTLCTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 20, ta.Addrinfo["alfa"].Address, hashLock[:], 2)
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{htlc}, ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	// the refund flagged replaceable carries no relative lock time in its sequence, which does not satisfy the HTLC
+	refund, err := bc.RefundHTLC(wallet.NewKeySigner(ta.Addrinfo["miner"]), htlc.Hash(), 0)
+	assert.Nil(err)
+	refund.TxIn[0].Sequence = SequenceReplaceable
+	refund.TxIn[0].UnlockScript = nil
+	refund.TxIn[0].UnlockScriptSize = 0
+	digest := refund.SigHash(0, htlc.TxOut[0].TxOutputPb)
+	hash := blake2b.Sum256(digest[:])
+	sig, err := wallet.NewKeySigner(ta.Addrinfo["miner"]).Sign(hash[:])
+	assert.Nil(err)
+	unlock, err := txvm.HTLCRefundScriptWithSig(sig, ta.Addrinfo["miner"].PublicKey)
+	assert.Nil(err)
+	refund.TxIn[0].UnlockScript = unlock
+	refund.TxIn[0].UnlockScriptSize = uint32(len(unlock))
+	assert.Nil(bc.ValidateLockTime(refund))
+
+	tp := New(bc, &config.TxPool{})
+	_, err = tp.ProcessTx(refund, false, false, 0)
+	assert.Equal(ErrInvalidTx, errors.Cause(err))
+	assert.Equal(0, len(tp.Txs()))
+}
+
+func TestTxPoolPendingBalance(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	miner := bc.BalanceOf(ta.Addrinfo["miner"].Address, 0)
+	tp := New(bc, &config.TxPool{})
+	assert.Equal(miner, tp.PendingBalanceOf(ta.Addrinfo["miner"].Address))
+
+	tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{NewPayee(ta.Addrinfo["bravo"].Address, 10)})
+	assert.Nil(err)
+	_, err = tp.ProcessTx(tx, false, false, 0)
+	assert.Nil(err)
+
+	// the pending tx is only counted by the pending balance
+	assert.Equal(miner, bc.BalanceOf(ta.Addrinfo["miner"].Address, 0))
+	assert.Equal(miner-10, tp.PendingBalanceOf(ta.Addrinfo["miner"].Address))
+	assert.Equal(uint64(0), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0))
+	assert.Equal(uint64(10), tp.PendingBalanceOf(ta.Addrinfo["bravo"].Address))
+
+	blk, err := bc.MintNewBlock(tp.Txs(), ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+	assert.Nil(tp.RemoveTxInBlock(blk))
+	assert.Equal(uint64(10), bc.BalanceOf(ta.Addrinfo["bravo"].Address, 0))
+	assert.Equal(uint64(10), tp.PendingBalanceOf(ta.Addrinfo["bravo"].Address))
+}
+
+func TestTxPoolDependentTxs(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	// zero-value coinbase outputs cannot be spent, so the blocks reward another address than the miner
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	// spendOutput returns a tx of the owner spending the index-th output of the parent
+	spendOutput := func(parent *Tx, index int32, owner string, to string) *Tx {
+		utxo := parent.TxOut[index]
+		in := NewTxInput(parent.Hash(), index, nil, 0)
+		tx, err := signTx(NewTx(1, []*TxInput{in}, []*TxOutput{CreateTxOutput(ta.Addrinfo[to].Address, utxo.Value)}, 0), utxo.TxOutputPb, owner)
+		assert.Nil(err)
+		return tx
+	}
+
+	// the child spending the output of a parent unknown to the pool is held as orphan
+	tp := New(bc, &config.TxPool{})
+	parent, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{NewPayee(ta.Addrinfo["alfa"].Address, 10)})
+	assert.Nil(err)
+	child := spendOutput(parent, 0, "alfa", "bravo")
+	descs, err := tp.ProcessTx(child, true, false, 0)
+	assert.Nil(err)
+	assert.Equal(0, len(descs))
+	assert.True(tp.HasOrphanTx(child.Hash()))
+
+	// it is admitted once the parent is accepted, but not mined before the parent
+	descs, err = tp.ProcessTx(parent, false, false, 0)
+	assert.Nil(err)
+	assert.Equal(2, len(descs))
+	assert.False(tp.HasOrphanTx(child.Hash()))
+	assert.Equal(2, len(tp.TxDescs()))
+	txs := tp.Txs()
+	assert.Equal(1, len(txs))
+	assert.Equal(parent.Hash(), txs[0].Hash())
+
+	// the child becomes ready when the parent is mined
+	blk, err := bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+	assert.Nil(tp.RemoveTxInBlock(blk))
+	txs = tp.Txs()
+	assert.Equal(1, len(txs))
+	assert.Equal(child.Hash(), txs[0].Hash())
+	blk, err = bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.ValidateBlock(blk))
+	bc.Reset()
+
+	// an orphan is admitted when its parent is mined without passing through the pool
+	tp = New(bc, &config.TxPool{})
+	parent, err = bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 20, []*Payee{NewPayee(ta.Addrinfo["charlie"].Address, 20)})
+	assert.Nil(err)
+	child = spendOutput(parent, 0, "charlie", "delta")
+	_, err = tp.ProcessTx(child, true, false, 0)
+	assert.Nil(err)
+	assert.True(tp.HasOrphanTx(child.Hash()))
+	blk, err = bc.MintNewBlock([]*Tx{parent}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+	assert.Nil(tp.RemoveTxInBlock(blk))
+	assert.False(tp.HasOrphanTx(child.Hash()))
+	txs = tp.Txs()
+	assert.Equal(1, len(txs))
+	assert.Equal(child.Hash(), txs[0].Hash())
+}
+
+func TestTxPoolReplaceByFee(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	// original pays 1 as fee
+	original, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{NewPayee(ta.Addrinfo["alfa"].Address, 10)})
+	assert.Nil(err)
+	original.TxOut[1].Value--
+	assert.Nil(bc.SignTransaction(original, wallet.NewKeySigner(ta.Addrinfo["miner"])))
+	change := original.TxOut[1].Value
+
+	// replacement spends the same UTXO paying bravo instead
+	replace := func(fee uint64) *Tx {
+		in := []*TxInput{}
+		for _, txIn := range original.TxIn {
+			var hash cp.Hash32B
+			copy(hash[:], txIn.TxHash)
+			in = append(in, NewTxInput(hash, txIn.OutIndex, nil, txIn.Sequence))
+		}
+		out := []*TxOutput{CreateTxOutput(ta.Addrinfo["bravo"].Address, 10), CreateTxOutput(ta.Addrinfo["miner"].Address, change+1-fee)}
+		tx := NewTx(1, in, out, 0)
+		assert.Nil(bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo["miner"])))
+		return tx
+	}
+
+	// a tx not opting in cannot be replaced
+	tp := New(bc, &config.TxPool{MinReplacementFeePerByte: 1})
+	_, err = tp.ProcessTx(original, false, false, 0)
+	assert.Nil(err)
+	_, err = tp.ProcessTx(replace(1000), false, false, 0)
+	assert.NotNil(err)
+	tp.RemoveTx(original, true)
+
+	original.TxIn[0].Sequence |= SequenceReplaceable
+	assert.Nil(bc.SignTransaction(original, wallet.NewKeySigner(ta.Addrinfo["miner"])))
+	assert.True(IsReplaceable(original))
+	assert.Nil(bc.ValidateLockTime(original))
+	_, err = tp.ProcessTx(original, false, false, 0)
+	assert.Nil(err)
+	// the child spending the output paying alfa is evicted with the original
+	child, err := signTx(NewTx(1, []*TxInput{NewTxInput(original.Hash(), 0, nil, 0)}, []*TxOutput{CreateTxOutput(ta.Addrinfo["bravo"].Address, 10)}, 0), original.TxOut[0].TxOutputPb, "alfa")
+	assert.Nil(err)
+	_, err = tp.ProcessTx(child, false, false, 0)
+	assert.Nil(err)
+	assert.Equal(2, len(tp.TxDescs()))
+
+	// the replacement has to pay the fees of the evicted txs plus the min replacement fee for its size
+	_, err = tp.ProcessTx(replace(2), false, false, 0)
+	assert.NotNil(err)
+	assert.Equal(2, len(tp.TxDescs()))
+
+	replacement := replace(1000)
+	descs, err := tp.ProcessTx(replacement, false, false, 0)
+	assert.Nil(err)
+	assert.Equal(int64(1000), descs[0].Fee)
+	txs := tp.Txs()
+	assert.Equal(1, len(txs))
+	assert.Equal(replacement.Hash(), txs[0].Hash())
+	assert.False(tp.HasTxOrOrphanTx(original.Hash()))
+	assert.False(tp.HasTxOrOrphanTx(child.Hash()))
+}
+
+func TestTxPoolPackages(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	// parent pays 1 as fee to alfa, whose output the child spends paying 2 as fee to bravo
+	tp := New(bc, &config.TxPool{})
+	parent, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{NewPayee(ta.Addrinfo["alfa"].Address, 10)})
+	assert.Nil(err)
+	parent.TxOut[1].Value--
+	assert.Nil(bc.SignTransaction(parent, wallet.NewKeySigner(ta.Addrinfo["miner"])))
+	child, err := signTx(NewTx(1, []*TxInput{NewTxInput(parent.Hash(), 0, nil, 0)}, []*TxOutput{CreateTxOutput(ta.Addrinfo["bravo"].Address, 8)}, 0), parent.TxOut[0].TxOutputPb, "alfa")
+	assert.Nil(err)
+	_, err = tp.ProcessTx(parent, false, false, 0)
+	assert.Nil(err)
+	_, err = tp.ProcessTx(child, false, false, 0)
+	assert.Nil(err)
+
+	// alfa is paid by the parent and spends in the child
+	descs := tp.PendingByAddress(ta.Addrinfo["alfa"].Address)
+	assert.Equal(2, len(descs))
+	assert.Equal(parent.Hash(), descs[0].Tx.Hash())
+	assert.Equal(child.Hash(), descs[1].Tx.Hash())
+	descs = tp.PendingByAddress(ta.Addrinfo["bravo"].Address)
+	assert.Equal(1, len(descs))
+	assert.Equal(child.Hash(), descs[0].Tx.Hash())
+	assert.Equal(0, len(tp.PendingByAddress(ta.Addrinfo["charlie"].Address)))
+
+	// the child is mined along with its parent, paying the fees of both for their sizes
+	parentSize, childSize := 0, 0
+	if serialize, err := parent.Serialize(); assert.Nil(err) {
+		parentSize = len(serialize)
+	}
+	if serialize, err := child.Serialize(); assert.Nil(err) {
+		childSize = len(serialize)
+	}
+	pkg, err := tp.AncestorPackage(child.Hash())
+	assert.Nil(err)
+	assert.Equal(2, len(pkg.Txs))
+	assert.Equal(parent.Hash(), pkg.Txs[0].Tx.Hash())
+	assert.Equal(int64(3), pkg.Fee)
+	assert.Equal(uint32(parentSize+childSize), pkg.Size)
+	assert.Equal(float64(3)/float64(parentSize+childSize), pkg.FeeRate())
+	pkg, err = tp.AncestorPackage(parent.Hash())
+	assert.Nil(err)
+	assert.Equal(1, len(pkg.Txs))
+	assert.Equal(int64(1), pkg.Fee)
+
+	// the child is evicted along with its parent
+	pkg, err = tp.DescendantPackage(parent.Hash())
+	assert.Nil(err)
+	assert.Equal(2, len(pkg.Txs))
+	assert.Equal(child.Hash(), pkg.Txs[1].Tx.Hash())
+	pkg, err = tp.DescendantPackage(child.Hash())
+	assert.Nil(err)
+	assert.Equal(1, len(pkg.Txs))
+	_, err = tp.AncestorPackage(cp.ZeroHash32B)
+	assert.Equal(ErrTxNotInPool, errors.Cause(err))
+	_, err = tp.DescendantPackage(cp.ZeroHash32B)
+	assert.Equal(ErrTxNotInPool, errors.Cause(err))
+
+	// a tx spending the UTXO of the parent conflicts with both, but a tx does not conflict with itself
+	in := []*TxInput{}
+	for _, txIn := range parent.TxIn {
+		var hash cp.Hash32B
+		copy(hash[:], txIn.TxHash)
+		in = append(in, NewTxInput(hash, txIn.OutIndex, nil, 0))
+	}
+	conflicting := NewTx(1, in, []*TxOutput{CreateTxOutput(ta.Addrinfo["charlie"].Address, 10)}, 0)
+	pkg = tp.Conflicts(conflicting)
+	assert.Equal(2, len(pkg.Txs))
+	assert.Equal(int64(3), pkg.Fee)
+	assert.Equal(0, len(tp.Conflicts(parent).Txs))
+	assert.Equal(float64(0), tp.Conflicts(parent).FeeRate())
+}
+
+func TestTxPoolPersistence(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	poolCfg := &config.TxPool{PersistPath: "pool.test"}
+	defer os.Remove(poolCfg.PersistPath)
+	tp := New(bc, poolCfg)
+	assert.Nil(tp.Start())
+	parent, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{NewPayee(ta.Addrinfo["alfa"].Address, 10)})
+	assert.Nil(err)
+	child, err := signTx(NewTx(1, []*TxInput{NewTxInput(parent.Hash(), 0, nil, 0)}, []*TxOutput{CreateTxOutput(ta.Addrinfo["bravo"].Address, 10)}, 0), parent.TxOut[0].TxOutputPb, "alfa")
+	assert.Nil(err)
+	_, err = tp.ProcessTx(parent, false, false, 0)
+	assert.Nil(err)
+	_, err = tp.ProcessTx(child, false, false, 0)
+	assert.Nil(err)
+	assert.Nil(tp.Stop())
+	// no tx is accepted once the pool is stopped
+	_, err = tp.ProcessTx(child, false, false, 0)
+	assert.Equal(ErrPoolStopped, errors.Cause(err))
+
+	// the txs are reloaded after a restart
+	tp = New(bc, poolCfg)
+	assert.Nil(tp.Start())
+	assert.Equal(2, len(tp.TxDescs()))
+	assert.True(tp.HasTxOrOrphanTx(parent.Hash()))
+	assert.True(tp.HasTxOrOrphanTx(child.Hash()))
+
+	// the parent mined while the node is down is dropped on reload
+	assert.Nil(tp.Stop())
+	blk, err := bc.MintNewBlock([]*Tx{parent}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	bc.Reset()
+	assert.Nil(bc.AddBlockCommit(blk))
+	tp = New(bc, poolCfg)
+	assert.Nil(tp.Start())
+	assert.Equal(1, len(tp.TxDescs()))
+	assert.False(tp.HasTxOrOrphanTx(parent.Hash()))
+	assert.True(tp.HasTxOrOrphanTx(child.Hash()))
+
+	// a corrupt file is rejected
+	assert.Nil(ioutil.WriteFile(poolCfg.PersistPath, []byte("IOTP garbage"), 0600))
+	assert.NotNil(New(bc, poolCfg).(*txPool).load())
+}
+
+func TestTxPoolEviction(t *testing.T) {
+	assert := assert.New(t)
+
+	bc, err := testutil.NewBlockchain(config.Chain{}, 10)
+	assert.Nil(err)
+	defer bc.Close()
+	commit := func(txs []*Tx) *Block {
+		blk, err := bc.MintNewBlock(txs, ta.Addrinfo["echo"].Address, "")
+		assert.Nil(err)
+		bc.Reset()
+		assert.Nil(bc.AddBlockCommit(blk))
+		return blk
+	}
+
+	// the txs of alfa, bravo and charlie to delta pay a fee of 1, 2 and 3
+	txs := []*Tx{}
+	for i, name := range []string{"alfa", "bravo", "charlie"} {
+		tx, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo[name]), 5, []*Payee{NewPayee(ta.Addrinfo["delta"].Address, 5)})
+		assert.Nil(err)
+		tx.TxOut[1].Value -= uint64(i + 1)
+		assert.Nil(bc.SignTransaction(tx, wallet.NewKeySigner(ta.Addrinfo[name])))
+		txs = append(txs, tx)
+	}
+
+	// the pool only holds two txs, so the tx paying more evicts the one paying the least
+	tp := New(bc, &config.TxPool{MaxPoolSize: uint64(txs[0].TotalSize() + txs[1].TotalSize())})
+	_, err = tp.ProcessTx(txs[0], false, false, 0)
+	assert.Nil(err)
+	_, err = tp.ProcessTx(txs[1], false, false, 0)
+	assert.Nil(err)
+	_, err = tp.ProcessTx(txs[2], false, false, 0)
+	assert.Nil(err)
+	assert.Equal(2, len(tp.TxDescs()))
+	assert.False(tp.HasTxOrOrphanTx(txs[0].Hash()))
+	_, err = tp.ProcessTx(txs[0], false, false, 0)
+	assert.NotNil(err)
+	assert.False(tp.HasTxOrOrphanTx(txs[0].Hash()))
+
+	// txs are evicted once they outlive the TTL
+	tp = New(bc, &config.TxPool{TxTTL: time.Millisecond})
+	_, err = tp.ProcessTx(txs[0], false, false, 0)
+	assert.Nil(err)
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(tp.RemoveTxInBlock(commit([]*Tx{})))
+	assert.Equal(0, len(tp.TxDescs()))
+
+	// or they cannot be included at the next height
+	tp = New(bc, &config.TxPool{})
+	txs[0].ExpiryHeight = bc.TipHeight() + 1
+	assert.Nil(bc.SignTransaction(txs[0], wallet.NewKeySigner(ta.Addrinfo["alfa"])))
+	_, err = tp.ProcessTx(txs[0], false, false, 0)
+	assert.Nil(err)
+	assert.Nil(tp.RemoveTxInBlock(commit([]*Tx{})))
+	assert.Equal(0, len(tp.TxDescs()))
+	_, err = tp.ProcessTx(txs[0], false, false, 0)
+	assert.Equal(ErrTxExpired, errors.Cause(err))
+}
+
+func TestTxPoolAcceptTransaction(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	parent, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{NewPayee(ta.Addrinfo["alfa"].Address, 10)})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{parent}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	// spend returns a tx spending the output of alfa, signed by the signer, paying 'value' to 'to'
+	spend := func(hash cp.Hash32B, signer string, to string, value uint64) *Tx {
+		tx, err := signTx(NewTx(1, []*TxInput{NewTxInput(hash, 0, nil, 0)}, []*TxOutput{CreateTxOutput(ta.Addrinfo[to].Address, value)}, 0), parent.TxOut[0].TxOutputPb, signer)
+		assert.Nil(err)
+		return tx
+	}
+
+	tp := New(bc, &config.TxPool{})
+	// the tx failing the stateless checks or the script verification is invalid
+	_, err = tp.AcceptTransaction(spend(parent.Hash(), "bravo", "bravo", 9))
+	assert.Equal(ErrInvalidTx, errors.Cause(err))
+	twice := spend(parent.Hash(), "alfa", "bravo", 9)
+	twice.TxIn = append(twice.TxIn, twice.TxIn[0])
+	twice.NumTxIn = 2
+	_, err = tp.AcceptTransaction(twice)
+	assert.Equal(ErrInvalidTx, errors.Cause(err))
+	// the tx spending unknown outputs is not held as orphan
+	missing := spend(blk.HashBlock(), "alfa", "bravo", 9)
+	_, err = tp.AcceptTransaction(missing)
+	assert.Equal(ErrMissingInputs, errors.Cause(err))
+	assert.False(tp.HasOrphanTx(missing.Hash()))
+	// the fee has to meet the pool policy
+	tx := spend(parent.Hash(), "alfa", "bravo", 9)
+	_, err = New(bc, &config.TxPool{MinTxFeePerByte: 1}).AcceptTransaction(tx)
+	assert.Equal(ErrInsufficientFee, errors.Cause(err))
+
+	// the accepted tx is delivered to the subscribers
+	ch := tp.Subscribe()
+	desc, err := tp.AcceptTransaction(tx)
+	assert.Nil(err)
+	assert.Equal(int64(1), desc.Fee)
+	assert.Equal(desc, <-ch)
+	tp.Unsubscribe(ch)
+	_, err = tp.AcceptTransaction(tx)
+	assert.Equal(ErrDuplicateTx, errors.Cause(err))
+	_, err = tp.AcceptTransaction(spend(parent.Hash(), "alfa", "charlie", 9))
+	assert.Equal(ErrDoubleSpend, errors.Cause(err))
+}
+
+func TestTxPoolValidateTransaction(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.Chain.BlockReward = 0
+
+	bc, err := CreateBlockchain(context.Background(), ta.Addrinfo["miner"].Address, cfg)
+	assert.Nil(err)
+	defer bc.Close()
+
+	parent, err := bc.CreateTransaction(wallet.NewKeySigner(ta.Addrinfo["miner"]), 10, []*Payee{NewPayee(ta.Addrinfo["alfa"].Address, 10)})
+	assert.Nil(err)
+	blk, err := bc.MintNewBlock([]*Tx{parent}, ta.Addrinfo["echo"].Address, "")
+	assert.Nil(err)
+	assert.Nil(bc.AddBlockCommit(blk))
+	bc.Reset()
+
+	tp := New(bc, &config.TxPool{MinTxFeePerByte: 1})
+	// the unsigned tx tells its fee and the inputs left to sign
+	tx := NewTx(1, []*TxInput{NewTxInput(parent.Hash(), 0, nil, 0)}, []*TxOutput{CreateTxOutput(ta.Addrinfo["bravo"].Address, 9)}, 0)
+	v := tp.ValidateTransaction(tx)
+	assert.Equal(ErrInvalidTx, errors.Cause(v.Err))
+	assert.Equal(int64(1), v.Fee)
+	assert.Equal([]int{0}, v.UnsignedInputs)
+	assert.Equal(int64(tx.TotalSize()), v.MinFee)
+
+	// once signed, only the fee falls short of the pool policy
+	tx, err = signTx(tx, parent.TxOut[0].TxOutputPb, "alfa")
+	assert.Nil(err)
+	v = tp.ValidateTransaction(tx)
+	assert.Equal(ErrInsufficientFee, errors.Cause(v.Err))
+	assert.Equal([]int{}, v.UnsignedInputs)
+	assert.Equal(tx.TotalSize(), v.Size)
+
+	// the fee is enough once the limits are lowered at runtime
+	tp.SetLimits(config.TxPool{})
+	v = tp.ValidateTransaction(tx)
+	assert.Nil(v.Err)
+	assert.Equal(int64(0), v.MinFee)
+
+	// the tx spending unknown outputs tells the missing ones
+	missing := NewTx(1, []*TxInput{NewTxInput(blk.HashBlock(), 0, nil, 0)}, []*TxOutput{CreateTxOutput(ta.Addrinfo["bravo"].Address, 9)}, 0)
+	v = tp.ValidateTransaction(missing)
+	assert.Equal(ErrMissingInputs, errors.Cause(v.Err))
+	assert.Equal([]cp.Hash32B{blk.HashBlock()}, v.MissingInputs)
+
+	// the valid tx is not added to the pool
+	tp = New(bc, &config.TxPool{})
+	v = tp.ValidateTransaction(tx)
+	assert.Nil(v.Err)
+	assert.False(tp.HasTxOrOrphanTx(tx.Hash()))
+	_, err = tp.AcceptTransaction(tx)
+	assert.Nil(err)
+	assert.Equal(ErrDuplicateTx, errors.Cause(tp.ValidateTransaction(tx).Err))
+}
+
+func TestTxPoolActions(t *testing.T) {
+	defer os.Remove(testDBPath)
+	assert := assert.New(t)
+
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	assert.Nil(err)
+	cfg.Chain.ChainDBPath = testDBPath
+	cfg.TxPool.MaxQueuedActions = 5
+	alfa, bravo := ta.Addrinfo["alfa"].Address, ta.Addrinfo["bravo"].Address
+	genesis := &config.Genesis{
+		Allocations: []config.Allocation{{Address: ta.Addrinfo["miner"].Address, Amount: 100}},
+		Accounts:    []config.Allocation{{Address: alfa, Amount: 50}},
+	}
+	bc, err := CreateBlockchainWithGenesis(context.Background(), cfg, genesis)
+	assert.Nil(err)
+	defer bc.Close()
+	tp := New(bc, &cfg.TxPool)
+
+	signer := wallet.NewKeySigner(ta.Addrinfo["alfa"])
+	transfer := func(nonce uint64, amount uint64) *Transfer {
+		tsf := NewTransfer(nonce, amount, alfa, bravo)
+		assert.Nil(tsf.Sign(signer))
+		return tsf
+	}
+	tsf1, tsf2, tsf3 := transfer(1, 10), transfer(2, 10), transfer(3, 10)
+	assert.Nil(tp.AcceptAction(tsf1))
+	assert.Equal(ErrDuplicateTx, errors.Cause(tp.AcceptAction(tsf1)))
+	assert.Equal(ErrDoubleSpend, errors.Cause(tp.AcceptAction(transfer(1, 20))))
+	assert.Equal(ErrInvalidTx, errors.Cause(tp.AcceptAction(NewTransfer(2, 10, alfa, bravo))))
+	assert.Equal(ErrNonceTooHigh, errors.Cause(tp.AcceptAction(transfer(6, 10))))
+
+	// the action after a gap is queued until the missing nonce is filled
+	assert.Nil(tp.AcceptAction(tsf3))
+	assert.Equal(uint64(1), tp.PendingNonce(alfa))
+	assert.Equal([]Action{tsf1}, tp.Actions())
+	assert.Nil(tp.AcceptAction(tsf2))
+	assert.Equal(uint64(3), tp.PendingNonce(alfa))
+	assert.Equal([]Action{tsf1, tsf2, tsf3}, tp.Actions())
+
+	// the block executes the transfers before the votes, so the transfer following the vote is left out
+	vote := NewVote(4, alfa, alfa)
+	assert.Nil(vote.Sign(signer))
+	assert.Nil(tp.AcceptAction(vote))
+	assert.Nil(tp.AcceptAction(transfer(5, 10)))
+	assert.Equal(uint64(5), tp.PendingNonce(alfa))
+	blk, err := bc.MintNewBlockWithActions(nil, tp.Actions(), ta.Addrinfo["miner"].Address, "")
+	assert.Nil(err)
+	assert.Equal([]Action{tsf1, tsf2, tsf3, vote}, blk.Actions())
+	assert.Nil(bc.AddBlockCommit(blk))
+	assert.Nil(tp.RemoveTxInBlock(blk))
+	assert.Equal(uint64(4), bc.AccountState(alfa).Nonce)
+	assert.Equal(uint64(5), tp.PendingNonce(alfa))
+	assert.Equal(1, len(tp.Actions()))
+	assert.Equal(ErrNonceTooLow, errors.Cause(tp.AcceptAction(tsf2)))
+	assert.Equal(ErrNonceTooHigh, errors.Cause(tp.AcceptAction(transfer(10, 10))))
+}
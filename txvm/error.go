@@ -27,6 +27,10 @@ const (
 	ErrEqualVerify
 	// ErrInvalidStackOperation ...
 	ErrInvalidStackOperation
+	// ErrEvalFalse ...
+	ErrEvalFalse
+	// ErrUnsatisfiedLockTime ...
+	ErrUnsatisfiedLockTime
 )
 
 // ScriptError defines the struct of script error
@@ -0,0 +1,179 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package txvm
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"golang.org/x/crypto/blake2b"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+)
+
+// HTLC is a hash time locked contract, the recipient can redeem the output by revealing the preimage of the hash
+// lock, or the sender can refund the output once it is confirmed for the lock time
+// The lock script is "OpIf OpSha256 <hash lock> OpEqualVerify OpDup OpHash160 <recipient> OpElse <lock time>
+// OpCheckSequenceVerify OpDup OpHash160 <sender> OpEndIf OpEqualVerify OpCheckSig".
+type HTLC struct {
+	HashLock  []byte // sha256 hash of the preimage
+	Recipient []byte // public key hash of the recipient
+	Sender    []byte // public key hash of the sender
+	LockTime  uint32 // number of blocks before the sender can refund
+}
+
+// HTLCScript creates the lock script of a hash time locked contract
+func HTLCScript(recipient string, sender string, hashLock []byte, lockTime uint32) ([]byte, error) {
+	if len(hashLock) != sha256.Size {
+		return nil, fmt.Errorf("hash lock is %d bytes, expecting %d", len(hashLock), sha256.Size)
+	}
+	recipientHash := iotxaddress.GetPubkeyHash(recipient)
+	senderHash := iotxaddress.GetPubkeyHash(sender)
+	if recipientHash == nil || senderHash == nil {
+		return nil, fmt.Errorf("invalid recipient %s or sender %s", recipient, sender)
+	}
+	locktime := make([]byte, 4)
+	cm.MachineEndian.PutUint32(locktime, lockTime)
+
+	script := []byte{OpIf, OpSha256, OpData32}
+	script = append(script, hashLock...)
+	script = append(script, OpEqualVerify, OpDup, OpHash160, OpData20)
+	script = append(script, recipientHash...)
+	script = append(script, OpElse, OpData4)
+	script = append(script, locktime...)
+	script = append(script, OpCheckSequenceVerify, OpDup, OpHash160, OpData20)
+	script = append(script, senderHash...)
+	script = append(script, OpEndIf, OpEqualVerify, OpCheckSig)
+
+	b := NewScriptBuilder()
+	if err := b.AddOps(script); err != nil {
+		return nil, err
+	}
+	return b.Bytecodes(), nil
+}
+
+// ParseHTLCScript returns the contract of a lock script created by HTLCScript
+func ParseHTLCScript(lockScript []byte) (*HTLC, error) {
+	ast, err := ParseRaw(lockScript)
+	if err != nil {
+		return nil, err
+	}
+	nodes := ast.nodes
+	if len(nodes) != 3 || nodes[0].opcode != OpIf || len(nodes[0].asts) != 2 ||
+		nodes[1].opcode != OpEqualVerify || nodes[2].opcode != OpCheckSig {
+		return nil, fmt.Errorf("not a HTLC script")
+	}
+	redeem, refund := nodes[0].asts[0].nodes, nodes[0].asts[1].nodes
+	if !matchOpcodes(redeem, []byte{OpSha256, OpData32, OpEqualVerify, OpDup, OpHash160, OpData20}) ||
+		!matchOpcodes(refund, []byte{OpData4, OpCheckSequenceVerify, OpDup, OpHash160, OpData20}) {
+		return nil, fmt.Errorf("not a HTLC script")
+	}
+	return &HTLC{
+		HashLock:  redeem[1].data,
+		Recipient: redeem[5].data,
+		Sender:    refund[4].data,
+		LockTime:  cm.MachineEndian.Uint32(refund[0].data),
+	}, nil
+}
+
+// HTLCRedeemScript creates the input signature script for the recipient to redeem a HTLC output with the preimage
+func HTLCRedeemScript(txin []byte, pubkey []byte, privkey []byte, preimage []byte) ([]byte, error) {
+	hash := blake2b.Sum256(txin)
+	return HTLCRedeemScriptWithSig(cp.Sign(privkey, hash[:]), pubkey, preimage)
+}
+
+// HTLCRedeemScriptWithSig creates the redeem script from the signature of the blake2b hash of the txin
+func HTLCRedeemScriptWithSig(sig []byte, pubkey []byte, preimage []byte) ([]byte, error) {
+	if len(preimage) == 0 {
+		return nil, fmt.Errorf("empty preimage")
+	}
+	b := NewScriptBuilder()
+	if err := addSignature(b, sig, pubkey); err != nil {
+		return nil, err
+	}
+	if err := b.AddOp(OpPushData); err != nil {
+		return nil, fmt.Errorf("cannot add data: %v", err)
+	}
+	datalen := make([]byte, 2)
+	cm.MachineEndian.PutUint16(datalen, uint16(len(preimage)))
+	if err := b.AddData(append(datalen, preimage...)); err != nil {
+		return nil, fmt.Errorf("cannot add data: %v", err)
+	}
+	// take the IF branch
+	if err := b.AddOp(OpData1); err != nil {
+		return nil, fmt.Errorf("cannot add data: %v", err)
+	}
+	if err := b.AddData([]byte{0x01}); err != nil {
+		return nil, fmt.Errorf("cannot add data: %v", err)
+	}
+	return b.Bytecodes(), nil
+}
+
+// HTLCRefundScript creates the input signature script for the sender to refund a HTLC output after the lock time,
+// the sequence of the input has to be set to the lock time
+func HTLCRefundScript(txin []byte, pubkey []byte, privkey []byte) ([]byte, error) {
+	hash := blake2b.Sum256(txin)
+	return HTLCRefundScriptWithSig(cp.Sign(privkey, hash[:]), pubkey)
+}
+
+// HTLCRefundScriptWithSig creates the refund script from the signature of the blake2b hash of the txin
+func HTLCRefundScriptWithSig(sig []byte, pubkey []byte) ([]byte, error) {
+	b := NewScriptBuilder()
+	if err := addSignature(b, sig, pubkey); err != nil {
+		return nil, err
+	}
+	// take the ELSE branch
+	if err := b.AddOp(Op0); err != nil {
+		return nil, fmt.Errorf("cannot add data: %v", err)
+	}
+	return b.Bytecodes(), nil
+}
+
+// HTLCPreimage returns the preimage revealed by a signature script created by HTLCRedeemScript, which lets the
+// sender of an atomic swap redeem the counterpart HTLC on the other chain, nil if it is not a redeem script
+func HTLCPreimage(unlockScript []byte) []byte {
+	ast, err := ParseRaw(unlockScript)
+	if err != nil {
+		return nil
+	}
+	if !matchOpcodes(ast.nodes, []byte{OpData64, OpData32, OpPushData, OpData1}) {
+		return nil
+	}
+	return ast.nodes[2].data
+}
+
+// addSignature adds the signature and the public key
+func addSignature(b ScriptBuilder, sig []byte, pubkey []byte) error {
+	if err := b.AddOp(OpData64); err != nil {
+		return fmt.Errorf("cannot add data: %v", err)
+	}
+	if err := b.AddData(sig); err != nil {
+		return fmt.Errorf("cannot add data: %v", err)
+	}
+	if err := b.AddOp(OpData32); err != nil {
+		return fmt.Errorf("cannot add data: %v", err)
+	}
+	if err := b.AddData(pubkey); err != nil {
+		return fmt.Errorf("cannot add data: %v", err)
+	}
+	return nil
+}
+
+// matchOpcodes checks the opcodes of the nodes are exactly the given ones
+func matchOpcodes(nodes []OpNode, opcodes []byte) bool {
+	if len(nodes) != len(opcodes) {
+		return false
+	}
+	for i, node := range nodes {
+		if node.opcode != opcodes[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package txvm
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/iotxaddress"
+)
+
+func TestHTLCScript(t *testing.T) {
+	recipient, err := iotxaddress.NewAddress(true, 0x01, []byte{0xa4, 0x00, 0x00, 0x00})
+	assert.Nil(t, err)
+	sender, err := iotxaddress.NewAddress(true, 0x01, []byte{0xa4, 0x00, 0x00, 0x00})
+	assert.Nil(t, err)
+	preimage := []byte("atomic swap secret")
+	hashLock := sha256.Sum256(preimage)
+
+	locks, err := HTLCScript(recipient.Address, sender.Address, hashLock[:], 10)
+	assert.Nil(t, err)
+	htlc, err := ParseHTLCScript(locks)
+	assert.Nil(t, err)
+	assert.Equal(t, hashLock[:], htlc.HashLock)
+	assert.Equal(t, iotxaddress.GetPubkeyHash(recipient.Address), htlc.Recipient)
+	assert.Equal(t, iotxaddress.GetPubkeyHash(sender.Address), htlc.Sender)
+	assert.Equal(t, uint32(10), htlc.LockTime)
+	plain, err := PayToAddrScript(recipient.Address)
+	assert.Nil(t, err)
+	_, err = ParseHTLCScript(plain)
+	assert.NotNil(t, err)
+
+	txin := []byte{0x11, 0x22, 0x33, 0x44}
+	run := func(unlocks []byte, sequence uint32) error {
+		v, err := NewIVM(txin, append(append([]byte{}, unlocks...), locks...))
+		assert.Nil(t, err)
+		v.SetSequence(sequence)
+		return v.Execute()
+	}
+
+	// the recipient redeems with the preimage, which is then revealed to the sender
+	unlocks, err := HTLCRedeemScript(txin, recipient.PublicKey, recipient.PrivateKey, preimage)
+	assert.Nil(t, err)
+	assert.Nil(t, run(unlocks, 0))
+	assert.Equal(t, preimage, HTLCPreimage(unlocks))
+	wrong, err := HTLCRedeemScript(txin, recipient.PublicKey, recipient.PrivateKey, []byte("wrong secret"))
+	assert.Nil(t, err)
+	assert.NotNil(t, run(wrong, 0))
+	stolen, err := HTLCRedeemScript(txin, sender.PublicKey, sender.PrivateKey, preimage)
+	assert.Nil(t, err)
+	assert.NotNil(t, run(stolen, 0))
+
+	// the sender refunds after the lock time
+	unlocks, err = HTLCRefundScript(txin, sender.PublicKey, sender.PrivateKey)
+	assert.Nil(t, err)
+	assert.Nil(t, HTLCPreimage(unlocks))
+	assert.Equal(t, ErrUnsatisfiedLockTime, run(unlocks, 9).(ScriptError).ErrorCode)
+	assert.Nil(t, run(unlocks, 10))
+	stolen, err = HTLCRefundScript(txin, recipient.PublicKey, recipient.PrivateKey)
+	assert.Nil(t, err)
+	assert.NotNil(t, run(stolen, 10))
+}
@@ -8,11 +8,14 @@ package txvm
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 
 	"golang.org/x/crypto/blake2b"
 
+	cm "github.com/iotexproject/iotex-core/common"
 	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
 )
 
 // ConstructFunc takes an array of bytecodes to build an opnode. Note that:
@@ -56,9 +59,14 @@ const (
 	OpData1
 	OpData2
 	OpData3
+	OpData4
 	OpData20 = 0x14
 	OpData32 = 0x20
+	OpData33 = 0x21
 	OpData64 = 0x40
+	OpData65 = 0x41
+	// OpPushData is followed by 2 bytes of data length and the data
+	OpPushData = 0x4c
 )
 
 // Enumerate of opcodes
@@ -81,12 +89,16 @@ const (
 const (
 	OpHash160 = iota + 0xb0
 	OpCheckSig
+	OpCheckMultiSig
+	OpSha256
+	OpCheckSchnorrSig
 )
 
 // External Call
 const (
 	OpCheckLockTime = iota + 0xc0
 	OpOtherSPVVerify
+	OpCheckSequenceVerify
 )
 
 // Enumerate unused opcodes
@@ -126,6 +138,22 @@ func opConstructData(bytecodes []byte) (*OpNode, int, error) {
 	return &node, datalen + 1, nil
 }
 
+func opConstructPushData(bytecodes []byte) (*OpNode, int, error) {
+	if len(bytecodes) < 3 {
+		return nil, 0, scriptError(ErrInvalidOpcode, "bytecodes not long enough for data length")
+	}
+	datalen := int(cm.MachineEndian.Uint16(bytecodes[1:3]))
+	if len(bytecodes) < datalen+3 {
+		return nil, 0, scriptError(ErrInvalidOpcode,
+			fmt.Sprintf("bytecodes not long enough."+
+				"Expect >= %d, get %d", datalen+3, len(bytecodes)))
+	}
+	node := OpNode{}
+	node.opcode = bytecodes[0]
+	node.data = bytecodes[3 : datalen+3]
+	return &node, datalen + 3, nil
+}
+
 func opConstructBranch(bytecodes []byte) (*OpNode, int, error) {
 	node := OpNode{opcode: bytecodes[0]}
 	offset := 1
@@ -245,8 +273,109 @@ func opcodeCheckSig(node *OpNode, vm *IVM) error {
 	return opcodePushFalse(node, vm)
 }
 
+// opcodeCheckSchnorrSig pops the Schnorr public key and signature, and pushes whether the signature is valid
+// When the VM verifies in batch, the signature is added to the batch and assumed valid, the batch has to be verified
+// for the result of the script to hold.
+func opcodeCheckSchnorrSig(node *OpNode, vm *IVM) error {
+	if len(vm.dstack) < 2 {
+		return scriptError(ErrInvalidStackOperation, "stack has too few entries, cannot CheckSchnorrSig")
+	}
+
+	pubkey := vm.dstack[len(vm.dstack)-1]
+	sig := vm.dstack[len(vm.dstack)-2]
+	vm.dstack = vm.dstack[:len(vm.dstack)-2] // pop
+
+	hash := blake2b.Sum256(vm.txin)
+	if vm.batch != nil {
+		vm.batch.Add(pubkey, hash[:], sig)
+		return opcodePushTrue(node, vm)
+	}
+	if cp.SchnorrVerify(pubkey, hash[:], sig) {
+		return opcodePushTrue(node, vm)
+	}
+	return opcodePushFalse(node, vm)
+}
+
+// opcodeCheckMultiSig pops the M-of-N multisig keys and M signatures, the signatures must be ordered the same as
+// the public keys they are signed with
+func opcodeCheckMultiSig(node *OpNode, vm *IVM) error {
+	if len(vm.dstack) == 0 {
+		return scriptError(ErrInvalidStackOperation, "empty stack, cannot CheckMultiSig")
+	}
+
+	m, pubkeys, err := iotxaddress.ParseMultisigKeys(vm.dstack[len(vm.dstack)-1])
+	if err != nil {
+		return scriptError(ErrInvalidOpdata, err.Error())
+	}
+	vm.dstack = vm.dstack[:len(vm.dstack)-1] // pop
+	if len(vm.dstack) < m {
+		return scriptError(ErrInvalidStackOperation, "stack has too few signatures, cannot CheckMultiSig")
+	}
+	sigs := vm.dstack[len(vm.dstack)-m:]
+	vm.dstack = vm.dstack[:len(vm.dstack)-m] // pop
+
+	hash := blake2b.Sum256(vm.txin)
+	k := 0
+	for _, sig := range sigs {
+		for k < len(pubkeys) && !cp.Verify(pubkeys[k], hash[:], sig) {
+			k++
+		}
+		if k == len(pubkeys) {
+			return opcodePushFalse(node, vm)
+		}
+		k++
+	}
+	return opcodePushTrue(node, vm)
+}
+
+// opcodeCheckSequenceVerify pops the relative lock time, and fails unless the sequence of the input is at least the
+// relative lock time. The chain makes sure the sequence is no more than the number of blocks the UTXO has been
+// confirmed for.
+func opcodeCheckSequenceVerify(node *OpNode, vm *IVM) error {
+	if len(vm.dstack) == 0 {
+		return scriptError(ErrInvalidStackOperation, "empty stack, cannot CheckSequenceVerify")
+	}
+
+	locktime := vm.dstack[len(vm.dstack)-1]
+	vm.dstack = vm.dstack[:len(vm.dstack)-1] // pop
+	if len(locktime) > 4 {
+		return scriptError(ErrInvalidOpdata, "relative lock time is longer than 4 bytes")
+	}
+	padded := make([]byte, 4)
+	copy(padded, locktime)
+	if blocks := cm.MachineEndian.Uint32(padded); vm.sequence < blocks {
+		return scriptError(ErrUnsatisfiedLockTime,
+			fmt.Sprintf("relative lock time %d is not satisfied by sequence %d", blocks, vm.sequence))
+	}
+	return nil
+}
+
+func opcodeSha256(node *OpNode, vm *IVM) error {
+	if len(vm.dstack) == 0 {
+		return scriptError(ErrInvalidStackOperation, "empty stack, cannot sha256")
+	}
+
+	hash := sha256.Sum256(vm.dstack[len(vm.dstack)-1])
+	vm.dstack[len(vm.dstack)-1] = hash[:]
+	return nil
+}
+
+// opcodeRunBranch pops the condition, and runs the IF part if it is true, otherwise the ELSE part if any
 func opcodeRunBranch(node *OpNode, vm *IVM) error {
-	return scriptError(ErrUnsupportedOpcode, "Unimplemented")
+	if len(vm.dstack) == 0 {
+		return scriptError(ErrInvalidStackOperation, "empty stack, cannot If")
+	}
+
+	cond := vm.dstack[len(vm.dstack)-1]
+	vm.dstack = vm.dstack[:len(vm.dstack)-1] // pop
+	branchIdx := 0
+	if !castToBool(cond) {
+		branchIdx = 1
+	}
+	if branchIdx >= len(node.asts) {
+		return nil
+	}
+	return vm.runNodes(node.asts[branchIdx].nodes)
 }
 
 func opcodeRunNothing(node *OpNode, vm *IVM) error {
@@ -263,9 +392,13 @@ func init() {
 	opinfoArray[OpData1] = opinfo{"OpData1", opConstructData, opcodePushData}
 	opinfoArray[OpData2] = opinfo{"OpData2", opConstructData, opcodePushData}
 	opinfoArray[OpData3] = opinfo{"OpData2", opConstructData, opcodePushData}
+	opinfoArray[OpData4] = opinfo{"OpData4", opConstructData, opcodePushData}
 	opinfoArray[OpData20] = opinfo{"OpData20", opConstructData, opcodePushData}
 	opinfoArray[OpData32] = opinfo{"OpData32", opConstructData, opcodePushData}
+	opinfoArray[OpData33] = opinfo{"OpData33", opConstructData, opcodePushData}
 	opinfoArray[OpData64] = opinfo{"OpData64", opConstructData, opcodePushData}
+	opinfoArray[OpData65] = opinfo{"OpData65", opConstructData, opcodePushData}
+	opinfoArray[OpPushData] = opinfo{"OpPushData", opConstructPushData, opcodePushData}
 
 	opinfoArray[OpIf] = opinfo{"OpIf", opConstructBranch, opcodeRunBranch}
 	opinfoArray[OpElse] = opinfo{"OpElse", opConstructError, opcodeRunError}
@@ -275,4 +408,8 @@ func init() {
 	opinfoArray[OpHash160] = opinfo{"OpHash160", opConstructDefault, opcodeHash160}
 	opinfoArray[OpEqualVerify] = opinfo{"OpEqualVerify", opConstructDefault, opcodeEqualVerify}
 	opinfoArray[OpCheckSig] = opinfo{"OpCheckSig", opConstructDefault, opcodeCheckSig}
+	opinfoArray[OpCheckMultiSig] = opinfo{"OpCheckMultiSig", opConstructDefault, opcodeCheckMultiSig}
+	opinfoArray[OpSha256] = opinfo{"OpSha256", opConstructDefault, opcodeSha256}
+	opinfoArray[OpCheckSchnorrSig] = opinfo{"OpCheckSchnorrSig", opConstructDefault, opcodeCheckSchnorrSig}
+	opinfoArray[OpCheckSequenceVerify] = opinfo{"OpCheckSequenceVerify", opConstructDefault, opcodeCheckSequenceVerify}
 }
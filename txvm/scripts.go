@@ -12,10 +12,26 @@ import (
 
 	"golang.org/x/crypto/blake2b"
 
+	cm "github.com/iotexproject/iotex-core/common"
 	cp "github.com/iotexproject/iotex-core/crypto"
 	"github.com/iotexproject/iotex-core/iotxaddress"
 )
 
+const (
+	// PubKeyHashScriptType pays to the public key hash of an address
+	PubKeyHashScriptType = "pubkeyhash"
+	// MultisigScriptType pays to the hash of the multisig keys of a multisig address
+	MultisigScriptType = "multisig"
+	// SchnorrScriptType pays to the public key hash of a Schnorr address
+	SchnorrScriptType = "schnorr"
+	// RelativeLockScriptType pays to an address after the output is confirmed for a number of blocks
+	RelativeLockScriptType = "relativelock"
+	// HTLCScriptType pays to a hash time locked contract
+	HTLCScriptType = "htlc"
+	// NonStandardScriptType is any other lock script
+	NonStandardScriptType = "nonstandard"
+)
+
 // printScript formats a disassembled script for one line printing.
 func printScript(bytecodes []byte) (string, error) {
 	var disbuf bytes.Buffer
@@ -44,27 +60,53 @@ func PayToAddrScript(addr string) ([]byte, error) {
 	if err := b.AddData(iotxaddress.GetPubkeyHash(addr)); err != nil {
 		return nil, err
 	}
-	if err := b.AddOps([]byte{OpEqualVerify, OpCheckSig}); err != nil {
+	// a multisig address is locked with the hash of its multisig keys instead of a public key, and a Schnorr address
+	// with a Schnorr public key
+	checksig := byte(OpCheckSig)
+	switch {
+	case iotxaddress.IsMultisigAddress(addr):
+		checksig = OpCheckMultiSig
+	case iotxaddress.IsSchnorrAddress(addr):
+		checksig = OpCheckSchnorrSig
+	}
+	if err := b.AddOps([]byte{OpEqualVerify, checksig}); err != nil {
 		return nil, err
 	}
 	return b.Bytecodes(), nil
 }
 
-// SignatureScript creates an input signature script for a transaction.
+// SignatureScript creates an input signature script for a transaction, signed with a Schnorr signature if the key is
+// a Schnorr key.
 func SignatureScript(txin []byte, pubkey []byte, privkey []byte) ([]byte, error) {
+	hash := blake2b.Sum256(txin)
+	if len(privkey) == cp.SchnorrPrivKeySize {
+		sig, err := cp.SchnorrSign(privkey, hash[:])
+		if err != nil {
+			return nil, err
+		}
+		return SignatureScriptWithSig(sig, pubkey)
+	}
+	return SignatureScriptWithSig(cp.Sign(privkey, hash[:]), pubkey)
+}
+
+// SignatureScriptWithSig creates the input signature script from the signature of the blake2b hash of the txin, signed
+// by the holder of the key
+func SignatureScriptWithSig(sig []byte, pubkey []byte) ([]byte, error) {
+	sigOp, keyOp := byte(OpData64), byte(OpData32)
+	if len(pubkey) == cp.SchnorrPubKeySize {
+		sigOp, keyOp = OpData65, OpData33
+	}
 	b := NewScriptBuilder()
-	err := b.AddOp(OpData64)
+	err := b.AddOp(sigOp)
 	if err != nil {
 		return nil, fmt.Errorf("cannot add data: %v", err)
 	}
-	hash := blake2b.Sum256(txin)
-	sig := cp.Sign(privkey, hash[:])
 	err = b.AddData(sig)
 	if err != nil {
 		return nil, fmt.Errorf("cannot add data: %v", err)
 	}
 
-	err = b.AddOp(OpData32)
+	err = b.AddOp(keyOp)
 	if err != nil {
 		return nil, fmt.Errorf("cannot add data: %v", err)
 	}
@@ -75,3 +117,129 @@ func SignatureScript(txin []byte, pubkey []byte, privkey []byte) ([]byte, error)
 
 	return b.Bytecodes(), nil
 }
+
+// PartialSignature is the signature of a transaction input by one of the keys of a multisig address
+type PartialSignature struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+// SignPartial signs a transaction input with one of the keys of a multisig address
+func SignPartial(txin []byte, pubkey []byte, privkey []byte) *PartialSignature {
+	hash := blake2b.Sum256(txin)
+	return &PartialSignature{PubKey: pubkey, Signature: cp.Sign(privkey, hash[:])}
+}
+
+// MultisigSignatureScript aggregates the partial signatures into an input signature script spending a multisig output.
+// Invalid signatures and signatures of keys not in the multisig keys are skipped, the script fails to build if
+// fewer than M keys have signed.
+func MultisigSignatureScript(txin []byte, multisigKeys []byte, sigs []*PartialSignature) ([]byte, error) {
+	m, pubkeys, err := iotxaddress.ParseMultisigKeys(multisigKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	// pick the signatures in the order of the public keys
+	hash := blake2b.Sum256(txin)
+	b := NewScriptBuilder()
+	signed := 0
+	for _, pubkey := range pubkeys {
+		if signed == m {
+			break
+		}
+		for _, sig := range sigs {
+			if bytes.Equal(sig.PubKey, pubkey) && cp.Verify(pubkey, hash[:], sig.Signature) {
+				if err := b.AddOp(OpData64); err != nil {
+					return nil, fmt.Errorf("cannot add data: %v", err)
+				}
+				if err := b.AddData(sig.Signature); err != nil {
+					return nil, fmt.Errorf("cannot add data: %v", err)
+				}
+				signed++
+				break
+			}
+		}
+	}
+	if signed < m {
+		return nil, fmt.Errorf("%d of %d required signatures", signed, m)
+	}
+
+	if err := b.AddOp(OpPushData); err != nil {
+		return nil, fmt.Errorf("cannot add data: %v", err)
+	}
+	datalen := make([]byte, 2)
+	cm.MachineEndian.PutUint16(datalen, uint16(len(multisigKeys)))
+	if err := b.AddData(append(datalen, multisigKeys...)); err != nil {
+		return nil, fmt.Errorf("cannot add data: %v", err)
+	}
+	return b.Bytecodes(), nil
+}
+
+// RelativeLockScript creates a script paying to the address, which can only be spent after the output is confirmed
+// for the given number of blocks
+func RelativeLockScript(addr string, blocks uint32) ([]byte, error) {
+	locks, err := PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	b := NewScriptBuilder()
+	if err := b.AddOps(locks); err != nil {
+		return nil, err
+	}
+	if err := b.AddOp(OpData4); err != nil {
+		return nil, fmt.Errorf("cannot add data: %v", err)
+	}
+	locktime := make([]byte, 4)
+	cm.MachineEndian.PutUint32(locktime, blocks)
+	if err := b.AddData(locktime); err != nil {
+		return nil, fmt.Errorf("cannot add data: %v", err)
+	}
+	if err := b.AddOp(OpCheckSequenceVerify); err != nil {
+		return nil, err
+	}
+	return b.Bytecodes(), nil
+}
+
+// RelativeLockTime returns the relative lock time of a script created by RelativeLockScript, 0 for other scripts
+func RelativeLockTime(lockScript []byte) uint32 {
+	ast, err := ParseRaw(lockScript)
+	if err != nil || len(ast.nodes) < 2 {
+		return 0
+	}
+	data, op := ast.nodes[len(ast.nodes)-2], ast.nodes[len(ast.nodes)-1]
+	if op.opcode != OpCheckSequenceVerify || data.opcode != OpData4 {
+		return 0
+	}
+	return cm.MachineEndian.Uint32(data.data)
+}
+
+// ScriptType returns the type of the lock script, one of the script types above
+func ScriptType(lockScript []byte) string {
+	if _, err := ParseHTLCScript(lockScript); err == nil {
+		return HTLCScriptType
+	}
+	ast, err := ParseRaw(lockScript)
+	if err != nil {
+		return NonStandardScriptType
+	}
+	nodes := ast.nodes
+	// a relative lock script is a pay to address script followed by the lock time
+	relative := len(nodes) == 7 && matchOpcodes(nodes[5:], []byte{OpData4, OpCheckSequenceVerify})
+	if relative {
+		nodes = nodes[:5]
+	}
+
+	scriptType := NonStandardScriptType
+	switch {
+	case matchOpcodes(nodes, []byte{OpDup, OpHash160, OpData20, OpEqualVerify, OpCheckSig}):
+		scriptType = PubKeyHashScriptType
+	case matchOpcodes(nodes, []byte{OpDup, OpHash160, OpData20, OpEqualVerify, OpCheckMultiSig}):
+		scriptType = MultisigScriptType
+	case matchOpcodes(nodes, []byte{OpDup, OpHash160, OpData20, OpEqualVerify, OpCheckSchnorrSig}):
+		scriptType = SchnorrScriptType
+	}
+	if relative && scriptType != NonStandardScriptType {
+		return RelativeLockScriptType
+	}
+	return scriptType
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	cp "github.com/iotexproject/iotex-core/crypto"
 	"github.com/iotexproject/iotex-core/iotxaddress"
 )
 
@@ -57,3 +58,151 @@ func TestPayToAddrScriptFalse(t *testing.T) {
 	err = v.Execute()
 	assert.Equal(t, "invalid signature", err.Error())
 }
+
+func TestPayToMultisigAddrScript(t *testing.T) {
+	var keys []*iotxaddress.Address
+	var pubkeys [][]byte
+	for i := 0; i < 3; i++ {
+		addr, err := iotxaddress.NewAddress(true, 0x01, []byte{0xa4, 0x00, 0x00, 0x00})
+		assert.Nil(t, err)
+		keys = append(keys, addr)
+		pubkeys = append(pubkeys, addr.PublicKey)
+	}
+	addr, err := iotxaddress.CreateMultisigAddress(2, pubkeys, true, []byte{0xa4, 0x00, 0x00, 0x00})
+	assert.Nil(t, err)
+
+	locks, err := PayToAddrScript(addr.Address)
+	assert.Nil(t, err)
+	parsed, err := printScript(locks)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(parsed, "OpEqualVerify OpCheckMultiSig"))
+
+	txin := []byte{0x11, 0x22, 0x33, 0x44}
+	sig0 := SignPartial(txin, keys[0].PublicKey, keys[0].PrivateKey)
+	sig2 := SignPartial(txin, keys[2].PublicKey, keys[2].PrivateKey)
+
+	// a single signature is not enough
+	_, err = MultisigSignatureScript(txin, addr.PublicKey, []*PartialSignature{sig2})
+	assert.NotNil(t, err)
+
+	// signatures are aggregated in the key order regardless of the order they are given
+	unlocks, err := MultisigSignatureScript(txin, addr.PublicKey, []*PartialSignature{sig2, sig0})
+	assert.Nil(t, err)
+	v, err := NewIVM(txin, append(unlocks, locks...))
+	assert.Nil(t, err)
+	assert.Nil(t, v.Execute())
+
+	// the signatures do not apply to another txin
+	v, err = NewIVM([]byte{0x55}, append(unlocks, locks...))
+	assert.Nil(t, err)
+	assert.NotNil(t, v.Execute())
+
+	// a signature of the wrong key is skipped
+	other := SignPartial(txin, keys[1].PublicKey, keys[0].PrivateKey)
+	_, err = MultisigSignatureScript(txin, addr.PublicKey, []*PartialSignature{sig0, other})
+	assert.NotNil(t, err)
+
+	// the same signature cannot count twice in the script
+	b := NewScriptBuilder()
+	assert.Nil(t, b.AddOp(OpData64))
+	assert.Nil(t, b.AddData(sig0.Signature))
+	assert.Nil(t, b.AddOp(OpData64))
+	assert.Nil(t, b.AddData(sig0.Signature))
+	twice := append(b.Bytecodes(), unlocks[2*65:]...)
+	v, err = NewIVM(txin, append(twice, locks...))
+	assert.Nil(t, err)
+	assert.Equal(t, ErrEvalFalse, v.Execute().(ScriptError).ErrorCode)
+}
+
+func TestPayToSchnorrAddrScript(t *testing.T) {
+	addr, err := iotxaddress.NewSchnorrAddress(true, []byte{0xa4, 0x00, 0x00, 0x00})
+	assert.Nil(t, err)
+	other, err := iotxaddress.NewSchnorrAddress(true, []byte{0xa4, 0x00, 0x00, 0x00})
+	assert.Nil(t, err)
+
+	locks, err := PayToAddrScript(addr.Address)
+	assert.Nil(t, err)
+	parsed, err := printScript(locks)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(parsed, "OpEqualVerify OpCheckSchnorrSig"))
+	assert.Equal(t, SchnorrScriptType, ScriptType(locks))
+
+	txin := []byte{0x11, 0x22, 0x33, 0x44}
+	unlocks, err := SignatureScript(txin, addr.PublicKey, addr.PrivateKey)
+	assert.Nil(t, err)
+	v, err := NewIVM(txin, append(unlocks, locks...))
+	assert.Nil(t, err)
+	assert.Nil(t, v.Execute())
+
+	// the signature of another Schnorr key with the public key of the address is invalid
+	wrong, err := SignatureScript(txin, other.PublicKey, other.PrivateKey)
+	assert.Nil(t, err)
+	forged := append(append([]byte{}, wrong[:1+cp.SchnorrSignatureSize]...), unlocks[1+cp.SchnorrSignatureSize:]...)
+	v, err = NewIVM(txin, append(forged, locks...))
+	assert.Nil(t, err)
+	assert.Equal(t, ErrEvalFalse, v.Execute().(ScriptError).ErrorCode)
+
+	// in batch, the signatures are assumed valid until the batch is verified
+	batch := cp.NewSchnorrBatch()
+	v, err = NewIVM(txin, append(unlocks, locks...))
+	assert.Nil(t, err)
+	v.SetSchnorrBatch(batch)
+	assert.Nil(t, v.Execute())
+	assert.Equal(t, 1, batch.Len())
+	assert.True(t, batch.Verify())
+	v, err = NewIVM(txin, append(forged, locks...))
+	assert.Nil(t, err)
+	v.SetSchnorrBatch(batch)
+	assert.Nil(t, v.Execute())
+	assert.False(t, batch.Verify())
+}
+
+func TestRelativeLockScript(t *testing.T) {
+	addr, err := iotxaddress.NewAddress(true, 0x01, []byte{0xa4, 0x00, 0x00, 0x00})
+	assert.Nil(t, err)
+
+	locks, err := RelativeLockScript(addr.Address, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(10), RelativeLockTime(locks))
+	plain, err := PayToAddrScript(addr.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), RelativeLockTime(plain))
+
+	txin := []byte{0x11, 0x22, 0x33, 0x44}
+	unlocks, err := SignatureScript(txin, addr.PublicKey, addr.PrivateKey)
+	assert.Nil(t, err)
+
+	// the sequence of the input has to reach the relative lock time
+	v, err := NewIVM(txin, append(unlocks, locks...))
+	assert.Nil(t, err)
+	v.SetSequence(9)
+	assert.Equal(t, ErrUnsatisfiedLockTime, v.Execute().(ScriptError).ErrorCode)
+
+	v, err = NewIVM(txin, append(unlocks, locks...))
+	assert.Nil(t, err)
+	v.SetSequence(10)
+	assert.Nil(t, v.Execute())
+}
+
+func TestScriptType(t *testing.T) {
+	addr, err := iotxaddress.NewAddress(true, 0x01, []byte{0xa4, 0x00, 0x00, 0x00})
+	assert.Nil(t, err)
+	other, err := iotxaddress.NewAddress(true, 0x01, []byte{0xa4, 0x00, 0x00, 0x00})
+	assert.Nil(t, err)
+	multisig, err := iotxaddress.CreateMultisigAddress(1, [][]byte{addr.PublicKey, other.PublicKey}, true, []byte{0xa4, 0x00, 0x00, 0x00})
+	assert.Nil(t, err)
+
+	locks, err := PayToAddrScript(addr.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, PubKeyHashScriptType, ScriptType(locks))
+	locks, err = PayToAddrScript(multisig.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, MultisigScriptType, ScriptType(locks))
+	locks, err = RelativeLockScript(addr.Address, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, RelativeLockScriptType, ScriptType(locks))
+	locks, err = HTLCScript(addr.Address, other.Address, make([]byte, 32), 10)
+	assert.Nil(t, err)
+	assert.Equal(t, HTLCScriptType, ScriptType(locks))
+	assert.Equal(t, NonStandardScriptType, ScriptType([]byte{OpDup, OpCheckSig}))
+}
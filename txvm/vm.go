@@ -6,25 +6,56 @@
 
 package txvm
 
+import (
+	cp "github.com/iotexproject/iotex-core/crypto"
+)
+
+// SequenceReplaceable is the flag of an input sequence signaling that the transaction may be replaced by one paying a
+// higher fee, which is not part of the relative lock time the other bits of the sequence carry
+const SequenceReplaceable = uint32(1) << 31
+
 // IVM defines the struct of IoTeX Virtual Machine
 type IVM struct {
 	ast          *IAST
 	contextStack []*IAST
 	dstack       [][]byte
 	txin         []byte
+	sequence     uint32 // sequence of the input being unlocked
+	batch        *cp.SchnorrBatch
 }
 
 // Execute executes IoTeX Virtual Machine
+// the script succeeds only if the top of the stack evaluates to true after execution
 func (vm *IVM) Execute() (err error) {
-	// TODO: evaluate AST recursively
-	for _, node := range vm.ast.nodes {
-		if err := opinfoArray[node.opcode].runfunc(&node, vm); err != nil {
+	if err := vm.runNodes(vm.ast.nodes); err != nil {
+		return err
+	}
+	if len(vm.dstack) == 0 || !castToBool(vm.dstack[len(vm.dstack)-1]) {
+		return scriptError(ErrEvalFalse, "script evaluated to false")
+	}
+	return nil
+}
+
+// runNodes runs the nodes in order, branches run the nodes of the taken branch recursively
+func (vm *IVM) runNodes(nodes []OpNode) error {
+	for i := range nodes {
+		if err := opinfoArray[nodes[i].opcode].runfunc(&nodes[i], vm); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// castToBool returns false if all bytes are zero, true otherwise
+func castToBool(v []byte) bool {
+	for _, b := range v {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // NewIVM creates a new IoTeX Virtual Machine
 func NewIVM(txin, bytecodes []byte) (*IVM, error) {
 	ast, err := ParseRaw(bytecodes)
@@ -34,3 +65,14 @@ func NewIVM(txin, bytecodes []byte) (*IVM, error) {
 	vm := IVM{ast: ast, txin: txin}
 	return &vm, nil
 }
+
+// SetSequence sets the sequence of the input being unlocked, which is checked against relative lock time
+func (vm *IVM) SetSequence(sequence uint32) {
+	vm.sequence = sequence
+}
+
+// SetSchnorrBatch makes the VM add the Schnorr signatures it checks to the batch instead of verifying them, the script
+// only succeeds if the batch verifies as well
+func (vm *IVM) SetSchnorrBatch(batch *cp.SchnorrBatch) {
+	vm.batch = batch
+}
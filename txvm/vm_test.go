@@ -31,3 +31,18 @@ func TestNewIVM(t *testing.T) {
 	err = vm.Execute()
 	assert.Nil(t, err)
 }
+
+func TestIVMEvalFalse(t *testing.T) {
+	t.Parallel()
+
+	builder := NewScriptBuilder()
+	assert.Nil(t, builder.AddOp(OpData3))
+	assert.Nil(t, builder.AddData([]byte{0x12, 0x34, 0x56}))
+	assert.Nil(t, builder.AddOp(Op0))
+
+	vm, err := NewIVM([]byte{}, builder.Bytecodes())
+	assert.Nil(t, err)
+	err = vm.Execute()
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrEvalFalse, err.(ScriptError).ErrorCode)
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/txvm"
+)
+
+// descriptorsFile is the file in the keystore directory the imported descriptors are stored in
+const descriptorsFile = "descriptors.json"
+
+var (
+	// ErrInvalidDescriptor is the error returned when the output script descriptor is malformed
+	ErrInvalidDescriptor = errors.New("invalid descriptor")
+	// ErrDescriptorExists is the error returned when importing a descriptor already in the wallet
+	ErrDescriptorExists = errors.New("descriptor already exists")
+	// ErrMissingSignature is the error returned when the signatures cannot unlock the outputs of a descriptor
+	ErrMissingSignature = errors.New("missing signature")
+)
+
+// Descriptor describes the lock script of the outputs the wallet recognizes, in one of the forms
+//
+//	pkh(<address>)                                   pays to an address, including a Schnorr or a multisig one
+//	multi(<m>,<public key>,...)                      pays to the multisig address of m of the hex public keys
+//	timelock(<address>,<blocks>)                     pays to the address once confirmed for a number of blocks
+//	htlc(<recipient>,<sender>,<hash lock>,<blocks>)  pays to a hash time locked contract with a hex hash lock
+//
+// Each form is one of the script templates of txvm, whose script type is the Type of the descriptor. The outputs of a
+// pkh descriptor of a multisig address are recognized but cannot be unlocked without its multisig keys.
+type Descriptor struct {
+	Type string
+	// Address is the address the outputs pay to, empty for a HTLC
+	Address string
+	// MultisigKeys are the encoded multisig keys of a multisig descriptor
+	MultisigKeys []byte
+	// Recipient, Sender and HashLock are the parties and the hash lock of a HTLC
+	Recipient string
+	Sender    string
+	HashLock  []byte
+	// LockTime is the number of blocks of a timelock or a HTLC refund
+	LockTime uint32
+}
+
+// NewPubKeyHashDescriptor returns the descriptor of the outputs paying to the single-key address
+func NewPubKeyHashDescriptor(address string) *Descriptor {
+	return &Descriptor{Type: txvm.PubKeyHashScriptType, Address: address}
+}
+
+// ParseDescriptor parses the descriptor, the multisig address of a multi descriptor is on the network and chain given
+func ParseDescriptor(desc string, isTestnet bool, chainID uint32) (*Descriptor, error) {
+	desc = strings.TrimSpace(desc)
+	open := strings.IndexByte(desc, '(')
+	if open <= 0 || !strings.HasSuffix(desc, ")") {
+		return nil, errors.Wrapf(ErrInvalidDescriptor, "%s", desc)
+	}
+	args := strings.Split(desc[open+1:len(desc)-1], ",")
+	for i := range args {
+		args[i] = strings.TrimSpace(args[i])
+	}
+
+	var d *Descriptor
+	var err error
+	switch name := desc[:open]; name {
+	case "pkh":
+		d, err = parsePubKeyHash(args)
+	case "multi":
+		d, err = parseMultisig(args, isTestnet, chainID)
+	case "timelock":
+		d, err = parseTimelock(args)
+	case "htlc":
+		d, err = parseHTLC(args)
+	default:
+		err = errors.Errorf("unknown descriptor %s", name)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidDescriptor, "%s: %v", desc, err)
+	}
+	return d, nil
+}
+
+func parsePubKeyHash(args []string) (*Descriptor, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expecting an address")
+	}
+	if !iotxaddress.ValidateAddress(args[0]) {
+		return nil, errors.Errorf("invalid address %s", args[0])
+	}
+	return NewPubKeyHashDescriptor(args[0]), nil
+}
+
+func parseMultisig(args []string, isTestnet bool, chainID uint32) (*Descriptor, error) {
+	if len(args) < 2 {
+		return nil, errors.New("expecting the threshold and the public keys")
+	}
+	m, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, err
+	}
+	pubkeys := make([][]byte, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		pubkey, err := hex.DecodeString(arg)
+		if err != nil {
+			return nil, err
+		}
+		pubkeys = append(pubkeys, pubkey)
+	}
+	chainid := make([]byte, 4)
+	cm.MachineEndian.PutUint32(chainid, chainID)
+	addr, err := iotxaddress.CreateMultisigAddress(m, pubkeys, isTestnet, chainid)
+	if err != nil {
+		return nil, err
+	}
+	return &Descriptor{Type: txvm.MultisigScriptType, Address: addr.Address, MultisigKeys: addr.PublicKey}, nil
+}
+
+func parseTimelock(args []string) (*Descriptor, error) {
+	if len(args) != 2 {
+		return nil, errors.New("expecting an address and the number of blocks")
+	}
+	if err := validateKeyAddress(args[0]); err != nil {
+		return nil, err
+	}
+	blocks, err := strconv.ParseUint(args[1], 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	return &Descriptor{Type: txvm.RelativeLockScriptType, Address: args[0], LockTime: uint32(blocks)}, nil
+}
+
+func parseHTLC(args []string) (*Descriptor, error) {
+	if len(args) != 4 {
+		return nil, errors.New("expecting the recipient, the sender, the hash lock and the number of blocks")
+	}
+	for _, address := range args[:2] {
+		if err := validateKeyAddress(address); err != nil {
+			return nil, err
+		}
+	}
+	hashLock, err := hex.DecodeString(args[2])
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := strconv.ParseUint(args[3], 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	d := &Descriptor{Type: txvm.HTLCScriptType, Recipient: args[0], Sender: args[1], HashLock: hashLock, LockTime: uint32(blocks)}
+	// the hash lock is checked by building the script
+	if _, err := d.LockScript(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// validateKeyAddress returns error unless the address is a single-key address
+func validateKeyAddress(address string) error {
+	if !iotxaddress.ValidateAddress(address) || iotxaddress.IsMultisigAddress(address) {
+		return errors.Errorf("invalid single-key address %s", address)
+	}
+	return nil
+}
+
+// String returns the canonical form of the descriptor, which ParseDescriptor parses back
+func (d *Descriptor) String() string {
+	switch d.Type {
+	case txvm.MultisigScriptType:
+		m, pubkeys, _ := iotxaddress.ParseMultisigKeys(d.MultisigKeys)
+		args := []string{strconv.Itoa(m)}
+		for _, pubkey := range pubkeys {
+			args = append(args, hex.EncodeToString(pubkey))
+		}
+		return fmt.Sprintf("multi(%s)", strings.Join(args, ","))
+	case txvm.RelativeLockScriptType:
+		return fmt.Sprintf("timelock(%s,%d)", d.Address, d.LockTime)
+	case txvm.HTLCScriptType:
+		return fmt.Sprintf("htlc(%s,%s,%x,%d)", d.Recipient, d.Sender, d.HashLock, d.LockTime)
+	default:
+		return fmt.Sprintf("pkh(%s)", d.Address)
+	}
+}
+
+// LockScript returns the lock script of the outputs of the descriptor
+func (d *Descriptor) LockScript() ([]byte, error) {
+	switch d.Type {
+	case txvm.RelativeLockScriptType:
+		return txvm.RelativeLockScript(d.Address, d.LockTime)
+	case txvm.HTLCScriptType:
+		return txvm.HTLCScript(d.Recipient, d.Sender, d.HashLock, d.LockTime)
+	default:
+		return txvm.PayToAddrScript(d.Address)
+	}
+}
+
+// UnlockScript returns the unlock script spending an output of the descriptor from the signatures of the txin, the
+// preimage redeems a HTLC, which is refunded to the sender without it
+// ErrMissingSignature is returned unless the signatures are made by the keys the output requires.
+func (d *Descriptor) UnlockScript(txin []byte, sigs []*txvm.PartialSignature, preimage []byte) ([]byte, error) {
+	switch d.Type {
+	case txvm.MultisigScriptType:
+		unlock, err := txvm.MultisigSignatureScript(txin, d.MultisigKeys, sigs)
+		if err != nil {
+			return nil, errors.Wrapf(ErrMissingSignature, "%v", err)
+		}
+		return unlock, nil
+	case txvm.HTLCScriptType:
+		if len(preimage) > 0 {
+			if sig := signatureOf(sigs, d.Recipient); sig != nil {
+				return txvm.HTLCRedeemScriptWithSig(sig.Signature, sig.PubKey, preimage)
+			}
+			return nil, errors.Wrapf(ErrMissingSignature, "recipient %s has not signed", d.Recipient)
+		}
+		if sig := signatureOf(sigs, d.Sender); sig != nil {
+			return txvm.HTLCRefundScriptWithSig(sig.Signature, sig.PubKey)
+		}
+		return nil, errors.Wrapf(ErrMissingSignature, "sender %s has not signed", d.Sender)
+	default:
+		if sig := signatureOf(sigs, d.Address); sig != nil {
+			return txvm.SignatureScriptWithSig(sig.Signature, sig.PubKey)
+		}
+		return nil, errors.Wrapf(ErrMissingSignature, "%s has not signed", d.Address)
+	}
+}
+
+// signatureOf returns the signature made by the key of the address, nil if there is none
+func signatureOf(sigs []*txvm.PartialSignature, address string) *txvm.PartialSignature {
+	pkHash := iotxaddress.GetPubkeyHash(address)
+	for _, sig := range sigs {
+		if bytes.Equal(iotxaddress.HashPubKey(sig.PubKey), pkHash) {
+			return sig
+		}
+	}
+	return nil
+}
+
+// ImportDescriptor imports the descriptor, so the outputs of its lock script are tracked by the wallet, and returns its
+// canonical form
+func (w *Wallet) ImportDescriptor(desc string) (string, error) {
+	d, err := ParseDescriptor(desc, w.cfg.IsTestnet, w.cfg.ChainID)
+	if err != nil {
+		return "", err
+	}
+	descs, err := w.Descriptors()
+	if err != nil {
+		return "", err
+	}
+	for _, existing := range descs {
+		if existing.String() == d.String() {
+			return "", errors.Wrapf(ErrDescriptorExists, "%s", d)
+		}
+	}
+	imported, err := readDescriptors(w.cfg.KeystorePath)
+	if err != nil {
+		return "", err
+	}
+	if err := writeDescriptors(w.cfg.KeystorePath, append(imported, d.String())); err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
+
+// Descriptors returns the descriptors the wallet recognizes the outputs of, which are the single-key descriptors of
+// its accounts, including the watch-only ones, followed by the imported descriptors sorted by their canonical form
+func (w *Wallet) Descriptors() ([]*Descriptor, error) {
+	accounts, err := w.Accounts()
+	if err != nil {
+		return nil, err
+	}
+	descs := make([]*Descriptor, 0, len(accounts))
+	for _, address := range accounts {
+		descs = append(descs, NewPubKeyHashDescriptor(address))
+	}
+	imported, err := readDescriptors(w.cfg.KeystorePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range imported {
+		d, err := ParseDescriptor(desc, w.cfg.IsTestnet, w.cfg.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		descs = append(descs, d)
+	}
+	return descs, nil
+}
+
+// ExportDescriptors returns the canonical forms of the descriptors of the wallet, which can be imported into another
+// wallet to track the same outputs
+func (w *Wallet) ExportDescriptors() ([]string, error) {
+	descs, err := w.Descriptors()
+	if err != nil {
+		return nil, err
+	}
+	exported := make([]string, 0, len(descs))
+	for _, d := range descs {
+		exported = append(exported, d.String())
+	}
+	return exported, nil
+}
+
+// Signers returns the signing handles of the unlocked accounts
+func (w *Wallet) Signers() []Signer {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	addresses := make([]string, 0, len(w.unlocked))
+	for address := range w.unlocked {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	signers := make([]Signer, 0, len(addresses))
+	for _, address := range addresses {
+		signers = append(signers, &accountSigner{w: w, address: address, pubkey: w.unlocked[address].addr.PublicKey})
+	}
+	return signers
+}
+
+// readDescriptors reads the sorted descriptors imported into the keystore directory
+func readDescriptors(dir string) ([]string, error) {
+	buf, err := ioutil.ReadFile(filepath.Join(dir, descriptorsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	descs := []string{}
+	if err := json.Unmarshal(buf, &descs); err != nil {
+		return nil, errors.Wrapf(ErrInvalidDescriptor, "%v", err)
+	}
+	return descs, nil
+}
+
+// writeDescriptors writes the descriptors sorted into the keystore directory, through a temporary file as the key
+// files are
+func writeDescriptors(dir string, descs []string) error {
+	sort.Strings(descs)
+	buf, err := json.Marshal(descs)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, "."+descriptorsFile)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, descriptorsFile))
+}
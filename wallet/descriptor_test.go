@@ -0,0 +1,151 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/txvm"
+)
+
+func TestParseDescriptor(t *testing.T) {
+	assert := assert.New(t)
+	alfa, bravo := ta.Addrinfo["alfa"], ta.Addrinfo["bravo"]
+	hashLock := strings.Repeat("ab", 32)
+
+	for _, desc := range []string{
+		"pkh(" + alfa.Address + ")",
+		"multi(2," + hex.EncodeToString(alfa.PublicKey) + "," + hex.EncodeToString(bravo.PublicKey) + ")",
+		"timelock(" + alfa.Address + ",10)",
+		"htlc(" + alfa.Address + "," + bravo.Address + "," + hashLock + ",20)",
+	} {
+		d, err := ParseDescriptor(desc, false, 1)
+		assert.Nil(err)
+		assert.Equal(desc, d.String())
+		lockScript, err := d.LockScript()
+		assert.Nil(err)
+		assert.Equal(d.Type, txvm.ScriptType(lockScript))
+	}
+
+	// the spaces are trimmed
+	d, err := ParseDescriptor(" timelock( "+alfa.Address+" , 10 ) ", false, 1)
+	assert.Nil(err)
+	assert.Equal(uint32(10), d.LockTime)
+
+	for _, desc := range []string{
+		"",
+		"pkh",
+		"wpkh(" + alfa.Address + ")",
+		"pkh(" + alfa.Address + "," + bravo.Address + ")",
+		"pkh(alfa)",
+		"multi(3," + hex.EncodeToString(alfa.PublicKey) + "," + hex.EncodeToString(bravo.PublicKey) + ")",
+		"multi(1,zz)",
+		"timelock(" + alfa.Address + ",-1)",
+		"htlc(" + alfa.Address + "," + bravo.Address + ",abcd,20)",
+	} {
+		_, err := ParseDescriptor(desc, false, 1)
+		assert.Equal(ErrInvalidDescriptor, errors.Cause(err), desc)
+	}
+}
+
+func TestDescriptorUnlockScript(t *testing.T) {
+	assert := assert.New(t)
+	alfa, bravo := ta.Addrinfo["alfa"], ta.Addrinfo["bravo"]
+	txin := []byte("txin")
+	hash := blake2b.Sum256(txin)
+	sign := func(name string) *txvm.PartialSignature {
+		sig, err := NewKeySigner(ta.Addrinfo[name]).Sign(hash[:])
+		assert.Nil(err)
+		return &txvm.PartialSignature{PubKey: ta.Addrinfo[name].PublicKey, Signature: sig}
+	}
+
+	// a single-key output needs the signature of its key
+	d := NewPubKeyHashDescriptor(alfa.Address)
+	_, err := d.UnlockScript(txin, []*txvm.PartialSignature{sign("bravo")}, nil)
+	assert.Equal(ErrMissingSignature, errors.Cause(err))
+	unlock, err := d.UnlockScript(txin, []*txvm.PartialSignature{sign("bravo"), sign("alfa")}, nil)
+	assert.Nil(err)
+	expected, err := txvm.SignatureScriptWithSig(sign("alfa").Signature, alfa.PublicKey)
+	assert.Nil(err)
+	assert.Equal(expected, unlock)
+
+	// a multisig output needs m signatures
+	d, err = ParseDescriptor("multi(2,"+hex.EncodeToString(alfa.PublicKey)+","+hex.EncodeToString(bravo.PublicKey)+")", false, 1)
+	assert.Nil(err)
+	_, err = d.UnlockScript(txin, []*txvm.PartialSignature{sign("alfa")}, nil)
+	assert.Equal(ErrMissingSignature, errors.Cause(err))
+	_, err = d.UnlockScript(txin, []*txvm.PartialSignature{sign("alfa"), sign("bravo")}, nil)
+	assert.Nil(err)
+
+	// a HTLC is redeemed by the recipient with the preimage, or refunded to the sender
+	d, err = ParseDescriptor("htlc("+alfa.Address+","+bravo.Address+","+strings.Repeat("ab", 32)+",20)", false, 1)
+	assert.Nil(err)
+	_, err = d.UnlockScript(txin, []*txvm.PartialSignature{sign("bravo")}, []byte("secret"))
+	assert.Equal(ErrMissingSignature, errors.Cause(err))
+	unlock, err = d.UnlockScript(txin, []*txvm.PartialSignature{sign("alfa")}, []byte("secret"))
+	assert.Nil(err)
+	assert.Equal([]byte("secret"), txvm.HTLCPreimage(unlock))
+	_, err = d.UnlockScript(txin, []*txvm.PartialSignature{sign("alfa")}, nil)
+	assert.Equal(ErrMissingSignature, errors.Cause(err))
+	_, err = d.UnlockScript(txin, []*txvm.PartialSignature{sign("bravo")}, nil)
+	assert.Nil(err)
+}
+
+func TestImportExportDescriptors(t *testing.T) {
+	assert := assert.New(t)
+	w := newTestWallet(t)
+	defer os.RemoveAll(w.cfg.KeystorePath)
+	alfa, bravo := ta.Addrinfo["alfa"], ta.Addrinfo["bravo"]
+
+	assert.Nil(w.ImportAddress(alfa.Address))
+	timelock := "timelock(" + bravo.Address + ",10)"
+	imported, err := w.ImportDescriptor(" " + timelock)
+	assert.Nil(err)
+	assert.Equal(timelock, imported)
+	_, err = w.ImportDescriptor(timelock)
+	assert.Equal(ErrDescriptorExists, errors.Cause(err))
+	// the descriptors of the accounts are implied
+	_, err = w.ImportDescriptor("pkh(" + alfa.Address + ")")
+	assert.Equal(ErrDescriptorExists, errors.Cause(err))
+	_, err = w.ImportDescriptor("pkh(bravo)")
+	assert.Equal(ErrInvalidDescriptor, errors.Cause(err))
+
+	exported, err := w.ExportDescriptors()
+	assert.Nil(err)
+	assert.Equal([]string{"pkh(" + alfa.Address + ")", timelock}, exported)
+	// the descriptors file is not an account
+	accounts, err := w.Accounts()
+	assert.Nil(err)
+	assert.Equal([]string{alfa.Address}, accounts)
+
+	// the exported descriptors are imported into another wallet
+	other := newTestWallet(t)
+	defer os.RemoveAll(other.cfg.KeystorePath)
+	for _, desc := range exported {
+		_, err := other.ImportDescriptor(desc)
+		assert.Nil(err)
+	}
+	descs, err := other.ExportDescriptors()
+	assert.Nil(err)
+	assert.Equal(exported, descs)
+
+	// only the unlocked accounts sign
+	assert.Equal(0, len(w.Signers()))
+	assert.Nil(w.ImportKey(&bravo, "foo"))
+	assert.Nil(w.Unlock(bravo.Address, "foo", 0))
+	signers := w.Signers()
+	assert.Equal(1, len(signers))
+	assert.Equal(bravo.Address, signers[0].Address())
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+)
+
+const (
+	// APDU of the signing application on the device
+	apduClass        = 0xe0
+	insGetPublicKey  = 0x02
+	insSign          = 0x04
+	p1FirstChunk     = 0x00
+	p1MoreChunks     = 0x80
+	maxApduDataSize  = 0xff
+	statusOK         = 0x9000
+	statusDenied     = 0x6985
+	statusWordLength = 2
+)
+
+var (
+	// ErrDevice is the error returned when the external signing device fails or responds unexpectedly
+	ErrDevice = errors.New("signing device error")
+	// ErrDeviceDenied is the error returned when the user rejects the request on the external signing device
+	ErrDeviceDenied = errors.New("denied on signing device")
+)
+
+// Device is the transport to an external signing device, e.g., a hardware wallet, which keeps the private keys and
+// only hands out public keys and signatures
+type Device interface {
+	// Exchange sends the APDU command to the device and returns its response, ending with the status word
+	Exchange(apdu []byte) ([]byte, error)
+	// Close closes the transport
+	Close() error
+}
+
+// deviceSigner signs with a key held by an external signing device
+type deviceSigner struct {
+	mu      sync.Mutex
+	dev     Device
+	path    []byte
+	address string
+	pubkey  []byte
+}
+
+// NewDeviceSigner returns the signing handle of the key derived at the path, e.g., iotxaddress.DerivationPath(0, 0, 0),
+// by the external signing device, with the address of the network of the config
+// The private key never leaves the device, which has to confirm every signature. The address can be imported into the
+// wallet as a watch-only account to track its balance.
+func NewDeviceSigner(dev Device, path string, cfg config.Wallet) (Signer, error) {
+	indexes, err := iotxaddress.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &deviceSigner{dev: dev, path: encodeDerivationPath(indexes)}
+	pubkey, err := s.exchange(insGetPublicKey, s.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(pubkey) != ed25519.PublicKeySize {
+		return nil, errors.Wrapf(ErrDevice, "public key is %d bytes", len(pubkey))
+	}
+	chainid := make([]byte, 4)
+	cm.MachineEndian.PutUint32(chainid, cfg.ChainID)
+	s.address, err = iotxaddress.GetAddress(pubkey, cfg.IsTestnet, addressVersion, chainid)
+	if err != nil {
+		return nil, err
+	}
+	s.pubkey = pubkey
+	return s, nil
+}
+
+func (s *deviceSigner) Address() string { return s.address }
+
+func (s *deviceSigner) PublicKey() []byte { return s.pubkey }
+
+// Sign sends the derivation path followed by the message to the device, split into chunks fitting an APDU
+func (s *deviceSigner) Sign(msg []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := append(append([]byte{}, s.path...), msg...)
+	var sig []byte
+	for p1 := byte(p1FirstChunk); len(data) > 0; p1 = p1MoreChunks {
+		size := len(data)
+		if size > maxApduDataSize {
+			size = maxApduDataSize
+		}
+		resp, err := s.exchangeChunk(insSign, p1, data[:size])
+		if err != nil {
+			return nil, err
+		}
+		data = data[size:]
+		sig = resp
+	}
+	// a faulty device must not get a bad signature into a transaction
+	if len(sig) != ed25519.SignatureSize || !cp.Verify(s.pubkey, msg, sig) {
+		return nil, errors.Wrapf(ErrDevice, "invalid signature by %s", s.address)
+	}
+	return sig, nil
+}
+
+// exchange sends a command fitting in a single APDU
+func (s *deviceSigner) exchange(ins byte, data []byte) ([]byte, error) {
+	return s.exchangeChunk(ins, p1FirstChunk, data)
+}
+
+// exchangeChunk sends a chunk of a command and returns the response data without the status word
+func (s *deviceSigner) exchangeChunk(ins byte, p1 byte, data []byte) ([]byte, error) {
+	apdu := append([]byte{apduClass, ins, p1, 0x00, byte(len(data))}, data...)
+	resp, err := s.dev.Exchange(apdu)
+	if err != nil {
+		return nil, errors.Wrapf(ErrDevice, "%v", err)
+	}
+	if len(resp) < statusWordLength {
+		return nil, errors.Wrapf(ErrDevice, "response of %d bytes", len(resp))
+	}
+	status := binary.BigEndian.Uint16(resp[len(resp)-statusWordLength:])
+	switch status {
+	case statusOK:
+		return resp[:len(resp)-statusWordLength], nil
+	case statusDenied:
+		return nil, errors.Wrapf(ErrDeviceDenied, "%s", s.address)
+	default:
+		return nil, errors.Wrapf(ErrDevice, "status %04x", status)
+	}
+}
+
+// encodeDerivationPath encodes the child indexes of the derivation path as sent to the device, the number of levels
+// followed by the big-endian indexes
+func encodeDerivationPath(indexes []uint32) []byte {
+	path := []byte{byte(len(indexes))}
+	for _, index := range indexes {
+		buf := make([]byte, 4)
+		binary.BigEndian.PutUint32(buf, index)
+		path = append(path, buf...)
+	}
+	return path
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"github.com/pkg/errors"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+)
+
+const (
+	// receiveChain and changeChain are the BIP44 chains of the receive and change addresses
+	receiveChain = 0
+	changeChain  = 1
+)
+
+// HDWallet derives the receive and change addresses of the accounts from the seed of a mnemonic
+// The same mnemonic and passphrase always derive the same addresses, so they are enough to restore a wallet.
+type HDWallet struct {
+	master    *iotxaddress.HDKey
+	isTestnet bool
+	chainid   []byte
+}
+
+// NewHDWallet returns the HD wallet of the mnemonic and its optional passphrase, deriving addresses of the network
+// of the config
+func NewHDWallet(mnemonic string, passphrase string, cfg config.Wallet) (*HDWallet, error) {
+	seed, err := iotxaddress.MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	master, err := iotxaddress.NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	chainid := make([]byte, 4)
+	cm.MachineEndian.PutUint32(chainid, cfg.ChainID)
+	return &HDWallet{master: master, isTestnet: cfg.IsTestnet, chainid: chainid}, nil
+}
+
+// ReceiveAddress derives the index-th receive address of the account
+func (hd *HDWallet) ReceiveAddress(account uint32, index uint32) (*iotxaddress.Address, error) {
+	return hd.derive(account, receiveChain, index)
+}
+
+// ChangeAddress derives the index-th change address of the account
+func (hd *HDWallet) ChangeAddress(account uint32, index uint32) (*iotxaddress.Address, error) {
+	return hd.derive(account, changeChain, index)
+}
+
+func (hd *HDWallet) derive(account uint32, change uint32, index uint32) (*iotxaddress.Address, error) {
+	key, err := hd.master.Derive(iotxaddress.DerivationPath(account, change, index))
+	if err != nil {
+		return nil, err
+	}
+	return key.Address(hd.isTestnet, addressVersion, hd.chainid)
+}
+
+// Restore imports the first 'count' receive and change addresses of the account into the keystore, encrypted with
+// the passphrase, and returns the addresses in the order of derivation
+// Addresses already in the keystore are kept as they are.
+func (w *Wallet) Restore(hd *HDWallet, account uint32, count uint32, passphrase string) ([]string, error) {
+	addresses := []string{}
+	for i := uint32(0); i < count; i++ {
+		for _, change := range []uint32{receiveChain, changeChain} {
+			addr, err := hd.derive(account, change, i)
+			if err != nil {
+				return nil, err
+			}
+			if err := w.storeKey(addr, passphrase); err != nil && errors.Cause(err) != ErrAccountExists {
+				return nil, err
+			}
+			addresses = append(addresses, addr.Address)
+		}
+	}
+	return addresses, nil
+}
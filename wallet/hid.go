@@ -0,0 +1,104 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// HID framing of the APDU exchanged with the device
+	hidReportSize   = 64
+	hidChannel      = 0x0101
+	hidTagApdu      = 0x05
+	hidHeaderLength = 5
+)
+
+// hidDevice exchanges APDU with a device over HID reports, where each APDU is split into reports tagged with the
+// channel and their sequence number, the first one also carrying the length of the APDU
+type hidDevice struct {
+	rw io.ReadWriteCloser
+}
+
+// OpenHIDDevice opens the raw HID device node of the external signing device, e.g., /dev/hidraw0
+func OpenHIDDevice(path string) (Device, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return NewHIDDevice(f), nil
+}
+
+// NewHIDDevice returns the device exchanging APDU over the HID reports read from and written to rw
+func NewHIDDevice(rw io.ReadWriteCloser) Device {
+	return &hidDevice{rw: rw}
+}
+
+// Exchange writes the APDU in HID reports and reads back the response
+func (d *hidDevice) Exchange(apdu []byte) ([]byte, error) {
+	if err := d.write(apdu); err != nil {
+		return nil, err
+	}
+	return d.read()
+}
+
+// Close closes the device node
+func (d *hidDevice) Close() error {
+	return d.rw.Close()
+}
+
+func (d *hidDevice) write(apdu []byte) error {
+	data := make([]byte, 2, len(apdu)+2)
+	binary.BigEndian.PutUint16(data, uint16(len(apdu)))
+	data = append(data, apdu...)
+	for seq := uint16(0); len(data) > 0; seq++ {
+		// the report is prefixed with the report ID 0
+		report := make([]byte, hidReportSize+1)
+		d.header(report[1:], seq)
+		n := copy(report[1+hidHeaderLength:], data)
+		data = data[n:]
+		if _, err := d.rw.Write(report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *hidDevice) read() ([]byte, error) {
+	var resp []byte
+	length := -1
+	for seq := uint16(0); length < 0 || len(resp) < length; seq++ {
+		report := make([]byte, hidReportSize)
+		if _, err := io.ReadFull(d.rw, report); err != nil {
+			return nil, err
+		}
+		header := make([]byte, hidHeaderLength)
+		d.header(header, seq)
+		if !bytes.Equal(report[:hidHeaderLength], header) {
+			return nil, errors.Errorf("unexpected report header %x", report[:hidHeaderLength])
+		}
+		data := report[hidHeaderLength:]
+		if seq == 0 {
+			length = int(binary.BigEndian.Uint16(data))
+			data = data[2:]
+		}
+		resp = append(resp, data...)
+	}
+	return resp[:length], nil
+}
+
+// header writes the header of the seq-th report of an APDU
+func (d *hidDevice) header(report []byte, seq uint16) {
+	binary.BigEndian.PutUint16(report, hidChannel)
+	report[2] = hidTagApdu
+	binary.BigEndian.PutUint16(report[3:], seq)
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/scrypt"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+)
+
+const (
+	// StandardScryptN and StandardScryptP are the scrypt cost parameters of key encryption
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	// LightScryptN and LightScryptP are the cheaper scrypt cost parameters for testing
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	keyFileVersion = 1
+	scryptR        = 8
+	scryptDKLen    = 32
+	// maxScryptN and maxScryptP, along with scryptR, bound the memory and time deriving the key of a key file read takes
+	maxScryptN = 1 << 20
+	maxScryptP = 16
+	saltSize   = 32
+)
+
+// keyFile is the JSON format of an encrypted key stored in the keystore
+// The key file of a watch-only account has no crypto, and its public key is empty if only the address is known.
+type keyFile struct {
+	Version   int       `json:"version"`
+	Address   string    `json:"address"`
+	PublicKey string    `json:"publickey"`
+	Crypto    keyCrypto `json:"crypto"`
+	WatchOnly bool      `json:"watchonly,omitempty"`
+}
+
+// keyCrypto is the private key encrypted by AES-GCM with the key derived from the passphrase by scrypt
+type keyCrypto struct {
+	Cipher     string    `json:"cipher"`
+	CipherText string    `json:"ciphertext"`
+	Nonce      string    `json:"nonce"`
+	KDF        string    `json:"kdf"`
+	KDFParams  kdfParams `json:"kdfparams"`
+}
+
+type kdfParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// encryptKey encrypts the private key of the address with the passphrase
+func encryptKey(addr *iotxaddress.Address, passphrase string, scryptN, scryptP int) (*keyFile, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newCipher(passphrase, salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	// the address is authenticated along with the key, so that a key file cannot be relabeled
+	ciphertext := gcm.Seal(nil, nonce, addr.PrivateKey, []byte(addr.Address))
+
+	return &keyFile{
+		Version:   keyFileVersion,
+		Address:   addr.Address,
+		PublicKey: hex.EncodeToString(addr.PublicKey),
+		Crypto: keyCrypto{
+			Cipher:     "aes-256-gcm",
+			CipherText: hex.EncodeToString(ciphertext),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        "scrypt",
+			KDFParams:  kdfParams{N: scryptN, R: scryptR, P: scryptP, DKLen: scryptDKLen, Salt: hex.EncodeToString(salt)},
+		},
+	}, nil
+}
+
+// decryptKey decrypts the key file with the passphrase
+func decryptKey(kf *keyFile, passphrase string) (*iotxaddress.Address, error) {
+	if kf.WatchOnly {
+		return nil, errors.Wrapf(ErrWatchOnly, "%s", kf.Address)
+	}
+	if kf.Version != keyFileVersion || kf.Crypto.Cipher != "aes-256-gcm" || kf.Crypto.KDF != "scrypt" {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "Unsupported key file version %d, cipher %s, kdf %s", kf.Version,
+			kf.Crypto.Cipher, kf.Crypto.KDF)
+	}
+	pubkey, err := hex.DecodeString(kf.PublicKey)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "%v", err)
+	}
+	ciphertext, err := hex.DecodeString(kf.Crypto.CipherText)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "%v", err)
+	}
+	nonce, err := hex.DecodeString(kf.Crypto.Nonce)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "%v", err)
+	}
+	salt, err := hex.DecodeString(kf.Crypto.KDFParams.Salt)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "%v", err)
+	}
+
+	params := kf.Crypto.KDFParams
+	if params.N > maxScryptN || params.R > scryptR || params.P > maxScryptP || params.DKLen != scryptDKLen {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "Scrypt parameters N %d, r %d, p %d, dklen %d out of bounds", params.N,
+			params.R, params.P, params.DKLen)
+	}
+	gcm, err := newCipher(passphrase, salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "%v", err)
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "Nonce is %d bytes, expecting %d", len(nonce), gcm.NonceSize())
+	}
+	privkey, err := gcm.Open(nil, nonce, ciphertext, []byte(kf.Address))
+	if err != nil {
+		return nil, errors.Wrapf(ErrWrongPassphrase, "Cannot decrypt the key of %s", kf.Address)
+	}
+	schnorr := iotxaddress.IsSchnorrAddress(kf.Address)
+	if !schnorr && (len(privkey) != ed25519.PrivateKeySize || len(pubkey) != ed25519.PublicKeySize) ||
+		schnorr && (len(privkey) != cp.SchnorrPrivKeySize || len(pubkey) != cp.SchnorrPubKeySize) {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "Key of %s has wrong size", kf.Address)
+	}
+	return &iotxaddress.Address{PublicKey: pubkey, PrivateKey: privkey, Address: kf.Address}, nil
+}
+
+// newCipher derives the key encryption key from the passphrase and returns its AES-GCM cipher
+func newCipher(passphrase string, salt []byte, n, r, p, dklen int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, dklen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// keyFilePath returns the path of the key file of the address in the keystore directory
+func keyFilePath(dir string, address string) string {
+	return filepath.Join(dir, address+".json")
+}
+
+// readKeyFile reads the key file of the address from the keystore directory
+func readKeyFile(dir string, address string) (*keyFile, error) {
+	buf, err := ioutil.ReadFile(keyFilePath(dir, address))
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(ErrAccountNotFound, "%s", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseKeyFile(buf)
+}
+
+// parseKeyFile decodes the JSON key file
+func parseKeyFile(buf []byte) (*keyFile, error) {
+	kf := keyFile{}
+	if err := json.Unmarshal(buf, &kf); err != nil {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "%v", err)
+	}
+	if !iotxaddress.ValidateAddress(kf.Address) {
+		return nil, errors.Wrapf(ErrInvalidKeyFile, "Invalid address %s", kf.Address)
+	}
+	return &kf, nil
+}
+
+// writeKeyFile writes the key file into the keystore directory, readable by the owner only
+// The file is written to a temporary file first and renamed, so that a crash never leaves a partial key file.
+func writeKeyFile(dir string, kf *keyFile) error {
+	buf, err := json.Marshal(kf)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, "."+kf.Address)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), keyFilePath(dir, kf.Address))
+}
@@ -0,0 +1,400 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+var log = logger.New("wallet")
+
+const (
+	// addressVersion is the version of the addresses created by the wallet
+	addressVersion = 0x01
+)
+
+var (
+	// ErrAccountNotFound is the error returned when the account is not in the keystore
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountExists is the error returned when importing an account already in the keystore
+	ErrAccountExists = errors.New("account already exists")
+	// ErrWrongPassphrase is the error returned when the key cannot be decrypted with the passphrase
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+	// ErrInvalidKeyFile is the error returned when the key file is malformed
+	ErrInvalidKeyFile = errors.New("invalid key file")
+	// ErrLocked is the error returned when signing with a locked account
+	ErrLocked = errors.New("account is locked")
+	// ErrWatchOnly is the error returned when decrypting the key of a watch-only account, which has no private key
+	ErrWatchOnly = errors.New("account is watch-only")
+)
+
+// Signer is the handle of an account which signs on behalf of the address, without handing out its private key
+type Signer interface {
+	// Address returns the address of the account
+	Address() string
+	// PublicKey returns the public key of the account
+	PublicKey() []byte
+	// Sign signs the message with the private key of the account
+	Sign(msg []byte) ([]byte, error)
+}
+
+// unlocked is the decrypted key of an unlocked account
+type unlocked struct {
+	addr  *iotxaddress.Address
+	timer *time.Timer
+}
+
+// Wallet manages the accounts whose keys are encrypted in the keystore directory
+// The key of an account is only decrypted in memory while the account is unlocked.
+type Wallet struct {
+	mu       sync.RWMutex
+	cfg      config.Wallet
+	unlocked map[string]*unlocked
+}
+
+// NewWallet returns a wallet on the keystore directory of the config
+func NewWallet(cfg config.Wallet) *Wallet {
+	if cfg.ScryptN == 0 {
+		cfg.ScryptN = StandardScryptN
+	}
+	if cfg.ScryptP == 0 {
+		cfg.ScryptP = StandardScryptP
+	}
+	return &Wallet{cfg: cfg, unlocked: make(map[string]*unlocked)}
+}
+
+// NewAccount creates an account with a new key pair encrypted with the passphrase, and returns its address
+func (w *Wallet) NewAccount(passphrase string) (string, error) {
+	chainid := make([]byte, 4)
+	cm.MachineEndian.PutUint32(chainid, w.cfg.ChainID)
+	addr, err := iotxaddress.NewAddress(w.cfg.IsTestnet, addressVersion, chainid)
+	if err != nil {
+		return "", err
+	}
+	if err := w.storeKey(addr, passphrase); err != nil {
+		return "", err
+	}
+	log.Infof("Created account %s", addr.Address)
+	return addr.Address, nil
+}
+
+// NewSchnorrAccount creates an account with a new Schnorr key pair encrypted with the passphrase, and returns its
+// address, whose outputs are spent with Schnorr signatures verified in batch
+func (w *Wallet) NewSchnorrAccount(passphrase string) (string, error) {
+	chainid := make([]byte, 4)
+	cm.MachineEndian.PutUint32(chainid, w.cfg.ChainID)
+	addr, err := iotxaddress.NewSchnorrAddress(w.cfg.IsTestnet, chainid)
+	if err != nil {
+		return "", err
+	}
+	if err := w.storeKey(addr, passphrase); err != nil {
+		return "", err
+	}
+	log.Infof("Created Schnorr account %s", addr.Address)
+	return addr.Address, nil
+}
+
+// ImportKey imports the raw key pair of the address, encrypted with the passphrase
+func (w *Wallet) ImportKey(addr *iotxaddress.Address, passphrase string) error {
+	if !iotxaddress.ValidateAddress(addr.Address) {
+		return errors.Wrapf(ErrInvalidKeyFile, "Invalid address %s", addr.Address)
+	}
+	if len(addr.PrivateKey) == 0 {
+		return errors.Wrapf(ErrInvalidKeyFile, "Address %s has no private key", addr.Address)
+	}
+	return w.storeKey(addr, passphrase)
+}
+
+// ImportAddress imports the address as a watch-only account, whose UTXO are tracked by the wallet while the key is held
+// elsewhere
+func (w *Wallet) ImportAddress(address string) error {
+	if !iotxaddress.ValidateAddress(address) {
+		return errors.Wrapf(ErrInvalidKeyFile, "Invalid address %s", address)
+	}
+	return w.storeWatchOnly(address, nil)
+}
+
+// ImportPublicKey imports the public key as a watch-only account, and returns its address on the network and chain of
+// the wallet, a Schnorr public key has a Schnorr address
+func (w *Wallet) ImportPublicKey(pubKey []byte) (string, error) {
+	version := byte(addressVersion)
+	switch len(pubKey) {
+	case ed25519.PublicKeySize:
+	case cp.SchnorrPubKeySize:
+		version = iotxaddress.SchnorrVersion
+	default:
+		return "", errors.Wrapf(ErrInvalidKeyFile, "Public key is %d bytes", len(pubKey))
+	}
+	chainid := make([]byte, 4)
+	cm.MachineEndian.PutUint32(chainid, w.cfg.ChainID)
+	address, err := iotxaddress.GetAddress(pubKey, w.cfg.IsTestnet, version, chainid)
+	if err != nil {
+		return "", err
+	}
+	return address, w.storeWatchOnly(address, pubKey)
+}
+
+// IsWatchOnly returns true if the account is watch-only
+func (w *Wallet) IsWatchOnly(address string) (bool, error) {
+	kf, err := readKeyFile(w.cfg.KeystorePath, address)
+	if err != nil {
+		return false, err
+	}
+	return kf.WatchOnly, nil
+}
+
+// ExportKey returns the raw key pair of the account
+func (w *Wallet) ExportKey(address string, passphrase string) (*iotxaddress.Address, error) {
+	kf, err := readKeyFile(w.cfg.KeystorePath, address)
+	if err != nil {
+		return nil, err
+	}
+	return decryptKey(kf, passphrase)
+}
+
+// Import imports a key file exported from a keystore, and returns its address
+// The key file has to be decrypted with the passphrase, and is re-encrypted with the new passphrase.
+func (w *Wallet) Import(keyJSON []byte, passphrase string, newPassphrase string) (string, error) {
+	kf, err := parseKeyFile(keyJSON)
+	if err != nil {
+		return "", err
+	}
+	addr, err := decryptKey(kf, passphrase)
+	if err != nil {
+		return "", err
+	}
+	if err := w.storeKey(addr, newPassphrase); err != nil {
+		return "", err
+	}
+	return addr.Address, nil
+}
+
+// Export returns the key file of the account re-encrypted with the new passphrase, which can be imported into
+// another keystore
+func (w *Wallet) Export(address string, passphrase string, newPassphrase string) ([]byte, error) {
+	addr, err := w.ExportKey(address, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	kf, err := encryptKey(addr, newPassphrase, w.cfg.ScryptN, w.cfg.ScryptP)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(kf)
+}
+
+// Accounts returns the sorted addresses of the accounts in the keystore
+func (w *Wallet) Accounts() ([]string, error) {
+	files, err := ioutil.ReadDir(w.cfg.KeystorePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	accounts := []string{}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+		address := strings.TrimSuffix(name, ".json")
+		if iotxaddress.ValidateAddress(address) {
+			accounts = append(accounts, address)
+		}
+	}
+	sort.Strings(accounts)
+	return accounts, nil
+}
+
+// Delete removes the account from the keystore, the passphrase has to decrypt its key unless it is watch-only
+func (w *Wallet) Delete(address string, passphrase string) error {
+	if _, err := w.ExportKey(address, passphrase); err != nil && errors.Cause(err) != ErrWatchOnly {
+		return err
+	}
+	w.Lock(address)
+	return os.Remove(keyFilePath(w.cfg.KeystorePath, address))
+}
+
+// Unlock decrypts the key of the account, which is available for signing until the timeout expires
+// The account stays unlocked until Lock is called if the timeout is 0. Unlocking an unlocked account resets its
+// timeout.
+func (w *Wallet) Unlock(address string, passphrase string, timeout time.Duration) error {
+	addr, err := w.ExportKey(address, passphrase)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lock(address)
+	u := &unlocked{addr: addr}
+	if timeout > 0 {
+		u.timer = time.AfterFunc(timeout, func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			// the account may have been locked and unlocked again since the timer started
+			if w.unlocked[address] == u {
+				w.lock(address)
+			}
+		})
+	}
+	w.unlocked[address] = u
+	return nil
+}
+
+// Lock drops the decrypted key of the account
+func (w *Wallet) Lock(address string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lock(address)
+}
+
+// IsUnlocked returns true if the account is unlocked
+func (w *Wallet) IsUnlocked(address string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.unlocked[address]
+	return ok
+}
+
+// Signer returns the signing handle of an unlocked account
+// The handle stops signing as soon as the account is locked again.
+func (w *Wallet) Signer(address string) (Signer, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	u, ok := w.unlocked[address]
+	if !ok {
+		return nil, errors.Wrapf(ErrLocked, "%s", address)
+	}
+	return &accountSigner{w: w, address: address, pubkey: u.addr.PublicKey}, nil
+}
+
+// SignMessage signs the message with the key of an unlocked account, proving the ownership of the address off-chain
+// The signature is checked against the address by iotxaddress.VerifyMessage.
+func (w *Wallet) SignMessage(address string, msg []byte) ([]byte, error) {
+	signer, err := w.Signer(address)
+	if err != nil {
+		return nil, err
+	}
+	return SignMessage(signer, msg)
+}
+
+// SignMessage signs the message with the signing handle, see Wallet.SignMessage
+func SignMessage(signer Signer, msg []byte) ([]byte, error) {
+	sig, err := signer.Sign(iotxaddress.MessageHash(msg))
+	if err != nil {
+		return nil, err
+	}
+	return iotxaddress.MessageSignature(signer.PublicKey(), sig), nil
+}
+
+// lock drops the decrypted key of the account, the caller has to hold the write lock
+func (w *Wallet) lock(address string) {
+	u, ok := w.unlocked[address]
+	if !ok {
+		return
+	}
+	if u.timer != nil {
+		u.timer.Stop()
+	}
+	for i := range u.addr.PrivateKey {
+		u.addr.PrivateKey[i] = 0
+	}
+	delete(w.unlocked, address)
+}
+
+// storeKey encrypts the key with the passphrase and writes it into the keystore
+// The key replaces the watch-only account of the address, if any.
+func (w *Wallet) storeKey(addr *iotxaddress.Address, passphrase string) error {
+	if _, err := os.Stat(keyFilePath(w.cfg.KeystorePath, addr.Address)); err == nil {
+		if watchOnly, err := w.IsWatchOnly(addr.Address); err != nil || !watchOnly {
+			return errors.Wrapf(ErrAccountExists, "%s", addr.Address)
+		}
+	}
+	kf, err := encryptKey(addr, passphrase, w.cfg.ScryptN, w.cfg.ScryptP)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(w.cfg.KeystorePath, kf)
+}
+
+// storeWatchOnly writes the watch-only account of the address, along with its public key if known, into the keystore
+func (w *Wallet) storeWatchOnly(address string, pubKey []byte) error {
+	if _, err := os.Stat(keyFilePath(w.cfg.KeystorePath, address)); err == nil {
+		return errors.Wrapf(ErrAccountExists, "%s", address)
+	}
+	kf := &keyFile{Version: keyFileVersion, Address: address, PublicKey: hex.EncodeToString(pubKey), WatchOnly: true}
+	return writeKeyFile(w.cfg.KeystorePath, kf)
+}
+
+// accountSigner signs with the key of an account while it is unlocked in the wallet
+type accountSigner struct {
+	w       *Wallet
+	address string
+	pubkey  []byte
+}
+
+func (s *accountSigner) Address() string { return s.address }
+
+func (s *accountSigner) PublicKey() []byte { return s.pubkey }
+
+func (s *accountSigner) Sign(msg []byte) ([]byte, error) {
+	s.w.mu.RLock()
+	defer s.w.mu.RUnlock()
+	u, ok := s.w.unlocked[s.address]
+	if !ok {
+		return nil, errors.Wrapf(ErrLocked, "%s", s.address)
+	}
+	return signWithKey(u.addr, msg)
+}
+
+// keySigner signs with a raw key held by the caller
+type keySigner struct {
+	addr iotxaddress.Address
+}
+
+// NewKeySigner returns the signing handle of a raw key pair, for callers which manage the key themselves
+func NewKeySigner(addr iotxaddress.Address) Signer {
+	return &keySigner{addr: addr}
+}
+
+func (s *keySigner) Address() string { return s.addr.Address }
+
+func (s *keySigner) PublicKey() []byte { return s.addr.PublicKey }
+
+func (s *keySigner) Sign(msg []byte) ([]byte, error) {
+	if len(s.addr.PrivateKey) == 0 {
+		return nil, errors.Wrapf(ErrLocked, "%s has no private key", s.addr.Address)
+	}
+	return signWithKey(&s.addr, msg)
+}
+
+// signWithKey signs the message with the private key of the address, with a Schnorr signature for a Schnorr address
+func signWithKey(addr *iotxaddress.Address, msg []byte) ([]byte, error) {
+	if iotxaddress.IsSchnorrAddress(addr.Address) {
+		return cp.SchnorrSign(addr.PrivateKey, msg)
+	}
+	return cp.Sign(addr.PrivateKey, msg), nil
+}
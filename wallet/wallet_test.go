@@ -0,0 +1,395 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func newTestWallet(t *testing.T) *Wallet {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.Nil(t, err)
+	return NewWallet(config.Wallet{KeystorePath: dir, ScryptN: LightScryptN, ScryptP: LightScryptP, ChainID: 0x04030201})
+}
+
+func TestWalletAccounts(t *testing.T) {
+	assert := assert.New(t)
+	w := newTestWallet(t)
+	defer os.RemoveAll(w.cfg.KeystorePath)
+
+	accounts, err := w.Accounts()
+	assert.Nil(err)
+	assert.Equal(0, len(accounts))
+
+	addr, err := w.NewAccount("foo")
+	assert.Nil(err)
+	alfa := ta.Addrinfo["alfa"]
+	assert.Nil(w.ImportKey(&alfa, "bar"))
+	assert.Equal(ErrAccountExists, errors.Cause(w.ImportKey(&alfa, "bar")))
+
+	accounts, err = w.Accounts()
+	assert.Nil(err)
+	assert.Equal(2, len(accounts))
+	assert.Contains(accounts, addr)
+	assert.Contains(accounts, alfa.Address)
+
+	// the key file only holds the encrypted private key
+	buf, err := ioutil.ReadFile(keyFilePath(w.cfg.KeystorePath, alfa.Address))
+	assert.Nil(err)
+	assert.NotContains(string(buf), "9bb680a519ac1c034231c33f0e2f46816f6263b2ff642808a5f33998f7e412b1")
+
+	_, err = w.ExportKey(alfa.Address, "foo")
+	assert.Equal(ErrWrongPassphrase, errors.Cause(err))
+	key, err := w.ExportKey(alfa.Address, "bar")
+	assert.Nil(err)
+	assert.Equal(alfa, *key)
+	_, err = w.ExportKey(ta.Addrinfo["bravo"].Address, "bar")
+	assert.Equal(ErrAccountNotFound, errors.Cause(err))
+
+	// move the account to another keystore with a new passphrase
+	keyJSON, err := w.Export(alfa.Address, "bar", "baz")
+	assert.Nil(err)
+	other := newTestWallet(t)
+	defer os.RemoveAll(other.cfg.KeystorePath)
+	_, err = other.Import(keyJSON, "bar", "qux")
+	assert.Equal(ErrWrongPassphrase, errors.Cause(err))
+	imported, err := other.Import(keyJSON, "baz", "qux")
+	assert.Nil(err)
+	assert.Equal(alfa.Address, imported)
+	key, err = other.ExportKey(alfa.Address, "qux")
+	assert.Nil(err)
+	assert.Equal(alfa, *key)
+
+	assert.NotNil(w.Delete(addr, "bar"))
+	assert.Nil(w.Delete(addr, "foo"))
+	accounts, err = w.Accounts()
+	assert.Nil(err)
+	assert.Equal([]string{alfa.Address}, accounts)
+}
+
+func TestWalletScryptParams(t *testing.T) {
+	assert := assert.New(t)
+	w := newTestWallet(t)
+	defer os.RemoveAll(w.cfg.KeystorePath)
+
+	alfa := ta.Addrinfo["alfa"]
+	assert.Nil(w.ImportKey(&alfa, "foo"))
+	keyJSON, err := w.Export(alfa.Address, "foo", "bar")
+	assert.Nil(err)
+
+	// a key file asking for more memory or time than any key file written takes is refused before deriving the key
+	for _, params := range []kdfParams{{N: 1 << 30, R: scryptR, P: 1}, {N: LightScryptN, R: 1 << 20, P: 1},
+		{N: LightScryptN, R: scryptR, P: 1 << 20}, {N: LightScryptN, R: scryptR, P: 1, DKLen: 1 << 30}} {
+		kf, err := parseKeyFile(keyJSON)
+		assert.Nil(err)
+		if params.DKLen == 0 {
+			params.DKLen = scryptDKLen
+		}
+		params.Salt = kf.Crypto.KDFParams.Salt
+		kf.Crypto.KDFParams = params
+		buf, err := json.Marshal(kf)
+		assert.Nil(err)
+		other := newTestWallet(t)
+		_, err = other.Import(buf, "bar", "baz")
+		assert.Equal(ErrInvalidKeyFile, errors.Cause(err))
+		os.RemoveAll(other.cfg.KeystorePath)
+	}
+}
+
+func TestWalletUnlock(t *testing.T) {
+	assert := assert.New(t)
+	w := newTestWallet(t)
+	defer os.RemoveAll(w.cfg.KeystorePath)
+
+	alfa := ta.Addrinfo["alfa"]
+	assert.Nil(w.ImportKey(&alfa, "foo"))
+	_, err := w.Signer(alfa.Address)
+	assert.Equal(ErrLocked, errors.Cause(err))
+	assert.Equal(ErrWrongPassphrase, errors.Cause(w.Unlock(alfa.Address, "bar", 0)))
+
+	assert.Nil(w.Unlock(alfa.Address, "foo", 0))
+	assert.True(w.IsUnlocked(alfa.Address))
+	signer, err := w.Signer(alfa.Address)
+	assert.Nil(err)
+	assert.Equal(alfa.Address, signer.Address())
+	assert.Equal(alfa.PublicKey, signer.PublicKey())
+	sig, err := signer.Sign([]byte("message"))
+	assert.Nil(err)
+	assert.True(cp.Verify(alfa.PublicKey, []byte("message"), sig))
+
+	// the signed message proves the ownership of the address
+	sig, err = w.SignMessage(alfa.Address, []byte("message"))
+	assert.Nil(err)
+	assert.True(iotxaddress.VerifyMessage(alfa.Address, []byte("message"), sig))
+	assert.False(iotxaddress.VerifyMessage(ta.Addrinfo["bravo"].Address, []byte("message"), sig))
+
+	// the handle stops signing once the account is locked
+	w.Lock(alfa.Address)
+	assert.False(w.IsUnlocked(alfa.Address))
+	_, err = signer.Sign([]byte("message"))
+	assert.Equal(ErrLocked, errors.Cause(err))
+
+	// the account is locked again after the timeout
+	assert.Nil(w.Unlock(alfa.Address, "foo", 50*time.Millisecond))
+	_, err = signer.Sign([]byte("message"))
+	assert.Nil(err)
+	time.Sleep(100 * time.Millisecond)
+	assert.False(w.IsUnlocked(alfa.Address))
+	_, err = signer.Sign([]byte("message"))
+	assert.Equal(ErrLocked, errors.Cause(err))
+
+	// a raw key signer signs with the key of the caller
+	sig, err = NewKeySigner(alfa).Sign([]byte("message"))
+	assert.Nil(err)
+	assert.True(cp.Verify(alfa.PublicKey, []byte("message"), sig))
+
+	// a Schnorr account signs with Schnorr signatures
+	addr, err := w.NewSchnorrAccount("foo")
+	assert.Nil(err)
+	assert.True(iotxaddress.IsSchnorrAddress(addr))
+	assert.Nil(w.Unlock(addr, "foo", 0))
+	signer, err = w.Signer(addr)
+	assert.Nil(err)
+	sig, err = signer.Sign([]byte("message"))
+	assert.Nil(err)
+	assert.True(cp.SchnorrVerify(signer.PublicKey(), []byte("message"), sig))
+}
+
+func TestWalletWatchOnly(t *testing.T) {
+	assert := assert.New(t)
+	w := newTestWallet(t)
+	defer os.RemoveAll(w.cfg.KeystorePath)
+
+	alfa, bravo := ta.Addrinfo["alfa"], ta.Addrinfo["bravo"]
+	assert.Equal(ErrInvalidKeyFile, errors.Cause(w.ImportAddress("io1invalid")))
+	assert.Nil(w.ImportAddress(alfa.Address))
+	assert.Equal(ErrAccountExists, errors.Cause(w.ImportAddress(alfa.Address)))
+	_, err := w.ImportPublicKey(bravo.PublicKey[1:])
+	assert.Equal(ErrInvalidKeyFile, errors.Cause(err))
+	addr, err := w.ImportPublicKey(bravo.PublicKey)
+	assert.Nil(err)
+	assert.Equal(iotxaddress.HashPubKey(bravo.PublicKey), iotxaddress.GetPubkeyHash(addr))
+
+	// the watch-only accounts are listed but cannot sign
+	accounts, err := w.Accounts()
+	assert.Nil(err)
+	assert.Equal(2, len(accounts))
+	assert.Contains(accounts, alfa.Address)
+	assert.Contains(accounts, addr)
+	watchOnly, err := w.IsWatchOnly(addr)
+	assert.Nil(err)
+	assert.True(watchOnly)
+	assert.Equal(ErrWatchOnly, errors.Cause(w.Unlock(alfa.Address, "foo", 0)))
+	_, err = w.ExportKey(addr, "foo")
+	assert.Equal(ErrWatchOnly, errors.Cause(err))
+
+	// importing the key turns the watch-only account into a regular one
+	assert.Nil(w.ImportKey(&alfa, "foo"))
+	watchOnly, err = w.IsWatchOnly(alfa.Address)
+	assert.Nil(err)
+	assert.False(watchOnly)
+	assert.Nil(w.Unlock(alfa.Address, "foo", 0))
+	assert.Equal(ErrAccountExists, errors.Cause(w.ImportAddress(alfa.Address)))
+
+	// a watch-only account is deleted without passphrase
+	assert.Nil(w.Delete(addr, ""))
+	accounts, err = w.Accounts()
+	assert.Nil(err)
+	assert.Equal([]string{alfa.Address}, accounts)
+}
+
+func TestHDWallet(t *testing.T) {
+	assert := assert.New(t)
+	w := newTestWallet(t)
+	defer os.RemoveAll(w.cfg.KeystorePath)
+
+	mnemonic := "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title"
+	hd, err := NewHDWallet(mnemonic, "", w.cfg)
+	assert.Nil(err)
+	_, err = NewHDWallet("legal winner", "", w.cfg)
+	assert.NotNil(err)
+
+	receive, err := hd.ReceiveAddress(0, 0)
+	assert.Nil(err)
+	change, err := hd.ChangeAddress(0, 0)
+	assert.Nil(err)
+	other, err := hd.ReceiveAddress(1, 0)
+	assert.Nil(err)
+	assert.NotEqual(receive.Address, change.Address)
+	assert.NotEqual(receive.Address, other.Address)
+
+	// the same mnemonic derives the same addresses, while another passphrase derives different ones
+	restored, err := NewHDWallet(mnemonic, "", w.cfg)
+	assert.Nil(err)
+	addr, err := restored.ReceiveAddress(0, 0)
+	assert.Nil(err)
+	assert.Equal(receive, addr)
+	protected, err := NewHDWallet(mnemonic, "passphrase", w.cfg)
+	assert.Nil(err)
+	addr, err = protected.ReceiveAddress(0, 0)
+	assert.Nil(err)
+	assert.NotEqual(receive.Address, addr.Address)
+
+	addresses, err := w.Restore(restored, 0, 2, "foo")
+	assert.Nil(err)
+	assert.Equal(4, len(addresses))
+	assert.Equal(receive.Address, addresses[0])
+	assert.Equal(change.Address, addresses[1])
+	addresses, err = w.Restore(restored, 0, 2, "foo")
+	assert.Nil(err)
+	assert.Equal(4, len(addresses))
+	accounts, err := w.Accounts()
+	assert.Nil(err)
+	assert.Equal(4, len(accounts))
+
+	key, err := w.ExportKey(change.Address, "foo")
+	assert.Nil(err)
+	assert.Equal(change, key)
+}
+
+// testDevice emulates the signing application of a hardware wallet holding the keys derived from a seed
+type testDevice struct {
+	master *iotxaddress.HDKey
+	deny   bool
+	// pending is the command being sent in chunks
+	pending []byte
+}
+
+func (d *testDevice) Exchange(apdu []byte) ([]byte, error) {
+	if len(apdu) < 5 || apdu[0] != apduClass || int(apdu[4]) != len(apdu)-5 {
+		return []byte{0x6a, 0x80}, nil
+	}
+	if apdu[2] == p1FirstChunk {
+		d.pending = nil
+	}
+	d.pending = append(d.pending, apdu[5:]...)
+	if apdu[1] == insSign && len(apdu[5:]) == maxApduDataSize {
+		return []byte{0x90, 0x00}, nil
+	}
+
+	// decode the derivation path preceding the message
+	key := d.master
+	levels := int(d.pending[0])
+	for i := 0; i < levels; i++ {
+		var err error
+		if key, err = key.Child(binary.BigEndian.Uint32(d.pending[1+4*i:])); err != nil {
+			return nil, err
+		}
+	}
+	addr, err := key.Address(false, addressVersion, []byte{0x01, 0x02, 0x03, 0x04})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case apdu[1] == insGetPublicKey:
+		return append(addr.PublicKey, 0x90, 0x00), nil
+	case d.deny:
+		return []byte{0x69, 0x85}, nil
+	default:
+		return append(cp.Sign(addr.PrivateKey, d.pending[1+4*levels:]), 0x90, 0x00), nil
+	}
+}
+
+func (d *testDevice) Close() error { return nil }
+
+// hidLoopback forwards the APDU framed in the HID reports written to it to the device, and frames its responses
+type hidLoopback struct {
+	dev     Device
+	request []byte
+	resp    bytes.Buffer
+}
+
+func (l *hidLoopback) Write(report []byte) (int, error) {
+	// skip the report ID and the header
+	data := report[1+hidHeaderLength:]
+	if binary.BigEndian.Uint16(report[4:]) == 0 {
+		l.request = nil
+	}
+	l.request = append(l.request, data...)
+	length := int(binary.BigEndian.Uint16(l.request))
+	if len(l.request) < length+2 {
+		return len(report), nil
+	}
+	resp, err := l.dev.Exchange(l.request[2 : length+2])
+	if err != nil {
+		return 0, err
+	}
+	data = make([]byte, 2)
+	binary.BigEndian.PutUint16(data, uint16(len(resp)))
+	data = append(data, resp...)
+	for seq := uint16(0); len(data) > 0; seq++ {
+		report := make([]byte, hidReportSize)
+		binary.BigEndian.PutUint16(report, hidChannel)
+		report[2] = hidTagApdu
+		binary.BigEndian.PutUint16(report[3:], seq)
+		data = data[copy(report[hidHeaderLength:], data):]
+		l.resp.Write(report)
+	}
+	return len(report), nil
+}
+
+func (l *hidLoopback) Read(p []byte) (int, error) { return l.resp.Read(p) }
+
+func (l *hidLoopback) Close() error { return nil }
+
+func TestDeviceSigner(t *testing.T) {
+	assert := assert.New(t)
+	w := newTestWallet(t)
+	defer os.RemoveAll(w.cfg.KeystorePath)
+
+	mnemonic := "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title"
+	seed, err := iotxaddress.MnemonicToSeed(mnemonic, "")
+	assert.Nil(err)
+	master, err := iotxaddress.NewMasterKey(seed)
+	assert.Nil(err)
+	device := &testDevice{master: master}
+	hd, err := NewHDWallet(mnemonic, "", w.cfg)
+	assert.Nil(err)
+	receive, err := hd.ReceiveAddress(0, 1)
+	assert.Nil(err)
+
+	_, err = NewDeviceSigner(device, "m/44/0", w.cfg)
+	assert.Equal(iotxaddress.ErrInvalidDerivation, errors.Cause(err))
+	signer, err := NewDeviceSigner(NewHIDDevice(&hidLoopback{dev: device}), iotxaddress.DerivationPath(0, 0, 1), w.cfg)
+	assert.Nil(err)
+	assert.Equal(receive.Address, signer.Address())
+	assert.Equal(receive.PublicKey, signer.PublicKey())
+
+	// messages longer than an APDU are sent in chunks
+	for _, msg := range [][]byte{[]byte("foo"), bytes.Repeat([]byte{0x01}, 600)} {
+		sig, err := signer.Sign(msg)
+		assert.Nil(err)
+		assert.True(cp.Verify(receive.PublicKey, msg, sig))
+	}
+	sig, err := SignMessage(signer, []byte("foo"))
+	assert.Nil(err)
+	assert.True(iotxaddress.VerifyMessage(receive.Address, []byte("foo"), sig))
+
+	// the device tracks the address as a watch-only account, and may deny signing
+	_, err = w.ImportPublicKey(signer.PublicKey())
+	assert.Nil(err)
+	watchOnly, err := w.IsWatchOnly(signer.Address())
+	assert.Nil(err)
+	assert.True(watchOnly)
+	device.deny = true
+	_, err = signer.Sign([]byte("foo"))
+	assert.Equal(ErrDeviceDenied, errors.Cause(err))
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package wire
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+)
+
+// nestedTypes caches the types of the nested messages of the message types, by field number
+var nestedTypes sync.Map
+
+// scan walks the encoding of a message of type t without decoding it, failing with ErrMalformedMsg if the encoding
+// is truncated or uses a wire type proto3 does not, or with blockchain.ErrMsgTooLarge once the length-delimited fields
+// it carries at any depth exceed the budget
+func scan(buf []byte, t reflect.Type, budget *int) error {
+	nested := nestedTypesOf(t)
+	for len(buf) > 0 {
+		key, n := proto.DecodeVarint(buf)
+		if n == 0 {
+			return errors.Wrap(ErrMalformedMsg, "truncated field key")
+		}
+		buf = buf[n:]
+		switch key & 7 {
+		case proto.WireVarint:
+			if _, n = proto.DecodeVarint(buf); n == 0 {
+				return errors.Wrap(ErrMalformedMsg, "truncated varint")
+			}
+			buf = buf[n:]
+		case proto.WireFixed64:
+			if len(buf) < 8 {
+				return errors.Wrap(ErrMalformedMsg, "truncated fixed64")
+			}
+			buf = buf[8:]
+		case proto.WireFixed32:
+			if len(buf) < 4 {
+				return errors.Wrap(ErrMalformedMsg, "truncated fixed32")
+			}
+			buf = buf[4:]
+		case proto.WireBytes:
+			size, n := proto.DecodeVarint(buf)
+			if n == 0 || size > uint64(len(buf)-n) {
+				return errors.Wrap(ErrMalformedMsg, "truncated length-delimited field")
+			}
+			field := buf[n : n+int(size)]
+			buf = buf[n+int(size):]
+			if *budget--; *budget < 0 {
+				return errors.Wrapf(blockchain.ErrMsgTooLarge, "more than %d length-delimited fields", MaxMsgFields)
+			}
+			if nt, ok := nested[key>>3]; ok {
+				if err := scan(field, nt, budget); err != nil {
+					return err
+				}
+			}
+		default:
+			return errors.Wrapf(ErrMalformedMsg, "wire type %d", key&7)
+		}
+	}
+	return nil
+}
+
+// nestedTypesOf returns the types of the nested messages of the message type t, by field number, read from the
+// protobuf tags of its generated struct
+func nestedTypesOf(t reflect.Type) map[uint64]reflect.Type {
+	if nested, ok := nestedTypes.Load(t); ok {
+		return nested.(map[uint64]reflect.Type)
+	}
+	nested := make(map[uint64]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("protobuf"), ",")
+		if len(tag) < 2 || tag[0] != "bytes" {
+			continue
+		}
+		num, err := strconv.ParseUint(tag[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		ft := field.Type
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct {
+			nested[num] = ft.Elem()
+		}
+	}
+	nestedTypes.Store(t, nested)
+	return nested
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+// Package wire decodes the messages received from peers, which are untrusted, so a malicious peer cannot crash the
+// node or make it allocate unbounded memory with a crafted message
+package wire
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	iproto "github.com/iotexproject/iotex-core/proto"
+)
+
+const (
+	// MaxRequestMsgSize is the maximum size in bytes of a message requesting blocks, headers or transactions
+	MaxRequestMsgSize = 1 << 20
+	// MaxMsgFields is the maximum number of length-delimited fields, i.e., nested messages, bytes and strings, a
+	// message may carry at any depth, bounding the allocations of decoding it
+	MaxMsgFields = 1 << 20
+)
+
+// ErrMalformedMsg indicates the message received from a peer is not the canonical encoding of a valid message
+var ErrMalformedMsg = errors.New("malformed message")
+
+// maxMsgSizes are the maximum sizes in bytes of the messages by type, the types missing are not accepted from peers
+var maxMsgSizes = map[uint32]int{
+	iproto.MsgTxProtoMsgType:          blockchain.MaxTxMsgSize,
+	iproto.MsgBlockProtoMsgType:       blockchain.MaxBlockMsgSize,
+	iproto.ViewChangeMsgType:          blockchain.MaxBlockMsgSize,
+	iproto.MsgBlockSyncReqType:        MaxRequestMsgSize,
+	iproto.MsgBlockSyncDataType:       blockchain.MaxBlockMsgSize,
+	iproto.MsgBlockHeaderSyncReqType:  MaxRequestMsgSize,
+	iproto.MsgBlockHeaderSyncDataType: blockchain.MaxBlockMsgSize,
+	iproto.MsgCompactBlockType:        blockchain.MaxBlockMsgSize,
+	iproto.MsgBlockTxsSyncReqType:     MaxRequestMsgSize,
+	iproto.MsgBlockTxsSyncDataType:    blockchain.MaxBlockMsgSize,
+	iproto.MsgCheckpointVoteType:      MaxRequestMsgSize,
+	iproto.TestPayloadType:            blockchain.MaxBlockMsgSize,
+}
+
+// Decode decodes the message of the type received from a peer
+// The message has to fit in the maximum size of its type and carry at most MaxMsgFields length-delimited fields,
+// otherwise blockchain.ErrMsgTooLarge is returned before it is decoded. It has to be the canonical encoding of the
+// message, i.e., the one proto.Marshal produces, without unknown fields, and its fields have to be well formed, e.g.,
+// the hashes have to be of the hash size and the counts have to match the content they count, otherwise
+// ErrMalformedMsg is returned.
+func Decode(msgType uint32, body []byte) (proto.Message, error) {
+	maxSize, ok := maxMsgSizes[msgType]
+	if !ok {
+		return nil, errors.Wrapf(ErrMalformedMsg, "unknown message type %d", msgType)
+	}
+	if len(body) > maxSize {
+		return nil, errors.Wrapf(blockchain.ErrMsgTooLarge, "message of type %d has %d bytes, the limit is %d", msgType, len(body), maxSize)
+	}
+	msg, err := iproto.NewProtoMsg(msgType)
+	if err != nil {
+		return nil, errors.Wrapf(ErrMalformedMsg, "%v", err)
+	}
+	budget := MaxMsgFields
+	if err := scan(body, reflect.TypeOf(msg).Elem(), &budget); err != nil {
+		return nil, errors.Wrapf(err, "message of type %d", msgType)
+	}
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, errors.Wrapf(ErrMalformedMsg, "cannot unmarshal message of type %d: %v", msgType, err)
+	}
+	canonical, err := proto.Marshal(msg)
+	if err != nil || !bytes.Equal(canonical, body) {
+		return nil, errors.Wrapf(ErrMalformedMsg, "message of type %d is not canonically encoded", msgType)
+	}
+	if err := validate(msg); err != nil {
+		return nil, errors.Wrapf(err, "message of type %d", msgType)
+	}
+	return msg, nil
+}
+
+// validate returns an error if the fields of the message are not well formed
+func validate(msg proto.Message) error {
+	switch msg := msg.(type) {
+	case *iproto.TxPb:
+		return wrap(blockchain.CheckTxPb(msg))
+	case *iproto.BlockPb:
+		return checkBlock(msg)
+	case *iproto.ViewChangeMsg:
+		if msg.Block != nil {
+			if err := checkBlock(msg.Block); err != nil {
+				return err
+			}
+		}
+		if len(msg.BlockHash) != 0 {
+			return checkHash("block hash", msg.BlockHash)
+		}
+	case *iproto.BlockContainer:
+		if msg.Block == nil && len(msg.Blocks) == 0 {
+			return errors.Wrap(ErrMalformedMsg, "no block")
+		}
+		if msg.Block != nil {
+			if err := checkBlock(msg.Block); err != nil {
+				return err
+			}
+		}
+		for i, blk := range msg.Blocks {
+			if err := checkBlock(blk); err != nil {
+				return errors.Wrapf(err, "block %d", i)
+			}
+		}
+	case *iproto.BlockHeaderContainer:
+		for i, header := range msg.Headers {
+			if err := checkHeader(header); err != nil {
+				return errors.Wrapf(err, "header %d", i)
+			}
+		}
+	case *iproto.CompactBlockPb:
+		return checkCompactBlock(msg)
+	case *iproto.BlockTxsSync:
+		return checkHash("block hash", msg.BlockHash)
+	case *iproto.BlockTxsContainer:
+		if err := checkHash("block hash", msg.BlockHash); err != nil {
+			return err
+		}
+		for i, tx := range msg.Txs {
+			if err := blockchain.CheckTxPb(tx); err != nil {
+				return errors.Wrapf(wrap(err), "tx %d", i)
+			}
+		}
+	case *iproto.CheckpointVotePb:
+		if err := checkHash("hash", msg.Hash); err != nil {
+			return err
+		}
+		if len(msg.PubKey) != ed25519.PublicKeySize || len(msg.Signature) != ed25519.SignatureSize {
+			return errors.Wrapf(ErrMalformedMsg, "public key of %d bytes and signature of %d bytes", len(msg.PubKey), len(msg.Signature))
+		}
+	}
+	return nil
+}
+
+// checkBlock returns an error if the header of the block is not well formed, or the block does not match it
+func checkBlock(blk *iproto.BlockPb) error {
+	if err := checkHeader(blk.Header); err != nil {
+		return err
+	}
+	return wrap(blockchain.CheckBlockPb(blk))
+}
+
+// checkCompactBlock returns an error if the header of the compact block is not well formed, it has not as many
+// prefilled transactions and short IDs as its header counts, or one of them is not well formed
+func checkCompactBlock(cb *iproto.CompactBlockPb) error {
+	if err := checkHeader(cb.Header); err != nil {
+		return err
+	}
+	if n := cb.Header.TrnxNumber; int(n) != len(cb.Prefilled)+len(cb.ShortIDs) {
+		return errors.Wrapf(ErrMalformedMsg, "compact block has %d prefilled txs and %d short IDs, header counts %d",
+			len(cb.Prefilled), len(cb.ShortIDs), n)
+	}
+	for i, tx := range cb.Prefilled {
+		if err := blockchain.CheckTxPb(tx); err != nil {
+			return errors.Wrapf(wrap(err), "prefilled tx %d", i)
+		}
+	}
+	for i, id := range cb.ShortIDs {
+		if len(id) != blockchain.ShortIDLen {
+			return errors.Wrapf(ErrMalformedMsg, "short ID %d has %d bytes", i, len(id))
+		}
+	}
+	for i, act := range cb.Actions {
+		if _, err := blockchain.DeserializeAction(act.GetKind(), act.GetPayload()); err != nil {
+			return errors.Wrapf(ErrMalformedMsg, "action %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// checkHeader returns an error if the header is missing or one of its hashes is not of the hash size
+func checkHeader(header *iproto.BlockHeaderPb) error {
+	if header == nil {
+		return errors.Wrap(ErrMalformedMsg, "no block header")
+	}
+	if err := checkHash("prev block hash", header.PrevBlockHash); err != nil {
+		return err
+	}
+	if err := checkHash("merkle root", header.MerkleRoot); err != nil {
+		return err
+	}
+	return checkHash("state root", header.StateRoot)
+}
+
+// checkHash returns an error if the hash is not of the hash size
+func checkHash(name string, hash []byte) error {
+	if len(hash) != cp.HashSize {
+		return errors.Wrapf(ErrMalformedMsg, "%s has %d bytes", name, len(hash))
+	}
+	return nil
+}
+
+// wrap wraps the error of checking a block or a transaction into ErrMalformedMsg
+func wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.Wrapf(ErrMalformedMsg, "%v", err)
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided ‘as is’ and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+package wire
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	iproto "github.com/iotexproject/iotex-core/proto"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func testBlock() *blockchain.Block {
+	amount := uint64(50 << 22)
+	cbtx := blockchain.NewCoinbaseTx(ta.Addrinfo["miner"].Address, amount, blockchain.GenesisCoinbaseData)
+	tx := blockchain.NewCoinbaseTx(ta.Addrinfo["alfa"].Address, amount, blockchain.GenesisCoinbaseData)
+	blk := blockchain.NewBlock(1, 3, cp.ZeroHash32B, []*blockchain.Tx{cbtx, tx})
+	blk.SignBlock(ta.Addrinfo["miner"].PublicKey, ta.Addrinfo["miner"].PrivateKey)
+	return blk
+}
+
+func encode(t *testing.T, msg proto.Message) []byte {
+	buf, err := proto.Marshal(msg)
+	require.Nil(t, err)
+	return buf
+}
+
+func TestDecode(t *testing.T) {
+	require := require.New(t)
+
+	blk := testBlock()
+	hash := blk.HashBlock()
+	blkPb := blk.ConvertToBlockPb()
+	for msgType, msg := range map[uint32]proto.Message{
+		iproto.MsgTxProtoMsgType:          blk.Tranxs[0].ConvertToTxPb(),
+		iproto.MsgBlockProtoMsgType:       blkPb,
+		iproto.ViewChangeMsgType:          &iproto.ViewChangeMsg{Block: blkPb, BlockHash: hash[:]},
+		iproto.MsgBlockSyncReqType:        &iproto.BlockSync{Start: 1, End: 3},
+		iproto.MsgBlockSyncDataType:       &iproto.BlockContainer{Blocks: []*iproto.BlockPb{blkPb, blkPb}},
+		iproto.MsgBlockHeaderSyncDataType: &iproto.BlockHeaderContainer{Headers: []*iproto.BlockHeaderPb{blkPb.Header}},
+		iproto.MsgCompactBlockType:        blockchain.NewCompactBlock(blk).ConvertToCompactBlockPb(),
+		iproto.MsgBlockTxsSyncReqType:     &iproto.BlockTxsSync{BlockHash: hash[:], Indexes: []uint32{1}},
+		iproto.MsgBlockTxsSyncDataType:    &iproto.BlockTxsContainer{BlockHash: hash[:], Txs: blkPb.Transactions},
+		iproto.MsgCheckpointVoteType: &iproto.CheckpointVotePb{Height: 3, Hash: hash[:],
+			PubKey: ta.Addrinfo["miner"].PublicKey, Signature: make([]byte, 64)},
+	} {
+		decoded, err := Decode(msgType, encode(t, msg))
+		require.Nil(err, "type %d", msgType)
+		require.True(proto.Equal(msg, decoded), "type %d", msgType)
+	}
+
+	// unknown types are refused
+	_, err := Decode(12345, nil)
+	require.Equal(ErrMalformedMsg, errors.Cause(err))
+	// as well as messages larger than the limit of their type
+	_, err = Decode(iproto.MsgBlockSyncReqType, make([]byte, MaxRequestMsgSize+1))
+	require.Equal(blockchain.ErrMsgTooLarge, errors.Cause(err))
+}
+
+func TestDecodeCanonical(t *testing.T) {
+	require := require.New(t)
+
+	buf := encode(t, &iproto.BlockSync{Start: 1, End: 3})
+	// fields out of order
+	_, err := Decode(iproto.MsgBlockSyncReqType, append(append([]byte{}, buf[2:]...), buf[:2]...))
+	require.Equal(ErrMalformedMsg, errors.Cause(err))
+	// a field repeated
+	_, err = Decode(iproto.MsgBlockSyncReqType, append(append([]byte{}, buf...), buf[:2]...))
+	require.Equal(ErrMalformedMsg, errors.Cause(err))
+	// an unknown field
+	_, err = Decode(iproto.MsgBlockSyncReqType, append(append([]byte{}, buf...), 0x78, 1))
+	require.Equal(ErrMalformedMsg, errors.Cause(err))
+	// a varint not of the minimal length
+	_, err = Decode(iproto.MsgBlockSyncReqType, []byte{0x10, 0x81, 0x00, 0x18, 0x03})
+	require.Equal(ErrMalformedMsg, errors.Cause(err))
+	// a truncated field
+	_, err = Decode(iproto.MsgBlockSyncReqType, buf[:len(buf)-1])
+	require.Equal(ErrMalformedMsg, errors.Cause(err))
+	// a group
+	_, err = Decode(iproto.MsgBlockSyncReqType, []byte{0x0b, 0x0c})
+	require.Equal(ErrMalformedMsg, errors.Cause(err))
+}
+
+func TestDecodeFields(t *testing.T) {
+	require := require.New(t)
+
+	blk := testBlock()
+	hash := blk.HashBlock()
+	for msgType, msg := range map[uint32]proto.Message{
+		// a block without header
+		iproto.MsgBlockProtoMsgType: &iproto.BlockPb{},
+		// a block counting more transactions than it has
+		iproto.ViewChangeMsgType: func() proto.Message {
+			blkPb := blk.ConvertToBlockPb()
+			blkPb.Header.TrnxNumber++
+			return &iproto.ViewChangeMsg{Block: blkPb}
+		}(),
+		// no block
+		iproto.MsgBlockSyncDataType: &iproto.BlockContainer{},
+		// a header with a short hash
+		iproto.MsgBlockHeaderSyncDataType: func() proto.Message {
+			header := blk.ConvertToBlockPb().Header
+			header.MerkleRoot = header.MerkleRoot[1:]
+			return &iproto.BlockHeaderContainer{Headers: []*iproto.BlockHeaderPb{header}}
+		}(),
+		// a short ID of the wrong size
+		iproto.MsgCompactBlockType: func() proto.Message {
+			cb := blockchain.NewCompactBlock(blk).ConvertToCompactBlockPb()
+			cb.ShortIDs[0] = append(cb.ShortIDs[0], 0)
+			return cb
+		}(),
+		// a transaction whose script size does not match its script
+		iproto.MsgBlockTxsSyncDataType: func() proto.Message {
+			txPb := blk.Tranxs[0].ConvertToTxPb()
+			txPb.TxOut[0].LockScriptSize++
+			return &iproto.BlockTxsContainer{BlockHash: hash[:], Txs: []*iproto.TxPb{txPb}}
+		}(),
+		// a vote without signature
+		iproto.MsgCheckpointVoteType: &iproto.CheckpointVotePb{Height: 3, Hash: hash[:], PubKey: ta.Addrinfo["miner"].PublicKey},
+	} {
+		_, err := Decode(msgType, encode(t, msg))
+		require.Equal(ErrMalformedMsg, errors.Cause(err), "type %d", msgType)
+	}
+}
+
+func TestDecodeAllocation(t *testing.T) {
+	require := require.New(t)
+
+	// empty headers cost 2 bytes each but would be allocated in full by decoding
+	buf := bytes.Repeat([]byte{0x0a, 0x00}, MaxMsgFields+1)
+	_, err := Decode(iproto.MsgBlockHeaderSyncDataType, buf)
+	require.Equal(blockchain.ErrMsgTooLarge, errors.Cause(err))
+
+	// the fields are counted at any depth
+	tx := bytes.Repeat([]byte{0x22, 0x00}, MaxMsgFields/2)
+	var blk []byte
+	for i := 0; i < 3; i++ {
+		blk = append(blk, 0x12)
+		blk = append(blk, proto.EncodeVarint(uint64(len(tx)))...)
+		blk = append(blk, tx...)
+	}
+	_, err = Decode(iproto.MsgBlockProtoMsgType, blk)
+	require.Equal(blockchain.ErrMsgTooLarge, errors.Cause(err))
+}
+
+func TestDecodeFuzz(t *testing.T) {
+	blk := testBlock()
+	hash := blk.HashBlock()
+	blkPb := blk.ConvertToBlockPb()
+	msgs := map[uint32][]byte{
+		iproto.MsgBlockProtoMsgType:    encode(t, blkPb),
+		iproto.MsgBlockSyncDataType:    encode(t, &iproto.BlockContainer{Blocks: []*iproto.BlockPb{blkPb}}),
+		iproto.MsgCompactBlockType:     encode(t, blockchain.NewCompactBlock(blk).ConvertToCompactBlockPb()),
+		iproto.MsgBlockTxsSyncDataType: encode(t, &iproto.BlockTxsContainer{BlockHash: hash[:], Txs: blkPb.Transactions}),
+	}
+	r := rand.New(rand.NewSource(0))
+	for msgType, buf := range msgs {
+		for i := 0; i < 2000; i++ {
+			mutated := append([]byte{}, buf...)
+			switch r.Intn(3) {
+			case 0:
+				for j := 1 + r.Intn(4); j > 0; j-- {
+					mutated[r.Intn(len(mutated))] = byte(r.Intn(256))
+				}
+			case 1:
+				mutated = mutated[:r.Intn(len(mutated))]
+			default:
+				pos := r.Intn(len(mutated))
+				mutated = append(mutated[:pos], append([]byte{byte(r.Intn(256))}, mutated[pos:]...)...)
+			}
+			msg, err := Decode(msgType, mutated)
+			if err != nil {
+				continue
+			}
+			// a message decoded is safe to convert
+			switch msg := msg.(type) {
+			case *iproto.BlockPb:
+				(&blockchain.Block{}).ConvertFromBlockPb(msg)
+			case *iproto.BlockContainer:
+				for _, blkPb := range msg.Blocks {
+					(&blockchain.Block{}).ConvertFromBlockPb(blkPb)
+				}
+			case *iproto.CompactBlockPb:
+				(&blockchain.CompactBlock{}).ConvertFromCompactBlockPb(msg)
+			case *iproto.BlockTxsContainer:
+				for _, txPb := range msg.Txs {
+					(&blockchain.Tx{}).ConvertFromTxPb(txPb)
+				}
+			}
+		}
+	}
+}